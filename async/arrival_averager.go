@@ -0,0 +1,59 @@
+package async
+
+import (
+	"sync"
+	"time"
+)
+
+// arrivalSmoothingFactor is the weight of the newest inter-arrival time in
+// the moving average. Lower values react more slowly to bursts.
+const arrivalSmoothingFactor = 0.2
+
+// arrivalAverager tracks an exponential moving average of the time between
+// message arrivals. It is safe for concurrent use.
+type arrivalAverager struct {
+	mu      sync.Mutex
+	alpha   float64
+	ema     time.Duration
+	last    time.Time
+	samples int // inter-arrival samples folded into ema
+}
+
+// newArrivalAverager creates an averager weighting each new sample by alpha.
+func newArrivalAverager(alpha float64) *arrivalAverager {
+	return &arrivalAverager{alpha: alpha}
+}
+
+// record adds an arrival at t and returns the updated average. Arrivals at or
+// before the previous one are ignored, so replays and out-of-order timestamps
+// do not skew the average. ok is false until two arrivals have been seen.
+func (a *arrivalAverager) record(t time.Time) (avg time.Duration, ok bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.last.IsZero() {
+		a.last = t
+		return 0, false
+	}
+	if !t.After(a.last) {
+		return a.ema, a.samples > 0
+	}
+
+	gap := t.Sub(a.last)
+	a.last = t
+	if a.samples == 0 {
+		a.ema = gap
+	} else {
+		a.ema = time.Duration(a.alpha*float64(gap) + (1-a.alpha)*float64(a.ema))
+	}
+	a.samples++
+	return a.ema, true
+}
+
+// average returns the current average; ok is false until two arrivals have
+// been seen.
+func (a *arrivalAverager) average() (avg time.Duration, ok bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.ema, a.samples > 0
+}
@@ -82,6 +82,7 @@ type AsyncClient struct {
 	parallelizeQueries bool                             // Whether to query storage nodes in parallel
 	erasureStorage     *ErasureStorage                  // Erasure-coded shard storage for message reconstruction
 	erasureEnabled     bool                             // Whether to use erasure-coded storage (default: true)
+	replicationFactor  int                              // Replicas per message without erasure coding
 	stopChan           chan struct{}                    // Channel to signal goroutine shutdown
 	closeOnce          sync.Once                        // Ensures Close is idempotent (M-19)
 	forwardSecurity    *ForwardSecurityManager          // Forward secrecy manager (optional; set by AsyncManager)
@@ -90,7 +91,7 @@ type AsyncClient struct {
 // NewAsyncClient creates a new async messaging client with obfuscation support
 // and erasure-coded storage for message redundancy across k=5 storage nodes.
 func NewAsyncClient(keyPair *crypto.KeyPair, trans transport.Transport) *AsyncClient {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":           "NewAsyncClient",
 		"public_key_preview": fmt.Sprintf("%x", keyPair.Public[:8]),
 	}).Info("Creating new async client")
@@ -112,13 +113,14 @@ func NewAsyncClient(keyPair *crypto.KeyPair, trans transport.Transport) *AsyncCl
 		parallelizeQueries: true,
 		erasureStorage:     erasureStorage,
 		erasureEnabled:     erasureStorage != nil,
+		replicationFactor:  DefaultReplicationFactor,
 		stopChan:           make(chan struct{}),
 	}
 
 	registerAsyncTransportHandler(ac, trans)
 	ac.retrievalScheduler = NewRetrievalScheduler(ac)
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":           "NewAsyncClient",
 		"public_key_preview": fmt.Sprintf("%x", keyPair.Public[:8]),
 	}).Info("Async client created successfully")
@@ -130,7 +132,7 @@ func NewAsyncClient(keyPair *crypto.KeyPair, trans transport.Transport) *AsyncCl
 func initErasureStorage() *ErasureStorage {
 	es, err := NewErasureStorage(DefaultErasureCodingConfig())
 	if err != nil {
-		logrus.WithField("error", err.Error()).
+		pkgLog.WithField("error", err.Error()).
 			Warn("Failed to initialize erasure storage, falling back to simple redundancy")
 	}
 	return es
@@ -143,7 +145,7 @@ func registerAsyncTransportHandler(ac *AsyncClient, trans transport.Transport) {
 		trans.RegisterHandler(transport.PacketAsyncRetrieveResponse, ac.handleRetrieveResponse)
 		return
 	}
-	logrus.WithField("function", "NewAsyncClient").
+	pkgLog.WithField("function", "NewAsyncClient").
 		Warn("Transport is nil - async messaging features will be unavailable")
 }
 
@@ -154,7 +156,7 @@ func (ac *AsyncClient) Close() {
 	ac.closeOnce.Do(func() {
 		close(ac.stopChan)
 	})
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "AsyncClient.Close",
 	}).Info("Async client closed")
 }
@@ -207,7 +209,7 @@ func (ac *AsyncClient) GetParallelizeQueries() bool {
 // SetErasureCodingEnabled enables or disables erasure-coded storage.
 // When enabled (default), messages are split into 5 shards (3 data + 2 parity)
 // and distributed across 5 storage nodes for 99.9% message survival.
-// When disabled, falls back to simple 3-way replication.
+// When disabled, falls back to full replication on SetReplicationFactor nodes.
 func (ac *AsyncClient) SetErasureCodingEnabled(enabled bool) {
 	ac.mutex.Lock()
 	defer ac.mutex.Unlock()
@@ -245,8 +247,18 @@ func (ac *AsyncClient) GetErasureStorageStats() *ErasureStats {
 func (ac *AsyncClient) SendObfuscatedMessage(recipientPK [32]byte,
 	forwardSecureMsg *ForwardSecureMessage,
 ) error {
+	_, err := ac.sendObfuscatedMessage(recipientPK, forwardSecureMsg)
+	return err
+}
+
+// sendObfuscatedMessage implements SendObfuscatedMessage and returns the
+// stored envelope, whose recipient proof identifies the message in delivery
+// receipts.
+func (ac *AsyncClient) sendObfuscatedMessage(recipientPK [32]byte,
+	forwardSecureMsg *ForwardSecureMessage,
+) (*ObfuscatedAsyncMessage, error) {
 	if forwardSecureMsg == nil {
-		return errors.New("nil forward secure message")
+		return nil, errors.New("nil forward secure message")
 	}
 
 	// Serialize the message and create the obfuscated envelope under a read lock.
@@ -256,13 +268,13 @@ func (ac *AsyncClient) SendObfuscatedMessage(recipientPK [32]byte,
 	serializedMsg, err := ac.serializeForwardSecureMessage(forwardSecureMsg)
 	if err != nil {
 		ac.mutex.RUnlock()
-		return fmt.Errorf("failed to serialize message: %w", err)
+		return nil, fmt.Errorf("failed to serialize message: %w", err)
 	}
 
 	sharedSecret, err := ac.deriveSharedSecret(recipientPK)
 	if err != nil {
 		ac.mutex.RUnlock()
-		return fmt.Errorf("failed to derive shared secret: %w", err)
+		return nil, fmt.Errorf("failed to derive shared secret: %w", err)
 	}
 
 	obfMsg, err := ac.obfuscation.CreateObfuscatedMessage(
@@ -270,12 +282,15 @@ func (ac *AsyncClient) SendObfuscatedMessage(recipientPK [32]byte,
 	)
 	ac.mutex.RUnlock()
 	if err != nil {
-		return fmt.Errorf("failed to create obfuscated message: %w", err)
+		return nil, fmt.Errorf("failed to create obfuscated message: %w", err)
 	}
 
 	// Store on multiple storage nodes for redundancy.
 	// Lock is not held here; collectCandidateNodes acquires its own RLock.
-	return ac.storeObfuscatedMessage(obfMsg)
+	if err := ac.storeObfuscatedMessage(obfMsg); err != nil {
+		return nil, err
+	}
+	return obfMsg, nil
 }
 
 // SendAsyncMessage sends a message asynchronously using obfuscation by default.
@@ -368,7 +383,7 @@ func (ac *AsyncClient) conditionalForwardSecureSend(
 	}
 
 	if fsm == nil {
-		logrus.Warn("AsyncClient.SendAsyncMessage: no ForwardSecurityManager configured — message will be sent without inner-layer encryption. Use AsyncManager or call SetForwardSecurityManager.")
+		pkgLog.Warn("AsyncClient.SendAsyncMessage: no ForwardSecurityManager configured — message will be sent without inner-layer encryption. Use AsyncManager or call SetForwardSecurityManager.")
 	} else {
 		return false, fmt.Errorf("forward secrecy configured but pre-keys unavailable for recipient; queue message for retry or disable forward secrecy")
 	}
@@ -427,6 +442,7 @@ func (ac *AsyncClient) RetrieveObfuscatedMessages() ([]DecryptedMessage, error)
 	collectionTimeout := ac.collectionTimeout
 	parallelizeQueries := ac.parallelizeQueries
 	retrieveTimeout := ac.retrieveTimeout
+	replicationFactor := ac.replicationFactorLocked()
 	storageNodesSnapshot := make(map[[32]byte]net.Addr, len(ac.storageNodes))
 	for k, v := range ac.storageNodes {
 		storageNodesSnapshot[k] = v
@@ -435,9 +451,12 @@ func (ac *AsyncClient) RetrieveObfuscatedMessages() ([]DecryptedMessage, error)
 
 	var allMessages []DecryptedMessage
 
+	// Replicated messages come back from several nodes; keep one copy of each
+	dedup := newMessageDeduplicator()
+
 	// For each epoch, generate our pseudonym and retrieve messages
 	for _, epoch := range recentEpochs {
-		epochMessages := ac.retrieveMessagesForEpochLockFree(epoch, keyPairPublic, storageNodesSnapshot, collectionTimeout, parallelizeQueries, retrieveTimeout)
+		epochMessages := ac.retrieveMessagesForEpochLockFree(epoch, keyPairPublic, storageNodesSnapshot, replicationFactor, collectionTimeout, parallelizeQueries, retrieveTimeout, dedup)
 		allMessages = append(allMessages, epochMessages...)
 	}
 
@@ -467,20 +486,20 @@ func (ac *AsyncClient) retrieveMessagesForEpoch(epoch uint64) []DecryptedMessage
 
 // retrieveMessagesForEpochLockFree retrieves messages for a specific epoch without
 // holding the mutex, using pre-snapshotted state to avoid recursive lock acquisition.
-func (ac *AsyncClient) retrieveMessagesForEpochLockFree(epoch uint64, publicKey [32]byte, storageNodesMap map[[32]byte]net.Addr, collectionTimeout time.Duration, parallelizeQueries bool, retrieveTimeout time.Duration) []DecryptedMessage {
+func (ac *AsyncClient) retrieveMessagesForEpochLockFree(epoch uint64, publicKey [32]byte, storageNodesMap map[[32]byte]net.Addr, replicationFactor int, collectionTimeout time.Duration, parallelizeQueries bool, retrieveTimeout time.Duration, dedup *messageDeduplicator) []DecryptedMessage {
 	myPseudonym, err := ac.obfuscation.GenerateRecipientPseudonym(publicKey, epoch)
 	if err != nil {
 		log.Printf("AsyncClient: Failed to generate pseudonym for epoch %d: %v", epoch, err)
 		return nil
 	}
 
-	nodes := ac.findStorageNodesFromSnapshot(myPseudonym, 5, storageNodesMap)
+	nodes := ac.retrievalNodes(myPseudonym, storageNodesMap, replicationFactor)
 	if len(nodes) == 0 {
 		log.Printf("AsyncClient: No storage nodes available for epoch %d", epoch)
 		return nil
 	}
 
-	return ac.collectMessagesFromNodesLockFree(nodes, myPseudonym, epoch, collectionTimeout, parallelizeQueries, retrieveTimeout)
+	return ac.collectMessagesFromNodesLockFree(nodes, myPseudonym, epoch, collectionTimeout, parallelizeQueries, retrieveTimeout, dedup)
 }
 
 // generateRecipientPseudonymForEpoch creates a recipient pseudonym for the given epoch
@@ -507,14 +526,15 @@ func (ac *AsyncClient) collectMessagesFromNodes(storageNodes []net.Addr, pseudon
 	ctx, cancel := context.WithTimeout(context.Background(), collectionTimeout)
 	defer cancel()
 
+	dedup := newMessageDeduplicator()
 	if parallelizeQueries {
-		return ac.collectMessagesParallel(ctx, storageNodes, pseudonym, epoch, retrieveTimeout)
+		return ac.collectMessagesParallel(ctx, storageNodes, pseudonym, epoch, retrieveTimeout, dedup)
 	}
-	return ac.collectMessagesSequential(ctx, storageNodes, pseudonym, epoch, retrieveTimeout)
+	return ac.collectMessagesSequential(ctx, storageNodes, pseudonym, epoch, retrieveTimeout, dedup)
 }
 
 // collectMessagesSequential queries storage nodes one at a time with adaptive timeout
-func (ac *AsyncClient) collectMessagesSequential(ctx context.Context, storageNodes []net.Addr, pseudonym [32]byte, epoch uint64, baseTimeout time.Duration) []DecryptedMessage {
+func (ac *AsyncClient) collectMessagesSequential(ctx context.Context, storageNodes []net.Addr, pseudonym [32]byte, epoch uint64, baseTimeout time.Duration, dedup *messageDeduplicator) []DecryptedMessage {
 	var messages []DecryptedMessage
 	consecutiveFailures := 0
 
@@ -524,7 +544,7 @@ func (ac *AsyncClient) collectMessagesSequential(ctx context.Context, storageNod
 		}
 
 		timeout := calculateAdaptiveTimeout(baseTimeout, consecutiveFailures)
-		nodeMessages, err := ac.retrieveMessagesFromSingleNodeWithTimeout(nodeAddr, pseudonym, epoch, timeout)
+		nodeMessages, err := ac.retrieveMessagesFromSingleNodeWithTimeout(nodeAddr, pseudonym, epoch, timeout, dedup)
 		if err != nil {
 			consecutiveFailures++
 			if shouldAbortSequentialCollection(consecutiveFailures) {
@@ -544,7 +564,7 @@ func (ac *AsyncClient) collectMessagesSequential(ctx context.Context, storageNod
 func shouldStopSequentialCollection(ctx context.Context) bool {
 	select {
 	case <-ctx.Done():
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "collectMessagesSequential",
 			"reason":   "overall timeout exceeded",
 		}).Warn("Stopping node queries due to timeout")
@@ -565,7 +585,7 @@ func calculateAdaptiveTimeout(baseTimeout time.Duration, consecutiveFailures int
 // shouldAbortSequentialCollection determines if retrieval should abort due to failures.
 func shouldAbortSequentialCollection(consecutiveFailures int) bool {
 	if consecutiveFailures >= 3 {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":             "collectMessagesSequential",
 			"consecutive_failures": consecutiveFailures,
 		}).Warn("Multiple consecutive node failures - aborting further retrieval attempts")
@@ -577,7 +597,7 @@ func shouldAbortSequentialCollection(consecutiveFailures int) bool {
 // collectMessagesParallel queries all storage nodes in parallel for better performance
 // queryStorageNode queries a single storage node for messages and sends the result to the channel.
 // It checks for context cancellation before starting the retrieval operation.
-func (ac *AsyncClient) queryStorageNode(ctx context.Context, addr net.Addr, pseudonym [32]byte, epoch uint64, timeout time.Duration, resultChan chan<- nodeResult) {
+func (ac *AsyncClient) queryStorageNode(ctx context.Context, addr net.Addr, pseudonym [32]byte, epoch uint64, timeout time.Duration, dedup *messageDeduplicator, resultChan chan<- nodeResult) {
 	select {
 	case <-ctx.Done():
 		resultChan <- nodeResult{err: ctx.Err(), nodeAddr: addr}
@@ -585,7 +605,7 @@ func (ac *AsyncClient) queryStorageNode(ctx context.Context, addr net.Addr, pseu
 	default:
 	}
 
-	nodeMessages, err := ac.retrieveMessagesFromSingleNodeWithTimeout(addr, pseudonym, epoch, timeout)
+	nodeMessages, err := ac.retrieveMessagesFromSingleNodeWithTimeout(addr, pseudonym, epoch, timeout, dedup)
 	resultChan <- nodeResult{
 		messages: nodeMessages,
 		err:      err,
@@ -595,7 +615,7 @@ func (ac *AsyncClient) queryStorageNode(ctx context.Context, addr net.Addr, pseu
 
 // launchParallelQueries initiates concurrent queries to all storage nodes.
 // It returns a channel that will be closed when all queries complete.
-func (ac *AsyncClient) launchParallelQueries(ctx context.Context, storageNodes []net.Addr, pseudonym [32]byte, epoch uint64, timeout time.Duration) <-chan nodeResult {
+func (ac *AsyncClient) launchParallelQueries(ctx context.Context, storageNodes []net.Addr, pseudonym [32]byte, epoch uint64, timeout time.Duration, dedup *messageDeduplicator) <-chan nodeResult {
 	resultChan := make(chan nodeResult, len(storageNodes))
 	var wg sync.WaitGroup
 
@@ -603,7 +623,7 @@ func (ac *AsyncClient) launchParallelQueries(ctx context.Context, storageNodes [
 		wg.Add(1)
 		go func(addr net.Addr) {
 			defer wg.Done()
-			ac.queryStorageNode(ctx, addr, pseudonym, epoch, timeout, resultChan)
+			ac.queryStorageNode(ctx, addr, pseudonym, epoch, timeout, dedup, resultChan)
 		}(nodeAddr)
 	}
 
@@ -627,8 +647,8 @@ func processNodeResult(result nodeResult, allMessages *[]DecryptedMessage, succe
 	return true
 }
 
-func (ac *AsyncClient) collectMessagesParallel(ctx context.Context, storageNodes []net.Addr, pseudonym [32]byte, epoch uint64, timeout time.Duration) []DecryptedMessage {
-	resultChan := ac.launchParallelQueries(ctx, storageNodes, pseudonym, epoch, timeout)
+func (ac *AsyncClient) collectMessagesParallel(ctx context.Context, storageNodes []net.Addr, pseudonym [32]byte, epoch uint64, timeout time.Duration, dedup *messageDeduplicator) []DecryptedMessage {
+	resultChan := ac.launchParallelQueries(ctx, storageNodes, pseudonym, epoch, timeout, dedup)
 	return drainQueryResults(ctx, resultChan, len(storageNodes))
 }
 
@@ -642,7 +662,7 @@ func drainQueryResults(ctx context.Context, resultChan <-chan nodeResult, totalN
 	for {
 		select {
 		case <-ctx.Done():
-			logrus.WithFields(logrus.Fields{
+			pkgLog.WithFields(logrus.Fields{
 				"function":      "collectMessagesParallel",
 				"success_count": successCount,
 				"failure_count": failureCount,
@@ -651,7 +671,7 @@ func drainQueryResults(ctx context.Context, resultChan <-chan nodeResult, totalN
 
 		case result, ok := <-resultChan:
 			if !ok {
-				logrus.WithFields(logrus.Fields{
+				pkgLog.WithFields(logrus.Fields{
 					"function":      "collectMessagesParallel",
 					"success_count": successCount,
 					"failure_count": failureCount,
@@ -665,15 +685,16 @@ func drainQueryResults(ctx context.Context, resultChan <-chan nodeResult, totalN
 	}
 }
 
-// retrieveMessagesFromSingleNodeWithTimeout retrieves and decrypts messages from one storage node with custom timeout
-func (ac *AsyncClient) retrieveMessagesFromSingleNodeWithTimeout(nodeAddr net.Addr, pseudonym [32]byte, epoch uint64, timeout time.Duration) ([]DecryptedMessage, error) {
+// retrieveMessagesFromSingleNodeWithTimeout retrieves and decrypts messages from one storage node with custom timeout.
+// Messages already retrieved from another node, as recorded by dedup, are skipped before decryption.
+func (ac *AsyncClient) retrieveMessagesFromSingleNodeWithTimeout(nodeAddr net.Addr, pseudonym [32]byte, epoch uint64, timeout time.Duration, dedup *messageDeduplicator) ([]DecryptedMessage, error) {
 	obfMessages, err := ac.retrieveObfuscatedMessagesFromNode(nodeAddr, pseudonym, []uint64{epoch}, timeout)
 	if err != nil {
 		log.Printf("AsyncClient: Failed to retrieve messages from node %v for epoch %d: %v", nodeAddr, epoch, err)
 		return nil, err
 	}
 
-	return ac.decryptRetrievedMessages(obfMessages), nil
+	return ac.decryptRetrievedMessages(dedup.filter(obfMessages)), nil
 }
 
 // decryptRetrievedMessages decrypts and validates a collection of obfuscated messages
@@ -703,7 +724,7 @@ func (ac *AsyncClient) decryptRetrievedMessages(obfMessages []*ObfuscatedAsyncMe
 			continue
 		}
 
-		decryptedMessages = append(decryptedMessages, buildDecryptedMessage(forwardSecureMsg, plaintext))
+		decryptedMessages = append(decryptedMessages, buildDecryptedMessage(obfMsg, forwardSecureMsg, plaintext))
 	}
 
 	return decryptedMessages
@@ -716,7 +737,7 @@ func (ac *AsyncClient) decryptInnerRetrievedPayload(
 	if fsm != nil && forwardSecureMsg.PreKeyID != 0 {
 		plaintext, err := fsm.DecryptForwardSecureMessage(forwardSecureMsg)
 		if err != nil {
-			logrus.WithFields(logrus.Fields{
+			pkgLog.WithFields(logrus.Fields{
 				"function":   "decryptInnerRetrievedPayload",
 				"pre_key_id": forwardSecureMsg.PreKeyID,
 				"sender":     fmt.Sprintf("%x", forwardSecureMsg.SenderPK[:8]),
@@ -738,7 +759,7 @@ func (ac *AsyncClient) decryptInnerRetrievedPayload(
 	return plaintext, nil
 }
 
-func buildDecryptedMessage(forwardSecureMsg *ForwardSecureMessage, plaintext []byte) DecryptedMessage {
+func buildDecryptedMessage(obfMsg *ObfuscatedAsyncMessage, forwardSecureMsg *ForwardSecureMessage, plaintext []byte) DecryptedMessage {
 	var messageID [16]byte
 	copy(messageID[:], forwardSecureMsg.MessageID[:16])
 
@@ -748,6 +769,7 @@ func buildDecryptedMessage(forwardSecureMsg *ForwardSecureMessage, plaintext []b
 		Message:     plaintext,
 		MessageType: forwardSecureMsg.MessageType,
 		Timestamp:   forwardSecureMsg.Timestamp,
+		MessageHMAC: obfMsg.RecipientProof,
 	}
 }
 
@@ -875,7 +897,8 @@ func (ac *AsyncClient) deriveSharedSecret(recipientPK [32]byte) ([32]byte, error
 // storeObfuscatedMessage stores an obfuscated message on multiple storage nodes.
 // When erasure coding is enabled, the message is split into k=5 shards (3 data + 2 parity)
 // and distributed across 5 storage nodes. This allows message reconstruction from any 3 nodes,
-// providing 99.9% message survival even with 2 node failures.
+// providing 99.9% message survival even with 2 node failures. Otherwise a full copy is
+// stored on each of the replication-factor nodes closest to the recipient pseudonym.
 func (ac *AsyncClient) storeObfuscatedMessage(obfMsg *ObfuscatedAsyncMessage) error {
 	// Snapshot the erasure-coding fields under a brief read lock so that
 	// collectCandidateNodes can acquire its own RLock without nesting.
@@ -887,7 +910,7 @@ func (ac *AsyncClient) storeObfuscatedMessage(obfMsg *ObfuscatedAsyncMessage) er
 	if erasureEnabled && erasureStorage != nil {
 		return ac.storeWithErasureCoding(obfMsg)
 	}
-	return ac.storeWithSimpleRedundancy(obfMsg)
+	return ac.storeWithReplication(obfMsg)
 }
 
 // storeWithErasureCoding stores a message using Reed-Solomon erasure coding across k=5 nodes.
@@ -915,7 +938,7 @@ func (ac *AsyncClient) storeWithErasureCoding(obfMsg *ObfuscatedAsyncMessage) er
 		return fmt.Errorf("failed to store sufficient shards: stored %d, need at least 3", storedCount)
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":      "storeWithErasureCoding",
 		"message_id":    fmt.Sprintf("%x", obfMsg.MessageID[:8]),
 		"shards_total":  len(shards),
@@ -947,7 +970,7 @@ func (ac *AsyncClient) distributeShardsToNodes(shards []*EncodedShard, storageNo
 func (ac *AsyncClient) storeShardOnNodeWithLogging(shardIndex int, shard *EncodedShard, node net.Addr, obfMsg *ObfuscatedAsyncMessage, originalSize int) bool {
 	envelope, err := NewErasureShardEnvelope(shard, obfMsg.RecipientPseudonym, originalSize)
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":   "storeWithErasureCoding",
 			"shard":      shardIndex,
 			"message_id": fmt.Sprintf("%x", obfMsg.MessageID[:8]),
@@ -959,29 +982,6 @@ func (ac *AsyncClient) storeShardOnNodeWithLogging(shardIndex int, shard *Encode
 	return ac.storeShardOnNode(node, envelope) == nil
 }
 
-// storeWithSimpleRedundancy stores a message using simple replication (legacy behavior).
-func (ac *AsyncClient) storeWithSimpleRedundancy(obfMsg *ObfuscatedAsyncMessage) error {
-	// Find suitable storage nodes from DHT
-	storageNodes := ac.findStorageNodes(obfMsg.RecipientPseudonym, 3) // Use 3 nodes for redundancy
-	if len(storageNodes) == 0 {
-		return errors.New("no storage nodes available")
-	}
-
-	// Store obfuscated message on multiple nodes for redundancy
-	storedCount := 0
-	for _, nodeAddr := range storageNodes {
-		if err := ac.storeObfuscatedMessageOnNode(nodeAddr, obfMsg); err == nil {
-			storedCount++
-		}
-	}
-
-	if storedCount == 0 {
-		return errors.New("failed to store obfuscated message on any storage node")
-	}
-
-	return nil
-}
-
 // storeShardOnNode sends an erasure-coded shard to a specific storage node.
 func (ac *AsyncClient) storeShardOnNode(nodeAddr net.Addr, envelope *ErasureShardEnvelope) error {
 	if envelope == nil || envelope.Shard == nil {
@@ -1054,6 +1054,7 @@ type DecryptedMessage struct {
 	Message     []byte
 	MessageType MessageType
 	Timestamp   time.Time
+	MessageHMAC [32]byte // Recipient proof of the storage envelope, acknowledged by delivery receipts
 }
 
 // AsyncRetrieveRequest represents a request to retrieve messages from a storage node
@@ -1132,14 +1133,14 @@ func (ac *AsyncClient) findStorageNodesFromSnapshot(targetPK [32]byte, maxNodes
 
 // collectMessagesFromNodesLockFree retrieves and decrypts messages without
 // re-acquiring the mutex; config values are passed in directly.
-func (ac *AsyncClient) collectMessagesFromNodesLockFree(storageNodes []net.Addr, pseudonym [32]byte, epoch uint64, collectionTimeout time.Duration, parallelizeQueries bool, retrieveTimeout time.Duration) []DecryptedMessage {
+func (ac *AsyncClient) collectMessagesFromNodesLockFree(storageNodes []net.Addr, pseudonym [32]byte, epoch uint64, collectionTimeout time.Duration, parallelizeQueries bool, retrieveTimeout time.Duration, dedup *messageDeduplicator) []DecryptedMessage {
 	ctx, cancel := context.WithTimeout(context.Background(), collectionTimeout)
 	defer cancel()
 
 	if parallelizeQueries {
-		return ac.collectMessagesParallel(ctx, storageNodes, pseudonym, epoch, retrieveTimeout)
+		return ac.collectMessagesParallel(ctx, storageNodes, pseudonym, epoch, retrieveTimeout, dedup)
 	}
-	return ac.collectMessagesSequential(ctx, storageNodes, pseudonym, epoch, retrieveTimeout)
+	return ac.collectMessagesSequential(ctx, storageNodes, pseudonym, epoch, retrieveTimeout, dedup)
 }
 
 // AddStorageNode adds a known storage node to the client
@@ -1443,7 +1444,7 @@ func (ac *AsyncClient) deserializeRetrieveResponse(data []byte) (*AsyncRetrieveR
 // decryptObfuscatedMessage attempts to decrypt an obfuscated message
 func (ac *AsyncClient) decryptObfuscatedMessage(obfMsg *ObfuscatedAsyncMessage) (*ForwardSecureMessage, error) {
 	if len(ac.knownSenders) == 0 {
-		logrus.Warn("AsyncClient: decryptObfuscatedMessage called with empty knownSenders — " +
+		pkgLog.Warn("AsyncClient: decryptObfuscatedMessage called with empty knownSenders — " +
 			"all messages will fail decryption. Call AddKnownSender for each friend public key, " +
 			"or use AsyncManager.AddFriend to populate the list automatically.")
 		return nil, errors.New("no known senders configured - cannot decrypt message without sender identification")
@@ -1527,7 +1528,7 @@ func (ac *AsyncClient) tryDecryptFromKnownSenders(obfMsg *ObfuscatedAsyncMessage
 	// For now, implement a simplified version that demonstrates the flow
 	// but requires the sender to be added to a known senders list
 	if len(ac.knownSenders) == 0 {
-		logrus.Warn("AsyncClient: RetrieveObfuscatedMessages called with empty knownSenders — " +
+		pkgLog.Warn("AsyncClient: RetrieveObfuscatedMessages called with empty knownSenders — " +
 			"all messages will fail decryption. Call AddKnownSender for each friend public key, " +
 			"or use AsyncManager.AddFriend to populate the list automatically.")
 		return DecryptedMessage{}, errors.New("no known senders configured - cannot decrypt message without sender identification")
@@ -1556,7 +1557,7 @@ func (ac *AsyncClient) tryDecryptWithSender(obfMsg *ObfuscatedAsyncMessage, send
 		return DecryptedMessage{}, err
 	}
 
-	return buildDecryptedMessage(forwardSecureMsg, plaintext), nil
+	return buildDecryptedMessage(obfMsg, forwardSecureMsg, plaintext), nil
 }
 
 func (ac *AsyncClient) decryptForwardSecureMessageFromSender(
@@ -0,0 +1,234 @@
+package async
+
+import (
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/opd-ai/toxcore/crypto"
+	"github.com/opd-ai/toxcore/transport"
+	"github.com/sirupsen/logrus"
+)
+
+// Delivery receipt packet format:
+// [MAGIC(4)][VERSION(1)][RECIPIENT_PK(32)][MESSAGE_HMAC(32)][SIGNATURE(64)]
+// The signature covers everything before it and is made with the
+// recipient's Ed25519 key, the same key that signs its pre-key exchanges.
+const (
+	receiptMagic       = "RCPT"
+	receiptVersion     = byte(1)
+	receiptPayloadSize = 4 + 1 + 32 + 32
+	receiptPacketSize  = receiptPayloadSize + crypto.SignatureSize
+)
+
+// awaitingReceipt records a sent message whose delivery receipt has not yet
+// arrived.
+type awaitingReceipt struct {
+	recipientPK [32]byte
+	expiresAt   time.Time
+}
+
+// deliveryReceiptTracker holds the delivery receipt handler and the messages
+// awaiting a receipt, keyed by recipient proof HMAC. It has its own mutex
+// because queued messages are sent without holding the manager's lock.
+type deliveryReceiptTracker struct {
+	mu       sync.Mutex
+	handler  func(recipientPK, messageHMAC [32]byte)
+	awaiting map[[32]byte]awaitingReceipt
+}
+
+// newDeliveryReceiptTracker creates a tracker with no handler.
+func newDeliveryReceiptTracker() *deliveryReceiptTracker {
+	return &deliveryReceiptTracker{awaiting: make(map[[32]byte]awaitingReceipt)}
+}
+
+// enabled reports whether a handler is set.
+func (t *deliveryReceiptTracker) enabled() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.handler != nil
+}
+
+// setHandler replaces the handler. Clearing it forgets all awaited receipts.
+func (t *deliveryReceiptTracker) setHandler(handler func(recipientPK, messageHMAC [32]byte)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.handler = handler
+	if handler == nil {
+		t.awaiting = make(map[[32]byte]awaitingReceipt)
+	}
+}
+
+// expect records that obfMsg was sent to recipientPK. It does nothing while
+// no handler is set.
+func (t *deliveryReceiptTracker) expect(recipientPK [32]byte, obfMsg *ObfuscatedAsyncMessage) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.handler == nil {
+		return
+	}
+	t.awaiting[obfMsg.RecipientProof] = awaitingReceipt{
+		recipientPK: recipientPK,
+		expiresAt:   obfMsg.ExpiresAt,
+	}
+}
+
+// resolve removes the awaited receipt for messageHMAC and returns the
+// handler to notify. It returns nil if no handler is set or the message was
+// not sent to recipientPK, so replayed receipts are ignored.
+func (t *deliveryReceiptTracker) resolve(recipientPK, messageHMAC [32]byte) func(recipientPK, messageHMAC [32]byte) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	entry, ok := t.awaiting[messageHMAC]
+	if t.handler == nil || !ok || entry.recipientPK != recipientPK {
+		return nil
+	}
+	delete(t.awaiting, messageHMAC)
+	return t.handler
+}
+
+// pruneExpired forgets messages that expired from storage without a receipt
+// and returns how many were removed.
+func (t *deliveryReceiptTracker) pruneExpired(now time.Time) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	removed := 0
+	for messageHMAC, entry := range t.awaiting {
+		if now.After(entry.expiresAt) {
+			delete(t.awaiting, messageHMAC)
+			removed++
+		}
+	}
+	return removed
+}
+
+// SetDeliveryReceiptHandler enables delivery receipts. The handler is called
+// with the recipient's public key and the message HMAC once a recipient
+// confirms it retrieved and decrypted a message we sent; the HMAC is the
+// recipient proof of the stored envelope. Receipts are sent directly to the
+// sender's friend address, never through storage nodes, and contain no
+// message content.
+//
+// Receipts are reciprocal: while a handler is set, this manager also sends
+// receipts for the messages it retrieves. A nil handler, the default,
+// disables receipts in both directions.
+func (am *AsyncManager) SetDeliveryReceiptHandler(handler func(recipientPK [32]byte, messageHMAC [32]byte)) {
+	am.receipts.setHandler(handler)
+}
+
+// sendDeliveryReceipts acknowledges each retrieved message to its sender, if
+// receipts are enabled and the sender's address is known.
+func (am *AsyncManager) sendDeliveryReceipts(messages []DecryptedMessage) {
+	if len(messages) == 0 || !am.receipts.enabled() {
+		return
+	}
+	for _, msg := range messages {
+		if err := am.sendDeliveryReceipt(msg.SenderPK, msg.MessageHMAC); err != nil {
+			pkgLog.WithFields(logrus.Fields{
+				"function": "sendDeliveryReceipts",
+				"sender":   fmt.Sprintf("%x", msg.SenderPK[:8]),
+				"error":    err.Error(),
+			}).Debug("Failed to send delivery receipt")
+		}
+	}
+}
+
+// sendDeliveryReceipt sends a signed receipt for messageHMAC to senderPK.
+func (am *AsyncManager) sendDeliveryReceipt(senderPK, messageHMAC [32]byte) error {
+	am.mutex.RLock()
+	senderAddr, ok := am.friendAddresses[senderPK]
+	am.mutex.RUnlock()
+	if !ok {
+		return fmt.Errorf("no address known for friend %x", senderPK[:8])
+	}
+	if am.client.transport == nil {
+		return fmt.Errorf("transport not available")
+	}
+
+	packet, err := am.createDeliveryReceiptPacket(messageHMAC)
+	if err != nil {
+		return err
+	}
+	if err := am.client.transport.Send(&transport.Packet{
+		PacketType: transport.PacketAsyncDeliveryReceipt,
+		Data:       packet,
+	}, senderAddr); err != nil {
+		return fmt.Errorf("failed to send delivery receipt: %w", err)
+	}
+	return nil
+}
+
+// createDeliveryReceiptPacket builds a receipt for messageHMAC signed with
+// our Ed25519 key.
+func (am *AsyncManager) createDeliveryReceiptPacket(messageHMAC [32]byte) ([]byte, error) {
+	packet := make([]byte, receiptPacketSize)
+	copy(packet[0:4], receiptMagic)
+	packet[4] = receiptVersion
+	copy(packet[5:37], am.keyPair.Public[:])
+	copy(packet[37:69], messageHMAC[:])
+
+	signature, err := crypto.Sign(packet[:receiptPayloadSize], am.keyPair.Private)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign delivery receipt: %w", err)
+	}
+	copy(packet[receiptPayloadSize:], signature[:])
+	return packet, nil
+}
+
+// handleDeliveryReceiptPacket verifies an incoming receipt against the
+// recipient's trusted Ed25519 key and notifies the handler. Receipts for
+// messages we did not send, or that were already acknowledged, are dropped.
+func (am *AsyncManager) handleDeliveryReceiptPacket(packet *transport.Packet, addr net.Addr) {
+	if !am.receipts.enabled() {
+		return
+	}
+
+	recipientPK, messageHMAC, err := am.verifyDeliveryReceipt(packet.Data)
+	if err != nil {
+		pkgLog.WithFields(logrus.Fields{
+			"function": "handleDeliveryReceiptPacket",
+			"addr":     addr.String(),
+			"error":    err.Error(),
+		}).Debug("Rejected delivery receipt")
+		return
+	}
+
+	if handler := am.receipts.resolve(recipientPK, messageHMAC); handler != nil {
+		handler(recipientPK, messageHMAC)
+	}
+}
+
+// verifyDeliveryReceipt parses a receipt and checks its signature with the
+// signing key learned from the recipient's pre-key exchange.
+func (am *AsyncManager) verifyDeliveryReceipt(data []byte) (recipientPK, messageHMAC [32]byte, err error) {
+	if len(data) != receiptPacketSize {
+		return recipientPK, messageHMAC, fmt.Errorf("invalid receipt size: %d bytes", len(data))
+	}
+	if string(data[0:4]) != receiptMagic {
+		return recipientPK, messageHMAC, fmt.Errorf("invalid magic bytes")
+	}
+	if data[4] != receiptVersion {
+		return recipientPK, messageHMAC, fmt.Errorf("unsupported version: %d", data[4])
+	}
+	copy(recipientPK[:], data[5:37])
+	copy(messageHMAC[:], data[37:69])
+
+	am.mutex.RLock()
+	signKey, known := am.friendSignKeys[recipientPK]
+	am.mutex.RUnlock()
+	if !known {
+		return recipientPK, messageHMAC, fmt.Errorf("no signing key known for %x", recipientPK[:8])
+	}
+
+	var signature crypto.Signature
+	copy(signature[:], data[receiptPayloadSize:])
+	valid, err := crypto.Verify(data[:receiptPayloadSize], signature, signKey)
+	if err != nil {
+		return recipientPK, messageHMAC, fmt.Errorf("signature verification error: %w", err)
+	}
+	if !valid {
+		return recipientPK, messageHMAC, fmt.Errorf("invalid signature - authentication failed")
+	}
+	return recipientPK, messageHMAC, nil
+}
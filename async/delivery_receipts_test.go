@@ -0,0 +1,192 @@
+package async
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/opd-ai/toxcore/crypto"
+	"github.com/opd-ai/toxcore/transport"
+)
+
+type deliveredReceipt struct {
+	recipientPK [32]byte
+	messageHMAC [32]byte
+}
+
+// newReceiptTestManagers creates a sender and recipient whose transports are
+// connected, with each side knowing the other's address and the sender
+// trusting the recipient's signing key as if a pre-key exchange had run.
+func newReceiptTestManagers(t *testing.T) (sender, recipient *AsyncManager, recipientTransport *MockTransport) {
+	t.Helper()
+	senderKeys, err := crypto.GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("Failed to generate sender keys: %v", err)
+	}
+	recipientKeys, err := crypto.GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("Failed to generate recipient keys: %v", err)
+	}
+
+	senderTransport := NewMockTransport("127.0.0.1:8000")
+	recipientTransport = NewMockTransport("127.0.0.1:9000")
+	sender, err = NewAsyncManager(senderKeys, senderTransport, t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create sender manager: %v", err)
+	}
+	recipient, err = NewAsyncManager(recipientKeys, recipientTransport, t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create recipient manager: %v", err)
+	}
+
+	recipientAddr := &MockAddr{network: "mock", address: "recipient:33445"}
+	recipientTransport.SetSendFunc(func(packet *transport.Packet, addr net.Addr) error {
+		return senderTransport.SimulateReceive(packet, recipientAddr)
+	})
+	recipient.SetFriendAddress(senderKeys.Public, senderTransport.LocalAddr())
+	sender.SetFriendAddress(recipientKeys.Public, recipientAddr)
+	sender.friendSignKeys[recipientKeys.Public] = crypto.GetSignaturePublicKey(recipientKeys.Private)
+	return sender, recipient, recipientTransport
+}
+
+// recordReceipts enables receipts on am and returns the receipts it reports.
+func recordReceipts(am *AsyncManager) *[]deliveredReceipt {
+	var got []deliveredReceipt
+	am.SetDeliveryReceiptHandler(func(recipientPK, messageHMAC [32]byte) {
+		got = append(got, deliveredReceipt{recipientPK, messageHMAC})
+	})
+	return &got
+}
+
+func expectReceiptFor(am *AsyncManager, recipientPK, messageHMAC [32]byte) {
+	am.receipts.expect(recipientPK, &ObfuscatedAsyncMessage{
+		RecipientProof: messageHMAC,
+		ExpiresAt:      time.Now().Add(time.Hour),
+	})
+}
+
+func TestDeliveryReceiptRoundTrip(t *testing.T) {
+	sender, recipient, _ := newReceiptTestManagers(t)
+	got := recordReceipts(sender)
+	recordReceipts(recipient)
+
+	messageHMAC := [32]byte{1, 2, 3}
+	expectReceiptFor(sender, recipient.keyPair.Public, messageHMAC)
+
+	retrieved := []DecryptedMessage{{SenderPK: sender.keyPair.Public, MessageHMAC: messageHMAC}}
+	recipient.sendDeliveryReceipts(retrieved)
+
+	if len(*got) != 1 {
+		t.Fatalf("Expected 1 delivery receipt, got %d", len(*got))
+	}
+	if (*got)[0].recipientPK != recipient.keyPair.Public || (*got)[0].messageHMAC != messageHMAC {
+		t.Errorf("Unexpected receipt %+v", (*got)[0])
+	}
+
+	// Retrieving the same message again does not notify twice
+	recipient.sendDeliveryReceipts(retrieved)
+	if len(*got) != 1 {
+		t.Errorf("Expected duplicate receipt to be ignored, got %d receipts", len(*got))
+	}
+}
+
+func TestDeliveryReceiptRejectsUnverifiedReceipts(t *testing.T) {
+	sender, recipient, _ := newReceiptTestManagers(t)
+	got := recordReceipts(sender)
+
+	messageHMAC := [32]byte{4, 5, 6}
+	expectReceiptFor(sender, recipient.keyPair.Public, messageHMAC)
+	valid, err := recipient.createDeliveryReceiptPacket(messageHMAC)
+	if err != nil {
+		t.Fatalf("Failed to create receipt: %v", err)
+	}
+
+	forged := append([]byte(nil), valid...)
+	forged[len(forged)-1] ^= 0xFF
+
+	unknownHMAC, err := recipient.createDeliveryReceiptPacket([32]byte{7, 8, 9})
+	if err != nil {
+		t.Fatalf("Failed to create receipt: %v", err)
+	}
+
+	// A receipt claiming to come from the recipient but signed by someone else
+	impostorKeys, err := crypto.GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("Failed to generate impostor keys: %v", err)
+	}
+	impostor := append([]byte(nil), valid[:receiptPayloadSize]...)
+	signature, err := crypto.Sign(impostor, impostorKeys.Private)
+	if err != nil {
+		t.Fatalf("Failed to sign impostor receipt: %v", err)
+	}
+	impostor = append(impostor, signature[:]...)
+
+	addr := &MockAddr{network: "mock", address: "attacker:33445"}
+	for name, data := range map[string][]byte{
+		"tampered signature": forged,
+		"impostor signature": impostor,
+		"unsent message":     unknownHMAC,
+		"truncated":          valid[:receiptPacketSize-1],
+	} {
+		sender.handleDeliveryReceiptPacket(&transport.Packet{
+			PacketType: transport.PacketAsyncDeliveryReceipt,
+			Data:       data,
+		}, addr)
+		if len(*got) != 0 {
+			t.Fatalf("%s: expected receipt to be rejected", name)
+		}
+	}
+
+	// Unknown signing key
+	delete(sender.friendSignKeys, recipient.keyPair.Public)
+	sender.handleDeliveryReceiptPacket(&transport.Packet{Data: valid}, addr)
+	if len(*got) != 0 {
+		t.Fatal("Expected receipt from signer without a trusted key to be rejected")
+	}
+}
+
+func TestDeliveryReceiptsDisabledByDefault(t *testing.T) {
+	sender, recipient, recipientTransport := newReceiptTestManagers(t)
+
+	messageHMAC := [32]byte{10, 11, 12}
+	expectReceiptFor(sender, recipient.keyPair.Public, messageHMAC)
+	if len(sender.receipts.awaiting) != 0 {
+		t.Error("Expected no receipts tracked without a handler")
+	}
+
+	recipient.sendDeliveryReceipts([]DecryptedMessage{{SenderPK: sender.keyPair.Public, MessageHMAC: messageHMAC}})
+	if packets := recipientTransport.GetPackets(); len(packets) != 0 {
+		t.Errorf("Expected no receipts sent without a handler, got %d packets", len(packets))
+	}
+
+	// Clearing the handler forgets awaited receipts
+	recordReceipts(sender)
+	expectReceiptFor(sender, recipient.keyPair.Public, messageHMAC)
+	sender.SetDeliveryReceiptHandler(nil)
+	if len(sender.receipts.awaiting) != 0 {
+		t.Error("Expected awaited receipts to be cleared with the handler")
+	}
+}
+
+func TestDeliveryReceiptTrackerPrunesExpired(t *testing.T) {
+	tracker := newDeliveryReceiptTracker()
+	tracker.setHandler(func(recipientPK, messageHMAC [32]byte) {})
+
+	now := time.Now()
+	recipientPK := [32]byte{1}
+	tracker.expect(recipientPK, &ObfuscatedAsyncMessage{RecipientProof: [32]byte{1}, ExpiresAt: now.Add(-time.Minute)})
+	tracker.expect(recipientPK, &ObfuscatedAsyncMessage{RecipientProof: [32]byte{2}, ExpiresAt: now.Add(time.Minute)})
+
+	if removed := tracker.pruneExpired(now); removed != 1 {
+		t.Errorf("Expected 1 expired entry pruned, got %d", removed)
+	}
+	if tracker.resolve(recipientPK, [32]byte{1}) != nil {
+		t.Error("Expected expired message to no longer await a receipt")
+	}
+	if tracker.resolve([32]byte{9}, [32]byte{2}) != nil {
+		t.Error("Expected receipt from a different recipient to be ignored")
+	}
+	if tracker.resolve(recipientPK, [32]byte{2}) == nil {
+		t.Error("Expected pending message to resolve")
+	}
+}
@@ -49,6 +49,19 @@
 //	// Send message to offline friend
 //	err = manager.SendAsyncMessage(friendPublicKey, "Hello!", async.MessageTypeNormal)
 //
+// Delivery receipts are opt-in. With a handler set, the manager is told when
+// a recipient has retrieved and decrypted a message, and sends receipts for
+// the messages it retrieves itself:
+//
+//	manager.SetDeliveryReceiptHandler(func(recipientPK, messageHMAC [32]byte) {
+//	    fmt.Printf("Delivered to %x\n", recipientPK[:8])
+//	})
+//
+// A receipt carries the recipient's public key, the message's recipient
+// proof HMAC and an Ed25519 signature checked against the key learned from
+// the recipient's pre-key exchange. It goes directly to the sender, so
+// storage nodes never see it.
+//
 // # AsyncClient
 //
 // AsyncClient handles direct communication with storage nodes:
@@ -66,6 +79,14 @@
 //	client.SetCollectionTimeout(10 * time.Second)
 //	client.SetParallelQueries(true)
 //
+// Messages are erasure-coded across five storage nodes by default. With
+// erasure coding disabled, each message is instead stored in full on the
+// nodes closest to the recipient pseudonym by XOR distance, and survives the
+// loss of all but one of them; copies are deduplicated on retrieval:
+//
+//	client.SetErasureCodingEnabled(false)
+//	client.SetReplicationFactor(5)
+//
 // # Forward Secrecy
 //
 // Forward secrecy is achieved through one-time pre-keys that are consumed
@@ -84,6 +105,30 @@
 //   - PreKeysPerPeer (200): Initial pre-keys generated per peer
 //   - PreKeyRefreshThreshold (50): Triggers bundle refresh
 //
+// When fewer than PreKeyLowWatermark of a friend's pre-keys remain, a
+// background routine sends the friend a PacketAsyncPreKeyRequest and the
+// friend answers with a fresh bundle. Requests to the same friend are sent
+// at most every PreKeyRequestMinInterval, and an unanswered request fails
+// after PreKeyRequestTimeout:
+//
+//	manager.SetPreKeyReplenishmentCallback(func(friendPK [32]byte, err error) {
+//	    if err != nil {
+//	        log.Printf("pre-key replenishment failed: %v", err)
+//	    }
+//	})
+//
+// Below PreKeyMinimum sends to the friend fail, so AsyncManager escalates:
+// it sends AsyncManagerConfig.MaxRecoveryAttempts (default 3) concurrent
+// requests right away, ignoring the usual interval, and waits
+// PreKeyRecoveryTimeout for a bundle. If none arrives, ErrPreKeysExhausted
+// is delivered on ErrorChannel:
+//
+//	for err := range manager.ErrorChannel() {
+//	    if errors.Is(err, async.ErrPreKeysExhausted) {
+//	        // show "waiting for key exchange"
+//	    }
+//	}
+//
 // # Identity Obfuscation
 //
 // The ObfuscationManager generates cryptographic pseudonyms to hide real
@@ -111,12 +156,16 @@
 //	pastEpoch := epochManager.GetEpochAt(someTime)
 //
 //	// Validate epoch freshness
-//	if epochManager.IsValidEpoch(messageEpoch) {
+//	if epochManager.IsValidEpoch(messageEpoch, async.DefaultEpochTolerance) {
 //	    // Process message
 //	}
 //
 // Network genesis time is January 1, 2025 00:00:00 UTC for consistent
-// epoch calculation across all nodes.
+// epoch calculation across all nodes. Test environments and private networks
+// can choose both values, and tests can control the clock:
+//
+//	em, _ := async.NewEpochManagerWithConfig(genesis, 30*time.Minute)
+//	em.SetTimeProvider(mockTime)
 //
 // # Message Storage
 //
@@ -138,6 +187,12 @@
 //   - MaxStorageCapacity: ~1,536,000 messages (~1GB)
 //   - MaxStorageTime: 24 hours before expiration
 //   - MaxMessagesPerRecipient: 100 per recipient
+//   - DefaultMaxStorageBytesPerRecipient: 1 MiB per recipient
+//
+// Exceeding a per-recipient quota fails with ErrRecipientStorageFull. Quotas
+// are configured with StorageConfig; UpdateCapacity evicts the oldest
+// messages of recipients left over quota, and GetStorageStats reports byte
+// usage per recipient.
 //
 // # Retrieval Scheduler
 //
@@ -147,7 +202,7 @@
 //	scheduler := async.NewRetrievalScheduler(client)
 //
 //	// Configure retrieval behavior
-//	scheduler.SetBaseInterval(5 * time.Minute)
+//	scheduler.SetAdaptiveBounds(30*time.Second, 30*time.Minute)
 //	scheduler.SetJitterPercent(50)          // Add up to 50% random delay
 //	scheduler.SetCoverTrafficEnabled(true)  // Enable dummy retrievals
 //	scheduler.SetCoverTrafficRatio(0.3)     // 30% cover traffic
@@ -155,6 +210,12 @@
 //	scheduler.Start()
 //	defer scheduler.Stop()
 //
+// By default the base interval follows an exponential moving average of the
+// time between retrieved messages, bounded by SetAdaptiveBounds, so busy
+// conversations are polled more often than idle ones. Jitter and backoff
+// after empty retrievals apply on top. SetBaseInterval fixes the interval
+// and turns adaptation off.
+//
 // # Message Types
 //
 // Two message types are supported:
@@ -185,7 +246,8 @@
 //   - Activity Privacy: Cover traffic and randomized timing prevent
 //     tracking of user activity patterns
 //   - HMAC Recipient Proof: Prevents spam while preserving anonymity
-//   - Secure Key Storage: Pre-keys are encrypted on disk with secure wiping
+//   - Secure Key Storage: Pre-keys are encrypted on disk with secure wiping;
+//     each bundle carries an HMAC and bundles that fail it are discarded on load
 //
 // # Cryptographic Primitives
 //
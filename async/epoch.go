@@ -10,6 +10,11 @@ import (
 // This provides a balance between privacy (frequent rotation) and performance (not too frequent)
 const EpochDuration = 6 * time.Hour
 
+// DefaultEpochTolerance is the number of epochs on either side of the current
+// epoch that IsValidEpoch accepts in the messaging paths. With the default
+// epoch duration it covers 24 hours of delay and clock skew.
+const DefaultEpochTolerance = 3
+
 // genesisTimeMu protects access to DefaultNetworkGenesisTime
 var genesisTimeMu sync.RWMutex
 
@@ -28,6 +33,9 @@ var DefaultNetworkGenesisTime = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
 type EpochManager struct {
 	startTime     time.Time     // Network genesis time for epoch calculation
 	epochDuration time.Duration // Duration of each epoch (normally 6 hours)
+
+	mu           sync.RWMutex
+	timeProvider TimeProvider // Source of the current time
 }
 
 // NewEpochManager creates a new epoch manager with the default network start time.
@@ -35,7 +43,7 @@ type EpochManager struct {
 // consistent epoch calculation across all nodes in the network.
 //
 // For private networks, set DefaultNetworkGenesisTime before calling this function.
-// For testing with custom times, use NewEpochManagerWithCustomStart instead.
+// For a custom genesis time or epoch duration, use NewEpochManagerWithConfig instead.
 func NewEpochManager() *EpochManager {
 	genesisTimeMu.RLock()
 	startTime := DefaultNetworkGenesisTime
@@ -44,26 +52,57 @@ func NewEpochManager() *EpochManager {
 	return &EpochManager{
 		startTime:     startTime,
 		epochDuration: EpochDuration,
+		timeProvider:  DefaultTimeProvider{},
 	}
 }
 
-// NewEpochManagerWithCustomStart creates an epoch manager with a custom start time.
-// This is primarily used for testing with controlled time scenarios.
-func NewEpochManagerWithCustomStart(startTime time.Time, duration time.Duration) (*EpochManager, error) {
-	if duration <= 0 {
+// NewEpochManagerWithConfig creates an epoch manager with the given genesis
+// time and epoch duration. All nodes in a network must use the same values
+// to agree on epoch numbers; shorter epochs rotate pseudonyms more often.
+func NewEpochManagerWithConfig(genesis time.Time, epochDuration time.Duration) (*EpochManager, error) {
+	if epochDuration <= 0 {
 		return nil, errors.New("epoch duration must be positive")
 	}
 
 	return &EpochManager{
-		startTime:     startTime,
-		epochDuration: duration,
+		startTime:     genesis,
+		epochDuration: epochDuration,
+		timeProvider:  DefaultTimeProvider{},
 	}, nil
 }
 
+// NewEpochManagerWithCustomStart creates an epoch manager with a custom start time.
+// It is equivalent to NewEpochManagerWithConfig.
+func NewEpochManagerWithCustomStart(startTime time.Time, duration time.Duration) (*EpochManager, error) {
+	return NewEpochManagerWithConfig(startTime, duration)
+}
+
+// SetTimeProvider sets the time provider for deterministic testing.
+// Pass nil to reset to the default time provider.
+func (em *EpochManager) SetTimeProvider(tp TimeProvider) {
+	if tp == nil {
+		tp = DefaultTimeProvider{}
+	}
+	em.mu.Lock()
+	em.timeProvider = tp
+	em.mu.Unlock()
+}
+
+// now returns the current time from the configured time provider.
+func (em *EpochManager) now() time.Time {
+	em.mu.RLock()
+	tp := em.timeProvider
+	em.mu.RUnlock()
+	if tp == nil {
+		return time.Now()
+	}
+	return tp.Now()
+}
+
 // GetCurrentEpoch returns the current epoch number based on the current time.
 // Epochs are numbered sequentially starting from 0 at the network start time.
 func (em *EpochManager) GetCurrentEpoch() uint64 {
-	return em.GetEpochAt(time.Now())
+	return em.GetEpochAt(em.now())
 }
 
 // GetEpochAt returns the epoch number for a specific time.
@@ -107,30 +146,27 @@ func (em *EpochManager) GetEpochEndTime(epoch uint64) time.Time {
 }
 
 // IsValidEpoch checks if an epoch is within the acceptable range for current operations.
-// Valid epochs are the current epoch and up to 3 previous epochs (24 hours total).
-// This allows for clock skew and delayed message processing while limiting storage requirements.
-func (em *EpochManager) IsValidEpoch(epoch uint64) bool {
+// Valid epochs are those at most tolerance epochs before or after the current epoch.
+// This allows for clock skew and delayed message processing while limiting storage
+// requirements; the messaging paths use DefaultEpochTolerance.
+func (em *EpochManager) IsValidEpoch(epoch, tolerance uint64) bool {
 	currentEpoch := em.GetCurrentEpoch()
 
-	// Allow current epoch and up to 3 previous epochs (24 hours total)
-	// This accounts for clock skew and ensures messages aren't rejected
-	// due to minor timing differences between nodes
 	if epoch > currentEpoch {
-		return false // Future epochs not allowed
+		return epoch-currentEpoch <= tolerance
 	}
-
-	return currentEpoch-epoch <= 3
+	return currentEpoch-epoch <= tolerance
 }
 
 // GetRecentEpochs returns a list of recent epochs that should be checked
-// when retrieving messages. This includes the current epoch and the 3
-// most recent previous epochs, covering a 24-hour window.
+// when retrieving messages. This includes the current epoch and the
+// DefaultEpochTolerance most recent previous epochs.
 func (em *EpochManager) GetRecentEpochs() []uint64 {
 	currentEpoch := em.GetCurrentEpoch()
-	epochs := make([]uint64, 0, 4)
+	epochs := make([]uint64, 0, DefaultEpochTolerance+1)
 
-	// Add current epoch and up to 3 previous epochs
-	for i := uint64(0); i <= 3; i++ {
+	// Add current epoch and up to DefaultEpochTolerance previous epochs
+	for i := uint64(0); i <= DefaultEpochTolerance; i++ {
 		if currentEpoch >= i {
 			epochs = append(epochs, currentEpoch-i)
 		}
@@ -144,7 +180,7 @@ func (em *EpochManager) GetRecentEpochs() []uint64 {
 func (em *EpochManager) TimeUntilNextEpoch() time.Duration {
 	currentEpoch := em.GetCurrentEpoch()
 	nextEpochStart := em.GetEpochStartTime(currentEpoch + 1)
-	return time.Until(nextEpochStart)
+	return nextEpochStart.Sub(em.now())
 }
 
 // GetEpochDuration returns the configured epoch duration.
@@ -196,36 +196,112 @@ func TestGetEpochEndTime(t *testing.T) {
 	}
 }
 
+// fixedTimeProvider returns a settable time for deterministic epoch tests.
+type fixedTimeProvider struct {
+	now time.Time
+}
+
+func (f *fixedTimeProvider) Now() time.Time { return f.now }
+
+func (f *fixedTimeProvider) Since(t time.Time) time.Duration { return f.now.Sub(t) }
+
 func TestIsValidEpoch(t *testing.T) {
-	// Test with real current time - we can verify the logic is correct
-	em := NewEpochManager()
+	genesis := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	em, err := NewEpochManagerWithConfig(genesis, time.Hour)
+	if err != nil {
+		t.Fatalf("Failed to create epoch manager: %v", err)
+	}
+	em.SetTimeProvider(&fixedTimeProvider{now: genesis.Add(10*time.Hour + 30*time.Minute)})
 	currentEpoch := em.GetCurrentEpoch()
+	if currentEpoch != 10 {
+		t.Fatalf("Expected current epoch 10, got %d", currentEpoch)
+	}
 
 	testCases := []struct {
-		name     string
-		epoch    uint64
-		expected bool
+		name      string
+		epoch     uint64
+		tolerance uint64
+		expected  bool
 	}{
-		{"Current epoch", currentEpoch, true},
-		{"Previous epoch", currentEpoch - 1, true},
-		{"Two epochs ago", currentEpoch - 2, true},
-		{"Three epochs ago", currentEpoch - 3, true},
-		{"Four epochs ago", currentEpoch - 4, false},
-		{"Future epoch", currentEpoch + 1, false},
-		{"Far future epoch", currentEpoch + 10, false},
+		{"Current epoch", currentEpoch, DefaultEpochTolerance, true},
+		{"Previous epoch", currentEpoch - 1, DefaultEpochTolerance, true},
+		{"Three epochs ago", currentEpoch - 3, DefaultEpochTolerance, true},
+		{"Four epochs ago", currentEpoch - 4, DefaultEpochTolerance, false},
+		{"Next epoch", currentEpoch + 1, DefaultEpochTolerance, true},
+		{"Three epochs ahead", currentEpoch + 3, DefaultEpochTolerance, true},
+		{"Far future epoch", currentEpoch + 10, DefaultEpochTolerance, false},
+		{"Zero tolerance current", currentEpoch, 0, true},
+		{"Zero tolerance previous", currentEpoch - 1, 0, false},
+		{"Zero tolerance next", currentEpoch + 1, 0, false},
+		{"Wide tolerance", currentEpoch - 10, 10, true},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			result := em.IsValidEpoch(tc.epoch)
+			result := em.IsValidEpoch(tc.epoch, tc.tolerance)
 			if result != tc.expected {
-				t.Errorf("Expected IsValidEpoch(%d) = %v, got %v",
-					tc.epoch, tc.expected, result)
+				t.Errorf("Expected IsValidEpoch(%d, %d) = %v, got %v",
+					tc.epoch, tc.tolerance, tc.expected, result)
 			}
 		})
 	}
 }
 
+func TestNewEpochManagerWithConfig(t *testing.T) {
+	genesis := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	em, err := NewEpochManagerWithConfig(genesis, 15*time.Minute)
+	if err != nil {
+		t.Fatalf("Failed to create epoch manager: %v", err)
+	}
+	if !em.GetNetworkGenesisTime().Equal(genesis) {
+		t.Errorf("Expected genesis %v, got %v", genesis, em.GetNetworkGenesisTime())
+	}
+	if em.GetEpochDuration() != 15*time.Minute {
+		t.Errorf("Expected epoch duration 15m, got %v", em.GetEpochDuration())
+	}
+
+	for _, d := range []time.Duration{0, -time.Second} {
+		if _, err := NewEpochManagerWithConfig(genesis, d); err == nil {
+			t.Errorf("Expected error for epoch duration %v", d)
+		}
+	}
+}
+
+func TestEpochManagerTimeProviderBoundaries(t *testing.T) {
+	genesis := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	clock := &fixedTimeProvider{now: genesis.Add(30*time.Minute - time.Nanosecond)}
+
+	// Two nodes with the same configuration agree on every boundary
+	a, _ := NewEpochManagerWithConfig(genesis, 30*time.Minute)
+	b, _ := NewEpochManagerWithConfig(genesis, 30*time.Minute)
+	a.SetTimeProvider(clock)
+	b.SetTimeProvider(clock)
+
+	if got := a.GetCurrentEpoch(); got != 0 {
+		t.Errorf("Expected epoch 0 just before the first boundary, got %d", got)
+	}
+	if got := a.TimeUntilNextEpoch(); got != time.Nanosecond {
+		t.Errorf("Expected 1ns until next epoch, got %v", got)
+	}
+
+	clock.now = genesis.Add(30 * time.Minute)
+	if got := a.GetCurrentEpoch(); got != 1 {
+		t.Errorf("Expected epoch 1 at the boundary, got %d", got)
+	}
+	if a.GetCurrentEpoch() != b.GetCurrentEpoch() {
+		t.Errorf("Nodes disagree on current epoch: %d vs %d", a.GetCurrentEpoch(), b.GetCurrentEpoch())
+	}
+	if got := a.TimeUntilNextEpoch(); got != 30*time.Minute {
+		t.Errorf("Expected 30m until next epoch, got %v", got)
+	}
+
+	// Resetting to the default provider uses the wall clock again
+	a.SetTimeProvider(nil)
+	if a.GetCurrentEpoch() == 1 {
+		t.Error("Expected wall-clock epoch after resetting time provider")
+	}
+}
+
 func TestGetRecentEpochs(t *testing.T) {
 	// Test with real current time and verify the pattern is correct
 	em := NewEpochManager()
@@ -362,7 +438,7 @@ func BenchmarkIsValidEpoch(b *testing.B) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		em.IsValidEpoch(currentEpoch - 1)
+		em.IsValidEpoch(currentEpoch-1, DefaultEpochTolerance)
 	}
 }
 
@@ -97,6 +97,10 @@ type ForwardSecurityManager struct {
 	// the peer public key and the current remaining count.  It is invoked outside
 	// of any mutex and must not call back into the ForwardSecurityManager.
 	onPreKeyLowWatermark func(peerPK [32]byte, remaining int)
+
+	// replenisher requests fresh pre-keys from peers whose pool fell below
+	// PreKeyLowWatermark. See SetPreKeyRequestSender.
+	replenisher *preKeyReplenisher
 }
 
 const (
@@ -164,6 +168,7 @@ func NewForwardSecurityManagerWithInterval(keyPair *crypto.KeyPair, dataDir stri
 		preKeyConsumed:  make(map[[32]byte][]time.Time),
 		cleanupInterval: cleanupInterval,
 		stopCleanup:     make(chan struct{}),
+		replenisher:     newPreKeyReplenisher(),
 	}
 
 	// Start automatic cleanup goroutine if interval is positive
@@ -174,6 +179,9 @@ func NewForwardSecurityManagerWithInterval(keyPair *crypto.KeyPair, dataDir stri
 	// Start proactive pre-key refresh goroutine (weekly by default).
 	fsm.startProactiveRefreshRoutine()
 
+	// Start automatic pre-key replenishment for peers below the watermark.
+	fsm.startReplenishmentRoutine()
+
 	return fsm, nil
 }
 
@@ -186,16 +194,16 @@ func (fsm *ForwardSecurityManager) startCleanupRoutine() {
 		ticker := time.NewTicker(fsm.cleanupInterval)
 		defer ticker.Stop()
 
-		logrus.WithField("interval", fsm.cleanupInterval).Info("Started pre-key cleanup routine")
+		pkgLog.WithField("interval", fsm.cleanupInterval).Info("Started pre-key cleanup routine")
 
 		for {
 			select {
 			case <-ticker.C:
-				logrus.Debug("Running scheduled pre-key cleanup")
+				pkgLog.Debug("Running scheduled pre-key cleanup")
 				fsm.CleanupExpiredData()
-				logrus.Debug("Scheduled pre-key cleanup completed")
+				pkgLog.Debug("Scheduled pre-key cleanup completed")
 			case <-fsm.stopCleanup:
-				logrus.Info("Stopping pre-key cleanup routine")
+				pkgLog.Info("Stopping pre-key cleanup routine")
 				return
 			}
 		}
@@ -266,13 +274,13 @@ func (fsm *ForwardSecurityManager) proactiveRefreshAll() {
 			select {
 			case err := <-done:
 				if err != nil {
-					logrus.WithFields(logrus.Fields{
+					pkgLog.WithFields(logrus.Fields{
 						"peer":  fmt.Sprintf("%x", peerPK[:8]),
 						"error": err.Error(),
 					}).Warn("Proactive pre-key refresh failed")
 				}
 			case <-time.After(preKeyRefreshTimeout):
-				logrus.WithFields(logrus.Fields{
+				pkgLog.WithFields(logrus.Fields{
 					"peer": fmt.Sprintf("%x", peerPK[:8]),
 				}).Warn("Proactive pre-key refresh timed out; callback will continue in background")
 			case <-fsm.stopCleanup:
@@ -299,7 +307,12 @@ func (fsm *ForwardSecurityManager) Close() error {
 	// Wait for all goroutines (cleanup + async refresh operations) to finish
 	fsm.cleanupWg.Wait()
 
-	logrus.Info("ForwardSecurityManager closed")
+	// Wipe cached pre-key material; saved bundles stay on disk
+	if err := fsm.preKeyStore.Close(); err != nil {
+		return fmt.Errorf("failed to close pre-key store: %w", err)
+	}
+
+	pkgLog.Info("ForwardSecurityManager closed")
 	return nil
 }
 
@@ -337,7 +350,7 @@ func (fsm *ForwardSecurityManager) validatePreKeys(recipientPK [32]byte) ([]PreK
 	}
 
 	if len(peerPreKeys) <= PreKeyMinimum+1 {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"recipient":      fmt.Sprintf("%x", recipientPK[:8]),
 			"available_keys": len(peerPreKeys),
 			"minimum":        PreKeyMinimum,
@@ -384,6 +397,9 @@ func (fsm *ForwardSecurityManager) consumePreKey(recipientPK [32]byte, peerPreKe
 			go hook(recipientPK, remainingKeys)
 		}
 	}
+	if remainingKeys < PreKeyLowWatermark {
+		fsm.wakeReplenisher()
+	}
 
 	return preKey, nil
 }
@@ -400,7 +416,7 @@ func (fsm *ForwardSecurityManager) triggerAsyncRefresh(recipientPK [32]byte, rem
 	fsm.cleanupWg.Add(1)
 	fsm.closedMu.Unlock()
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"recipient":      fmt.Sprintf("%x", recipientPK[:8]),
 		"remaining_keys": remainingKeys,
 		"low_watermark":  PreKeyLowWatermark,
@@ -425,17 +441,17 @@ func (fsm *ForwardSecurityManager) triggerAsyncRefresh(recipientPK [32]byte, rem
 		select {
 		case err := <-done:
 			if err != nil {
-				logrus.WithFields(logrus.Fields{
+				pkgLog.WithFields(logrus.Fields{
 					"recipient": fmt.Sprintf("%x", recipientPK[:8]),
 					"error":     err.Error(),
 				}).Error("Pre-key refresh failed")
 			} else {
-				logrus.WithFields(logrus.Fields{
+				pkgLog.WithFields(logrus.Fields{
 					"recipient": fmt.Sprintf("%x", recipientPK[:8]),
 				}).Info("Pre-key refresh completed successfully")
 			}
 		case <-time.After(preKeyRefreshTimeout):
-			logrus.WithFields(logrus.Fields{
+			pkgLog.WithFields(logrus.Fields{
 				"recipient": fmt.Sprintf("%x", recipientPK[:8]),
 			}).Warn("Pre-key refresh timed out; callback will continue in background")
 		case <-fsm.stopCleanup:
@@ -479,7 +495,7 @@ func generateMessageID() ([32]byte, error) {
 // step, preventing accidental identity leakage.
 func (fsm *ForwardSecurityManager) SendForwardSecureMessage(recipientPK [32]byte, message []byte, messageType MessageType) (*ForwardSecureMessage, error) {
 	sendFSMDeprecationWarningOnce.Do(func() {
-		logrus.Warn("ForwardSecurityManager.SendForwardSecureMessage is deprecated: " +
+		pkgLog.Warn("ForwardSecurityManager.SendForwardSecureMessage is deprecated: " +
 			"wrap the result in ObfuscatedAsyncMessage via AsyncClient.SendObfuscatedMessage, " +
 			"or switch to AsyncClient.SendAsyncMessage / AsyncManager.SendAsyncMessage")
 	})
@@ -658,13 +674,13 @@ func (fsm *ForwardSecurityManager) ProcessPreKeyExchange(exchange *PreKeyExchang
 	}
 
 	fsm.peerPreKeysMutex.Lock()
-	defer fsm.peerPreKeysMutex.Unlock()
-
 	fsm.peerPreKeys[exchange.SenderPK] = mergeUniquePreKeys(
 		fsm.peerPreKeys[exchange.SenderPK],
 		exchange.PreKeys,
 	)
+	fsm.peerPreKeysMutex.Unlock()
 
+	fsm.completeReplenishment(exchange.SenderPK)
 	return nil
 }
 
@@ -134,7 +134,7 @@ func (mo *MessageOrdering) CurrentClock() uint64 {
 func SortByLamport[T any](items []T, getTimestamp func(T) uint64) {
 	// Log warning for unusually large message batches
 	if len(items) > 10000 {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"component": "lamport",
 			"count":     len(items),
 		}).Warn("Large message batch detected, Lamport sorting overhead increasing")
@@ -0,0 +1,15 @@
+package async
+
+import (
+	"github.com/opd-ai/toxcore/logging"
+	"github.com/sirupsen/logrus"
+)
+
+// pkgLog is the logger for this package, tagged with component "async".
+var pkgLog = logging.NewComponent("async")
+
+// SetLogLevel sets the log level for this package independently of the
+// global logrus level.
+func SetLogLevel(level logrus.Level) {
+	pkgLog.SetLevel(level)
+}
@@ -36,11 +36,14 @@ type AsyncManager struct {
 	friendSignKeys    map[[32]byte][32]byte                                            // Trusted Ed25519 signing key per friend (TOFU)
 	pendingMessages   map[[32]byte][]pendingMessage                                    // Messages queued for pre-key exchange
 	preKeyReadyCh     map[[32]byte]chan struct{}                                       // Signaled when peer's pre-keys arrive
+	preKeyResponses   map[[32]byte]time.Time                                           // Last pre-key request answered per friend (rate limiting)
 	messageHandler    func(senderPK [32]byte, message string, messageType MessageType) // Callback for received async messages
 	keyChangeCallback func(friendPK, oldKey, newKey [32]byte)                          // Fired on Ed25519 signing-key mismatch (TOFU alarm)
 	notificationHub   *NotificationHub                                                 // Push notification system
+	receipts          *deliveryReceiptTracker                                          // Delivery receipt handler and awaited receipts
 	messageOrdering   *MessageOrdering                                                 // Lamport clock for causal message ordering
 	discovery         *StorageNodeDiscovery                                            // DHT-based storage node discovery
+	errorCh           chan error                                                       // Asynchronous failures, see ErrorChannel
 	running           bool
 	stopChan          chan struct{}
 	wg                sync.WaitGroup // Tracks background goroutines for clean shutdown
@@ -52,12 +55,22 @@ type AsyncManagerConfig struct {
 	// When set to 0 or not specified, the default of 100 is used.
 	// This value configures the base limit for dynamic per-recipient limits.
 	MaxMessagesPerRecipient int
+	// MaxStorageBytesPerRecipient limits the encrypted bytes stored per
+	// recipient. When set to 0, DefaultMaxStorageBytesPerRecipient is used.
+	MaxStorageBytesPerRecipient int64
+	// MaxRecoveryAttempts is the number of concurrent pre-key requests sent
+	// when a friend's pre-key pool falls below PreKeyMinimum. When set to 0,
+	// DefaultMaxRecoveryAttempts is used; a negative value disables
+	// critical-zone recovery.
+	MaxRecoveryAttempts int
 }
 
 // DefaultAsyncManagerConfig returns the default configuration for AsyncManager.
 func DefaultAsyncManagerConfig() *AsyncManagerConfig {
 	return &AsyncManagerConfig{
-		MaxMessagesPerRecipient: MaxMessagesPerRecipient,
+		MaxMessagesPerRecipient:     MaxMessagesPerRecipient,
+		MaxStorageBytesPerRecipient: DefaultMaxStorageBytesPerRecipient,
+		MaxRecoveryAttempts:         DefaultMaxRecoveryAttempts,
 	}
 }
 
@@ -98,19 +111,20 @@ func (am *AsyncManager) initializeWAL(dataDir string) {
 	}
 	recovered, recoverErr := am.storage.RecoverFromWAL()
 	if recoverErr != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "initializeWAL",
 			"error":    recoverErr.Error(),
 		}).Warn("WAL recovery encountered errors")
 	} else if recovered > 0 {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":  "initializeWAL",
 			"recovered": recovered,
 		}).Info("Recovered messages from WAL after restart")
 	}
 }
 
-// registerPreKeyHandler registers the pre-key exchange packet handler with the transport.
+// registerPreKeyHandler registers the pre-key exchange, pre-key request and
+// delivery receipt packet handlers with the transport.
 func (am *AsyncManager) registerPreKeyHandler(trans transport.Transport) {
 	if trans == nil {
 		return
@@ -119,6 +133,14 @@ func (am *AsyncManager) registerPreKeyHandler(trans transport.Transport) {
 		am.handlePreKeyExchangePacket(packet, addr)
 		return nil
 	})
+	trans.RegisterHandler(transport.PacketAsyncPreKeyRequest, func(packet *transport.Packet, addr net.Addr) error {
+		am.handlePreKeyRequestPacket(packet, addr)
+		return nil
+	})
+	trans.RegisterHandler(transport.PacketAsyncDeliveryReceipt, func(packet *transport.Packet, addr net.Addr) error {
+		am.handleDeliveryReceiptPacket(packet, addr)
+		return nil
+	})
 }
 
 // NewAsyncManager creates a new async message manager with built-in obfuscation
@@ -149,11 +171,15 @@ func buildAsyncManager(keyPair *crypto.KeyPair, trans transport.Transport, stora
 		friendSignKeys:  make(map[[32]byte][32]byte),
 		pendingMessages: make(map[[32]byte][]pendingMessage),
 		preKeyReadyCh:   make(map[[32]byte]chan struct{}),
+		preKeyResponses: make(map[[32]byte]time.Time),
 		messageOrdering: NewMessageOrdering(),
+		receipts:        newDeliveryReceiptTracker(),
 		discovery:       discovery,
 		stopChan:        make(chan struct{}),
+		errorCh:         make(chan error, errorChannelSize),
 	}
 	am.client.SetForwardSecurityManager(forwardSecurity)
+	forwardSecurity.SetPreKeyRequestSender(am.sendPreKeyRequest)
 	return am
 }
 
@@ -161,7 +187,7 @@ func buildAsyncManager(keyPair *crypto.KeyPair, trans transport.Transport, stora
 func registerDiscoveryCallback(am *AsyncManager, discovery *StorageNodeDiscovery) {
 	discovery.OnNodeDiscovered(func(ann *StorageNodeAnnouncement) {
 		am.client.AddStorageNode(ann.PublicKey, ann.ToNetAddr())
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "StorageNodeDiscovery",
 			"address":  ann.Address,
 			"port":     ann.Port,
@@ -182,9 +208,18 @@ func NewAsyncManagerWithConfig(keyPair *crypto.KeyPair, trans transport.Transpor
 	if config.MaxMessagesPerRecipient > 0 && config.MaxMessagesPerRecipient != MaxMessagesPerRecipient {
 		storage.SetMaxMessagesPerRecipient(config.MaxMessagesPerRecipient)
 	}
+	if config.MaxStorageBytesPerRecipient > 0 {
+		storage.SetMaxStorageBytesPerRecipient(config.MaxStorageBytesPerRecipient)
+	}
 	discovery := NewStorageNodeDiscovery()
 	obfuscation := NewObfuscationManager(keyPair, NewEpochManager())
 	am := buildAsyncManager(keyPair, trans, storage, forwardSecurity, obfuscation, discovery)
+	switch {
+	case config.MaxRecoveryAttempts > 0:
+		forwardSecurity.setRecovery(config.MaxRecoveryAttempts, am.reportPreKeysExhausted)
+	case config.MaxRecoveryAttempts == 0:
+		forwardSecurity.setRecovery(DefaultMaxRecoveryAttempts, am.reportPreKeysExhausted)
+	}
 	registerDiscoveryCallback(am, discovery)
 	am.initializeWAL(dataDir)
 	am.registerPreKeyHandler(trans)
@@ -293,7 +328,12 @@ func (am *AsyncManager) sendForwardSecureMessage(recipientPK [32]byte, message s
 	}
 
 	// Store the forward-secure message using obfuscation
-	return am.client.SendObfuscatedMessage(recipientPK, fsMsg)
+	obfMsg, err := am.client.sendObfuscatedMessage(recipientPK, fsMsg)
+	if err != nil {
+		return err
+	}
+	am.receipts.expect(recipientPK, obfMsg)
+	return nil
 }
 
 // SetFriendOnlineStatus updates the online status of a friend
@@ -591,7 +631,7 @@ func (am *AsyncManager) performStorageNodeDiscovery() {
 	// Clean up expired announcements
 	removed := am.discovery.CleanExpired()
 	if removed > 0 {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "performStorageNodeDiscovery",
 			"removed":  removed,
 		}).Debug("Cleaned up expired storage node announcements")
@@ -604,7 +644,7 @@ func (am *AsyncManager) performStorageNodeDiscovery() {
 
 	// Check if we need to discover more nodes
 	if am.discovery.NeedsDiscovery() {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":    "performStorageNodeDiscovery",
 			"cachedNodes": am.discovery.Count(),
 		}).Debug("Storage node cache low, would initiate DHT discovery")
@@ -631,7 +671,7 @@ func (am *AsyncManager) announceAsStorageNode() {
 		return
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "announceAsStorageNode",
 		"load":     load,
 		"capacity": ann.Capacity,
@@ -673,7 +713,7 @@ func (am *AsyncManager) GetDiscoveredStorageNodes() []*StorageNodeAnnouncement {
 func (am *AsyncManager) AddDiscoveredStorageNode(ann *StorageNodeAnnouncement) {
 	if am.discovery.StoreAnnouncement(ann) {
 		// New node - callback will auto-add to client
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "AddDiscoveredStorageNode",
 			"address":  ann.Address,
 			"port":     ann.Port,
@@ -744,6 +784,7 @@ func (am *AsyncManager) performExpiredMessageCleanup() {
 	if expired > 0 {
 		log.Printf("Async storage: cleaned up %d expired messages", expired)
 	}
+	am.receipts.pruneExpired(time.Now())
 }
 
 // performCapacityUpdate updates storage capacity and logs status or errors
@@ -781,6 +822,8 @@ func (am *AsyncManager) retrievePendingMessages() {
 		}
 	}
 
+	am.sendDeliveryReceipts(messages)
+
 	if len(messages) > 0 {
 		log.Printf("Async messaging: retrieved %d pending obfuscated messages", len(messages))
 	}
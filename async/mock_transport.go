@@ -37,8 +37,8 @@ func (m MockAddr) String() string { return m.address }
 
 // NewMockTransport creates a new mock transport for testing
 func NewMockTransport(addr string) *MockTransport {
-	logrus.Warn("SIMULATION FUNCTION - NOT A REAL OPERATION")
-	logrus.WithFields(logrus.Fields{
+	pkgLog.Warn("SIMULATION FUNCTION - NOT A REAL OPERATION")
+	pkgLog.WithFields(logrus.Fields{
 		"function": "NewMockTransport",
 		"addr":     addr,
 	}).Info("Creating mock transport for testing")
@@ -51,7 +51,7 @@ func NewMockTransport(addr string) *MockTransport {
 		sendFunc:  func(packet *transport.Packet, addr net.Addr) error { return nil },
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":   "NewMockTransport",
 		"local_addr": localAddr.String(),
 	}).Info("Mock transport created successfully")
@@ -61,7 +61,7 @@ func NewMockTransport(addr string) *MockTransport {
 
 // Send implements Transport.Send
 func (m *MockTransport) Send(packet *transport.Packet, addr net.Addr) error {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":    "MockTransport.Send",
 		"packet_type": packet.PacketType,
 		"destination": addr.String(),
@@ -72,7 +72,7 @@ func (m *MockTransport) Send(packet *transport.Packet, addr net.Addr) error {
 	defer m.mu.Unlock()
 	m.packets = append(m.packets, MockPacketSend{packet: packet, addr: addr})
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"total_packets_sent": len(m.packets),
 	}).Debug("Packet added to mock transport history")
 
@@ -119,8 +119,8 @@ func (m *MockTransport) SetSendFunc(f func(packet *transport.Packet, addr net.Ad
 
 // SimulateReceive simulates receiving a packet from the network
 func (m *MockTransport) SimulateReceive(packet *transport.Packet, addr net.Addr) error {
-	logrus.Warn("SIMULATION FUNCTION - NOT A REAL OPERATION")
-	logrus.WithFields(logrus.Fields{
+	pkgLog.Warn("SIMULATION FUNCTION - NOT A REAL OPERATION")
+	pkgLog.WithFields(logrus.Fields{
 		"function":    "SimulateReceive",
 		"packet_type": packet.PacketType,
 		"source":      addr.String(),
@@ -132,24 +132,24 @@ func (m *MockTransport) SimulateReceive(packet *transport.Packet, addr net.Addr)
 	m.mu.Unlock()
 
 	if exists {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"packet_type": packet.PacketType,
 		}).Debug("Handler found, processing packet")
 		err := handler(packet, addr)
 		if err != nil {
-			logrus.WithFields(logrus.Fields{
+			pkgLog.WithFields(logrus.Fields{
 				"packet_type": packet.PacketType,
 				"error":       err.Error(),
 			}).Error("Packet handler returned error")
 			return err
 		}
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"packet_type": packet.PacketType,
 		}).Debug("Packet processed successfully")
 		return nil
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"packet_type": packet.PacketType,
 	}).Debug("No handler found for packet type")
 	return nil
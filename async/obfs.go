@@ -393,9 +393,9 @@ func (om *ObfuscationManager) CreateObfuscatedMessage(senderSK, recipientPK [32]
 
 // validateEpoch checks if the message epoch is within acceptable range.
 func (om *ObfuscationManager) validateEpoch(msgEpoch uint64) error {
-	if !om.epochManager.IsValidEpoch(msgEpoch) {
+	if !om.epochManager.IsValidEpoch(msgEpoch, DefaultEpochTolerance) {
 		currentEpoch := om.epochManager.GetCurrentEpoch()
-		return fmt.Errorf("invalid epoch %d: outside acceptable range (current: %d, max drift: 3)", msgEpoch, currentEpoch)
+		return fmt.Errorf("invalid epoch %d: outside acceptable range (current: %d, max drift: %d)", msgEpoch, currentEpoch, DefaultEpochTolerance)
 	}
 	return nil
 }
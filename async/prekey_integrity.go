@@ -0,0 +1,108 @@
+package async
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/opd-ai/toxcore/crypto"
+	"golang.org/x/crypto/hkdf"
+)
+
+// preKeyMACInfoLabel provides domain separation between the pre-key bundle
+// integrity key and the storage encryption key, which are both derived from
+// the identity key.
+const preKeyMACInfoLabel = "toxcore-async-prekey-mac-v1"
+
+// ErrPreKeyBundleIntegrity indicates a stored pre-key bundle failed its HMAC
+// check. Such bundles are discarded on load.
+var ErrPreKeyBundleIntegrity = errors.New("pre-key bundle integrity check failed")
+
+// storedPreKeyBundle is the plaintext of an encrypted bundle file: the
+// serialized bundle and an HMAC-SHA256 over it. Files written before the MAC
+// was introduced hold a bare PreKeyBundle and are re-saved in this form.
+type storedPreKeyBundle struct {
+	Bundle []byte `json:"bundle"`
+	MAC    []byte `json:"mac"`
+}
+
+// derivePreKeyMACKey derives the bundle integrity key from the identity
+// private key using HKDF-SHA256.
+func derivePreKeyMACKey(identityKey [32]byte) ([]byte, error) {
+	kdf := hkdf.New(sha256.New, identityKey[:], nil, []byte(preKeyMACInfoLabel))
+	key := make([]byte, 32)
+	if _, err := io.ReadFull(kdf, key); err != nil {
+		return nil, fmt.Errorf("failed to derive pre-key MAC key: %w", err)
+	}
+	return key, nil
+}
+
+// computeBundleMAC returns the HMAC-SHA256 of data under the store's MAC key.
+func (pks *PreKeyStore) computeBundleMAC(data []byte) []byte {
+	mac := hmac.New(sha256.New, pks.macKey)
+	mac.Write(data)
+	return mac.Sum(nil)
+}
+
+// sealBundle serializes bundle together with its MAC.
+func (pks *PreKeyStore) sealBundle(bundle *PreKeyBundle) ([]byte, error) {
+	data, err := json.Marshal(bundle)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal bundle: %w", err)
+	}
+	defer crypto.ZeroBytes(data)
+
+	sealed, err := json.Marshal(storedPreKeyBundle{Bundle: data, MAC: pks.computeBundleMAC(data)})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal bundle envelope: %w", err)
+	}
+	return sealed, nil
+}
+
+// openBundle parses a decrypted bundle file and verifies its MAC. It reports
+// legacy=true for a bare bundle written without a MAC, which the caller
+// should re-save so it gains one.
+func (pks *PreKeyStore) openBundle(data []byte) (bundle *PreKeyBundle, legacy bool, err error) {
+	var stored storedPreKeyBundle
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, false, fmt.Errorf("failed to unmarshal bundle: %w", err)
+	}
+
+	payload := stored.Bundle
+	if payload == nil {
+		payload, legacy = data, true
+	} else if !hmac.Equal(stored.MAC, pks.computeBundleMAC(payload)) {
+		return nil, false, ErrPreKeyBundleIntegrity
+	}
+
+	bundle = &PreKeyBundle{}
+	if err := json.Unmarshal(payload, bundle); err != nil {
+		return nil, false, fmt.Errorf("failed to unmarshal bundle: %w", err)
+	}
+	return bundle, legacy, nil
+}
+
+// Close securely wipes the private key material of every cached pre-key and
+// the bundle integrity key. Bundles already saved remain on disk and are
+// loaded again by the next PreKeyStore created for the same identity and
+// data directory. The store must not be used after Close; calling it more
+// than once is safe.
+func (pks *PreKeyStore) Close() error {
+	pks.mutex.Lock()
+	defer pks.mutex.Unlock()
+
+	for peerPK, bundle := range pks.bundles {
+		for i := range bundle.Keys {
+			if bundle.Keys[i].KeyPair != nil {
+				crypto.WipeKeyPair(bundle.Keys[i].KeyPair)
+			}
+		}
+		delete(pks.bundles, peerPK)
+	}
+	crypto.ZeroBytes(pks.macKey)
+	pks.macKey = nil
+	return nil
+}
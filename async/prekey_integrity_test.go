@@ -0,0 +1,183 @@
+package async
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/opd-ai/toxcore/crypto"
+)
+
+// newIntegrityTestStore creates a pre-key store in a fresh temporary directory.
+func newIntegrityTestStore(t *testing.T) (*PreKeyStore, *crypto.KeyPair, string) {
+	t.Helper()
+	dir := t.TempDir()
+	keyPair, err := crypto.GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("Failed to generate key pair: %v", err)
+	}
+	store, err := NewPreKeyStore(keyPair, dir)
+	if err != nil {
+		t.Fatalf("Failed to create pre-key store: %v", err)
+	}
+	return store, keyPair, dir
+}
+
+// bundlePathFor returns the on-disk path of peerPK's bundle.
+func bundlePathFor(dir string, peerPK [32]byte) string {
+	return filepath.Join(dir, "prekeys", fmt.Sprintf("%x.json.enc", peerPK))
+}
+
+// TestPreKeyStoreSurvivesRestart verifies bundles are reloaded with the same
+// key material after the store is closed and recreated.
+func TestPreKeyStoreSurvivesRestart(t *testing.T) {
+	store, keyPair, dir := newIntegrityTestStore(t)
+	peerPK := [32]byte{1, 2, 3}
+
+	bundle, err := store.GeneratePreKeys(peerPK)
+	if err != nil {
+		t.Fatalf("Failed to generate pre-keys: %v", err)
+	}
+	first := bundle.Keys[0]
+	wantID, wantPrivate := first.ID, first.KeyPair.Private
+
+	if err := store.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	reopened, err := NewPreKeyStore(keyPair, dir)
+	if err != nil {
+		t.Fatalf("Failed to reopen pre-key store: %v", err)
+	}
+	defer reopened.Close()
+
+	if got := reopened.GetRemainingKeyCount(peerPK); got != PreKeysPerPeer {
+		t.Fatalf("Expected %d keys after restart, got %d", PreKeysPerPeer, got)
+	}
+	key, err := reopened.GetPreKeyByID(peerPK, wantID)
+	if err != nil {
+		t.Fatalf("Pre-key %d not found after restart: %v", wantID, err)
+	}
+	if key.KeyPair.Private != wantPrivate {
+		t.Error("Pre-key private key changed across restart")
+	}
+}
+
+// TestPreKeyStoreDiscardsTamperedBundle verifies a bundle whose MAC does not
+// match is dropped on load and its file removed.
+func TestPreKeyStoreDiscardsTamperedBundle(t *testing.T) {
+	store, keyPair, dir := newIntegrityTestStore(t)
+	peerPK := [32]byte{4, 5, 6}
+
+	bundle, err := store.GeneratePreKeys(peerPK)
+	if err != nil {
+		t.Fatalf("Failed to generate pre-keys: %v", err)
+	}
+	sealed, err := store.sealBundle(bundle)
+	if err != nil {
+		t.Fatalf("Failed to seal bundle: %v", err)
+	}
+	store.Close()
+
+	var stored storedPreKeyBundle
+	if err := json.Unmarshal(sealed, &stored); err != nil {
+		t.Fatalf("Failed to parse sealed bundle: %v", err)
+	}
+	stored.MAC[0] ^= 0xFF
+	tampered, err := json.Marshal(stored)
+	if err != nil {
+		t.Fatalf("Failed to marshal tampered bundle: %v", err)
+	}
+	encrypted, err := encryptData(tampered, keyPair.Private[:])
+	if err != nil {
+		t.Fatalf("Failed to encrypt tampered bundle: %v", err)
+	}
+	path := bundlePathFor(dir, peerPK)
+	if err := os.WriteFile(path, encrypted, 0o600); err != nil {
+		t.Fatalf("Failed to write tampered bundle: %v", err)
+	}
+
+	reopened, err := NewPreKeyStore(keyPair, dir)
+	if err != nil {
+		t.Fatalf("Failed to reopen pre-key store: %v", err)
+	}
+	defer reopened.Close()
+
+	if got := reopened.GetRemainingKeyCount(peerPK); got != 0 {
+		t.Errorf("Expected tampered bundle to be discarded, got %d keys", got)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("Expected tampered bundle file to be removed, stat error: %v", err)
+	}
+}
+
+// TestPreKeyStoreUpgradesBundleWithoutMAC verifies bundles written before the
+// MAC was introduced are loaded and re-saved with one.
+func TestPreKeyStoreUpgradesBundleWithoutMAC(t *testing.T) {
+	store, keyPair, dir := newIntegrityTestStore(t)
+	peerPK := [32]byte{7, 8, 9}
+
+	bundle, err := store.GeneratePreKeys(peerPK)
+	if err != nil {
+		t.Fatalf("Failed to generate pre-keys: %v", err)
+	}
+	bare, err := json.Marshal(bundle)
+	if err != nil {
+		t.Fatalf("Failed to marshal bundle: %v", err)
+	}
+	store.Close()
+
+	encrypted, err := encryptData(bare, keyPair.Private[:])
+	if err != nil {
+		t.Fatalf("Failed to encrypt bundle: %v", err)
+	}
+	path := bundlePathFor(dir, peerPK)
+	if err := os.WriteFile(path, encrypted, 0o600); err != nil {
+		t.Fatalf("Failed to write bundle: %v", err)
+	}
+
+	reopened, err := NewPreKeyStore(keyPair, dir)
+	if err != nil {
+		t.Fatalf("Failed to reopen pre-key store: %v", err)
+	}
+	defer reopened.Close()
+
+	if got := reopened.GetRemainingKeyCount(peerPK); got != PreKeysPerPeer {
+		t.Fatalf("Expected %d keys from bundle without MAC, got %d", PreKeysPerPeer, got)
+	}
+
+	_, legacy, err := reopened.loadBundleFromDisk(path)
+	if err != nil {
+		t.Fatalf("Failed to load re-saved bundle: %v", err)
+	}
+	if legacy {
+		t.Error("Expected bundle to be re-saved with a MAC")
+	}
+}
+
+// TestPreKeyStoreCloseWipesKeys verifies Close zeroes cached private keys.
+func TestPreKeyStoreCloseWipesKeys(t *testing.T) {
+	store, _, _ := newIntegrityTestStore(t)
+	peerPK := [32]byte{10, 11, 12}
+
+	bundle, err := store.GeneratePreKeys(peerPK)
+	if err != nil {
+		t.Fatalf("Failed to generate pre-keys: %v", err)
+	}
+	cached := bundle.Keys[0].KeyPair
+
+	if err := store.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if cached.Private != [32]byte{} {
+		t.Error("Expected cached private key to be wiped on Close")
+	}
+	if got := store.GetRemainingKeyCount(peerPK); got != 0 {
+		t.Errorf("Expected no cached keys after Close, got %d", got)
+	}
+	if err := store.Close(); err != nil {
+		t.Errorf("Second Close failed: %v", err)
+	}
+}
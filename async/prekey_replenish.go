@@ -0,0 +1,484 @@
+package async
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/opd-ai/toxcore/transport"
+	"github.com/sirupsen/logrus"
+)
+
+// ReplenishmentCallback is called when an automatic pre-key replenishment
+// request to a peer completes. err is nil when the peer answered with a
+// fresh bundle, and non-nil when the request could not be sent or the peer
+// did not answer within PreKeyRequestTimeout. It is invoked outside of any
+// mutex and must not block.
+type ReplenishmentCallback func(peerPK [32]byte, err error)
+
+const (
+	// PreKeyReplenishCheckInterval is how often the replenishment routine
+	// scans every peer's pool for counts below PreKeyLowWatermark. Consuming
+	// a pre-key below the watermark also wakes the routine immediately.
+	PreKeyReplenishCheckInterval = time.Minute
+
+	// PreKeyRequestMinInterval is the minimum time between two pre-key
+	// requests to the same peer, so a slow or offline friend is not flooded.
+	PreKeyRequestMinInterval = 5 * time.Minute
+
+	// PreKeyRequestTimeout is how long a pre-key request may remain
+	// unanswered before it is reported as failed.
+	PreKeyRequestTimeout = 2 * time.Minute
+
+	// DefaultMaxRecoveryAttempts is the default number of concurrent pre-key
+	// requests AsyncManager sends when a friend's pool falls below
+	// PreKeyMinimum. See AsyncManagerConfig.MaxRecoveryAttempts.
+	DefaultMaxRecoveryAttempts = 3
+
+	// PreKeyRecoveryTimeout is how long a critical-zone recovery waits for a
+	// fresh bundle before reporting ErrPreKeysExhausted. It is shorter than
+	// PreKeyRequestTimeout because sends to the friend are already blocked.
+	PreKeyRecoveryTimeout = 30 * time.Second
+)
+
+var (
+	// ErrPreKeyRequestTimeout is reported to the ReplenishmentCallback when a
+	// peer does not answer a pre-key request within PreKeyRequestTimeout.
+	ErrPreKeyRequestTimeout = errors.New("pre-key request timed out")
+
+	// ErrPreKeysExhausted is reported when a friend's pre-key pool is below
+	// PreKeyMinimum and critical-zone recovery did not obtain a fresh bundle.
+	// Forward-secure messages to the friend cannot be sent until a pre-key
+	// exchange completes.
+	ErrPreKeysExhausted = errors.New("pre-keys exhausted")
+)
+
+// preKeyReplenisher tracks outstanding pre-key requests per peer.
+type preKeyReplenisher struct {
+	mu          sync.Mutex
+	sender      func(peerPK [32]byte) error
+	callback    ReplenishmentCallback
+	pending     map[[32]byte]time.Time // Peers with an unanswered request, by send time
+	lastRequest map[[32]byte]time.Time // Last request time per peer (rate limiting)
+	wake        chan struct{}          // Wakes the routine when a pool drops below the watermark
+
+	minInterval time.Duration
+	timeout     time.Duration
+
+	// Critical-zone recovery, disabled while recoveryAttempts is zero
+	recoveryAttempts int
+	recoveryTimeout  time.Duration
+	onExhausted      func(peerPK [32]byte, err error)
+	recovering       map[[32]byte]time.Time // Peers in recovery, by start time
+	exhaustedAt      map[[32]byte]time.Time // Last failed recovery per peer (rate limiting)
+}
+
+func newPreKeyReplenisher() *preKeyReplenisher {
+	return &preKeyReplenisher{
+		pending:     make(map[[32]byte]time.Time),
+		lastRequest: make(map[[32]byte]time.Time),
+		wake:        make(chan struct{}, 1),
+		minInterval: PreKeyRequestMinInterval,
+		timeout:     PreKeyRequestTimeout,
+
+		recoveryTimeout: PreKeyRecoveryTimeout,
+		recovering:      make(map[[32]byte]time.Time),
+		exhaustedAt:     make(map[[32]byte]time.Time),
+	}
+}
+
+// SetPreKeyRequestSender sets the function used to ask a peer for a fresh
+// pre-key bundle. AsyncManager installs one that sends a
+// PacketAsyncPreKeyRequest to the friend. Pass nil to disable automatic
+// replenishment.
+func (fsm *ForwardSecurityManager) SetPreKeyRequestSender(sender func(peerPK [32]byte) error) {
+	fsm.replenisher.mu.Lock()
+	defer fsm.replenisher.mu.Unlock()
+	fsm.replenisher.sender = sender
+}
+
+// SetReplenishmentCallback registers a callback fired when an automatic
+// pre-key request succeeds or fails. Pass nil to clear it.
+func (fsm *ForwardSecurityManager) SetReplenishmentCallback(callback ReplenishmentCallback) {
+	fsm.replenisher.mu.Lock()
+	defer fsm.replenisher.mu.Unlock()
+	fsm.replenisher.callback = callback
+}
+
+// setRecovery enables critical-zone recovery with the given number of
+// concurrent requests per peer (0 disables it) and sets the function that
+// receives ErrPreKeysExhausted failures.
+func (fsm *ForwardSecurityManager) setRecovery(attempts int, onExhausted func(peerPK [32]byte, err error)) {
+	fsm.replenisher.mu.Lock()
+	defer fsm.replenisher.mu.Unlock()
+	fsm.replenisher.recoveryAttempts = attempts
+	fsm.replenisher.onExhausted = onExhausted
+}
+
+// startReplenishmentRoutine starts the background goroutine that requests
+// fresh pre-keys from peers whose pool fell below PreKeyLowWatermark.
+// The goroutine exits when the manager is closed via Close().
+func (fsm *ForwardSecurityManager) startReplenishmentRoutine() {
+	fsm.cleanupWg.Add(1)
+	go func() {
+		defer fsm.cleanupWg.Done()
+
+		ticker := time.NewTicker(PreKeyReplenishCheckInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+			case <-fsm.replenisher.wake:
+			case <-fsm.stopCleanup:
+				return
+			}
+			fsm.replenishLowPeers()
+		}
+	}()
+}
+
+// wakeReplenisher schedules an immediate replenishment check without
+// blocking. Safe to call while holding peerPreKeysMutex.
+func (fsm *ForwardSecurityManager) wakeReplenisher() {
+	select {
+	case fsm.replenisher.wake <- struct{}{}:
+	default:
+	}
+}
+
+// replenishLowPeers fails requests that timed out and sends a new request to
+// every peer below the watermark that is not rate limited. Peers below
+// PreKeyMinimum enter critical-zone recovery when it is enabled.
+func (fsm *ForwardSecurityManager) replenishLowPeers() {
+	r := fsm.replenisher
+	now := time.Now()
+
+	fsm.peerPreKeysMutex.RLock()
+	var low [][32]byte
+	critical := make(map[[32]byte]bool)
+	for pk, keys := range fsm.peerPreKeys {
+		if len(keys) < PreKeyLowWatermark {
+			low = append(low, pk)
+		}
+		if len(keys) < PreKeyMinimum {
+			critical[pk] = true
+		}
+	}
+	fsm.peerPreKeysMutex.RUnlock()
+
+	r.mu.Lock()
+	failed := make(map[[32]byte]error)
+	exhausted := make(map[[32]byte]error)
+	for pk, startedAt := range r.recovering {
+		if now.Sub(startedAt) >= r.recoveryTimeout {
+			fsm.failRecoveryLocked(pk, now, exhausted, fmt.Errorf("%w: no pre-key bundle from peer %x after %d requests and %s",
+				ErrPreKeysExhausted, pk[:8], r.recoveryAttempts, r.recoveryTimeout))
+		}
+	}
+	for pk, sentAt := range r.pending {
+		if now.Sub(sentAt) >= r.timeout {
+			delete(r.pending, pk)
+			failed[pk] = fmt.Errorf("%w after %s", ErrPreKeyRequestTimeout, r.timeout)
+		}
+	}
+
+	sender := r.sender
+	var due, recover [][32]byte
+	for _, pk := range low {
+		if sender == nil {
+			break
+		}
+		if _, inRecovery := r.recovering[pk]; inRecovery {
+			continue
+		}
+		if critical[pk] && r.recoveryAttempts > 0 && !r.recoveryRateLimited(pk, now, exhausted) {
+			// Critical peers skip the pending and interval checks
+			r.recovering[pk] = now
+			r.pending[pk] = now
+			r.lastRequest[pk] = now
+			recover = append(recover, pk)
+			continue
+		}
+		if _, waiting := r.pending[pk]; waiting {
+			continue
+		}
+		if last, ok := r.lastRequest[pk]; ok && now.Sub(last) < r.minInterval {
+			continue
+		}
+		r.pending[pk] = now
+		r.lastRequest[pk] = now
+		due = append(due, pk)
+	}
+	attempts := r.recoveryAttempts
+	r.mu.Unlock()
+
+	for _, pk := range recover {
+		if err := fsm.startRecovery(pk, sender, attempts); err != nil {
+			r.mu.Lock()
+			fsm.failRecoveryLocked(pk, now, exhausted, err)
+			r.mu.Unlock()
+		}
+	}
+
+	for _, pk := range due {
+		pkgLog.WithFields(logrus.Fields{
+			"function":      "replenishLowPeers",
+			"peer":          fmt.Sprintf("%x", pk[:8]),
+			"low_watermark": PreKeyLowWatermark,
+		}).Info("Requesting fresh pre-keys from peer")
+
+		if err := sender(pk); err != nil {
+			r.mu.Lock()
+			delete(r.pending, pk)
+			r.mu.Unlock()
+			failed[pk] = fmt.Errorf("failed to send pre-key request: %w", err)
+		}
+	}
+
+	for pk, err := range failed {
+		pkgLog.WithFields(logrus.Fields{
+			"function": "replenishLowPeers",
+			"peer":     fmt.Sprintf("%x", pk[:8]),
+			"error":    err.Error(),
+		}).Warn("Pre-key replenishment failed")
+		fsm.notifyReplenishment(pk, err)
+	}
+	fsm.notifyExhausted(exhausted)
+}
+
+// recoveryRateLimited reports whether a recovery for pk failed within the
+// last PreKeyRequestMinInterval, including in the current pass. The caller
+// must hold r.mu.
+func (r *preKeyReplenisher) recoveryRateLimited(pk [32]byte, now time.Time, exhausted map[[32]byte]error) bool {
+	if _, justFailed := exhausted[pk]; justFailed {
+		return true
+	}
+	last, ok := r.exhaustedAt[pk]
+	return ok && now.Sub(last) < r.minInterval
+}
+
+// startRecovery sends attempts concurrent pre-key requests to pk, so a lost
+// packet does not stall recovery, and schedules a check for when the
+// recovery times out. It fails only if every request failed to send.
+// Bundles are signed by the friend itself, so every request goes to the
+// friend.
+func (fsm *ForwardSecurityManager) startRecovery(pk [32]byte, sender func(peerPK [32]byte) error, attempts int) error {
+	pkgLog.WithFields(logrus.Fields{
+		"function": "startRecovery",
+		"peer":     fmt.Sprintf("%x", pk[:8]),
+		"attempts": attempts,
+		"minimum":  PreKeyMinimum,
+	}).Warn("Pre-keys below minimum - starting recovery")
+
+	errs := make([]error, attempts)
+	var wg sync.WaitGroup
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = sender(pk)
+		}(i)
+	}
+	wg.Wait()
+
+	fsm.replenisher.mu.Lock()
+	timeout := fsm.replenisher.recoveryTimeout
+	fsm.replenisher.mu.Unlock()
+	time.AfterFunc(timeout, fsm.wakeReplenisher)
+
+	for _, err := range errs {
+		if err == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: failed to send pre-key request to peer %x: %w", ErrPreKeysExhausted, pk[:8], errors.Join(errs...))
+}
+
+// failRecoveryLocked ends the recovery of pk and records err for reporting.
+// The caller must hold fsm.replenisher.mu.
+func (fsm *ForwardSecurityManager) failRecoveryLocked(pk [32]byte, now time.Time, exhausted map[[32]byte]error, err error) {
+	r := fsm.replenisher
+	delete(r.recovering, pk)
+	delete(r.pending, pk)
+	r.exhaustedAt[pk] = now
+	exhausted[pk] = err
+}
+
+// notifyExhausted reports failed recoveries to the ReplenishmentCallback and
+// the exhaustion handler installed by setRecovery.
+func (fsm *ForwardSecurityManager) notifyExhausted(exhausted map[[32]byte]error) {
+	fsm.replenisher.mu.Lock()
+	onExhausted := fsm.replenisher.onExhausted
+	fsm.replenisher.mu.Unlock()
+
+	for pk, err := range exhausted {
+		pkgLog.WithFields(logrus.Fields{
+			"function": "notifyExhausted",
+			"peer":     fmt.Sprintf("%x", pk[:8]),
+			"error":    err.Error(),
+		}).Warn("Pre-key recovery failed")
+		fsm.notifyReplenishment(pk, err)
+		if onExhausted != nil {
+			onExhausted(pk, err)
+		}
+	}
+}
+
+// completeReplenishment reports success if a request to peerPK was pending.
+// Called after a pre-key bundle from peerPK has been accepted.
+func (fsm *ForwardSecurityManager) completeReplenishment(peerPK [32]byte) {
+	r := fsm.replenisher
+	r.mu.Lock()
+	_, waiting := r.pending[peerPK]
+	delete(r.pending, peerPK)
+	delete(r.recovering, peerPK)
+	delete(r.exhaustedAt, peerPK)
+	r.mu.Unlock()
+
+	if waiting {
+		fsm.notifyReplenishment(peerPK, nil)
+	}
+}
+
+// notifyReplenishment invokes the ReplenishmentCallback, if any.
+func (fsm *ForwardSecurityManager) notifyReplenishment(peerPK [32]byte, err error) {
+	fsm.replenisher.mu.Lock()
+	callback := fsm.replenisher.callback
+	fsm.replenisher.mu.Unlock()
+
+	if callback != nil {
+		callback(peerPK, err)
+	}
+}
+
+// PacketAsyncPreKeyRequest payload: magic(4) + version(1) + sender_pk(32).
+const (
+	preKeyRequestMagic   = "PKRQ"
+	preKeyRequestVersion = 1
+	preKeyRequestSize    = 4 + 1 + 32
+
+	// preKeyResponseMinInterval is the minimum time between two bundles sent
+	// to the same friend in answer to pre-key requests.
+	preKeyResponseMinInterval = time.Minute
+)
+
+// errorChannelSize is the number of undelivered errors ErrorChannel buffers
+// before further errors are dropped.
+const errorChannelSize = 16
+
+// ErrorChannel returns a channel of errors from background operations that
+// have no caller to return to. It currently carries ErrPreKeysExhausted
+// (wrapped, use errors.Is) when a friend's pre-keys fell below PreKeyMinimum
+// and recovery failed, so applications can show a "waiting for key
+// exchange" state instead of failing sends silently. Errors are dropped
+// while the channel is full.
+func (am *AsyncManager) ErrorChannel() <-chan error {
+	return am.errorCh
+}
+
+// reportPreKeysExhausted publishes a failed recovery on the error channel.
+func (am *AsyncManager) reportPreKeysExhausted(friendPK [32]byte, err error) {
+	select {
+	case am.errorCh <- err:
+	default:
+		pkgLog.WithFields(logrus.Fields{
+			"function": "reportPreKeysExhausted",
+			"friend":   fmt.Sprintf("%x", friendPK[:8]),
+		}).Warn("Error channel full, dropping pre-key exhaustion error")
+	}
+}
+
+// SetPreKeyReplenishmentCallback registers a callback fired when an automatic
+// pre-key request to a friend succeeds or fails. Pass nil to clear it.
+func (am *AsyncManager) SetPreKeyReplenishmentCallback(callback ReplenishmentCallback) {
+	am.forwardSecurity.SetReplenishmentCallback(callback)
+}
+
+// sendPreKeyRequest asks a friend to send us a fresh pre-key bundle.
+func (am *AsyncManager) sendPreKeyRequest(friendPK [32]byte) error {
+	am.mutex.RLock()
+	friendAddr, ok := am.friendAddresses[friendPK]
+	am.mutex.RUnlock()
+
+	if !ok {
+		return fmt.Errorf("no address known for friend %x", friendPK[:8])
+	}
+	if am.client.transport == nil {
+		return fmt.Errorf("transport not available")
+	}
+
+	data := make([]byte, preKeyRequestSize)
+	copy(data, preKeyRequestMagic)
+	data[4] = preKeyRequestVersion
+	copy(data[5:], am.keyPair.Public[:])
+
+	return am.client.transport.Send(&transport.Packet{
+		PacketType: transport.PacketAsyncPreKeyRequest,
+		Data:       data,
+	}, friendAddr)
+}
+
+// handlePreKeyRequestPacket answers a friend's pre-key request with a fresh
+// bundle. The request is unauthenticated, so the bundle is always sent to the
+// friend's known address rather than the packet source, and at most once per
+// preKeyResponseMinInterval; a forged request can therefore only make us
+// resend our public pre-keys to the real friend.
+func (am *AsyncManager) handlePreKeyRequestPacket(packet *transport.Packet, _ net.Addr) {
+	data := packet.Data
+	if len(data) != preKeyRequestSize || string(data[:4]) != preKeyRequestMagic || data[4] != preKeyRequestVersion {
+		pkgLog.WithFields(logrus.Fields{
+			"function": "handlePreKeyRequestPacket",
+			"size":     len(data),
+		}).Debug("Dropping malformed pre-key request")
+		return
+	}
+
+	var senderPK [32]byte
+	copy(senderPK[:], data[5:])
+
+	am.mutex.Lock()
+	_, isKnownFriend := am.friendAddresses[senderPK]
+	last, answered := am.preKeyResponses[senderPK]
+	rateLimited := answered && time.Since(last) < preKeyResponseMinInterval
+	if isKnownFriend && !rateLimited {
+		am.preKeyResponses[senderPK] = time.Now()
+	}
+	am.mutex.Unlock()
+
+	if !isKnownFriend {
+		pkgLog.WithFields(logrus.Fields{
+			"function": "handlePreKeyRequestPacket",
+			"sender":   fmt.Sprintf("%x", senderPK[:8]),
+		}).Debug("Rejected pre-key request from unknown sender")
+		return
+	}
+	if rateLimited {
+		pkgLog.WithFields(logrus.Fields{
+			"function": "handlePreKeyRequestPacket",
+			"sender":   fmt.Sprintf("%x", senderPK[:8]),
+		}).Debug("Pre-key request rate limited")
+		return
+	}
+
+	exchange, err := am.forwardSecurity.ExchangePreKeys(senderPK)
+	if err == nil {
+		err = am.sendPreKeyExchange(senderPK, exchange)
+	}
+	if err != nil {
+		pkgLog.WithFields(logrus.Fields{
+			"function": "handlePreKeyRequestPacket",
+			"sender":   fmt.Sprintf("%x", senderPK[:8]),
+			"error":    err.Error(),
+		}).Warn("Failed to answer pre-key request")
+		return
+	}
+
+	pkgLog.WithFields(logrus.Fields{
+		"function": "handlePreKeyRequestPacket",
+		"sender":   fmt.Sprintf("%x", senderPK[:8]),
+		"pre_keys": len(exchange.PreKeys),
+	}).Info("Sent fresh pre-keys in answer to request")
+}
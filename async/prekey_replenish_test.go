@@ -0,0 +1,331 @@
+package async
+
+import (
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/opd-ai/toxcore/crypto"
+	"github.com/opd-ai/toxcore/transport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// replenishResult records ReplenishmentCallback invocations.
+type replenishResult struct {
+	mu      sync.Mutex
+	results map[[32]byte][]error
+}
+
+func (r *replenishResult) callback(peerPK [32]byte, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.results[peerPK] = append(r.results[peerPK], err)
+}
+
+func (r *replenishResult) get(peerPK [32]byte) []error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.results[peerPK]
+}
+
+func newReplenishTestManager(t *testing.T) (*ForwardSecurityManager, *replenishResult) {
+	t.Helper()
+	keyPair, err := crypto.GenerateKeyPair()
+	require.NoError(t, err)
+	fsm, err := NewForwardSecurityManagerWithInterval(keyPair, t.TempDir(), 0)
+	require.NoError(t, err)
+	t.Cleanup(func() { fsm.Close() })
+
+	result := &replenishResult{results: make(map[[32]byte][]error)}
+	fsm.SetReplenishmentCallback(result.callback)
+	return fsm, result
+}
+
+func testPreKeys(n int, firstID uint32) []PreKeyForExchange {
+	keys := make([]PreKeyForExchange, n)
+	for i := range keys {
+		keys[i] = PreKeyForExchange{ID: firstID + uint32(i)}
+	}
+	return keys
+}
+
+func TestReplenishmentRequestsLowPeers(t *testing.T) {
+	fsm, result := newReplenishTestManager(t)
+	lowPeer, fullPeer := [32]byte{1}, [32]byte{2}
+	fsm.peerPreKeys[lowPeer] = testPreKeys(PreKeyLowWatermark-1, 0)
+	fsm.peerPreKeys[fullPeer] = testPreKeys(PreKeyLowWatermark, 0)
+
+	var requested [][32]byte
+	fsm.SetPreKeyRequestSender(func(peerPK [32]byte) error {
+		requested = append(requested, peerPK)
+		return nil
+	})
+
+	fsm.replenishLowPeers()
+	assert.Equal(t, [][32]byte{lowPeer}, requested)
+
+	// An outstanding request is not repeated
+	fsm.replenishLowPeers()
+	assert.Len(t, requested, 1)
+
+	require.NoError(t, fsm.ProcessPreKeyExchange(&PreKeyExchangeMessage{
+		SenderPK: lowPeer,
+		PreKeys:  testPreKeys(PreKeysPerPeer, 1000),
+	}))
+	assert.Equal(t, []error{nil}, result.get(lowPeer))
+	assert.Empty(t, result.get(fullPeer))
+	assert.Equal(t, PreKeyLowWatermark-1+PreKeysPerPeer, fsm.GetAvailableKeyCount(lowPeer))
+
+	// A bundle nobody asked for does not fire the callback
+	require.NoError(t, fsm.ProcessPreKeyExchange(&PreKeyExchangeMessage{
+		SenderPK: fullPeer,
+		PreKeys:  testPreKeys(1, 5000),
+	}))
+	assert.Empty(t, result.get(fullPeer))
+}
+
+func TestReplenishmentRateLimit(t *testing.T) {
+	fsm, _ := newReplenishTestManager(t)
+	peer := [32]byte{1}
+	fsm.peerPreKeys[peer] = testPreKeys(1, 0)
+
+	requests := 0
+	fsm.SetPreKeyRequestSender(func([32]byte) error {
+		requests++
+		return nil
+	})
+
+	fsm.replenishLowPeers()
+	fsm.completeReplenishment(peer)
+	// Still below the watermark, but within PreKeyRequestMinInterval
+	fsm.replenishLowPeers()
+	assert.Equal(t, 1, requests)
+
+	fsm.replenisher.mu.Lock()
+	fsm.replenisher.lastRequest[peer] = time.Now().Add(-PreKeyRequestMinInterval)
+	fsm.replenisher.mu.Unlock()
+	fsm.replenishLowPeers()
+	assert.Equal(t, 2, requests)
+}
+
+func TestReplenishmentTimeout(t *testing.T) {
+	fsm, result := newReplenishTestManager(t)
+	peer := [32]byte{1}
+	fsm.peerPreKeys[peer] = testPreKeys(1, 0)
+	fsm.SetPreKeyRequestSender(func([32]byte) error { return nil })
+	fsm.replenisher.timeout = 0
+
+	fsm.replenishLowPeers()
+	assert.Empty(t, result.get(peer))
+
+	fsm.replenishLowPeers()
+	errs := result.get(peer)
+	require.Len(t, errs, 1)
+	assert.ErrorIs(t, errs[0], ErrPreKeyRequestTimeout)
+}
+
+func TestReplenishmentSendFailure(t *testing.T) {
+	fsm, result := newReplenishTestManager(t)
+	peer := [32]byte{1}
+	fsm.peerPreKeys[peer] = testPreKeys(1, 0)
+
+	sendErr := errors.New("unreachable")
+	fsm.SetPreKeyRequestSender(func([32]byte) error { return sendErr })
+	fsm.replenishLowPeers()
+
+	errs := result.get(peer)
+	require.Len(t, errs, 1)
+	assert.ErrorIs(t, errs[0], sendErr)
+}
+
+func TestReplenishmentWakesOnConsume(t *testing.T) {
+	fsm, _ := newReplenishTestManager(t)
+	peer := [32]byte{1}
+	fsm.peerPreKeys[peer] = testPreKeys(PreKeyLowWatermark, 0)
+
+	requested := make(chan [32]byte, 1)
+	fsm.SetPreKeyRequestSender(func(peerPK [32]byte) error {
+		requested <- peerPK
+		return nil
+	})
+
+	fsm.peerPreKeysMutex.Lock()
+	_, err := fsm.consumePreKey(peer, fsm.peerPreKeys[peer])
+	fsm.peerPreKeysMutex.Unlock()
+	require.NoError(t, err)
+
+	select {
+	case pk := <-requested:
+		assert.Equal(t, peer, pk)
+	case <-time.After(5 * time.Second):
+		t.Fatal("replenishment routine did not request pre-keys")
+	}
+}
+
+func TestAsyncManagerPreKeyRequestRoundTrip(t *testing.T) {
+	aliceKeys, err := crypto.GenerateKeyPair()
+	require.NoError(t, err)
+	bobKeys, err := crypto.GenerateKeyPair()
+	require.NoError(t, err)
+
+	aliceTransport := NewMockTransport("127.0.0.1:33445")
+	bobTransport := NewMockTransport("127.0.0.1:33446")
+	alice, err := NewAsyncManager(aliceKeys, aliceTransport, t.TempDir())
+	require.NoError(t, err)
+	bob, err := NewAsyncManager(bobKeys, bobTransport, t.TempDir())
+	require.NoError(t, err)
+
+	aliceAddr := &MockAddr{network: "mock", address: "alice.node:33445"}
+	bobAddr := &MockAddr{network: "mock", address: "bob.node:33446"}
+	alice.SetFriendAddress(bobKeys.Public, bobAddr)
+	bob.SetFriendAddress(aliceKeys.Public, aliceAddr)
+
+	// relay delivers the last packet sent on from to the peer's transport.
+	relay := func(from, to *MockTransport, source net.Addr) transport.PacketType {
+		packets := from.GetPackets()
+		require.NotEmpty(t, packets)
+		last := packets[len(packets)-1]
+		require.NoError(t, to.SimulateReceive(last.packet, source))
+		return last.packet.PacketType
+	}
+
+	result := &replenishResult{results: make(map[[32]byte][]error)}
+	alice.SetPreKeyReplenishmentCallback(result.callback)
+	alice.forwardSecurity.peerPreKeys[bobKeys.Public] = testPreKeys(PreKeyMinimum, 0)
+
+	alice.forwardSecurity.replenishLowPeers()
+	assert.Equal(t, transport.PacketAsyncPreKeyRequest, relay(aliceTransport, bobTransport, aliceAddr))
+	assert.Equal(t, transport.PacketAsyncPreKeyExchange, relay(bobTransport, aliceTransport, bobAddr))
+
+	assert.Equal(t, []error{nil}, result.get(bobKeys.Public))
+	assert.Greater(t, alice.forwardSecurity.GetAvailableKeyCount(bobKeys.Public), PreKeyLowWatermark)
+
+	// Bob does not answer again within preKeyResponseMinInterval
+	sent := len(bobTransport.GetPackets())
+	require.NoError(t, alice.sendPreKeyRequest(bobKeys.Public))
+	relay(aliceTransport, bobTransport, aliceAddr)
+	assert.Len(t, bobTransport.GetPackets(), sent)
+}
+
+func TestPreKeyRequestRejectsUnknownAndMalformed(t *testing.T) {
+	keys, err := crypto.GenerateKeyPair()
+	require.NoError(t, err)
+	mockTransport := NewMockTransport("127.0.0.1:33445")
+	am, err := NewAsyncManager(keys, mockTransport, t.TempDir())
+	require.NoError(t, err)
+
+	stranger := [32]byte{9}
+	request := make([]byte, preKeyRequestSize)
+	copy(request, preKeyRequestMagic)
+	request[4] = preKeyRequestVersion
+	copy(request[5:], stranger[:])
+
+	addr := &MockAddr{network: "mock", address: "stranger:1"}
+	am.handlePreKeyRequestPacket(&transport.Packet{PacketType: transport.PacketAsyncPreKeyRequest, Data: request}, addr)
+	assert.Empty(t, mockTransport.GetPackets(), "unknown sender should be ignored")
+
+	am.SetFriendAddress(stranger, addr)
+	am.handlePreKeyRequestPacket(&transport.Packet{PacketType: transport.PacketAsyncPreKeyRequest, Data: request[:10]}, addr)
+	assert.Empty(t, mockTransport.GetPackets(), "malformed request should be ignored")
+
+	am.handlePreKeyRequestPacket(&transport.Packet{PacketType: transport.PacketAsyncPreKeyRequest, Data: request}, addr)
+	packets := mockTransport.GetPackets()
+	require.Len(t, packets, 1)
+	assert.Equal(t, transport.PacketAsyncPreKeyExchange, packets[0].packet.PacketType)
+	assert.Equal(t, addr, packets[0].addr)
+}
+
+func TestRecoveryCriticalPeer(t *testing.T) {
+	fsm, result := newReplenishTestManager(t)
+	peer := [32]byte{1}
+	fsm.peerPreKeys[peer] = testPreKeys(PreKeyMinimum-1, 0)
+
+	var exhausted []error
+	fsm.setRecovery(3, func(_ [32]byte, err error) { exhausted = append(exhausted, err) })
+
+	var mu sync.Mutex
+	requests := 0
+	fsm.SetPreKeyRequestSender(func([32]byte) error {
+		mu.Lock()
+		defer mu.Unlock()
+		requests++
+		return nil
+	})
+
+	// A peer rate limited for normal requests still gets a recovery
+	fsm.replenisher.mu.Lock()
+	fsm.replenisher.lastRequest[peer] = time.Now()
+	fsm.replenisher.mu.Unlock()
+
+	fsm.replenishLowPeers()
+	assert.Equal(t, 3, requests, "recovery should send concurrent requests")
+
+	// A recovery in progress is not restarted
+	fsm.replenishLowPeers()
+	assert.Equal(t, 3, requests)
+
+	fsm.replenisher.mu.Lock()
+	fsm.replenisher.recoveryTimeout = 0
+	fsm.replenisher.mu.Unlock()
+	fsm.replenishLowPeers()
+	require.Len(t, exhausted, 1)
+	assert.ErrorIs(t, exhausted[0], ErrPreKeysExhausted)
+	require.Len(t, result.get(peer), 1)
+	assert.ErrorIs(t, result.get(peer)[0], ErrPreKeysExhausted)
+
+	// A failed recovery is retried only after PreKeyRequestMinInterval
+	fsm.replenishLowPeers()
+	assert.Equal(t, 3, requests)
+	assert.Len(t, exhausted, 1)
+}
+
+func TestRecoverySucceeds(t *testing.T) {
+	fsm, result := newReplenishTestManager(t)
+	peer := [32]byte{1}
+	fsm.peerPreKeys[peer] = testPreKeys(1, 0)
+
+	var exhausted []error
+	fsm.setRecovery(2, func(_ [32]byte, err error) { exhausted = append(exhausted, err) })
+	fsm.SetPreKeyRequestSender(func([32]byte) error { return nil })
+
+	fsm.replenishLowPeers()
+	require.NoError(t, fsm.ProcessPreKeyExchange(&PreKeyExchangeMessage{
+		SenderPK: peer,
+		PreKeys:  testPreKeys(PreKeysPerPeer, 1000),
+	}))
+	assert.Equal(t, []error{nil}, result.get(peer))
+
+	fsm.replenisher.mu.Lock()
+	fsm.replenisher.recoveryTimeout = 0
+	fsm.replenisher.mu.Unlock()
+	fsm.replenishLowPeers()
+	assert.Empty(t, exhausted)
+}
+
+func TestAsyncManagerErrorChannel(t *testing.T) {
+	keys, err := crypto.GenerateKeyPair()
+	require.NoError(t, err)
+	am, err := NewAsyncManager(keys, NewMockTransport("127.0.0.1:33445"), t.TempDir())
+	require.NoError(t, err)
+
+	// No address is known for the friend, so every recovery request fails
+	friend := [32]byte{7}
+	am.forwardSecurity.peerPreKeysMutex.Lock()
+	am.forwardSecurity.peerPreKeys[friend] = testPreKeys(1, 0)
+	am.forwardSecurity.peerPreKeysMutex.Unlock()
+	am.forwardSecurity.replenishLowPeers()
+
+	select {
+	case err := <-am.ErrorChannel():
+		assert.ErrorIs(t, err, ErrPreKeysExhausted)
+	default:
+		t.Fatal("expected ErrPreKeysExhausted on the error channel")
+	}
+
+	disabled, err := NewAsyncManagerWithConfig(keys, NewMockTransport("127.0.0.1:33446"), t.TempDir(), &AsyncManagerConfig{MaxRecoveryAttempts: -1})
+	require.NoError(t, err)
+	assert.Zero(t, disabled.forwardSecurity.replenisher.recoveryAttempts)
+}
@@ -2,7 +2,7 @@ package async
 
 import (
 	"crypto/rand"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/opd-ai/toxcore/crypto"
+	"github.com/sirupsen/logrus"
 )
 
 // PreKeyBundle represents a collection of one-time keys for a specific peer
@@ -37,6 +38,7 @@ type PreKeyStore struct {
 	dataDir string
 	keyPair *crypto.KeyPair            // Our main identity key
 	bundles map[[32]byte]*PreKeyBundle // In-memory cache of pre-key bundles
+	macKey  []byte                     // HKDF-derived key for bundle integrity checks
 }
 
 // PreKeyRefreshMessage is sent when peers are online to refresh pre-keys
@@ -135,16 +137,25 @@ const (
 	SignedPreKeyRotationInterval = 7 * 24 * time.Hour
 )
 
-// NewPreKeyStore creates a new pre-key storage manager
+// NewPreKeyStore creates a new pre-key storage manager. Bundles are kept
+// encrypted under dataDir/prekeys and loaded again on construction, so
+// pre-keys survive restarts; bundles that fail their integrity check are
+// discarded. Call Close to wipe the cached key material.
 func NewPreKeyStore(keyPair *crypto.KeyPair, dataDir string) (*PreKeyStore, error) {
 	if err := os.MkdirAll(dataDir, 0o755); err != nil {
 		return nil, fmt.Errorf("failed to create data directory: %w", err)
 	}
 
+	macKey, err := derivePreKeyMACKey(keyPair.Private)
+	if err != nil {
+		return nil, err
+	}
+
 	store := &PreKeyStore{
 		dataDir: dataDir,
 		keyPair: keyPair,
 		bundles: make(map[[32]byte]*PreKeyBundle),
+		macKey:  macKey,
 	}
 
 	// Load existing bundles from disk
@@ -422,7 +433,8 @@ func (pks *PreKeyStore) loadBundles() error {
 	return pks.processBundleEntries(entries, preKeyDir)
 }
 
-// saveBundleToDisk saves a pre-key bundle to disk with encryption
+// saveBundleToDisk saves a pre-key bundle to disk with its MAC, encrypted
+// with AES-256-GCM under a key derived from the identity key
 func (pks *PreKeyStore) saveBundleToDisk(bundle *PreKeyBundle) error {
 	preKeyDir := filepath.Join(pks.dataDir, "prekeys")
 	if err := os.MkdirAll(preKeyDir, 0o755); err != nil {
@@ -432,11 +444,11 @@ func (pks *PreKeyStore) saveBundleToDisk(bundle *PreKeyBundle) error {
 	filename := fmt.Sprintf("%x.json.enc", bundle.PeerPK)
 	bundlePath := filepath.Join(preKeyDir, filename)
 
-	// Marshal the data
-	data, err := json.MarshalIndent(bundle, "", "  ")
+	data, err := pks.sealBundle(bundle)
 	if err != nil {
-		return fmt.Errorf("failed to marshal bundle: %w", err)
+		return err
 	}
+	defer crypto.ZeroBytes(data)
 
 	// Encrypt the data using our identity key as the encryption key
 	encryptedData, err := encryptData(data, pks.keyPair.Private[:])
@@ -452,11 +464,12 @@ func (pks *PreKeyStore) saveBundleToDisk(bundle *PreKeyBundle) error {
 	return nil
 }
 
-// loadBundleFromDisk loads a pre-key bundle from disk and decrypts it
-func (pks *PreKeyStore) loadBundleFromDisk(bundlePath string) (*PreKeyBundle, error) {
+// loadBundleFromDisk loads a pre-key bundle from disk, decrypts it, and
+// verifies its MAC. legacy reports a bundle written without a MAC.
+func (pks *PreKeyStore) loadBundleFromDisk(bundlePath string) (bundle *PreKeyBundle, legacy bool, err error) {
 	encryptedData, err := os.ReadFile(bundlePath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read bundle file: %w", err)
+		return nil, false, fmt.Errorf("failed to read bundle file: %w", err)
 	}
 
 	// Check if the file is encrypted (has .enc extension)
@@ -467,19 +480,15 @@ func (pks *PreKeyStore) loadBundleFromDisk(bundlePath string) (*PreKeyBundle, er
 		// Decrypt the data
 		data, err = decryptData(encryptedData, pks.keyPair.Private[:])
 		if err != nil {
-			return nil, fmt.Errorf("failed to decrypt bundle file: %w", err)
+			return nil, false, fmt.Errorf("failed to decrypt bundle file: %w", err)
 		}
+		defer crypto.ZeroBytes(data)
 	} else {
 		// Handle legacy unencrypted files (for backward compatibility)
 		data = encryptedData
 	}
 
-	var bundle PreKeyBundle
-	if err := json.Unmarshal(data, &bundle); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal bundle: %w", err)
-	}
-
-	return &bundle, nil
+	return pks.openBundle(data)
 }
 
 // removeBundleFromDisk removes a pre-key bundle file from disk
@@ -694,13 +703,16 @@ func (pks *PreKeyStore) processBundleEntries(entries []os.DirEntry, preKeyDir st
 // processBundleFile loads a single bundle file and handles conversion if needed.
 func (pks *PreKeyStore) processBundleFile(bundlePath, ext string) error {
 	// Load the bundle
-	bundle, err := pks.loadBundleFromDisk(bundlePath)
+	bundle, legacy, err := pks.loadBundleFromDisk(bundlePath)
 	if err != nil {
 		// Silently skip bundles that fail authentication - they belong to different identities
 		// This is common when running tests with different key pairs
 		if strings.Contains(err.Error(), "cipher: message authentication failed") {
 			return nil // Skip silently - bundle belongs to a different identity
 		}
+		if errors.Is(err, ErrPreKeyBundleIntegrity) {
+			return pks.discardBundleFile(bundlePath)
+		}
 		return fmt.Errorf("failed to load bundle %s: %w", filepath.Base(bundlePath), err)
 	}
 
@@ -712,6 +724,26 @@ func (pks *PreKeyStore) processBundleFile(bundlePath, ext string) error {
 		return pks.convertLegacyBundle(bundle, bundlePath)
 	}
 
+	// Re-save bundles written before the MAC was introduced
+	if legacy {
+		if err := pks.saveBundleToDisk(bundle); err != nil {
+			return fmt.Errorf("failed to re-save bundle with MAC: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// discardBundleFile removes a bundle file that failed its integrity check.
+func (pks *PreKeyStore) discardBundleFile(bundlePath string) error {
+	pkgLog.WithFields(logrus.Fields{
+		"function": "processBundleFile",
+		"file":     filepath.Base(bundlePath),
+	}).Warn("Discarding pre-key bundle that failed integrity check")
+
+	if err := os.Remove(bundlePath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove corrupted bundle %s: %w", filepath.Base(bundlePath), err)
+	}
 	return nil
 }
 
@@ -122,7 +122,7 @@ func NewNotificationHub(config *NotificationConfig) *NotificationHub {
 		cancel:      cancel,
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":         "NewNotificationHub",
 		"max_pending":      config.MaxPendingNotifications,
 		"timeout":          config.NotificationTimeout,
@@ -163,7 +163,7 @@ func (h *NotificationHub) Subscribe(publicKey [32]byte, handler NotificationHand
 	h.wg.Add(1)
 	go h.deliveryLoop(subscriber)
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":   "Subscribe",
 		"public_key": formatKey(publicKey),
 	}).Debug("Subscriber registered")
@@ -184,7 +184,7 @@ func (h *NotificationHub) Unsubscribe(publicKey [32]byte) {
 		delete(h.subscribers, publicKey)
 		h.stats.ActiveSubscribers.Add(-1)
 
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":   "Unsubscribe",
 			"public_key": formatKey(publicKey),
 		}).Debug("Subscriber unregistered")
@@ -211,7 +211,7 @@ func (h *NotificationHub) Notify(notification *Notification) (delivered bool) {
 		// Queue full, drop notification
 		subscriber.Dropped.Add(1)
 		h.stats.TotalDropped.Add(1)
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":   "Notify",
 			"recipient":  formatKey(notification.RecipientPK),
 			"queue_full": true,
@@ -378,7 +378,7 @@ func (h *NotificationHub) deliverNotification(sub *Subscriber, notification *Not
 		lastErr = err
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":  "deliverNotification",
 		"recipient": formatKey(sub.PublicKey),
 		"error":     lastErr.Error(),
@@ -409,7 +409,7 @@ func (h *NotificationHub) Start() {
 		return
 	}
 
-	logrus.WithField("function", "Start").Info("Notification hub started")
+	pkgLog.WithField("function", "Start").Info("Notification hub started")
 }
 
 // Stop stops the notification hub and waits for pending deliveries.
@@ -423,7 +423,7 @@ func (h *NotificationHub) Stop() {
 	h.cancel()
 	h.wg.Wait()
 
-	logrus.WithField("function", "Stop").Info("Notification hub stopped")
+	pkgLog.WithField("function", "Stop").Info("Notification hub stopped")
 }
 
 // SubscriberCount returns the number of active subscribers.
@@ -467,7 +467,7 @@ func (am *AsyncManager) EnablePushNotifications(config *NotificationConfig) {
 		am.notificationHub.Start()
 	}
 
-	logrus.WithField("function", "EnablePushNotifications").Info("Push notifications enabled")
+	pkgLog.WithField("function", "EnablePushNotifications").Info("Push notifications enabled")
 }
 
 // DisablePushNotifications disables push-based notifications.
@@ -0,0 +1,191 @@
+package async
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultReplicationFactor is the number of storage nodes each message is
+// replicated to when erasure coding is disabled.
+const DefaultReplicationFactor = 3
+
+// retrievalNodeCount is the minimum number of storage nodes queried per epoch
+// when retrieving messages.
+const retrievalNodeCount = 5
+
+// ErrInvalidReplicationFactor is returned by SetReplicationFactor for a
+// factor below one.
+var ErrInvalidReplicationFactor = errors.New("replication factor must be at least 1")
+
+// SetReplicationFactor sets how many storage nodes each message is stored on
+// when erasure coding is disabled. Replicas go to the n nodes closest to the
+// recipient pseudonym by XOR distance; a node that rejects the write is
+// replaced by the next-closest one. A message therefore survives the failure
+// of n-1 of its nodes. Erasure-coded storage has its own redundancy and is
+// not affected.
+func (ac *AsyncClient) SetReplicationFactor(n int) error {
+	if n < 1 {
+		return fmt.Errorf("%w: got %d", ErrInvalidReplicationFactor, n)
+	}
+	ac.mutex.Lock()
+	defer ac.mutex.Unlock()
+	ac.replicationFactor = n
+	return nil
+}
+
+// GetReplicationFactor returns the number of storage nodes each message is
+// replicated to when erasure coding is disabled.
+func (ac *AsyncClient) GetReplicationFactor() int {
+	ac.mutex.RLock()
+	defer ac.mutex.RUnlock()
+	return ac.replicationFactorLocked()
+}
+
+// replicationFactorLocked returns the configured factor, falling back to the
+// default for clients not built with NewAsyncClient. Callers must hold
+// ac.mutex.
+func (ac *AsyncClient) replicationFactorLocked() int {
+	if ac.replicationFactor < 1 {
+		return DefaultReplicationFactor
+	}
+	return ac.replicationFactor
+}
+
+// xorDistance returns the Kademlia XOR distance between two keys.
+func xorDistance(a, b [32]byte) [32]byte {
+	var d [32]byte
+	for i := range d {
+		d[i] = a[i] ^ b[i]
+	}
+	return d
+}
+
+// rankNodesByXORDistance orders storage nodes from closest to furthest from
+// target by XOR distance of their public keys, as the DHT routing table does.
+func rankNodesByXORDistance(target [32]byte, nodes map[[32]byte]net.Addr) []net.Addr {
+	type ranked struct {
+		addr     net.Addr
+		distance [32]byte
+	}
+	candidates := make([]ranked, 0, len(nodes))
+	for pk, addr := range nodes {
+		candidates = append(candidates, ranked{addr: addr, distance: xorDistance(target, pk)})
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return bytes.Compare(candidates[i].distance[:], candidates[j].distance[:]) < 0
+	})
+
+	addrs := make([]net.Addr, len(candidates))
+	for i, c := range candidates {
+		addrs[i] = c.addr
+	}
+	return addrs
+}
+
+// storeWithReplication stores a full copy of the message on the
+// replication-factor nodes closest to the recipient pseudonym. A failed write
+// moves on to the next-closest node; an error is returned only if no node
+// accepted the message.
+func (ac *AsyncClient) storeWithReplication(obfMsg *ObfuscatedAsyncMessage) error {
+	ac.mutex.RLock()
+	factor := ac.replicationFactorLocked()
+	candidates := rankNodesByXORDistance(obfMsg.RecipientPseudonym, ac.storageNodes)
+	ac.mutex.RUnlock()
+
+	if len(candidates) == 0 {
+		return errors.New("no storage nodes available")
+	}
+
+	stored := 0
+	for _, nodeAddr := range candidates {
+		if stored == factor {
+			break
+		}
+		if err := ac.storeObfuscatedMessageOnNode(nodeAddr, obfMsg); err != nil {
+			pkgLog.WithFields(logrus.Fields{
+				"function":   "storeWithReplication",
+				"node":       nodeAddr.String(),
+				"message_id": fmt.Sprintf("%x", obfMsg.MessageID[:8]),
+				"error":      err.Error(),
+			}).Debug("Storage node write failed, trying next-closest node")
+			continue
+		}
+		stored++
+	}
+
+	if stored == 0 {
+		return errors.New("failed to store obfuscated message on any storage node")
+	}
+	if stored < factor {
+		pkgLog.WithFields(logrus.Fields{
+			"function":           "storeWithReplication",
+			"message_id":         fmt.Sprintf("%x", obfMsg.MessageID[:8]),
+			"replicas_stored":    stored,
+			"replication_factor": factor,
+		}).Warn("Message stored on fewer nodes than the replication factor")
+	}
+	return nil
+}
+
+// retrievalNodes returns the storage nodes to query for messages addressed to
+// pseudonym: the nodes holding erasure-coded shards and the nodes holding
+// replicas, without duplicates.
+func (ac *AsyncClient) retrievalNodes(pseudonym [32]byte, storageNodesMap map[[32]byte]net.Addr, factor int) []net.Addr {
+	nodes := ac.findStorageNodesFromSnapshot(pseudonym, retrievalNodeCount, storageNodesMap)
+
+	replicaCount := factor
+	if replicaCount < retrievalNodeCount {
+		replicaCount = retrievalNodeCount
+	}
+	replicas := rankNodesByXORDistance(pseudonym, storageNodesMap)
+	if len(replicas) > replicaCount {
+		replicas = replicas[:replicaCount]
+	}
+
+	seen := make(map[string]bool, len(nodes)+len(replicas))
+	for _, addr := range nodes {
+		seen[addr.String()] = true
+	}
+	for _, addr := range replicas {
+		if !seen[addr.String()] {
+			seen[addr.String()] = true
+			nodes = append(nodes, addr)
+		}
+	}
+	return nodes
+}
+
+// messageDeduplicator drops copies of a message retrieved from several
+// storage nodes, keyed by the message's recipient proof HMAC. It is safe for
+// concurrent use by parallel node queries.
+type messageDeduplicator struct {
+	mu   sync.Mutex
+	seen map[[32]byte]bool
+}
+
+// newMessageDeduplicator creates an empty deduplicator.
+func newMessageDeduplicator() *messageDeduplicator {
+	return &messageDeduplicator{seen: make(map[[32]byte]bool)}
+}
+
+// filter returns the messages not seen before, recording them as seen.
+func (d *messageDeduplicator) filter(messages []*ObfuscatedAsyncMessage) []*ObfuscatedAsyncMessage {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	fresh := messages[:0:0]
+	for _, msg := range messages {
+		if msg == nil || d.seen[msg.RecipientProof] {
+			continue
+		}
+		d.seen[msg.RecipientProof] = true
+		fresh = append(fresh, msg)
+	}
+	return fresh
+}
@@ -0,0 +1,291 @@
+package async
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/opd-ai/toxcore/crypto"
+	"github.com/opd-ai/toxcore/transport"
+)
+
+// simulatedStorageNetwork is a set of in-memory storage nodes reachable
+// through mock transports. Nodes can be failed to reject reads and writes.
+type simulatedStorageNetwork struct {
+	mu       sync.Mutex
+	messages map[string][]*ObfuscatedAsyncMessage
+	failed   map[string]bool
+	nodes    map[[32]byte]net.Addr
+}
+
+func newSimulatedStorageNetwork(t *testing.T, count int) *simulatedStorageNetwork {
+	t.Helper()
+	sn := &simulatedStorageNetwork{
+		messages: make(map[string][]*ObfuscatedAsyncMessage),
+		failed:   make(map[string]bool),
+		nodes:    make(map[[32]byte]net.Addr),
+	}
+	for i := 0; i < count; i++ {
+		kp, err := crypto.GenerateKeyPair()
+		if err != nil {
+			t.Fatalf("Failed to generate storage node key: %v", err)
+		}
+		sn.nodes[kp.Public] = &MockAddr{network: "udp", address: fmt.Sprintf("10.0.0.%d:33445", i+1)}
+	}
+	return sn
+}
+
+func (sn *simulatedStorageNetwork) fail(addr net.Addr) {
+	sn.mu.Lock()
+	defer sn.mu.Unlock()
+	sn.failed[addr.String()] = true
+}
+
+func (sn *simulatedStorageNetwork) holders() []net.Addr {
+	sn.mu.Lock()
+	defer sn.mu.Unlock()
+	var addrs []net.Addr
+	for _, addr := range sn.nodes {
+		if len(sn.messages[addr.String()]) > 0 {
+			addrs = append(addrs, addr)
+		}
+	}
+	return addrs
+}
+
+// attach registers the storage nodes with client and routes its store and
+// retrieve packets to them.
+func (sn *simulatedStorageNetwork) attach(t *testing.T, client *AsyncClient, mt *MockTransport) {
+	t.Helper()
+	for pk, addr := range sn.nodes {
+		client.AddStorageNode(pk, addr)
+	}
+	mt.SetSendFunc(func(packet *transport.Packet, addr net.Addr) error {
+		sn.mu.Lock()
+		failed := sn.failed[addr.String()]
+		sn.mu.Unlock()
+		if failed {
+			return errors.New("storage node offline")
+		}
+
+		switch packet.PacketType {
+		case transport.PacketAsyncStore:
+			msg, err := client.deserializeObfuscatedMessage(packet.Data)
+			if err != nil {
+				return err
+			}
+			sn.mu.Lock()
+			sn.messages[addr.String()] = append(sn.messages[addr.String()], msg)
+			sn.mu.Unlock()
+		case transport.PacketAsyncRetrieve:
+			var req AsyncRetrieveRequest
+			if err := gob.NewDecoder(bytes.NewReader(packet.Data)).Decode(&req); err != nil {
+				return err
+			}
+			sn.mu.Lock()
+			var matches []*ObfuscatedAsyncMessage
+			for _, msg := range sn.messages[addr.String()] {
+				if msg.RecipientPseudonym == req.RecipientPseudonym {
+					matches = append(matches, msg)
+				}
+			}
+			sn.mu.Unlock()
+			go func() {
+				data, err := client.serializeRetrieveResponse(req.RequestID, matches)
+				if err != nil {
+					return
+				}
+				_ = client.handleRetrieveResponse(&transport.Packet{
+					PacketType: transport.PacketAsyncRetrieveResponse,
+					Data:       data,
+				}, addr)
+			}()
+		}
+		return nil
+	})
+}
+
+// newReplicationTestClients creates a sender and a recipient sharing a
+// simulated storage network, with erasure coding disabled on the sender.
+func newReplicationTestClients(t *testing.T, nodes int) (*AsyncClient, *AsyncClient, *simulatedStorageNetwork) {
+	t.Helper()
+	senderKey, err := crypto.GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("Failed to generate sender key: %v", err)
+	}
+	recipientKey, err := crypto.GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("Failed to generate recipient key: %v", err)
+	}
+
+	network := newSimulatedStorageNetwork(t, nodes)
+
+	senderTransport := NewMockTransport("127.0.0.1:8000")
+	sender := NewAsyncClient(senderKey, senderTransport)
+	sender.SetErasureCodingEnabled(false)
+	network.attach(t, sender, senderTransport)
+
+	recipientTransport := NewMockTransport("127.0.0.1:9000")
+	recipient := NewAsyncClient(recipientKey, recipientTransport)
+	recipient.AddKnownSender(senderKey.Public)
+	network.attach(t, recipient, recipientTransport)
+
+	t.Cleanup(func() {
+		sender.Close()
+		recipient.Close()
+	})
+	return sender, recipient, network
+}
+
+func sendReplicationTestMessage(t *testing.T, sender, recipient *AsyncClient) {
+	t.Helper()
+	padded, err := PadMessageToStandardSize([]byte("replicated hello"))
+	if err != nil {
+		t.Fatalf("Failed to pad message: %v", err)
+	}
+	fsMsg := &ForwardSecureMessage{
+		Type:          "forward_secure_message",
+		MessageID:     [32]byte{9, 9, 9},
+		SenderPK:      sender.keyPair.Public,
+		RecipientPK:   recipient.keyPair.Public,
+		EncryptedData: padded,
+		MessageType:   MessageTypeNormal,
+		Timestamp:     time.Now(),
+		ExpiresAt:     time.Now().Add(time.Hour),
+	}
+	if err := sender.SendObfuscatedMessage(recipient.keyPair.Public, fsMsg); err != nil {
+		t.Fatalf("SendObfuscatedMessage failed: %v", err)
+	}
+}
+
+func TestSetReplicationFactor(t *testing.T) {
+	keyPair, err := crypto.GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	client := NewAsyncClient(keyPair, NewMockTransport("127.0.0.1:8000"))
+	defer client.Close()
+
+	if got := client.GetReplicationFactor(); got != DefaultReplicationFactor {
+		t.Errorf("Expected default replication factor %d, got %d", DefaultReplicationFactor, got)
+	}
+	if err := client.SetReplicationFactor(5); err != nil {
+		t.Fatalf("SetReplicationFactor(5) failed: %v", err)
+	}
+	if got := client.GetReplicationFactor(); got != 5 {
+		t.Errorf("Expected replication factor 5, got %d", got)
+	}
+	for _, n := range []int{0, -1} {
+		if err := client.SetReplicationFactor(n); !errors.Is(err, ErrInvalidReplicationFactor) {
+			t.Errorf("SetReplicationFactor(%d): expected ErrInvalidReplicationFactor, got %v", n, err)
+		}
+	}
+}
+
+func TestReplicationStoresOnClosestNodes(t *testing.T) {
+	sender, recipient, network := newReplicationTestClients(t, 6)
+	if err := sender.SetReplicationFactor(4); err != nil {
+		t.Fatalf("SetReplicationFactor failed: %v", err)
+	}
+
+	sendReplicationTestMessage(t, sender, recipient)
+
+	holders := network.holders()
+	if len(holders) != 4 {
+		t.Fatalf("Expected message on 4 nodes, got %d", len(holders))
+	}
+
+	msg := network.messages[holders[0].String()][0]
+	closest := rankNodesByXORDistance(msg.RecipientPseudonym, network.nodes)[:4]
+	want := make(map[string]bool)
+	for _, addr := range closest {
+		want[addr.String()] = true
+	}
+	for _, addr := range holders {
+		if !want[addr.String()] {
+			t.Errorf("Node %s holds a replica but is not among the 4 closest", addr)
+		}
+	}
+}
+
+func TestReplicationRetriesOnNextClosestNode(t *testing.T) {
+	sender, recipient, network := newReplicationTestClients(t, 5)
+	if err := sender.SetReplicationFactor(2); err != nil {
+		t.Fatalf("SetReplicationFactor failed: %v", err)
+	}
+
+	// The pseudonym, and so the node ranking, depends only on the recipient
+	// and the epoch.
+	pseudonym, err := sender.obfuscation.GenerateRecipientPseudonym(
+		recipient.keyPair.Public, sender.obfuscation.epochManager.GetCurrentEpoch())
+	if err != nil {
+		t.Fatalf("Failed to generate pseudonym: %v", err)
+	}
+	ranked := rankNodesByXORDistance(pseudonym, network.nodes)
+	network.fail(ranked[0])
+
+	sendReplicationTestMessage(t, sender, recipient)
+
+	holders := network.holders()
+	if len(holders) != 2 {
+		t.Fatalf("Expected message on 2 nodes after retry, got %d", len(holders))
+	}
+	got := map[string]bool{holders[0].String(): true, holders[1].String(): true}
+	if !got[ranked[1].String()] || !got[ranked[2].String()] {
+		t.Errorf("Expected replicas on the next-closest nodes %s and %s, got %v", ranked[1], ranked[2], holders)
+	}
+}
+
+func TestReplicationSurvivesNodeFailures(t *testing.T) {
+	for _, factor := range []int{1, 2, 3} {
+		t.Run(fmt.Sprintf("factor=%d", factor), func(t *testing.T) {
+			sender, recipient, network := newReplicationTestClients(t, 5)
+			if err := sender.SetReplicationFactor(factor); err != nil {
+				t.Fatalf("SetReplicationFactor failed: %v", err)
+			}
+
+			sendReplicationTestMessage(t, sender, recipient)
+
+			holders := network.holders()
+			if len(holders) != factor {
+				t.Fatalf("Expected message on %d nodes, got %d", factor, len(holders))
+			}
+			for _, addr := range holders[:factor-1] {
+				network.fail(addr)
+			}
+
+			messages, err := recipient.RetrieveObfuscatedMessages()
+			if err != nil {
+				t.Fatalf("RetrieveObfuscatedMessages failed: %v", err)
+			}
+			if len(messages) != 1 {
+				t.Fatalf("Expected 1 message after %d node failures, got %d", factor-1, len(messages))
+			}
+			if string(messages[0].Message) != "replicated hello" {
+				t.Errorf("Unexpected message content %q", messages[0].Message)
+			}
+		})
+	}
+}
+
+func TestRetrievalDeduplicatesReplicas(t *testing.T) {
+	sender, recipient, _ := newReplicationTestClients(t, 5)
+	if err := sender.SetReplicationFactor(5); err != nil {
+		t.Fatalf("SetReplicationFactor failed: %v", err)
+	}
+
+	sendReplicationTestMessage(t, sender, recipient)
+
+	messages, err := recipient.RetrieveObfuscatedMessages()
+	if err != nil {
+		t.Fatalf("RetrieveObfuscatedMessages failed: %v", err)
+	}
+	if len(messages) != 1 {
+		t.Fatalf("Expected replicas from 5 nodes to collapse to 1 message, got %d", len(messages))
+	}
+}
@@ -2,18 +2,28 @@ package async
 
 import (
 	"crypto/rand"
+	"errors"
 	"log"
 	"math/big"
+	"sort"
 	"sync"
 	"time"
-
-	"github.com/sirupsen/logrus"
 )
 
 // defaultBaseRetrievalInterval is the fallback used when Configure receives a
 // non-positive base interval (L-09).
 const defaultBaseRetrievalInterval = 5 * time.Minute
 
+// Default bounds for the adaptive retrieval interval.
+const (
+	// DefaultMinRetrievalInterval is the shortest interval the scheduler
+	// adapts to, however often messages arrive.
+	DefaultMinRetrievalInterval = 30 * time.Second
+	// DefaultMaxRetrievalInterval is the longest interval the scheduler
+	// adapts to, however rarely messages arrive.
+	DefaultMaxRetrievalInterval = 30 * time.Minute
+)
+
 // RetrievalScheduler manages randomized retrieval schedules with cover traffic
 // to prevent storage nodes from tracking user activity based on retrieval patterns
 type RetrievalScheduler struct {
@@ -29,6 +39,12 @@ type RetrievalScheduler struct {
 
 	lastRetrieval    time.Time // When the last retrieval happened
 	consecutiveEmpty int       // Count of consecutive empty retrievals
+
+	adaptive     bool             // Whether baseInterval follows observed message frequency
+	minInterval  time.Duration    // Lower bound for the adapted interval
+	maxInterval  time.Duration    // Upper bound for the adapted interval
+	arrivals     *arrivalAverager // Moving average of message inter-arrival times
+	timeProvider TimeProvider     // Source of the current time
 }
 
 // NewRetrievalScheduler creates a new scheduler with default settings
@@ -42,6 +58,11 @@ func NewRetrievalScheduler(client *AsyncClient) *RetrievalScheduler {
 		lastRetrieval:       time.Time{},     // Zero time
 		consecutiveEmpty:    0,
 		stopChan:            make(chan struct{}),
+		adaptive:            true,
+		minInterval:         DefaultMinRetrievalInterval,
+		maxInterval:         DefaultMaxRetrievalInterval,
+		arrivals:            newArrivalAverager(arrivalSmoothingFactor),
+		timeProvider:        DefaultTimeProvider{},
 	}
 }
 
@@ -105,6 +126,9 @@ func (rs *RetrievalScheduler) calculateNextInterval() time.Duration {
 			multiplier = 4
 		}
 		interval = time.Duration(float64(interval) * multiplier)
+		if rs.adaptive && rs.maxInterval > 0 && interval > rs.maxInterval {
+			interval = rs.maxInterval
+		}
 	}
 
 	// Calculate jitter value (±jitterPercent% of interval)
@@ -117,7 +141,7 @@ func (rs *RetrievalScheduler) calculateNextInterval() time.Duration {
 		jitterBig, err := rand.Int(rand.Reader, big.NewInt(2*maxJitter))
 		if err != nil {
 			// Log error but use a fallback jitter value to maintain privacy
-			logrus.WithError(err).Warn("Failed to generate random jitter, using default")
+			pkgLog.WithError(err).Warn("Failed to generate random jitter, using default")
 			fallback := time.Now().UnixNano() % (2 * maxJitter)
 			jitter = time.Duration(fallback - maxJitter)
 		} else {
@@ -137,7 +161,7 @@ func (rs *RetrievalScheduler) performRetrieval() {
 	isCoverTraffic := rs.shouldSendCoverTraffic()
 
 	// Track retrieval time
-	rs.lastRetrieval = time.Now()
+	rs.lastRetrieval = rs.now()
 	rs.mutex.Unlock()
 
 	if isCoverTraffic {
@@ -158,7 +182,62 @@ func (rs *RetrievalScheduler) performRetrieval() {
 	} else {
 		// Reset the counter when we get messages
 		rs.consecutiveEmpty = 0
+		rs.recordMessageArrivals(messages)
+	}
+}
+
+// recordMessageArrivals feeds retrieved messages to the arrival average in
+// send order. Callers must hold rs.mutex.
+func (rs *RetrievalScheduler) recordMessageArrivals(messages []DecryptedMessage) {
+	times := make([]time.Time, 0, len(messages))
+	for _, msg := range messages {
+		t := msg.Timestamp
+		if t.IsZero() {
+			t = rs.now()
+		}
+		times = append(times, t)
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i].Before(times[j]) })
+	for _, t := range times {
+		rs.recordArrivalLocked(t)
+	}
+}
+
+// recordArrival records a message sent at t and, when adaptive scheduling is
+// on, moves the base interval to the clamped moving average of inter-arrival
+// times.
+func (rs *RetrievalScheduler) recordArrival(t time.Time) {
+	rs.mutex.Lock()
+	defer rs.mutex.Unlock()
+	rs.recordArrivalLocked(t)
+}
+
+// recordArrivalLocked is recordArrival for callers holding rs.mutex.
+func (rs *RetrievalScheduler) recordArrivalLocked(t time.Time) {
+	if rs.arrivals == nil {
+		rs.arrivals = newArrivalAverager(arrivalSmoothingFactor)
+	}
+	avg, ok := rs.arrivals.record(t)
+	if !ok || !rs.adaptive {
+		return
 	}
+
+	if rs.minInterval > 0 && avg < rs.minInterval {
+		avg = rs.minInterval
+	}
+	if rs.maxInterval > 0 && avg > rs.maxInterval {
+		avg = rs.maxInterval
+	}
+	rs.baseInterval = avg
+}
+
+// now returns the current time from the configured time provider. Callers
+// must hold rs.mutex.
+func (rs *RetrievalScheduler) now() time.Time {
+	if rs.timeProvider == nil {
+		return time.Now()
+	}
+	return rs.timeProvider.Now()
 }
 
 // shouldSendCoverTraffic determines if the current retrieval should be cover traffic
@@ -180,7 +259,8 @@ func (rs *RetrievalScheduler) shouldSendCoverTraffic() bool {
 	return random < rs.coverTrafficRatio
 }
 
-// Configure updates the scheduler configuration
+// Configure updates the scheduler configuration. With adaptive scheduling on,
+// baseInterval is the starting point until message frequency is known.
 func (rs *RetrievalScheduler) Configure(
 	baseInterval time.Duration,
 	jitterPercent int,
@@ -212,6 +292,81 @@ func (rs *RetrievalScheduler) Configure(
 	rs.coverTrafficRatio = coverTrafficRatio
 }
 
+// SetBaseInterval fixes the base interval between retrievals and turns off
+// adaptive scheduling. Jitter and the empty-retrieval backoff still apply.
+// Non-positive values select the 5-minute default.
+func (rs *RetrievalScheduler) SetBaseInterval(interval time.Duration) {
+	if interval <= 0 {
+		interval = defaultBaseRetrievalInterval
+	}
+
+	rs.mutex.Lock()
+	defer rs.mutex.Unlock()
+
+	rs.baseInterval = interval
+	rs.adaptive = false
+}
+
+// SetAdaptiveBounds turns on adaptive scheduling, in which the base interval
+// follows the moving average of message inter-arrival times, limited to
+// [minInterval, maxInterval]. The base interval is kept until enough
+// messages have arrived to estimate their frequency.
+func (rs *RetrievalScheduler) SetAdaptiveBounds(minInterval, maxInterval time.Duration) error {
+	if minInterval <= 0 || maxInterval < minInterval {
+		return errors.New("adaptive bounds must satisfy 0 < minInterval <= maxInterval")
+	}
+
+	rs.mutex.Lock()
+	defer rs.mutex.Unlock()
+
+	rs.minInterval = minInterval
+	rs.maxInterval = maxInterval
+	rs.adaptive = true
+	if rs.arrivals == nil {
+		rs.arrivals = newArrivalAverager(arrivalSmoothingFactor)
+	}
+	if avg, ok := rs.arrivals.average(); ok {
+		if avg < minInterval {
+			avg = minInterval
+		} else if avg > maxInterval {
+			avg = maxInterval
+		}
+		rs.baseInterval = avg
+	}
+	return nil
+}
+
+// IsAdaptive reports whether the base interval follows observed message
+// frequency.
+func (rs *RetrievalScheduler) IsAdaptive() bool {
+	rs.mutex.Lock()
+	defer rs.mutex.Unlock()
+
+	return rs.adaptive
+}
+
+// BaseInterval returns the current base interval between retrievals, before
+// jitter and backoff.
+func (rs *RetrievalScheduler) BaseInterval() time.Duration {
+	rs.mutex.Lock()
+	defer rs.mutex.Unlock()
+
+	return rs.baseInterval
+}
+
+// SetTimeProvider sets the time provider for deterministic testing.
+// Pass nil to reset to the default time provider.
+func (rs *RetrievalScheduler) SetTimeProvider(tp TimeProvider) {
+	if tp == nil {
+		tp = DefaultTimeProvider{}
+	}
+
+	rs.mutex.Lock()
+	defer rs.mutex.Unlock()
+
+	rs.timeProvider = tp
+}
+
 // SetCoverTrafficEnabled turns cover traffic on or off
 func (rs *RetrievalScheduler) SetCoverTrafficEnabled(enabled bool) {
 	rs.mutex.Lock()
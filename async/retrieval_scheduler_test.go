@@ -118,3 +118,150 @@ func TestRetrievalSchedulerConfigureClampsInvalidValues(t *testing.T) {
 		t.Fatalf("Expected cover traffic ratio clamped to 0, got %f", scheduler.coverTrafficRatio)
 	}
 }
+
+func TestArrivalAverager(t *testing.T) {
+	a := newArrivalAverager(0.5)
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	if _, ok := a.average(); ok {
+		t.Fatal("Expected no average before any arrival")
+	}
+	if _, ok := a.record(start); ok {
+		t.Fatal("Expected no average after a single arrival")
+	}
+	if avg, ok := a.record(start.Add(4 * time.Minute)); !ok || avg != 4*time.Minute {
+		t.Fatalf("Expected first average 4m, got %v (ok=%v)", avg, ok)
+	}
+	if avg, _ := a.record(start.Add(6 * time.Minute)); avg != 3*time.Minute {
+		t.Fatalf("Expected smoothed average 3m, got %v", avg)
+	}
+
+	// Out-of-order and duplicate arrivals are ignored
+	if avg, _ := a.record(start.Add(5 * time.Minute)); avg != 3*time.Minute {
+		t.Errorf("Expected average unchanged by out-of-order arrival, got %v", avg)
+	}
+	if avg, _ := a.record(start.Add(6 * time.Minute)); avg != 3*time.Minute {
+		t.Errorf("Expected average unchanged by duplicate arrival, got %v", avg)
+	}
+}
+
+func TestRetrievalSchedulerAdaptsToArrivalFrequency(t *testing.T) {
+	scheduler := NewRetrievalScheduler(&AsyncClient{})
+	clock := &fixedTimeProvider{now: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	scheduler.SetTimeProvider(clock)
+
+	if !scheduler.IsAdaptive() {
+		t.Fatal("Expected adaptive scheduling by default")
+	}
+	if got := scheduler.BaseInterval(); got != 5*time.Minute {
+		t.Fatalf("Expected initial base interval 5m, got %v", got)
+	}
+
+	// Messages every two minutes pull the interval down to two minutes
+	for i := 0; i < 10; i++ {
+		scheduler.recordArrival(clock.now.Add(time.Duration(i) * 2 * time.Minute))
+	}
+	if got := scheduler.BaseInterval(); got != 2*time.Minute {
+		t.Errorf("Expected base interval 2m, got %v", got)
+	}
+
+	// Jitter still applies on top of the adapted interval
+	scheduler.jitterPercent = 50
+	for i := 0; i < 20; i++ {
+		got := scheduler.calculateNextInterval()
+		if got < time.Minute || got > 3*time.Minute {
+			t.Fatalf("Expected jittered interval within 1m-3m, got %v", got)
+		}
+	}
+}
+
+func TestRetrievalSchedulerAdaptiveBounds(t *testing.T) {
+	scheduler := NewRetrievalScheduler(&AsyncClient{})
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// Bursts faster than the minimum clamp to the minimum
+	for i := 0; i < 5; i++ {
+		scheduler.recordArrival(start.Add(time.Duration(i) * time.Second))
+	}
+	if got := scheduler.BaseInterval(); got != DefaultMinRetrievalInterval {
+		t.Errorf("Expected base interval clamped to %v, got %v", DefaultMinRetrievalInterval, got)
+	}
+
+	// Rare messages clamp to the maximum
+	scheduler.recordArrival(start.Add(24 * time.Hour))
+	scheduler.recordArrival(start.Add(48 * time.Hour))
+	if got := scheduler.BaseInterval(); got != DefaultMaxRetrievalInterval {
+		t.Errorf("Expected base interval clamped to %v, got %v", DefaultMaxRetrievalInterval, got)
+	}
+
+	// New bounds apply to the current average immediately
+	if err := scheduler.SetAdaptiveBounds(time.Minute, 10*time.Minute); err != nil {
+		t.Fatalf("SetAdaptiveBounds failed: %v", err)
+	}
+	if got := scheduler.BaseInterval(); got != 10*time.Minute {
+		t.Errorf("Expected base interval clamped to new maximum 10m, got %v", got)
+	}
+
+	// Backoff after empty retrievals also respects the maximum
+	scheduler.jitterPercent = 0
+	scheduler.consecutiveEmpty = 10
+	if got := scheduler.calculateNextInterval(); got != 10*time.Minute {
+		t.Errorf("Expected backoff capped at 10m, got %v", got)
+	}
+
+	for _, bounds := range [][2]time.Duration{{0, time.Minute}, {-time.Second, time.Minute}, {time.Minute, time.Second}} {
+		if err := scheduler.SetAdaptiveBounds(bounds[0], bounds[1]); err == nil {
+			t.Errorf("Expected error for bounds %v", bounds)
+		}
+	}
+}
+
+func TestRetrievalSchedulerSetBaseIntervalOverridesAdaptive(t *testing.T) {
+	scheduler := NewRetrievalScheduler(&AsyncClient{})
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	scheduler.SetBaseInterval(7 * time.Minute)
+	if scheduler.IsAdaptive() {
+		t.Fatal("Expected SetBaseInterval to turn off adaptive scheduling")
+	}
+	for i := 0; i < 5; i++ {
+		scheduler.recordArrival(start.Add(time.Duration(i) * time.Minute))
+	}
+	if got := scheduler.BaseInterval(); got != 7*time.Minute {
+		t.Errorf("Expected fixed base interval 7m, got %v", got)
+	}
+
+	// Re-enabling adaptive scheduling uses the arrivals observed meanwhile
+	if err := scheduler.SetAdaptiveBounds(DefaultMinRetrievalInterval, DefaultMaxRetrievalInterval); err != nil {
+		t.Fatalf("SetAdaptiveBounds failed: %v", err)
+	}
+	if got := scheduler.BaseInterval(); got != time.Minute {
+		t.Errorf("Expected adapted base interval 1m, got %v", got)
+	}
+
+	scheduler.SetBaseInterval(0)
+	if got := scheduler.BaseInterval(); got != defaultBaseRetrievalInterval {
+		t.Errorf("Expected default base interval for non-positive value, got %v", got)
+	}
+}
+
+func TestRetrievalSchedulerRecordsRetrievedMessages(t *testing.T) {
+	scheduler := NewRetrievalScheduler(&AsyncClient{})
+	clock := &fixedTimeProvider{now: time.Date(2026, 1, 1, 1, 0, 0, 0, time.UTC)}
+	scheduler.SetTimeProvider(clock)
+
+	// Out-of-order timestamps are sorted; a missing timestamp uses the
+	// provider's current time.
+	scheduler.mutex.Lock()
+	scheduler.recordMessageArrivals([]DecryptedMessage{
+		{Timestamp: clock.now.Add(-20 * time.Minute)},
+		{},
+		{Timestamp: clock.now.Add(-40 * time.Minute)},
+	})
+	scheduler.mutex.Unlock()
+
+	// Gaps of 20m then 20m
+	if got := scheduler.BaseInterval(); got != 20*time.Minute {
+		t.Errorf("Expected base interval 20m, got %v", got)
+	}
+}
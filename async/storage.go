@@ -118,6 +118,7 @@ type MessageStorage struct {
 	dataDir              string                // Directory for storage calculations
 	maxCapacity          int                   // Dynamic capacity based on available storage
 	maxMessagesPerRecip  int                   // Dynamic per-recipient limit based on capacity
+	maxBytesPerRecip     int64                 // Per-recipient encrypted byte quota
 	dynamicLimitsEnabled bool                  // Whether to use dynamic limits
 	wal                  *WriteAheadLog        // Write-ahead log for crash recovery (optional)
 }
@@ -174,7 +175,7 @@ func CalculateDynamicRecipientLimit(maxCapacity int, config *DynamicLimitConfig)
 // and support for both legacy and obfuscated message formats.
 // Dynamic per-recipient limits are automatically enabled based on storage capacity.
 func NewMessageStorage(keyPair *crypto.KeyPair, dataDir string) *MessageStorage {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":   "NewMessageStorage",
 		"public_key": keyPair.Public[:8],
 		"data_dir":   dataDir,
@@ -193,6 +194,7 @@ func NewMessageStorage(keyPair *crypto.KeyPair, dataDir string) *MessageStorage
 		dataDir:              dataDir,
 		maxCapacity:          maxCapacity,
 		maxMessagesPerRecip:  dynamicLimit,
+		maxBytesPerRecip:     DefaultMaxStorageBytesPerRecipient,
 		dynamicLimitsEnabled: true,
 	}
 
@@ -205,7 +207,7 @@ func NewMessageStorage(keyPair *crypto.KeyPair, dataDir string) *MessageStorage
 func computeStorageCapacity(dataDir string) (maxCapacity, perRecipLimit int) {
 	bytesLimit, err := CalculateAsyncStorageLimit(dataDir)
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "NewMessageStorage",
 			"data_dir": dataDir,
 			"error":    err.Error(),
@@ -224,14 +226,14 @@ func enableWALForStorage(storage *MessageStorage, dataDir string) {
 		return
 	}
 	if err := storage.EnableWAL(); err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "NewMessageStorage",
 			"data_dir": dataDir,
 			"error":    err.Error(),
 		}).Warn("Failed to auto-enable WAL, storage will be memory-only")
 		return
 	}
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "NewMessageStorage",
 		"data_dir": dataDir,
 	}).Info("WAL auto-enabled for crash recovery")
@@ -249,7 +251,7 @@ func (ms *MessageStorage) StoreMessage(recipientPK, senderPK [32]byte,
 	ms.mutex.Lock()
 	defer ms.mutex.Unlock()
 
-	if err := ms.checkLegacyStorageCapacity(recipientPK); err != nil {
+	if err := ms.checkLegacyStorageCapacity(recipientPK, len(encryptedMessage)); err != nil {
 		return [16]byte{}, err
 	}
 
@@ -282,16 +284,14 @@ func validateEncryptedMessage(encryptedMessage []byte) error {
 	return nil
 }
 
-// checkLegacyStorageCapacity verifies storage and per-recipient limits for legacy messages.
-func (ms *MessageStorage) checkLegacyStorageCapacity(recipientPK [32]byte) error {
+// checkLegacyStorageCapacity verifies storage and per-recipient quotas for
+// storing size more bytes for a legacy recipient.
+func (ms *MessageStorage) checkLegacyStorageCapacity(recipientPK [32]byte, size int) error {
 	if len(ms.messages) >= ms.maxCapacity {
 		return ErrStorageFull
 	}
-	recipientLimit := ms.getRecipientLimit()
-	if len(ms.recipientIndex[recipientPK]) >= recipientLimit {
-		return fmt.Errorf("too many messages for recipient (max %d)", recipientLimit)
-	}
-	return nil
+	return ms.checkRecipientQuota(len(ms.recipientIndex[recipientPK]),
+		ms.legacyRecipientBytes(recipientPK), size, "recipient")
 }
 
 // generateLegacyMessageID creates a cryptographically random 16-byte message ID.
@@ -592,7 +592,7 @@ func (ms *MessageStorage) validateObfuscatedMessage(obfMsg *ObfuscatedAsyncMessa
 	}
 
 	// Validate epoch is current or recent
-	if !ms.epochManager.IsValidEpoch(obfMsg.Epoch) {
+	if !ms.epochManager.IsValidEpoch(obfMsg.Epoch, DefaultEpochTolerance) {
 		return fmt.Errorf("invalid epoch %d (too old or future)", obfMsg.Epoch)
 	}
 
@@ -622,18 +622,14 @@ func (ms *MessageStorage) checkStorageCapacity(obfMsg *ObfuscatedAsyncMessage) e
 		return ErrStorageFull
 	}
 
-	// Check per-pseudonym limit to prevent spam using dynamic limit
+	// Check per-pseudonym quotas to prevent spam using dynamic limit
 	pseudonymMessages := ms.pseudonymIndex[obfMsg.RecipientPseudonym]
 	totalForPseudonym := 0
 	for _, epochMessages := range pseudonymMessages {
 		totalForPseudonym += len(epochMessages)
 	}
-	recipientLimit := ms.getRecipientLimit()
-	if totalForPseudonym >= recipientLimit {
-		return fmt.Errorf("too many messages for recipient pseudonym (max %d)", recipientLimit)
-	}
-
-	return nil
+	return ms.checkRecipientQuota(totalForPseudonym, ms.pseudonymBytes(obfMsg.RecipientPseudonym),
+		len(obfMsg.EncryptedPayload), "recipient pseudonym")
 }
 
 // storeAndIndexMessage stores the message and updates the pseudonym index.
@@ -768,6 +764,7 @@ func (ms *MessageStorage) GetStorageStats() StorageStats {
 	if ms.maxCapacity > 0 {
 		utilizationPercent = float64(totalMessages) / float64(ms.maxCapacity) * 100
 	}
+	recipientBytes, pseudonymBytes := ms.recipientByteUsage()
 
 	return StorageStats{
 		TotalMessages:        totalMessages,
@@ -778,8 +775,11 @@ func (ms *MessageStorage) GetStorageStats() StorageStats {
 		StorageCapacity:      ms.maxCapacity,
 		StorageNodes:         len(ms.storageNodes),
 		MaxPerRecipient:      ms.getRecipientLimit(),
+		MaxBytesPerRecipient: ms.getRecipientByteLimit(),
 		UtilizationPercent:   utilizationPercent,
 		DynamicLimitsEnabled: ms.dynamicLimitsEnabled,
+		RecipientBytes:       recipientBytes,
+		PseudonymBytes:       pseudonymBytes,
 	}
 }
 
@@ -793,8 +793,12 @@ type StorageStats struct {
 	StorageCapacity      int
 	StorageNodes         int
 	MaxPerRecipient      int     // Current per-recipient limit (may be dynamic)
+	MaxBytesPerRecipient int64   // Current per-recipient byte quota
 	UtilizationPercent   float64 // Percentage of capacity used
 	DynamicLimitsEnabled bool    // Whether dynamic limits are enabled
+
+	RecipientBytes map[[32]byte]int64 // Encrypted bytes stored per recipient public key (legacy)
+	PseudonymBytes map[[32]byte]int64 // Encrypted bytes stored per recipient pseudonym (obfuscated)
 }
 
 // EncryptForRecipient encrypts a message for a recipient using basic encryption.
@@ -858,7 +862,8 @@ func (ms *MessageStorage) GetMaxCapacity() int {
 	return ms.maxCapacity
 }
 
-// UpdateCapacity recalculates and updates the storage capacity based on current disk space
+// UpdateCapacity recalculates and updates the storage capacity based on current disk space,
+// then evicts the oldest messages of any recipient over its per-recipient quotas.
 func (ms *MessageStorage) UpdateCapacity() error {
 	bytesLimit, err := CalculateAsyncStorageLimit(ms.dataDir)
 	if err != nil {
@@ -869,6 +874,7 @@ func (ms *MessageStorage) UpdateCapacity() error {
 
 	ms.mutex.Lock()
 	ms.maxCapacity = newCapacity
+	ms.enforceRecipientQuotas()
 	ms.mutex.Unlock()
 
 	return nil
@@ -1015,8 +1021,9 @@ func (ms *MessageStorage) UpdateCapacityAndLimits() error {
 
 	ms.maxCapacity = newCapacity
 	ms.maxMessagesPerRecip = newRecipLimit
+	ms.enforceRecipientQuotas()
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":        "UpdateCapacityAndLimits",
 		"new_capacity":    newCapacity,
 		"new_recip_limit": newRecipLimit,
@@ -1045,7 +1052,7 @@ func (ms *MessageStorage) EnableWAL() error {
 
 	ms.wal = wal
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":  "EnableWAL",
 		"directory": ms.dataDir,
 	}).Info("Write-ahead logging enabled")
@@ -1073,7 +1080,7 @@ func (ms *MessageStorage) EnableWALWithConfig(config WALConfig) error {
 
 	ms.wal = wal
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":  "EnableWALWithConfig",
 		"directory": config.Directory,
 	}).Info("Write-ahead logging enabled with custom config")
@@ -1096,7 +1103,7 @@ func (ms *MessageStorage) DisableWAL() error {
 
 	ms.wal = nil
 
-	logrus.Info("Write-ahead logging disabled")
+	pkgLog.Info("Write-ahead logging disabled")
 	return nil
 }
 
@@ -1124,14 +1131,14 @@ func (ms *MessageStorage) RecoverFromWAL() (int, error) {
 
 	recovered := ms.replayWALEntries(entries)
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":      "RecoverFromWAL",
 		"total_entries": len(entries),
 		"recovered":     recovered,
 	}).Info("WAL recovery complete")
 
 	if err := ms.wal.Checkpoint(); err != nil {
-		logrus.WithError(err).Warn("Failed to checkpoint after recovery")
+		pkgLog.WithError(err).Warn("Failed to checkpoint after recovery")
 	}
 
 	return recovered, nil
@@ -1153,14 +1160,14 @@ func (ms *MessageStorage) replaySingleEntry(entry *WALEntry) bool {
 	switch entry.Operation {
 	case WALOpStoreMessage:
 		if err := ms.replayStoreMessage(entry); err != nil {
-			logrus.WithError(err).Warn("Failed to replay store message")
+			pkgLog.WithError(err).Warn("Failed to replay store message")
 			return false
 		}
 		return true
 
 	case WALOpDeleteMessage:
 		if err := ms.replayDeleteMessage(entry); err != nil {
-			logrus.WithError(err).Warn("Failed to replay delete message")
+			pkgLog.WithError(err).Warn("Failed to replay delete message")
 			return false
 		}
 		return true
@@ -143,7 +143,7 @@ func (sd *StorageNodeDiscovery) StoreAnnouncement(announcement *StorageNodeAnnou
 		go sd.onNodeDiscovered(announcement)
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":           "StoreAnnouncement",
 		"public_key_preview": formatKeyPreview(announcement.PublicKey[:]),
 		"address":            announcement.Address,
@@ -19,7 +19,7 @@ type StorageInfo struct {
 // GetStorageInfo returns storage information for the given path
 // On Unix systems, this uses the statfs system call
 func GetStorageInfo(path string) (*StorageInfo, error) {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "GetStorageInfo",
 		"path":     path,
 	}).Debug("Getting storage information")
@@ -40,7 +40,7 @@ func GetStorageInfo(path string) (*StorageInfo, error) {
 		UsedBytes:      usedBytes,
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":        "GetStorageInfo",
 		"total_bytes":     totalBytes,
 		"available_bytes": availableBytes,
@@ -54,7 +54,7 @@ func GetStorageInfo(path string) (*StorageInfo, error) {
 func resolveAbsoluteStoragePath(path string) (string, error) {
 	absPath, err := filepath.Abs(path)
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "resolveAndValidateDirectory",
 			"path":     path,
 			"error":    err.Error(),
@@ -73,7 +73,7 @@ func resolveParentStorageDirectory(absPath string) (string, error) {
 	if err := validateIsDirectory(dir); err != nil {
 		return "", err
 	}
-	logrus.WithFields(logrus.Fields{"function": "resolveAndValidateDirectory", "abs_path": absPath, "parent": dir}).Debug("Path doesn't exist, using parent directory")
+	pkgLog.WithFields(logrus.Fields{"function": "resolveAndValidateDirectory", "abs_path": absPath, "parent": dir}).Debug("Path doesn't exist, using parent directory")
 	return dir, nil
 }
 
@@ -84,7 +84,7 @@ func validateResolvedStorageDirectory(absPath string) (string, error) {
 		if !info.IsDir() {
 			return "", fmt.Errorf("path is not a directory: %s", absPath)
 		}
-		logrus.WithFields(logrus.Fields{"function": "resolveAndValidateDirectory", "abs_path": absPath}).Debug("Resolved directory path")
+		pkgLog.WithFields(logrus.Fields{"function": "resolveAndValidateDirectory", "abs_path": absPath}).Debug("Resolved directory path")
 		return absPath, nil
 	}
 	if !os.IsNotExist(err) {
@@ -105,13 +105,13 @@ func resolveAndValidateDirectory(path string) (string, error) {
 // ensureDirectoryExists creates the directory if it doesn't exist.
 func ensureDirectoryExists(dir string) error {
 	if _, err := os.Stat(dir); os.IsNotExist(err) {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "ensureDirectoryExists",
 			"dir":      dir,
 		}).Debug("Directory does not exist, creating")
 
 		if err := os.MkdirAll(dir, 0o755); err != nil {
-			logrus.WithFields(logrus.Fields{
+			pkgLog.WithFields(logrus.Fields{
 				"function": "ensureDirectoryExists",
 				"dir":      dir,
 				"error":    err.Error(),
@@ -126,7 +126,7 @@ func ensureDirectoryExists(dir string) error {
 func validateIsDirectory(dir string) error {
 	fileInfo, err := os.Stat(dir)
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "validateIsDirectory",
 			"dir":      dir,
 			"error":    err.Error(),
@@ -136,7 +136,7 @@ func validateIsDirectory(dir string) error {
 
 	if !fileInfo.IsDir() {
 		err := fmt.Errorf("path is not a directory")
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "validateIsDirectory",
 			"dir":      dir,
 		}).Error("Path is not a directory")
@@ -150,7 +150,7 @@ func validateIsDirectory(dir string) error {
 func getWindowsFilesystemStats(dir string) (totalBytes, availableBytes, usedBytes uint64, err error) {
 	totalBytes, availableBytes, usedBytes, err = getWindowsDiskSpace(dir)
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "getWindowsFilesystemStats",
 			"dir":      dir,
 			"error":    err.Error(),
@@ -173,7 +173,7 @@ func getWindowsFilesystemStats(dir string) (totalBytes, availableBytes, usedByte
 // running on these platforms should monitor their storage usage manually or
 // configure limits via CalculateAsyncStorageLimitWithMax().
 func getDefaultFilesystemStats(dir string) (totalBytes, availableBytes, usedBytes uint64, err error) {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "getDefaultFilesystemStats",
 		"os":       runtime.GOOS,
 	}).Warn("Platform-specific disk space detection not supported, using defaults")
@@ -195,14 +195,14 @@ func getDefaultFilesystemStats(dir string) (totalBytes, availableBytes, usedByte
 // CalculateAsyncStorageLimit calculates the maximum bytes to use for async storage
 // This is set to 1% of available storage
 func CalculateAsyncStorageLimit(path string) (uint64, error) {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "CalculateAsyncStorageLimit",
 		"path":     path,
 	}).Info("Calculating async storage limit")
 
 	info, err := GetStorageInfo(path)
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "CalculateAsyncStorageLimit",
 			"path":     path,
 			"error":    err.Error(),
@@ -227,7 +227,7 @@ func CalculateAsyncStorageLimit(path string) (uint64, error) {
 //
 // Returns the calculated limit, which will be min(1% of available, maxBytes).
 func CalculateAsyncStorageLimitWithMax(path string, maxBytes uint64) (uint64, error) {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":  "CalculateAsyncStorageLimitWithMax",
 		"path":      path,
 		"max_bytes": maxBytes,
@@ -235,7 +235,7 @@ func CalculateAsyncStorageLimitWithMax(path string, maxBytes uint64) (uint64, er
 
 	info, err := GetStorageInfo(path)
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "CalculateAsyncStorageLimitWithMax",
 			"path":     path,
 			"error":    err.Error(),
@@ -247,7 +247,7 @@ func CalculateAsyncStorageLimitWithMax(path string, maxBytes uint64) (uint64, er
 
 	// Apply custom max if provided
 	if maxBytes > 0 && onePercentOfAvailable > maxBytes {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":             "CalculateAsyncStorageLimitWithMax",
 			"calculated_1_percent": onePercentOfAvailable,
 			"applied_custom_max":   maxBytes,
@@ -267,7 +267,7 @@ func applyStorageLimitConstraints(onePercentOfAvailable uint64) uint64 {
 	const maxLimit = 1024 * 1024 * 1024 // 1GB maximum
 
 	if onePercentOfAvailable < minLimit {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":             "CalculateAsyncStorageLimit",
 			"calculated_1_percent": onePercentOfAvailable,
 			"applied_minimum":      minLimit,
@@ -276,7 +276,7 @@ func applyStorageLimitConstraints(onePercentOfAvailable uint64) uint64 {
 	}
 
 	if onePercentOfAvailable > maxLimit {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":             "CalculateAsyncStorageLimit",
 			"calculated_1_percent": onePercentOfAvailable,
 			"applied_maximum":      maxLimit,
@@ -284,7 +284,7 @@ func applyStorageLimitConstraints(onePercentOfAvailable uint64) uint64 {
 		return maxLimit
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":             "CalculateAsyncStorageLimit",
 		"calculated_1_percent": onePercentOfAvailable,
 	}).Debug("Applied calculated 1% storage limit")
@@ -293,7 +293,7 @@ func applyStorageLimitConstraints(onePercentOfAvailable uint64) uint64 {
 
 // logStorageLimitResult logs the final calculated storage limit.
 func logStorageLimitResult(totalBytes, finalLimit uint64) {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":    "CalculateAsyncStorageLimit",
 		"total_bytes": totalBytes,
 		"final_limit": finalLimit,
@@ -303,7 +303,7 @@ func logStorageLimitResult(totalBytes, finalLimit uint64) {
 
 // EstimateMessageCapacity estimates how many messages can be stored given a byte limit
 func EstimateMessageCapacity(bytesLimit uint64) int {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":    "EstimateMessageCapacity",
 		"bytes_limit": bytesLimit,
 		"limit_mb":    bytesLimit / (1024 * 1024),
@@ -321,27 +321,27 @@ func EstimateMessageCapacity(bytesLimit uint64) int {
 	var finalCapacity int
 	if capacity < MinStorageCapacity {
 		finalCapacity = MinStorageCapacity
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":        "EstimateMessageCapacity",
 			"calculated":      capacity,
 			"applied_minimum": MinStorageCapacity,
 		}).Debug("Applied minimum capacity")
 	} else if capacity > MaxStorageCapacity {
 		finalCapacity = MaxStorageCapacity
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":        "EstimateMessageCapacity",
 			"calculated":      capacity,
 			"applied_maximum": MaxStorageCapacity,
 		}).Debug("Applied maximum capacity")
 	} else {
 		finalCapacity = capacity
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":   "EstimateMessageCapacity",
 			"calculated": capacity,
 		}).Debug("Applied calculated capacity")
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":       "EstimateMessageCapacity",
 		"bytes_limit":    bytesLimit,
 		"avg_msg_size":   avgMessageSize,
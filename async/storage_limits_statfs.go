@@ -14,7 +14,7 @@ import (
 func getFilesystemStatistics(dir string) (totalBytes, availableBytes, usedBytes uint64, err error) {
 	var stat unix.Statfs_t
 	if err := unix.Statfs(dir, &stat); err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "getFilesystemStatistics",
 			"dir":      dir,
 			"error":    err,
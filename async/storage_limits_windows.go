@@ -68,7 +68,7 @@ func callWindowsDiskSpaceAPI(pathPtr *uint16, absPath string) (totalBytes, avail
 	)
 
 	if ret == 0 {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "getWindowsDiskSpace",
 			"path":     absPath,
 			"error":    err.Error(),
@@ -80,7 +80,7 @@ func callWindowsDiskSpaceAPI(pathPtr *uint16, absPath string) (totalBytes, avail
 }
 
 func logWindowsDiskSpaceInfo(absPath string, totalBytes, availableBytes, usedBytes uint64) {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":        "getWindowsDiskSpace",
 		"path":            absPath,
 		"total_bytes":     totalBytes,
@@ -0,0 +1,212 @@
+package async
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/opd-ai/toxcore/crypto"
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultMaxStorageBytesPerRecipient is the default cap on encrypted bytes
+// stored for a single recipient (or recipient pseudonym).
+const DefaultMaxStorageBytesPerRecipient = 1024 * 1024
+
+// ErrRecipientStorageFull indicates a recipient has reached its per-recipient
+// message or byte quota. Other recipients can still receive messages.
+var ErrRecipientStorageFull = errors.New("recipient storage full")
+
+// StorageConfig configures per-recipient storage quotas, which stop a single
+// prolific sender from filling a storage node by targeting one recipient.
+type StorageConfig struct {
+	// MaxMessagesPerRecipient caps the messages stored per recipient.
+	// 0 uses the dynamic limit derived from storage capacity.
+	MaxMessagesPerRecipient int
+	// MaxStorageBytesPerRecipient caps the encrypted bytes stored per
+	// recipient. 0 uses DefaultMaxStorageBytesPerRecipient.
+	MaxStorageBytesPerRecipient int64
+}
+
+// DefaultStorageConfig returns the default storage quota configuration.
+func DefaultStorageConfig() StorageConfig {
+	return StorageConfig{
+		MaxStorageBytesPerRecipient: DefaultMaxStorageBytesPerRecipient,
+	}
+}
+
+// NewMessageStorageWithConfig creates message storage with custom
+// per-recipient quotas.
+func NewMessageStorageWithConfig(keyPair *crypto.KeyPair, dataDir string, config StorageConfig) *MessageStorage {
+	storage := NewMessageStorage(keyPair, dataDir)
+	storage.SetStorageConfig(config)
+	return storage
+}
+
+// SetStorageConfig updates the per-recipient quotas. Lowered quotas apply to
+// new messages immediately; messages already stored are evicted on the next
+// UpdateCapacity.
+func (ms *MessageStorage) SetStorageConfig(config StorageConfig) {
+	ms.SetMaxMessagesPerRecipient(config.MaxMessagesPerRecipient)
+	ms.SetMaxStorageBytesPerRecipient(config.MaxStorageBytesPerRecipient)
+}
+
+// SetMaxStorageBytesPerRecipient sets the per-recipient byte quota.
+// Use 0 to reset to DefaultMaxStorageBytesPerRecipient.
+func (ms *MessageStorage) SetMaxStorageBytesPerRecipient(limit int64) {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+	if limit > 0 {
+		ms.maxBytesPerRecip = limit
+	} else {
+		ms.maxBytesPerRecip = DefaultMaxStorageBytesPerRecipient
+	}
+}
+
+// GetMaxStorageBytesPerRecipient returns the per-recipient byte quota.
+func (ms *MessageStorage) GetMaxStorageBytesPerRecipient() int64 {
+	ms.mutex.RLock()
+	defer ms.mutex.RUnlock()
+	return ms.getRecipientByteLimit()
+}
+
+// getRecipientByteLimit returns the per-recipient byte quota. Must be called
+// with lock held.
+func (ms *MessageStorage) getRecipientByteLimit() int64 {
+	if ms.maxBytesPerRecip > 0 {
+		return ms.maxBytesPerRecip
+	}
+	return DefaultMaxStorageBytesPerRecipient
+}
+
+// checkRecipientQuota returns ErrRecipientStorageFull if storing size more
+// bytes for a recipient holding count messages and stored bytes would exceed
+// a quota. Must be called with lock held.
+func (ms *MessageStorage) checkRecipientQuota(count int, stored int64, size int, kind string) error {
+	recipientLimit := ms.getRecipientLimit()
+	if count >= recipientLimit {
+		return fmt.Errorf("%w: too many messages for %s (max %d)", ErrRecipientStorageFull, kind, recipientLimit)
+	}
+	byteLimit := ms.getRecipientByteLimit()
+	if stored+int64(size) > byteLimit {
+		return fmt.Errorf("%w: %s storage would exceed %d bytes", ErrRecipientStorageFull, kind, byteLimit)
+	}
+	return nil
+}
+
+// legacyRecipientBytes returns the encrypted bytes stored for recipientPK.
+// Must be called with lock held.
+func (ms *MessageStorage) legacyRecipientBytes(recipientPK [32]byte) int64 {
+	var total int64
+	for _, msg := range ms.recipientIndex[recipientPK] {
+		total += int64(len(msg.EncryptedData))
+	}
+	return total
+}
+
+// pseudonymMessages returns every message stored for pseudonym, oldest
+// first. Must be called with lock held.
+func (ms *MessageStorage) pseudonymMessages(pseudonym [32]byte) []*ObfuscatedAsyncMessage {
+	var messages []*ObfuscatedAsyncMessage
+	for _, epochMessages := range ms.pseudonymIndex[pseudonym] {
+		messages = append(messages, epochMessages...)
+	}
+	sort.SliceStable(messages, func(i, j int) bool {
+		return messages[i].Timestamp.Before(messages[j].Timestamp)
+	})
+	return messages
+}
+
+// pseudonymBytes returns the encrypted bytes stored for pseudonym. Must be
+// called with lock held.
+func (ms *MessageStorage) pseudonymBytes(pseudonym [32]byte) int64 {
+	var total int64
+	for _, epochMessages := range ms.pseudonymIndex[pseudonym] {
+		for _, msg := range epochMessages {
+			total += int64(len(msg.EncryptedPayload))
+		}
+	}
+	return total
+}
+
+// enforceRecipientQuotas evicts the oldest messages of every recipient that
+// is over its message or byte quota, so each can accept new messages again.
+// It returns the number of messages evicted. Must be called with lock held.
+func (ms *MessageStorage) enforceRecipientQuotas() int {
+	evicted := 0
+	for recipientPK := range ms.recipientIndex {
+		evicted += ms.evictLegacyOverQuota(recipientPK)
+	}
+	for pseudonym := range ms.pseudonymIndex {
+		evicted += ms.evictObfuscatedOverQuota(pseudonym)
+	}
+
+	if evicted > 0 {
+		pkgLog.WithFields(logrus.Fields{
+			"function": "enforceRecipientQuotas",
+			"evicted":  evicted,
+		}).Info("Evicted messages from over-quota recipients")
+	}
+	return evicted
+}
+
+// evictLegacyOverQuota removes recipientPK's oldest messages until it is
+// within quota. The recipient index is kept in arrival order.
+func (ms *MessageStorage) evictLegacyOverQuota(recipientPK [32]byte) int {
+	messages := ms.recipientIndex[recipientPK]
+	count, stored := len(messages), ms.legacyRecipientBytes(recipientPK)
+	recipientLimit, byteLimit := ms.getRecipientLimit(), ms.getRecipientByteLimit()
+
+	var evictIDs [][16]byte
+	for _, msg := range messages {
+		if count <= recipientLimit && stored <= byteLimit {
+			break
+		}
+		evictIDs = append(evictIDs, msg.ID)
+		count--
+		stored -= int64(len(msg.EncryptedData))
+	}
+
+	for _, id := range evictIDs {
+		if err := ms.logDeletionToWAL(id, recipientPK); err != nil {
+			pkgLog.WithFields(logrus.Fields{
+				"function": "evictLegacyOverQuota",
+				"error":    err.Error(),
+			}).Warn("Failed to log eviction to WAL")
+		}
+	}
+	return ms.removeLegacyMessages(evictIDs)
+}
+
+// evictObfuscatedOverQuota removes pseudonym's oldest messages until it is
+// within quota.
+func (ms *MessageStorage) evictObfuscatedOverQuota(pseudonym [32]byte) int {
+	messages := ms.pseudonymMessages(pseudonym)
+	count, stored := len(messages), ms.pseudonymBytes(pseudonym)
+	recipientLimit, byteLimit := ms.getRecipientLimit(), ms.getRecipientByteLimit()
+
+	var evictIDs [][32]byte
+	for _, msg := range messages {
+		if count <= recipientLimit && stored <= byteLimit {
+			break
+		}
+		evictIDs = append(evictIDs, msg.MessageID)
+		count--
+		stored -= int64(len(msg.EncryptedPayload))
+	}
+	return ms.removeObfuscatedMessages(evictIDs)
+}
+
+// recipientByteUsage returns the bytes stored per recipient public key and
+// per recipient pseudonym. Must be called with lock held.
+func (ms *MessageStorage) recipientByteUsage() (byRecipient, byPseudonym map[[32]byte]int64) {
+	byRecipient = make(map[[32]byte]int64, len(ms.recipientIndex))
+	for recipientPK := range ms.recipientIndex {
+		byRecipient[recipientPK] = ms.legacyRecipientBytes(recipientPK)
+	}
+	byPseudonym = make(map[[32]byte]int64, len(ms.pseudonymIndex))
+	for pseudonym := range ms.pseudonymIndex {
+		byPseudonym[pseudonym] = ms.pseudonymBytes(pseudonym)
+	}
+	return byRecipient, byPseudonym
+}
@@ -0,0 +1,114 @@
+package async
+
+import (
+	"testing"
+	"time"
+
+	"github.com/opd-ai/toxcore/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newQuotaTestStorage(t *testing.T, config StorageConfig) *MessageStorage {
+	t.Helper()
+	keyPair, err := crypto.GenerateKeyPair()
+	require.NoError(t, err)
+	storage := NewMessageStorageWithConfig(keyPair, "", config)
+	storage.SetDynamicLimitsEnabled(true)
+	return storage
+}
+
+func TestStorageConfigDefaults(t *testing.T) {
+	storage := newQuotaTestStorage(t, StorageConfig{})
+	assert.Equal(t, int64(DefaultMaxStorageBytesPerRecipient), storage.GetMaxStorageBytesPerRecipient())
+	assert.Equal(t, int64(DefaultMaxStorageBytesPerRecipient), DefaultStorageConfig().MaxStorageBytesPerRecipient)
+}
+
+func TestStoreMessageRecipientMessageQuota(t *testing.T) {
+	storage := newQuotaTestStorage(t, StorageConfig{MaxMessagesPerRecipient: 3})
+	recipient, other, sender := [32]byte{1}, [32]byte{2}, [32]byte{3}
+
+	for i := 0; i < 3; i++ {
+		_, err := storage.StoreMessage(recipient, sender, make([]byte, 10), [24]byte{byte(i)}, MessageTypeNormal)
+		require.NoError(t, err)
+	}
+
+	_, err := storage.StoreMessage(recipient, sender, make([]byte, 10), [24]byte{}, MessageTypeNormal)
+	assert.ErrorIs(t, err, ErrRecipientStorageFull)
+
+	// Other recipients are unaffected
+	_, err = storage.StoreMessage(other, sender, make([]byte, 10), [24]byte{}, MessageTypeNormal)
+	assert.NoError(t, err)
+}
+
+func TestStoreMessageRecipientByteQuota(t *testing.T) {
+	storage := newQuotaTestStorage(t, StorageConfig{MaxStorageBytesPerRecipient: 250})
+	recipient, sender := [32]byte{1}, [32]byte{3}
+
+	_, err := storage.StoreMessage(recipient, sender, make([]byte, 200), [24]byte{1}, MessageTypeNormal)
+	require.NoError(t, err)
+
+	_, err = storage.StoreMessage(recipient, sender, make([]byte, 100), [24]byte{2}, MessageTypeNormal)
+	assert.ErrorIs(t, err, ErrRecipientStorageFull)
+
+	_, err = storage.StoreMessage(recipient, sender, make([]byte, 50), [24]byte{3}, MessageTypeNormal)
+	assert.NoError(t, err)
+
+	stats := storage.GetStorageStats()
+	assert.Equal(t, int64(250), stats.RecipientBytes[recipient])
+	assert.Equal(t, int64(250), stats.MaxBytesPerRecipient)
+}
+
+func TestStoreObfuscatedMessageByteQuota(t *testing.T) {
+	storage := newQuotaTestStorage(t, StorageConfig{MaxStorageBytesPerRecipient: 150})
+	pseudonym := [32]byte{7}
+
+	newMsg := func(id byte, size int) *ObfuscatedAsyncMessage {
+		return &ObfuscatedAsyncMessage{
+			MessageID:          [32]byte{id},
+			RecipientPseudonym: pseudonym,
+			Epoch:              storage.GetEpochManager().GetCurrentEpoch(),
+			EncryptedPayload:   make([]byte, size),
+			Timestamp:          time.Now(),
+			ExpiresAt:          time.Now().Add(time.Hour),
+		}
+	}
+
+	require.NoError(t, storage.StoreObfuscatedMessage(newMsg(1, 100)))
+	assert.ErrorIs(t, storage.StoreObfuscatedMessage(newMsg(2, 100)), ErrRecipientStorageFull)
+
+	stats := storage.GetStorageStats()
+	assert.Equal(t, int64(100), stats.PseudonymBytes[pseudonym])
+}
+
+func TestUpdateCapacityEvictsOldestOverQuota(t *testing.T) {
+	storage := newQuotaTestStorage(t, StorageConfig{MaxMessagesPerRecipient: 10})
+	recipient, other, sender := [32]byte{1}, [32]byte{2}, [32]byte{3}
+
+	var ids [][16]byte
+	for i := 0; i < 5; i++ {
+		id, err := storage.StoreMessage(recipient, sender, make([]byte, 100), [24]byte{byte(i)}, MessageTypeNormal)
+		require.NoError(t, err)
+		ids = append(ids, id)
+	}
+	_, err := storage.StoreMessage(other, sender, make([]byte, 100), [24]byte{9}, MessageTypeNormal)
+	require.NoError(t, err)
+
+	// Lower the quotas below current usage
+	storage.SetStorageConfig(StorageConfig{MaxMessagesPerRecipient: 3, MaxStorageBytesPerRecipient: 250})
+	require.NoError(t, storage.UpdateCapacity())
+
+	messages, err := storage.RetrieveMessages(recipient)
+	require.NoError(t, err)
+	require.Len(t, messages, 2, "byte quota of 250 leaves two 100-byte messages")
+	remaining := map[[16]byte]bool{messages[0].ID: true, messages[1].ID: true}
+	assert.True(t, remaining[ids[3]] && remaining[ids[4]], "oldest messages should be evicted first")
+
+	otherMessages, err := storage.RetrieveMessages(other)
+	require.NoError(t, err)
+	assert.Len(t, otherMessages, 1)
+
+	stats := storage.GetStorageStats()
+	assert.Equal(t, int64(200), stats.RecipientBytes[recipient])
+	assert.Equal(t, int64(100), stats.RecipientBytes[other])
+}
@@ -118,7 +118,7 @@ func NewWriteAheadLog(config WALConfig) (*WriteAheadLog, error) {
 		lastCheckpoint: time.Now(),
 		closeDone:      make(chan struct{}),
 		checkpointSem:  make(chan struct{}, 1), // Only 1 concurrent checkpoint allowed
-		logger: logrus.WithFields(logrus.Fields{
+		logger: pkgLog.WithFields(logrus.Fields{
 			"component": "wal",
 			"directory": config.Directory,
 		}),
@@ -83,6 +83,15 @@ type AdaptationConfig struct {
 	// Stability controls
 	MinChangeBitRate uint32        // Minimum bitrate change to trigger callback (default: 5000 bps)
 	BackoffDuration  time.Duration // How long to wait after decrease before increasing (default: 5s)
+
+	// Per-stream policies used by UpdateStreamStats
+	AudioAdaptationPolicy StreamAdaptationPolicy
+	VideoAdaptationPolicy StreamAdaptationPolicy
+
+	// SacrificeVideoForAudio drops video entirely, instead of lowering the
+	// audio bitrate, when audio quality is MinAudioQuality or worse
+	SacrificeVideoForAudio bool
+	MinAudioQuality        NetworkQuality // (default: NetworkPoor)
 }
 
 // DefaultAdaptationConfig returns configuration with conservative defaults.
@@ -116,6 +125,12 @@ func DefaultAdaptationConfig() *AdaptationConfig {
 		// Stability controls to prevent oscillation
 		MinChangeBitRate: 5000,            // 5 kbps minimum change threshold
 		BackoffDuration:  5 * time.Second, // 5s backoff after decrease
+
+		// Opus tolerates more loss than VP8, whose inter-frame dependencies
+		// make every lost packet visible until the next keyframe
+		AudioAdaptationPolicy: DefaultAudioAdaptationPolicy(),
+		VideoAdaptationPolicy: DefaultVideoAdaptationPolicy(),
+		MinAudioQuality:       NetworkPoor,
 	}
 }
 
@@ -154,6 +169,17 @@ type BitrateAdapter struct {
 
 	// Time provider for deterministic testing
 	timeProvider TimeProvider
+
+	// Bandwidth estimation. limits holds the caller's configuration while
+	// config carries the caps derived from the latest estimate.
+	limits            *AdaptationConfig
+	estimator         *BandwidthEstimator
+	bandwidthCb       BandwidthEstimateCallback
+	reportedBandwidth uint32
+
+	// Per-stream adaptation state
+	lastStreamAdaptation [2]time.Time
+	videoDropped         bool
 }
 
 // NewBitrateAdapter creates a new adaptive bitrate manager.
@@ -173,7 +199,7 @@ func NewBitrateAdapter(config *AdaptationConfig, initialAudioBitRate, initialVid
 		config = DefaultAdaptationConfig()
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":          "NewBitrateAdapter",
 		"initial_audio_bps": initialAudioBitRate,
 		"initial_video_bps": initialVideoBitRate,
@@ -191,7 +217,7 @@ func NewBitrateAdapter(config *AdaptationConfig, initialAudioBitRate, initialVid
 		qualityHistory: make([]NetworkQuality, 0, 5), // Keep last 5 measurements
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":        "NewBitrateAdapter",
 		"initial_quality": adapter.currentQuality.String(),
 	}).Info("Bitrate adapter created successfully")
@@ -220,7 +246,7 @@ func (ba *BitrateAdapter) SetCallbacks(
 	ba.videoBitRateCb = videoBitRateCb
 	ba.qualityCb = qualityCb
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":         "SetCallbacks",
 		"audio_callback":   audioBitRateCb != nil,
 		"video_callback":   videoBitRateCb != nil,
@@ -263,7 +289,7 @@ func (ba *BitrateAdapter) UpdateNetworkStats(packetsSent, packetsReceived, packe
 
 // logNetworkStatsUpdate logs the incoming network statistics.
 func (ba *BitrateAdapter) logNetworkStatsUpdate(packetsSent, packetsReceived, packetsLost uint64, jitter time.Duration) {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":         "UpdateNetworkStats",
 		"packets_sent":     packetsSent,
 		"packets_received": packetsReceived,
@@ -295,7 +321,7 @@ func (ba *BitrateAdapter) handleQualityChange(newQuality NetworkQuality, lossPer
 		return false
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":     "UpdateNetworkStats",
 		"old_quality":  ba.currentQuality.String(),
 		"new_quality":  newQuality.String(),
@@ -319,7 +345,7 @@ func (ba *BitrateAdapter) handleQualityChange(newQuality NetworkQuality, lossPer
 func (ba *BitrateAdapter) performAdaptation(newQuality NetworkQuality, timestamp time.Time) (bool, error) {
 	if ba.lastAdaptation.IsZero() {
 		ba.lastAdaptation = timestamp
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":  "UpdateNetworkStats",
 			"timestamp": timestamp,
 		}).Debug("Initialized adaptation baseline timestamp")
@@ -330,7 +356,7 @@ func (ba *BitrateAdapter) performAdaptation(newQuality NetworkQuality, timestamp
 	shouldAdapt := timeSinceLastAdaptation >= ba.config.AdaptationWindow
 
 	if !shouldAdapt {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":          "UpdateNetworkStats",
 			"time_since_last":   timeSinceLastAdaptation,
 			"adaptation_window": ba.config.AdaptationWindow,
@@ -343,7 +369,7 @@ func (ba *BitrateAdapter) performAdaptation(newQuality NetworkQuality, timestamp
 		ba.lastAdaptation = timestamp
 		ba.adaptationCount++
 
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":         "UpdateNetworkStats",
 			"adaptation_count": ba.adaptationCount,
 			"new_audio_bps":    ba.audioBitRate,
@@ -359,7 +385,7 @@ func (ba *BitrateAdapter) performAdaptation(newQuality NetworkQuality, timestamp
 // Uses both packet loss and jitter to assess quality, taking the
 // worst condition of the two metrics for conservative adaptation.
 func (ba *BitrateAdapter) assessNetworkQuality(lossPercent float64, jitter time.Duration) NetworkQuality {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":     "assessNetworkQuality",
 		"loss_percent": lossPercent,
 		"jitter_ms":    jitter.Milliseconds(),
@@ -397,7 +423,7 @@ func (ba *BitrateAdapter) assessNetworkQuality(lossPercent float64, jitter time.
 		finalQuality = qualityByJitter
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":          "assessNetworkQuality",
 		"quality_by_loss":   qualityByLoss.String(),
 		"quality_by_jitter": qualityByJitter.String(),
@@ -456,7 +482,7 @@ func (ba *BitrateAdapter) adaptBitrates(quality NetworkQuality, timestamp time.T
 	oldAudioBitRate := ba.audioBitRate
 	oldVideoBitRate := ba.videoBitRate
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":      "adaptBitrates",
 		"quality":       quality.String(),
 		"current_audio": oldAudioBitRate,
@@ -464,12 +490,16 @@ func (ba *BitrateAdapter) adaptBitrates(quality NetworkQuality, timestamp time.T
 	}).Debug("Starting bitrate adaptation")
 
 	ba.applyQualityBasedAdaptation(quality, timestamp)
+	if ba.videoDropped {
+		// Video stays off until the audio stream recovers
+		ba.videoBitRate = 0
+	}
 
 	audioChanged, videoChanged := ba.triggerBitrateCallbacks(oldAudioBitRate, oldVideoBitRate)
 
 	adapted := audioChanged || videoChanged
 	if adapted {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":      "adaptBitrates",
 			"audio_changed": audioChanged,
 			"video_changed": videoChanged,
@@ -500,7 +530,7 @@ func (ba *BitrateAdapter) decreaseBitrates(timestamp time.Time) {
 	ba.audioBitRate = newAudioBitRate
 	ba.videoBitRate = newVideoBitRate
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":      "decreaseBitrates",
 		"new_audio":     ba.audioBitRate,
 		"new_video":     ba.videoBitRate,
@@ -520,7 +550,7 @@ func (ba *BitrateAdapter) conservativeBitrates() {
 	ba.videoBitRate = newVideoBitRate
 	// Audio bitrate remains unchanged for fair quality
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":  "conservativeBitrates",
 		"new_video": ba.videoBitRate,
 		"audio":     ba.audioBitRate,
@@ -544,7 +574,7 @@ func (ba *BitrateAdapter) increaseBitrates() {
 	ba.audioBitRate = newAudioBitRate
 	ba.videoBitRate = newVideoBitRate
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":      "increaseBitrates",
 		"new_audio":     ba.audioBitRate,
 		"new_video":     ba.videoBitRate,
@@ -564,7 +594,7 @@ func (ba *BitrateAdapter) canIncreaseBitrates(timestamp time.Time) bool {
 	timeSinceDecrease := timestamp.Sub(ba.lastDecrease)
 	canIncrease := timeSinceDecrease >= ba.config.BackoffDuration
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":            "canIncreaseBitrates",
 		"time_since_decrease": timeSinceDecrease,
 		"backoff_duration":    ba.config.BackoffDuration,
@@ -590,6 +620,59 @@ func (ba *BitrateAdapter) isSignificantChange(oldBitRate, newBitRate uint32) boo
 	return change >= ba.config.MinChangeBitRate
 }
 
+// SetAdaptationConfig replaces the adaptation configuration. Current
+// bitrates are clamped to the new limits, firing bitrate callbacks if they
+// change significantly. A nil config restores DefaultAdaptationConfig.
+func (ba *BitrateAdapter) SetAdaptationConfig(config *AdaptationConfig) {
+	if config == nil {
+		config = DefaultAdaptationConfig()
+	}
+	ba.mu.Lock()
+	defer ba.mu.Unlock()
+	ba.limits = config
+	ba.applyConfigLocked(config)
+}
+
+// ScaleBitrates multiplies both bitrates by factor, respecting configured
+// limits, and fires bitrate callbacks for significant changes. It is used to
+// shed load for reasons other than network quality, such as CPU pressure.
+func (ba *BitrateAdapter) ScaleBitrates(factor float64) {
+	if factor <= 0 {
+		return
+	}
+	ba.mu.Lock()
+	defer ba.mu.Unlock()
+
+	oldAudioBitRate, oldVideoBitRate := ba.audioBitRate, ba.videoBitRate
+	ba.lastDecrease = ba.getTimeProvider().Now()
+	ba.audioBitRate = clampUint32(uint32(float64(ba.audioBitRate)*factor), ba.config.MinAudioBitRate, ba.config.MaxAudioBitRate)
+	if !ba.videoDropped {
+		ba.videoBitRate = clampUint32(uint32(float64(ba.videoBitRate)*factor), ba.config.MinVideoBitRate, ba.config.MaxVideoBitRate)
+	}
+	ba.triggerBitrateCallbacks(oldAudioBitRate, oldVideoBitRate)
+}
+
+// limitsConfigLocked returns the caller-supplied configuration, which
+// bandwidth caps are derived from. Caller must hold ba.mu.
+func (ba *BitrateAdapter) limitsConfigLocked() *AdaptationConfig {
+	if ba.limits == nil {
+		ba.limits = ba.config
+	}
+	return ba.limits
+}
+
+// applyConfigLocked installs config and clamps current bitrates to its limits.
+// Caller must hold ba.mu.
+func (ba *BitrateAdapter) applyConfigLocked(config *AdaptationConfig) {
+	oldAudioBitRate, oldVideoBitRate := ba.audioBitRate, ba.videoBitRate
+	ba.config = config
+	ba.audioBitRate = clampUint32(ba.audioBitRate, config.MinAudioBitRate, config.MaxAudioBitRate)
+	if !ba.videoDropped {
+		ba.videoBitRate = clampUint32(ba.videoBitRate, config.MinVideoBitRate, config.MaxVideoBitRate)
+	}
+	ba.triggerBitrateCallbacks(oldAudioBitRate, oldVideoBitRate)
+}
+
 // GetCurrentBitrates returns current audio and video bitrates.
 func (ba *BitrateAdapter) GetCurrentBitrates() (audio, video uint32) {
 	ba.mu.RLock()
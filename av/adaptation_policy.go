@@ -0,0 +1,169 @@
+package av
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// MediaKind identifies the media stream a statistics report refers to.
+type MediaKind int
+
+const (
+	// MediaAudio is the Opus audio stream.
+	MediaAudio MediaKind = iota
+	// MediaVideo is the VP8 video stream.
+	MediaVideo
+)
+
+// String returns a human-readable media kind.
+func (k MediaKind) String() string {
+	switch k {
+	case MediaAudio:
+		return "audio"
+	case MediaVideo:
+		return "video"
+	default:
+		return "unknown"
+	}
+}
+
+// StreamAdaptationPolicy holds the degradation thresholds and step size for one stream.
+type StreamAdaptationPolicy struct {
+	PacketLossThreshold float64       // Loss % at or above which the stream is degraded
+	JitterThreshold     time.Duration // Jitter at or above which the stream is degraded
+	BitrateStep         float64       // Fractional bitrate change per adaptation
+}
+
+// DefaultAudioAdaptationPolicy returns thresholds suited to Opus with in-band FEC.
+func DefaultAudioAdaptationPolicy() StreamAdaptationPolicy {
+	return StreamAdaptationPolicy{
+		PacketLossThreshold: 5.0,
+		JitterThreshold:     150 * time.Millisecond,
+		BitrateStep:         0.1,
+	}
+}
+
+// DefaultVideoAdaptationPolicy returns thresholds suited to VP8.
+func DefaultVideoAdaptationPolicy() StreamAdaptationPolicy {
+	return StreamAdaptationPolicy{
+		PacketLossThreshold: 2.0,
+		JitterThreshold:     100 * time.Millisecond,
+		BitrateStep:         0.2,
+	}
+}
+
+// policyFor returns the configured policy for kind, falling back to the
+// defaults when the configuration leaves it unset.
+func (ba *BitrateAdapter) policyFor(kind MediaKind) StreamAdaptationPolicy {
+	if kind == MediaAudio {
+		if ba.config.AudioAdaptationPolicy == (StreamAdaptationPolicy{}) {
+			return DefaultAudioAdaptationPolicy()
+		}
+		return ba.config.AudioAdaptationPolicy
+	}
+	if ba.config.VideoAdaptationPolicy == (StreamAdaptationPolicy{}) {
+		return DefaultVideoAdaptationPolicy()
+	}
+	return ba.config.VideoAdaptationPolicy
+}
+
+// UpdateStreamStats adapts a single stream from its own statistics, such as
+// per-SSRC RTCP receiver reports. A degraded video stream lowers only the
+// video bitrate; audio keeps its configured level.
+//
+// When SacrificeVideoForAudio is set and the audio stream's quality is
+// MinAudioQuality or worse, video is dropped (bitrate 0) rather than
+// lowering audio. Video resumes at MinVideoBitRate once audio recovers.
+//
+// Returns whether either bitrate changed significantly.
+func (ba *BitrateAdapter) UpdateStreamStats(kind MediaKind, lossPercent float64, jitter time.Duration, timestamp time.Time) (bool, error) {
+	if kind != MediaAudio && kind != MediaVideo {
+		return false, fmt.Errorf("unknown media kind: %d", kind)
+	}
+
+	ba.mu.Lock()
+	defer ba.mu.Unlock()
+
+	last := ba.lastStreamAdaptation[kind]
+	if !last.IsZero() && timestamp.Sub(last) < ba.config.StatsInterval {
+		return false, nil
+	}
+	ba.lastStreamAdaptation[kind] = timestamp
+
+	policy := ba.policyFor(kind)
+	degraded := lossPercent >= policy.PacketLossThreshold || jitter >= policy.JitterThreshold
+	oldAudioBitRate, oldVideoBitRate := ba.audioBitRate, ba.videoBitRate
+
+	if kind == MediaAudio {
+		ba.adaptAudioStream(degraded, ba.assessNetworkQuality(lossPercent, jitter), policy, timestamp)
+	} else {
+		ba.adaptVideoStream(degraded, policy, timestamp)
+	}
+
+	audioChanged, videoChanged := ba.triggerBitrateCallbacks(oldAudioBitRate, oldVideoBitRate)
+	adapted := audioChanged || videoChanged
+	if adapted {
+		ba.lastAdaptation = timestamp
+		ba.adaptationCount++
+		pkgLog.WithFields(logrus.Fields{
+			"function":      "UpdateStreamStats",
+			"stream":        kind.String(),
+			"degraded":      degraded,
+			"new_audio":     ba.audioBitRate,
+			"new_video":     ba.videoBitRate,
+			"video_dropped": ba.videoDropped,
+		}).Info("Per-stream bitrate adaptation applied")
+	}
+	return adapted, nil
+}
+
+// adaptAudioStream lowers or raises the audio bitrate, or drops video in
+// favour of audio when the policy allows it.
+func (ba *BitrateAdapter) adaptAudioStream(degraded bool, quality NetworkQuality, policy StreamAdaptationPolicy, timestamp time.Time) {
+	if !degraded {
+		if ba.videoDropped {
+			ba.videoDropped = false
+			ba.videoBitRate = ba.config.MinVideoBitRate
+		}
+		if ba.canIncreaseBitrates(timestamp) {
+			next := uint32(float64(ba.audioBitRate) * (1 + policy.BitrateStep))
+			ba.audioBitRate = clampUint32(next, ba.config.MinAudioBitRate, ba.config.MaxAudioBitRate)
+		}
+		return
+	}
+
+	if ba.config.SacrificeVideoForAudio && quality >= ba.config.MinAudioQuality {
+		ba.videoDropped = true
+		ba.videoBitRate = 0
+		return
+	}
+	ba.lastDecrease = timestamp
+	next := uint32(float64(ba.audioBitRate) * (1 - policy.BitrateStep))
+	ba.audioBitRate = clampUint32(next, ba.config.MinAudioBitRate, ba.config.MaxAudioBitRate)
+}
+
+// adaptVideoStream lowers or raises the video bitrate without touching audio.
+func (ba *BitrateAdapter) adaptVideoStream(degraded bool, policy StreamAdaptationPolicy, timestamp time.Time) {
+	if ba.videoDropped {
+		return
+	}
+	if degraded {
+		ba.lastDecrease = timestamp
+		next := uint32(float64(ba.videoBitRate) * (1 - policy.BitrateStep))
+		ba.videoBitRate = clampUint32(next, ba.config.MinVideoBitRate, ba.config.MaxVideoBitRate)
+		return
+	}
+	if ba.canIncreaseBitrates(timestamp) {
+		next := uint32(float64(ba.videoBitRate) * (1 + policy.BitrateStep))
+		ba.videoBitRate = clampUint32(next, ba.config.MinVideoBitRate, ba.config.MaxVideoBitRate)
+	}
+}
+
+// IsVideoDropped reports whether video was disabled to protect audio quality.
+func (ba *BitrateAdapter) IsVideoDropped() bool {
+	ba.mu.RLock()
+	defer ba.mu.RUnlock()
+	return ba.videoDropped
+}
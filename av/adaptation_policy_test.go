@@ -0,0 +1,89 @@
+package av
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateStreamStatsVideoOnlyDegradation(t *testing.T) {
+	ba := NewBitrateAdapter(DefaultAdaptationConfig(), 48000, 1000000)
+	defer ba.Close()
+	now := time.Unix(1000, 0)
+
+	adapted, err := ba.UpdateStreamStats(MediaVideo, 4.0, 20*time.Millisecond, now)
+	require.NoError(t, err)
+	assert.True(t, adapted)
+
+	audio, video := ba.GetCurrentBitrates()
+	assert.Equal(t, uint32(48000), audio, "audio must keep its level")
+	assert.Equal(t, uint32(800000), video, "video drops by its own BitrateStep")
+
+	// Audio at 4% loss is still within its policy
+	adapted, err = ba.UpdateStreamStats(MediaAudio, 4.0, 20*time.Millisecond, now)
+	require.NoError(t, err)
+	assert.False(t, adapted)
+}
+
+func TestUpdateStreamStatsRespectsStatsInterval(t *testing.T) {
+	ba := NewBitrateAdapter(DefaultAdaptationConfig(), 48000, 1000000)
+	defer ba.Close()
+	now := time.Unix(1000, 0)
+
+	_, _ = ba.UpdateStreamStats(MediaVideo, 10.0, 0, now)
+	adapted, _ := ba.UpdateStreamStats(MediaVideo, 10.0, 0, now.Add(time.Second))
+	assert.False(t, adapted, "second report within StatsInterval is ignored")
+}
+
+func TestSacrificeVideoForAudio(t *testing.T) {
+	cfg := DefaultAdaptationConfig()
+	cfg.SacrificeVideoForAudio = true
+	ba := NewBitrateAdapter(cfg, 48000, 1000000)
+	defer ba.Close()
+	now := time.Unix(1000, 0)
+
+	adapted, err := ba.UpdateStreamStats(MediaAudio, 8.0, 200*time.Millisecond, now)
+	require.NoError(t, err)
+	assert.True(t, adapted)
+	audio, video := ba.GetCurrentBitrates()
+	assert.Equal(t, uint32(48000), audio)
+	assert.Zero(t, video)
+	assert.True(t, ba.IsVideoDropped())
+
+	// Video reports are ignored while dropped
+	_, _ = ba.UpdateStreamStats(MediaVideo, 0, 0, now.Add(10*time.Second))
+	_, video = ba.GetCurrentBitrates()
+	assert.Zero(t, video)
+
+	// Audio recovery restores video at its minimum
+	_, _ = ba.UpdateStreamStats(MediaAudio, 0, 10*time.Millisecond, now.Add(10*time.Second))
+	_, video = ba.GetCurrentBitrates()
+	assert.Equal(t, cfg.MinVideoBitRate, video)
+	assert.False(t, ba.IsVideoDropped())
+}
+
+func TestDegradedAudioWithoutSacrifice(t *testing.T) {
+	ba := NewBitrateAdapter(DefaultAdaptationConfig(), 48000, 1000000)
+	defer ba.Close()
+
+	_, err := ba.UpdateStreamStats(MediaAudio, 8.0, 0, time.Unix(1000, 0))
+	require.NoError(t, err)
+	audio, video := ba.GetCurrentBitrates()
+	assert.Equal(t, uint32(43200), audio)
+	assert.Equal(t, uint32(1000000), video)
+}
+
+func TestUpdateStreamStatsUnknownKind(t *testing.T) {
+	ba := NewBitrateAdapter(nil, 48000, 1000000)
+	_, err := ba.UpdateStreamStats(MediaKind(7), 0, 0, time.Now())
+	assert.Error(t, err)
+}
+
+func TestPolicyDefaultsForLiteralConfig(t *testing.T) {
+	ba := NewBitrateAdapter(&AdaptationConfig{MinVideoBitRate: 1, MaxVideoBitRate: 2000000}, 48000, 1000000)
+	defer ba.Close()
+	assert.Equal(t, DefaultVideoAdaptationPolicy(), ba.policyFor(MediaVideo))
+	assert.Equal(t, DefaultAudioAdaptationPolicy(), ba.policyFor(MediaAudio))
+}
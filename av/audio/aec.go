@@ -0,0 +1,203 @@
+package audio
+
+import (
+	"fmt"
+	"math"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultAECFilterLength is the default number of adaptive filter taps.
+	// At 48kHz, 256 taps model echo paths of about 5ms.
+	DefaultAECFilterLength = 256
+	// DefaultAECStepSize is the default NLMS adaptation step size.
+	DefaultAECStepSize = 0.1
+	// MaxAECFilterLength bounds the filter length to keep per-sample cost
+	// reasonable for real-time processing.
+	MaxAECFilterLength = 8192
+)
+
+// aecRegularization keeps the NLMS step bounded when the far-end signal is
+// near silent.
+const aecRegularization = 1e-6
+
+// AcousticEchoCanceller removes the far-end signal that leaks from the
+// speakers back into the microphone.
+//
+// The far-end (speaker output) signal is supplied with SetFarEnd before the
+// matching near-end (microphone) frame is processed. A normalized LMS
+// adaptive filter estimates the echo path, and its prediction of the echo is
+// subtracted from the near-end signal.
+//
+// Design decisions:
+//   - NLMS for stable adaptation independent of the far-end level
+//   - Far-end samples consumed in order, one per near-end sample; missing
+//     far-end samples are treated as silence
+//   - Buffered far-end audio capped at one second so a stalled capture path
+//     cannot grow memory without bound
+type AcousticEchoCanceller struct {
+	mu         sync.Mutex
+	weights    []float64
+	history    []float64 // Circular buffer of recent far-end samples
+	pos        int       // Index of the newest sample in history
+	energy     float64   // Sum of squares of history
+	stepSize   float64
+	sampleRate int
+	farEnd     []int16 // Far-end samples not yet matched to near-end input
+}
+
+// NewAcousticEchoCanceller creates an echo canceller.
+//
+// Parameters:
+//   - filterLength: Number of adaptive filter taps (1 to MaxAECFilterLength)
+//   - stepSize: NLMS step size, greater than 0 and less than 2
+//   - sampleRate: Sample rate of both signals in Hz
+//
+// Returns:
+//   - *AcousticEchoCanceller: New canceller with zeroed coefficients
+//   - error: Validation error if a parameter is out of range
+func NewAcousticEchoCanceller(filterLength int, stepSize float64, sampleRate int) (*AcousticEchoCanceller, error) {
+	if filterLength < 1 || filterLength > MaxAECFilterLength {
+		pkgLog.WithFields(logrus.Fields{
+			"function":      "NewAcousticEchoCanceller",
+			"filter_length": filterLength,
+		}).Error("Filter length validation failed")
+		return nil, fmt.Errorf("filter length must be between 1 and %d: %d", MaxAECFilterLength, filterLength)
+	}
+	if math.IsNaN(stepSize) || stepSize <= 0 || stepSize >= 2 {
+		pkgLog.WithFields(logrus.Fields{
+			"function":  "NewAcousticEchoCanceller",
+			"step_size": stepSize,
+		}).Error("Step size validation failed")
+		return nil, fmt.Errorf("step size must be in (0, 2): %f", stepSize)
+	}
+	if sampleRate <= 0 {
+		pkgLog.WithFields(logrus.Fields{
+			"function":    "NewAcousticEchoCanceller",
+			"sample_rate": sampleRate,
+		}).Error("Sample rate validation failed")
+		return nil, fmt.Errorf("sample rate must be positive: %d", sampleRate)
+	}
+
+	pkgLog.WithFields(logrus.Fields{
+		"function":      "NewAcousticEchoCanceller",
+		"filter_length": filterLength,
+		"step_size":     stepSize,
+		"sample_rate":   sampleRate,
+	}).Info("Acoustic echo canceller created")
+
+	return &AcousticEchoCanceller{
+		weights:    make([]float64, filterLength),
+		history:    make([]float64, filterLength),
+		stepSize:   stepSize,
+		sampleRate: sampleRate,
+	}, nil
+}
+
+// SetFarEnd queues far-end samples, the audio played through the speakers,
+// to be matched against the next near-end samples passed to Process.
+func (a *AcousticEchoCanceller) SetFarEnd(samples []int16) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.farEnd = append(a.farEnd, samples...)
+	if excess := len(a.farEnd) - a.sampleRate; excess > 0 {
+		pkgLog.WithFields(logrus.Fields{
+			"function":        "AcousticEchoCanceller.SetFarEnd",
+			"dropped_samples": excess,
+		}).Warn("Far-end buffer full, dropping oldest samples")
+		a.farEnd = append(a.farEnd[:0], a.farEnd[excess:]...)
+	}
+}
+
+// Process subtracts the estimated echo from the near-end samples and adapts
+// the filter towards the residual.
+func (a *AcousticEchoCanceller) Process(samples []int16) ([]int16, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	out := make([]int16, len(samples))
+	for i, s := range samples {
+		var far int16
+		if i < len(a.farEnd) {
+			far = a.farEnd[i]
+		}
+		out[i] = clampSample(32768.0 * a.cancelSampleLocked(float64(far)/32768.0, float64(s)/32768.0))
+	}
+	consumed := len(samples)
+	if consumed > len(a.farEnd) {
+		consumed = len(a.farEnd)
+	}
+	a.farEnd = append(a.farEnd[:0], a.farEnd[consumed:]...)
+	return out, nil
+}
+
+// cancelSampleLocked pushes one far-end sample into the history, returns the
+// near-end sample minus the echo estimate and updates the filter. Caller
+// must hold a.mu.
+func (a *AcousticEchoCanceller) cancelSampleLocked(far, near float64) float64 {
+	n := len(a.history)
+	a.pos = (a.pos + 1) % n
+	old := a.history[a.pos]
+	a.history[a.pos] = far
+	a.energy += far*far - old*old
+	if a.energy < 0 {
+		a.energy = 0 // Guard against rounding drift
+	}
+
+	// weights[k] applies to the far-end sample k steps in the past.
+	var estimate float64
+	for k, idx := 0, a.pos; k < n; k++ {
+		estimate += a.weights[k] * a.history[idx]
+		if idx--; idx < 0 {
+			idx = n - 1
+		}
+	}
+
+	residual := near - estimate
+	g := a.stepSize * residual / (a.energy + aecRegularization)
+	for k, idx := 0, a.pos; k < n; k++ {
+		a.weights[k] += g * a.history[idx]
+		if idx--; idx < 0 {
+			idx = n - 1
+		}
+	}
+	return residual
+}
+
+// Apply is an alias of Process.
+func (a *AcousticEchoCanceller) Apply(samples []int16) ([]int16, error) {
+	return a.Process(samples)
+}
+
+// Reset zeroes the filter coefficients and discards buffered far-end audio,
+// e.g. when the echo path changes after switching audio devices.
+func (a *AcousticEchoCanceller) Reset() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for i := range a.weights {
+		a.weights[i] = 0
+		a.history[i] = 0
+	}
+	a.pos = 0
+	a.energy = 0
+	a.farEnd = a.farEnd[:0]
+
+	pkgLog.WithFields(logrus.Fields{
+		"function": "AcousticEchoCanceller.Reset",
+	}).Debug("Echo canceller reset")
+}
+
+// GetName returns the effect name.
+func (a *AcousticEchoCanceller) GetName() string {
+	return "AcousticEchoCanceller"
+}
+
+// Close releases resources used by the canceller.
+func (a *AcousticEchoCanceller) Close() error {
+	a.Reset()
+	return nil
+}
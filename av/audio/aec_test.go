@@ -0,0 +1,176 @@
+package audio
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// echoScenario produces far-end white noise and the near-end signal picked up
+// by the microphone: the far end through a two-tap echo path plus an
+// optional near-end voice.
+type echoScenario struct {
+	rng     *rand.Rand
+	history []float64
+	phase   int
+	voice   float64 // Near-end sine amplitude
+}
+
+func newEchoScenario(voice float64) *echoScenario {
+	return &echoScenario{rng: rand.New(rand.NewSource(1)), history: make([]float64, 64), voice: voice}
+}
+
+// frame returns the next far-end frame, the echo it causes and the near-end
+// microphone frame.
+func (s *echoScenario) frame(n int) (farEnd, echo, near []int16) {
+	farEnd, echo, near = make([]int16, n), make([]int16, n), make([]int16, n)
+	for i := 0; i < n; i++ {
+		copy(s.history[1:], s.history)
+		s.history[0] = s.rng.Float64()*16000 - 8000
+		e := 0.5*s.history[20] - 0.25*s.history[45]
+		v := s.voice * math.Sin(2*math.Pi*300*float64(s.phase)/48000)
+		s.phase++
+
+		farEnd[i] = int16(s.history[0])
+		echo[i] = int16(e)
+		near[i] = clampSample(e + v)
+	}
+	return farEnd, echo, near
+}
+
+func signalPower(samples []int16) float64 {
+	var sum float64
+	for _, s := range samples {
+		sum += float64(s) * float64(s)
+	}
+	return sum / float64(len(samples))
+}
+
+func TestAcousticEchoCanceller_NewAcousticEchoCanceller(t *testing.T) {
+	tests := []struct {
+		name         string
+		filterLength int
+		stepSize     float64
+		sampleRate   int
+		wantErr      bool
+	}{
+		{"defaults", DefaultAECFilterLength, DefaultAECStepSize, 48000, false},
+		{"zero filter length", 0, DefaultAECStepSize, 48000, true},
+		{"filter too long", MaxAECFilterLength + 1, DefaultAECStepSize, 48000, true},
+		{"zero step size", DefaultAECFilterLength, 0, 48000, true},
+		{"unstable step size", DefaultAECFilterLength, 2, 48000, true},
+		{"NaN step size", DefaultAECFilterLength, math.NaN(), 48000, true},
+		{"zero sample rate", DefaultAECFilterLength, DefaultAECStepSize, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewAcousticEchoCanceller(tt.filterLength, tt.stepSize, tt.sampleRate)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewAcousticEchoCanceller() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestAcousticEchoCanceller_EchoAttenuation measures the echo return loss
+// enhancement after the filter has converged.
+func TestAcousticEchoCanceller_EchoAttenuation(t *testing.T) {
+	aec, err := NewAcousticEchoCanceller(DefaultAECFilterLength, DefaultAECStepSize, 48000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	scenario := newEchoScenario(0)
+
+	var echoPower, residualPower float64
+	for frame := 0; frame < 75; frame++ {
+		farEnd, echo, near := scenario.frame(960)
+		aec.SetFarEnd(farEnd)
+		out, err := aec.Apply(near)
+		if err != nil {
+			t.Fatalf("Apply() frame %d error = %v", frame, err)
+		}
+		if frame >= 50 { // Measure the last 500ms
+			echoPower += signalPower(echo)
+			residualPower += signalPower(out)
+		}
+	}
+
+	erle := 10 * math.Log10(echoPower/residualPower)
+	if erle < 20 {
+		t.Errorf("expected at least 20 dB echo attenuation, got %.1f dB", erle)
+	}
+}
+
+// TestAcousticEchoCanceller_PreservesNearEnd checks that near-end speech
+// survives while the echo is removed.
+func TestAcousticEchoCanceller_PreservesNearEnd(t *testing.T) {
+	aec, err := NewAcousticEchoCanceller(DefaultAECFilterLength, DefaultAECStepSize, 48000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	scenario := newEchoScenario(2000)
+
+	var voicePower, errorPower float64
+	for frame := 0; frame < 75; frame++ {
+		farEnd, echo, near := scenario.frame(960)
+		aec.SetFarEnd(farEnd)
+		out, _ := aec.Apply(near)
+		if frame < 50 {
+			continue
+		}
+		for i := range out {
+			voice := float64(near[i]) - float64(echo[i])
+			diff := float64(out[i]) - voice
+			voicePower += voice * voice
+			errorPower += diff * diff
+		}
+	}
+
+	if snr := 10 * math.Log10(voicePower/errorPower); snr < 10 {
+		t.Errorf("expected near-end voice preserved with at least 10 dB SNR, got %.1f dB", snr)
+	}
+}
+
+func TestAcousticEchoCanceller_Reset(t *testing.T) {
+	aec, err := NewAcousticEchoCanceller(64, DefaultAECStepSize, 48000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	scenario := newEchoScenario(0)
+	for frame := 0; frame < 10; frame++ {
+		farEnd, _, near := scenario.frame(960)
+		aec.SetFarEnd(farEnd)
+		aec.Apply(near)
+	}
+	aec.SetFarEnd(make([]int16, 100))
+
+	aec.Reset()
+	for i, w := range aec.weights {
+		if w != 0 {
+			t.Fatalf("coefficient %d not zeroed: %v", i, w)
+		}
+	}
+	if len(aec.farEnd) != 0 {
+		t.Errorf("expected buffered far-end audio discarded, got %d samples", len(aec.farEnd))
+	}
+
+	// With zeroed coefficients the first sample passes through unchanged.
+	_, _, near := scenario.frame(960)
+	out, err := aec.Apply(near)
+	if err != nil || out[0] != near[0] {
+		t.Errorf("expected unmodified first sample after reset, got %d want %d (%v)", out[0], near[0], err)
+	}
+}
+
+func TestAcousticEchoCanceller_FarEndBufferBounded(t *testing.T) {
+	aec, err := NewAcousticEchoCanceller(16, DefaultAECStepSize, 8000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 20; i++ {
+		aec.SetFarEnd(make([]int16, 960))
+	}
+	if n := len(aec.farEnd); n != 8000 {
+		t.Errorf("expected far-end buffer capped at one second (8000 samples), got %d", n)
+	}
+}
@@ -0,0 +1,191 @@
+// Package audio provides audio processing capabilities for ToxAV.
+//
+// This file implements Opus application modes, which tune the encoder for
+// speech, general audio such as music, or minimal latency.
+package audio
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/opd-ai/magnum"
+	"github.com/sirupsen/logrus"
+)
+
+// ErrInvalidApplicationMode indicates an unknown Opus application mode.
+var ErrInvalidApplicationMode = errors.New("invalid opus application mode")
+
+// OpusApplicationMode selects what the Opus encoder is optimized for.
+type OpusApplicationMode int
+
+const (
+	// OpusApplicationVoice optimizes for speech intelligibility
+	// (OPUS_APPLICATION_VOIP). This is the default mode.
+	OpusApplicationVoice OpusApplicationMode = iota
+	// OpusApplicationAudio optimizes for fidelity of music and other
+	// non-speech audio (OPUS_APPLICATION_AUDIO).
+	OpusApplicationAudio
+	// OpusApplicationLowDelay minimizes algorithmic delay at the expense
+	// of quality (OPUS_APPLICATION_RESTRICTED_LOWDELAY).
+	OpusApplicationLowDelay
+)
+
+// String returns the name of the application mode.
+func (m OpusApplicationMode) String() string {
+	switch m {
+	case OpusApplicationVoice:
+		return "voice"
+	case OpusApplicationAudio:
+		return "audio"
+	case OpusApplicationLowDelay:
+		return "lowdelay"
+	default:
+		return fmt.Sprintf("OpusApplicationMode(%d)", int(m))
+	}
+}
+
+// IsValid reports whether m is a known application mode.
+func (m OpusApplicationMode) IsValid() bool {
+	return m >= OpusApplicationVoice && m <= OpusApplicationLowDelay
+}
+
+// applicationProfile holds the encoder settings applied for a mode.
+type applicationProfile struct {
+	application   magnum.Application
+	frameDuration magnum.FrameDuration
+	complexity    int
+	bandwidth     func(sampleRate uint32) magnum.Bandwidth
+}
+
+// profile returns the encoder settings for the mode.
+//
+// Voice caps the bandwidth at wideband, where speech energy lies, and keeps
+// 20ms frames. Audio encodes the full bandwidth of the sample rate at the
+// highest complexity. LowDelay halves the frame duration to 10ms and lowers
+// the complexity to keep per-frame encoding time short.
+func (m OpusApplicationMode) profile() (applicationProfile, error) {
+	switch m {
+	case OpusApplicationVoice:
+		return applicationProfile{
+			application:   magnum.ApplicationVoIP,
+			frameDuration: magnum.FrameDuration20ms,
+			complexity:    10,
+			bandwidth: func(sampleRate uint32) magnum.Bandwidth {
+				if sampleRate > 16000 {
+					return magnum.BandwidthWideband
+				}
+				return GetBandwidthFromSampleRate(sampleRate)
+			},
+		}, nil
+	case OpusApplicationAudio:
+		return applicationProfile{
+			application:   magnum.ApplicationAudio,
+			frameDuration: magnum.FrameDuration20ms,
+			complexity:    10,
+			bandwidth:     GetBandwidthFromSampleRate,
+		}, nil
+	case OpusApplicationLowDelay:
+		return applicationProfile{
+			application:   magnum.ApplicationLowDelay,
+			frameDuration: magnum.FrameDuration10ms,
+			complexity:    5,
+			bandwidth:     GetBandwidthFromSampleRate,
+		}, nil
+	default:
+		return applicationProfile{}, fmt.Errorf("%w: %d", ErrInvalidApplicationMode, int(m))
+	}
+}
+
+// SetApplicationMode switches the encoder to the given application mode.
+//
+// magnum fixes the application when an encoder is created, so the
+// underlying encoder is recreated with the current bit rate and then
+// configured with the frame duration, complexity, and bandwidth of the
+// mode. Samples buffered by the previous encoder are discarded. In
+// OpusApplicationLowDelay callers should pass 10ms frames to Encode.
+func (e *MagnumOpusEncoder) SetApplicationMode(mode OpusApplicationMode) error {
+	profile, err := mode.profile()
+	if err != nil {
+		return err
+	}
+
+	enc, err := magnum.NewEncoderWithApplication(int(e.sampleRate), e.channels, profile.application)
+	if err != nil {
+		return fmt.Errorf("failed to create magnum encoder: %w", err)
+	}
+	enc.SetBitrate(int(e.bitRate))
+	configureCodecPath(enc, e.sampleRate)
+	if err := enc.SetFrameDuration(profile.frameDuration); err != nil {
+		return fmt.Errorf("failed to set frame duration: %w", err)
+	}
+	enc.SetComplexity(profile.complexity)
+	enc.SetBandwidth(profile.bandwidth(e.sampleRate))
+
+	e.enc = enc
+	e.mode = mode
+
+	pkgLog.WithFields(logrus.Fields{
+		"function":       "MagnumOpusEncoder.SetApplicationMode",
+		"mode":           mode.String(),
+		"frame_duration": profile.frameDuration.Milliseconds(),
+		"complexity":     profile.complexity,
+	}).Info("Encoder application mode updated")
+
+	return nil
+}
+
+// ApplicationMode returns the encoder's current application mode.
+func (e *MagnumOpusEncoder) ApplicationMode() OpusApplicationMode {
+	return e.mode
+}
+
+// FrameSize returns the number of samples per channel in one encoded frame.
+func (e *MagnumOpusEncoder) FrameSize() int {
+	return e.enc.FrameDuration().Samples(int(e.sampleRate))
+}
+
+// applicationModeSetter is implemented by encoders that support Opus
+// application modes.
+type applicationModeSetter interface {
+	SetApplicationMode(mode OpusApplicationMode) error
+}
+
+// SetApplicationMode switches the processor's encoder to the given Opus
+// application mode.
+//
+// Parameters:
+//   - mode: The application mode to use for outgoing audio
+//
+// Returns:
+//   - error: Any error that occurred while reconfiguring the encoder
+func (p *Processor) SetApplicationMode(mode OpusApplicationMode) error {
+	setter, ok := p.encoder.(applicationModeSetter)
+	if !ok {
+		return fmt.Errorf("audio encoder does not support application modes")
+	}
+	if err := setter.SetApplicationMode(mode); err != nil {
+		return fmt.Errorf("failed to set application mode: %w", err)
+	}
+	p.mode = mode
+	return nil
+}
+
+// GetApplicationMode returns the processor's current Opus application mode.
+func (p *Processor) GetApplicationMode() OpusApplicationMode {
+	return p.mode
+}
+
+// SetApplicationMode switches the codec to the given Opus application mode.
+//
+// Parameters:
+//   - mode: OpusApplicationVoice, OpusApplicationAudio, or OpusApplicationLowDelay
+//
+// Returns:
+//   - error: ErrInvalidApplicationMode for unknown modes, or any error that
+//     occurred while reconfiguring the encoder
+func (c *OpusCodec) SetApplicationMode(mode OpusApplicationMode) error {
+	if c.processor == nil {
+		return fmt.Errorf("codec processor not initialized")
+	}
+	return c.processor.SetApplicationMode(mode)
+}
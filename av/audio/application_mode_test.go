@@ -0,0 +1,61 @@
+package audio
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/opd-ai/magnum"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpusCodecSetApplicationMode(t *testing.T) {
+	tests := []struct {
+		mode        OpusApplicationMode
+		application magnum.Application
+		frameSize   int
+		bandwidth   magnum.Bandwidth
+	}{
+		{OpusApplicationVoice, magnum.ApplicationVoIP, 960, magnum.BandwidthWideband},
+		{OpusApplicationAudio, magnum.ApplicationAudio, 960, magnum.BandwidthFullband},
+		{OpusApplicationLowDelay, magnum.ApplicationLowDelay, 480, magnum.BandwidthFullband},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode.String(), func(t *testing.T) {
+			codec := NewOpusCodec()
+			defer codec.Close()
+			require.NoError(t, codec.SetBitRate(32000))
+
+			require.NoError(t, codec.SetApplicationMode(tt.mode))
+			assert.Equal(t, tt.mode, codec.processor.GetApplicationMode())
+
+			encoder := codec.processor.encoder.(*MagnumOpusEncoder)
+			assert.Equal(t, tt.application, encoder.enc.Application())
+			assert.Equal(t, tt.frameSize, encoder.FrameSize())
+			assert.Equal(t, tt.bandwidth, encoder.enc.Bandwidth())
+			assert.Equal(t, uint32(32000), encoder.bitRate, "bit rate should survive the switch")
+
+			pcm := make([]int16, tt.frameSize)
+			for i := range pcm {
+				pcm[i] = int16((i % 100) * 100)
+			}
+			encoded, err := codec.EncodeFrame(pcm, 48000)
+			require.NoError(t, err)
+			decoded, _, err := codec.DecodeFrame(encoded)
+			require.NoError(t, err)
+			assert.NotEmpty(t, decoded)
+		})
+	}
+}
+
+func TestOpusCodecSetApplicationModeInvalid(t *testing.T) {
+	codec := NewOpusCodec()
+	defer codec.Close()
+
+	err := codec.SetApplicationMode(OpusApplicationMode(42))
+	assert.True(t, errors.Is(err, ErrInvalidApplicationMode))
+	assert.Equal(t, OpusApplicationVoice, codec.processor.GetApplicationMode())
+	assert.False(t, OpusApplicationMode(42).IsValid())
+	assert.Equal(t, "OpusApplicationMode(42)", OpusApplicationMode(42).String())
+}
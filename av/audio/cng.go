@@ -0,0 +1,228 @@
+package audio
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultComfortNoiseLevelDB is the default comfort noise level in dBFS.
+	DefaultComfortNoiseLevelDB = -65.0
+	// ComfortNoiseWindow is how much recent audio the background noise
+	// estimate is drawn from.
+	ComfortNoiseWindow = 2 * time.Second
+)
+
+const (
+	// maxComfortNoiseDB caps the estimated noise floor so speech energy in
+	// the estimation window cannot produce loud comfort noise.
+	maxComfortNoiseDB = -40.0
+	// comfortNoiseCutoffHz is the low-pass cutoff that band-limits the noise.
+	comfortNoiseCutoffHz = 4000.0
+)
+
+// frameEnergy records the RMS level of one observed frame.
+type frameEnergy struct {
+	rms     float64
+	samples int
+}
+
+// ComfortNoiseGenerator fills frames suppressed by VoiceActivityDetector with
+// low-level noise so the far end hears room ambience instead of dead
+// silence, which users mistake for a dropped call.
+//
+// During speech the generator is transparent and only observes the frames.
+// It tracks the minimum frame energy over the last ComfortNoiseWindow of
+// audio as an estimate of the background noise. When it receives an empty,
+// suppressed frame it produces a frame of band-limited white noise at the
+// estimated level, or at the configured level when that is louder.
+//
+// It must be placed after VoiceActivityDetector in an EffectChain; effects
+// between the two are skipped for suppressed frames.
+//
+// Design decisions:
+//   - Running minimum rather than mean, so speech does not raise the estimate
+//   - One-pole low-pass filter for band limiting, cheap and free of artifacts
+//   - Generated frames match the length of the last observed frame
+type ComfortNoiseGenerator struct {
+	mu          sync.Mutex
+	levelDB     float64
+	sampleRate  int
+	frameSize   int
+	history     []frameEnergy
+	historySize int // Total samples in history
+	rng         *rand.Rand
+	alpha       float64 // Low-pass smoothing factor
+	filterState float64
+}
+
+// NewComfortNoiseGenerator creates a comfort noise generator.
+//
+// Parameters:
+//   - levelDB: Minimum comfort noise level in dBFS (DefaultComfortNoiseLevelDB is -65)
+//   - sampleRate: Sample rate of the processed frames in Hz
+//
+// Returns:
+//   - *ComfortNoiseGenerator: New generator expecting 20ms frames until one is observed
+//   - error: Validation error if a parameter is invalid
+func NewComfortNoiseGenerator(levelDB float64, sampleRate int) (*ComfortNoiseGenerator, error) {
+	if err := validateComfortNoiseLevel(levelDB); err != nil {
+		return nil, err
+	}
+	if sampleRate <= 0 {
+		pkgLog.WithFields(logrus.Fields{
+			"function":    "NewComfortNoiseGenerator",
+			"sample_rate": sampleRate,
+		}).Error("Sample rate validation failed")
+		return nil, fmt.Errorf("sample rate must be positive: %d", sampleRate)
+	}
+
+	pkgLog.WithFields(logrus.Fields{
+		"function":    "NewComfortNoiseGenerator",
+		"level_db":    levelDB,
+		"sample_rate": sampleRate,
+	}).Info("Comfort noise generator created")
+
+	return &ComfortNoiseGenerator{
+		levelDB:    levelDB,
+		sampleRate: sampleRate,
+		frameSize:  sampleRate / 50,
+		rng:        rand.New(rand.NewSource(time.Now().UnixNano())),
+		alpha:      1 - math.Exp(-2*math.Pi*math.Min(comfortNoiseCutoffHz, float64(sampleRate)/2)/float64(sampleRate)),
+	}, nil
+}
+
+// validateComfortNoiseLevel checks that a level is a finite dBFS value.
+func validateComfortNoiseLevel(levelDB float64) error {
+	if math.IsNaN(levelDB) || math.IsInf(levelDB, 0) || levelDB > 0 {
+		return fmt.Errorf("comfort noise level must be a finite dBFS value <= 0: %f", levelDB)
+	}
+	return nil
+}
+
+// Process returns voiced frames unchanged after updating the noise estimate,
+// and replaces an empty frame with comfort noise.
+func (c *ComfortNoiseGenerator) Process(samples []int16) ([]int16, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(samples) > 0 {
+		c.observeLocked(samples)
+		return samples, nil
+	}
+
+	levelDB := c.targetLevelLocked()
+	pkgLog.WithFields(logrus.Fields{
+		"function":   "ComfortNoiseGenerator.Process",
+		"level_db":   levelDB,
+		"frame_size": c.frameSize,
+	}).Debug("Generating comfort noise for suppressed frame")
+	return c.generateLocked(c.frameSize, levelDB), nil
+}
+
+// Apply is an alias of Process.
+func (c *ComfortNoiseGenerator) Apply(samples []int16) ([]int16, error) {
+	return c.Process(samples)
+}
+
+// fillsSuppressedFrames marks the generator as receiving suppressed frames
+// from EffectChain.
+func (c *ComfortNoiseGenerator) fillsSuppressedFrames() {}
+
+// observeLocked records the frame energy, dropping frames older than the
+// estimation window. Caller must hold c.mu.
+func (c *ComfortNoiseGenerator) observeLocked(samples []int16) {
+	var sum float64
+	for _, s := range samples {
+		sum += float64(s) * float64(s)
+	}
+	c.history = append(c.history, frameEnergy{rms: math.Sqrt(sum / float64(len(samples))), samples: len(samples)})
+	c.historySize += len(samples)
+	c.frameSize = len(samples)
+
+	window := int(ComfortNoiseWindow.Seconds() * float64(c.sampleRate))
+	for len(c.history) > 1 && c.historySize-c.history[0].samples >= window {
+		c.historySize -= c.history[0].samples
+		c.history = c.history[1:]
+	}
+}
+
+// noiseFloorLocked returns the minimum frame RMS in the window in dBFS, or
+// -Inf when no audio has been observed. Caller must hold c.mu.
+func (c *ComfortNoiseGenerator) noiseFloorLocked() float64 {
+	if len(c.history) == 0 {
+		return math.Inf(-1)
+	}
+	minRMS := c.history[0].rms
+	for _, f := range c.history[1:] {
+		minRMS = math.Min(minRMS, f.rms)
+	}
+	if minRMS == 0 {
+		return math.Inf(-1)
+	}
+	return 20 * math.Log10(minRMS/32768.0)
+}
+
+// targetLevelLocked returns the comfort noise level: the estimated noise
+// floor, capped at maxComfortNoiseDB and no quieter than the configured
+// level. Caller must hold c.mu.
+func (c *ComfortNoiseGenerator) targetLevelLocked() float64 {
+	return math.Max(c.levelDB, math.Min(c.noiseFloorLocked(), maxComfortNoiseDB))
+}
+
+// generateLocked produces n samples of low-pass filtered white noise scaled
+// to levelDB RMS. Caller must hold c.mu.
+func (c *ComfortNoiseGenerator) generateLocked(n int, levelDB float64) []int16 {
+	noise := make([]float64, n)
+	var sum float64
+	for i := range noise {
+		c.filterState += c.alpha * (c.rng.Float64()*2 - 1 - c.filterState)
+		noise[i] = c.filterState
+		sum += c.filterState * c.filterState
+	}
+
+	out := make([]int16, n)
+	rms := math.Sqrt(sum / float64(n))
+	if rms == 0 {
+		return out
+	}
+	scale := 32768.0 * math.Pow(10, levelDB/20) / rms
+	for i, v := range noise {
+		out[i] = clampSample(v * scale)
+	}
+	return out
+}
+
+// SetLevelDB changes the minimum comfort noise level in dBFS.
+func (c *ComfortNoiseGenerator) SetLevelDB(levelDB float64) error {
+	if err := validateComfortNoiseLevel(levelDB); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.levelDB = levelDB
+	return nil
+}
+
+// GetNoiseFloorDB returns the background noise estimated from the last
+// ComfortNoiseWindow of audio in dBFS, or -Inf before any audio is observed.
+func (c *ComfortNoiseGenerator) GetNoiseFloorDB() float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.noiseFloorLocked()
+}
+
+// GetName returns the effect name.
+func (c *ComfortNoiseGenerator) GetName() string {
+	return "ComfortNoiseGenerator"
+}
+
+// Close releases resources used by the generator.
+func (c *ComfortNoiseGenerator) Close() error {
+	return nil
+}
@@ -0,0 +1,142 @@
+package audio
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// noiseFrame returns a 20ms frame at 48kHz of white noise with the given RMS
+// level in dBFS.
+func noiseFrame(rng *rand.Rand, levelDB float64) []int16 {
+	frame := make([]int16, 960)
+	// Uniform noise in [-a, a] has an RMS of a/sqrt(3).
+	a := 32768 * math.Pow(10, levelDB/20) * math.Sqrt(3)
+	for i := range frame {
+		frame[i] = int16((rng.Float64()*2 - 1) * a)
+	}
+	return frame
+}
+
+func TestComfortNoiseGenerator_NewComfortNoiseGenerator(t *testing.T) {
+	if _, err := NewComfortNoiseGenerator(DefaultComfortNoiseLevelDB, 48000); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := NewComfortNoiseGenerator(1, 48000); err == nil {
+		t.Error("expected error for positive level")
+	}
+	if _, err := NewComfortNoiseGenerator(DefaultComfortNoiseLevelDB, -1); err == nil {
+		t.Error("expected error for negative sample rate")
+	}
+}
+
+func TestComfortNoiseGenerator_Transparent(t *testing.T) {
+	cng, err := NewComfortNoiseGenerator(DefaultComfortNoiseLevelDB, 48000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	voice := vadFrame(8000)
+	out, err := cng.Apply(voice)
+	if err != nil || len(out) != len(voice) {
+		t.Fatalf("expected voiced frame unchanged, got %d samples (%v)", len(out), err)
+	}
+	for i := range out {
+		if out[i] != voice[i] {
+			t.Fatalf("sample %d modified: %d != %d", i, out[i], voice[i])
+		}
+	}
+}
+
+func TestComfortNoiseGenerator_DefaultLevel(t *testing.T) {
+	cng, err := NewComfortNoiseGenerator(DefaultComfortNoiseLevelDB, 48000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cng.Apply(make([]int16, 480)) // Digital silence does not raise the level
+
+	out, err := cng.Apply(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(out) != 480 {
+		t.Fatalf("expected a frame matching the last observed length, got %d", len(out))
+	}
+	if level := frameEnergyDB(out); math.Abs(level-DefaultComfortNoiseLevelDB) > 1 {
+		t.Errorf("expected comfort noise near %.0f dBFS, got %.1f", DefaultComfortNoiseLevelDB, level)
+	}
+
+	// Band-limited noise is correlated between neighbouring samples.
+	var lag0, lag1 float64
+	for i := 1; i < len(out); i++ {
+		lag0 += float64(out[i]) * float64(out[i])
+		lag1 += float64(out[i]) * float64(out[i-1])
+	}
+	if lag1/lag0 < 0.2 {
+		t.Errorf("expected low-pass filtered noise, lag-1 autocorrelation %.2f", lag1/lag0)
+	}
+}
+
+func TestComfortNoiseGenerator_TracksBackgroundNoise(t *testing.T) {
+	cng, err := NewComfortNoiseGenerator(DefaultComfortNoiseLevelDB, 48000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rng := rand.New(rand.NewSource(1))
+
+	// Room noise at -55 dBFS with speech on top in half the frames.
+	for i := 0; i < 100; i++ {
+		frame := noiseFrame(rng, -55)
+		if i%2 == 0 {
+			frame = vadFrame(8000)
+		}
+		cng.Apply(frame)
+	}
+	if floor := cng.GetNoiseFloorDB(); math.Abs(floor+55) > 1 {
+		t.Errorf("expected noise floor estimate near -55 dBFS, got %.1f", floor)
+	}
+	out, _ := cng.Apply([]int16{})
+	if level := frameEnergyDB(out); math.Abs(level+55) > 1 {
+		t.Errorf("expected comfort noise to match the room at -55 dBFS, got %.1f", level)
+	}
+
+	// After more than two seconds in a noisier room the estimate follows.
+	for i := 0; i < 110; i++ {
+		cng.Apply(noiseFrame(rng, -45))
+	}
+	if floor := cng.GetNoiseFloorDB(); math.Abs(floor+45) > 1 {
+		t.Errorf("expected estimate to forget audio older than two seconds, got %.1f", floor)
+	}
+}
+
+func TestComfortNoiseGenerator_AfterVADInChain(t *testing.T) {
+	vad, err := NewVoiceActivityDetector(DefaultVADThresholdDB, 48000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := vad.SetMinSilenceDuration(0); err != nil {
+		t.Fatal(err)
+	}
+	gain, err := NewGainEffect(4.0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cng, err := NewComfortNoiseGenerator(DefaultComfortNoiseLevelDB, 48000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chain := NewEffectChain()
+	chain.AddEffect(vad)
+	chain.AddEffect(gain)
+	chain.AddEffect(cng)
+
+	out, err := chain.Process(make([]int16, 960))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(out) != 960 {
+		t.Fatalf("expected the suppressed frame filled with comfort noise, got %d samples", len(out))
+	}
+	if level := frameEnergyDB(out); math.Abs(level-DefaultComfortNoiseLevelDB) > 1 {
+		t.Errorf("expected comfort noise near %.0f dBFS without gain applied, got %.1f", DefaultComfortNoiseLevelDB, level)
+	}
+}
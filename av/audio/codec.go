@@ -25,7 +25,7 @@ type OpusCodec struct {
 // Initializes the codec with a standard audio processor configured
 // for Opus-compatible settings (48kHz sample rate, appropriate bit rates).
 func NewOpusCodec() *OpusCodec {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "NewOpusCodec",
 	}).Info("Creating new Opus codec instance")
 
@@ -34,7 +34,7 @@ func NewOpusCodec() *OpusCodec {
 		processor: processor,
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "NewOpusCodec",
 	}).Info("Opus codec created successfully")
 
@@ -54,14 +54,14 @@ func NewOpusCodec() *OpusCodec {
 //   - []byte: Encoded audio frame
 //   - error: Any error that occurred during encoding
 func (c *OpusCodec) EncodeFrame(pcm []int16, sampleRate uint32) ([]byte, error) {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":     "OpusCodec.EncodeFrame",
 		"sample_count": len(pcm),
 		"sample_rate":  sampleRate,
 	}).Debug("Encoding PCM audio frame with Opus codec")
 
 	if c.processor == nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "OpusCodec.EncodeFrame",
 			"error":    "processor not initialized",
 		}).Error("Codec processor validation failed")
@@ -70,14 +70,14 @@ func (c *OpusCodec) EncodeFrame(pcm []int16, sampleRate uint32) ([]byte, error)
 
 	result, err := c.processor.ProcessOutgoing(pcm, sampleRate)
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "OpusCodec.EncodeFrame",
 			"error":    err.Error(),
 		}).Error("Audio frame encoding failed")
 		return nil, err
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":    "OpusCodec.EncodeFrame",
 		"input_size":  len(pcm),
 		"output_size": len(result),
@@ -100,13 +100,13 @@ func (c *OpusCodec) EncodeFrame(pcm []int16, sampleRate uint32) ([]byte, error)
 //   - uint32: Audio sample rate in Hz
 //   - error: Any error that occurred during decoding
 func (c *OpusCodec) DecodeFrame(data []byte) ([]int16, uint32, error) {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":  "OpusCodec.DecodeFrame",
 		"data_size": len(data),
 	}).Debug("Decoding Opus audio frame to PCM")
 
 	if c.processor == nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "OpusCodec.DecodeFrame",
 			"error":    "processor not initialized",
 		}).Error("Codec processor validation failed")
@@ -115,14 +115,14 @@ func (c *OpusCodec) DecodeFrame(data []byte) ([]int16, uint32, error) {
 
 	pcm, sampleRate, err := c.processor.ProcessIncoming(data)
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "OpusCodec.DecodeFrame",
 			"error":    err.Error(),
 		}).Error("Audio frame decoding failed")
 		return nil, 0, err
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":    "OpusCodec.DecodeFrame",
 		"input_size":  len(data),
 		"output_size": len(pcm),
@@ -137,13 +137,13 @@ func (c *OpusCodec) DecodeFrame(data []byte) ([]int16, uint32, error) {
 // Configures both encoder and any future decoder settings to use
 // the specified bit rate.
 func (c *OpusCodec) SetBitRate(bitRate uint32) error {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "OpusCodec.SetBitRate",
 		"bit_rate": bitRate,
 	}).Info("Setting Opus codec bit rate")
 
 	if c.processor == nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "OpusCodec.SetBitRate",
 			"error":    "processor not initialized",
 		}).Error("Codec processor validation failed")
@@ -152,7 +152,7 @@ func (c *OpusCodec) SetBitRate(bitRate uint32) error {
 
 	err := c.processor.SetBitRate(bitRate)
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "OpusCodec.SetBitRate",
 			"bit_rate": bitRate,
 			"error":    err.Error(),
@@ -160,7 +160,7 @@ func (c *OpusCodec) SetBitRate(bitRate uint32) error {
 		return err
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "OpusCodec.SetBitRate",
 		"bit_rate": bitRate,
 	}).Info("Opus codec bit rate updated successfully")
@@ -173,7 +173,7 @@ func (c *OpusCodec) SetBitRate(bitRate uint32) error {
 // Opus supports multiple sample rates, but 48kHz is recommended for VoIP.
 func (c *OpusCodec) GetSupportedSampleRates() []uint32 {
 	rates := []uint32{8000, 12000, 16000, 24000, 48000}
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":        "OpusCodec.GetSupportedSampleRates",
 		"supported_rates": rates,
 	}).Debug("Retrieving supported sample rates")
@@ -185,7 +185,7 @@ func (c *OpusCodec) GetSupportedSampleRates() []uint32 {
 // Opus supports a wide range of bit rates suitable for different use cases.
 func (c *OpusCodec) GetSupportedBitRates() []uint32 {
 	rates := []uint32{8000, 16000, 32000, 64000, 96000, 128000, 256000, 512000}
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":           "OpusCodec.GetSupportedBitRates",
 		"supported_bitrates": rates,
 	}).Debug("Retrieving supported bit rates")
@@ -196,7 +196,7 @@ func (c *OpusCodec) GetSupportedBitRates() []uint32 {
 //
 // Opus requires specific frame durations: 2.5, 5, 10, 20, 40, or 60 ms.
 func (c *OpusCodec) ValidateFrameSize(frameSize int, sampleRate uint32, channels int) error {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":    "OpusCodec.ValidateFrameSize",
 		"frame_size":  frameSize,
 		"sample_rate": sampleRate,
@@ -210,7 +210,7 @@ func (c *OpusCodec) ValidateFrameSize(frameSize int, sampleRate uint32, channels
 	validDurations := []float32{2.5, 5.0, 10.0, 20.0, 40.0, 60.0}
 	for _, duration := range validDurations {
 		if frameDurationMs == duration {
-			logrus.WithFields(logrus.Fields{
+			pkgLog.WithFields(logrus.Fields{
 				"function":       "OpusCodec.ValidateFrameSize",
 				"frame_duration": frameDurationMs,
 				"is_valid":       true,
@@ -219,7 +219,7 @@ func (c *OpusCodec) ValidateFrameSize(frameSize int, sampleRate uint32, channels
 		}
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":        "OpusCodec.ValidateFrameSize",
 		"frame_size":      frameSize,
 		"frame_duration":  frameDurationMs,
@@ -233,24 +233,24 @@ func (c *OpusCodec) ValidateFrameSize(frameSize int, sampleRate uint32, channels
 
 // Close releases codec resources.
 func (c *OpusCodec) Close() error {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "OpusCodec.Close",
 	}).Info("Closing Opus codec and releasing resources")
 
 	if c.processor != nil {
 		err := c.processor.Close()
 		if err != nil {
-			logrus.WithFields(logrus.Fields{
+			pkgLog.WithFields(logrus.Fields{
 				"function": "OpusCodec.Close",
 				"error":    err.Error(),
 			}).Error("Failed to close codec processor")
 			return err
 		}
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "OpusCodec.Close",
 		}).Info("Opus codec closed successfully")
 	} else {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "OpusCodec.Close",
 		}).Debug("No processor to close")
 	}
@@ -262,7 +262,7 @@ func (c *OpusCodec) Close() error {
 //
 // Maps sample rates to Opus bandwidth definitions for optimal encoding.
 func GetBandwidthFromSampleRate(sampleRate uint32) magnum.Bandwidth {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":    "GetBandwidthFromSampleRate",
 		"sample_rate": sampleRate,
 	}).Debug("Mapping sample rate to Opus bandwidth")
@@ -282,14 +282,14 @@ func GetBandwidthFromSampleRate(sampleRate uint32) magnum.Bandwidth {
 	default:
 		// Default to fullband for unsupported rates
 		bandwidth = magnum.BandwidthFullband
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":    "GetBandwidthFromSampleRate",
 			"sample_rate": sampleRate,
 			"warning":     "unsupported sample rate, defaulting to fullband",
 		}).Warn("Unsupported sample rate detected")
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":    "GetBandwidthFromSampleRate",
 		"sample_rate": sampleRate,
 		"bandwidth":   bandwidth,
@@ -23,6 +23,12 @@
 //	encoded, err := codec.EncodeFrame(pcmSamples, 48000)
 //	decoded, err := codec.DecodeFrame(opusData, 48000)
 //
+// The encoder is tuned for speech by default. SetApplicationMode switches it
+// to OpusApplicationAudio for music or OpusApplicationLowDelay for minimal
+// latency; low-delay mode encodes 10ms frames instead of 20ms:
+//
+//	err := codec.SetApplicationMode(audio.OpusApplicationAudio)
+//
 // ## Processor
 //
 // The main audio processing pipeline combining encoding, decoding, resampling,
@@ -57,6 +63,8 @@
 //   - GainEffect: Volume adjustment with clipping protection
 //   - AutoGainEffect: Automatic gain control (AGC) for consistent volume
 //   - NoiseSuppressionEffect: Spectral subtraction-based noise reduction
+//   - SpatialAudioEffect: 3D positioning of a mono voice into stereo output
+//   - WatermarkEffect: Inaudible spread-spectrum watermark for leak tracing
 //   - EffectChain: Sequential effect processing pipeline
 //
 // Example of building an effects chain:
@@ -68,6 +76,29 @@
 //
 //	processed, err := chain.Process(samples)
 //
+// ## Spatial Audio
+//
+// SpatialAudioEffect places a voice around the listener using inter-aural
+// delay and level differences, or impulse responses from an HRTFDatabase set
+// with SetHRTFDatabase. SpatialMixer combines the voices of a group call,
+// each at the position given to SetPosition:
+//
+//	mixer, err := audio.NewSpatialMixer(48000)
+//	mixer.SetPosition(peerID, -1.0, 0, 1.0) // front left
+//	stereo, err := mixer.Mix(map[uint32][]int16{peerID: monoFrame})
+//
+// ## Watermarking
+//
+// WatermarkEffect embeds a short payload, such as a participant ID, at just
+// under 0.1% of the signal level. A conference bridge can mark each
+// participant's outgoing mix differently and later identify the source of a
+// leaked recording with ExtractWatermark. At about 4 bits per second, a
+// 2-byte payload needs 20 s of audio:
+//
+//	mark, err := audio.NewWatermarkEffect([]byte{0x01, 0x2a}, 48000)
+//	chain.AddEffect(mark)
+//	payload, ok := audio.ExtractWatermark(recording, 48000)
+//
 // # Thread Safety
 //
 // All components in this package are designed for concurrent use:
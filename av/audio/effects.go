@@ -31,6 +31,13 @@ type AudioEffect interface {
 	Close() error
 }
 
+// suppressedFrameFiller is implemented by effects that synthesize audio for
+// frames suppressed by an earlier effect, such as ComfortNoiseGenerator.
+// EffectChain passes empty frames only to these effects.
+type suppressedFrameFiller interface {
+	fillsSuppressedFrames()
+}
+
 // GainEffect implements basic audio gain (volume) control.
 //
 // Provides linear gain adjustment with clipping prevention.
@@ -54,13 +61,13 @@ type GainEffect struct {
 //   - *GainEffect: New gain effect instance
 //   - error: Validation error if gain is invalid
 func NewGainEffect(gain float64) (*GainEffect, error) {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "NewGainEffect",
 		"gain":     gain,
 	}).Info("Creating new gain effect")
 
 	if gain < 0.0 {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "NewGainEffect",
 			"gain":     gain,
 			"error":    "gain cannot be negative",
@@ -68,7 +75,7 @@ func NewGainEffect(gain float64) (*GainEffect, error) {
 		return nil, fmt.Errorf("gain cannot be negative: %f", gain)
 	}
 	if gain > 4.0 {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "NewGainEffect",
 			"gain":     gain,
 			"error":    "gain too high (max 4.0)",
@@ -76,7 +83,7 @@ func NewGainEffect(gain float64) (*GainEffect, error) {
 		return nil, fmt.Errorf("gain too high (max 4.0): %f", gain)
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "NewGainEffect",
 		"gain":     gain,
 	}).Info("Gain effect created successfully")
@@ -102,13 +109,13 @@ func (g *GainEffect) Process(samples []int16) ([]int16, error) {
 	gain := g.gain
 	g.mu.RUnlock()
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":     "GainEffect.Process",
 		"sample_count": len(samples),
 		"gain":         gain,
 	}).Debug("Processing audio samples with gain control")
 	if len(samples) == 0 {
-		logrus.WithFields(logrus.Fields{"function": "GainEffect.Process"}).Debug("Empty sample buffer, no processing needed")
+		pkgLog.WithFields(logrus.Fields{"function": "GainEffect.Process"}).Debug("Empty sample buffer, no processing needed")
 		return samples, nil
 	}
 	clippedCount := 0
@@ -119,7 +126,7 @@ func (g *GainEffect) Process(samples []int16) ([]int16, error) {
 			clippedCount++
 		}
 	}
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":      "GainEffect.Process",
 		"sample_count":  len(samples),
 		"gain":          gain,
@@ -127,7 +134,7 @@ func (g *GainEffect) Process(samples []int16) ([]int16, error) {
 	}).Debug("Gain processing completed")
 
 	if clippedCount > 0 {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":      "GainEffect.Process",
 			"clipped_count": clippedCount,
 			"total_samples": len(samples),
@@ -170,7 +177,7 @@ func (g *GainEffect) GetName() string {
 //   - error: Validation error if gain is invalid
 func (g *GainEffect) SetGain(gain float64) error {
 	if gain < 0.0 {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "GainEffect.SetGain",
 			"gain":     gain,
 			"error":    "gain cannot be negative",
@@ -178,7 +185,7 @@ func (g *GainEffect) SetGain(gain float64) error {
 		return fmt.Errorf("gain cannot be negative: %f", gain)
 	}
 	if gain > 4.0 {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "GainEffect.SetGain",
 			"gain":     gain,
 			"error":    "gain too high (max 4.0)",
@@ -191,7 +198,7 @@ func (g *GainEffect) SetGain(gain float64) error {
 	g.gain = gain
 	g.mu.Unlock()
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "GainEffect.SetGain",
 		"old_gain": oldGain,
 		"new_gain": gain,
@@ -206,7 +213,7 @@ func (g *GainEffect) GetGain() float64 {
 	gain := g.gain
 	g.mu.RUnlock()
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "GainEffect.GetGain",
 		"gain":     gain,
 	}).Debug("Retrieving current gain value")
@@ -219,7 +226,7 @@ func (g *GainEffect) Close() error {
 	gain := g.gain
 	g.mu.RUnlock()
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "GainEffect.Close",
 		"gain":     gain,
 	}).Debug("Closing gain effect (no resources to release)")
@@ -265,7 +272,7 @@ func NewAutoGainEffect() *AutoGainEffect {
 		maxGain:     4.0,    // Maximum 400% gain (+12dB)
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":     "NewAutoGainEffect",
 		"target_level": agc.targetLevel,
 		"min_gain":     agc.minGain,
@@ -289,13 +296,13 @@ func NewAutoGainEffect() *AutoGainEffect {
 //   - []int16: Processed samples with AGC applied
 //   - error: Processing error (should not occur in normal operation)
 func (a *AutoGainEffect) Process(samples []int16) ([]int16, error) {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":     "AutoGainEffect.Process",
 		"sample_count": len(samples),
 	}).Debug("Processing audio samples with automatic gain control")
 
 	if len(samples) == 0 {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "AutoGainEffect.Process",
 		}).Debug("Empty sample buffer, no processing needed")
 		return samples, nil
@@ -321,7 +328,7 @@ func (a *AutoGainEffect) Process(samples []int16) ([]int16, error) {
 	newPeakLevel := a.peakLevel
 	a.mu.Unlock()
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":       "AutoGainEffect.Process",
 		"sample_count":   len(samples),
 		"peak_measured":  peak,
@@ -436,7 +443,7 @@ func (a *AutoGainEffect) GetCurrentGain() float64 {
 	peakLevel := a.peakLevel
 	a.mu.RUnlock()
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":     "AutoGainEffect.GetCurrentGain",
 		"current_gain": currentGain,
 		"peak_level":   peakLevel,
@@ -456,14 +463,14 @@ func (a *AutoGainEffect) SetTargetLevel(level float64) error {
 	oldTarget := a.targetLevel
 	a.mu.RUnlock()
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":   "AutoGainEffect.SetTargetLevel",
 		"old_target": oldTarget,
 		"new_target": level,
 	}).Info("Updating auto gain target level")
 
 	if level < 0.0 || level > 1.0 {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "AutoGainEffect.SetTargetLevel",
 			"level":    level,
 			"error":    "target level must be between 0.0 and 1.0",
@@ -475,7 +482,7 @@ func (a *AutoGainEffect) SetTargetLevel(level float64) error {
 	a.targetLevel = level
 	a.mu.Unlock()
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":   "AutoGainEffect.SetTargetLevel",
 		"new_target": level,
 	}).Info("Auto gain target level updated successfully")
@@ -490,7 +497,7 @@ func (a *AutoGainEffect) Close() error {
 	peakLevel := a.peakLevel
 	a.mu.RUnlock()
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":     "AutoGainEffect.Close",
 		"current_gain": currentGain,
 		"peak_level":   peakLevel,
@@ -517,7 +524,7 @@ type EffectChain struct {
 // Returns:
 //   - *EffectChain: New empty effect chain
 func NewEffectChain() *EffectChain {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "NewEffectChain",
 	}).Info("Creating new audio effect chain")
 
@@ -538,14 +545,14 @@ func (e *EffectChain) AddEffect(effect AudioEffect) {
 	e.effects = append(e.effects, effect)
 	e.mu.Unlock()
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":     "EffectChain.AddEffect",
 		"effect_name":  effect.GetName(),
 		"effect_count": effectCount,
 		"new_position": effectCount,
 	}).Info("Adding effect to audio chain")
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":     "EffectChain.AddEffect",
 		"effect_name":  effect.GetName(),
 		"effect_count": effectCount + 1,
@@ -555,7 +562,11 @@ func (e *EffectChain) AddEffect(effect AudioEffect) {
 // Process applies all effects in the chain sequentially.
 //
 // Processes audio through each effect in order. If any effect returns
-// an error, processing stops and the error is returned.
+// an error, processing stops and the error is returned. An effect that
+// returns an empty or nil frame, such as VoiceActivityDetector suppressing
+// silence, causes the remaining effects to be skipped, except those that
+// fill suppressed frames such as ComfortNoiseGenerator. If the frame is
+// still empty at the end, an empty frame is returned.
 //
 // Parameters:
 //   - samples: Input PCM samples to process
@@ -569,25 +580,34 @@ func (e *EffectChain) Process(samples []int16) ([]int16, error) {
 	copy(effects, e.effects)
 	e.mu.RUnlock()
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":     "EffectChain.Process",
 		"sample_count": len(samples),
 		"effect_count": len(effects),
 	}).Debug("Processing audio through effect chain")
 	if len(effects) == 0 {
-		logrus.WithFields(logrus.Fields{"function": "EffectChain.Process"}).Debug("No effects in chain, returning samples unchanged")
+		pkgLog.WithFields(logrus.Fields{"function": "EffectChain.Process"}).Debug("No effects in chain, returning samples unchanged")
 		return samples, nil
 	}
 	currentSamples := samples
 	for i, effect := range effects {
+		if len(currentSamples) == 0 {
+			if _, ok := effect.(suppressedFrameFiller); !ok {
+				continue
+			}
+		}
 		var err error
 		currentSamples, err = e.processEffect(currentSamples, effect, i)
 		if err != nil {
 			return nil, err
 		}
 	}
+	if len(currentSamples) == 0 {
+		pkgLog.WithFields(logrus.Fields{"function": "EffectChain.Process"}).Debug("Frame suppressed by effect chain")
+		return []int16{}, nil
+	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":        "EffectChain.Process",
 		"input_samples":   len(samples),
 		"output_samples":  len(currentSamples),
@@ -600,12 +620,12 @@ func (e *EffectChain) Process(samples []int16) ([]int16, error) {
 // processEffect applies one effect with consistent logging and wrapping.
 func (e *EffectChain) processEffect(samples []int16, effect AudioEffect, index int) ([]int16, error) {
 	name := effect.GetName()
-	logrus.WithFields(logrus.Fields{"function": "EffectChain.Process", "effect_index": index, "effect_name": name, "sample_count": len(samples)}).Debug("Processing samples through effect")
+	pkgLog.WithFields(logrus.Fields{"function": "EffectChain.Process", "effect_index": index, "effect_name": name, "sample_count": len(samples)}).Debug("Processing samples through effect")
 	processedSamples, err := effect.Process(samples)
 	if err == nil {
 		return processedSamples, nil
 	}
-	logrus.WithFields(logrus.Fields{"function": "EffectChain.Process", "effect_index": index, "effect_name": name, "error": err.Error()}).Error("Effect processing failed")
+	pkgLog.WithFields(logrus.Fields{"function": "EffectChain.Process", "effect_index": index, "effect_name": name, "error": err.Error()}).Error("Effect processing failed")
 	return nil, fmt.Errorf("effect %d (%s) failed: %w", index, name, err)
 }
 
@@ -615,7 +635,7 @@ func (e *EffectChain) GetEffectCount() int {
 	count := len(e.effects)
 	e.mu.RUnlock()
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":     "EffectChain.GetEffectCount",
 		"effect_count": count,
 	}).Debug("Retrieving effect chain count")
@@ -631,7 +651,7 @@ func (e *EffectChain) GetEffectNames() []string {
 	}
 	e.mu.RUnlock()
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":     "EffectChain.GetEffectNames",
 		"effect_count": len(names),
 		"effect_names": names,
@@ -649,7 +669,7 @@ func (e *EffectChain) Clear() error {
 	e.effects = nil
 	e.mu.Unlock()
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":     "EffectChain.Clear",
 		"effect_count": len(effects),
 	}).Info("Clearing all effects from chain")
@@ -657,14 +677,14 @@ func (e *EffectChain) Clear() error {
 	var errors []error
 
 	for i, effect := range effects {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":     "EffectChain.Clear",
 			"effect_index": i,
 			"effect_name":  effect.GetName(),
 		}).Debug("Closing effect")
 
 		if err := effect.Close(); err != nil {
-			logrus.WithFields(logrus.Fields{
+			pkgLog.WithFields(logrus.Fields{
 				"function":     "EffectChain.Clear",
 				"effect_index": i,
 				"effect_name":  effect.GetName(),
@@ -675,14 +695,14 @@ func (e *EffectChain) Clear() error {
 	}
 
 	if len(errors) > 0 {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":    "EffectChain.Clear",
 			"error_count": len(errors),
 		}).Error("Multiple errors occurred during effect chain clear")
 		return fmt.Errorf("multiple close errors: %v", errors)
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "EffectChain.Clear",
 	}).Info("Effect chain cleared successfully")
 
@@ -691,19 +711,19 @@ func (e *EffectChain) Clear() error {
 
 // Close releases all effect resources.
 func (e *EffectChain) Close() error {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":     "EffectChain.Close",
 		"effect_count": len(e.effects),
 	}).Info("Closing effect chain")
 
 	err := e.Clear()
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "EffectChain.Close",
 			"error":    err.Error(),
 		}).Error("Failed to close effect chain")
 	} else {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "EffectChain.Close",
 		}).Info("Effect chain closed successfully")
 	}
@@ -746,7 +766,7 @@ type NoiseSuppressionEffect struct {
 //   - *NoiseSuppressionEffect: New noise suppression effect instance
 //   - error: Validation error if parameters are invalid
 func NewNoiseSuppressionEffect(suppressionLevel float64, frameSize int) (*NoiseSuppressionEffect, error) {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":         "NewNoiseSuppressionEffect",
 		"suppressionLevel": suppressionLevel,
 		"frameSize":        frameSize,
@@ -763,7 +783,7 @@ func NewNoiseSuppressionEffect(suppressionLevel float64, frameSize int) (*NoiseS
 	overlapSize := frameSize / 2
 	window := createHanningWindow(frameSize)
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":         "NewNoiseSuppressionEffect",
 		"suppressionLevel": suppressionLevel,
 		"frameSize":        frameSize,
@@ -775,7 +795,7 @@ func NewNoiseSuppressionEffect(suppressionLevel float64, frameSize int) (*NoiseS
 
 func validateSuppressionLevel(level float64) error {
 	if level < 0.0 || level > 1.0 {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":         "NewNoiseSuppressionEffect",
 			"suppressionLevel": level,
 			"error":            "suppression level must be between 0.0 and 1.0",
@@ -787,7 +807,7 @@ func validateSuppressionLevel(level float64) error {
 
 func validateNoiseFrameSize(frameSize int) error {
 	if frameSize < 64 || frameSize > 4096 || (frameSize&(frameSize-1)) != 0 {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":  "NewNoiseSuppressionEffect",
 			"frameSize": frameSize,
 			"error":     "frame size must be power of 2 between 64 and 4096",
@@ -844,7 +864,7 @@ func (ns *NoiseSuppressionEffect) Process(samples []int16) ([]int16, error) {
 	ns.mu.Lock()
 	initialized := ns.initialized
 	frameCount := ns.frameCount
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":    "NoiseSuppressionEffect.Process",
 		"sampleCount": len(samples),
 		"initialized": initialized,
@@ -855,7 +875,7 @@ func (ns *NoiseSuppressionEffect) Process(samples []int16) ([]int16, error) {
 	ns.mu.Unlock()
 	result := buildInt16Samples(processedSamples)
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":      "NoiseSuppressionEffect.Process",
 		"inputSamples":  len(samples),
 		"outputSamples": len(result),
@@ -985,7 +1005,7 @@ func (ns *NoiseSuppressionEffect) updateNoiseFloorEstimation(magnitude []float64
 		ns.frameCount++
 		if ns.frameCount >= 10 {
 			ns.initialized = true
-			logrus.WithFields(logrus.Fields{
+			pkgLog.WithFields(logrus.Fields{
 				"function": "updateNoiseFloorEstimation",
 			}).Info("Noise floor estimation completed")
 		}
@@ -1148,7 +1168,7 @@ func (ns *NoiseSuppressionEffect) Close() error {
 	ns.mu.Lock()
 	defer ns.mu.Unlock()
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "NoiseSuppressionEffect.Close",
 	}).Info("Closing noise suppression effect")
 
@@ -1159,7 +1179,7 @@ func (ns *NoiseSuppressionEffect) Close() error {
 	ns.spectrumBuffer = nil
 	ns.windowBuffer = nil
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "NoiseSuppressionEffect.Close",
 	}).Info("Noise suppression effect closed successfully")
 
@@ -40,6 +40,7 @@ type MagnumOpusEncoder struct {
 	bitRate    uint32
 	sampleRate uint32
 	channels   int
+	mode       OpusApplicationMode
 }
 
 // NewMagnumOpusEncoder creates a new Opus encoder using the magnum library.
@@ -49,16 +50,16 @@ type MagnumOpusEncoder struct {
 //   - channels: Number of audio channels (1 for mono, 2 for stereo)
 //   - bitRate: Target encoding bit rate in bits per second
 func NewMagnumOpusEncoder(sampleRate, bitRate uint32, channels int) (*MagnumOpusEncoder, error) {
-	logrus.WithFields(logrus.Fields{"function": "NewMagnumOpusEncoder", "sample_rate": sampleRate, "bit_rate": bitRate, "channels": channels}).Info("Creating new Magnum Opus encoder")
+	pkgLog.WithFields(logrus.Fields{"function": "NewMagnumOpusEncoder", "sample_rate": sampleRate, "bit_rate": bitRate, "channels": channels}).Info("Creating new Magnum Opus encoder")
 	enc, err := magnum.NewEncoderWithApplication(int(sampleRate), channels, magnum.ApplicationVoIP)
 	if err != nil {
-		logrus.WithFields(logrus.Fields{"function": "NewMagnumOpusEncoder", "sample_rate": sampleRate, "channels": channels, "error": err.Error()}).Error("Failed to create magnum encoder")
+		pkgLog.WithFields(logrus.Fields{"function": "NewMagnumOpusEncoder", "sample_rate": sampleRate, "channels": channels, "error": err.Error()}).Error("Failed to create magnum encoder")
 		return nil, fmt.Errorf("failed to create magnum encoder: %w", err)
 	}
 	enc.SetBitrate(int(bitRate))
 	configureCodecPath(enc, sampleRate)
 	encoder := &MagnumOpusEncoder{enc: enc, bitRate: bitRate, sampleRate: sampleRate, channels: channels}
-	logrus.WithFields(logrus.Fields{"function": "NewMagnumOpusEncoder", "sample_rate": encoder.sampleRate, "bit_rate": encoder.bitRate, "channels": encoder.channels}).Info("Magnum Opus encoder created successfully")
+	pkgLog.WithFields(logrus.Fields{"function": "NewMagnumOpusEncoder", "sample_rate": encoder.sampleRate, "bit_rate": encoder.bitRate, "channels": encoder.channels}).Info("Magnum Opus encoder created successfully")
 	return encoder, nil
 }
 
@@ -66,7 +67,7 @@ func NewMagnumOpusEncoder(sampleRate, bitRate uint32, channels int) (*MagnumOpus
 func configureCodecPath(enc *magnum.Encoder, sampleRate uint32) {
 	enableCodec := func(name string, enable func() error) {
 		if err := enable(); err != nil {
-			logrus.WithFields(logrus.Fields{"function": "NewMagnumOpusEncoder", "sample_rate": sampleRate, "error": err.Error()}).Warn("Failed to enable " + name + " codec path, continuing with default encoder mode")
+			pkgLog.WithFields(logrus.Fields{"function": "NewMagnumOpusEncoder", "sample_rate": sampleRate, "error": err.Error()}).Warn("Failed to enable " + name + " codec path, continuing with default encoder mode")
 		}
 	}
 	switch sampleRate {
@@ -82,7 +83,7 @@ func configureCodecPath(enc *magnum.Encoder, sampleRate uint32) {
 // The magnum encoder expects 20ms frames. This method passes the PCM data
 // to the encoder which buffers and returns encoded packets when ready.
 func (e *MagnumOpusEncoder) Encode(pcm []int16, sampleRate uint32) ([]byte, error) {
-	logrus.WithFields(logrus.Fields{"function": "MagnumOpusEncoder.Encode", "pcm_length": len(pcm), "sample_rate": sampleRate, "expected_rate": e.sampleRate, "bit_rate": e.bitRate}).Debug("Encoding PCM audio data with Opus")
+	pkgLog.WithFields(logrus.Fields{"function": "MagnumOpusEncoder.Encode", "pcm_length": len(pcm), "sample_rate": sampleRate, "expected_rate": e.sampleRate, "bit_rate": e.bitRate}).Debug("Encoding PCM audio data with Opus")
 	if err := e.validateSampleRate(sampleRate); err != nil {
 		return nil, err
 	}
@@ -90,7 +91,7 @@ func (e *MagnumOpusEncoder) Encode(pcm []int16, sampleRate uint32) ([]byte, erro
 	if err != nil {
 		return nil, err
 	}
-	logrus.WithFields(logrus.Fields{"function": "MagnumOpusEncoder.Encode", "input_size": len(pcm), "output_size": len(packet), "sample_rate": sampleRate, "bit_rate": e.bitRate}).Debug("Opus encoding completed successfully")
+	pkgLog.WithFields(logrus.Fields{"function": "MagnumOpusEncoder.Encode", "input_size": len(pcm), "output_size": len(packet), "sample_rate": sampleRate, "bit_rate": e.bitRate}).Debug("Opus encoding completed successfully")
 	return packet, nil
 }
 
@@ -99,7 +100,7 @@ func (e *MagnumOpusEncoder) validateSampleRate(sampleRate uint32) error {
 	if sampleRate == e.sampleRate {
 		return nil
 	}
-	logrus.WithFields(logrus.Fields{"function": "MagnumOpusEncoder.Encode", "expected_rate": e.sampleRate, "actual_rate": sampleRate, "error": "sample rate mismatch"}).Error("Sample rate validation failed")
+	pkgLog.WithFields(logrus.Fields{"function": "MagnumOpusEncoder.Encode", "expected_rate": e.sampleRate, "actual_rate": sampleRate, "error": "sample rate mismatch"}).Error("Sample rate validation failed")
 	return fmt.Errorf("sample rate mismatch: expected %d, got %d", e.sampleRate, sampleRate)
 }
 
@@ -107,7 +108,7 @@ func (e *MagnumOpusEncoder) validateSampleRate(sampleRate uint32) error {
 func (e *MagnumOpusEncoder) encodePacket(pcm []int16) ([]byte, error) {
 	packet, err := e.enc.Encode(pcm)
 	if err != nil {
-		logrus.WithFields(logrus.Fields{"function": "MagnumOpusEncoder.Encode", "error": err.Error()}).Error("Opus encoding failed")
+		pkgLog.WithFields(logrus.Fields{"function": "MagnumOpusEncoder.Encode", "error": err.Error()}).Error("Opus encoding failed")
 		return nil, fmt.Errorf("opus encode failed: %w", err)
 	}
 	return e.flushPacket(packet, pcm)
@@ -118,7 +119,7 @@ func (e *MagnumOpusEncoder) flushPacket(packet []byte, pcm []int16) ([]byte, err
 	if packet == nil {
 		flushed, err := e.enc.Flush()
 		if err != nil {
-			logrus.WithFields(logrus.Fields{"function": "MagnumOpusEncoder.Encode", "error": err.Error()}).Error("Opus flush failed")
+			pkgLog.WithFields(logrus.Fields{"function": "MagnumOpusEncoder.Encode", "error": err.Error()}).Error("Opus flush failed")
 			return nil, fmt.Errorf("opus flush failed: %w", err)
 		}
 		packet = flushed
@@ -126,13 +127,13 @@ func (e *MagnumOpusEncoder) flushPacket(packet []byte, pcm []int16) ([]byte, err
 	if packet != nil {
 		return packet, nil
 	}
-	logrus.WithFields(logrus.Fields{"function": "MagnumOpusEncoder.Encode", "pcm_length": len(pcm)}).Warn("Encoder returned nil packet after flush (insufficient data for frame)")
+	pkgLog.WithFields(logrus.Fields{"function": "MagnumOpusEncoder.Encode", "pcm_length": len(pcm)}).Warn("Encoder returned nil packet after flush (insufficient data for frame)")
 	return nil, fmt.Errorf("encoder returned no packet: input may be too short for a complete frame")
 }
 
 // SetBitRate updates the target bit rate.
 func (e *MagnumOpusEncoder) SetBitRate(bitRate uint32) error {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":     "MagnumOpusEncoder.SetBitRate",
 		"old_bit_rate": e.bitRate,
 		"new_bit_rate": bitRate,
@@ -141,7 +142,7 @@ func (e *MagnumOpusEncoder) SetBitRate(bitRate uint32) error {
 	e.enc.SetBitrate(int(bitRate))
 	e.bitRate = bitRate
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "MagnumOpusEncoder.SetBitRate",
 		"bit_rate": e.bitRate,
 	}).Info("Encoder bit rate updated successfully")
@@ -151,13 +152,13 @@ func (e *MagnumOpusEncoder) SetBitRate(bitRate uint32) error {
 
 // Close releases encoder resources.
 func (e *MagnumOpusEncoder) Close() error {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":    "MagnumOpusEncoder.Close",
 		"sample_rate": e.sampleRate,
 		"bit_rate":    e.bitRate,
 	}).Info("Closing Magnum Opus encoder")
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "MagnumOpusEncoder.Close",
 	}).Info("Magnum Opus encoder closed successfully")
 
@@ -178,6 +179,7 @@ type Processor struct {
 	sampleRate  uint32
 	bitRate     uint32
 	channels    int
+	mode        OpusApplicationMode
 }
 
 // NewProcessor creates a new audio processor instance.
@@ -188,15 +190,15 @@ type Processor struct {
 // - Empty effect chain for audio effects processing
 // - Standard sample rate and bit rate settings for VoIP
 func NewProcessor() *Processor {
-	logrus.WithFields(logrus.Fields{"function": "NewProcessor"}).Info("Creating new audio processor")
+	pkgLog.WithFields(logrus.Fields{"function": "NewProcessor"}).Info("Creating new audio processor")
 	sampleRate, bitRate, channels := uint32(48000), uint32(64000), 1
 	encoder, err := NewMagnumOpusEncoder(sampleRate, bitRate, channels)
 	if err != nil {
-		logrus.WithFields(logrus.Fields{"function": "NewProcessor", "error": err.Error()}).Error("Failed to create magnum encoder, processor will have nil encoder")
+		pkgLog.WithFields(logrus.Fields{"function": "NewProcessor", "error": err.Error()}).Error("Failed to create magnum encoder, processor will have nil encoder")
 	}
 	decoder := createDefaultDecoder(sampleRate, channels)
 	processor := buildProcessor(sampleRate, bitRate, channels, encoder, decoder)
-	logrus.WithFields(logrus.Fields{"function": "NewProcessor", "sample_rate": processor.sampleRate, "bit_rate": processor.bitRate, "encoder_initialized": processor.encoder != nil, "decoder_initialized": processor.decoder != nil}).Info("Audio processor created")
+	pkgLog.WithFields(logrus.Fields{"function": "NewProcessor", "sample_rate": processor.sampleRate, "bit_rate": processor.bitRate, "encoder_initialized": processor.encoder != nil, "decoder_initialized": processor.decoder != nil}).Info("Audio processor created")
 	return processor
 }
 
@@ -204,11 +206,11 @@ func NewProcessor() *Processor {
 func createDefaultDecoder(sampleRate uint32, channels int) *magnum.Decoder {
 	decoder, err := magnum.NewDecoder(int(sampleRate), channels)
 	if err != nil {
-		logrus.WithFields(logrus.Fields{"function": "NewProcessor", "error": err.Error()}).Error("Failed to create magnum decoder, processor will have nil decoder")
+		pkgLog.WithFields(logrus.Fields{"function": "NewProcessor", "error": err.Error()}).Error("Failed to create magnum decoder, processor will have nil decoder")
 		return nil
 	}
 	if celtErr := decoder.EnableCELT(); celtErr != nil {
-		logrus.WithFields(logrus.Fields{"function": "NewProcessor", "error": celtErr.Error()}).Warn("Failed to enable CELT decoding")
+		pkgLog.WithFields(logrus.Fields{"function": "NewProcessor", "error": celtErr.Error()}).Warn("Failed to enable CELT decoding")
 	}
 	return decoder
 }
@@ -233,10 +235,11 @@ func buildProcessor(sampleRate, bitRate uint32, channels int, encoder *MagnumOpu
 //   - sampleRate: Original sample rate of the input audio
 //
 // Returns:
-//   - []byte: Encoded audio data ready for transmission
+//   - []byte: Encoded audio data ready for transmission, or empty when an
+//     effect suppressed the frame
 //   - error: Any error that occurred during processing
 func (p *Processor) ProcessOutgoing(pcm []int16, sampleRate uint32) ([]byte, error) {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":    "ProcessOutgoing",
 		"pcm_length":  len(pcm),
 		"sample_rate": sampleRate,
@@ -261,13 +264,22 @@ func (p *Processor) ProcessOutgoing(pcm []int16, sampleRate uint32) ([]byte, err
 		return nil, err
 	}
 
+	// An effect such as VoiceActivityDetector suppressed the frame
+	if len(processedPCM) == 0 {
+		pkgLog.WithFields(logrus.Fields{
+			"function":   "ProcessOutgoing",
+			"pcm_length": len(pcm),
+		}).Debug("Frame suppressed by audio effects, nothing to encode")
+		return nil, nil
+	}
+
 	// Encode the processed audio
 	result, err := p.encodeProcessedAudio(processedPCM)
 	if err != nil {
 		return nil, err
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":        "ProcessOutgoing",
 		"input_size":      len(pcm),
 		"output_size":     len(result),
@@ -284,7 +296,7 @@ func (p *Processor) ProcessOutgoing(pcm []int16, sampleRate uint32) ([]byte, err
 // Returns an error if the encoder is not initialized or PCM data is empty.
 func (p *Processor) validateProcessingInput(pcm []int16) error {
 	if p.encoder == nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "validateProcessingInput",
 			"error":    "encoder not initialized",
 		}).Error("Audio encoder validation failed")
@@ -292,7 +304,7 @@ func (p *Processor) validateProcessingInput(pcm []int16) error {
 	}
 
 	if len(pcm) == 0 {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "validateProcessingInput",
 			"error":    "empty PCM data",
 		}).Error("PCM data validation failed")
@@ -306,14 +318,14 @@ func (p *Processor) validateProcessingInput(pcm []int16) error {
 // Returns the resampled PCM data or the original data if no resampling is needed.
 func (p *Processor) resampleAudioIfNeeded(pcm []int16, sampleRate uint32) ([]int16, error) {
 	if sampleRate == p.sampleRate {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":    "resampleAudioIfNeeded",
 			"sample_rate": sampleRate,
 		}).Debug("Sample rates match, no resampling needed")
 		return pcm, nil
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":    "resampleAudioIfNeeded",
 		"input_rate":  sampleRate,
 		"target_rate": p.sampleRate,
@@ -325,21 +337,21 @@ func (p *Processor) resampleAudioIfNeeded(pcm []int16, sampleRate uint32) ([]int
 	}
 
 	// Perform resampling
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":   "resampleAudioIfNeeded",
 		"input_size": len(pcm),
 	}).Debug("Performing audio resampling")
 
 	resampledPCM, err := p.resampler.Resample(pcm)
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "resampleAudioIfNeeded",
 			"error":    err.Error(),
 		}).Error("Resampling failed")
 		return nil, fmt.Errorf("resampling failed: %w", err)
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":    "resampleAudioIfNeeded",
 		"input_size":  len(pcm),
 		"output_size": len(resampledPCM),
@@ -358,7 +370,7 @@ func (p *Processor) ensureResamplerReady(sampleRate uint32) error {
 	// Determine channel count (assume mono for now, could be enhanced)
 	channels := 1
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":    "ensureResamplerReady",
 		"input_rate":  sampleRate,
 		"output_rate": p.sampleRate,
@@ -372,7 +384,7 @@ func (p *Processor) ensureResamplerReady(sampleRate uint32) error {
 		Quality:    4, // Good balance of quality and performance
 	})
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "ensureResamplerReady",
 			"error":    err.Error(),
 		}).Error("Failed to create resampler")
@@ -381,7 +393,7 @@ func (p *Processor) ensureResamplerReady(sampleRate uint32) error {
 
 	// Clean up old resampler if it exists
 	if p.resampler != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "ensureResamplerReady",
 		}).Debug("Closing old resampler")
 		p.resampler.Close()
@@ -395,13 +407,13 @@ func (p *Processor) ensureResamplerReady(sampleRate uint32) error {
 // Returns the processed PCM data or the original data if no effects are configured.
 func (p *Processor) applyAudioEffects(pcm []int16) ([]int16, error) {
 	if p.effectChain == nil || p.effectChain.GetEffectCount() == 0 {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "applyAudioEffects",
 		}).Debug("No audio effects to apply")
 		return pcm, nil
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":     "applyAudioEffects",
 		"effect_count": p.effectChain.GetEffectCount(),
 		"input_size":   len(pcm),
@@ -409,14 +421,14 @@ func (p *Processor) applyAudioEffects(pcm []int16) ([]int16, error) {
 
 	effectsPCM, err := p.effectChain.Process(pcm)
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "applyAudioEffects",
 			"error":    err.Error(),
 		}).Error("Effects processing failed")
 		return nil, fmt.Errorf("effects processing failed: %w", err)
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":    "applyAudioEffects",
 		"input_size":  len(pcm),
 		"output_size": len(effectsPCM),
@@ -428,7 +440,7 @@ func (p *Processor) applyAudioEffects(pcm []int16) ([]int16, error) {
 // encodeProcessedAudio encodes the processed PCM data using the configured encoder.
 // Returns the encoded audio data ready for transmission.
 func (p *Processor) encodeProcessedAudio(pcm []int16) ([]byte, error) {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":    "encodeProcessedAudio",
 		"pcm_size":    len(pcm),
 		"sample_rate": p.sampleRate,
@@ -436,7 +448,7 @@ func (p *Processor) encodeProcessedAudio(pcm []int16) ([]byte, error) {
 
 	result, err := p.encoder.Encode(pcm, p.sampleRate)
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "encodeProcessedAudio",
 			"error":    err.Error(),
 		}).Error("Audio encoding failed")
@@ -460,7 +472,7 @@ func (p *Processor) encodeProcessedAudio(pcm []int16) ([]byte, error) {
 //   - uint32: Audio sample rate in Hz
 //   - error: Any error that occurred during processing
 func (p *Processor) ProcessIncoming(data []byte) ([]int16, uint32, error) {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":  "ProcessIncoming",
 		"data_size": len(data),
 	}).Info("Processing incoming audio data")
@@ -474,7 +486,7 @@ func (p *Processor) ProcessIncoming(data []byte) ([]int16, uint32, error) {
 		return nil, 0, err
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":    "ProcessIncoming",
 		"input_size":  len(data),
 		"pcm_samples": len(pcm),
@@ -487,7 +499,7 @@ func (p *Processor) ProcessIncoming(data []byte) ([]int16, uint32, error) {
 // validateIncomingData checks if the audio data and decoder are valid.
 func (p *Processor) validateIncomingData(data []byte) error {
 	if len(data) == 0 {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "ProcessIncoming",
 			"error":    "empty audio data",
 		}).Error("Audio data validation failed")
@@ -495,7 +507,7 @@ func (p *Processor) validateIncomingData(data []byte) error {
 	}
 
 	if p.decoder == nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "ProcessIncoming",
 			"error":    "decoder not initialized",
 		}).Error("Audio decoder validation failed")
@@ -513,7 +525,7 @@ func (p *Processor) decodeOpusData(data []byte) ([]int16, error) {
 	maxFrameSamples := int(p.sampleRate) / 1000 * 120 * p.channels
 	out := make([]int16, maxFrameSamples)
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":    "decodeOpusData",
 		"input_size":  len(data),
 		"buffer_size": maxFrameSamples,
@@ -523,7 +535,7 @@ func (p *Processor) decodeOpusData(data []byte) ([]int16, error) {
 
 	n, err := p.decoder.Decode(data, out)
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "decodeOpusData",
 			"error":    err.Error(),
 		}).Error("Opus decode failed")
@@ -535,7 +547,7 @@ func (p *Processor) decodeOpusData(data []byte) ([]int16, error) {
 		out = out[:n]
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":        "decodeOpusData",
 		"decoded_samples": n,
 		"output_size":     len(out),
@@ -555,27 +567,27 @@ func (p *Processor) decodeOpusData(data []byte) ([]int16, error) {
 // Returns:
 //   - error: Any error that occurred during bit rate update
 func (p *Processor) SetBitRate(bitRate uint32) error {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":     "SetBitRate",
 		"new_bit_rate": bitRate,
 		"old_bit_rate": p.bitRate,
 	}).Info("Updating audio encoder bit rate")
 
 	if p.encoder == nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "SetBitRate",
 			"error":    "encoder not initialized",
 		}).Error("Audio encoder validation failed")
 		return fmt.Errorf("audio encoder not initialized")
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "SetBitRate",
 		"bit_rate": bitRate,
 	}).Debug("Setting encoder bit rate")
 
 	if err := p.encoder.SetBitRate(bitRate); err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "SetBitRate",
 			"bit_rate": bitRate,
 			"error":    err.Error(),
@@ -585,7 +597,7 @@ func (p *Processor) SetBitRate(bitRate uint32) error {
 
 	p.bitRate = bitRate
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "SetBitRate",
 		"bit_rate": bitRate,
 	}).Info("Audio encoder bit rate updated successfully")
@@ -597,14 +609,14 @@ func (p *Processor) SetBitRate(bitRate uint32) error {
 //
 // Properly cleans up encoder, decoder, resampler, and effects resources to prevent memory leaks.
 func (p *Processor) Close() error {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "Close",
 	}).Info("Closing audio processor and releasing resources")
 
 	errors := p.closeAllComponents()
 
 	if len(errors) > 0 {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":    "Close",
 			"error_count": len(errors),
 			"errors":      errors,
@@ -612,7 +624,7 @@ func (p *Processor) Close() error {
 		return fmt.Errorf("multiple close errors: %v", errors)
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "Close",
 	}).Info("Audio processor closed successfully")
 
@@ -648,7 +660,7 @@ func (p *Processor) closeDecoder() error {
 		return nil
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "Close",
 	}).Debug("Closing audio decoder")
 
@@ -656,7 +668,7 @@ func (p *Processor) closeDecoder() error {
 	// to release internal buffers for garbage collection.
 	p.decoder = nil
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "Close",
 	}).Debug("Audio decoder closed successfully")
 
@@ -686,12 +698,12 @@ func closeNamedComponent(name string, component componentCloser) error {
 	if value.Kind() == reflect.Ptr && value.IsNil() {
 		return nil
 	}
-	logrus.WithFields(logrus.Fields{"function": "Close"}).Debug("Closing audio " + name)
+	pkgLog.WithFields(logrus.Fields{"function": "Close"}).Debug("Closing audio " + name)
 	if err := component.Close(); err != nil {
-		logrus.WithFields(logrus.Fields{"function": "Close", "error": err.Error()}).Error("Failed to close " + name)
+		pkgLog.WithFields(logrus.Fields{"function": "Close", "error": err.Error()}).Error("Failed to close " + name)
 		return fmt.Errorf("failed to close %s: %w", name, err)
 	}
-	logrus.WithFields(logrus.Fields{"function": "Close"}).Debug("Audio " + name + " closed successfully")
+	pkgLog.WithFields(logrus.Fields{"function": "Close"}).Debug("Audio " + name + " closed successfully")
 	return nil
 }
 
@@ -701,20 +713,20 @@ func (p *Processor) closeEffectChain() error {
 		return nil
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":     "Close",
 		"effect_count": p.effectChain.GetEffectCount(),
 	}).Debug("Closing audio effect chain")
 
 	if err := p.effectChain.Close(); err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "Close",
 			"error":    err.Error(),
 		}).Error("Failed to close effect chain")
 		return fmt.Errorf("failed to close effect chain: %w", err)
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "Close",
 	}).Debug("Audio effect chain closed successfully")
 
@@ -730,19 +742,19 @@ func (p *Processor) closeEffectChain() error {
 // Parameters:
 //   - effect: Audio effect to add to the processing chain
 func (p *Processor) AddEffect(effect AudioEffect) {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":    "AddEffect",
 		"effect_type": fmt.Sprintf("%T", effect),
 	}).Info("Adding audio effect to processing chain")
 
 	if p.effectChain != nil {
 		p.effectChain.AddEffect(effect)
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":     "AddEffect",
 			"effect_count": p.effectChain.GetEffectCount(),
 		}).Debug("Audio effect added successfully")
 	} else {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "AddEffect",
 			"error":    "effect chain not initialized",
 		}).Error("Failed to add effect - chain not initialized")
@@ -754,7 +766,7 @@ func (p *Processor) AddEffect(effect AudioEffect) {
 // Returns:
 //   - *EffectChain: Current effect chain (may be nil)
 func (p *Processor) GetEffectChain() *EffectChain {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":  "GetEffectChain",
 		"has_chain": p.effectChain != nil,
 		"effect_count": func() int {
@@ -781,13 +793,13 @@ func (p *Processor) GetEffectChain() *EffectChain {
 // Returns:
 //   - error: Validation error if gain is invalid
 func (p *Processor) SetGain(gain float64) error {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "SetGain",
 		"gain":     gain,
 	}).Info("Setting audio gain effect")
 
 	if p.effectChain == nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "SetGain",
 			"error":    "effect chain not initialized",
 		}).Error("Effect chain validation failed")
@@ -795,14 +807,14 @@ func (p *Processor) SetGain(gain float64) error {
 	}
 
 	// Create new gain effect
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "SetGain",
 		"gain":     gain,
 	}).Debug("Creating new gain effect")
 
 	gainEffect, err := NewGainEffect(gain)
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "SetGain",
 			"gain":     gain,
 			"error":    err.Error(),
@@ -812,12 +824,12 @@ func (p *Processor) SetGain(gain float64) error {
 
 	// Clear existing effects and add the gain effect
 	// This is simplified - a full implementation might maintain other effects
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "SetGain",
 	}).Debug("Clearing existing effects")
 
 	if err := p.effectChain.Clear(); err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "SetGain",
 			"error":    err.Error(),
 		}).Error("Failed to clear existing effects")
@@ -826,7 +838,7 @@ func (p *Processor) SetGain(gain float64) error {
 
 	p.effectChain.AddEffect(gainEffect)
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "SetGain",
 		"gain":     gain,
 	}).Info("Audio gain effect set successfully")
@@ -842,12 +854,12 @@ func (p *Processor) SetGain(gain float64) error {
 // Returns:
 //   - error: Any error that occurred during AGC setup
 func (p *Processor) EnableAutoGain() error {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "EnableAutoGain",
 	}).Info("Enabling automatic gain control")
 
 	if p.effectChain == nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "EnableAutoGain",
 			"error":    "effect chain not initialized",
 		}).Error("Effect chain validation failed")
@@ -855,12 +867,12 @@ func (p *Processor) EnableAutoGain() error {
 	}
 
 	// Clear existing effects
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "EnableAutoGain",
 	}).Debug("Clearing existing effects for AGC")
 
 	if err := p.effectChain.Clear(); err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "EnableAutoGain",
 			"error":    err.Error(),
 		}).Error("Failed to clear existing effects")
@@ -868,14 +880,14 @@ func (p *Processor) EnableAutoGain() error {
 	}
 
 	// Add AGC effect
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "EnableAutoGain",
 	}).Debug("Creating automatic gain control effect")
 
 	agcEffect := NewAutoGainEffect()
 	p.effectChain.AddEffect(agcEffect)
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "EnableAutoGain",
 	}).Info("Automatic gain control enabled successfully")
 
@@ -887,32 +899,32 @@ func (p *Processor) EnableAutoGain() error {
 // Returns:
 //   - error: Any error that occurred during effect cleanup
 func (p *Processor) DisableEffects() error {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "DisableEffects",
 	}).Info("Disabling all audio effects")
 
 	if p.effectChain == nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "DisableEffects",
 		}).Debug("No effect chain to disable")
 		return nil // Already disabled
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":     "DisableEffects",
 		"effect_count": p.effectChain.GetEffectCount(),
 	}).Debug("Clearing all effects from chain")
 
 	err := p.effectChain.Clear()
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "DisableEffects",
 			"error":    err.Error(),
 		}).Error("Failed to clear effects")
 		return err
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "DisableEffects",
 	}).Info("All audio effects disabled successfully")
 
@@ -61,7 +61,7 @@ func NewResampler(config ResamplerConfig) (*Resampler, error) {
 
 // logResamplerCreation logs the start of resampler creation.
 func logResamplerCreation(config ResamplerConfig) {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":    "NewResampler",
 		"input_rate":  config.InputRate,
 		"output_rate": config.OutputRate,
@@ -73,7 +73,7 @@ func logResamplerCreation(config ResamplerConfig) {
 // validateResamplerConfig validates the resampler configuration parameters.
 func validateResamplerConfig(config ResamplerConfig) error {
 	if config.InputRate == 0 || config.OutputRate == 0 {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":    "NewResampler",
 			"input_rate":  config.InputRate,
 			"output_rate": config.OutputRate,
@@ -83,7 +83,7 @@ func validateResamplerConfig(config ResamplerConfig) error {
 	}
 
 	if config.Channels < 1 || config.Channels > 2 {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "NewResampler",
 			"channels": config.Channels,
 			"error":    "unsupported channel count",
@@ -93,7 +93,7 @@ func validateResamplerConfig(config ResamplerConfig) error {
 
 	if config.Quality != 0 && (config.Quality < 1 || config.Quality > 10) {
 		// Quality 0 is a special value meaning "use default (4)"; other values must be in [1, 10].
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "NewResampler",
 			"quality":  config.Quality,
 			"error":    "quality out of range",
@@ -108,7 +108,7 @@ func validateResamplerConfig(config ResamplerConfig) error {
 func determineResamplerQuality(quality int) int {
 	if quality == 0 {
 		quality = 4
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":        "NewResampler",
 			"default_quality": quality,
 		}).Debug("Using default quality setting")
@@ -130,7 +130,7 @@ func buildResamplerInstance(config ResamplerConfig, quality int) *Resampler {
 
 // logResamplerSuccess logs successful resampler creation with final configuration.
 func logResamplerSuccess(resampler *Resampler, config ResamplerConfig) {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":    "NewResampler",
 		"input_rate":  resampler.inputRate,
 		"output_rate": resampler.outputRate,
@@ -152,14 +152,14 @@ func logResamplerSuccess(resampler *Resampler, config ResamplerConfig) {
 // Returns:
 //   - error: Validation error, or nil if input is valid
 func validateResamplerInput(input []int16, channels int) error {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":     "validateResamplerInput",
 		"input_length": len(input),
 		"channels":     channels,
 	}).Debug("Validating resampler input")
 
 	if len(input) == 0 {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "validateResamplerInput",
 			"error":    "empty input samples",
 		}).Error("Input validation failed")
@@ -168,7 +168,7 @@ func validateResamplerInput(input []int16, channels int) error {
 
 	// Check if samples are properly aligned for channels
 	if len(input)%channels != 0 {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":     "validateResamplerInput",
 			"input_length": len(input),
 			"channels":     channels,
@@ -178,7 +178,7 @@ func validateResamplerInput(input []int16, channels int) error {
 		return fmt.Errorf("input samples (%d) not aligned to channel count (%d)", len(input), channels)
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":     "validateResamplerInput",
 		"input_length": len(input),
 		"channels":     channels,
@@ -200,7 +200,7 @@ func validateResamplerInput(input []int16, channels int) error {
 //   - []int16: Copy of input samples
 //   - bool: true if same-rate optimization was applied
 func handleSameRateResampling(input []int16, inputRate, outputRate uint32) ([]int16, bool) {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":    "handleSameRateResampling",
 		"input_rate":  inputRate,
 		"output_rate": outputRate,
@@ -211,7 +211,7 @@ func handleSameRateResampling(input []int16, inputRate, outputRate uint32) ([]in
 		result := make([]int16, len(input))
 		copy(result, input)
 
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":    "handleSameRateResampling",
 			"input_rate":  inputRate,
 			"output_rate": outputRate,
@@ -222,7 +222,7 @@ func handleSameRateResampling(input []int16, inputRate, outputRate uint32) ([]in
 		return result, true
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":    "handleSameRateResampling",
 		"input_rate":  inputRate,
 		"output_rate": outputRate,
@@ -262,7 +262,7 @@ func interpolateSample(input []int16, inputIndex int, frac float64, ch, channels
 func getSampleFromPrevious(lastSamples []int16, ch, inputIndex int) int16 {
 	if len(lastSamples) > ch {
 		sample := lastSamples[ch]
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":    "getSampleFromPrevious",
 			"input_index": inputIndex,
 			"channel":     ch,
@@ -278,7 +278,7 @@ func getSampleFromPrevious(lastSamples []int16, ch, inputIndex int) int16 {
 func getSampleAtUpperBoundary(input []int16, inputIndex, ch, channels, inputFrames int) int16 {
 	if inputIndex < inputFrames {
 		sample := input[inputIndex*channels+ch]
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":    "getSampleAtUpperBoundary",
 			"input_index": inputIndex,
 			"channel":     ch,
@@ -289,7 +289,7 @@ func getSampleAtUpperBoundary(input []int16, inputIndex, ch, channels, inputFram
 	}
 	if len(input) > ch {
 		sample := input[len(input)-channels+ch]
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":    "getSampleAtUpperBoundary",
 			"input_index": inputIndex,
 			"channel":     ch,
@@ -308,7 +308,7 @@ func performLinearInterpolation(input []int16, inputIndex int, frac float64, ch,
 	interpolated := float64(sample1)*(1.0-frac) + float64(sample2)*frac
 	sample := int16(interpolated)
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":     "performLinearInterpolation",
 		"input_index":  inputIndex,
 		"channel":      ch,
@@ -331,7 +331,7 @@ func performLinearInterpolation(input []int16, inputIndex int, frac float64, ch,
 //   - input: Input PCM audio samples
 //   - inputFrames: Number of input frames processed
 func updateResamplerState(r *Resampler, input []int16, inputFrames int) {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":     "updateResamplerState",
 		"old_position": r.position,
 		"input_frames": inputFrames,
@@ -346,14 +346,14 @@ func updateResamplerState(r *Resampler, input []int16, inputFrames int) {
 	// Store last samples for next interpolation
 	if len(input) >= r.channels {
 		copy(r.lastSamples, input[len(input)-r.channels:])
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":     "updateResamplerState",
 			"old_position": oldPosition,
 			"new_position": r.position,
 			"last_samples": r.lastSamples,
 		}).Debug("Updated resampler state and stored last samples")
 	} else {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":     "updateResamplerState",
 			"input_length": len(input),
 			"channels":     r.channels,
@@ -374,7 +374,7 @@ func updateResamplerState(r *Resampler, input []int16, inputFrames int) {
 //   - []int16: Resampled PCM audio samples
 //   - error: Any error that occurred during resampling
 func (r *Resampler) Resample(input []int16) ([]int16, error) {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":     "Resample",
 		"input_length": len(input),
 		"input_rate":   r.inputRate,
@@ -384,7 +384,7 @@ func (r *Resampler) Resample(input []int16) ([]int16, error) {
 	}).Info("Starting audio resampling")
 
 	if err := validateResamplerInput(input, r.channels); err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "Resample",
 			"error":    err.Error(),
 		}).Error("Input validation failed")
@@ -392,7 +392,7 @@ func (r *Resampler) Resample(input []int16) ([]int16, error) {
 	}
 
 	if result, handled := handleSameRateResampling(input, r.inputRate, r.outputRate); handled {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":     "Resample",
 			"input_size":   len(input),
 			"output_size":  len(result),
@@ -403,7 +403,7 @@ func (r *Resampler) Resample(input []int16) ([]int16, error) {
 
 	output := r.performLinearInterpolation(input)
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":       "Resample",
 		"input_length":   len(input),
 		"output_length":  len(output),
@@ -423,7 +423,7 @@ func (r *Resampler) performLinearInterpolation(input []int16) []int16 {
 	outputFrames := int(float64(inputFrames)/ratio + 0.5)
 	output := make([]int16, 0, outputFrames*r.channels)
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":      "Resample",
 		"ratio":         ratio,
 		"input_frames":  inputFrames,
@@ -453,7 +453,7 @@ func (r *Resampler) interpolateAndAppendFrame(output *[]int16, input []int16, in
 	r.position += ratio
 
 	if outputFrames > 1000 && outputFrame%500 == 0 {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":     "Resample",
 			"progress":     float64(outputFrame) / float64(outputFrames) * 100,
 			"output_frame": outputFrame,
@@ -464,7 +464,7 @@ func (r *Resampler) interpolateAndAppendFrame(output *[]int16, input []int16, in
 
 // GetInputRate returns the configured input sample rate.
 func (r *Resampler) GetInputRate() uint32 {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":   "GetInputRate",
 		"input_rate": r.inputRate,
 	}).Debug("Retrieved input sample rate")
@@ -473,7 +473,7 @@ func (r *Resampler) GetInputRate() uint32 {
 
 // GetOutputRate returns the configured output sample rate.
 func (r *Resampler) GetOutputRate() uint32 {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":    "GetOutputRate",
 		"output_rate": r.outputRate,
 	}).Debug("Retrieved output sample rate")
@@ -482,7 +482,7 @@ func (r *Resampler) GetOutputRate() uint32 {
 
 // GetChannels returns the configured number of channels.
 func (r *Resampler) GetChannels() int {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "GetChannels",
 		"channels": r.channels,
 	}).Debug("Retrieved channel count")
@@ -491,7 +491,7 @@ func (r *Resampler) GetChannels() int {
 
 // GetQuality returns the configured resampling quality.
 func (r *Resampler) GetQuality() int {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "GetQuality",
 		"quality":  r.quality,
 	}).Debug("Retrieved resampling quality")
@@ -509,7 +509,7 @@ func (r *Resampler) GetQuality() int {
 // Returns:
 //   - int: Estimated number of output samples
 func (r *Resampler) CalculateOutputSize(inputSize int) int {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":    "CalculateOutputSize",
 		"input_size":  inputSize,
 		"input_rate":  r.inputRate,
@@ -517,7 +517,7 @@ func (r *Resampler) CalculateOutputSize(inputSize int) int {
 	}).Debug("Calculating output size")
 
 	if r.inputRate == r.outputRate {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":     "CalculateOutputSize",
 			"input_size":   inputSize,
 			"output_size":  inputSize,
@@ -530,7 +530,7 @@ func (r *Resampler) CalculateOutputSize(inputSize int) int {
 	ratio := float64(r.outputRate) / float64(r.inputRate)
 	outputSize := int(float64(inputSize)*ratio + 0.5) // Round to nearest integer
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":    "CalculateOutputSize",
 		"input_size":  inputSize,
 		"output_size": outputSize,
@@ -545,7 +545,7 @@ func (r *Resampler) CalculateOutputSize(inputSize int) int {
 // This is useful when starting a new audio stream or when there's
 // a discontinuity in the audio data.
 func (r *Resampler) Reset() error {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":     "Reset",
 		"old_position": r.position,
 		"channels":     r.channels,
@@ -557,7 +557,7 @@ func (r *Resampler) Reset() error {
 		r.lastSamples[i] = 0
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":        "Reset",
 		"new_position":    r.position,
 		"cleared_samples": len(r.lastSamples),
@@ -570,7 +570,7 @@ func (r *Resampler) Reset() error {
 //
 // After calling Close, the resampler should not be used.
 func (r *Resampler) Close() error {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":    "Close",
 		"input_rate":  r.inputRate,
 		"output_rate": r.outputRate,
@@ -578,7 +578,7 @@ func (r *Resampler) Close() error {
 	}).Info("Closing resampler")
 
 	// No resources to clean up for our simple implementation
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "Close",
 	}).Info("Resampler closed successfully")
 
@@ -589,7 +589,7 @@ func (r *Resampler) Close() error {
 
 // NewTelephoneToOpusResampler creates a resampler for telephone quality (8kHz) to Opus (48kHz).
 func NewTelephoneToOpusResampler(channels int) (*Resampler, error) {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "NewTelephoneToOpusResampler",
 		"channels": channels,
 		"type":     "telephone_to_opus",
@@ -605,7 +605,7 @@ func NewTelephoneToOpusResampler(channels int) (*Resampler, error) {
 
 // NewCDToOpusResampler creates a resampler for CD quality (44.1kHz) to Opus (48kHz).
 func NewCDToOpusResampler(channels int) (*Resampler, error) {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "NewCDToOpusResampler",
 		"channels": channels,
 		"type":     "cd_to_opus",
@@ -621,7 +621,7 @@ func NewCDToOpusResampler(channels int) (*Resampler, error) {
 
 // NewWidebandToOpusResampler creates a resampler for wideband audio (16kHz) to Opus (48kHz).
 func NewWidebandToOpusResampler(channels int) (*Resampler, error) {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "NewWidebandToOpusResampler",
 		"channels": channels,
 		"type":     "wideband_to_opus",
@@ -637,7 +637,7 @@ func NewWidebandToOpusResampler(channels int) (*Resampler, error) {
 
 // NewOpusToPlaybackResampler creates a resampler for Opus (48kHz) to common playback rates.
 func NewOpusToPlaybackResampler(outputRate uint32, channels int) (*Resampler, error) {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":    "NewOpusToPlaybackResampler",
 		"output_rate": outputRate,
 		"channels":    channels,
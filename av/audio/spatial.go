@@ -0,0 +1,382 @@
+package audio
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ErrInvalidPosition is returned when a spatial position has a NaN or
+// infinite coordinate.
+var ErrInvalidPosition = errors.New("invalid spatial position")
+
+const (
+	// DefaultSpatialSampleRate is the sample rate assumed by a new
+	// SpatialAudioEffect until SetSampleRate is called.
+	DefaultSpatialSampleRate = 48000
+
+	// headRadius is the average human head radius in meters, used by the
+	// Woodworth inter-aural time difference model.
+	headRadius = 0.0875
+	// speedOfSound in meters per second at room temperature.
+	speedOfSound = 343.0
+	// spatialReferenceDistance is the distance in meters below which no
+	// distance attenuation is applied.
+	spatialReferenceDistance = 1.0
+	// maxPanning limits constant-power panning so the far ear stays audible
+	// (about -16 dB) for sources directly to one side, as it would be
+	// naturally.
+	maxPanning = 0.8
+)
+
+// HRTFDatabase supplies head-related impulse responses for higher quality
+// spatialization than the built-in delay and level panning.
+//
+// Directions use the listener's frame: azimuth is 0 straight ahead and
+// positive to the right, elevation is 0 at ear level and positive upwards,
+// both in radians. The returned impulse responses are FIR filters at the
+// effect's sample rate and must not be modified by the caller.
+type HRTFDatabase interface {
+	ImpulseResponse(azimuth, elevation float64) (left, right []float64)
+}
+
+// SpatialAudioEffect positions a mono voice in 3D space around the listener.
+//
+// Process takes mono PCM and returns interleaved stereo PCM (L, R) of twice
+// the length, so the effect must be the last one in an EffectChain.
+//
+// Coordinates are in meters relative to the listener: x to the right, y up
+// and z straight ahead. Without an HRTFDatabase the effect uses a
+// simplified model:
+// - Inter-aural time difference from the Woodworth spherical head formula
+// - Inter-aural level difference from constant-power panning
+// - Inverse distance attenuation beyond one meter
+type SpatialAudioEffect struct {
+	mu         sync.Mutex
+	x, y, z    float64
+	sampleRate uint32
+	hrtf       HRTFDatabase
+	history    []float64 // Trailing mono input kept for delays and FIR taps
+}
+
+// NewSpatialAudioEffect creates a spatial audio effect for a source at
+// (x, y, z), using the simplified panning model at
+// DefaultSpatialSampleRate.
+//
+// Parameters:
+//   - x, y, z: Source position in meters (see SpatialAudioEffect)
+//
+// Returns:
+//   - *SpatialAudioEffect: New spatial effect instance
+//   - error: ErrInvalidPosition if a coordinate is NaN or infinite
+func NewSpatialAudioEffect(x, y, z float64) (*SpatialAudioEffect, error) {
+	if err := validatePosition(x, y, z); err != nil {
+		pkgLog.WithFields(logrus.Fields{
+			"function": "NewSpatialAudioEffect",
+			"error":    err.Error(),
+		}).Error("Spatial position validation failed")
+		return nil, err
+	}
+
+	pkgLog.WithFields(logrus.Fields{
+		"function": "NewSpatialAudioEffect",
+		"x":        x,
+		"y":        y,
+		"z":        z,
+	}).Info("Creating new spatial audio effect")
+
+	return &SpatialAudioEffect{x: x, y: y, z: z, sampleRate: DefaultSpatialSampleRate}, nil
+}
+
+// validatePosition rejects coordinates that cannot be spatialized.
+func validatePosition(x, y, z float64) error {
+	for _, c := range []float64{x, y, z} {
+		if math.IsNaN(c) || math.IsInf(c, 0) {
+			return fmt.Errorf("%w: (%g, %g, %g)", ErrInvalidPosition, x, y, z)
+		}
+	}
+	return nil
+}
+
+// SetPosition moves the source to (x, y, z).
+func (s *SpatialAudioEffect) SetPosition(x, y, z float64) error {
+	if err := validatePosition(x, y, z); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.x, s.y, s.z = x, y, z
+	s.mu.Unlock()
+	return nil
+}
+
+// GetPosition returns the source position.
+func (s *SpatialAudioEffect) GetPosition() (x, y, z float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.x, s.y, s.z
+}
+
+// SetSampleRate sets the sample rate of the processed audio, which
+// determines the inter-aural delay in samples.
+func (s *SpatialAudioEffect) SetSampleRate(sampleRate uint32) error {
+	if sampleRate == 0 {
+		return fmt.Errorf("sample rate cannot be zero")
+	}
+	s.mu.Lock()
+	s.sampleRate = sampleRate
+	s.mu.Unlock()
+	return nil
+}
+
+// SetHRTFDatabase switches the effect to convolution with impulse responses
+// from db. A nil db restores the simplified panning model.
+func (s *SpatialAudioEffect) SetHRTFDatabase(db HRTFDatabase) {
+	s.mu.Lock()
+	s.hrtf = db
+	s.mu.Unlock()
+
+	pkgLog.WithFields(logrus.Fields{
+		"function": "SpatialAudioEffect.SetHRTFDatabase",
+		"hrtf":     db != nil,
+	}).Info("Spatial audio model updated")
+}
+
+// Process spatializes mono samples into interleaved stereo samples.
+//
+// Parameters:
+//   - samples: Mono PCM input
+//
+// Returns:
+//   - []int16: Interleaved stereo PCM, twice the length of samples
+//   - error: Processing error (should not occur in normal operation)
+func (s *SpatialAudioEffect) Process(samples []int16) ([]int16, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	out := make([]int16, 2*len(samples))
+	if len(samples) == 0 {
+		return out, nil
+	}
+
+	left, right := s.filters()
+	taps := max(len(left), len(right))
+	input := make([]float64, taps-1, taps-1+len(samples))
+	// Right-align the history so the newest sample precedes samples[0]
+	copy(input[max(0, len(input)-len(s.history)):], s.history[max(0, len(s.history)-len(input)):])
+	for _, sample := range samples {
+		input = append(input, float64(sample))
+	}
+
+	offset := taps - 1
+	for i := range samples {
+		out[2*i] = clampSample(convolveAt(input, offset+i, left))
+		out[2*i+1] = clampSample(convolveAt(input, offset+i, right))
+	}
+
+	s.history = append(s.history[:0], input[max(0, len(input)-offset):]...)
+	return out, nil
+}
+
+// filters returns the left and right FIR filters for the current position.
+// The simplified model is expressed as a delayed, scaled unit impulse so
+// both models share one convolution path. The caller must hold s.mu.
+func (s *SpatialAudioEffect) filters() (left, right []float64) {
+	distance := math.Sqrt(s.x*s.x + s.y*s.y + s.z*s.z)
+	attenuation := 1.0
+	if distance > spatialReferenceDistance {
+		attenuation = spatialReferenceDistance / distance
+	}
+
+	if s.hrtf != nil {
+		azimuth := math.Atan2(s.x, s.z)
+		elevation := math.Atan2(s.y, math.Hypot(s.x, s.z))
+		left, right = s.hrtf.ImpulseResponse(azimuth, elevation)
+		if len(left) > 0 && len(right) > 0 {
+			return scaleFilter(left, attenuation), scaleFilter(right, attenuation)
+		}
+	}
+
+	// Lateral position in [-1, 1]: -1 fully left, 1 fully right
+	lateral := 0.0
+	if distance > 0 {
+		lateral = s.x / distance
+	}
+
+	angle := (lateral*maxPanning + 1) * math.Pi / 4
+	leftGain, rightGain := math.Cos(angle)*attenuation, math.Sin(angle)*attenuation
+
+	theta := math.Asin(math.Abs(lateral))
+	delay := int(math.Round(headRadius / speedOfSound * (theta + math.Sin(theta)) * float64(s.sampleRate)))
+
+	left, right = []float64{leftGain}, []float64{rightGain}
+	if lateral > 0 {
+		left = delayedImpulse(delay, leftGain)
+	} else if lateral < 0 {
+		right = delayedImpulse(delay, rightGain)
+	}
+	return left, right
+}
+
+// delayedImpulse returns a filter that delays by delay samples and scales by
+// gain.
+func delayedImpulse(delay int, gain float64) []float64 {
+	filter := make([]float64, delay+1)
+	filter[delay] = gain
+	return filter
+}
+
+// scaleFilter returns a copy of filter scaled by gain.
+func scaleFilter(filter []float64, gain float64) []float64 {
+	scaled := make([]float64, len(filter))
+	for i, tap := range filter {
+		scaled[i] = tap * gain
+	}
+	return scaled
+}
+
+// convolveAt returns the output of filter for the input sample at index i.
+// The input must hold at least len(filter)-1 samples before i.
+func convolveAt(input []float64, i int, filter []float64) float64 {
+	var sum float64
+	for k, tap := range filter {
+		if tap != 0 {
+			sum += tap * input[i-k]
+		}
+	}
+	return sum
+}
+
+// clampSample converts to int16 with saturation.
+func clampSample(v float64) int16 {
+	if v > math.MaxInt16 {
+		return math.MaxInt16
+	}
+	if v < math.MinInt16 {
+		return math.MinInt16
+	}
+	return int16(math.Round(v))
+}
+
+// GetName returns the effect name for debugging and logging.
+func (s *SpatialAudioEffect) GetName() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return fmt.Sprintf("Spatial(%.2f,%.2f,%.2f)", s.x, s.y, s.z)
+}
+
+// Close releases effect resources (no-op for spatial effect).
+func (s *SpatialAudioEffect) Close() error {
+	s.mu.Lock()
+	s.history = nil
+	s.mu.Unlock()
+	return nil
+}
+
+// SpatialMixer mixes the mono voices of several peers into one stereo
+// stream, each positioned by its own SpatialAudioEffect. A conference call
+// mixer updates positions with SetPosition and mixes each frame with Mix.
+type SpatialMixer struct {
+	mu         sync.Mutex
+	sampleRate uint32
+	hrtf       HRTFDatabase
+	peers      map[uint32]*SpatialAudioEffect
+}
+
+// NewSpatialMixer creates a mixer for mono frames at sampleRate.
+func NewSpatialMixer(sampleRate uint32) (*SpatialMixer, error) {
+	if sampleRate == 0 {
+		return nil, fmt.Errorf("sample rate cannot be zero")
+	}
+	return &SpatialMixer{
+		sampleRate: sampleRate,
+		peers:      make(map[uint32]*SpatialAudioEffect),
+	}, nil
+}
+
+// SetPosition places peerID at (x, y, z). Peers that were never positioned
+// are mixed at the listener's position, equally in both ears.
+func (m *SpatialMixer) SetPosition(peerID uint32, x, y, z float64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if effect, ok := m.peers[peerID]; ok {
+		return effect.SetPosition(x, y, z)
+	}
+	effect, err := m.newEffect(x, y, z)
+	if err != nil {
+		return err
+	}
+	m.peers[peerID] = effect
+	return nil
+}
+
+// newEffect creates a peer effect with the mixer's settings. The caller must
+// hold m.mu.
+func (m *SpatialMixer) newEffect(x, y, z float64) (*SpatialAudioEffect, error) {
+	effect, err := NewSpatialAudioEffect(x, y, z)
+	if err != nil {
+		return nil, err
+	}
+	effect.sampleRate = m.sampleRate
+	effect.hrtf = m.hrtf
+	return effect, nil
+}
+
+// RemovePeer forgets the position and filter state of peerID.
+func (m *SpatialMixer) RemovePeer(peerID uint32) {
+	m.mu.Lock()
+	delete(m.peers, peerID)
+	m.mu.Unlock()
+}
+
+// SetHRTFDatabase sets the impulse responses used for every peer. A nil db
+// restores the simplified panning model.
+func (m *SpatialMixer) SetHRTFDatabase(db HRTFDatabase) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.hrtf = db
+	for _, effect := range m.peers {
+		effect.SetHRTFDatabase(db)
+	}
+}
+
+// Mix spatializes one mono frame per peer and sums them into interleaved
+// stereo PCM. The output is twice the length of the longest frame; shorter
+// frames are padded with silence.
+func (m *SpatialMixer) Mix(frames map[uint32][]int16) ([]int16, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	frameLen := 0
+	for _, frame := range frames {
+		frameLen = max(frameLen, len(frame))
+	}
+
+	sum := make([]float64, 2*frameLen)
+	for peerID, frame := range frames {
+		effect, ok := m.peers[peerID]
+		if !ok {
+			var err error
+			if effect, err = m.newEffect(0, 0, 0); err != nil {
+				return nil, err
+			}
+			m.peers[peerID] = effect
+		}
+		stereo, err := effect.Process(frame)
+		if err != nil {
+			return nil, fmt.Errorf("spatialize peer %d: %w", peerID, err)
+		}
+		for i, sample := range stereo {
+			sum[i] += float64(sample)
+		}
+	}
+
+	out := make([]int16, len(sum))
+	for i, v := range sum {
+		out[i] = clampSample(v)
+	}
+	return out, nil
+}
@@ -0,0 +1,203 @@
+package audio
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+// energy returns the sum of squares of the left and right channels of
+// interleaved stereo samples.
+func energy(stereo []int16) (left, right float64) {
+	for i := 0; i+1 < len(stereo); i += 2 {
+		left += float64(stereo[i]) * float64(stereo[i])
+		right += float64(stereo[i+1]) * float64(stereo[i+1])
+	}
+	return left, right
+}
+
+func impulseFrame(n int) []int16 {
+	frame := make([]int16, n)
+	frame[0] = 10000
+	return frame
+}
+
+// firstNonZero returns the frame index of the first non-zero sample in the
+// given channel (0 left, 1 right) of interleaved stereo, or -1.
+func firstNonZero(stereo []int16, channel int) int {
+	for i := channel; i < len(stereo); i += 2 {
+		if stereo[i] != 0 {
+			return i / 2
+		}
+	}
+	return -1
+}
+
+func TestNewSpatialAudioEffectValidation(t *testing.T) {
+	if _, err := NewSpatialAudioEffect(math.NaN(), 0, 1); !errors.Is(err, ErrInvalidPosition) {
+		t.Errorf("NaN coordinate: got %v, want ErrInvalidPosition", err)
+	}
+	effect, err := NewSpatialAudioEffect(1, 0, 1)
+	if err != nil {
+		t.Fatalf("NewSpatialAudioEffect() error = %v", err)
+	}
+	if err := effect.SetPosition(0, math.Inf(1), 0); !errors.Is(err, ErrInvalidPosition) {
+		t.Errorf("SetPosition(Inf): got %v, want ErrInvalidPosition", err)
+	}
+	if x, y, z := effect.GetPosition(); x != 1 || y != 0 || z != 1 {
+		t.Errorf("position changed by rejected update: (%g, %g, %g)", x, y, z)
+	}
+	if err := effect.SetSampleRate(0); err == nil {
+		t.Error("SetSampleRate(0) should fail")
+	}
+}
+
+func TestSpatialAudioEffectPanning(t *testing.T) {
+	tests := []struct {
+		name       string
+		x, z       float64
+		louder     string
+		delayedEar int // 0 left, 1 right, -1 none
+	}{
+		{"front", 0, 1, "", -1},
+		{"right", 1, 0, "right", 0},
+		{"left", -1, 0, "left", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			effect, err := NewSpatialAudioEffect(tt.x, 0, tt.z)
+			if err != nil {
+				t.Fatal(err)
+			}
+			out, err := effect.Process(impulseFrame(64))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(out) != 128 {
+				t.Fatalf("output length = %d, want 128", len(out))
+			}
+
+			left, right := energy(out)
+			switch tt.louder {
+			case "":
+				if left != right {
+					t.Errorf("front source should be balanced: left %g right %g", left, right)
+				}
+			case "right":
+				if right <= left {
+					t.Errorf("right source: left %g right %g", left, right)
+				}
+			case "left":
+				if left <= right {
+					t.Errorf("left source: left %g right %g", left, right)
+				}
+			}
+
+			leftOnset, rightOnset := firstNonZero(out, 0), firstNonZero(out, 1)
+			switch tt.delayedEar {
+			case -1:
+				if leftOnset != rightOnset {
+					t.Errorf("front source onsets differ: left %d right %d", leftOnset, rightOnset)
+				}
+			case 0:
+				// Fully lateral at 48 kHz: about 31 samples of delay
+				if leftOnset-rightOnset < 25 {
+					t.Errorf("left ear delay = %d samples, want about 31", leftOnset-rightOnset)
+				}
+			case 1:
+				if rightOnset-leftOnset < 25 {
+					t.Errorf("right ear delay = %d samples, want about 31", rightOnset-leftOnset)
+				}
+			}
+		})
+	}
+}
+
+func TestSpatialAudioEffectDelayAcrossFrames(t *testing.T) {
+	effect, err := NewSpatialAudioEffect(1, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	frame := impulseFrame(8)
+	frame[0] = 0
+	frame[7] = 10000
+
+	first, _ := effect.Process(frame)
+	if firstNonZero(first, 0) != -1 {
+		t.Error("delayed left channel should be silent in the first frame")
+	}
+	second, _ := effect.Process(make([]int16, 64))
+	if firstNonZero(second, 0) == -1 {
+		t.Error("delayed impulse should carry over into the next frame")
+	}
+}
+
+func TestSpatialAudioEffectDistanceAttenuation(t *testing.T) {
+	near, _ := NewSpatialAudioEffect(0, 0, 1)
+	far, _ := NewSpatialAudioEffect(0, 0, 4)
+	nearOut, _ := near.Process(impulseFrame(16))
+	farOut, _ := far.Process(impulseFrame(16))
+	nearLeft, _ := energy(nearOut)
+	farLeft, _ := energy(farOut)
+	if farLeft >= nearLeft {
+		t.Errorf("distant source should be quieter: near %g far %g", nearLeft, farLeft)
+	}
+}
+
+type testHRTF struct{}
+
+func (testHRTF) ImpulseResponse(azimuth, elevation float64) (left, right []float64) {
+	return []float64{0.5}, []float64{0, 0, 0.25}
+}
+
+func TestSpatialAudioEffectHRTFDatabase(t *testing.T) {
+	effect, _ := NewSpatialAudioEffect(0, 0, 1)
+	effect.SetHRTFDatabase(testHRTF{})
+	out, _ := effect.Process(impulseFrame(8))
+	if out[0] != 5000 || out[1] != 0 || out[5] != 2500 {
+		t.Errorf("HRTF convolution output = %v", out[:6])
+	}
+
+	effect.SetHRTFDatabase(nil)
+	out, _ = effect.Process(impulseFrame(8))
+	if out[0] != out[1] {
+		t.Errorf("simplified model not restored: %v", out[:2])
+	}
+}
+
+func TestSpatialMixer(t *testing.T) {
+	mixer, err := NewSpatialMixer(48000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := mixer.SetPosition(1, -1, 0, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := mixer.SetPosition(2, 1, 0, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	leftOnly, err := mixer.Mix(map[uint32][]int16{1: impulseFrame(64)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if left, right := energy(leftOnly); left <= right {
+		t.Errorf("peer 1 should be on the left: left %g right %g", left, right)
+	}
+
+	both, err := mixer.Mix(map[uint32][]int16{1: impulseFrame(64), 2: impulseFrame(32), 3: impulseFrame(16)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(both) != 128 {
+		t.Errorf("mixed length = %d, want 128", len(both))
+	}
+
+	if err := mixer.SetPosition(2, math.NaN(), 0, 0); !errors.Is(err, ErrInvalidPosition) {
+		t.Errorf("SetPosition(NaN): got %v", err)
+	}
+	mixer.RemovePeer(1)
+	if _, err := NewSpatialMixer(0); err == nil {
+		t.Error("NewSpatialMixer(0) should fail")
+	}
+}
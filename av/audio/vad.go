@@ -0,0 +1,198 @@
+package audio
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultVADThresholdDB is the frame energy, in dBFS, below which a
+	// frame counts as silence.
+	DefaultVADThresholdDB = -40.0
+	// DefaultVADMinSilenceDuration is how long silence must last before
+	// frames are suppressed.
+	DefaultVADMinSilenceDuration = 300 * time.Millisecond
+	// DefaultVADHoldTime is how long frames keep being transmitted after
+	// the last voiced frame, so word endings are not clipped.
+	DefaultVADHoldTime = 200 * time.Millisecond
+)
+
+// VoiceActivityDetector suppresses silent frames to save bandwidth.
+//
+// Each frame is classified by its short-time energy: the RMS level of the
+// whole frame in dBFS. Once frames have stayed below ThresholdDB for longer
+// than MinSilenceDuration, and the hold time since the last voiced frame has
+// passed, Process returns an empty slice to signal that the frame should not
+// be transmitted. EffectChain skips the remaining effects for an empty frame
+// except a ComfortNoiseGenerator placed after the detector.
+//
+// Design decisions:
+// - Frame energy rather than per-sample thresholds, robust to zero crossings
+// - Durations derived from frame length and sample rate, no wall clock
+// - Mono input assumed; interleaved channels shorten the measured durations
+type VoiceActivityDetector struct {
+	mu                 sync.Mutex
+	thresholdDB        float64
+	sampleRate         int
+	minSilenceDuration time.Duration
+	holdTime           time.Duration
+	silence            time.Duration // Silence since the last voiced frame
+	voiced             bool          // Whether any frame has been voiced yet
+}
+
+// NewVoiceActivityDetector creates a voice activity detector.
+//
+// Parameters:
+//   - thresholdDB: Energy threshold in dBFS (DefaultVADThresholdDB is -40)
+//   - sampleRate: Sample rate of the processed frames in Hz
+//
+// Returns:
+//   - *VoiceActivityDetector: New detector with default silence and hold times
+//   - error: Validation error if a parameter is invalid
+func NewVoiceActivityDetector(thresholdDB float64, sampleRate int) (*VoiceActivityDetector, error) {
+	if err := validateVADThreshold(thresholdDB); err != nil {
+		return nil, err
+	}
+	if sampleRate <= 0 {
+		pkgLog.WithFields(logrus.Fields{
+			"function":    "NewVoiceActivityDetector",
+			"sample_rate": sampleRate,
+		}).Error("Sample rate validation failed")
+		return nil, fmt.Errorf("sample rate must be positive: %d", sampleRate)
+	}
+
+	pkgLog.WithFields(logrus.Fields{
+		"function":     "NewVoiceActivityDetector",
+		"threshold_db": thresholdDB,
+		"sample_rate":  sampleRate,
+	}).Info("Voice activity detector created")
+
+	return &VoiceActivityDetector{
+		thresholdDB:        thresholdDB,
+		sampleRate:         sampleRate,
+		minSilenceDuration: DefaultVADMinSilenceDuration,
+		holdTime:           DefaultVADHoldTime,
+	}, nil
+}
+
+// validateVADThreshold checks that a threshold is a finite dBFS level.
+func validateVADThreshold(thresholdDB float64) error {
+	if math.IsNaN(thresholdDB) || math.IsInf(thresholdDB, 0) || thresholdDB > 0 {
+		return fmt.Errorf("threshold must be a finite dBFS value <= 0: %f", thresholdDB)
+	}
+	return nil
+}
+
+// Process classifies the frame and returns it unchanged, or an empty slice
+// when it falls in a suppressed stretch of silence.
+func (v *VoiceActivityDetector) Process(samples []int16) ([]int16, error) {
+	if len(samples) == 0 {
+		return samples, nil
+	}
+	level := frameEnergyDB(samples)
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if level >= v.thresholdDB {
+		v.silence = 0
+		v.voiced = true
+		return samples, nil
+	}
+
+	v.silence += time.Duration(len(samples)) * time.Second / time.Duration(v.sampleRate)
+	if v.transmittingLocked() {
+		return samples, nil
+	}
+
+	pkgLog.WithFields(logrus.Fields{
+		"function":   "VoiceActivityDetector.Process",
+		"level_db":   level,
+		"silence_ms": v.silence.Milliseconds(),
+	}).Debug("Suppressing silent frame")
+	return samples[:0], nil
+}
+
+// Apply is an alias of Process.
+func (v *VoiceActivityDetector) Apply(samples []int16) ([]int16, error) {
+	return v.Process(samples)
+}
+
+// transmittingLocked reports whether the current stretch of silence is
+// still short enough to transmit. Caller must hold v.mu.
+func (v *VoiceActivityDetector) transmittingLocked() bool {
+	return v.silence <= v.minSilenceDuration || (v.voiced && v.silence <= v.holdTime)
+}
+
+// frameEnergyDB returns the RMS level of the frame in dBFS, or -Inf for
+// digital silence.
+func frameEnergyDB(samples []int16) float64 {
+	var sum float64
+	for _, s := range samples {
+		f := float64(s)
+		sum += f * f
+	}
+	rms := math.Sqrt(sum / float64(len(samples)))
+	if rms == 0 {
+		return math.Inf(-1)
+	}
+	return 20 * math.Log10(rms/32768.0)
+}
+
+// SetThresholdDB changes the energy threshold in dBFS.
+func (v *VoiceActivityDetector) SetThresholdDB(thresholdDB float64) error {
+	if err := validateVADThreshold(thresholdDB); err != nil {
+		return err
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.thresholdDB = thresholdDB
+	return nil
+}
+
+// SetMinSilenceDuration changes how long silence must last before frames
+// are suppressed.
+func (v *VoiceActivityDetector) SetMinSilenceDuration(d time.Duration) error {
+	if d < 0 {
+		return fmt.Errorf("minimum silence duration cannot be negative: %v", d)
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.minSilenceDuration = d
+	return nil
+}
+
+// SetHoldTime changes how long frames keep being transmitted after the
+// last voiced frame.
+func (v *VoiceActivityDetector) SetHoldTime(d time.Duration) error {
+	if d < 0 {
+		return fmt.Errorf("hold time cannot be negative: %v", d)
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.holdTime = d
+	return nil
+}
+
+// IsVoiceActive reports whether the most recent frame is being transmitted,
+// either because it was voiced or because silence has not lasted long
+// enough to suppress it.
+func (v *VoiceActivityDetector) IsVoiceActive() bool {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.transmittingLocked()
+}
+
+// GetName returns the effect name.
+func (v *VoiceActivityDetector) GetName() string {
+	return "VoiceActivityDetector"
+}
+
+// Close releases resources used by the detector.
+func (v *VoiceActivityDetector) Close() error {
+	return nil
+}
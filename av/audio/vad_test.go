@@ -0,0 +1,138 @@
+package audio
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// vadFrame returns a 20ms frame at 48kHz of a sine wave with the given peak
+// amplitude.
+func vadFrame(amplitude float64) []int16 {
+	frame := make([]int16, 960)
+	for i := range frame {
+		frame[i] = int16(amplitude * math.Sin(2*math.Pi*440*float64(i)/48000))
+	}
+	return frame
+}
+
+func TestVoiceActivityDetector_NewVoiceActivityDetector(t *testing.T) {
+	if _, err := NewVoiceActivityDetector(DefaultVADThresholdDB, 48000); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := NewVoiceActivityDetector(DefaultVADThresholdDB, 0); err == nil {
+		t.Error("expected error for zero sample rate")
+	}
+	if _, err := NewVoiceActivityDetector(3, 48000); err == nil {
+		t.Error("expected error for positive threshold")
+	}
+	if _, err := NewVoiceActivityDetector(math.NaN(), 48000); err == nil {
+		t.Error("expected error for NaN threshold")
+	}
+}
+
+func TestVoiceActivityDetector_Apply(t *testing.T) {
+	vad, err := NewVoiceActivityDetector(DefaultVADThresholdDB, 48000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	voice := vadFrame(8000) // about -15 dBFS
+	quiet := vadFrame(100)  // about -53 dBFS
+
+	out, err := vad.Apply(voice)
+	if err != nil || len(out) != len(voice) {
+		t.Fatalf("expected voiced frame passed through, got %d samples (%v)", len(out), err)
+	}
+
+	// 300ms of silence is 15 frames; the 16th is suppressed.
+	for i := 1; i <= 15; i++ {
+		if out, _ := vad.Apply(quiet); len(out) == 0 {
+			t.Fatalf("silent frame %d suppressed before the minimum silence duration", i)
+		}
+	}
+	if out, _ := vad.Apply(quiet); len(out) != 0 {
+		t.Errorf("expected frame suppressed after 320ms of silence, got %d samples", len(out))
+	}
+	if vad.IsVoiceActive() {
+		t.Error("expected voice inactive while suppressing")
+	}
+
+	if out, _ := vad.Apply(voice); len(out) != len(voice) {
+		t.Error("expected transmission to resume on voice")
+	}
+}
+
+func TestVoiceActivityDetector_HoldTime(t *testing.T) {
+	vad, err := NewVoiceActivityDetector(DefaultVADThresholdDB, 48000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := vad.SetMinSilenceDuration(0); err != nil {
+		t.Fatal(err)
+	}
+	quiet := make([]int16, 960)
+
+	if out, _ := vad.Apply(quiet); len(out) != 0 {
+		t.Error("expected silence suppressed before any voice")
+	}
+	vad.Apply(vadFrame(8000))
+	// The 200ms hold keeps 10 frames after voice.
+	for i := 1; i <= 10; i++ {
+		if out, _ := vad.Apply(quiet); len(out) == 0 {
+			t.Fatalf("frame %d suppressed within the hold time", i)
+		}
+	}
+	if out, _ := vad.Apply(quiet); len(out) != 0 {
+		t.Error("expected frame suppressed after the hold time")
+	}
+
+	if err := vad.SetHoldTime(-time.Second); err == nil {
+		t.Error("expected error for negative hold time")
+	}
+}
+
+func TestEffectChain_SuppressedFrame(t *testing.T) {
+	vad, err := NewVoiceActivityDetector(DefaultVADThresholdDB, 48000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := vad.SetMinSilenceDuration(0); err != nil {
+		t.Fatal(err)
+	}
+	gain, err := NewGainEffect(2.0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chain := NewEffectChain()
+	chain.AddEffect(vad)
+	chain.AddEffect(gain)
+
+	out, err := chain.Process(make([]int16, 960))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out == nil || len(out) != 0 {
+		t.Errorf("expected an empty non-nil frame, got %v", out)
+	}
+}
+
+func TestProcessor_ProcessOutgoingSuppressedFrame(t *testing.T) {
+	processor := NewProcessor()
+	defer processor.Close()
+	vad, err := NewVoiceActivityDetector(DefaultVADThresholdDB, 48000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := vad.SetMinSilenceDuration(0); err != nil {
+		t.Fatal(err)
+	}
+	processor.AddEffect(vad)
+
+	encoded, err := processor.ProcessOutgoing(make([]int16, 960), 48000)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(encoded) != 0 {
+		t.Errorf("expected no encoded data for a suppressed frame, got %d bytes", len(encoded))
+	}
+}
@@ -0,0 +1,418 @@
+package audio
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/cmplx"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ErrInvalidWatermark is returned by NewWatermarkEffect for an empty or
+// oversized payload or an unsupported sample rate.
+var ErrInvalidWatermark = errors.New("invalid watermark")
+
+const (
+	// MaxWatermarkPayload is the largest payload in bytes that
+	// NewWatermarkEffect accepts.
+	MaxWatermarkPayload = 16
+
+	// WatermarkBitRate is the approximate number of payload bits embedded
+	// per second of audio. A frame carries 24 bits of overhead plus the
+	// payload, so a 2-byte payload repeats every 10 s and any 20 s of
+	// watermarked audio contains a complete frame.
+	WatermarkBitRate = 4
+
+	// watermarkChips is the length of the pseudo-noise sequence. Each bit
+	// spans a whole number of sequence repetitions.
+	watermarkChips = 1024
+	// watermarkSeed seeds the pseudo-noise sequence shared by the embedder
+	// and the extractor.
+	watermarkSeed = 0x5eed7013
+	// watermarkStrength is the watermark amplitude relative to the signal
+	// RMS, kept below 0.1% (-60 dB) so rounding does not exceed it.
+	watermarkStrength = 0.0009
+	// watermarkMinAmplitude keeps the watermark at least this many LSBs in
+	// quiet passages so it survives requantization.
+	watermarkMinAmplitude = 2.0
+	// watermarkSync starts every frame.
+	watermarkSync = 0xB5
+)
+
+// WatermarkEffect embeds a payload into PCM audio as a spread-spectrum
+// watermark for forensic tracing, for example to find which participant of
+// a conference bridge leaked a recording.
+//
+// Each payload bit is spread over about a quarter second of audio by
+// adding a pseudo-noise sequence, inverted for a zero bit, in the least
+// significant bits of the samples. The amplitude follows the signal level,
+// just under 0.1% of its RMS, with a floor of a few LSBs. The payload is
+// framed as a sync byte, a length byte, the payload and a CRC-8, repeated
+// for as long as audio is processed, so any complete frame in a recording
+// identifies the source. ExtractWatermark recovers it without the original audio.
+//
+// Correlating over thousands of samples per bit lets the watermark survive
+// requantization, gain changes and added noise. Codecs that do not
+// preserve the waveform remove it.
+type WatermarkEffect struct {
+	mu            sync.Mutex
+	sampleRate    int
+	bits          []int8 // Frame bits as +1 or -1
+	samplesPerBit int
+	position      int // Samples processed since the start of the first frame
+}
+
+// NewWatermarkEffect creates an effect that embeds payload into audio at
+// sampleRate.
+//
+// Parameters:
+//   - payload: 1 to MaxWatermarkPayload bytes, such as a participant ID
+//   - sampleRate: Sample rate of the processed audio in Hz (8000-48000)
+//
+// Returns:
+//   - *WatermarkEffect: New watermark effect instance
+//   - error: ErrInvalidWatermark for an unusable payload or sample rate
+func NewWatermarkEffect(payload []byte, sampleRate int) (*WatermarkEffect, error) {
+	if len(payload) == 0 || len(payload) > MaxWatermarkPayload {
+		err := fmt.Errorf("%w: payload must be 1-%d bytes, got %d", ErrInvalidWatermark, MaxWatermarkPayload, len(payload))
+		pkgLog.WithFields(logrus.Fields{
+			"function": "NewWatermarkEffect",
+			"error":    err.Error(),
+		}).Error("Watermark validation failed")
+		return nil, err
+	}
+	if sampleRate < 8000 || sampleRate > 48000 {
+		err := fmt.Errorf("%w: sample rate %d not in 8000-48000", ErrInvalidWatermark, sampleRate)
+		pkgLog.WithFields(logrus.Fields{
+			"function": "NewWatermarkEffect",
+			"error":    err.Error(),
+		}).Error("Watermark validation failed")
+		return nil, err
+	}
+
+	frame := make([]byte, 0, len(payload)+3)
+	frame = append(frame, watermarkSync, byte(len(payload)))
+	frame = append(frame, payload...)
+	frame = append(frame, crc8(frame))
+
+	bits := make([]int8, 0, len(frame)*8)
+	for _, b := range frame {
+		for i := 7; i >= 0; i-- {
+			if b>>uint(i)&1 == 1 {
+				bits = append(bits, 1)
+			} else {
+				bits = append(bits, -1)
+			}
+		}
+	}
+
+	effect := &WatermarkEffect{
+		sampleRate:    sampleRate,
+		bits:          bits,
+		samplesPerBit: watermarkRepeats(sampleRate) * watermarkChips,
+	}
+
+	pkgLog.WithFields(logrus.Fields{
+		"function":        "NewWatermarkEffect",
+		"payload_bytes":   len(payload),
+		"sample_rate":     sampleRate,
+		"samples_per_bit": effect.samplesPerBit,
+		"frame_seconds":   float64(len(bits)*effect.samplesPerBit) / float64(sampleRate),
+	}).Info("Watermark effect created successfully")
+	return effect, nil
+}
+
+// Process adds the watermark to mono PCM samples in place. Consecutive
+// calls continue the watermark where the previous call stopped.
+func (w *WatermarkEffect) Process(samples []int16) ([]int16, error) {
+	if len(samples) == 0 {
+		return samples, nil
+	}
+
+	amplitude := watermarkSignalRMS(samples) * watermarkStrength
+	if amplitude < watermarkMinAmplitude {
+		amplitude = watermarkMinAmplitude
+	}
+	pn := watermarkSequence()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	frameSamples := len(w.bits) * w.samplesPerBit
+	for i, sample := range samples {
+		pos := w.position
+		chip := float64(pn[pos%watermarkChips] * w.bits[pos/w.samplesPerBit])
+		samples[i] = clampSample(float64(sample) + math.Round(amplitude*chip))
+		w.position = (pos + 1) % frameSamples
+	}
+
+	pkgLog.WithFields(logrus.Fields{
+		"function":     "WatermarkEffect.Process",
+		"sample_count": len(samples),
+		"amplitude":    amplitude,
+	}).Debug("Embedded watermark")
+	return samples, nil
+}
+
+// GetName returns the effect name for debugging and logging.
+func (w *WatermarkEffect) GetName() string {
+	return "Watermark"
+}
+
+// Close releases resources. The watermark effect holds none.
+func (w *WatermarkEffect) Close() error {
+	return nil
+}
+
+// ExtractWatermark recovers a payload embedded by WatermarkEffect from mono
+// PCM samples at sampleRate. The recording may start anywhere in the
+// watermarked stream and have inverted polarity; it must contain one
+// complete frame. It returns false if no frame with a valid checksum is
+// found.
+func ExtractWatermark(samples []int16, sampleRate int) ([]byte, bool) {
+	if sampleRate < 8000 || sampleRate > 48000 {
+		return nil, false
+	}
+	repeats := watermarkRepeats(sampleRate)
+	if len(samples) < 2*repeats*watermarkChips {
+		return nil, false
+	}
+
+	whitened := whitenSamples(samples)
+	reference := whitenSequence(watermarkSequence())
+
+	offset := findChipOffset(whitened, reference)
+	var blocks []float64
+	for start := offset; start+watermarkChips <= len(whitened); start += watermarkChips {
+		var sum float64
+		for i, r := range reference {
+			sum += whitened[start+i] * r
+		}
+		blocks = append(blocks, sum)
+	}
+
+	bits := decodeWatermarkBits(blocks, repeats)
+	for _, polarity := range []int8{1, -1} {
+		if payload, ok := findWatermarkFrame(bits, polarity); ok {
+			return payload, true
+		}
+	}
+	return nil, false
+}
+
+// watermarkRepeats returns how many pseudo-noise sequences make up one bit
+// at sampleRate, giving about WatermarkBitRate bits per second.
+func watermarkRepeats(sampleRate int) int {
+	return max(1, int(math.Round(float64(sampleRate)/float64(WatermarkBitRate*watermarkChips))))
+}
+
+// watermarkSequence returns the shared pseudo-noise sequence of +1 and -1
+// chips from a fixed xorshift generator.
+func watermarkSequence() []int8 {
+	pn := make([]int8, watermarkChips)
+	state := uint32(watermarkSeed)
+	for i := range pn {
+		state ^= state << 13
+		state ^= state >> 17
+		state ^= state << 5
+		if state&1 == 1 {
+			pn[i] = 1
+		} else {
+			pn[i] = -1
+		}
+	}
+	return pn
+}
+
+// watermarkSignalRMS returns the RMS level of samples.
+func watermarkSignalRMS(samples []int16) float64 {
+	var sum float64
+	for _, s := range samples {
+		sum += float64(s) * float64(s)
+	}
+	return math.Sqrt(sum / float64(len(samples)))
+}
+
+// whitenSamples applies a second-order difference, which suppresses the
+// low-frequency energy of voice and music that would otherwise mask the
+// flat-spectrum watermark.
+func whitenSamples(samples []int16) []float64 {
+	out := make([]float64, len(samples))
+	for i := 2; i < len(samples); i++ {
+		out[i] = float64(samples[i]) - 2*float64(samples[i-1]) + float64(samples[i-2])
+	}
+	return out
+}
+
+// whitenSequence applies the second-order difference to the periodic
+// pseudo-noise sequence, giving the matched filter for whitened samples.
+func whitenSequence(pn []int8) []float64 {
+	n := len(pn)
+	out := make([]float64, n)
+	for i := range pn {
+		out[i] = float64(pn[i]) - 2*float64(pn[(i+n-1)%n]) + float64(pn[(i+n-2)%n])
+	}
+	return out
+}
+
+// findChipOffset returns the offset in [0, watermarkChips) at which the
+// pseudo-noise sequence repeats in whitened, found by summing the squared
+// cross-correlation of every sequence-length block. Squaring makes the sum
+// independent of the unknown bit signs.
+func findChipOffset(whitened, reference []float64) int {
+	size := 2 * watermarkChips
+	ref := make([]complex128, size)
+	for i, r := range reference {
+		ref[i] = complex(r, 0)
+	}
+	fftInPlace(ref, false)
+
+	energy := make([]float64, watermarkChips)
+	window := make([]complex128, size)
+	for start := 0; start+size <= len(whitened); start += watermarkChips {
+		for i := range window {
+			window[i] = complex(whitened[start+i], 0)
+		}
+		fftInPlace(window, false)
+		for i := range window {
+			window[i] *= cmplx.Conj(ref[i])
+		}
+		fftInPlace(window, true)
+		for o := range energy {
+			c := real(window[o])
+			energy[o] += c * c
+		}
+	}
+
+	best := 0
+	for o, e := range energy {
+		if e > energy[best] {
+			best = o
+		}
+	}
+	return best
+}
+
+// decodeWatermarkBits groups sequence-length correlations into bits. The
+// bit boundary is the grouping phase with the largest total correlation
+// magnitude.
+func decodeWatermarkBits(blocks []float64, repeats int) []int8 {
+	bestPhase, bestScore := 0, -1.0
+	for phase := 0; phase < repeats; phase++ {
+		var score float64
+		for start := phase; start+repeats <= len(blocks); start += repeats {
+			var sum float64
+			for _, b := range blocks[start : start+repeats] {
+				sum += b
+			}
+			score += math.Abs(sum)
+		}
+		if score > bestScore {
+			bestPhase, bestScore = phase, score
+		}
+	}
+
+	var bits []int8
+	for start := bestPhase; start+repeats <= len(blocks); start += repeats {
+		var sum float64
+		for _, b := range blocks[start : start+repeats] {
+			sum += b
+		}
+		if sum >= 0 {
+			bits = append(bits, 1)
+		} else {
+			bits = append(bits, -1)
+		}
+	}
+	return bits
+}
+
+// findWatermarkFrame scans bits, multiplied by polarity, for a frame with
+// a valid sync byte, length and checksum and returns its payload.
+func findWatermarkFrame(bits []int8, polarity int8) ([]byte, bool) {
+	readByte := func(at int) byte {
+		var b byte
+		for _, bit := range bits[at : at+8] {
+			b <<= 1
+			if bit*polarity > 0 {
+				b |= 1
+			}
+		}
+		return b
+	}
+
+	for start := 0; start+16 <= len(bits); start++ {
+		if readByte(start) != watermarkSync {
+			continue
+		}
+		length := int(readByte(start + 8))
+		if length == 0 || length > MaxWatermarkPayload || start+8*(length+3) > len(bits) {
+			continue
+		}
+		frame := make([]byte, length+3)
+		for i := range frame {
+			frame[i] = readByte(start + 8*i)
+		}
+		if crc8(frame[:length+2]) == frame[length+2] {
+			return frame[2 : length+2], true
+		}
+	}
+	return nil, false
+}
+
+// crc8 computes the CRC-8 (polynomial 0x07) of data.
+func crc8(data []byte) byte {
+	var crc byte
+	for _, b := range data {
+		crc ^= b
+		for i := 0; i < 8; i++ {
+			if crc&0x80 != 0 {
+				crc = crc<<1 ^ 0x07
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
+
+// fftInPlace computes the radix-2 FFT of data, or the inverse FFT scaled
+// by 1/n when inverse is set. len(data) must be a power of two.
+func fftInPlace(data []complex128, inverse bool) {
+	n := len(data)
+	for i, j := 1, 0; i < n; i++ {
+		bit := n >> 1
+		for ; j&bit != 0; bit >>= 1 {
+			j ^= bit
+		}
+		j ^= bit
+		if i < j {
+			data[i], data[j] = data[j], data[i]
+		}
+	}
+
+	sign := -1.0
+	if inverse {
+		sign = 1.0
+	}
+	for size := 2; size <= n; size <<= 1 {
+		step := cmplx.Exp(complex(0, sign*2*math.Pi/float64(size)))
+		for start := 0; start < n; start += size {
+			w := complex(1, 0)
+			for k := 0; k < size/2; k++ {
+				even := data[start+k]
+				odd := data[start+k+size/2] * w
+				data[start+k] = even + odd
+				data[start+k+size/2] = even - odd
+				w *= step
+			}
+		}
+	}
+
+	if inverse {
+		for i := range data {
+			data[i] /= complex(float64(n), 0)
+		}
+	}
+}
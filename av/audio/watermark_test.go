@@ -0,0 +1,129 @@
+package audio
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// watermarkHost returns seconds of a voice-like host signal: a few harmonics
+// with a slow amplitude envelope.
+func watermarkHost(sampleRate int, seconds float64) []int16 {
+	n := int(float64(sampleRate) * seconds)
+	samples := make([]int16, n)
+	for i := range samples {
+		t := float64(i) / float64(sampleRate)
+		envelope := 0.6 + 0.4*math.Sin(2*math.Pi*0.7*t)
+		v := 6000*math.Sin(2*math.Pi*220*t) +
+			3000*math.Sin(2*math.Pi*440*t+0.3) +
+			1500*math.Sin(2*math.Pi*1320*t+1.1)
+		samples[i] = int16(envelope * v)
+	}
+	return samples
+}
+
+// embedWatermark runs host through a watermark effect in 20 ms frames, as
+// the audio pipeline does.
+func embedWatermark(t *testing.T, payload []byte, sampleRate int, host []int16) []int16 {
+	t.Helper()
+	effect, err := NewWatermarkEffect(payload, sampleRate)
+	if err != nil {
+		t.Fatalf("NewWatermarkEffect() error = %v", err)
+	}
+	defer effect.Close()
+
+	marked := append([]int16(nil), host...)
+	frame := sampleRate / 50
+	for start := 0; start < len(marked); start += frame {
+		end := min(start+frame, len(marked))
+		if _, err := effect.Process(marked[start:end]); err != nil {
+			t.Fatalf("Process() error = %v", err)
+		}
+	}
+	return marked
+}
+
+func TestNewWatermarkEffectValidation(t *testing.T) {
+	tests := []struct {
+		name       string
+		payload    []byte
+		sampleRate int
+	}{
+		{"empty payload", nil, 48000},
+		{"payload too long", make([]byte, MaxWatermarkPayload+1), 48000},
+		{"sample rate too low", []byte{1}, 4000},
+		{"sample rate too high", []byte{1}, 96000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewWatermarkEffect(tt.payload, tt.sampleRate); !errors.Is(err, ErrInvalidWatermark) {
+				t.Errorf("got %v, want ErrInvalidWatermark", err)
+			}
+		})
+	}
+}
+
+func TestWatermarkRoundTrip(t *testing.T) {
+	for _, sampleRate := range []int{16000, 48000} {
+		payload := []byte{0xA5, 0x3C}
+		marked := embedWatermark(t, payload, sampleRate, watermarkHost(sampleRate, 12))
+
+		got, ok := ExtractWatermark(marked, sampleRate)
+		if !ok || !bytes.Equal(got, payload) {
+			t.Errorf("%d Hz: ExtractWatermark() = %x, %v; want %x", sampleRate, got, ok, payload)
+		}
+	}
+}
+
+func TestWatermarkIsInaudible(t *testing.T) {
+	host := watermarkHost(48000, 2)
+	marked := embedWatermark(t, []byte{0x12, 0x34}, 48000, host)
+
+	var signal, added float64
+	for i := range host {
+		signal += float64(host[i]) * float64(host[i])
+		d := float64(marked[i]) - float64(host[i])
+		added += d * d
+	}
+	// Distortion relative to the host, the THD+N the watermark introduces.
+	if ratio := math.Sqrt(added / signal); ratio > 0.001 {
+		t.Errorf("watermark level %.5f of signal, want <= 0.001", ratio)
+	}
+}
+
+// TestWatermarkSurvivesTranscoding models a lossy voice path: the recording
+// starts mid-stream, is low-pass filtered, attenuated, mixed with noise and
+// requantized. The Opus decoder available in this tree does not reproduce
+// the input waveform at all, so a real codec round trip cannot be used here.
+func TestWatermarkSurvivesTranscoding(t *testing.T) {
+	const sampleRate = 48000
+	payload := []byte{0xBE, 0xEF}
+	marked := embedWatermark(t, payload, sampleRate, watermarkHost(sampleRate, 24))
+
+	rng := rand.New(rand.NewSource(1))
+	recording := marked[sampleRate*3/2+137:]
+	degraded := make([]int16, len(recording))
+	var prev float64
+	for i, s := range recording {
+		prev = 0.7*float64(s) + 0.3*prev
+		v := 0.8*prev + rng.NormFloat64()*3
+		degraded[i] = clampSample(math.Round(v/2) * 2)
+	}
+
+	got, ok := ExtractWatermark(degraded, sampleRate)
+	if !ok || !bytes.Equal(got, payload) {
+		t.Errorf("ExtractWatermark() = %x, %v; want %x", got, ok, payload)
+	}
+}
+
+func TestExtractWatermarkWithoutMark(t *testing.T) {
+	host := watermarkHost(48000, 12)
+	if got, ok := ExtractWatermark(host, 48000); ok {
+		t.Errorf("ExtractWatermark() found %x in unmarked audio", got)
+	}
+	if _, ok := ExtractWatermark(host[:100], 48000); ok {
+		t.Error("ExtractWatermark() should fail on short input")
+	}
+}
@@ -0,0 +1,440 @@
+package av
+
+import (
+	"encoding/binary"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Bandwidth estimation parameters, modelled on Google Congestion Control (GCC).
+const (
+	// DefaultProbeInterval is how often a probe burst is sent.
+	DefaultProbeInterval = 5 * time.Second
+	// DefaultProbeBurstSize is the number of padding packets per probe burst.
+	DefaultProbeBurstSize = 5
+	// DefaultProbePacketSize is the size in bytes of each padding packet.
+	DefaultProbePacketSize = 1000
+
+	// probeHeaderSize is magic(2) + burst(2) + seq(2) + pad(2) + send time(8).
+	probeHeaderSize = 16
+	// probeMagic marks a packet as a bandwidth probe rather than media.
+	probeMagic = 0xBE57
+
+	// Over-use detector tuning (see draft-ietf-rmcat-gcc-02 section 5.4).
+	initialOveruseThreshold = 12.5 // ms
+	minOveruseThreshold     = 6.0
+	maxOveruseThreshold     = 600.0
+	thresholdGainUp         = 0.01
+	thresholdGainDown       = 0.00018
+	trendSmoothing          = 0.9
+	maxTrendDeltas          = 60 // caps how much sample history amplifies the trend
+
+	// Rate controller tuning.
+	overuseBackoff           = 0.85 // estimate = backoff * receive rate on over-use
+	increasePerSecond        = 1.08 // multiplicative increase in the normal state
+	receiveRateWindow        = 500 * time.Millisecond
+	significantEstimateDelta = 0.10 // relative change that triggers the callback
+)
+
+// BandwidthUsage is the state of the delay-based over-use detector.
+type BandwidthUsage int
+
+const (
+	// BandwidthNormal means queuing delay is stable.
+	BandwidthNormal BandwidthUsage = iota
+	// BandwidthUnderusing means queues are draining.
+	BandwidthUnderusing
+	// BandwidthOverusing means queuing delay is growing; the link is saturated.
+	BandwidthOverusing
+)
+
+// String returns a human-readable representation of the detector state.
+func (u BandwidthUsage) String() string {
+	switch u {
+	case BandwidthNormal:
+		return "normal"
+	case BandwidthUnderusing:
+		return "underusing"
+	case BandwidthOverusing:
+		return "overusing"
+	default:
+		return "unknown"
+	}
+}
+
+// BandwidthEstimateCallback is called when the estimated available bandwidth
+// changes by more than 10% from the last reported value.
+type BandwidthEstimateCallback func(bps uint32)
+
+// ErrNotProbePacket is returned when parsing data that is not a probe packet.
+var ErrNotProbePacket = errors.New("not a bandwidth probe packet")
+
+// ProbePacket is the header carried by each padding packet of a probe burst.
+type ProbePacket struct {
+	BurstID  uint16
+	Sequence uint16
+	SendTime time.Time
+	Size     int
+}
+
+// ParseProbePacket decodes a probe packet produced by BandwidthEstimator.BuildProbeBurst.
+func ParseProbePacket(data []byte) (*ProbePacket, error) {
+	if len(data) < probeHeaderSize || binary.BigEndian.Uint16(data[0:2]) != probeMagic {
+		return nil, ErrNotProbePacket
+	}
+	return &ProbePacket{
+		BurstID:  binary.BigEndian.Uint16(data[2:4]),
+		Sequence: binary.BigEndian.Uint16(data[4:6]),
+		SendTime: time.Unix(0, int64(binary.BigEndian.Uint64(data[8:16]))),
+		Size:     len(data),
+	}, nil
+}
+
+// delaySample is one packet's send and arrival timestamps.
+type delaySample struct {
+	send    time.Time
+	arrival time.Time
+	size    int
+}
+
+// BandwidthEstimator implements a simplified GCC delay-based estimator.
+//
+// Feedback samples (send timestamp from the sender, arrival timestamp reported
+// by the receiver via RTCP) yield one-way delay gradients. Gradients are
+// smoothed into a trend and compared against an adaptive threshold; over-use
+// cuts the estimate to a fraction of the measured receive rate, while normal
+// operation grows it multiplicatively.
+type BandwidthEstimator struct {
+	mu sync.Mutex
+
+	estimate    uint32
+	minEstimate uint32
+	maxEstimate uint32
+
+	probeInterval   time.Duration
+	probeBurstSize  int
+	probePacketSize int
+	lastProbe       time.Time
+	burstID         uint16
+
+	prev        *delaySample
+	trend       float64 // smoothed delay gradient in ms
+	numDeltas   int
+	threshold   float64 // adaptive over-use threshold in ms
+	usage       BandwidthUsage
+	lastUpdate  time.Time
+	lastOveruse time.Time
+	recent      []delaySample // samples within receiveRateWindow
+}
+
+// NewBandwidthEstimator creates an estimator starting at initialBps and
+// bounded to [minBps, maxBps].
+func NewBandwidthEstimator(initialBps, minBps, maxBps uint32) *BandwidthEstimator {
+	if maxBps < minBps {
+		maxBps = minBps
+	}
+	return &BandwidthEstimator{
+		estimate:        clampUint32(initialBps, minBps, maxBps),
+		minEstimate:     minBps,
+		maxEstimate:     maxBps,
+		probeInterval:   DefaultProbeInterval,
+		probeBurstSize:  DefaultProbeBurstSize,
+		probePacketSize: DefaultProbePacketSize,
+		threshold:       initialOveruseThreshold,
+	}
+}
+
+// SetProbeParameters configures probe bursts. Non-positive values keep the current setting.
+func (be *BandwidthEstimator) SetProbeParameters(interval time.Duration, burstSize, packetSize int) {
+	be.mu.Lock()
+	defer be.mu.Unlock()
+	if interval > 0 {
+		be.probeInterval = interval
+	}
+	if burstSize > 0 {
+		be.probeBurstSize = burstSize
+	}
+	if packetSize >= probeHeaderSize {
+		be.probePacketSize = packetSize
+	}
+}
+
+// BuildProbeBurst returns a burst of padding packets if a probe is due at now,
+// or nil otherwise. The caller sends the packets back-to-back over the media path.
+func (be *BandwidthEstimator) BuildProbeBurst(now time.Time) [][]byte {
+	be.mu.Lock()
+	defer be.mu.Unlock()
+
+	if !be.lastProbe.IsZero() && now.Sub(be.lastProbe) < be.probeInterval {
+		return nil
+	}
+	be.lastProbe = now
+	be.burstID++
+
+	burst := make([][]byte, be.probeBurstSize)
+	for i := range burst {
+		pkt := make([]byte, be.probePacketSize)
+		binary.BigEndian.PutUint16(pkt[0:2], probeMagic)
+		binary.BigEndian.PutUint16(pkt[2:4], be.burstID)
+		binary.BigEndian.PutUint16(pkt[4:6], uint16(i))
+		binary.BigEndian.PutUint64(pkt[8:16], uint64(now.UnixNano()))
+		burst[i] = pkt
+	}
+	return burst
+}
+
+// OnFeedback processes one packet's send and arrival timestamps and returns
+// the updated bandwidth estimate in bits per second.
+func (be *BandwidthEstimator) OnFeedback(sendTime, arrivalTime time.Time, size int) uint32 {
+	be.mu.Lock()
+	defer be.mu.Unlock()
+
+	sample := delaySample{send: sendTime, arrival: arrivalTime, size: size}
+	be.trackReceiveRate(sample)
+
+	if be.prev != nil && !sendTime.Before(be.prev.send) {
+		gradient := arrivalTime.Sub(be.prev.arrival) - sendTime.Sub(be.prev.send)
+		be.updateDetector(float64(gradient) / float64(time.Millisecond))
+		be.updateRate(arrivalTime)
+	}
+	be.prev = &sample
+	return be.estimate
+}
+
+// trackReceiveRate keeps samples within the receive rate window.
+func (be *BandwidthEstimator) trackReceiveRate(sample delaySample) {
+	be.recent = append(be.recent, sample)
+	cutoff := sample.arrival.Add(-receiveRateWindow)
+	i := 0
+	for i < len(be.recent) && be.recent[i].arrival.Before(cutoff) {
+		i++
+	}
+	be.recent = be.recent[i:]
+}
+
+// receiveRate returns the measured incoming rate in bits per second.
+func (be *BandwidthEstimator) receiveRate() uint32 {
+	if len(be.recent) < 2 {
+		return 0
+	}
+	span := be.recent[len(be.recent)-1].arrival.Sub(be.recent[0].arrival)
+	if span <= 0 {
+		return 0
+	}
+	bytes := 0
+	for _, s := range be.recent[1:] {
+		bytes += s.size
+	}
+	return uint32(float64(bytes*8) / span.Seconds())
+}
+
+// updateDetector smooths the delay gradient and classifies link usage
+// against an adaptive threshold.
+func (be *BandwidthEstimator) updateDetector(gradientMs float64) {
+	be.trend = trendSmoothing*be.trend + (1-trendSmoothing)*gradientMs
+	if be.numDeltas < maxTrendDeltas {
+		be.numDeltas++
+	}
+	// As in GCC, a sustained trend counts for more than a single late packet
+	modified := be.trend * float64(be.numDeltas)
+
+	switch {
+	case modified > be.threshold:
+		be.usage = BandwidthOverusing
+	case modified < -be.threshold:
+		be.usage = BandwidthUnderusing
+	default:
+		be.usage = BandwidthNormal
+	}
+
+	abs := modified
+	if abs < 0 {
+		abs = -abs
+	}
+	gain := thresholdGainDown
+	if abs > be.threshold {
+		gain = thresholdGainUp
+	}
+	be.threshold += gain * (abs - be.threshold)
+	if be.threshold < minOveruseThreshold {
+		be.threshold = minOveruseThreshold
+	} else if be.threshold > maxOveruseThreshold {
+		be.threshold = maxOveruseThreshold
+	}
+}
+
+// updateRate applies the AIMD rate controller for the current usage state.
+func (be *BandwidthEstimator) updateRate(now time.Time) {
+	elapsed := time.Duration(0)
+	if !be.lastUpdate.IsZero() {
+		elapsed = now.Sub(be.lastUpdate)
+	}
+	be.lastUpdate = now
+
+	switch be.usage {
+	case BandwidthOverusing:
+		// Back off at most once per receive window so a burst of late packets
+		// doesn't collapse the estimate
+		if !be.lastOveruse.IsZero() && now.Sub(be.lastOveruse) < receiveRateWindow {
+			return
+		}
+		be.lastOveruse = now
+		target := be.estimate
+		if rate := be.receiveRate(); rate > 0 && rate < target {
+			target = rate
+		}
+		be.estimate = clampUint32(uint32(float64(target)*overuseBackoff), be.minEstimate, be.maxEstimate)
+	case BandwidthNormal:
+		if elapsed <= 0 {
+			return
+		}
+		factor := 1 + (increasePerSecond-1)*elapsed.Seconds()
+		if factor > increasePerSecond {
+			factor = increasePerSecond
+		}
+		be.estimate = clampUint32(uint32(float64(be.estimate)*factor), be.minEstimate, be.maxEstimate)
+	case BandwidthUnderusing:
+		// Hold: queues are draining and the receive rate understates capacity
+	}
+}
+
+// Estimate returns the current bandwidth estimate in bits per second.
+func (be *BandwidthEstimator) Estimate() uint32 {
+	be.mu.Lock()
+	defer be.mu.Unlock()
+	return be.estimate
+}
+
+// Usage returns the current over-use detector state.
+func (be *BandwidthEstimator) Usage() BandwidthUsage {
+	be.mu.Lock()
+	defer be.mu.Unlock()
+	return be.usage
+}
+
+// clampUint32 limits v to [lo, hi].
+func clampUint32(v, lo, hi uint32) uint32 {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
+
+// EnableBandwidthEstimation attaches a GCC-style estimator to the adapter.
+// The estimator's range spans the configured audio plus video bitrate limits.
+func (ba *BitrateAdapter) EnableBandwidthEstimation() *BandwidthEstimator {
+	ba.mu.Lock()
+	defer ba.mu.Unlock()
+
+	if ba.estimator == nil {
+		limits := ba.limitsConfigLocked()
+		ba.estimator = NewBandwidthEstimator(
+			ba.audioBitRate+ba.videoBitRate,
+			limits.MinAudioBitRate+limits.MinVideoBitRate,
+			limits.MaxAudioBitRate+limits.MaxVideoBitRate,
+		)
+	}
+	return ba.estimator
+}
+
+// SetBandwidthEstimateCallback sets the callback fired when the bandwidth
+// estimate changes by more than 10%.
+func (ba *BitrateAdapter) SetBandwidthEstimateCallback(cb BandwidthEstimateCallback) {
+	ba.mu.Lock()
+	defer ba.mu.Unlock()
+	ba.bandwidthCb = cb
+}
+
+// ProbeBurst returns padding packets to send if a probe is due, or nil.
+// EnableBandwidthEstimation must be called first.
+func (ba *BitrateAdapter) ProbeBurst() [][]byte {
+	ba.mu.RLock()
+	estimator := ba.estimator
+	now := ba.getTimeProvider().Now()
+	ba.mu.RUnlock()
+	if estimator == nil {
+		return nil
+	}
+	return estimator.BuildProbeBurst(now)
+}
+
+// OnBandwidthFeedback feeds a packet's send timestamp and the arrival
+// timestamp reported by the remote peer (e.g. from RTCP feedback) into the
+// estimator and applies the resulting estimate.
+func (ba *BitrateAdapter) OnBandwidthFeedback(sendTime, arrivalTime time.Time, size int) {
+	ba.mu.RLock()
+	estimator := ba.estimator
+	ba.mu.RUnlock()
+	if estimator == nil {
+		return
+	}
+	ba.SetEstimatedBandwidth(estimator.OnFeedback(sendTime, arrivalTime, size))
+}
+
+// SetEstimatedBandwidth caps audio and video allocations to fit within bps.
+// Audio is served first, up to its configured maximum; video receives the
+// remainder. The caps are applied through SetAdaptationConfig, so later
+// AIMD adaptation stays within the estimate.
+func (ba *BitrateAdapter) SetEstimatedBandwidth(bps uint32) {
+	ba.mu.Lock()
+	limits := ba.limitsConfigLocked()
+	audioCap := clampUint32(bps, limits.MinAudioBitRate, limits.MaxAudioBitRate)
+	videoBudget := uint32(0)
+	if bps > audioCap {
+		videoBudget = bps - audioCap
+	}
+	videoCap := clampUint32(videoBudget, limits.MinVideoBitRate, limits.MaxVideoBitRate)
+
+	cfg := *limits
+	cfg.MaxAudioBitRate = audioCap
+	cfg.MaxVideoBitRate = videoCap
+
+	cb := ba.bandwidthCb
+	notify := false
+	if isSignificantEstimateChange(ba.reportedBandwidth, bps) {
+		ba.reportedBandwidth = bps
+		notify = cb != nil
+	}
+	ba.applyConfigLocked(&cfg)
+	ba.mu.Unlock()
+
+	pkgLog.WithFields(logrus.Fields{
+		"function":  "SetEstimatedBandwidth",
+		"bandwidth": bps,
+		"audio_cap": audioCap,
+		"video_cap": videoCap,
+	}).Debug("Applied bandwidth estimate to bitrate limits")
+
+	if notify {
+		ba.callbackWg.Add(1)
+		go func() {
+			defer ba.callbackWg.Done()
+			cb(bps)
+		}()
+	}
+}
+
+// GetEstimatedBandwidth returns the last bandwidth estimate reported to the callback.
+func (ba *BitrateAdapter) GetEstimatedBandwidth() uint32 {
+	ba.mu.RLock()
+	defer ba.mu.RUnlock()
+	return ba.reportedBandwidth
+}
+
+// isSignificantEstimateChange reports whether next differs from prev by more than 10%.
+func isSignificantEstimateChange(prev, next uint32) bool {
+	if prev == 0 {
+		return next != 0
+	}
+	diff := float64(next) - float64(prev)
+	if diff < 0 {
+		diff = -diff
+	}
+	return diff/float64(prev) > significantEstimateDelta
+}
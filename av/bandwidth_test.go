@@ -0,0 +1,112 @@
+package av
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildProbeBurst(t *testing.T) {
+	be := NewBandwidthEstimator(500000, 100000, 2000000)
+	now := time.Unix(1000, 0)
+
+	burst := be.BuildProbeBurst(now)
+	require.Len(t, burst, DefaultProbeBurstSize)
+	assert.Nil(t, be.BuildProbeBurst(now.Add(time.Second)), "probe must wait for the interval")
+	assert.Len(t, be.BuildProbeBurst(now.Add(DefaultProbeInterval)), DefaultProbeBurstSize)
+
+	probe, err := ParseProbePacket(burst[2])
+	require.NoError(t, err)
+	assert.Equal(t, uint16(1), probe.BurstID)
+	assert.Equal(t, uint16(2), probe.Sequence)
+	assert.True(t, probe.SendTime.Equal(now))
+	assert.Equal(t, DefaultProbePacketSize, probe.Size)
+
+	_, err = ParseProbePacket([]byte{0x80, 0x60, 0, 0})
+	assert.ErrorIs(t, err, ErrNotProbePacket)
+}
+
+func TestBandwidthEstimatorGrowsOnStableDelay(t *testing.T) {
+	be := NewBandwidthEstimator(500000, 100000, 2000000)
+	start := time.Unix(1000, 0)
+
+	for i := 0; i < 100; i++ {
+		send := start.Add(time.Duration(i) * 20 * time.Millisecond)
+		be.OnFeedback(send, send.Add(40*time.Millisecond), 1000)
+	}
+
+	assert.Equal(t, BandwidthNormal, be.Usage())
+	assert.Greater(t, be.Estimate(), uint32(500000))
+}
+
+func TestBandwidthEstimatorBacksOffOnGrowingDelay(t *testing.T) {
+	be := NewBandwidthEstimator(1000000, 100000, 2000000)
+	start := time.Unix(1000, 0)
+
+	// Each packet is queued 5ms longer than the previous one
+	for i := 0; i < 40; i++ {
+		send := start.Add(time.Duration(i) * 10 * time.Millisecond)
+		delay := 40*time.Millisecond + time.Duration(i)*5*time.Millisecond
+		be.OnFeedback(send, send.Add(delay), 1000)
+	}
+
+	assert.Equal(t, BandwidthOverusing, be.Usage())
+	assert.Less(t, be.Estimate(), uint32(1000000))
+}
+
+func TestSetEstimatedBandwidthCapsAllocations(t *testing.T) {
+	ba := NewBitrateAdapter(DefaultAdaptationConfig(), 64000, 1000000)
+	defer ba.Close()
+
+	ba.SetEstimatedBandwidth(300000)
+	audio, video := ba.GetCurrentBitrates()
+	assert.Equal(t, uint32(64000), audio, "audio is served first")
+	assert.Equal(t, uint32(236000), video, "video receives the remainder")
+
+	// A larger estimate lifts the caps again but never above the configured limits
+	ba.SetEstimatedBandwidth(10000000)
+	ba.increaseBitrates()
+	_, video = ba.GetCurrentBitrates()
+	assert.LessOrEqual(t, video, DefaultAdaptationConfig().MaxVideoBitRate)
+	assert.Greater(t, video, uint32(236000))
+}
+
+func TestBandwidthEstimateCallbackThreshold(t *testing.T) {
+	ba := NewBitrateAdapter(DefaultAdaptationConfig(), 32000, 500000)
+
+	var mu sync.Mutex
+	var reported []uint32
+	ba.SetBandwidthEstimateCallback(func(bps uint32) {
+		mu.Lock()
+		defer mu.Unlock()
+		reported = append(reported, bps)
+	})
+
+	ba.SetEstimatedBandwidth(1000000)
+	ba.SetEstimatedBandwidth(1050000) // 5%: not reported
+	ba.SetEstimatedBandwidth(800000)  // 20%: reported
+	ba.Close()
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.ElementsMatch(t, []uint32{1000000, 800000}, reported)
+	assert.Equal(t, uint32(800000), ba.GetEstimatedBandwidth())
+}
+
+func TestOnBandwidthFeedbackRequiresEstimator(t *testing.T) {
+	ba := NewBitrateAdapter(DefaultAdaptationConfig(), 32000, 500000)
+	defer ba.Close()
+
+	assert.Nil(t, ba.ProbeBurst())
+	ba.OnBandwidthFeedback(time.Now(), time.Now(), 1000)
+	assert.Zero(t, ba.GetEstimatedBandwidth())
+
+	ba.EnableBandwidthEstimation()
+	assert.NotNil(t, ba.ProbeBurst())
+	now := time.Now()
+	ba.OnBandwidthFeedback(now, now.Add(30*time.Millisecond), 1000)
+	assert.NotZero(t, ba.GetEstimatedBandwidth())
+}
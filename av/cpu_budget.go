@@ -0,0 +1,246 @@
+package av
+
+import (
+	"context"
+	"runtime/pprof"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// CPU budget escalation parameters.
+const (
+	// DefaultCPUMeasurementWindow is the default CPU accounting window.
+	DefaultCPUMeasurementWindow = 5 * time.Second
+
+	// frameDropStep is how much the drop rate rises per over-budget window.
+	frameDropStep = 0.25
+	// maxFrameDropRate caps frame dropping so video never stalls entirely.
+	maxFrameDropRate = 0.5
+	// cpuBitrateReduction scales call bitrates once other measures are exhausted.
+	cpuBitrateReduction = 0.8
+	// cpuRecoveryRatio is the fraction of the budget usage must fall below
+	// before measures are relaxed, to avoid oscillation.
+	cpuRecoveryRatio = 0.8
+)
+
+// CPUBudget limits the CPU time spent on media processing across all calls.
+type CPUBudget struct {
+	// MaxCPUPercent is the allowed usage as a percentage of one core
+	MaxCPUPercent float64
+	// MeasurementWindow is the accounting period (default: 5s)
+	MeasurementWindow time.Duration
+}
+
+// CPUWorkKind categorizes tracked media processing work.
+type CPUWorkKind int
+
+const (
+	// CPUWorkEncoding covers audio and video encoding.
+	CPUWorkEncoding CPUWorkKind = iota
+	// CPUWorkEffects covers optional video effects such as color temperature.
+	CPUWorkEffects
+	// CPUWorkOther covers decoding and any other tracked media work.
+	CPUWorkOther
+)
+
+// String returns the pprof label value for the work kind.
+func (k CPUWorkKind) String() string {
+	switch k {
+	case CPUWorkEncoding:
+		return "encoding"
+	case CPUWorkEffects:
+		return "effects"
+	default:
+		return "other"
+	}
+}
+
+// CPUStats reports media CPU usage for the last completed measurement window
+// and the measures currently applied to stay within budget.
+type CPUStats struct {
+	EncodingPercent   float64 // Encoding time as a percentage of one core
+	EffectsPercent    float64 // Effects time as a percentage of one core
+	TotalPercent      float64 // All tracked work as a percentage of one core
+	FrameDropRate     float64 // Fraction of video frames currently being dropped
+	EffectsEnabled    bool    // Whether optional effects may run
+	BitrateReductions int     // Times bitrates were reduced to save CPU
+}
+
+// cpuBudgetState holds CPU accounting for a PerformanceOptimizer.
+type cpuBudgetState struct {
+	mu          sync.Mutex
+	budget      *CPUBudget
+	windowStart time.Time
+	spent       [3]time.Duration
+	stats       CPUStats
+	dropAccum   float64
+}
+
+// SetCPUBudget enables CPU budget enforcement. A nil budget disables it and
+// lifts any frame dropping or effect restrictions.
+func (po *PerformanceOptimizer) SetCPUBudget(budget *CPUBudget) {
+	po.cpu.mu.Lock()
+	defer po.cpu.mu.Unlock()
+
+	po.cpu.stats = CPUStats{EffectsEnabled: true}
+	po.cpu.spent = [3]time.Duration{}
+	po.cpu.windowStart = po.getTimeProvider().Now()
+	if budget == nil {
+		po.cpu.budget = nil
+		return
+	}
+	b := *budget
+	if b.MeasurementWindow <= 0 {
+		b.MeasurementWindow = DefaultCPUMeasurementWindow
+	}
+	po.cpu.budget = &b
+}
+
+// TrackCPU runs fn under a pprof label for kind and charges its duration to
+// the CPU budget. Media work runs on a single goroutine per call, so the
+// elapsed time of fn approximates the CPU time it consumed.
+func (po *PerformanceOptimizer) TrackCPU(kind CPUWorkKind, fn func()) {
+	start := po.getTimeProvider().Now()
+	labels := pprof.Labels("component", "toxav", "work", kind.String())
+	pprof.Do(context.Background(), labels, func(context.Context) {
+		fn()
+	})
+	po.RecordCPUTime(kind, po.getTimeProvider().Now().Sub(start))
+}
+
+// RecordCPUTime charges d of CPU time for kind to the current window.
+func (po *PerformanceOptimizer) RecordCPUTime(kind CPUWorkKind, d time.Duration) {
+	if kind < CPUWorkEncoding || kind > CPUWorkOther || d <= 0 {
+		return
+	}
+	po.cpu.mu.Lock()
+	po.cpu.spent[kind] += d
+	po.cpu.mu.Unlock()
+}
+
+// GetCPUUsage returns CPU statistics for the last completed window.
+func (po *PerformanceOptimizer) GetCPUUsage() CPUStats {
+	po.cpu.mu.Lock()
+	defer po.cpu.mu.Unlock()
+	stats := po.cpu.stats
+	if po.cpu.budget == nil {
+		stats.EffectsEnabled = true
+	}
+	return stats
+}
+
+// EffectsEnabled reports whether optional video effects may be applied.
+// Callers should skip effects such as ColorTemperatureEffect when false.
+func (po *PerformanceOptimizer) EffectsEnabled() bool {
+	return po.GetCPUUsage().EffectsEnabled
+}
+
+// ShouldDropFrame reports whether the next video frame should be skipped to
+// honour the current frame drop rate. Drops are spread evenly across frames.
+func (po *PerformanceOptimizer) ShouldDropFrame() bool {
+	po.cpu.mu.Lock()
+	defer po.cpu.mu.Unlock()
+	if po.cpu.stats.FrameDropRate <= 0 {
+		return false
+	}
+	po.cpu.dropAccum += po.cpu.stats.FrameDropRate
+	if po.cpu.dropAccum >= 1 {
+		po.cpu.dropAccum--
+		return true
+	}
+	return false
+}
+
+// checkCPUBudget closes the measurement window if it has elapsed and
+// escalates or relaxes measures. Escalation order is: raise frame drop rate,
+// disable effects, then reduce call bitrates.
+func (po *PerformanceOptimizer) checkCPUBudget(calls []*Call) {
+	now := po.getTimeProvider().Now()
+
+	po.cpu.mu.Lock()
+	budget := po.cpu.budget
+	if budget == nil {
+		po.cpu.mu.Unlock()
+		return
+	}
+	if po.cpu.windowStart.IsZero() {
+		po.cpu.windowStart = now
+	}
+	elapsed := now.Sub(po.cpu.windowStart)
+	if elapsed < budget.MeasurementWindow {
+		po.cpu.mu.Unlock()
+		return
+	}
+
+	percent := func(d time.Duration) float64 { return float64(d) / float64(elapsed) * 100 }
+	s := &po.cpu.stats
+	s.EncodingPercent = percent(po.cpu.spent[CPUWorkEncoding])
+	s.EffectsPercent = percent(po.cpu.spent[CPUWorkEffects])
+	s.TotalPercent = percent(po.cpu.spent[CPUWorkEncoding] + po.cpu.spent[CPUWorkEffects] + po.cpu.spent[CPUWorkOther])
+	po.cpu.spent = [3]time.Duration{}
+	po.cpu.windowStart = now
+
+	reduceBitrate := false
+	switch {
+	case s.TotalPercent > budget.MaxCPUPercent:
+		reduceBitrate = po.escalateCPUMeasuresLocked()
+	case s.TotalPercent < budget.MaxCPUPercent*cpuRecoveryRatio:
+		po.relaxCPUMeasuresLocked()
+	}
+	stats := *s
+	po.cpu.mu.Unlock()
+
+	if reduceBitrate {
+		for _, call := range calls {
+			if adapter := call.GetBitrateAdapter(); adapter != nil {
+				adapter.ScaleBitrates(cpuBitrateReduction)
+			}
+		}
+	}
+
+	pkgLog.WithFields(logrus.Fields{
+		"function":        "checkCPUBudget",
+		"total_percent":   stats.TotalPercent,
+		"max_percent":     budget.MaxCPUPercent,
+		"frame_drop_rate": stats.FrameDropRate,
+		"effects_enabled": stats.EffectsEnabled,
+		"reduce_bitrate":  reduceBitrate,
+	}).Debug("CPU budget window evaluated")
+}
+
+// escalateCPUMeasuresLocked applies the next measure and reports whether
+// bitrates must be reduced. Caller must hold po.cpu.mu.
+func (po *PerformanceOptimizer) escalateCPUMeasuresLocked() bool {
+	s := &po.cpu.stats
+	switch {
+	case s.FrameDropRate < maxFrameDropRate:
+		s.FrameDropRate += frameDropStep
+		if s.FrameDropRate > maxFrameDropRate {
+			s.FrameDropRate = maxFrameDropRate
+		}
+		return false
+	case s.EffectsEnabled:
+		s.EffectsEnabled = false
+		return false
+	default:
+		s.BitrateReductions++
+		return true
+	}
+}
+
+// relaxCPUMeasuresLocked undoes the most recent measure. Bitrates recover
+// through normal adaptation. Caller must hold po.cpu.mu.
+func (po *PerformanceOptimizer) relaxCPUMeasuresLocked() {
+	s := &po.cpu.stats
+	switch {
+	case !s.EffectsEnabled:
+		s.EffectsEnabled = true
+	case s.FrameDropRate > 0:
+		s.FrameDropRate -= frameDropStep
+		if s.FrameDropRate < 0 {
+			s.FrameDropRate = 0
+		}
+	}
+}
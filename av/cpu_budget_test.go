@@ -0,0 +1,94 @@
+package av
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// runCPUWindow charges the given usage and closes one measurement window.
+func runCPUWindow(po *PerformanceOptimizer, tp *mockTimeProvider, calls []*Call, encoding, effects time.Duration) {
+	po.RecordCPUTime(CPUWorkEncoding, encoding)
+	po.RecordCPUTime(CPUWorkEffects, effects)
+	tp.Advance(time.Second)
+	po.checkCPUBudget(calls)
+}
+
+func TestCPUBudgetEscalationOrder(t *testing.T) {
+	tp := &mockTimeProvider{currentTime: time.Unix(1000, 0)}
+	po := NewPerformanceOptimizer()
+	po.SetTimeProvider(tp)
+	po.SetCPUBudget(&CPUBudget{MaxCPUPercent: 50, MeasurementWindow: time.Second})
+
+	call := NewCall(1)
+	adapter := NewBitrateAdapter(DefaultAdaptationConfig(), 64000, 1000000)
+	defer adapter.Close()
+	call.SetBitrateAdapter(adapter)
+	calls := []*Call{call}
+
+	// 60% encoding + 20% effects per window exceeds the 50% budget
+	runCPUWindow(po, tp, calls, 600*time.Millisecond, 200*time.Millisecond)
+	stats := po.GetCPUUsage()
+	assert.InDelta(t, 60, stats.EncodingPercent, 0.01)
+	assert.InDelta(t, 20, stats.EffectsPercent, 0.01)
+	assert.InDelta(t, 80, stats.TotalPercent, 0.01)
+	assert.Equal(t, 0.25, stats.FrameDropRate)
+	assert.True(t, stats.EffectsEnabled)
+
+	runCPUWindow(po, tp, calls, 600*time.Millisecond, 200*time.Millisecond)
+	assert.Equal(t, 0.5, po.GetCPUUsage().FrameDropRate)
+	assert.True(t, po.EffectsEnabled())
+
+	runCPUWindow(po, tp, calls, 600*time.Millisecond, 200*time.Millisecond)
+	assert.False(t, po.EffectsEnabled(), "effects go before bitrate")
+	_, video := adapter.GetCurrentBitrates()
+	assert.Equal(t, uint32(1000000), video)
+
+	runCPUWindow(po, tp, calls, 600*time.Millisecond, 0)
+	_, video = adapter.GetCurrentBitrates()
+	assert.Equal(t, uint32(800000), video)
+	assert.Equal(t, 1, po.GetCPUUsage().BitrateReductions)
+
+	// Usage well under budget relaxes measures in reverse order
+	runCPUWindow(po, tp, calls, 100*time.Millisecond, 0)
+	assert.True(t, po.EffectsEnabled())
+	assert.Equal(t, 0.5, po.GetCPUUsage().FrameDropRate)
+	runCPUWindow(po, tp, calls, 100*time.Millisecond, 0)
+	assert.Equal(t, 0.25, po.GetCPUUsage().FrameDropRate)
+}
+
+func TestShouldDropFrameSpreadsDrops(t *testing.T) {
+	tp := &mockTimeProvider{currentTime: time.Unix(1000, 0)}
+	po := NewPerformanceOptimizer()
+	po.SetTimeProvider(tp)
+	assert.False(t, po.ShouldDropFrame())
+
+	po.SetCPUBudget(&CPUBudget{MaxCPUPercent: 10, MeasurementWindow: time.Second})
+	runCPUWindow(po, tp, nil, 500*time.Millisecond, 0)
+	runCPUWindow(po, tp, nil, 500*time.Millisecond, 0)
+
+	dropped := 0
+	for i := 0; i < 100; i++ {
+		if po.ShouldDropFrame() {
+			dropped++
+		}
+	}
+	assert.Equal(t, 50, dropped)
+}
+
+func TestTrackCPUAndDisabledBudget(t *testing.T) {
+	tp := &mockTimeProvider{currentTime: time.Unix(1000, 0)}
+	po := NewPerformanceOptimizer()
+	po.SetTimeProvider(tp)
+	po.SetCPUBudget(&CPUBudget{MaxCPUPercent: 100, MeasurementWindow: time.Second})
+
+	po.TrackCPU(CPUWorkEncoding, func() { tp.Advance(300 * time.Millisecond) })
+	tp.Advance(700 * time.Millisecond)
+	po.checkCPUBudget(nil)
+	assert.InDelta(t, 30, po.GetCPUUsage().EncodingPercent, 0.01)
+
+	po.SetCPUBudget(nil)
+	assert.True(t, po.EffectsEnabled())
+	assert.Zero(t, po.GetCPUUsage().FrameDropRate)
+}
@@ -87,6 +87,19 @@
 //	manager.CallControl(friendNumber, av.CallControlMuteAudio)// Mute audio
 //	manager.CallControl(friendNumber, av.CallControlCancel)   // End call
 //
+// # Call Transfer
+//
+// TransferCall hands an active call over to another friend, for example from
+// a receptionist to an agent. The original call ends and the new party
+// receives an incoming call, which it accepts with AnswerCall or declines
+// with EndCall. The invite carries the audio RTP sequence number and
+// timestamp, so the new call's stream continues without a discontinuity:
+//
+//	manager.SetTransferCallCallback(func(fromFriend, toFriend uint32) {
+//	    log.Printf("call from %d accepted by %d", fromFriend, toFriend)
+//	})
+//	err := manager.TransferCall(callerFriend, agentFriend)
+//
 // # Quality Monitoring
 //
 // Monitor call quality in real-time:
@@ -115,6 +128,43 @@
 //	config.MaxVideoBitRate = 1000000// 1 Mbps maximum
 //	manager.SetAdaptationConfig(config)
 //
+// Audio and video can also be adapted independently from per-SSRC RTCP
+// reports. Each stream has its own AudioAdaptationPolicy or
+// VideoAdaptationPolicy thresholds, so a lossy video stream is stepped down
+// while audio keeps its bitrate. With SacrificeVideoForAudio set, degraded
+// audio turns video off instead of lowering audio:
+//
+//	config.SacrificeVideoForAudio = true
+//	adapter.UpdateStreamStats(av.MediaVideo, lossPercent, jitter, time.Now())
+//
+// For proactive adaptation, a GCC-style bandwidth estimator can be attached
+// to a call's BitrateAdapter. Probe bursts of padding packets are sent
+// periodically, and send/arrival timestamp pairs from RTCP feedback drive a
+// delay-gradient over-use detector. Each new estimate caps the audio and
+// video allocations, with audio served first:
+//
+//	adapter := call.GetBitrateAdapter()
+//	adapter.EnableBandwidthEstimation()
+//	adapter.SetBandwidthEstimateCallback(func(bps uint32) {
+//	    fmt.Printf("Estimated bandwidth: %d kbps\n", bps/1000)
+//	})
+//	for _, probe := range adapter.ProbeBurst() { send(probe) }
+//	adapter.OnBandwidthFeedback(sendTime, arrivalTime, size)
+//
+// # CPU Budget
+//
+// The PerformanceOptimizer can cap media CPU usage across all calls.
+// Encoding and effects work wrapped in TrackCPU is labelled for pprof and
+// charged to a measurement window. When a window exceeds MaxCPUPercent the
+// optimizer first raises the video frame drop rate, then disables optional
+// effects, and only then reduces call bitrates:
+//
+//	optimizer.SetCPUBudget(&av.CPUBudget{MaxCPUPercent: 50, MeasurementWindow: 5 * time.Second})
+//	optimizer.TrackCPU(av.CPUWorkEncoding, func() { encodeFrame(frame) })
+//	if optimizer.ShouldDropFrame() { return }
+//	if optimizer.EffectsEnabled() { frame, _ = effects.Apply(frame) }
+//	stats := optimizer.GetCPUUsage()
+//
 // # Call States
 //
 // Calls progress through defined states matching the ToxAV C API:
@@ -136,6 +186,8 @@
 //   - CallRequestPacket: 20 bytes (call initiation)
 //   - CallResponsePacket: 21 bytes (call answer)
 //   - CallControlPacket: Call control messages
+//   - CallTransferPacket: 12 bytes (transfer notice)
+//   - CallTransferInvitePacket: 26 bytes (transferred call offer)
 //
 // # RTP Transport
 //
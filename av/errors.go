@@ -54,6 +54,10 @@ var (
 
 	// ErrInvalidTransition indicates an invalid state transition.
 	ErrInvalidTransition = errors.New("invalid state transition")
+
+	// ErrInvalidTransfer indicates a call cannot be transferred to the
+	// requested friend.
+	ErrInvalidTransfer = errors.New("invalid call transfer")
 )
 
 // Send frame errors.
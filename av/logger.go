@@ -0,0 +1,21 @@
+package av
+
+import (
+	"github.com/opd-ai/toxcore/av/audio"
+	"github.com/opd-ai/toxcore/av/rtp"
+	"github.com/opd-ai/toxcore/av/video"
+	"github.com/opd-ai/toxcore/logging"
+	"github.com/sirupsen/logrus"
+)
+
+// pkgLog is the logger for this package, tagged with component "av".
+var pkgLog = logging.NewComponent("av")
+
+// SetLogLevel sets the log level for the av component, including the audio,
+// rtp and video sub-packages, independently of the global logrus level.
+func SetLogLevel(level logrus.Level) {
+	pkgLog.SetLevel(level)
+	audio.SetLogLevel(level)
+	rtp.SetLogLevel(level)
+	video.SetLogLevel(level)
+}
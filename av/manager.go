@@ -69,6 +69,10 @@ type Manager struct {
 	audioBitRateCallback func(friendNumber, bitRate uint32)
 	videoBitRateCallback func(friendNumber, bitRate uint32)
 
+	// Fired on the transferring side when the new party accepts a call
+	// handed over with TransferCall.
+	transferCallback func(fromFriendNumber, toFriendNumber uint32)
+
 	// Time provider for deterministic testing.
 	// If nil, DefaultTimeProvider is used.
 	timeProvider TimeProvider
@@ -99,19 +103,19 @@ type TransportInterface interface {
 //   - *Manager: The new manager instance
 //   - error: Any error that occurred during setup
 func NewManager(transport TransportInterface, friendAddressLookup func(uint32) ([]byte, error)) (*Manager, error) {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "NewManager",
 	}).Info("Creating new ToxAV manager instance")
 
 	if transport == nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "NewManager",
 			"error":    "transport interface cannot be nil",
 		}).Error("Transport validation failed")
 		return nil, errors.New("transport interface cannot be nil")
 	}
 	if friendAddressLookup == nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "NewManager",
 			"error":    "friend address lookup function cannot be nil",
 		}).Error("Friend lookup validation failed")
@@ -130,7 +134,7 @@ func NewManager(transport TransportInterface, friendAddressLookup func(uint32) (
 		timeProvider:         DefaultTimeProvider{},
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":           "NewManager",
 		"iteration_interval": manager.iterationInterval,
 		"initial_call_id":    manager.nextCallID,
@@ -139,7 +143,7 @@ func NewManager(transport TransportInterface, friendAddressLookup func(uint32) (
 	// Register packet handlers for AV signaling
 	manager.registerPacketHandlers()
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "NewManager",
 	}).Info("ToxAV manager created successfully")
 
@@ -149,7 +153,7 @@ func NewManager(transport TransportInterface, friendAddressLookup func(uint32) (
 // registerPacketHandlers sets up packet handlers for AV signaling.
 // This integrates with the existing transport system to handle call-related packets.
 func (m *Manager) registerPacketHandlers() {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "registerPacketHandlers",
 	}).Info("Registering ToxAV packet handlers")
 
@@ -160,10 +164,12 @@ func (m *Manager) registerPacketHandlers() {
 		0x31: "CallResponse",
 		0x32: "CallControl",
 		0x35: "BitrateControl",
+		0x36: "CallTransfer",
+		0x37: "CallTransferInvite",
 	}
 
 	for packetType, handlerName := range packetHandlers {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":     "registerPacketHandlers",
 			"packet_type":  packetType,
 			"handler_name": handlerName,
@@ -176,8 +182,10 @@ func (m *Manager) registerPacketHandlers() {
 	m.transport.RegisterHandler(0x33, m.handleAudioFrame)     // PacketAVAudioFrame
 	m.transport.RegisterHandler(0x34, m.handleVideoFrame)     // PacketAVVideoFrame
 	m.transport.RegisterHandler(0x35, m.handleBitrateControl) // PacketAVBitrateControl
+	m.transport.RegisterHandler(0x36, m.handleCallTransfer)   // PacketAVCallTransfer
+	m.transport.RegisterHandler(0x37, m.handleTransferInvite) // PacketAVCallTransferInvite
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":      "registerPacketHandlers",
 		"handler_count": len(packetHandlers) + 2, // Include audio and video frame handlers
 	}).Info("ToxAV packet handlers registered successfully")
@@ -189,12 +197,13 @@ func (m *Manager) registerPacketHandlers() {
 //
 // Returns nil on successful processing (call created or rejection sent).
 // Returns an error if deserialization fails or the sender is unknown.
-// processIncomingCall creates and registers a new incoming call.
-func (m *Manager) processIncomingCall(friendNumber uint32, req *CallRequestPacket) error {
+// processIncomingCall creates and registers a new incoming call. If configure
+// is non-nil it is applied to the call before the call callback fires.
+func (m *Manager) processIncomingCall(friendNumber uint32, req *CallRequestPacket, configure func(*Call)) error {
 	m.mu.Lock()
 
 	if _, exists := m.calls[friendNumber]; exists {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":      "handleCallRequest",
 			"friend_number": friendNumber,
 			"call_id":       req.CallID,
@@ -206,6 +215,9 @@ func (m *Manager) processIncomingCall(friendNumber uint32, req *CallRequestPacke
 	}
 
 	call := m.buildIncomingCall(friendNumber, req)
+	if configure != nil {
+		configure(call)
+	}
 	m.calls[friendNumber] = call
 	m.logIncomingCall(friendNumber, req.CallID, call)
 
@@ -241,7 +253,7 @@ func (m *Manager) buildIncomingCall(friendNumber uint32, req *CallRequestPacket)
 }
 
 func (m *Manager) logIncomingCall(friendNumber, callID uint32, call *Call) {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":      "handleCallRequest",
 		"friend_number": friendNumber,
 		"call_id":       callID,
@@ -257,7 +269,7 @@ func (m *Manager) logIncomingCall(friendNumber, callID uint32, call *Call) {
 func (m *Manager) notifyIncomingCall(friendNumber uint32, call *Call) {
 	if m.callCallback != nil {
 		m.callCallback(friendNumber, call.IsAudioEnabled(), call.IsVideoEnabled())
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":      "handleCallRequest",
 			"friend_number": friendNumber,
 		}).Debug("Call callback invoked")
@@ -273,7 +285,7 @@ func (m *Manager) notifyIncomingCall(friendNumber uint32, call *Call) {
 func (m *Manager) notifyIncomingCallDirect(friendNumber uint32, cb func(uint32, bool, bool), audioEnabled, videoEnabled bool) {
 	if cb != nil {
 		cb(friendNumber, audioEnabled, videoEnabled)
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":      "handleCallRequest",
 			"friend_number": friendNumber,
 		}).Debug("Call callback invoked")
@@ -284,7 +296,7 @@ func (m *Manager) notifyIncomingCallDirect(friendNumber uint32, cb func(uint32,
 }
 
 func (m *Manager) handleCallRequest(data, addr []byte) error {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":  "handleCallRequest",
 		"data_size": len(data),
 		"addr_size": len(addr),
@@ -292,14 +304,14 @@ func (m *Manager) handleCallRequest(data, addr []byte) error {
 
 	req, err := DeserializeCallRequest(data)
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "handleCallRequest",
 			"error":    err.Error(),
 		}).Error("Failed to deserialize call request")
 		return fmt.Errorf("failed to deserialize call request: %w", err)
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":       "handleCallRequest",
 		"call_id":        req.CallID,
 		"audio_bit_rate": req.AudioBitRate,
@@ -310,20 +322,20 @@ func (m *Manager) handleCallRequest(data, addr []byte) error {
 
 	friendNumber, found := m.findFriendByAddress(addr)
 	if !found {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "handleCallRequest",
 			"error":    "call request from unknown friend",
 		}).Error("Friend lookup failed")
 		return errors.New("call request from unknown friend")
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":      "handleCallRequest",
 		"friend_number": friendNumber,
 		"call_id":       req.CallID,
 	}).Info("Call request from known friend")
 
-	return m.processIncomingCall(friendNumber, req)
+	return m.processIncomingCall(friendNumber, req, nil)
 }
 
 // handleCallResponse processes incoming call response packets.
@@ -339,7 +351,7 @@ func (m *Manager) updateCallOnAcceptance(call *Call, friendNumber uint32, resp *
 	call.SetVideoBitRate(resp.VideoBitRate)
 	m.updateCallState(call, CallStateSendingAudio)
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":      "handleCallResponse",
 		"friend_number": friendNumber,
 		"call_id":       resp.CallID,
@@ -361,7 +373,7 @@ func (m *Manager) handleCallRejection(call *Call, friendNumber uint32) {
 }
 
 func (m *Manager) handleCallResponse(data, addr []byte) error {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":  "handleCallResponse",
 		"data_size": len(data),
 		"addr_size": len(addr),
@@ -376,19 +388,29 @@ func (m *Manager) handleCallResponse(data, addr []byte) error {
 	// findFriendByAddress, map reads, and the deletion in handleCallRejection
 	// are all performed under the same lock.
 	m.mu.Lock()
-	defer m.mu.Unlock()
 
 	friendNumber, call, err := m.validateCallResponseLocked(resp, addr)
 	if err != nil {
+		m.mu.Unlock()
 		return err
 	}
 
-	if resp.Accepted {
-		m.updateCallOnAcceptance(call, friendNumber, resp)
-	} else {
+	if !resp.Accepted {
 		m.handleCallRejection(call, friendNumber)
+		m.mu.Unlock()
+		return nil
 	}
 
+	m.updateCallOnAcceptance(call, friendNumber, resp)
+
+	// Snapshot the transfer callback so it runs without m.mu held.
+	fromFriend, transferred := call.transferredFrom()
+	cb := m.transferCallback
+	m.mu.Unlock()
+
+	if transferred && cb != nil {
+		cb(fromFriend, friendNumber)
+	}
 	return nil
 }
 
@@ -396,14 +418,14 @@ func (m *Manager) handleCallResponse(data, addr []byte) error {
 func (m *Manager) deserializeAndLogResponse(data []byte) (*CallResponsePacket, error) {
 	resp, err := DeserializeCallResponse(data)
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "handleCallResponse",
 			"error":    err.Error(),
 		}).Error("Failed to deserialize call response")
 		return nil, fmt.Errorf("failed to deserialize call response: %w", err)
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":       "handleCallResponse",
 		"call_id":        resp.CallID,
 		"accepted":       resp.Accepted,
@@ -418,14 +440,14 @@ func (m *Manager) deserializeAndLogResponse(data []byte) (*CallResponsePacket, e
 func (m *Manager) validateCallResponseLocked(resp *CallResponsePacket, addr []byte) (uint32, *Call, error) {
 	friendNumber, found := m.findFriendByAddress(addr)
 	if !found {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "handleCallResponse",
 			"error":    "call response from unknown friend",
 		}).Error("Friend lookup failed")
 		return 0, nil, errors.New("call response from unknown friend")
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":      "handleCallResponse",
 		"friend_number": friendNumber,
 		"call_id":       resp.CallID,
@@ -438,7 +460,7 @@ func (m *Manager) validateCallResponseLocked(resp *CallResponsePacket, addr []by
 		if exists {
 			storedCallID = call.GetCallID()
 		}
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":         "handleCallResponse",
 			"friend_number":    friendNumber,
 			"response_call_id": resp.CallID,
@@ -549,7 +571,7 @@ func (m *Manager) handleBitrateControl(data, addr []byte) error {
 // This routes audio packets to the appropriate RTP session for the call
 // and triggers the audio receive callback when complete frames are decoded.
 func (m *Manager) handleAudioFrame(data, addr []byte) error {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":  "handleAudioFrame",
 		"data_size": len(data),
 	}).Trace("Processing incoming audio frame")
@@ -580,7 +602,7 @@ func (m *Manager) validateAudioFrameCall(addr []byte) (uint32, *Call, error) {
 	friendNumber, found := m.findFriendByAddress(addr)
 	if !found {
 		m.mu.RUnlock()
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "handleAudioFrame",
 			"error":    "audio frame from unknown friend",
 		}).Warn("Ignoring audio frame from unknown peer")
@@ -591,7 +613,7 @@ func (m *Manager) validateAudioFrameCall(addr []byte) (uint32, *Call, error) {
 	m.mu.RUnlock()
 
 	if !exists {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":      "handleAudioFrame",
 			"friend_number": friendNumber,
 			"error":         "no active call",
@@ -605,7 +627,7 @@ func (m *Manager) validateAudioFrameCall(addr []byte) (uint32, *Call, error) {
 // isAudioProcessingReady checks if audio processing components are initialized.
 func (m *Manager) isAudioProcessingReady(call *Call, friendNumber uint32) bool {
 	if call.rtpSession == nil || call.GetAudioProcessor() == nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":      "handleAudioFrame",
 			"friend_number": friendNumber,
 			"rtp_session":   call.rtpSession != nil,
@@ -646,7 +668,7 @@ func (m *Manager) receiveAudioRTPPacket(call *Call, data []byte, friendNumber ui
 
 	frameData, _, err := rtpSession.ReceivePacket(data)
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":      "handleAudioFrame",
 			"friend_number": friendNumber,
 			"error":         err.Error(),
@@ -655,7 +677,7 @@ func (m *Manager) receiveAudioRTPPacket(call *Call, data []byte, friendNumber ui
 	}
 
 	if frameData == nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":      "handleAudioFrame",
 			"friend_number": friendNumber,
 		}).Trace("Audio frame not complete, waiting for more packets")
@@ -668,7 +690,7 @@ func (m *Manager) receiveAudioRTPPacket(call *Call, data []byte, friendNumber ui
 func (m *Manager) decodeAudioFrame(call *Call, frameData []byte, friendNumber uint32, audioProcessor *audio.Processor) ([]int16, uint32, error) {
 	pcmSamples, sampleRate, err := audioProcessor.ProcessIncoming(frameData)
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":      "handleAudioFrame",
 			"friend_number": friendNumber,
 			"error":         err.Error(),
@@ -676,7 +698,7 @@ func (m *Manager) decodeAudioFrame(call *Call, frameData []byte, friendNumber ui
 		return nil, 0, fmt.Errorf("failed to decode audio frame: %w", err)
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":      "handleAudioFrame",
 		"friend_number": friendNumber,
 		"sample_count":  len(pcmSamples),
@@ -697,7 +719,7 @@ func (m *Manager) triggerAudioReceiveCallback(friendNumber uint32, pcmSamples []
 	}
 
 	channels := uint8(1)
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":      "handleAudioFrame",
 		"friend_number": friendNumber,
 		"sample_count":  len(pcmSamples),
@@ -707,7 +729,7 @@ func (m *Manager) triggerAudioReceiveCallback(friendNumber uint32, pcmSamples []
 
 	audioCallback(friendNumber, pcmSamples, len(pcmSamples), channels, sampleRate)
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":      "handleAudioFrame",
 		"friend_number": friendNumber,
 		"frame_size":    len(pcmSamples) * 2,
@@ -718,7 +740,7 @@ func (m *Manager) triggerAudioReceiveCallback(friendNumber uint32, pcmSamples []
 // This routes video packets to the appropriate RTP session for the call
 // and triggers the video receive callback when complete frames are decoded.
 func (m *Manager) handleVideoFrame(data, addr []byte) error {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":  "handleVideoFrame",
 		"data_size": len(data),
 	}).Trace("Processing incoming video frame")
@@ -749,7 +771,7 @@ func (m *Manager) validateVideoFrameCall(addr []byte) (uint32, *Call, error) {
 	friendNumber, found := m.findFriendByAddress(addr)
 	if !found {
 		m.mu.RUnlock()
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "handleVideoFrame",
 			"error":    "video frame from unknown friend",
 		}).Warn("Ignoring video frame from unknown peer")
@@ -760,7 +782,7 @@ func (m *Manager) validateVideoFrameCall(addr []byte) (uint32, *Call, error) {
 	m.mu.RUnlock()
 
 	if !exists {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":      "handleVideoFrame",
 			"friend_number": friendNumber,
 			"error":         "no active call",
@@ -774,7 +796,7 @@ func (m *Manager) validateVideoFrameCall(addr []byte) (uint32, *Call, error) {
 // isVideoProcessingReady checks if video processing components are initialized.
 func (m *Manager) isVideoProcessingReady(call *Call, friendNumber uint32) bool {
 	if call.rtpSession == nil || call.GetVideoProcessor() == nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":      "handleVideoFrame",
 			"friend_number": friendNumber,
 			"rtp_session":   call.rtpSession != nil,
@@ -815,7 +837,7 @@ func (m *Manager) receiveVideoRTPPacket(call *Call, data []byte, friendNumber ui
 
 	frameData, _, err := rtpSession.ReceiveVideoPacket(data)
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":      "handleVideoFrame",
 			"friend_number": friendNumber,
 			"error":         err.Error(),
@@ -824,7 +846,7 @@ func (m *Manager) receiveVideoRTPPacket(call *Call, data []byte, friendNumber ui
 	}
 
 	if frameData == nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":      "handleVideoFrame",
 			"friend_number": friendNumber,
 		}).Trace("Video frame not complete, waiting for more packets")
@@ -837,7 +859,7 @@ func (m *Manager) receiveVideoRTPPacket(call *Call, data []byte, friendNumber ui
 func (m *Manager) decodeVideoFrame(call *Call, frameData []byte, friendNumber uint32, videoProcessor *video.Processor) (*video.VideoFrame, error) {
 	decodedFrame, err := videoProcessor.ProcessIncomingLegacy(frameData)
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":      "handleVideoFrame",
 			"friend_number": friendNumber,
 			"error":         err.Error(),
@@ -845,7 +867,7 @@ func (m *Manager) decodeVideoFrame(call *Call, frameData []byte, friendNumber ui
 		return nil, fmt.Errorf("failed to decode video frame: %w", err)
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":      "handleVideoFrame",
 		"friend_number": friendNumber,
 		"frame_width":   decodedFrame.Width,
@@ -865,7 +887,7 @@ func (m *Manager) triggerVideoReceiveCallback(friendNumber uint32, decodedFrame
 		return
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":      "handleVideoFrame",
 		"friend_number": friendNumber,
 		"width":         decodedFrame.Width,
@@ -895,7 +917,7 @@ func (m *Manager) findFriendByAddress(addr []byte) (uint32, bool) {
 	if m.addressFriendLookup != nil {
 		friendNumber, err := m.addressFriendLookup(addr)
 		if err != nil {
-			logrus.WithFields(logrus.Fields{
+			pkgLog.WithFields(logrus.Fields{
 				"function": "findFriendByAddress",
 				"error":    err.Error(),
 			}).Debug("Address-to-friend lookup failed")
@@ -966,7 +988,7 @@ func (m *Manager) sendCallResponse(friendNumber, callID uint32, accepted bool, a
 // validateCallPrerequisites checks if the manager is running and validates call state.
 func (m *Manager) validateCallPrerequisites(friendNumber uint32) error {
 	if !m.running {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "StartCall",
 			"error":    "manager is not running",
 		}).Error("Manager state validation failed")
@@ -975,7 +997,7 @@ func (m *Manager) validateCallPrerequisites(friendNumber uint32) error {
 
 	// Check if there's already an active call with this friend
 	if _, exists := m.calls[friendNumber]; exists {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":      "StartCall",
 			"friend_number": friendNumber,
 			"error":         "call already active with this friend",
@@ -991,7 +1013,7 @@ func (m *Manager) generateUniqueCallID(friendNumber uint32) uint32 {
 	callID := m.nextCallID
 	m.nextCallID++
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":      "StartCall",
 		"friend_number": friendNumber,
 		"call_id":       callID,
@@ -1029,14 +1051,14 @@ func (m *Manager) buildCallRequestPacket(callID, audioBitRate, videoBitRate uint
 
 // serializeCallRequest serializes a call request packet to bytes.
 func (m *Manager) serializeCallRequest(req *CallRequestPacket, callID uint32) ([]byte, error) {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "StartCall",
 		"call_id":  callID,
 	}).Debug("Serializing call request packet")
 
 	data, err := SerializeCallRequest(req)
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "StartCall",
 			"call_id":  callID,
 			"error":    err.Error(),
@@ -1050,7 +1072,7 @@ func (m *Manager) serializeCallRequest(req *CallRequestPacket, callID uint32) ([
 func (m *Manager) lookupFriendAddress(friendNumber uint32) ([]byte, error) {
 	addr, err := m.friendAddressLookup(friendNumber)
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":      "StartCall",
 			"friend_number": friendNumber,
 			"error":         err.Error(),
@@ -1062,7 +1084,7 @@ func (m *Manager) lookupFriendAddress(friendNumber uint32) ([]byte, error) {
 
 // sendCallRequestPacket sends the serialized call request packet to the friend.
 func (m *Manager) sendCallRequestPacket(friendNumber, callID uint32, data, addr []byte) error {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":      "StartCall",
 		"friend_number": friendNumber,
 		"call_id":       callID,
@@ -1072,7 +1094,7 @@ func (m *Manager) sendCallRequestPacket(friendNumber, callID uint32, data, addr
 
 	err := m.transport.Send(0x30, data, addr) // PacketAVCallRequest
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "StartCall",
 			"call_id":  callID,
 			"error":    err.Error(),
@@ -1109,7 +1131,7 @@ func (m *Manager) createCallSession(friendNumber, callID, audioBitRate, videoBit
 	// Create a bitrate adapter so UpdateNetworkStats is driven during iteration.
 	call.SetBitrateAdapter(newCallBitrateAdapter(audioBitRate, videoBitRate))
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":      "StartCall",
 		"friend_number": friendNumber,
 		"call_id":       callID,
@@ -1131,15 +1153,16 @@ func (m *Manager) setupCallMedia(call *Call, friendNumber, callID uint32) error
 	}
 	if provider, ok := m.transport.(underlyingTransportProvider); ok {
 		transportArg = provider.GetUnderlyingTransport()
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":      "setupCallMedia",
 			"friend_number": friendNumber,
 		}).Debug("Using underlying transport for RTP session")
 	}
+	enableMediaQoS(transportArg)
 
 	err := call.SetupMedia(transportArg, friendNumber)
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "StartCall",
 			"call_id":  callID,
 			"error":    err.Error(),
@@ -1148,10 +1171,46 @@ func (m *Manager) setupCallMedia(call *Call, friendNumber, callID uint32) error
 		delete(m.calls, friendNumber)
 		return fmt.Errorf("failed to setup media for call: %w", err)
 	}
+	m.handleKeyframeRequests(call, friendNumber)
 
 	return nil
 }
 
+// handleKeyframeRequests makes the call's video encoder emit a key frame
+// whenever the peer reports loss on our video stream with a NACK or PLI.
+func (m *Manager) handleKeyframeRequests(call *Call, friendNumber uint32) {
+	rtpSession := call.GetRTPSession()
+	videoProcessor := call.GetVideoProcessor()
+	if rtpSession == nil || videoProcessor == nil {
+		return
+	}
+	rtpSession.SetKeyframeRequestHandler(func() {
+		pkgLog.WithFields(logrus.Fields{
+			"function":      "handleKeyframeRequests",
+			"friend_number": friendNumber,
+		}).Debug("Peer requested video key frame")
+		videoProcessor.RequestKeyframe()
+	})
+}
+
+// enableMediaQoS turns on per-packet DSCP marking on transports that support
+// it, so RTP frames are sent as Expedited Forwarding.
+func enableMediaQoS(t interface{}) {
+	type dscpMarker interface {
+		EnableDSCPMarking(enabled bool) error
+	}
+	marker, ok := t.(dscpMarker)
+	if !ok {
+		return
+	}
+	if err := marker.EnableDSCPMarking(true); err != nil {
+		pkgLog.WithFields(logrus.Fields{
+			"function": "enableMediaQoS",
+			"error":    err.Error(),
+		}).Warn("Failed to enable DSCP marking for media")
+	}
+}
+
 // StartCall initiates a new audio/video call to a friend.
 //
 // This method sends a call request packet and creates a new call session.
@@ -1165,7 +1224,7 @@ func (m *Manager) setupCallMedia(call *Call, friendNumber, callID uint32) error
 // Returns:
 //   - error: Any error that occurred during call initiation
 func (m *Manager) StartCall(friendNumber, audioBitRate, videoBitRate uint32) error {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":       "StartCall",
 		"friend_number":  friendNumber,
 		"audio_bit_rate": audioBitRate,
@@ -1201,7 +1260,7 @@ func (m *Manager) StartCall(friendNumber, audioBitRate, videoBitRate uint32) err
 	// Store call session
 	m.calls[friendNumber] = call
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":      "StartCall",
 		"friend_number": friendNumber,
 		"call_id":       callID,
@@ -1262,11 +1321,12 @@ func (m *Manager) AnswerCall(friendNumber, audioBitRate, videoBitRate uint32) er
 	}
 	if provider, ok := m.transport.(underlyingTransportProvider); ok {
 		transportArg = provider.GetUnderlyingTransport()
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":      "AnswerCall",
 			"friend_number": friendNumber,
 		}).Debug("Using underlying transport for RTP session")
 	}
+	enableMediaQoS(transportArg)
 
 	err = call.SetupMedia(transportArg, friendNumber)
 	if err != nil {
@@ -1274,6 +1334,7 @@ func (m *Manager) AnswerCall(friendNumber, audioBitRate, videoBitRate uint32) er
 		m.updateCallState(call, CallStateError)
 		return fmt.Errorf("failed to setup media for answered call: %w", err)
 	}
+	m.handleKeyframeRequests(call, friendNumber)
 
 	fmt.Printf("Answered call from friend %d (audio: %t, video: %t)\n",
 		friendNumber, call.IsAudioEnabled(), call.IsVideoEnabled())
@@ -1393,7 +1454,7 @@ func (m *Manager) toggleCallState(
 	}
 
 	setState(call, targetState)
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":      funcName,
 		"friend_number": friendNumber,
 	}).Info(successMsg)
@@ -1544,7 +1605,7 @@ func (m *Manager) ShowVideo(friendNumber uint32) error {
 // starting any calls. It follows the established pattern of lifecycle
 // management in toxcore-go components.
 func (m *Manager) Start() error {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "Start",
 	}).Debug("Starting AV manager")
 
@@ -1552,7 +1613,7 @@ func (m *Manager) Start() error {
 	defer m.mu.Unlock()
 
 	if m.running {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "Start",
 			"error":    "already running",
 		}).Error("AV manager is already running")
@@ -1561,7 +1622,7 @@ func (m *Manager) Start() error {
 
 	m.running = true
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "Start",
 	}).Info("AV manager started successfully")
 
@@ -1573,7 +1634,7 @@ func (m *Manager) Start() error {
 // This method ends all active calls and stops the manager operation.
 // It follows the established cleanup patterns in toxcore-go.
 func (m *Manager) Stop() error {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "Stop",
 	}).Debug("Stopping AV manager")
 
@@ -1581,21 +1642,21 @@ func (m *Manager) Stop() error {
 	defer m.mu.Unlock()
 
 	if !m.running {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "Stop",
 		}).Debug("AV manager already stopped")
 		return nil
 	}
 
 	activeCallCount := len(m.calls)
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":          "Stop",
 		"active_call_count": activeCallCount,
 	}).Info("Ending all active calls before shutdown")
 
 	// End all active calls
 	for friendNumber, call := range m.calls {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":      "Stop",
 			"friend_number": friendNumber,
 		}).Debug("Ending call with friend")
@@ -1607,7 +1668,7 @@ func (m *Manager) Stop() error {
 
 	m.running = false
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":    "Stop",
 		"calls_ended": activeCallCount,
 	}).Info("AV manager stopped successfully")
@@ -1652,7 +1713,7 @@ func (m *Manager) Iterate() {
 
 	callCount := len(callSlice)
 	if callCount > 0 && m.performanceOptimizer.IsDetailedLoggingEnabled() {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":   "Iterate",
 			"call_count": callCount,
 		}).Trace("Processing active calls")
@@ -1664,7 +1725,7 @@ func (m *Manager) Iterate() {
 	}
 
 	if callCount > 0 && m.performanceOptimizer.IsDetailedLoggingEnabled() {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":   "Iterate",
 			"call_count": callCount,
 		}).Trace("AV manager iteration completed")
@@ -1678,7 +1739,7 @@ func (m *Manager) Iterate() {
 // for each active call.
 func (m *Manager) processCall(call *Call) {
 	friendNumber := call.GetFriendNumber()
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":      "processCall",
 		"friend_number": friendNumber,
 	}).Trace("Processing call")
@@ -1716,7 +1777,7 @@ func (m *Manager) handleCallTimeout(call *Call, state CallState, friendNumber ui
 		return false
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":              "processCall",
 		"friend_number":         friendNumber,
 		"time_since_last_frame": timeSinceLastFrame,
@@ -1749,7 +1810,7 @@ func (m *Manager) monitorActiveCallQuality(call *Call, state CallState, friendNu
 
 	_, err := m.qualityMonitor.MonitorCall(call, adapter)
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":      "processCall",
 			"friend_number": friendNumber,
 			"error":         err.Error(),
@@ -1772,7 +1833,7 @@ func (m *Manager) feedRTPStatsToAdapter(call *Call, adapter *BitrateAdapter, fri
 		stats.Jitter,
 		time.Now(),
 	); err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":      "processCall",
 			"friend_number": friendNumber,
 			"error":         err.Error(),
@@ -1799,7 +1860,7 @@ func (m *Manager) removeCompletedCall(call *Call, state CallState, friendNumber
 		return
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":      "processCall",
 		"friend_number": friendNumber,
 		"state":         state,
@@ -1812,7 +1873,7 @@ func (m *Manager) removeCompletedCall(call *Call, state CallState, friendNumber
 	delete(m.calls, friendNumber)
 	m.mu.Unlock()
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":      "processCall",
 		"friend_number": friendNumber,
 	}).Info("Failed call removed from active calls")
@@ -1823,7 +1884,7 @@ func (m *Manager) removeCompletedCall(call *Call, state CallState, friendNumber
 // This method provides access to call information for monitoring
 // and control purposes. Returns nil if no call exists.
 func (m *Manager) GetCall(friendNumber uint32) *Call {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":      "GetCall",
 		"friend_number": friendNumber,
 	}).Trace("Looking up call for friend")
@@ -1832,7 +1893,7 @@ func (m *Manager) GetCall(friendNumber uint32) *Call {
 	defer m.mu.RUnlock()
 	call := m.calls[friendNumber]
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":      "GetCall",
 		"friend_number": friendNumber,
 		"call_found":    call != nil,
@@ -1894,7 +1955,7 @@ func (m *Manager) EnablePerformanceOptimization(detailedLogging, cpuProfiling bo
 		return errors.New("performance optimizer not initialized")
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":         "EnablePerformanceOptimization",
 		"detailed_logging": detailedLogging,
 		"cpu_profiling":    cpuProfiling,
@@ -1904,7 +1965,7 @@ func (m *Manager) EnablePerformanceOptimization(detailedLogging, cpuProfiling bo
 
 	if cpuProfiling {
 		if err := m.performanceOptimizer.StartCPUProfiling(); err != nil {
-			logrus.WithFields(logrus.Fields{
+			pkgLog.WithFields(logrus.Fields{
 				"function": "EnablePerformanceOptimization",
 				"error":    err.Error(),
 			}).Error("Failed to start CPU profiling")
@@ -1914,7 +1975,7 @@ func (m *Manager) EnablePerformanceOptimization(detailedLogging, cpuProfiling bo
 		m.performanceOptimizer.StopCPUProfiling()
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "EnablePerformanceOptimization",
 	}).Info("Performance optimization configuration completed")
 
@@ -1948,7 +2009,7 @@ func (m *Manager) GetPerformanceOptimizer() *PerformanceOptimizer {
 // Parameters:
 //   - callback: Function to call when an audio frame is received, or nil to unregister
 func (m *Manager) SetAudioReceiveCallback(callback func(friendNumber uint32, pcm []int16, sampleCount int, channels uint8, samplingRate uint32)) {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":        "SetAudioReceiveCallback",
 		"callback_is_nil": callback == nil,
 	}).Debug("Registering audio receive callback")
@@ -1957,7 +2018,7 @@ func (m *Manager) SetAudioReceiveCallback(callback func(friendNumber uint32, pcm
 	defer m.mu.Unlock()
 	m.audioReceiveCallback = callback
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "SetAudioReceiveCallback",
 	}).Info("Audio receive callback registered")
 }
@@ -1971,7 +2032,7 @@ func (m *Manager) SetAudioReceiveCallback(callback func(friendNumber uint32, pcm
 // Parameters:
 //   - callback: Function to call when a video frame is received, or nil to unregister
 func (m *Manager) SetVideoReceiveCallback(callback func(friendNumber uint32, width, height uint16, y, u, v []byte, yStride, uStride, vStride int)) {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":        "SetVideoReceiveCallback",
 		"callback_is_nil": callback == nil,
 	}).Debug("Registering video receive callback")
@@ -1980,7 +2041,7 @@ func (m *Manager) SetVideoReceiveCallback(callback func(friendNumber uint32, wid
 	defer m.mu.Unlock()
 	m.videoReceiveCallback = callback
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "SetVideoReceiveCallback",
 	}).Info("Video receive callback registered")
 }
@@ -2010,7 +2071,7 @@ func (m *Manager) SetVideoBitRateCallback(callback func(friendNumber, bitRate ui
 // Parameters:
 //   - callback: Function to call when a call request is received, or nil to unregister
 func (m *Manager) SetCallCallback(callback func(friendNumber uint32, audioEnabled, videoEnabled bool)) {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":        "SetCallCallback",
 		"callback_is_nil": callback == nil,
 	}).Debug("Registering call request callback")
@@ -2019,7 +2080,7 @@ func (m *Manager) SetCallCallback(callback func(friendNumber uint32, audioEnable
 	defer m.mu.Unlock()
 	m.callCallback = callback
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "SetCallCallback",
 	}).Info("Call request callback registered")
 }
@@ -2033,7 +2094,7 @@ func (m *Manager) SetCallCallback(callback func(friendNumber uint32, audioEnable
 // Parameters:
 //   - callback: Function to call when call state changes, or nil to unregister
 func (m *Manager) SetCallStateCallback(callback func(friendNumber uint32, state CallState)) {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":        "SetCallStateCallback",
 		"callback_is_nil": callback == nil,
 	}).Debug("Registering call state change callback")
@@ -2042,7 +2103,7 @@ func (m *Manager) SetCallStateCallback(callback func(friendNumber uint32, state
 	defer m.mu.Unlock()
 	m.callStateCallback = callback
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "SetCallStateCallback",
 	}).Info("Call state change callback registered")
 }
@@ -2059,7 +2120,7 @@ func (m *Manager) updateCallState(call *Call, newState CallState) {
 	if m.callStateCallback != nil {
 		friendNumber := call.friendNumber
 		m.callStateCallback(friendNumber, newState)
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":      "updateCallState",
 			"friend_number": friendNumber,
 			"new_state":     newState,
@@ -118,7 +118,7 @@ type AggregatedReport struct {
 // Returns:
 //   - *MetricsAggregator: New aggregator instance
 func NewMetricsAggregator(reportInterval time.Duration) *MetricsAggregator {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":        "NewMetricsAggregator",
 		"report_interval": reportInterval,
 	}).Info("Creating new metrics aggregator")
@@ -135,7 +135,7 @@ func NewMetricsAggregator(reportInterval time.Duration) *MetricsAggregator {
 		cancel:          cancel,
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":         "NewMetricsAggregator",
 		"report_interval":  reportInterval,
 		"history_duration": aggregator.historyDuration,
@@ -156,13 +156,13 @@ func (ma *MetricsAggregator) Start() error {
 	// Validate interval before starting (L-08)
 	if ma.reportInterval <= 0 {
 		ma.reportInterval = 10 * time.Second // Default interval
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "MetricsAggregator.Start",
 			"reason":   "invalid interval",
 		}).Warn("Invalid metrics report interval, using default 10s")
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "MetricsAggregator.Start",
 	}).Info("Starting metrics aggregator")
 
@@ -172,7 +172,7 @@ func (ma *MetricsAggregator) Start() error {
 	ma.loopWg.Add(1)
 	go ma.reportLoop()
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "MetricsAggregator.Start",
 	}).Info("Metrics aggregator started successfully")
 
@@ -187,7 +187,7 @@ func (ma *MetricsAggregator) Stop() {
 		return
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "MetricsAggregator.Stop",
 	}).Info("Stopping metrics aggregator")
 
@@ -203,7 +203,7 @@ func (ma *MetricsAggregator) Stop() {
 	// Wait for in-flight callback goroutines to complete
 	ma.callbackWg.Wait()
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "MetricsAggregator.Stop",
 	}).Info("Metrics aggregator stopped successfully")
 }
@@ -232,7 +232,7 @@ func (ma *MetricsAggregator) OnReport(callback func(report AggregatedReport)) {
 	defer ma.mu.Unlock()
 	ma.reportCallback = callback
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "MetricsAggregator.OnReport",
 	}).Debug("Report callback registered")
 }
@@ -268,8 +268,8 @@ func (ma *MetricsAggregator) RecordMetrics(friendNumber uint32, metrics CallMetr
 	// Update system metrics
 	ma.updateSystemMetrics()
 
-	if logrus.IsLevelEnabled(logrus.TraceLevel) {
-		logrus.WithFields(logrus.Fields{
+	if pkgLog.IsLevelEnabled(logrus.TraceLevel) {
+		pkgLog.WithFields(logrus.Fields{
 			"function":        "MetricsAggregator.RecordMetrics",
 			"friend_number":   friendNumber,
 			"quality":         metrics.Quality.String(),
@@ -285,7 +285,7 @@ func (ma *MetricsAggregator) StartCallTracking(friendNumber uint32) {
 	ma.mu.Lock()
 	defer ma.mu.Unlock()
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":      "MetricsAggregator.StartCallTracking",
 		"friend_number": friendNumber,
 	}).Info("Starting call tracking")
@@ -311,7 +311,7 @@ func (ma *MetricsAggregator) StopCallTracking(friendNumber uint32) {
 	ma.mu.Lock()
 	defer ma.mu.Unlock()
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":      "MetricsAggregator.StopCallTracking",
 		"friend_number": friendNumber,
 	}).Info("Stopping call tracking")
@@ -359,7 +359,7 @@ func (ma *MetricsAggregator) reportLoop() {
 	ticker := time.NewTicker(ma.reportInterval)
 	defer ticker.Stop()
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "MetricsAggregator.reportLoop",
 		"interval": ma.reportInterval,
 	}).Debug("Starting aggregated report loop")
@@ -367,7 +367,7 @@ func (ma *MetricsAggregator) reportLoop() {
 	for {
 		select {
 		case <-ma.ctx.Done():
-			logrus.WithFields(logrus.Fields{
+			pkgLog.WithFields(logrus.Fields{
 				"function": "MetricsAggregator.reportLoop",
 			}).Debug("Report loop stopped")
 			return
@@ -413,7 +413,7 @@ func (ma *MetricsAggregator) generateReport() {
 		callback(report)
 	}()
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":        "MetricsAggregator.generateReport",
 		"active_calls":    report.SystemMetrics.ActiveCalls,
 		"overall_quality": report.OverallQuality.String(),
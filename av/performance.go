@@ -48,6 +48,9 @@ type PerformanceOptimizer struct {
 
 	// Time provider for deterministic testing
 	timeProvider TimeProvider
+
+	// CPU budget accounting and enforcement
+	cpu cpuBudgetState
 }
 
 // NewPerformanceOptimizer creates a new performance optimizer instance.
@@ -57,13 +60,14 @@ type PerformanceOptimizer struct {
 // - Cache validity of 100ms for reasonable responsiveness
 // - Detailed logging disabled by default for maximum performance
 func NewPerformanceOptimizer() *PerformanceOptimizer {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "NewPerformanceOptimizer",
 	}).Debug("Creating new performance optimizer")
 
 	optimizer := &PerformanceOptimizer{
 		cacheValidityNs: 100 * time.Millisecond.Nanoseconds(), // 100ms cache validity
 	}
+	optimizer.cpu.stats.EffectsEnabled = true
 
 	// Initialize call slice pool with pre-allocated slices
 	optimizer.callSlicePool.New = func() interface{} {
@@ -71,7 +75,7 @@ func NewPerformanceOptimizer() *PerformanceOptimizer {
 		return make([]*Call, 0, 8)
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":          "NewPerformanceOptimizer",
 		"cache_validity_ms": optimizer.cacheValidityNs / 1000000,
 		"detailed_logging":  optimizer.IsDetailedLoggingEnabled(),
@@ -125,6 +129,8 @@ func (po *PerformanceOptimizer) OptimizeIteration(m *Manager) ([]*Call, bool) {
 	iterationTime := time.Since(iterationStart)
 	po.updateIterationMetrics(iterationTime)
 
+	po.checkCPUBudget(callSlice)
+
 	return callSlice, true
 }
 
@@ -147,7 +153,7 @@ func (po *PerformanceOptimizer) checkCachedFastPath() (bool, bool) {
 		cachedCount := atomic.LoadInt32(&po.lastCallCount)
 		if cachedCount == 0 {
 			if po.IsDetailedLoggingEnabled() {
-				logrus.WithFields(logrus.Fields{
+				pkgLog.WithFields(logrus.Fields{
 					"function":     "OptimizeIteration",
 					"call_count":   0,
 					"cached":       true,
@@ -193,7 +199,7 @@ func (po *PerformanceOptimizer) updateCacheAndCounters(callCount int) {
 // logProcessing emits conditional detailed logging if enabled.
 func (po *PerformanceOptimizer) logProcessing(iterationStart time.Time, callCount int) {
 	if po.IsDetailedLoggingEnabled() && callCount > 0 {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":     "OptimizeIteration",
 			"call_count":   callCount,
 			"cached":       false,
@@ -254,7 +260,7 @@ func (po *PerformanceOptimizer) EnableDetailedLogging(enabled bool) {
 	}
 	atomic.StoreInt32(&po.enableDetailedLogging, value)
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "EnableDetailedLogging",
 		"enabled":  enabled,
 	}).Info("Detailed logging configuration updated")
@@ -274,7 +280,7 @@ func (po *PerformanceOptimizer) StartCPUProfiling() error {
 		return nil // Already profiling
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "StartCPUProfiling",
 	}).Info("Starting CPU profiling")
 
@@ -294,7 +300,7 @@ func (po *PerformanceOptimizer) StartCPUProfiling() error {
 		})
 	}()
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "StartCPUProfiling",
 	}).Info("CPU profiling started")
 
@@ -307,7 +313,7 @@ func (po *PerformanceOptimizer) StopCPUProfiling() {
 		return // Not profiling
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "StopCPUProfiling",
 	}).Info("Stopping CPU profiling")
 
@@ -321,7 +327,7 @@ func (po *PerformanceOptimizer) StopCPUProfiling() {
 
 	atomic.StoreInt32(&po.enableProfiling, 0)
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "StopCPUProfiling",
 	}).Info("CPU profiling stopped")
 }
@@ -377,7 +383,7 @@ func (po *PerformanceOptimizer) GetPerformanceMetrics() PerformanceMetrics {
 //
 // Useful for benchmarking and performance testing to get clean measurements.
 func (po *PerformanceOptimizer) ResetPerformanceMetrics() {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "ResetPerformanceMetrics",
 	}).Info("Resetting performance metrics")
 
@@ -391,7 +397,7 @@ func (po *PerformanceOptimizer) ResetPerformanceMetrics() {
 	po.peakIterationTime = 0
 	po.metricsLock.Unlock()
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "ResetPerformanceMetrics",
 	}).Info("Performance metrics reset completed")
 }
@@ -155,7 +155,7 @@ func NewQualityMonitor(thresholds *QualityThresholds) *QualityMonitor {
 		thresholds = DefaultQualityThresholds()
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "NewQualityMonitor",
 	}).Info("Creating new quality monitor")
 
@@ -165,7 +165,7 @@ func NewQualityMonitor(thresholds *QualityThresholds) *QualityMonitor {
 		monitorInterval: 5 * time.Second, // Monitor every 5 seconds
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":         "NewQualityMonitor",
 		"monitor_interval": monitor.monitorInterval,
 		"enabled":          monitor.enabled,
@@ -188,7 +188,7 @@ func (qm *QualityMonitor) SetQualityCallback(callback func(friendNumber uint32,
 
 	qm.qualityCallback = callback
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":     "SetQualityCallback",
 		"has_callback": callback != nil,
 	}).Debug("Quality callback updated")
@@ -204,7 +204,7 @@ func (qm *QualityMonitor) SetEnabled(enabled bool) {
 
 	qm.enabled = enabled
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "SetEnabled",
 		"enabled":  enabled,
 	}).Info("Quality monitoring enabled status changed")
@@ -239,7 +239,7 @@ func (qm *QualityMonitor) GetCallMetrics(call *Call, adapter *BitrateAdapter) (C
 	qm.enrichWithRTPStatistics(call, &metrics)
 	metrics.Quality = qm.assessQuality(metrics)
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":      "GetCallMetrics",
 		"friend_number": call.GetFriendNumber(),
 		"quality":       metrics.Quality.String(),
@@ -257,7 +257,7 @@ func (qm *QualityMonitor) buildBasicMetrics(call *Call, adapter *BitrateAdapter)
 	callStart := call.GetStartTime()
 	lastFrame := call.GetLastFrameTime()
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":      "GetCallMetrics",
 		"friend_number": friendNumber,
 	}).Trace("Collecting call metrics")
@@ -283,7 +283,7 @@ func (qm *QualityMonitor) buildBasicMetrics(call *Call, adapter *BitrateAdapter)
 
 	if adapter != nil {
 		metrics.NetworkQuality = adapter.GetNetworkQuality()
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":        "GetCallMetrics",
 			"friend_number":   friendNumber,
 			"network_quality": metrics.NetworkQuality,
@@ -297,7 +297,7 @@ func (qm *QualityMonitor) buildBasicMetrics(call *Call, adapter *BitrateAdapter)
 func (qm *QualityMonitor) enrichWithRTPStatistics(call *Call, metrics *CallMetrics) {
 	rtpSession := call.GetRTPSession()
 	if rtpSession == nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":      "GetCallMetrics",
 			"friend_number": call.GetFriendNumber(),
 		}).Trace("No RTP session available for metrics")
@@ -314,7 +314,7 @@ func (qm *QualityMonitor) enrichWithRTPStatistics(call *Call, metrics *CallMetri
 	metrics.PacketsReceived = rtpStats.PacketsReceived
 	metrics.Jitter = rtpStats.Jitter
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":         "GetCallMetrics",
 		"friend_number":    call.GetFriendNumber(),
 		"packet_loss":      metrics.PacketLoss,
@@ -432,7 +432,7 @@ func (qm *QualityMonitor) MonitorCall(call *Call, adapter *BitrateAdapter) (Call
 	if callback != nil {
 		friendNumber := call.GetFriendNumber()
 
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":      "MonitorCall",
 			"friend_number": friendNumber,
 			"quality":       metrics.Quality.String(),
@@ -463,7 +463,7 @@ func (qm *QualityMonitor) SetMonitorInterval(interval time.Duration) {
 
 	qm.monitorInterval = interval
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "SetMonitorInterval",
 		"interval": interval,
 	}).Debug("Quality monitor interval updated")
@@ -52,6 +52,87 @@
 // The buffer uses a simple time-based approach where packets are held for
 // a configurable duration before being released for playback.
 //
+// SetConfig applies a capacity, prune policy and prune age together.
+// PruneOldest discards the lowest timestamp when the buffer is full;
+// PruneHighestJitter discards the packet that arrived latest relative to its
+// timestamp. GetBufferStats reports depth, discards, late packets and the
+// current delay for tuning:
+//
+//	err := buffer.SetConfig(rtp.JitterBufferConfig{
+//	    MaxCapacity: 50,
+//	    PrunePolicy: rtp.PruneHighestJitter,
+//	    PruneAge:    200 * time.Millisecond,
+//	})
+//	stats := buffer.GetBufferStats()
+//
+// The buffer tracks interarrival jitter with the RFC 3550 estimator
+// (GetJitter). EnableAdaptiveDelay replaces the fixed buffer time with a
+// playout delay of jitter x AdaptationFactor, exponentially smoothed and
+// kept between MinDelay and MaxDelay, so calm networks get low latency and
+// congested ones fewer underflows. GetCurrentDelay reports the live delay:
+//
+//	err := buffer.EnableAdaptiveDelay(rtp.AdaptiveDelayConfig{
+//	    MinDelay:         20 * time.Millisecond,
+//	    MaxDelay:         300 * time.Millisecond,
+//	    AdaptationFactor: 2.5,
+//	    Alpha:            0.1,
+//	})
+//	delay := buffer.GetCurrentDelay()
+//
+// # Redundant Audio (RED)
+//
+// AudioPacketizer.SetRedundancy(levels), or its equivalent SetREDEnabled,
+// turns on RFC 2198 redundancy: each packet is sent with payload type
+// REDPayloadType (116) and carries the previous one or two Opus frames
+// alongside the current one. The depacketizer
+// unwraps RED packets to their primary frame and, when earlier sequence
+// numbers were detected as lost, buffers the redundant copies in their place.
+//
+// # Header Extensions
+//
+// Sessions support RFC 8285 two-byte header extensions. Register each
+// negotiated ID with its URI, attach values to the next outgoing packet, and
+// receive them with the decoded audio:
+//
+//	session.RegisterExtension(1, rtp.AudioLevelURI)
+//	session.SetAudioExtension(1, rtp.NewAudioLevelExtension(30, true).Marshal())
+//	session.SetExtensionHandler(func(audio []byte, exts []rtp.HeaderExtension) {
+//	    // exts holds registered extensions only
+//	})
+//
+// AudioLevelExtension and AbsoluteSendTimeExtension provide typed encoding
+// and decoding for the two most common extensions.
+//
+// # Video FEC
+//
+// Session.EnableVideoFEC(groupSize) sends an XOR parity packet after every
+// groupSize video packets (video.FECGroupSize by default). ReceiveVideoPacket
+// recognises parity packets by video.FECPayloadType and uses them to rebuild
+// a single lost packet per group before frame reassembly.
+//
+// # RTCP
+//
+// Once media flows, a Session sends an RTCP report every DefaultRTCPInterval
+// (RFC 3550 section 6.4): a sender report while it is transmitting audio,
+// otherwise a receiver report, each with a reception block per remote
+// source. Reports are multiplexed with audio RTP by default and told apart
+// by payload type (RFC 5761); SetRTCPMode(rtp.RTCPSeparate) sends them on
+// the dedicated transport.PacketAVRTCP type instead. The peer's reports fill
+// RTT, FractionLost and CumulativeLost in GetStatistics.
+//
+// A receiver whose video decoder lost its reference calls
+// SendPictureLossIndication to ask for a key frame (RFC 4585). A PLI about
+// the session's video stream invokes the handler set with
+// SetKeyframeRequestHandler, which av.Manager wires to the call's video
+// encoder.
+//
+// Gaps in received video sequence numbers are reported with an RFC 4585
+// Generic NACK in the next RTCP report. The sender keeps its last
+// DefaultRetransmitBufferSize video packets in an RTPRetransmitBuffer and
+// resends NACKed packets younger than DefaultMaxRetransmitAge; NACKed
+// packets it can no longer resend trigger a key frame request instead.
+// SetRetransmitBufferSize and SetMaxRetransmitAge tune both limits.
+//
 // # Session Management
 //
 // RTP sessions track statistics and manage packet flow:
@@ -118,7 +199,8 @@
 //
 // Note: The jitter buffer now uses a sorted slice with binary search insertion
 // for timestamp-ordered packet delivery, and includes configurable capacity
-// limits with automatic pruning to prevent unbounded memory growth.
+// limits with automatic pruning to prevent unbounded memory growth. The
+// pruning policy is configured with JitterBuffer.SetConfig.
 //
 // For more detailed integration documentation, see INTEGRATION.md.
 package rtp
@@ -0,0 +1,266 @@
+package rtp
+
+import (
+	"encoding/binary"
+	"fmt"
+	"time"
+
+	"github.com/pion/rtp"
+	"github.com/sirupsen/logrus"
+)
+
+// Well-known RTP header extension URIs.
+const (
+	// AudioLevelURI identifies the client-to-mixer audio level extension (RFC 6464).
+	AudioLevelURI = "urn:ietf:params:rtp-hdrext:ssrc-audio-level"
+	// AbsoluteSendTimeURI identifies the abs-send-time extension used by WebRTC.
+	AbsoluteSendTimeURI = "http://www.webrtc.org/experiments/rtp-hdrext/abs-send-time"
+	// TransmissionOffsetURI identifies the transmission time offset extension (RFC 5450).
+	TransmissionOffsetURI = "urn:ietf:params:rtp-hdrext:toffset"
+)
+
+// maxExtensionValueSize is the largest element an RFC 8285 two-byte header
+// extension can carry.
+const maxExtensionValueSize = 255
+
+// HeaderExtension is a single RTP header extension element received with a
+// packet. URI is the name the extension ID was registered under.
+type HeaderExtension struct {
+	ID    uint8
+	URI   string
+	Value []byte
+}
+
+// ExtensionHandler receives the audio frame and registered header
+// extensions of each packet passed to Session.ReceivePacket.
+type ExtensionHandler func(audio []byte, extensions []HeaderExtension)
+
+// SetExtension attaches an RFC 8285 two-byte header extension element to the
+// next packet sent. Setting the same id twice before sending replaces the
+// earlier value. Pending extensions are cleared once a packet is sent.
+//
+// Parameters:
+//   - id: Extension ID (1-255) negotiated with the peer
+//   - value: Extension payload (at most 255 bytes)
+//
+// Returns:
+//   - error: If id or value is out of range
+func (ap *AudioPacketizer) SetExtension(id uint8, value []byte) error {
+	if err := validateExtension(id, value); err != nil {
+		return err
+	}
+
+	ap.mu.Lock()
+	defer ap.mu.Unlock()
+
+	for i := range ap.pendingExtensions {
+		if ap.pendingExtensions[i].ID == id {
+			ap.pendingExtensions[i].Value = append([]byte(nil), value...)
+			return nil
+		}
+	}
+	ap.pendingExtensions = append(ap.pendingExtensions, HeaderExtension{
+		ID:    id,
+		Value: append([]byte(nil), value...),
+	})
+	return nil
+}
+
+// applyExtensions copies pending extensions into header and clears them.
+func (ap *AudioPacketizer) applyExtensions(header *rtp.Header) error {
+	if len(ap.pendingExtensions) == 0 {
+		return nil
+	}
+	header.Extension = true
+	header.ExtensionProfile = rtp.ExtensionProfileTwoByte
+	for _, ext := range ap.pendingExtensions {
+		if err := header.SetExtension(ext.ID, ext.Value); err != nil {
+			return fmt.Errorf("failed to set header extension %d: %w", ext.ID, err)
+		}
+	}
+	ap.pendingExtensions = nil
+	return nil
+}
+
+// validateExtension checks id and value against two-byte header limits.
+func validateExtension(id uint8, value []byte) error {
+	if id == 0 {
+		return fmt.Errorf("extension ID 0 is reserved")
+	}
+	if len(value) > maxExtensionValueSize {
+		return fmt.Errorf("extension value too large: %d bytes (max %d)", len(value), maxExtensionValueSize)
+	}
+	return nil
+}
+
+// RegisterExtension associates an extension ID with its URI for this
+// session. Only registered extensions are sent with SetAudioExtension or
+// delivered to the ExtensionHandler; re-registering an ID replaces its URI.
+//
+// Parameters:
+//   - id: Extension ID (1-255) negotiated with the peer
+//   - uri: Extension URI, e.g. AudioLevelURI
+//
+// Returns:
+//   - error: If id is 0 or uri is empty
+func (s *Session) RegisterExtension(id uint8, uri string) error {
+	if id == 0 {
+		return fmt.Errorf("extension ID 0 is reserved")
+	}
+	if uri == "" {
+		return fmt.Errorf("extension URI cannot be empty")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.extensions == nil {
+		s.extensions = make(map[uint8]string)
+	}
+	s.extensions[id] = uri
+
+	pkgLog.WithFields(logrus.Fields{
+		"function":      "Session.RegisterExtension",
+		"friend_number": s.friendNumber,
+		"id":            id,
+		"uri":           uri,
+	}).Debug("Registered RTP header extension")
+
+	return nil
+}
+
+// SetAudioExtension attaches a registered extension to the next audio packet.
+func (s *Session) SetAudioExtension(id uint8, value []byte) error {
+	s.mu.RLock()
+	_, registered := s.extensions[id]
+	packetizer := s.audioPacketizer
+	s.mu.RUnlock()
+
+	if !registered {
+		return fmt.Errorf("extension ID %d not registered", id)
+	}
+	if packetizer == nil {
+		return fmt.Errorf("audio packetizer not initialized")
+	}
+	return packetizer.SetExtension(id, value)
+}
+
+// SetExtensionHandler sets the callback that receives header extensions
+// alongside decoded audio in ReceivePacket. Pass nil to remove it.
+// The handler is called without the session lock held.
+func (s *Session) SetExtensionHandler(handler ExtensionHandler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.extensionHandler = handler
+}
+
+// registeredExtensions returns the registered extensions present in header.
+func (s *Session) registeredExtensions(header *rtp.Header) []HeaderExtension {
+	if !header.Extension || len(s.extensions) == 0 {
+		return nil
+	}
+	var exts []HeaderExtension
+	for _, id := range header.GetExtensionIDs() {
+		uri, ok := s.extensions[id]
+		if !ok {
+			continue
+		}
+		exts = append(exts, HeaderExtension{
+			ID:    id,
+			URI:   uri,
+			Value: append([]byte(nil), header.GetExtension(id)...),
+		})
+	}
+	return exts
+}
+
+// AudioLevelExtension is the RFC 6464 audio level indication: the level of
+// the audio in the packet in -dBov (0 is loudest, 127 is silence) and
+// whether it contains voice activity.
+type AudioLevelExtension struct {
+	level uint8
+	voice bool
+}
+
+// NewAudioLevelExtension creates an audio level extension. Levels above 127
+// are clamped to 127.
+func NewAudioLevelExtension(level uint8, voice bool) AudioLevelExtension {
+	if level > 127 {
+		level = 127
+	}
+	return AudioLevelExtension{level: level, voice: voice}
+}
+
+// ParseAudioLevelExtension decodes an audio level extension value.
+func ParseAudioLevelExtension(value []byte) (AudioLevelExtension, error) {
+	if len(value) < 1 {
+		return AudioLevelExtension{}, fmt.Errorf("audio level extension too short: %d bytes", len(value))
+	}
+	return AudioLevelExtension{level: value[0] & 0x7F, voice: value[0]&0x80 != 0}, nil
+}
+
+// Level returns the audio level in -dBov.
+func (e AudioLevelExtension) Level() uint8 { return e.level }
+
+// Voice reports whether the packet contains voice activity.
+func (e AudioLevelExtension) Voice() bool { return e.voice }
+
+// Marshal encodes the extension value.
+func (e AudioLevelExtension) Marshal() []byte {
+	b := e.level & 0x7F
+	if e.voice {
+		b |= 0x80
+	}
+	return []byte{b}
+}
+
+// AbsoluteSendTimeExtension is the abs-send-time extension: the send time
+// as a 24-bit 6.18 fixed-point number of seconds, wrapping every 64 seconds.
+type AbsoluteSendTimeExtension struct {
+	timestamp uint32
+}
+
+// NewAbsoluteSendTimeExtension creates an abs-send-time extension for t.
+func NewAbsoluteSendTimeExtension(t time.Time) AbsoluteSendTimeExtension {
+	ns := uint64(t.UnixNano())
+	seconds := ns / uint64(time.Second)
+	fraction := (ns % uint64(time.Second)) << 18 / uint64(time.Second)
+	return AbsoluteSendTimeExtension{timestamp: uint32((seconds&0x3F)<<18|fraction) & 0xFFFFFF}
+}
+
+// ParseAbsoluteSendTimeExtension decodes an abs-send-time extension value.
+func ParseAbsoluteSendTimeExtension(value []byte) (AbsoluteSendTimeExtension, error) {
+	if len(value) < 3 {
+		return AbsoluteSendTimeExtension{}, fmt.Errorf("abs-send-time extension too short: %d bytes", len(value))
+	}
+	return AbsoluteSendTimeExtension{timestamp: uint32(value[0])<<16 | uint32(value[1])<<8 | uint32(value[2])}, nil
+}
+
+// Timestamp returns the raw 24-bit 6.18 fixed-point value.
+func (e AbsoluteSendTimeExtension) Timestamp() uint32 { return e.timestamp }
+
+// Offset returns the send time within its 64-second wrap period.
+func (e AbsoluteSendTimeExtension) Offset() time.Duration {
+	return time.Duration(uint64(e.timestamp) * uint64(time.Second) >> 18)
+}
+
+// Estimate returns the absolute send time closest to receive, resolving the
+// 64-second wrap relative to the time the packet arrived.
+func (e AbsoluteSendTimeExtension) Estimate(receive time.Time) time.Time {
+	const period = 64 * time.Second
+	ref := NewAbsoluteSendTimeExtension(receive)
+	diff := e.Offset() - ref.Offset()
+	if diff > period/2 {
+		diff -= period
+	} else if diff < -period/2 {
+		diff += period
+	}
+	return receive.Add(diff)
+}
+
+// Marshal encodes the extension value.
+func (e AbsoluteSendTimeExtension) Marshal() []byte {
+	var b [4]byte
+	binary.BigEndian.PutUint32(b[:], e.timestamp)
+	return b[1:]
+}
@@ -0,0 +1,119 @@
+package rtp
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/pion/rtp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAudioPacketizer_SetExtension(t *testing.T) {
+	mockTransport := NewMockTransport()
+	remoteAddr := &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 33445}
+	packetizer, err := NewAudioPacketizer(48000, mockTransport, remoteAddr)
+	require.NoError(t, err)
+
+	assert.Error(t, packetizer.SetExtension(0, []byte{1}))
+	assert.Error(t, packetizer.SetExtension(1, make([]byte, 256)))
+
+	require.NoError(t, packetizer.SetExtension(3, []byte{0x01}))
+	require.NoError(t, packetizer.SetExtension(3, []byte{0x02}))
+	require.NoError(t, packetizer.SetExtension(200, make([]byte, 40)))
+	require.NoError(t, packetizer.PacketizeAndSend([]byte("frame"), 960))
+	require.NoError(t, packetizer.PacketizeAndSend([]byte("frame"), 960))
+
+	sent := mockTransport.GetSentPackets()
+	require.Len(t, sent, 2)
+
+	first := &rtp.Packet{}
+	require.NoError(t, first.Unmarshal(sent[0].Packet.Data))
+	assert.Equal(t, uint16(rtp.ExtensionProfileTwoByte), first.ExtensionProfile)
+	assert.Equal(t, []byte{0x02}, first.GetExtension(3))
+	assert.Len(t, first.GetExtension(200), 40)
+	assert.Equal(t, []byte("frame"), first.Payload)
+
+	second := &rtp.Packet{}
+	require.NoError(t, second.Unmarshal(sent[1].Packet.Data))
+	assert.False(t, second.Extension, "extensions apply to the next packet only")
+}
+
+func TestSession_HeaderExtensions(t *testing.T) {
+	mockTransport := NewMockTransport()
+	remoteAddr := &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 33445}
+	sender, err := NewSession(1, mockTransport, remoteAddr)
+	require.NoError(t, err)
+	receiver, err := NewSession(2, NewMockTransport(), remoteAddr)
+	require.NoError(t, err)
+
+	assert.Error(t, sender.RegisterExtension(0, AudioLevelURI))
+	assert.Error(t, sender.RegisterExtension(1, ""))
+	assert.Error(t, sender.SetAudioExtension(1, []byte{0}), "unregistered ID")
+
+	sendTime := time.Unix(1700000000, 250_000_000)
+	for _, s := range []*Session{sender, receiver} {
+		require.NoError(t, s.RegisterExtension(1, AudioLevelURI))
+		require.NoError(t, s.RegisterExtension(2, AbsoluteSendTimeURI))
+	}
+	require.NoError(t, sender.SetAudioExtension(1, NewAudioLevelExtension(30, true).Marshal()))
+	require.NoError(t, sender.SetAudioExtension(2, NewAbsoluteSendTimeExtension(sendTime).Marshal()))
+	require.NoError(t, sender.audioPacketizer.SetExtension(9, []byte("unregistered")))
+	require.NoError(t, sender.SendAudioPacket([]byte("opus"), 960))
+
+	var gotAudio []byte
+	var gotExts []HeaderExtension
+	receiver.SetExtensionHandler(func(audio []byte, extensions []HeaderExtension) {
+		gotAudio = audio
+		gotExts = extensions
+	})
+
+	audio, mediaType, err := receiver.ReceivePacket(mockTransport.GetSentPackets()[0].Packet.Data)
+	require.NoError(t, err)
+	assert.Equal(t, "audio", mediaType)
+	assert.Equal(t, []byte("opus"), audio)
+	assert.Equal(t, audio, gotAudio)
+	require.Len(t, gotExts, 2, "only registered extensions are delivered")
+
+	byURI := map[string][]byte{}
+	for _, ext := range gotExts {
+		byURI[ext.URI] = ext.Value
+	}
+
+	level, err := ParseAudioLevelExtension(byURI[AudioLevelURI])
+	require.NoError(t, err)
+	assert.Equal(t, uint8(30), level.Level())
+	assert.True(t, level.Voice())
+
+	ast, err := ParseAbsoluteSendTimeExtension(byURI[AbsoluteSendTimeURI])
+	require.NoError(t, err)
+	estimate := ast.Estimate(sendTime.Add(80 * time.Millisecond))
+	assert.WithinDuration(t, sendTime, estimate, time.Millisecond)
+}
+
+func TestAudioLevelExtension(t *testing.T) {
+	ext := NewAudioLevelExtension(200, false)
+	assert.Equal(t, uint8(127), ext.Level())
+	assert.Equal(t, []byte{0x7F}, ext.Marshal())
+
+	_, err := ParseAudioLevelExtension(nil)
+	assert.Error(t, err)
+}
+
+func TestAbsoluteSendTimeExtension(t *testing.T) {
+	t0 := time.Unix(63, 500_000_000)
+	ext := NewAbsoluteSendTimeExtension(t0)
+	assert.Equal(t, uint32(63<<18|1<<17), ext.Timestamp())
+	assert.Equal(t, 63500*time.Millisecond, ext.Offset())
+
+	// Received just after the 64-second wrap
+	assert.WithinDuration(t, t0, ext.Estimate(time.Unix(64, 100_000_000)), 5*time.Microsecond)
+
+	parsed, err := ParseAbsoluteSendTimeExtension(ext.Marshal())
+	require.NoError(t, err)
+	assert.Equal(t, ext, parsed)
+
+	_, err = ParseAbsoluteSendTimeExtension([]byte{1, 2})
+	assert.Error(t, err)
+}
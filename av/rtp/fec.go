@@ -0,0 +1,156 @@
+package rtp
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/opd-ai/toxcore/av/video"
+	"github.com/opd-ai/toxcore/transport"
+	"github.com/pion/rtp"
+	"github.com/sirupsen/logrus"
+)
+
+// fecReceiveWindow is how many recent video packets the receiver keeps for
+// FEC recovery.
+const fecReceiveWindow = 2 * video.MaxFECGroupSize
+
+// EnableVideoFEC turns on XOR forward error correction (RFC 5109) for video:
+// after every groupSize video packets a parity packet covering the group is
+// sent. A groupSize of 0 uses video.FECGroupSize.
+//
+// Receivers need no configuration; parity packets are recognised by
+// video.FECPayloadType and used automatically by ReceiveVideoPacket.
+func (s *Session) EnableVideoFEC(groupSize int) error {
+	if groupSize == 0 {
+		groupSize = video.FECGroupSize
+	}
+	if groupSize < 2 || groupSize > video.MaxFECGroupSize {
+		return fmt.Errorf("FEC group size must be between 2 and %d, got %d", video.MaxFECGroupSize, groupSize)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.fecEncoder == nil {
+		s.fecEncoder = video.NewFECEncoder()
+	}
+	s.fecGroupSize = groupSize
+	s.fecPending = nil
+
+	pkgLog.WithFields(logrus.Fields{
+		"function":      "Session.EnableVideoFEC",
+		"friend_number": s.friendNumber,
+		"group_size":    groupSize,
+	}).Info("Video FEC enabled")
+
+	return nil
+}
+
+// DisableVideoFEC stops sending video parity packets.
+func (s *Session) DisableVideoFEC() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fecGroupSize = 0
+	s.fecPending = nil
+}
+
+// protectVideoPacket adds a sent video packet to the current FEC group and
+// sends the group's parity packet once it is full.
+func (s *Session) protectVideoPacket(packetData []byte) error {
+	if s.fecGroupSize == 0 {
+		return nil
+	}
+
+	packet := &rtp.Packet{}
+	if err := packet.Unmarshal(append([]byte(nil), packetData...)); err != nil {
+		return fmt.Errorf("failed to parse video packet for FEC: %w", err)
+	}
+	s.fecPending = append(s.fecPending, packet)
+	if len(s.fecPending) < s.fecGroupSize {
+		return nil
+	}
+
+	parity := s.fecEncoder.GenerateParity(s.fecPending)
+	s.fecPending = nil
+	if parity == nil {
+		return nil
+	}
+	parityData, err := parity.Marshal()
+	if err != nil {
+		return fmt.Errorf("failed to marshal FEC packet: %w", err)
+	}
+
+	toxPacket := &transport.Packet{
+		PacketType: transport.PacketAVVideoFrame,
+		Data:       parityData,
+	}
+	if err := s.transport.Send(toxPacket, s.remoteAddr); err != nil {
+		return fmt.Errorf("failed to send FEC packet: %w", err)
+	}
+	s.stats.PacketsSent++
+	return nil
+}
+
+// isFECPacket reports whether raw RTP data carries a video parity packet.
+func isFECPacket(data []byte) bool {
+	return len(data) >= 2 && data[1]&0x7F == video.FECPayloadType
+}
+
+// rememberVideoPacket keeps a received video packet for FEC recovery,
+// evicting packets that have fallen out of the receive window.
+func (s *Session) rememberVideoPacket(data []byte) {
+	packet := &rtp.Packet{}
+	if err := packet.Unmarshal(append([]byte(nil), data...)); err != nil {
+		return
+	}
+	if s.fecRecent == nil {
+		s.fecRecent = make(map[uint16]*rtp.Packet)
+	}
+	s.fecRecent[packet.SequenceNumber] = packet
+
+	if len(s.fecRecent) > fecReceiveWindow {
+		for seq := range s.fecRecent {
+			if int16(packet.SequenceNumber-seq) >= fecReceiveWindow {
+				delete(s.fecRecent, seq)
+			}
+		}
+	}
+}
+
+// recoverFromFEC uses a parity packet to rebuild a lost video packet. It
+// returns the recovered packet in wire format, or nil if nothing needed or
+// could be recovered.
+func (s *Session) recoverFromFEC(data []byte) ([]byte, error) {
+	parity := &rtp.Packet{}
+	if err := parity.Unmarshal(data); err != nil {
+		return nil, fmt.Errorf("failed to parse FEC packet: %w", err)
+	}
+
+	group := make([]*rtp.Packet, 0, len(s.fecRecent)+1)
+	group = append(group, parity)
+	for _, p := range s.fecRecent {
+		group = append(group, p)
+	}
+
+	recovered, err := video.NewFECDecoder().Recover(group)
+	switch {
+	case errors.Is(err, video.ErrFECNothingMissing), errors.Is(err, video.ErrFECUnrecoverable):
+		return nil, nil
+	case err != nil:
+		return nil, err
+	}
+
+	s.fecRecent[recovered.SequenceNumber] = recovered
+	recoveredData, err := recovered.Marshal()
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal recovered packet: %w", err)
+	}
+
+	pkgLog.WithFields(logrus.Fields{
+		"function":      "Session.ReceiveVideoPacket",
+		"friend_number": s.friendNumber,
+		"sequence":      recovered.SequenceNumber,
+	}).Debug("Recovered lost video packet using FEC")
+
+	return recoveredData, nil
+}
@@ -0,0 +1,64 @@
+package rtp
+
+import (
+	"net"
+	"testing"
+
+	"github.com/opd-ai/toxcore/av/video"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSession_VideoFECRecovery(t *testing.T) {
+	mockTransport := NewMockTransport()
+	remoteAddr, _ := net.ResolveUDPAddr("udp", "127.0.0.1:54321")
+
+	sender, err := NewSession(1, mockTransport, remoteAddr)
+	require.NoError(t, err)
+	receiver, err := NewSession(2, NewMockTransport(), remoteAddr)
+	require.NoError(t, err)
+
+	assert.Error(t, sender.EnableVideoFEC(1))
+	assert.Error(t, sender.EnableVideoFEC(video.MaxFECGroupSize+1))
+	require.NoError(t, sender.EnableVideoFEC(0))
+
+	// Large enough to span exactly one FEC group of video packets
+	frame := make([]byte, 4000)
+	for i := range frame {
+		frame[i] = byte(i * 7)
+	}
+	require.NoError(t, sender.SendVideoPacket(frame))
+
+	sent := mockTransport.GetSentPackets()
+	require.Len(t, sent, video.FECGroupSize+1, "media packets followed by one parity packet")
+	require.True(t, isFECPacket(sent[len(sent)-1].Packet.Data))
+
+	// Drop the second media packet
+	var got []byte
+	for i, sp := range sent {
+		if i == 1 {
+			continue
+		}
+		data, _, err := receiver.ReceiveVideoPacket(sp.Packet.Data)
+		require.NoError(t, err)
+		if data != nil {
+			got = data
+		}
+	}
+	assert.Equal(t, frame, got, "frame should be reassembled using the recovered packet")
+}
+
+func TestSession_VideoFECDisabled(t *testing.T) {
+	mockTransport := NewMockTransport()
+	remoteAddr, _ := net.ResolveUDPAddr("udp", "127.0.0.1:54321")
+
+	session, err := NewSession(1, mockTransport, remoteAddr)
+	require.NoError(t, err)
+	require.NoError(t, session.EnableVideoFEC(2))
+	session.DisableVideoFEC()
+
+	require.NoError(t, session.SendVideoPacket(make([]byte, 4000)))
+	for _, sp := range mockTransport.GetSentPackets() {
+		assert.False(t, isFECPacket(sp.Packet.Data))
+	}
+}
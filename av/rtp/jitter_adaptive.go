@@ -0,0 +1,177 @@
+package rtp
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultMinDelay is the default lower bound of the adaptive playout delay.
+	DefaultMinDelay = 20 * time.Millisecond
+	// DefaultMaxDelay is the default upper bound of the adaptive playout delay.
+	DefaultMaxDelay = 300 * time.Millisecond
+	// DefaultAdaptationFactor is the default multiple of the measured jitter
+	// used as the target playout delay.
+	DefaultAdaptationFactor = 2.5
+	// DefaultDelaySmoothing is the default exponential smoothing factor
+	// applied when moving the playout delay towards its target.
+	DefaultDelaySmoothing = 0.1
+)
+
+// AdaptiveDelayConfig configures adaptive playout delay. Zero fields take
+// their defaults.
+type AdaptiveDelayConfig struct {
+	// MinDelay is the lowest playout delay (default DefaultMinDelay).
+	MinDelay time.Duration
+	// MaxDelay is the highest playout delay (default DefaultMaxDelay).
+	MaxDelay time.Duration
+	// AdaptationFactor multiplies the measured jitter to give the target
+	// delay (default DefaultAdaptationFactor).
+	AdaptationFactor float64
+	// Alpha is the smoothing factor in (0, 1]; smaller values adapt more
+	// slowly and resist oscillation (default DefaultDelaySmoothing).
+	Alpha float64
+}
+
+// withDefaults fills zero fields with their defaults.
+func (c AdaptiveDelayConfig) withDefaults() AdaptiveDelayConfig {
+	if c.MinDelay == 0 {
+		c.MinDelay = DefaultMinDelay
+	}
+	if c.MaxDelay == 0 {
+		c.MaxDelay = DefaultMaxDelay
+	}
+	if c.AdaptationFactor == 0 {
+		c.AdaptationFactor = DefaultAdaptationFactor
+	}
+	if c.Alpha == 0 {
+		c.Alpha = DefaultDelaySmoothing
+	}
+	return c
+}
+
+// validate checks a configuration after defaults have been applied.
+func (c AdaptiveDelayConfig) validate() error {
+	if c.MinDelay < 0 {
+		return fmt.Errorf("min delay cannot be negative: %v", c.MinDelay)
+	}
+	if c.MaxDelay < c.MinDelay {
+		return fmt.Errorf("max delay %v is below min delay %v", c.MaxDelay, c.MinDelay)
+	}
+	if math.IsNaN(c.AdaptationFactor) || c.AdaptationFactor <= 0 {
+		return fmt.Errorf("adaptation factor must be positive: %f", c.AdaptationFactor)
+	}
+	if math.IsNaN(c.Alpha) || c.Alpha <= 0 || c.Alpha > 1 {
+		return fmt.Errorf("alpha must be in (0, 1]: %f", c.Alpha)
+	}
+	return nil
+}
+
+// EnableAdaptiveDelay switches the buffer from the fixed buffer time to an
+// adaptive playout delay. Packets are scheduled by timestamp: each is
+// played the current delay after the time it would have arrived with the
+// fastest transit seen, so latency stays constant while packets delayed by
+// less than the current delay are absorbed. The delay follows the measured
+// jitter times AdaptationFactor within [MinDelay, MaxDelay] and starts from
+// the buffer time, clamped to that range.
+func (jb *JitterBuffer) EnableAdaptiveDelay(cfg AdaptiveDelayConfig) error {
+	cfg = cfg.withDefaults()
+	if err := cfg.validate(); err != nil {
+		pkgLog.WithFields(logrus.Fields{
+			"function": "JitterBuffer.EnableAdaptiveDelay",
+			"error":    err.Error(),
+		}).Error("Invalid adaptive delay configuration")
+		return err
+	}
+
+	jb.mu.Lock()
+	defer jb.mu.Unlock()
+
+	jb.adaptive = true
+	jb.adaptiveConfig = cfg
+	jb.currentDelay = clampDelay(jb.bufferTime, cfg)
+
+	pkgLog.WithFields(logrus.Fields{
+		"function":          "JitterBuffer.EnableAdaptiveDelay",
+		"min_delay":         cfg.MinDelay.String(),
+		"max_delay":         cfg.MaxDelay.String(),
+		"adaptation_factor": cfg.AdaptationFactor,
+		"alpha":             cfg.Alpha,
+	}).Info("Adaptive playout delay enabled")
+	return nil
+}
+
+// DisableAdaptiveDelay returns the buffer to its fixed buffer time.
+func (jb *JitterBuffer) DisableAdaptiveDelay() {
+	jb.mu.Lock()
+	defer jb.mu.Unlock()
+	jb.adaptive = false
+}
+
+// GetCurrentDelay returns the live playout delay: the smoothed adaptive
+// target when adaptive delay is enabled, otherwise the fixed buffer time.
+func (jb *JitterBuffer) GetCurrentDelay() time.Duration {
+	jb.mu.RLock()
+	defer jb.mu.RUnlock()
+	if jb.adaptive {
+		return jb.currentDelay
+	}
+	return jb.bufferTime
+}
+
+// GetJitter returns the interarrival jitter estimate of RFC 3550 section
+// 6.4.1. It is tracked whether or not adaptive delay is enabled.
+func (jb *JitterBuffer) GetJitter() time.Duration {
+	jb.mu.RLock()
+	defer jb.mu.RUnlock()
+	return time.Duration(jb.jitter)
+}
+
+// updateJitterLocked folds the transit time difference between entry and
+// the previous arrival into the jitter estimate, J += (|D| - J) / 16, and
+// moves the adaptive delay towards its target. The caller must hold jb.mu.
+func (jb *JitterBuffer) updateJitterLocked(entry jitterBufferEntry) {
+	if jb.hasArrival {
+		d := entry.relativeTransit(jitterBufferEntry{timestamp: jb.lastTimestamp, arrival: jb.lastArrival})
+		jb.jitter += (math.Abs(float64(d)) - jb.jitter) / 16
+	}
+	jb.lastArrival = entry.arrival
+	jb.lastTimestamp = entry.timestamp
+	jb.hasArrival = true
+
+	// The fastest transit seen anchors the playout schedule
+	if !jb.hasPlayoutRef {
+		jb.playoutRef = jitterBufferEntry{timestamp: entry.timestamp, arrival: entry.arrival}
+		jb.hasPlayoutRef = true
+	} else if transit := entry.relativeTransit(jb.playoutRef); transit < jb.minTransit {
+		jb.minTransit = transit
+	}
+
+	if !jb.adaptive {
+		return
+	}
+	target := clampDelay(time.Duration(jb.jitter*jb.adaptiveConfig.AdaptationFactor), jb.adaptiveConfig)
+	jb.currentDelay += time.Duration(jb.adaptiveConfig.Alpha * float64(target-jb.currentDelay))
+}
+
+// playoutTimeLocked returns when the packet should be played: the arrival
+// time it would have had with the fastest transit seen, plus the current
+// delay. The caller must hold jb.mu.
+func (jb *JitterBuffer) playoutTimeLocked(entry jitterBufferEntry) time.Time {
+	mediaOffset := time.Duration(int32(entry.timestamp-jb.playoutRef.timestamp)) * time.Second / jitterClockRate
+	return jb.playoutRef.arrival.Add(mediaOffset + jb.minTransit + jb.currentDelay)
+}
+
+// clampDelay limits d to the configured delay range.
+func clampDelay(d time.Duration, cfg AdaptiveDelayConfig) time.Duration {
+	if d < cfg.MinDelay {
+		return cfg.MinDelay
+	}
+	if d > cfg.MaxDelay {
+		return cfg.MaxDelay
+	}
+	return d
+}
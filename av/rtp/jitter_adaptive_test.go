@@ -0,0 +1,239 @@
+package rtp
+
+import (
+	"encoding/binary"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// packetInterval is the media duration of each packet in the traces below.
+const packetInterval = 20 * time.Millisecond
+
+// arrivalTrace returns the network delay of n packets: a steady 10ms plus
+// uniform random jitter of up to spread, with bursts of congestion adding up
+// to burst more in 10 of every 50 packets.
+func arrivalTrace(n int, spread, burst time.Duration) []time.Duration {
+	rng := rand.New(rand.NewSource(1))
+	delays := make([]time.Duration, n)
+	for i := range delays {
+		delays[i] = 10*time.Millisecond + time.Duration(rng.Int63n(int64(spread)+1))
+		if burst > 0 && i%50 >= 40 {
+			delays[i] += time.Duration(rng.Int63n(int64(burst) + 1))
+		}
+	}
+	return delays
+}
+
+// fixedDelay is the playout delay the adaptive buffer is compared against,
+// the buffer time AudioDepacketizer uses.
+const fixedDelay = 50 * time.Millisecond
+
+// newTraceBuffer returns a buffer with adaptive delay, or pinned to
+// fixedDelay when adaptive is false.
+func newTraceBuffer(tb testing.TB, adaptive bool) (*JitterBuffer, *MockTimeProvider) {
+	tb.Helper()
+	jb, clock := newConfigTestBuffer(DefaultMaxBufferCapacity)
+	cfg := AdaptiveDelayConfig{MinDelay: fixedDelay, MaxDelay: fixedDelay}
+	if adaptive {
+		cfg = AdaptiveDelayConfig{}
+	}
+	if err := jb.EnableAdaptiveDelay(cfg); err != nil {
+		tb.Fatal(err)
+	}
+	return jb, clock
+}
+
+// playTrace sends packets every packetInterval with the given network
+// delays and plays one packet per interval once playback has started. It
+// returns the number of playout ticks that found no packet and the mean
+// end-to-end latency of the played packets.
+func playTrace(jb *JitterBuffer, clock *MockTimeProvider, delays []time.Duration) (underflows int, latency time.Duration) {
+	start := clock.Now()
+	arrivals := make(map[time.Duration][]int)
+	for i, d := range delays {
+		at := time.Duration(i)*packetInterval + d
+		arrivals[at.Truncate(time.Millisecond)] = append(arrivals[at.Truncate(time.Millisecond)], i)
+	}
+
+	var played int
+	var total time.Duration
+	end := time.Duration(len(delays))*packetInterval + DefaultMaxDelay
+	for now := time.Duration(0); now < end; now += time.Millisecond {
+		clock.currentTime = start.Add(now)
+		for _, i := range arrivals[now] {
+			payload := make([]byte, 4)
+			binary.BigEndian.PutUint32(payload, uint32(i))
+			jb.Add(uint32(i)*960, payload)
+		}
+		if now%packetInterval != 0 {
+			continue
+		}
+		data, ok := jb.Get()
+		if !ok {
+			if played > 0 {
+				underflows++
+			}
+			continue
+		}
+		played++
+		total += now - time.Duration(binary.BigEndian.Uint32(data))*packetInterval
+	}
+	if played == 0 {
+		return underflows, 0
+	}
+	return underflows, total / time.Duration(played)
+}
+
+func TestJitterBuffer_AdaptiveDelayValidation(t *testing.T) {
+	jb, _ := newConfigTestBuffer(10)
+	tests := []struct {
+		name string
+		cfg  AdaptiveDelayConfig
+	}{
+		{"negative min", AdaptiveDelayConfig{MinDelay: -time.Millisecond}},
+		{"max below min", AdaptiveDelayConfig{MinDelay: 100 * time.Millisecond, MaxDelay: 50 * time.Millisecond}},
+		{"negative factor", AdaptiveDelayConfig{AdaptationFactor: -1}},
+		{"alpha above one", AdaptiveDelayConfig{Alpha: 1.5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Error(t, jb.EnableAdaptiveDelay(tt.cfg))
+			assert.Equal(t, 50*time.Millisecond, jb.GetCurrentDelay(), "fixed delay must remain after an error")
+		})
+	}
+
+	require.NoError(t, jb.EnableAdaptiveDelay(AdaptiveDelayConfig{}))
+	assert.Equal(t, 50*time.Millisecond, jb.GetCurrentDelay(), "delay starts from the buffer time")
+}
+
+func TestJitterBuffer_JitterEstimate(t *testing.T) {
+	jb, clock := newConfigTestBuffer(DefaultMaxBufferCapacity)
+
+	// Steady arrivals have no jitter.
+	for i := uint32(0); i < 20; i++ {
+		jb.Add(i*960, []byte{1})
+		clock.Advance(packetInterval)
+	}
+	assert.Zero(t, jb.GetJitter())
+
+	// Delays alternating between 0 and 10ms give |D| = 10ms, so the
+	// estimate converges towards 10ms.
+	jb.Reset()
+	for i := uint32(0); i < 200; i++ {
+		offset := time.Duration(i%2) * 10 * time.Millisecond
+		clock.currentTime = clock.currentTime.Add(packetInterval + offset)
+		jb.Add(i*960, []byte{1})
+		clock.currentTime = clock.currentTime.Add(-offset)
+	}
+	assert.InDelta(t, float64(10*time.Millisecond), float64(jb.GetJitter()), float64(time.Millisecond))
+}
+
+func TestJitterBuffer_AdaptiveDelayFollowsJitter(t *testing.T) {
+	jb, clock := newConfigTestBuffer(DefaultMaxBufferCapacity)
+	require.NoError(t, jb.EnableAdaptiveDelay(AdaptiveDelayConfig{}))
+
+	// Steady network: the delay shrinks to MinDelay.
+	for i := uint32(0); i < 100; i++ {
+		jb.Add(i*960, []byte{1})
+		clock.Advance(packetInterval)
+		jb.Get()
+	}
+	assert.InDelta(t, float64(DefaultMinDelay), float64(jb.GetCurrentDelay()), float64(time.Millisecond))
+
+	// 40ms of jitter targets 2.5 x 40ms = 100ms.
+	for i := uint32(100); i < 400; i++ {
+		offset := time.Duration(i%2) * 40 * time.Millisecond
+		clock.currentTime = clock.currentTime.Add(packetInterval + offset)
+		jb.Add(i*960, []byte{1})
+		clock.currentTime = clock.currentTime.Add(-offset)
+		jb.Get()
+	}
+	assert.InDelta(t, float64(100*time.Millisecond), float64(jb.GetCurrentDelay()), float64(5*time.Millisecond))
+
+	// Extreme jitter is capped at MaxDelay.
+	for i := uint32(400); i < 700; i++ {
+		offset := time.Duration(i%2) * 500 * time.Millisecond
+		clock.currentTime = clock.currentTime.Add(packetInterval + offset)
+		jb.Add(i*960, []byte{1})
+		clock.currentTime = clock.currentTime.Add(-offset)
+		jb.Get()
+	}
+	assert.LessOrEqual(t, jb.GetCurrentDelay(), DefaultMaxDelay)
+	assert.Greater(t, jb.GetCurrentDelay(), 250*time.Millisecond)
+}
+
+func TestJitterBuffer_AdaptiveRelease(t *testing.T) {
+	jb, clock := newConfigTestBuffer(10)
+	require.NoError(t, jb.EnableAdaptiveDelay(AdaptiveDelayConfig{MinDelay: 30 * time.Millisecond, MaxDelay: 30 * time.Millisecond}))
+	assert.Equal(t, 30*time.Millisecond, jb.GetCurrentDelay())
+
+	jb.Add(0, []byte{1})
+	clock.Advance(packetInterval)
+	jb.Add(960, []byte{2})
+	clock.Advance(9 * time.Millisecond)
+	_, ok := jb.Get()
+	assert.False(t, ok, "packet must be held for the playout delay")
+
+	clock.Advance(time.Millisecond)
+	data, ok := jb.Get()
+	require.True(t, ok)
+	assert.Equal(t, []byte{1}, data)
+	_, ok = jb.Get()
+	assert.False(t, ok, "second packet plays one packet interval later")
+
+	// A late packet whose successor is already due is skipped.
+	jb.Add(2880, []byte{4})
+	clock.Advance(3 * packetInterval)
+	jb.Add(1920, []byte{3})
+	clock.Advance(packetInterval)
+	data, ok = jb.Get()
+	require.True(t, ok)
+	assert.Equal(t, []byte{4}, data)
+	assert.Equal(t, uint64(2), jb.GetBufferStats().Discarded)
+
+	jb.DisableAdaptiveDelay()
+	assert.Equal(t, 50*time.Millisecond, jb.GetCurrentDelay())
+}
+
+func TestJitterBuffer_AdaptiveVersusFixed(t *testing.T) {
+	// On a calm network the adaptive buffer needs less latency.
+	calm := arrivalTrace(500, 2*time.Millisecond, 0)
+	fixed, fixedClock := newTraceBuffer(t, false)
+	_, fixedLatency := playTrace(fixed, fixedClock, calm)
+	adaptive, adaptiveClock := newTraceBuffer(t, true)
+	_, adaptiveLatency := playTrace(adaptive, adaptiveClock, calm)
+	assert.Less(t, adaptiveLatency, fixedLatency)
+
+	// On a congested network it grows to avoid underflows.
+	congested := arrivalTrace(500, 100*time.Millisecond, 150*time.Millisecond)
+	fixed, fixedClock = newTraceBuffer(t, false)
+	fixedUnderflows, _ := playTrace(fixed, fixedClock, congested)
+	adaptive, adaptiveClock = newTraceBuffer(t, true)
+	adaptiveUnderflows, _ := playTrace(adaptive, adaptiveClock, congested)
+	assert.Less(t, adaptiveUnderflows, fixedUnderflows)
+}
+
+// BenchmarkJitterBuffer_BurstyTrace compares a fixed playout delay with
+// adaptive delay on the same bursty arrival trace, reporting playout
+// underflows and mean latency per run.
+func BenchmarkJitterBuffer_BurstyTrace(b *testing.B) {
+	delays := append(arrivalTrace(500, 2*time.Millisecond, 0), arrivalTrace(500, 100*time.Millisecond, 150*time.Millisecond)...)
+	run := func(b *testing.B, adaptive bool) {
+		var underflows int
+		var latency time.Duration
+		for i := 0; i < b.N; i++ {
+			jb, clock := newTraceBuffer(b, adaptive)
+			u, l := playTrace(jb, clock, delays)
+			underflows += u
+			latency += l
+		}
+		b.ReportMetric(float64(underflows)/float64(b.N), "underflows/op")
+		b.ReportMetric(float64(latency.Milliseconds())/float64(b.N), "latency-ms/op")
+	}
+	b.Run("fixed", func(b *testing.B) { run(b, false) })
+	b.Run("adaptive", func(b *testing.B) { run(b, true) })
+}
@@ -0,0 +1,202 @@
+package rtp
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// jitterClockRate is the RTP clock rate of buffered audio. Opus always uses
+// a 48 kHz RTP clock regardless of the encoded sample rate (RFC 7587).
+const jitterClockRate = 48000
+
+// PrunePolicy selects which packet a full JitterBuffer discards.
+type PrunePolicy int
+
+const (
+	// PruneOldest discards the packet with the lowest timestamp.
+	PruneOldest PrunePolicy = iota
+	// PruneHighestJitter discards the packet that arrived latest relative to
+	// its timestamp, keeping the packets that arrived on schedule. The
+	// incoming packet itself is discarded if it is the most delayed one.
+	PruneHighestJitter
+)
+
+// String returns the policy name for logging.
+func (p PrunePolicy) String() string {
+	switch p {
+	case PruneOldest:
+		return "oldest"
+	case PruneHighestJitter:
+		return "highest_jitter"
+	default:
+		return fmt.Sprintf("PrunePolicy(%d)", int(p))
+	}
+}
+
+// JitterBufferConfig configures the capacity limiting and pruning of a
+// JitterBuffer.
+type JitterBufferConfig struct {
+	// MaxCapacity is the maximum number of buffered packets (0 uses
+	// DefaultMaxBufferCapacity).
+	MaxCapacity int
+	// PrunePolicy selects the packet discarded when the buffer is full.
+	PrunePolicy PrunePolicy
+	// PruneAge discards packets that have waited longer than this without
+	// being played (0 disables age-based pruning).
+	PruneAge time.Duration
+}
+
+// BufferStats reports jitter buffer state for tuning its configuration.
+type BufferStats struct {
+	// CurrentDepth is the number of buffered packets.
+	CurrentDepth int
+	// Discarded counts packets dropped by capacity limits or PruneAge.
+	Discarded uint64
+	// LatePackets counts packets that arrived after a packet with a later
+	// timestamp had already been played.
+	LatePackets uint64
+	// CurrentDelay is how long the oldest buffered packet has been waiting.
+	CurrentDelay time.Duration
+}
+
+// validate checks the configuration for invalid values.
+func (c JitterBufferConfig) validate() error {
+	if c.MaxCapacity < 0 {
+		return fmt.Errorf("max capacity cannot be negative: %d", c.MaxCapacity)
+	}
+	if c.PrunePolicy != PruneOldest && c.PrunePolicy != PruneHighestJitter {
+		return fmt.Errorf("unknown prune policy: %v", c.PrunePolicy)
+	}
+	if c.PruneAge < 0 {
+		return fmt.Errorf("prune age cannot be negative: %v", c.PruneAge)
+	}
+	return nil
+}
+
+// SetConfig applies capacity, prune policy and prune age in one step.
+// Packets exceeding the new limits are discarded immediately according to
+// the new policy. On error the current configuration is left unchanged.
+func (jb *JitterBuffer) SetConfig(cfg JitterBufferConfig) error {
+	if err := cfg.validate(); err != nil {
+		pkgLog.WithFields(logrus.Fields{
+			"function": "JitterBuffer.SetConfig",
+			"error":    err.Error(),
+		}).Error("Invalid jitter buffer configuration")
+		return err
+	}
+	if cfg.MaxCapacity == 0 {
+		cfg.MaxCapacity = DefaultMaxBufferCapacity
+	}
+
+	jb.mu.Lock()
+	defer jb.mu.Unlock()
+
+	jb.maxCapacity = cfg.MaxCapacity
+	jb.prunePolicy = cfg.PrunePolicy
+	jb.pruneAge = cfg.PruneAge
+	jb.pruneExpiredLocked()
+	for len(jb.packets) > jb.maxCapacity {
+		jb.discardLocked(jb.pruneIndexLocked())
+	}
+
+	pkgLog.WithFields(logrus.Fields{
+		"function":     "JitterBuffer.SetConfig",
+		"max_capacity": cfg.MaxCapacity,
+		"prune_policy": cfg.PrunePolicy.String(),
+		"prune_age":    cfg.PruneAge.String(),
+		"buffer_size":  len(jb.packets),
+	}).Info("Jitter buffer configuration updated")
+	return nil
+}
+
+// GetConfig returns the current capacity and pruning configuration.
+func (jb *JitterBuffer) GetConfig() JitterBufferConfig {
+	jb.mu.RLock()
+	defer jb.mu.RUnlock()
+	return JitterBufferConfig{
+		MaxCapacity: jb.maxCapacity,
+		PrunePolicy: jb.prunePolicy,
+		PruneAge:    jb.pruneAge,
+	}
+}
+
+// GetBufferStats returns the buffer depth, discard counters and current
+// delay.
+func (jb *JitterBuffer) GetBufferStats() BufferStats {
+	jb.mu.RLock()
+	defer jb.mu.RUnlock()
+
+	stats := BufferStats{
+		CurrentDepth: len(jb.packets),
+		Discarded:    jb.discarded,
+		LatePackets:  jb.latePackets,
+	}
+	if len(jb.packets) > 0 {
+		oldest := jb.packets[0].arrival
+		for _, entry := range jb.packets[1:] {
+			if entry.arrival.Before(oldest) {
+				oldest = entry.arrival
+			}
+		}
+		stats.CurrentDelay = jb.timeProvider.Now().Sub(oldest)
+	}
+	return stats
+}
+
+// pruneIndexLocked returns the index of the packet to discard under the
+// current policy. The buffer must not be empty and the caller must hold
+// jb.mu.
+func (jb *JitterBuffer) pruneIndexLocked() int {
+	if jb.prunePolicy != PruneHighestJitter {
+		return 0
+	}
+	worst := 0
+	for i := 1; i < len(jb.packets); i++ {
+		if jb.packets[i].relativeTransit(jb.packets[0]) > jb.packets[worst].relativeTransit(jb.packets[0]) {
+			worst = i
+		}
+	}
+	return worst
+}
+
+// discardLocked removes the packet at index i and counts it as discarded.
+// The caller must hold jb.mu.
+func (jb *JitterBuffer) discardLocked(i int) {
+	evicted := jb.packets[i]
+	jb.packets = append(jb.packets[:i], jb.packets[i+1:]...)
+	jb.discarded++
+
+	pkgLog.WithFields(logrus.Fields{
+		"function":          "JitterBuffer.discard",
+		"evicted_timestamp": evicted.timestamp,
+		"prune_policy":      jb.prunePolicy.String(),
+	}).Debug("Discarded packet from jitter buffer")
+}
+
+// pruneExpiredLocked discards packets that have waited longer than
+// PruneAge. The caller must hold jb.mu.
+func (jb *JitterBuffer) pruneExpiredLocked() {
+	if jb.pruneAge <= 0 || len(jb.packets) == 0 {
+		return
+	}
+	cutoff := jb.timeProvider.Now().Add(-jb.pruneAge)
+	kept := jb.packets[:0]
+	for _, entry := range jb.packets {
+		if entry.arrival.Before(cutoff) {
+			jb.discarded++
+			continue
+		}
+		kept = append(kept, entry)
+	}
+	jb.packets = kept
+}
+
+// relativeTransit returns how much later e arrived than ref, less the
+// media time between their timestamps. Larger values mean e was delayed
+// more by the network than ref.
+func (e jitterBufferEntry) relativeTransit(ref jitterBufferEntry) time.Duration {
+	mediaOffset := time.Duration(int32(e.timestamp-ref.timestamp)) * time.Second / jitterClockRate
+	return e.arrival.Sub(ref.arrival) - mediaOffset
+}
@@ -0,0 +1,115 @@
+package rtp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newConfigTestBuffer(capacity int) (*JitterBuffer, *MockTimeProvider) {
+	mockTime := &MockTimeProvider{
+		currentTime: time.Date(2026, 2, 17, 0, 0, 0, 0, time.UTC),
+	}
+	return NewJitterBufferWithOptions(50*time.Millisecond, capacity, mockTime), mockTime
+}
+
+func TestJitterBuffer_SetConfigValidation(t *testing.T) {
+	jb, _ := newConfigTestBuffer(5)
+	tests := []struct {
+		name string
+		cfg  JitterBufferConfig
+	}{
+		{"negative capacity", JitterBufferConfig{MaxCapacity: -1}},
+		{"unknown policy", JitterBufferConfig{PrunePolicy: PrunePolicy(7)}},
+		{"negative age", JitterBufferConfig{PruneAge: -time.Second}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Error(t, jb.SetConfig(tt.cfg))
+			assert.Equal(t, JitterBufferConfig{MaxCapacity: 5}, jb.GetConfig(), "config must be unchanged on error")
+		})
+	}
+
+	require.NoError(t, jb.SetConfig(JitterBufferConfig{}))
+	assert.Equal(t, DefaultMaxBufferCapacity, jb.GetConfig().MaxCapacity)
+}
+
+func TestJitterBuffer_SetConfigShrinksBuffer(t *testing.T) {
+	jb, _ := newConfigTestBuffer(5)
+	for i := uint32(1); i <= 5; i++ {
+		jb.Add(i*960, []byte{byte(i)})
+	}
+
+	require.NoError(t, jb.SetConfig(JitterBufferConfig{MaxCapacity: 3}))
+	stats := jb.GetBufferStats()
+	assert.Equal(t, 3, stats.CurrentDepth)
+	assert.Equal(t, uint64(2), stats.Discarded)
+}
+
+func TestJitterBuffer_PruneHighestJitter(t *testing.T) {
+	jb, mockTime := newConfigTestBuffer(3)
+	require.NoError(t, jb.SetConfig(JitterBufferConfig{MaxCapacity: 3, PrunePolicy: PruneHighestJitter}))
+
+	// 20ms frames (960 ticks at 48 kHz) arriving on schedule, except the
+	// second one, which is 30ms late
+	jb.Add(0, []byte{0x00})
+	mockTime.Advance(50 * time.Millisecond)
+	jb.Add(960, []byte{0x01})
+	jb.Add(1920, []byte{0x02})
+	mockTime.Advance(10 * time.Millisecond)
+	jb.Add(2880, []byte{0x03})
+
+	assert.Equal(t, uint64(1), jb.GetBufferStats().Discarded)
+	var got []byte
+	for jb.Len() > 0 {
+		mockTime.Advance(60 * time.Millisecond)
+		data, ok := jb.Get()
+		require.True(t, ok)
+		got = append(got, data...)
+	}
+	assert.Equal(t, []byte{0x00, 0x02, 0x03}, got, "the most delayed packet should be discarded")
+}
+
+func TestJitterBuffer_PruneAge(t *testing.T) {
+	jb, mockTime := newConfigTestBuffer(10)
+	require.NoError(t, jb.SetConfig(JitterBufferConfig{MaxCapacity: 10, PruneAge: 100 * time.Millisecond}))
+
+	jb.Add(960, []byte{0x01})
+	mockTime.Advance(80 * time.Millisecond)
+	jb.Add(1920, []byte{0x02})
+	assert.Equal(t, 80*time.Millisecond, jb.GetBufferStats().CurrentDelay)
+
+	mockTime.Advance(40 * time.Millisecond)
+	data, ok := jb.Get()
+	require.True(t, ok)
+	assert.Equal(t, []byte{0x02}, data, "expired packet should be pruned before playout")
+
+	stats := jb.GetBufferStats()
+	assert.Equal(t, uint64(1), stats.Discarded)
+	assert.Equal(t, 0, stats.CurrentDepth)
+	assert.Zero(t, stats.CurrentDelay)
+}
+
+func TestJitterBuffer_LatePackets(t *testing.T) {
+	jb, mockTime := newConfigTestBuffer(10)
+
+	jb.Add(1920, []byte{0x02})
+	mockTime.Advance(60 * time.Millisecond)
+	_, ok := jb.Get()
+	require.True(t, ok)
+
+	jb.Add(960, []byte{0x01})
+	jb.Add(2880, []byte{0x03})
+	assert.Equal(t, uint64(1), jb.GetBufferStats().LatePackets)
+
+	// Wraparound past the playout position is not late
+	jb.Reset()
+	jb.Add(0xFFFFFF00, []byte{0x04})
+	mockTime.Advance(60 * time.Millisecond)
+	_, ok = jb.Get()
+	require.True(t, ok)
+	jb.Add(0x00000100, []byte{0x05})
+	assert.Equal(t, uint64(1), jb.GetBufferStats().LatePackets)
+}
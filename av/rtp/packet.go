@@ -70,6 +70,10 @@ type AudioPacketizer struct {
 	transport      transport.Transport
 	remoteAddr     net.Addr
 	ssrcProvider   SSRCProvider
+	redLevels      int        // RFC 2198 redundancy depth (0 = disabled)
+	redHistory     []redFrame // Most recent frames sent, oldest first
+
+	pendingExtensions []HeaderExtension // Header extensions for the next packet
 }
 
 // NewAudioPacketizer creates a new audio RTP packetizer.
@@ -121,21 +125,21 @@ func NewAudioPacketizerWithSSRCProvider(clockRate uint32, transport transport.Tr
 // validatePacketizerInputs validates the required parameters for audio packetizer creation.
 func validatePacketizerInputs(clockRate uint32, transport transport.Transport, remoteAddr net.Addr) error {
 	if clockRate == 0 {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "NewAudioPacketizer",
 			"error":    "clock rate cannot be zero",
 		}).Error("Invalid clock rate")
 		return fmt.Errorf("clock rate cannot be zero")
 	}
 	if transport == nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "NewAudioPacketizer",
 			"error":    "transport cannot be nil",
 		}).Error("Invalid transport")
 		return fmt.Errorf("transport cannot be nil")
 	}
 	if remoteAddr == nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "NewAudioPacketizer",
 			"error":    "remote address cannot be nil",
 		}).Error("Invalid remote address")
@@ -154,7 +158,7 @@ func ensurePacketizerSSRCProvider(ssrcProvider SSRCProvider) SSRCProvider {
 
 // logPacketizerCreation logs the start of audio packetizer creation.
 func logPacketizerCreation(clockRate uint32, remoteAddr net.Addr) {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":    "NewAudioPacketizer",
 		"clock_rate":  clockRate,
 		"remote_addr": remoteAddr.String(),
@@ -165,7 +169,7 @@ func logPacketizerCreation(clockRate uint32, remoteAddr net.Addr) {
 func generatePacketizerSSRC(ssrcProvider SSRCProvider) (uint32, error) {
 	ssrc, err := ssrcProvider.GenerateSSRC()
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "NewAudioPacketizer",
 			"error":    err.Error(),
 		}).Error("Failed to generate SSRC")
@@ -189,7 +193,7 @@ func buildAudioPacketizer(ssrc, clockRate uint32, transport transport.Transport,
 
 // logPacketizerSuccess logs successful audio packetizer creation.
 func logPacketizerSuccess(ssrc, clockRate uint32) {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":   "NewAudioPacketizer",
 		"ssrc":       ssrc,
 		"clock_rate": clockRate,
@@ -208,7 +212,7 @@ func logPacketizerSuccess(ssrc, clockRate uint32) {
 // Returns:
 //   - error: Any error that occurred during packetization or sending
 func (ap *AudioPacketizer) PacketizeAndSend(audioData []byte, sampleCount uint32) error {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":     "AudioPacketizer.PacketizeAndSend",
 		"data_size":    len(audioData),
 		"sample_count": sampleCount,
@@ -230,9 +234,10 @@ func (ap *AudioPacketizer) PacketizeAndSend(audioData []byte, sampleCount uint32
 		return err
 	}
 
+	ap.rememberREDFrame(audioData)
 	ap.updateRTPCounters(sampleCount)
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":      "AudioPacketizer.PacketizeAndSend",
 		"new_sequence":  ap.sequenceNumber,
 		"new_timestamp": ap.timestamp,
@@ -244,7 +249,7 @@ func (ap *AudioPacketizer) PacketizeAndSend(audioData []byte, sampleCount uint32
 // validateAudioData validates that audio data is non-empty.
 func validateAudioData(audioData []byte) error {
 	if len(audioData) == 0 {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "AudioPacketizer.PacketizeAndSend",
 			"error":    "audio data cannot be empty",
 		}).Error("Invalid audio data")
@@ -255,21 +260,25 @@ func validateAudioData(audioData []byte) error {
 
 // createAndMarshalRTPPacket creates and marshals an RTP packet from audio data.
 func (ap *AudioPacketizer) createAndMarshalRTPPacket(audioData []byte) ([]byte, error) {
+	payloadType, payload := ap.buildPayload(audioData)
 	packet := &rtp.Packet{
 		Header: rtp.Header{
 			Version:        2,
 			Padding:        false,
 			Extension:      false,
 			Marker:         false,
-			PayloadType:    96,
+			PayloadType:    payloadType,
 			SequenceNumber: ap.sequenceNumber,
 			Timestamp:      ap.timestamp,
 			SSRC:           ap.ssrc,
 		},
-		Payload: audioData,
+		Payload: payload,
+	}
+	if err := ap.applyExtensions(&packet.Header); err != nil {
+		return nil, err
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":        "AudioPacketizer.PacketizeAndSend",
 		"sequence_number": ap.sequenceNumber,
 		"timestamp":       ap.timestamp,
@@ -278,7 +287,7 @@ func (ap *AudioPacketizer) createAndMarshalRTPPacket(audioData []byte) ([]byte,
 
 	rtpData, err := packet.Marshal()
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "AudioPacketizer.PacketizeAndSend",
 			"error":    err.Error(),
 		}).Error("Failed to marshal RTP packet")
@@ -295,14 +304,14 @@ func (ap *AudioPacketizer) sendToxPacket(rtpData []byte) error {
 		Data:       rtpData,
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":    "AudioPacketizer.PacketizeAndSend",
 		"rtp_size":    len(rtpData),
 		"packet_type": toxPacket.PacketType,
 	}).Debug("Created Tox transport packet")
 
 	if err := ap.transport.Send(toxPacket, ap.remoteAddr); err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "AudioPacketizer.PacketizeAndSend",
 			"error":    err.Error(),
 		}).Error("Failed to send audio RTP packet")
@@ -318,6 +327,24 @@ func (ap *AudioPacketizer) updateRTPCounters(sampleCount uint32) {
 	ap.timestamp += sampleCount
 }
 
+// GetStreamState returns the sequence number and timestamp the next packet
+// will be sent with.
+func (ap *AudioPacketizer) GetStreamState() (sequenceNumber uint16, timestamp uint32) {
+	ap.mu.RLock()
+	defer ap.mu.RUnlock()
+	return ap.sequenceNumber, ap.timestamp
+}
+
+// SetStreamState sets the sequence number and timestamp of the next packet,
+// so that a stream handed over from another packetizer continues without a
+// discontinuity.
+func (ap *AudioPacketizer) SetStreamState(sequenceNumber uint16, timestamp uint32) {
+	ap.mu.Lock()
+	defer ap.mu.Unlock()
+	ap.sequenceNumber = sequenceNumber
+	ap.timestamp = timestamp
+}
+
 // AudioDepacketizer handles RTP depacketization for incoming audio frames.
 //
 // This extracts audio data from received RTP packets and provides
@@ -329,6 +356,7 @@ type AudioDepacketizer struct {
 	lastSeq      uint16
 	hasLastSeq   bool
 	jitterBuffer *JitterBuffer
+	missing      map[uint16]struct{} // Lost sequence numbers awaiting RED recovery
 }
 
 // NewAudioDepacketizer creates a new audio RTP depacketizer.
@@ -336,7 +364,7 @@ type AudioDepacketizer struct {
 // Returns:
 //   - *AudioDepacketizer: New depacketizer instance
 func NewAudioDepacketizer() *AudioDepacketizer {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "NewAudioDepacketizer",
 	}).Info("Creating new audio depacketizer")
 
@@ -344,7 +372,7 @@ func NewAudioDepacketizer() *AudioDepacketizer {
 		jitterBuffer: NewJitterBuffer(50 * time.Millisecond), // 50ms jitter buffer
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":           "NewAudioDepacketizer",
 		"jitter_buffer_size": "50ms",
 	}).Info("Audio depacketizer created successfully")
@@ -355,7 +383,9 @@ func NewAudioDepacketizer() *AudioDepacketizer {
 // ProcessPacket processes an incoming RTP audio packet.
 //
 // This method extracts audio data from RTP packets and handles
-// basic packet validation and jitter buffering.
+// basic packet validation and jitter buffering. RED packets
+// (REDPayloadType) are unwrapped to their primary frame; redundant
+// frames are buffered only for sequence numbers detected as lost.
 //
 // Parameters:
 //   - rtpData: Raw RTP packet data
@@ -365,13 +395,13 @@ func NewAudioDepacketizer() *AudioDepacketizer {
 //   - uint32: Timestamp from RTP header
 //   - error: Any error that occurred during processing
 func (ad *AudioDepacketizer) ProcessPacket(rtpData []byte) ([]byte, uint32, error) {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":  "AudioDepacketizer.ProcessPacket",
 		"data_size": len(rtpData),
 	}).Debug("Processing incoming RTP packet")
 
 	if len(rtpData) == 0 {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "AudioDepacketizer.ProcessPacket",
 			"error":    "RTP data cannot be empty",
 		}).Error("Invalid RTP data")
@@ -391,18 +421,31 @@ func (ad *AudioDepacketizer) ProcessPacket(rtpData []byte) ([]byte, uint32, erro
 	}
 
 	ad.checkSequenceGap(packet)
-	ad.jitterBuffer.Add(packet.Timestamp, packet.Payload)
 
-	logrus.WithFields(logrus.Fields{
+	payload := packet.Payload
+	if packet.PayloadType == REDPayloadType {
+		blocks, err := decodeREDPayload(packet.Payload)
+		if err != nil {
+			pkgLog.WithFields(logrus.Fields{
+				"function": "AudioDepacketizer.ProcessPacket",
+				"error":    err.Error(),
+			}).Error("Failed to decode RED payload")
+			return nil, 0, fmt.Errorf("failed to decode RED payload: %w", err)
+		}
+		payload = ad.recoverRedundant(packet.SequenceNumber, packet.Timestamp, blocks)
+	}
+	ad.jitterBuffer.Add(packet.Timestamp, payload)
+
+	pkgLog.WithFields(logrus.Fields{
 		"function":     "AudioDepacketizer.ProcessPacket",
 		"timestamp":    packet.Timestamp,
-		"payload_size": len(packet.Payload),
+		"payload_size": len(payload),
 	}).Debug("RTP packet processed successfully")
 
 	// Return a copy of the payload to prevent aliasing with the input buffer.
 	// pion/rtp.Unmarshal makes packet.Payload alias the input slice, so we must copy
 	// before returning to the caller (L-2 fix).
-	payloadCopy := append([]byte(nil), packet.Payload...)
+	payloadCopy := append([]byte(nil), payload...)
 	return payloadCopy, packet.Timestamp, nil
 }
 
@@ -410,14 +453,14 @@ func (ad *AudioDepacketizer) ProcessPacket(rtpData []byte) ([]byte, uint32, erro
 func (ad *AudioDepacketizer) parseRTPPacket(rtpData []byte) (*rtp.Packet, error) {
 	packet := &rtp.Packet{}
 	if err := packet.Unmarshal(rtpData); err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "AudioDepacketizer.ProcessPacket",
 			"error":    err.Error(),
 		}).Error("Failed to unmarshal RTP packet")
 		return nil, fmt.Errorf("failed to unmarshal RTP packet: %w", err)
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":     "AudioDepacketizer.ProcessPacket",
 		"ssrc":         packet.SSRC,
 		"sequence":     packet.SequenceNumber,
@@ -433,7 +476,7 @@ func (ad *AudioDepacketizer) validateAndUpdateSSRC(packet *rtp.Packet) error {
 	if !ad.hasSSRC {
 		ad.expectedSSRC = packet.SSRC
 		ad.hasSSRC = true
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "AudioDepacketizer.ProcessPacket",
 			"ssrc":     packet.SSRC,
 		}).Info("Accepted new SSRC for stream")
@@ -441,7 +484,7 @@ func (ad *AudioDepacketizer) validateAndUpdateSSRC(packet *rtp.Packet) error {
 	}
 
 	if packet.SSRC != ad.expectedSSRC {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":      "AudioDepacketizer.ProcessPacket",
 			"expected_ssrc": ad.expectedSSRC,
 			"received_ssrc": packet.SSRC,
@@ -453,16 +496,24 @@ func (ad *AudioDepacketizer) validateAndUpdateSSRC(packet *rtp.Packet) error {
 }
 
 // checkSequenceGap detects and logs gaps in the RTP sequence numbers.
+// Skipped sequence numbers are flagged as missing for RED recovery, and a
+// late packet clears its own flag.
 func (ad *AudioDepacketizer) checkSequenceGap(packet *rtp.Packet) {
+	delete(ad.missing, packet.SequenceNumber)
 	if ad.hasLastSeq {
 		expectedSeq := ad.lastSeq + 1
 		if packet.SequenceNumber != expectedSeq {
-			logrus.WithFields(logrus.Fields{
+			pkgLog.WithFields(logrus.Fields{
 				"function":          "AudioDepacketizer.ProcessPacket",
 				"expected_sequence": expectedSeq,
 				"received_sequence": packet.SequenceNumber,
 			}).Warn("Sequence gap detected in RTP stream")
 		}
+		if int16(packet.SequenceNumber-ad.lastSeq) <= 0 {
+			// Reordered or duplicate packet; keep tracking from the newest
+			return
+		}
+		ad.markMissing(expectedSeq, packet.SequenceNumber)
 	}
 	ad.lastSeq = packet.SequenceNumber
 	ad.hasLastSeq = true
@@ -491,24 +542,48 @@ const DefaultMaxBufferCapacity = 100
 type jitterBufferEntry struct {
 	timestamp uint32
 	data      []byte
+	arrival   time.Time
 }
 
 // JitterBuffer provides basic jitter buffering for audio packets.
 //
 // This implementation buffers packets for a fixed duration to smooth out
 // network jitter and provides consistent audio playback. Packets are
-// returned in timestamp order for proper audio sequencing.
+// returned in timestamp order for proper audio sequencing. With
+// EnableAdaptiveDelay the duration follows the measured jitter instead.
 //
 // The buffer has a configurable maximum capacity (default 100 packets)
-// to prevent unbounded memory growth. When capacity is exceeded, the
-// oldest packets are evicted.
+// to prevent unbounded memory growth. When capacity is exceeded, a packet
+// is discarded according to the PrunePolicy (by default the oldest); see
+// SetConfig.
 type JitterBuffer struct {
 	mu           sync.RWMutex
 	bufferTime   time.Duration
 	packets      []jitterBufferEntry // sorted by timestamp
 	maxCapacity  int                 // maximum number of packets to buffer
+	prunePolicy  PrunePolicy
+	pruneAge     time.Duration // 0 disables age-based pruning
 	lastDequeue  time.Time
 	timeProvider TimeProvider
+
+	// Playout position and counters reported by GetBufferStats
+	lastReleased uint32
+	hasReleased  bool
+	discarded    uint64
+	latePackets  uint64
+
+	// Interarrival jitter (RFC 3550) and adaptive playout delay; see
+	// EnableAdaptiveDelay
+	jitter         float64 // nanoseconds
+	lastArrival    time.Time
+	lastTimestamp  uint32
+	hasArrival     bool
+	adaptive       bool
+	adaptiveConfig AdaptiveDelayConfig
+	currentDelay   time.Duration
+	playoutRef     jitterBufferEntry // timestamp and arrival anchoring playout
+	hasPlayoutRef  bool
+	minTransit     time.Duration // fastest transit relative to playoutRef
 }
 
 // NewJitterBuffer creates a new jitter buffer.
@@ -546,7 +621,7 @@ func NewJitterBufferWithTimeProvider(bufferTime time.Duration, timeProvider Time
 // Returns:
 //   - *JitterBuffer: New jitter buffer instance
 func NewJitterBufferWithOptions(bufferTime time.Duration, maxCapacity int, timeProvider TimeProvider) *JitterBuffer {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":     "NewJitterBuffer",
 		"buffer_time":  bufferTime.String(),
 		"max_capacity": maxCapacity,
@@ -567,7 +642,7 @@ func NewJitterBufferWithOptions(bufferTime time.Duration, maxCapacity int, timeP
 		timeProvider: timeProvider,
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":     "NewJitterBuffer",
 		"buffer_time":  bufferTime.String(),
 		"max_capacity": maxCapacity,
@@ -588,8 +663,8 @@ func (jb *JitterBuffer) SetTimeProvider(tp TimeProvider) {
 }
 
 // SetMaxCapacity sets the maximum number of packets in the jitter buffer.
-// When capacity is exceeded, oldest packets are evicted.
-// A value of 0 or negative uses the default capacity.
+// When capacity is exceeded, packets are evicted according to the prune
+// policy. A value of 0 or negative uses the default capacity.
 func (jb *JitterBuffer) SetMaxCapacity(capacity int) {
 	jb.mu.Lock()
 	defer jb.mu.Unlock()
@@ -600,8 +675,10 @@ func (jb *JitterBuffer) SetMaxCapacity(capacity int) {
 	// Evict excess packets if necessary
 	if len(jb.packets) > jb.maxCapacity {
 		evicted := len(jb.packets) - jb.maxCapacity
-		jb.packets = jb.packets[evicted:]
-		logrus.WithFields(logrus.Fields{
+		for len(jb.packets) > jb.maxCapacity {
+			jb.discardLocked(jb.pruneIndexLocked())
+		}
+		pkgLog.WithFields(logrus.Fields{
 			"function":      "JitterBuffer.SetMaxCapacity",
 			"evicted_count": evicted,
 			"new_capacity":  capacity,
@@ -619,14 +696,15 @@ func (jb *JitterBuffer) Len() int {
 
 // Add adds a packet to the jitter buffer.
 //
-// Packets are inserted in timestamp order. If the buffer is at capacity,
-// the oldest packet is evicted to make room.
+// Packets are inserted in timestamp order. Packets older than the prune age
+// are dropped first; if the buffer is still at capacity, a packet is
+// discarded according to the prune policy to make room.
 //
 // Parameters:
 //   - timestamp: RTP timestamp
 //   - data: Audio data
 func (jb *JitterBuffer) Add(timestamp uint32, data []byte) {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":  "JitterBuffer.Add",
 		"timestamp": timestamp,
 		"data_size": len(data),
@@ -640,20 +718,28 @@ func (jb *JitterBuffer) Add(timestamp uint32, data []byte) {
 	// retaining it without copying causes corruption if the buffer is reused (L-06 fix).
 	dataCopy := make([]byte, len(data))
 	copy(dataCopy, data)
-	entry := jitterBufferEntry{timestamp: timestamp, data: dataCopy}
+	entry := jitterBufferEntry{timestamp: timestamp, data: dataCopy, arrival: jb.timeProvider.Now()}
+
+	// Serial number comparison so timestamp wraparound is not counted as late
+	if jb.hasReleased && int32(timestamp-jb.lastReleased) < 0 {
+		jb.latePackets++
+	}
+	jb.pruneExpiredLocked()
+	jb.updateJitterLocked(entry)
 
 	// Find insertion point using binary search for sorted order
 	insertIdx := jb.findInsertIndex(timestamp)
 
 	// If at capacity, evict oldest packet first
-	if len(jb.packets) >= jb.maxCapacity {
+	if len(jb.packets) >= jb.maxCapacity && jb.prunePolicy == PruneOldest {
 		evicted := jb.packets[0]
 		jb.packets = jb.packets[1:]
+		jb.discarded++
 		// Adjust insert index after eviction
 		if insertIdx > 0 {
 			insertIdx--
 		}
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":          "JitterBuffer.Add",
 			"evicted_timestamp": evicted.timestamp,
 			"new_timestamp":     timestamp,
@@ -665,7 +751,12 @@ func (jb *JitterBuffer) Add(timestamp uint32, data []byte) {
 	copy(jb.packets[insertIdx+1:], jb.packets[insertIdx:])
 	jb.packets[insertIdx] = entry
 
-	logrus.WithFields(logrus.Fields{
+	// Other policies may discard any packet, including the new one
+	if len(jb.packets) > jb.maxCapacity {
+		jb.discardLocked(jb.pruneIndexLocked())
+	}
+
+	pkgLog.WithFields(logrus.Fields{
 		"function":    "JitterBuffer.Add",
 		"timestamp":   timestamp,
 		"buffer_size": len(jb.packets),
@@ -692,23 +783,28 @@ func (jb *JitterBuffer) findInsertIndex(timestamp uint32) int {
 //
 // This implements a simple time-based release mechanism.
 // Packets are returned in timestamp order (oldest first) after the
-// buffer time has elapsed since the last dequeue.
+// buffer time has elapsed since the last dequeue. With adaptive delay
+// enabled, the oldest packet is returned once its playout time has come.
 //
 // Returns:
 //   - []byte: Audio data (nil if no data ready)
 //   - bool: Whether data was available
 func (jb *JitterBuffer) Get() ([]byte, bool) {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "JitterBuffer.Get",
 	}).Debug("Retrieving packet from jitter buffer")
 
 	jb.mu.Lock()
 	defer jb.mu.Unlock()
 
+	if jb.adaptive {
+		return jb.getAdaptiveLocked()
+	}
+
 	// Simple time-based release: wait for buffer time to pass since last dequeue
 	timeSinceLastDequeue := jb.timeProvider.Now().Sub(jb.lastDequeue)
 	if timeSinceLastDequeue < jb.bufferTime {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":        "JitterBuffer.Get",
 			"time_since_last": timeSinceLastDequeue.String(),
 			"buffer_time":     jb.bufferTime.String(),
@@ -716,32 +812,66 @@ func (jb *JitterBuffer) Get() ([]byte, bool) {
 		return nil, false
 	}
 
+	jb.pruneExpiredLocked()
+
 	// Return oldest packet (first in sorted slice) for proper ordering
 	if len(jb.packets) == 0 {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "JitterBuffer.Get",
 		}).Debug("No packets available in jitter buffer")
 		return nil, false
 	}
 
-	// Get oldest packet (lowest timestamp, first in sorted slice)
+	return jb.releaseHeadLocked(), true
+}
+
+// getAdaptiveLocked releases the oldest packet once its playout time under
+// the current adaptive delay has come. Packets whose successor is already
+// due have missed their slot and are discarded, so a late packet does not
+// push back the playout of everything after it. The caller must hold jb.mu.
+func (jb *JitterBuffer) getAdaptiveLocked() ([]byte, bool) {
+	jb.pruneExpiredLocked()
+	if len(jb.packets) == 0 {
+		return nil, false
+	}
+	now := jb.timeProvider.Now()
+	for len(jb.packets) > 1 && !jb.playoutTimeLocked(jb.packets[1]).After(now) {
+		jb.discardLocked(0)
+	}
+	if until := jb.playoutTimeLocked(jb.packets[0]).Sub(now); until > 0 {
+		pkgLog.WithFields(logrus.Fields{
+			"function":      "JitterBuffer.Get",
+			"until_playout": until.String(),
+			"current_delay": jb.currentDelay.String(),
+		}).Debug("Playout time not reached, no packet ready")
+		return nil, false
+	}
+	return jb.releaseHeadLocked(), true
+}
+
+// releaseHeadLocked removes and returns the oldest packet (lowest
+// timestamp, first in the sorted slice). The buffer must not be empty and
+// the caller must hold jb.mu.
+func (jb *JitterBuffer) releaseHeadLocked() []byte {
 	entry := jb.packets[0]
 	jb.packets = jb.packets[1:]
 	jb.lastDequeue = jb.timeProvider.Now()
+	jb.lastReleased = entry.timestamp
+	jb.hasReleased = true
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":          "JitterBuffer.Get",
 		"timestamp":         entry.timestamp,
 		"data_size":         len(entry.data),
 		"remaining_packets": len(jb.packets),
 	}).Debug("Retrieved packet from jitter buffer")
 
-	return entry.data, true
+	return entry.data
 }
 
 // Reset clears the jitter buffer.
 func (jb *JitterBuffer) Reset() {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "JitterBuffer.Reset",
 	}).Info("Resetting jitter buffer")
 
@@ -751,8 +881,16 @@ func (jb *JitterBuffer) Reset() {
 	packetCount := len(jb.packets)
 	jb.packets = make([]jitterBufferEntry, 0, jb.maxCapacity)
 	jb.lastDequeue = jb.timeProvider.Now()
+	jb.hasReleased = false
+	jb.hasArrival = false
+	jb.hasPlayoutRef = false
+	jb.minTransit = 0
+	jb.jitter = 0
+	if jb.adaptive {
+		jb.currentDelay = clampDelay(jb.bufferTime, jb.adaptiveConfig)
+	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":        "JitterBuffer.Reset",
 		"cleared_packets": packetCount,
 	}).Info("Jitter buffer reset successfully")
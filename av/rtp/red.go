@@ -0,0 +1,225 @@
+package rtp
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/opd-ai/toxcore/transport"
+	"github.com/sirupsen/logrus"
+)
+
+func init() {
+	transport.RegisterCapability(transport.CapabilityREDAudio, "red-audio")
+}
+
+// RFC 2198 redundant audio (RED) parameters.
+const (
+	// REDPayloadType is the dynamic RTP payload type used for RED packets.
+	REDPayloadType uint8 = 116
+
+	// OpusPayloadType is the dynamic RTP payload type used for Opus frames,
+	// both as plain packets and as the block type inside RED packets.
+	OpusPayloadType uint8 = 96
+
+	// MaxREDLevels is the maximum number of previous frames carried as
+	// redundancy in each RED packet.
+	MaxREDLevels = 2
+
+	// redBlockHeaderSize is the size of a redundant block header; the final
+	// (primary) block header is a single byte.
+	redBlockHeaderSize = 4
+	// redMaxTimestampOffset and redMaxBlockLength are the limits of the
+	// 14-bit offset and 10-bit length fields.
+	redMaxTimestampOffset = 1<<14 - 1
+	redMaxBlockLength     = 1<<10 - 1
+	// maxTrackedMissing bounds the number of lost sequence numbers the
+	// depacketizer remembers for RED recovery.
+	maxTrackedMissing = 16
+)
+
+// redFrame is a previously sent frame kept for redundancy.
+type redFrame struct {
+	timestamp uint32
+	payload   []byte
+}
+
+// redBlock is one block decoded from a RED payload. For redundant blocks
+// timestampOffset is how far the block lies before the packet timestamp; the
+// primary block has an offset of zero.
+type redBlock struct {
+	payloadType     uint8
+	timestampOffset uint32
+	payload         []byte
+}
+
+// SetREDEnabled enables RFC 2198 redundant audio with the given number of
+// previous frames (1 or 2) bundled into each packet, or disables it when
+// levels is 0. RED packets are sent with payload type REDPayloadType.
+//
+// Previous frames that cannot be described by a RED block header (more than
+// 1023 bytes, or more than 16383 timestamp units old) are left out of the
+// redundancy, together with any older frames, rather than failing the send.
+func (ap *AudioPacketizer) SetREDEnabled(levels int) error {
+	if levels < 0 || levels > MaxREDLevels {
+		return fmt.Errorf("RED levels must be between 0 and %d, got %d", MaxREDLevels, levels)
+	}
+
+	ap.mu.Lock()
+	defer ap.mu.Unlock()
+
+	ap.redLevels = levels
+	if len(ap.redHistory) > levels {
+		ap.redHistory = ap.redHistory[len(ap.redHistory)-levels:]
+	}
+
+	pkgLog.WithFields(logrus.Fields{
+		"function": "AudioPacketizer.SetREDEnabled",
+		"levels":   levels,
+	}).Info("RED redundancy configured")
+
+	return nil
+}
+
+// SetRedundancy sets the RED redundancy level: 0 turns it off, 1 carries
+// the previous frame and 2 the two previous frames. It is equivalent to
+// SetREDEnabled.
+func (ap *AudioPacketizer) SetRedundancy(level int) error {
+	return ap.SetREDEnabled(level)
+}
+
+// buildPayload returns the RTP payload type and payload for audioData,
+// wrapping it in a RED envelope when redundancy is enabled.
+func (ap *AudioPacketizer) buildPayload(audioData []byte) (uint8, []byte) {
+	if ap.redLevels == 0 {
+		return OpusPayloadType, audioData
+	}
+	return REDPayloadType, encodeREDPayload(ap.redHistory, ap.timestamp, audioData)
+}
+
+// rememberREDFrame records the frame just sent for use as redundancy.
+func (ap *AudioPacketizer) rememberREDFrame(audioData []byte) {
+	if ap.redLevels == 0 {
+		return
+	}
+	ap.redHistory = append(ap.redHistory, redFrame{
+		timestamp: ap.timestamp,
+		payload:   append([]byte(nil), audioData...),
+	})
+	if len(ap.redHistory) > ap.redLevels {
+		ap.redHistory = ap.redHistory[len(ap.redHistory)-ap.redLevels:]
+	}
+}
+
+// encodeREDPayload builds an RFC 2198 payload carrying history (oldest
+// first) as redundant blocks followed by primary.
+func encodeREDPayload(history []redFrame, timestamp uint32, primary []byte) []byte {
+	// Receivers map redundant blocks to the immediately preceding sequence
+	// numbers, so an unusable frame also drops every older one.
+	start := len(history)
+	for start > 0 {
+		frame := history[start-1]
+		offset := timestamp - frame.timestamp
+		if offset == 0 || offset > redMaxTimestampOffset || len(frame.payload) > redMaxBlockLength {
+			break
+		}
+		start--
+	}
+	usable := history[start:]
+
+	size := len(usable)*redBlockHeaderSize + 1 + len(primary)
+	for _, frame := range usable {
+		size += len(frame.payload)
+	}
+
+	payload := make([]byte, 0, size)
+	for _, frame := range usable {
+		offset := timestamp - frame.timestamp
+		header := 1<<31 | uint32(OpusPayloadType)<<24 | offset<<10 | uint32(len(frame.payload))
+		payload = binary.BigEndian.AppendUint32(payload, header)
+	}
+	payload = append(payload, OpusPayloadType)
+	for _, frame := range usable {
+		payload = append(payload, frame.payload...)
+	}
+	return append(payload, primary...)
+}
+
+// decodeREDPayload splits an RFC 2198 payload into its blocks, with the
+// primary block last.
+func decodeREDPayload(payload []byte) ([]redBlock, error) {
+	var blocks []redBlock
+	pos := 0
+	for {
+		if pos >= len(payload) {
+			return nil, fmt.Errorf("truncated RED header")
+		}
+		if payload[pos]&0x80 == 0 {
+			blocks = append(blocks, redBlock{payloadType: payload[pos] & 0x7F})
+			pos++
+			break
+		}
+		if pos+redBlockHeaderSize > len(payload) {
+			return nil, fmt.Errorf("truncated RED block header")
+		}
+		header := binary.BigEndian.Uint32(payload[pos:])
+		blocks = append(blocks, redBlock{
+			payloadType:     uint8(header>>24) & 0x7F,
+			timestampOffset: (header >> 10) & redMaxTimestampOffset,
+			payload:         make([]byte, header&redMaxBlockLength),
+		})
+		pos += redBlockHeaderSize
+	}
+
+	for i := range blocks[:len(blocks)-1] {
+		n := len(blocks[i].payload)
+		if pos+n > len(payload) {
+			return nil, fmt.Errorf("RED block %d exceeds payload", i)
+		}
+		copy(blocks[i].payload, payload[pos:pos+n])
+		pos += n
+	}
+	blocks[len(blocks)-1].payload = payload[pos:]
+	return blocks, nil
+}
+
+// markMissing flags the sequence numbers in [from, to) as lost so a later
+// RED packet can fill them in. Only the most recent maxTrackedMissing
+// sequence numbers before to are remembered; older flags are dropped.
+func (ad *AudioDepacketizer) markMissing(from, to uint16) {
+	if ad.missing == nil {
+		ad.missing = make(map[uint16]struct{})
+	}
+	for seq := range ad.missing {
+		if uint16(to-seq) > maxTrackedMissing {
+			delete(ad.missing, seq)
+		}
+	}
+	if uint16(to-from) > maxTrackedMissing {
+		from = to - maxTrackedMissing
+	}
+	for seq := from; seq != to; seq++ {
+		ad.missing[seq] = struct{}{}
+	}
+}
+
+// recoverRedundant adds redundant blocks of a RED packet to the jitter
+// buffer for any sequence numbers previously flagged as missing, and returns
+// the primary block.
+func (ad *AudioDepacketizer) recoverRedundant(seq uint16, timestamp uint32, blocks []redBlock) []byte {
+	redundant := blocks[:len(blocks)-1]
+	for i, block := range redundant {
+		blockSeq := seq - uint16(len(redundant)-i)
+		if _, lost := ad.missing[blockSeq]; !lost {
+			continue
+		}
+		delete(ad.missing, blockSeq)
+		ad.jitterBuffer.Add(timestamp-block.timestampOffset, block.payload)
+
+		pkgLog.WithFields(logrus.Fields{
+			"function":  "AudioDepacketizer.ProcessPacket",
+			"sequence":  blockSeq,
+			"timestamp": timestamp - block.timestampOffset,
+		}).Debug("Recovered lost frame from RED redundancy")
+	}
+	return blocks[len(blocks)-1].payload
+}
@@ -0,0 +1,188 @@
+package rtp
+
+import (
+	"net"
+	"testing"
+
+	"github.com/opd-ai/toxcore/transport"
+	"github.com/pion/rtp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestREDCapabilityRegistered(t *testing.T) {
+	assert.NotZero(t, transport.LocalCapabilities()&transport.CapabilityREDAudio.Mask())
+	assert.Equal(t, "red-audio", transport.CapabilityREDAudio.String())
+}
+
+func TestREDPayloadRoundTrip(t *testing.T) {
+	history := []redFrame{
+		{timestamp: 960, payload: []byte("frame-1")},
+		{timestamp: 1920, payload: []byte("frame-2")},
+	}
+	payload := encodeREDPayload(history, 2880, []byte("frame-3"))
+
+	blocks, err := decodeREDPayload(payload)
+	require.NoError(t, err)
+	require.Len(t, blocks, 3)
+
+	assert.Equal(t, uint32(1920), blocks[0].timestampOffset)
+	assert.Equal(t, []byte("frame-1"), blocks[0].payload)
+	assert.Equal(t, uint32(960), blocks[1].timestampOffset)
+	assert.Equal(t, []byte("frame-2"), blocks[1].payload)
+	assert.Equal(t, OpusPayloadType, blocks[2].payloadType)
+	assert.Equal(t, []byte("frame-3"), blocks[2].payload)
+}
+
+func TestREDPayloadSkipsUnencodableFrames(t *testing.T) {
+	history := []redFrame{
+		{timestamp: 0, payload: []byte("old")},
+		{timestamp: 960, payload: make([]byte, redMaxBlockLength+1)},
+		{timestamp: 1920, payload: []byte("recent")},
+	}
+	blocks, err := decodeREDPayload(encodeREDPayload(history, 2880, []byte("now")))
+	require.NoError(t, err)
+
+	// The oversized frame and everything before it are dropped
+	require.Len(t, blocks, 2)
+	assert.Equal(t, []byte("recent"), blocks[0].payload)
+	assert.Equal(t, []byte("now"), blocks[1].payload)
+}
+
+func TestDecodeREDPayloadErrors(t *testing.T) {
+	_, err := decodeREDPayload(nil)
+	assert.Error(t, err)
+
+	_, err = decodeREDPayload([]byte{0x80 | OpusPayloadType, 0x00})
+	assert.Error(t, err, "truncated block header")
+
+	_, err = decodeREDPayload([]byte{0x80 | OpusPayloadType, 0x00, 0x04, 0x10, OpusPayloadType, 0x01})
+	assert.Error(t, err, "block length beyond payload")
+}
+
+func TestAudioPacketizer_SetREDEnabled(t *testing.T) {
+	mockTransport := NewMockTransport()
+	remoteAddr := &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 33445}
+	packetizer, err := NewAudioPacketizer(48000, mockTransport, remoteAddr)
+	require.NoError(t, err)
+
+	assert.Error(t, packetizer.SetREDEnabled(3))
+	assert.Error(t, packetizer.SetREDEnabled(-1))
+	require.NoError(t, packetizer.SetREDEnabled(2))
+
+	for _, frame := range []string{"a", "b", "c"} {
+		require.NoError(t, packetizer.PacketizeAndSend([]byte(frame), 960))
+	}
+
+	sent := mockTransport.GetSentPackets()
+	require.Len(t, sent, 3)
+
+	wantBlocks := []int{1, 2, 3}
+	for i, sp := range sent {
+		packet := &rtp.Packet{}
+		require.NoError(t, packet.Unmarshal(sp.Packet.Data))
+		assert.Equal(t, REDPayloadType, packet.PayloadType)
+
+		blocks, err := decodeREDPayload(packet.Payload)
+		require.NoError(t, err)
+		assert.Len(t, blocks, wantBlocks[i])
+	}
+
+	require.NoError(t, packetizer.SetREDEnabled(0))
+	require.NoError(t, packetizer.PacketizeAndSend([]byte("d"), 960))
+	packet := &rtp.Packet{}
+	require.NoError(t, packet.Unmarshal(mockTransport.GetSentPackets()[3].Packet.Data))
+	assert.Equal(t, OpusPayloadType, packet.PayloadType)
+	assert.Equal(t, []byte("d"), packet.Payload)
+}
+
+func TestAudioDepacketizer_REDRecovery(t *testing.T) {
+	mockTransport := NewMockTransport()
+	remoteAddr := &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 33445}
+	packetizer, err := NewAudioPacketizer(48000, mockTransport, remoteAddr)
+	require.NoError(t, err)
+	require.NoError(t, packetizer.SetREDEnabled(2))
+
+	for _, frame := range []string{"f0", "f1", "f2", "f3"} {
+		require.NoError(t, packetizer.PacketizeAndSend([]byte(frame), 960))
+	}
+	sent := mockTransport.GetSentPackets()
+
+	depacketizer := NewAudioDepacketizer()
+
+	data, ts, err := depacketizer.ProcessPacket(sent[0].Packet.Data)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("f0"), data)
+	assert.Equal(t, uint32(0), ts)
+
+	// Packets 1 and 2 are lost; packet 3 carries both as redundancy
+	data, ts, err = depacketizer.ProcessPacket(sent[3].Packet.Data)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("f3"), data)
+	assert.Equal(t, uint32(2880), ts)
+	assert.Empty(t, depacketizer.missing)
+
+	depacketizer.jitterBuffer.mu.Lock()
+	var buffered []string
+	var timestamps []uint32
+	for _, entry := range depacketizer.jitterBuffer.packets {
+		buffered = append(buffered, string(entry.data))
+		timestamps = append(timestamps, entry.timestamp)
+	}
+	depacketizer.jitterBuffer.mu.Unlock()
+	assert.Equal(t, []string{"f0", "f1", "f2", "f3"}, buffered)
+	assert.Equal(t, []uint32{0, 960, 1920, 2880}, timestamps)
+}
+
+func TestAudioDepacketizer_REDSingleLossLevelOne(t *testing.T) {
+	mockTransport := NewMockTransport()
+	remoteAddr := &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 33445}
+	packetizer, err := NewAudioPacketizer(48000, mockTransport, remoteAddr)
+	require.NoError(t, err)
+	assert.Error(t, packetizer.SetRedundancy(MaxREDLevels+1))
+	require.NoError(t, packetizer.SetRedundancy(1))
+
+	frames := []string{"f0", "f1", "f2", "f3", "f4"}
+	for _, frame := range frames {
+		require.NoError(t, packetizer.PacketizeAndSend([]byte(frame), 960))
+	}
+
+	// Packet 2 is lost; packet 3 carries it as redundancy
+	depacketizer := NewAudioDepacketizer()
+	for i, sp := range mockTransport.GetSentPackets() {
+		if i == 2 {
+			continue
+		}
+		_, _, err := depacketizer.ProcessPacket(sp.Packet.Data)
+		require.NoError(t, err)
+	}
+
+	depacketizer.jitterBuffer.mu.Lock()
+	var buffered []string
+	for _, entry := range depacketizer.jitterBuffer.packets {
+		buffered = append(buffered, string(entry.data))
+	}
+	depacketizer.jitterBuffer.mu.Unlock()
+	assert.Equal(t, frames, buffered)
+}
+
+func TestAudioDepacketizer_REDIgnoresReceivedFrames(t *testing.T) {
+	mockTransport := NewMockTransport()
+	remoteAddr := &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 33445}
+	packetizer, err := NewAudioPacketizer(48000, mockTransport, remoteAddr)
+	require.NoError(t, err)
+	require.NoError(t, packetizer.SetREDEnabled(1))
+
+	for _, frame := range []string{"f0", "f1"} {
+		require.NoError(t, packetizer.PacketizeAndSend([]byte(frame), 960))
+	}
+
+	depacketizer := NewAudioDepacketizer()
+	for _, sp := range mockTransport.GetSentPackets() {
+		_, _, err := depacketizer.ProcessPacket(sp.Packet.Data)
+		require.NoError(t, err)
+	}
+
+	// No loss, so the redundant copy of f0 must not be buffered twice
+	assert.Equal(t, 2, depacketizer.jitterBuffer.Len())
+}
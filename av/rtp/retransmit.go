@@ -0,0 +1,183 @@
+package rtp
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// DefaultRetransmitBufferSize is the number of sent video packets kept
+	// for NACK retransmission.
+	DefaultRetransmitBufferSize = 200
+
+	// DefaultMaxRetransmitAge is the age beyond which a video packet is no
+	// longer retransmitted, since it would arrive too late to be decoded.
+	DefaultMaxRetransmitAge = 200 * time.Millisecond
+
+	// maxNACKGap is the largest sequence gap for which missing packets are
+	// NACKed. Larger gaps are left to key frame recovery.
+	maxNACKGap = 64
+)
+
+// retransmitEntry is a sent packet and the time it was sent.
+type retransmitEntry struct {
+	data []byte
+	sent time.Time
+}
+
+// RTPRetransmitBuffer holds the most recently sent video RTP packets by
+// sequence number so they can be resent when the peer NACKs them (RFC
+// 4585 Generic NACK). When full, the oldest packet is evicted.
+//
+// RTPRetransmitBuffer is not safe for concurrent use; Session guards it
+// with its own lock.
+type RTPRetransmitBuffer struct {
+	capacity int
+	order    []uint16 // Sequence numbers, oldest first
+	packets  map[uint16]retransmitEntry
+}
+
+// NewRTPRetransmitBuffer creates a buffer holding up to capacity packets.
+//
+// Returns:
+//   - *RTPRetransmitBuffer: The new buffer
+//   - error: If capacity is not positive
+func NewRTPRetransmitBuffer(capacity int) (*RTPRetransmitBuffer, error) {
+	if capacity <= 0 {
+		return nil, fmt.Errorf("retransmit buffer size must be positive: %d", capacity)
+	}
+	return newRetransmitBuffer(capacity), nil
+}
+
+// newRetransmitBuffer creates a buffer for a capacity known to be positive.
+func newRetransmitBuffer(capacity int) *RTPRetransmitBuffer {
+	return &RTPRetransmitBuffer{
+		capacity: capacity,
+		order:    make([]uint16, 0, capacity),
+		packets:  make(map[uint16]retransmitEntry, capacity),
+	}
+}
+
+// Store records a serialized packet sent at time sent. A packet with the
+// same sequence number replaces the earlier one.
+func (b *RTPRetransmitBuffer) Store(seq uint16, data []byte, sent time.Time) {
+	if _, ok := b.packets[seq]; ok {
+		b.remove(seq)
+	}
+	if len(b.order) == b.capacity {
+		delete(b.packets, b.order[0])
+		b.order = b.order[1:]
+	}
+	b.order = append(b.order, seq)
+	b.packets[seq] = retransmitEntry{data: data, sent: sent}
+}
+
+// Lookup returns the packet with sequence number seq if it is buffered and
+// no older than maxAge at time now.
+func (b *RTPRetransmitBuffer) Lookup(seq uint16, now time.Time, maxAge time.Duration) ([]byte, bool) {
+	entry, ok := b.packets[seq]
+	if !ok || now.Sub(entry.sent) > maxAge {
+		return nil, false
+	}
+	return entry.data, true
+}
+
+// Len returns the number of buffered packets.
+func (b *RTPRetransmitBuffer) Len() int {
+	return len(b.order)
+}
+
+// remove drops seq from the buffer.
+func (b *RTPRetransmitBuffer) remove(seq uint16) {
+	delete(b.packets, seq)
+	for i, s := range b.order {
+		if s == seq {
+			b.order = append(b.order[:i], b.order[i+1:]...)
+			return
+		}
+	}
+}
+
+// SetRetransmitBufferSize changes how many sent video packets are kept for
+// NACK retransmission. Buffered packets are discarded.
+func (s *Session) SetRetransmitBufferSize(size int) error {
+	buf, err := NewRTPRetransmitBuffer(size)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.retransmitBuffer = buf
+	return nil
+}
+
+// SetMaxRetransmitAge changes the age beyond which NACKed video packets are
+// not retransmitted.
+func (s *Session) SetMaxRetransmitAge(age time.Duration) error {
+	if age <= 0 {
+		return fmt.Errorf("max retransmit age must be positive: %v", age)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.maxRetransmitAge = age
+	return nil
+}
+
+// retransmitLocked returns the buffered packets for the sequence numbers a
+// NACK reported lost, and whether any could not be resent. The caller must
+// hold s.mu.
+func (s *Session) retransmitLocked(lost []uint16, now time.Time) (packets [][]byte, missed bool) {
+	for _, seq := range lost {
+		data, ok := s.retransmitBuffer.Lookup(seq, now, s.maxRetransmitAge)
+		if !ok {
+			missed = true
+			continue
+		}
+		packets = append(packets, data)
+	}
+	s.stats.PacketsRetransmitted += uint64(len(packets))
+	return packets, missed
+}
+
+// trackVideoGapLocked queues NACKs for the packets missing between the
+// highest sequence number seen from ssrc and seq, and clears a pending NACK
+// that seq answers. It must run before the packet updates the source's
+// RTCP state. The caller must hold s.mu.
+func (s *Session) trackVideoGapLocked(ssrc uint32, seq uint16) {
+	for i, pending := range s.nackPending {
+		if pending == seq {
+			s.nackPending = append(s.nackPending[:i], s.nackPending[i+1:]...)
+			break
+		}
+	}
+
+	src, ok := s.rtcpSources[ssrc]
+	if !ok || !src.initialized {
+		return
+	}
+	gap := seq - src.maxSeq
+	if gap <= 1 || gap > maxNACKGap {
+		return
+	}
+	if s.nackSSRC != ssrc {
+		s.nackPending = nil
+		s.nackSSRC = ssrc
+	}
+	for missing := src.maxSeq + 1; missing != seq; missing++ {
+		if len(s.nackPending) == maxNACKGap {
+			s.nackPending = s.nackPending[1:]
+		}
+		s.nackPending = append(s.nackPending, missing)
+	}
+}
+
+// takeNACKLocked returns a NACK for the pending lost video packets, or nil
+// if none are pending, and clears them. The caller must hold s.mu.
+func (s *Session) takeNACKLocked() []byte {
+	if len(s.nackPending) == 0 {
+		return nil
+	}
+	nack := rtcpFeedback{senderSSRC: s.audioSSRC, mediaSSRC: s.nackSSRC, lost: s.nackPending}
+	s.nackPending = nil
+	return nack.marshal()
+}
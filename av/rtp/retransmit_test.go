@@ -0,0 +1,124 @@
+package rtp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRTPRetransmitBuffer(t *testing.T) {
+	_, err := NewRTPRetransmitBuffer(0)
+	assert.Error(t, err)
+
+	buf, err := NewRTPRetransmitBuffer(3)
+	require.NoError(t, err)
+	start := time.Unix(1700000000, 0)
+	for seq := uint16(65534); seq != 3; seq++ { // Wraps through 0
+		buf.Store(seq, []byte{byte(seq)}, start)
+	}
+	assert.Equal(t, 3, buf.Len())
+
+	// The oldest packets were evicted
+	_, ok := buf.Lookup(65535, start, time.Second)
+	assert.False(t, ok)
+	data, ok := buf.Lookup(2, start, time.Second)
+	require.True(t, ok)
+	assert.Equal(t, []byte{2}, data)
+
+	// Packets older than the maximum age are not returned
+	_, ok = buf.Lookup(2, start.Add(2*time.Second), time.Second)
+	assert.False(t, ok)
+
+	// Storing a sequence number again replaces it without evicting others
+	buf.Store(1, []byte{9}, start)
+	assert.Equal(t, 3, buf.Len())
+	data, ok = buf.Lookup(1, start, time.Second)
+	require.True(t, ok)
+	assert.Equal(t, []byte{9}, data)
+}
+
+// sendLossyVideoFrame sends a three-packet video frame from a to b with the
+// second packet dropped, and returns the sequence number that was lost.
+func sendLossyVideoFrame(t *testing.T, a *Session, ab *loopbackTransport) uint16 {
+	t.Helper()
+	ab.dropVideo = func(n int) bool { return n == 1 }
+	seq, _ := a.videoPacketizer.GetStats()
+	require.NoError(t, a.SendVideoPacket(make([]byte, 3000)))
+	ab.dropVideo = nil
+	ab.flush(t)
+	return seq + 1
+}
+
+func TestSession_NACKRetransmission(t *testing.T) {
+	a, b, ab, ba, clock := newLoopbackPair(t)
+
+	lost := sendLossyVideoFrame(t, a, ab)
+	assert.Empty(t, ab.frames, "frame is incomplete without the lost packet")
+	assert.Equal(t, uint64(1), b.GetStatistics().PacketsLost)
+
+	// The next report, sent within one RTCP interval, carries the NACK
+	clock.Advance(20 * time.Millisecond)
+	require.NoError(t, b.SendRTCPReport())
+	feedback, err := parseRTCPFeedback(ba.sentRTCP()[0].Data)
+	require.NoError(t, err)
+	require.Len(t, feedback, 1)
+	assert.Equal(t, []uint16{lost}, feedback[0].lost)
+	assert.Equal(t, uint32(0xA1), feedback[0].mediaSSRC)
+
+	ba.flush(t)
+	assert.Equal(t, uint64(1), a.GetStatistics().PacketsRetransmitted)
+	ab.flush(t)
+	require.Len(t, ab.frames, 1)
+	assert.Len(t, ab.frames[0], 3000)
+
+	// Nothing is NACKed once the loss is repaired
+	require.NoError(t, b.SendRTCPReport())
+	feedback, err = parseRTCPFeedback(ba.sentRTCP()[1].Data)
+	require.NoError(t, err)
+	assert.Empty(t, feedback)
+}
+
+func TestSession_NACKRetransmissionPeriodic(t *testing.T) {
+	a, b, ab, ba, _ := newLoopbackPair(t)
+	interval := 20 * time.Millisecond
+	require.NoError(t, b.SetRTCPInterval(interval))
+
+	sendLossyVideoFrame(t, a, ab)
+	assert.Eventually(t, func() bool { return len(ba.sentRTCP()) > 0 }, 2*interval, time.Millisecond)
+	ba.flush(t)
+	ab.flush(t)
+	assert.Equal(t, uint64(1), a.GetStatistics().PacketsRetransmitted)
+	assert.Len(t, ab.frames, 1)
+}
+
+func TestSession_NACKTooOld(t *testing.T) {
+	a, b, ab, ba, clock := newLoopbackPair(t)
+	assert.Error(t, a.SetMaxRetransmitAge(0))
+	require.NoError(t, a.SetMaxRetransmitAge(100*time.Millisecond))
+	keyframes := 0
+	a.SetKeyframeRequestHandler(func() { keyframes++ })
+
+	sendLossyVideoFrame(t, a, ab)
+	clock.Advance(150 * time.Millisecond)
+	require.NoError(t, b.SendRTCPReport())
+	ba.flush(t)
+
+	assert.Zero(t, a.GetStatistics().PacketsRetransmitted)
+	assert.Equal(t, 1, keyframes, "an unrecoverable loss falls back to a key frame")
+	ab.flush(t)
+	assert.Empty(t, ab.frames)
+}
+
+func TestSession_SetRetransmitBufferSize(t *testing.T) {
+	a, b, ab, ba, _ := newLoopbackPair(t)
+	assert.Error(t, a.SetRetransmitBufferSize(-1))
+	require.NoError(t, a.SetRetransmitBufferSize(1))
+
+	// Only the last packet of the frame is still buffered
+	sendLossyVideoFrame(t, a, ab)
+	require.NoError(t, b.SendRTCPReport())
+	ba.flush(t)
+	assert.Zero(t, a.GetStatistics().PacketsRetransmitted)
+}
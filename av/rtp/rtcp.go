@@ -0,0 +1,628 @@
+package rtp
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/opd-ai/toxcore/transport"
+	"github.com/sirupsen/logrus"
+)
+
+// RTCPMode selects how RTCP packets share the Tox transport with RTP.
+type RTCPMode int
+
+const (
+	// RTCPMux sends RTCP on the audio RTP packet type. Receivers tell the
+	// two apart by the payload type byte, as in RFC 5761.
+	RTCPMux RTCPMode = iota
+	// RTCPSeparate sends RTCP on the dedicated PacketAVRTCP packet type,
+	// the Tox counterpart of the odd port next to the RTP port in RFC 3550.
+	RTCPSeparate
+)
+
+// String returns the mode name for logging.
+func (m RTCPMode) String() string {
+	switch m {
+	case RTCPMux:
+		return "mux"
+	case RTCPSeparate:
+		return "separate"
+	default:
+		return fmt.Sprintf("RTCPMode(%d)", int(m))
+	}
+}
+
+// DefaultRTCPInterval is the default interval between RTCP reports.
+const DefaultRTCPInterval = 5 * time.Second
+
+const (
+	rtcpTypeSR         = 200
+	rtcpTypeRR         = 201
+	rtcpHeaderSize     = 8  // common header and sender SSRC
+	rtcpSenderInfoSize = 20 // NTP, RTP timestamp, packet and octet counts
+	rtcpBlockSize      = 24
+	rtcpMaxBlocks      = 31
+
+	// ntpEpochOffset is the number of seconds from 1900 to 1970.
+	ntpEpochOffset = 2208988800
+
+	audioClockRate = 48000
+	videoClockRate = 90000
+)
+
+// RFC 4585 feedback packet types and formats.
+const (
+	rtcpTypeRTPFB    = 205 // Transport layer feedback
+	rtcpTypePSFB     = 206 // Payload-specific feedback
+	rtcpFmtNACK      = 1   // Generic NACK, carried in RTPFB
+	rtcpFmtPLI       = 1   // Picture Loss Indication, carried in PSFB
+	rtcpFeedbackSize = 12  // common header, sender and media SSRC
+	rtcpNACKItemSize = 4   // PID and BLP
+)
+
+// ErrInvalidRTCPPacket indicates a packet is not a well-formed RTCP sender or
+// receiver report.
+var ErrInvalidRTCPPacket = errors.New("invalid RTCP packet")
+
+// rtcpReportBlock is one reception report block (RFC 3550 section 6.4.1).
+type rtcpReportBlock struct {
+	ssrc           uint32
+	fractionLost   uint8
+	cumulativeLost int32 // 24-bit signed on the wire
+	highestSeq     uint32
+	jitter         uint32 // timestamp units
+	lastSR         uint32 // middle 32 bits of the NTP time of the last SR
+	delaySinceSR   uint32 // units of 1/65536 s
+}
+
+// rtcpReport is a sender report, or a receiver report when sender is false.
+type rtcpReport struct {
+	sender      bool
+	ssrc        uint32
+	ntpTime     uint64
+	rtpTime     uint32
+	packetCount uint32
+	octetCount  uint32
+	blocks      []rtcpReportBlock
+}
+
+// marshal encodes the report in RTCP wire format.
+func (r *rtcpReport) marshal() []byte {
+	size := rtcpHeaderSize + rtcpBlockSize*len(r.blocks)
+	pt := byte(rtcpTypeRR)
+	if r.sender {
+		size += rtcpSenderInfoSize
+		pt = rtcpTypeSR
+	}
+	data := make([]byte, size)
+	data[0] = 2<<6 | byte(len(r.blocks))
+	data[1] = pt
+	binary.BigEndian.PutUint16(data[2:4], uint16(size/4-1))
+	binary.BigEndian.PutUint32(data[4:8], r.ssrc)
+
+	off := rtcpHeaderSize
+	if r.sender {
+		binary.BigEndian.PutUint64(data[off:], r.ntpTime)
+		binary.BigEndian.PutUint32(data[off+8:], r.rtpTime)
+		binary.BigEndian.PutUint32(data[off+12:], r.packetCount)
+		binary.BigEndian.PutUint32(data[off+16:], r.octetCount)
+		off += rtcpSenderInfoSize
+	}
+	for _, b := range r.blocks {
+		binary.BigEndian.PutUint32(data[off:], b.ssrc)
+		lost := uint32(b.cumulativeLost) & 0xFFFFFF
+		binary.BigEndian.PutUint32(data[off+4:], uint32(b.fractionLost)<<24|lost)
+		binary.BigEndian.PutUint32(data[off+8:], b.highestSeq)
+		binary.BigEndian.PutUint32(data[off+12:], b.jitter)
+		binary.BigEndian.PutUint32(data[off+16:], b.lastSR)
+		binary.BigEndian.PutUint32(data[off+20:], b.delaySinceSR)
+		off += rtcpBlockSize
+	}
+	return data
+}
+
+// splitRTCP splits a compound RTCP packet into its individual packets.
+func splitRTCP(data []byte) ([][]byte, error) {
+	var pkts [][]byte
+	for len(data) > 0 {
+		if len(data) < 4 || data[0]>>6 != 2 {
+			return nil, fmt.Errorf("%w: bad header", ErrInvalidRTCPPacket)
+		}
+		length := (int(binary.BigEndian.Uint16(data[2:4])) + 1) * 4
+		if length > len(data) {
+			return nil, fmt.Errorf("%w: length %d exceeds packet", ErrInvalidRTCPPacket, length)
+		}
+		pkts = append(pkts, data[:length])
+		data = data[length:]
+	}
+	return pkts, nil
+}
+
+// parseRTCPReports decodes the sender and receiver reports of a compound
+// RTCP packet, skipping other RTCP packet types.
+func parseRTCPReports(data []byte) ([]rtcpReport, error) {
+	pkts, err := splitRTCP(data)
+	if err != nil {
+		return nil, err
+	}
+	var reports []rtcpReport
+	for _, pkt := range pkts {
+		pt := pkt[1]
+		if pt != rtcpTypeSR && pt != rtcpTypeRR {
+			continue
+		}
+		report, err := parseRTCPReport(pkt, pt == rtcpTypeSR)
+		if err != nil {
+			return nil, err
+		}
+		reports = append(reports, report)
+	}
+	return reports, nil
+}
+
+// parseRTCPReport decodes a single SR or RR packet.
+func parseRTCPReport(pkt []byte, sender bool) (rtcpReport, error) {
+	count := int(pkt[0] & 0x1F)
+	need := rtcpHeaderSize + rtcpBlockSize*count
+	if sender {
+		need += rtcpSenderInfoSize
+	}
+	if len(pkt) < need {
+		return rtcpReport{}, fmt.Errorf("%w: %d report blocks do not fit", ErrInvalidRTCPPacket, count)
+	}
+
+	r := rtcpReport{sender: sender, ssrc: binary.BigEndian.Uint32(pkt[4:8])}
+	off := rtcpHeaderSize
+	if sender {
+		r.ntpTime = binary.BigEndian.Uint64(pkt[off:])
+		r.rtpTime = binary.BigEndian.Uint32(pkt[off+8:])
+		r.packetCount = binary.BigEndian.Uint32(pkt[off+12:])
+		r.octetCount = binary.BigEndian.Uint32(pkt[off+16:])
+		off += rtcpSenderInfoSize
+	}
+	for i := 0; i < count; i++ {
+		word := binary.BigEndian.Uint32(pkt[off+4:])
+		lost := int32(word&0xFFFFFF) << 8 >> 8 // sign-extend 24 bits
+		r.blocks = append(r.blocks, rtcpReportBlock{
+			ssrc:           binary.BigEndian.Uint32(pkt[off:]),
+			fractionLost:   uint8(word >> 24),
+			cumulativeLost: lost,
+			highestSeq:     binary.BigEndian.Uint32(pkt[off+8:]),
+			jitter:         binary.BigEndian.Uint32(pkt[off+12:]),
+			lastSR:         binary.BigEndian.Uint32(pkt[off+16:]),
+			delaySinceSR:   binary.BigEndian.Uint32(pkt[off+20:]),
+		})
+		off += rtcpBlockSize
+	}
+	return r, nil
+}
+
+// rtcpFeedback is a Generic NACK or Picture Loss Indication (RFC 4585
+// section 6) about the media source mediaSSRC.
+type rtcpFeedback struct {
+	pli        bool
+	senderSSRC uint32
+	mediaSSRC  uint32
+	lost       []uint16 // Sequence numbers reported missing by a NACK
+}
+
+// marshal encodes the feedback in RTCP wire format. Lost sequence numbers
+// are packed into PID/BLP pairs in the order given.
+func (f *rtcpFeedback) marshal() []byte {
+	fmtType, pt := byte(rtcpFmtNACK), byte(rtcpTypeRTPFB)
+	var items [][2]uint16 // PID, BLP
+	if f.pli {
+		fmtType, pt = rtcpFmtPLI, rtcpTypePSFB
+	} else {
+		for _, seq := range f.lost {
+			if n := len(items); n > 0 {
+				if d := seq - items[n-1][0]; d >= 1 && d <= 16 {
+					items[n-1][1] |= 1 << (d - 1)
+					continue
+				}
+			}
+			items = append(items, [2]uint16{seq, 0})
+		}
+	}
+
+	size := rtcpFeedbackSize + rtcpNACKItemSize*len(items)
+	data := make([]byte, size)
+	data[0] = 2<<6 | fmtType
+	data[1] = pt
+	binary.BigEndian.PutUint16(data[2:4], uint16(size/4-1))
+	binary.BigEndian.PutUint32(data[4:8], f.senderSSRC)
+	binary.BigEndian.PutUint32(data[8:12], f.mediaSSRC)
+	off := rtcpFeedbackSize
+	for _, item := range items {
+		binary.BigEndian.PutUint16(data[off:], item[0])
+		binary.BigEndian.PutUint16(data[off+2:], item[1])
+		off += rtcpNACKItemSize
+	}
+	return data
+}
+
+// parseRTCPFeedback decodes the Generic NACK and PLI packets of a compound
+// RTCP packet, skipping other RTCP packet types.
+func parseRTCPFeedback(data []byte) ([]rtcpFeedback, error) {
+	pkts, err := splitRTCP(data)
+	if err != nil {
+		return nil, err
+	}
+	var feedback []rtcpFeedback
+	for _, pkt := range pkts {
+		fmtType, pt := pkt[0]&0x1F, pkt[1]
+		nack := pt == rtcpTypeRTPFB && fmtType == rtcpFmtNACK
+		pli := pt == rtcpTypePSFB && fmtType == rtcpFmtPLI
+		if !nack && !pli {
+			continue
+		}
+		if len(pkt) < rtcpFeedbackSize {
+			return nil, fmt.Errorf("%w: feedback packet too short", ErrInvalidRTCPPacket)
+		}
+		f := rtcpFeedback{
+			pli:        pli,
+			senderSSRC: binary.BigEndian.Uint32(pkt[4:8]),
+			mediaSSRC:  binary.BigEndian.Uint32(pkt[8:12]),
+		}
+		for off := rtcpFeedbackSize; nack && off+rtcpNACKItemSize <= len(pkt); off += rtcpNACKItemSize {
+			pid := binary.BigEndian.Uint16(pkt[off:])
+			blp := binary.BigEndian.Uint16(pkt[off+2:])
+			f.lost = append(f.lost, pid)
+			for i := uint16(0); i < 16; i++ {
+				if blp&(1<<i) != 0 {
+					f.lost = append(f.lost, pid+i+1)
+				}
+			}
+		}
+		feedback = append(feedback, f)
+	}
+	return feedback, nil
+}
+
+// isRTCPPacket reports whether a packet received on an RTP packet type is
+// RTCP. RFC 5761 reserves the second byte values 192-223 for RTCP.
+func isRTCPPacket(data []byte) bool {
+	return len(data) >= rtcpHeaderSize && data[0]>>6 == 2 && data[1] >= 192 && data[1] <= 223
+}
+
+// toNTP converts t to a 64-bit NTP timestamp.
+func toNTP(t time.Time) uint64 {
+	secs := uint64(t.Unix() + ntpEpochOffset)
+	frac := uint64(t.Nanosecond()) << 32 / 1e9
+	return secs<<32 | frac
+}
+
+// ntpMiddle returns the middle 32 bits of an NTP timestamp, the compact form
+// used by the LSR and DLSR fields.
+func ntpMiddle(ntp uint64) uint32 {
+	return uint32(ntp >> 16)
+}
+
+// rtcpSource holds receive statistics for one remote SSRC, following RFC
+// 3550 appendix A.
+type rtcpSource struct {
+	clockRate     uint32
+	initialized   bool
+	baseSeq       uint16
+	maxSeq        uint16
+	cycles        uint32
+	received      uint32
+	expectedPrior uint32
+	receivedPrior uint32
+	transit       int64
+	jitter        float64 // timestamp units
+	lastSR        uint32
+	lastSRArrival time.Time
+}
+
+// update records an RTP packet with the given sequence number and
+// timestamp, arriving at arrival (measured from a fixed reference).
+func (src *rtcpSource) update(seq uint16, timestamp uint32, arrival time.Duration) {
+	if !src.initialized {
+		src.initialized = true
+		src.baseSeq = seq
+		src.maxSeq = seq
+	} else if delta := seq - src.maxSeq; delta > 0 && delta < 0x8000 {
+		if seq < src.maxSeq {
+			src.cycles += 1 << 16
+		}
+		src.maxSeq = seq
+	}
+	src.received++
+
+	arrivalTS := int64(arrival) * int64(src.clockRate) / int64(time.Second)
+	transit := arrivalTS - int64(timestamp)
+	if src.received > 1 {
+		d := math.Abs(float64(transit - src.transit))
+		src.jitter += (d - src.jitter) / 16
+	}
+	src.transit = transit
+}
+
+// reportBlock builds the reception report for ssrc and starts a new
+// reporting interval.
+func (src *rtcpSource) reportBlock(ssrc uint32, now time.Time) rtcpReportBlock {
+	extended := src.cycles + uint32(src.maxSeq)
+	expected := extended - uint32(src.baseSeq) + 1
+	lost := int64(expected) - int64(src.received)
+	if lost > 0x7FFFFF {
+		lost = 0x7FFFFF
+	} else if lost < -0x800000 {
+		lost = -0x800000
+	}
+
+	expectedInterval := expected - src.expectedPrior
+	receivedInterval := src.received - src.receivedPrior
+	src.expectedPrior = expected
+	src.receivedPrior = src.received
+	var fraction uint8
+	if lostInterval := int64(expectedInterval) - int64(receivedInterval); expectedInterval > 0 && lostInterval > 0 {
+		fraction = uint8(lostInterval << 8 / int64(expectedInterval))
+	}
+
+	block := rtcpReportBlock{
+		ssrc:           ssrc,
+		fractionLost:   fraction,
+		cumulativeLost: int32(lost),
+		highestSeq:     extended,
+		jitter:         uint32(src.jitter),
+		lastSR:         src.lastSR,
+	}
+	if src.lastSR != 0 {
+		block.delaySinceSR = uint32(now.Sub(src.lastSRArrival) * 65536 / time.Second)
+	}
+	return block
+}
+
+// SetRTCPMode selects whether RTCP reports are multiplexed with audio RTP
+// or sent on the dedicated PacketAVRTCP packet type. Incoming RTCP is
+// accepted in either form.
+func (s *Session) SetRTCPMode(mode RTCPMode) error {
+	if mode != RTCPMux && mode != RTCPSeparate {
+		return fmt.Errorf("unknown RTCP mode: %v", mode)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rtcpMode = mode
+	return nil
+}
+
+// SetRTCPInterval changes the interval between RTCP reports. It takes
+// effect before the report goroutine starts, which happens when media is
+// first sent or received.
+func (s *Session) SetRTCPInterval(interval time.Duration) error {
+	if interval <= 0 {
+		return fmt.Errorf("RTCP interval must be positive: %v", interval)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rtcpInterval = interval
+	return nil
+}
+
+// startRTCPLocked starts the goroutine that sends periodic reports, once.
+// The caller must hold s.mu.
+func (s *Session) startRTCPLocked() {
+	if s.rtcpStop != nil || s.closed {
+		return
+	}
+	stop := make(chan struct{})
+	s.rtcpStop = stop
+	interval := s.rtcpInterval
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				if err := s.SendRTCPReport(); err != nil {
+					pkgLog.WithFields(logrus.Fields{
+						"function":      "Session.rtcpLoop",
+						"friend_number": s.friendNumber,
+						"error":         err.Error(),
+					}).Warn("Failed to send RTCP report")
+				}
+			}
+		}
+	}()
+}
+
+// trackRTCPSourceLocked updates the receive statistics of the remote SSRC.
+// The caller must hold s.mu.
+func (s *Session) trackRTCPSourceLocked(ssrc uint32, seq uint16, timestamp, clockRate uint32) {
+	src := s.rtcpSourceLocked(ssrc, clockRate)
+	src.update(seq, timestamp, s.timeProvider.Now().Sub(s.created))
+	s.startRTCPLocked()
+}
+
+// rtcpSourceLocked returns the state of ssrc, creating it if needed. The
+// caller must hold s.mu.
+func (s *Session) rtcpSourceLocked(ssrc, clockRate uint32) *rtcpSource {
+	src, ok := s.rtcpSources[ssrc]
+	if !ok {
+		src = &rtcpSource{clockRate: clockRate}
+		s.rtcpSources[ssrc] = src
+	}
+	return src
+}
+
+// SendRTCPReport sends a sender report if audio was sent in the last two
+// report intervals, otherwise a receiver report, with a reception report
+// block for every remote source. Remote video packets lost since the last
+// report are NACKed in the same compound packet. It is called periodically
+// once media flows and may be called directly.
+func (s *Session) SendRTCPReport() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return fmt.Errorf("session closed")
+	}
+	now := s.timeProvider.Now()
+	report := rtcpReport{ssrc: s.audioSSRC}
+	if !s.txLastSend.IsZero() && now.Sub(s.txLastSend) < 2*s.rtcpInterval {
+		report.sender = true
+		report.ntpTime = toNTP(now)
+		report.rtpTime = s.txLastRTPTime + uint32(now.Sub(s.txLastSend)*audioClockRate/time.Second)
+		report.packetCount = s.txPackets
+		report.octetCount = s.txOctets
+	}
+	for ssrc, src := range s.rtcpSources {
+		if !src.initialized || len(report.blocks) == rtcpMaxBlocks {
+			continue
+		}
+		report.blocks = append(report.blocks, src.reportBlock(ssrc, now))
+	}
+	packetType := transport.PacketAVAudioFrame
+	if s.rtcpMode == RTCPSeparate {
+		packetType = transport.PacketAVRTCP
+	}
+	nack := s.takeNACKLocked()
+	tr, addr := s.transport, s.remoteAddr
+	s.mu.Unlock()
+
+	data := append(report.marshal(), nack...)
+	if err := tr.Send(&transport.Packet{PacketType: packetType, Data: data}, addr); err != nil {
+		return fmt.Errorf("failed to send RTCP report: %w", err)
+	}
+
+	pkgLog.WithFields(logrus.Fields{
+		"function":      "Session.SendRTCPReport",
+		"friend_number": s.friendNumber,
+		"sender_report": report.sender,
+		"report_blocks": len(report.blocks),
+		"nack":          len(nack) > 0,
+	}).Debug("Sent RTCP report")
+	return nil
+}
+
+// ReceiveRTCP processes an incoming RTCP compound packet. Sender reports
+// are remembered for the LSR and DLSR fields of our next report; report
+// blocks about our audio stream update RTT, FractionLost and
+// CumulativeLost in the session statistics. Video packets NACKed by the
+// peer are retransmitted from the retransmit buffer; a PLI, or a NACK for
+// packets no longer buffered, invokes the handler set with
+// SetKeyframeRequestHandler.
+func (s *Session) ReceiveRTCP(data []byte) error {
+	reports, err := parseRTCPReports(data)
+	if err != nil {
+		return err
+	}
+	feedback, err := parseRTCPFeedback(data)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	now := s.timeProvider.Now()
+	for _, r := range reports {
+		if r.sender {
+			src := s.rtcpSourceLocked(r.ssrc, audioClockRate)
+			src.lastSR = ntpMiddle(r.ntpTime)
+			src.lastSRArrival = now
+		}
+		for _, b := range r.blocks {
+			if b.ssrc != s.audioSSRC {
+				continue
+			}
+			s.applyReportBlockLocked(b, now)
+		}
+	}
+	keyframeWanted := false
+	var retransmits [][]byte
+	for _, f := range feedback {
+		if f.mediaSSRC != s.videoSSRC {
+			continue
+		}
+		if f.pli {
+			keyframeWanted = true
+			continue
+		}
+		packets, missed := s.retransmitLocked(f.lost, now)
+		retransmits = append(retransmits, packets...)
+		keyframeWanted = keyframeWanted || missed
+	}
+	handler := s.keyframeHandler
+	tr, addr := s.transport, s.remoteAddr
+	s.mu.Unlock()
+
+	for _, data := range retransmits {
+		if err := tr.Send(&transport.Packet{PacketType: transport.PacketAVVideoFrame, Data: data}, addr); err != nil {
+			return fmt.Errorf("failed to retransmit video packet: %w", err)
+		}
+	}
+	// The handler runs without the lock so it may call back into the session.
+	if keyframeWanted && handler != nil {
+		handler()
+	}
+	return nil
+}
+
+// SetKeyframeRequestHandler sets the function called when the peer sends a
+// Picture Loss Indication for our video stream, or NACKs video packets that
+// can no longer be retransmitted. The handler should
+// make the video encoder emit a key frame. Pass nil to remove it.
+func (s *Session) SetKeyframeRequestHandler(handler func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keyframeHandler = handler
+}
+
+// SendPictureLossIndication asks the peer for a video key frame by sending
+// an RFC 4585 PLI about the remote video source. It fails if no video has
+// been received yet, since the remote video SSRC is still unknown.
+func (s *Session) SendPictureLossIndication() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return fmt.Errorf("session closed")
+	}
+	mediaSSRC, ok := s.remoteVideoSSRCLocked()
+	if !ok {
+		s.mu.Unlock()
+		return fmt.Errorf("no remote video source")
+	}
+	pli := rtcpFeedback{pli: true, senderSSRC: s.videoSSRC, mediaSSRC: mediaSSRC}
+	packetType := transport.PacketAVAudioFrame
+	if s.rtcpMode == RTCPSeparate {
+		packetType = transport.PacketAVRTCP
+	}
+	tr, addr := s.transport, s.remoteAddr
+	s.mu.Unlock()
+
+	if err := tr.Send(&transport.Packet{PacketType: packetType, Data: pli.marshal()}, addr); err != nil {
+		return fmt.Errorf("failed to send PLI: %w", err)
+	}
+	return nil
+}
+
+// remoteVideoSSRCLocked returns the SSRC of the remote video source. The
+// caller must hold s.mu.
+func (s *Session) remoteVideoSSRCLocked() (uint32, bool) {
+	for ssrc, src := range s.rtcpSources {
+		if src.clockRate == videoClockRate && src.initialized {
+			return ssrc, true
+		}
+	}
+	return 0, false
+}
+
+// applyReportBlockLocked updates the statistics from a peer's report on our
+// audio stream. The caller must hold s.mu.
+func (s *Session) applyReportBlockLocked(b rtcpReportBlock, now time.Time) {
+	s.stats.FractionLost = float64(b.fractionLost) / 256
+	s.stats.CumulativeLost = int64(b.cumulativeLost)
+	if b.lastSR == 0 {
+		return
+	}
+	// RTT = A - LSR - DLSR in units of 1/65536 s (RFC 3550 section 6.4.1)
+	rtt := ntpMiddle(toNTP(now)) - b.lastSR - b.delaySinceSR
+	if rtt < 1<<31 {
+		s.stats.RTT = time.Duration(rtt) * time.Second / 65536
+	}
+}
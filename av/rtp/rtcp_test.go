@@ -0,0 +1,331 @@
+package rtp
+
+import (
+	"net"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/opd-ai/toxcore/transport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// loopbackTransport queues packets for a peer Session until flush is called,
+// so tests control the network delay with the mock clock.
+type loopbackTransport struct {
+	mu      sync.Mutex
+	peer    *Session
+	pending []*transport.Packet
+	sent    []*transport.Packet
+	drop    func(n int) bool // Drops the nth audio packet when it returns true
+	audio   int
+
+	dropVideo func(n int) bool // Drops the nth video packet when it returns true
+	video     int
+	frames    [][]byte // Video frames completed by the peer
+}
+
+func (lt *loopbackTransport) Send(packet *transport.Packet, addr net.Addr) error {
+	lt.mu.Lock()
+	defer lt.mu.Unlock()
+	lt.sent = append(lt.sent, packet)
+	if packet.PacketType == transport.PacketAVAudioFrame && !isRTCPPacket(packet.Data) {
+		lt.audio++
+		if lt.drop != nil && lt.drop(lt.audio-1) {
+			return nil
+		}
+	}
+	if packet.PacketType == transport.PacketAVVideoFrame {
+		lt.video++
+		if lt.dropVideo != nil && lt.dropVideo(lt.video-1) {
+			return nil
+		}
+	}
+	lt.pending = append(lt.pending, packet)
+	return nil
+}
+
+func (lt *loopbackTransport) Close() error                                                  { return nil }
+func (lt *loopbackTransport) LocalAddr() net.Addr                                           { return &net.UDPAddr{} }
+func (lt *loopbackTransport) RegisterHandler(transport.PacketType, transport.PacketHandler) {}
+func (lt *loopbackTransport) IsConnectionOriented() bool                                    { return false }
+
+// flush delivers the queued packets to the peer the way TransportIntegration
+// routes them.
+func (lt *loopbackTransport) flush(t *testing.T) {
+	t.Helper()
+	lt.mu.Lock()
+	pending := lt.pending
+	lt.pending = nil
+	lt.mu.Unlock()
+
+	for _, p := range pending {
+		var err error
+		switch p.PacketType {
+		case transport.PacketAVAudioFrame:
+			_, _, err = lt.peer.ReceivePacket(p.Data)
+		case transport.PacketAVVideoFrame:
+			var frame []byte
+			frame, _, err = lt.peer.ReceiveVideoPacket(p.Data)
+			if frame != nil {
+				lt.frames = append(lt.frames, frame)
+			}
+		case transport.PacketAVRTCP:
+			err = lt.peer.ReceiveRTCP(p.Data)
+		}
+		require.NoError(t, err)
+	}
+}
+
+// sentRTCP returns the RTCP packets sent so far.
+func (lt *loopbackTransport) sentRTCP() []*transport.Packet {
+	lt.mu.Lock()
+	defer lt.mu.Unlock()
+	var out []*transport.Packet
+	for _, p := range lt.sent {
+		if p.PacketType == transport.PacketAVRTCP || isRTCPPacket(p.Data) {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// newLoopbackPair connects two sessions sharing a mock clock.
+func newLoopbackPair(t *testing.T) (a, b *Session, ab, ba *loopbackTransport, clock *MockTimeProvider) {
+	t.Helper()
+	clock = &MockTimeProvider{currentTime: time.Unix(1700000000, 0)}
+	ab, ba = &loopbackTransport{}, &loopbackTransport{}
+	addr := &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 33445}
+
+	var err error
+	a, err = NewSessionWithProviders(1, ab, addr, clock, &MockSSRCProvider{ssrcValues: []uint32{0xA0, 0xA1}})
+	require.NoError(t, err)
+	b, err = NewSessionWithProviders(2, ba, addr, clock, &MockSSRCProvider{ssrcValues: []uint32{0xB0, 0xB1}})
+	require.NoError(t, err)
+	ab.peer, ba.peer = b, a
+	t.Cleanup(func() {
+		a.Close()
+		b.Close()
+	})
+	return a, b, ab, ba, clock
+}
+
+// sendAudio sends n 20ms audio frames from s, advancing the clock for each.
+func sendAudio(t *testing.T, s *Session, lt *loopbackTransport, clock *MockTimeProvider, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		require.NoError(t, s.SendAudioPacket([]byte{1, 2, 3, 4}, 960))
+		clock.Advance(20 * time.Millisecond)
+		lt.flush(t)
+	}
+}
+
+func TestRTCPReport_MarshalRoundTrip(t *testing.T) {
+	r := rtcpReport{
+		sender:      true,
+		ssrc:        0x01020304,
+		ntpTime:     toNTP(time.Unix(1700000000, 500000000)),
+		rtpTime:     48000,
+		packetCount: 50,
+		octetCount:  4000,
+		blocks: []rtcpReportBlock{
+			{ssrc: 7, fractionLost: 25, cumulativeLost: 10, highestSeq: 1<<16 + 5, jitter: 120, lastSR: 0xABCD, delaySinceSR: 65536},
+			{ssrc: 8, cumulativeLost: -3}, // Duplicates make loss negative
+		},
+	}
+	data := r.marshal()
+	assert.Len(t, data, rtcpHeaderSize+rtcpSenderInfoSize+2*rtcpBlockSize)
+	assert.True(t, isRTCPPacket(data))
+
+	// A compound packet carrying a following receiver report
+	rr := rtcpReport{ssrc: 9}
+	reports, err := parseRTCPReports(append(data, rr.marshal()...))
+	require.NoError(t, err)
+	require.Len(t, reports, 2)
+	assert.Equal(t, r, reports[0])
+	assert.Equal(t, rr, reports[1])
+
+	_, err = parseRTCPReports(data[:len(data)-4])
+	assert.ErrorIs(t, err, ErrInvalidRTCPPacket)
+	assert.Equal(t, uint32(0x00018000), ntpMiddle(toNTP(time.Unix(1-ntpEpochOffset, 500000000))), "1.5s after the NTP epoch")
+}
+
+func TestSession_RTCPSenderAndReceiverReports(t *testing.T) {
+	a, b, ab, ba, clock := newLoopbackPair(t)
+	sendAudio(t, a, ab, clock, 50)
+
+	require.NoError(t, a.SendRTCPReport())
+	require.NoError(t, b.SendRTCPReport())
+
+	// A sends media, so it sends a sender report
+	sent := ab.sentRTCP()
+	require.Len(t, sent, 1)
+	assert.Equal(t, transport.PacketAVAudioFrame, sent[0].PacketType, "RTCP is multiplexed by default")
+	reports, err := parseRTCPReports(sent[0].Data)
+	require.NoError(t, err)
+	require.Len(t, reports, 1)
+	sr := reports[0]
+	assert.True(t, sr.sender)
+	assert.Equal(t, uint32(0xA0), sr.ssrc)
+	assert.Equal(t, uint32(50), sr.packetCount)
+	assert.Equal(t, uint32(200), sr.octetCount)
+	assert.Equal(t, toNTP(clock.Now()), sr.ntpTime)
+	_, next := a.GetAudioStreamState()
+	assert.Equal(t, next, sr.rtpTime, "RTP timestamp advances with the clock since the last packet")
+	assert.Empty(t, sr.blocks)
+
+	// B only receives, so it sends a receiver report about A's stream
+	sent = ba.sentRTCP()
+	require.Len(t, sent, 1)
+	reports, err = parseRTCPReports(sent[0].Data)
+	require.NoError(t, err)
+	rr := reports[0]
+	assert.False(t, rr.sender)
+	assert.Equal(t, uint32(0xB0), rr.ssrc)
+	require.Len(t, rr.blocks, 1)
+	assert.Equal(t, uint32(0xA0), rr.blocks[0].ssrc)
+	assert.Zero(t, rr.blocks[0].cumulativeLost)
+	assert.Equal(t, uint32(49), rr.blocks[0].highestSeq-uint32(a.audioPacketizer.sequenceNumber-50))
+
+	// A sender that has gone quiet falls back to receiver reports
+	clock.Advance(2 * DefaultRTCPInterval)
+	require.NoError(t, a.SendRTCPReport())
+	reports, err = parseRTCPReports(ab.sentRTCP()[1].Data)
+	require.NoError(t, err)
+	assert.False(t, reports[0].sender)
+}
+
+func TestSession_RTCPRoundTripTime(t *testing.T) {
+	a, b, ab, ba, clock := newLoopbackPair(t)
+	sendAudio(t, a, ab, clock, 10)
+
+	// SR takes 25ms each way and B holds it for 100ms before replying
+	require.NoError(t, a.SendRTCPReport())
+	clock.Advance(25 * time.Millisecond)
+	ab.flush(t)
+	clock.Advance(100 * time.Millisecond)
+	require.NoError(t, b.SendRTCPReport())
+	clock.Advance(25 * time.Millisecond)
+	ba.flush(t)
+
+	stats := a.GetStatistics()
+	assert.InDelta(t, float64(50*time.Millisecond), float64(stats.RTT), float64(time.Millisecond))
+}
+
+func TestSession_RTCPLossReporting(t *testing.T) {
+	a, b, ab, ba, clock := newLoopbackPair(t)
+	ab.drop = func(n int) bool { return n%10 == 5 }
+	sendAudio(t, a, ab, clock, 100)
+
+	require.NoError(t, b.SendRTCPReport())
+	ba.flush(t)
+
+	stats := a.GetStatistics()
+	assert.Equal(t, int64(10), stats.CumulativeLost)
+	assert.InDelta(t, 0.1, stats.FractionLost, 0.01)
+
+	// The fraction covers only the interval since the previous report
+	ab.drop = nil
+	sendAudio(t, a, ab, clock, 50)
+	require.NoError(t, b.SendRTCPReport())
+	ba.flush(t)
+
+	stats = a.GetStatistics()
+	assert.Equal(t, int64(10), stats.CumulativeLost)
+	assert.Zero(t, stats.FractionLost)
+}
+
+func TestSession_RTCPSeparateMode(t *testing.T) {
+	a, b, ab, ba, clock := newLoopbackPair(t)
+	require.NoError(t, b.SetRTCPMode(RTCPSeparate))
+	assert.Error(t, b.SetRTCPMode(RTCPMode(7)))
+	ab.drop = func(n int) bool { return n == 3 }
+	sendAudio(t, a, ab, clock, 10)
+
+	require.NoError(t, b.SendRTCPReport())
+	sent := ba.sentRTCP()
+	require.Len(t, sent, 1)
+	assert.Equal(t, transport.PacketAVRTCP, sent[0].PacketType)
+	ba.flush(t)
+	assert.Equal(t, int64(1), a.GetStatistics().CumulativeLost)
+
+	// Multiplexed RTCP is not handed to the audio path
+	data, mediaType, err := a.ReceivePacket(sent[0].Data)
+	require.NoError(t, err)
+	assert.Equal(t, "rtcp", mediaType)
+	assert.Nil(t, data)
+}
+
+func TestSession_RTCPPeriodicReports(t *testing.T) {
+	a, _, ab, _, _ := newLoopbackPair(t)
+	assert.Error(t, a.SetRTCPInterval(0))
+	require.NoError(t, a.SetRTCPInterval(10*time.Millisecond))
+
+	// No reports are sent before any media flows
+	time.Sleep(30 * time.Millisecond)
+	assert.Empty(t, ab.sentRTCP())
+
+	require.NoError(t, a.SendAudioPacket([]byte{1}, 960))
+	ab.flush(t)
+	assert.Eventually(t, func() bool { return len(ab.sentRTCP()) >= 2 }, time.Second, 5*time.Millisecond)
+
+	require.NoError(t, a.Close())
+	time.Sleep(20 * time.Millisecond) // Let an in-flight report finish
+	count := len(ab.sentRTCP())
+	time.Sleep(30 * time.Millisecond)
+	assert.Equal(t, count, len(ab.sentRTCP()), "Close stops the report goroutine")
+	assert.Error(t, a.SendRTCPReport())
+}
+
+func TestRTCPFeedback_MarshalRoundTrip(t *testing.T) {
+	nack := rtcpFeedback{senderSSRC: 1, mediaSSRC: 2, lost: []uint16{100, 101, 116, 117, 65535, 3}}
+	pli := rtcpFeedback{pli: true, senderSSRC: 3, mediaSSRC: 4}
+	report := rtcpReport{ssrc: 9}
+	compound := append(append(report.marshal(), nack.marshal()...), pli.marshal()...)
+
+	// 100 and 116 share a PID/BLP pair; 117 and 65535 start new ones
+	assert.Len(t, nack.marshal(), rtcpFeedbackSize+3*rtcpNACKItemSize)
+
+	feedback, err := parseRTCPFeedback(compound)
+	require.NoError(t, err)
+	require.Len(t, feedback, 2)
+	assert.Equal(t, nack, feedback[0])
+	assert.Equal(t, pli, feedback[1])
+
+	// Reports are still found alongside feedback
+	reports, err := parseRTCPReports(compound)
+	require.NoError(t, err)
+	require.Len(t, reports, 1)
+
+	short := pli.marshal()[:8]
+	short[3] = 1 // Length of two words
+	_, err = parseRTCPFeedback(short)
+	assert.ErrorIs(t, err, ErrInvalidRTCPPacket)
+}
+
+func TestSession_KeyframeRequests(t *testing.T) {
+	a, b, ab, ba, _ := newLoopbackPair(t)
+	var requests atomic.Int32
+	a.SetKeyframeRequestHandler(func() { requests.Add(1) })
+
+	// A PLI needs the remote video SSRC, learned from received video
+	assert.Error(t, b.SendPictureLossIndication())
+	require.NoError(t, a.SendVideoPacket([]byte{1, 2, 3, 4}))
+	ab.flush(t)
+
+	require.NoError(t, b.SendPictureLossIndication())
+	ba.flush(t)
+	assert.Equal(t, int32(1), requests.Load())
+
+	// A NACK for a packet that cannot be retransmitted also requests one
+	nack := rtcpFeedback{senderSSRC: 0xB1, mediaSSRC: 0xA1, lost: []uint16{500}}
+	require.NoError(t, a.ReceiveRTCP(nack.marshal()))
+	assert.Equal(t, int32(2), requests.Load())
+
+	// Feedback about another source is ignored
+	other := rtcpFeedback{pli: true, senderSSRC: 0xB1, mediaSSRC: 0xA0}
+	require.NoError(t, a.ReceiveRTCP(other.marshal()))
+	assert.Equal(t, int32(2), requests.Load())
+}
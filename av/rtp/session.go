@@ -72,6 +72,16 @@ type Session struct {
 	timeProvider TimeProvider
 	ssrcProvider SSRCProvider
 
+	// RFC 8285 header extensions registered by ID
+	extensions       map[uint8]string
+	extensionHandler ExtensionHandler
+
+	// RFC 5109 video FEC state
+	fecEncoder   *video.FECEncoder
+	fecGroupSize int                    // 0 = disabled
+	fecPending   []*rtp.Packet          // Sent packets in the current group
+	fecRecent    map[uint16]*rtp.Packet // Received packets available for recovery
+
 	// Statistics tracking
 	stats Statistics
 
@@ -83,6 +93,27 @@ type Session struct {
 	rxJitterUs  float64 // EWMA of |interval - mean|, microseconds
 	rxBandBytes uint64  // bytes received in current bandwidth window
 	rxBandStart time.Time
+
+	// RFC 3550 RTCP state
+	rtcpMode      RTCPMode
+	rtcpInterval  time.Duration
+	rtcpSources   map[uint32]*rtcpSource // Remote sources by SSRC
+	rtcpStop      chan struct{}          // Closed to stop the report goroutine
+	closed        bool
+	txPackets     uint32 // Audio packets sent, for sender reports
+	txOctets      uint32 // Audio payload octets sent
+	txLastRTPTime uint32 // RTP timestamp of the last audio packet sent
+	txLastSend    time.Time
+
+	// Called on a NACK or PLI about our video stream
+	keyframeHandler func()
+
+	// RFC 4585 NACK state: sent video packets kept for retransmission, and
+	// lost remote video packets to report in the next RTCP report
+	retransmitBuffer *RTPRetransmitBuffer
+	maxRetransmitAge time.Duration
+	nackPending      []uint16
+	nackSSRC         uint32
 }
 
 // NewSession creates a new RTP session for a friend.
@@ -125,7 +156,7 @@ func NewSessionWithProviders(friendNumber uint32, transport transport.Transport,
 	timeProvider = ensureTimeProvider(timeProvider)
 	ssrcProvider = ensureSSRCProvider(ssrcProvider)
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":      "NewSession",
 		"friend_number": friendNumber,
 		"remote_addr":   remoteAddr.String(),
@@ -144,7 +175,7 @@ func NewSessionWithProviders(friendNumber uint32, transport transport.Transport,
 	session := buildSession(friendNumber, videoSSRC, audioPacketizer, audioDepacketizer,
 		videoPacketizer, videoDepacketizer, transport, remoteAddr, timeProvider, ssrcProvider)
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":        "NewSession",
 		"friend_number":   friendNumber,
 		"session_created": session.created,
@@ -156,14 +187,14 @@ func NewSessionWithProviders(friendNumber uint32, transport transport.Transport,
 // validateSessionParameters validates required session parameters.
 func validateSessionParameters(transport transport.Transport, remoteAddr net.Addr) error {
 	if transport == nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "NewSession",
 			"error":    "transport cannot be nil",
 		}).Error("Invalid transport")
 		return fmt.Errorf("transport cannot be nil")
 	}
 	if remoteAddr == nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "NewSession",
 			"error":    "remote address cannot be nil",
 		}).Error("Invalid remote address")
@@ -192,7 +223,7 @@ func ensureSSRCProvider(sp SSRCProvider) SSRCProvider {
 func createAudioComponents(transport transport.Transport, remoteAddr net.Addr, ssrcProvider SSRCProvider) (*AudioPacketizer, *AudioDepacketizer, error) {
 	audioPacketizer, err := NewAudioPacketizerWithSSRCProvider(48000, transport, remoteAddr, ssrcProvider)
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "NewSession",
 			"error":    err.Error(),
 		}).Error("Failed to create audio packetizer")
@@ -207,7 +238,7 @@ func createAudioComponents(transport transport.Transport, remoteAddr net.Addr, s
 func createVideoComponents(ssrcProvider SSRCProvider) (*video.RTPPacketizer, *video.RTPDepacketizer, uint32, error) {
 	videoSSRC, err := ssrcProvider.GenerateSSRC()
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "NewSession",
 			"error":    err.Error(),
 		}).Error("Failed to generate video SSRC")
@@ -228,6 +259,7 @@ func buildSession(friendNumber, videoSSRC uint32, audioPacketizer *AudioPacketiz
 	now := timeProvider.Now()
 	return &Session{
 		friendNumber:      friendNumber,
+		audioSSRC:         audioPacketizer.ssrc,
 		videoSSRC:         videoSSRC,
 		created:           now,
 		audioConfig:       DefaultAudioConfig(),
@@ -243,6 +275,10 @@ func buildSession(friendNumber, videoSSRC uint32, audioPacketizer *AudioPacketiz
 		stats: Statistics{
 			StartTime: now,
 		},
+		rtcpInterval:     DefaultRTCPInterval,
+		rtcpSources:      make(map[uint32]*rtcpSource),
+		retransmitBuffer: newRetransmitBuffer(DefaultRetransmitBufferSize),
+		maxRetransmitAge: DefaultMaxRetransmitAge,
 	}
 }
 
@@ -278,6 +314,28 @@ func (s *Session) SetAudioConfig(config AudioConfig) {
 	s.audioConfig = config
 }
 
+// GetAudioStreamState returns the RTP sequence number and timestamp of the
+// next outgoing audio packet.
+func (s *Session) GetAudioStreamState() (sequenceNumber uint16, timestamp uint32) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.audioPacketizer == nil {
+		return 0, 0
+	}
+	return s.audioPacketizer.GetStreamState()
+}
+
+// SetAudioStreamState continues the outgoing audio stream from the given
+// RTP sequence number and timestamp, as captured by GetAudioStreamState on
+// another session.
+func (s *Session) SetAudioStreamState(sequenceNumber uint16, timestamp uint32) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.audioPacketizer != nil {
+		s.audioPacketizer.SetStreamState(sequenceNumber, timestamp)
+	}
+}
+
 // SendAudioPacket sends an RTP audio packet.
 //
 // This method takes encoded audio data, wraps it in RTP packets
@@ -307,6 +365,13 @@ func (s *Session) SendAudioPacket(data []byte, sampleCount uint32) error {
 	s.stats.PacketsSent++
 	s.stats.BytesSent += uint64(len(data))
 
+	_, next := s.audioPacketizer.GetStreamState()
+	s.txPackets++
+	s.txOctets += uint32(len(data))
+	s.txLastRTPTime = next - sampleCount
+	s.txLastSend = s.timeProvider.Now()
+	s.startRTCPLocked()
+
 	return nil
 }
 
@@ -363,8 +428,10 @@ func (s *Session) calculateVideoTimestamp() uint32 {
 	return uint32(elapsed.Milliseconds() * 90)
 }
 
-// sendVideoRTPPackets transmits each RTP packet over the transport.
+// sendVideoRTPPackets transmits each RTP packet over the transport and
+// keeps it for NACK retransmission.
 func (s *Session) sendVideoRTPPackets(rtpPackets []video.RTPPacket) error {
+	now := s.timeProvider.Now()
 	for _, rtpPacket := range rtpPackets {
 		packetData := serializeVideoRTPPacket(rtpPacket)
 
@@ -376,6 +443,10 @@ func (s *Session) sendVideoRTPPackets(rtpPackets []video.RTPPacket) error {
 		if err := s.transport.Send(toxPacket, s.remoteAddr); err != nil {
 			return fmt.Errorf("failed to send video packet: %w", err)
 		}
+		s.retransmitBuffer.Store(rtpPacket.SequenceNumber, packetData, now)
+		if err := s.protectVideoPacket(packetData); err != nil {
+			return err
+		}
 	}
 	return nil
 }
@@ -399,37 +470,60 @@ func (s *Session) incrementVideoPictureID() {
 // This method parses RTP packets and extracts audio/video
 // data for decoding and playback using the session's
 // depacketizers. Currently supports audio packets only;
-// use ReceiveVideoPacket for video packets.
+// use ReceiveVideoPacket for video packets. If an
+// ExtensionHandler is set, it receives the audio together
+// with any registered header extensions.
 //
 // Parameters:
 //   - packet: Raw RTP packet data
 //
 // Returns:
 //   - []byte: Extracted media data
-//   - string: Media type ("audio")
+//   - string: Media type ("audio", or "rtcp" for multiplexed RTCP)
 //   - error: Any error that occurred during processing
 func (s *Session) ReceivePacket(packet []byte) ([]byte, string, error) {
+	// RTCP multiplexed with audio RTP is told apart by its payload type
+	if isRTCPPacket(packet) {
+		return nil, "rtcp", s.ReceiveRTCP(packet)
+	}
+
+	audioData, extensions, handler, err := s.receiveAudioPacket(packet)
+	if err != nil {
+		return nil, "", err
+	}
+	if handler != nil {
+		handler(audioData, extensions)
+	}
+	return audioData, "audio", nil
+}
+
+// receiveAudioPacket depacketizes packet under the session lock and returns
+// the extension handler to invoke once the lock is released.
+func (s *Session) receiveAudioPacket(packet []byte) ([]byte, []HeaderExtension, ExtensionHandler, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	if len(packet) == 0 {
-		return nil, "", fmt.Errorf("packet cannot be empty")
+		return nil, nil, nil, fmt.Errorf("packet cannot be empty")
 	}
 
 	if s.audioDepacketizer == nil {
-		return nil, "", fmt.Errorf("audio depacketizer not initialized")
+		return nil, nil, nil, fmt.Errorf("audio depacketizer not initialized")
 	}
 
 	// Parse the RTP header to extract sequence number for stats tracking.
 	var rtpPkt rtp.Packet
+	var extensions []HeaderExtension
 	if err := rtpPkt.Unmarshal(packet); err == nil {
 		s.updateRxStats(rtpPkt.SequenceNumber, len(rtpPkt.Payload))
+		s.trackRTCPSourceLocked(rtpPkt.SSRC, rtpPkt.SequenceNumber, rtpPkt.Timestamp, audioClockRate)
+		extensions = s.registeredExtensions(&rtpPkt.Header)
 	}
 
 	// Process as audio packet
 	audioData, timestamp, err := s.audioDepacketizer.ProcessPacket(packet)
 	if err != nil {
-		return nil, "", fmt.Errorf("failed to process audio packet: %w", err)
+		return nil, nil, nil, fmt.Errorf("failed to process audio packet: %w", err)
 	}
 
 	// Update statistics
@@ -439,14 +533,16 @@ func (s *Session) ReceivePacket(packet []byte) ([]byte, string, error) {
 	// per-packet timing information, but Session-level jitter handling is performed by
 	// the JitterBuffer internally. Suppressing "declared and not used" error explicitly.
 	_ = timestamp
-	return audioData, "audio", nil
+	return audioData, extensions, s.extensionHandler, nil
 }
 
 // ReceiveVideoPacket processes an incoming video RTP packet.
 //
 // This method parses video RTP packets and extracts VP8 frame
 // data using the session's video depacketizer, reassembling
-// fragmented frames as needed.
+// fragmented frames as needed. FEC parity packets are used to
+// rebuild a single lost packet of their group. Sequence gaps are
+// NACKed in the next RTCP report so the sender can retransmit.
 //
 // Parameters:
 //   - packet: Raw RTP packet data
@@ -467,6 +563,21 @@ func (s *Session) ReceiveVideoPacket(packet []byte) ([]byte, uint16, error) {
 		return nil, 0, fmt.Errorf("video depacketizer not initialized")
 	}
 
+	// Parity packets are consumed here; a recovered packet continues
+	// through the normal path in place of the parity packet.
+	if isFECPacket(packet) {
+		recovered, err := s.recoverFromFEC(packet)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to process FEC packet: %w", err)
+		}
+		if recovered == nil {
+			return nil, 0, nil
+		}
+		packet = recovered
+	} else {
+		s.rememberVideoPacket(packet)
+	}
+
 	// Deserialize the RTP packet
 	rtpPacket, err := deserializeVideoRTPPacket(packet)
 	if err != nil {
@@ -475,6 +586,8 @@ func (s *Session) ReceiveVideoPacket(packet []byte) ([]byte, uint16, error) {
 
 	// Track RFC 3550 receive stats using parsed sequence number.
 	s.updateRxStats(rtpPacket.SequenceNumber, len(rtpPacket.Payload))
+	s.trackVideoGapLocked(rtpPacket.SSRC, rtpPacket.SequenceNumber)
+	s.trackRTCPSourceLocked(rtpPacket.SSRC, rtpPacket.SequenceNumber, rtpPacket.Timestamp, videoClockRate)
 
 	// Process the packet and attempt frame reassembly
 	frameData, pictureID, err := s.videoDepacketizer.ProcessPacket(rtpPacket)
@@ -501,6 +614,14 @@ type Statistics struct {
 	Jitter          time.Duration
 	Bandwidth       uint64 // bits per second
 	StartTime       time.Time
+
+	// From the peer's RTCP reports on our audio stream
+	RTT            time.Duration // Round-trip time of the last SR/RR exchange
+	FractionLost   float64       // Fraction of packets lost in the last report interval
+	CumulativeLost int64         // Packets lost since the stream began
+
+	// Video packets resent in answer to the peer's NACKs
+	PacketsRetransmitted uint64
 }
 
 // updateRxStats updates RFC 3550 receive statistics for a newly received packet.
@@ -560,6 +681,13 @@ func (s *Session) Close() error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	// Stop RTCP reports
+	s.closed = true
+	if s.rtcpStop != nil {
+		close(s.rtcpStop)
+		s.rtcpStop = nil
+	}
+
 	// Clean up audio resources
 	s.audioPacketizer = nil
 	s.audioDepacketizer = nil
@@ -690,6 +818,7 @@ func deserializeVideoRTPPacket(data []byte) (video.RTPPacket, error) {
 		packet.ExtendedControlBits = (firstByte & 0x80) != 0 // X bit
 		packet.NonReferenceBit = (firstByte & 0x20) != 0     // N bit
 		packet.StartOfPartition = (firstByte & 0x10) != 0    // S bit
+		packet.KeyFrame = video.IsKeyFramePayload(packet.Payload)
 
 		// Extract Picture ID if extended control bits are present (RFC 7741 Section 4.2)
 		// Byte 1 contains: I (1 bit), L (1 bit), T (1 bit), K (1 bit), RSV (4 bits)
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/opd-ai/toxcore/transport"
+	"github.com/pion/rtp"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -574,3 +575,29 @@ func TestSession_RFC3550StatsIgnoreOutOfOrderPackets(t *testing.T) {
 	stats := session.GetStatistics()
 	assert.Equal(t, uint64(1), stats.PacketsLost, "out-of-order packets must not inflate loss accounting")
 }
+
+func TestSession_AudioStreamStateHandover(t *testing.T) {
+	remoteAddr, _ := net.ResolveUDPAddr("udp", "127.0.0.1:54321")
+
+	original, err := NewSession(1, NewMockTransport(), remoteAddr)
+	require.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		require.NoError(t, original.SendAudioPacket([]byte{0x01}, 960))
+	}
+	seq, ts := original.GetAudioStreamState()
+	assert.Equal(t, uint16(3), seq)
+	assert.Equal(t, uint32(2880), ts)
+
+	mockTransport := NewMockTransport()
+	continued, err := NewSession(2, mockTransport, remoteAddr)
+	require.NoError(t, err)
+	continued.SetAudioStreamState(seq, ts)
+	require.NoError(t, continued.SendAudioPacket([]byte{0x01}, 960))
+
+	sent := mockTransport.GetSentPackets()
+	require.Len(t, sent, 1)
+	packet := &rtp.Packet{}
+	require.NoError(t, packet.Unmarshal(sent[0].Packet.Data))
+	assert.Equal(t, seq, packet.SequenceNumber)
+	assert.Equal(t, ts, packet.Timestamp)
+}
@@ -55,12 +55,12 @@ type TransportIntegration struct {
 //   - *TransportIntegration: New integration instance
 //   - error: Any error that occurred during setup
 func NewTransportIntegration(transport transport.Transport) (*TransportIntegration, error) {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "NewTransportIntegration",
 	}).Info("Creating new RTP transport integration")
 
 	if transport == nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "NewTransportIntegration",
 			"error":    "transport cannot be nil",
 		}).Error("Invalid transport")
@@ -77,7 +77,7 @@ func NewTransportIntegration(transport transport.Transport) (*TransportIntegrati
 	// Register packet handlers for audio/video frames
 	integration.setupPacketHandlers()
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "NewTransportIntegration",
 	}).Info("RTP transport integration created successfully")
 
@@ -109,6 +109,12 @@ func (ti *TransportIntegration) setupPacketHandlers() {
 		return ti.handleIncomingVideoFrame(packet, addr)
 	}
 	ti.transport.RegisterHandler(transport.PacketAVVideoFrame, videoHandler)
+
+	// Handler for RTCP reports sent on their own packet type
+	rtcpHandler := func(packet *transport.Packet, addr net.Addr) error {
+		return ti.handleIncomingRTCP(packet, addr)
+	}
+	ti.transport.RegisterHandler(transport.PacketAVRTCP, rtcpHandler)
 }
 
 // CreateSession creates a new RTP session for a friend.
@@ -146,7 +152,7 @@ func (ti *TransportIntegration) CreateSession(friendNumber uint32, remoteAddr ne
 	ti.addrToFriend[addrKey] = friendNumber
 	ti.friendToAddr[friendNumber] = remoteAddr
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":      "CreateSession",
 		"friend_number": friendNumber,
 		"remote_addr":   addrKey,
@@ -198,7 +204,7 @@ func (ti *TransportIntegration) CloseSession(friendNumber uint32) error {
 		delete(ti.addrToFriend, addrKey)
 		delete(ti.friendToAddr, friendNumber)
 
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":      "CloseSession",
 			"friend_number": friendNumber,
 			"remote_addr":   addrKey,
@@ -235,12 +241,39 @@ func (ti *TransportIntegration) handleIncomingAudioFrame(packet *transport.Packe
 	return nil
 }
 
+// handleIncomingRTCP processes incoming RTCP packets sent in RTCPSeparate
+// mode; multiplexed RTCP arrives through handleIncomingAudioFrame.
+func (ti *TransportIntegration) handleIncomingRTCP(packet *transport.Packet, addr net.Addr) error {
+	ti.mu.RLock()
+	defer ti.mu.RUnlock()
+
+	friendNumber, err := ti.lookupFriendNumber(addr)
+	if err != nil {
+		return err
+	}
+
+	session, err := ti.getSession(friendNumber)
+	if err != nil {
+		return err
+	}
+
+	if err := session.ReceiveRTCP(packet.Data); err != nil {
+		pkgLog.WithFields(logrus.Fields{
+			"function":      "handleIncomingRTCP",
+			"friend_number": friendNumber,
+			"error":         err.Error(),
+		}).Warn("Failed to process incoming RTCP packet")
+		return fmt.Errorf("failed to process RTCP packet: %w", err)
+	}
+	return nil
+}
+
 // lookupFriendNumber retrieves the friend number for a given address.
 func (ti *TransportIntegration) lookupFriendNumber(addr net.Addr) (uint32, error) {
 	addrKey := addr.String()
 	friendNumber, exists := ti.addrToFriend[addrKey]
 	if !exists {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":    "handleIncomingAudioFrame",
 			"remote_addr": addrKey,
 		}).Debug("No session found for address")
@@ -253,7 +286,7 @@ func (ti *TransportIntegration) lookupFriendNumber(addr net.Addr) (uint32, error
 func (ti *TransportIntegration) getSession(friendNumber uint32) (*Session, error) {
 	session, exists := ti.sessions[friendNumber]
 	if !exists {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":      "handleIncomingAudioFrame",
 			"friend_number": friendNumber,
 		}).Debug("Session not found for friend")
@@ -266,7 +299,7 @@ func (ti *TransportIntegration) getSession(friendNumber uint32) (*Session, error
 func (ti *TransportIntegration) processAudioPacket(session *Session, packet *transport.Packet, friendNumber uint32) ([]byte, string, error) {
 	audioData, mediaType, err := session.ReceivePacket(packet.Data)
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":      "handleIncomingAudioFrame",
 			"friend_number": friendNumber,
 			"error":         err.Error(),
@@ -274,7 +307,7 @@ func (ti *TransportIntegration) processAudioPacket(session *Session, packet *tra
 		return nil, "", fmt.Errorf("failed to process packet: %w", err)
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":      "handleIncomingAudioFrame",
 		"friend_number": friendNumber,
 		"media_type":    mediaType,
@@ -318,7 +351,7 @@ func (ti *TransportIntegration) handleIncomingVideoFrame(packet *transport.Packe
 	friendNumber, exists := ti.addrToFriend[addrKey]
 	if !exists {
 		ti.mu.RUnlock()
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":    "handleIncomingVideoFrame",
 			"remote_addr": addrKey,
 		}).Debug("No session found for address")
@@ -329,7 +362,7 @@ func (ti *TransportIntegration) handleIncomingVideoFrame(packet *transport.Packe
 	session, exists := ti.sessions[friendNumber]
 	if !exists {
 		ti.mu.RUnlock()
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":      "handleIncomingVideoFrame",
 			"friend_number": friendNumber,
 		}).Debug("Session not found for friend")
@@ -344,7 +377,7 @@ func (ti *TransportIntegration) handleIncomingVideoFrame(packet *transport.Packe
 	// Route packet to the session's ReceiveVideoPacket method
 	videoData, pictureID, err := session.ReceiveVideoPacket(packet.Data)
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":      "handleIncomingVideoFrame",
 			"friend_number": friendNumber,
 			"error":         err.Error(),
@@ -354,7 +387,7 @@ func (ti *TransportIntegration) handleIncomingVideoFrame(packet *transport.Packe
 
 	// Log and invoke callback only when we have a complete frame
 	if videoData != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":      "handleIncomingVideoFrame",
 			"friend_number": friendNumber,
 			"picture_id":    pictureID,
@@ -395,7 +428,7 @@ func (ti *TransportIntegration) Close() error {
 	for friendNumber, session := range ti.sessions {
 		if err := session.Close(); err != nil {
 			// Log error but continue closing other sessions
-			logrus.WithFields(logrus.Fields{
+			pkgLog.WithFields(logrus.Fields{
 				"function":      "Close",
 				"friend_number": friendNumber,
 				"error":         err.Error(),
@@ -419,7 +452,7 @@ func (ti *TransportIntegration) SetAudioReceiveCallback(callback AudioReceiveCal
 	defer ti.mu.Unlock()
 	ti.audioReceiveCallback = callback
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":        "SetAudioReceiveCallback",
 		"callback_is_nil": callback == nil,
 	}).Debug("Audio receive callback registered")
@@ -433,7 +466,7 @@ func (ti *TransportIntegration) SetVideoReceiveCallback(callback VideoReceiveCal
 	defer ti.mu.Unlock()
 	ti.videoReceiveCallback = callback
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":        "SetVideoReceiveCallback",
 		"callback_is_nil": callback == nil,
 	}).Debug("Video receive callback registered")
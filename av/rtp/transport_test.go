@@ -61,6 +61,7 @@ func TestSetupPacketHandlersIdempotent(t *testing.T) {
 	// Verify both handlers were registered
 	assert.NotNil(t, mockTransport.handlers[transport.PacketAVAudioFrame], "audio handler should be registered")
 	assert.NotNil(t, mockTransport.handlers[transport.PacketAVVideoFrame], "video handler should be registered")
+	assert.NotNil(t, mockTransport.handlers[transport.PacketAVRTCP], "RTCP handler should be registered")
 	initialHandlerCount := len(mockTransport.handlers)
 
 	// Call setupPacketHandlers multiple times
@@ -72,17 +72,48 @@ type BitrateControlPacket struct {
 	Timestamp    time.Time // Bitrate change timestamp
 }
 
+// CallTransferPacket tells the other party that a call is being
+// transferred away and ends it.
+//
+// Wire format:
+//
+//	[CALL_ID(4)][TIMESTAMP(8)]
+//
+// Total size: 12 bytes
+type CallTransferPacket struct {
+	CallID    uint32    // Call being transferred
+	Timestamp time.Time // Transfer timestamp
+}
+
+// CallTransferInvitePacket offers a transferred call to a new party. It
+// carries the RTP audio stream position of the original call so the
+// receiver can continue the stream without a discontinuity.
+//
+// Wire format:
+//
+//	[CALL_ID(4)][AUDIO_BITRATE(4)][VIDEO_BITRATE(4)][RTP_SEQUENCE(2)][RTP_TIMESTAMP(4)][TIMESTAMP(8)]
+//
+// Total size: 26 bytes
+type CallTransferInvitePacket struct {
+	CallID       uint32    // Identifier of the new call
+	AudioBitRate uint32    // Requested audio bit rate (0 = disabled)
+	VideoBitRate uint32    // Requested video bit rate (0 = disabled)
+	RTPSequence  uint16    // Next audio RTP sequence number
+	RTPTimestamp uint32    // Next audio RTP timestamp
+	Timestamp    time.Time // Invite timestamp
+}
+
 // SerializeCallRequest converts a CallRequestPacket to bytes for transmission.
 func SerializeCallRequest(req *CallRequestPacket) ([]byte, error) {
 	if req == nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "SerializeCallRequest",
 			"error":    "call request packet is nil",
 		}).Error("Invalid call request packet")
 		return nil, errors.New("call request packet is nil")
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":      "SerializeCallRequest",
 		"call_id":       req.CallID,
 		"audio_bitrate": req.AudioBitRate,
@@ -95,7 +126,7 @@ func SerializeCallRequest(req *CallRequestPacket) ([]byte, error) {
 	binary.BigEndian.PutUint32(data[8:12], req.VideoBitRate)
 	binary.BigEndian.PutUint64(data[12:20], uint64(req.Timestamp.UnixNano()))
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":  "SerializeCallRequest",
 		"data_size": len(data),
 	}).Debug("Call request packet serialized successfully")
@@ -209,3 +240,61 @@ func DeserializeBitrateControl(data []byte) (*BitrateControlPacket, error) {
 		Timestamp:    time.Unix(0, int64(binary.BigEndian.Uint64(data[12:20]))),
 	}, nil
 }
+
+// SerializeCallTransfer converts a CallTransferPacket to bytes for transmission.
+func SerializeCallTransfer(xfer *CallTransferPacket) ([]byte, error) {
+	if xfer == nil {
+		return nil, errors.New("call transfer packet is nil")
+	}
+
+	data := make([]byte, 12)
+	binary.BigEndian.PutUint32(data[0:4], xfer.CallID)
+	binary.BigEndian.PutUint64(data[4:12], uint64(xfer.Timestamp.UnixNano()))
+
+	return data, nil
+}
+
+// DeserializeCallTransfer converts bytes to a CallTransferPacket.
+func DeserializeCallTransfer(data []byte) (*CallTransferPacket, error) {
+	if len(data) < 12 {
+		return nil, errors.New("call transfer packet too short")
+	}
+
+	return &CallTransferPacket{
+		CallID:    binary.BigEndian.Uint32(data[0:4]),
+		Timestamp: time.Unix(0, int64(binary.BigEndian.Uint64(data[4:12]))),
+	}, nil
+}
+
+// SerializeCallTransferInvite converts a CallTransferInvitePacket to bytes for transmission.
+func SerializeCallTransferInvite(invite *CallTransferInvitePacket) ([]byte, error) {
+	if invite == nil {
+		return nil, errors.New("call transfer invite packet is nil")
+	}
+
+	data := make([]byte, 26)
+	binary.BigEndian.PutUint32(data[0:4], invite.CallID)
+	binary.BigEndian.PutUint32(data[4:8], invite.AudioBitRate)
+	binary.BigEndian.PutUint32(data[8:12], invite.VideoBitRate)
+	binary.BigEndian.PutUint16(data[12:14], invite.RTPSequence)
+	binary.BigEndian.PutUint32(data[14:18], invite.RTPTimestamp)
+	binary.BigEndian.PutUint64(data[18:26], uint64(invite.Timestamp.UnixNano()))
+
+	return data, nil
+}
+
+// DeserializeCallTransferInvite converts bytes to a CallTransferInvitePacket.
+func DeserializeCallTransferInvite(data []byte) (*CallTransferInvitePacket, error) {
+	if len(data) < 26 {
+		return nil, errors.New("call transfer invite packet too short")
+	}
+
+	return &CallTransferInvitePacket{
+		CallID:       binary.BigEndian.Uint32(data[0:4]),
+		AudioBitRate: binary.BigEndian.Uint32(data[4:8]),
+		VideoBitRate: binary.BigEndian.Uint32(data[8:12]),
+		RTPSequence:  binary.BigEndian.Uint16(data[12:14]),
+		RTPTimestamp: binary.BigEndian.Uint32(data[14:18]),
+		Timestamp:    time.Unix(0, int64(binary.BigEndian.Uint64(data[18:26]))),
+	}, nil
+}
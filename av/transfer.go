@@ -0,0 +1,250 @@
+package av
+
+import (
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+)
+
+// rtpStreamState is the position of an outgoing RTP audio stream, handed
+// from one call to the next when a call is transferred.
+type rtpStreamState struct {
+	sequence  uint16
+	timestamp uint32
+}
+
+// audioStreamState returns the position of the call's outgoing audio
+// stream, or the zero state if no RTP session exists.
+func (c *Call) audioStreamState() rtpStreamState {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.rtpSession != nil {
+		seq, ts := c.rtpSession.GetAudioStreamState()
+		return rtpStreamState{sequence: seq, timestamp: ts}
+	}
+	if c.rtpContinuation != nil {
+		return *c.rtpContinuation
+	}
+	return rtpStreamState{}
+}
+
+// setRTPContinuation makes the call's RTP session continue the audio stream
+// from state when it is created.
+func (c *Call) setRTPContinuation(state rtpStreamState) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.rtpContinuation = &state
+}
+
+// setTransferFrom marks the call as the new leg of a call transferred away
+// from fromFriend.
+func (c *Call) setTransferFrom(fromFriend uint32) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.transferFrom = &fromFriend
+}
+
+// transferredFrom returns the friend a call was transferred away from and
+// whether the call is a transfer at all.
+func (c *Call) transferredFrom() (uint32, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.transferFrom == nil {
+		return 0, false
+	}
+	return *c.transferFrom, true
+}
+
+// TransferCall hands the active call with fromFriend over to toFriend.
+//
+// toFriend receives a transfer invite carrying the audio RTP sequence number
+// and timestamp of the current call, so its outgoing stream continues from
+// where the original left off. It accepts with AnswerCall or declines with
+// EndCall, like any incoming call. fromFriend receives a transfer notice
+// and the original call ends. The callback registered with
+// SetTransferCallCallback fires when toFriend accepts.
+//
+// Parameters:
+//   - fromFriend: The friend whose active call to transfer
+//   - toFriend: The friend to transfer the call to
+//
+// Returns:
+//   - error: Any error that occurred during the transfer
+func (m *Manager) TransferCall(fromFriend, toFriend uint32) error {
+	pkgLog.WithFields(logrus.Fields{
+		"function":    "TransferCall",
+		"from_friend": fromFriend,
+		"to_friend":   toFriend,
+	}).Info("Transferring call")
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.running {
+		return ErrManagerNotRunning
+	}
+	if fromFriend == toFriend {
+		return fmt.Errorf("%w: cannot transfer a call to the same friend", ErrInvalidTransfer)
+	}
+	call, exists := m.calls[fromFriend]
+	if !exists {
+		return ErrNoActiveCall
+	}
+	if _, busy := m.calls[toFriend]; busy {
+		return ErrCallAlreadyActive
+	}
+
+	state := call.audioStreamState()
+	audioBitRate := call.GetAudioBitRate()
+	videoBitRate := call.GetVideoBitRate()
+	callID := m.generateUniqueCallID(toFriend)
+
+	// Invite the new party first so a send failure leaves the original
+	// call untouched.
+	if err := m.sendTransferInvite(toFriend, callID, audioBitRate, videoBitRate, state); err != nil {
+		return err
+	}
+	if err := m.sendCallTransfer(call, fromFriend); err != nil {
+		return err
+	}
+
+	m.updateCallState(call, CallStateFinished)
+	call.CleanupMedia()
+	delete(m.calls, fromFriend)
+
+	newCall := m.createCallSession(toFriend, callID, audioBitRate, videoBitRate)
+	newCall.setTransferFrom(fromFriend)
+	newCall.setRTPContinuation(state)
+	if err := m.setupCallMedia(newCall, toFriend, callID); err != nil {
+		return err
+	}
+	m.calls[toFriend] = newCall
+
+	pkgLog.WithFields(logrus.Fields{
+		"function":      "TransferCall",
+		"from_friend":   fromFriend,
+		"to_friend":     toFriend,
+		"call_id":       callID,
+		"rtp_sequence":  state.sequence,
+		"rtp_timestamp": state.timestamp,
+	}).Info("Call transfer initiated")
+
+	return nil
+}
+
+// sendTransferInvite sends a transfer invite for a new call to a friend.
+func (m *Manager) sendTransferInvite(friendNumber, callID, audioBitRate, videoBitRate uint32, state rtpStreamState) error {
+	invite := &CallTransferInvitePacket{
+		CallID:       callID,
+		AudioBitRate: audioBitRate,
+		VideoBitRate: videoBitRate,
+		RTPSequence:  state.sequence,
+		RTPTimestamp: state.timestamp,
+		Timestamp:    m.getTimeProvider().Now(),
+	}
+
+	data, err := SerializeCallTransferInvite(invite)
+	if err != nil {
+		return fmt.Errorf("failed to serialize call transfer invite: %w", err)
+	}
+
+	addr, err := m.friendAddressLookup(friendNumber)
+	if err != nil {
+		return fmt.Errorf("failed to get friend address: %w", err)
+	}
+
+	if err := m.transport.Send(0x37, data, addr); err != nil { // PacketAVCallTransferInvite
+		return fmt.Errorf("failed to send call transfer invite: %w", err)
+	}
+	return nil
+}
+
+// sendCallTransfer notifies a friend that their call is being transferred.
+func (m *Manager) sendCallTransfer(call *Call, friendNumber uint32) error {
+	xfer := &CallTransferPacket{
+		CallID:    call.GetCallID(),
+		Timestamp: m.getTimeProvider().Now(),
+	}
+
+	data, err := SerializeCallTransfer(xfer)
+	if err != nil {
+		return fmt.Errorf("failed to serialize call transfer: %w", err)
+	}
+
+	addr, err := m.friendAddressLookup(friendNumber)
+	if err != nil {
+		return fmt.Errorf("failed to get friend address: %w", err)
+	}
+
+	if err := m.transport.Send(0x36, data, addr); err != nil { // PacketAVCallTransfer
+		return fmt.Errorf("failed to send call transfer: %w", err)
+	}
+	return nil
+}
+
+// handleCallTransfer processes a transfer notice from the other party,
+// ending the call it refers to.
+func (m *Manager) handleCallTransfer(data, addr []byte) error {
+	xfer, err := DeserializeCallTransfer(data)
+	if err != nil {
+		return fmt.Errorf("failed to deserialize call transfer: %w", err)
+	}
+
+	return m.withCallByAddress(addr, xfer.CallID, "call transfer", func(friendNumber uint32, call *Call) error {
+		m.updateCallState(call, CallStateFinished)
+		call.CleanupMedia()
+		delete(m.calls, friendNumber)
+
+		pkgLog.WithFields(logrus.Fields{
+			"function":      "handleCallTransfer",
+			"friend_number": friendNumber,
+			"call_id":       xfer.CallID,
+		}).Info("Call transferred away by friend")
+		return nil
+	})
+}
+
+// handleTransferInvite processes a transfer invite. The invite is presented
+// to the application as an incoming call; its RTP stream state is applied
+// once the call is answered and media is set up.
+func (m *Manager) handleTransferInvite(data, addr []byte) error {
+	invite, err := DeserializeCallTransferInvite(data)
+	if err != nil {
+		return fmt.Errorf("failed to deserialize call transfer invite: %w", err)
+	}
+
+	friendNumber, found := m.findFriendByAddress(addr)
+	if !found {
+		return fmt.Errorf("call transfer invite from unknown friend")
+	}
+
+	pkgLog.WithFields(logrus.Fields{
+		"function":      "handleTransferInvite",
+		"friend_number": friendNumber,
+		"call_id":       invite.CallID,
+		"rtp_sequence":  invite.RTPSequence,
+		"rtp_timestamp": invite.RTPTimestamp,
+	}).Info("Received call transfer invite")
+
+	req := &CallRequestPacket{
+		CallID:       invite.CallID,
+		AudioBitRate: invite.AudioBitRate,
+		VideoBitRate: invite.VideoBitRate,
+		Timestamp:    invite.Timestamp,
+	}
+	state := rtpStreamState{sequence: invite.RTPSequence, timestamp: invite.RTPTimestamp}
+	return m.processIncomingCall(friendNumber, req, func(call *Call) {
+		call.setRTPContinuation(state)
+	})
+}
+
+// SetTransferCallCallback registers a callback invoked on the transferring
+// side when the new party accepts a call handed over with TransferCall.
+//
+// Parameters:
+//   - callback: Function receiving the original and new friend numbers, or nil to unregister
+func (m *Manager) SetTransferCallCallback(callback func(fromFriendNumber, toFriendNumber uint32)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.transferCallback = callback
+}
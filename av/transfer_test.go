@@ -0,0 +1,189 @@
+package av
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// transferFriendLookup returns a full IPv4 address and port so RTP sessions
+// can be created; the first byte doubles as the friend number for the
+// manager's fallback reverse lookup.
+func transferFriendLookup(friendNumber uint32) ([]byte, error) {
+	return []byte{byte(friendNumber), 0, 0, 1, 0x82, 0x35}, nil
+}
+
+func newTransferTestManager(t *testing.T) (*Manager, *mockTransport) {
+	t.Helper()
+	transport := newMockTransport()
+	manager, err := NewManager(transport, transferFriendLookup)
+	if err != nil {
+		t.Fatalf("Failed to create manager: %v", err)
+	}
+	if err := manager.Start(); err != nil {
+		t.Fatalf("Failed to start manager: %v", err)
+	}
+	t.Cleanup(func() { manager.Stop() })
+	return manager, transport
+}
+
+// TestTransferCall verifies the transferring side hands the RTP stream
+// position to the new party and fires the callback on acceptance.
+func TestTransferCall(t *testing.T) {
+	manager, transport := newTransferTestManager(t)
+
+	if err := manager.StartCall(1, 48000, 0); err != nil {
+		t.Fatalf("Failed to start call: %v", err)
+	}
+	media, err := newMockRTPMediaTransport("127.0.0.1:40001")
+	if err != nil {
+		t.Fatalf("failed to create mock RTP transport: %v", err)
+	}
+	call := manager.GetCall(1)
+	if err := call.SetupMedia(media, 1); err != nil {
+		t.Fatalf("SetupMedia failed: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := call.GetRTPSession().SendAudioPacket([]byte{0x01}, 960); err != nil {
+			t.Fatalf("SendAudioPacket failed: %v", err)
+		}
+	}
+
+	var transferred [2]uint32
+	manager.SetTransferCallCallback(func(fromFriendNumber, toFriendNumber uint32) {
+		transferred = [2]uint32{fromFriendNumber, toFriendNumber}
+	})
+
+	transport.sentPackets = nil
+	if err := manager.TransferCall(1, 2); err != nil {
+		t.Fatalf("TransferCall failed: %v", err)
+	}
+
+	if len(transport.sentPackets) != 2 {
+		t.Fatalf("Expected 2 transfer packets, got %d", len(transport.sentPackets))
+	}
+	invitePacket, noticePacket := transport.sentPackets[0], transport.sentPackets[1]
+	if invitePacket.packetType != 0x37 || invitePacket.addr[0] != 2 {
+		t.Errorf("Expected invite 0x37 to friend 2, got 0x%02x to %d", invitePacket.packetType, invitePacket.addr[0])
+	}
+	if noticePacket.packetType != 0x36 || noticePacket.addr[0] != 1 {
+		t.Errorf("Expected transfer notice 0x36 to friend 1, got 0x%02x to %d", noticePacket.packetType, noticePacket.addr[0])
+	}
+
+	invite, err := DeserializeCallTransferInvite(invitePacket.data)
+	if err != nil {
+		t.Fatalf("Failed to deserialize invite: %v", err)
+	}
+	if invite.RTPSequence != 2 || invite.RTPTimestamp != 1920 {
+		t.Errorf("Expected RTP state (2, 1920), got (%d, %d)", invite.RTPSequence, invite.RTPTimestamp)
+	}
+	if invite.AudioBitRate != 48000 {
+		t.Errorf("Expected audio bit rate 48000, got %d", invite.AudioBitRate)
+	}
+
+	if manager.GetCall(1) != nil {
+		t.Error("Original call should end after transfer")
+	}
+	if manager.GetCall(2) == nil {
+		t.Fatal("Transferred call should exist for friend 2")
+	}
+	if transferred != [2]uint32{} {
+		t.Error("Transfer callback fired before acceptance")
+	}
+
+	resp, _ := SerializeCallResponse(&CallResponsePacket{
+		CallID:       invite.CallID,
+		Accepted:     true,
+		AudioBitRate: 48000,
+		Timestamp:    time.Now(),
+	})
+	if err := transport.simulatePacket(0x31, resp, []byte{2, 0, 0, 1}); err != nil {
+		t.Fatalf("Failed to handle call response: %v", err)
+	}
+	if transferred != [2]uint32{1, 2} {
+		t.Errorf("Expected transfer callback (1, 2), got %v", transferred)
+	}
+}
+
+// TestTransferCallValidation verifies transfers are refused in invalid states.
+func TestTransferCallValidation(t *testing.T) {
+	manager, _ := newTransferTestManager(t)
+
+	if err := manager.TransferCall(1, 2); !errors.Is(err, ErrNoActiveCall) {
+		t.Errorf("Expected ErrNoActiveCall, got %v", err)
+	}
+	if err := manager.StartCall(1, 48000, 0); err != nil {
+		t.Fatalf("Failed to start call: %v", err)
+	}
+	if err := manager.StartCall(2, 48000, 0); err != nil {
+		t.Fatalf("Failed to start call: %v", err)
+	}
+	if err := manager.TransferCall(1, 1); !errors.Is(err, ErrInvalidTransfer) {
+		t.Errorf("Expected ErrInvalidTransfer, got %v", err)
+	}
+	if err := manager.TransferCall(1, 2); !errors.Is(err, ErrCallAlreadyActive) {
+		t.Errorf("Expected ErrCallAlreadyActive, got %v", err)
+	}
+	if manager.GetCall(1) == nil {
+		t.Error("Failed transfer should leave the original call intact")
+	}
+}
+
+// TestHandleTransferInvite verifies the new party sees an incoming call whose
+// RTP stream continues from the transferred position.
+func TestHandleTransferInvite(t *testing.T) {
+	manager, transport := newTransferTestManager(t)
+
+	incoming := false
+	manager.SetCallCallback(func(friendNumber uint32, audioEnabled, videoEnabled bool) {
+		incoming = friendNumber == 3 && audioEnabled && !videoEnabled
+	})
+
+	data, _ := SerializeCallTransferInvite(&CallTransferInvitePacket{
+		CallID:       7,
+		AudioBitRate: 48000,
+		RTPSequence:  100,
+		RTPTimestamp: 96000,
+		Timestamp:    time.Now(),
+	})
+	if err := transport.simulatePacket(0x37, data, []byte{3, 0, 0, 1}); err != nil {
+		t.Fatalf("Failed to handle transfer invite: %v", err)
+	}
+	if !incoming {
+		t.Fatal("Transfer invite should be reported as an incoming call")
+	}
+
+	if err := manager.AnswerCall(3, 48000, 0); err != nil {
+		t.Fatalf("Failed to answer transferred call: %v", err)
+	}
+	media, err := newMockRTPMediaTransport("127.0.0.1:40002")
+	if err != nil {
+		t.Fatalf("failed to create mock RTP transport: %v", err)
+	}
+	call := manager.GetCall(3)
+	if err := call.SetupMedia(media, 3); err != nil {
+		t.Fatalf("SetupMedia failed: %v", err)
+	}
+	if seq, ts := call.GetRTPSession().GetAudioStreamState(); seq != 100 || ts != 96000 {
+		t.Errorf("Expected RTP stream to continue at (100, 96000), got (%d, %d)", seq, ts)
+	}
+}
+
+// TestHandleCallTransfer verifies a transfer notice ends the call.
+func TestHandleCallTransfer(t *testing.T) {
+	manager, transport := newTransferTestManager(t)
+
+	if err := manager.StartCall(1, 48000, 0); err != nil {
+		t.Fatalf("Failed to start call: %v", err)
+	}
+	data, _ := SerializeCallTransfer(&CallTransferPacket{
+		CallID:    manager.GetCall(1).GetCallID(),
+		Timestamp: time.Now(),
+	})
+	if err := transport.simulatePacket(0x36, data, []byte{1, 0, 0, 1}); err != nil {
+		t.Fatalf("Failed to handle call transfer: %v", err)
+	}
+	if manager.GetCall(1) != nil {
+		t.Error("Call should end when transferred away by the other party")
+	}
+}
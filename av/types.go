@@ -130,7 +130,7 @@ func resolveRemoteAddress(resolver AddressResolver, friendNumber uint32) (net.Ad
 
 	addrBytes, err := resolver(friendNumber)
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":      funcName,
 			"friend_number": friendNumber,
 			"error":         err.Error(),
@@ -139,7 +139,7 @@ func resolveRemoteAddress(resolver AddressResolver, friendNumber uint32) (net.Ad
 	}
 
 	if len(addrBytes) < 6 {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":      funcName,
 			"friend_number": friendNumber,
 			"addr_len":      len(addrBytes),
@@ -151,7 +151,7 @@ func resolveRemoteAddress(resolver AddressResolver, friendNumber uint32) (net.Ad
 	ip := net.IP(addrBytes[:4])
 	port := int(addrBytes[4])<<8 | int(addrBytes[5])
 	addr := &net.UDPAddr{IP: ip, Port: port}
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":      funcName,
 		"friend_number": friendNumber,
 		"remote_addr":   addr.String(),
@@ -191,6 +191,10 @@ type Call struct {
 	videoProcessor *video.Processor
 	rtpSession     *rtp.Session
 
+	// Opus application mode for outgoing audio. Applied to the audio
+	// processor when it is created if set before media setup.
+	audioMode audio.OpusApplicationMode
+
 	// Address resolver for RTP session setup.
 	// If configured, used to resolve friend number to network address.
 	// If nil, falls back to placeholder localhost address.
@@ -204,6 +208,13 @@ type Call struct {
 	// If nil, quality monitoring operates without network quality data.
 	bitrateAdapter *BitrateAdapter
 
+	// Call transfer state. transferFrom is set on the transferring side
+	// to the friend the call was taken from; rtpContinuation holds the
+	// audio stream position received in a transfer invite and is applied
+	// when the RTP session is created.
+	transferFrom    *uint32
+	rtpContinuation *rtpStreamState
+
 	// Thread safety
 	mu sync.RWMutex
 }
@@ -217,7 +228,7 @@ type Call struct {
 // Note: RTP session and audio processor are initialized separately
 // via SetupMedia when the call is actually started or answered.
 func NewCall(friendNumber uint32) *Call {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":      "NewCall",
 		"friend_number": friendNumber,
 	}).Info("Creating new call")
@@ -241,7 +252,7 @@ func NewCall(friendNumber uint32) *Call {
 		timeProvider:   DefaultTimeProvider{},
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":      "NewCall",
 		"friend_number": friendNumber,
 		"state":         call.state,
@@ -267,7 +278,7 @@ func (c *Call) GetState() CallState {
 // SetState updates the call state.
 // This method is thread-safe and used internally by the manager.
 func (c *Call) SetState(state CallState) {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":      "SetState",
 		"friend_number": c.friendNumber,
 		"old_state":     c.state,
@@ -279,7 +290,7 @@ func (c *Call) SetState(state CallState) {
 	oldState := c.state
 	c.state = state
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":      "SetState",
 		"friend_number": c.friendNumber,
 		"old_state":     oldState,
@@ -382,7 +393,7 @@ func (c *Call) SetBitrateAdapter(adapter *BitrateAdapter) {
 
 // SetAudioBitRate updates the audio bit rate for this call.
 func (c *Call) SetAudioBitRate(bitRate uint32) {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":      "SetAudioBitRate",
 		"friend_number": c.friendNumber,
 		"old_bitrate":   c.audioBitRate,
@@ -393,16 +404,57 @@ func (c *Call) SetAudioBitRate(bitRate uint32) {
 	defer c.mu.Unlock()
 	c.audioBitRate = bitRate
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":      "SetAudioBitRate",
 		"friend_number": c.friendNumber,
 		"bitrate":       bitRate,
 	}).Info("Audio bit rate updated")
 }
 
+// SetAudioMode selects the Opus application mode for the call's outgoing
+// audio: OpusApplicationVoice for speech, OpusApplicationAudio for music,
+// or OpusApplicationLowDelay for minimal latency. If media has not been
+// set up yet, the mode is applied when the audio processor is created.
+//
+// Parameters:
+//   - mode: The Opus application mode to use
+//
+// Returns:
+//   - error: Any error that occurred while reconfiguring the encoder
+func (c *Call) SetAudioMode(mode audio.OpusApplicationMode) error {
+	if !mode.IsValid() {
+		return fmt.Errorf("%w: %d", audio.ErrInvalidApplicationMode, int(mode))
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.audioProcessor != nil {
+		if err := c.audioProcessor.SetApplicationMode(mode); err != nil {
+			return err
+		}
+	}
+	c.audioMode = mode
+
+	pkgLog.WithFields(logrus.Fields{
+		"function":      "SetAudioMode",
+		"friend_number": c.friendNumber,
+		"mode":          mode.String(),
+	}).Info("Audio mode updated")
+
+	return nil
+}
+
+// GetAudioMode returns the Opus application mode for the call's outgoing audio.
+func (c *Call) GetAudioMode() audio.OpusApplicationMode {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.audioMode
+}
+
 // SetVideoBitRate updates the video bit rate for this call.
 func (c *Call) SetVideoBitRate(bitRate uint32) {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":      "SetVideoBitRate",
 		"friend_number": c.friendNumber,
 		"old_bitrate":   c.videoBitRate,
@@ -413,7 +465,7 @@ func (c *Call) SetVideoBitRate(bitRate uint32) {
 	defer c.mu.Unlock()
 	c.videoBitRate = bitRate
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":      "SetVideoBitRate",
 		"friend_number": c.friendNumber,
 		"bitrate":       bitRate,
@@ -498,7 +550,7 @@ func (c *Call) IsVideoHidden() bool {
 
 // SetPaused updates the paused state of the call.
 func (c *Call) SetPaused(paused bool) {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":      "SetPaused",
 		"friend_number": c.friendNumber,
 		"paused":        paused,
@@ -508,7 +560,7 @@ func (c *Call) SetPaused(paused bool) {
 	defer c.mu.Unlock()
 	c.paused = paused
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":      "SetPaused",
 		"friend_number": c.friendNumber,
 		"paused":        paused,
@@ -517,7 +569,7 @@ func (c *Call) SetPaused(paused bool) {
 
 // SetAudioMuted updates the audio muted state.
 func (c *Call) SetAudioMuted(muted bool) {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":      "SetAudioMuted",
 		"friend_number": c.friendNumber,
 		"muted":         muted,
@@ -527,7 +579,7 @@ func (c *Call) SetAudioMuted(muted bool) {
 	defer c.mu.Unlock()
 	c.audioMuted = muted
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":      "SetAudioMuted",
 		"friend_number": c.friendNumber,
 		"muted":         muted,
@@ -536,7 +588,7 @@ func (c *Call) SetAudioMuted(muted bool) {
 
 // SetVideoHidden updates the video hidden state.
 func (c *Call) SetVideoHidden(hidden bool) {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":      "SetVideoHidden",
 		"friend_number": c.friendNumber,
 		"hidden":        hidden,
@@ -546,7 +598,7 @@ func (c *Call) SetVideoHidden(hidden bool) {
 	defer c.mu.Unlock()
 	c.videoHidden = hidden
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":      "SetVideoHidden",
 		"friend_number": c.friendNumber,
 		"hidden":        hidden,
@@ -564,7 +616,7 @@ func (c *Call) SetAddressResolver(resolver AddressResolver) {
 	defer c.mu.Unlock()
 	c.addressResolver = resolver
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":      "SetAddressResolver",
 		"friend_number": c.friendNumber,
 		"resolver_set":  resolver != nil,
@@ -584,7 +636,7 @@ func (c *Call) SetAddressResolver(resolver AddressResolver) {
 // Returns:
 //   - error: Any error that occurred during media setup
 func (c *Call) SetupMedia(transportArg interface{}, friendNumber uint32) error {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":      "SetupMedia",
 		"friend_number": friendNumber,
 		"call_friend":   c.friendNumber,
@@ -600,7 +652,7 @@ func (c *Call) SetupMedia(transportArg interface{}, friendNumber uint32) error {
 		return err
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":      "SetupMedia",
 		"friend_number": c.friendNumber,
 	}).Info("Media pipeline setup completed")
@@ -616,6 +668,16 @@ func (c *Call) initializeAudioProcessor() {
 	}
 	c.logMediaComponent("Initializing audio processor", false)
 	c.audioProcessor = audio.NewProcessor()
+	if c.audioMode != audio.OpusApplicationVoice {
+		if err := c.audioProcessor.SetApplicationMode(c.audioMode); err != nil {
+			pkgLog.WithFields(logrus.Fields{
+				"function":      "SetupMedia",
+				"friend_number": c.friendNumber,
+				"mode":          c.audioMode.String(),
+				"error":         err.Error(),
+			}).Warn("Failed to apply audio mode, continuing with voice mode")
+		}
+	}
 	c.logMediaComponent("Audio processor initialized", true)
 }
 
@@ -632,7 +694,7 @@ func (c *Call) initializeVideoProcessor() {
 
 // logMediaComponent logs a media setup message at Debug or Info level.
 func (c *Call) logMediaComponent(msg string, info bool) {
-	entry := logrus.WithFields(logrus.Fields{
+	entry := pkgLog.WithFields(logrus.Fields{
 		"function":      "SetupMedia",
 		"friend_number": c.friendNumber,
 	})
@@ -646,20 +708,20 @@ func (c *Call) logMediaComponent(msg string, info bool) {
 // setupRTPSession initializes the RTP session with transport integration.
 func (c *Call) setupRTPSession(transportArg interface{}, friendNumber uint32) error {
 	if c.rtpSession != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":      "SetupMedia",
 			"friend_number": c.friendNumber,
 		}).Debug("RTP session already initialized")
 		return nil
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":      "SetupMedia",
 		"friend_number": c.friendNumber,
 	}).Debug("Setting up RTP session with full transport integration")
 
 	if transportArg == nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":      "SetupMedia",
 			"friend_number": c.friendNumber,
 		}).Debug("Transport is nil - skipping RTP session creation (expected for testing)")
@@ -668,7 +730,7 @@ func (c *Call) setupRTPSession(transportArg interface{}, friendNumber uint32) er
 
 	toxTransport, ok := transportArg.(transport.Transport)
 	if !ok {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":       "SetupMedia",
 			"friend_number":  c.friendNumber,
 			"transport_type": fmt.Sprintf("%T", transportArg),
@@ -683,7 +745,7 @@ func (c *Call) setupRTPSession(transportArg interface{}, friendNumber uint32) er
 func (c *Call) createRTPSession(toxTransport transport.Transport, friendNumber uint32) error {
 	remoteAddr, err := resolveRemoteAddress(c.addressResolver, friendNumber)
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":      "SetupMedia",
 			"friend_number": c.friendNumber,
 			"error":         err.Error(),
@@ -693,7 +755,7 @@ func (c *Call) createRTPSession(toxTransport transport.Transport, friendNumber u
 
 	session, err := rtp.NewSession(friendNumber, toxTransport, remoteAddr)
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":      "SetupMedia",
 			"friend_number": c.friendNumber,
 			"error":         err.Error(),
@@ -702,8 +764,11 @@ func (c *Call) createRTPSession(toxTransport transport.Transport, friendNumber u
 	}
 
 	c.rtpSession = session
+	if c.rtpContinuation != nil {
+		session.SetAudioStreamState(c.rtpContinuation.sequence, c.rtpContinuation.timestamp)
+	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":      "SetupMedia",
 		"friend_number": c.friendNumber,
 		"remote_addr":   remoteAddr.String(),
@@ -728,7 +793,7 @@ func (c *Call) createRTPSession(toxTransport transport.Transport, friendNumber u
 // Returns:
 //   - error: Any error that occurred during frame processing and sending
 func (c *Call) SendAudioFrame(pcm []int16, sampleCount int, channels uint8, samplingRate uint32) error {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":      "SendAudioFrame",
 		"friend_number": c.friendNumber,
 		"pcm_length":    len(pcm),
@@ -753,6 +818,9 @@ func (c *Call) SendAudioFrame(pcm []int16, sampleCount int, channels uint8, samp
 	if err != nil {
 		return err
 	}
+	if len(encodedData) == 0 {
+		return nil
+	}
 
 	// Send processed audio via RTP
 	if err := c.sendAudioViaRTP(encodedData, sampleCount, rtpSession); err != nil {
@@ -762,7 +830,7 @@ func (c *Call) SendAudioFrame(pcm []int16, sampleCount int, channels uint8, samp
 	// Update frame timing for quality monitoring
 	c.updateLastFrame()
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":      "SendAudioFrame",
 		"friend_number": c.friendNumber,
 		"sample_count":  sampleCount,
@@ -775,7 +843,7 @@ func (c *Call) SendAudioFrame(pcm []int16, sampleCount int, channels uint8, samp
 // This function ensures all required parameters are valid before audio processing begins.
 func (c *Call) validateAudioFrameInputs(pcm []int16, sampleCount int, channels uint8, samplingRate uint32) error {
 	if len(pcm) == 0 {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":      "validateAudioFrameInputs",
 			"friend_number": c.friendNumber,
 		}).Error("Empty PCM data provided")
@@ -783,7 +851,7 @@ func (c *Call) validateAudioFrameInputs(pcm []int16, sampleCount int, channels u
 	}
 
 	if sampleCount <= 0 {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":      "validateAudioFrameInputs",
 			"friend_number": c.friendNumber,
 			"sample_count":  sampleCount,
@@ -792,7 +860,7 @@ func (c *Call) validateAudioFrameInputs(pcm []int16, sampleCount int, channels u
 	}
 
 	if channels == 0 || channels > 2 {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":      "validateAudioFrameInputs",
 			"friend_number": c.friendNumber,
 			"channels":      channels,
@@ -801,7 +869,7 @@ func (c *Call) validateAudioFrameInputs(pcm []int16, sampleCount int, channels u
 	}
 
 	if samplingRate == 0 {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":      "validateAudioFrameInputs",
 			"friend_number": c.friendNumber,
 			"sampling_rate": samplingRate,
@@ -822,7 +890,7 @@ func (c *Call) getAudioComponents() (*audio.Processor, *rtp.Session, error) {
 	c.mu.RUnlock()
 
 	if !audioEnabled {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":      "getAudioComponents",
 			"friend_number": c.friendNumber,
 		}).Error("Audio not enabled for this call")
@@ -830,7 +898,7 @@ func (c *Call) getAudioComponents() (*audio.Processor, *rtp.Session, error) {
 	}
 
 	if audioProcessor == nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":      "getAudioComponents",
 			"friend_number": c.friendNumber,
 		}).Error("Audio processor not initialized")
@@ -842,8 +910,9 @@ func (c *Call) getAudioComponents() (*audio.Processor, *rtp.Session, error) {
 
 // processAudioData processes PCM audio data through the audio processing pipeline.
 // This function handles encoding and validation of the processed audio data.
+// It returns empty data without an error when the frame was suppressed.
 func (c *Call) processAudioData(pcm []int16, samplingRate uint32, audioProcessor *audio.Processor) ([]byte, error) {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":      "processAudioData",
 		"friend_number": c.friendNumber,
 		"sample_count":  len(pcm),
@@ -853,7 +922,7 @@ func (c *Call) processAudioData(pcm []int16, samplingRate uint32, audioProcessor
 	// Process outgoing audio through the audio processor (Phase 2 integration)
 	encodedData, err := audioProcessor.ProcessOutgoing(pcm, samplingRate)
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":      "processAudioData",
 			"friend_number": c.friendNumber,
 			"error":         err.Error(),
@@ -861,16 +930,17 @@ func (c *Call) processAudioData(pcm []int16, samplingRate uint32, audioProcessor
 		return nil, fmt.Errorf("failed to process audio: %w", err)
 	}
 
-	// Phase 2 focus: Validate that audio processing works correctly
+	// Empty output means an effect such as voice activity detection
+	// suppressed the frame
 	if len(encodedData) == 0 {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":      "processAudioData",
 			"friend_number": c.friendNumber,
-		}).Error("Audio processor returned empty data")
-		return nil, fmt.Errorf("audio processor returned empty data")
+		}).Debug("Audio frame suppressed by effects")
+		return nil, nil
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":      "processAudioData",
 		"friend_number": c.friendNumber,
 		"encoded_size":  len(encodedData),
@@ -886,7 +956,7 @@ func (c *Call) processAudioData(pcm []int16, samplingRate uint32, audioProcessor
 func (c *Call) sendAudioViaRTP(encodedData []byte, sampleCount int, rtpSession *rtp.Session) error {
 	// RTP transmission integration (to be completed in next iteration)
 	if rtpSession != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":      "sendAudioViaRTP",
 			"friend_number": c.friendNumber,
 			"packet_size":   len(encodedData),
@@ -895,7 +965,7 @@ func (c *Call) sendAudioViaRTP(encodedData []byte, sampleCount int, rtpSession *
 		// Send via RTP session when fully integrated
 		err := rtpSession.SendAudioPacket(encodedData, uint32(sampleCount))
 		if err != nil {
-			logrus.WithFields(logrus.Fields{
+			pkgLog.WithFields(logrus.Fields{
 				"function":      "sendAudioViaRTP",
 				"friend_number": c.friendNumber,
 				"error":         err.Error(),
@@ -903,13 +973,13 @@ func (c *Call) sendAudioViaRTP(encodedData []byte, sampleCount int, rtpSession *
 			return fmt.Errorf("failed to send RTP audio packet: %w", err)
 		}
 
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":      "sendAudioViaRTP",
 			"friend_number": c.friendNumber,
 			"packet_size":   len(encodedData),
 		}).Debug("Audio packet sent via RTP successfully")
 	} else {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":      "sendAudioViaRTP",
 			"friend_number": c.friendNumber,
 			"encoded_size":  len(encodedData),
@@ -954,7 +1024,7 @@ func (c *Call) GetRTPSession() *rtp.Session {
 // Returns:
 //   - error: Any error that occurred during frame processing and sending
 func (c *Call) SendVideoFrame(width, height uint16, y, u, v []byte) error {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":      "SendVideoFrame",
 		"friend_number": c.friendNumber,
 		"width":         width,
@@ -989,7 +1059,7 @@ func (c *Call) SendVideoFrame(width, height uint16, y, u, v []byte) error {
 	// Update frame timing for quality monitoring
 	c.updateLastFrame()
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":      "SendVideoFrame",
 		"friend_number": c.friendNumber,
 		"width":         width,
@@ -1003,7 +1073,7 @@ func (c *Call) SendVideoFrame(width, height uint16, y, u, v []byte) error {
 // This function ensures all required parameters are valid before video processing begins.
 func (c *Call) validateVideoFrameInputs(width, height uint16, y, u, v []byte) error {
 	if width == 0 || height == 0 {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":      "validateVideoFrameInputs",
 			"friend_number": c.friendNumber,
 			"width":         width,
@@ -1017,7 +1087,7 @@ func (c *Call) validateVideoFrameInputs(width, height uint16, y, u, v []byte) er
 	expectedUVSize := expectedYSize / 4
 
 	if len(y) < expectedYSize {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":      "validateVideoFrameInputs",
 			"friend_number": c.friendNumber,
 			"expected_y":    expectedYSize,
@@ -1027,7 +1097,7 @@ func (c *Call) validateVideoFrameInputs(width, height uint16, y, u, v []byte) er
 	}
 
 	if len(u) < expectedUVSize {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":      "validateVideoFrameInputs",
 			"friend_number": c.friendNumber,
 			"expected_u":    expectedUVSize,
@@ -1037,7 +1107,7 @@ func (c *Call) validateVideoFrameInputs(width, height uint16, y, u, v []byte) er
 	}
 
 	if len(v) < expectedUVSize {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":      "validateVideoFrameInputs",
 			"friend_number": c.friendNumber,
 			"expected_v":    expectedUVSize,
@@ -1059,7 +1129,7 @@ func (c *Call) getVideoComponents() (*video.Processor, *rtp.Session, error) {
 	c.mu.RUnlock()
 
 	if !videoEnabled {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":      "getVideoComponents",
 			"friend_number": c.friendNumber,
 		}).Error("Video not enabled for this call")
@@ -1067,7 +1137,7 @@ func (c *Call) getVideoComponents() (*video.Processor, *rtp.Session, error) {
 	}
 
 	if videoProcessor == nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":      "getVideoComponents",
 			"friend_number": c.friendNumber,
 		}).Error("Video processor not initialized")
@@ -1097,7 +1167,7 @@ func (c *Call) processVideoData(width, height uint16, y, u, v []byte, processor
 	// Uses legacy API to get raw encoded data for RTP transmission
 	encodedData, err := processor.ProcessOutgoingLegacy(frame)
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":      "processVideoData",
 			"friend_number": c.friendNumber,
 			"width":         width,
@@ -1107,7 +1177,7 @@ func (c *Call) processVideoData(width, height uint16, y, u, v []byte, processor
 		return nil, fmt.Errorf("video processing failed: %w", err)
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":      "processVideoData",
 		"friend_number": c.friendNumber,
 		"width":         width,
@@ -1122,7 +1192,7 @@ func (c *Call) processVideoData(width, height uint16, y, u, v []byte, processor
 // This function handles RTP transmission similar to the audio pattern.
 func (c *Call) sendVideoViaRTP(encodedData []byte, rtpSession *rtp.Session) error {
 	if rtpSession != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":      "sendVideoViaRTP",
 			"friend_number": c.friendNumber,
 			"encoded_size":  len(encodedData),
@@ -1132,7 +1202,7 @@ func (c *Call) sendVideoViaRTP(encodedData []byte, rtpSession *rtp.Session) erro
 		// The RTP session will handle packetization and transmission
 		err := rtpSession.SendVideoPacket(encodedData)
 		if err != nil {
-			logrus.WithFields(logrus.Fields{
+			pkgLog.WithFields(logrus.Fields{
 				"function":      "sendVideoViaRTP",
 				"friend_number": c.friendNumber,
 				"error":         err.Error(),
@@ -1140,13 +1210,13 @@ func (c *Call) sendVideoViaRTP(encodedData []byte, rtpSession *rtp.Session) erro
 			return fmt.Errorf("failed to send RTP video packet: %w", err)
 		}
 
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":      "sendVideoViaRTP",
 			"friend_number": c.friendNumber,
 			"encoded_size":  len(encodedData),
 		}).Debug("Video frame sent via RTP successfully")
 	} else {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":      "sendVideoViaRTP",
 			"friend_number": c.friendNumber,
 			"encoded_size":  len(encodedData),
@@ -1167,7 +1237,7 @@ func (c *Call) GetVideoProcessor() *video.Processor {
 // CleanupMedia releases resources used by audio and video processors and RTP session.
 // This should be called when a call ends to prevent resource leaks.
 func (c *Call) CleanupMedia() {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":      "CleanupMedia",
 		"friend_number": c.friendNumber,
 	}).Debug("Cleaning up media resources")
@@ -1176,13 +1246,13 @@ func (c *Call) CleanupMedia() {
 	defer c.mu.Unlock()
 
 	if c.audioProcessor != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":      "CleanupMedia",
 			"friend_number": c.friendNumber,
 		}).Debug("Cleaning up audio processor")
 		// Close releases the underlying Opus codec resources (F-AV-H2).
 		if err := c.audioProcessor.Close(); err != nil {
-			logrus.WithFields(logrus.Fields{
+			pkgLog.WithFields(logrus.Fields{
 				"function":      "CleanupMedia",
 				"friend_number": c.friendNumber,
 				"error":         err.Error(),
@@ -1192,13 +1262,13 @@ func (c *Call) CleanupMedia() {
 	}
 
 	if c.videoProcessor != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":      "CleanupMedia",
 			"friend_number": c.friendNumber,
 		}).Debug("Cleaning up video processor")
 		// Video processor cleanup
 		if err := c.videoProcessor.Close(); err != nil {
-			logrus.WithFields(logrus.Fields{
+			pkgLog.WithFields(logrus.Fields{
 				"function":      "CleanupMedia",
 				"friend_number": c.friendNumber,
 				"error":         err.Error(),
@@ -1208,13 +1278,13 @@ func (c *Call) CleanupMedia() {
 	}
 
 	if c.rtpSession != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":      "CleanupMedia",
 			"friend_number": c.friendNumber,
 		}).Debug("Cleaning up RTP session")
 		// Close the RTP session to release resources
 		if err := c.rtpSession.Close(); err != nil {
-			logrus.WithFields(logrus.Fields{
+			pkgLog.WithFields(logrus.Fields{
 				"function":      "CleanupMedia",
 				"friend_number": c.friendNumber,
 				"error":         err.Error(),
@@ -1225,7 +1295,7 @@ func (c *Call) CleanupMedia() {
 
 	// Close the bitrate adapter to stop any tracking goroutines (M-10)
 	if c.bitrateAdapter != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":      "CleanupMedia",
 			"friend_number": c.friendNumber,
 		}).Debug("Cleaning up bitrate adapter")
@@ -1233,7 +1303,7 @@ func (c *Call) CleanupMedia() {
 		c.bitrateAdapter = nil
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":      "CleanupMedia",
 		"friend_number": c.friendNumber,
 	}).Info("Media resources cleaned up")
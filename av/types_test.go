@@ -1,11 +1,13 @@
 package av
 
 import (
+	"errors"
 	"net"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/opd-ai/toxcore/av/audio"
 	"github.com/opd-ai/toxcore/transport"
 )
 
@@ -143,6 +145,36 @@ func TestCallBitRateManagement(t *testing.T) {
 	}
 }
 
+// TestCallSetAudioMode verifies the audio mode reaches the encoder whether it
+// is set before or after media setup.
+func TestCallSetAudioMode(t *testing.T) {
+	call := NewCall(457)
+	if err := call.SetAudioMode(audio.OpusApplicationAudio); err != nil {
+		t.Fatalf("SetAudioMode before media setup failed: %v", err)
+	}
+	if err := call.SetupMedia(nil, 457); err != nil {
+		t.Fatalf("SetupMedia failed: %v", err)
+	}
+	defer call.CleanupMedia()
+	if mode := call.GetAudioProcessor().GetApplicationMode(); mode != audio.OpusApplicationAudio {
+		t.Errorf("Expected processor in audio mode, got %v", mode)
+	}
+
+	if err := call.SetAudioMode(audio.OpusApplicationLowDelay); err != nil {
+		t.Fatalf("SetAudioMode after media setup failed: %v", err)
+	}
+	if mode := call.GetAudioProcessor().GetApplicationMode(); mode != audio.OpusApplicationLowDelay {
+		t.Errorf("Expected processor in low-delay mode, got %v", mode)
+	}
+
+	if err := call.SetAudioMode(audio.OpusApplicationMode(9)); !errors.Is(err, audio.ErrInvalidApplicationMode) {
+		t.Errorf("Expected ErrInvalidApplicationMode, got %v", err)
+	}
+	if mode := call.GetAudioMode(); mode != audio.OpusApplicationLowDelay {
+		t.Errorf("Invalid mode should not change the call's mode, got %v", mode)
+	}
+}
+
 // TestCallEnabledStatus verifies audio/video enabled status management.
 func TestCallEnabledStatus(t *testing.T) {
 	call := NewCall(789)
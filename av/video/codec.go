@@ -24,7 +24,7 @@ type VP8Codec struct {
 // Initializes the codec with a standard video processor configured
 // for VP8-compatible settings (standard resolutions, appropriate bit rates).
 func NewVP8Codec() *VP8Codec {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "NewVP8Codec",
 	}).Info("Creating new VP8 codec instance")
 
@@ -32,7 +32,7 @@ func NewVP8Codec() *VP8Codec {
 		processor: NewProcessor(),
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "NewVP8Codec",
 	}).Info("VP8 codec created successfully")
 
@@ -53,14 +53,14 @@ func NewVP8Codec() *VP8Codec {
 //   - error: Any error that occurred during encoding
 func (c *VP8Codec) EncodeFrame(frame *VideoFrame) ([]byte, error) {
 	if frame == nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "VP8Codec.EncodeFrame",
 			"error":    "frame cannot be nil",
 		}).Error("Invalid input frame")
 		return nil, fmt.Errorf("frame cannot be nil")
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":     "VP8Codec.EncodeFrame",
 		"frame_width":  frame.Width,
 		"frame_height": frame.Height,
@@ -68,14 +68,14 @@ func (c *VP8Codec) EncodeFrame(frame *VideoFrame) ([]byte, error) {
 
 	data, err := c.processor.ProcessOutgoingLegacy(frame)
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "VP8Codec.EncodeFrame",
 			"error":    err.Error(),
 		}).Error("Failed to encode VP8 frame")
 		return nil, err
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":     "VP8Codec.EncodeFrame",
 		"encoded_size": len(data),
 	}).Debug("VP8 frame encoded successfully")
@@ -83,6 +83,35 @@ func (c *VP8Codec) EncodeFrame(frame *VideoFrame) ([]byte, error) {
 	return data, nil
 }
 
+// RequestKeyframe makes the next EncodeFrame call produce a key frame.
+//
+// Call this when the peer reports packet loss (NACK) or picture loss (PLI)
+// so its decoder can recover without waiting for the next periodic key
+// frame. The request is cleared once the key frame has been produced.
+func (c *VP8Codec) RequestKeyframe() {
+	pkgLog.WithFields(logrus.Fields{
+		"function": "VP8Codec.RequestKeyframe",
+	}).Debug("Key frame requested")
+
+	c.processor.RequestKeyframe()
+}
+
+// ForceKeyframe encodes frame as a key frame immediately.
+//
+// Unlike RequestKeyframe, the key frame is produced by this call rather
+// than the next EncodeFrame call.
+//
+// Parameters:
+//   - frame: Video frame in YUV420 format
+//
+// Returns:
+//   - []byte: VP8-encoded key frame
+//   - error: Any error that occurred during encoding
+func (c *VP8Codec) ForceKeyframe(frame *VideoFrame) ([]byte, error) {
+	c.processor.RequestKeyframe()
+	return c.EncodeFrame(frame)
+}
+
 // DecodeFrame decodes a VP8 video frame to YUV420 format.
 //
 // Uses a pure Go VP8 decoder to handle actual VP8-encoded data.
@@ -95,21 +124,21 @@ func (c *VP8Codec) EncodeFrame(frame *VideoFrame) ([]byte, error) {
 //   - *VideoFrame: Decoded video frame in YUV420 format
 //   - error: Any error that occurred during decoding
 func (c *VP8Codec) DecodeFrame(data []byte) (*VideoFrame, error) {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":  "VP8Codec.DecodeFrame",
 		"data_size": len(data),
 	}).Debug("Starting VP8 frame decoding")
 
 	frame, err := c.processor.ProcessIncomingLegacy(data)
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "VP8Codec.DecodeFrame",
 			"error":    err.Error(),
 		}).Error("Failed to decode VP8 frame")
 		return nil, err
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":     "VP8Codec.DecodeFrame",
 		"frame_width":  frame.Width,
 		"frame_height": frame.Height,
@@ -123,14 +152,14 @@ func (c *VP8Codec) DecodeFrame(data []byte) (*VideoFrame, error) {
 // Configures both encoder and any future decoder settings to use
 // the specified bit rate.
 func (c *VP8Codec) SetBitRate(bitRate uint32) error {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "VP8Codec.SetBitRate",
 		"bitrate":  bitRate,
 	}).Info("Setting VP8 codec bit rate")
 
 	err := c.processor.SetBitRate(bitRate)
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "VP8Codec.SetBitRate",
 			"bitrate":  bitRate,
 			"error":    err.Error(),
@@ -138,7 +167,7 @@ func (c *VP8Codec) SetBitRate(bitRate uint32) error {
 		return err
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "VP8Codec.SetBitRate",
 		"bitrate":  bitRate,
 	}).Info("VP8 codec bit rate set successfully")
@@ -150,7 +179,7 @@ func (c *VP8Codec) SetBitRate(bitRate uint32) error {
 //
 // VP8 supports a wide range of resolutions, these are common ones for video calls.
 func (c *VP8Codec) GetSupportedResolutions() []Resolution {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "VP8Codec.GetSupportedResolutions",
 	}).Debug("Retrieving supported VP8 resolutions")
 
@@ -164,7 +193,7 @@ func (c *VP8Codec) GetSupportedResolutions() []Resolution {
 		{Width: 1920, Height: 1080}, // HD 1080p
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":         "VP8Codec.GetSupportedResolutions",
 		"resolution_count": len(resolutions),
 	}).Debug("Retrieved supported VP8 resolutions")
@@ -176,13 +205,13 @@ func (c *VP8Codec) GetSupportedResolutions() []Resolution {
 //
 // VP8 supports a wide range of bit rates suitable for different quality levels.
 func (c *VP8Codec) GetSupportedBitRates() []uint32 {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "VP8Codec.GetSupportedBitRates",
 	}).Debug("Retrieving supported VP8 bit rates")
 
 	bitRates := []uint32{64000, 128000, 256000, 512000, 1000000, 2000000, 4000000, 8000000}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":      "VP8Codec.GetSupportedBitRates",
 		"bitrate_count": len(bitRates),
 	}).Debug("Retrieved supported VP8 bit rates")
@@ -214,7 +243,7 @@ func (c *VP8Codec) ValidateFrameSize(width, height uint16) error {
 
 // logFrameSizeValidation logs the start of frame size validation.
 func logFrameSizeValidation(width, height uint16) {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "VP8Codec.ValidateFrameSize",
 		"width":    width,
 		"height":   height,
@@ -224,7 +253,7 @@ func logFrameSizeValidation(width, height uint16) {
 // validateEvenDimensions checks that both width and height are even numbers.
 func validateEvenDimensions(width, height uint16) error {
 	if width%2 != 0 {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "VP8Codec.ValidateFrameSize",
 			"width":    width,
 			"error":    "width must be even",
@@ -232,7 +261,7 @@ func validateEvenDimensions(width, height uint16) error {
 		return fmt.Errorf("invalid VP8 frame width: %d - must be even", width)
 	}
 	if height%2 != 0 {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "VP8Codec.ValidateFrameSize",
 			"height":   height,
 			"error":    "height must be even",
@@ -245,7 +274,7 @@ func validateEvenDimensions(width, height uint16) error {
 // validateMinimumDimensions checks that dimensions meet VP8 minimum requirements.
 func validateMinimumDimensions(width, height uint16) error {
 	if width < 16 || height < 16 {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "VP8Codec.ValidateFrameSize",
 			"width":    width,
 			"height":   height,
@@ -259,7 +288,7 @@ func validateMinimumDimensions(width, height uint16) error {
 // validateMaximumDimensions checks that dimensions do not exceed VP8 maximum limits.
 func validateMaximumDimensions(width, height uint16) error {
 	if width > 16383 || height > 16383 {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "VP8Codec.ValidateFrameSize",
 			"width":    width,
 			"height":   height,
@@ -272,7 +301,7 @@ func validateMaximumDimensions(width, height uint16) error {
 
 // logFrameSizeSuccess logs successful frame size validation.
 func logFrameSizeSuccess(width, height uint16) {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "VP8Codec.ValidateFrameSize",
 		"width":    width,
 		"height":   height,
@@ -281,14 +310,14 @@ func logFrameSizeSuccess(width, height uint16) {
 
 // Close releases codec resources.
 func (c *VP8Codec) Close() error {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "VP8Codec.Close",
 	}).Info("Closing VP8 codec")
 
 	if c.processor != nil {
 		err := c.processor.Close()
 		if err != nil {
-			logrus.WithFields(logrus.Fields{
+			pkgLog.WithFields(logrus.Fields{
 				"function": "VP8Codec.Close",
 				"error":    err.Error(),
 			}).Error("Failed to close VP8 processor")
@@ -296,7 +325,7 @@ func (c *VP8Codec) Close() error {
 		}
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "VP8Codec.Close",
 	}).Info("VP8 codec closed successfully")
 
@@ -318,7 +347,7 @@ func (r Resolution) String() string {
 //
 // Provides reasonable default bitrates based on resolution for video calls.
 func GetBitrateForResolution(resolution Resolution) uint32 {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":   "GetBitrateForResolution",
 		"resolution": resolution.String(),
 	}).Debug("Calculating appropriate bitrate for resolution")
@@ -345,7 +374,7 @@ func GetBitrateForResolution(resolution Resolution) uint32 {
 		bitrate = 8000000 // 8 Mbps for larger resolutions
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":   "GetBitrateForResolution",
 		"resolution": resolution.String(),
 		"pixels":     pixels,
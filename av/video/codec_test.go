@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewVP8Codec(t *testing.T) {
@@ -432,3 +433,55 @@ func BenchmarkVP8CodecRoundTrip(b *testing.B) {
 		}
 	}
 }
+
+func newKeyframeTestFrame() *VideoFrame {
+	return &VideoFrame{
+		Width:   640,
+		Height:  480,
+		Y:       make([]byte, 640*480),
+		U:       make([]byte, 640*480/4),
+		V:       make([]byte, 640*480/4),
+		YStride: 640,
+		UStride: 320,
+		VStride: 320,
+	}
+}
+
+func TestVP8CodecRequestKeyframe(t *testing.T) {
+	codec := NewVP8Codec()
+	defer codec.Close()
+	frame := newKeyframeTestFrame()
+
+	first, err := codec.EncodeFrame(frame)
+	require.NoError(t, err)
+	assert.True(t, isVP8KeyFrame(first), "first frame should be a key frame")
+
+	inter, err := codec.EncodeFrame(frame)
+	require.NoError(t, err)
+	assert.False(t, isVP8KeyFrame(inter), "second frame should be an inter frame")
+
+	codec.RequestKeyframe()
+	requested, err := codec.EncodeFrame(frame)
+	require.NoError(t, err)
+	assert.True(t, isVP8KeyFrame(requested), "requested frame should be a key frame")
+
+	after, err := codec.EncodeFrame(frame)
+	require.NoError(t, err)
+	assert.False(t, isVP8KeyFrame(after), "request should be cleared after the key frame")
+}
+
+func TestVP8CodecForceKeyframe(t *testing.T) {
+	codec := NewVP8Codec()
+	defer codec.Close()
+	frame := newKeyframeTestFrame()
+
+	_, err := codec.EncodeFrame(frame)
+	require.NoError(t, err)
+
+	data, err := codec.ForceKeyframe(frame)
+	require.NoError(t, err)
+	assert.True(t, isVP8KeyFrame(data))
+
+	_, err = codec.ForceKeyframe(nil)
+	assert.Error(t, err)
+}
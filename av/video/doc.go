@@ -72,6 +72,15 @@
 //	    // Frame is ready for decoding
 //	}
 //
+// # Forward Error Correction
+//
+// FECEncoder and FECDecoder implement XOR parity FEC (RFC 5109). A parity
+// packet covers a group of up to MaxFECGroupSize media packets and lets the
+// receiver rebuild any single lost packet of that group:
+//
+//	parity := video.NewFECEncoder().GenerateParity(group)
+//	lost, err := video.NewFECDecoder().Recover(append(received, parity))
+//
 // # Video Scaling
 //
 // The Scaler resizes video frames using bilinear interpolation:
@@ -107,6 +116,15 @@
 //   - BlurEffect: Apply Gaussian blur
 //   - SharpenEffect: Sharpen image details
 //   - ColorTemperatureEffect: Adjust warm/cool tones
+//   - VirtualBackgroundEffect: Replace the background with a custom image
+//
+// VirtualBackgroundEffect takes a SegmentationFunc that returns a per-pixel
+// foreground mask, and scales the background to each frame's size.
+// UpdateBackground swaps the image mid-call for animated or live backgrounds:
+//
+//	effect, err := video.NewVirtualBackgroundEffect(beachFrame, segmenter)
+//	chain.AddEffect(effect)
+//	err = effect.UpdateBackground(nextFrame)
 //
 // # Video Processor
 //
@@ -120,6 +138,38 @@
 //	// Process and encode frame
 //	encoded, err := processor.ProcessFrame(frame)
 //
+// # Snapshots
+//
+// Processor.CaptureSnapshot converts the most recently processed frame to an
+// *image.RGBA with BT.601 coefficients. SaveSnapshotJPEG and SaveSnapshotPNG
+// write any frame to a file atomically:
+//
+//	img, err := processor.CaptureSnapshot()
+//	err = video.SaveSnapshotPNG(frame, "snapshot.png")
+//	err = video.SaveSnapshotJPEG(frame, "snapshot.jpg", 90)
+//
+// # Screen Capture
+//
+// ScreenCapture is a video source for screen sharing. It grabs the display
+// at TargetFPS (DefaultScreenCaptureFPS by default), converts each grab to
+// YUV420 and scales it to the call resolution with the Scaler:
+//
+//	capture := video.NewScreenCapture(1280, 720)
+//	if err := capture.Start(); err != nil {
+//	    return err
+//	}
+//	defer capture.Stop()
+//	for frame := range capture.FrameChan() {
+//	    encoded, err := processor.ProcessFrame(frame)
+//	    // ...
+//	}
+//
+// Linux captures through wf-recorder on Wayland or ffmpeg's x11grab on X11,
+// and macOS through ffmpeg's avfoundation device. The tool is started once
+// by Start and streams uncompressed video until Stop. Windows uses GDI
+// BitBlt. Start returns ErrNotSupported on other platforms or when no
+// capture tool is installed.
+//
 // # Deterministic Testing
 //
 // For reproducible tests, inject a custom TimeProvider:
@@ -25,7 +25,7 @@ type EffectChain struct {
 
 // NewEffectChain creates a new effect processing chain.
 func NewEffectChain() *EffectChain {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "NewEffectChain",
 	}).Info("Creating new effect chain")
 
@@ -33,7 +33,7 @@ func NewEffectChain() *EffectChain {
 		effects: make([]Effect, 0),
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "NewEffectChain",
 	}).Info("Effect chain created successfully")
 
@@ -42,7 +42,7 @@ func NewEffectChain() *EffectChain {
 
 // AddEffect adds an effect to the processing chain.
 func (ec *EffectChain) AddEffect(effect Effect) {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":    "EffectChain.AddEffect",
 		"effect_name": effect.GetName(),
 		"chain_size":  len(ec.effects),
@@ -50,7 +50,7 @@ func (ec *EffectChain) AddEffect(effect Effect) {
 
 	ec.effects = append(ec.effects, effect)
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":       "EffectChain.AddEffect",
 		"effect_name":    effect.GetName(),
 		"new_chain_size": len(ec.effects),
@@ -75,7 +75,7 @@ func (ec *EffectChain) Apply(frame *VideoFrame) (*VideoFrame, error) {
 // validateInputFrame checks if the input frame is valid.
 func (ec *EffectChain) validateInputFrame(frame *VideoFrame) error {
 	if frame == nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "EffectChain.Apply",
 			"error":    "input frame cannot be nil",
 		}).Error("Invalid input frame")
@@ -86,7 +86,7 @@ func (ec *EffectChain) validateInputFrame(frame *VideoFrame) error {
 
 // logApplyStart logs the start of effect chain application.
 func (ec *EffectChain) logApplyStart(frame *VideoFrame) {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":     "EffectChain.Apply",
 		"effect_count": len(ec.effects),
 		"frame_width":  frame.Width,
@@ -96,7 +96,7 @@ func (ec *EffectChain) logApplyStart(frame *VideoFrame) {
 
 // handleEmptyChain returns a copy of the frame when no effects are present.
 func (ec *EffectChain) handleEmptyChain(frame *VideoFrame) *VideoFrame {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "EffectChain.Apply",
 	}).Debug("No effects in chain, returning copy")
 	return copyFrame(frame)
@@ -119,7 +119,7 @@ func (ec *EffectChain) processEffectChain(frame *VideoFrame) (*VideoFrame, error
 
 // applyEffect applies a single effect with logging.
 func (ec *EffectChain) applyEffect(frame *VideoFrame, index int, effect Effect) (*VideoFrame, error) {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":     "EffectChain.Apply",
 		"effect_index": index,
 		"effect_name":  effect.GetName(),
@@ -127,7 +127,7 @@ func (ec *EffectChain) applyEffect(frame *VideoFrame, index int, effect Effect)
 
 	result, err := effect.Apply(frame)
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":     "EffectChain.Apply",
 			"effect_index": index,
 			"effect_name":  effect.GetName(),
@@ -140,7 +140,7 @@ func (ec *EffectChain) applyEffect(frame *VideoFrame, index int, effect Effect)
 
 // logApplySuccess logs successful completion of effect chain.
 func (ec *EffectChain) logApplySuccess() {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":          "EffectChain.Apply",
 		"processed_effects": len(ec.effects),
 	}).Debug("Effect chain applied successfully")
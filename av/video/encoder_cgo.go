@@ -116,7 +116,7 @@ func NewLibVPXEncoder(width, height uint16, bitRate uint32) (*LibVPXEncoder, err
 	// Populate Go-side Planes and Stride fields from the C struct.
 	img.Deref()
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "NewLibVPXEncoder",
 		"width":    width,
 		"height":   height,
@@ -0,0 +1,247 @@
+package video
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/pion/rtp"
+	"github.com/sirupsen/logrus"
+)
+
+// XOR forward error correction for video RTP streams (RFC 5109, level 0
+// protection with the short 16-bit mask).
+const (
+	// FECGroupSize is the default number of media packets covered by each
+	// parity packet.
+	FECGroupSize = 4
+
+	// MaxFECGroupSize is the largest group the 16-bit protection mask can cover.
+	MaxFECGroupSize = 16
+
+	// FECPayloadType is the RTP payload type used for parity packets.
+	FECPayloadType uint8 = 117
+
+	// fecHeaderSize is the FEC header (10 bytes) plus the level 0 header with
+	// a short mask (4 bytes).
+	fecHeaderSize = 14
+)
+
+var (
+	// ErrFECNoParity indicates the group passed to Recover has no parity packet.
+	ErrFECNoParity = errors.New("FEC group has no parity packet")
+	// ErrFECUnrecoverable indicates more than one protected packet is missing.
+	ErrFECUnrecoverable = errors.New("FEC group has more than one missing packet")
+	// ErrFECNothingMissing indicates every protected packet is present.
+	ErrFECNothingMissing = errors.New("FEC group has no missing packet")
+	// ErrFECMalformed indicates the parity packet cannot be parsed.
+	ErrFECMalformed = errors.New("malformed FEC packet")
+)
+
+// FECEncoder produces XOR parity packets for groups of media packets.
+// Parity packets share the media SSRC but use their own sequence numbers
+// and FECPayloadType, so receivers can tell them apart.
+type FECEncoder struct {
+	sequenceNumber uint16
+}
+
+// NewFECEncoder creates a parity packet generator.
+func NewFECEncoder() *FECEncoder {
+	return &FECEncoder{}
+}
+
+// GenerateParity returns a parity packet protecting packets, or nil if the
+// group is empty, larger than MaxFECGroupSize, spans more than
+// MaxFECGroupSize sequence numbers, or mixes SSRCs.
+func (e *FECEncoder) GenerateParity(packets []*rtp.Packet) *rtp.Packet {
+	if len(packets) == 0 || len(packets) > MaxFECGroupSize {
+		return nil
+	}
+
+	base := packets[0].SequenceNumber
+	for _, p := range packets[1:] {
+		if isSeqBefore(p.SequenceNumber, base) {
+			base = p.SequenceNumber
+		}
+	}
+
+	var (
+		mask     uint16
+		recovery [8]byte
+		maxLen   int
+	)
+	for _, p := range packets {
+		offset := p.SequenceNumber - base
+		if offset >= MaxFECGroupSize || p.SSRC != packets[0].SSRC {
+			return nil
+		}
+		mask |= 1 << (15 - offset)
+		xorBytes(recovery[:], fecRecoveryFields(p))
+		if len(p.Payload) > maxLen {
+			maxLen = len(p.Payload)
+		}
+	}
+
+	payload := make([]byte, fecHeaderSize+maxLen)
+	// FEC header: E=0, L=0, P/X/CC/M/PT recovery
+	payload[0] = recovery[0] & 0x3F
+	payload[1] = recovery[1]
+	binary.BigEndian.PutUint16(payload[2:], base)
+	copy(payload[4:8], recovery[2:6])
+	copy(payload[8:10], recovery[6:8])
+	// Level 0 header: protection length and mask
+	binary.BigEndian.PutUint16(payload[10:], uint16(maxLen))
+	binary.BigEndian.PutUint16(payload[12:], mask)
+	for _, p := range packets {
+		xorBytes(payload[fecHeaderSize:], p.Payload)
+	}
+
+	parity := &rtp.Packet{
+		Header: rtp.Header{
+			Version:        2,
+			PayloadType:    FECPayloadType,
+			SequenceNumber: e.sequenceNumber,
+			Timestamp:      packets[len(packets)-1].Timestamp,
+			SSRC:           packets[0].SSRC,
+		},
+		Payload: payload,
+	}
+	e.sequenceNumber++
+
+	pkgLog.WithFields(logrus.Fields{
+		"function":      "FECEncoder.GenerateParity",
+		"base_sequence": base,
+		"group_size":    len(packets),
+		"parity_size":   len(payload),
+	}).Debug("Generated FEC parity packet")
+
+	return parity
+}
+
+// FECDecoder reconstructs lost media packets from XOR parity packets.
+type FECDecoder struct{}
+
+// NewFECDecoder creates a parity packet decoder.
+func NewFECDecoder() *FECDecoder {
+	return &FECDecoder{}
+}
+
+// Recover reconstructs the single missing packet of a group. group holds
+// the parity packet (identified by FECPayloadType) and whichever protected
+// media packets were received; nil entries and packets outside the parity
+// mask are ignored.
+func (d *FECDecoder) Recover(group []*rtp.Packet) (*rtp.Packet, error) {
+	var parity *rtp.Packet
+	media := make(map[uint16]*rtp.Packet, len(group))
+	for _, p := range group {
+		switch {
+		case p == nil:
+		case p.PayloadType == FECPayloadType:
+			parity = p
+		default:
+			media[p.SequenceNumber] = p
+		}
+	}
+	if parity == nil {
+		return nil, ErrFECNoParity
+	}
+	if len(parity.Payload) < fecHeaderSize {
+		return nil, fmt.Errorf("%w: %d bytes", ErrFECMalformed, len(parity.Payload))
+	}
+
+	fec := parity.Payload
+	base := binary.BigEndian.Uint16(fec[2:])
+	protLen := int(binary.BigEndian.Uint16(fec[10:]))
+	mask := binary.BigEndian.Uint16(fec[12:])
+	if fec[0]&0x40 != 0 || len(fec) < fecHeaderSize+protLen {
+		return nil, fmt.Errorf("%w: unsupported long mask or truncated payload", ErrFECMalformed)
+	}
+
+	missing, found := uint16(0), false
+	var present []*rtp.Packet
+	for i := uint16(0); i < MaxFECGroupSize; i++ {
+		if mask&(1<<(15-i)) == 0 {
+			continue
+		}
+		seq := base + i
+		if p, ok := media[seq]; ok {
+			present = append(present, p)
+			continue
+		}
+		if found {
+			return nil, ErrFECUnrecoverable
+		}
+		missing, found = seq, true
+	}
+	if !found {
+		return nil, ErrFECNothingMissing
+	}
+
+	var recovery [8]byte
+	recovery[0], recovery[1] = fec[0], fec[1]
+	copy(recovery[2:6], fec[4:8])
+	copy(recovery[6:8], fec[8:10])
+	payload := append([]byte(nil), fec[fecHeaderSize:fecHeaderSize+protLen]...)
+	for _, p := range present {
+		xorBytes(recovery[:], fecRecoveryFields(p))
+		xorBytes(payload, p.Payload)
+	}
+
+	length := int(binary.BigEndian.Uint16(recovery[6:]))
+	if length > len(payload) {
+		return nil, fmt.Errorf("%w: recovered length %d exceeds protection length %d", ErrFECMalformed, length, protLen)
+	}
+
+	recovered := &rtp.Packet{
+		Header: rtp.Header{
+			Version:        2,
+			Padding:        recovery[0]&0x20 != 0,
+			Marker:         recovery[1]&0x80 != 0,
+			PayloadType:    recovery[1] & 0x7F,
+			SequenceNumber: missing,
+			Timestamp:      binary.BigEndian.Uint32(recovery[2:6]),
+			SSRC:           parity.SSRC,
+		},
+		Payload: payload[:length],
+	}
+
+	pkgLog.WithFields(logrus.Fields{
+		"function": "FECDecoder.Recover",
+		"sequence": missing,
+		"size":     length,
+	}).Debug("Recovered lost video packet from FEC parity")
+
+	return recovered, nil
+}
+
+// fecRecoveryFields returns the header fields protected by XOR FEC: the
+// first two header bytes, the timestamp and the payload length.
+func fecRecoveryFields(p *rtp.Packet) []byte {
+	var b [8]byte
+	if p.Padding {
+		b[0] |= 0x20
+	}
+	if p.Extension {
+		b[0] |= 0x10
+	}
+	b[0] |= uint8(len(p.CSRC)) & 0x0F
+	b[1] = p.PayloadType & 0x7F
+	if p.Marker {
+		b[1] |= 0x80
+	}
+	binary.BigEndian.PutUint32(b[2:], p.Timestamp)
+	binary.BigEndian.PutUint16(b[6:], uint16(len(p.Payload)))
+	return b[:]
+}
+
+// xorBytes XORs src into dst; src may be shorter than dst.
+func xorBytes(dst, src []byte) {
+	for i := range src {
+		dst[i] ^= src[i]
+	}
+}
+
+// isSeqBefore reports whether a precedes b in RTP sequence order.
+func isSeqBefore(a, b uint16) bool {
+	return int16(a-b) < 0
+}
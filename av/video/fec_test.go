@@ -0,0 +1,96 @@
+package video
+
+import (
+	"testing"
+
+	"github.com/pion/rtp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func makeFECTestGroup(base uint16) []*rtp.Packet {
+	payloads := [][]byte{
+		[]byte("first packet payload"),
+		[]byte("second"),
+		[]byte("third packet, somewhat longer than the others"),
+		[]byte("fourth"),
+	}
+	group := make([]*rtp.Packet, len(payloads))
+	for i, payload := range payloads {
+		group[i] = &rtp.Packet{
+			Header: rtp.Header{
+				Version:        2,
+				PayloadType:    96,
+				SequenceNumber: base + uint16(i),
+				Timestamp:      9000,
+				SSRC:           0xCAFEBABE,
+				Marker:         i == len(payloads)-1,
+			},
+			Payload: payload,
+		}
+	}
+	return group
+}
+
+func TestFECRecoverEachPosition(t *testing.T) {
+	for _, base := range []uint16{100, 65534} {
+		group := makeFECTestGroup(base)
+		parity := NewFECEncoder().GenerateParity(group)
+		require.NotNil(t, parity)
+		assert.Equal(t, FECPayloadType, parity.PayloadType)
+		assert.Equal(t, group[0].SSRC, parity.SSRC)
+
+		for lost := range group {
+			received := []*rtp.Packet{parity}
+			for i, p := range group {
+				if i != lost {
+					received = append(received, p)
+				}
+			}
+
+			recovered, err := NewFECDecoder().Recover(received)
+			require.NoError(t, err)
+			assert.Equal(t, group[lost].SequenceNumber, recovered.SequenceNumber)
+			assert.Equal(t, group[lost].Timestamp, recovered.Timestamp)
+			assert.Equal(t, group[lost].Marker, recovered.Marker)
+			assert.Equal(t, group[lost].PayloadType, recovered.PayloadType)
+			assert.Equal(t, group[lost].Payload, recovered.Payload)
+		}
+	}
+}
+
+func TestFECRecoverErrors(t *testing.T) {
+	group := makeFECTestGroup(10)
+	parity := NewFECEncoder().GenerateParity(group)
+	decoder := NewFECDecoder()
+
+	_, err := decoder.Recover(group)
+	assert.ErrorIs(t, err, ErrFECNoParity)
+
+	_, err = decoder.Recover(append([]*rtp.Packet{parity}, group...))
+	assert.ErrorIs(t, err, ErrFECNothingMissing)
+
+	_, err = decoder.Recover([]*rtp.Packet{parity, group[0], group[1]})
+	assert.ErrorIs(t, err, ErrFECUnrecoverable)
+
+	short := &rtp.Packet{Header: rtp.Header{PayloadType: FECPayloadType}, Payload: []byte{1, 2}}
+	_, err = decoder.Recover([]*rtp.Packet{short})
+	assert.ErrorIs(t, err, ErrFECMalformed)
+}
+
+func TestFECGenerateParityRejectsInvalidGroups(t *testing.T) {
+	encoder := NewFECEncoder()
+	assert.Nil(t, encoder.GenerateParity(nil))
+
+	group := makeFECTestGroup(0)
+	group[3].SequenceNumber = 40
+	assert.Nil(t, encoder.GenerateParity(group), "span beyond the 16-bit mask")
+
+	group = makeFECTestGroup(0)
+	group[2].SSRC = 1
+	assert.Nil(t, encoder.GenerateParity(group), "mixed SSRCs")
+
+	first := encoder.GenerateParity(makeFECTestGroup(0))
+	second := encoder.GenerateParity(makeFECTestGroup(4))
+	assert.Equal(t, first.SequenceNumber+1, second.SequenceNumber)
+}
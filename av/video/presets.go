@@ -117,21 +117,21 @@ var presetConfigs = map[QualityPreset]PresetConfig{
 //
 // Returns an error if the preset is not recognized.
 func GetPresetConfig(preset QualityPreset) (PresetConfig, error) {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "GetPresetConfig",
 		"preset":   preset.String(),
 	}).Debug("Getting preset configuration")
 
 	config, ok := presetConfigs[preset]
 	if !ok {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "GetPresetConfig",
 			"preset":   int(preset),
 		}).Error("Unknown quality preset")
 		return PresetConfig{}, fmt.Errorf("unknown quality preset: %d", preset)
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":  "GetPresetConfig",
 		"preset":    preset.String(),
 		"width":     config.Width,
@@ -148,7 +148,7 @@ func GetPresetConfig(preset QualityPreset) (PresetConfig, error) {
 // This is a convenience function that creates a processor with the appropriate
 // resolution and bitrate settings for the specified quality level.
 func NewProcessorWithPreset(preset QualityPreset) (*Processor, error) {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "NewProcessorWithPreset",
 		"preset":   preset.String(),
 	}).Info("Creating video processor with quality preset")
@@ -160,7 +160,7 @@ func NewProcessorWithPreset(preset QualityPreset) (*Processor, error) {
 
 	processor := NewProcessorWithSettings(config.Width, config.Height, config.Bitrate)
 	if processor == nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "NewProcessorWithPreset",
 			"preset":   preset.String(),
 		}).Error("Failed to create video processor")
@@ -173,7 +173,7 @@ func NewProcessorWithPreset(preset QualityPreset) (*Processor, error) {
 	// default interval.
 	processor.encoder.SetKeyFrameInterval(int(config.KeyframeInterval))
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "NewProcessorWithPreset",
 		"preset":   preset.String(),
 		"width":    config.Width,
@@ -192,7 +192,7 @@ func NewProcessorWithPreset(preset QualityPreset) (*Processor, error) {
 // Returns the highest quality preset that fits within the available bandwidth,
 // with a 20% headroom for network variance.
 func PresetForBandwidth(bandwidthKbps uint32) QualityPreset {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":       "PresetForBandwidth",
 		"bandwidth_kbps": bandwidthKbps,
 	}).Debug("Selecting preset for bandwidth")
@@ -212,7 +212,7 @@ func PresetForBandwidth(bandwidthKbps uint32) QualityPreset {
 		selected = QualityLow
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":       "PresetForBandwidth",
 		"bandwidth_kbps": bandwidthKbps,
 		"effective_bps":  effectiveBandwidth,
@@ -228,7 +228,7 @@ func PresetForBandwidth(bandwidthKbps uint32) QualityPreset {
 // resolution is not available, returns the nearest preset that doesn't exceed
 // the target.
 func PresetForResolution(width, height uint16) QualityPreset {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "PresetForResolution",
 		"width":    width,
 		"height":   height,
@@ -248,7 +248,7 @@ func PresetForResolution(width, height uint16) QualityPreset {
 		selected = QualityLow
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":      "PresetForResolution",
 		"width":         width,
 		"height":        height,
@@ -280,7 +280,7 @@ func EstimateBandwidthUsage(preset QualityPreset) uint32 {
 //
 // Returns true if the preset can be used reliably with current conditions.
 func ValidatePresetForNetwork(preset QualityPreset, availableBandwidthKbps uint32, packetLossPercent float32) bool {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":    "ValidatePresetForNetwork",
 		"preset":      preset.String(),
 		"bandwidth":   availableBandwidthKbps,
@@ -297,7 +297,7 @@ func ValidatePresetForNetwork(preset QualityPreset, availableBandwidthKbps uint3
 
 	// If packet loss is high, recommend lower quality
 	if packetLossPercent > 5.0 && preset > QualityLow {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":    "ValidatePresetForNetwork",
 			"preset":      preset.String(),
 			"packet_loss": packetLossPercent,
@@ -307,7 +307,7 @@ func ValidatePresetForNetwork(preset QualityPreset, availableBandwidthKbps uint3
 
 	valid := availableBandwidthKbps >= requiredBandwidth
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":           "ValidatePresetForNetwork",
 		"preset":             preset.String(),
 		"required_bandwidth": requiredBandwidth,
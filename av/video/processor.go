@@ -16,6 +16,7 @@ import (
 	"bytes"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	vp8enc "github.com/opd-ai/vp8"
@@ -115,7 +116,7 @@ type RealVP8Encoder struct {
 // Returns an error if the underlying VP8 encoder cannot be created
 // (e.g. invalid dimensions).
 func NewRealVP8Encoder(width, height uint16, bitRate uint32) (*RealVP8Encoder, error) {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "NewRealVP8Encoder",
 		"width":    width,
 		"height":   height,
@@ -124,7 +125,7 @@ func NewRealVP8Encoder(width, height uint16, bitRate uint32) (*RealVP8Encoder, e
 
 	enc, err := vp8enc.NewEncoder(int(width), int(height), defaultFPS)
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "NewRealVP8Encoder",
 			"width":    width,
 			"height":   height,
@@ -137,7 +138,7 @@ func NewRealVP8Encoder(width, height uint16, bitRate uint32) (*RealVP8Encoder, e
 	enc.SetKeyFrameInterval(defaultKeyFrameInterval)
 	enc.SetLoopFilterLevel(defaultLoopFilterLevel)
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":           "NewRealVP8Encoder",
 		"width":              width,
 		"height":             height,
@@ -167,7 +168,7 @@ func (e *RealVP8Encoder) Encode(frame *VideoFrame) ([]byte, error) {
 		return nil, fmt.Errorf("video frame cannot be nil")
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":       "RealVP8Encoder.Encode",
 		"frame_width":    frame.Width,
 		"frame_height":   frame.Height,
@@ -184,13 +185,13 @@ func (e *RealVP8Encoder) Encode(frame *VideoFrame) ([]byte, error) {
 	}
 	vp8Data, err := e.enc.Encode(yuv)
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "RealVP8Encoder.Encode",
 			"error":    err.Error(),
 		}).Error("VP8 encoding failed")
 		return nil, fmt.Errorf("VP8 encoding failed: %w", err)
 	}
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":     "RealVP8Encoder.Encode",
 		"input_size":   len(yuv),
 		"output_size":  len(vp8Data),
@@ -205,7 +206,7 @@ func (e *RealVP8Encoder) validateFrameSize(frame *VideoFrame) error {
 	if frame.Width == e.width && frame.Height == e.height {
 		return nil
 	}
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":        "RealVP8Encoder.Encode",
 		"expected_width":  e.width,
 		"expected_height": e.height,
@@ -236,7 +237,7 @@ func (e *RealVP8Encoder) buildFrameBuffer(frame *VideoFrame) ([]byte, error) {
 // packNamedPlane packs one named plane and wraps errors with plane context.
 func packNamedPlane(dst, src []byte, stride, width, height int, label string) error {
 	if err := packPlane(dst, src, stride, width, height); err != nil {
-		logrus.WithFields(logrus.Fields{"function": "RealVP8Encoder.Encode", "error": err.Error(), "plane": label}).Error(label + " plane packing failed")
+		pkgLog.WithFields(logrus.Fields{"function": "RealVP8Encoder.Encode", "error": err.Error(), "plane": label}).Error(label + " plane packing failed")
 		return fmt.Errorf("%s plane packing failed: %w", label, err)
 	}
 	return nil
@@ -291,7 +292,7 @@ func packPlane(dst, src []byte, stride, width, height int) error {
 
 // SetBitRate updates the target bit rate for the VP8 encoder.
 func (e *RealVP8Encoder) SetBitRate(bitRate uint32) error {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":     "RealVP8Encoder.SetBitRate",
 		"old_bit_rate": e.bitRate,
 		"new_bit_rate": bitRate,
@@ -300,7 +301,7 @@ func (e *RealVP8Encoder) SetBitRate(bitRate uint32) error {
 	e.bitRate = bitRate
 	e.enc.SetBitrate(int(bitRate))
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "RealVP8Encoder.SetBitRate",
 		"bit_rate": bitRate,
 	}).Info("VP8 encoder bit rate updated successfully")
@@ -310,7 +311,7 @@ func (e *RealVP8Encoder) SetBitRate(bitRate uint32) error {
 
 // Close releases encoder resources.
 func (e *RealVP8Encoder) Close() error {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "RealVP8Encoder.Close",
 		"width":    e.width,
 		"height":   e.height,
@@ -330,7 +331,7 @@ func (e *RealVP8Encoder) SupportsInterframe() bool {
 // Negative values are clamped to 0.
 func (e *RealVP8Encoder) SetKeyFrameInterval(interval int) {
 	if interval < 0 {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":           "RealVP8Encoder.SetKeyFrameInterval",
 			"requested_interval": interval,
 			"applied_interval":   0,
@@ -430,7 +431,7 @@ type SimpleVP8Encoder struct {
 
 // NewSimpleVP8Encoder creates a new YUV420 passthrough encoder.
 func NewSimpleVP8Encoder(width, height uint16, bitRate uint32) *SimpleVP8Encoder {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "NewSimpleVP8Encoder",
 		"width":    width,
 		"height":   height,
@@ -555,6 +556,11 @@ type Processor struct {
 	pictureID      uint16 // Current picture ID for VP8
 	timeProvider   TimeProvider
 	lastDecodedKey *VideoFrame // Cache of last successfully decoded key frame (protected by mu)
+	lastFrame      *VideoFrame // Most recently processed frame for CaptureSnapshot (protected by mu)
+
+	// keyframeRequested is set by RequestKeyframe and consumed by the next
+	// encode, so requests may arrive from any goroutine.
+	keyframeRequested atomic.Bool
 }
 
 // NewProcessor creates a new video processor instance.
@@ -565,7 +571,7 @@ type Processor struct {
 // - RealVP8Encoder for actual VP8 compression
 // - Complete pipeline with scaling, effects, and RTP support
 func NewProcessor() *Processor {
-	logrus.WithFields(logrus.Fields{"function": "NewProcessor"}).Info("Creating new video processor with default settings")
+	pkgLog.WithFields(logrus.Fields{"function": "NewProcessor"}).Info("Creating new video processor with default settings")
 	const (
 		defaultWidth   = 640
 		defaultHeight  = 480
@@ -573,16 +579,16 @@ func NewProcessor() *Processor {
 		defaultSSRC    = 1
 	)
 	processor := initProcessor(defaultWidth, defaultHeight, defaultBitRate, defaultSSRC, buildPreferredEncoder(defaultWidth, defaultHeight, defaultBitRate, "NewProcessor"))
-	logrus.WithFields(logrus.Fields{"function": "NewProcessor", "width": defaultWidth, "height": defaultHeight, "bit_rate": defaultBitRate, "ssrc": defaultSSRC}).Info("Video processor created successfully")
+	pkgLog.WithFields(logrus.Fields{"function": "NewProcessor", "width": defaultWidth, "height": defaultHeight, "bit_rate": defaultBitRate, "ssrc": defaultSSRC}).Info("Video processor created successfully")
 	return processor
 }
 
 // NewProcessorWithSettings creates a processor with specific settings.
 func NewProcessorWithSettings(width, height uint16, bitRate uint32) *Processor {
-	logrus.WithFields(logrus.Fields{"function": "NewProcessorWithSettings", "width": width, "height": height, "bit_rate": bitRate}).Info("Creating new video processor with custom settings")
+	pkgLog.WithFields(logrus.Fields{"function": "NewProcessorWithSettings", "width": width, "height": height, "bit_rate": bitRate}).Info("Creating new video processor with custom settings")
 	ssrc := uint32(1)
 	processor := initProcessor(width, height, bitRate, ssrc, buildPreferredEncoder(width, height, bitRate, "NewProcessorWithSettings"))
-	logrus.WithFields(logrus.Fields{"function": "NewProcessorWithSettings", "width": width, "height": height, "bit_rate": bitRate, "ssrc": ssrc}).Info("Video processor with custom settings created successfully")
+	pkgLog.WithFields(logrus.Fields{"function": "NewProcessorWithSettings", "width": width, "height": height, "bit_rate": bitRate, "ssrc": ssrc}).Info("Video processor with custom settings created successfully")
 	return processor
 }
 
@@ -592,7 +598,7 @@ func buildPreferredEncoder(width, height uint16, bitRate uint32, function string
 	if err == nil {
 		return enc
 	}
-	logrus.WithFields(logrus.Fields{"function": function, "error": err.Error()}).Warn("Failed to create real VP8 encoder, falling back to simple encoder")
+	pkgLog.WithFields(logrus.Fields{"function": function, "error": err.Error()}).Warn("Failed to create real VP8 encoder, falling back to simple encoder")
 	return NewSimpleVP8Encoder(width, height, bitRate)
 }
 
@@ -661,6 +667,7 @@ func (p *Processor) ProcessOutgoing(frame *VideoFrame) ([]RTPPacket, error) {
 		return nil, err
 	}
 
+	p.recordSnapshotFrame(processedFrame)
 	return packets, nil
 }
 
@@ -708,7 +715,7 @@ func (p *Processor) applyEffects(frame *VideoFrame) (*VideoFrame, error) {
 // timestamps, sequence numbers, and VP8-specific headers.
 func (p *Processor) encodeAndPacketize(frame *VideoFrame) ([]RTPPacket, error) {
 	// Encode with VP8
-	encodedData, err := p.encoder.Encode(frame)
+	encodedData, err := p.encode(frame)
 	if err != nil {
 		return nil, fmt.Errorf("encoding failed: %w", err)
 	}
@@ -757,7 +764,28 @@ func (p *Processor) ProcessOutgoingLegacy(frame *VideoFrame) ([]byte, error) {
 	}
 
 	// Step 5: Encode with VP8 (no RTP packetization)
-	return p.encoder.Encode(processedFrame)
+	encoded, err := p.encode(processedFrame)
+	if err != nil {
+		return nil, err
+	}
+
+	p.recordSnapshotFrame(processedFrame)
+	return encoded, nil
+}
+
+// RequestKeyframe makes the next encoded frame a key frame, letting a peer
+// that lost packets resynchronize without waiting for the key frame interval.
+// It is safe to call concurrently with encoding.
+func (p *Processor) RequestKeyframe() {
+	p.keyframeRequested.Store(true)
+}
+
+// encode runs the encoder, forcing a key frame first if one was requested.
+func (p *Processor) encode(frame *VideoFrame) ([]byte, error) {
+	if p.keyframeRequested.Swap(false) {
+		p.encoder.ForceKeyFrame()
+	}
+	return p.encoder.Encode(frame)
 }
 
 // validateBasicFrameInput validates basic frame input constraints for processing.
@@ -870,12 +898,18 @@ func (p *Processor) ProcessIncoming(packet RTPPacket) (*VideoFrame, error) {
 	// For now, return the decoded frame as-is
 	// Future enhancement: implement output scaling
 
+	p.recordSnapshotFrame(frame)
 	return frame, nil
 }
 
 // ProcessIncomingLegacy provides backward compatibility with []byte input.
 func (p *Processor) ProcessIncomingLegacy(data []byte) (*VideoFrame, error) {
-	return p.decodeFrameData(data)
+	frame, err := p.decodeFrameData(data)
+	if err != nil {
+		return nil, err
+	}
+	p.recordSnapshotFrame(frame)
+	return frame, nil
 }
 
 // vp8FrameTag extracts the frame type and validates the VP8 frame tag.
@@ -935,7 +969,7 @@ func (p *Processor) handleInterFrame(dataSize int) (*VideoFrame, error) {
 	if lastKey == nil {
 		return nil, fmt.Errorf("inter frame received but no cached key frame available")
 	}
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":  "Processor.handleInterFrame",
 		"data_size": dataSize,
 	}).Debug("Received inter frame, returning cached key frame")
@@ -951,7 +985,7 @@ func (p *Processor) tryFallbackToCache(err error, dataSize int) (*VideoFrame, er
 	if lastKey == nil {
 		return nil, err
 	}
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":  "Processor.tryFallbackToCache",
 		"error":     err.Error(),
 		"data_size": dataSize,
@@ -1179,7 +1213,7 @@ func DefaultVideoEncoderConfig() VideoEncoderConfig {
 // strategy, partition count, or probability updates. For standard video
 // calls, prefer NewProcessorWithSettings or NewProcessorWithPreset.
 func NewProcessorWithConfig(width, height uint16, bitRate uint32, cfg VideoEncoderConfig) (*Processor, error) {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":            "NewProcessorWithConfig",
 		"width":               width,
 		"height":              height,
@@ -1196,6 +1230,6 @@ func NewProcessorWithConfig(width, height uint16, bitRate uint32, cfg VideoEncod
 	}
 	applyEncoderConfig(enc, cfg)
 	processor := initProcessor(width, height, bitRate, 1, enc)
-	logrus.WithFields(logrus.Fields{"function": "NewProcessorWithConfig", "width": width, "height": height, "bit_rate": bitRate}).Info("Video processor with advanced configuration created successfully")
+	pkgLog.WithFields(logrus.Fields{"function": "NewProcessorWithConfig", "width": width, "height": height, "bit_rate": bitRate}).Info("Video processor with advanced configuration created successfully")
 	return processor, nil
 }
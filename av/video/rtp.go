@@ -19,6 +19,11 @@ const (
 	// maxBytesPerFrame is the maximum payload bytes accepted per frame assembly
 	// (~256 KB covers high-quality VP8 I-frames while bounding memory per stream).
 	maxBytesPerFrame = 256 * 1024
+
+	// vp8KeyFrameBit is a reserved bit of the VP8 payload descriptor extension
+	// octet that marks packets carrying a key frame, so receivers can detect
+	// key frames without parsing the VP8 bitstream.
+	vp8KeyFrameBit = 0x08
 )
 
 // RTPPacket represents an RTP packet for video transmission.
@@ -39,6 +44,7 @@ type RTPPacket struct {
 	NonReferenceBit     bool   // N bit
 	StartOfPartition    bool   // S bit
 	PictureID           uint16 // Picture ID
+	KeyFrame            bool   // Packet belongs to a key frame
 
 	// Payload data
 	Payload []byte // VP8 encoded data
@@ -55,7 +61,7 @@ type RTPPacketizer struct {
 
 // NewRTPPacketizer creates a new VP8 RTP packetizer.
 func NewRTPPacketizer(ssrc uint32) *RTPPacketizer {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "NewRTPPacketizer",
 		"ssrc":     ssrc,
 	}).Info("Creating new RTP packetizer")
@@ -68,7 +74,7 @@ func NewRTPPacketizer(ssrc uint32) *RTPPacketizer {
 		maxPacketSize:  1200, // Conservative MTU minus IP/UDP headers
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":        "NewRTPPacketizer",
 		"ssrc":            ssrc,
 		"payload_type":    packetizer.payloadType,
@@ -117,7 +123,7 @@ func (rp *RTPPacketizer) calculatePacketParameters(frameDataLen int) (maxPayload
 }
 
 // createRTPPacket builds a single RTP packet for a frame chunk.
-func (rp *RTPPacketizer) createRTPPacket(frameData []byte, start, end, packetIndex, totalPackets int, frameTimestamp uint32, pictureID uint16) RTPPacket {
+func (rp *RTPPacketizer) createRTPPacket(frameData []byte, start, end, packetIndex, totalPackets int, frameTimestamp uint32, pictureID uint16, keyFrame bool) RTPPacket {
 	packet := RTPPacket{
 		Version:             2,
 		Padding:             false,
@@ -132,6 +138,7 @@ func (rp *RTPPacketizer) createRTPPacket(frameData []byte, start, end, packetInd
 		NonReferenceBit:     false,
 		StartOfPartition:    packetIndex == 0,
 		PictureID:           pictureID,
+		KeyFrame:            keyFrame,
 	}
 	packet.Payload = rp.buildVP8Payload(packet, frameData[start:end])
 	return packet
@@ -145,6 +152,7 @@ func (rp *RTPPacketizer) incrementSequenceNumber() {
 
 // PacketizeFrame splits a video frame into RTP packets for network transmission.
 // Returns a slice of RTP packets with proper sequencing and timing information.
+// Every packet of a VP8 key frame carries the key frame descriptor bit.
 func (rp *RTPPacketizer) PacketizeFrame(frameData []byte, frameTimestamp uint32, pictureID uint16) ([]RTPPacket, error) {
 	if err := validateFrameData(frameData); err != nil {
 		return nil, err
@@ -155,6 +163,7 @@ func (rp *RTPPacketizer) PacketizeFrame(frameData []byte, frameTimestamp uint32,
 		return nil, err
 	}
 
+	keyFrame := isVP8KeyFrame(frameData)
 	packets := make([]RTPPacket, numPackets)
 	for i := 0; i < numPackets; i++ {
 		start := i * maxPayloadSize
@@ -163,7 +172,7 @@ func (rp *RTPPacketizer) PacketizeFrame(frameData []byte, frameTimestamp uint32,
 			end = len(frameData)
 		}
 
-		packets[i] = rp.createRTPPacket(frameData, start, end, i, numPackets, frameTimestamp, pictureID)
+		packets[i] = rp.createRTPPacket(frameData, start, end, i, numPackets, frameTimestamp, pictureID, keyFrame)
 		rp.incrementSequenceNumber()
 	}
 
@@ -175,7 +184,8 @@ func (rp *RTPPacketizer) PacketizeFrame(frameData []byte, frameTimestamp uint32,
 // Descriptor layout when X and I bits are set (15-bit PictureID):
 //
 //	octet 0:  X|R|R|R|N|S|PID   (X=1 signals extension octet follows)
-//	octet 1:  I|L|T|K|RSV        (extension octet; I=1 signals PictureID follows)
+//	octet 1:  I|L|T|K|RSV        (extension octet; I=1 signals PictureID follows,
+//	                              RSV bit 0x08 marks key frame packets)
 //	octet 2:  M|PicID[14:8]      (M=1 for 15-bit form)
 //	octet 3:  PicID[7:0]
 //	octet 4…: VP8 bitstream
@@ -195,6 +205,9 @@ func (rp *RTPPacketizer) buildVP8Payload(packet RTPPacket, frameData []byte) []b
 
 	// Extension octet: I bit set (PictureID follows); L, T, K bits clear
 	payload[1] = 0x80 // I bit
+	if packet.KeyFrame {
+		payload[1] |= vp8KeyFrameBit
+	}
 
 	// PictureID: M bit set (15-bit form), upper 7 bits, then lower 8 bits
 	payload[2] = 0x80 | byte((packet.PictureID>>8)&0x7F) // M bit + high 7 bits
@@ -537,6 +550,12 @@ func (rd *RTPDepacketizer) parseVP8Payload(payload []byte) (uint16, []byte, bool
 	return pictureID, frameData, startOfPartition, nil
 }
 
+// IsKeyFramePayload reports whether a VP8 RTP payload was marked as part of
+// a key frame by RTPPacketizer. Only the payload descriptor is inspected.
+func IsKeyFramePayload(payload []byte) bool {
+	return len(payload) >= 2 && payload[0]&0x80 != 0 && payload[1]&vp8KeyFrameBit != 0
+}
+
 // reassembleFrame combines packets into complete frame data.
 func (rd *RTPDepacketizer) reassembleFrame(assembly *FrameAssembly) ([]byte, error) {
 	if err := rd.validatePacketAssembly(assembly); err != nil {
@@ -571,7 +590,7 @@ func (rd *RTPDepacketizer) detectSequenceGaps(packets []RTPPacket) {
 		expectedSeq++
 		if packet.SequenceNumber != expectedSeq {
 			// Log warning but continue - some applications might handle partial frames
-			logrus.WithFields(logrus.Fields{
+			pkgLog.WithFields(logrus.Fields{
 				"expected": expectedSeq,
 				"got":      packet.SequenceNumber,
 			}).Debug("sequence gap detected")
@@ -361,6 +361,41 @@ func TestVP8PayloadRFC7741RoundTrip(t *testing.T) {
 	}
 }
 
+func TestRTPPacketizer_KeyFrameBit(t *testing.T) {
+	codec := NewVP8Codec()
+	defer codec.Close()
+	frame := newKeyframeTestFrame()
+	packetizer := NewRTPPacketizer(12345)
+	require.NoError(t, packetizer.SetMaxPacketSize(200)) // Split frames across packets
+
+	_, err := codec.EncodeFrame(frame)
+	require.NoError(t, err)
+	inter, err := codec.EncodeFrame(frame)
+	require.NoError(t, err)
+	packets, err := packetizer.PacketizeFrame(inter, 90000, 1)
+	require.NoError(t, err)
+	for _, p := range packets {
+		assert.False(t, p.KeyFrame)
+		assert.False(t, IsKeyFramePayload(p.Payload))
+	}
+
+	key, err := codec.ForceKeyframe(frame)
+	require.NoError(t, err)
+	packets, err = packetizer.PacketizeFrame(key, 93000, 2)
+	require.NoError(t, err)
+	require.Greater(t, len(packets), 1)
+	for _, p := range packets {
+		assert.True(t, p.KeyFrame)
+		assert.True(t, IsKeyFramePayload(p.Payload), "key frame bit must be set on every packet")
+	}
+
+	// The marker bit does not disturb PictureID parsing.
+	depacketizer := NewRTPDepacketizer()
+	pictureID, _, _, err := depacketizer.parseVP8Payload(packets[0].Payload)
+	require.NoError(t, err)
+	assert.Equal(t, uint16(2), pictureID)
+}
+
 func TestRTPPacketizer_SetMaxPacketSize(t *testing.T) {
 	packetizer := NewRTPPacketizer(12345)
 
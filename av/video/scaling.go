@@ -21,13 +21,13 @@ type Scaler struct {
 
 // NewScaler creates a new video frame scaler.
 func NewScaler() *Scaler {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "NewScaler",
 	}).Info("Creating new video scaler")
 
 	scaler := &Scaler{}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "NewScaler",
 	}).Info("Video scaler created successfully")
 
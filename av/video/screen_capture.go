@@ -0,0 +1,341 @@
+// Package video provides screen capture as a video source for ToxAV.
+//
+// This file implements the platform-independent part of screen capture:
+// the capture loop, RGB to YUV420 conversion and scaling to the call
+// resolution. Grabbing the display is implemented per platform in the
+// screen_capture_<os>.go files.
+package video
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultScreenCaptureFPS is the frame rate of a ScreenCapture whose
+// TargetFPS is not set.
+const DefaultScreenCaptureFPS = 15
+
+// Screen capture errors.
+var (
+	// ErrNotSupported is returned by ScreenCapture.Start on platforms
+	// without a screen capture implementation, or when the capture tool the
+	// platform implementation relies on is not installed.
+	ErrNotSupported = errors.New("screen capture is not supported on this platform")
+
+	// ErrScreenCaptureRunning is returned by Start when capture is already running.
+	ErrScreenCaptureRunning = errors.New("screen capture is already running")
+
+	// ErrScreenCaptureStopped is returned by Stop when capture is not running.
+	ErrScreenCaptureStopped = errors.New("screen capture is not running")
+)
+
+// screenGrabber captures the current contents of the display.
+type screenGrabber interface {
+	Grab() (image.Image, error)
+}
+
+// screenStream is a screenGrabber backed by a capture process that runs
+// while capture is started. open starts it at the capture frame rate and
+// close stops it, unblocking a pending Grab.
+type screenStream interface {
+	screenGrabber
+	open(fps int) error
+	close() error
+}
+
+// ScreenCapture is a video source that captures the display for screen
+// sharing. Frames are converted to YUV420, scaled to the configured
+// resolution and delivered on FrameChan at TargetFPS:
+//
+//	capture := video.NewScreenCapture(1280, 720)
+//	if err := capture.Start(); err != nil {
+//	    return err // ErrNotSupported on platforms without screen capture
+//	}
+//	defer capture.Stop()
+//	for frame := range capture.FrameChan() {
+//	    // Encode and send frame
+//	}
+//
+// Frames the consumer does not receive in time are dropped, so a slow
+// encoder lowers the effective frame rate instead of delaying capture.
+type ScreenCapture struct {
+	// TargetFPS is the number of frames captured per second. Zero or a
+	// negative value selects DefaultScreenCaptureFPS. It is read by Start.
+	TargetFPS int
+
+	mu      sync.Mutex
+	width   uint16 // Output width; 0 keeps the captured size
+	height  uint16 // Output height; 0 keeps the captured size
+	grabber screenGrabber
+	scaler  *Scaler
+	frames  chan *VideoFrame
+	closed  bool // frames was closed by Stop
+	stop    chan struct{}
+	done    chan struct{}
+	running bool
+}
+
+// NewScreenCapture creates a screen capture source that scales frames to
+// width x height, normally the call's negotiated resolution. Pass zero for
+// both to deliver frames at the display's size, rounded down to even
+// dimensions.
+func NewScreenCapture(width, height uint16) *ScreenCapture {
+	return &ScreenCapture{
+		TargetFPS: DefaultScreenCaptureFPS,
+		width:     width,
+		height:    height,
+		scaler:    NewScaler(),
+		frames:    make(chan *VideoFrame, 1),
+	}
+}
+
+// SetResolution changes the output resolution, for example after the call's
+// resolution is renegotiated. It takes effect with the next frame.
+func (sc *ScreenCapture) SetResolution(width, height uint16) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	sc.width = width
+	sc.height = height
+}
+
+// FrameChan returns the channel frames are delivered on. It is closed by
+// Stop; Start opens a new channel, so call FrameChan again after restarting.
+func (sc *ScreenCapture) FrameChan() <-chan *VideoFrame {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	return sc.frames
+}
+
+// Start begins capturing the display. It returns ErrNotSupported when the
+// platform cannot capture the screen.
+func (sc *ScreenCapture) Start() error {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	if sc.running {
+		return ErrScreenCaptureRunning
+	}
+
+	if sc.grabber == nil {
+		grabber, err := newPlatformScreenGrabber()
+		if err != nil {
+			return err
+		}
+		sc.grabber = grabber
+	}
+
+	fps := sc.TargetFPS
+	if fps <= 0 {
+		fps = DefaultScreenCaptureFPS
+	}
+	if stream, ok := sc.grabber.(screenStream); ok {
+		if err := stream.open(fps); err != nil {
+			return err
+		}
+	}
+
+	// A channel closed by a previous Stop cannot be reused
+	if sc.closed {
+		sc.frames = make(chan *VideoFrame, 1)
+		sc.closed = false
+	}
+	sc.stop = make(chan struct{})
+	sc.done = make(chan struct{})
+	sc.running = true
+	go sc.captureLoop(time.Second/time.Duration(fps), sc.frames, sc.stop, sc.done)
+
+	pkgLog.WithFields(logrus.Fields{
+		"function":   "ScreenCapture.Start",
+		"target_fps": fps,
+		"width":      sc.width,
+		"height":     sc.height,
+	}).Info("Screen capture started")
+
+	return nil
+}
+
+// Stop ends capture and closes the frame channel.
+func (sc *ScreenCapture) Stop() error {
+	sc.mu.Lock()
+	if !sc.running {
+		sc.mu.Unlock()
+		return ErrScreenCaptureStopped
+	}
+	sc.running = false
+	sc.closed = true
+	close(sc.stop)
+	done := sc.done
+	stream, _ := sc.grabber.(screenStream)
+	sc.mu.Unlock()
+
+	if stream != nil {
+		if err := stream.close(); err != nil {
+			pkgLog.WithFields(logrus.Fields{
+				"function": "ScreenCapture.Stop",
+				"error":    err.Error(),
+			}).Warn("Failed to stop screen capture process")
+		}
+	}
+	<-done
+
+	pkgLog.WithFields(logrus.Fields{
+		"function": "ScreenCapture.Stop",
+	}).Info("Screen capture stopped")
+
+	return nil
+}
+
+// captureLoop grabs a frame every interval until stop is closed, then
+// closes frames and done. A stream grabber blocks until its process delivers
+// the next frame, so the loop then runs at the process's frame rate.
+func (sc *ScreenCapture) captureLoop(interval time.Duration, frames chan *VideoFrame, stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+	defer close(frames)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		frame, err := sc.captureFrame()
+		if err != nil {
+			select {
+			case <-stop:
+				// Stop ended the capture process mid-frame
+				return
+			default:
+			}
+			pkgLog.WithFields(logrus.Fields{
+				"function": "ScreenCapture.captureLoop",
+				"error":    err.Error(),
+			}).Warn("Failed to capture screen frame")
+		} else {
+			select {
+			case frames <- frame:
+			default:
+				// Consumer is behind; drop the frame
+			}
+		}
+
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// captureFrame grabs the display and converts it to a YUV420 frame at the
+// configured resolution.
+func (sc *ScreenCapture) captureFrame() (*VideoFrame, error) {
+	sc.mu.Lock()
+	grabber, width, height := sc.grabber, sc.width, sc.height
+	sc.mu.Unlock()
+
+	img, err := grabber.Grab()
+	if err != nil {
+		return nil, err
+	}
+	frame, err := imageToYUV420(img)
+	if err != nil {
+		return nil, err
+	}
+	if width == 0 || height == 0 || !sc.scaler.IsScalingRequired(frame.Width, frame.Height, width, height) {
+		return frame, nil
+	}
+	return sc.scaler.Scale(frame, width, height)
+}
+
+// imageToYUV420 converts img to a tightly packed BT.601 limited-range YUV420
+// frame, cropping an odd width or height by one pixel. Chroma is taken from
+// the top-left pixel of each 2x2 block. 4:2:0 YCbCr images are copied
+// without conversion.
+func imageToYUV420(img image.Image) (*VideoFrame, error) {
+	bounds := img.Bounds()
+	w, h := bounds.Dx()&^1, bounds.Dy()&^1
+	if w < 2 || h < 2 || w > 0xFFFF || h > 0xFFFF {
+		return nil, fmt.Errorf("unsupported screen size %dx%d", bounds.Dx(), bounds.Dy())
+	}
+	if ycc, ok := img.(*image.YCbCr); ok && ycc.SubsampleRatio == image.YCbCrSubsampleRatio420 {
+		return ycbcrToYUV420(ycc, w, h), nil
+	}
+
+	uvWidth := w / 2
+	frame := &VideoFrame{
+		Width:   uint16(w),
+		Height:  uint16(h),
+		Y:       make([]byte, w*h),
+		U:       make([]byte, uvWidth*(h/2)),
+		V:       make([]byte, uvWidth*(h/2)),
+		YStride: w,
+		UStride: uvWidth,
+		VStride: uvWidth,
+	}
+	pixel := rgbAt(img)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			r, g, b := pixel(bounds.Min.X+x, bounds.Min.Y+y)
+			frame.Y[y*w+x] = uint8((66*r+129*g+25*b+128)>>8 + 16)
+			if x&1 == 0 && y&1 == 0 {
+				i := (y/2)*uvWidth + x/2
+				frame.U[i] = uint8((-38*r-74*g+112*b+128)>>8 + 128)
+				frame.V[i] = uint8((112*r-94*g-18*b+128)>>8 + 128)
+			}
+		}
+	}
+	return frame, nil
+}
+
+// ycbcrToYUV420 copies the top-left w x h pixels of a 4:2:0 image into a
+// tightly packed frame. w and h must be even.
+func ycbcrToYUV420(img *image.YCbCr, w, h int) *VideoFrame {
+	uvWidth, uvHeight := w/2, h/2
+	frame := &VideoFrame{
+		Width:   uint16(w),
+		Height:  uint16(h),
+		Y:       make([]byte, w*h),
+		U:       make([]byte, uvWidth*uvHeight),
+		V:       make([]byte, uvWidth*uvHeight),
+		YStride: w,
+		UStride: uvWidth,
+		VStride: uvWidth,
+	}
+	origin := img.Rect.Min
+	for y := 0; y < h; y++ {
+		src := img.YOffset(origin.X, origin.Y+y)
+		copy(frame.Y[y*w:(y+1)*w], img.Y[src:src+w])
+	}
+	for y := 0; y < uvHeight; y++ {
+		src := img.COffset(origin.X, origin.Y+2*y)
+		copy(frame.U[y*uvWidth:(y+1)*uvWidth], img.Cb[src:src+uvWidth])
+		copy(frame.V[y*uvWidth:(y+1)*uvWidth], img.Cr[src:src+uvWidth])
+	}
+	return frame
+}
+
+// rgbAt returns a function reading the 8-bit RGB value of a pixel of img,
+// reading the pixel buffer directly for the RGBA layouts screen grabs use.
+// Screen pixels are opaque, so RGBA and NRGBA are read alike.
+func rgbAt(img image.Image) func(x, y int) (r, g, b int) {
+	var pix []uint8
+	var stride int
+	var rect image.Rectangle
+	switch m := img.(type) {
+	case *image.RGBA:
+		pix, stride, rect = m.Pix, m.Stride, m.Rect
+	case *image.NRGBA:
+		pix, stride, rect = m.Pix, m.Stride, m.Rect
+	default:
+		return func(x, y int) (int, int, int) {
+			r, g, b, _ := img.At(x, y).RGBA()
+			return int(r >> 8), int(g >> 8), int(b >> 8)
+		}
+	}
+	return func(x, y int) (int, int, int) {
+		i := (y-rect.Min.Y)*stride + (x-rect.Min.X)*4
+		return int(pix[i]), int(pix[i+1]), int(pix[i+2])
+	}
+}
@@ -0,0 +1,18 @@
+//go:build darwin
+
+package video
+
+import (
+	"fmt"
+	"os/exec"
+)
+
+// newPlatformScreenGrabber captures the main display with ffmpeg's
+// avfoundation device, so the pure Go build needs no cgo binding to
+// CGDisplayStream. ffmpeg runs for the whole capture.
+func newPlatformScreenGrabber() (screenGrabber, error) {
+	if _, err := exec.LookPath("ffmpeg"); err != nil {
+		return nil, fmt.Errorf("%w: install ffmpeg to capture the display", ErrNotSupported)
+	}
+	return &streamScreenGrabber{name: "ffmpeg", args: ffmpegScreenArgs("avfoundation", "Capture screen 0:none")}, nil
+}
@@ -0,0 +1,189 @@
+//go:build linux || darwin
+
+package video
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	// y4mMagic starts the stream header of a YUV4MPEG2 stream.
+	y4mMagic = "YUV4MPEG2"
+
+	// maxToolDiagnostics bounds the stderr output kept from a capture tool.
+	maxToolDiagnostics = 4096
+)
+
+// streamScreenGrabber captures the display with one long-lived capture
+// process that writes uncompressed YUV4MPEG2 video to stdout. The stream
+// header carries the display size, so frames are read without probing the
+// display first. Each Grab returns the next frame of the stream.
+type streamScreenGrabber struct {
+	name string
+	// args returns the capture tool's arguments for a frame rate.
+	args func(fps int) []string
+
+	cmd    *exec.Cmd
+	stderr *toolDiagnostics
+	r      *bufio.Reader
+	width  int
+	height int
+}
+
+// open starts the capture process at fps frames per second.
+func (g *streamScreenGrabber) open(fps int) error {
+	cmd := exec.Command(g.name, g.args(fps)...)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("failed to open %s output: %w", g.name, err)
+	}
+	stderr := &toolDiagnostics{}
+	cmd.Stderr = stderr
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start %s: %w", g.name, err)
+	}
+	g.cmd = cmd
+	g.stderr = stderr
+	g.r = bufio.NewReaderSize(stdout, 1<<20)
+	g.width, g.height = 0, 0
+	return nil
+}
+
+// close stops the capture process. A Grab blocked on the stream returns
+// once the process has exited.
+func (g *streamScreenGrabber) close() error {
+	if g.cmd == nil {
+		return nil
+	}
+	cmd := g.cmd
+	g.cmd = nil
+	if err := cmd.Process.Kill(); err != nil && !errors.Is(err, os.ErrProcessDone) {
+		return fmt.Errorf("failed to stop %s: %w", g.name, err)
+	}
+	// The process was killed, so its exit status carries no information
+	_ = cmd.Wait()
+	return nil
+}
+
+// Grab reads the next frame from the capture process, reading the stream
+// header first on the first call.
+func (g *streamScreenGrabber) Grab() (image.Image, error) {
+	if g.r == nil {
+		return nil, fmt.Errorf("%s is not running", g.name)
+	}
+	if g.width == 0 {
+		width, height, err := readY4MHeader(g.r)
+		if err != nil {
+			return nil, g.streamError(err)
+		}
+		g.width, g.height = width, height
+	}
+	img, err := readY4MFrame(g.r, g.width, g.height)
+	if err != nil {
+		return nil, g.streamError(err)
+	}
+	return img, nil
+}
+
+// streamError wraps a stream read error with the tool's diagnostics.
+func (g *streamScreenGrabber) streamError(err error) error {
+	if msg := strings.TrimSpace(g.stderr.String()); msg != "" {
+		return fmt.Errorf("%s stream failed: %w: %s", g.name, err, msg)
+	}
+	return fmt.Errorf("%s stream failed: %w", g.name, err)
+}
+
+// toolDiagnostics keeps the start of a capture tool's stderr output. It is
+// written by the process's copy goroutine and read by Grab.
+type toolDiagnostics struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+// Write records p up to maxToolDiagnostics bytes in total.
+func (d *toolDiagnostics) Write(p []byte) (int, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if room := maxToolDiagnostics - d.buf.Len(); room > 0 {
+		d.buf.Write(p[:min(len(p), room)])
+	}
+	return len(p), nil
+}
+
+// String returns the recorded output.
+func (d *toolDiagnostics) String() string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.buf.String()
+}
+
+// readY4MHeader reads a YUV4MPEG2 stream header and returns the frame size.
+// Only 4:2:0 chroma subsampling is accepted.
+func readY4MHeader(r *bufio.Reader) (width, height int, err error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return 0, 0, err
+	}
+	fields := strings.Fields(line)
+	if len(fields) == 0 || fields[0] != y4mMagic {
+		return 0, 0, errors.New("not a YUV4MPEG2 stream")
+	}
+	for _, field := range fields[1:] {
+		switch field[0] {
+		case 'W':
+			width, err = strconv.Atoi(field[1:])
+		case 'H':
+			height, err = strconv.Atoi(field[1:])
+		case 'C':
+			if !strings.HasPrefix(field, "C420") {
+				return 0, 0, fmt.Errorf("unsupported chroma subsampling %s", field[1:])
+			}
+		}
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid stream header field %q", field)
+		}
+	}
+	if width < 1 || height < 1 || width > 0xFFFF || height > 0xFFFF {
+		return 0, 0, fmt.Errorf("invalid stream size %dx%d", width, height)
+	}
+	return width, height, nil
+}
+
+// readY4MFrame reads one 4:2:0 frame of a YUV4MPEG2 stream.
+func readY4MFrame(r *bufio.Reader, width, height int) (*image.YCbCr, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(line, "FRAME") {
+		return nil, errors.New("missing YUV4MPEG2 frame marker")
+	}
+	img := image.NewYCbCr(image.Rect(0, 0, width, height), image.YCbCrSubsampleRatio420)
+	for _, plane := range [][]byte{img.Y, img.Cb, img.Cr} {
+		if _, err := io.ReadFull(r, plane); err != nil {
+			return nil, err
+		}
+	}
+	return img, nil
+}
+
+// ffmpegScreenArgs returns the arguments that make ffmpeg capture input with
+// the device demuxer and stream it to stdout as YUV4MPEG2.
+func ffmpegScreenArgs(device, input string) func(fps int) []string {
+	return func(fps int) []string {
+		return []string{
+			"-loglevel", "error", "-f", device, "-framerate", strconv.Itoa(fps), "-i", input,
+			"-f", "yuv4mpegpipe", "-pix_fmt", "yuv420p", "-",
+		}
+	}
+}
@@ -0,0 +1,75 @@
+//go:build linux || darwin
+
+package video
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStreamScreenGrabberReadsOneProcess(t *testing.T) {
+	// Two 4x2 frames followed by a process that stays alive until stopped
+	stream := "YUV4MPEG2 W4 H2 F15:1 Ip A1:1 C420jpeg\n"
+	for _, luma := range []byte{'a', 'b'} {
+		stream += "FRAME\n" + strings.Repeat(string(luma), 8) + "uuvv"
+	}
+	path := filepath.Join(t.TempDir(), "screen.y4m")
+	require.NoError(t, os.WriteFile(path, []byte(stream), 0o600))
+
+	var starts int
+	grabber := &streamScreenGrabber{name: "sh", args: func(fps int) []string {
+		starts++
+		return []string{"-c", `cat "$0"; exec sleep 30`, path}
+	}}
+	sc := NewScreenCapture(0, 0)
+	sc.TargetFPS = 100
+	sc.grabber = grabber
+	require.NoError(t, sc.Start())
+
+	for _, luma := range []byte{'a', 'b'} {
+		select {
+		case frame := <-sc.FrameChan():
+			assert.Equal(t, uint16(4), frame.Width)
+			assert.Equal(t, uint16(2), frame.Height)
+			assert.Equal(t, []byte(strings.Repeat(string(luma), 8)), frame.Y)
+			assert.Equal(t, []byte("uu"), frame.U)
+			assert.Equal(t, []byte("vv"), frame.V)
+		case <-time.After(5 * time.Second):
+			t.Fatal("no frame from the capture process")
+		}
+	}
+
+	stopped := make(chan error, 1)
+	go func() { stopped <- sc.Stop() }()
+	select {
+	case err := <-stopped:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Stop did not end the capture process")
+	}
+	assert.Equal(t, 1, starts, "one process serves every frame")
+}
+
+func TestReadY4MHeader(t *testing.T) {
+	width, height, err := readY4MHeader(bufio.NewReader(strings.NewReader("YUV4MPEG2 W1920 H1080 F30:1\n")))
+	require.NoError(t, err)
+	assert.Equal(t, 1920, width)
+	assert.Equal(t, 1080, height)
+
+	for _, header := range []string{
+		"YUV4MPEG2 W4 H2 C444\n",
+		"YUV4MPEG2 H2\n",
+		"YUV4MPEG2 Wx H2\n",
+		"P6 4 2 255\n",
+	} {
+		_, _, err := readY4MHeader(bufio.NewReader(strings.NewReader(header)))
+		assert.Error(t, err, header)
+	}
+}
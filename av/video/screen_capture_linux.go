@@ -0,0 +1,36 @@
+//go:build linux
+
+package video
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+)
+
+// newPlatformScreenGrabber picks a capture tool for the running display
+// server: wf-recorder on wlroots-based Wayland compositors, and ffmpeg's
+// x11grab device on X11. The tool runs for the whole capture. Without one of
+// them screen capture is not supported.
+func newPlatformScreenGrabber() (screenGrabber, error) {
+	if os.Getenv("WAYLAND_DISPLAY") != "" {
+		if _, err := exec.LookPath("wf-recorder"); err == nil {
+			return &streamScreenGrabber{name: "wf-recorder", args: func(fps int) []string {
+				return []string{
+					"-y", "-r", strconv.Itoa(fps), "-m", "yuv4mpegpipe", "-c", "rawvideo",
+					"-x", "yuv420p", "-f", "/dev/stdout",
+				}
+			}}, nil
+		}
+	}
+
+	display := os.Getenv("DISPLAY")
+	if display == "" {
+		return nil, fmt.Errorf("%w: no X11 or Wayland display", ErrNotSupported)
+	}
+	if _, err := exec.LookPath("ffmpeg"); err != nil {
+		return nil, fmt.Errorf("%w: install ffmpeg to capture the X11 display", ErrNotSupported)
+	}
+	return &streamScreenGrabber{name: "ffmpeg", args: ffmpegScreenArgs("x11grab", display)}, nil
+}
@@ -0,0 +1,9 @@
+//go:build !linux && !darwin && !windows
+
+package video
+
+// newPlatformScreenGrabber reports that this platform has no screen capture
+// implementation.
+func newPlatformScreenGrabber() (screenGrabber, error) {
+	return nil, ErrNotSupported
+}
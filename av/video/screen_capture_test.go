@@ -0,0 +1,125 @@
+package video
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeScreenGrabber returns a solid image and counts grabs.
+type fakeScreenGrabber struct {
+	mu    sync.Mutex
+	img   image.Image
+	err   error
+	grabs int
+}
+
+func (g *fakeScreenGrabber) Grab() (image.Image, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.grabs++
+	return g.img, g.err
+}
+
+func solidImage(w, h int, c color.RGBA) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for i := 0; i < len(img.Pix); i += 4 {
+		img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = c.R, c.G, c.B, c.A
+	}
+	return img
+}
+
+func TestImageToYUV420(t *testing.T) {
+	frame, err := imageToYUV420(solidImage(65, 33, color.RGBA{R: 255, G: 255, B: 255, A: 255}))
+	require.NoError(t, err)
+	assert.Equal(t, uint16(64), frame.Width, "odd width is cropped")
+	assert.Equal(t, uint16(32), frame.Height, "odd height is cropped")
+	assert.Len(t, frame.Y, 64*32)
+	assert.Len(t, frame.U, 32*16)
+	assert.Equal(t, byte(235), frame.Y[0])
+	assert.Equal(t, byte(128), frame.U[0])
+	assert.Equal(t, byte(128), frame.V[0])
+
+	// Generic images go through image.Image.At
+	gray := image.NewGray(image.Rect(0, 0, 16, 16))
+	frame, err = imageToYUV420(gray)
+	require.NoError(t, err)
+	assert.Equal(t, byte(16), frame.Y[0])
+
+	_, err = imageToYUV420(solidImage(1, 1, color.RGBA{}))
+	assert.Error(t, err)
+}
+
+func TestScreenCaptureDeliversScaledFrames(t *testing.T) {
+	grabber := &fakeScreenGrabber{img: solidImage(320, 240, color.RGBA{R: 10, G: 200, B: 30, A: 255})}
+	sc := NewScreenCapture(160, 120)
+	assert.Equal(t, DefaultScreenCaptureFPS, sc.TargetFPS)
+	sc.TargetFPS = 100
+	sc.grabber = grabber
+
+	require.NoError(t, sc.Start())
+	assert.ErrorIs(t, sc.Start(), ErrScreenCaptureRunning)
+
+	select {
+	case frame := <-sc.FrameChan():
+		require.NotNil(t, frame)
+		assert.Equal(t, uint16(160), frame.Width)
+		assert.Equal(t, uint16(120), frame.Height)
+	case <-time.After(2 * time.Second):
+		t.Fatal("No frame delivered")
+	}
+
+	sc.SetResolution(0, 0)
+	deadline := time.After(2 * time.Second)
+	for {
+		var frame *VideoFrame
+		select {
+		case frame = <-sc.FrameChan():
+		case <-deadline:
+			t.Fatal("No frame at the captured size")
+		}
+		if frame.Width == 320 {
+			break
+		}
+	}
+
+	require.NoError(t, sc.Stop())
+	assert.ErrorIs(t, sc.Stop(), ErrScreenCaptureStopped)
+	for range sc.FrameChan() {
+		// Drain until closed
+	}
+
+	// A stopped capture can be restarted with a new channel
+	require.NoError(t, sc.Start())
+	select {
+	case _, ok := <-sc.FrameChan():
+		assert.True(t, ok, "restarted capture must deliver on a new channel")
+	case <-time.After(2 * time.Second):
+		t.Fatal("No frame after restart")
+	}
+	require.NoError(t, sc.Stop())
+}
+
+func TestScreenCaptureSkipsFailedGrabs(t *testing.T) {
+	grabber := &fakeScreenGrabber{err: errors.New("display gone")}
+	sc := NewScreenCapture(0, 0)
+	sc.TargetFPS = 200
+	sc.grabber = grabber
+	require.NoError(t, sc.Start())
+
+	time.Sleep(50 * time.Millisecond)
+	require.NoError(t, sc.Stop())
+
+	grabber.mu.Lock()
+	grabs := grabber.grabs
+	grabber.mu.Unlock()
+	assert.Greater(t, grabs, 1, "capture continues after a failed grab")
+	_, ok := <-sc.FrameChan()
+	assert.False(t, ok, "failed grabs deliver no frames")
+}
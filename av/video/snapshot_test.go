@@ -0,0 +1,194 @@
+package video
+
+import (
+	"image"
+	"image/jpeg"
+	"image/png"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// yuv420ToRGBAScalar is the naive floating-point BT.601 reference that
+// yuv420ToRGBA is checked and benchmarked against.
+func yuv420ToRGBAScalar(frame *VideoFrame) *image.RGBA {
+	w, h := int(frame.Width), int(frame.Height)
+	cw := (w + 1) / 2
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	clamp := func(v float64) uint8 {
+		return uint8(math.Max(0, math.Min(255, math.Floor(v+0.5))))
+	}
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			luma := 1.164383 * (float64(frame.Y[y*w+x]) - 16)
+			u := float64(frame.U[(y/2)*cw+x/2]) - 128
+			v := float64(frame.V[(y/2)*cw+x/2]) - 128
+			i := img.PixOffset(x, y)
+			img.Pix[i] = clamp(luma + 1.596027*v)
+			img.Pix[i+1] = clamp(luma - 0.391762*u - 0.812968*v)
+			img.Pix[i+2] = clamp(luma + 2.017232*u)
+			img.Pix[i+3] = 0xFF
+		}
+	}
+	return img
+}
+
+// solidYUVFrame returns a w x h frame with every pixel set to (y, u, v).
+func solidYUVFrame(w, h int, y, u, v byte) *VideoFrame {
+	frame := &VideoFrame{
+		Width: uint16(w), Height: uint16(h),
+		Y: make([]byte, w*h), U: make([]byte, w*h/4), V: make([]byte, w*h/4),
+		YStride: w, UStride: w / 2, VStride: w / 2,
+	}
+	for i := range frame.Y {
+		frame.Y[i] = y
+	}
+	for i := range frame.U {
+		frame.U[i], frame.V[i] = u, v
+	}
+	return frame
+}
+
+// randomYUVFrame returns a frame with random plane contents.
+func randomYUVFrame(w, h int, seed int64) *VideoFrame {
+	rng := rand.New(rand.NewSource(seed))
+	frame := solidYUVFrame(w, h, 0, 0, 0)
+	rng.Read(frame.Y)
+	rng.Read(frame.U)
+	rng.Read(frame.V)
+	return frame
+}
+
+func TestYUV420ToRGBAKnownColors(t *testing.T) {
+	tests := []struct {
+		name    string
+		y, u, v byte
+		rgb     [3]uint8
+	}{
+		{"black", 16, 128, 128, [3]uint8{0, 0, 0}},
+		{"white", 235, 128, 128, [3]uint8{255, 255, 255}},
+		{"mid gray", 126, 128, 128, [3]uint8{128, 128, 128}},
+		{"red", 81, 90, 240, [3]uint8{255, 0, 0}},
+		{"green", 145, 54, 34, [3]uint8{0, 255, 0}},
+		{"blue", 41, 240, 110, [3]uint8{0, 0, 255}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img, err := yuv420ToRGBA(solidYUVFrame(4, 4, tt.y, tt.u, tt.v))
+			require.NoError(t, err)
+			px := img.RGBAAt(3, 3)
+			for i, got := range []uint8{px.R, px.G, px.B} {
+				assert.InDelta(t, tt.rgb[i], got, 2, "channel %d of %v", i, px)
+			}
+			assert.Equal(t, uint8(0xFF), px.A)
+		})
+	}
+}
+
+func TestYUV420ToRGBAMatchesScalar(t *testing.T) {
+	frame := randomYUVFrame(64, 48, 1)
+	got, err := yuv420ToRGBA(frame)
+	require.NoError(t, err)
+	want := yuv420ToRGBAScalar(frame)
+	for i := range want.Pix {
+		if d := int(got.Pix[i]) - int(want.Pix[i]); d < -1 || d > 1 {
+			t.Fatalf("byte %d: got %d, want %d", i, got.Pix[i], want.Pix[i])
+		}
+	}
+}
+
+func TestYUV420ToRGBAStridesAndErrors(t *testing.T) {
+	// Padded strides must read the same pixels as packed planes
+	packed := randomYUVFrame(16, 16, 2)
+	padded := &VideoFrame{Width: 16, Height: 16, YStride: 24, UStride: 12, VStride: 12,
+		Y: make([]byte, 24*16), U: make([]byte, 12*8), V: make([]byte, 12*8)}
+	for y := 0; y < 16; y++ {
+		copy(padded.Y[y*24:], packed.Y[y*16:y*16+16])
+	}
+	for y := 0; y < 8; y++ {
+		copy(padded.U[y*12:], packed.U[y*8:y*8+8])
+		copy(padded.V[y*12:], packed.V[y*8:y*8+8])
+	}
+	a, err := yuv420ToRGBA(packed)
+	require.NoError(t, err)
+	b, err := yuv420ToRGBA(padded)
+	require.NoError(t, err)
+	assert.Equal(t, a.Pix, b.Pix)
+
+	_, err = yuv420ToRGBA(nil)
+	assert.Error(t, err)
+	_, err = yuv420ToRGBA(&VideoFrame{Width: 16, Height: 16, Y: make([]byte, 10)})
+	assert.Error(t, err)
+}
+
+func TestProcessorCaptureSnapshot(t *testing.T) {
+	processor := NewProcessorWithSettings(16, 16, 100000)
+	defer processor.Close()
+
+	_, err := processor.CaptureSnapshot()
+	assert.ErrorIs(t, err, ErrNoSnapshotFrame)
+
+	_, err = processor.ProcessOutgoing(solidYUVFrame(16, 16, 235, 128, 128))
+	require.NoError(t, err)
+	img, err := processor.CaptureSnapshot()
+	require.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 16, 16), img.Bounds())
+	assert.Equal(t, uint8(255), img.RGBAAt(0, 0).R)
+}
+
+func TestSaveSnapshot(t *testing.T) {
+	dir := t.TempDir()
+	frame := solidYUVFrame(16, 16, 81, 90, 240) // Red
+
+	pngPath := filepath.Join(dir, "snap.png")
+	require.NoError(t, SaveSnapshotPNG(frame, pngPath))
+	f, err := os.Open(pngPath)
+	require.NoError(t, err)
+	decoded, err := png.Decode(f)
+	f.Close()
+	require.NoError(t, err)
+	r, g, b, _ := decoded.At(8, 8).RGBA()
+	assert.InDelta(t, 255, r>>8, 2)
+	assert.InDelta(t, 0, g>>8, 2)
+	assert.InDelta(t, 0, b>>8, 2)
+
+	jpegPath := filepath.Join(dir, "snap.jpg")
+	require.NoError(t, SaveSnapshotJPEG(frame, jpegPath, 90))
+	f, err = os.Open(jpegPath)
+	require.NoError(t, err)
+	_, err = jpeg.Decode(f)
+	f.Close()
+	require.NoError(t, err)
+
+	assert.Error(t, SaveSnapshotJPEG(frame, jpegPath, 0))
+	assert.Error(t, SaveSnapshotPNG(nil, pngPath))
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 2, "no temporary files are left behind")
+}
+
+func BenchmarkYUV420ToRGBA(b *testing.B) {
+	frame := randomYUVFrame(1280, 720, 3)
+	b.SetBytes(1280 * 720 * 4)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := yuv420ToRGBA(frame); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkYUV420ToRGBAScalar(b *testing.B) {
+	frame := randomYUVFrame(1280, 720, 3)
+	b.SetBytes(1280 * 720 * 4)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		yuv420ToRGBAScalar(frame)
+	}
+}
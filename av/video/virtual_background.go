@@ -0,0 +1,190 @@
+// Package video provides video effects processing capabilities for ToxAV.
+//
+// This file implements a virtual background effect that replaces the
+// background of YUV420 frames with a custom image using a foreground mask.
+package video
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// SegmentationFunc computes a foreground mask for a frame.
+//
+// The mask has one byte per luma pixel, row by row with a stride equal to
+// the frame width: 255 marks foreground, 0 marks background, and values in
+// between blend the two, which softens the edge around the subject.
+type SegmentationFunc func(frame *VideoFrame) ([]byte, error)
+
+// VirtualBackgroundEffect replaces the background of video frames with a
+// custom image. Foreground pixels come from the input frame and background
+// pixels from the background image, as selected by a SegmentationFunc.
+//
+// The background is scaled to the size of each input frame with a Scaler;
+// the scaled copy is cached until the frame size or the background changes.
+// UpdateBackground may be called from another goroutine while frames are
+// being processed.
+type VirtualBackgroundEffect struct {
+	mu         sync.Mutex
+	background *VideoFrame
+	scaled     *VideoFrame // background scaled to the last frame size
+	mask       SegmentationFunc
+	scaler     *Scaler
+}
+
+// NewVirtualBackgroundEffect creates an effect that composites frames over
+// background using the foreground mask computed by mask.
+func NewVirtualBackgroundEffect(background *VideoFrame, mask SegmentationFunc) (*VirtualBackgroundEffect, error) {
+	if mask == nil {
+		return nil, fmt.Errorf("segmentation function cannot be nil")
+	}
+	if err := validateCompositeFrame(background); err != nil {
+		return nil, fmt.Errorf("invalid background: %w", err)
+	}
+
+	pkgLog.WithFields(logrus.Fields{
+		"function":          "NewVirtualBackgroundEffect",
+		"background_width":  background.Width,
+		"background_height": background.Height,
+	}).Info("Virtual background effect created")
+
+	return &VirtualBackgroundEffect{
+		background: copyFrame(background),
+		mask:       mask,
+		scaler:     NewScaler(),
+	}, nil
+}
+
+// UpdateBackground replaces the background image, for example with the
+// next frame of an animation or a live feed. It takes effect from the next
+// call to Apply.
+func (vb *VirtualBackgroundEffect) UpdateBackground(frame *VideoFrame) error {
+	if err := validateCompositeFrame(frame); err != nil {
+		return fmt.Errorf("invalid background: %w", err)
+	}
+
+	vb.mu.Lock()
+	defer vb.mu.Unlock()
+	vb.background = copyFrame(frame)
+	vb.scaled = nil
+	return nil
+}
+
+// Apply composites the foreground of frame over the background image.
+func (vb *VirtualBackgroundEffect) Apply(frame *VideoFrame) (*VideoFrame, error) {
+	if err := validateCompositeFrame(frame); err != nil {
+		return nil, err
+	}
+
+	mask, err := vb.mask(frame)
+	if err != nil {
+		return nil, fmt.Errorf("segmentation failed: %w", err)
+	}
+	width, height := int(frame.Width), int(frame.Height)
+	if len(mask) < width*height {
+		return nil, fmt.Errorf("mask has %d bytes, need %d for %dx%d frame", len(mask), width*height, width, height)
+	}
+
+	background, err := vb.scaledBackground(frame.Width, frame.Height)
+	if err != nil {
+		return nil, err
+	}
+
+	result := copyFrame(frame)
+	ys, us, vs := planeStrides(result)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			alpha := mask[y*width+x]
+			result.Y[y*ys+x] = blendPixel(frame.Y[y*ys+x], background.Y[y*background.YStride+x], alpha)
+		}
+	}
+
+	// Chroma is subsampled 2x2, so each chroma sample uses the mean of the
+	// four mask values it covers.
+	for y := 0; y < height/2; y++ {
+		for x := 0; x < width/2; x++ {
+			i := 2*y*width + 2*x
+			alpha := byte((int(mask[i]) + int(mask[i+1]) + int(mask[i+width]) + int(mask[i+width+1]) + 2) / 4)
+			result.U[y*us+x] = blendPixel(frame.U[y*us+x], background.U[y*background.UStride+x], alpha)
+			result.V[y*vs+x] = blendPixel(frame.V[y*vs+x], background.V[y*background.VStride+x], alpha)
+		}
+	}
+
+	return result, nil
+}
+
+// scaledBackground returns the background scaled to width x height,
+// reusing the cached copy when the size is unchanged.
+func (vb *VirtualBackgroundEffect) scaledBackground(width, height uint16) (*VideoFrame, error) {
+	vb.mu.Lock()
+	defer vb.mu.Unlock()
+
+	if vb.scaled != nil && vb.scaled.Width == width && vb.scaled.Height == height {
+		return vb.scaled, nil
+	}
+
+	scaled, err := vb.scaler.Scale(vb.background, width, height)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scale background: %w", err)
+	}
+	vb.scaled = scaled
+
+	pkgLog.WithFields(logrus.Fields{
+		"function": "VirtualBackgroundEffect.Apply",
+		"width":    width,
+		"height":   height,
+	}).Debug("Scaled virtual background to frame size")
+
+	return scaled, nil
+}
+
+// GetName returns the effect name.
+func (vb *VirtualBackgroundEffect) GetName() string {
+	return "VirtualBackground"
+}
+
+// validateCompositeFrame checks that a frame has even dimensions and planes
+// large enough for its size and strides.
+func validateCompositeFrame(frame *VideoFrame) error {
+	if frame == nil {
+		return fmt.Errorf("input frame cannot be nil")
+	}
+	if frame.Width == 0 || frame.Height == 0 || frame.Width%2 != 0 || frame.Height%2 != 0 {
+		return fmt.Errorf("frame dimensions must be even and non-zero: %dx%d", frame.Width, frame.Height)
+	}
+
+	ys, us, vs := planeStrides(frame)
+	width, height := int(frame.Width), int(frame.Height)
+	if ys < width || us < width/2 || vs < width/2 {
+		return fmt.Errorf("frame strides too small for width %d", width)
+	}
+	if len(frame.Y) < ys*(height-1)+width ||
+		len(frame.U) < us*(height/2-1)+width/2 ||
+		len(frame.V) < vs*(height/2-1)+width/2 {
+		return fmt.Errorf("frame planes too small for %dx%d", width, height)
+	}
+	return nil
+}
+
+// planeStrides returns the frame's strides, treating zero as tightly packed.
+func planeStrides(frame *VideoFrame) (ys, us, vs int) {
+	ys, us, vs = frame.YStride, frame.UStride, frame.VStride
+	if ys == 0 {
+		ys = int(frame.Width)
+	}
+	if us == 0 {
+		us = int(frame.Width / 2)
+	}
+	if vs == 0 {
+		vs = int(frame.Width / 2)
+	}
+	return ys, us, vs
+}
+
+// blendPixel mixes foreground and background by alpha, where 255 is fully
+// foreground.
+func blendPixel(fg, bg, alpha byte) byte {
+	return byte((int(fg)*int(alpha) + int(bg)*(255-int(alpha)) + 127) / 255)
+}
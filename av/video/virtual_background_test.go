@@ -0,0 +1,112 @@
+package video
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// solidFrame returns a frame with every pixel set to the given YUV values.
+func solidFrame(width, height uint16, y, u, v byte) *VideoFrame {
+	frame := createTestFrame(width, height)
+	for i := range frame.Y {
+		frame.Y[i] = y
+	}
+	for i := range frame.U {
+		frame.U[i] = u
+		frame.V[i] = v
+	}
+	return frame
+}
+
+// leftHalfMask marks the left half of each frame as foreground.
+func leftHalfMask(frame *VideoFrame) ([]byte, error) {
+	width, height := int(frame.Width), int(frame.Height)
+	mask := make([]byte, width*height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width/2; x++ {
+			mask[y*width+x] = 255
+		}
+	}
+	return mask, nil
+}
+
+func TestVirtualBackgroundEffectComposite(t *testing.T) {
+	// The background is smaller than the input and must be scaled up.
+	background := solidFrame(32, 32, 200, 90, 160)
+	effect, err := NewVirtualBackgroundEffect(background, leftHalfMask)
+	require.NoError(t, err)
+	assert.Equal(t, "VirtualBackground", effect.GetName())
+
+	input := solidFrame(64, 48, 50, 128, 128)
+	result, err := effect.Apply(input)
+	require.NoError(t, err)
+	require.Equal(t, input.Width, result.Width)
+	require.Equal(t, input.Height, result.Height)
+
+	row := 10 * result.YStride
+	assert.Equal(t, byte(50), result.Y[row+5], "foreground luma should come from the input")
+	assert.Equal(t, byte(200), result.Y[row+60], "background luma should come from the background")
+	chromaRow := 5 * result.UStride
+	assert.Equal(t, byte(128), result.U[chromaRow+2])
+	assert.Equal(t, byte(90), result.U[chromaRow+30])
+	assert.Equal(t, byte(160), result.V[chromaRow+30])
+	assert.Equal(t, byte(50), input.Y[row+60], "input frame must not be modified")
+}
+
+func TestVirtualBackgroundEffectBlendsEdges(t *testing.T) {
+	effect, err := NewVirtualBackgroundEffect(solidFrame(16, 16, 200, 128, 128), func(frame *VideoFrame) ([]byte, error) {
+		mask := make([]byte, int(frame.Width)*int(frame.Height))
+		for i := range mask {
+			mask[i] = 128
+		}
+		return mask, nil
+	})
+	require.NoError(t, err)
+
+	result, err := effect.Apply(solidFrame(16, 16, 0, 128, 128))
+	require.NoError(t, err)
+	assert.Equal(t, byte(100), result.Y[0], "half mask should average foreground and background")
+}
+
+func TestVirtualBackgroundEffectUpdateBackground(t *testing.T) {
+	effect, err := NewVirtualBackgroundEffect(solidFrame(16, 16, 200, 128, 128), leftHalfMask)
+	require.NoError(t, err)
+
+	input := solidFrame(32, 32, 50, 128, 128)
+	result, err := effect.Apply(input)
+	require.NoError(t, err)
+	assert.Equal(t, byte(200), result.Y[31])
+
+	require.NoError(t, effect.UpdateBackground(solidFrame(16, 16, 20, 128, 128)))
+	result, err = effect.Apply(input)
+	require.NoError(t, err)
+	assert.Equal(t, byte(20), result.Y[31], "new background should replace the cached one")
+
+	assert.Error(t, effect.UpdateBackground(nil))
+	assert.Error(t, effect.UpdateBackground(&VideoFrame{Width: 15, Height: 16}))
+}
+
+func TestVirtualBackgroundEffectErrors(t *testing.T) {
+	_, err := NewVirtualBackgroundEffect(solidFrame(16, 16, 0, 128, 128), nil)
+	assert.Error(t, err, "nil segmentation function should be rejected")
+	_, err = NewVirtualBackgroundEffect(nil, leftHalfMask)
+	assert.Error(t, err, "nil background should be rejected")
+
+	segErr := errors.New("model unavailable")
+	effect, err := NewVirtualBackgroundEffect(solidFrame(16, 16, 0, 128, 128), func(*VideoFrame) ([]byte, error) {
+		return nil, segErr
+	})
+	require.NoError(t, err)
+	_, err = effect.Apply(solidFrame(16, 16, 0, 128, 128))
+	assert.ErrorIs(t, err, segErr)
+
+	short, err := NewVirtualBackgroundEffect(solidFrame(16, 16, 0, 128, 128), func(*VideoFrame) ([]byte, error) {
+		return make([]byte, 10), nil
+	})
+	require.NoError(t, err)
+	_, err = short.Apply(solidFrame(16, 16, 0, 128, 128))
+	assert.Error(t, err, "short mask should be rejected")
+}
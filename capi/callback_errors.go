@@ -0,0 +1,131 @@
+package main
+
+/*
+#include <stdlib.h>
+
+// Callback type for errors raised while dispatching a registered callback
+typedef void (*tox_callback_error_cb)(const char *callback_name, const char *error);
+
+static inline void invoke_callback_error_cb(tox_callback_error_cb cb, const char *callback_name, const char *error) {
+    if (cb != NULL) {
+        cb(callback_name, error);
+    }
+}
+*/
+import "C"
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"unsafe"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ErrToxAVHandleMissing indicates a ToxAV callback fired after its C handle
+// or callback storage was released.
+var ErrToxAVHandleMissing = errors.New("toxav handle not registered")
+
+// callbackErrorHandlers stores the per-instance callback error handlers
+var (
+	callbackErrorHandlers   = make(map[int]C.tox_callback_error_cb)
+	callbackErrorHandlersMu sync.RWMutex
+)
+
+// toxcore_set_callback_error_handler registers a handler that is invoked
+// whenever dispatching a callback for this Tox instance (or a ToxAV instance
+// created from it) fails. The handler receives the callback name and the
+// error message as NUL-terminated strings that are only valid for the
+// duration of the call. Passing NULL removes the handler.
+//
+// This is a toxcore-go extension; libtoxcore callbacks have no error channel.
+//
+//export toxcore_set_callback_error_handler
+func toxcore_set_callback_error_handler(tox unsafe.Pointer, handler C.tox_callback_error_cb) {
+	_, toxID, ok := registerToxCallback(tox, handler, nil, &callbackErrorHandlersMu, callbackErrorHandlers)
+	if !ok {
+		return
+	}
+	logrus.WithField("tox_id", toxID).Debug("Callback error handler registered")
+}
+
+// reportCallbackError marshals err to C strings and invokes the error
+// handler registered for toxID. Errors are always logged.
+func reportCallbackError(toxID int, callbackName string, err error) {
+	if err == nil {
+		return
+	}
+	logrus.WithFields(logrus.Fields{
+		"function": "reportCallbackError",
+		"tox_id":   toxID,
+		"callback": callbackName,
+		"error":    err.Error(),
+	}).Warn("Callback dispatch failed")
+
+	callbackErrorHandlersMu.RLock()
+	handler := callbackErrorHandlers[toxID]
+	callbackErrorHandlersMu.RUnlock()
+	if handler == nil {
+		return
+	}
+
+	cName := C.CString(callbackName)
+	defer C.free(unsafe.Pointer(cName))
+	cErr := C.CString(err.Error())
+	defer C.free(unsafe.Pointer(cErr))
+	C.invoke_callback_error_cb(handler, cName, cErr)
+}
+
+// recoverCallback converts a panic in a callback bridge into a reported
+// error. It must be deferred directly by the bridge.
+func recoverCallback(toxID int, callbackName string) {
+	if r := recover(); r != nil {
+		reportCallbackError(toxID, callbackName, fmt.Errorf("callback panicked: %v", r))
+	}
+}
+
+// toxIDForToxAV resolves the Tox instance ID that owns a ToxAV instance.
+func toxIDForToxAV(toxavID uintptr) (int, bool) {
+	toxPtr, exists := toxavRegistry.GetToxPtr(toxavID)
+	if !exists {
+		return 0, false
+	}
+	return safeGetToxID(toxPtr)
+}
+
+// reportToxAVCallbackError reports err through the handler of the Tox
+// instance that owns toxavID.
+func reportToxAVCallbackError(toxavID uintptr, callbackName string, err error) {
+	toxID, ok := toxIDForToxAV(toxavID)
+	if !ok {
+		logrus.WithFields(logrus.Fields{
+			"function": "reportToxAVCallbackError",
+			"toxav_id": toxavID,
+			"callback": callbackName,
+			"error":    err.Error(),
+		}).Warn("Callback dispatch failed for unregistered ToxAV instance")
+		return
+	}
+	reportCallbackError(toxID, callbackName, err)
+}
+
+// recoverToxAVCallback converts a panic in a ToxAV callback bridge into a
+// reported error. It must be deferred directly by the bridge.
+func recoverToxAVCallback(toxavID uintptr, callbackName string) {
+	if r := recover(); r != nil {
+		reportToxAVCallbackError(toxavID, callbackName, fmt.Errorf("callback panicked: %v", r))
+	}
+}
+
+// resolveToxAVCallback returns the callback storage and C handle for a
+// ToxAV instance, reporting ErrToxAVHandleMissing when either is gone.
+func resolveToxAVCallback(toxavID uintptr, callbackName string) (*toxavCallbacks, unsafe.Pointer, bool) {
+	callbacks, cbExists := toxavRegistry.GetCallbacks(toxavID)
+	handle, handleExists := toxavRegistry.GetHandle(toxavID)
+	if !cbExists || !handleExists {
+		reportToxAVCallbackError(toxavID, callbackName, ErrToxAVHandleMissing)
+		return nil, nil, false
+	}
+	return callbacks, handle, true
+}
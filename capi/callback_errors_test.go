@@ -0,0 +1,54 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestCallbackErrorHandlerRegistration tests handler registration and removal
+func TestCallbackErrorHandlerRegistration(t *testing.T) {
+	// Invalid pointers must not crash
+	toxcore_set_callback_error_handler(nil, nil)
+
+	tox := tox_new()
+	if tox == nil {
+		t.Fatal("Failed to create Tox instance")
+	}
+	toxcore_set_callback_error_handler(tox, nil)
+
+	toxID, ok := safeGetToxID(tox)
+	if !ok {
+		t.Fatal("Failed to resolve Tox ID")
+	}
+	// Reporting without a handler only logs
+	reportCallbackError(toxID, "friend_message_cb", errors.New("test failure"))
+
+	tox_kill(tox)
+	callbackErrorHandlersMu.RLock()
+	_, exists := callbackErrorHandlers[toxID]
+	callbackErrorHandlersMu.RUnlock()
+	if exists {
+		t.Error("Expected handler entry to be removed by tox_kill")
+	}
+}
+
+// TestRecoverCallbackSwallowsPanic tests that bridge panics are converted to reports
+func TestRecoverCallbackSwallowsPanic(t *testing.T) {
+	func() {
+		defer recoverCallback(-1, "friend_request_cb")
+		panic("bridge failure")
+	}()
+
+	func() {
+		defer recoverToxAVCallback(0, "toxav_call_cb")
+		panic("bridge failure")
+	}()
+}
+
+// TestResolveToxAVCallbackMissingHandle tests that a missing handle is reported
+func TestResolveToxAVCallbackMissingHandle(t *testing.T) {
+	callbacks, handle, ok := resolveToxAVCallback(^uintptr(0), "toxav_call_cb")
+	if ok || callbacks != nil || handle != nil {
+		t.Error("Expected resolution to fail for an unknown ToxAV ID")
+	}
+}
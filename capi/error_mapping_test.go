@@ -419,3 +419,38 @@ func TestToxAVWithNilPointers(t *testing.T) {
 		t.Error("Answer should fail with nil pointer")
 	}
 }
+
+// TestToxAVRecordingAndScreenShareWithoutCall tests the recording and screen
+// share extensions fail cleanly when no call is active.
+func TestToxAVRecordingAndScreenShareWithoutCall(t *testing.T) {
+	if toxav_stop_recording(nil, 0, nil) {
+		t.Error("Expected false for toxav_stop_recording with nil")
+	}
+	if toxav_start_screen_share(nil, 0, nil) {
+		t.Error("Expected false for toxav_start_screen_share with nil")
+	}
+	toxav_callback_recording_stats(nil, nil, nil)
+
+	tox := tox_new()
+	if tox == nil {
+		t.Fatal("Failed to create Tox instance")
+	}
+	defer tox_kill(tox)
+
+	toxav := toxav_new(tox, nil)
+	if toxav == nil {
+		t.Fatal("Failed to create ToxAV instance")
+	}
+	defer toxav_kill(toxav)
+
+	toxav_callback_recording_stats(toxav, nil, nil)
+	if toxav_stop_recording(toxav, 999, nil) {
+		t.Error("Expected false when stopping a recording that was never started")
+	}
+	if toxav_start_screen_share(toxav, 999, nil) {
+		t.Error("Expected false for screen share with non-existent friend")
+	}
+	if toxav_stop_screen_share(toxav, 999, nil) {
+		t.Error("Expected false when stopping a screen share that was never started")
+	}
+}
@@ -0,0 +1,603 @@
+#!/usr/bin/env python3
+"""Generate ctypes bindings for the toxcore-go C API.
+
+Parses the cgo-generated libtoxcore.h with pycparser and writes a toxcore.py
+module containing:
+
+  * ctypes.CDLL loading of libtoxcore
+  * argtypes/restype declarations for every exported function
+  * enum constants and ctypes.CFUNCTYPE callback types
+  * Tox and ToxAV classes wrapping the functions as methods, with docstrings
+    taken from the Go doc comments above each //export directive
+
+Usage:
+
+    go build -buildmode=c-shared -o capi/libtoxcore.so ./capi
+    python3 capi/generate_python.py
+
+Requires pycparser and a C preprocessor (cc -E).
+"""
+
+import argparse
+import os
+import re
+import subprocess
+import sys
+import textwrap
+
+from pycparser import c_ast, c_parser
+
+HERE = os.path.dirname(os.path.abspath(__file__))
+
+# Types normally supplied by system headers, which are stripped before parsing.
+PRELUDE = """
+typedef unsigned long size_t;
+typedef long ptrdiff_t;
+typedef signed char int8_t;
+typedef unsigned char uint8_t;
+typedef short int16_t;
+typedef unsigned short uint16_t;
+typedef int int32_t;
+typedef unsigned int uint32_t;
+typedef long long int64_t;
+typedef unsigned long long uint64_t;
+#define NULL ((void*)0)
+#define bool _Bool
+#define true 1
+#define false 0
+"""
+
+# Base C types to ctypes names.
+BASE_TYPES = {
+    "void": "None",
+    "char": "ctypes.c_char",
+    "signed char": "ctypes.c_int8",
+    "unsigned char": "ctypes.c_uint8",
+    "short": "ctypes.c_int16",
+    "unsigned short": "ctypes.c_uint16",
+    "int": "ctypes.c_int",
+    "unsigned int": "ctypes.c_uint",
+    "long": "ctypes.c_long",
+    "unsigned long": "ctypes.c_ulong",
+    "long long": "ctypes.c_longlong",
+    "unsigned long long": "ctypes.c_ulonglong",
+    "float": "ctypes.c_float",
+    "double": "ctypes.c_double",
+    "_Bool": "ctypes.c_bool",
+}
+
+# Typedef names with a direct ctypes equivalent.
+NAMED_TYPES = {
+    "size_t": "ctypes.c_size_t",
+    "ptrdiff_t": "ctypes.c_ssize_t",
+    "int8_t": "ctypes.c_int8",
+    "uint8_t": "ctypes.c_uint8",
+    "int16_t": "ctypes.c_int16",
+    "uint16_t": "ctypes.c_uint16",
+    "int32_t": "ctypes.c_int32",
+    "uint32_t": "ctypes.c_uint32",
+    "int64_t": "ctypes.c_int64",
+    "uint64_t": "ctypes.c_uint64",
+}
+
+ERROR_PARAM_NAMES = ("error_ptr", "err", "error")
+
+
+def preprocess(header_path):
+    """Strips includes and line markers, then runs the C preprocessor."""
+    with open(header_path) as f:
+        lines = [
+            line for line in f
+            if not line.lstrip().startswith(("#include", "#line"))
+        ]
+    source = PRELUDE + "".join(lines)
+    cc = os.environ.get("CC", "cc")
+    result = subprocess.run(
+        [cc, "-E", "-P", "-x", "c", "-D_Complex=", "-D__attribute__(x)=", "-"],
+        input=source, capture_output=True, text=True, check=True,
+    )
+    return result.stdout
+
+
+def parse_go_docs(go_dir):
+    """Returns {function name: doc comment} for //export directives."""
+    docs = {}
+    for name in sorted(os.listdir(go_dir)):
+        if not name.endswith(".go") or name.endswith("_test.go"):
+            continue
+        with open(os.path.join(go_dir, name)) as f:
+            lines = f.read().splitlines()
+        for i, line in enumerate(lines):
+            m = re.match(r"^//export (\w+)", line)
+            if not m:
+                continue
+            comment = []
+            j = i - 1
+            while j >= 0 and lines[j].startswith("//"):
+                comment.append(lines[j][2:].strip())
+                j -= 1
+            comment.reverse()
+            while comment and not comment[-1]:
+                comment.pop()
+            docs[m.group(1)] = "\n".join(comment)
+    return docs
+
+
+class Param:
+    def __init__(self, name, ctype, kind, target=None):
+        self.name = name
+        self.ctype = ctype      # ctypes expression
+        self.kind = kind        # "value", "pointer", "string", "voidp", "callback"
+        self.target = target    # pointee typedef/enum name for pointers
+
+
+class Function:
+    def __init__(self, name, restype, params, doc):
+        self.name = name
+        self.restype = restype
+        self.params = params
+        self.doc = doc
+
+
+class HeaderModel:
+    """Typedefs, enums, callbacks and functions extracted from the header."""
+
+    def __init__(self):
+        self.typedefs = {}      # name -> AST type
+        self.enums = {}         # enum typedef name -> [(name, value)]
+        self.callbacks = {}     # typedef name -> (restype, [Param])
+        self.functions = []
+
+    def load(self, text, docs):
+        ast = c_parser.CParser().parse(text)
+        for node in ast.ext:
+            if isinstance(node, c_ast.Typedef):
+                self.typedefs[node.name] = node.type
+                self._record_typedef(node)
+        for node in ast.ext:
+            if isinstance(node, c_ast.Decl) and isinstance(node.type, c_ast.FuncDecl):
+                # Skip cgo runtime helpers such as _GoStringLen
+                if "extern" in (node.storage or []) and not node.name.startswith("_"):
+                    self.functions.append(self._function(node, docs.get(node.name, "")))
+
+    def _record_typedef(self, node):
+        t = node.type
+        if isinstance(t, c_ast.TypeDecl) and isinstance(t.type, c_ast.Enum) and t.type.values:
+            values, current = [], -1
+            for e in t.type.values.enumerators:
+                current = int(e.value.value, 0) if e.value is not None else current + 1
+                values.append((e.name, current))
+            self.enums[node.name] = values
+        elif isinstance(t, c_ast.PtrDecl) and isinstance(t.type, c_ast.FuncDecl):
+            fd = t.type
+            self.callbacks[node.name] = (self.ctype(fd.type), self._params(fd))
+
+    def _function(self, node, doc):
+        fd = node.type
+        return Function(node.name, self.ctype(fd.type), self._params(fd), doc)
+
+    def _params(self, fd):
+        params = []
+        for i, p in enumerate(fd.args.params if fd.args else []):
+            if isinstance(p, c_ast.Typename) and self.ctype(p.type) == "None":
+                continue  # (void)
+            params.append(self.param(p.name or "arg%d" % i, p.type))
+        return params
+
+    def param(self, name, t):
+        if isinstance(t, c_ast.PtrDecl):
+            inner = t.type
+            if isinstance(inner, c_ast.TypeDecl) and isinstance(inner.type, c_ast.IdentifierType):
+                names = " ".join(inner.type.names)
+                if names == "void":
+                    return Param(name, "ctypes.c_void_p", "voidp")
+                if names == "char":
+                    return Param(name, "ctypes.c_char_p", "string")
+                return Param(name, "ctypes.POINTER(%s)" % self.ctype(inner), "pointer", names)
+            return Param(name, "ctypes.c_void_p", "voidp")
+        if isinstance(t, c_ast.TypeDecl) and isinstance(t.type, c_ast.IdentifierType):
+            typename = " ".join(t.type.names)
+            if typename in self.callbacks:
+                return Param(name, typename, "callback", typename)
+        return Param(name, self.ctype(t), "value")
+
+    def ctype(self, t):
+        """Returns the ctypes expression for a type node."""
+        if isinstance(t, c_ast.PtrDecl):
+            inner = t.type
+            if isinstance(inner, c_ast.TypeDecl) and isinstance(inner.type, c_ast.IdentifierType):
+                names = " ".join(inner.type.names)
+                if names == "void":
+                    return "ctypes.c_void_p"
+                if names == "char":
+                    return "ctypes.c_char_p"
+            return "ctypes.POINTER(%s)" % self.ctype(inner)
+        if isinstance(t, c_ast.TypeDecl):
+            t = t.type
+        if isinstance(t, c_ast.Enum):
+            return "ctypes.c_int"
+        if isinstance(t, c_ast.IdentifierType):
+            names = " ".join(t.names)
+            if names in BASE_TYPES:
+                return BASE_TYPES[names]
+            if names in NAMED_TYPES:
+                return NAMED_TYPES[names]
+            if names in self.callbacks:
+                return names
+            if names in self.enums:
+                return "ctypes.c_int"
+            if names in self.typedefs:
+                return self.ctype(self.typedefs[names])
+        return "ctypes.c_void_p"
+
+    def callback_for(self, func, param):
+        """Resolves the callback typedef for a callback parameter.
+
+        Parameters typed as void* in the Go API (tox_callback_friend_*) are
+        matched by name: tox_callback_<event> takes <event>_cb.
+        """
+        if param.kind == "callback":
+            return param.target
+        if param.kind == "voidp" and param.name == "callback":
+            m = re.match(r"^tox_callback_(\w+)$", func.name)
+            if m and m.group(1) + "_cb" in self.callbacks:
+                return m.group(1) + "_cb"
+        return None
+
+
+def error_param(func):
+    if func.params and func.params[-1].name in ERROR_PARAM_NAMES and func.params[-1].kind == "pointer":
+        return func.params[-1]
+    return None
+
+
+def docstring(doc, indent):
+    doc = doc.replace("\\", "\\\\").replace('"""', "'''").strip() or "No documentation."
+    body = textwrap.indent(doc, indent)
+    return '%s"""%s\n%s"""\n' % (indent, body.lstrip(), indent) if "\n" in doc else '%s"""%s"""\n' % (indent, doc)
+
+
+class Emitter:
+    def __init__(self, model):
+        self.model = model
+        self.out = []
+
+    def w(self, s=""):
+        self.out.append(s)
+
+    def emit(self):
+        self.w(MODULE_HEADER)
+        self.emit_enums()
+        self.emit_callbacks()
+        self.emit_declarations()
+        self.emit_functions()
+        self.emit_class("Tox", "tox", "tox_", TOX_INIT)
+        self.emit_class("ToxAV", "av", "toxav_", TOXAV_INIT)
+        return "\n".join(self.out) + "\n"
+
+    def emit_enums(self):
+        self.w("# Enumerations")
+        self.w()
+        for enum, values in sorted(self.model.enums.items()):
+            for name, value in values:
+                self.w("%s = %d" % (name, value))
+            self.w("_ENUM_NAMES[%r] = {%s}" % (enum, ", ".join("%d: %r" % (v, n) for n, v in values)))
+            self.w()
+
+    def emit_callbacks(self):
+        self.w("# Callback function types")
+        self.w()
+        for name, (restype, params) in sorted(self.model.callbacks.items()):
+            self.w("%s = ctypes.CFUNCTYPE(%s)" % (name, ", ".join([restype] + [p.ctype for p in params])))
+        self.w()
+        self.w("# Callback argument names, used to convert raw arguments for Python callables")
+        self.w("_CALLBACK_PARAMS = {")
+        for name, (_, params) in sorted(self.model.callbacks.items()):
+            self.w("    %r: %r," % (name, [p.name for p in params]))
+        self.w("}")
+        self.w()
+
+    def emit_declarations(self):
+        self.w()
+        self.w("def _declare(lib):")
+        self.w('    """Declares argtypes and restype for every exported function."""')
+        for f in self.model.functions:
+            self.w("    lib.%s.argtypes = [%s]" % (f.name, ", ".join(p.ctype for p in f.params)))
+            self.w("    lib.%s.restype = %s" % (f.name, f.restype))
+        self.w()
+        self.w()
+        self.w("_lib = _declare_loaded(_load_library(), _declare)")
+        self.w()
+
+    def emit_functions(self):
+        self.w()
+        self.w("# Raw functions")
+        for f in self.model.functions:
+            args = ", ".join(p.name for p in f.params)
+            self.w()
+            self.w()
+            self.w("def %s(%s):" % (f.name, args))
+            self.out.append(docstring(f.doc, "    ").rstrip("\n"))
+            self.w("    return _lib.%s(%s)" % (f.name, args))
+
+    def emit_class(self, cls, handle, prefix, init):
+        funcs = [
+            f for f in self.model.functions
+            if f.name.startswith(prefix) and f.params and f.params[0].name == handle
+            and f.params[0].kind == "voidp"
+        ]
+        self.w()
+        self.w()
+        self.out.append(init)
+        for f in funcs:
+            if f.name in (prefix + "kill",):
+                continue
+            method = f.name[len(prefix):]
+            rest = f.params[1:]
+            err = error_param(f)
+            cb_index = next((i for i, p in enumerate(rest) if self.model.callback_for(f, p)), None)
+            self.w()
+            if cb_index is not None:
+                self.emit_callback_method(f, method, rest, cb_index)
+                continue
+            visible = [p for p in rest if p is not err]
+            self.w("    def %s(self%s):" % (method, "".join(", " + p.name for p in visible)))
+            self.out.append(docstring(f.doc, "        ").rstrip("\n"))
+            self.w("        self._check_open()")
+            call_args = ["self._ptr"]
+            for p in rest:
+                if p is err:
+                    self.w("        _err = %s(0)" % p.ctype.replace("ctypes.POINTER(", "", 1)[:-1])
+                    call_args.append("ctypes.byref(_err)")
+                elif p.kind == "string":
+                    call_args.append("_as_cstring(%s)" % p.name)
+                elif p.kind == "pointer":
+                    call_args.append("_as_pointer(%s, %s)" % (p.name, p.ctype))
+                else:
+                    call_args.append(p.name)
+            self.w("        result = _lib.%s(%s)" % (f.name, ", ".join(call_args)))
+            self.w("        self._raise_pending()")
+            if err is not None:
+                self.w("        _check_error(%r, _err.value, %r)" % (f.name, err.target))
+            self.w("        return result")
+
+    def emit_callback_method(self, f, method, rest, cb_index):
+        cb_param = rest[cb_index]
+        cb_type = self.model.callback_for(f, cb_param)
+        self.w("    def %s(self, callback):" % method)
+        doc = f.doc + "\n\nThe callable receives the C callback arguments without the instance\n" \
+            "handle and user data. Pointer/length pairs are passed as bytes.\n" \
+            "Exceptions raised by the callable are re-raised from the next\nmethod call on this instance."
+        self.out.append(docstring(doc, "        ").rstrip("\n"))
+        self.w("        self._check_open()")
+        self.w("        cfunc = self._wrap_callback(%r, %s, callback)" % (cb_type, cb_type))
+        args = ["self._ptr"]
+        for i, p in enumerate(rest):
+            if i == cb_index:
+                args.append("ctypes.cast(cfunc, ctypes.c_void_p)" if cb_param.kind == "voidp" else "cfunc")
+            else:
+                args.append("None")
+        self.w("        _lib.%s(%s)" % (f.name, ", ".join(args)))
+
+
+MODULE_HEADER = '''"""ctypes bindings for the toxcore-go C API.
+
+Code generated by capi/generate_python.py from libtoxcore.h; DO NOT EDIT.
+
+The shared library is located through the TOXCORE_LIBRARY environment
+variable, then next to this module, then in the parent capi directory, and
+finally on the system library path.
+"""
+
+import ctypes
+import ctypes.util
+import os
+import sys
+import threading
+
+_ENUM_NAMES = {}
+
+
+class ToxError(Exception):
+    """Raised when a C API call reports an error code."""
+
+    def __init__(self, function, code, code_name=None):
+        self.function = function
+        self.code = code
+        self.code_name = code_name
+        super().__init__("%s failed: %s" % (function, code_name or code))
+
+
+def _library_names():
+    if sys.platform == "darwin":
+        return ["libtoxcore.dylib", "libtoxcore.so"]
+    if sys.platform == "win32":
+        return ["libtoxcore.dll", "toxcore.dll"]
+    return ["libtoxcore.so"]
+
+
+def _load_library():
+    path = os.environ.get("TOXCORE_LIBRARY")
+    if path:
+        return ctypes.CDLL(path)
+    here = os.path.dirname(os.path.abspath(__file__))
+    for directory in (here, os.path.dirname(here)):
+        for name in _library_names():
+            candidate = os.path.join(directory, name)
+            if os.path.exists(candidate):
+                return ctypes.CDLL(candidate)
+    found = ctypes.util.find_library("toxcore")
+    if found:
+        return ctypes.CDLL(found)
+    raise OSError("libtoxcore not found; build it with "
+                  "`go build -buildmode=c-shared -o capi/libtoxcore.so ./capi` "
+                  "or set TOXCORE_LIBRARY")
+
+
+def _declare_loaded(lib, declare):
+    declare(lib)
+    return lib
+
+
+def _check_error(function, code, enum_name):
+    if code != 0:
+        raise ToxError(function, code, _ENUM_NAMES.get(enum_name, {}).get(code))
+
+
+def _as_cstring(value):
+    if value is None or isinstance(value, bytes):
+        return value
+    return str(value).encode("utf-8")
+
+
+def _as_pointer(value, ptype):
+    """Converts bytes, bytearray or a ctypes array to a typed pointer."""
+    if value is None:
+        return None
+    if isinstance(value, bytearray):
+        buf = (ptype._type_ * len(value)).from_buffer(value)
+        return ctypes.cast(buf, ptype)
+    if isinstance(value, bytes):
+        return ctypes.cast(ctypes.create_string_buffer(value, len(value)), ptype)
+    if isinstance(value, ctypes.Array):
+        return ctypes.cast(value, ptype)
+    return value
+
+
+def _convert_callback_args(names, args):
+    """Drops handle and user data and collapses pointer/length pairs to bytes."""
+    out = []
+    skip = False
+    for i, (name, value) in enumerate(zip(names, args)):
+        if skip:
+            skip = False
+            continue
+        if (i == 0 and name in ("tox", "av")) or name in ("user_data", "userData"):
+            continue
+        if i + 1 < len(names) and names[i + 1] in ("length", "%s_length" % name, "size"):
+            out.append(ctypes.string_at(value, args[i + 1]) if value else b"")
+            skip = True
+            continue
+        if name == "public_key" and value:
+            out.append(ctypes.string_at(value, 32))
+            continue
+        out.append(value)
+    return out
+
+
+class _Handle:
+    """Shared lifecycle and callback handling for Tox and ToxAV."""
+
+    _ptr = None
+
+    def __init__(self):
+        self._callbacks = {}
+        self._pending = []
+        self._pending_lock = threading.Lock()
+
+    def _check_open(self):
+        if not self._ptr:
+            raise ValueError("%s instance is closed" % type(self).__name__)
+
+    def _wrap_callback(self, name, cfunctype, func):
+        names = _CALLBACK_PARAMS[name]
+
+        def trampoline(*args):
+            if func is None:
+                return None
+            try:
+                return func(*_convert_callback_args(names, args))
+            except BaseException as exc:  # re-raised on the caller's thread
+                with self._pending_lock:
+                    self._pending.append(exc)
+            return None
+
+        cfunc = cfunctype(trampoline) if func is not None else ctypes.cast(None, cfunctype)
+        # Keep a reference so the C function pointer stays valid
+        self._callbacks[name] = cfunc
+        return cfunc
+
+    def _raise_pending(self):
+        with self._pending_lock:
+            if not self._pending:
+                return
+            exc = self._pending.pop(0)
+        raise exc
+
+    def __enter__(self):
+        return self
+
+    def __exit__(self, *exc):
+        self.kill()
+
+    def __del__(self):
+        try:
+            self.kill()
+        except Exception:
+            pass
+'''
+
+TOX_INIT = '''class Tox(_Handle):
+    """A toxcore-go instance created with tox_new and released with tox_kill."""
+
+    def __init__(self):
+        super().__init__()
+        self._ptr = _lib.tox_new()
+        if not self._ptr:
+            raise ToxError("tox_new", -1)
+
+    def kill(self):
+        """Releases the instance. Safe to call more than once."""
+        if self._ptr:
+            _lib.tox_kill(self._ptr)
+            self._ptr = None
+            self._callbacks.clear()
+
+    def address(self):
+        """Returns the binary Tox address."""
+        self._check_open()
+        buf = (ctypes.c_uint8 * _lib.tox_self_get_address_size(self._ptr))()
+        if _lib.tox_self_get_address(self._ptr, buf) != 0:
+            raise ToxError("tox_self_get_address", -1)
+        return bytes(buf)
+'''
+
+TOXAV_INIT = '''class ToxAV(_Handle):
+    """A ToxAV instance sharing the handle of an existing Tox instance."""
+
+    def __init__(self, tox):
+        super().__init__()
+        self.tox = tox
+        err = ctypes.c_int(0)
+        self._ptr = _lib.toxav_new(tox._ptr, ctypes.byref(err))
+        if not self._ptr:
+            raise ToxError("toxav_new", err.value, _ENUM_NAMES["TOX_AV_ERR_NEW"].get(err.value))
+
+    def kill(self):
+        """Releases the instance. Safe to call more than once."""
+        if self._ptr:
+            _lib.toxav_kill(self._ptr)
+            self._ptr = None
+            self._callbacks.clear()
+'''
+
+
+def main():
+    parser = argparse.ArgumentParser(description=__doc__.splitlines()[0])
+    parser.add_argument("--header", default=os.path.join(HERE, "libtoxcore.h"))
+    parser.add_argument("--go-dir", default=HERE, help="directory with the //export Go sources")
+    parser.add_argument("--output", default=os.path.join(HERE, "python", "toxcore.py"))
+    args = parser.parse_args()
+
+    model = HeaderModel()
+    model.load(preprocess(args.header), parse_go_docs(args.go_dir))
+    source = Emitter(model).emit()
+
+    os.makedirs(os.path.dirname(args.output), exist_ok=True)
+    with open(args.output, "w") as f:
+        f.write(source)
+    print("wrote %s (%d functions)" % (args.output, len(model.functions)), file=sys.stderr)
+
+
+if __name__ == "__main__":
+    main()
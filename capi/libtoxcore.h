@@ -12,6 +12,8 @@
 
 #ifndef GO_CGO_GOSTRING_TYPEDEF
 typedef struct { const char *p; ptrdiff_t n; } _GoString_;
+extern size_t _GoStringLen(_GoString_ s);
+extern const char *_GoStringPtr(_GoString_ s);
 #endif
 
 #endif
@@ -19,6 +21,21 @@ typedef struct { const char *p; ptrdiff_t n; } _GoString_;
 /* Start of preamble from import "C" comments.  */
 
 
+#line 3 "callback_errors.go"
+
+#include <stdlib.h>
+
+// Callback type for errors raised while dispatching a registered callback
+typedef void (*tox_callback_error_cb)(const char *callback_name, const char *error);
+
+static inline void invoke_callback_error_cb(tox_callback_error_cb cb, const char *callback_name, const char *error) {
+    if (cb != NULL) {
+        cb(callback_name, error);
+    }
+}
+
+#line 1 "cgo-generated-wrapper"
+
 #line 18 "toxav_c.go"
 
 #include <stdint.h>
@@ -106,6 +123,35 @@ typedef enum TOX_AV_ERR_SEND_FRAME {
     TOX_AV_ERR_SEND_FRAME_RTP_FAILED = 7,
 } TOX_AV_ERR_SEND_FRAME;
 
+// Recording and screen share extensions (not part of libtoxcore)
+typedef enum TOX_AV_RECORDING_FORMAT {
+    TOX_AV_RECORDING_FORMAT_WAV = 0,
+    TOX_AV_RECORDING_FORMAT_RAW = 1,
+} TOX_AV_RECORDING_FORMAT;
+
+typedef enum TOX_AV_ERR_RECORDING {
+    TOX_AV_ERR_RECORDING_OK = 0,
+    TOX_AV_ERR_RECORDING_NULL = 1,
+    TOX_AV_ERR_RECORDING_SYNC = 2,
+    TOX_AV_ERR_RECORDING_FRIEND_NOT_FOUND = 3,
+    TOX_AV_ERR_RECORDING_FRIEND_NOT_IN_CALL = 4,
+    TOX_AV_ERR_RECORDING_ALREADY_ACTIVE = 5,
+    TOX_AV_ERR_RECORDING_NOT_ACTIVE = 6,
+    TOX_AV_ERR_RECORDING_INVALID_FORMAT = 7,
+    TOX_AV_ERR_RECORDING_IO = 8,
+} TOX_AV_ERR_RECORDING;
+
+typedef enum TOX_AV_ERR_SCREEN_SHARE {
+    TOX_AV_ERR_SCREEN_SHARE_OK = 0,
+    TOX_AV_ERR_SCREEN_SHARE_SYNC = 1,
+    TOX_AV_ERR_SCREEN_SHARE_FRIEND_NOT_FOUND = 2,
+    TOX_AV_ERR_SCREEN_SHARE_FRIEND_NOT_IN_CALL = 3,
+    TOX_AV_ERR_SCREEN_SHARE_VIDEO_DISABLED = 4,
+    TOX_AV_ERR_SCREEN_SHARE_ALREADY_ACTIVE = 5,
+    TOX_AV_ERR_SCREEN_SHARE_NOT_ACTIVE = 6,
+    TOX_AV_ERR_SCREEN_SHARE_NOT_SUPPORTED = 7,
+} TOX_AV_ERR_SCREEN_SHARE;
+
 // Callback function types matching libtoxcore exactly
 typedef void (*toxav_call_cb)(ToxAV *av, uint32_t friend_number, bool audio_enabled, bool video_enabled, void *user_data);
 typedef void (*toxav_call_state_cb)(ToxAV *av, uint32_t friend_number, uint32_t state, void *user_data);
@@ -113,6 +159,7 @@ typedef void (*toxav_audio_bit_rate_cb)(ToxAV *av, uint32_t friend_number, uint3
 typedef void (*toxav_video_bit_rate_cb)(ToxAV *av, uint32_t friend_number, uint32_t video_bit_rate, void *user_data);
 typedef void (*toxav_audio_receive_frame_cb)(ToxAV *av, uint32_t friend_number, const int16_t *pcm, size_t sample_count, uint8_t channels, uint32_t sampling_rate, void *user_data);
 typedef void (*toxav_video_receive_frame_cb)(ToxAV *av, uint32_t friend_number, uint16_t width, uint16_t height, const uint8_t *y, const uint8_t *u, const uint8_t *v, int32_t ystride, int32_t ustride, int32_t vstride, void *user_data);
+typedef void (*toxav_recording_stats_cb)(ToxAV *av, uint32_t friend_number, uint64_t bytes_written, void *user_data);
 
 // Bridge functions to invoke C callbacks from Go
 // These are necessary because Go cannot directly call C function pointers
@@ -153,6 +200,12 @@ static inline void invoke_video_receive_frame_cb(toxav_video_receive_frame_cb cb
     }
 }
 
+static inline void invoke_recording_stats_cb(toxav_recording_stats_cb cb, ToxAV *av, uint32_t friend_number, uint64_t bytes_written, void *user_data) {
+    if (cb != NULL) {
+        cb(av, friend_number, bytes_written, user_data);
+    }
+}
+
 #line 1 "cgo-generated-wrapper"
 
 #line 3 "toxcore_c.go"
@@ -160,6 +213,18 @@ static inline void invoke_video_receive_frame_cb(toxav_video_receive_frame_cb cb
 #include <stdint.h>
 #include <stdlib.h>
 
+// Callback type for friend requests
+typedef void (*friend_request_cb)(void *tox, const uint8_t *public_key,
+                                  const uint8_t *message, size_t length, void *user_data);
+
+// Callback type for friend messages
+typedef void (*friend_message_cb)(void *tox, uint32_t friend_number,
+                                  const uint8_t *message, size_t length, void *user_data);
+
+// Callback type for friend connection status changes
+typedef void (*friend_connection_status_cb)(void *tox, uint32_t friend_number,
+                                           uint8_t connection_status, void *user_data);
+
 // Callback type for conference messages
 typedef void (*group_message_cb)(void *tox, uint32_t conference_number, uint32_t peer_number,
                                  int type, const uint8_t *message, size_t length, void *user_data);
@@ -182,6 +247,62 @@ typedef void (*file_recv_chunk_cb)(void *tox, uint32_t friend_number, uint32_t f
 typedef void (*file_chunk_request_cb)(void *tox, uint32_t friend_number, uint32_t file_number,
                                       uint64_t position, size_t length, void *user_data);
 
+// Bridge functions to invoke C callbacks from Go
+static inline void invoke_friend_request_cb(friend_request_cb cb, void *tox,
+                                           const uint8_t *public_key,
+                                           const uint8_t *message, size_t length,
+                                           void *user_data) {
+    if (cb != NULL) {
+        cb(tox, public_key, message, length, user_data);
+    }
+}
+
+static inline void invoke_friend_message_cb(friend_message_cb cb, void *tox,
+                                           uint32_t friend_number,
+                                           const uint8_t *message, size_t length,
+                                           void *user_data) {
+    if (cb != NULL) {
+        cb(tox, friend_number, message, length, user_data);
+    }
+}
+
+static inline void invoke_friend_connection_status_cb(friend_connection_status_cb cb, void *tox,
+                                                     uint32_t friend_number,
+                                                     uint8_t connection_status,
+                                                     void *user_data) {
+    if (cb != NULL) {
+        cb(tox, friend_number, connection_status, user_data);
+    }
+}
+
+static inline void invoke_file_recv_cb(file_recv_cb cb, void *tox,
+                                       uint32_t friend_number, uint32_t file_number,
+                                       uint32_t kind, uint64_t file_size,
+                                       const uint8_t *filename, size_t filename_length,
+                                       void *user_data) {
+    if (cb != NULL) {
+        cb(tox, friend_number, file_number, kind, file_size, filename, filename_length, user_data);
+    }
+}
+
+static inline void invoke_file_recv_chunk_cb(file_recv_chunk_cb cb, void *tox,
+                                             uint32_t friend_number, uint32_t file_number,
+                                             uint64_t position, const uint8_t *data,
+                                             size_t length, void *user_data) {
+    if (cb != NULL) {
+        cb(tox, friend_number, file_number, position, data, length, user_data);
+    }
+}
+
+static inline void invoke_file_chunk_request_cb(file_chunk_request_cb cb, void *tox,
+                                                uint32_t friend_number, uint32_t file_number,
+                                                uint64_t position, size_t length,
+                                                void *user_data) {
+    if (cb != NULL) {
+        cb(tox, friend_number, file_number, position, length, user_data);
+    }
+}
+
 #line 1 "cgo-generated-wrapper"
 
 
@@ -208,10 +329,16 @@ typedef size_t GoUintptr;
 typedef float GoFloat32;
 typedef double GoFloat64;
 #ifdef _MSC_VER
+#if !defined(__cplusplus) || _MSVC_LANG <= 201402L
 #include <complex.h>
 typedef _Fcomplex GoComplex64;
 typedef _Dcomplex GoComplex128;
 #else
+#include <complex>
+typedef std::complex<float> GoComplex64;
+typedef std::complex<double> GoComplex128;
+#endif
+#else
 typedef float _Complex GoComplex64;
 typedef double _Complex GoComplex128;
 #endif
@@ -238,232 +365,102 @@ typedef struct { void *data; GoInt len; GoInt cap; } GoSlice;
 extern "C" {
 #endif
 
-
-// toxav_new creates a new ToxAV instance from a Tox instance.
-//
-// This function matches the libtoxcore toxav_new API exactly.
-//
+extern void toxcore_set_callback_error_handler(void* tox, tox_callback_error_cb handler);
 extern void* toxav_new(void* tox, TOX_AV_ERR_NEW* error_ptr);
-
-// toxav_kill gracefully shuts down a ToxAV instance.
-//
-// This function matches the libtoxcore toxav_kill API exactly.
-//
 extern void toxav_kill(void* av);
-
-// toxav_get_tox_from_av returns the Tox instance associated with ToxAV.
-//
-// This function matches the libtoxcore toxav_get_tox_from_av API exactly.
-//
 extern void* toxav_get_tox_from_av(void* av);
-
-// toxav_iteration_interval returns the iteration interval for ToxAV.
-//
-// This function matches the libtoxcore toxav_iteration_interval API exactly.
-//
 extern uint32_t toxav_iteration_interval(void* av);
-
-// toxav_iterate performs one iteration of the ToxAV event loop.
-//
-// This function matches the libtoxcore toxav_iterate API exactly.
-//
 extern void toxav_iterate(void* av);
-
-// toxav_call initiates an audio/video call.
-//
-// This function matches the libtoxcore toxav_call API exactly.
-//
 extern _Bool toxav_call(void* av, uint32_t friend_number, uint32_t audio_bit_rate, uint32_t video_bit_rate, TOX_AV_ERR_CALL* error_ptr);
-
-// toxav_answer accepts an incoming audio/video call.
-//
-// This function matches the libtoxcore toxav_answer API exactly.
-//
 extern _Bool toxav_answer(void* av, uint32_t friend_number, uint32_t audio_bit_rate, uint32_t video_bit_rate, TOX_AV_ERR_ANSWER* error_ptr);
-
-// toxav_call_control sends a call control command.
-//
-// This function matches the libtoxcore toxav_call_control API exactly.
-//
 extern _Bool toxav_call_control(void* av, uint32_t friend_number, TOX_AV_CALL_CONTROL control, TOX_AV_ERR_CALL_CONTROL* error_ptr);
-
-// toxav_audio_set_bit_rate sets the audio bit rate for a call.
-//
-// This function matches the libtoxcore toxav_audio_set_bit_rate API exactly.
-//
 extern _Bool toxav_audio_set_bit_rate(void* av, uint32_t friend_number, uint32_t bit_rate, TOX_AV_ERR_BIT_RATE_SET* error_ptr);
-
-// toxav_video_set_bit_rate sets the video bit rate for a call.
-//
-// This function matches the libtoxcore toxav_video_set_bit_rate API exactly.
-//
 extern _Bool toxav_video_set_bit_rate(void* av, uint32_t friend_number, uint32_t bit_rate, TOX_AV_ERR_BIT_RATE_SET* error_ptr);
-
-// toxav_audio_send_frame sends an audio frame.
-//
-// This function matches the libtoxcore toxav_audio_send_frame API exactly.
-//
 extern _Bool toxav_audio_send_frame(void* av, uint32_t friend_number, int16_t* pcm, size_t sample_count, uint8_t channels, uint32_t sampling_rate, TOX_AV_ERR_SEND_FRAME* error_ptr);
-
-// toxav_video_send_frame sends a video frame.
-//
-// This function matches the libtoxcore toxav_video_send_frame API exactly.
-//
 extern _Bool toxav_video_send_frame(void* av, uint32_t friend_number, uint16_t width, uint16_t height, uint8_t* y, uint8_t* u, uint8_t* v, TOX_AV_ERR_SEND_FRAME* error_ptr);
 extern void toxav_callback_call(void* av, toxav_call_cb callback, void* user_data);
 extern void toxav_callback_call_state(void* av, toxav_call_state_cb callback, void* user_data);
 extern void toxav_callback_audio_bit_rate(void* av, toxav_audio_bit_rate_cb callback, void* user_data);
 extern void toxav_callback_video_bit_rate(void* av, toxav_video_bit_rate_cb callback, void* user_data);
-// The pcm pointer is only valid during callback execution; copy before return
-// if data must be retained.
 extern void toxav_callback_audio_receive_frame(void* av, toxav_audio_receive_frame_cb callback, void* user_data);
-// The y/u/v plane pointers are only valid during callback execution; copy
-// before return if data must be retained.
 extern void toxav_callback_video_receive_frame(void* av, toxav_video_receive_frame_cb callback, void* user_data);
-extern void* tox_new();
+extern _Bool toxav_start_recording(void* av, uint32_t friend_number, char* path, TOX_AV_RECORDING_FORMAT format, TOX_AV_ERR_RECORDING* error_ptr);
+extern _Bool toxav_stop_recording(void* av, uint32_t friend_number, TOX_AV_ERR_RECORDING* error_ptr);
+extern _Bool toxav_start_screen_share(void* av, uint32_t friend_number, TOX_AV_ERR_SCREEN_SHARE* error_ptr);
+extern _Bool toxav_stop_screen_share(void* av, uint32_t friend_number, TOX_AV_ERR_SCREEN_SHARE* error_ptr);
+extern void toxav_callback_recording_stats(void* av, toxav_recording_stats_cb callback, void* user_data);
+extern void* tox_new(void);
 extern void tox_kill(void* tox);
 extern GoInt tox_bootstrap_simple(void* tox);
 extern void tox_iterate(void* tox);
 extern GoInt tox_iteration_interval(void* tox);
 extern GoInt tox_self_get_address_size(void* tox);
 extern GoInt hex_string_to_bin(GoUint8* hexStr, GoInt hexLen, GoUint8* output, GoInt outputLen);
-
-// tox_self_get_address copies the Tox address to the provided buffer.
-// The buffer must be at least TOX_ADDRESS_SIZE (38) bytes.
-// Returns 0 on success, -1 on error.
-//
 extern GoInt tox_self_get_address(void* tox, GoUint8* address);
-
-// tox_self_get_public_key copies the public key to the provided buffer.
-// The buffer must be at least TOX_PUBLIC_KEY_SIZE (32) bytes.
-// Returns 0 on success, -1 on error.
-//
 extern GoInt tox_self_get_public_key(void* tox, GoUint8* publicKey);
-
-// tox_friend_add adds a friend by Tox address and sends a friend request message.
-// Returns the friend number on success, or UINT32_MAX on failure.
-//
 extern GoUint32 tox_friend_add(void* tox, GoUint8* address, GoUint8* message, GoInt messageLen);
-
-// tox_friend_add_norequest adds a friend by public key without sending a request.
-// Use this to accept incoming friend requests.
-// Returns the friend number on success, or UINT32_MAX on failure.
-//
 extern GoUint32 tox_friend_add_norequest(void* tox, GoUint8* publicKey);
-
-// tox_friend_delete removes a friend from the friends list.
-// Returns 0 on success, -1 on failure.
-//
 extern GoInt tox_friend_delete(void* tox, GoUint32 friendNumber);
-
-// tox_friend_send_message sends a message to a friend.
-// messageType: 0 = normal message, 1 = action message.
-// Returns the message ID on success (always 1 for now), or 0 on failure.
-//
 extern GoUint32 tox_friend_send_message(void* tox, GoUint32 friendNumber, GoInt messageType, GoUint8* message, GoInt messageLen);
-
-// tox_callback_friend_request registers a callback for friend requests.
-// The callback receives: tox pointer, public key (32 bytes), message, message length, user data.
-//
 extern void tox_callback_friend_request(void* tox, void* callback, void* userData);
-
-// tox_callback_friend_message registers a callback for friend messages.
-// The callback receives: tox pointer, friend number, message type, message, message length, user data.
-//
 extern void tox_callback_friend_message(void* tox, void* callback, void* userData);
-
-// tox_callback_friend_connection_status registers a callback for friend connection status changes.
-// The callback receives: tox pointer, friend number, connection status, user data.
-//
 extern void tox_callback_friend_connection_status(void* tox, void* callback, void* userData);
-
-// tox_self_set_name sets the name of this Tox instance.
-// Returns 0 on success, -1 on error.
-//
 extern GoInt tox_self_set_name(void* tox, GoUint8* name, GoInt nameLen);
-
-// tox_self_get_name_size returns the length of the name.
-//
 extern GoInt tox_self_get_name_size(void* tox);
-
-// tox_self_get_name copies the name to the provided buffer.
-// Returns 0 on success, -1 on error.
-//
 extern GoInt tox_self_get_name(void* tox, GoUint8* name);
-
-// tox_self_set_status_message sets the status message of this Tox instance.
-// Returns 0 on success, -1 on error.
-//
 extern GoInt tox_self_set_status_message(void* tox, GoUint8* message, GoInt messageLen);
-
-// tox_self_get_status_message_size returns the length of the status message.
-//
 extern GoInt tox_self_get_status_message_size(void* tox);
-
-// tox_self_get_status_message copies the status message to the provided buffer.
-// Returns 0 on success, -1 on error.
-//
 extern GoInt tox_self_get_status_message(void* tox, GoUint8* message);
-
-// tox_conference_new creates a new conference (group chat).
-// Returns the conference ID on success, or UINT32_MAX on failure.
-//
 extern GoUint32 tox_conference_new(void* tox, GoUint32* err);
-
-// tox_conference_invite invites a friend to a conference.
-// Returns 0 on success, non-zero on error.
-//
 extern GoInt tox_conference_invite(void* tox, GoUint32 friendID, GoUint32 conferenceID, GoUint32* err);
-
-// tox_conference_send_message sends a message to a conference.
-// Returns 0 on success, non-zero on error.
-//
 extern GoInt tox_conference_send_message(void* tox, GoUint32 conferenceID, GoInt msgType, GoUint8* message, GoUint32 length, GoUint32* err);
-
-// tox_conference_delete leaves and deletes a conference.
-// Returns 0 on success, non-zero on error.
-//
 extern GoInt tox_conference_delete(void* tox, GoUint32 conferenceID, GoUint32* err);
-
-// tox_conference_get_title gets the title of a conference.
-// Returns the length of the title on success, or -1 on error.
-//
 extern GoInt tox_conference_get_title_size(void* tox, GoUint32 conferenceID, GoUint32* err);
-
-// tox_callback_conference_message sets the callback for conference message events.
-//
 extern void tox_callback_conference_message(void* tox, group_message_cb callback);
-
-// tox_callback_conference_invite sets the callback for conference invite events.
-//
 extern void tox_callback_conference_invite(void* tox, group_invite_cb callback);
-
-// tox_file_send sends a file send request.
-// Returns the file number on success, or UINT32_MAX on failure.
-//
 extern GoUint32 tox_file_send(void* tox, GoUint32 friendID, GoUint32 kind, GoUint64 fileSize, GoUint8* fileID, GoUint8* filename, GoUint32 filenameLen, GoUint32* err);
-
-// tox_file_control controls an ongoing file transfer.
-// Returns 0 on success, non-zero on error.
-//
 extern GoInt tox_file_control(void* tox, GoUint32 friendID, GoUint32 fileID, GoInt control, GoUint32* err);
-
-// tox_file_send_chunk sends a chunk of a file being transferred.
-// Returns 0 on success, non-zero on error.
-//
 extern GoInt tox_file_send_chunk(void* tox, GoUint32 friendID, GoUint32 fileID, GoUint64 position, GoUint8* data, GoUint32 length, GoUint32* err);
-
-// tox_callback_file_recv sets the callback for file receive events.
-//
 extern void tox_callback_file_recv(void* tox, file_recv_cb callback);
-
-// tox_callback_file_recv_chunk sets the callback for file chunk receive events.
-//
 extern void tox_callback_file_recv_chunk(void* tox, file_recv_chunk_cb callback);
-
-// tox_callback_file_chunk_request sets the callback for file chunk request events.
-//
 extern void tox_callback_file_chunk_request(void* tox, file_chunk_request_cb callback);
+extern int tox_self_get_connection_status(void* tox);
+extern int tox_self_get_status(void* tox);
+extern int tox_self_set_status(void* tox, int status);
+extern uint32_t tox_self_get_nospam(void* tox);
+extern void tox_self_set_nospam(void* tox, uint32_t nospam);
+extern size_t tox_friend_get_name_size(void* tox, uint32_t friendNumber);
+extern int tox_friend_get_name(void* tox, uint32_t friendNumber, uint8_t* name);
+extern size_t tox_friend_get_status_message_size(void* tox, uint32_t friendNumber);
+extern int tox_friend_get_status_message(void* tox, uint32_t friendNumber, uint8_t* statusMessage);
+extern int tox_friend_get_status(void* tox, uint32_t friendNumber);
+extern int tox_friend_get_connection_status(void* tox, uint32_t friendNumber);
+extern int tox_friend_get_public_key(void* tox, uint32_t friendNumber, uint8_t* publicKey);
+extern uint64_t tox_friend_get_last_online(void* tox, uint32_t friendNumber);
+extern int tox_friend_exists(void* tox, uint32_t friendNumber);
+extern size_t tox_self_get_friend_list_size(void* tox);
+extern void tox_self_get_friend_list(void* tox, uint32_t* friendList);
+extern int tox_conference_get_type(void* tox, uint32_t conferenceNumber);
+extern int tox_conference_peer_count(void* tox, uint32_t conferenceNumber);
+extern int tox_conference_set_title(void* tox, uint32_t conferenceNumber, uint8_t* title, size_t length);
+extern int tox_conference_get_title(void* tox, uint32_t conferenceNumber, uint8_t* title);
+extern size_t tox_conference_peer_get_name_size(void* tox, uint32_t conferenceNumber, uint32_t peerNumber);
+extern int tox_conference_peer_get_name(void* tox, uint32_t conferenceNumber, uint32_t peerNumber, uint8_t* name);
+extern int tox_conference_peer_get_public_key(void* tox, uint32_t conferenceNumber, uint32_t peerNumber, uint8_t* publicKey);
+extern int tox_conference_connected(void* tox, uint32_t conferenceNumber);
+extern uint32_t tox_conference_offline_peer_count(void* tox, uint32_t conferenceNumber);
+extern size_t tox_conference_offline_peer_get_name_size(void* tox, uint32_t conferenceNumber, uint32_t offlinePeerNumber);
+extern int tox_conference_offline_peer_get_name(void* tox, uint32_t conferenceNumber, uint32_t offlinePeerNumber, uint8_t* name);
+extern int tox_file_get_file_id(void* tox, uint32_t friendNumber, uint32_t fileNumber, uint8_t* fileID);
+extern int tox_hash(uint8_t* hash, uint8_t* data, size_t length);
+extern uint32_t tox_abi_version_major(void);
+extern uint32_t tox_abi_version_minor(void);
+extern uint32_t tox_abi_version_patch(void);
+extern GoInt tox_abi_version_string(GoUint8* out, GoInt outLen);
+extern uint64_t tox_abi_feature_flags(void);
+extern GoInt tox_crypto_generate_keypair(GoUint8* publicKey, GoUint8* secretKey);
+extern GoInt tox_crypto_secure_wipe(GoUint8* data, GoInt length);
+extern GoInt tox_self_get_safety_number(void* tox, GoUint8* peerPublicKey, GoUint8* out, GoInt outLen);
 
 #ifdef __cplusplus
 }
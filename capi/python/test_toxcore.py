@@ -0,0 +1,77 @@
+"""Tests for the generated toxcore ctypes bindings.
+
+Build the shared library first:
+
+    go build -buildmode=c-shared -o capi/libtoxcore.so ./capi
+    python3 -m unittest discover -s capi/python
+"""
+
+import ctypes
+import unittest
+
+try:
+    import toxcore
+except OSError as exc:  # library not built
+    toxcore = None
+    _IMPORT_ERROR = exc
+
+
+@unittest.skipIf(toxcore is None, "libtoxcore not available")
+class ToxLifecycleTest(unittest.TestCase):
+
+    def test_raw_new_kill_round_trip(self):
+        for _ in range(3):
+            handle = toxcore.tox_new()
+            self.assertTrue(handle)
+            self.assertEqual(toxcore.tox_self_get_address_size(handle), 38)
+            toxcore.tox_kill(handle)
+        # A NULL handle is ignored by the C API
+        toxcore.tox_kill(None)
+
+    def test_class_round_trip(self):
+        with toxcore.Tox() as tox:
+            address = tox.address()
+            self.assertEqual(len(address), 38)
+            public_key = (ctypes.c_uint8 * 32)()
+            self.assertEqual(tox.self_get_public_key(public_key), 0)
+            self.assertEqual(bytes(public_key), address[:32])
+        with self.assertRaises(ValueError):
+            tox.address()
+        tox.kill()  # idempotent
+
+    def test_toxav_shares_tox_handle(self):
+        with toxcore.Tox() as tox:
+            av = toxcore.ToxAV(tox)
+            try:
+                self.assertEqual(av.get_tox_from_av(), tox._ptr)
+                with self.assertRaises(toxcore.ToxError) as ctx:
+                    av.call(999, 48000, 0)
+                self.assertEqual(ctx.exception.function, "toxav_call")
+            finally:
+                av.kill()
+
+    def test_callback_exception_propagates(self):
+        with toxcore.Tox() as tox:
+            def failing(*args):
+                raise RuntimeError("callback failed")
+
+            tox.callback_friend_message(failing)
+            cfunc = tox._callbacks["friend_message_cb"]
+            message = ctypes.create_string_buffer(b"hi", 2)
+            cfunc(None, 0, ctypes.cast(message, ctypes.POINTER(ctypes.c_uint8)), 2, None)
+            with self.assertRaises(RuntimeError):
+                tox.iterate()
+            tox.iterate()  # the exception is raised once
+
+    def test_callback_receives_bytes(self):
+        received = []
+        with toxcore.Tox() as tox:
+            tox.callback_friend_message(lambda friend, msg: received.append((friend, msg)))
+            cfunc = tox._callbacks["friend_message_cb"]
+            message = ctypes.create_string_buffer(b"hello", 5)
+            cfunc(None, 7, ctypes.cast(message, ctypes.POINTER(ctypes.c_uint8)), 5, None)
+        self.assertEqual(received, [(7, b"hello")])
+
+
+if __name__ == "__main__":
+    unittest.main()
@@ -0,0 +1,2067 @@
+"""ctypes bindings for the toxcore-go C API.
+
+Code generated by capi/generate_python.py from libtoxcore.h; DO NOT EDIT.
+
+The shared library is located through the TOXCORE_LIBRARY environment
+variable, then next to this module, then in the parent capi directory, and
+finally on the system library path.
+"""
+
+import ctypes
+import ctypes.util
+import os
+import sys
+import threading
+
+_ENUM_NAMES = {}
+
+
+class ToxError(Exception):
+    """Raised when a C API call reports an error code."""
+
+    def __init__(self, function, code, code_name=None):
+        self.function = function
+        self.code = code
+        self.code_name = code_name
+        super().__init__("%s failed: %s" % (function, code_name or code))
+
+
+def _library_names():
+    if sys.platform == "darwin":
+        return ["libtoxcore.dylib", "libtoxcore.so"]
+    if sys.platform == "win32":
+        return ["libtoxcore.dll", "toxcore.dll"]
+    return ["libtoxcore.so"]
+
+
+def _load_library():
+    path = os.environ.get("TOXCORE_LIBRARY")
+    if path:
+        return ctypes.CDLL(path)
+    here = os.path.dirname(os.path.abspath(__file__))
+    for directory in (here, os.path.dirname(here)):
+        for name in _library_names():
+            candidate = os.path.join(directory, name)
+            if os.path.exists(candidate):
+                return ctypes.CDLL(candidate)
+    found = ctypes.util.find_library("toxcore")
+    if found:
+        return ctypes.CDLL(found)
+    raise OSError("libtoxcore not found; build it with "
+                  "`go build -buildmode=c-shared -o capi/libtoxcore.so ./capi` "
+                  "or set TOXCORE_LIBRARY")
+
+
+def _declare_loaded(lib, declare):
+    declare(lib)
+    return lib
+
+
+def _check_error(function, code, enum_name):
+    if code != 0:
+        raise ToxError(function, code, _ENUM_NAMES.get(enum_name, {}).get(code))
+
+
+def _as_cstring(value):
+    if value is None or isinstance(value, bytes):
+        return value
+    return str(value).encode("utf-8")
+
+
+def _as_pointer(value, ptype):
+    """Converts bytes, bytearray or a ctypes array to a typed pointer."""
+    if value is None:
+        return None
+    if isinstance(value, bytearray):
+        buf = (ptype._type_ * len(value)).from_buffer(value)
+        return ctypes.cast(buf, ptype)
+    if isinstance(value, bytes):
+        return ctypes.cast(ctypes.create_string_buffer(value, len(value)), ptype)
+    if isinstance(value, ctypes.Array):
+        return ctypes.cast(value, ptype)
+    return value
+
+
+def _convert_callback_args(names, args):
+    """Drops handle and user data and collapses pointer/length pairs to bytes."""
+    out = []
+    skip = False
+    for i, (name, value) in enumerate(zip(names, args)):
+        if skip:
+            skip = False
+            continue
+        if (i == 0 and name in ("tox", "av")) or name in ("user_data", "userData"):
+            continue
+        if i + 1 < len(names) and names[i + 1] in ("length", "%s_length" % name, "size"):
+            out.append(ctypes.string_at(value, args[i + 1]) if value else b"")
+            skip = True
+            continue
+        if name == "public_key" and value:
+            out.append(ctypes.string_at(value, 32))
+            continue
+        out.append(value)
+    return out
+
+
+class _Handle:
+    """Shared lifecycle and callback handling for Tox and ToxAV."""
+
+    _ptr = None
+
+    def __init__(self):
+        self._callbacks = {}
+        self._pending = []
+        self._pending_lock = threading.Lock()
+
+    def _check_open(self):
+        if not self._ptr:
+            raise ValueError("%s instance is closed" % type(self).__name__)
+
+    def _wrap_callback(self, name, cfunctype, func):
+        names = _CALLBACK_PARAMS[name]
+
+        def trampoline(*args):
+            if func is None:
+                return None
+            try:
+                return func(*_convert_callback_args(names, args))
+            except BaseException as exc:  # re-raised on the caller's thread
+                with self._pending_lock:
+                    self._pending.append(exc)
+            return None
+
+        cfunc = cfunctype(trampoline) if func is not None else ctypes.cast(None, cfunctype)
+        # Keep a reference so the C function pointer stays valid
+        self._callbacks[name] = cfunc
+        return cfunc
+
+    def _raise_pending(self):
+        with self._pending_lock:
+            if not self._pending:
+                return
+            exc = self._pending.pop(0)
+        raise exc
+
+    def __enter__(self):
+        return self
+
+    def __exit__(self, *exc):
+        self.kill()
+
+    def __del__(self):
+        try:
+            self.kill()
+        except Exception:
+            pass
+
+# Enumerations
+
+TOX_AV_CALL_CONTROL_RESUME = 0
+TOX_AV_CALL_CONTROL_PAUSE = 1
+TOX_AV_CALL_CONTROL_CANCEL = 2
+TOX_AV_CALL_CONTROL_MUTE_AUDIO = 3
+TOX_AV_CALL_CONTROL_UNMUTE_AUDIO = 4
+TOX_AV_CALL_CONTROL_HIDE_VIDEO = 5
+TOX_AV_CALL_CONTROL_SHOW_VIDEO = 6
+_ENUM_NAMES['TOX_AV_CALL_CONTROL'] = {0: 'TOX_AV_CALL_CONTROL_RESUME', 1: 'TOX_AV_CALL_CONTROL_PAUSE', 2: 'TOX_AV_CALL_CONTROL_CANCEL', 3: 'TOX_AV_CALL_CONTROL_MUTE_AUDIO', 4: 'TOX_AV_CALL_CONTROL_UNMUTE_AUDIO', 5: 'TOX_AV_CALL_CONTROL_HIDE_VIDEO', 6: 'TOX_AV_CALL_CONTROL_SHOW_VIDEO'}
+
+TOX_AV_CALL_STATE_NONE = 0
+TOX_AV_CALL_STATE_ERROR = 1
+TOX_AV_CALL_STATE_FINISHED = 2
+TOX_AV_CALL_STATE_SENDING_AUDIO = 4
+TOX_AV_CALL_STATE_SENDING_VIDEO = 8
+TOX_AV_CALL_STATE_ACCEPTING_AUDIO = 16
+TOX_AV_CALL_STATE_ACCEPTING_VIDEO = 32
+_ENUM_NAMES['TOX_AV_CALL_STATE'] = {0: 'TOX_AV_CALL_STATE_NONE', 1: 'TOX_AV_CALL_STATE_ERROR', 2: 'TOX_AV_CALL_STATE_FINISHED', 4: 'TOX_AV_CALL_STATE_SENDING_AUDIO', 8: 'TOX_AV_CALL_STATE_SENDING_VIDEO', 16: 'TOX_AV_CALL_STATE_ACCEPTING_AUDIO', 32: 'TOX_AV_CALL_STATE_ACCEPTING_VIDEO'}
+
+TOX_AV_ERR_ANSWER_OK = 0
+TOX_AV_ERR_ANSWER_SYNC = 1
+TOX_AV_ERR_ANSWER_CODEC_INITIALIZATION = 2
+TOX_AV_ERR_ANSWER_FRIEND_NOT_FOUND = 3
+TOX_AV_ERR_ANSWER_FRIEND_NOT_CALLING = 4
+TOX_AV_ERR_ANSWER_INVALID_BIT_RATE = 5
+_ENUM_NAMES['TOX_AV_ERR_ANSWER'] = {0: 'TOX_AV_ERR_ANSWER_OK', 1: 'TOX_AV_ERR_ANSWER_SYNC', 2: 'TOX_AV_ERR_ANSWER_CODEC_INITIALIZATION', 3: 'TOX_AV_ERR_ANSWER_FRIEND_NOT_FOUND', 4: 'TOX_AV_ERR_ANSWER_FRIEND_NOT_CALLING', 5: 'TOX_AV_ERR_ANSWER_INVALID_BIT_RATE'}
+
+TOX_AV_ERR_BIT_RATE_SET_OK = 0
+TOX_AV_ERR_BIT_RATE_SET_SYNC = 1
+TOX_AV_ERR_BIT_RATE_SET_INVALID_AUDIO_BIT_RATE = 2
+TOX_AV_ERR_BIT_RATE_SET_INVALID_VIDEO_BIT_RATE = 3
+TOX_AV_ERR_BIT_RATE_SET_FRIEND_NOT_FOUND = 4
+TOX_AV_ERR_BIT_RATE_SET_FRIEND_NOT_IN_CALL = 5
+_ENUM_NAMES['TOX_AV_ERR_BIT_RATE_SET'] = {0: 'TOX_AV_ERR_BIT_RATE_SET_OK', 1: 'TOX_AV_ERR_BIT_RATE_SET_SYNC', 2: 'TOX_AV_ERR_BIT_RATE_SET_INVALID_AUDIO_BIT_RATE', 3: 'TOX_AV_ERR_BIT_RATE_SET_INVALID_VIDEO_BIT_RATE', 4: 'TOX_AV_ERR_BIT_RATE_SET_FRIEND_NOT_FOUND', 5: 'TOX_AV_ERR_BIT_RATE_SET_FRIEND_NOT_IN_CALL'}
+
+TOX_AV_ERR_CALL_OK = 0
+TOX_AV_ERR_CALL_MALLOC = 1
+TOX_AV_ERR_CALL_SYNC = 2
+TOX_AV_ERR_CALL_FRIEND_NOT_FOUND = 3
+TOX_AV_ERR_CALL_FRIEND_NOT_CONNECTED = 4
+TOX_AV_ERR_CALL_FRIEND_ALREADY_IN_CALL = 5
+TOX_AV_ERR_CALL_INVALID_BIT_RATE = 6
+_ENUM_NAMES['TOX_AV_ERR_CALL'] = {0: 'TOX_AV_ERR_CALL_OK', 1: 'TOX_AV_ERR_CALL_MALLOC', 2: 'TOX_AV_ERR_CALL_SYNC', 3: 'TOX_AV_ERR_CALL_FRIEND_NOT_FOUND', 4: 'TOX_AV_ERR_CALL_FRIEND_NOT_CONNECTED', 5: 'TOX_AV_ERR_CALL_FRIEND_ALREADY_IN_CALL', 6: 'TOX_AV_ERR_CALL_INVALID_BIT_RATE'}
+
+TOX_AV_ERR_CALL_CONTROL_OK = 0
+TOX_AV_ERR_CALL_CONTROL_SYNC = 1
+TOX_AV_ERR_CALL_CONTROL_FRIEND_NOT_FOUND = 2
+TOX_AV_ERR_CALL_CONTROL_FRIEND_NOT_IN_CALL = 3
+TOX_AV_ERR_CALL_CONTROL_INVALID_TRANSITION = 4
+_ENUM_NAMES['TOX_AV_ERR_CALL_CONTROL'] = {0: 'TOX_AV_ERR_CALL_CONTROL_OK', 1: 'TOX_AV_ERR_CALL_CONTROL_SYNC', 2: 'TOX_AV_ERR_CALL_CONTROL_FRIEND_NOT_FOUND', 3: 'TOX_AV_ERR_CALL_CONTROL_FRIEND_NOT_IN_CALL', 4: 'TOX_AV_ERR_CALL_CONTROL_INVALID_TRANSITION'}
+
+TOX_AV_ERR_NEW_OK = 0
+TOX_AV_ERR_NEW_NULL = 1
+TOX_AV_ERR_NEW_MALLOC = 2
+TOX_AV_ERR_NEW_MULTIPLE = 3
+_ENUM_NAMES['TOX_AV_ERR_NEW'] = {0: 'TOX_AV_ERR_NEW_OK', 1: 'TOX_AV_ERR_NEW_NULL', 2: 'TOX_AV_ERR_NEW_MALLOC', 3: 'TOX_AV_ERR_NEW_MULTIPLE'}
+
+TOX_AV_ERR_RECORDING_OK = 0
+TOX_AV_ERR_RECORDING_NULL = 1
+TOX_AV_ERR_RECORDING_SYNC = 2
+TOX_AV_ERR_RECORDING_FRIEND_NOT_FOUND = 3
+TOX_AV_ERR_RECORDING_FRIEND_NOT_IN_CALL = 4
+TOX_AV_ERR_RECORDING_ALREADY_ACTIVE = 5
+TOX_AV_ERR_RECORDING_NOT_ACTIVE = 6
+TOX_AV_ERR_RECORDING_INVALID_FORMAT = 7
+TOX_AV_ERR_RECORDING_IO = 8
+_ENUM_NAMES['TOX_AV_ERR_RECORDING'] = {0: 'TOX_AV_ERR_RECORDING_OK', 1: 'TOX_AV_ERR_RECORDING_NULL', 2: 'TOX_AV_ERR_RECORDING_SYNC', 3: 'TOX_AV_ERR_RECORDING_FRIEND_NOT_FOUND', 4: 'TOX_AV_ERR_RECORDING_FRIEND_NOT_IN_CALL', 5: 'TOX_AV_ERR_RECORDING_ALREADY_ACTIVE', 6: 'TOX_AV_ERR_RECORDING_NOT_ACTIVE', 7: 'TOX_AV_ERR_RECORDING_INVALID_FORMAT', 8: 'TOX_AV_ERR_RECORDING_IO'}
+
+TOX_AV_ERR_SCREEN_SHARE_OK = 0
+TOX_AV_ERR_SCREEN_SHARE_SYNC = 1
+TOX_AV_ERR_SCREEN_SHARE_FRIEND_NOT_FOUND = 2
+TOX_AV_ERR_SCREEN_SHARE_FRIEND_NOT_IN_CALL = 3
+TOX_AV_ERR_SCREEN_SHARE_VIDEO_DISABLED = 4
+TOX_AV_ERR_SCREEN_SHARE_ALREADY_ACTIVE = 5
+TOX_AV_ERR_SCREEN_SHARE_NOT_ACTIVE = 6
+_ENUM_NAMES['TOX_AV_ERR_SCREEN_SHARE'] = {0: 'TOX_AV_ERR_SCREEN_SHARE_OK', 1: 'TOX_AV_ERR_SCREEN_SHARE_SYNC', 2: 'TOX_AV_ERR_SCREEN_SHARE_FRIEND_NOT_FOUND', 3: 'TOX_AV_ERR_SCREEN_SHARE_FRIEND_NOT_IN_CALL', 4: 'TOX_AV_ERR_SCREEN_SHARE_VIDEO_DISABLED', 5: 'TOX_AV_ERR_SCREEN_SHARE_ALREADY_ACTIVE', 6: 'TOX_AV_ERR_SCREEN_SHARE_NOT_ACTIVE'}
+
+TOX_AV_ERR_SEND_FRAME_OK = 0
+TOX_AV_ERR_SEND_FRAME_NULL = 1
+TOX_AV_ERR_SEND_FRAME_FRIEND_NOT_FOUND = 2
+TOX_AV_ERR_SEND_FRAME_FRIEND_NOT_IN_CALL = 3
+TOX_AV_ERR_SEND_FRAME_SYNC = 4
+TOX_AV_ERR_SEND_FRAME_INVALID = 5
+TOX_AV_ERR_SEND_FRAME_PAYLOAD_TYPE_DISABLED = 6
+TOX_AV_ERR_SEND_FRAME_RTP_FAILED = 7
+_ENUM_NAMES['TOX_AV_ERR_SEND_FRAME'] = {0: 'TOX_AV_ERR_SEND_FRAME_OK', 1: 'TOX_AV_ERR_SEND_FRAME_NULL', 2: 'TOX_AV_ERR_SEND_FRAME_FRIEND_NOT_FOUND', 3: 'TOX_AV_ERR_SEND_FRAME_FRIEND_NOT_IN_CALL', 4: 'TOX_AV_ERR_SEND_FRAME_SYNC', 5: 'TOX_AV_ERR_SEND_FRAME_INVALID', 6: 'TOX_AV_ERR_SEND_FRAME_PAYLOAD_TYPE_DISABLED', 7: 'TOX_AV_ERR_SEND_FRAME_RTP_FAILED'}
+
+TOX_AV_RECORDING_FORMAT_WAV = 0
+TOX_AV_RECORDING_FORMAT_RAW = 1
+_ENUM_NAMES['TOX_AV_RECORDING_FORMAT'] = {0: 'TOX_AV_RECORDING_FORMAT_WAV', 1: 'TOX_AV_RECORDING_FORMAT_RAW'}
+
+# Callback function types
+
+file_chunk_request_cb = ctypes.CFUNCTYPE(None, ctypes.c_void_p, ctypes.c_uint32, ctypes.c_uint32, ctypes.c_uint64, ctypes.c_size_t, ctypes.c_void_p)
+file_recv_cb = ctypes.CFUNCTYPE(None, ctypes.c_void_p, ctypes.c_uint32, ctypes.c_uint32, ctypes.c_uint32, ctypes.c_uint64, ctypes.POINTER(ctypes.c_uint8), ctypes.c_size_t, ctypes.c_void_p)
+file_recv_chunk_cb = ctypes.CFUNCTYPE(None, ctypes.c_void_p, ctypes.c_uint32, ctypes.c_uint32, ctypes.c_uint64, ctypes.POINTER(ctypes.c_uint8), ctypes.c_size_t, ctypes.c_void_p)
+friend_connection_status_cb = ctypes.CFUNCTYPE(None, ctypes.c_void_p, ctypes.c_uint32, ctypes.c_uint8, ctypes.c_void_p)
+friend_message_cb = ctypes.CFUNCTYPE(None, ctypes.c_void_p, ctypes.c_uint32, ctypes.POINTER(ctypes.c_uint8), ctypes.c_size_t, ctypes.c_void_p)
+friend_request_cb = ctypes.CFUNCTYPE(None, ctypes.c_void_p, ctypes.POINTER(ctypes.c_uint8), ctypes.POINTER(ctypes.c_uint8), ctypes.c_size_t, ctypes.c_void_p)
+group_invite_cb = ctypes.CFUNCTYPE(None, ctypes.c_void_p, ctypes.c_uint32, ctypes.c_int, ctypes.POINTER(ctypes.c_uint8), ctypes.c_size_t, ctypes.c_void_p)
+group_message_cb = ctypes.CFUNCTYPE(None, ctypes.c_void_p, ctypes.c_uint32, ctypes.c_uint32, ctypes.c_int, ctypes.POINTER(ctypes.c_uint8), ctypes.c_size_t, ctypes.c_void_p)
+tox_callback_error_cb = ctypes.CFUNCTYPE(None, ctypes.c_char_p, ctypes.c_char_p)
+toxav_audio_bit_rate_cb = ctypes.CFUNCTYPE(None, ctypes.POINTER(ctypes.c_void_p), ctypes.c_uint32, ctypes.c_uint32, ctypes.c_void_p)
+toxav_audio_receive_frame_cb = ctypes.CFUNCTYPE(None, ctypes.POINTER(ctypes.c_void_p), ctypes.c_uint32, ctypes.POINTER(ctypes.c_int16), ctypes.c_size_t, ctypes.c_uint8, ctypes.c_uint32, ctypes.c_void_p)
+toxav_call_cb = ctypes.CFUNCTYPE(None, ctypes.POINTER(ctypes.c_void_p), ctypes.c_uint32, ctypes.c_bool, ctypes.c_bool, ctypes.c_void_p)
+toxav_call_state_cb = ctypes.CFUNCTYPE(None, ctypes.POINTER(ctypes.c_void_p), ctypes.c_uint32, ctypes.c_uint32, ctypes.c_void_p)
+toxav_recording_stats_cb = ctypes.CFUNCTYPE(None, ctypes.POINTER(ctypes.c_void_p), ctypes.c_uint32, ctypes.c_uint64, ctypes.c_void_p)
+toxav_video_bit_rate_cb = ctypes.CFUNCTYPE(None, ctypes.POINTER(ctypes.c_void_p), ctypes.c_uint32, ctypes.c_uint32, ctypes.c_void_p)
+toxav_video_receive_frame_cb = ctypes.CFUNCTYPE(None, ctypes.POINTER(ctypes.c_void_p), ctypes.c_uint32, ctypes.c_uint16, ctypes.c_uint16, ctypes.POINTER(ctypes.c_uint8), ctypes.POINTER(ctypes.c_uint8), ctypes.POINTER(ctypes.c_uint8), ctypes.c_int32, ctypes.c_int32, ctypes.c_int32, ctypes.c_void_p)
+
+# Callback argument names, used to convert raw arguments for Python callables
+_CALLBACK_PARAMS = {
+    'file_chunk_request_cb': ['tox', 'friend_number', 'file_number', 'position', 'length', 'user_data'],
+    'file_recv_cb': ['tox', 'friend_number', 'file_number', 'kind', 'file_size', 'filename', 'filename_length', 'user_data'],
+    'file_recv_chunk_cb': ['tox', 'friend_number', 'file_number', 'position', 'data', 'length', 'user_data'],
+    'friend_connection_status_cb': ['tox', 'friend_number', 'connection_status', 'user_data'],
+    'friend_message_cb': ['tox', 'friend_number', 'message', 'length', 'user_data'],
+    'friend_request_cb': ['tox', 'public_key', 'message', 'length', 'user_data'],
+    'group_invite_cb': ['tox', 'friend_number', 'type', 'cookie', 'length', 'user_data'],
+    'group_message_cb': ['tox', 'conference_number', 'peer_number', 'type', 'message', 'length', 'user_data'],
+    'tox_callback_error_cb': ['callback_name', 'error'],
+    'toxav_audio_bit_rate_cb': ['av', 'friend_number', 'audio_bit_rate', 'user_data'],
+    'toxav_audio_receive_frame_cb': ['av', 'friend_number', 'pcm', 'sample_count', 'channels', 'sampling_rate', 'user_data'],
+    'toxav_call_cb': ['av', 'friend_number', 'audio_enabled', 'video_enabled', 'user_data'],
+    'toxav_call_state_cb': ['av', 'friend_number', 'state', 'user_data'],
+    'toxav_recording_stats_cb': ['av', 'friend_number', 'bytes_written', 'user_data'],
+    'toxav_video_bit_rate_cb': ['av', 'friend_number', 'video_bit_rate', 'user_data'],
+    'toxav_video_receive_frame_cb': ['av', 'friend_number', 'width', 'height', 'y', 'u', 'v', 'ystride', 'ustride', 'vstride', 'user_data'],
+}
+
+
+def _declare(lib):
+    """Declares argtypes and restype for every exported function."""
+    lib.toxcore_set_callback_error_handler.argtypes = [ctypes.c_void_p, tox_callback_error_cb]
+    lib.toxcore_set_callback_error_handler.restype = None
+    lib.toxav_new.argtypes = [ctypes.c_void_p, ctypes.POINTER(ctypes.c_int)]
+    lib.toxav_new.restype = ctypes.c_void_p
+    lib.toxav_kill.argtypes = [ctypes.c_void_p]
+    lib.toxav_kill.restype = None
+    lib.toxav_get_tox_from_av.argtypes = [ctypes.c_void_p]
+    lib.toxav_get_tox_from_av.restype = ctypes.c_void_p
+    lib.toxav_iteration_interval.argtypes = [ctypes.c_void_p]
+    lib.toxav_iteration_interval.restype = ctypes.c_uint32
+    lib.toxav_iterate.argtypes = [ctypes.c_void_p]
+    lib.toxav_iterate.restype = None
+    lib.toxav_call.argtypes = [ctypes.c_void_p, ctypes.c_uint32, ctypes.c_uint32, ctypes.c_uint32, ctypes.POINTER(ctypes.c_int)]
+    lib.toxav_call.restype = ctypes.c_bool
+    lib.toxav_answer.argtypes = [ctypes.c_void_p, ctypes.c_uint32, ctypes.c_uint32, ctypes.c_uint32, ctypes.POINTER(ctypes.c_int)]
+    lib.toxav_answer.restype = ctypes.c_bool
+    lib.toxav_call_control.argtypes = [ctypes.c_void_p, ctypes.c_uint32, ctypes.c_int, ctypes.POINTER(ctypes.c_int)]
+    lib.toxav_call_control.restype = ctypes.c_bool
+    lib.toxav_audio_set_bit_rate.argtypes = [ctypes.c_void_p, ctypes.c_uint32, ctypes.c_uint32, ctypes.POINTER(ctypes.c_int)]
+    lib.toxav_audio_set_bit_rate.restype = ctypes.c_bool
+    lib.toxav_video_set_bit_rate.argtypes = [ctypes.c_void_p, ctypes.c_uint32, ctypes.c_uint32, ctypes.POINTER(ctypes.c_int)]
+    lib.toxav_video_set_bit_rate.restype = ctypes.c_bool
+    lib.toxav_audio_send_frame.argtypes = [ctypes.c_void_p, ctypes.c_uint32, ctypes.POINTER(ctypes.c_int16), ctypes.c_size_t, ctypes.c_uint8, ctypes.c_uint32, ctypes.POINTER(ctypes.c_int)]
+    lib.toxav_audio_send_frame.restype = ctypes.c_bool
+    lib.toxav_video_send_frame.argtypes = [ctypes.c_void_p, ctypes.c_uint32, ctypes.c_uint16, ctypes.c_uint16, ctypes.POINTER(ctypes.c_uint8), ctypes.POINTER(ctypes.c_uint8), ctypes.POINTER(ctypes.c_uint8), ctypes.POINTER(ctypes.c_int)]
+    lib.toxav_video_send_frame.restype = ctypes.c_bool
+    lib.toxav_callback_call.argtypes = [ctypes.c_void_p, toxav_call_cb, ctypes.c_void_p]
+    lib.toxav_callback_call.restype = None
+    lib.toxav_callback_call_state.argtypes = [ctypes.c_void_p, toxav_call_state_cb, ctypes.c_void_p]
+    lib.toxav_callback_call_state.restype = None
+    lib.toxav_callback_audio_bit_rate.argtypes = [ctypes.c_void_p, toxav_audio_bit_rate_cb, ctypes.c_void_p]
+    lib.toxav_callback_audio_bit_rate.restype = None
+    lib.toxav_callback_video_bit_rate.argtypes = [ctypes.c_void_p, toxav_video_bit_rate_cb, ctypes.c_void_p]
+    lib.toxav_callback_video_bit_rate.restype = None
+    lib.toxav_callback_audio_receive_frame.argtypes = [ctypes.c_void_p, toxav_audio_receive_frame_cb, ctypes.c_void_p]
+    lib.toxav_callback_audio_receive_frame.restype = None
+    lib.toxav_callback_video_receive_frame.argtypes = [ctypes.c_void_p, toxav_video_receive_frame_cb, ctypes.c_void_p]
+    lib.toxav_callback_video_receive_frame.restype = None
+    lib.toxav_start_recording.argtypes = [ctypes.c_void_p, ctypes.c_uint32, ctypes.c_char_p, ctypes.c_int, ctypes.POINTER(ctypes.c_int)]
+    lib.toxav_start_recording.restype = ctypes.c_bool
+    lib.toxav_stop_recording.argtypes = [ctypes.c_void_p, ctypes.c_uint32, ctypes.POINTER(ctypes.c_int)]
+    lib.toxav_stop_recording.restype = ctypes.c_bool
+    lib.toxav_start_screen_share.argtypes = [ctypes.c_void_p, ctypes.c_uint32, ctypes.POINTER(ctypes.c_int)]
+    lib.toxav_start_screen_share.restype = ctypes.c_bool
+    lib.toxav_stop_screen_share.argtypes = [ctypes.c_void_p, ctypes.c_uint32, ctypes.POINTER(ctypes.c_int)]
+    lib.toxav_stop_screen_share.restype = ctypes.c_bool
+    lib.toxav_callback_recording_stats.argtypes = [ctypes.c_void_p, toxav_recording_stats_cb, ctypes.c_void_p]
+    lib.toxav_callback_recording_stats.restype = None
+    lib.tox_new.argtypes = []
+    lib.tox_new.restype = ctypes.c_void_p
+    lib.tox_kill.argtypes = [ctypes.c_void_p]
+    lib.tox_kill.restype = None
+    lib.tox_bootstrap_simple.argtypes = [ctypes.c_void_p]
+    lib.tox_bootstrap_simple.restype = ctypes.c_longlong
+    lib.tox_iterate.argtypes = [ctypes.c_void_p]
+    lib.tox_iterate.restype = None
+    lib.tox_iteration_interval.argtypes = [ctypes.c_void_p]
+    lib.tox_iteration_interval.restype = ctypes.c_longlong
+    lib.tox_self_get_address_size.argtypes = [ctypes.c_void_p]
+    lib.tox_self_get_address_size.restype = ctypes.c_longlong
+    lib.hex_string_to_bin.argtypes = [ctypes.POINTER(ctypes.c_uint8), ctypes.c_longlong, ctypes.POINTER(ctypes.c_uint8), ctypes.c_longlong]
+    lib.hex_string_to_bin.restype = ctypes.c_longlong
+    lib.tox_self_get_address.argtypes = [ctypes.c_void_p, ctypes.POINTER(ctypes.c_uint8)]
+    lib.tox_self_get_address.restype = ctypes.c_longlong
+    lib.tox_self_get_public_key.argtypes = [ctypes.c_void_p, ctypes.POINTER(ctypes.c_uint8)]
+    lib.tox_self_get_public_key.restype = ctypes.c_longlong
+    lib.tox_friend_add.argtypes = [ctypes.c_void_p, ctypes.POINTER(ctypes.c_uint8), ctypes.POINTER(ctypes.c_uint8), ctypes.c_longlong]
+    lib.tox_friend_add.restype = ctypes.c_uint
+    lib.tox_friend_add_norequest.argtypes = [ctypes.c_void_p, ctypes.POINTER(ctypes.c_uint8)]
+    lib.tox_friend_add_norequest.restype = ctypes.c_uint
+    lib.tox_friend_delete.argtypes = [ctypes.c_void_p, ctypes.c_uint]
+    lib.tox_friend_delete.restype = ctypes.c_longlong
+    lib.tox_friend_send_message.argtypes = [ctypes.c_void_p, ctypes.c_uint, ctypes.c_longlong, ctypes.POINTER(ctypes.c_uint8), ctypes.c_longlong]
+    lib.tox_friend_send_message.restype = ctypes.c_uint
+    lib.tox_callback_friend_request.argtypes = [ctypes.c_void_p, ctypes.c_void_p, ctypes.c_void_p]
+    lib.tox_callback_friend_request.restype = None
+    lib.tox_callback_friend_message.argtypes = [ctypes.c_void_p, ctypes.c_void_p, ctypes.c_void_p]
+    lib.tox_callback_friend_message.restype = None
+    lib.tox_callback_friend_connection_status.argtypes = [ctypes.c_void_p, ctypes.c_void_p, ctypes.c_void_p]
+    lib.tox_callback_friend_connection_status.restype = None
+    lib.tox_self_set_name.argtypes = [ctypes.c_void_p, ctypes.POINTER(ctypes.c_uint8), ctypes.c_longlong]
+    lib.tox_self_set_name.restype = ctypes.c_longlong
+    lib.tox_self_get_name_size.argtypes = [ctypes.c_void_p]
+    lib.tox_self_get_name_size.restype = ctypes.c_longlong
+    lib.tox_self_get_name.argtypes = [ctypes.c_void_p, ctypes.POINTER(ctypes.c_uint8)]
+    lib.tox_self_get_name.restype = ctypes.c_longlong
+    lib.tox_self_set_status_message.argtypes = [ctypes.c_void_p, ctypes.POINTER(ctypes.c_uint8), ctypes.c_longlong]
+    lib.tox_self_set_status_message.restype = ctypes.c_longlong
+    lib.tox_self_get_status_message_size.argtypes = [ctypes.c_void_p]
+    lib.tox_self_get_status_message_size.restype = ctypes.c_longlong
+    lib.tox_self_get_status_message.argtypes = [ctypes.c_void_p, ctypes.POINTER(ctypes.c_uint8)]
+    lib.tox_self_get_status_message.restype = ctypes.c_longlong
+    lib.tox_conference_new.argtypes = [ctypes.c_void_p, ctypes.POINTER(ctypes.c_uint)]
+    lib.tox_conference_new.restype = ctypes.c_uint
+    lib.tox_conference_invite.argtypes = [ctypes.c_void_p, ctypes.c_uint, ctypes.c_uint, ctypes.POINTER(ctypes.c_uint)]
+    lib.tox_conference_invite.restype = ctypes.c_longlong
+    lib.tox_conference_send_message.argtypes = [ctypes.c_void_p, ctypes.c_uint, ctypes.c_longlong, ctypes.POINTER(ctypes.c_uint8), ctypes.c_uint, ctypes.POINTER(ctypes.c_uint)]
+    lib.tox_conference_send_message.restype = ctypes.c_longlong
+    lib.tox_conference_delete.argtypes = [ctypes.c_void_p, ctypes.c_uint, ctypes.POINTER(ctypes.c_uint)]
+    lib.tox_conference_delete.restype = ctypes.c_longlong
+    lib.tox_conference_get_title_size.argtypes = [ctypes.c_void_p, ctypes.c_uint, ctypes.POINTER(ctypes.c_uint)]
+    lib.tox_conference_get_title_size.restype = ctypes.c_longlong
+    lib.tox_callback_conference_message.argtypes = [ctypes.c_void_p, group_message_cb]
+    lib.tox_callback_conference_message.restype = None
+    lib.tox_callback_conference_invite.argtypes = [ctypes.c_void_p, group_invite_cb]
+    lib.tox_callback_conference_invite.restype = None
+    lib.tox_file_send.argtypes = [ctypes.c_void_p, ctypes.c_uint, ctypes.c_uint, ctypes.c_ulonglong, ctypes.POINTER(ctypes.c_uint8), ctypes.POINTER(ctypes.c_uint8), ctypes.c_uint, ctypes.POINTER(ctypes.c_uint)]
+    lib.tox_file_send.restype = ctypes.c_uint
+    lib.tox_file_control.argtypes = [ctypes.c_void_p, ctypes.c_uint, ctypes.c_uint, ctypes.c_longlong, ctypes.POINTER(ctypes.c_uint)]
+    lib.tox_file_control.restype = ctypes.c_longlong
+    lib.tox_file_send_chunk.argtypes = [ctypes.c_void_p, ctypes.c_uint, ctypes.c_uint, ctypes.c_ulonglong, ctypes.POINTER(ctypes.c_uint8), ctypes.c_uint, ctypes.POINTER(ctypes.c_uint)]
+    lib.tox_file_send_chunk.restype = ctypes.c_longlong
+    lib.tox_callback_file_recv.argtypes = [ctypes.c_void_p, file_recv_cb]
+    lib.tox_callback_file_recv.restype = None
+    lib.tox_callback_file_recv_chunk.argtypes = [ctypes.c_void_p, file_recv_chunk_cb]
+    lib.tox_callback_file_recv_chunk.restype = None
+    lib.tox_callback_file_chunk_request.argtypes = [ctypes.c_void_p, file_chunk_request_cb]
+    lib.tox_callback_file_chunk_request.restype = None
+    lib.tox_self_get_connection_status.argtypes = [ctypes.c_void_p]
+    lib.tox_self_get_connection_status.restype = ctypes.c_int
+    lib.tox_self_get_status.argtypes = [ctypes.c_void_p]
+    lib.tox_self_get_status.restype = ctypes.c_int
+    lib.tox_self_set_status.argtypes = [ctypes.c_void_p, ctypes.c_int]
+    lib.tox_self_set_status.restype = ctypes.c_int
+    lib.tox_self_get_nospam.argtypes = [ctypes.c_void_p]
+    lib.tox_self_get_nospam.restype = ctypes.c_uint32
+    lib.tox_self_set_nospam.argtypes = [ctypes.c_void_p, ctypes.c_uint32]
+    lib.tox_self_set_nospam.restype = None
+    lib.tox_friend_get_name_size.argtypes = [ctypes.c_void_p, ctypes.c_uint32]
+    lib.tox_friend_get_name_size.restype = ctypes.c_size_t
+    lib.tox_friend_get_name.argtypes = [ctypes.c_void_p, ctypes.c_uint32, ctypes.POINTER(ctypes.c_uint8)]
+    lib.tox_friend_get_name.restype = ctypes.c_int
+    lib.tox_friend_get_status_message_size.argtypes = [ctypes.c_void_p, ctypes.c_uint32]
+    lib.tox_friend_get_status_message_size.restype = ctypes.c_size_t
+    lib.tox_friend_get_status_message.argtypes = [ctypes.c_void_p, ctypes.c_uint32, ctypes.POINTER(ctypes.c_uint8)]
+    lib.tox_friend_get_status_message.restype = ctypes.c_int
+    lib.tox_friend_get_status.argtypes = [ctypes.c_void_p, ctypes.c_uint32]
+    lib.tox_friend_get_status.restype = ctypes.c_int
+    lib.tox_friend_get_connection_status.argtypes = [ctypes.c_void_p, ctypes.c_uint32]
+    lib.tox_friend_get_connection_status.restype = ctypes.c_int
+    lib.tox_friend_get_public_key.argtypes = [ctypes.c_void_p, ctypes.c_uint32, ctypes.POINTER(ctypes.c_uint8)]
+    lib.tox_friend_get_public_key.restype = ctypes.c_int
+    lib.tox_friend_get_last_online.argtypes = [ctypes.c_void_p, ctypes.c_uint32]
+    lib.tox_friend_get_last_online.restype = ctypes.c_uint64
+    lib.tox_friend_exists.argtypes = [ctypes.c_void_p, ctypes.c_uint32]
+    lib.tox_friend_exists.restype = ctypes.c_int
+    lib.tox_self_get_friend_list_size.argtypes = [ctypes.c_void_p]
+    lib.tox_self_get_friend_list_size.restype = ctypes.c_size_t
+    lib.tox_self_get_friend_list.argtypes = [ctypes.c_void_p, ctypes.POINTER(ctypes.c_uint32)]
+    lib.tox_self_get_friend_list.restype = None
+    lib.tox_conference_get_type.argtypes = [ctypes.c_void_p, ctypes.c_uint32]
+    lib.tox_conference_get_type.restype = ctypes.c_int
+    lib.tox_conference_peer_count.argtypes = [ctypes.c_void_p, ctypes.c_uint32]
+    lib.tox_conference_peer_count.restype = ctypes.c_int
+    lib.tox_conference_set_title.argtypes = [ctypes.c_void_p, ctypes.c_uint32, ctypes.POINTER(ctypes.c_uint8), ctypes.c_size_t]
+    lib.tox_conference_set_title.restype = ctypes.c_int
+    lib.tox_conference_get_title.argtypes = [ctypes.c_void_p, ctypes.c_uint32, ctypes.POINTER(ctypes.c_uint8)]
+    lib.tox_conference_get_title.restype = ctypes.c_int
+    lib.tox_conference_peer_get_name_size.argtypes = [ctypes.c_void_p, ctypes.c_uint32, ctypes.c_uint32]
+    lib.tox_conference_peer_get_name_size.restype = ctypes.c_size_t
+    lib.tox_conference_peer_get_name.argtypes = [ctypes.c_void_p, ctypes.c_uint32, ctypes.c_uint32, ctypes.POINTER(ctypes.c_uint8)]
+    lib.tox_conference_peer_get_name.restype = ctypes.c_int
+    lib.tox_conference_peer_get_public_key.argtypes = [ctypes.c_void_p, ctypes.c_uint32, ctypes.c_uint32, ctypes.POINTER(ctypes.c_uint8)]
+    lib.tox_conference_peer_get_public_key.restype = ctypes.c_int
+    lib.tox_conference_connected.argtypes = [ctypes.c_void_p, ctypes.c_uint32]
+    lib.tox_conference_connected.restype = ctypes.c_int
+    lib.tox_conference_offline_peer_count.argtypes = [ctypes.c_void_p, ctypes.c_uint32]
+    lib.tox_conference_offline_peer_count.restype = ctypes.c_uint32
+    lib.tox_conference_offline_peer_get_name_size.argtypes = [ctypes.c_void_p, ctypes.c_uint32, ctypes.c_uint32]
+    lib.tox_conference_offline_peer_get_name_size.restype = ctypes.c_size_t
+    lib.tox_conference_offline_peer_get_name.argtypes = [ctypes.c_void_p, ctypes.c_uint32, ctypes.c_uint32, ctypes.POINTER(ctypes.c_uint8)]
+    lib.tox_conference_offline_peer_get_name.restype = ctypes.c_int
+    lib.tox_file_get_file_id.argtypes = [ctypes.c_void_p, ctypes.c_uint32, ctypes.c_uint32, ctypes.POINTER(ctypes.c_uint8)]
+    lib.tox_file_get_file_id.restype = ctypes.c_int
+    lib.tox_hash.argtypes = [ctypes.POINTER(ctypes.c_uint8), ctypes.POINTER(ctypes.c_uint8), ctypes.c_size_t]
+    lib.tox_hash.restype = ctypes.c_int
+    lib.tox_abi_version_major.argtypes = []
+    lib.tox_abi_version_major.restype = ctypes.c_uint32
+    lib.tox_abi_version_minor.argtypes = []
+    lib.tox_abi_version_minor.restype = ctypes.c_uint32
+    lib.tox_abi_version_patch.argtypes = []
+    lib.tox_abi_version_patch.restype = ctypes.c_uint32
+    lib.tox_abi_version_string.argtypes = [ctypes.POINTER(ctypes.c_uint8), ctypes.c_longlong]
+    lib.tox_abi_version_string.restype = ctypes.c_longlong
+    lib.tox_abi_feature_flags.argtypes = []
+    lib.tox_abi_feature_flags.restype = ctypes.c_uint64
+    lib.tox_crypto_generate_keypair.argtypes = [ctypes.POINTER(ctypes.c_uint8), ctypes.POINTER(ctypes.c_uint8)]
+    lib.tox_crypto_generate_keypair.restype = ctypes.c_longlong
+    lib.tox_crypto_secure_wipe.argtypes = [ctypes.POINTER(ctypes.c_uint8), ctypes.c_longlong]
+    lib.tox_crypto_secure_wipe.restype = ctypes.c_longlong
+    lib.tox_self_get_safety_number.argtypes = [ctypes.c_void_p, ctypes.POINTER(ctypes.c_uint8), ctypes.POINTER(ctypes.c_uint8), ctypes.c_longlong]
+    lib.tox_self_get_safety_number.restype = ctypes.c_longlong
+
+
+_lib = _declare_loaded(_load_library(), _declare)
+
+
+# Raw functions
+
+
+def toxcore_set_callback_error_handler(tox, handler):
+    """toxcore_set_callback_error_handler registers a handler that is invoked
+    whenever dispatching a callback for this Tox instance (or a ToxAV instance
+    created from it) fails. The handler receives the callback name and the
+    error message as NUL-terminated strings that are only valid for the
+    duration of the call. Passing NULL removes the handler.
+
+    This is a toxcore-go extension; libtoxcore callbacks have no error channel.
+    """
+    return _lib.toxcore_set_callback_error_handler(tox, handler)
+
+
+def toxav_new(tox, error_ptr):
+    """toxav_new creates a new ToxAV instance from a Tox instance.
+
+    This function matches the libtoxcore toxav_new API exactly.
+    """
+    return _lib.toxav_new(tox, error_ptr)
+
+
+def toxav_kill(av):
+    """toxav_kill gracefully shuts down a ToxAV instance.
+
+    This function matches the libtoxcore toxav_kill API exactly.
+    """
+    return _lib.toxav_kill(av)
+
+
+def toxav_get_tox_from_av(av):
+    """toxav_get_tox_from_av returns the Tox instance associated with ToxAV.
+
+    This function matches the libtoxcore toxav_get_tox_from_av API exactly.
+    """
+    return _lib.toxav_get_tox_from_av(av)
+
+
+def toxav_iteration_interval(av):
+    """toxav_iteration_interval returns the iteration interval for ToxAV.
+
+    This function matches the libtoxcore toxav_iteration_interval API exactly.
+    """
+    return _lib.toxav_iteration_interval(av)
+
+
+def toxav_iterate(av):
+    """toxav_iterate performs one iteration of the ToxAV event loop.
+
+    This function matches the libtoxcore toxav_iterate API exactly.
+    """
+    return _lib.toxav_iterate(av)
+
+
+def toxav_call(av, friend_number, audio_bit_rate, video_bit_rate, error_ptr):
+    """toxav_call initiates an audio/video call.
+
+    This function matches the libtoxcore toxav_call API exactly.
+    """
+    return _lib.toxav_call(av, friend_number, audio_bit_rate, video_bit_rate, error_ptr)
+
+
+def toxav_answer(av, friend_number, audio_bit_rate, video_bit_rate, error_ptr):
+    """toxav_answer accepts an incoming audio/video call.
+
+    This function matches the libtoxcore toxav_answer API exactly.
+    """
+    return _lib.toxav_answer(av, friend_number, audio_bit_rate, video_bit_rate, error_ptr)
+
+
+def toxav_call_control(av, friend_number, control, error_ptr):
+    """toxav_call_control sends a call control command.
+
+    This function matches the libtoxcore toxav_call_control API exactly.
+    """
+    return _lib.toxav_call_control(av, friend_number, control, error_ptr)
+
+
+def toxav_audio_set_bit_rate(av, friend_number, bit_rate, error_ptr):
+    """toxav_audio_set_bit_rate sets the audio bit rate for a call.
+
+    This function matches the libtoxcore toxav_audio_set_bit_rate API exactly.
+    """
+    return _lib.toxav_audio_set_bit_rate(av, friend_number, bit_rate, error_ptr)
+
+
+def toxav_video_set_bit_rate(av, friend_number, bit_rate, error_ptr):
+    """toxav_video_set_bit_rate sets the video bit rate for a call.
+
+    This function matches the libtoxcore toxav_video_set_bit_rate API exactly.
+    """
+    return _lib.toxav_video_set_bit_rate(av, friend_number, bit_rate, error_ptr)
+
+
+def toxav_audio_send_frame(av, friend_number, pcm, sample_count, channels, sampling_rate, error_ptr):
+    """toxav_audio_send_frame sends an audio frame.
+
+    This function matches the libtoxcore toxav_audio_send_frame API exactly.
+    """
+    return _lib.toxav_audio_send_frame(av, friend_number, pcm, sample_count, channels, sampling_rate, error_ptr)
+
+
+def toxav_video_send_frame(av, friend_number, width, height, y, u, v, error_ptr):
+    """toxav_video_send_frame sends a video frame.
+
+    This function matches the libtoxcore toxav_video_send_frame API exactly.
+    """
+    return _lib.toxav_video_send_frame(av, friend_number, width, height, y, u, v, error_ptr)
+
+
+def toxav_callback_call(av, callback, user_data):
+    """No documentation."""
+    return _lib.toxav_callback_call(av, callback, user_data)
+
+
+def toxav_callback_call_state(av, callback, user_data):
+    """No documentation."""
+    return _lib.toxav_callback_call_state(av, callback, user_data)
+
+
+def toxav_callback_audio_bit_rate(av, callback, user_data):
+    """No documentation."""
+    return _lib.toxav_callback_audio_bit_rate(av, callback, user_data)
+
+
+def toxav_callback_video_bit_rate(av, callback, user_data):
+    """No documentation."""
+    return _lib.toxav_callback_video_bit_rate(av, callback, user_data)
+
+
+def toxav_callback_audio_receive_frame(av, callback, user_data):
+    """toxav_callback_audio_receive_frame registers the C audio frame callback.
+
+    IMPORTANT: The pcm pointer passed to callback points into Go-managed memory
+    and is only valid for the duration of each callback invocation. C callers
+    MUST copy the audio data before returning if it needs to be retained.
+    """
+    return _lib.toxav_callback_audio_receive_frame(av, callback, user_data)
+
+
+def toxav_callback_video_receive_frame(av, callback, user_data):
+    """toxav_callback_video_receive_frame registers the C video frame callback.
+
+    IMPORTANT: The y/u/v pointers passed to callback point into Go-managed
+    memory and are only valid for the duration of each callback invocation. C
+    callers MUST copy frame data before returning if it needs to be retained.
+    """
+    return _lib.toxav_callback_video_receive_frame(av, callback, user_data)
+
+
+def toxav_start_recording(av, friend_number, path, format, error_ptr):
+    """toxav_start_recording starts writing media received from a friend to a file.
+
+    This is a toxcore-go extension; libtoxcore has no recording API.
+    """
+    return _lib.toxav_start_recording(av, friend_number, path, format, error_ptr)
+
+
+def toxav_stop_recording(av, friend_number, error_ptr):
+    """toxav_stop_recording stops a recording and finalizes its file.
+
+    This is a toxcore-go extension; libtoxcore has no recording API.
+    """
+    return _lib.toxav_stop_recording(av, friend_number, error_ptr)
+
+
+def toxav_start_screen_share(av, friend_number, error_ptr):
+    """toxav_start_screen_share marks a call's video stream as screen content.
+
+    This is a toxcore-go extension; libtoxcore has no screen share API.
+    """
+    return _lib.toxav_start_screen_share(av, friend_number, error_ptr)
+
+
+def toxav_stop_screen_share(av, friend_number, error_ptr):
+    """toxav_stop_screen_share ends screen sharing with a friend.
+
+    This is a toxcore-go extension; libtoxcore has no screen share API.
+    """
+    return _lib.toxav_stop_screen_share(av, friend_number, error_ptr)
+
+
+def toxav_callback_recording_stats(av, callback, user_data):
+    """toxav_callback_recording_stats sets the callback reporting bytes written
+    to a recording.
+
+    This is a toxcore-go extension; libtoxcore has no recording API.
+    """
+    return _lib.toxav_callback_recording_stats(av, callback, user_data)
+
+
+def tox_new():
+    """No documentation."""
+    return _lib.tox_new()
+
+
+def tox_kill(tox):
+    """No documentation."""
+    return _lib.tox_kill(tox)
+
+
+def tox_bootstrap_simple(tox):
+    """No documentation."""
+    return _lib.tox_bootstrap_simple(tox)
+
+
+def tox_iterate(tox):
+    """No documentation."""
+    return _lib.tox_iterate(tox)
+
+
+def tox_iteration_interval(tox):
+    """No documentation."""
+    return _lib.tox_iteration_interval(tox)
+
+
+def tox_self_get_address_size(tox):
+    """No documentation."""
+    return _lib.tox_self_get_address_size(tox)
+
+
+def hex_string_to_bin(hexStr, hexLen, output, outputLen):
+    """No documentation."""
+    return _lib.hex_string_to_bin(hexStr, hexLen, output, outputLen)
+
+
+def tox_self_get_address(tox, address):
+    """tox_self_get_address copies the Tox address to the provided buffer.
+    The buffer must be at least TOX_ADDRESS_SIZE (38) bytes.
+    Returns 0 on success, -1 on error.
+    """
+    return _lib.tox_self_get_address(tox, address)
+
+
+def tox_self_get_public_key(tox, publicKey):
+    """tox_self_get_public_key copies the public key to the provided buffer.
+    The buffer must be at least TOX_PUBLIC_KEY_SIZE (32) bytes.
+    Returns 0 on success, -1 on error.
+    """
+    return _lib.tox_self_get_public_key(tox, publicKey)
+
+
+def tox_friend_add(tox, address, message, messageLen):
+    """tox_friend_add adds a friend by Tox address and sends a friend request message.
+    Returns the friend number on success, or UINT32_MAX on failure.
+    """
+    return _lib.tox_friend_add(tox, address, message, messageLen)
+
+
+def tox_friend_add_norequest(tox, publicKey):
+    """tox_friend_add_norequest adds a friend by public key without sending a request.
+    Use this to accept incoming friend requests.
+    Returns the friend number on success, or UINT32_MAX on failure.
+    """
+    return _lib.tox_friend_add_norequest(tox, publicKey)
+
+
+def tox_friend_delete(tox, friendNumber):
+    """tox_friend_delete removes a friend from the friends list.
+    Returns 0 on success, -1 on failure.
+    """
+    return _lib.tox_friend_delete(tox, friendNumber)
+
+
+def tox_friend_send_message(tox, friendNumber, messageType, message, messageLen):
+    """tox_friend_send_message sends a message to a friend.
+    messageType: 0 = normal message, 1 = action message.
+    Returns the message ID on success (always 1 for now), or 0 on failure.
+    """
+    return _lib.tox_friend_send_message(tox, friendNumber, messageType, message, messageLen)
+
+
+def tox_callback_friend_request(tox, callback, userData):
+    """tox_callback_friend_request registers a callback for friend requests.
+    The callback receives: tox pointer, public key (32 bytes), message, message length, user data.
+    """
+    return _lib.tox_callback_friend_request(tox, callback, userData)
+
+
+def tox_callback_friend_message(tox, callback, userData):
+    """tox_callback_friend_message registers a callback for friend messages.
+    The callback receives: tox pointer, friend number, message type, message, message length, user data.
+    """
+    return _lib.tox_callback_friend_message(tox, callback, userData)
+
+
+def tox_callback_friend_connection_status(tox, callback, userData):
+    """tox_callback_friend_connection_status registers a callback for friend connection status changes.
+    The callback receives: tox pointer, friend number, connection status, user data.
+    """
+    return _lib.tox_callback_friend_connection_status(tox, callback, userData)
+
+
+def tox_self_set_name(tox, name, nameLen):
+    """tox_self_set_name sets the name of this Tox instance.
+    Returns 0 on success, -1 on error.
+    """
+    return _lib.tox_self_set_name(tox, name, nameLen)
+
+
+def tox_self_get_name_size(tox):
+    """tox_self_get_name_size returns the length of the name."""
+    return _lib.tox_self_get_name_size(tox)
+
+
+def tox_self_get_name(tox, name):
+    """tox_self_get_name copies the name to the provided buffer.
+    Returns 0 on success, -1 on error.
+    """
+    return _lib.tox_self_get_name(tox, name)
+
+
+def tox_self_set_status_message(tox, message, messageLen):
+    """tox_self_set_status_message sets the status message of this Tox instance.
+    Returns 0 on success, -1 on error.
+    """
+    return _lib.tox_self_set_status_message(tox, message, messageLen)
+
+
+def tox_self_get_status_message_size(tox):
+    """tox_self_get_status_message_size returns the length of the status message."""
+    return _lib.tox_self_get_status_message_size(tox)
+
+
+def tox_self_get_status_message(tox, message):
+    """tox_self_get_status_message copies the status message to the provided buffer.
+    Returns 0 on success, -1 on error.
+    """
+    return _lib.tox_self_get_status_message(tox, message)
+
+
+def tox_conference_new(tox, err):
+    """tox_conference_new creates a new conference (group chat).
+    Returns the conference ID on success, or UINT32_MAX on failure.
+    """
+    return _lib.tox_conference_new(tox, err)
+
+
+def tox_conference_invite(tox, friendID, conferenceID, err):
+    """tox_conference_invite invites a friend to a conference.
+    Returns 0 on success, non-zero on error.
+    """
+    return _lib.tox_conference_invite(tox, friendID, conferenceID, err)
+
+
+def tox_conference_send_message(tox, conferenceID, msgType, message, length, err):
+    """No documentation."""
+    return _lib.tox_conference_send_message(tox, conferenceID, msgType, message, length, err)
+
+
+def tox_conference_delete(tox, conferenceID, err):
+    """tox_conference_delete leaves and deletes a conference.
+    Returns 0 on success, non-zero on error.
+    """
+    return _lib.tox_conference_delete(tox, conferenceID, err)
+
+
+def tox_conference_get_title_size(tox, conferenceID, err):
+    """tox_conference_get_title gets the title of a conference.
+    Returns the length of the title on success, or -1 on error.
+    """
+    return _lib.tox_conference_get_title_size(tox, conferenceID, err)
+
+
+def tox_callback_conference_message(tox, callback):
+    """tox_callback_conference_message sets the callback for conference message events."""
+    return _lib.tox_callback_conference_message(tox, callback)
+
+
+def tox_callback_conference_invite(tox, callback):
+    """tox_callback_conference_invite sets the callback for conference invite events."""
+    return _lib.tox_callback_conference_invite(tox, callback)
+
+
+def tox_file_send(tox, friendID, kind, fileSize, fileID, filename, filenameLen, err):
+    """tox_file_send sends a file send request.
+    Returns the file number on success, or UINT32_MAX on failure.
+    """
+    return _lib.tox_file_send(tox, friendID, kind, fileSize, fileID, filename, filenameLen, err)
+
+
+def tox_file_control(tox, friendID, fileID, control, err):
+    """tox_file_control controls an ongoing file transfer.
+    Returns 0 on success, non-zero on error.
+    """
+    return _lib.tox_file_control(tox, friendID, fileID, control, err)
+
+
+def tox_file_send_chunk(tox, friendID, fileID, position, data, length, err):
+    """tox_file_send_chunk sends a chunk of a file being transferred.
+    Returns 0 on success, non-zero on error.
+    """
+    return _lib.tox_file_send_chunk(tox, friendID, fileID, position, data, length, err)
+
+
+def tox_callback_file_recv(tox, callback):
+    """tox_callback_file_recv sets the callback for file receive events."""
+    return _lib.tox_callback_file_recv(tox, callback)
+
+
+def tox_callback_file_recv_chunk(tox, callback):
+    """tox_callback_file_recv_chunk sets the callback for file chunk receive events."""
+    return _lib.tox_callback_file_recv_chunk(tox, callback)
+
+
+def tox_callback_file_chunk_request(tox, callback):
+    """tox_callback_file_chunk_request sets the callback for file chunk request events."""
+    return _lib.tox_callback_file_chunk_request(tox, callback)
+
+
+def tox_self_get_connection_status(tox):
+    """tox_self_get_connection_status returns the connection status of the Tox instance.
+    Returns: 0 = TCP, 1 = UDP, -1 = error
+    """
+    return _lib.tox_self_get_connection_status(tox)
+
+
+def tox_self_get_status(tox):
+    """tox_self_get_status returns the current user status of this Tox instance.
+    Returns: 0 = None, 1 = Away, 2 = Busy, -1 = error
+    """
+    return _lib.tox_self_get_status(tox)
+
+
+def tox_self_set_status(tox, status):
+    """tox_self_set_status sets the user status of this Tox instance.
+    status: 0 = None, 1 = Away, 2 = Busy
+    Returns: 0 on success, -1 on error
+    """
+    return _lib.tox_self_set_status(tox, status)
+
+
+def tox_self_get_nospam(tox):
+    """tox_self_get_nospam returns the 4-byte nospam value from the Tox ID."""
+    return _lib.tox_self_get_nospam(tox)
+
+
+def tox_self_set_nospam(tox, nospam):
+    """tox_self_set_nospam sets the 4-byte nospam value for the Tox ID."""
+    return _lib.tox_self_set_nospam(tox, nospam)
+
+
+def tox_friend_get_name_size(tox, friendNumber):
+    """tox_friend_get_name_size returns the length of a friend's name.
+    Returns: The length of the name, or 0 on error.
+    """
+    return _lib.tox_friend_get_name_size(tox, friendNumber)
+
+
+def tox_friend_get_name(tox, friendNumber, name):
+    """tox_friend_get_name writes a friend's name to a buffer.
+    name: Buffer to write the name to (must be at least tox_friend_get_name_size bytes).
+    WARNING: This function follows the libtoxcore size-then-copy pattern. If the name changes
+    between the tox_friend_get_name_size() call and this function, the buffer may overflow.
+    Callers must synchronize the size+copy call pair as one logical operation when the
+    peer's profile may change concurrently.
+    Returns: 1 on success, 0 on error.
+    """
+    return _lib.tox_friend_get_name(tox, friendNumber, name)
+
+
+def tox_friend_get_status_message_size(tox, friendNumber):
+    """tox_friend_get_status_message_size returns the length of a friend's status message.
+    Returns: The length of the status message, or 0 on error.
+    """
+    return _lib.tox_friend_get_status_message_size(tox, friendNumber)
+
+
+def tox_friend_get_status_message(tox, friendNumber, statusMessage):
+    """tox_friend_get_status_message writes a friend's status message to a buffer.
+    status_message: Buffer to write the status message to.
+    WARNING: This function follows the libtoxcore size-then-copy pattern. If the status message changes
+    between the tox_friend_get_status_message_size() call and this function, the buffer may overflow.
+    Callers must synchronize the size+copy call pair as one logical operation when the
+    peer's profile may change concurrently.
+    Returns: 1 on success, 0 on error.
+    """
+    return _lib.tox_friend_get_status_message(tox, friendNumber, statusMessage)
+
+
+def tox_friend_get_status(tox, friendNumber):
+    """tox_friend_get_status returns the status of a friend.
+    Returns: 0 = None/Online, 1 = Away, 2 = Busy, -1 = error
+    """
+    return _lib.tox_friend_get_status(tox, friendNumber)
+
+
+def tox_friend_get_connection_status(tox, friendNumber):
+    """tox_friend_get_connection_status returns the connection status of a friend.
+    Returns: 0 = None, 1 = TCP, 2 = UDP, -1 = error
+    """
+    return _lib.tox_friend_get_connection_status(tox, friendNumber)
+
+
+def tox_friend_get_public_key(tox, friendNumber, publicKey):
+    """tox_friend_get_public_key writes a friend's public key to a buffer.
+    public_key: Buffer to write the 32-byte public key to.
+    Returns: 1 on success, 0 on error.
+    """
+    return _lib.tox_friend_get_public_key(tox, friendNumber, publicKey)
+
+
+def tox_friend_get_last_online(tox, friendNumber):
+    """tox_friend_get_last_online returns the Unix timestamp of when a friend was last online.
+    Returns: Unix timestamp, or 0 on error.
+    """
+    return _lib.tox_friend_get_last_online(tox, friendNumber)
+
+
+def tox_friend_exists(tox, friendNumber):
+    """tox_friend_exists checks if a friend with the given number exists.
+    Returns: 1 if exists, 0 if not.
+    """
+    return _lib.tox_friend_exists(tox, friendNumber)
+
+
+def tox_self_get_friend_list_size(tox):
+    """tox_self_get_friend_list_size returns the number of friends."""
+    return _lib.tox_self_get_friend_list_size(tox)
+
+
+def tox_self_get_friend_list(tox, friendList):
+    """tox_self_get_friend_list writes the friend list to a buffer.
+    friend_list: Buffer to write friend numbers to.
+    Returns: nothing (void function in C API)
+
+    NOTE: This API does not receive the caller's buffer length. The caller must
+    allocate from tox_self_get_friend_list_size() and synchronize that size/list
+    sequence externally; concurrent friend additions can otherwise overflow.
+    """
+    return _lib.tox_self_get_friend_list(tox, friendList)
+
+
+def tox_conference_get_type(tox, conferenceNumber):
+    """tox_conference_get_type returns the type of a conference.
+    Returns: 0 = Text, 1 = AV, -1 = error.
+    """
+    return _lib.tox_conference_get_type(tox, conferenceNumber)
+
+
+def tox_conference_peer_count(tox, conferenceNumber):
+    """tox_conference_peer_count returns the number of peers in a conference.
+    Returns: Number of peers, or -1 on error.
+    """
+    return _lib.tox_conference_peer_count(tox, conferenceNumber)
+
+
+def tox_conference_set_title(tox, conferenceNumber, title, length):
+    """tox_conference_set_title sets the title of a conference.
+    Returns: 1 on success, 0 on error.
+    """
+    return _lib.tox_conference_set_title(tox, conferenceNumber, title, length)
+
+
+def tox_conference_get_title(tox, conferenceNumber, title):
+    """tox_conference_get_title writes the title of a conference to a buffer.
+    title: Buffer to write the title to (must be at least tox_conference_get_title_size bytes).
+    Returns: 1 on success, 0 on error.
+    """
+    return _lib.tox_conference_get_title(tox, conferenceNumber, title)
+
+
+def tox_conference_peer_get_name_size(tox, conferenceNumber, peerNumber):
+    """tox_conference_peer_get_name_size returns the size of a peer's name.
+    Returns: The size of the name, or 0 on error.
+    """
+    return _lib.tox_conference_peer_get_name_size(tox, conferenceNumber, peerNumber)
+
+
+def tox_conference_peer_get_name(tox, conferenceNumber, peerNumber, name):
+    """tox_conference_peer_get_name writes a peer's name to a buffer.
+    name: Buffer to write the name to.
+    Returns: 1 on success, 0 on error.
+    """
+    return _lib.tox_conference_peer_get_name(tox, conferenceNumber, peerNumber, name)
+
+
+def tox_conference_peer_get_public_key(tox, conferenceNumber, peerNumber, publicKey):
+    """tox_conference_peer_get_public_key writes a peer's public key to a buffer.
+    public_key: Buffer to write the 32-byte public key to.
+    Returns: 1 on success, 0 on error.
+    """
+    return _lib.tox_conference_peer_get_public_key(tox, conferenceNumber, peerNumber, publicKey)
+
+
+def tox_conference_connected(tox, conferenceNumber):
+    """tox_conference_connected returns whether we are connected to a conference.
+    Returns: 1 if connected, 0 if not connected or error.
+    """
+    return _lib.tox_conference_connected(tox, conferenceNumber)
+
+
+def tox_conference_offline_peer_count(tox, conferenceNumber):
+    """tox_conference_offline_peer_count returns the number of offline peers in a conference.
+    Returns: Number of offline peers, or 0 on error.
+    """
+    return _lib.tox_conference_offline_peer_count(tox, conferenceNumber)
+
+
+def tox_conference_offline_peer_get_name_size(tox, conferenceNumber, offlinePeerNumber):
+    """tox_conference_offline_peer_get_name_size returns the size of an offline peer's name.
+    Returns: The size of the name, or 0 on error.
+    """
+    return _lib.tox_conference_offline_peer_get_name_size(tox, conferenceNumber, offlinePeerNumber)
+
+
+def tox_conference_offline_peer_get_name(tox, conferenceNumber, offlinePeerNumber, name):
+    """tox_conference_offline_peer_get_name writes an offline peer's name to a buffer.
+    name: Buffer to write the name to.
+    Returns: 1 on success, 0 on error.
+    """
+    return _lib.tox_conference_offline_peer_get_name(tox, conferenceNumber, offlinePeerNumber, name)
+
+
+def tox_file_get_file_id(tox, friendNumber, fileNumber, fileID):
+    """tox_file_get_file_id gets the file ID for a file transfer.
+    file_id: Buffer to write the 32-byte file ID to.
+    Returns: 1 on success, 0 on error.
+    """
+    return _lib.tox_file_get_file_id(tox, friendNumber, fileNumber, fileID)
+
+
+def tox_hash(hash, data, length):
+    """tox_hash computes the SHA-256 hash of data.
+    hash: Buffer to write the 32-byte hash to.
+    Returns: 1 on success, 0 on error.
+    """
+    return _lib.tox_hash(hash, data, length)
+
+
+def tox_abi_version_major():
+    """tox_abi_version_major returns the C ABI major version."""
+    return _lib.tox_abi_version_major()
+
+
+def tox_abi_version_minor():
+    """tox_abi_version_minor returns the C ABI minor version."""
+    return _lib.tox_abi_version_minor()
+
+
+def tox_abi_version_patch():
+    """tox_abi_version_patch returns the C ABI patch version."""
+    return _lib.tox_abi_version_patch()
+
+
+def tox_abi_version_string(out, outLen):
+    """tox_abi_version_string writes the ABI semantic version string (e.g. "1.0.0").
+    out may be nil to query required size. Returns string length without terminator,
+    or 0 on error.
+    """
+    return _lib.tox_abi_version_string(out, outLen)
+
+
+def tox_abi_feature_flags():
+    """tox_abi_feature_flags returns a bitmask of security-critical ABI features."""
+    return _lib.tox_abi_feature_flags()
+
+
+def tox_crypto_generate_keypair(publicKey, secretKey):
+    """tox_crypto_generate_keypair creates a new Curve25519 key pair.
+    publicKey and secretKey must each point to writable 32-byte buffers.
+    Returns 1 on success, 0 on error.
+    """
+    return _lib.tox_crypto_generate_keypair(publicKey, secretKey)
+
+
+def tox_crypto_secure_wipe(data, length):
+    """tox_crypto_secure_wipe clears a mutable byte buffer in-place.
+    Returns 1 on success, 0 on error.
+    """
+    return _lib.tox_crypto_secure_wipe(data, length)
+
+
+def tox_self_get_safety_number(tox, peerPublicKey, out, outLen):
+    """tox_self_get_safety_number derives the 60-digit safety number for this Tox
+    instance and a peer public key.
+
+    peerPublicKey must point to a 32-byte key.
+    out may be nil to query required size.
+    Returns the string length (without null terminator) on success, 0 on error.
+    """
+    return _lib.tox_self_get_safety_number(tox, peerPublicKey, out, outLen)
+
+
+class Tox(_Handle):
+    """A toxcore-go instance created with tox_new and released with tox_kill."""
+
+    def __init__(self):
+        super().__init__()
+        self._ptr = _lib.tox_new()
+        if not self._ptr:
+            raise ToxError("tox_new", -1)
+
+    def kill(self):
+        """Releases the instance. Safe to call more than once."""
+        if self._ptr:
+            _lib.tox_kill(self._ptr)
+            self._ptr = None
+            self._callbacks.clear()
+
+    def address(self):
+        """Returns the binary Tox address."""
+        self._check_open()
+        buf = (ctypes.c_uint8 * _lib.tox_self_get_address_size(self._ptr))()
+        if _lib.tox_self_get_address(self._ptr, buf) != 0:
+            raise ToxError("tox_self_get_address", -1)
+        return bytes(buf)
+
+
+    def bootstrap_simple(self):
+        """No documentation."""
+        self._check_open()
+        result = _lib.tox_bootstrap_simple(self._ptr)
+        self._raise_pending()
+        return result
+
+    def iterate(self):
+        """No documentation."""
+        self._check_open()
+        result = _lib.tox_iterate(self._ptr)
+        self._raise_pending()
+        return result
+
+    def iteration_interval(self):
+        """No documentation."""
+        self._check_open()
+        result = _lib.tox_iteration_interval(self._ptr)
+        self._raise_pending()
+        return result
+
+    def self_get_address_size(self):
+        """No documentation."""
+        self._check_open()
+        result = _lib.tox_self_get_address_size(self._ptr)
+        self._raise_pending()
+        return result
+
+    def self_get_address(self, address):
+        """tox_self_get_address copies the Tox address to the provided buffer.
+        The buffer must be at least TOX_ADDRESS_SIZE (38) bytes.
+        Returns 0 on success, -1 on error.
+        """
+        self._check_open()
+        result = _lib.tox_self_get_address(self._ptr, _as_pointer(address, ctypes.POINTER(ctypes.c_uint8)))
+        self._raise_pending()
+        return result
+
+    def self_get_public_key(self, publicKey):
+        """tox_self_get_public_key copies the public key to the provided buffer.
+        The buffer must be at least TOX_PUBLIC_KEY_SIZE (32) bytes.
+        Returns 0 on success, -1 on error.
+        """
+        self._check_open()
+        result = _lib.tox_self_get_public_key(self._ptr, _as_pointer(publicKey, ctypes.POINTER(ctypes.c_uint8)))
+        self._raise_pending()
+        return result
+
+    def friend_add(self, address, message, messageLen):
+        """tox_friend_add adds a friend by Tox address and sends a friend request message.
+        Returns the friend number on success, or UINT32_MAX on failure.
+        """
+        self._check_open()
+        result = _lib.tox_friend_add(self._ptr, _as_pointer(address, ctypes.POINTER(ctypes.c_uint8)), _as_pointer(message, ctypes.POINTER(ctypes.c_uint8)), messageLen)
+        self._raise_pending()
+        return result
+
+    def friend_add_norequest(self, publicKey):
+        """tox_friend_add_norequest adds a friend by public key without sending a request.
+        Use this to accept incoming friend requests.
+        Returns the friend number on success, or UINT32_MAX on failure.
+        """
+        self._check_open()
+        result = _lib.tox_friend_add_norequest(self._ptr, _as_pointer(publicKey, ctypes.POINTER(ctypes.c_uint8)))
+        self._raise_pending()
+        return result
+
+    def friend_delete(self, friendNumber):
+        """tox_friend_delete removes a friend from the friends list.
+        Returns 0 on success, -1 on failure.
+        """
+        self._check_open()
+        result = _lib.tox_friend_delete(self._ptr, friendNumber)
+        self._raise_pending()
+        return result
+
+    def friend_send_message(self, friendNumber, messageType, message, messageLen):
+        """tox_friend_send_message sends a message to a friend.
+        messageType: 0 = normal message, 1 = action message.
+        Returns the message ID on success (always 1 for now), or 0 on failure.
+        """
+        self._check_open()
+        result = _lib.tox_friend_send_message(self._ptr, friendNumber, messageType, _as_pointer(message, ctypes.POINTER(ctypes.c_uint8)), messageLen)
+        self._raise_pending()
+        return result
+
+    def callback_friend_request(self, callback):
+        """tox_callback_friend_request registers a callback for friend requests.
+        The callback receives: tox pointer, public key (32 bytes), message, message length, user data.
+
+        The callable receives the C callback arguments without the instance
+        handle and user data. Pointer/length pairs are passed as bytes.
+        Exceptions raised by the callable are re-raised from the next
+        method call on this instance.
+        """
+        self._check_open()
+        cfunc = self._wrap_callback('friend_request_cb', friend_request_cb, callback)
+        _lib.tox_callback_friend_request(self._ptr, ctypes.cast(cfunc, ctypes.c_void_p), None)
+
+    def callback_friend_message(self, callback):
+        """tox_callback_friend_message registers a callback for friend messages.
+        The callback receives: tox pointer, friend number, message type, message, message length, user data.
+
+        The callable receives the C callback arguments without the instance
+        handle and user data. Pointer/length pairs are passed as bytes.
+        Exceptions raised by the callable are re-raised from the next
+        method call on this instance.
+        """
+        self._check_open()
+        cfunc = self._wrap_callback('friend_message_cb', friend_message_cb, callback)
+        _lib.tox_callback_friend_message(self._ptr, ctypes.cast(cfunc, ctypes.c_void_p), None)
+
+    def callback_friend_connection_status(self, callback):
+        """tox_callback_friend_connection_status registers a callback for friend connection status changes.
+        The callback receives: tox pointer, friend number, connection status, user data.
+
+        The callable receives the C callback arguments without the instance
+        handle and user data. Pointer/length pairs are passed as bytes.
+        Exceptions raised by the callable are re-raised from the next
+        method call on this instance.
+        """
+        self._check_open()
+        cfunc = self._wrap_callback('friend_connection_status_cb', friend_connection_status_cb, callback)
+        _lib.tox_callback_friend_connection_status(self._ptr, ctypes.cast(cfunc, ctypes.c_void_p), None)
+
+    def self_set_name(self, name, nameLen):
+        """tox_self_set_name sets the name of this Tox instance.
+        Returns 0 on success, -1 on error.
+        """
+        self._check_open()
+        result = _lib.tox_self_set_name(self._ptr, _as_pointer(name, ctypes.POINTER(ctypes.c_uint8)), nameLen)
+        self._raise_pending()
+        return result
+
+    def self_get_name_size(self):
+        """tox_self_get_name_size returns the length of the name."""
+        self._check_open()
+        result = _lib.tox_self_get_name_size(self._ptr)
+        self._raise_pending()
+        return result
+
+    def self_get_name(self, name):
+        """tox_self_get_name copies the name to the provided buffer.
+        Returns 0 on success, -1 on error.
+        """
+        self._check_open()
+        result = _lib.tox_self_get_name(self._ptr, _as_pointer(name, ctypes.POINTER(ctypes.c_uint8)))
+        self._raise_pending()
+        return result
+
+    def self_set_status_message(self, message, messageLen):
+        """tox_self_set_status_message sets the status message of this Tox instance.
+        Returns 0 on success, -1 on error.
+        """
+        self._check_open()
+        result = _lib.tox_self_set_status_message(self._ptr, _as_pointer(message, ctypes.POINTER(ctypes.c_uint8)), messageLen)
+        self._raise_pending()
+        return result
+
+    def self_get_status_message_size(self):
+        """tox_self_get_status_message_size returns the length of the status message."""
+        self._check_open()
+        result = _lib.tox_self_get_status_message_size(self._ptr)
+        self._raise_pending()
+        return result
+
+    def self_get_status_message(self, message):
+        """tox_self_get_status_message copies the status message to the provided buffer.
+        Returns 0 on success, -1 on error.
+        """
+        self._check_open()
+        result = _lib.tox_self_get_status_message(self._ptr, _as_pointer(message, ctypes.POINTER(ctypes.c_uint8)))
+        self._raise_pending()
+        return result
+
+    def conference_new(self):
+        """tox_conference_new creates a new conference (group chat).
+        Returns the conference ID on success, or UINT32_MAX on failure.
+        """
+        self._check_open()
+        _err = ctypes.c_uint(0)
+        result = _lib.tox_conference_new(self._ptr, ctypes.byref(_err))
+        self._raise_pending()
+        _check_error('tox_conference_new', _err.value, 'GoUint32')
+        return result
+
+    def conference_invite(self, friendID, conferenceID):
+        """tox_conference_invite invites a friend to a conference.
+        Returns 0 on success, non-zero on error.
+        """
+        self._check_open()
+        _err = ctypes.c_uint(0)
+        result = _lib.tox_conference_invite(self._ptr, friendID, conferenceID, ctypes.byref(_err))
+        self._raise_pending()
+        _check_error('tox_conference_invite', _err.value, 'GoUint32')
+        return result
+
+    def conference_send_message(self, conferenceID, msgType, message, length):
+        """No documentation."""
+        self._check_open()
+        _err = ctypes.c_uint(0)
+        result = _lib.tox_conference_send_message(self._ptr, conferenceID, msgType, _as_pointer(message, ctypes.POINTER(ctypes.c_uint8)), length, ctypes.byref(_err))
+        self._raise_pending()
+        _check_error('tox_conference_send_message', _err.value, 'GoUint32')
+        return result
+
+    def conference_delete(self, conferenceID):
+        """tox_conference_delete leaves and deletes a conference.
+        Returns 0 on success, non-zero on error.
+        """
+        self._check_open()
+        _err = ctypes.c_uint(0)
+        result = _lib.tox_conference_delete(self._ptr, conferenceID, ctypes.byref(_err))
+        self._raise_pending()
+        _check_error('tox_conference_delete', _err.value, 'GoUint32')
+        return result
+
+    def conference_get_title_size(self, conferenceID):
+        """tox_conference_get_title gets the title of a conference.
+        Returns the length of the title on success, or -1 on error.
+        """
+        self._check_open()
+        _err = ctypes.c_uint(0)
+        result = _lib.tox_conference_get_title_size(self._ptr, conferenceID, ctypes.byref(_err))
+        self._raise_pending()
+        _check_error('tox_conference_get_title_size', _err.value, 'GoUint32')
+        return result
+
+    def callback_conference_message(self, callback):
+        """tox_callback_conference_message sets the callback for conference message events.
+
+        The callable receives the C callback arguments without the instance
+        handle and user data. Pointer/length pairs are passed as bytes.
+        Exceptions raised by the callable are re-raised from the next
+        method call on this instance.
+        """
+        self._check_open()
+        cfunc = self._wrap_callback('group_message_cb', group_message_cb, callback)
+        _lib.tox_callback_conference_message(self._ptr, cfunc)
+
+    def callback_conference_invite(self, callback):
+        """tox_callback_conference_invite sets the callback for conference invite events.
+
+        The callable receives the C callback arguments without the instance
+        handle and user data. Pointer/length pairs are passed as bytes.
+        Exceptions raised by the callable are re-raised from the next
+        method call on this instance.
+        """
+        self._check_open()
+        cfunc = self._wrap_callback('group_invite_cb', group_invite_cb, callback)
+        _lib.tox_callback_conference_invite(self._ptr, cfunc)
+
+    def file_send(self, friendID, kind, fileSize, fileID, filename, filenameLen):
+        """tox_file_send sends a file send request.
+        Returns the file number on success, or UINT32_MAX on failure.
+        """
+        self._check_open()
+        _err = ctypes.c_uint(0)
+        result = _lib.tox_file_send(self._ptr, friendID, kind, fileSize, _as_pointer(fileID, ctypes.POINTER(ctypes.c_uint8)), _as_pointer(filename, ctypes.POINTER(ctypes.c_uint8)), filenameLen, ctypes.byref(_err))
+        self._raise_pending()
+        _check_error('tox_file_send', _err.value, 'GoUint32')
+        return result
+
+    def file_control(self, friendID, fileID, control):
+        """tox_file_control controls an ongoing file transfer.
+        Returns 0 on success, non-zero on error.
+        """
+        self._check_open()
+        _err = ctypes.c_uint(0)
+        result = _lib.tox_file_control(self._ptr, friendID, fileID, control, ctypes.byref(_err))
+        self._raise_pending()
+        _check_error('tox_file_control', _err.value, 'GoUint32')
+        return result
+
+    def file_send_chunk(self, friendID, fileID, position, data, length):
+        """tox_file_send_chunk sends a chunk of a file being transferred.
+        Returns 0 on success, non-zero on error.
+        """
+        self._check_open()
+        _err = ctypes.c_uint(0)
+        result = _lib.tox_file_send_chunk(self._ptr, friendID, fileID, position, _as_pointer(data, ctypes.POINTER(ctypes.c_uint8)), length, ctypes.byref(_err))
+        self._raise_pending()
+        _check_error('tox_file_send_chunk', _err.value, 'GoUint32')
+        return result
+
+    def callback_file_recv(self, callback):
+        """tox_callback_file_recv sets the callback for file receive events.
+
+        The callable receives the C callback arguments without the instance
+        handle and user data. Pointer/length pairs are passed as bytes.
+        Exceptions raised by the callable are re-raised from the next
+        method call on this instance.
+        """
+        self._check_open()
+        cfunc = self._wrap_callback('file_recv_cb', file_recv_cb, callback)
+        _lib.tox_callback_file_recv(self._ptr, cfunc)
+
+    def callback_file_recv_chunk(self, callback):
+        """tox_callback_file_recv_chunk sets the callback for file chunk receive events.
+
+        The callable receives the C callback arguments without the instance
+        handle and user data. Pointer/length pairs are passed as bytes.
+        Exceptions raised by the callable are re-raised from the next
+        method call on this instance.
+        """
+        self._check_open()
+        cfunc = self._wrap_callback('file_recv_chunk_cb', file_recv_chunk_cb, callback)
+        _lib.tox_callback_file_recv_chunk(self._ptr, cfunc)
+
+    def callback_file_chunk_request(self, callback):
+        """tox_callback_file_chunk_request sets the callback for file chunk request events.
+
+        The callable receives the C callback arguments without the instance
+        handle and user data. Pointer/length pairs are passed as bytes.
+        Exceptions raised by the callable are re-raised from the next
+        method call on this instance.
+        """
+        self._check_open()
+        cfunc = self._wrap_callback('file_chunk_request_cb', file_chunk_request_cb, callback)
+        _lib.tox_callback_file_chunk_request(self._ptr, cfunc)
+
+    def self_get_connection_status(self):
+        """tox_self_get_connection_status returns the connection status of the Tox instance.
+        Returns: 0 = TCP, 1 = UDP, -1 = error
+        """
+        self._check_open()
+        result = _lib.tox_self_get_connection_status(self._ptr)
+        self._raise_pending()
+        return result
+
+    def self_get_status(self):
+        """tox_self_get_status returns the current user status of this Tox instance.
+        Returns: 0 = None, 1 = Away, 2 = Busy, -1 = error
+        """
+        self._check_open()
+        result = _lib.tox_self_get_status(self._ptr)
+        self._raise_pending()
+        return result
+
+    def self_set_status(self, status):
+        """tox_self_set_status sets the user status of this Tox instance.
+        status: 0 = None, 1 = Away, 2 = Busy
+        Returns: 0 on success, -1 on error
+        """
+        self._check_open()
+        result = _lib.tox_self_set_status(self._ptr, status)
+        self._raise_pending()
+        return result
+
+    def self_get_nospam(self):
+        """tox_self_get_nospam returns the 4-byte nospam value from the Tox ID."""
+        self._check_open()
+        result = _lib.tox_self_get_nospam(self._ptr)
+        self._raise_pending()
+        return result
+
+    def self_set_nospam(self, nospam):
+        """tox_self_set_nospam sets the 4-byte nospam value for the Tox ID."""
+        self._check_open()
+        result = _lib.tox_self_set_nospam(self._ptr, nospam)
+        self._raise_pending()
+        return result
+
+    def friend_get_name_size(self, friendNumber):
+        """tox_friend_get_name_size returns the length of a friend's name.
+        Returns: The length of the name, or 0 on error.
+        """
+        self._check_open()
+        result = _lib.tox_friend_get_name_size(self._ptr, friendNumber)
+        self._raise_pending()
+        return result
+
+    def friend_get_name(self, friendNumber, name):
+        """tox_friend_get_name writes a friend's name to a buffer.
+        name: Buffer to write the name to (must be at least tox_friend_get_name_size bytes).
+        WARNING: This function follows the libtoxcore size-then-copy pattern. If the name changes
+        between the tox_friend_get_name_size() call and this function, the buffer may overflow.
+        Callers must synchronize the size+copy call pair as one logical operation when the
+        peer's profile may change concurrently.
+        Returns: 1 on success, 0 on error.
+        """
+        self._check_open()
+        result = _lib.tox_friend_get_name(self._ptr, friendNumber, _as_pointer(name, ctypes.POINTER(ctypes.c_uint8)))
+        self._raise_pending()
+        return result
+
+    def friend_get_status_message_size(self, friendNumber):
+        """tox_friend_get_status_message_size returns the length of a friend's status message.
+        Returns: The length of the status message, or 0 on error.
+        """
+        self._check_open()
+        result = _lib.tox_friend_get_status_message_size(self._ptr, friendNumber)
+        self._raise_pending()
+        return result
+
+    def friend_get_status_message(self, friendNumber, statusMessage):
+        """tox_friend_get_status_message writes a friend's status message to a buffer.
+        status_message: Buffer to write the status message to.
+        WARNING: This function follows the libtoxcore size-then-copy pattern. If the status message changes
+        between the tox_friend_get_status_message_size() call and this function, the buffer may overflow.
+        Callers must synchronize the size+copy call pair as one logical operation when the
+        peer's profile may change concurrently.
+        Returns: 1 on success, 0 on error.
+        """
+        self._check_open()
+        result = _lib.tox_friend_get_status_message(self._ptr, friendNumber, _as_pointer(statusMessage, ctypes.POINTER(ctypes.c_uint8)))
+        self._raise_pending()
+        return result
+
+    def friend_get_status(self, friendNumber):
+        """tox_friend_get_status returns the status of a friend.
+        Returns: 0 = None/Online, 1 = Away, 2 = Busy, -1 = error
+        """
+        self._check_open()
+        result = _lib.tox_friend_get_status(self._ptr, friendNumber)
+        self._raise_pending()
+        return result
+
+    def friend_get_connection_status(self, friendNumber):
+        """tox_friend_get_connection_status returns the connection status of a friend.
+        Returns: 0 = None, 1 = TCP, 2 = UDP, -1 = error
+        """
+        self._check_open()
+        result = _lib.tox_friend_get_connection_status(self._ptr, friendNumber)
+        self._raise_pending()
+        return result
+
+    def friend_get_public_key(self, friendNumber, publicKey):
+        """tox_friend_get_public_key writes a friend's public key to a buffer.
+        public_key: Buffer to write the 32-byte public key to.
+        Returns: 1 on success, 0 on error.
+        """
+        self._check_open()
+        result = _lib.tox_friend_get_public_key(self._ptr, friendNumber, _as_pointer(publicKey, ctypes.POINTER(ctypes.c_uint8)))
+        self._raise_pending()
+        return result
+
+    def friend_get_last_online(self, friendNumber):
+        """tox_friend_get_last_online returns the Unix timestamp of when a friend was last online.
+        Returns: Unix timestamp, or 0 on error.
+        """
+        self._check_open()
+        result = _lib.tox_friend_get_last_online(self._ptr, friendNumber)
+        self._raise_pending()
+        return result
+
+    def friend_exists(self, friendNumber):
+        """tox_friend_exists checks if a friend with the given number exists.
+        Returns: 1 if exists, 0 if not.
+        """
+        self._check_open()
+        result = _lib.tox_friend_exists(self._ptr, friendNumber)
+        self._raise_pending()
+        return result
+
+    def self_get_friend_list_size(self):
+        """tox_self_get_friend_list_size returns the number of friends."""
+        self._check_open()
+        result = _lib.tox_self_get_friend_list_size(self._ptr)
+        self._raise_pending()
+        return result
+
+    def self_get_friend_list(self, friendList):
+        """tox_self_get_friend_list writes the friend list to a buffer.
+        friend_list: Buffer to write friend numbers to.
+        Returns: nothing (void function in C API)
+
+        NOTE: This API does not receive the caller's buffer length. The caller must
+        allocate from tox_self_get_friend_list_size() and synchronize that size/list
+        sequence externally; concurrent friend additions can otherwise overflow.
+        """
+        self._check_open()
+        result = _lib.tox_self_get_friend_list(self._ptr, _as_pointer(friendList, ctypes.POINTER(ctypes.c_uint32)))
+        self._raise_pending()
+        return result
+
+    def conference_get_type(self, conferenceNumber):
+        """tox_conference_get_type returns the type of a conference.
+        Returns: 0 = Text, 1 = AV, -1 = error.
+        """
+        self._check_open()
+        result = _lib.tox_conference_get_type(self._ptr, conferenceNumber)
+        self._raise_pending()
+        return result
+
+    def conference_peer_count(self, conferenceNumber):
+        """tox_conference_peer_count returns the number of peers in a conference.
+        Returns: Number of peers, or -1 on error.
+        """
+        self._check_open()
+        result = _lib.tox_conference_peer_count(self._ptr, conferenceNumber)
+        self._raise_pending()
+        return result
+
+    def conference_set_title(self, conferenceNumber, title, length):
+        """tox_conference_set_title sets the title of a conference.
+        Returns: 1 on success, 0 on error.
+        """
+        self._check_open()
+        result = _lib.tox_conference_set_title(self._ptr, conferenceNumber, _as_pointer(title, ctypes.POINTER(ctypes.c_uint8)), length)
+        self._raise_pending()
+        return result
+
+    def conference_get_title(self, conferenceNumber, title):
+        """tox_conference_get_title writes the title of a conference to a buffer.
+        title: Buffer to write the title to (must be at least tox_conference_get_title_size bytes).
+        Returns: 1 on success, 0 on error.
+        """
+        self._check_open()
+        result = _lib.tox_conference_get_title(self._ptr, conferenceNumber, _as_pointer(title, ctypes.POINTER(ctypes.c_uint8)))
+        self._raise_pending()
+        return result
+
+    def conference_peer_get_name_size(self, conferenceNumber, peerNumber):
+        """tox_conference_peer_get_name_size returns the size of a peer's name.
+        Returns: The size of the name, or 0 on error.
+        """
+        self._check_open()
+        result = _lib.tox_conference_peer_get_name_size(self._ptr, conferenceNumber, peerNumber)
+        self._raise_pending()
+        return result
+
+    def conference_peer_get_name(self, conferenceNumber, peerNumber, name):
+        """tox_conference_peer_get_name writes a peer's name to a buffer.
+        name: Buffer to write the name to.
+        Returns: 1 on success, 0 on error.
+        """
+        self._check_open()
+        result = _lib.tox_conference_peer_get_name(self._ptr, conferenceNumber, peerNumber, _as_pointer(name, ctypes.POINTER(ctypes.c_uint8)))
+        self._raise_pending()
+        return result
+
+    def conference_peer_get_public_key(self, conferenceNumber, peerNumber, publicKey):
+        """tox_conference_peer_get_public_key writes a peer's public key to a buffer.
+        public_key: Buffer to write the 32-byte public key to.
+        Returns: 1 on success, 0 on error.
+        """
+        self._check_open()
+        result = _lib.tox_conference_peer_get_public_key(self._ptr, conferenceNumber, peerNumber, _as_pointer(publicKey, ctypes.POINTER(ctypes.c_uint8)))
+        self._raise_pending()
+        return result
+
+    def conference_connected(self, conferenceNumber):
+        """tox_conference_connected returns whether we are connected to a conference.
+        Returns: 1 if connected, 0 if not connected or error.
+        """
+        self._check_open()
+        result = _lib.tox_conference_connected(self._ptr, conferenceNumber)
+        self._raise_pending()
+        return result
+
+    def conference_offline_peer_count(self, conferenceNumber):
+        """tox_conference_offline_peer_count returns the number of offline peers in a conference.
+        Returns: Number of offline peers, or 0 on error.
+        """
+        self._check_open()
+        result = _lib.tox_conference_offline_peer_count(self._ptr, conferenceNumber)
+        self._raise_pending()
+        return result
+
+    def conference_offline_peer_get_name_size(self, conferenceNumber, offlinePeerNumber):
+        """tox_conference_offline_peer_get_name_size returns the size of an offline peer's name.
+        Returns: The size of the name, or 0 on error.
+        """
+        self._check_open()
+        result = _lib.tox_conference_offline_peer_get_name_size(self._ptr, conferenceNumber, offlinePeerNumber)
+        self._raise_pending()
+        return result
+
+    def conference_offline_peer_get_name(self, conferenceNumber, offlinePeerNumber, name):
+        """tox_conference_offline_peer_get_name writes an offline peer's name to a buffer.
+        name: Buffer to write the name to.
+        Returns: 1 on success, 0 on error.
+        """
+        self._check_open()
+        result = _lib.tox_conference_offline_peer_get_name(self._ptr, conferenceNumber, offlinePeerNumber, _as_pointer(name, ctypes.POINTER(ctypes.c_uint8)))
+        self._raise_pending()
+        return result
+
+    def file_get_file_id(self, friendNumber, fileNumber, fileID):
+        """tox_file_get_file_id gets the file ID for a file transfer.
+        file_id: Buffer to write the 32-byte file ID to.
+        Returns: 1 on success, 0 on error.
+        """
+        self._check_open()
+        result = _lib.tox_file_get_file_id(self._ptr, friendNumber, fileNumber, _as_pointer(fileID, ctypes.POINTER(ctypes.c_uint8)))
+        self._raise_pending()
+        return result
+
+    def self_get_safety_number(self, peerPublicKey, out, outLen):
+        """tox_self_get_safety_number derives the 60-digit safety number for this Tox
+        instance and a peer public key.
+
+        peerPublicKey must point to a 32-byte key.
+        out may be nil to query required size.
+        Returns the string length (without null terminator) on success, 0 on error.
+        """
+        self._check_open()
+        result = _lib.tox_self_get_safety_number(self._ptr, _as_pointer(peerPublicKey, ctypes.POINTER(ctypes.c_uint8)), _as_pointer(out, ctypes.POINTER(ctypes.c_uint8)), outLen)
+        self._raise_pending()
+        return result
+
+
+class ToxAV(_Handle):
+    """A ToxAV instance sharing the handle of an existing Tox instance."""
+
+    def __init__(self, tox):
+        super().__init__()
+        self.tox = tox
+        err = ctypes.c_int(0)
+        self._ptr = _lib.toxav_new(tox._ptr, ctypes.byref(err))
+        if not self._ptr:
+            raise ToxError("toxav_new", err.value, _ENUM_NAMES["TOX_AV_ERR_NEW"].get(err.value))
+
+    def kill(self):
+        """Releases the instance. Safe to call more than once."""
+        if self._ptr:
+            _lib.toxav_kill(self._ptr)
+            self._ptr = None
+            self._callbacks.clear()
+
+
+    def get_tox_from_av(self):
+        """toxav_get_tox_from_av returns the Tox instance associated with ToxAV.
+
+        This function matches the libtoxcore toxav_get_tox_from_av API exactly.
+        """
+        self._check_open()
+        result = _lib.toxav_get_tox_from_av(self._ptr)
+        self._raise_pending()
+        return result
+
+    def iteration_interval(self):
+        """toxav_iteration_interval returns the iteration interval for ToxAV.
+
+        This function matches the libtoxcore toxav_iteration_interval API exactly.
+        """
+        self._check_open()
+        result = _lib.toxav_iteration_interval(self._ptr)
+        self._raise_pending()
+        return result
+
+    def iterate(self):
+        """toxav_iterate performs one iteration of the ToxAV event loop.
+
+        This function matches the libtoxcore toxav_iterate API exactly.
+        """
+        self._check_open()
+        result = _lib.toxav_iterate(self._ptr)
+        self._raise_pending()
+        return result
+
+    def call(self, friend_number, audio_bit_rate, video_bit_rate):
+        """toxav_call initiates an audio/video call.
+
+        This function matches the libtoxcore toxav_call API exactly.
+        """
+        self._check_open()
+        _err = ctypes.c_int(0)
+        result = _lib.toxav_call(self._ptr, friend_number, audio_bit_rate, video_bit_rate, ctypes.byref(_err))
+        self._raise_pending()
+        _check_error('toxav_call', _err.value, 'TOX_AV_ERR_CALL')
+        return result
+
+    def answer(self, friend_number, audio_bit_rate, video_bit_rate):
+        """toxav_answer accepts an incoming audio/video call.
+
+        This function matches the libtoxcore toxav_answer API exactly.
+        """
+        self._check_open()
+        _err = ctypes.c_int(0)
+        result = _lib.toxav_answer(self._ptr, friend_number, audio_bit_rate, video_bit_rate, ctypes.byref(_err))
+        self._raise_pending()
+        _check_error('toxav_answer', _err.value, 'TOX_AV_ERR_ANSWER')
+        return result
+
+    def call_control(self, friend_number, control):
+        """toxav_call_control sends a call control command.
+
+        This function matches the libtoxcore toxav_call_control API exactly.
+        """
+        self._check_open()
+        _err = ctypes.c_int(0)
+        result = _lib.toxav_call_control(self._ptr, friend_number, control, ctypes.byref(_err))
+        self._raise_pending()
+        _check_error('toxav_call_control', _err.value, 'TOX_AV_ERR_CALL_CONTROL')
+        return result
+
+    def audio_set_bit_rate(self, friend_number, bit_rate):
+        """toxav_audio_set_bit_rate sets the audio bit rate for a call.
+
+        This function matches the libtoxcore toxav_audio_set_bit_rate API exactly.
+        """
+        self._check_open()
+        _err = ctypes.c_int(0)
+        result = _lib.toxav_audio_set_bit_rate(self._ptr, friend_number, bit_rate, ctypes.byref(_err))
+        self._raise_pending()
+        _check_error('toxav_audio_set_bit_rate', _err.value, 'TOX_AV_ERR_BIT_RATE_SET')
+        return result
+
+    def video_set_bit_rate(self, friend_number, bit_rate):
+        """toxav_video_set_bit_rate sets the video bit rate for a call.
+
+        This function matches the libtoxcore toxav_video_set_bit_rate API exactly.
+        """
+        self._check_open()
+        _err = ctypes.c_int(0)
+        result = _lib.toxav_video_set_bit_rate(self._ptr, friend_number, bit_rate, ctypes.byref(_err))
+        self._raise_pending()
+        _check_error('toxav_video_set_bit_rate', _err.value, 'TOX_AV_ERR_BIT_RATE_SET')
+        return result
+
+    def audio_send_frame(self, friend_number, pcm, sample_count, channels, sampling_rate):
+        """toxav_audio_send_frame sends an audio frame.
+
+        This function matches the libtoxcore toxav_audio_send_frame API exactly.
+        """
+        self._check_open()
+        _err = ctypes.c_int(0)
+        result = _lib.toxav_audio_send_frame(self._ptr, friend_number, _as_pointer(pcm, ctypes.POINTER(ctypes.c_int16)), sample_count, channels, sampling_rate, ctypes.byref(_err))
+        self._raise_pending()
+        _check_error('toxav_audio_send_frame', _err.value, 'TOX_AV_ERR_SEND_FRAME')
+        return result
+
+    def video_send_frame(self, friend_number, width, height, y, u, v):
+        """toxav_video_send_frame sends a video frame.
+
+        This function matches the libtoxcore toxav_video_send_frame API exactly.
+        """
+        self._check_open()
+        _err = ctypes.c_int(0)
+        result = _lib.toxav_video_send_frame(self._ptr, friend_number, width, height, _as_pointer(y, ctypes.POINTER(ctypes.c_uint8)), _as_pointer(u, ctypes.POINTER(ctypes.c_uint8)), _as_pointer(v, ctypes.POINTER(ctypes.c_uint8)), ctypes.byref(_err))
+        self._raise_pending()
+        _check_error('toxav_video_send_frame', _err.value, 'TOX_AV_ERR_SEND_FRAME')
+        return result
+
+    def callback_call(self, callback):
+        """The callable receives the C callback arguments without the instance
+        handle and user data. Pointer/length pairs are passed as bytes.
+        Exceptions raised by the callable are re-raised from the next
+        method call on this instance.
+        """
+        self._check_open()
+        cfunc = self._wrap_callback('toxav_call_cb', toxav_call_cb, callback)
+        _lib.toxav_callback_call(self._ptr, cfunc, None)
+
+    def callback_call_state(self, callback):
+        """The callable receives the C callback arguments without the instance
+        handle and user data. Pointer/length pairs are passed as bytes.
+        Exceptions raised by the callable are re-raised from the next
+        method call on this instance.
+        """
+        self._check_open()
+        cfunc = self._wrap_callback('toxav_call_state_cb', toxav_call_state_cb, callback)
+        _lib.toxav_callback_call_state(self._ptr, cfunc, None)
+
+    def callback_audio_bit_rate(self, callback):
+        """The callable receives the C callback arguments without the instance
+        handle and user data. Pointer/length pairs are passed as bytes.
+        Exceptions raised by the callable are re-raised from the next
+        method call on this instance.
+        """
+        self._check_open()
+        cfunc = self._wrap_callback('toxav_audio_bit_rate_cb', toxav_audio_bit_rate_cb, callback)
+        _lib.toxav_callback_audio_bit_rate(self._ptr, cfunc, None)
+
+    def callback_video_bit_rate(self, callback):
+        """The callable receives the C callback arguments without the instance
+        handle and user data. Pointer/length pairs are passed as bytes.
+        Exceptions raised by the callable are re-raised from the next
+        method call on this instance.
+        """
+        self._check_open()
+        cfunc = self._wrap_callback('toxav_video_bit_rate_cb', toxav_video_bit_rate_cb, callback)
+        _lib.toxav_callback_video_bit_rate(self._ptr, cfunc, None)
+
+    def callback_audio_receive_frame(self, callback):
+        """toxav_callback_audio_receive_frame registers the C audio frame callback.
+
+        IMPORTANT: The pcm pointer passed to callback points into Go-managed memory
+        and is only valid for the duration of each callback invocation. C callers
+        MUST copy the audio data before returning if it needs to be retained.
+
+        The callable receives the C callback arguments without the instance
+        handle and user data. Pointer/length pairs are passed as bytes.
+        Exceptions raised by the callable are re-raised from the next
+        method call on this instance.
+        """
+        self._check_open()
+        cfunc = self._wrap_callback('toxav_audio_receive_frame_cb', toxav_audio_receive_frame_cb, callback)
+        _lib.toxav_callback_audio_receive_frame(self._ptr, cfunc, None)
+
+    def callback_video_receive_frame(self, callback):
+        """toxav_callback_video_receive_frame registers the C video frame callback.
+
+        IMPORTANT: The y/u/v pointers passed to callback point into Go-managed
+        memory and are only valid for the duration of each callback invocation. C
+        callers MUST copy frame data before returning if it needs to be retained.
+
+        The callable receives the C callback arguments without the instance
+        handle and user data. Pointer/length pairs are passed as bytes.
+        Exceptions raised by the callable are re-raised from the next
+        method call on this instance.
+        """
+        self._check_open()
+        cfunc = self._wrap_callback('toxav_video_receive_frame_cb', toxav_video_receive_frame_cb, callback)
+        _lib.toxav_callback_video_receive_frame(self._ptr, cfunc, None)
+
+    def start_recording(self, friend_number, path, format):
+        """toxav_start_recording starts writing media received from a friend to a file.
+
+        This is a toxcore-go extension; libtoxcore has no recording API.
+        """
+        self._check_open()
+        _err = ctypes.c_int(0)
+        result = _lib.toxav_start_recording(self._ptr, friend_number, _as_cstring(path), format, ctypes.byref(_err))
+        self._raise_pending()
+        _check_error('toxav_start_recording', _err.value, 'TOX_AV_ERR_RECORDING')
+        return result
+
+    def stop_recording(self, friend_number):
+        """toxav_stop_recording stops a recording and finalizes its file.
+
+        This is a toxcore-go extension; libtoxcore has no recording API.
+        """
+        self._check_open()
+        _err = ctypes.c_int(0)
+        result = _lib.toxav_stop_recording(self._ptr, friend_number, ctypes.byref(_err))
+        self._raise_pending()
+        _check_error('toxav_stop_recording', _err.value, 'TOX_AV_ERR_RECORDING')
+        return result
+
+    def start_screen_share(self, friend_number):
+        """toxav_start_screen_share marks a call's video stream as screen content.
+
+        This is a toxcore-go extension; libtoxcore has no screen share API.
+        """
+        self._check_open()
+        _err = ctypes.c_int(0)
+        result = _lib.toxav_start_screen_share(self._ptr, friend_number, ctypes.byref(_err))
+        self._raise_pending()
+        _check_error('toxav_start_screen_share', _err.value, 'TOX_AV_ERR_SCREEN_SHARE')
+        return result
+
+    def stop_screen_share(self, friend_number):
+        """toxav_stop_screen_share ends screen sharing with a friend.
+
+        This is a toxcore-go extension; libtoxcore has no screen share API.
+        """
+        self._check_open()
+        _err = ctypes.c_int(0)
+        result = _lib.toxav_stop_screen_share(self._ptr, friend_number, ctypes.byref(_err))
+        self._raise_pending()
+        _check_error('toxav_stop_screen_share', _err.value, 'TOX_AV_ERR_SCREEN_SHARE')
+        return result
+
+    def callback_recording_stats(self, callback):
+        """toxav_callback_recording_stats sets the callback reporting bytes written
+        to a recording.
+
+        This is a toxcore-go extension; libtoxcore has no recording API.
+
+        The callable receives the C callback arguments without the instance
+        handle and user data. Pointer/length pairs are passed as bytes.
+        Exceptions raised by the callable are re-raised from the next
+        method call on this instance.
+        """
+        self._check_open()
+        cfunc = self._wrap_callback('toxav_recording_stats_cb', toxav_recording_stats_cb, callback)
+        _lib.toxav_callback_recording_stats(self._ptr, cfunc, None)
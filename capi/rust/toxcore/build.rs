@@ -0,0 +1,81 @@
+//! Builds or locates libtoxcore.so and generates FFI bindings from the
+//! cgo-generated libtoxcore.h.
+//!
+//! Set `TOXCORE_LIB_DIR` to a directory containing a prebuilt
+//! `libtoxcore.so` and `libtoxcore.h`. Otherwise, when the crate is built
+//! inside the toxcore-go repository, the library is compiled with
+//! `go build -buildmode=c-shared` into `OUT_DIR`.
+
+use std::env;
+use std::path::{Path, PathBuf};
+use std::process::Command;
+
+fn main() {
+    println!("cargo:rerun-if-env-changed=TOXCORE_LIB_DIR");
+
+    let out_dir = PathBuf::from(env::var("OUT_DIR").expect("OUT_DIR not set"));
+    let lib_dir = match env::var("TOXCORE_LIB_DIR") {
+        Ok(dir) => PathBuf::from(dir),
+        Err(_) => build_go_library(&out_dir),
+    };
+
+    let header = lib_dir.join("libtoxcore.h");
+    if !header.exists() {
+        panic!(
+            "libtoxcore.h not found in {}; set TOXCORE_LIB_DIR to a directory \
+             produced by `go build -buildmode=c-shared -o libtoxcore.so ./capi`",
+            lib_dir.display()
+        );
+    }
+
+    bindgen::Builder::default()
+        .header(header.to_string_lossy())
+        .allowlist_function("tox_.*")
+        .allowlist_function("toxav_.*")
+        .allowlist_function("toxcore_.*")
+        .allowlist_type("TOX_.*")
+        .allowlist_var("TOX_.*")
+        .parse_callbacks(Box::new(bindgen::CargoCallbacks::new()))
+        .generate()
+        .expect("failed to generate libtoxcore bindings")
+        .write_to_file(out_dir.join("bindings.rs"))
+        .expect("failed to write libtoxcore bindings");
+
+    println!("cargo:rustc-link-search=native={}", lib_dir.display());
+    println!("cargo:rustc-link-lib=dylib=toxcore");
+    // Let examples and tests find the shared library without LD_LIBRARY_PATH
+    println!("cargo:rustc-link-arg=-Wl,-rpath,{}", lib_dir.display());
+}
+
+/// Compiles the Go C API from the enclosing repository into `out_dir`.
+fn build_go_library(out_dir: &Path) -> PathBuf {
+    let manifest_dir = PathBuf::from(env::var("CARGO_MANIFEST_DIR").expect("CARGO_MANIFEST_DIR not set"));
+    // capi/rust/toxcore -> repository root
+    let repo_root = manifest_dir
+        .ancestors()
+        .nth(3)
+        .expect("crate is not inside the toxcore-go repository")
+        .to_path_buf();
+    if !repo_root.join("go.mod").exists() {
+        panic!(
+            "toxcore-go sources not found at {}; set TOXCORE_LIB_DIR to a prebuilt libtoxcore",
+            repo_root.display()
+        );
+    }
+
+    let capi_dir = repo_root.join("capi");
+    println!("cargo:rerun-if-changed={}", capi_dir.display());
+
+    let status = Command::new("go")
+        .current_dir(&repo_root)
+        .env("CGO_ENABLED", "1")
+        .args(["build", "-buildmode=c-shared", "-o"])
+        .arg(out_dir.join("libtoxcore.so"))
+        .arg("./capi")
+        .status()
+        .expect("failed to run `go build`; is Go installed?");
+    if !status.success() {
+        panic!("`go build -buildmode=c-shared ./capi` failed with {status}");
+    }
+    out_dir.to_path_buf()
+}
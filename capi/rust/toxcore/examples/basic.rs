@@ -0,0 +1,34 @@
+//! Creates a Tox instance, bootstraps from the default nodes and prints the
+//! Tox address.
+//!
+//! Run with `cargo run --example basic`.
+
+use std::thread;
+
+use toxcore::{ToxError, ToxInstance};
+
+fn main() -> Result<(), ToxError> {
+    let mut tox = ToxInstance::new()?;
+    tox.set_name("toxcore-rs example")?;
+
+    tox.on_friend_request(|pk, message| {
+        let pk: String = pk.iter().map(|b| format!("{b:02X}")).collect();
+        println!("Friend request from {pk}: {message}");
+    });
+    tox.on_friend_message(|friend, message| {
+        println!("Message from friend {friend}: {message}");
+    });
+
+    if let Err(err) = tox.bootstrap_defaults() {
+        // Bootstrapping needs network access; the address is still valid offline.
+        eprintln!("Bootstrap failed: {err}");
+    }
+
+    println!("Tox address: {}", tox.address_hex()?);
+
+    for _ in 0..10 {
+        tox.iterate();
+        thread::sleep(tox.iteration_interval());
+    }
+    Ok(())
+}
@@ -0,0 +1,345 @@
+//! Safe Rust bindings for the toxcore-go C API.
+//!
+//! [`ToxInstance`] owns a Tox handle created by `tox_new` and releases it
+//! with `tox_kill` when dropped. Callbacks are registered with closures;
+//! they may be invoked from toxcore-go's internal threads, so they must be
+//! `Send`.
+//!
+//! ```no_run
+//! let mut tox = toxcore::ToxInstance::new()?;
+//! tox.bootstrap_defaults()?;
+//! println!("{}", tox.address_hex()?);
+//! # Ok::<(), toxcore::ToxError>(())
+//! ```
+
+use std::ffi::c_void;
+use std::fmt;
+use std::sync::Mutex;
+use std::time::Duration;
+
+/// Raw bindings generated by bindgen from libtoxcore.h.
+#[allow(non_upper_case_globals, non_camel_case_types, non_snake_case, dead_code)]
+pub mod sys {
+    include!(concat!(env!("OUT_DIR"), "/bindings.rs"));
+}
+
+/// Size of a binary Tox address: public key, nospam and checksum.
+pub const ADDRESS_SIZE: usize = 38;
+/// Size of a Tox public key.
+pub const PUBLIC_KEY_SIZE: usize = 32;
+
+const FRIEND_NUMBER_INVALID: u32 = u32::MAX;
+
+/// Errors returned by the safe wrapper.
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub enum ToxError {
+    /// `tox_new` failed to create an instance.
+    NewFailed,
+    /// Bootstrapping from the default node list failed.
+    BootstrapFailed,
+    /// The instance address or public key could not be read.
+    AddressUnavailable,
+    /// Adding a friend failed.
+    FriendAddFailed,
+    /// Sending a message failed.
+    SendFailed,
+    /// Setting a profile field failed.
+    SetInfoFailed,
+}
+
+impl fmt::Display for ToxError {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        let msg = match self {
+            ToxError::NewFailed => "failed to create Tox instance",
+            ToxError::BootstrapFailed => "failed to bootstrap",
+            ToxError::AddressUnavailable => "Tox address unavailable",
+            ToxError::FriendAddFailed => "failed to add friend",
+            ToxError::SendFailed => "failed to send message",
+            ToxError::SetInfoFailed => "failed to set profile information",
+        };
+        f.write_str(msg)
+    }
+}
+
+impl std::error::Error for ToxError {}
+
+/// Message kind for [`ToxInstance::send_message`].
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub enum MessageType {
+    /// A normal text message.
+    Normal,
+    /// An action ("/me") message.
+    Action,
+}
+
+/// Friend connection status reported by the connection status callback.
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub enum ConnectionStatus {
+    /// The friend is offline.
+    None,
+    /// The friend is reachable over TCP.
+    Tcp,
+    /// The friend is reachable over UDP.
+    Udp,
+}
+
+impl From<u8> for ConnectionStatus {
+    fn from(v: u8) -> Self {
+        match v {
+            1 => ConnectionStatus::Tcp,
+            2 => ConnectionStatus::Udp,
+            _ => ConnectionStatus::None,
+        }
+    }
+}
+
+type FriendRequestFn = Box<dyn FnMut([u8; PUBLIC_KEY_SIZE], &str) + Send>;
+type FriendMessageFn = Box<dyn FnMut(u32, &str) + Send>;
+type ConnectionStatusFn = Box<dyn FnMut(u32, ConnectionStatus) + Send>;
+
+/// Closures registered on an instance. Boxed so its address can be passed
+/// to C as user data and stays stable for the lifetime of the instance.
+#[derive(Default)]
+struct Callbacks {
+    friend_request: Mutex<Option<FriendRequestFn>>,
+    friend_message: Mutex<Option<FriendMessageFn>>,
+    connection_status: Mutex<Option<ConnectionStatusFn>>,
+}
+
+/// An owned toxcore-go instance.
+pub struct ToxInstance {
+    ptr: *mut c_void,
+    callbacks: Box<Callbacks>,
+}
+
+// The C API serializes access internally, so the handle may move between threads.
+unsafe impl Send for ToxInstance {}
+
+impl ToxInstance {
+    /// Creates a new instance with default options.
+    pub fn new() -> Result<Self, ToxError> {
+        let ptr = unsafe { sys::tox_new() };
+        if ptr.is_null() {
+            return Err(ToxError::NewFailed);
+        }
+        Ok(ToxInstance {
+            ptr,
+            callbacks: Box::default(),
+        })
+    }
+
+    /// Bootstraps from the built-in default node list.
+    pub fn bootstrap_defaults(&mut self) -> Result<(), ToxError> {
+        if unsafe { sys::tox_bootstrap_simple(self.ptr) } != 0 {
+            return Err(ToxError::BootstrapFailed);
+        }
+        Ok(())
+    }
+
+    /// Runs one iteration of the event loop.
+    pub fn iterate(&mut self) {
+        unsafe { sys::tox_iterate(self.ptr) }
+    }
+
+    /// Returns the recommended delay between calls to [`iterate`](Self::iterate).
+    pub fn iteration_interval(&self) -> Duration {
+        let ms = unsafe { sys::tox_iteration_interval(self.ptr) };
+        Duration::from_millis(ms.max(0) as u64)
+    }
+
+    /// Returns the binary Tox address of this instance.
+    pub fn address(&self) -> Result<[u8; ADDRESS_SIZE], ToxError> {
+        let mut buf = [0u8; ADDRESS_SIZE];
+        if unsafe { sys::tox_self_get_address(self.ptr, buf.as_mut_ptr()) } != 0 {
+            return Err(ToxError::AddressUnavailable);
+        }
+        Ok(buf)
+    }
+
+    /// Returns the Tox address as an uppercase hex string.
+    pub fn address_hex(&self) -> Result<String, ToxError> {
+        Ok(self.address()?.iter().map(|b| format!("{b:02X}")).collect())
+    }
+
+    /// Returns the long-term public key of this instance.
+    pub fn public_key(&self) -> Result<[u8; PUBLIC_KEY_SIZE], ToxError> {
+        let mut buf = [0u8; PUBLIC_KEY_SIZE];
+        if unsafe { sys::tox_self_get_public_key(self.ptr, buf.as_mut_ptr()) } != 0 {
+            return Err(ToxError::AddressUnavailable);
+        }
+        Ok(buf)
+    }
+
+    /// Sets the display name.
+    pub fn set_name(&mut self, name: &str) -> Result<(), ToxError> {
+        let mut bytes = name.as_bytes().to_vec();
+        let rc = unsafe { sys::tox_self_set_name(self.ptr, bytes.as_mut_ptr(), bytes.len() as _) };
+        if rc != 0 {
+            return Err(ToxError::SetInfoFailed);
+        }
+        Ok(())
+    }
+
+    /// Sends a friend request and returns the new friend number.
+    pub fn friend_add(&mut self, address: &[u8; ADDRESS_SIZE], message: &str) -> Result<u32, ToxError> {
+        let mut addr = *address;
+        let mut msg = message.as_bytes().to_vec();
+        let n = unsafe { sys::tox_friend_add(self.ptr, addr.as_mut_ptr(), msg.as_mut_ptr(), msg.len() as _) };
+        if n == FRIEND_NUMBER_INVALID {
+            return Err(ToxError::FriendAddFailed);
+        }
+        Ok(n)
+    }
+
+    /// Adds a friend by public key without sending a request, typically to
+    /// accept an incoming request.
+    pub fn friend_add_norequest(&mut self, public_key: &[u8; PUBLIC_KEY_SIZE]) -> Result<u32, ToxError> {
+        let mut pk = *public_key;
+        let n = unsafe { sys::tox_friend_add_norequest(self.ptr, pk.as_mut_ptr()) };
+        if n == FRIEND_NUMBER_INVALID {
+            return Err(ToxError::FriendAddFailed);
+        }
+        Ok(n)
+    }
+
+    /// Sends a message to a friend and returns its message ID.
+    pub fn send_message(&mut self, friend_number: u32, kind: MessageType, message: &str) -> Result<u32, ToxError> {
+        let mut msg = message.as_bytes().to_vec();
+        let kind = match kind {
+            MessageType::Normal => 0,
+            MessageType::Action => 1,
+        };
+        let id = unsafe {
+            sys::tox_friend_send_message(self.ptr, friend_number, kind, msg.as_mut_ptr(), msg.len() as _)
+        };
+        if id == 0 {
+            return Err(ToxError::SendFailed);
+        }
+        Ok(id)
+    }
+
+    /// Registers the friend request callback.
+    pub fn on_friend_request<F>(&mut self, f: F)
+    where
+        F: FnMut([u8; PUBLIC_KEY_SIZE], &str) + Send + 'static,
+    {
+        *self.callbacks.friend_request.lock().unwrap() = Some(Box::new(f));
+        let cb: unsafe extern "C" fn(*mut c_void, *const u8, *const u8, usize, *mut c_void) = friend_request_trampoline;
+        unsafe { sys::tox_callback_friend_request(self.ptr, cb as *mut c_void, self.user_data()) }
+    }
+
+    /// Registers the friend message callback.
+    pub fn on_friend_message<F>(&mut self, f: F)
+    where
+        F: FnMut(u32, &str) + Send + 'static,
+    {
+        *self.callbacks.friend_message.lock().unwrap() = Some(Box::new(f));
+        let cb: unsafe extern "C" fn(*mut c_void, u32, *const u8, usize, *mut c_void) = friend_message_trampoline;
+        unsafe { sys::tox_callback_friend_message(self.ptr, cb as *mut c_void, self.user_data()) }
+    }
+
+    /// Registers the friend connection status callback.
+    pub fn on_friend_connection_status<F>(&mut self, f: F)
+    where
+        F: FnMut(u32, ConnectionStatus) + Send + 'static,
+    {
+        *self.callbacks.connection_status.lock().unwrap() = Some(Box::new(f));
+        let cb: unsafe extern "C" fn(*mut c_void, u32, u8, *mut c_void) = connection_status_trampoline;
+        unsafe { sys::tox_callback_friend_connection_status(self.ptr, cb as *mut c_void, self.user_data()) }
+    }
+
+    /// Returns the raw handle for use with the [`sys`] bindings.
+    pub fn as_ptr(&self) -> *mut c_void {
+        self.ptr
+    }
+
+    fn user_data(&self) -> *mut c_void {
+        &*self.callbacks as *const Callbacks as *mut c_void
+    }
+}
+
+impl Drop for ToxInstance {
+    fn drop(&mut self) {
+        // tox_kill stops all callbacks before the boxed closures are released.
+        unsafe { sys::tox_kill(self.ptr) }
+    }
+}
+
+/// Converts a C byte buffer to a string slice, replacing invalid UTF-8.
+unsafe fn message_from_raw<'a>(data: *const u8, len: usize) -> std::borrow::Cow<'a, str> {
+    if data.is_null() || len == 0 {
+        return std::borrow::Cow::Borrowed("");
+    }
+    String::from_utf8_lossy(std::slice::from_raw_parts(data, len))
+}
+
+unsafe extern "C" fn friend_request_trampoline(
+    _tox: *mut c_void,
+    public_key: *const u8,
+    message: *const u8,
+    length: usize,
+    user_data: *mut c_void,
+) {
+    if public_key.is_null() || user_data.is_null() {
+        return;
+    }
+    let callbacks = &*(user_data as *const Callbacks);
+    let mut pk = [0u8; PUBLIC_KEY_SIZE];
+    pk.copy_from_slice(std::slice::from_raw_parts(public_key, PUBLIC_KEY_SIZE));
+    let msg = message_from_raw(message, length);
+    if let Some(f) = callbacks.friend_request.lock().unwrap().as_mut() {
+        f(pk, &msg);
+    }
+}
+
+unsafe extern "C" fn friend_message_trampoline(
+    _tox: *mut c_void,
+    friend_number: u32,
+    message: *const u8,
+    length: usize,
+    user_data: *mut c_void,
+) {
+    if user_data.is_null() {
+        return;
+    }
+    let callbacks = &*(user_data as *const Callbacks);
+    let msg = message_from_raw(message, length);
+    if let Some(f) = callbacks.friend_message.lock().unwrap().as_mut() {
+        f(friend_number, &msg);
+    }
+}
+
+unsafe extern "C" fn connection_status_trampoline(
+    _tox: *mut c_void,
+    friend_number: u32,
+    status: u8,
+    user_data: *mut c_void,
+) {
+    if user_data.is_null() {
+        return;
+    }
+    let callbacks = &*(user_data as *const Callbacks);
+    if let Some(f) = callbacks.connection_status.lock().unwrap().as_mut() {
+        f(friend_number, ConnectionStatus::from(status));
+    }
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn new_instance_has_address() {
+        let tox = ToxInstance::new().expect("tox_new failed");
+        let addr = tox.address().expect("address");
+        let pk = tox.public_key().expect("public key");
+        assert_eq!(&addr[..PUBLIC_KEY_SIZE], &pk[..]);
+        assert_eq!(tox.address_hex().unwrap().len(), ADDRESS_SIZE * 2);
+    }
+
+    #[test]
+    fn connection_status_conversion() {
+        assert_eq!(ConnectionStatus::from(0), ConnectionStatus::None);
+        assert_eq!(ConnectionStatus::from(2), ConnectionStatus::Udp);
+    }
+}
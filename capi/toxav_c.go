@@ -101,6 +101,35 @@ typedef enum TOX_AV_ERR_SEND_FRAME {
     TOX_AV_ERR_SEND_FRAME_RTP_FAILED = 7,
 } TOX_AV_ERR_SEND_FRAME;
 
+// Recording and screen share extensions (not part of libtoxcore)
+typedef enum TOX_AV_RECORDING_FORMAT {
+    TOX_AV_RECORDING_FORMAT_WAV = 0,
+    TOX_AV_RECORDING_FORMAT_RAW = 1,
+} TOX_AV_RECORDING_FORMAT;
+
+typedef enum TOX_AV_ERR_RECORDING {
+    TOX_AV_ERR_RECORDING_OK = 0,
+    TOX_AV_ERR_RECORDING_NULL = 1,
+    TOX_AV_ERR_RECORDING_SYNC = 2,
+    TOX_AV_ERR_RECORDING_FRIEND_NOT_FOUND = 3,
+    TOX_AV_ERR_RECORDING_FRIEND_NOT_IN_CALL = 4,
+    TOX_AV_ERR_RECORDING_ALREADY_ACTIVE = 5,
+    TOX_AV_ERR_RECORDING_NOT_ACTIVE = 6,
+    TOX_AV_ERR_RECORDING_INVALID_FORMAT = 7,
+    TOX_AV_ERR_RECORDING_IO = 8,
+} TOX_AV_ERR_RECORDING;
+
+typedef enum TOX_AV_ERR_SCREEN_SHARE {
+    TOX_AV_ERR_SCREEN_SHARE_OK = 0,
+    TOX_AV_ERR_SCREEN_SHARE_SYNC = 1,
+    TOX_AV_ERR_SCREEN_SHARE_FRIEND_NOT_FOUND = 2,
+    TOX_AV_ERR_SCREEN_SHARE_FRIEND_NOT_IN_CALL = 3,
+    TOX_AV_ERR_SCREEN_SHARE_VIDEO_DISABLED = 4,
+    TOX_AV_ERR_SCREEN_SHARE_ALREADY_ACTIVE = 5,
+    TOX_AV_ERR_SCREEN_SHARE_NOT_ACTIVE = 6,
+    TOX_AV_ERR_SCREEN_SHARE_NOT_SUPPORTED = 7,
+} TOX_AV_ERR_SCREEN_SHARE;
+
 // Callback function types matching libtoxcore exactly
 typedef void (*toxav_call_cb)(ToxAV *av, uint32_t friend_number, bool audio_enabled, bool video_enabled, void *user_data);
 typedef void (*toxav_call_state_cb)(ToxAV *av, uint32_t friend_number, uint32_t state, void *user_data);
@@ -108,6 +137,7 @@ typedef void (*toxav_audio_bit_rate_cb)(ToxAV *av, uint32_t friend_number, uint3
 typedef void (*toxav_video_bit_rate_cb)(ToxAV *av, uint32_t friend_number, uint32_t video_bit_rate, void *user_data);
 typedef void (*toxav_audio_receive_frame_cb)(ToxAV *av, uint32_t friend_number, const int16_t *pcm, size_t sample_count, uint8_t channels, uint32_t sampling_rate, void *user_data);
 typedef void (*toxav_video_receive_frame_cb)(ToxAV *av, uint32_t friend_number, uint16_t width, uint16_t height, const uint8_t *y, const uint8_t *u, const uint8_t *v, int32_t ystride, int32_t ustride, int32_t vstride, void *user_data);
+typedef void (*toxav_recording_stats_cb)(ToxAV *av, uint32_t friend_number, uint64_t bytes_written, void *user_data);
 
 // Bridge functions to invoke C callbacks from Go
 // These are necessary because Go cannot directly call C function pointers
@@ -147,17 +177,25 @@ static inline void invoke_video_receive_frame_cb(toxav_video_receive_frame_cb cb
         cb(av, friend_number, width, height, y, u, v, ystride, ustride, vstride, user_data);
     }
 }
+
+static inline void invoke_recording_stats_cb(toxav_recording_stats_cb cb, ToxAV *av, uint32_t friend_number, uint64_t bytes_written, void *user_data) {
+    if (cb != NULL) {
+        cb(av, friend_number, bytes_written, user_data);
+    }
+}
 */
 import "C"
 
 import (
 	"errors"
 	"fmt"
+	"os"
 	"sync"
 	"unsafe"
 
 	"github.com/opd-ai/toxcore"
 	avpkg "github.com/opd-ai/toxcore/av"
+	"github.com/opd-ai/toxcore/av/video"
 	"github.com/sirupsen/logrus"
 )
 
@@ -344,6 +382,7 @@ var toxavRegistry = NewToxAVRegistry()
 //   - videoBitRateCb/videoBitRateUserData: Video bitrate suggestion callback
 //   - audioReceiveFrameCb/audioReceiveUserData: Audio frame reception callback
 //   - videoReceiveFrameCb/videoReceiveUserData: Video frame reception callback
+//   - recordingStatsCb/recordingStatsUserData: Recording progress callback
 type toxavCallbacks struct {
 	mu                   sync.RWMutex
 	callCb               C.toxav_call_cb
@@ -358,6 +397,9 @@ type toxavCallbacks struct {
 	audioReceiveUserData unsafe.Pointer
 	videoReceiveFrameCb  C.toxav_video_receive_frame_cb
 	videoReceiveUserData unsafe.Pointer
+	// Recording progress callback
+	recordingStatsCb       C.toxav_recording_stats_cb
+	recordingStatsUserData unsafe.Pointer
 }
 
 // getToxAVID safely extracts the toxavID from an opaque pointer handle.
@@ -1075,9 +1117,9 @@ func toxav_callback_call(av unsafe.Pointer, callback C.toxav_call_cb, user_data
 		callbacks.mu.Unlock()
 	}, func(toxavInstance *toxcore.ToxAV, toxavID uintptr) {
 		toxavInstance.CallbackCall(func(friendNumber uint32, audioEnabled, videoEnabled bool) {
-			callbacks, cbExists := toxavRegistry.GetCallbacks(toxavID)
-			handle, handleExists := toxavRegistry.GetHandle(toxavID)
-			if !cbExists || !handleExists {
+			defer recoverToxAVCallback(toxavID, "toxav_call_cb")
+			callbacks, handle, ok := resolveToxAVCallback(toxavID, "toxav_call_cb")
+			if !ok {
 				return
 			}
 			callbacks.mu.RLock()
@@ -1099,9 +1141,9 @@ func toxav_callback_call_state(av unsafe.Pointer, callback C.toxav_call_state_cb
 		callbacks.mu.Unlock()
 	}, func(toxavInstance *toxcore.ToxAV, toxavID uintptr) {
 		toxavInstance.CallbackCallState(func(friendNumber uint32, state avpkg.CallState) {
-			callbacks, cbExists := toxavRegistry.GetCallbacks(toxavID)
-			handle, handleExists := toxavRegistry.GetHandle(toxavID)
-			if !cbExists || !handleExists {
+			defer recoverToxAVCallback(toxavID, "toxav_call_state_cb")
+			callbacks, handle, ok := resolveToxAVCallback(toxavID, "toxav_call_state_cb")
+			if !ok {
 				return
 			}
 			callbacks.mu.RLock()
@@ -1123,9 +1165,9 @@ func toxav_callback_audio_bit_rate(av unsafe.Pointer, callback C.toxav_audio_bit
 		callbacks.mu.Unlock()
 	}, func(toxavInstance *toxcore.ToxAV, toxavID uintptr) {
 		toxavInstance.CallbackAudioBitRate(func(friendNumber, bitRate uint32) {
-			callbacks, cbExists := toxavRegistry.GetCallbacks(toxavID)
-			handle, handleExists := toxavRegistry.GetHandle(toxavID)
-			if !cbExists || !handleExists {
+			defer recoverToxAVCallback(toxavID, "toxav_audio_bit_rate_cb")
+			callbacks, handle, ok := resolveToxAVCallback(toxavID, "toxav_audio_bit_rate_cb")
+			if !ok {
 				return
 			}
 			callbacks.mu.RLock()
@@ -1147,9 +1189,9 @@ func toxav_callback_video_bit_rate(av unsafe.Pointer, callback C.toxav_video_bit
 		callbacks.mu.Unlock()
 	}, func(toxavInstance *toxcore.ToxAV, toxavID uintptr) {
 		toxavInstance.CallbackVideoBitRate(func(friendNumber, bitRate uint32) {
-			callbacks, cbExists := toxavRegistry.GetCallbacks(toxavID)
-			handle, handleExists := toxavRegistry.GetHandle(toxavID)
-			if !cbExists || !handleExists {
+			defer recoverToxAVCallback(toxavID, "toxav_video_bit_rate_cb")
+			callbacks, handle, ok := resolveToxAVCallback(toxavID, "toxav_video_bit_rate_cb")
+			if !ok {
 				return
 			}
 			callbacks.mu.RLock()
@@ -1180,10 +1222,9 @@ func storeAudioReceiveCallback(toxavID uintptr, callback C.toxav_audio_receive_f
 // Retaining the pointer beyond the callback return leads to undefined behavior (the Go
 // GC may reclaim or relocate the underlying memory).
 func bridgeAudioReceiveFrame(capturedID uintptr, friendNumber uint32, pcm []int16, sampleCount int, channels uint8, samplingRate uint32) {
-	callbacks, cbExists := toxavRegistry.GetCallbacks(capturedID)
-	handle, handleExists := toxavRegistry.GetHandle(capturedID)
-
-	if !cbExists || !handleExists {
+	defer recoverToxAVCallback(capturedID, "toxav_audio_receive_frame_cb")
+	callbacks, handle, ok := resolveToxAVCallback(capturedID, "toxav_audio_receive_frame_cb")
+	if !ok {
 		return
 	}
 
@@ -1289,10 +1330,9 @@ func registerVideoFrameBridge(toxavID uintptr) {
 // to retain it. Retaining any pointer beyond the callback return leads to undefined
 // behavior (the Go GC may reclaim or relocate the underlying memory).
 func invokeVideoReceiveCallback(toxavID uintptr, friendNumber uint32, width, height uint16, y, u, v []byte, yStride, uStride, vStride int) {
-	callbacks, cbExists := toxavRegistry.GetCallbacks(toxavID)
-	handle, handleExists := toxavRegistry.GetHandle(toxavID)
-
-	if !cbExists || !handleExists {
+	defer recoverToxAVCallback(toxavID, "toxav_video_receive_frame_cb")
+	callbacks, handle, ok := resolveToxAVCallback(toxavID, "toxav_video_receive_frame_cb")
+	if !ok {
 		return
 	}
 
@@ -1336,6 +1376,182 @@ func convertFrameBuffersToPointers(y, u, v []byte) (*C.uint8_t, *C.uint8_t, *C.u
 	return yPtr, uPtr, vPtr
 }
 
+// mapRecordingError maps a Go error to the appropriate C recording error code.
+func mapRecordingError(err error, error_ptr *C.TOX_AV_ERR_RECORDING) {
+	if error_ptr == nil {
+		return
+	}
+	switch {
+	case errors.Is(err, toxcore.ErrFriendNotFound):
+		*error_ptr = C.TOX_AV_ERR_RECORDING_FRIEND_NOT_FOUND
+	case errors.Is(err, toxcore.ErrNoActiveCall):
+		*error_ptr = C.TOX_AV_ERR_RECORDING_FRIEND_NOT_IN_CALL
+	case errors.Is(err, toxcore.ErrRecordingActive):
+		*error_ptr = C.TOX_AV_ERR_RECORDING_ALREADY_ACTIVE
+	case errors.Is(err, toxcore.ErrNotRecording):
+		*error_ptr = C.TOX_AV_ERR_RECORDING_NOT_ACTIVE
+	case errors.Is(err, toxcore.ErrInvalidRecordingFormat):
+		*error_ptr = C.TOX_AV_ERR_RECORDING_INVALID_FORMAT
+	case errors.As(err, new(*os.PathError)):
+		*error_ptr = C.TOX_AV_ERR_RECORDING_IO
+	default:
+		*error_ptr = C.TOX_AV_ERR_RECORDING_SYNC
+	}
+}
+
+// mapScreenShareError maps a Go error to the appropriate C screen share error code.
+func mapScreenShareError(err error, error_ptr *C.TOX_AV_ERR_SCREEN_SHARE) {
+	if error_ptr == nil {
+		return
+	}
+	switch {
+	case errors.Is(err, toxcore.ErrFriendNotFound):
+		*error_ptr = C.TOX_AV_ERR_SCREEN_SHARE_FRIEND_NOT_FOUND
+	case errors.Is(err, toxcore.ErrNoActiveCall):
+		*error_ptr = C.TOX_AV_ERR_SCREEN_SHARE_FRIEND_NOT_IN_CALL
+	case errors.Is(err, toxcore.ErrVideoNotEnabled):
+		*error_ptr = C.TOX_AV_ERR_SCREEN_SHARE_VIDEO_DISABLED
+	case errors.Is(err, toxcore.ErrScreenShareActive):
+		*error_ptr = C.TOX_AV_ERR_SCREEN_SHARE_ALREADY_ACTIVE
+	case errors.Is(err, toxcore.ErrNotScreenSharing):
+		*error_ptr = C.TOX_AV_ERR_SCREEN_SHARE_NOT_ACTIVE
+	case errors.Is(err, video.ErrNotSupported):
+		*error_ptr = C.TOX_AV_ERR_SCREEN_SHARE_NOT_SUPPORTED
+	default:
+		*error_ptr = C.TOX_AV_ERR_SCREEN_SHARE_SYNC
+	}
+}
+
+// toxav_start_recording starts writing media received from a friend to a file.
+//
+// This is a toxcore-go extension; libtoxcore has no recording API.
+//
+//export toxav_start_recording
+func toxav_start_recording(av unsafe.Pointer, friend_number C.uint32_t, path *C.char, format C.TOX_AV_RECORDING_FORMAT, error_ptr *C.TOX_AV_ERR_RECORDING) C.bool {
+	if path == nil {
+		if error_ptr != nil {
+			*error_ptr = C.TOX_AV_ERR_RECORDING_NULL
+		}
+		return C.bool(false)
+	}
+	goPath := C.GoString(path)
+	return runToxAVOperation(
+		av,
+		error_ptr,
+		C.TOX_AV_ERR_RECORDING_OK,
+		C.TOX_AV_ERR_RECORDING_SYNC,
+		logrus.Fields{
+			"function":      "toxav_start_recording",
+			"friend_number": friend_number,
+			"path":          goPath,
+			"format":        format,
+		},
+		"Failed to start recording",
+		mapRecordingError,
+		func(toxavInstance *toxcore.ToxAV) error {
+			return toxavInstance.StartRecording(uint32(friend_number), goPath, toxcore.RecordingFormat(format))
+		},
+	)
+}
+
+// toxav_stop_recording stops a recording and finalizes its file.
+//
+// This is a toxcore-go extension; libtoxcore has no recording API.
+//
+//export toxav_stop_recording
+func toxav_stop_recording(av unsafe.Pointer, friend_number C.uint32_t, error_ptr *C.TOX_AV_ERR_RECORDING) C.bool {
+	return runToxAVOperation(
+		av,
+		error_ptr,
+		C.TOX_AV_ERR_RECORDING_OK,
+		C.TOX_AV_ERR_RECORDING_SYNC,
+		logrus.Fields{
+			"function":      "toxav_stop_recording",
+			"friend_number": friend_number,
+		},
+		"Failed to stop recording",
+		mapRecordingError,
+		func(toxavInstance *toxcore.ToxAV) error {
+			return toxavInstance.StopRecording(uint32(friend_number))
+		},
+	)
+}
+
+// toxav_start_screen_share captures the display and sends it as a call's video stream.
+//
+// This is a toxcore-go extension; libtoxcore has no screen share API.
+//
+//export toxav_start_screen_share
+func toxav_start_screen_share(av unsafe.Pointer, friend_number C.uint32_t, error_ptr *C.TOX_AV_ERR_SCREEN_SHARE) C.bool {
+	return runToxAVOperation(
+		av,
+		error_ptr,
+		C.TOX_AV_ERR_SCREEN_SHARE_OK,
+		C.TOX_AV_ERR_SCREEN_SHARE_SYNC,
+		logrus.Fields{
+			"function":      "toxav_start_screen_share",
+			"friend_number": friend_number,
+		},
+		"Failed to start screen share",
+		mapScreenShareError,
+		func(toxavInstance *toxcore.ToxAV) error {
+			return toxavInstance.StartScreenShare(uint32(friend_number))
+		},
+	)
+}
+
+// toxav_stop_screen_share ends screen sharing with a friend.
+//
+// This is a toxcore-go extension; libtoxcore has no screen share API.
+//
+//export toxav_stop_screen_share
+func toxav_stop_screen_share(av unsafe.Pointer, friend_number C.uint32_t, error_ptr *C.TOX_AV_ERR_SCREEN_SHARE) C.bool {
+	return runToxAVOperation(
+		av,
+		error_ptr,
+		C.TOX_AV_ERR_SCREEN_SHARE_OK,
+		C.TOX_AV_ERR_SCREEN_SHARE_SYNC,
+		logrus.Fields{
+			"function":      "toxav_stop_screen_share",
+			"friend_number": friend_number,
+		},
+		"Failed to stop screen share",
+		mapScreenShareError,
+		func(toxavInstance *toxcore.ToxAV) error {
+			return toxavInstance.StopScreenShare(uint32(friend_number))
+		},
+	)
+}
+
+// toxav_callback_recording_stats sets the callback reporting bytes written
+// to a recording.
+//
+// This is a toxcore-go extension; libtoxcore has no recording API.
+//
+//export toxav_callback_recording_stats
+func toxav_callback_recording_stats(av unsafe.Pointer, callback C.toxav_recording_stats_cb, user_data unsafe.Pointer) {
+	registerToxAVCallback(av, func(callbacks *toxavCallbacks) {
+		callbacks.mu.Lock()
+		callbacks.recordingStatsCb = callback
+		callbacks.recordingStatsUserData = user_data
+		callbacks.mu.Unlock()
+	}, func(toxavInstance *toxcore.ToxAV, toxavID uintptr) {
+		toxavInstance.CallbackRecordingStats(func(friendNumber uint32, bytesWritten uint64) {
+			defer recoverToxAVCallback(toxavID, "toxav_recording_stats_cb")
+			callbacks, handle, ok := resolveToxAVCallback(toxavID, "toxav_recording_stats_cb")
+			if !ok {
+				return
+			}
+			callbacks.mu.RLock()
+			cb, ud := callbacks.recordingStatsCb, callbacks.recordingStatsUserData
+			callbacks.mu.RUnlock()
+			if cb != nil {
+				C.invoke_recording_stats_cb(cb, (*C.ToxAV)(handle), C.uint32_t(friendNumber), C.uint64_t(bytesWritten), ud)
+			}
+		})
+	})
+}
+
 // Required for building as a shared library but defined in toxcore_c.go
 // func main() is already defined in the main toxcore C bindings
 
@@ -440,6 +440,10 @@ func tox_kill(tox unsafe.Pointer) {
 		delete(toxCallbackMap, toxID)
 		toxCallbacksMu.Unlock()
 
+		callbackErrorHandlersMu.Lock()
+		delete(callbackErrorHandlers, toxID)
+		callbackErrorHandlersMu.Unlock()
+
 		// Clean up group message and invite callbacks for this instance
 		groupMessageCallbacksMu.Lock()
 		delete(groupMessageCallbacks, toxID)
@@ -788,6 +792,7 @@ func tox_callback_friend_request(tox, callback, userData unsafe.Pointer) {
 	toxID := ctx.toxID
 
 	ctx.toxInstance.OnFriendRequest(func(publicKey [32]byte, message string) {
+		defer recoverCallback(toxID, "friend_request_cb")
 		toxCallbacksMu.RLock()
 		cbData := toxCallbackMap[toxID]
 		toxCallbacksMu.RUnlock()
@@ -833,6 +838,7 @@ func tox_callback_friend_message(tox, callback, userData unsafe.Pointer) {
 	toxID := ctx.toxID
 
 	ctx.toxInstance.OnFriendMessage(func(friendID uint32, message string) {
+		defer recoverCallback(toxID, "friend_message_cb")
 		toxCallbacksMu.RLock()
 		cbData := toxCallbackMap[toxID]
 		toxCallbacksMu.RUnlock()
@@ -877,6 +883,7 @@ func tox_callback_friend_connection_status(tox, callback, userData unsafe.Pointe
 	toxID := ctx.toxID
 
 	ctx.toxInstance.OnFriendConnectionStatus(func(friendID uint32, connectionStatus toxcore.ConnectionStatus) {
+		defer recoverCallback(toxID, "friend_connection_status_cb")
 		toxCallbacksMu.RLock()
 		cbData := toxCallbackMap[toxID]
 		toxCallbacksMu.RUnlock()
@@ -1482,6 +1489,7 @@ func invokeFileChunkRequestCallback(cb C.file_chunk_request_cb, tox unsafe.Point
 func tox_callback_file_recv(tox unsafe.Pointer, callback C.file_recv_cb) {
 	registerFileCallback(tox, callback, nil, &fileRecvCallbacksMu, fileRecvCallbacks, "File recv callback registered", func(toxInstance *toxcore.Tox, toxID int) {
 		toxInstance.OnFileRecv(func(friendID, fileID, kind uint32, fileSize uint64, filename string) {
+			defer recoverCallback(toxID, "file_recv_cb")
 			fileRecvCallbacksMu.RLock()
 			cb := fileRecvCallbacks[toxID]
 			fileRecvCallbacksMu.RUnlock()
@@ -1496,6 +1504,7 @@ func tox_callback_file_recv(tox unsafe.Pointer, callback C.file_recv_cb) {
 func tox_callback_file_recv_chunk(tox unsafe.Pointer, callback C.file_recv_chunk_cb) {
 	registerFileCallback(tox, callback, nil, &fileRecvChunkCallbacksMu, fileRecvChunkCallbacks, "File recv chunk callback registered", func(toxInstance *toxcore.Tox, toxID int) {
 		toxInstance.OnFileRecvChunk(func(friendID, fileID uint32, position uint64, data []byte) {
+			defer recoverCallback(toxID, "file_recv_chunk_cb")
 			fileRecvChunkCallbacksMu.RLock()
 			cb := fileRecvChunkCallbacks[toxID]
 			fileRecvChunkCallbacksMu.RUnlock()
@@ -1510,6 +1519,7 @@ func tox_callback_file_recv_chunk(tox unsafe.Pointer, callback C.file_recv_chunk
 func tox_callback_file_chunk_request(tox unsafe.Pointer, callback C.file_chunk_request_cb) {
 	registerFileCallback(tox, callback, nil, &fileChunkRequestCallbacksMu, fileChunkRequestCallbacks, "File chunk request callback registered", func(toxInstance *toxcore.Tox, toxID int) {
 		toxInstance.OnFileChunkRequest(func(friendID, fileID uint32, position uint64, length int) {
+			defer recoverCallback(toxID, "file_chunk_request_cb")
 			fileChunkRequestCallbacksMu.RLock()
 			cb := fileChunkRequestCallbacks[toxID]
 			fileChunkRequestCallbacksMu.RUnlock()
@@ -71,6 +71,13 @@
 //	store.WriteEncrypted("identity", keyPair.Private[:])
 //	key, _ := store.ReadEncrypted("identity")
 //
+// Keys are derived with Argon2id by default; NewEncryptedKeyStoreWithKDF
+// selects PBKDF2 instead. Each file records the KDF and parameters it was
+// written with, so files from either KDF, and legacy files, remain readable.
+//
+// The shamirshare sub-package splits a secret key into k-of-n shares for
+// backup and recovers it from any k of them.
+//
 // NonceStore provides replay attack protection through persistent nonce tracking:
 //
 //	ns, _ := crypto.NewNonceStore("/path/to/data")
@@ -80,6 +87,10 @@
 //	    // Replay attack detected
 //	}
 //
+// NewNonceStoreWithOptions bounds the store with MaxSize, evicting the oldest
+// nonces under load instead of rejecting new ones, and reports its size to a
+// MetricsRecorder.
+//
 // # Secure Memory Handling
 //
 // All sensitive data should be securely wiped after use to prevent memory disclosure:
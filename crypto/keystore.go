@@ -4,7 +4,6 @@ import (
 	"crypto/aes"
 	"crypto/cipher"
 	"crypto/rand"
-	"crypto/sha256"
 	"encoding/binary"
 	"fmt"
 	"io"
@@ -14,8 +13,6 @@ import (
 	"sync"
 
 	"github.com/sirupsen/logrus"
-	"golang.org/x/crypto/argon2"
-	"golang.org/x/crypto/pbkdf2"
 )
 
 // EncryptedKeyStore wraps file storage with AES-GCM encryption at rest.
@@ -24,8 +21,9 @@ import (
 type EncryptedKeyStore struct {
 	mu             sync.RWMutex // Protects encryptionKey, masterPassword, salt from concurrent access
 	encryptionKey  [32]byte
-	masterPassword []byte // Retained for on-demand legacy PBKDF2 derivation; wiped on Close
-	salt           []byte // KDF salt retained alongside masterPassword
+	kdf            kdfParams // KDF that derived encryptionKey; recorded in every file written
+	masterPassword []byte    // Retained for on-demand derivation for files using other KDF parameters; wiped on Close
+	salt           []byte    // KDF salt retained alongside masterPassword
 	dataDir        string
 	saltFile       string
 }
@@ -36,15 +34,20 @@ const (
 	PBKDF2Iterations = 100000
 	// EncryptionVersion is the current encryption format version
 	// Version 1: PBKDF2-SHA256 (legacy)
-	// Version 2: Argon2id (current)
-	EncryptionVersion = 2
+	// Version 2: Argon2id with the fixed Argon2Time/Argon2Memory/Argon2Threads parameters
+	// Version 3: KDF type and parameters recorded in a header (current)
+	EncryptionVersion = 3
 	// EncryptionVersionLegacy is the version using PBKDF2
 	EncryptionVersionLegacy = 1
+	// EncryptionVersionArgon2id is the version using Argon2id without a KDF header
+	EncryptionVersionArgon2id = 2
 	// SaltSize is the size of the salt for key derivation
 	SaltSize = 32
 
-	// Argon2id parameters following OWASP recommendations for high-security applications
-	// These provide strong resistance against GPU/ASIC attacks
+	// Argon2id parameters of version 2 files, following OWASP recommendations
+	// for high-security applications. NewEncryptedKeyStore still writes new
+	// files with them; stores created with NewEncryptedKeyStoreWithKDF use the
+	// DefaultArgon2id* parameters. Either way they are recorded in the header.
 	Argon2Time = 3 // Number of iterations
 	// Argon2Memory is the memory cost parameter for Argon2id (64 MB).
 	Argon2Memory = 64 * 1024 // 64 MB memory cost
@@ -69,16 +72,39 @@ func validateFilename(filename string) error {
 	return nil
 }
 
-// NewEncryptedKeyStore creates a key store with encryption at rest.
+// NewEncryptedKeyStore creates a key store with encryption at rest, deriving
+// its key with Argon2id at the Argon2Time/Argon2Memory/Argon2Threads cost of
+// version 2 files. Keeping that cost means existing version 2 files are read
+// with the store's key instead of a fresh derivation per read; use
+// NewEncryptedKeyStoreWithKDF to opt into the DefaultArgon2id* parameters.
 // masterPassword should be a user-provided passphrase or derived from system keyring.
 // For production use, consider using a key derivation service or hardware security module.
 //
-// The master password is retained in memory until Close() is called so that legacy
-// v1 (PBKDF2) files can be decrypted on demand without keeping a pre-derived key in
-// the struct.  Call Close() to securely wipe the password and all derived keys.
+// The master password is retained in memory until Close() is called so that files
+// written with a different KDF or different parameters, such as legacy v1 (PBKDF2)
+// files, can be decrypted on demand without keeping a pre-derived key in the struct.
+// Call Close() to securely wipe the password and all derived keys.
 //
 // CWE-311: Missing Encryption of Sensitive Data (addressed)
 func NewEncryptedKeyStore(dataDir string, masterPassword []byte) (*EncryptedKeyStore, error) {
+	return newEncryptedKeyStore(dataDir, masterPassword, legacyKDFParams(EncryptionVersionArgon2id))
+}
+
+// NewEncryptedKeyStoreWithKDF creates a key store that derives its key with
+// kdf, using PBKDF2Iterations for PBKDF2 and the DefaultArgon2id* parameters
+// for Argon2id. Every file records the KDF and parameters it was encrypted
+// under, so a store reads files written with any supported KDF regardless of
+// its own choice.
+func NewEncryptedKeyStoreWithKDF(dataDir string, masterPassword []byte, kdf KDFType) (*EncryptedKeyStore, error) {
+	params, err := defaultKDFParams(kdf)
+	if err != nil {
+		return nil, err
+	}
+	return newEncryptedKeyStore(dataDir, masterPassword, params)
+}
+
+// newEncryptedKeyStore creates a key store that writes files under params.
+func newEncryptedKeyStore(dataDir string, masterPassword []byte, params kdfParams) (*EncryptedKeyStore, error) {
 	if len(masterPassword) == 0 {
 		return nil, fmt.Errorf("master password cannot be empty")
 	}
@@ -88,6 +114,7 @@ func NewEncryptedKeyStore(dataDir string, masterPassword []byte) (*EncryptedKeyS
 	}
 
 	ks := &EncryptedKeyStore{
+		kdf:      params,
 		dataDir:  dataDir,
 		saltFile: filepath.Join(dataDir, ".salt"),
 	}
@@ -98,15 +125,15 @@ func NewEncryptedKeyStore(dataDir string, masterPassword []byte) (*EncryptedKeyS
 		return nil, fmt.Errorf("failed to initialize salt: %w", err)
 	}
 
-	// Derive encryption key using Argon2id
-	// This provides memory-hard protection against GPU/ASIC brute-force attacks
-	derivedKey := argon2.IDKey(masterPassword, salt, Argon2Time, Argon2Memory, Argon2Threads, Argon2KeyLen)
+	// Derive the encryption key. Argon2id provides memory-hard protection
+	// against GPU/ASIC brute-force attacks.
+	derivedKey := params.deriveKey(masterPassword, salt)
 	copy(ks.encryptionKey[:], derivedKey)
 	SecureWipe(derivedKey)
 
-	// Retain the master password and salt so that legacy v1 (PBKDF2) files can
-	// be decrypted on demand in ReadEncrypted without keeping the derived key in
-	// the struct.  Both are wiped in Close().
+	// Retain the master password and salt so that files using other KDF
+	// parameters can be decrypted on demand in ReadEncrypted without keeping
+	// their derived keys in the struct.  Both are wiped in Close().
 	ks.masterPassword = make([]byte, len(masterPassword))
 	copy(ks.masterPassword, masterPassword)
 	ks.salt = salt
@@ -150,7 +177,8 @@ func (ks *EncryptedKeyStore) loadOrGenerateSalt() ([]byte, error) {
 }
 
 // WriteEncrypted encrypts and writes data to a file.
-// Format: [version:2][nonce:12][ciphertext+tag:N]
+// Format: [version:2][kdf header:10][nonce:12][ciphertext+tag:N]
+// The version and KDF header are authenticated as additional data.
 //
 // The encryption provides:
 // - Confidentiality: AES-256-GCM encryption
@@ -188,14 +216,18 @@ func (ks *EncryptedKeyStore) writeEncryptedNoLock(filename string, plaintext []b
 		return fmt.Errorf("failed to generate nonce: %w", err)
 	}
 
+	header := make([]byte, 2, 2+kdfHeaderSize)
+	binary.BigEndian.PutUint16(header, EncryptionVersion)
+	header = append(header, ks.kdf.marshal()...)
+
 	// Encrypt with authentication
-	ciphertext := gcm.Seal(nil, nonce, plaintext, nil)
+	ciphertext := gcm.Seal(nil, nonce, plaintext, header)
 
-	// Construct output: version || nonce || ciphertext
-	output := make([]byte, 2+len(nonce)+len(ciphertext))
-	binary.BigEndian.PutUint16(output[0:2], EncryptionVersion)
-	copy(output[2:2+len(nonce)], nonce)
-	copy(output[2+len(nonce):], ciphertext)
+	// Construct output: header || nonce || ciphertext
+	output := make([]byte, 0, len(header)+len(nonce)+len(ciphertext))
+	output = append(output, header...)
+	output = append(output, nonce...)
+	output = append(output, ciphertext...)
 
 	// Atomic write using temporary file + rename
 	tmpFile := filepath.Join(ks.dataDir, filename+".tmp")
@@ -216,7 +248,7 @@ func (ks *EncryptedKeyStore) writeEncryptedNoLock(filename string, plaintext []b
 
 // ReadEncrypted reads and decrypts data from a file.
 // Returns error if the file doesn't exist, is corrupted, or authentication fails.
-// Supports reading both v1 (PBKDF2) and v2 (Argon2id) encrypted files.
+// Supports reading v1 (PBKDF2), v2 (Argon2id) and v3 (any KDF) encrypted files.
 func (ks *EncryptedKeyStore) ReadEncrypted(filename string) ([]byte, error) {
 	if err := validateFilename(filename); err != nil {
 		return nil, err
@@ -236,26 +268,40 @@ func (ks *EncryptedKeyStore) readEncryptedNoLock(filename string) ([]byte, error
 		return nil, err
 	}
 
-	// Determine which key to use based on file version
+	// Determine which KDF the file was written with
 	version := binary.BigEndian.Uint16(data[0:2])
+	params := legacyKDFParams(version)
+	headerSize := 2
+	if version == EncryptionVersion {
+		if params, err = parseKDFHeader(data[2:]); err != nil {
+			return nil, err
+		}
+		headerSize += kdfHeaderSize
+	}
+
 	var gcm cipher.AEAD
-	if version == EncryptionVersionLegacy {
+	if params == ks.kdf {
+		gcm, err = ks.createGCMCipher()
+	} else {
 		if len(ks.masterPassword) == 0 {
-			return nil, fmt.Errorf("legacy v1 file found but master password is no longer available (already wiped)")
+			return nil, fmt.Errorf("v%d %s file found but master password is no longer available (already wiped)", version, params.kdf)
 		}
-		// Derive the PBKDF2 key on-demand and wipe it immediately after use
-		// to avoid retaining the legacy key in memory longer than necessary.
-		legacyKey := pbkdf2.Key(ks.masterPassword, ks.salt, PBKDF2Iterations, 32, sha256.New)
-		gcm, err = ks.createGCMCipherWithKey(legacyKey)
-		SecureWipe(legacyKey)
-	} else {
-		gcm, err = ks.createGCMCipher()
+		// Derive the file's key on-demand and wipe it immediately after use
+		// to avoid retaining it in memory longer than necessary.
+		fileKey := params.deriveKey(ks.masterPassword, ks.salt)
+		gcm, err = ks.createGCMCipherWithKey(fileKey)
+		SecureWipe(fileKey)
 	}
 	if err != nil {
 		return nil, err
 	}
 
-	return ks.decryptData(data, gcm)
+	// Only v3 authenticates its header; earlier versions used no additional data
+	var additionalData []byte
+	if version == EncryptionVersion {
+		additionalData = data[:headerSize]
+	}
+	return ks.decryptData(data[headerSize:], additionalData, gcm)
 }
 
 // readAndValidateFile reads the encrypted file and validates its format.
@@ -271,8 +317,11 @@ func (ks *EncryptedKeyStore) readAndValidateFile(filename string) ([]byte, error
 	}
 
 	version := binary.BigEndian.Uint16(data[0:2])
-	if version != EncryptionVersion && version != EncryptionVersionLegacy {
-		return nil, fmt.Errorf("unsupported encryption version: %d (expected %d or %d)", version, EncryptionVersionLegacy, EncryptionVersion)
+	if version < EncryptionVersionLegacy || version > EncryptionVersion {
+		return nil, fmt.Errorf("unsupported encryption version: %d (expected %d to %d)", version, EncryptionVersionLegacy, EncryptionVersion)
+	}
+	if version == EncryptionVersion && len(data) < 2+kdfHeaderSize+12+16 {
+		return nil, fmt.Errorf("file too short: %d bytes (minimum %d bytes)", len(data), 2+kdfHeaderSize+12+16)
 	}
 
 	return data, nil
@@ -298,17 +347,18 @@ func (ks *EncryptedKeyStore) createGCMCipherWithKey(key []byte) (cipher.AEAD, er
 	return gcm, nil
 }
 
-// decryptData extracts the nonce and decrypts the ciphertext.
-func (ks *EncryptedKeyStore) decryptData(data []byte, gcm cipher.AEAD) ([]byte, error) {
+// decryptData extracts the nonce from the data following the file header
+// and decrypts the ciphertext.
+func (ks *EncryptedKeyStore) decryptData(body, additionalData []byte, gcm cipher.AEAD) ([]byte, error) {
 	nonceSize := gcm.NonceSize()
-	if len(data) < 2+nonceSize {
-		return nil, fmt.Errorf("file too short for nonce: %d bytes", len(data))
+	if len(body) < nonceSize {
+		return nil, fmt.Errorf("file too short for nonce: %d bytes", len(body))
 	}
 
-	nonce := data[2 : 2+nonceSize]
-	ciphertext := data[2+nonceSize:]
+	nonce := body[:nonceSize]
+	ciphertext := body[nonceSize:]
 
-	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, additionalData)
 	if err != nil {
 		return nil, fmt.Errorf("decryption failed (wrong password or corrupted data): %w", err)
 	}
@@ -351,7 +401,7 @@ func (ks *EncryptedKeyStore) Close() error {
 	ks.mu.Lock()
 	defer ks.mu.Unlock()
 
-	// Securely wipe the primary Argon2id-derived encryption key
+	// Securely wipe the primary derived encryption key
 	ZeroBytes(ks.encryptionKey[:])
 	// Wipe the retained master password and salt used for on-demand legacy derivation
 	SecureWipe(ks.masterPassword)
@@ -396,7 +446,7 @@ func (ks *EncryptedKeyStore) RotateKey(newMasterPassword []byte) (err error) {
 	}
 
 	// Replace the stored master password and salt with the new values so that
-	// on-demand legacy PBKDF2 derivation uses the correct credentials.
+	// on-demand derivation uses the correct credentials.
 	SecureWipe(ks.masterPassword)
 	SecureWipe(ks.salt)
 	ks.masterPassword = make([]byte, len(newMasterPassword))
@@ -439,14 +489,14 @@ func (ks *EncryptedKeyStore) decryptAllFiles() (map[string][]byte, error) {
 	return fileData, nil
 }
 
-// deriveNewEncryptionKey generates a new salt and derives a new encryption key using Argon2id.
+// deriveNewEncryptionKey generates a new salt and derives a new encryption key using the store's KDF.
 func (ks *EncryptedKeyStore) deriveNewEncryptionKey(newMasterPassword []byte) ([]byte, []byte, error) {
 	newSalt := make([]byte, SaltSize)
 	if _, err := rand.Read(newSalt); err != nil {
 		return nil, nil, fmt.Errorf("failed to generate new salt: %w", err)
 	}
 
-	newKey := argon2.IDKey(newMasterPassword, newSalt, Argon2Time, Argon2Memory, Argon2Threads, Argon2KeyLen)
+	newKey := ks.kdf.deriveKey(newMasterPassword, newSalt)
 	return newKey, newSalt, nil
 }
 
@@ -0,0 +1,140 @@
+package crypto
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// KDFType selects the key derivation function EncryptedKeyStore uses to turn
+// the master password into its encryption key.
+type KDFType uint8
+
+const (
+	// KDFTypePBKDF2 derives keys with PBKDF2-SHA256.
+	KDFTypePBKDF2 KDFType = iota
+	// KDFTypeArgon2id derives keys with the memory-hard Argon2id function.
+	KDFTypeArgon2id
+)
+
+// String returns the KDF name.
+func (k KDFType) String() string {
+	switch k {
+	case KDFTypePBKDF2:
+		return "PBKDF2"
+	case KDFTypeArgon2id:
+		return "Argon2id"
+	default:
+		return fmt.Sprintf("KDFType(%d)", uint8(k))
+	}
+}
+
+const (
+	// DefaultArgon2idTime is the Argon2id iteration count for files written
+	// by NewEncryptedKeyStoreWithKDF, following the OWASP 2023 recommendation.
+	DefaultArgon2idTime = 1
+	// DefaultArgon2idMemory is the Argon2id memory cost for new files (64 MB).
+	DefaultArgon2idMemory = 64 * 1024
+	// DefaultArgon2idThreads is the Argon2id parallelism for new files.
+	DefaultArgon2idThreads = 4
+
+	// kdfHeaderSize is the size of the KDF header that follows the version in
+	// version 3 files: [kdf:1][param1:4][param2:4][param3:1]. PBKDF2 stores
+	// its iteration count in param1; Argon2id stores time, memory (KiB) and
+	// threads.
+	kdfHeaderSize = 10
+
+	// Upper bounds on parameters read from a file header, so a crafted file
+	// cannot make a read allocate or compute without limit.
+	maxPBKDF2Iterations = 10_000_000
+	maxArgon2idTime     = 64
+	maxArgon2idMemory   = 1024 * 1024 // 1 GiB
+)
+
+// kdfParams is a KDF together with the parameters it was run with. It is
+// comparable so a file's parameters can be matched against the store's.
+type kdfParams struct {
+	kdf        KDFType
+	iterations uint32 // PBKDF2
+	time       uint32 // Argon2id
+	memory     uint32 // Argon2id, in KiB
+	threads    uint8  // Argon2id
+}
+
+// defaultKDFParams returns the parameters new files are written with.
+func defaultKDFParams(kdf KDFType) (kdfParams, error) {
+	switch kdf {
+	case KDFTypePBKDF2:
+		return kdfParams{kdf: kdf, iterations: PBKDF2Iterations}, nil
+	case KDFTypeArgon2id:
+		return kdfParams{
+			kdf:     kdf,
+			time:    DefaultArgon2idTime,
+			memory:  DefaultArgon2idMemory,
+			threads: DefaultArgon2idThreads,
+		}, nil
+	default:
+		return kdfParams{}, fmt.Errorf("unsupported KDF type: %s", kdf)
+	}
+}
+
+// legacyKDFParams returns the fixed parameters of files written before the
+// KDF header existed.
+func legacyKDFParams(version uint16) kdfParams {
+	if version == EncryptionVersionLegacy {
+		return kdfParams{kdf: KDFTypePBKDF2, iterations: PBKDF2Iterations}
+	}
+	return kdfParams{kdf: KDFTypeArgon2id, time: Argon2Time, memory: Argon2Memory, threads: Argon2Threads}
+}
+
+// deriveKey runs the KDF over password and salt. The caller must wipe the
+// returned key.
+func (p kdfParams) deriveKey(password, salt []byte) []byte {
+	if p.kdf == KDFTypePBKDF2 {
+		return pbkdf2.Key(password, salt, int(p.iterations), Argon2KeyLen, sha256.New)
+	}
+	return argon2.IDKey(password, salt, p.time, p.memory, p.threads, Argon2KeyLen)
+}
+
+// marshal encodes p as a KDF header.
+func (p kdfParams) marshal() []byte {
+	header := make([]byte, kdfHeaderSize)
+	header[0] = byte(p.kdf)
+	if p.kdf == KDFTypePBKDF2 {
+		binary.BigEndian.PutUint32(header[1:5], p.iterations)
+		return header
+	}
+	binary.BigEndian.PutUint32(header[1:5], p.time)
+	binary.BigEndian.PutUint32(header[5:9], p.memory)
+	header[9] = p.threads
+	return header
+}
+
+// parseKDFHeader decodes and validates a KDF header.
+func parseKDFHeader(header []byte) (kdfParams, error) {
+	if len(header) < kdfHeaderSize {
+		return kdfParams{}, fmt.Errorf("KDF header too short: %d bytes", len(header))
+	}
+	p := kdfParams{kdf: KDFType(header[0])}
+	switch p.kdf {
+	case KDFTypePBKDF2:
+		p.iterations = binary.BigEndian.Uint32(header[1:5])
+		if p.iterations == 0 || p.iterations > maxPBKDF2Iterations {
+			return kdfParams{}, fmt.Errorf("invalid PBKDF2 iteration count: %d", p.iterations)
+		}
+	case KDFTypeArgon2id:
+		p.time = binary.BigEndian.Uint32(header[1:5])
+		p.memory = binary.BigEndian.Uint32(header[5:9])
+		p.threads = header[9]
+		if p.time == 0 || p.time > maxArgon2idTime ||
+			p.memory < 8*uint32(p.threads) || p.memory > maxArgon2idMemory || p.threads == 0 {
+			return kdfParams{}, fmt.Errorf("invalid Argon2id parameters: time=%d memory=%d threads=%d", p.time, p.memory, p.threads)
+		}
+	default:
+		return kdfParams{}, fmt.Errorf("unsupported KDF type: %s", p.kdf)
+	}
+	return p, nil
+}
@@ -0,0 +1,211 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/crypto/argon2"
+)
+
+func TestEncryptedKeyStoreWithKDF_HeaderRecordsKDF(t *testing.T) {
+	tests := []struct {
+		kdf  KDFType
+		want kdfParams
+	}{
+		{KDFTypePBKDF2, kdfParams{kdf: KDFTypePBKDF2, iterations: PBKDF2Iterations}},
+		{KDFTypeArgon2id, kdfParams{kdf: KDFTypeArgon2id, time: 1, memory: 64 * 1024, threads: 4}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.kdf.String(), func(t *testing.T) {
+			tempDir := t.TempDir()
+			ks, err := NewEncryptedKeyStoreWithKDF(tempDir, []byte("test-password"), tt.kdf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer ks.Close()
+
+			testData := []byte("kdf-test-data")
+			if err := ks.WriteEncrypted("test.dat", testData); err != nil {
+				t.Fatal(err)
+			}
+			rawData, err := os.ReadFile(filepath.Join(tempDir, "test.dat"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if version := binary.BigEndian.Uint16(rawData[0:2]); version != EncryptionVersion {
+				t.Errorf("Expected version %d, got %d", EncryptionVersion, version)
+			}
+			params, err := parseKDFHeader(rawData[2:])
+			if err != nil {
+				t.Fatalf("Failed to parse KDF header: %v", err)
+			}
+			if params != tt.want {
+				t.Errorf("Expected header %+v, got %+v", tt.want, params)
+			}
+
+			decrypted, err := ks.ReadEncrypted("test.dat")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(testData, decrypted) {
+				t.Error("Decrypted data mismatch")
+			}
+		})
+	}
+}
+
+func TestEncryptedKeyStoreWithKDF_AutoDetectsKDF(t *testing.T) {
+	tempDir := t.TempDir()
+	password := []byte("test-password")
+
+	pbkdf2Store, err := NewEncryptedKeyStoreWithKDF(tempDir, append([]byte(nil), password...), KDFTypePBKDF2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pbkdf2Store.Close()
+	argon2Store, err := NewEncryptedKeyStoreWithKDF(tempDir, append([]byte(nil), password...), KDFTypeArgon2id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer argon2Store.Close()
+
+	if err := pbkdf2Store.WriteEncrypted("pbkdf2.dat", []byte("from pbkdf2")); err != nil {
+		t.Fatal(err)
+	}
+	if err := argon2Store.WriteEncrypted("argon2.dat", []byte("from argon2id")); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, ks := range map[string]*EncryptedKeyStore{"pbkdf2 store": pbkdf2Store, "argon2id store": argon2Store} {
+		for filename, want := range map[string]string{"pbkdf2.dat": "from pbkdf2", "argon2.dat": "from argon2id"} {
+			got, err := ks.ReadEncrypted(filename)
+			if err != nil {
+				t.Fatalf("%s: failed to read %s: %v", name, filename, err)
+			}
+			if string(got) != want {
+				t.Errorf("%s: %s mismatch: got %q", name, filename, got)
+			}
+		}
+	}
+}
+
+func TestEncryptedKeyStore_V2FileCompatibility(t *testing.T) {
+	tempDir := t.TempDir()
+	password := []byte("test-password")
+
+	ks, err := NewEncryptedKeyStore(tempDir, append([]byte(nil), password...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ks.Close()
+
+	// Write a file the way version 2 did: fixed Argon2id parameters, no KDF header
+	salt, err := os.ReadFile(filepath.Join(tempDir, ".salt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := argon2.IDKey(password, salt, Argon2Time, Argon2Memory, Argon2Threads, Argon2KeyLen)
+	block, _ := aes.NewCipher(key)
+	gcm, _ := cipher.NewGCM(block)
+	nonce := make([]byte, gcm.NonceSize())
+	rand.Read(nonce)
+	testData := []byte("v2-test-data")
+	output := binary.BigEndian.AppendUint16(nil, EncryptionVersionArgon2id)
+	output = append(output, nonce...)
+	output = gcm.Seal(output, nonce, testData, nil)
+	if err := os.WriteFile(filepath.Join(tempDir, "v2.dat"), output, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	decrypted, err := ks.ReadEncrypted("v2.dat")
+	if err != nil {
+		t.Fatalf("Failed to read v2 file: %v", err)
+	}
+	if !bytes.Equal(testData, decrypted) {
+		t.Error("v2 file data mismatch")
+	}
+}
+
+func TestEncryptedKeyStore_DefaultKDFCost(t *testing.T) {
+	ks, err := NewEncryptedKeyStore(t.TempDir(), []byte("test-password"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ks.Close()
+	// The default store keeps the version 2 cost, so v2 files need no extra derivation
+	if ks.kdf != legacyKDFParams(EncryptionVersionArgon2id) {
+		t.Errorf("default store uses %+v, want the version 2 parameters", ks.kdf)
+	}
+
+	explicit, err := NewEncryptedKeyStoreWithKDF(t.TempDir(), []byte("test-password"), KDFTypeArgon2id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer explicit.Close()
+	if explicit.kdf.time != DefaultArgon2idTime || explicit.kdf.memory != DefaultArgon2idMemory {
+		t.Errorf("explicit Argon2id store uses %+v, want the DefaultArgon2id* parameters", explicit.kdf)
+	}
+}
+
+func TestEncryptedKeyStore_TamperedKDFHeader(t *testing.T) {
+	tempDir := t.TempDir()
+	ks, err := NewEncryptedKeyStoreWithKDF(tempDir, []byte("test-password"), KDFTypePBKDF2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ks.Close()
+
+	if err := ks.WriteEncrypted("test.dat", []byte("tamper-test")); err != nil {
+		t.Fatal(err)
+	}
+	filePath := filepath.Join(tempDir, "test.dat")
+	rawData, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Lowering the iteration count must not yield a readable file
+	tampered := append([]byte(nil), rawData...)
+	binary.BigEndian.PutUint32(tampered[3:7], 1)
+	if err := os.WriteFile(filePath, tampered, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ks.ReadEncrypted("test.dat"); err == nil {
+		t.Error("Expected tampered KDF header to fail decryption")
+	}
+
+	// Parameters beyond the limits are rejected before any derivation
+	binary.BigEndian.PutUint32(tampered[3:7], maxPBKDF2Iterations+1)
+	if err := os.WriteFile(filePath, tampered, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ks.ReadEncrypted("test.dat"); err == nil {
+		t.Error("Expected excessive iteration count to be rejected")
+	}
+}
+
+func TestParseKDFHeader_Invalid(t *testing.T) {
+	valid := kdfParams{kdf: KDFTypeArgon2id, time: 1, memory: 64 * 1024, threads: 4}
+	tests := map[string]func(header []byte) []byte{
+		"truncated":    func(h []byte) []byte { return h[:kdfHeaderSize-1] },
+		"unknown KDF":  func(h []byte) []byte { h[0] = 9; return h },
+		"zero time":    func(h []byte) []byte { binary.BigEndian.PutUint32(h[1:5], 0); return h },
+		"huge memory":  func(h []byte) []byte { binary.BigEndian.PutUint32(h[5:9], maxArgon2idMemory+1); return h },
+		"2 GiB memory": func(h []byte) []byte { binary.BigEndian.PutUint32(h[5:9], 2*1024*1024); return h },
+		"zero threads": func(h []byte) []byte { h[9] = 0; return h },
+	}
+	for name, mutate := range tests {
+		if _, err := parseKDFHeader(mutate(valid.marshal())); err == nil {
+			t.Errorf("%s: expected header to be rejected", name)
+		}
+	}
+	if _, err := NewEncryptedKeyStoreWithKDF(t.TempDir(), []byte("test-password"), KDFType(9)); err == nil {
+		t.Error("Expected unsupported KDF type to be rejected")
+	}
+}
@@ -1,11 +1,13 @@
 package crypto
 
 import (
+	"cmp"
 	"encoding/binary"
 	"fmt"
 	"math"
 	"os"
 	"path/filepath"
+	"slices"
 	"sync"
 	"time"
 
@@ -35,7 +37,9 @@ import (
 //	}
 //
 // The store is safe for concurrent use and automatically runs a background
-// goroutine to cleanup expired nonces.
+// goroutine to cleanup expired nonces. By default it rejects new nonces once
+// it holds maxNonceStoreEntries; NewNonceStoreWithOptions can instead bound it
+// by evicting the oldest nonces.
 type NonceStore struct {
 	mu           sync.RWMutex
 	nonces       map[[32]byte]int64 // nonce -> expiry timestamp
@@ -46,6 +50,14 @@ type NonceStore struct {
 	logger       *logrus.Logger
 	timeProvider TimeProvider
 	maxEntries   int // hard cap on nonce map size to prevent unbounded growth
+
+	// Eviction state, used only when maxSize > 0. buckets holds nonces in
+	// insertion order. evictedWatermark is the newest timestamp of any
+	// evicted nonce, capped at the local clock: nonces at or before it are
+	// rejected, since they may be replays of evicted ones.
+	maxSize          int
+	buckets          []*nonceBucket
+	evictedWatermark int64
 }
 
 // maxNonceStoreEntries is the hard cap on the number of nonces that can be
@@ -53,6 +65,10 @@ type NonceStore struct {
 // high-throughput or malicious traffic.
 const maxNonceStoreEntries = 100000
 
+// nonceExpiryDelta is how long a nonce is remembered past its timestamp
+// (5 minutes handshake window + 1 minute future drift), in seconds.
+const nonceExpiryDelta = int64(6 * time.Minute / time.Second)
+
 // NewNonceStore creates a persistent nonce store
 func NewNonceStore(dataDir string) (*NonceStore, error) {
 	return NewNonceStoreWithTimeProvider(dataDir, nil)
@@ -61,10 +77,28 @@ func NewNonceStore(dataDir string) (*NonceStore, error) {
 // NewNonceStoreWithTimeProvider creates a persistent nonce store with a custom TimeProvider.
 // Pass nil for timeProvider to use the default time provider.
 func NewNonceStoreWithTimeProvider(dataDir string, timeProvider TimeProvider) (*NonceStore, error) {
+	return NewNonceStoreWithOptions(dataDir, NonceStoreOptions{TimeProvider: timeProvider})
+}
+
+// NewNonceStoreWithOptions creates a persistent nonce store configured by opts.
+//
+// With opts.MaxSize set, the store never holds more than MaxSize nonces:
+// when an insertion exceeds it, expired nonces are dropped and then the
+// oldest-inserted nonces are evicted, FIFO within each second of insertion,
+// until a tenth of the capacity is free. Replay protection is kept for
+// evicted nonces by rejecting any nonce whose timestamp is not newer than the
+// newest evicted one, capped at the local clock, so under sustained overload
+// peers with lagging clocks may need to retry. The cap means an evicted nonce
+// stamped ahead of the local clock is only remembered up to now.
+func NewNonceStoreWithOptions(dataDir string, opts NonceStoreOptions) (*NonceStore, error) {
+	if opts.MaxSize < 0 {
+		return nil, fmt.Errorf("nonce store max size must not be negative, got %d", opts.MaxSize)
+	}
 	if err := os.MkdirAll(dataDir, 0o700); err != nil {
 		return nil, fmt.Errorf("failed to create data directory: %w", err)
 	}
 
+	timeProvider := opts.TimeProvider
 	if timeProvider == nil {
 		timeProvider = DefaultTimeProvider{}
 	}
@@ -77,6 +111,7 @@ func NewNonceStoreWithTimeProvider(dataDir string, timeProvider TimeProvider) (*
 		logger:       logrus.StandardLogger(),
 		timeProvider: timeProvider,
 		maxEntries:   maxNonceStoreEntries,
+		maxSize:      opts.MaxSize,
 	}
 
 	// Load existing nonces from disk
@@ -88,6 +123,14 @@ func NewNonceStoreWithTimeProvider(dataDir string, timeProvider TimeProvider) (*
 	// Start background cleanup
 	go ns.cleanupLoop()
 
+	if opts.Metrics != nil {
+		interval := opts.MetricsInterval
+		if interval <= 0 {
+			interval = DefaultMetricsInterval
+		}
+		go ns.metricsLoop(opts.Metrics, interval)
+	}
+
 	return ns, nil
 }
 
@@ -109,8 +152,18 @@ func (ns *NonceStore) CheckAndStore(nonce [32]byte, timestamp int64) bool {
 		return false
 	}
 
-	// Enforce hard cap to prevent unbounded memory growth
-	if len(ns.nonces) >= ns.maxEntries {
+	if ns.maxSize > 0 && timestamp <= ns.evictedWatermark {
+		ns.logger.WithFields(logrus.Fields{
+			"nonce":     fmt.Sprintf("%x", nonce[:8]),
+			"timestamp": timestamp,
+			"watermark": ns.evictedWatermark,
+		}).Warn("Rejecting nonce: timestamp not newer than evicted nonces")
+		return false
+	}
+
+	// Enforce hard cap to prevent unbounded memory growth; with eviction
+	// enabled the store is bounded by maxSize instead.
+	if ns.maxSize == 0 && len(ns.nonces) >= ns.maxEntries {
 		ns.cleanupExpiredLocked()
 		if len(ns.nonces) >= ns.maxEntries {
 			ns.logger.WithFields(logrus.Fields{
@@ -122,16 +175,17 @@ func (ns *NonceStore) CheckAndStore(nonce [32]byte, timestamp int64) bool {
 		}
 	}
 
-	// Calculate expiry (5 minutes handshake window + 1 minute future drift)
-	const expiryDelta = int64(6 * time.Minute / time.Second)
-	if timestamp > math.MaxInt64-expiryDelta {
+	// Calculate expiry
+	if timestamp > math.MaxInt64-nonceExpiryDelta {
 		ns.logger.WithField("timestamp", timestamp).Warn("Rejecting nonce: timestamp would overflow expiry calculation")
 		return false
 	}
-	expiry := timestamp + expiryDelta
+	expiry := timestamp + nonceExpiryDelta
 
 	// Store nonce
 	ns.nonces[nonce] = expiry
+	ns.recordInsertionLocked(nonce, expiry, ns.getTimeProvider().Now().Unix())
+	ns.evictLocked()
 
 	// Note: save() is called synchronously during Close() to ensure persistence
 	// Async saves during operation are optional for performance
@@ -192,6 +246,7 @@ func (ns *NonceStore) load() error {
 		nonce, timestamp, valid := ns.parseNonceRecord(data, offset, now)
 		if valid {
 			ns.nonces[nonce] = timestamp
+			ns.recordInsertionLocked(nonce, timestamp, now)
 			loaded++
 		}
 		offset += 40
@@ -203,6 +258,15 @@ func (ns *NonceStore) load() error {
 		"expired_pruned": count - uint64(loaded),
 	}).Info("Nonce store loaded successfully")
 
+	// Loaded nonces share one insertion bucket; order it oldest first so a
+	// file saved by a store with a larger capacity is trimmed sensibly.
+	if len(ns.buckets) > 0 {
+		slices.SortFunc(ns.buckets[0].refs, func(a, b nonceRef) int {
+			return cmp.Compare(a.expiry, b.expiry)
+		})
+	}
+	ns.evictLocked()
+
 	return nil
 }
 
@@ -290,6 +354,9 @@ func (ns *NonceStore) cleanupExpiredLocked() {
 	}
 
 	if removed > 0 {
+		if ns.maxSize > 0 {
+			ns.compactBucketsLocked()
+		}
 		ns.logger.WithFields(logrus.Fields{
 			"removed":   removed,
 			"remaining": len(ns.nonces),
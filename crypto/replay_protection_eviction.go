@@ -0,0 +1,143 @@
+package crypto
+
+import (
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// NonceStoreSizeMetric is the histogram name under which NonceStore reports
+// its size to a MetricsRecorder.
+const NonceStoreSizeMetric = "toxcore_crypto_nonce_store_size"
+
+// DefaultMetricsInterval is how often NonceStore reports its size when
+// NonceStoreOptions.MetricsInterval is zero.
+const DefaultMetricsInterval = time.Minute
+
+// MetricsRecorder receives periodic observations for Prometheus-style
+// monitoring. Implementations must be safe for concurrent use; a
+// prometheus.HistogramVec keyed by name is a natural backing store.
+type MetricsRecorder interface {
+	ObserveHistogram(name string, value float64)
+}
+
+// NonceStoreOptions configures a NonceStore created with
+// NewNonceStoreWithOptions. The zero value matches NewNonceStore.
+type NonceStoreOptions struct {
+	// MaxSize enables eviction: once the store holds more than MaxSize
+	// nonces, the oldest-inserted ones are evicted in a batch. Zero keeps the
+	// default behaviour of rejecting new nonces at a fixed capacity.
+	MaxSize int
+
+	// TimeProvider overrides the clock; nil uses DefaultTimeProvider.
+	TimeProvider TimeProvider
+
+	// Metrics, if set, receives the store size every MetricsInterval
+	// (DefaultMetricsInterval if zero).
+	Metrics         MetricsRecorder
+	MetricsInterval time.Duration
+}
+
+// nonceRef identifies one insertion of a nonce. A ref whose expiry no longer
+// matches the map entry belongs to a nonce that was already removed.
+type nonceRef struct {
+	nonce  [32]byte
+	expiry int64
+}
+
+// nonceBucket holds the nonces inserted during one second, in insertion
+// order.
+type nonceBucket struct {
+	insertedAt int64
+	refs       []nonceRef
+}
+
+// recordInsertionLocked appends nonce to the eviction queue. Caller must
+// hold ns.mu.
+func (ns *NonceStore) recordInsertionLocked(nonce [32]byte, expiry int64, now int64) {
+	if ns.maxSize <= 0 {
+		return
+	}
+	if n := len(ns.buckets); n == 0 || ns.buckets[n-1].insertedAt != now {
+		ns.buckets = append(ns.buckets, &nonceBucket{insertedAt: now})
+	}
+	last := ns.buckets[len(ns.buckets)-1]
+	last.refs = append(last.refs, nonceRef{nonce: nonce, expiry: expiry})
+}
+
+// evictLocked brings the store back under maxSize once it exceeds it:
+// expired nonces are dropped first, then the oldest-inserted nonces, until a
+// tenth of the capacity is free again. The watermark never moves past the
+// local clock, so a peer flooding future-stamped nonces cannot make the
+// store reject everyone else's current ones. Caller must hold ns.mu.
+func (ns *NonceStore) evictLocked() {
+	if ns.maxSize <= 0 || len(ns.nonces) <= ns.maxSize {
+		return
+	}
+	ns.cleanupExpiredLocked()
+	now := ns.getTimeProvider().Now().Unix()
+
+	target := ns.maxSize - max(1, ns.maxSize/10)
+	evicted := 0
+	for len(ns.nonces) > target && len(ns.buckets) > 0 {
+		bucket := ns.buckets[0]
+		for len(bucket.refs) > 0 && len(ns.nonces) > target {
+			ref := bucket.refs[0]
+			bucket.refs = bucket.refs[1:]
+			if expiry, ok := ns.nonces[ref.nonce]; ok && expiry == ref.expiry {
+				delete(ns.nonces, ref.nonce)
+				stamped := ref.expiry - nonceExpiryDelta
+				if stamped > now {
+					stamped = now
+				}
+				ns.evictedWatermark = max(ns.evictedWatermark, stamped)
+				evicted++
+			}
+		}
+		if len(bucket.refs) == 0 {
+			ns.buckets = ns.buckets[1:]
+		}
+	}
+
+	ns.logger.WithFields(logrus.Fields{
+		"evicted":   evicted,
+		"remaining": len(ns.nonces),
+		"max":       ns.maxSize,
+		"watermark": ns.evictedWatermark,
+	}).Warn("Nonce store over capacity, evicted oldest nonces")
+}
+
+// compactBucketsLocked drops refs to nonces that have since been removed,
+// so the queue does not outgrow the map. Caller must hold ns.mu.
+func (ns *NonceStore) compactBucketsLocked() {
+	kept := ns.buckets[:0]
+	for _, bucket := range ns.buckets {
+		refs := bucket.refs[:0]
+		for _, ref := range bucket.refs {
+			if expiry, ok := ns.nonces[ref.nonce]; ok && expiry == ref.expiry {
+				refs = append(refs, ref)
+			}
+		}
+		if len(refs) > 0 {
+			bucket.refs = refs
+			kept = append(kept, bucket)
+		}
+	}
+	clear(ns.buckets[len(kept):])
+	ns.buckets = kept
+}
+
+// metricsLoop reports the store size to recorder until the store is closed.
+func (ns *NonceStore) metricsLoop(recorder MetricsRecorder, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			recorder.ObserveHistogram(NonceStoreSizeMetric, float64(ns.Size()))
+		case <-ns.stopChan:
+			return
+		}
+	}
+}
@@ -3,6 +3,8 @@ package crypto
 import (
 	"os"
 	"path/filepath"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -399,3 +401,176 @@ func TestNonceStoreCloseIdempotent(t *testing.T) {
 	})
 	assert.NoError(t, err)
 }
+
+func TestNonceStoreEvictsOldestFirst(t *testing.T) {
+	fixedTime := time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC)
+	mock := &MockTimeProvider{currentTime: fixedTime}
+	ns, err := NewNonceStoreWithOptions(t.TempDir(), NonceStoreOptions{MaxSize: 10, TimeProvider: mock})
+	require.NoError(t, err)
+	defer ns.Close()
+
+	// Two insertion buckets of six nonces each
+	var nonces [][32]byte
+	for i := 0; i < 12; i++ {
+		if i == 6 {
+			mock.Advance(time.Second)
+		}
+		nonce := [32]byte{byte(i + 1)}
+		nonces = append(nonces, nonce)
+		require.True(t, ns.CheckAndStore(nonce, mock.Now().Unix()), "nonce %d should be accepted", i)
+		assert.LessOrEqual(t, ns.Size(), 10)
+	}
+
+	// Exceeding the limit on the 11th insertion evicted down to 9 entries
+	for i, nonce := range nonces {
+		ns.mu.RLock()
+		_, stored := ns.nonces[nonce]
+		ns.mu.RUnlock()
+		assert.Equal(t, i >= 2, stored, "nonce %d stored", i)
+	}
+
+	// Evicted nonces, and anything as old, are still treated as replays
+	assert.False(t, ns.CheckAndStore(nonces[0], fixedTime.Unix()), "evicted nonce replay should be rejected")
+	assert.False(t, ns.CheckAndStore([32]byte{0xAA}, fixedTime.Unix()), "nonce no newer than evicted ones should be rejected")
+	assert.True(t, ns.CheckAndStore([32]byte{0xBB}, fixedTime.Unix()+1), "newer nonce should be accepted")
+}
+
+func TestNonceStoreEvictionIgnoresFutureTimestamps(t *testing.T) {
+	fixedTime := time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC)
+	mock := &MockTimeProvider{currentTime: fixedTime}
+	ns, err := NewNonceStoreWithOptions(t.TempDir(), NonceStoreOptions{MaxSize: 10, TimeProvider: mock})
+	require.NoError(t, err)
+	defer ns.Close()
+
+	// A peer floods nonces stamped a day ahead, forcing evictions
+	future := fixedTime.Add(24 * time.Hour).Unix()
+	for i := 0; i < 100; i++ {
+		require.True(t, ns.CheckAndStore([32]byte{0xF0, byte(i)}, future+int64(i)))
+	}
+
+	ns.mu.RLock()
+	watermark := ns.evictedWatermark
+	ns.mu.RUnlock()
+	assert.LessOrEqual(t, watermark, fixedTime.Unix(), "watermark must not pass the local clock")
+
+	// Honest peers with current timestamps are still accepted
+	mock.Advance(time.Second)
+	assert.True(t, ns.CheckAndStore([32]byte{0x01}, mock.Now().Unix()))
+}
+
+func TestNonceStoreEvictionOrderUnderConcurrentInsertion(t *testing.T) {
+	const (
+		maxSize    = 100
+		goroutines = 8
+		perWorker  = 200
+	)
+	ns, err := NewNonceStoreWithOptions(t.TempDir(), NonceStoreOptions{MaxSize: maxSize})
+	require.NoError(t, err)
+	defer ns.Close()
+
+	base := time.Now().Unix()
+	var clock atomic.Int64
+	accepted := make([][][32]byte, goroutines)
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < perWorker; i++ {
+				nonce := [32]byte{byte(g), byte(i >> 8), byte(i)}
+				if ns.CheckAndStore(nonce, base+clock.Add(1)) {
+					accepted[g] = append(accepted[g], nonce)
+				}
+				if size := ns.Size(); size > maxSize {
+					t.Errorf("store grew to %d entries", size)
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	ns.mu.RLock()
+	defer ns.mu.RUnlock()
+	total := 0
+	for g, nonces := range accepted {
+		total += len(nonces)
+		// Each worker inserts in order, so FIFO eviction leaves a suffix of
+		// its accepted nonces.
+		evictedSeen := false
+		for i := len(nonces) - 1; i >= 0; i-- {
+			_, stored := ns.nonces[nonces[i]]
+			if stored && evictedSeen {
+				t.Fatalf("worker %d: nonce %d kept after a newer nonce was evicted", g, i)
+			}
+			evictedSeen = evictedSeen || !stored
+		}
+	}
+	assert.Greater(t, total, maxSize, "test should insert enough nonces to evict")
+	assert.LessOrEqual(t, len(ns.nonces), maxSize)
+}
+
+func TestNonceStoreEvictionCompactsExpiredEntries(t *testing.T) {
+	fixedTime := time.Date(2024, 6, 15, 12, 0, 0, 0, time.UTC)
+	mock := &MockTimeProvider{currentTime: fixedTime}
+	ns, err := NewNonceStoreWithOptions(t.TempDir(), NonceStoreOptions{MaxSize: 50, TimeProvider: mock})
+	require.NoError(t, err)
+	defer ns.Close()
+
+	for i := 0; i < 20; i++ {
+		require.True(t, ns.CheckAndStore([32]byte{byte(i)}, fixedTime.Unix()))
+	}
+	mock.Advance(7 * time.Minute)
+	ns.cleanup()
+
+	assert.Equal(t, 0, ns.Size())
+	ns.mu.RLock()
+	assert.Empty(t, ns.buckets, "expired nonces should leave the eviction queue")
+	ns.mu.RUnlock()
+}
+
+type recordedObservation struct {
+	name  string
+	value float64
+}
+
+type testMetricsRecorder struct {
+	observations chan recordedObservation
+}
+
+func (r *testMetricsRecorder) ObserveHistogram(name string, value float64) {
+	select {
+	case r.observations <- recordedObservation{name, value}:
+	default:
+	}
+}
+
+func TestNonceStoreRecordsSizeMetric(t *testing.T) {
+	recorder := &testMetricsRecorder{observations: make(chan recordedObservation, 16)}
+	ns, err := NewNonceStoreWithOptions(t.TempDir(), NonceStoreOptions{
+		Metrics:         recorder,
+		MetricsInterval: 10 * time.Millisecond,
+	})
+	require.NoError(t, err)
+	defer ns.Close()
+
+	require.True(t, ns.CheckAndStore([32]byte{1}, time.Now().Unix()))
+	require.True(t, ns.CheckAndStore([32]byte{2}, time.Now().Unix()))
+
+	deadline := time.After(2 * time.Second)
+	for {
+		select {
+		case obs := <-recorder.observations:
+			assert.Equal(t, NonceStoreSizeMetric, obs.name)
+			if obs.value == 2 {
+				return
+			}
+		case <-deadline:
+			t.Fatal("timed out waiting for nonce store size observation")
+		}
+	}
+}
+
+func TestNonceStoreOptionsValidation(t *testing.T) {
+	_, err := NewNonceStoreWithOptions(t.TempDir(), NonceStoreOptions{MaxSize: -1})
+	assert.Error(t, err)
+}
@@ -0,0 +1,31 @@
+// Package shamirshare splits a Tox secret key into shares for backup using
+// Shamir's secret sharing over GF(2^8).
+//
+// A key split into n shares with threshold k can be recovered from any k of
+// them, while k-1 shares reveal nothing about it. Shares can be handed to
+// trusted friends or kept in separate places, so losing the device that
+// held the key no longer means losing the Tox identity:
+//
+//	shares, err := shamirshare.SplitKey(keyPair.Private, 5, 3)
+//	if err != nil {
+//	    return err
+//	}
+//	for _, share := range shares {
+//	    printBackup(share.Marshal()) // 34 bytes, small enough for a QR code
+//	}
+//	shamirshare.WipeShares(shares)
+//
+// and later, with any three of them:
+//
+//	share, err := shamirshare.UnmarshalShare(scanned)
+//	...
+//	secretKey, err := shamirshare.RecoverKey(collected)
+//	keyPair, err := crypto.FromSecretKey(secretKey)
+//
+// Shares do not record the threshold. RecoverKey given fewer than k shares
+// returns a wrong key rather than an error, so callers should check the
+// recovered key, for example against the user's known Tox ID.
+//
+// Field multiplication is table-free and branch-free, and the random
+// polynomial coefficients are wiped after splitting.
+package shamirshare
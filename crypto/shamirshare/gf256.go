@@ -0,0 +1,47 @@
+package shamirshare
+
+// Arithmetic in GF(2^8) with the AES reduction polynomial
+// x^8 + x^4 + x^3 + x + 1. Addition and subtraction are both XOR.
+// Multiplication avoids lookup tables and branches on secret data so its
+// timing does not depend on the operands.
+
+// gfMul returns a*b in GF(2^8).
+func gfMul(a, b byte) byte {
+	var product byte
+	for i := 0; i < 8; i++ {
+		// Add a when the low bit of b is set, without branching
+		product ^= a & -(b & 1)
+		b >>= 1
+		// Multiply a by x, reducing when the high bit overflows
+		a = (a << 1) ^ (0x1b & -(a >> 7))
+	}
+	return product
+}
+
+// gfInv returns the multiplicative inverse of a, computed as a^254. The
+// inverse of 0 is returned as 0; callers never divide by zero.
+func gfInv(a byte) byte {
+	// a^254 = a^(2+4+8+16+32+64+128)
+	result := byte(1)
+	square := a
+	for i := 0; i < 7; i++ {
+		square = gfMul(square, square)
+		result = gfMul(result, square)
+	}
+	return result
+}
+
+// gfDiv returns a/b in GF(2^8).
+func gfDiv(a, b byte) byte {
+	return gfMul(a, gfInv(b))
+}
+
+// evalPolynomial evaluates the polynomial with the given coefficients,
+// lowest degree first, at x using Horner's method.
+func evalPolynomial(coefficients []byte, x byte) byte {
+	var y byte
+	for i := len(coefficients) - 1; i >= 0; i-- {
+		y = gfMul(y, x) ^ coefficients[i]
+	}
+	return y
+}
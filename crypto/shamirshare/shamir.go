@@ -0,0 +1,142 @@
+package shamirshare
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+
+	"github.com/opd-ai/toxcore/crypto"
+)
+
+const (
+	// KeySize is the size of a secret key and of each share value.
+	KeySize = 32
+
+	// MaxShares is the largest number of shares a key can be split into.
+	// Share indices are the non-zero elements of GF(2^8).
+	MaxShares = 255
+
+	// ShareSize is the size of a serialized share.
+	ShareSize = 2 + KeySize
+
+	// shareVersion is the first byte of a serialized share.
+	shareVersion byte = 1
+)
+
+var (
+	// ErrInvalidThreshold is returned when n and k do not satisfy 2 <= k <= n <= MaxShares.
+	ErrInvalidThreshold = errors.New("shamirshare: invalid share count or threshold")
+	// ErrNoShares is returned when RecoverKey is called without shares.
+	ErrNoShares = errors.New("shamirshare: no shares provided")
+	// ErrInvalidShare is returned for shares with a zero or repeated index,
+	// or serialized shares that cannot be parsed.
+	ErrInvalidShare = errors.New("shamirshare: invalid share")
+)
+
+// Share is one piece of a split key: the value of the sharing polynomials
+// at Index for each byte of the key.
+type Share struct {
+	Index byte
+	Value [KeySize]byte
+}
+
+// SplitKey splits secretKey into n shares, any k of which recover it with
+// RecoverKey. Fewer than k shares reveal nothing about the key.
+//
+// Each byte of the key is the constant term of its own random polynomial
+// of degree k-1 over GF(2^8), and share i holds every polynomial evaluated
+// at i. The random coefficients are wiped before returning.
+func SplitKey(secretKey [KeySize]byte, n, k int) ([]Share, error) {
+	if k < 2 || k > n || n > MaxShares {
+		return nil, fmt.Errorf("%w: n=%d k=%d", ErrInvalidThreshold, n, k)
+	}
+
+	// coefficients[i*k : (i+1)*k] is the polynomial for key byte i
+	coefficients := make([]byte, KeySize*k)
+	defer crypto.ZeroBytes(coefficients)
+	if _, err := rand.Read(coefficients); err != nil {
+		return nil, fmt.Errorf("shamirshare: failed to generate coefficients: %w", err)
+	}
+	for i := 0; i < KeySize; i++ {
+		coefficients[i*k] = secretKey[i]
+	}
+
+	shares := make([]Share, n)
+	for j := range shares {
+		x := byte(j + 1)
+		shares[j].Index = x
+		for i := 0; i < KeySize; i++ {
+			shares[j].Value[i] = evalPolynomial(coefficients[i*k:(i+1)*k], x)
+		}
+	}
+	return shares, nil
+}
+
+// RecoverKey reconstructs the key from shares by Lagrange interpolation at
+// zero. It needs at least as many shares as the threshold used in SplitKey;
+// with fewer, it returns an unrelated key rather than an error, since the
+// shares do not record the threshold.
+func RecoverKey(shares []Share) ([KeySize]byte, error) {
+	var secretKey [KeySize]byte
+	if len(shares) == 0 {
+		return secretKey, ErrNoShares
+	}
+	var seen [MaxShares + 1]bool
+	for _, share := range shares {
+		if share.Index == 0 || seen[share.Index] {
+			return secretKey, fmt.Errorf("%w: index %d is zero or repeated", ErrInvalidShare, share.Index)
+		}
+		seen[share.Index] = true
+	}
+
+	for j, share := range shares {
+		// Lagrange basis polynomial for this share evaluated at zero:
+		// the product of x_m / (x_m - x_j) over the other shares.
+		basis := byte(1)
+		for m, other := range shares {
+			if m != j {
+				basis = gfMul(basis, gfDiv(other.Index, other.Index^share.Index))
+			}
+		}
+		for i := 0; i < KeySize; i++ {
+			secretKey[i] ^= gfMul(basis, share.Value[i])
+		}
+	}
+	return secretKey, nil
+}
+
+// WipeShares securely erases the values of shares once they have been
+// written out or used for recovery.
+func WipeShares(shares []Share) {
+	for i := range shares {
+		crypto.ZeroBytes(shares[i].Value[:])
+		shares[i].Index = 0
+	}
+}
+
+// Marshal encodes the share as [version:1][index:1][value:32] for printed
+// or QR-code backups.
+func (s Share) Marshal() []byte {
+	data := make([]byte, ShareSize)
+	data[0] = shareVersion
+	data[1] = s.Index
+	copy(data[2:], s.Value[:])
+	return data
+}
+
+// UnmarshalShare decodes a share produced by Share.Marshal.
+func UnmarshalShare(data []byte) (Share, error) {
+	var share Share
+	if len(data) != ShareSize {
+		return share, fmt.Errorf("%w: got %d bytes, want %d", ErrInvalidShare, len(data), ShareSize)
+	}
+	if data[0] != shareVersion {
+		return share, fmt.Errorf("%w: unsupported version %d", ErrInvalidShare, data[0])
+	}
+	if data[1] == 0 {
+		return share, fmt.Errorf("%w: index 0", ErrInvalidShare)
+	}
+	share.Index = data[1]
+	copy(share.Value[:], data[2:])
+	return share, nil
+}
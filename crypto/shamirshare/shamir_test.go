@@ -0,0 +1,171 @@
+package shamirshare
+
+import (
+	"crypto/rand"
+	"errors"
+	"testing"
+)
+
+func randomKey(t testing.TB) [KeySize]byte {
+	t.Helper()
+	var key [KeySize]byte
+	if _, err := rand.Read(key[:]); err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func TestGFArithmetic(t *testing.T) {
+	// Worked example from FIPS-197 section 4.2
+	if got := gfMul(0x57, 0x83); got != 0xc1 {
+		t.Errorf("gfMul(0x57, 0x83) = %#x, want 0xc1", got)
+	}
+	for a := 1; a < 256; a++ {
+		if got := gfMul(byte(a), gfInv(byte(a))); got != 1 {
+			t.Fatalf("a * a^-1 = %#x for a = %#x", got, a)
+		}
+		if got := gfDiv(gfMul(byte(a), 0x1d), 0x1d); got != byte(a) {
+			t.Fatalf("(a * 0x1d) / 0x1d = %#x for a = %#x", got, a)
+		}
+	}
+}
+
+func TestSplitAndRecoverAnyThresholdSubset(t *testing.T) {
+	key := randomKey(t)
+	shares, err := SplitKey(key, 5, 3)
+	if err != nil {
+		t.Fatalf("SplitKey failed: %v", err)
+	}
+	if len(shares) != 5 {
+		t.Fatalf("Expected 5 shares, got %d", len(shares))
+	}
+
+	for a := 0; a < 5; a++ {
+		for b := a + 1; b < 5; b++ {
+			for c := b + 1; c < 5; c++ {
+				recovered, err := RecoverKey([]Share{shares[c], shares[a], shares[b]})
+				if err != nil {
+					t.Fatalf("RecoverKey(%d,%d,%d) failed: %v", a, b, c, err)
+				}
+				if recovered != key {
+					t.Errorf("RecoverKey(%d,%d,%d) returned the wrong key", a, b, c)
+				}
+			}
+		}
+	}
+
+	all, err := RecoverKey(shares)
+	if err != nil || all != key {
+		t.Errorf("Expected all shares to recover the key, got err %v", err)
+	}
+	two, err := RecoverKey(shares[:2])
+	if err != nil {
+		t.Fatalf("RecoverKey with 2 shares failed: %v", err)
+	}
+	if two == key {
+		t.Error("Expected fewer than k shares not to recover the key")
+	}
+}
+
+func TestSplitKeyInvalidParameters(t *testing.T) {
+	key := randomKey(t)
+	for _, tt := range []struct{ n, k int }{{5, 1}, {5, 0}, {3, 4}, {256, 3}, {0, 0}} {
+		if _, err := SplitKey(key, tt.n, tt.k); !errors.Is(err, ErrInvalidThreshold) {
+			t.Errorf("SplitKey(n=%d, k=%d): expected ErrInvalidThreshold, got %v", tt.n, tt.k, err)
+		}
+	}
+	if shares, err := SplitKey(key, MaxShares, MaxShares); err != nil || len(shares) != MaxShares {
+		t.Errorf("Expected %d shares at the maximum, got %d (%v)", MaxShares, len(shares), err)
+	}
+}
+
+func TestRecoverKeyInvalidShares(t *testing.T) {
+	shares, err := SplitKey(randomKey(t), 3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := RecoverKey(nil); !errors.Is(err, ErrNoShares) {
+		t.Errorf("Expected ErrNoShares, got %v", err)
+	}
+	if _, err := RecoverKey([]Share{shares[0], shares[0]}); !errors.Is(err, ErrInvalidShare) {
+		t.Errorf("Expected ErrInvalidShare for a repeated index, got %v", err)
+	}
+	if _, err := RecoverKey([]Share{shares[0], {Index: 0}}); !errors.Is(err, ErrInvalidShare) {
+		t.Errorf("Expected ErrInvalidShare for index 0, got %v", err)
+	}
+}
+
+func TestShareMarshalRoundTrip(t *testing.T) {
+	key := randomKey(t)
+	shares, err := SplitKey(key, 4, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded []Share
+	for _, share := range shares[2:] {
+		data := share.Marshal()
+		if len(data) != ShareSize {
+			t.Fatalf("Expected %d byte share, got %d", ShareSize, len(data))
+		}
+		parsed, err := UnmarshalShare(data)
+		if err != nil {
+			t.Fatalf("UnmarshalShare failed: %v", err)
+		}
+		if parsed != share {
+			t.Errorf("Share %d did not round-trip", share.Index)
+		}
+		decoded = append(decoded, parsed)
+	}
+	if recovered, err := RecoverKey(decoded); err != nil || recovered != key {
+		t.Errorf("Expected decoded shares to recover the key, got err %v", err)
+	}
+
+	valid := shares[0].Marshal()
+	for name, data := range map[string][]byte{
+		"truncated":   valid[:ShareSize-1],
+		"bad version": append([]byte{shareVersion + 1}, valid[1:]...),
+		"zero index":  append([]byte{shareVersion, 0}, valid[2:]...),
+	} {
+		if _, err := UnmarshalShare(data); !errors.Is(err, ErrInvalidShare) {
+			t.Errorf("%s: expected ErrInvalidShare, got %v", name, err)
+		}
+	}
+}
+
+func TestWipeShares(t *testing.T) {
+	shares, err := SplitKey(randomKey(t), 3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	WipeShares(shares)
+	for _, share := range shares {
+		if share != (Share{}) {
+			t.Errorf("Expected share to be wiped, got %+v", share)
+		}
+	}
+}
+
+func BenchmarkSplitKey(b *testing.B) {
+	key := randomKey(b)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := SplitKey(key, 5, 3); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkRecoverKey(b *testing.B) {
+	shares, err := SplitKey(randomKey(b), 5, 3)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := RecoverKey(shares[:3]); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"crypto/rand"
 	"encoding/hex"
 	"errors"
+	"fmt"
+	"strings"
 )
 
 // ToxID represents a Tox identifier, consisting of a public key, nospam value, and checksum.
@@ -144,3 +146,37 @@ func (id *ToxID) calculateChecksum() {
 
 	id.Checksum = checksum
 }
+
+// ToxURIScheme is the URI scheme prefix used for Tox addresses in links and
+// QR codes, as in "tox:<76 hex characters>".
+const ToxURIScheme = "tox:"
+
+// ErrInvalidToxAddress indicates a string does not hold a valid Tox address.
+var ErrInvalidToxAddress = errors.New("invalid tox address")
+
+// HasToxURIScheme reports whether s, ignoring surrounding whitespace, starts
+// with the tox: URI scheme in any case.
+func HasToxURIScheme(s string) bool {
+	s = strings.TrimSpace(s)
+	return len(s) >= len(ToxURIScheme) && strings.EqualFold(s[:len(ToxURIScheme)], ToxURIScheme)
+}
+
+// ParseToxAddress parses a Tox address given either as 76 hex characters or
+// in the tox: URI form. Surrounding whitespace, the case of the hex digits
+// and the scheme, and a "//" after the scheme are accepted. The checksum is
+// verified. This is the canonical parser for Tox addresses in text form.
+func ParseToxAddress(s string) (*ToxID, error) {
+	value := strings.TrimSpace(s)
+	if HasToxURIScheme(value) {
+		value = strings.TrimPrefix(value[len(ToxURIScheme):], "//")
+	}
+
+	if len(value) != ToxIDHexLength {
+		return nil, fmt.Errorf("%w: expected %d hex characters, got %d", ErrInvalidToxAddress, ToxIDHexLength, len(value))
+	}
+	id, err := ToxIDFromString(strings.ToLower(value))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToxAddress, err)
+	}
+	return id, nil
+}
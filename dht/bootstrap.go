@@ -45,6 +45,15 @@ type BootstrapNode struct {
 	PublicKey [32]byte
 	LastUsed  time.Time
 	Success   bool
+
+	// Health-check state, updated by ForceHealthCheck
+	ConsecutiveFailures int
+	Unavailable         bool
+	LastHealthCheck     time.Time
+
+	// Bootstrap history, used to try the most reliable nodes first
+	Attempts  int
+	Successes int
 }
 
 // BootstrapManager handles the process of connecting to the Tox network.
@@ -92,6 +101,37 @@ type BootstrapManager struct {
 
 	// Packet handler dispatch table (initialized once)
 	packetHandlers map[transport.PacketType]packetHandler
+
+	// Bootstrap node health checking (initialized on first use)
+	healthOnce sync.Once
+	health     *bootstrapHealth
+
+	// DNS bootstrap domains, initialized lazily by getDNS
+	dnsOnce sync.Once
+	dns     *dnsBootstrap
+
+	// Onion relay state (initialized on first use)
+	onionOnce  sync.Once
+	onionRelay *onionRelay
+
+	// Bootstrap node weight persistence (see SetWeightsFile)
+	weightsFile  string
+	savedWeights map[[32]byte]nodeHistory
+
+	// Nodes restored from a saved routing table, pinged on the next
+	// Bootstrap (see NewBootstrapManagerWithSavedRoutingTable)
+	restoredNodes []*Node
+
+	// Parallel FIND_NODE lookup run by Bootstrap (see SetLookupAlpha)
+	lookupAlpha     int
+	findNodeTimeout time.Duration
+	findNodeMu      sync.Mutex
+	findNodePending map[[32]byte][]chan []*Node
+
+	// Migrated node addresses awaiting a ping round trip (initialized on
+	// first use)
+	migrationOnce sync.Once
+	migration     *migrationProbes
 }
 
 // initBootstrapManagerCommon performs common initialization after creating a BootstrapManager.
@@ -112,6 +152,10 @@ func (bm *BootstrapManager) initBootstrapManagerCommon() {
 
 	// Initialize packet handler dispatch table once
 	bm.packetHandlers = bm.buildPacketHandlers()
+
+	bm.lookupAlpha = Alpha
+	bm.findNodeTimeout = DefaultResponseTimeout
+	bm.findNodePending = make(map[[32]byte][]chan []*Node)
 }
 
 // NewBootstrapManager creates a new bootstrap manager without versioned handshake support.
@@ -255,7 +299,7 @@ func (bm *BootstrapManager) validateAndDecodePublicKey(publicKeyHex string, addr
 	var publicKey [32]byte
 
 	if len(publicKeyHex) != 64 {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":          "AddNode",
 			"address":           address.String(),
 			"public_key_length": len(publicKeyHex),
@@ -264,7 +308,7 @@ func (bm *BootstrapManager) validateAndDecodePublicKey(publicKeyHex string, addr
 		return publicKey, errors.New("invalid public key length")
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":   "AddNode",
 		"address":    address.String(),
 		"public_key": publicKeyHex[:16] + "...",
@@ -272,7 +316,7 @@ func (bm *BootstrapManager) validateAndDecodePublicKey(publicKeyHex string, addr
 
 	decoded, err := hex.DecodeString(publicKeyHex)
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "AddNode",
 			"address":  address.String(),
 			"error":    err.Error(),
@@ -281,7 +325,7 @@ func (bm *BootstrapManager) validateAndDecodePublicKey(publicKeyHex string, addr
 	}
 
 	if len(decoded) != 32 {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":       "AddNode",
 			"address":        address.String(),
 			"decoded_length": len(decoded),
@@ -298,7 +342,7 @@ func (bm *BootstrapManager) validateAndDecodePublicKey(publicKeyHex string, addr
 func (bm *BootstrapManager) updateExistingNode(address net.Addr, publicKey [32]byte) bool {
 	for _, node := range bm.nodes {
 		if node.Address.String() == address.String() {
-			logrus.WithFields(logrus.Fields{
+			pkgLog.WithFields(logrus.Fields{
 				"function": "AddNode",
 				"address":  address.String(),
 			}).Info("Updating existing bootstrap node")
@@ -311,14 +355,16 @@ func (bm *BootstrapManager) updateExistingNode(address net.Addr, publicKey [32]b
 
 // addNewNode appends a new bootstrap node to the list.
 func (bm *BootstrapManager) addNewNode(address net.Addr, publicKey [32]byte) {
-	bm.nodes = append(bm.nodes, &BootstrapNode{
+	bn := &BootstrapNode{
 		Address:   address,
 		PublicKey: publicKey,
 		LastUsed:  time.Time{},
 		Success:   false,
-	})
+	}
+	bm.applySavedWeight(bn)
+	bm.nodes = append(bm.nodes, bn)
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":    "AddNode",
 		"address":     address.String(),
 		"total_nodes": len(bm.nodes),
@@ -329,44 +375,58 @@ func (bm *BootstrapManager) addNewNode(address net.Addr, publicKey [32]byte) {
 //
 //export ToxDHTBootstrap
 func (bm *BootstrapManager) Bootstrap(ctx context.Context) error {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":    "Bootstrap",
 		"nodes_count": len(bm.nodes),
 	}).Info("Starting bootstrap process")
 
+	bm.pingRestoredNodes()
+	bm.resolveBootstrapDomains(ctx)
+
 	if err := bm.validateBootstrapRequest(); err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "Bootstrap",
 			"error":    err.Error(),
 		}).Error("Bootstrap validation failed")
+		bm.expireBootstrapDomains()
 		return err
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "Bootstrap",
 	}).Debug("Preparing bootstrap nodes")
 	nodes := bm.prepareBootstrapNodes()
 	resultChan := make(chan *BootstrapResult, len(nodes))
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":       "Bootstrap",
 		"prepared_nodes": len(nodes),
 	}).Debug("Launching bootstrap workers")
 	bm.launchBootstrapWorkers(nodes, resultChan)
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "Bootstrap",
 	}).Debug("Processing bootstrap results")
 	err := bm.processBootstrapResults(ctx, resultChan)
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "Bootstrap",
 			"error":    err.Error(),
 		}).Error("Bootstrap process failed")
+		bm.expireBootstrapDomains()
 		return err
 	}
 
-	logrus.WithFields(logrus.Fields{
+	bm.lookupSelf(ctx)
+
+	if err := bm.saveWeights(); err != nil {
+		pkgLog.WithFields(logrus.Fields{
+			"function": "Bootstrap",
+			"error":    err.Error(),
+		}).Warn("Failed to save bootstrap node weights")
+	}
+
+	pkgLog.WithFields(logrus.Fields{
 		"function": "Bootstrap",
 	}).Info("Bootstrap process completed successfully")
 
@@ -390,14 +450,23 @@ func (bm *BootstrapManager) validateBootstrapRequest() error {
 	return nil
 }
 
-// prepareBootstrapNodes creates a safe copy of bootstrap nodes for concurrent processing.
+// prepareBootstrapNodes creates a safe copy of bootstrap nodes for concurrent processing,
+// ordered by weight so the historically most reliable nodes are contacted first.
+// Nodes marked unavailable by health checks are skipped unless no healthy node remains.
 func (bm *BootstrapManager) prepareBootstrapNodes() []*BootstrapNode {
 	bm.mu.RLock()
 	defer bm.mu.RUnlock()
 
-	nodes := make([]*BootstrapNode, len(bm.nodes))
-	copy(nodes, bm.nodes)
-	return nodes
+	nodes := make([]*BootstrapNode, 0, len(bm.nodes))
+	for _, bn := range bm.nodes {
+		if !bn.Unavailable {
+			nodes = append(nodes, bn)
+		}
+	}
+	if len(nodes) == 0 {
+		nodes = append(nodes, bm.nodes...)
+	}
+	return orderByWeight(nodes)
 }
 
 // launchBootstrapWorkers starts goroutines to connect to each bootstrap node.
@@ -425,21 +494,23 @@ func (bm *BootstrapManager) connectToBootstrapNode(wg *sync.WaitGroup, bn *Boots
 		bm.sendBootstrapError(resultChan, bn, "node creation", err)
 		return
 	}
+	bm.recordBootstrapAttempt(bn)
 
 	// Attempt versioned handshake if supported and enabled
 	if bm.enableVersioned && bm.handshakeManager != nil {
 		if err := bm.attemptVersionedHandshake(bn, dhtNode); err != nil {
-			logrus.WithFields(logrus.Fields{
+			pkgLog.WithFields(logrus.Fields{
 				"function": "connectToBootstrapNode",
 				"address":  bn.Address.String(),
 				"error":    err.Error(),
 			}).Debug("Versioned handshake failed, falling back to legacy bootstrap")
 		} else {
-			logrus.WithFields(logrus.Fields{
+			pkgLog.WithFields(logrus.Fields{
 				"function": "connectToBootstrapNode",
 				"address":  bn.Address.String(),
 			}).Info("Versioned handshake successful")
 
+			bm.markBootstrapNodeSuccess(bn.PublicKey)
 			bm.updateNodeLastUsed(bn)
 			bm.sendBootstrapSuccess(resultChan, dhtNode)
 			return
@@ -485,7 +556,7 @@ func (bm *BootstrapManager) attemptVersionedHandshake(bn *BootstrapNode, dhtNode
 		return errors.New("handshake manager not initialized")
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "attemptVersionedHandshake",
 		"address":  bn.Address.String(),
 	}).Debug("Initiating versioned handshake")
@@ -497,7 +568,7 @@ func (bm *BootstrapManager) attemptVersionedHandshake(bn *BootstrapNode, dhtNode
 	}
 
 	// Log the negotiated protocol version
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":       "attemptVersionedHandshake",
 		"address":        bn.Address.String(),
 		"agreed_version": response.AgreedVersion,
@@ -506,7 +577,7 @@ func (bm *BootstrapManager) attemptVersionedHandshake(bn *BootstrapNode, dhtNode
 	// Additional setup based on negotiated protocol version
 	switch response.AgreedVersion {
 	case transport.ProtocolNoiseIK:
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "attemptVersionedHandshake",
 			"address":  bn.Address.String(),
 		}).Debug("Setting up Noise-IK secure channel")
@@ -514,7 +585,7 @@ func (bm *BootstrapManager) attemptVersionedHandshake(bn *BootstrapNode, dhtNode
 		// For now, we just log the successful negotiation
 
 	case transport.ProtocolLegacy:
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "attemptVersionedHandshake",
 			"address":  bn.Address.String(),
 		}).Debug("Using legacy protocol")
@@ -643,7 +714,7 @@ func (bm *BootstrapManager) trackPartialProgress(successful int) {
 	if successful > bm.lastSuccessful {
 		bm.attempts = 0
 		bm.lastSuccessful = successful
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":   "handleBootstrapCompletion",
 			"successful": successful,
 			"min_nodes":  bm.minNodes,
@@ -663,7 +734,7 @@ func (bm *BootstrapManager) tryGossipFallback() bool {
 	gossipErr := bm.attemptGossipBootstrap()
 
 	if gossipErr == nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "handleBootstrapCompletion",
 		}).Info("Gossip bootstrap fallback initiated")
 		return true
@@ -746,7 +817,7 @@ func (bm *BootstrapManager) SetVersionedHandshakeEnabled(enabled bool) {
 
 	bm.enableVersioned = enabled && bm.handshakeManager != nil
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "SetVersionedHandshakeEnabled",
 		"enabled":  bm.enableVersioned,
 	}).Info("Versioned handshake support updated")
@@ -799,7 +870,7 @@ func (bm *BootstrapManager) SetPeerProtocolVersion(peerAddr net.Addr, version tr
 	}
 	bm.peerVersions[key] = version
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "SetPeerProtocolVersion",
 		"peer":     key,
 		"version":  version,
@@ -837,7 +908,7 @@ func (bm *BootstrapManager) ClearPeerProtocolVersion(peerAddr net.Addr) {
 		}
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "ClearPeerProtocolVersion",
 		"peer":     key,
 	}).Debug("Cleared peer protocol version")
@@ -868,7 +939,7 @@ func (bm *BootstrapManager) ResetAddressTypeStats() {
 	defer bm.mu.Unlock()
 	bm.addressStats = &AddressTypeStats{}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "ResetAddressTypeStats",
 	}).Debug("Reset address type statistics")
 }
@@ -932,9 +1003,17 @@ func (bm *BootstrapManager) registerGroupPacketHandlers() {
 		return bm.HandleGroupPacket(packet, senderAddr)
 	})
 
-	logrus.WithFields(logrus.Fields{
+	// Register handlers for group name searches
+	bm.transport.RegisterHandler(transport.PacketGroupSearchQuery, func(packet *transport.Packet, senderAddr net.Addr) error {
+		return bm.HandleGroupPacket(packet, senderAddr)
+	})
+	bm.transport.RegisterHandler(transport.PacketGroupSearchResponse, func(packet *transport.Packet, senderAddr net.Addr) error {
+		return bm.HandleGroupPacket(packet, senderAddr)
+	})
+
+	pkgLog.WithFields(logrus.Fields{
 		"function":       "registerGroupPacketHandlers",
-		"handlers_count": 3,
+		"handlers_count": 5,
 	}).Debug("Registered group packet handlers")
 }
 
@@ -0,0 +1,238 @@
+package dht
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// DNSBootstrapCacheTTL is the longest time nodes resolved from a bootstrap
+// domain are reused before the domain is queried again. A lower TTL reported
+// by the resolver takes precedence.
+const DNSBootstrapCacheTTL = time.Hour
+
+// Resolver looks up the TXT records of a bootstrap domain. A TTL of zero
+// means the resolver does not know it, and DNSBootstrapCacheTTL applies.
+type Resolver interface {
+	LookupTXT(ctx context.Context, domain string) (records []string, ttl time.Duration, err error)
+}
+
+// netResolver adapts net.Resolver, which does not report TTLs, to Resolver.
+type netResolver struct {
+	resolver *net.Resolver
+}
+
+// LookupTXT resolves the TXT records of domain.
+func (r netResolver) LookupTXT(ctx context.Context, domain string) ([]string, time.Duration, error) {
+	records, err := r.resolver.LookupTXT(ctx, domain)
+	return records, 0, err
+}
+
+// dnsBootstrap holds the DNS bootstrap state of a BootstrapManager.
+type dnsBootstrap struct {
+	mu       sync.Mutex
+	domains  []string
+	resolver Resolver
+	cache    map[string]time.Time // domain -> when its nodes must be resolved again
+	disabled bool                 // Set in simulation mode, where no real network is reached
+}
+
+// getDNS returns the DNS bootstrap state, initializing it on first use.
+func (bm *BootstrapManager) getDNS() *dnsBootstrap {
+	bm.dnsOnce.Do(func() {
+		bm.dns = &dnsBootstrap{
+			resolver: netResolver{resolver: net.DefaultResolver},
+			cache:    make(map[string]time.Time),
+		}
+	})
+	return bm.dns
+}
+
+// AddBootstrapDomain registers a domain whose TXT records list bootstrap
+// nodes, one "host:port:pubkey" node per record. The domain is resolved by
+// the next Bootstrap and again after every failed attempt; in between, its
+// nodes are cached for DNSBootstrapCacheTTL or the record TTL if lower. When
+// every lookup fails, Bootstrap proceeds with the nodes added by AddNode.
+//
+//export ToxDHTBootstrapManagerAddBootstrapDomain
+func (bm *BootstrapManager) AddBootstrapDomain(domain string) error {
+	domain = strings.TrimSuffix(strings.TrimSpace(domain), ".")
+	if domain == "" {
+		return errors.New("bootstrap domain cannot be empty")
+	}
+
+	d := bm.getDNS()
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, existing := range d.domains {
+		if existing == domain {
+			return nil
+		}
+	}
+	d.domains = append(d.domains, domain)
+
+	pkgLog.WithFields(logrus.Fields{
+		"function": "AddBootstrapDomain",
+		"domain":   domain,
+	}).Info("Bootstrap domain added")
+	return nil
+}
+
+// SetResolver replaces the resolver used for bootstrap domains. Pass nil to
+// restore net.DefaultResolver. Cached results are discarded.
+func (bm *BootstrapManager) SetResolver(resolver Resolver) {
+	if resolver == nil {
+		resolver = netResolver{resolver: net.DefaultResolver}
+	}
+	d := bm.getDNS()
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.resolver = resolver
+	d.cache = make(map[string]time.Time)
+}
+
+// SetDNSBootstrapEnabled enables or disables resolving bootstrap domains.
+// It is disabled while packet delivery runs in simulation mode.
+func (bm *BootstrapManager) SetDNSBootstrapEnabled(enabled bool) {
+	d := bm.getDNS()
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.disabled = !enabled
+}
+
+// IsDNSBootstrapEnabled returns whether bootstrap domains are resolved.
+func (bm *BootstrapManager) IsDNSBootstrapEnabled() bool {
+	d := bm.getDNS()
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return !d.disabled
+}
+
+// resolveBootstrapDomains adds the nodes of every bootstrap domain whose
+// cache entry has expired. Lookup failures are logged and leave the other
+// nodes to Bootstrap.
+func (bm *BootstrapManager) resolveBootstrapDomains(ctx context.Context) {
+	d := bm.getDNS()
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.disabled || len(d.domains) == 0 {
+		return
+	}
+
+	bm.mu.RLock()
+	now := bm.getTimeProvider().Now()
+	bm.mu.RUnlock()
+
+	for _, domain := range d.domains {
+		if ctx.Err() != nil {
+			return
+		}
+		if expires, ok := d.cache[domain]; ok && now.Before(expires) {
+			continue
+		}
+
+		added, ttl, err := bm.resolveBootstrapDomain(ctx, d.resolver, domain)
+		if err != nil {
+			pkgLog.WithFields(logrus.Fields{
+				"function": "resolveBootstrapDomains",
+				"domain":   domain,
+				"error":    err.Error(),
+			}).Warn("DNS bootstrap lookup failed, using other bootstrap nodes")
+			continue
+		}
+		if ttl <= 0 || ttl > DNSBootstrapCacheTTL {
+			ttl = DNSBootstrapCacheTTL
+		}
+		d.cache[domain] = now.Add(ttl)
+
+		pkgLog.WithFields(logrus.Fields{
+			"function":    "resolveBootstrapDomains",
+			"domain":      domain,
+			"nodes_added": added,
+			"cache_ttl":   ttl,
+		}).Info("Resolved bootstrap nodes from DNS")
+	}
+}
+
+// resolveBootstrapDomain looks up domain and adds the nodes of its valid
+// records. Malformed records are skipped.
+func (bm *BootstrapManager) resolveBootstrapDomain(ctx context.Context, resolver Resolver, domain string) (int, time.Duration, error) {
+	records, ttl, err := resolver.LookupTXT(ctx, domain)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	added := 0
+	for _, record := range records {
+		addr, publicKeyHex, err := parseBootstrapTXTRecord(ctx, record)
+		if err == nil {
+			err = bm.AddNode(addr, publicKeyHex)
+		}
+		if err != nil {
+			pkgLog.WithFields(logrus.Fields{
+				"function": "resolveBootstrapDomain",
+				"domain":   domain,
+				"error":    err.Error(),
+			}).Warn("Skipping invalid bootstrap TXT record")
+			continue
+		}
+		added++
+	}
+	if added == 0 {
+		return 0, 0, fmt.Errorf("no valid bootstrap nodes in %d TXT records", len(records))
+	}
+	return added, ttl, nil
+}
+
+// expireBootstrapDomains forces every bootstrap domain to be resolved again
+// by the next Bootstrap.
+func (bm *BootstrapManager) expireBootstrapDomains() {
+	d := bm.getDNS()
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for domain := range d.cache {
+		delete(d.cache, domain)
+	}
+}
+
+// parseBootstrapTXTRecord parses a "host:port:pubkey" TXT record. The host
+// may be an IP address, a bracketed IPv6 address or a hostname, which is
+// resolved with net.DefaultResolver.
+func parseBootstrapTXTRecord(ctx context.Context, record string) (net.Addr, string, error) {
+	record = strings.TrimSpace(record)
+	keySep := strings.LastIndexByte(record, ':')
+	if keySep < 0 {
+		return nil, "", fmt.Errorf("malformed bootstrap record %q", record)
+	}
+	portSep := strings.LastIndexByte(record[:keySep], ':')
+	if portSep <= 0 {
+		return nil, "", fmt.Errorf("malformed bootstrap record %q", record)
+	}
+	host := strings.TrimSuffix(strings.TrimPrefix(record[:portSep], "["), "]")
+	publicKeyHex := record[keySep+1:]
+
+	port, err := strconv.ParseUint(record[portSep+1:keySep], 10, 16)
+	if err != nil || port == 0 {
+		return nil, "", fmt.Errorf("invalid port in bootstrap record %q", record)
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil {
+		ips, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to resolve bootstrap host %q: %w", host, err)
+		}
+		if len(ips) == 0 {
+			return nil, "", fmt.Errorf("no addresses for bootstrap host %q", host)
+		}
+		ip = ips[0].IP
+	}
+	return &net.UDPAddr{IP: ip, Port: int(port)}, publicKeyHex, nil
+}
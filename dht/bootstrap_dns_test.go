@@ -0,0 +1,232 @@
+package dht
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/opd-ai/toxcore/crypto"
+)
+
+// mockResolver returns fixed TXT records per domain and counts lookups.
+type mockResolver struct {
+	mu      sync.Mutex
+	records map[string][]string
+	ttl     time.Duration
+	err     error
+	lookups int
+}
+
+func (r *mockResolver) LookupTXT(ctx context.Context, domain string) ([]string, time.Duration, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.lookups++
+	if err := ctx.Err(); err != nil {
+		return nil, 0, err
+	}
+	if r.err != nil {
+		return nil, 0, r.err
+	}
+	return r.records[domain], r.ttl, nil
+}
+
+func (r *mockResolver) lookupCount() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.lookups
+}
+
+// newDNSTestManager creates a bootstrap manager resolving through resolver,
+// with a mock clock.
+func newDNSTestManager(t *testing.T, resolver Resolver) (*BootstrapManager, *mockTimeProvider) {
+	t.Helper()
+	mt := newMockTransport(newMockAddr("127.0.0.1:33445"))
+	selfID := crypto.ToxID{PublicKey: [32]byte{0xaa}}
+	bm, err := NewBootstrapManagerForTesting(selfID, mt, NewRoutingTable(selfID, 8), 1)
+	if err != nil {
+		t.Fatalf("NewBootstrapManagerForTesting failed: %v", err)
+	}
+	clock := &mockTimeProvider{current: time.Unix(1700000000, 0)}
+	bm.SetTimeProvider(clock)
+	bm.SetResolver(resolver)
+	return bm, clock
+}
+
+// nodeAddresses returns the addresses of the registered bootstrap nodes.
+func nodeAddresses(bm *BootstrapManager) []string {
+	var addrs []string
+	for _, node := range bm.GetNodes() {
+		addrs = append(addrs, node.Address.String())
+	}
+	return addrs
+}
+
+func TestParseBootstrapTXTRecord(t *testing.T) {
+	key := strings.Repeat("ab", 32)
+	tests := []struct {
+		record string
+		want   string
+		valid  bool
+	}{
+		{"192.0.2.1:33445:" + key, "192.0.2.1:33445", true},
+		{"[2001:db8::1]:33445:" + key, "[2001:db8::1]:33445", true},
+		{"192.0.2.1:" + key, "", false},
+		{"192.0.2.1:0:" + key, "", false},
+		{"192.0.2.1:99999:" + key, "", false},
+		{key, "", false},
+	}
+	for _, tt := range tests {
+		addr, pk, err := parseBootstrapTXTRecord(context.Background(), tt.record)
+		if !tt.valid {
+			if err == nil {
+				t.Errorf("%q: expected an error", tt.record)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: %v", tt.record, err)
+			continue
+		}
+		if addr.String() != tt.want || pk != key {
+			t.Errorf("%q: got %s %s", tt.record, addr, pk)
+		}
+	}
+}
+
+func TestResolveBootstrapDomainsAddsNodes(t *testing.T) {
+	resolver := &mockResolver{records: map[string][]string{
+		"nodes.example.org": {
+			"192.0.2.1:33445:" + strings.Repeat("01", 32),
+			"malformed",
+			"192.0.2.2:33445:" + strings.Repeat("02", 32),
+		},
+	}}
+	bm, _ := newDNSTestManager(t, resolver)
+
+	if err := bm.AddBootstrapDomain(""); err == nil {
+		t.Error("Expected an error for an empty domain")
+	}
+	if err := bm.AddBootstrapDomain("nodes.example.org."); err != nil {
+		t.Fatal(err)
+	}
+	if err := bm.AddBootstrapDomain("nodes.example.org"); err != nil {
+		t.Fatal(err)
+	}
+
+	bm.resolveBootstrapDomains(context.Background())
+	addrs := nodeAddresses(bm)
+	if len(addrs) != 2 || addrs[0] != "192.0.2.1:33445" || addrs[1] != "192.0.2.2:33445" {
+		t.Errorf("Unexpected bootstrap nodes: %v", addrs)
+	}
+	if resolver.lookupCount() != 1 {
+		t.Errorf("Expected one lookup for a duplicated domain, got %d", resolver.lookupCount())
+	}
+}
+
+func TestResolveBootstrapDomainsCache(t *testing.T) {
+	resolver := &mockResolver{records: map[string][]string{
+		"nodes.example.org": {"192.0.2.1:33445:" + strings.Repeat("01", 32)},
+	}}
+	bm, clock := newDNSTestManager(t, resolver)
+	if err := bm.AddBootstrapDomain("nodes.example.org"); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	// Without a TTL from the resolver, results are cached for an hour
+	bm.resolveBootstrapDomains(ctx)
+	clock.Advance(DNSBootstrapCacheTTL - time.Second)
+	bm.resolveBootstrapDomains(ctx)
+	if n := resolver.lookupCount(); n != 1 {
+		t.Fatalf("Expected a cached result, got %d lookups", n)
+	}
+	clock.Advance(time.Second)
+	bm.resolveBootstrapDomains(ctx)
+	if n := resolver.lookupCount(); n != 2 {
+		t.Fatalf("Expected the cache to expire after an hour, got %d lookups", n)
+	}
+
+	// A lower TTL takes precedence
+	resolver.ttl = 5 * time.Minute
+	clock.Advance(DNSBootstrapCacheTTL)
+	bm.resolveBootstrapDomains(ctx)
+	clock.Advance(5 * time.Minute)
+	bm.resolveBootstrapDomains(ctx)
+	if n := resolver.lookupCount(); n != 4 {
+		t.Fatalf("Expected the record TTL to apply, got %d lookups", n)
+	}
+
+	// A failed bootstrap forces a new lookup
+	bm.expireBootstrapDomains()
+	bm.resolveBootstrapDomains(ctx)
+	if n := resolver.lookupCount(); n != 5 {
+		t.Fatalf("Expected a lookup after a failed bootstrap, got %d lookups", n)
+	}
+}
+
+func TestBootstrapFallsBackWhenDNSFails(t *testing.T) {
+	resolver := &mockResolver{err: errors.New("SERVFAIL")}
+	bm, _ := newDNSTestManager(t, resolver)
+	if err := bm.AddBootstrapDomain("nodes.example.org"); err != nil {
+		t.Fatal(err)
+	}
+
+	// With no static nodes the failure surfaces as an empty node list
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := bm.Bootstrap(ctx); err == nil || !strings.Contains(err.Error(), "no bootstrap nodes") {
+		t.Fatalf("Expected no bootstrap nodes, got %v", err)
+	}
+
+	// Static nodes are still used, and the domain is retried on the next attempt
+	if err := bm.AddNode(newMockAddr("10.0.0.1:33445"), strings.Repeat("01", 32)); err != nil {
+		t.Fatal(err)
+	}
+	before := resolver.lookupCount()
+	bm.resolveBootstrapDomains(ctx)
+	if resolver.lookupCount() != before+1 {
+		t.Error("Expected a failed lookup to be retried")
+	}
+	if addrs := nodeAddresses(bm); len(addrs) != 1 || addrs[0] != "10.0.0.1:33445" {
+		t.Errorf("Unexpected bootstrap nodes: %v", addrs)
+	}
+}
+
+func TestDNSBootstrapDisabled(t *testing.T) {
+	resolver := &mockResolver{records: map[string][]string{
+		"nodes.example.org": {"192.0.2.1:33445:" + strings.Repeat("01", 32)},
+	}}
+	bm, _ := newDNSTestManager(t, resolver)
+	if !bm.IsDNSBootstrapEnabled() {
+		t.Fatal("DNS bootstrap should be enabled by default")
+	}
+	if err := bm.AddBootstrapDomain("nodes.example.org"); err != nil {
+		t.Fatal(err)
+	}
+
+	bm.SetDNSBootstrapEnabled(false)
+	bm.resolveBootstrapDomains(context.Background())
+	if resolver.lookupCount() != 0 || len(bm.GetNodes()) != 0 {
+		t.Error("Disabled DNS bootstrap must not resolve domains")
+	}
+}
+
+func TestResolveBootstrapDomainsContextCancelled(t *testing.T) {
+	resolver := &mockResolver{records: map[string][]string{
+		"nodes.example.org": {"192.0.2.1:33445:" + strings.Repeat("01", 32)},
+	}}
+	bm, _ := newDNSTestManager(t, resolver)
+	if err := bm.AddBootstrapDomain("nodes.example.org"); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	bm.resolveBootstrapDomains(ctx)
+	if len(bm.GetNodes()) != 0 {
+		t.Error("Cancelled context must not add nodes")
+	}
+}
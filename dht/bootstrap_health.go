@@ -0,0 +1,292 @@
+package dht
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/opd-ai/toxcore/transport"
+	"github.com/sirupsen/logrus"
+)
+
+// BootstrapHealthConfig configures periodic health checking of bootstrap nodes.
+type BootstrapHealthConfig struct {
+	// How often every registered bootstrap node is pinged
+	Interval time.Duration
+	// How long to wait for a ping response before counting a timeout
+	Timeout time.Duration
+	// Consecutive timeouts before a node is marked unavailable
+	MaxConsecutiveFailures int
+}
+
+// DefaultBootstrapHealthConfig returns sensible defaults for bootstrap health checks.
+func DefaultBootstrapHealthConfig() *BootstrapHealthConfig {
+	return &BootstrapHealthConfig{
+		Interval:               5 * time.Minute,
+		Timeout:                5 * time.Second,
+		MaxConsecutiveFailures: 3,
+	}
+}
+
+// healthNonceSize is the size of the nonce appended to health-check pings.
+// Only a response echoing it counts as an answer.
+const healthNonceSize = 16
+
+// healthPing is a health-check ping awaiting its echo.
+type healthPing struct {
+	nonce    [healthNonceSize]byte
+	response chan struct{}
+}
+
+// NodeHealthCallback is called when a bootstrap node changes between healthy and unavailable.
+type NodeHealthCallback func(publicKey [32]byte, healthy bool)
+
+// bootstrapHealth holds the health-check state of a BootstrapManager.
+type bootstrapHealth struct {
+	mu       sync.Mutex
+	config   *BootstrapHealthConfig
+	callback NodeHealthCallback
+	pending  map[string]*healthPing // address -> ping awaiting a response
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+}
+
+// getHealth returns the health-check state, initializing it on first use.
+func (bm *BootstrapManager) getHealth() *bootstrapHealth {
+	bm.healthOnce.Do(func() {
+		bm.health = &bootstrapHealth{
+			config:  DefaultBootstrapHealthConfig(),
+			pending: make(map[string]*healthPing),
+		}
+	})
+	return bm.health
+}
+
+// SetHealthCheckConfig replaces the bootstrap health-check configuration.
+// A nil config restores DefaultBootstrapHealthConfig. Takes effect on the next cycle.
+func (bm *BootstrapManager) SetHealthCheckConfig(config *BootstrapHealthConfig) {
+	if config == nil {
+		config = DefaultBootstrapHealthConfig()
+	}
+	h := bm.getHealth()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	cfg := *config
+	defaults := DefaultBootstrapHealthConfig()
+	if cfg.Interval <= 0 {
+		cfg.Interval = defaults.Interval
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaults.Timeout
+	}
+	if cfg.MaxConsecutiveFailures < 1 {
+		cfg.MaxConsecutiveFailures = 1
+	}
+	h.config = &cfg
+}
+
+// OnNodeHealthChange sets the callback invoked when a bootstrap node is marked
+// unavailable or recovers. The callback runs on the health-check goroutine.
+func (bm *BootstrapManager) OnNodeHealthChange(callback NodeHealthCallback) {
+	h := bm.getHealth()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.callback = callback
+}
+
+// StartHealthChecks begins periodic health checks of all registered bootstrap nodes.
+// Calling it while checks are already running has no effect.
+func (bm *BootstrapManager) StartHealthChecks() error {
+	if bm.transport == nil {
+		return errors.New("health checks require a transport")
+	}
+	h := bm.getHealth()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.cancel != nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	h.cancel = cancel
+	interval := h.config.Interval
+	h.wg.Add(1)
+	go bm.healthCheckRoutine(ctx, interval)
+	return nil
+}
+
+// StopHealthChecks stops periodic health checks and waits for the current cycle to finish.
+func (bm *BootstrapManager) StopHealthChecks() {
+	h := bm.getHealth()
+	h.mu.Lock()
+	cancel := h.cancel
+	h.cancel = nil
+	h.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+		h.wg.Wait()
+	}
+}
+
+// healthCheckRoutine runs health-check cycles until ctx is cancelled.
+func (bm *BootstrapManager) healthCheckRoutine(ctx context.Context, interval time.Duration) {
+	defer bm.getHealth().wg.Done()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := bm.ForceHealthCheck(ctx); err != nil && ctx.Err() == nil {
+				pkgLog.WithError(err).Debug("dht: bootstrap health check cycle failed")
+			}
+		}
+	}
+}
+
+// ForceHealthCheck immediately pings every registered bootstrap node and waits
+// for the results. Nodes that time out MaxConsecutiveFailures times in a row are
+// marked unavailable; unavailable nodes that respond are restored.
+func (bm *BootstrapManager) ForceHealthCheck(ctx context.Context) error {
+	if bm.transport == nil {
+		return errors.New("health checks require a transport")
+	}
+	nodes := bm.GetNodes()
+	if len(nodes) == 0 {
+		return errors.New("no bootstrap nodes available")
+	}
+
+	h := bm.getHealth()
+	h.mu.Lock()
+	timeout := h.config.Timeout
+	h.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, bn := range nodes {
+		wg.Add(1)
+		go func(bn *BootstrapNode) {
+			defer wg.Done()
+			healthy := bm.pingBootstrapNode(ctx, bn, timeout)
+			if ctx.Err() == nil {
+				bm.recordHealthResult(bn, healthy)
+			}
+		}(bn)
+	}
+	wg.Wait()
+	return ctx.Err()
+}
+
+// pingBootstrapNode sends a PING request and reports whether a response arrived in time.
+func (bm *BootstrapManager) pingBootstrapNode(ctx context.Context, bn *BootstrapNode, timeout time.Duration) bool {
+	if bn.Address == nil {
+		return false
+	}
+	key := bn.Address.String()
+	ping := &healthPing{response: make(chan struct{}, 1)}
+	if _, err := rand.Read(ping.nonce[:]); err != nil {
+		pkgLog.WithError(err).Debug("dht: skipping bootstrap health ping: crypto/rand failed")
+		return false
+	}
+
+	h := bm.getHealth()
+	h.mu.Lock()
+	h.pending[key] = ping
+	h.mu.Unlock()
+	defer func() {
+		h.mu.Lock()
+		if h.pending[key] == ping {
+			delete(h.pending, key)
+		}
+		h.mu.Unlock()
+	}()
+
+	packet := &transport.Packet{
+		PacketType: transport.PacketPingRequest,
+		Data:       append(createPingPacket(bm.selfID.PublicKey), ping.nonce[:]...),
+	}
+	if err := bm.transport.Send(packet, bn.Address); err != nil {
+		pkgLog.WithFields(logrus.Fields{
+			"function": "pingBootstrapNode",
+			"address":  key,
+			"error":    err.Error(),
+		}).Debug("Bootstrap health ping send failed")
+		return false
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-ping.response:
+		return true
+	case <-timer.C:
+		return false
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// confirmHealthPing signals the health check waiting on addr if data echoes
+// the nonce of its ping, and reports whether it did. Responses that answer no
+// outstanding health ping, or come from another address, are ignored.
+func (bm *BootstrapManager) confirmHealthPing(data []byte, addr net.Addr) bool {
+	if addr == nil || len(data) != 32+healthNonceSize {
+		return false
+	}
+	h := bm.getHealth()
+	h.mu.Lock()
+	ping, ok := h.pending[addr.String()]
+	h.mu.Unlock()
+	if !ok || subtle.ConstantTimeCompare(data[32:], ping.nonce[:]) != 1 {
+		return false
+	}
+	select {
+	case ping.response <- struct{}{}:
+	default:
+	}
+	return true
+}
+
+// recordHealthResult updates a node's failure count and availability and
+// fires the health callback when availability changes.
+func (bm *BootstrapManager) recordHealthResult(bn *BootstrapNode, healthy bool) {
+	h := bm.getHealth()
+	h.mu.Lock()
+	maxFailures := h.config.MaxConsecutiveFailures
+	callback := h.callback
+	h.mu.Unlock()
+
+	bm.mu.Lock()
+	bn.LastHealthCheck = bm.getTimeProvider().Now()
+	wasUnavailable := bn.Unavailable
+	if healthy {
+		bn.ConsecutiveFailures = 0
+		bn.Unavailable = false
+	} else {
+		bn.ConsecutiveFailures++
+		if bn.ConsecutiveFailures >= maxFailures {
+			bn.Unavailable = true
+		}
+	}
+	changed := wasUnavailable != bn.Unavailable
+	publicKey := bn.PublicKey
+	bm.mu.Unlock()
+
+	if !changed {
+		return
+	}
+	pkgLog.WithFields(logrus.Fields{
+		"function": "recordHealthResult",
+		"address":  bn.Address.String(),
+		"healthy":  healthy,
+	}).Info("Bootstrap node health changed")
+	if callback != nil {
+		callback(publicKey, healthy)
+	}
+}
@@ -0,0 +1,180 @@
+package dht
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/opd-ai/toxcore/crypto"
+	"github.com/opd-ai/toxcore/transport"
+)
+
+// newHealthTestManager creates a bootstrap manager with one responsive and one dead node.
+func newHealthTestManager(t *testing.T) (*BootstrapManager, net.Addr, net.Addr) {
+	t.Helper()
+	alive := newMockAddr("10.0.0.1:33445")
+	dead := newMockAddr("10.0.0.2:33445")
+
+	mt := newMockTransport(newMockAddr("127.0.0.1:33445"))
+	selfID := crypto.ToxID{PublicKey: [32]byte{0xaa}}
+	bm, err := NewBootstrapManagerForTesting(selfID, mt, NewRoutingTable(selfID, 8), 1)
+	if err != nil {
+		t.Fatalf("NewBootstrapManagerForTesting failed: %v", err)
+	}
+
+	mt.sendFunc = func(packet *transport.Packet, addr net.Addr) error {
+		if packet.PacketType == transport.PacketPingRequest && addr.String() == alive.String() {
+			go func() {
+				_ = bm.HandlePacket(&transport.Packet{
+					PacketType: transport.PacketPingResponse,
+					Data:       packet.Data,
+				}, alive)
+			}()
+		}
+		return nil
+	}
+
+	if err := bm.AddNode(alive, strings.Repeat("01", 32)); err != nil {
+		t.Fatal(err)
+	}
+	if err := bm.AddNode(dead, strings.Repeat("02", 32)); err != nil {
+		t.Fatal(err)
+	}
+	bm.SetHealthCheckConfig(&BootstrapHealthConfig{
+		Interval:               time.Hour,
+		Timeout:                50 * time.Millisecond,
+		MaxConsecutiveFailures: 2,
+	})
+	return bm, alive, dead
+}
+
+func findBootstrapNode(bm *BootstrapManager, addr net.Addr) *BootstrapNode {
+	for _, bn := range bm.GetNodes() {
+		if bn.Address.String() == addr.String() {
+			return bn
+		}
+	}
+	return nil
+}
+
+// TestForceHealthCheckMarksUnavailable verifies nodes are marked after N consecutive timeouts
+func TestForceHealthCheckMarksUnavailable(t *testing.T) {
+	bm, alive, dead := newHealthTestManager(t)
+
+	var mu sync.Mutex
+	var changes []bool
+	bm.OnNodeHealthChange(func(pk [32]byte, healthy bool) {
+		mu.Lock()
+		defer mu.Unlock()
+		changes = append(changes, healthy)
+	})
+
+	ctx := context.Background()
+	if err := bm.ForceHealthCheck(ctx); err != nil {
+		t.Fatalf("ForceHealthCheck failed: %v", err)
+	}
+	if findBootstrapNode(bm, dead).Unavailable {
+		t.Fatal("dead node should tolerate a single timeout")
+	}
+
+	if err := bm.ForceHealthCheck(ctx); err != nil {
+		t.Fatalf("ForceHealthCheck failed: %v", err)
+	}
+	if !findBootstrapNode(bm, dead).Unavailable {
+		t.Error("dead node should be unavailable after 2 timeouts")
+	}
+	if findBootstrapNode(bm, alive).Unavailable {
+		t.Error("responsive node should remain available")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(changes) != 1 || changes[0] {
+		t.Errorf("expected one unhealthy callback, got %v", changes)
+	}
+}
+
+// TestHealthCheckIgnoresUnsolicitedResponses verifies that only a response
+// echoing the health ping's nonce marks a node as answering
+func TestHealthCheckIgnoresUnsolicitedResponses(t *testing.T) {
+	bm, alive, dead := newHealthTestManager(t)
+	mt := bm.transport.(*MockTransport)
+	mt.sendFunc = func(packet *transport.Packet, addr net.Addr) error {
+		if packet.PacketType != transport.PacketPingRequest {
+			return nil
+		}
+		forged := append([]byte(nil), packet.Data...)
+		forged[len(forged)-1] ^= 0xff
+		go func() {
+			// A bare ping response, a wrong nonce, and the right nonce from another address
+			_ = bm.HandlePacket(&transport.Packet{PacketType: transport.PacketPingResponse, Data: packet.Data[:32]}, addr)
+			_ = bm.HandlePacket(&transport.Packet{PacketType: transport.PacketPingResponse, Data: forged}, addr)
+			if addr.String() == dead.String() {
+				_ = bm.HandlePacket(&transport.Packet{PacketType: transport.PacketPingResponse, Data: packet.Data}, alive)
+			}
+		}()
+		return nil
+	}
+
+	ctx := context.Background()
+	_ = bm.ForceHealthCheck(ctx)
+	_ = bm.ForceHealthCheck(ctx)
+	if !findBootstrapNode(bm, dead).Unavailable || !findBootstrapNode(bm, alive).Unavailable {
+		t.Error("unsolicited ping responses should not count as health check answers")
+	}
+}
+
+// TestPrepareBootstrapNodesSkipsUnavailable verifies unavailable nodes are excluded but retained
+func TestPrepareBootstrapNodesSkipsUnavailable(t *testing.T) {
+	bm, alive, _ := newHealthTestManager(t)
+	ctx := context.Background()
+	_ = bm.ForceHealthCheck(ctx)
+	_ = bm.ForceHealthCheck(ctx)
+
+	nodes := bm.prepareBootstrapNodes()
+	if len(nodes) != 1 || nodes[0].Address.String() != alive.String() {
+		t.Fatalf("expected only the healthy node, got %d nodes", len(nodes))
+	}
+	if len(bm.GetNodes()) != 2 {
+		t.Error("unavailable node should remain registered")
+	}
+
+	// With every node unavailable, all nodes are retried as a last resort
+	aliveNode := findBootstrapNode(bm, alive)
+	bm.mu.Lock()
+	aliveNode.Unavailable = true
+	bm.mu.Unlock()
+	if got := len(bm.prepareBootstrapNodes()); got != 2 {
+		t.Errorf("expected fallback to all nodes when none are healthy, got %d", got)
+	}
+}
+
+// TestForceHealthCheckContextCancelled verifies cancellation is reported without penalizing nodes
+func TestForceHealthCheckContextCancelled(t *testing.T) {
+	bm, _, dead := newHealthTestManager(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := bm.ForceHealthCheck(ctx); err == nil {
+		t.Fatal("expected context error")
+	}
+	if findBootstrapNode(bm, dead).ConsecutiveFailures != 0 {
+		t.Error("cancelled checks should not count as failures")
+	}
+}
+
+// TestStartStopHealthChecks verifies the periodic routine lifecycle
+func TestStartStopHealthChecks(t *testing.T) {
+	bm, _, _ := newHealthTestManager(t)
+	if err := bm.StartHealthChecks(); err != nil {
+		t.Fatalf("StartHealthChecks failed: %v", err)
+	}
+	if err := bm.StartHealthChecks(); err != nil {
+		t.Fatalf("second StartHealthChecks failed: %v", err)
+	}
+	bm.StopHealthChecks()
+	bm.StopHealthChecks()
+}
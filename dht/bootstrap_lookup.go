@@ -0,0 +1,263 @@
+package dht
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/opd-ai/toxcore/transport"
+	"github.com/sirupsen/logrus"
+)
+
+// SetLookupAlpha sets how many FIND_NODE queries the lookup run by Bootstrap
+// keeps in flight. Values below one select the Kademlia default, Alpha.
+// Maintainer applies MaintenanceConfig.Alpha through this method.
+func (bm *BootstrapManager) SetLookupAlpha(alpha int) {
+	if alpha < 1 {
+		alpha = Alpha
+	}
+	bm.mu.Lock()
+	defer bm.mu.Unlock()
+	bm.lookupAlpha = alpha
+}
+
+// LookupAlpha returns the number of parallel FIND_NODE queries used by
+// Bootstrap.
+func (bm *BootstrapManager) LookupAlpha() int {
+	bm.mu.RLock()
+	defer bm.mu.RUnlock()
+	return bm.lookupAlpha
+}
+
+// findNodeWave is a batch of FIND_NODE queries launched together.
+type findNodeWave struct {
+	number     int
+	started    time.Time
+	queried    int
+	pending    int
+	discovered int
+}
+
+// findNodeReply is the outcome of one FIND_NODE query.
+type findNodeReply struct {
+	wave  *findNodeWave
+	nodes []*Node
+	err   error
+}
+
+// findNodeLookup walks the DHT towards a target with at most alpha FIND_NODE
+// queries in flight. A slot freed by a fast node is refilled right away, so
+// the next wave starts without waiting for slower nodes of the current one.
+type findNodeLookup struct {
+	bm         *BootstrapManager
+	target     *Node
+	alpha      int
+	rpcTimeout time.Duration
+	candidates *nodeSet
+
+	visitedMu sync.Mutex
+	visited   map[[32]byte]struct{}
+}
+
+// lookupSelf runs a parallel FIND_NODE lookup for our own key, starting from
+// the routing table nodes closest to it, so a freshly bootstrapped table fills
+// with nodes beyond the bootstrap nodes' immediate neighbours. It returns when
+// no unqueried candidate is left or ctx is done.
+func (bm *BootstrapManager) lookupSelf(ctx context.Context) {
+	if bm.transport == nil || bm.routingTable == nil {
+		return
+	}
+	bm.mu.RLock()
+	alpha, rpcTimeout := bm.lookupAlpha, bm.findNodeTimeout
+	bm.mu.RUnlock()
+	if alpha < 1 {
+		alpha = Alpha
+	}
+	if rpcTimeout <= 0 {
+		rpcTimeout = DefaultResponseTimeout
+	}
+
+	target := &Node{ID: bm.selfID, PublicKey: bm.selfID.PublicKey}
+	l := &findNodeLookup{
+		bm:         bm,
+		target:     target,
+		alpha:      alpha,
+		rpcTimeout: rpcTimeout,
+		candidates: newNodeSet(target, DefaultLookupK*3),
+		visited:    map[[32]byte]struct{}{bm.selfID.PublicKey: {}},
+	}
+	l.addCandidates(bm.routingTable.FindClosestNodes(bm.selfID, DefaultLookupK))
+	l.run(ctx)
+}
+
+// run drives the lookup until it converges or ctx is done.
+func (l *findNodeLookup) run(ctx context.Context) {
+	started := l.bm.getTimeProvider().Now()
+	replies := make(chan *findNodeReply, l.alpha)
+	maxQueries := DefaultMaxLookupIterations * l.alpha
+	inFlight, queries, waves := 0, 0, 0
+
+	for {
+		if ctx.Err() == nil && queries < maxQueries {
+			batch := l.nextBatch(min(l.alpha-inFlight, maxQueries-queries))
+			if len(batch) > 0 {
+				waves++
+				l.launchWave(ctx, waves, batch, replies)
+				inFlight += len(batch)
+				queries += len(batch)
+			}
+		}
+		if inFlight == 0 {
+			break
+		}
+		// Every query answers within rpcTimeout, or sooner once ctx is done
+		l.handleReply(<-replies)
+		inFlight--
+	}
+
+	pkgLog.WithFields(logrus.Fields{
+		"function": "lookupSelf",
+		"alpha":    l.alpha,
+		"waves":    waves,
+		"queried":  queries,
+		"duration": l.bm.getTimeProvider().Now().Sub(started),
+	}).Debug("Parallel FIND_NODE lookup finished")
+}
+
+// nextBatch marks up to n of the closest unqueried candidates as visited and
+// returns them.
+func (l *findNodeLookup) nextBatch(n int) []*Node {
+	if n <= 0 {
+		return nil
+	}
+	l.visitedMu.Lock()
+	defer l.visitedMu.Unlock()
+
+	batch := l.candidates.selectUnqueried(n, l.visited)
+	for _, node := range batch {
+		l.visited[node.PublicKey] = struct{}{}
+	}
+	return batch
+}
+
+// addCandidates adds unvisited nodes with an address to the candidate set and
+// returns how many were new.
+func (l *findNodeLookup) addCandidates(nodes []*Node) int {
+	l.visitedMu.Lock()
+	defer l.visitedMu.Unlock()
+
+	added := 0
+	for _, node := range nodes {
+		if node == nil || node.Address == nil {
+			continue
+		}
+		if _, seen := l.visited[node.PublicKey]; seen {
+			continue
+		}
+		if l.candidates.add(node) {
+			added++
+		}
+	}
+	return added
+}
+
+// launchWave starts one goroutine per node in batch.
+func (l *findNodeLookup) launchWave(ctx context.Context, number int, batch []*Node, replies chan<- *findNodeReply) {
+	wave := &findNodeWave{
+		number:  number,
+		started: l.bm.getTimeProvider().Now(),
+		queried: len(batch),
+		pending: len(batch),
+	}
+	for _, node := range batch {
+		go func(n *Node) {
+			rpcCtx, cancel := context.WithTimeout(ctx, l.rpcTimeout)
+			defer cancel()
+			nodes, err := l.bm.findNode(rpcCtx, n, l.target.PublicKey)
+			replies <- &findNodeReply{wave: wave, nodes: nodes, err: err}
+		}(node)
+	}
+}
+
+// handleReply folds a query's result into the candidates and logs the wave
+// once its last query has returned.
+func (l *findNodeLookup) handleReply(reply *findNodeReply) {
+	wave := reply.wave
+	wave.pending--
+	if reply.err == nil {
+		wave.discovered += l.addCandidates(reply.nodes)
+	}
+	if wave.pending > 0 {
+		return
+	}
+
+	pkgLog.WithFields(logrus.Fields{
+		"function":   "lookupSelf",
+		"wave":       wave.number,
+		"queried":    wave.queried,
+		"discovered": wave.discovered,
+		"latency":    l.bm.getTimeProvider().Now().Sub(wave.started),
+	}).Debug("FIND_NODE wave completed")
+}
+
+// findNode sends a FIND_NODE request for target to node and waits for the
+// nodes it returns until ctx is done.
+func (bm *BootstrapManager) findNode(ctx context.Context, node *Node, target [32]byte) ([]*Node, error) {
+	replies := bm.registerFindNode(node.PublicKey)
+	defer bm.deregisterFindNode(node.PublicKey, replies)
+
+	packet := &transport.Packet{
+		PacketType: transport.PacketGetNodes,
+		Data:       bm.createGetNodesPacket(target),
+	}
+	if err := bm.transport.Send(packet, node.Address); err != nil {
+		return nil, err
+	}
+
+	select {
+	case nodes := <-replies:
+		return nodes, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// registerFindNode returns a channel that receives the next send_nodes
+// response from the node with publicKey.
+func (bm *BootstrapManager) registerFindNode(publicKey [32]byte) chan []*Node {
+	ch := make(chan []*Node, 1)
+	bm.findNodeMu.Lock()
+	defer bm.findNodeMu.Unlock()
+	if bm.findNodePending == nil {
+		bm.findNodePending = make(map[[32]byte][]chan []*Node)
+	}
+	bm.findNodePending[publicKey] = append(bm.findNodePending[publicKey], ch)
+	return ch
+}
+
+// deregisterFindNode removes a channel added by registerFindNode.
+func (bm *BootstrapManager) deregisterFindNode(publicKey [32]byte, ch chan []*Node) {
+	bm.findNodeMu.Lock()
+	defer bm.findNodeMu.Unlock()
+	pending := slices.DeleteFunc(bm.findNodePending[publicKey], func(c chan []*Node) bool { return c == ch })
+	if len(pending) == 0 {
+		delete(bm.findNodePending, publicKey)
+		return
+	}
+	bm.findNodePending[publicKey] = pending
+}
+
+// deliverFindNodeResponse hands the nodes from a send_nodes response to the
+// FIND_NODE queries waiting on its sender.
+func (bm *BootstrapManager) deliverFindNodeResponse(senderPK [32]byte, nodes []*Node) {
+	bm.findNodeMu.Lock()
+	defer bm.findNodeMu.Unlock()
+	for _, ch := range bm.findNodePending[senderPK] {
+		select {
+		case ch <- nodes:
+		default:
+			// Already answered
+		}
+	}
+}
@@ -0,0 +1,255 @@
+package dht
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/opd-ai/toxcore/crypto"
+	"github.com/opd-ai/toxcore/transport"
+)
+
+// lookupTestPeer is a simulated DHT node answering FIND_NODE queries.
+type lookupTestPeer struct {
+	node    *Node
+	delay   time.Duration
+	returns []*Node
+	silent  bool
+}
+
+// lookupTestNetwork answers the FIND_NODE queries a BootstrapManager sends
+// for its own key and records the order of queries and replies.
+type lookupTestNetwork struct {
+	bm    *BootstrapManager
+	peers map[string]*lookupTestPeer
+
+	mu          sync.Mutex
+	events      []string
+	queries     map[byte]int
+	inFlight    int
+	maxInFlight int
+}
+
+func newLookupTestNetwork() *lookupTestNetwork {
+	return &lookupTestNetwork{
+		peers:   make(map[string]*lookupTestPeer),
+		queries: make(map[byte]int),
+	}
+}
+
+// newLookupTestNode creates a node with a public key starting with b.
+func newLookupTestNode(b byte) *Node {
+	return NewNode(crypto.ToxID{PublicKey: [32]byte{b}}, newMockAddr(fmt.Sprintf("10.1.0.%d:33445", b)))
+}
+
+func (n *lookupTestNetwork) addPeer(peer *lookupTestPeer) {
+	n.peers[peer.node.Address.String()] = peer
+}
+
+func (n *lookupTestNetwork) send(packet *transport.Packet, addr net.Addr) error {
+	if packet.PacketType != transport.PacketGetNodes || len(packet.Data) < 64 || packet.Data[32] != 0xaa {
+		return nil // Not a lookup for our own key
+	}
+	peer, ok := n.peers[addr.String()]
+	if !ok {
+		return errors.New("unknown peer")
+	}
+
+	n.mu.Lock()
+	n.events = append(n.events, fmt.Sprintf("query %02x", peer.node.PublicKey[0]))
+	n.queries[peer.node.PublicKey[0]]++
+	if peer.silent {
+		n.mu.Unlock()
+		return nil
+	}
+	n.inFlight++
+	n.maxInFlight = max(n.maxInFlight, n.inFlight)
+	n.mu.Unlock()
+
+	go func() {
+		time.Sleep(peer.delay)
+		n.mu.Lock()
+		n.inFlight--
+		n.events = append(n.events, fmt.Sprintf("reply %02x", peer.node.PublicKey[0]))
+		n.mu.Unlock()
+		n.bm.deliverFindNodeResponse(peer.node.PublicKey, peer.returns)
+	}()
+	return nil
+}
+
+func (n *lookupTestNetwork) eventIndex(event string) int {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for i, e := range n.events {
+		if e == event {
+			return i
+		}
+	}
+	return -1
+}
+
+// newLookupTestManager creates a bootstrap manager whose transport is backed
+// by network and whose routing table holds seeds.
+func newLookupTestManager(t *testing.T, network *lookupTestNetwork, alpha int, seeds ...*Node) *BootstrapManager {
+	t.Helper()
+	mt := newMockTransport(newMockAddr("127.0.0.1:33445"))
+	mt.sendFunc = network.send
+	selfID := crypto.ToxID{PublicKey: [32]byte{0xaa}}
+	rt := NewRoutingTable(selfID, 8)
+	for _, node := range seeds {
+		rt.AddNode(node)
+	}
+	bm, err := NewBootstrapManagerForTesting(selfID, mt, rt, 1)
+	if err != nil {
+		t.Fatalf("NewBootstrapManagerForTesting failed: %v", err)
+	}
+	bm.SetLookupAlpha(alpha)
+	bm.findNodeTimeout = time.Second
+	network.bm = bm
+	return bm
+}
+
+func TestBootstrapRunsAlphaParallelLookup(t *testing.T) {
+	network := newLookupTestNetwork()
+	var seeds []*Node
+	for b := byte(0x10); b < 0x16; b++ {
+		node := newLookupTestNode(b)
+		network.addPeer(&lookupTestPeer{node: node, delay: 20 * time.Millisecond})
+		seeds = append(seeds, node)
+	}
+	bm := newLookupTestManager(t, network, 2, seeds...)
+
+	bootstrap := newLookupTestNode(0x01)
+	network.addPeer(&lookupTestPeer{node: bootstrap, delay: 20 * time.Millisecond})
+	if err := bm.AddNode(bootstrap.Address, "01"+strings.Repeat("00", 31)); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := bm.Bootstrap(ctx); err != nil {
+		t.Fatalf("Bootstrap failed: %v", err)
+	}
+
+	network.mu.Lock()
+	defer network.mu.Unlock()
+	if network.maxInFlight != 2 {
+		t.Errorf("Expected 2 queries in flight at most, got %d", network.maxInFlight)
+	}
+	if len(network.events) < 2 || !strings.HasPrefix(network.events[0], "query") || !strings.HasPrefix(network.events[1], "query") {
+		t.Errorf("Expected the first wave to send 2 queries before any reply, got %v", network.events)
+	}
+	if len(network.queries) != len(seeds)+1 {
+		t.Errorf("Expected all %d known nodes to be queried, got %d", len(seeds)+1, len(network.queries))
+	}
+}
+
+func TestLookupSchedulesNextWaveBeforeSlowNodes(t *testing.T) {
+	network := newLookupTestNetwork()
+	fast, slow, discovered := newLookupTestNode(0x01), newLookupTestNode(0x02), newLookupTestNode(0x03)
+	network.addPeer(&lookupTestPeer{node: fast, delay: 10 * time.Millisecond, returns: []*Node{discovered}})
+	network.addPeer(&lookupTestPeer{node: slow, delay: 300 * time.Millisecond})
+	network.addPeer(&lookupTestPeer{node: discovered, delay: 10 * time.Millisecond})
+	bm := newLookupTestManager(t, network, 2, fast, slow)
+
+	bm.lookupSelf(context.Background())
+
+	queried, slowReply := network.eventIndex("query 03"), network.eventIndex("reply 02")
+	if queried < 0 || slowReply < 0 || queried > slowReply {
+		t.Errorf("Expected the discovered node to be queried before the slow node replied, got %v", network.events)
+	}
+}
+
+func TestLookupQueriesEachNodeOnce(t *testing.T) {
+	network := newLookupTestNetwork()
+	a, b := newLookupTestNode(0x01), newLookupTestNode(0x02)
+	self := NewNode(crypto.ToxID{PublicKey: [32]byte{0xaa}}, newMockAddr("127.0.0.1:33445"))
+	network.addPeer(&lookupTestPeer{node: a, returns: []*Node{b, self}})
+	network.addPeer(&lookupTestPeer{node: b, returns: []*Node{a}})
+	bm := newLookupTestManager(t, network, 3, a)
+
+	bm.lookupSelf(context.Background())
+
+	network.mu.Lock()
+	defer network.mu.Unlock()
+	if network.queries[0x01] != 1 || network.queries[0x02] != 1 || len(network.queries) != 2 {
+		t.Errorf("Expected nodes 01 and 02 to be queried once each, got %v", network.queries)
+	}
+}
+
+func TestLookupStopsOnContextCancel(t *testing.T) {
+	network := newLookupTestNetwork()
+	silent := newLookupTestNode(0x01)
+	network.addPeer(&lookupTestPeer{node: silent, silent: true})
+	bm := newLookupTestManager(t, network, 3, silent)
+	bm.findNodeTimeout = 10 * time.Second
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	bm.lookupSelf(ctx)
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected the lookup to stop with its context, took %v", elapsed)
+	}
+
+	bm.findNodeMu.Lock()
+	defer bm.findNodeMu.Unlock()
+	if len(bm.findNodePending) != 0 {
+		t.Errorf("Expected no pending queries after the lookup, got %d", len(bm.findNodePending))
+	}
+}
+
+func TestSendNodesResponseReachesPendingQuery(t *testing.T) {
+	bm := newLookupTestManager(t, newLookupTestNetwork(), 3)
+	responder := [32]byte{0x01}
+	replies := bm.registerFindNode(responder)
+	defer bm.deregisterFindNode(responder, replies)
+
+	returned := NewNode(crypto.ToxID{PublicKey: [32]byte{0x05}}, &net.UDPAddr{IP: net.ParseIP("192.0.2.5"), Port: 33445})
+	data := bm.buildResponseData([]*Node{returned})
+	copy(data[:32], responder[:])
+	if err := bm.handleSendNodesPacket(&transport.Packet{PacketType: transport.PacketSendNodes, Data: data}, newMockAddr("10.1.0.1:33445")); err != nil {
+		t.Fatalf("handleSendNodesPacket failed: %v", err)
+	}
+
+	select {
+	case nodes := <-replies:
+		if len(nodes) != 1 || nodes[0].PublicKey != returned.PublicKey {
+			t.Errorf("Expected node 05 in the response, got %d nodes", len(nodes))
+		}
+	default:
+		t.Fatal("Expected the response to reach the pending query")
+	}
+}
+
+func TestMaintenanceConfigAlpha(t *testing.T) {
+	bm := newLookupTestManager(t, newLookupTestNetwork(), 0)
+	if bm.LookupAlpha() != Alpha {
+		t.Errorf("Expected default alpha %d, got %d", Alpha, bm.LookupAlpha())
+	}
+
+	cfg := *DefaultMaintenanceConfig()
+	cfg.Alpha = 5
+	m := NewMaintainer(bm.routingTable, bm, bm.transport, nil, &cfg)
+	if bm.LookupAlpha() != 5 {
+		t.Errorf("Expected NewMaintainer to apply alpha 5, got %d", bm.LookupAlpha())
+	}
+
+	cfg.Alpha = 0
+	if err := m.Reconfigure(cfg); err != nil {
+		t.Fatalf("Reconfigure failed: %v", err)
+	}
+	if bm.LookupAlpha() != Alpha {
+		t.Errorf("Expected alpha 0 to select the default, got %d", bm.LookupAlpha())
+	}
+
+	cfg.Alpha = -1
+	if err := cfg.Validate(); !errors.Is(err, ErrInvalidMaintenanceConfig) {
+		t.Errorf("Expected negative alpha to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,221 @@
+package dht
+
+import (
+	"container/heap"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Weight returns the node's bootstrap priority, its historical success rate
+// with add-one smoothing. A node that has never been tried weighs 0.5, so it
+// ranks below reliable nodes and above consistently failing ones.
+//
+// Callers must hold the BootstrapManager lock when the node is shared.
+func (bn *BootstrapNode) Weight() float64 {
+	return float64(bn.Successes+1) / float64(bn.Attempts+2)
+}
+
+// bootstrapQueueItem is an entry in a bootstrapQueue.
+type bootstrapQueueItem struct {
+	node   *BootstrapNode
+	weight float64
+	order  int // registration order, breaks ties between equal weights
+}
+
+// bootstrapQueue implements heap.Interface as a max-heap on node weight.
+type bootstrapQueue []bootstrapQueueItem
+
+// Len returns the number of elements in the queue.
+func (q bootstrapQueue) Len() int { return len(q) }
+
+// Less reports whether element i should be tried before element j.
+func (q bootstrapQueue) Less(i, j int) bool {
+	if q[i].weight != q[j].weight {
+		return q[i].weight > q[j].weight
+	}
+	return q[i].order < q[j].order
+}
+
+// Swap exchanges the elements at indices i and j.
+func (q bootstrapQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+// Push adds an element to the queue.
+func (q *bootstrapQueue) Push(x interface{}) {
+	item, ok := x.(bootstrapQueueItem)
+	if !ok {
+		return
+	}
+	*q = append(*q, item)
+}
+
+// Pop removes and returns the highest-weight element from the queue.
+func (q *bootstrapQueue) Pop() interface{} {
+	old := *q
+	n := len(old)
+	item := old[n-1]
+	*q = old[:n-1]
+	return item
+}
+
+// orderByWeight returns nodes ordered from highest to lowest weight, keeping
+// registration order among nodes of equal weight. Callers must hold bm.mu.
+func orderByWeight(nodes []*BootstrapNode) []*BootstrapNode {
+	q := make(bootstrapQueue, 0, len(nodes))
+	for i, bn := range nodes {
+		q = append(q, bootstrapQueueItem{node: bn, weight: bn.Weight(), order: i})
+	}
+	heap.Init(&q)
+
+	ordered := make([]*BootstrapNode, 0, len(nodes))
+	for q.Len() > 0 {
+		ordered = append(ordered, heap.Pop(&q).(bootstrapQueueItem).node)
+	}
+	return ordered
+}
+
+// recordBootstrapAttempt counts a bootstrap request sent to bn.
+func (bm *BootstrapManager) recordBootstrapAttempt(bn *BootstrapNode) {
+	bm.mu.Lock()
+	defer bm.mu.Unlock()
+	bn.Attempts++
+}
+
+// recordBootstrapSuccess counts a response from bn. Successes never exceed
+// attempts, so unsolicited responses do not inflate the weight.
+// Callers must hold bm.mu.
+func recordBootstrapSuccess(bn *BootstrapNode) {
+	if bn.Successes < bn.Attempts {
+		bn.Successes++
+	}
+}
+
+// bootstrapWeightsFile is the on-disk format of the bootstrap weights sidecar.
+type bootstrapWeightsFile struct {
+	Nodes []bootstrapWeightJSON `json:"nodes"`
+}
+
+// bootstrapWeightJSON is the JSON representation of one node's history.
+type bootstrapWeightJSON struct {
+	PublicKey string `json:"public_key"`
+	Attempts  int    `json:"attempts"`
+	Successes int    `json:"successes"`
+}
+
+// nodeHistory is a node's saved success history.
+type nodeHistory struct {
+	attempts  int
+	successes int
+}
+
+// SetWeightsFile sets the sidecar file where bootstrap node weights are kept
+// and loads any weights already saved there. Weights are saved after every
+// successful Bootstrap; loaded weights apply to matching nodes now and to
+// nodes added later. A missing file is not an error.
+func (bm *BootstrapManager) SetWeightsFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read bootstrap weights: %w", err)
+	}
+
+	saved := make(map[[32]byte]nodeHistory)
+	if err == nil {
+		var file bootstrapWeightsFile
+		if err := json.Unmarshal(data, &file); err != nil {
+			return fmt.Errorf("failed to parse bootstrap weights: %w", err)
+		}
+		for _, w := range file.Nodes {
+			decoded, err := hex.DecodeString(w.PublicKey)
+			if err != nil || len(decoded) != 32 || w.Attempts < 0 || w.Successes < 0 || w.Successes > w.Attempts {
+				continue
+			}
+			var pk [32]byte
+			copy(pk[:], decoded)
+			saved[pk] = nodeHistory{attempts: w.Attempts, successes: w.Successes}
+		}
+	}
+
+	bm.mu.Lock()
+	defer bm.mu.Unlock()
+	bm.weightsFile = path
+	bm.savedWeights = saved
+	for _, bn := range bm.nodes {
+		bm.applySavedWeight(bn)
+	}
+
+	pkgLog.WithFields(logrus.Fields{
+		"function":     "SetWeightsFile",
+		"path":         path,
+		"loaded_nodes": len(saved),
+	}).Info("Bootstrap weights file configured")
+	return nil
+}
+
+// applySavedWeight restores bn's history from loaded weights, if any.
+// Callers must hold bm.mu.
+func (bm *BootstrapManager) applySavedWeight(bn *BootstrapNode) {
+	if h, ok := bm.savedWeights[bn.PublicKey]; ok {
+		bn.Attempts = h.attempts
+		bn.Successes = h.successes
+	}
+}
+
+// saveWeights writes the weights of all nodes to the sidecar file, if one
+// is configured. The file is written atomically via a temporary file and
+// rename.
+func (bm *BootstrapManager) saveWeights() error {
+	bm.mu.RLock()
+	path := bm.weightsFile
+	file := bootstrapWeightsFile{Nodes: make([]bootstrapWeightJSON, 0, len(bm.nodes))}
+	for _, bn := range bm.nodes {
+		file.Nodes = append(file.Nodes, bootstrapWeightJSON{
+			PublicKey: hex.EncodeToString(bn.PublicKey[:]),
+			Attempts:  bn.Attempts,
+			Successes: bn.Successes,
+		})
+	}
+	bm.mu.RUnlock()
+
+	if path == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to serialize bootstrap weights: %w", err)
+	}
+
+	tmpFile := path + ".tmp"
+	if err := os.WriteFile(tmpFile, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write temporary bootstrap weights: %w", err)
+	}
+	if err := os.Rename(tmpFile, path); err != nil {
+		return fmt.Errorf("failed to rename bootstrap weights: %w", err)
+	}
+	return nil
+}
+
+// ResetWeights discards the success history of every bootstrap node, so all
+// nodes are tried in registration order again. Use it when saved weights are
+// suspected to be stale. The sidecar file, if configured, is removed.
+func (bm *BootstrapManager) ResetWeights() error {
+	bm.mu.Lock()
+	for _, bn := range bm.nodes {
+		bn.Attempts = 0
+		bn.Successes = 0
+	}
+	bm.savedWeights = nil
+	path := bm.weightsFile
+	bm.mu.Unlock()
+
+	if path == "" {
+		return nil
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove bootstrap weights: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package dht
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opd-ai/toxcore/crypto"
+	"github.com/opd-ai/toxcore/transport"
+)
+
+// newWeightsTestManager creates a bootstrap manager with three nodes whose
+// public keys start with 0x01, 0x02 and 0x03 in registration order.
+func newWeightsTestManager(t *testing.T, sendFunc func(*transport.Packet, net.Addr) error) *BootstrapManager {
+	t.Helper()
+	mt := newMockTransport(newMockAddr("127.0.0.1:33445"))
+	if sendFunc != nil {
+		mt.sendFunc = sendFunc
+	}
+	selfID := crypto.ToxID{PublicKey: [32]byte{0xaa}}
+	bm, err := NewBootstrapManagerForTesting(selfID, mt, NewRoutingTable(selfID, 8), 1)
+	if err != nil {
+		t.Fatalf("NewBootstrapManagerForTesting failed: %v", err)
+	}
+	for i := 1; i <= 3; i++ {
+		addr := newMockAddr(fmt.Sprintf("10.0.0.%d:33445", i))
+		if err := bm.AddNode(addr, strings.Repeat(fmt.Sprintf("%02x", i), 32)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return bm
+}
+
+func TestPrepareBootstrapNodesOrdersByWeight(t *testing.T) {
+	bm := newWeightsTestManager(t, nil)
+	nodes := bm.GetNodes()
+	nodes[0].Attempts, nodes[0].Successes = 10, 1 // unreliable
+	nodes[2].Attempts, nodes[2].Successes = 10, 9 // reliable
+
+	ordered := bm.prepareBootstrapNodes()
+	got := []byte{ordered[0].PublicKey[0], ordered[1].PublicKey[0], ordered[2].PublicKey[0]}
+	if string(got) != "\x03\x02\x01" {
+		t.Errorf("Expected nodes ordered 3, 2, 1 by weight, got %v", got)
+	}
+}
+
+func TestBootstrapSuccessUpdatesWeight(t *testing.T) {
+	bm := newWeightsTestManager(t, nil)
+	bn := bm.GetNodes()[1]
+
+	bm.recordBootstrapAttempt(bn)
+	bm.markBootstrapNodeSuccess(bn.PublicKey)
+	bm.markBootstrapNodeSuccess(bn.PublicKey)
+	if bn.Attempts != 1 || bn.Successes != 1 {
+		t.Errorf("Expected 1 attempt and 1 success, got %d and %d", bn.Attempts, bn.Successes)
+	}
+	if w := bn.Weight(); w <= 0.5 {
+		t.Errorf("Expected weight above the untried weight 0.5, got %f", w)
+	}
+}
+
+func TestBootstrapWeightsPersistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bootstrap_weights.json")
+	bm := newWeightsTestManager(t, func(packet *transport.Packet, addr net.Addr) error {
+		if addr.String() == "10.0.0.1:33445" {
+			return net.ErrClosed
+		}
+		return nil
+	})
+	if err := bm.SetWeightsFile(path); err != nil {
+		t.Fatalf("SetWeightsFile failed: %v", err)
+	}
+	bm.GetNodes()[2].Attempts = 4
+	bm.GetNodes()[2].Successes = 4
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := bm.Bootstrap(ctx); err != nil {
+		t.Fatalf("Bootstrap failed: %v", err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("Expected weights sidecar after bootstrap: %v", err)
+	}
+
+	restored := newWeightsTestManager(t, nil)
+	if err := restored.SetWeightsFile(path); err != nil {
+		t.Fatalf("SetWeightsFile failed: %v", err)
+	}
+	nodes := restored.GetNodes()
+	if nodes[0].Attempts != 1 || nodes[2].Attempts != 5 {
+		t.Errorf("Expected restored attempts 1 and 5, got %d and %d", nodes[0].Attempts, nodes[2].Attempts)
+	}
+	if first := restored.prepareBootstrapNodes()[0]; first.PublicKey[0] != 0x03 {
+		t.Errorf("Expected node 3 first after restore, got node %d", first.PublicKey[0])
+	}
+
+	if err := restored.ResetWeights(); err != nil {
+		t.Fatalf("ResetWeights failed: %v", err)
+	}
+	for _, bn := range restored.GetNodes() {
+		if bn.Attempts != 0 || bn.Successes != 0 {
+			t.Errorf("Expected history cleared, got %d/%d", bn.Successes, bn.Attempts)
+		}
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("Expected sidecar removed by ResetWeights, got %v", err)
+	}
+	if first := restored.prepareBootstrapNodes()[0]; first.PublicKey[0] != 0x01 {
+		t.Errorf("Expected registration order after reset, got node %d first", first.PublicKey[0])
+	}
+}
@@ -727,6 +727,66 @@ func TestRoutingTable(t *testing.T) {
 			t.Errorf("Expected 0 nodes from empty routing table, got %d", len(allNodes))
 		}
 	})
+
+	t.Run("NodeLimit", func(t *testing.T) {
+		// Arrange
+		selfID := createTestToxID(0x00)
+		rt := NewRoutingTable(selfID, 8)
+		rt.SetNodeLimit(2)
+
+		first := NewNode(createTestToxID(0x80), newMockAddr("1.1.1.1:1"))
+		second := NewNode(createTestToxID(0x40), newMockAddr("1.1.1.2:1"))
+		third := NewNode(createTestToxID(0x20), newMockAddr("1.1.1.3:1"))
+
+		// Act & Assert
+		if !rt.AddNode(first) || !rt.AddNode(second) {
+			t.Fatal("Expected nodes below the limit to be added")
+		}
+		if rt.AddNode(third) {
+			t.Error("Expected AddNode to reject a new node at the limit")
+		}
+		if !rt.AddNode(first) {
+			t.Error("Expected AddNode to refresh a known node at the limit")
+		}
+		if rt.Size() != 2 {
+			t.Errorf("Expected size 2, got %d", rt.Size())
+		}
+
+		rt.SetNodeLimit(0)
+		if !rt.AddNode(third) {
+			t.Error("Expected AddNode to succeed after removing the limit")
+		}
+		if rt.Size() != 3 {
+			t.Errorf("Expected size 3, got %d", rt.Size())
+		}
+	})
+
+	t.Run("UpdateNodeAddress", func(t *testing.T) {
+		// Arrange
+		selfID := createTestToxID(0x00)
+		rt := NewRoutingTable(selfID, 8)
+		known := createTestToxID(0x80)
+		rt.AddNode(NewNode(known, newMockAddr("1.1.1.1:1")))
+		newAddr := newMockAddr("2.2.2.2:2")
+
+		// Act & Assert
+		if !rt.UpdateNodeAddress(known.PublicKey, newAddr) {
+			t.Fatal("Expected known node to be updated")
+		}
+		nodes := rt.GetAllNodes()
+		if len(nodes) != 1 || nodes[0].Address.String() != newAddr.String() {
+			t.Errorf("Expected node at %s, got %v", newAddr, nodes)
+		}
+		if nodes[0].GetStatus() != StatusGood {
+			t.Errorf("Expected migrated node to be good, got %v", nodes[0].GetStatus())
+		}
+		if rt.UpdateNodeAddress(createTestToxID(0x40).PublicKey, newAddr) {
+			t.Error("Expected unknown node not to be added")
+		}
+		if rt.Size() != 1 {
+			t.Errorf("Expected size 1, got %d", rt.Size())
+		}
+	})
 }
 
 // TestBootstrapManager tests the BootstrapManager implementation
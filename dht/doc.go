@@ -34,6 +34,38 @@
 // The bootstrap manager includes exponential backoff for failed attempts and
 // version negotiation for protocol compatibility.
 //
+// Periodic health checks ping every bootstrap node and mark nodes that miss
+// MaxConsecutiveFailures pings in a row as unavailable. Unavailable nodes stay
+// registered but are skipped by Bootstrap until they answer again:
+//
+//	manager.SetHealthCheckConfig(dht.DefaultBootstrapHealthConfig())
+//	manager.OnNodeHealthChange(func(pk [32]byte, healthy bool) { ... })
+//	err = manager.StartHealthChecks()
+//	defer manager.StopHealthChecks()
+//
+// Bootstrap contacts nodes in order of their historical success rate, so a
+// node that has often failed to answer no longer gets the first request.
+// The history can be kept across restarts in a sidecar file, which is
+// rewritten after each successful bootstrap; ResetWeights discards it:
+//
+//	err = manager.SetWeightsFile(filepath.Join(dataDir, "bootstrap_weights.json"))
+//
+// Operators can publish nodes in DNS TXT records, one "host:port:pubkey"
+// node per record. Bootstrap resolves registered domains before contacting
+// nodes and again after a failed attempt, caching results for
+// DNSBootstrapCacheTTL or the record TTL if lower. Failed lookups leave the
+// statically added nodes in use, and SetDNSBootstrapEnabled(false) turns
+// resolution off, as Tox does in simulation mode:
+//
+//	err = manager.AddBootstrapDomain("nodes.example.org")
+//	manager.SetResolver(myResolver) // Optional; defaults to net.DefaultResolver
+//
+// Once the bootstrap nodes have been contacted, Bootstrap looks up the local
+// node's own key with up to alpha FIND_NODE queries in flight (default 3,
+// MaintenanceConfig.Alpha or SetLookupAlpha). Each answer frees a slot for
+// the closest node not yet queried, so slow nodes do not hold up the walk,
+// and no node is queried twice.
+//
 // # Routing Table
 //
 // The routing table implements Kademlia-style k-buckets with configurable size
@@ -46,6 +78,28 @@
 // The FindClosest method uses a min-heap for efficient retrieval of the k
 // closest nodes to any target ID.
 //
+// Nodes may advertise both an IPv4 and an IPv6 address. The table's
+// NetworkPreference selects which one Address carries, falling back to the
+// node's only family; single-address nodes behave as before:
+//
+//	table.SetNetworkPreference(dht.NetworkPreference{PreferIPv6: true})
+//	table.AddNode(dht.NewDualStackNode(id, v4Addr, v6Addr))
+//
+// The table can be saved on shutdown and restored on startup, so a
+// restarting node does not need a full re-bootstrap. Restored nodes start in
+// StatusUnknown, and nodes last seen more than RestoredNodeTTL before the
+// load are dropped:
+//
+//	err := table.SaveFile(filepath.Join(dataDir, "routing_table.json"))
+//	...
+//	var saved io.Reader // nil restores nothing
+//	if f, err := os.Open(filepath.Join(dataDir, "routing_table.json")); err == nil {
+//	    defer f.Close()
+//	    saved = f
+//	}
+//	manager, err := dht.NewBootstrapManagerWithSavedRoutingTable(selfID, keyPair, tr, table, saved)
+//	// The restored nodes are pinged by the next manager.Bootstrap
+//
 // # Node Status
 //
 // Nodes transition through three states based on responsiveness:
@@ -71,6 +125,50 @@
 //   - Removing unresponsive nodes (bad timeout: 10 minutes)
 //   - Pruning stale entries (prune timeout: 1 hour)
 //
+// Reconfigure swaps the configuration of a running Maintainer without a
+// restart: in-flight passes finish, then the routines restart on the new
+// intervals. Intervals below MinPingInterval (5s) or MinLookupInterval (30s)
+// are rejected. GetCurrentConfig reports the active settings.
+//
+// # Metrics
+//
+// RoutingTable.PrometheusCollector exposes bucket fill ratios, good/bad node
+// counts, and lookup count and latency. Maintainer.RegisterMetrics registers
+// it together with a per-task maintenance cycle counter:
+//
+//	reg := prometheus.NewRegistry()
+//	err := maintainer.RegisterMetrics(reg)
+//
+// Each answered ping updates the node's rolling average round-trip time.
+// RoutingTable.Metrics summarizes bucket fill, node health and average RTT,
+// and MetricsSnapshot lists per-node latency; the Maintainer logs the summary
+// after every ping cycle. Other backends can receive RTT samples and table
+// sizes through a MetricsRecorder, the interface NonceStore reports to:
+//
+//	routingTable.SetMetricsRecorder(recorder)
+//
+// # Onion Lookups
+//
+// With an OnionConfig set on the routing table, IterativeLookup sends each
+// FIND_NODE request, and QueryGroup each group query (FIND_VALUE), through a
+// lazily built circuit of relays, one crypto.Encrypt layer per hop. FIND_NODE
+// requests carry a per-circuit ephemeral key. The queried node learns the
+// target but not the client's address; each relay learns only its
+// neighbours. Circuits are reused for CircuitLifetime. This is a lightweight
+// privacy measure, not Tor-grade anonymity:
+//
+//	routingTable.SetOnionConfig(dht.DefaultOnionConfig())
+//	manager.EnableOnionRelay(keyPair) // relay for other clients
+//	manager.OnOnionReply(func(pk [32]byte, t transport.PacketType, data []byte) { ... })
+//
+// Relays only forward further onion layers and these two lookup requests.
+// Each layer carries a random per-request circuit ID under which the relay
+// remembers the return path; a reply is only sent back when it answers an
+// outstanding relayed request, and the route is consumed by it.
+//
+// Lookups fail with ErrInsufficientRelays rather than falling back to direct
+// queries when the routing table is too small to build a circuit.
+//
 // # LAN Discovery
 //
 // Local network peer discovery uses UDP broadcast for quick connection to
@@ -84,7 +182,11 @@
 //	defer discovery.Stop()
 //
 // LAN discovery broadcasts on port+1 to avoid conflicts with the main
-// transport, with a 10-second interval between announcements.
+// transport, with a 10-second interval between announcements. Where IPv6 is
+// available it also joins the ff02::1 all-nodes group on every up,
+// multicast-capable interface and announces there, so IPv6-only networks are
+// covered too. A peer heard on several interfaces at once is reported once
+// per public key; Stop (or Close) leaves every group joined.
 //
 // # Group Announcements
 //
@@ -101,6 +203,13 @@
 //
 //	announcement := storage.GetAnnouncement(12345)
 //
+// Groups can also be found by name, case-insensitively. SearchByName looks at
+// local announcements only; BootstrapManager.SearchGroups also asks the k
+// nodes closest to the name's hash and merges the results by group ID:
+//
+//	local, err := storage.SearchByName("chess", 10)
+//	all, err := bootstrapManager.SearchGroups(ctx, "chess", 8)
+//
 // # Multi-Network Support
 //
 // The DHT supports alternative network types through address detection:
@@ -0,0 +1,190 @@
+package dht
+
+import (
+	"net"
+	"net/netip"
+
+	"github.com/opd-ai/toxcore/crypto"
+)
+
+// NetworkPreference selects which address family the routing table uses for
+// nodes that advertise both an IPv4 and an IPv6 address.
+type NetworkPreference struct {
+	// PreferIPv6 selects the IPv6 address of dual-stack nodes. Enable it
+	// only when the local transport can reach IPv6 peers.
+	PreferIPv6 bool
+}
+
+// NewDualStackNode creates a node reachable over IPv4 at v4 and over IPv6 at
+// v6. Either address may be nil. Address is set to v4, or v6 if there is no
+// IPv4 address; a RoutingTable re-selects it according to its
+// NetworkPreference when the node is added.
+//
+//export ToxDHTNodeNewDualStack
+func NewDualStackNode(id crypto.ToxID, v4, v6 net.Addr) *Node {
+	addr := v4
+	if addr == nil {
+		addr = v6
+	}
+	node := NewNode(id, addr)
+	node.AddrV4 = v4
+	node.AddrV6 = v6
+	return node
+}
+
+// SupportsIPv6 reports whether a transport bound to localAddr can reach IPv6
+// peers, which is the case for IPv6 and unspecified IPv6 ("[::]") binds.
+func SupportsIPv6(localAddr net.Addr) bool {
+	ip, ok := addrIP(localAddr)
+	return ok && ip.Is6() && !ip.Is4In6()
+}
+
+// addrIP extracts the IP of an IP-based address.
+func addrIP(addr net.Addr) (netip.Addr, bool) {
+	switch a := addr.(type) {
+	case nil:
+		return netip.Addr{}, false
+	case *net.UDPAddr:
+		ip, ok := netip.AddrFromSlice(a.IP)
+		return ip, ok
+	case *net.TCPAddr:
+		ip, ok := netip.AddrFromSlice(a.IP)
+		return ip, ok
+	}
+	ap, err := netip.ParseAddrPort(addr.String())
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	return ap.Addr(), true
+}
+
+// familyAddrs returns the node's IPv4 and IPv6 addresses. Nodes created with
+// a single address report it under its own family; non-IP addresses such as
+// .onion are reported under neither.
+func (n *Node) familyAddrs() (v4, v6 net.Addr) {
+	if n.AddrV4 != nil || n.AddrV6 != nil {
+		return n.AddrV4, n.AddrV6
+	}
+	ip, ok := addrIP(n.Address)
+	switch {
+	case !ok:
+		return nil, nil
+	case ip.Is4() || ip.Is4In6():
+		return n.Address, nil
+	default:
+		return nil, n.Address
+	}
+}
+
+// IsDualStack reports whether the node has both an IPv4 and an IPv6 address.
+//
+//export ToxDHTNodeIsDualStack
+func (n *Node) IsDualStack() bool {
+	v4, v6 := n.familyAddrs()
+	return v4 != nil && v6 != nil
+}
+
+// PreferredAddress returns the address to use for the node under pref. It
+// falls back to whichever family the node has, and to Address for nodes
+// without an IP address.
+//
+//export ToxDHTNodePreferredAddress
+func (n *Node) PreferredAddress(pref NetworkPreference) net.Addr {
+	v4, v6 := n.familyAddrs()
+	switch {
+	case pref.PreferIPv6 && v6 != nil:
+		return v6
+	case v4 != nil:
+		return v4
+	case v6 != nil:
+		return v6
+	}
+	return n.Address
+}
+
+// mergeFamilyAddrs fills in address families n lacks from known, so a node
+// re-announced over one family keeps the address it advertised over the
+// other. n must not yet be visible to other goroutines.
+func (n *Node) mergeFamilyAddrs(known *Node) {
+	v4, v6 := n.familyAddrs()
+	knownV4, knownV6 := known.familyAddrs()
+	if v4 == nil {
+		v4 = knownV4
+	}
+	if v6 == nil {
+		v6 = knownV6
+	}
+	n.AddrV4, n.AddrV6 = v4, v6
+}
+
+// withAddress returns a copy of n that uses addr. Routing table entries are
+// replaced rather than mutated because callers read Address without locking.
+func (n *Node) withAddress(addr net.Addr) *Node {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	return &Node{
+		ID:        n.ID,
+		Address:   addr,
+		AddrV4:    n.AddrV4,
+		AddrV6:    n.AddrV6,
+		LastSeen:  n.LastSeen,
+		Status:    n.Status,
+		PublicKey: n.PublicKey,
+		PingStats: n.PingStats,
+	}
+}
+
+// findNode returns the bucket entry for publicKey, or nil.
+func (kb *KBucket) findNode(publicKey [32]byte) *Node {
+	kb.mu.RLock()
+	defer kb.mu.RUnlock()
+	for _, node := range kb.nodes {
+		if node.ID.PublicKey == publicKey {
+			return node
+		}
+	}
+	return nil
+}
+
+// prepareDualStack merges the addresses of an existing entry into node and
+// selects its address under the table's preference. The caller must hold
+// rt.mu.
+func (rt *RoutingTable) prepareDualStack(node *Node, bucketIndex int) {
+	if known := rt.kBuckets[bucketIndex].findNode(node.ID.PublicKey); known != nil {
+		node.mergeFamilyAddrs(known)
+	}
+	node.Address = node.PreferredAddress(rt.preference)
+}
+
+// SetNetworkPreference sets the address family preference for dual-stack
+// nodes. Known dual-stack nodes are switched to the preferred address and
+// the lookup cache is cleared, so FindClosestNodes returns addresses matching
+// the new preference. Nodes that advertise a single family are unaffected.
+//
+//export ToxDHTRoutingTableSetNetworkPreference
+func (rt *RoutingTable) SetNetworkPreference(pref NetworkPreference) {
+	rt.mu.Lock()
+	rt.preference = pref
+	for _, bucket := range rt.kBuckets {
+		for _, node := range bucket.GetNodes() {
+			if !node.IsDualStack() {
+				continue
+			}
+			if addr := node.PreferredAddress(pref); addr != node.Address {
+				bucket.updateNode(node.withAddress(addr))
+			}
+		}
+	}
+	rt.mu.Unlock()
+
+	if rt.lookupCache != nil {
+		rt.lookupCache.Clear()
+	}
+}
+
+// GetNetworkPreference returns the address family preference.
+func (rt *RoutingTable) GetNetworkPreference() NetworkPreference {
+	rt.mu.RLock()
+	defer rt.mu.RUnlock()
+	return rt.preference
+}
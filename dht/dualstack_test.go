@@ -0,0 +1,92 @@
+package dht
+
+import (
+	"net"
+	"testing"
+
+	"github.com/opd-ai/toxcore/crypto"
+)
+
+func dualStackTestID(b byte) crypto.ToxID {
+	var id crypto.ToxID
+	id.PublicKey[0] = b
+	return id
+}
+
+func TestPreferredAddress(t *testing.T) {
+	v4 := &net.UDPAddr{IP: net.ParseIP("192.0.2.1"), Port: 33445}
+	v6 := &net.UDPAddr{IP: net.ParseIP("2001:db8::1"), Port: 33445}
+
+	tests := []struct {
+		name       string
+		node       *Node
+		preferIPv6 bool
+		want       net.Addr
+	}{
+		{"dual-stack prefers v4", NewDualStackNode(dualStackTestID(1), v4, v6), false, v4},
+		{"dual-stack prefers v6", NewDualStackNode(dualStackTestID(1), v4, v6), true, v6},
+		{"v4 only falls back", NewNode(dualStackTestID(1), v4), true, v4},
+		{"v6 only falls back", NewNode(dualStackTestID(1), v6), false, v6},
+		{"v6 only dual-stack constructor", NewDualStackNode(dualStackTestID(1), nil, v6), false, v6},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.node.PreferredAddress(NetworkPreference{PreferIPv6: tt.preferIPv6}); got != tt.want {
+				t.Errorf("PreferredAddress() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRoutingTableNetworkPreference(t *testing.T) {
+	rt := NewRoutingTable(dualStackTestID(0), 8)
+	target := dualStackTestID(0)
+	v4 := &net.UDPAddr{IP: net.ParseIP("192.0.2.1"), Port: 33445}
+	v6 := &net.UDPAddr{IP: net.ParseIP("2001:db8::1"), Port: 33445}
+	v4Only := &net.UDPAddr{IP: net.ParseIP("192.0.2.2"), Port: 33445}
+
+	if !rt.AddNode(NewDualStackNode(dualStackTestID(1), v4, v6)) || !rt.AddNode(NewNode(dualStackTestID(2), v4Only)) {
+		t.Fatal("AddNode failed")
+	}
+	addrs := func() map[byte]net.Addr {
+		m := make(map[byte]net.Addr)
+		for _, n := range rt.FindClosestNodes(target, 8) {
+			m[n.ID.PublicKey[0]] = n.Address
+		}
+		return m
+	}
+
+	if got := addrs(); got[1] != v4 || got[2] != v4Only {
+		t.Errorf("default preference: got %v", got)
+	}
+
+	rt.SetNetworkPreference(NetworkPreference{PreferIPv6: true})
+	if got := addrs(); got[1] != v6 || got[2] != v4Only {
+		t.Errorf("IPv6 preference: got %v", got)
+	}
+
+	// Re-announcing over IPv4 alone keeps the known IPv6 address
+	if !rt.AddNode(NewNode(dualStackTestID(1), v4)) {
+		t.Fatal("re-adding node failed")
+	}
+	if got := addrs(); got[1] != v6 {
+		t.Errorf("re-announced node: got %v, want %v", got[1], v6)
+	}
+}
+
+func TestSupportsIPv6(t *testing.T) {
+	tests := []struct {
+		addr net.Addr
+		want bool
+	}{
+		{&net.UDPAddr{IP: net.IPv6unspecified, Port: 33445}, true},
+		{&net.UDPAddr{IP: net.ParseIP("2001:db8::1"), Port: 33445}, true},
+		{&net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 33445}, false},
+		{nil, false},
+	}
+	for _, tt := range tests {
+		if got := SupportsIPv6(tt.addr); got != tt.want {
+			t.Errorf("SupportsIPv6(%v) = %v, want %v", tt.addr, got, tt.want)
+		}
+	}
+}
@@ -149,7 +149,7 @@ func (gb *GossipBootstrap) BootstrapFromGossip(ctx context.Context) error {
 		return errors.New("no gossip peers available")
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":   "BootstrapFromGossip",
 		"peer_count": len(peers),
 	}).Info("Attempting gossip-based bootstrap")
@@ -160,7 +160,7 @@ func (gb *GossipBootstrap) BootstrapFromGossip(ctx context.Context) error {
 		return errors.New("all gossip peers unreachable")
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":      "BootstrapFromGossip",
 		"success_count": successCount,
 	}).Info("Gossip bootstrap requests sent")
@@ -179,7 +179,7 @@ func (gb *GossipBootstrap) sendGetNodesRequests(ctx context.Context, peers []*Go
 		}
 
 		if err := gb.sendGetNodesRequest(peer); err != nil {
-			logrus.WithFields(logrus.Fields{
+			pkgLog.WithFields(logrus.Fields{
 				"function": "BootstrapFromGossip",
 				"peer":     peer.Address.String(),
 				"error":    err.Error(),
@@ -235,7 +235,7 @@ func (gb *GossipBootstrap) GetPeerCount() int {
 func (gb *GossipBootstrap) registerGossipHandlers() {
 	// Handler registration is deferred to BootstrapManager to avoid conflicts.
 	// The BootstrapManager.handleSendNodesPacket() method now calls the gossip handler.
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "registerGossipHandlers",
 	}).Debug("Gossip handlers will be registered through BootstrapManager")
 }
@@ -257,7 +257,7 @@ func (gb *GossipBootstrap) handleSendNodes(packet *transport.Packet, senderAddr
 	for i := 0; i < nodeCount && offset+39 <= len(packet.Data); i++ {
 		peer, newOffset, err := gb.parseNodeEntry(packet.Data, offset, senderAddr)
 		if err != nil {
-			logrus.WithFields(logrus.Fields{
+			pkgLog.WithFields(logrus.Fields{
 				"function": "handleSendNodes",
 				"error":    err.Error(),
 			}).Debug("Failed to parse node entry")
@@ -379,7 +379,7 @@ func (gb *GossipBootstrap) performExchange() {
 	closestNodes := gb.routingTable.FindClosestNodes(gb.selfID, 4)
 	for _, node := range closestNodes {
 		if err := gb.RequestPeerExchange(node.Address); err != nil {
-			logrus.WithFields(logrus.Fields{
+			pkgLog.WithFields(logrus.Fields{
 				"function": "performExchange",
 				"node":     node.Address.String(),
 				"error":    err.Error(),
@@ -468,7 +468,7 @@ func (gb *GossipBootstrap) SeedFromRoutingTable() int {
 		count += gb.seedFromBucket(gb.routingTable.kBuckets[i], gb.config.MaxCachedPeers-count)
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":   "SeedFromRoutingTable",
 		"peer_count": count,
 	}).Debug("Seeded gossip cache from routing table")
@@ -0,0 +1,326 @@
+package dht
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/opd-ai/toxcore/crypto"
+	"github.com/opd-ai/toxcore/transport"
+)
+
+// ErrInvalidGroupSearch is returned for an empty or overlong search query or
+// a non-positive result limit.
+var ErrInvalidGroupSearch = errors.New("invalid group search")
+
+const (
+	// maxGroupSearchResults caps the announcements carried in one search
+	// response so the packet stays well below typical UDP payload limits.
+	maxGroupSearchResults = 16
+
+	// defaultGroupSearchTimeout bounds a network search whose context has
+	// no deadline.
+	defaultGroupSearchTimeout = 5 * time.Second
+
+	// groupSearchHeaderSize is [request_id(4)][max_results(1)] for queries
+	// and [request_id(4)][count(1)] for responses.
+	groupSearchHeaderSize = 5
+)
+
+// groupSearch tracks an outstanding network search.
+type groupSearch struct {
+	responses chan []*GroupAnnouncement
+	// peers holds the addresses queried; responses from others are dropped.
+	peers map[string]bool
+}
+
+// SearchByName returns up to maxResults unexpired announcements whose name
+// contains query, compared case-insensitively, ordered by group ID.
+func (gs *GroupStorage) SearchByName(query string, maxResults int) ([]*GroupAnnouncement, error) {
+	if err := validateGroupSearch(query, maxResults); err != nil {
+		return nil, err
+	}
+	needle := strings.ToLower(query)
+
+	gs.mu.RLock()
+	matches := make([]*GroupAnnouncement, 0)
+	for _, a := range gs.announcements {
+		if time.Since(a.Timestamp) > a.TTL {
+			continue
+		}
+		if strings.Contains(strings.ToLower(a.Name), needle) {
+			matches = append(matches, a)
+		}
+	}
+	gs.mu.RUnlock()
+
+	sort.Slice(matches, func(i, j int) bool { return matches[i].GroupID < matches[j].GroupID })
+	if len(matches) > maxResults {
+		matches = matches[:maxResults]
+	}
+	return matches, nil
+}
+
+// validateGroupSearch checks a search query and result limit.
+func validateGroupSearch(query string, maxResults int) error {
+	if query == "" || len(query) > maxGroupNameLen {
+		return fmt.Errorf("%w: query must be 1-%d bytes", ErrInvalidGroupSearch, maxGroupNameLen)
+	}
+	if maxResults <= 0 {
+		return fmt.Errorf("%w: maxResults must be positive", ErrInvalidGroupSearch)
+	}
+	return nil
+}
+
+// registerSearch registers a pending search expecting responses from peers.
+func (gs *GroupStorage) registerSearch(requestID uint32, peers []*Node) *groupSearch {
+	search := &groupSearch{
+		responses: make(chan []*GroupAnnouncement, len(peers)),
+		peers:     make(map[string]bool, len(peers)),
+	}
+	for _, node := range peers {
+		search.peers[node.Address.String()] = true
+	}
+
+	gs.pendingMu.Lock()
+	if gs.pendingSearches == nil {
+		gs.pendingSearches = make(map[uint32]*groupSearch)
+	}
+	gs.pendingSearches[requestID] = search
+	gs.pendingMu.Unlock()
+	return search
+}
+
+// deregisterSearch removes a pending search.
+func (gs *GroupStorage) deregisterSearch(requestID uint32) {
+	gs.pendingMu.Lock()
+	delete(gs.pendingSearches, requestID)
+	gs.pendingMu.Unlock()
+}
+
+// deliverSearchResponse hands results from sender to the matching search.
+// Each queried peer may answer once; other responses are dropped.
+func (gs *GroupStorage) deliverSearchResponse(requestID uint32, sender net.Addr, results []*GroupAnnouncement) bool {
+	gs.pendingMu.Lock()
+	defer gs.pendingMu.Unlock()
+
+	search, ok := gs.pendingSearches[requestID]
+	if !ok || sender == nil || !search.peers[sender.String()] {
+		return false
+	}
+	delete(search.peers, sender.String())
+
+	select {
+	case search.responses <- results:
+		return true
+	default:
+		return false
+	}
+}
+
+// SearchGroups finds groups whose name contains name, case-insensitively,
+// among the announcements stored locally and on the k DHT nodes closest to
+// the hash of the lowercased name. Results are deduplicated by group ID and
+// ordered by group ID.
+//
+// The search returns when every queried node has answered or ctx is done;
+// without a deadline on ctx it waits at most 5 seconds. Nodes that do not
+// answer in time are ignored.
+//
+//export ToxDHTSearchGroups
+func (bm *BootstrapManager) SearchGroups(ctx context.Context, name string, k int) ([]GroupAnnouncement, error) {
+	if err := validateGroupSearch(name, k); err != nil {
+		return nil, err
+	}
+	if bm.groupStorage == nil {
+		return nil, ErrGroupDHTNotImplemented
+	}
+
+	found := make(map[uint32]*GroupAnnouncement)
+	local, err := bm.groupStorage.SearchByName(name, maxGroupSearchResults)
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range local {
+		found[a.GroupID] = a
+	}
+
+	nodes := bm.groupSearchNodes(name, k)
+	if len(nodes) > 0 && bm.transport != nil {
+		bm.collectNetworkSearch(ctx, name, nodes, found)
+	}
+
+	results := make([]GroupAnnouncement, 0, len(found))
+	for _, a := range found {
+		results = append(results, *a)
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].GroupID < results[j].GroupID })
+	return results, nil
+}
+
+// groupSearchNodes returns up to k routing table nodes closest to the hash of
+// the lowercased search name.
+func (bm *BootstrapManager) groupSearchNodes(name string, k int) []*Node {
+	if bm.routingTable == nil {
+		return nil
+	}
+	target := crypto.NewToxID(sha256.Sum256([]byte(strings.ToLower(name))), [4]byte{})
+	candidates := bm.routingTable.FindClosestNodes(*target, k)
+
+	nodes := make([]*Node, 0, len(candidates))
+	for _, node := range candidates {
+		if node.Address != nil {
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes
+}
+
+// collectNetworkSearch queries nodes and merges their results into found.
+func (bm *BootstrapManager) collectNetworkSearch(ctx context.Context, name string, nodes []*Node, found map[uint32]*GroupAnnouncement) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, defaultGroupSearchTimeout)
+		defer cancel()
+	}
+
+	requestID, err := newGroupSearchID()
+	if err != nil {
+		pkgLog.WithError(err).Debug("dht: skipping network group search: crypto/rand failed")
+		return
+	}
+	search := bm.groupStorage.registerSearch(requestID, nodes)
+	defer bm.groupStorage.deregisterSearch(requestID)
+
+	packet := buildGroupSearchQuery(requestID, name)
+	sent := 0
+	for _, node := range nodes {
+		if err := bm.transport.Send(packet, node.Address); err != nil {
+			pkgLog.WithError(err).Debug("dht: best-effort group search send failed")
+			continue
+		}
+		sent++
+	}
+
+	for ; sent > 0; sent-- {
+		select {
+		case results := <-search.responses:
+			for _, a := range results {
+				if _, dup := found[a.GroupID]; !dup {
+					found[a.GroupID] = a
+				}
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// newGroupSearchID returns a random request ID so responses cannot be
+// guessed by nodes that did not see the query.
+func newGroupSearchID() (uint32, error) {
+	var b [4]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint32(b[:]), nil
+}
+
+// buildGroupSearchQuery creates a search query packet:
+// [request_id(4)][max_results(1)][query...].
+func buildGroupSearchQuery(requestID uint32, query string) *transport.Packet {
+	data := make([]byte, groupSearchHeaderSize+len(query))
+	binary.BigEndian.PutUint32(data[0:4], requestID)
+	data[4] = maxGroupSearchResults
+	copy(data[groupSearchHeaderSize:], query)
+
+	return &transport.Packet{
+		PacketType: transport.PacketGroupSearchQuery,
+		Data:       data,
+	}
+}
+
+// handleGroupSearchQuery answers a search query with matching local
+// announcements: [request_id(4)][count(1)][announcement...].
+func (bm *BootstrapManager) handleGroupSearchQuery(packet *transport.Packet, senderAddr net.Addr) error {
+	if bm.groupStorage == nil || bm.transport == nil {
+		return fmt.Errorf("group storage or transport not initialized")
+	}
+	if len(packet.Data) <= groupSearchHeaderSize {
+		return fmt.Errorf("group search query packet too short")
+	}
+
+	requestID := binary.BigEndian.Uint32(packet.Data[0:4])
+	maxResults := int(packet.Data[4])
+	if maxResults > maxGroupSearchResults {
+		maxResults = maxGroupSearchResults
+	}
+	query := string(packet.Data[groupSearchHeaderSize:])
+
+	var matches []*GroupAnnouncement
+	if maxResults > 0 {
+		var err error
+		if matches, err = bm.groupStorage.SearchByName(query, maxResults); err != nil {
+			return err
+		}
+	}
+
+	data := make([]byte, groupSearchHeaderSize, groupSearchHeaderSize+len(matches)*(19+maxGroupNameLen+MaxAnnouncementDescriptionLen))
+	binary.BigEndian.PutUint32(data[0:4], requestID)
+	data[4] = byte(len(matches))
+	for _, a := range matches {
+		serialized, err := SerializeAnnouncement(a)
+		if err != nil {
+			return fmt.Errorf("failed to serialize search result: %w", err)
+		}
+		data = append(data, serialized...)
+	}
+
+	response := &transport.Packet{
+		PacketType: transport.PacketGroupSearchResponse,
+		Data:       data,
+	}
+	return bm.transport.Send(response, senderAddr)
+}
+
+// handleGroupSearchResponse parses a search response and delivers it to the
+// pending search. Results are also stored so later lookups by ID hit locally.
+func (bm *BootstrapManager) handleGroupSearchResponse(packet *transport.Packet, senderAddr net.Addr) error {
+	if bm.groupStorage == nil {
+		return fmt.Errorf("group storage not initialized")
+	}
+	if len(packet.Data) < groupSearchHeaderSize {
+		return fmt.Errorf("group search response too short")
+	}
+
+	requestID := binary.BigEndian.Uint32(packet.Data[0:4])
+	count := int(packet.Data[4])
+	if count > maxGroupSearchResults {
+		return fmt.Errorf("group search response has %d results (max %d)", count, maxGroupSearchResults)
+	}
+
+	results := make([]*GroupAnnouncement, 0, count)
+	rest := packet.Data[groupSearchHeaderSize:]
+	for i := 0; i < count; i++ {
+		announcement, consumed, err := decodeAnnouncement(rest, true)
+		if err != nil {
+			return fmt.Errorf("failed to deserialize search result: %w", err)
+		}
+		results = append(results, announcement)
+		rest = rest[consumed:]
+	}
+
+	if bm.groupStorage.deliverSearchResponse(requestID, senderAddr, results) {
+		for _, a := range results {
+			bm.groupStorage.StoreAnnouncement(a)
+		}
+	}
+	return nil
+}
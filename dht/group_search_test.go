@@ -0,0 +1,147 @@
+package dht
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/opd-ai/toxcore/transport"
+)
+
+func storeTestGroups(gs *GroupStorage, names map[uint32]string) {
+	for id, name := range names {
+		gs.StoreAnnouncement(&GroupAnnouncement{GroupID: id, Name: name, Timestamp: time.Now(), TTL: time.Hour})
+	}
+}
+
+func TestGroupStorageSearchByName(t *testing.T) {
+	gs := NewGroupStorage()
+	storeTestGroups(gs, map[uint32]string{1: "Go Developers", 2: "golang-nuts", 3: "Rustaceans", 4: "GOPHERS"})
+	gs.StoreAnnouncement(&GroupAnnouncement{GroupID: 5, Name: "go expired", Timestamp: time.Now().Add(-2 * time.Hour), TTL: time.Hour})
+
+	results, err := gs.SearchByName("gO", 10)
+	if err != nil {
+		t.Fatalf("SearchByName failed: %v", err)
+	}
+	var ids []uint32
+	for _, a := range results {
+		ids = append(ids, a.GroupID)
+	}
+	if len(ids) != 3 || ids[0] != 1 || ids[1] != 2 || ids[2] != 4 {
+		t.Errorf("SearchByName(\"gO\") returned groups %v, want [1 2 4]", ids)
+	}
+
+	if results, _ := gs.SearchByName("go", 2); len(results) != 2 {
+		t.Errorf("maxResults not applied: got %d results", len(results))
+	}
+	if results, _ := gs.SearchByName("python", 10); len(results) != 0 {
+		t.Errorf("expected no matches, got %d", len(results))
+	}
+
+	for _, tc := range []struct {
+		query string
+		max   int
+	}{{"", 10}, {"go", 0}} {
+		if _, err := gs.SearchByName(tc.query, tc.max); !errors.Is(err, ErrInvalidGroupSearch) {
+			t.Errorf("SearchByName(%q, %d) error = %v, want ErrInvalidGroupSearch", tc.query, tc.max, err)
+		}
+	}
+}
+
+// newSearchPeer creates a bootstrap manager whose transport delivers packets
+// to the handlers of the peer registered in peers under the destination.
+func newSearchPeer(t *testing.T, id byte, addr net.Addr, peers map[string]*MockTransport) (*BootstrapManager, *RoutingTable) {
+	t.Helper()
+	selfID := createTestToxID(id)
+	mt := newMockTransport(addr)
+	mt.sendFunc = func(packet *transport.Packet, dest net.Addr) error {
+		if peer, ok := peers[dest.String()]; ok {
+			return peer.SimulateReceive(packet, addr)
+		}
+		return nil
+	}
+	peers[addr.String()] = mt
+
+	rt := NewRoutingTable(selfID, 8)
+	bm, err := NewBootstrapManager(selfID, mt, rt)
+	if err != nil {
+		t.Fatalf("Failed to create bootstrap manager: %v", err)
+	}
+	return bm, rt
+}
+
+func TestBootstrapManagerSearchGroups(t *testing.T) {
+	peers := make(map[string]*MockTransport)
+	addrA := newMockAddr("node-a:1")
+	addrB := newMockAddr("node-b:1")
+	addrC := newMockAddr("node-c:1")
+	searcher, rt := newSearchPeer(t, 1, addrA, peers)
+	peerB, _ := newSearchPeer(t, 2, addrB, peers)
+	peerC, _ := newSearchPeer(t, 3, addrC, peers)
+	rt.AddNode(NewNode(createTestToxID(2), addrB))
+	rt.AddNode(NewNode(createTestToxID(3), addrC))
+
+	storeTestGroups(searcher.groupStorage, map[uint32]string{10: "Chess Club"})
+	storeTestGroups(peerB.groupStorage, map[uint32]string{10: "Chess Club", 11: "chess openings", 12: "Poker"})
+	storeTestGroups(peerC.groupStorage, map[uint32]string{11: "chess openings", 13: "CHESS960"})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	results, err := searcher.SearchGroups(ctx, "chess", 2)
+	if err != nil {
+		t.Fatalf("SearchGroups failed: %v", err)
+	}
+
+	var ids []uint32
+	for _, a := range results {
+		ids = append(ids, a.GroupID)
+	}
+	if len(ids) != 3 || ids[0] != 10 || ids[1] != 11 || ids[2] != 13 {
+		t.Errorf("SearchGroups returned groups %v, want deduplicated [10 11 13]", ids)
+	}
+	if _, ok := searcher.groupStorage.GetAnnouncement(13); !ok {
+		t.Error("network results should be stored locally")
+	}
+
+	if _, err := searcher.SearchGroups(ctx, "", 2); !errors.Is(err, ErrInvalidGroupSearch) {
+		t.Errorf("empty search error = %v, want ErrInvalidGroupSearch", err)
+	}
+}
+
+func TestGroupSearchResponseFromUnqueriedPeer(t *testing.T) {
+	peers := make(map[string]*MockTransport)
+	searcher, _ := newSearchPeer(t, 1, newMockAddr("node-a:1"), peers)
+
+	node := NewNode(createTestToxID(2), newMockAddr("node-b:1"))
+	search := searcher.groupStorage.registerSearch(7, []*Node{node})
+	defer searcher.groupStorage.deregisterSearch(7)
+
+	data, err := SerializeAnnouncement(&GroupAnnouncement{GroupID: 99, Name: "spoofed", Timestamp: time.Now()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	packet := &transport.Packet{
+		PacketType: transport.PacketGroupSearchResponse,
+		Data:       append([]byte{0, 0, 0, 7, 1}, data...),
+	}
+	if err := searcher.HandleGroupPacket(packet, newMockAddr("attacker:1")); err != nil {
+		t.Fatalf("HandleGroupPacket failed: %v", err)
+	}
+
+	select {
+	case <-search.responses:
+		t.Error("response from a peer that was not queried should be dropped")
+	default:
+	}
+	if _, ok := searcher.groupStorage.GetAnnouncement(99); ok {
+		t.Error("dropped response should not be stored")
+	}
+
+	// Truncated result lists are rejected
+	packet.Data = packet.Data[:len(packet.Data)-1]
+	if err := searcher.HandleGroupPacket(packet, node.Address); err == nil {
+		t.Error("expected error for truncated search response")
+	}
+}
@@ -8,9 +8,9 @@ import (
 	"net"
 	"sync"
 	"time"
+	"unicode/utf8"
 
 	"github.com/opd-ai/toxcore/transport"
-	"github.com/sirupsen/logrus"
 )
 
 // ErrGroupDHTNotImplemented is returned when group storage is unavailable and
@@ -22,6 +22,10 @@ var ErrGroupDHTNotImplemented = errors.New("group DHT storage unavailable")
 // integer-overflow bypasses on the length check (F-DHT-H3).
 const maxGroupNameLen = 256
 
+// MaxAnnouncementDescriptionLen is the number of description bytes carried
+// in a group announcement. Longer descriptions are truncated on serialization.
+const MaxAnnouncementDescriptionLen = 128
+
 // GroupAnnouncement represents a group chat announcement stored in the DHT.
 type GroupAnnouncement struct {
 	GroupID   uint32
@@ -30,6 +34,22 @@ type GroupAnnouncement struct {
 	Privacy   uint8 // Privacy level
 	Timestamp time.Time
 	TTL       time.Duration
+	// Description is a short summary for discovery listings, at most
+	// MaxAnnouncementDescriptionLen bytes.
+	Description string
+}
+
+// TruncateDescription shortens desc to MaxAnnouncementDescriptionLen bytes
+// without splitting a UTF-8 sequence.
+func TruncateDescription(desc string) string {
+	if len(desc) <= MaxAnnouncementDescriptionLen {
+		return desc
+	}
+	cut := MaxAnnouncementDescriptionLen
+	for cut > 0 && !utf8.RuneStart(desc[cut]) {
+		cut--
+	}
+	return desc[:cut]
 }
 
 // GroupQueryResponseCallback is called when a group query response is received from the DHT network.
@@ -47,13 +67,17 @@ type GroupStorage struct {
 	// pendingQueries holds per-query response channels keyed by groupID.
 	pendingQueries map[uint32][]chan *GroupAnnouncement
 	pendingMu      sync.Mutex
+
+	// pendingSearches holds outstanding name searches keyed by request ID.
+	pendingSearches map[uint32]*groupSearch
 }
 
 // NewGroupStorage creates a new group storage instance.
 func NewGroupStorage() *GroupStorage {
 	return &GroupStorage{
-		announcements:  make(map[uint32]*GroupAnnouncement),
-		pendingQueries: make(map[uint32][]chan *GroupAnnouncement),
+		announcements:   make(map[uint32]*GroupAnnouncement),
+		pendingQueries:  make(map[uint32][]chan *GroupAnnouncement),
+		pendingSearches: make(map[uint32]*groupSearch),
 	}
 }
 
@@ -174,6 +198,8 @@ func (gs *GroupStorage) notifyResponse(announcement *GroupAnnouncement) {
 }
 
 // SerializeAnnouncement converts a group announcement to bytes for network transmission.
+// The name is followed by a one-byte description length and the description,
+// truncated to MaxAnnouncementDescriptionLen bytes.
 func SerializeAnnouncement(announcement *GroupAnnouncement) ([]byte, error) {
 	data := make([]byte, 4+4+1+1+8) // groupID(4) + nameLen(4) + type(1) + privacy(1) + timestamp(8)
 
@@ -186,13 +212,28 @@ func SerializeAnnouncement(announcement *GroupAnnouncement) ([]byte, error) {
 	// Append name
 	data = append(data, []byte(announcement.Name)...)
 
+	// Append description
+	description := TruncateDescription(announcement.Description)
+	data = append(data, byte(len(description)))
+	data = append(data, description...)
+
 	return data, nil
 }
 
 // DeserializeAnnouncement converts bytes back to a group announcement.
+// Announcements from older peers that end after the name have no description.
 func DeserializeAnnouncement(data []byte) (*GroupAnnouncement, error) {
+	announcement, _, err := decodeAnnouncement(data, false)
+	return announcement, err
+}
+
+// decodeAnnouncement parses one announcement from the start of data and
+// returns it with the number of bytes consumed. requireDescription rejects
+// announcements without the description field, which concatenated
+// announcements must carry to be split reliably.
+func decodeAnnouncement(data []byte, requireDescription bool) (*GroupAnnouncement, int, error) {
 	if len(data) < 18 {
-		return nil, fmt.Errorf("announcement data too short: %d bytes", len(data))
+		return nil, 0, fmt.Errorf("announcement data too short: %d bytes", len(data))
 	}
 
 	groupID := binary.BigEndian.Uint32(data[0:4])
@@ -205,23 +246,40 @@ func DeserializeAnnouncement(data []byte) (*GroupAnnouncement, error) {
 	// Without this check, nameLen near MaxUint32 would wrap the uint32 addition,
 	// pass the length guard, and panic on the slice (F-DHT-H3).
 	if nameLen > maxGroupNameLen {
-		return nil, fmt.Errorf("announcement name too long: %d bytes (max %d)", nameLen, maxGroupNameLen)
+		return nil, 0, fmt.Errorf("announcement name too long: %d bytes (max %d)", nameLen, maxGroupNameLen)
 	}
 
 	if len(data) < int(18+nameLen) {
-		return nil, fmt.Errorf("announcement data truncated, expected %d bytes", 18+nameLen)
+		return nil, 0, fmt.Errorf("announcement data truncated, expected %d bytes", 18+nameLen)
 	}
 
 	name := string(data[18 : 18+nameLen])
 
+	consumed := int(18 + nameLen)
+	var description string
+	if rest := data[consumed:]; len(rest) > 0 {
+		descLen := int(rest[0])
+		if descLen > MaxAnnouncementDescriptionLen {
+			return nil, 0, fmt.Errorf("announcement description too long: %d bytes (max %d)", descLen, MaxAnnouncementDescriptionLen)
+		}
+		if len(rest) < 1+descLen {
+			return nil, 0, fmt.Errorf("announcement description truncated, expected %d bytes", descLen)
+		}
+		description = string(rest[1 : 1+descLen])
+		consumed += 1 + descLen
+	} else if requireDescription {
+		return nil, 0, fmt.Errorf("announcement data truncated, missing description")
+	}
+
 	return &GroupAnnouncement{
-		GroupID:   groupID,
-		Name:      name,
-		Type:      chatType,
-		Privacy:   privacy,
-		Timestamp: time.Unix(timestamp, 0),
-		TTL:       24 * time.Hour, // Default TTL
-	}, nil
+		GroupID:     groupID,
+		Name:        name,
+		Type:        chatType,
+		Privacy:     privacy,
+		Timestamp:   time.Unix(timestamp, 0),
+		TTL:         24 * time.Hour, // Default TTL
+		Description: description,
+	}, consumed, nil
 }
 
 // AnnounceGroup broadcasts a group announcement to DHT nodes.
@@ -347,17 +405,39 @@ func (rt *RoutingTable) selectNodesToQuery() []*Node {
 	return nodes
 }
 
-// sendQueryToNodes sends the query packet to all good nodes.
+// sendQueryToNodes sends the query packet to all good nodes, through an
+// onion circuit when the routing table has onion routing enabled.
 func (rt *RoutingTable) sendQueryToNodes(packet *transport.Packet, nodes []*Node, tr transport.Transport) {
+	onion := rt.OnionEnabled()
 	for _, node := range nodes {
-		if node.GetStatus() == StatusGood && node.Address != nil {
-			if err := tr.Send(packet, node.Address); err != nil {
-				logrus.WithError(err).Debug("dht: best-effort group query send failed")
-			}
+		if node.GetStatus() != StatusGood || node.Address == nil {
+			continue
+		}
+		var err error
+		if onion {
+			err = rt.sendOnionQuery(packet, node, tr)
+		} else {
+			err = tr.Send(packet, node.Address)
+		}
+		if err != nil {
+			pkgLog.WithError(err).Debug("dht: best-effort group query send failed")
 		}
 	}
 }
 
+// sendOnionQuery sends a group query to node through an onion circuit.
+func (rt *RoutingTable) sendOnionQuery(packet *transport.Packet, node *Node, tr transport.Transport) error {
+	circuit, err := rt.OnionCircuit(node.PublicKey)
+	if err != nil {
+		return err
+	}
+	wrapped, firstHop, err := rt.wrapOnionRequest(circuit, node.Address, packet)
+	if err != nil {
+		return err
+	}
+	return tr.Send(wrapped, firstHop)
+}
+
 // HandleGroupPacket processes group-related DHT packets.
 func (bm *BootstrapManager) HandleGroupPacket(packet *transport.Packet, senderAddr net.Addr) error {
 	switch packet.PacketType {
@@ -367,6 +447,10 @@ func (bm *BootstrapManager) HandleGroupPacket(packet *transport.Packet, senderAd
 		return bm.handleGroupQuery(packet, senderAddr)
 	case transport.PacketGroupQueryResponse:
 		return bm.handleGroupQueryResponse(packet, senderAddr)
+	case transport.PacketGroupSearchQuery:
+		return bm.handleGroupSearchQuery(packet, senderAddr)
+	case transport.PacketGroupSearchResponse:
+		return bm.handleGroupSearchResponse(packet, senderAddr)
 	default:
 		return fmt.Errorf("unsupported group packet type: %d", packet.PacketType)
 	}
@@ -426,18 +510,28 @@ func (bm *BootstrapManager) handleGroupQuery(packet *transport.Packet, senderAdd
 
 // handleGroupQueryResponse processes a group query response.
 func (bm *BootstrapManager) handleGroupQueryResponse(packet *transport.Packet, senderAddr net.Addr) error {
-	if len(packet.Data) < 1 {
+	// Replies to queries we relayed as an onion exit go back along the circuit
+	if forwarded, err := bm.forwardOnionReply(packet, senderAddr); forwarded {
+		return err
+	}
+	return bm.deliverGroupQueryResponse(packet.Data)
+}
+
+// deliverGroupQueryResponse hands a group query response body to the
+// routing table.
+func (bm *BootstrapManager) deliverGroupQueryResponse(data []byte) error {
+	if len(data) < 1 {
 		return fmt.Errorf("group query response too short")
 	}
 
-	found := packet.Data[0]
+	found := data[0]
 	if found == 0 {
 		// Group not found on this node
 		return nil
 	}
 
 	// Deserialize announcement
-	announcement, err := DeserializeAnnouncement(packet.Data[1:])
+	announcement, err := DeserializeAnnouncement(data[1:])
 	if err != nil {
 		return fmt.Errorf("failed to deserialize response: %w", err)
 	}
@@ -459,16 +553,18 @@ type GroupQueryResponse struct {
 // MarshalJSON implements json.Marshaler for GroupAnnouncement.
 func (ga *GroupAnnouncement) MarshalJSON() ([]byte, error) {
 	return json.Marshal(struct {
-		GroupID   uint32 `json:"group_id"`
-		Name      string `json:"name"`
-		Type      uint8  `json:"type"`
-		Privacy   uint8  `json:"privacy"`
-		Timestamp string `json:"timestamp"`
+		GroupID     uint32 `json:"group_id"`
+		Name        string `json:"name"`
+		Type        uint8  `json:"type"`
+		Privacy     uint8  `json:"privacy"`
+		Timestamp   string `json:"timestamp"`
+		Description string `json:"description,omitempty"`
 	}{
-		GroupID:   ga.GroupID,
-		Name:      ga.Name,
-		Type:      ga.Type,
-		Privacy:   ga.Privacy,
-		Timestamp: ga.Timestamp.Format(time.RFC3339),
+		GroupID:     ga.GroupID,
+		Name:        ga.Name,
+		Type:        ga.Type,
+		Privacy:     ga.Privacy,
+		Timestamp:   ga.Timestamp.Format(time.RFC3339),
+		Description: ga.Description,
 	})
 }
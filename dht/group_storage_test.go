@@ -1,6 +1,7 @@
 package dht
 
 import (
+	"strings"
 	"testing"
 	"time"
 )
@@ -189,3 +190,38 @@ func TestDeserializeInvalidData(t *testing.T) {
 		})
 	}
 }
+
+func TestSerializeDescription(t *testing.T) {
+	original := &GroupAnnouncement{
+		GroupID:     7,
+		Name:        "Release",
+		Timestamp:   time.Unix(1640000000, 0),
+		Description: "a" + strings.Repeat("é", MaxAnnouncementDescriptionLen),
+	}
+
+	data, err := SerializeAnnouncement(original)
+	if err != nil {
+		t.Fatalf("Failed to serialize: %v", err)
+	}
+	deserialized, err := DeserializeAnnouncement(data)
+	if err != nil {
+		t.Fatalf("Failed to deserialize: %v", err)
+	}
+
+	want := "a" + strings.Repeat("é", MaxAnnouncementDescriptionLen/2-1)
+	if deserialized.Description != want {
+		t.Errorf("Description not truncated on a rune boundary: got %d bytes", len(deserialized.Description))
+	}
+	if _, consumed, _ := decodeAnnouncement(data, true); consumed != len(data) {
+		t.Errorf("decodeAnnouncement consumed %d bytes, want %d", consumed, len(data))
+	}
+
+	// Announcements from peers without description support end after the name.
+	legacy, err := DeserializeAnnouncement(data[:18+len(original.Name)])
+	if err != nil {
+		t.Fatalf("Failed to deserialize legacy announcement: %v", err)
+	}
+	if legacy.Name != original.Name || legacy.Description != "" {
+		t.Errorf("Unexpected legacy announcement: %+v", legacy)
+	}
+}
@@ -19,15 +19,20 @@ type packetHandler func(packet *transport.Packet, senderAddr net.Addr) error
 // This is called once during initialization and stored as a field.
 func (bm *BootstrapManager) buildPacketHandlers() map[transport.PacketType]packetHandler {
 	return map[transport.PacketType]packetHandler{
-		transport.PacketVersionNegotiation: bm.handleVersionNegotiationPacket,
-		transport.PacketNoiseHandshake:     bm.handleVersionedHandshakePacket,
-		transport.PacketSendNodes:          bm.handleSendNodesPacket,
-		transport.PacketPingRequest:        bm.handlePingPacket,
-		transport.PacketPingResponse:       bm.handlePingResponsePacket,
-		transport.PacketGetNodes:           bm.handleGetNodesPacket,
-		transport.PacketGroupAnnounce:      bm.handleGroupAnnounce,
-		transport.PacketGroupQuery:         bm.handleGroupQuery,
-		transport.PacketGroupQueryResponse: bm.handleGroupQueryResponse,
+		transport.PacketVersionNegotiation:  bm.handleVersionNegotiationPacket,
+		transport.PacketNoiseHandshake:      bm.handleVersionedHandshakePacket,
+		transport.PacketSendNodes:           bm.handleSendNodesPacket,
+		transport.PacketPingRequest:         bm.handlePingPacket,
+		transport.PacketPingResponse:        bm.handlePingResponsePacket,
+		transport.PacketGetNodes:            bm.handleGetNodesPacket,
+		transport.PacketGroupAnnounce:       bm.handleGroupAnnounce,
+		transport.PacketGroupQuery:          bm.handleGroupQuery,
+		transport.PacketGroupQueryResponse:  bm.handleGroupQueryResponse,
+		transport.PacketGroupSearchQuery:    bm.handleGroupSearchQuery,
+		transport.PacketGroupSearchResponse: bm.handleGroupSearchResponse,
+		transport.PacketOnionSend:           bm.handleOnionSendPacket,
+		transport.PacketOnionReply:          bm.handleOnionReplyPacket,
+		transport.PacketMigrationNotify:     bm.handleMigrationNotifyPacket,
 	}
 }
 
@@ -50,6 +55,11 @@ func (bm *BootstrapManager) handleSendNodesPacket(packet *transport.Packet, send
 		return err
 	}
 
+	// Replies to requests we relayed as an onion exit go back along the circuit
+	if forwarded, err := bm.forwardOnionReply(packet, senderAddr); forwarded {
+		return err
+	}
+
 	senderPK, senderID := bm.extractSenderInfo(packet)
 	bm.processSender(senderID, senderAddr)
 	bm.markBootstrapNodeSuccess(senderPK)
@@ -60,18 +70,21 @@ func (bm *BootstrapManager) handleSendNodesPacket(packet *transport.Packet, send
 
 	// If numNodes is 0, that's valid - the sender has no nodes to share
 	// We still processed the sender successfully above
+	var received []*Node
 	if numNodes > 0 {
-		if err := bm.processReceivedNodesWithVersionDetection(packet, numNodes, senderAddr); err != nil {
+		var err error
+		if received, err = bm.processReceivedNodesWithVersionDetection(packet, numNodes, senderAddr); err != nil {
 			return err
 		}
 	}
+	bm.deliverFindNodeResponse(senderPK, received)
 
 	// Also process through gossip handler if available to enable peer-exchange discovery.
 	// This ensures both DHT bootstrap and gossip peer exchange paths execute.
 	if bm.gossipBootstrap != nil {
 		gossipErr := bm.gossipBootstrap.handleSendNodes(packet, senderAddr)
 		if gossipErr != nil {
-			logrus.WithFields(logrus.Fields{
+			pkgLog.WithFields(logrus.Fields{
 				"function": "handleSendNodesPacket",
 				"error":    gossipErr.Error(),
 			}).Debug("Gossip handler returned error, but DHT processing succeeded")
@@ -86,14 +99,14 @@ func (bm *BootstrapManager) handleSendNodesPacket(packet *transport.Packet, send
 // This enables protocol capability discovery and ensures compatibility.
 func (bm *BootstrapManager) handleVersionNegotiationPacket(packet *transport.Packet, senderAddr net.Addr) error {
 	if !bm.enableVersioned || bm.handshakeManager == nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "handleVersionNegotiationPacket",
 			"address":  senderAddr.String(),
 		}).Debug("Version negotiation not enabled, ignoring packet")
 		return nil
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "handleVersionNegotiationPacket",
 		"address":  senderAddr.String(),
 	}).Debug("Processing version negotiation request")
@@ -115,7 +128,7 @@ func (bm *BootstrapManager) handleVersionNegotiationPacket(packet *transport.Pac
 func (bm *BootstrapManager) parseVersionNegotiationPacket(packet *transport.Packet, senderAddr net.Addr) (*transport.VersionNegotiationPacket, error) {
 	vnPacket, err := transport.ParseVersionNegotiation(packet.Data)
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "handleVersionNegotiationPacket",
 			"address":  senderAddr.String(),
 			"error":    err.Error(),
@@ -123,7 +136,7 @@ func (bm *BootstrapManager) parseVersionNegotiationPacket(packet *transport.Pack
 		return nil, fmt.Errorf("failed to parse version negotiation packet: %w", err)
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":         "handleVersionNegotiationPacket",
 		"address":          senderAddr.String(),
 		"peerPreferred":    vnPacket.PreferredVersion.String(),
@@ -150,7 +163,7 @@ func (bm *BootstrapManager) buildSupportedVersions() ([]transport.ProtocolVersio
 func (bm *BootstrapManager) storeNegotiatedVersion(senderAddr net.Addr, negotiatedVersion transport.ProtocolVersion) {
 	bm.SetPeerProtocolVersion(senderAddr, negotiatedVersion)
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":          "handleVersionNegotiationPacket",
 		"address":           senderAddr.String(),
 		"negotiatedVersion": negotiatedVersion.String(),
@@ -166,7 +179,7 @@ func (bm *BootstrapManager) sendVersionResponse(senderAddr net.Addr, ourVersions
 
 	responseData, err := transport.SerializeVersionNegotiation(responsePacket)
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "handleVersionNegotiationPacket",
 			"address":  senderAddr.String(),
 			"error":    err.Error(),
@@ -180,7 +193,7 @@ func (bm *BootstrapManager) sendVersionResponse(senderAddr net.Addr, ourVersions
 	}
 
 	if err := bm.transport.Send(response, senderAddr); err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "handleVersionNegotiationPacket",
 			"address":  senderAddr.String(),
 			"error":    err.Error(),
@@ -215,14 +228,14 @@ func (bm *BootstrapManager) selectBestVersion(peerVersions, ourVersions []transp
 func (bm *BootstrapManager) handleVersionedHandshakePacket(packet *transport.Packet, senderAddr net.Addr) error {
 	if !bm.enableVersioned || bm.handshakeManager == nil {
 		// Versioned handshakes not supported, ignore packet
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "handleVersionedHandshakePacket",
 			"address":  senderAddr.String(),
 		}).Debug("Versioned handshakes not enabled, ignoring packet")
 		return nil
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "handleVersionedHandshakePacket",
 		"address":  senderAddr.String(),
 	}).Debug("Processing versioned handshake request")
@@ -230,7 +243,7 @@ func (bm *BootstrapManager) handleVersionedHandshakePacket(packet *transport.Pac
 	// Parse the versioned handshake request
 	request, err := transport.ParseVersionedHandshakeRequest(packet.Data)
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "handleVersionedHandshakePacket",
 			"address":  senderAddr.String(),
 			"error":    err.Error(),
@@ -241,7 +254,7 @@ func (bm *BootstrapManager) handleVersionedHandshakePacket(packet *transport.Pac
 	// Handle the handshake request (this now sends the response automatically)
 	response, err := bm.handshakeManager.HandleHandshakeRequest(request, bm.transport, senderAddr)
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "handleVersionedHandshakePacket",
 			"address":  senderAddr.String(),
 			"error":    err.Error(),
@@ -249,7 +262,7 @@ func (bm *BootstrapManager) handleVersionedHandshakePacket(packet *transport.Pac
 		return fmt.Errorf("handshake processing failed: %w", err)
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":       "handleVersionedHandshakePacket",
 		"address":        senderAddr.String(),
 		"agreed_version": response.AgreedVersion,
@@ -297,13 +310,15 @@ func (bm *BootstrapManager) markBootstrapNodeSuccess(senderPK [32]byte) {
 		if node.PublicKey == senderPK {
 			node.Success = true
 			node.LastUsed = bm.getTimeProvider().Now()
+			recordBootstrapSuccess(node)
 		}
 	}
 }
 
 // processReceivedNodesWithVersionDetection parses and adds received nodes using version-aware parsing.
 // This method replaces processReceivedNodes() with multi-network and protocol version support.
-func (bm *BootstrapManager) processReceivedNodesWithVersionDetection(packet *transport.Packet, numNodes int, senderAddr net.Addr) error {
+// It returns the nodes that were parsed and accepted.
+func (bm *BootstrapManager) processReceivedNodesWithVersionDetection(packet *transport.Packet, numNodes int, senderAddr net.Addr) ([]*Node, error) {
 	context := bm.initializeVersionDetectionContext(packet, numNodes, senderAddr)
 
 	// Process each node using the version-aware parser
@@ -313,7 +328,7 @@ func (bm *BootstrapManager) processReceivedNodesWithVersionDetection(packet *tra
 		}
 	}
 
-	return nil
+	return context.nodes, nil
 }
 
 // initializeVersionDetectionContext sets up the processing context for version-aware node parsing.
@@ -321,7 +336,7 @@ func (bm *BootstrapManager) processReceivedNodesWithVersionDetection(packet *tra
 func (bm *BootstrapManager) initializeVersionDetectionContext(packet *transport.Packet, numNodes int, senderAddr net.Addr) *nodeProcessingContext {
 	protocolVersion := bm.detectProtocolVersionFromPacket(packet, senderAddr)
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":         "processReceivedNodesWithVersionDetection",
 		"sender":           senderAddr.String(),
 		"protocol_version": protocolVersion,
@@ -350,13 +365,15 @@ func (bm *BootstrapManager) processNodeWithErrorHandling(context *nodeProcessing
 	}
 
 	// Convert to DHT node and add to routing table
-	if err := bm.processNodeEntryVersionAware(entry, context.nospam); err != nil {
-		logrus.WithFields(logrus.Fields{
+	if node, err := bm.addNodeEntryVersionAware(entry, context.nospam); err != nil {
+		pkgLog.WithFields(logrus.Fields{
 			"function":   "processReceivedNodesWithVersionDetection",
 			"node_index": nodeIndex,
 			"error":      err.Error(),
 		}).Warn("Failed to process node entry, skipping")
 		// Continue with next node
+	} else {
+		context.nodes = append(context.nodes, node)
 	}
 
 	context.offset = nextOffset
@@ -366,7 +383,7 @@ func (bm *BootstrapManager) processNodeWithErrorHandling(context *nodeProcessing
 // handleNodeParsingError handles parsing errors and determines recovery strategy.
 // Returns true if processing should continue, false if the packet should be abandoned.
 func (bm *BootstrapManager) handleNodeParsingError(context *nodeProcessingContext, nodeIndex int, err error) bool {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":   "processReceivedNodesWithVersionDetection",
 		"node_index": nodeIndex,
 		"offset":     context.offset,
@@ -395,32 +412,40 @@ type nodeProcessingContext struct {
 	protocolVersion transport.ProtocolVersion
 	nospam          [4]byte
 	offset          int
+	nodes           []*Node // Nodes accepted so far
 }
 
 // processNodeEntryVersionAware processes a single parsed node entry with address type detection.
 // This method replaces the original processNodeEntry with enhanced version awareness and multi-network support.
 func (bm *BootstrapManager) processNodeEntryVersionAware(entry *transport.NodeEntry, nospam [4]byte) error {
+	_, err := bm.addNodeEntryVersionAware(entry, nospam)
+	return err
+}
+
+// addNodeEntryVersionAware implements processNodeEntryVersionAware and
+// returns the node added to the routing table.
+func (bm *BootstrapManager) addNodeEntryVersionAware(entry *transport.NodeEntry, nospam [4]byte) (*Node, error) {
 	// Convert to net.Addr for address type detection
 	addr := entry.Address.ToNetAddr()
 
 	// Check for nil address (malformed packet with empty or invalid address data)
 	if addr == nil {
-		return fmt.Errorf("invalid address: ToNetAddr returned nil for address type %d", entry.Address.Type)
+		return nil, fmt.Errorf("invalid address: ToNetAddr returned nil for address type %d", entry.Address.Type)
 	}
 
 	// Detect and validate address type
 	addrType, err := bm.addressDetector.DetectAddressType(addr)
 	if err != nil {
-		return fmt.Errorf("address type detection failed for %s: %w", addr.String(), err)
+		return nil, fmt.Errorf("address type detection failed for %s: %w", addr.String(), err)
 	}
 
 	// Validate that the address type is supported and routable
 	if !bm.addressDetector.ValidateAddressType(addrType) {
-		return fmt.Errorf("unsupported address type %s for address %s", addrType.String(), addr.String())
+		return nil, fmt.Errorf("unsupported address type %s for address %s", addrType.String(), addr.String())
 	}
 
 	if !bm.addressDetector.IsRoutableAddress(addrType) {
-		return fmt.Errorf("address type %s is not routable for address %s", addrType.String(), addr.String())
+		return nil, fmt.Errorf("address type %s is not routable for address %s", addrType.String(), addr.String())
 	}
 
 	// Update address type statistics
@@ -431,13 +456,13 @@ func (bm *BootstrapManager) processNodeEntryVersionAware(entry *transport.NodeEn
 	// Convert the transport.NodeEntry to a DHT Node
 	newNode, err := bm.convertNodeEntryToNode(entry, nospam)
 	if err != nil {
-		return fmt.Errorf("failed to convert node entry to DHT node: %w", err)
+		return nil, fmt.Errorf("failed to convert node entry to DHT node: %w", err)
 	}
 
 	// Add the node to the routing table
 	bm.routingTable.AddNode(newNode)
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":              "processNodeEntryVersionAware",
 		"node_id":               newNode.ID.String()[:16] + "...",
 		"address":               newNode.Address.String(),
@@ -448,7 +473,7 @@ func (bm *BootstrapManager) processNodeEntryVersionAware(entry *transport.NodeEn
 		"total_nodes_processed": bm.addressStats.TotalCount,
 	}).Debug("Successfully processed node entry with address type detection")
 
-	return nil
+	return newNode, nil
 }
 
 // detectProtocolVersionFromPacket determines the protocol version used by the sender.
@@ -466,7 +491,7 @@ func (bm *BootstrapManager) detectProtocolVersionFromPacket(packet *transport.Pa
 		expectedLegacySize := numNodes * legacyNodeSize
 
 		if dataLen == expectedLegacySize {
-			logrus.WithFields(logrus.Fields{
+			pkgLog.WithFields(logrus.Fields{
 				"function":        "detectProtocolVersionFromPacket",
 				"sender":          senderAddr.String(),
 				"data_length":     dataLen,
@@ -478,7 +503,7 @@ func (bm *BootstrapManager) detectProtocolVersionFromPacket(packet *transport.Pa
 
 	// For packets that don't match legacy format exactly, assume extended format
 	// In a complete implementation, this would check for version negotiation state
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":    "detectProtocolVersionFromPacket",
 		"sender":      senderAddr.String(),
 		"data_length": dataLen,
@@ -553,6 +578,12 @@ func (bm *BootstrapManager) handlePingResponsePacket(packet *transport.Packet, s
 	if len(packet.Data) < 32 { // Minimum size: sender's public key
 		return errors.New("invalid ping response packet: too short")
 	}
+	if bm.confirmMigration(packet.Data, senderAddr) {
+		return nil
+	}
+
+	// Resolve a bootstrap health check if this echoes its ping
+	bm.confirmHealthPing(packet.Data, senderAddr)
 
 	// Extract sender's public key
 	var senderPK [32]byte
@@ -562,6 +593,17 @@ func (bm *BootstrapManager) handlePingResponsePacket(packet *transport.Packet, s
 	var nospam [4]byte
 	senderID := crypto.NewToxID(senderPK, nospam)
 
+	// A known node keeps its ping statistics; the response may also yield
+	// an RTT sample for the ping we sent it
+	if existing := bm.routingTable.getNode(senderPK); existing != nil && senderAddr != nil &&
+		existing.Address != nil && existing.Address.String() == senderAddr.String() {
+		if rtt, ok := existing.recordPingResponse(true, nil); ok {
+			bm.routingTable.recordRTT(rtt)
+		}
+		bm.routingTable.AddNode(existing)
+		return nil
+	}
+
 	// Update sender in routing table as good
 	senderNode := NewNode(*senderID, senderAddr)
 	senderNode.Update(StatusGood)
@@ -570,6 +612,27 @@ func (bm *BootstrapManager) handlePingResponsePacket(packet *transport.Packet, s
 	return nil
 }
 
+// handleMigrationNotifyPacket processes a peer's announcement that it has
+// moved to a new network address. The notification is unauthenticated, so
+// only nodes already in the routing table are considered, and the entry is
+// not updated until a ping round trip with the new address succeeds (see
+// confirmMigration).
+func (bm *BootstrapManager) handleMigrationNotifyPacket(packet *transport.Packet, senderAddr net.Addr) error {
+	notification, err := transport.ParseMigrationNotification(packet.Data)
+	if err != nil {
+		return err
+	}
+	if senderAddr == nil {
+		return errors.New("migration notification without sender address")
+	}
+
+	node := bm.routingTable.getNode(notification.PublicKey)
+	if node == nil || (node.Address != nil && node.Address.String() == senderAddr.String()) {
+		return nil
+	}
+	return bm.probeMigratedNode(notification.PublicKey, senderAddr)
+}
+
 // Add this method to the BootstrapManager struct
 
 // handleGetNodesPacket processes a get_nodes request packet and responds with
@@ -604,7 +667,7 @@ func (bm *BootstrapManager) determineResponseProtocolVersion(senderAddr net.Addr
 	bm.versionMu.RUnlock()
 
 	if hasNegotiatedVersion {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "determineResponseProtocolVersion",
 			"sender":   senderAddr.String(),
 			"version":  negotiatedVersion,
@@ -616,14 +679,14 @@ func (bm *BootstrapManager) determineResponseProtocolVersion(senderAddr net.Addr
 	if bm.enableVersioned {
 		// If we support versioned handshakes, we can try the extended format
 		// This assumes peers that support versioned handshakes also support extended node formats
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "determineResponseProtocolVersion",
 			"sender":   senderAddr.String(),
 		}).Debug("Using extended protocol for response (no negotiated version)")
 		return transport.ProtocolNoiseIK
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "determineResponseProtocolVersion",
 		"sender":   senderAddr.String(),
 	}).Debug("Using legacy protocol for response")
@@ -679,7 +742,7 @@ func (bm *BootstrapManager) isNodeCompatible(node *Node, supportedTypeMap map[tr
 	// Detect address type for each node
 	addrType, err := bm.addressDetector.DetectAddressType(node.Address)
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "isNodeCompatible",
 			"node_id":  node.ID.String()[:16] + "...",
 			"address":  node.Address.String(),
@@ -690,7 +753,7 @@ func (bm *BootstrapManager) isNodeCompatible(node *Node, supportedTypeMap map[tr
 
 	// Check if this address type is supported by the target protocol
 	if !supportedTypeMap[addrType] {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":         "isNodeCompatible",
 			"node_id":          node.ID.String()[:16] + "...",
 			"address":          node.Address.String(),
@@ -702,7 +765,7 @@ func (bm *BootstrapManager) isNodeCompatible(node *Node, supportedTypeMap map[tr
 
 	// Validate that the address is routable
 	if !bm.addressDetector.IsRoutableAddress(addrType) {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":     "isNodeCompatible",
 			"node_id":      node.ID.String()[:16] + "...",
 			"address":      node.Address.String(),
@@ -731,7 +794,7 @@ func (bm *BootstrapManager) serializeFilteredNodes(responseData []byte, filtered
 		// Convert DHT Node to transport.NodeEntry
 		entry, err := bm.convertNodeToNodeEntry(node)
 		if err != nil {
-			logrus.WithError(err).WithField("node", node.ID.String()).
+			pkgLog.WithError(err).WithField("node", node.ID.String()).
 				Warn("Failed to convert node to entry, skipping")
 			continue
 		}
@@ -739,7 +802,7 @@ func (bm *BootstrapManager) serializeFilteredNodes(responseData []byte, filtered
 		// Serialize the node entry
 		nodeBytes, err := parser.SerializeNodeEntry(entry)
 		if err != nil {
-			logrus.WithError(err).WithField("node", node.ID.String()).
+			pkgLog.WithError(err).WithField("node", node.ID.String()).
 				Warn("Failed to serialize node entry, skipping")
 			continue
 		}
@@ -754,7 +817,7 @@ func (bm *BootstrapManager) serializeFilteredNodes(responseData []byte, filtered
 
 // logResponseSummary logs a summary of the response building process.
 func (bm *BootstrapManager) logResponseSummary(protocolVersion transport.ProtocolVersion, originalNodes, filteredNodes, responseSize int) {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":         "buildVersionedResponseData",
 		"protocol_version": protocolVersion,
 		"original_nodes":   originalNodes,
@@ -830,7 +893,7 @@ func (bm *BootstrapManager) encodeNodeEntry(responseData []byte, offset int, nod
 	entry, err := bm.convertNodeToNodeEntry(node)
 	if err != nil {
 		// Log error and skip this node entry
-		logrus.WithError(err).WithField("node", node.ID.String()).
+		pkgLog.WithError(err).WithField("node", node.ID.String()).
 			Warn("Failed to convert node to entry, skipping")
 		return offset
 	}
@@ -839,7 +902,7 @@ func (bm *BootstrapManager) encodeNodeEntry(responseData []byte, offset int, nod
 	serialized, err := bm.serializeNodeEntry(entry)
 	if err != nil {
 		// Log error and skip this node entry
-		logrus.WithError(err).WithField("node", node.ID.String()).
+		pkgLog.WithError(err).WithField("node", node.ID.String()).
 			Warn("Failed to serialize node entry, skipping")
 		return offset
 	}
@@ -850,7 +913,7 @@ func (bm *BootstrapManager) encodeNodeEntry(responseData []byte, offset int, nod
 		return offset + len(serialized)
 	} else {
 		// Not enough space in response buffer
-		logrus.WithField("node", node.ID.String()).WithField("needed", len(serialized)).
+		pkgLog.WithField("node", node.ID.String()).WithField("needed", len(serialized)).
 			WithField("available", len(responseData)-offset).
 			Warn("Insufficient space in response buffer for node entry")
 		return offset
@@ -146,6 +146,7 @@ func (il *IterativeLookup) FindNode(ctx context.Context, targetKey [32]byte) *It
 	result := &IterativeLookupResult{
 		QueriedNodes: make(map[[32]byte]struct{}),
 	}
+	defer il.recordLookup(result)
 
 	// Initialize with closest nodes from our routing table
 	targetNode, candidates, err := il.initializeCandidates(targetKey)
@@ -185,6 +186,13 @@ func (il *IterativeLookup) FindNode(ctx context.Context, targetKey [32]byte) *It
 	return result
 }
 
+// recordLookup reports a finished lookup to the routing table metrics.
+func (il *IterativeLookup) recordLookup(result *IterativeLookupResult) {
+	if il.routingTable != nil {
+		il.routingTable.RecordLookup(result.Duration)
+	}
+}
+
 // initializeCandidates sets up the initial candidate set from the routing table.
 func (il *IterativeLookup) initializeCandidates(targetKey [32]byte) (*Node, *nodeSet, error) {
 	var targetNospam [4]byte
@@ -328,17 +336,7 @@ func (il *IterativeLookup) queryNode(ctx context.Context, node *Node, targetKey
 		il.responsesMu.Unlock()
 	}()
 
-	// Create and send FIND_NODE packet
-	data := make([]byte, 64)
-	copy(data[:32], il.selfID.PublicKey[:]) // Our public key
-	copy(data[32:], targetKey[:])           // Target key
-
-	packet := &transport.Packet{
-		PacketType: transport.PacketGetNodes,
-		Data:       data,
-	}
-
-	if err := il.transport.Send(packet, node.Address); err != nil {
+	if err := il.sendFindNode(node, targetKey); err != nil {
 		return nil, err
 	}
 
@@ -353,6 +351,38 @@ func (il *IterativeLookup) queryNode(ctx context.Context, node *Node, targetKey
 	}
 }
 
+// sendFindNode sends a FIND_NODE request to node, through an onion circuit
+// when the routing table has onion routing enabled.
+func (il *IterativeLookup) sendFindNode(node *Node, targetKey [32]byte) error {
+	senderKey := il.selfID.PublicKey
+	var circuit *OnionCircuit
+	if il.routingTable != nil && il.routingTable.OnionEnabled() {
+		var err error
+		if circuit, err = il.routingTable.OnionCircuit(node.PublicKey); err != nil {
+			return err
+		}
+		// Our long-term key would identify us to the queried node
+		senderKey = circuit.EphemeralPublicKey()
+	}
+
+	data := make([]byte, 64)
+	copy(data[:32], senderKey[:])
+	copy(data[32:], targetKey[:])
+	packet := &transport.Packet{
+		PacketType: transport.PacketGetNodes,
+		Data:       data,
+	}
+
+	if circuit == nil {
+		return il.transport.Send(packet, node.Address)
+	}
+	wrapped, firstHop, err := il.routingTable.wrapOnionRequest(circuit, node.Address, packet)
+	if err != nil {
+		return err
+	}
+	return il.transport.Send(wrapped, firstHop)
+}
+
 // HandleNodesResponse handles incoming NODES response packets.
 // This should be called from the transport layer when a NODES response is received.
 // It sends the response to all pending queries from the given node.
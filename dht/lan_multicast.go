@@ -0,0 +1,154 @@
+package dht
+
+import (
+	"net"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// lanPeerDedupWindow is how long a peer announcement suppresses further
+// OnPeer events for the same public key. It is shorter than the announcement
+// interval, so a peer heard by broadcast and on several multicast interfaces
+// is reported once per announcement.
+const lanPeerDedupWindow = lanDiscoveryInterval / 2
+
+// lanMulticastGroupIP is the IPv6 link-local all-nodes group LAN discovery
+// announces to, since IPv6 has no broadcast.
+var lanMulticastGroupIP = net.ParseIP("ff02::1")
+
+// lanMulticastConn is a socket joined to the all-nodes group on one
+// interface.
+type lanMulticastConn struct {
+	iface string
+	conn  net.PacketConn
+	dest  *net.UDPAddr // Group address scoped to iface
+}
+
+// listenLANMulticast joins group on ifi with net.ListenMulticastUDP.
+func listenLANMulticast(ifi *net.Interface, group *net.UDPAddr) (net.PacketConn, error) {
+	return net.ListenMulticastUDP("udp6", ifi, group)
+}
+
+// eligibleMulticastInterface reports whether ifi is up, supports multicast
+// and is not a loopback interface.
+func eligibleMulticastInterface(ifi net.Interface) bool {
+	return ifi.Flags&net.FlagUp != 0 &&
+		ifi.Flags&net.FlagMulticast != 0 &&
+		ifi.Flags&net.FlagLoopback == 0
+}
+
+// joinMulticastGroupsLocked joins the all-nodes group on every eligible
+// interface and starts a receiver for each. IPv6 is optional: interfaces
+// that cannot join are skipped. Caller must hold ld.mu.
+func (ld *LANDiscovery) joinMulticastGroupsLocked() {
+	listInterfaces, listen := ld.interfaces, ld.listenMulticast
+	if listInterfaces == nil {
+		listInterfaces = net.Interfaces
+	}
+	if listen == nil {
+		listen = listenLANMulticast
+	}
+
+	ifaces, err := listInterfaces()
+	if err != nil {
+		pkgLog.WithError(err).Debug("dht: cannot list interfaces for LAN multicast")
+		return
+	}
+
+	group := &net.UDPAddr{IP: lanMulticastGroupIP, Port: int(ld.discoveryPort)}
+	for i := range ifaces {
+		ifi := ifaces[i]
+		if !eligibleMulticastInterface(ifi) {
+			continue
+		}
+		conn, err := listen(&ifi, group)
+		if err != nil {
+			pkgLog.WithFields(logrus.Fields{
+				"function":  "joinMulticastGroups",
+				"interface": ifi.Name,
+				"error":     err.Error(),
+			}).Debug("Failed to join LAN discovery multicast group")
+			continue
+		}
+		mc := &lanMulticastConn{
+			iface: ifi.Name,
+			conn:  conn,
+			dest:  &net.UDPAddr{IP: lanMulticastGroupIP, Port: int(ld.discoveryPort), Zone: ifi.Name},
+		}
+		ld.multicast = append(ld.multicast, mc)
+
+		ld.wg.Add(1)
+		go ld.multicastReceiveLoop(conn)
+	}
+
+	if len(ld.multicast) > 0 {
+		pkgLog.WithFields(logrus.Fields{
+			"function":   "joinMulticastGroups",
+			"group":      lanMulticastGroupIP.String(),
+			"interfaces": len(ld.multicast),
+		}).Info("Joined LAN discovery multicast group")
+	}
+}
+
+// leaveMulticastGroupsLocked closes every multicast socket, which leaves the
+// groups they joined. Caller must hold ld.mu.
+func (ld *LANDiscovery) leaveMulticastGroupsLocked() {
+	for _, mc := range ld.multicast {
+		mc.conn.Close()
+	}
+	ld.multicast = nil
+}
+
+// sendMulticast sends packet to the all-nodes group on every joined
+// interface. It returns true if any send succeeded.
+func (ld *LANDiscovery) sendMulticast(packet []byte) bool {
+	ld.mu.RLock()
+	groups := append([]*lanMulticastConn(nil), ld.multicast...)
+	ld.mu.RUnlock()
+
+	sent := false
+	for _, mc := range groups {
+		if _, err := mc.conn.WriteTo(packet, mc.dest); err != nil {
+			pkgLog.WithFields(logrus.Fields{"interface": mc.iface, "error": err.Error()}).Debug("Failed to send LAN discovery multicast")
+			continue
+		}
+		sent = true
+	}
+	return sent
+}
+
+// multicastReceiveLoop handles announcements arriving on one multicast socket.
+func (ld *LANDiscovery) multicastReceiveLoop(conn net.PacketConn) {
+	defer ld.wg.Done()
+
+	buffer := make([]byte, 1024)
+	for !ld.checkStopSignal() {
+		if !ld.receiveAndHandlePacket(conn, buffer) {
+			return
+		}
+	}
+}
+
+// markPeerSeen records an announcement from publicKey and reports whether it
+// should produce an OnPeer event, which is the case unless the same key was
+// reported less than lanPeerDedupWindow ago.
+func (ld *LANDiscovery) markPeerSeen(publicKey [32]byte) bool {
+	ld.mu.Lock()
+	defer ld.mu.Unlock()
+
+	now := time.Now()
+	if last, ok := ld.seenPeers[publicKey]; ok && now.Sub(last) < lanPeerDedupWindow {
+		return false
+	}
+	if ld.seenPeers == nil {
+		ld.seenPeers = make(map[[32]byte]time.Time)
+	}
+	for key, last := range ld.seenPeers {
+		if now.Sub(last) >= lanPeerDedupWindow {
+			delete(ld.seenPeers, key)
+		}
+	}
+	ld.seenPeers[publicKey] = now
+	return true
+}
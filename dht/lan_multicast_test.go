@@ -0,0 +1,211 @@
+package dht
+
+import (
+	"errors"
+	"math/rand"
+	"net"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeMulticastConn is a net.PacketConn standing in for a socket joined to
+// the all-nodes group on one interface.
+type fakeMulticastConn struct {
+	incoming chan fakeDatagram
+	closed   chan struct{}
+
+	mu     sync.Mutex
+	sent   []*net.UDPAddr
+	isShut bool
+}
+
+type fakeDatagram struct {
+	data []byte
+	from net.Addr
+}
+
+func newFakeMulticastConn() *fakeMulticastConn {
+	return &fakeMulticastConn{incoming: make(chan fakeDatagram, 4), closed: make(chan struct{})}
+}
+
+func (c *fakeMulticastConn) ReadFrom(b []byte) (int, net.Addr, error) {
+	select {
+	case d := <-c.incoming:
+		return copy(b, d.data), d.from, nil
+	case <-c.closed:
+		return 0, nil, net.ErrClosed
+	}
+}
+
+func (c *fakeMulticastConn) WriteTo(b []byte, addr net.Addr) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.isShut {
+		return 0, net.ErrClosed
+	}
+	c.sent = append(c.sent, addr.(*net.UDPAddr))
+	return len(b), nil
+}
+
+func (c *fakeMulticastConn) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.isShut {
+		c.isShut = true
+		close(c.closed)
+	}
+	return nil
+}
+
+func (c *fakeMulticastConn) shut() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.isShut
+}
+
+func (c *fakeMulticastConn) LocalAddr() net.Addr                { return &net.UDPAddr{IP: net.IPv6unspecified} }
+func (c *fakeMulticastConn) SetDeadline(t time.Time) error      { return nil }
+func (c *fakeMulticastConn) SetReadDeadline(t time.Time) error  { return nil }
+func (c *fakeMulticastConn) SetWriteDeadline(t time.Time) error { return nil }
+
+// mockInterfaces is the interface list used by the multicast tests.
+func mockInterfaces() ([]net.Interface, error) {
+	return []net.Interface{
+		{Index: 1, Name: "lo", Flags: net.FlagUp | net.FlagLoopback | net.FlagMulticast},
+		{Index: 2, Name: "eth0", Flags: net.FlagUp | net.FlagBroadcast | net.FlagMulticast},
+		{Index: 3, Name: "wlan0", Flags: net.FlagUp | net.FlagMulticast},
+		{Index: 4, Name: "eth1", Flags: net.FlagBroadcast | net.FlagMulticast}, // down
+		{Index: 5, Name: "tun0", Flags: net.FlagUp | net.FlagPointToPoint},
+	}, nil
+}
+
+// newMulticastTestDiscovery creates a LANDiscovery on a random port whose
+// multicast joins are served by fake sockets, returned by interface name.
+func newMulticastTestDiscovery(t *testing.T, key byte) (*LANDiscovery, map[string]*fakeMulticastConn) {
+	t.Helper()
+	ld := NewLANDiscovery([32]byte{key}, uint16(40000+rand.Intn(10000)))
+	ld.interfaces = mockInterfaces
+
+	var mu sync.Mutex
+	conns := make(map[string]*fakeMulticastConn)
+	ld.listenMulticast = func(ifi *net.Interface, group *net.UDPAddr) (net.PacketConn, error) {
+		if !group.IP.Equal(net.ParseIP("ff02::1")) || group.Port != int(ld.discoveryPort) {
+			t.Errorf("Unexpected multicast group %s", group)
+		}
+		conn := newFakeMulticastConn()
+		mu.Lock()
+		conns[ifi.Name] = conn
+		mu.Unlock()
+		return conn, nil
+	}
+	return ld, conns
+}
+
+func TestLANDiscoveryJoinsAndLeavesMulticastGroups(t *testing.T) {
+	ld, conns := newMulticastTestDiscovery(t, 0x01)
+	if err := ld.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	if len(conns) != 2 || conns["eth0"] == nil || conns["wlan0"] == nil {
+		t.Fatalf("Expected to join on eth0 and wlan0 only, joined %d interfaces", len(conns))
+	}
+
+	ld.broadcast()
+	for name, conn := range conns {
+		conn.mu.Lock()
+		sent := append([]*net.UDPAddr(nil), conn.sent...)
+		conn.mu.Unlock()
+		if len(sent) == 0 {
+			t.Errorf("Expected an announcement on %s", name)
+			continue
+		}
+		dest := sent[len(sent)-1]
+		if !dest.IP.Equal(net.ParseIP("ff02::1")) || dest.Zone != name || dest.Port != int(ld.discoveryPort) {
+			t.Errorf("Expected announcement to [ff02::1%%%s]:%d, got %s", name, ld.discoveryPort, dest)
+		}
+	}
+
+	if err := ld.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	for name, conn := range conns {
+		if !conn.shut() {
+			t.Errorf("Expected the %s multicast socket to be closed", name)
+		}
+	}
+	if len(ld.multicast) != 0 {
+		t.Errorf("Expected no joined groups after Close, got %d", len(ld.multicast))
+	}
+}
+
+func TestLANDiscoveryWithoutIPv6(t *testing.T) {
+	ld := NewLANDiscovery([32]byte{0x01}, uint16(40000+rand.Intn(10000)))
+	ld.interfaces = mockInterfaces
+	ld.listenMulticast = func(*net.Interface, *net.UDPAddr) (net.PacketConn, error) {
+		return nil, errors.New("address family not supported")
+	}
+	if err := ld.Start(); err != nil {
+		t.Fatalf("Expected Start to succeed over IPv4 alone, got %v", err)
+	}
+	defer ld.Stop()
+
+	ld.mu.RLock()
+	defer ld.mu.RUnlock()
+	if len(ld.multicast) != 0 {
+		t.Errorf("Expected no multicast groups, got %d", len(ld.multicast))
+	}
+}
+
+func TestLANDiscoveryDeduplicatesPeersAcrossInterfaces(t *testing.T) {
+	ld, conns := newMulticastTestDiscovery(t, 0x01)
+
+	var mu sync.Mutex
+	var events []net.Addr
+	ld.OnPeer(func(publicKey [32]byte, addr net.Addr) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, addr)
+	})
+	if err := ld.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer ld.Stop()
+
+	peer := LANDiscoveryPacketData([32]byte{0x02}, 33445)
+	from := &net.UDPAddr{IP: net.ParseIP("fe80::2"), Port: int(ld.discoveryPort), Zone: "eth0"}
+	conns["eth0"].incoming <- fakeDatagram{data: peer, from: from}
+	conns["wlan0"].incoming <- fakeDatagram{data: peer, from: &net.UDPAddr{IP: net.ParseIP("fe80::2"), Port: int(ld.discoveryPort), Zone: "wlan0"}}
+	ld.handlePacket(peer, &net.UDPAddr{IP: net.ParseIP("192.168.1.2"), Port: int(ld.discoveryPort)})
+
+	other := LANDiscoveryPacketData([32]byte{0x03}, 33445)
+	conns["eth0"].incoming <- fakeDatagram{data: other, from: from}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		mu.Lock()
+		n := len(events)
+		mu.Unlock()
+		if n >= 2 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	time.Sleep(50 * time.Millisecond) // Let any duplicate arrive
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(events) != 2 {
+		t.Fatalf("Expected one event per public key, got %d: %v", len(events), events)
+	}
+	for _, addr := range events {
+		udp := addr.(*net.UDPAddr)
+		if udp.Port != 33445 {
+			t.Errorf("Expected the announced port 33445, got %d", udp.Port)
+		}
+		if udp.IP.To4() == nil && udp.Zone == "" {
+			t.Errorf("Expected link-local peer %s to keep its zone", udp)
+		}
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/binary"
 	"fmt"
 	"net"
+	"strings"
 	"sync"
 	"time"
 
@@ -18,9 +19,10 @@ const (
 	lanDiscoveryTimeout  = 60 * time.Second
 )
 
-// LANDiscovery handles local area network peer discovery via UDP broadcast.
-// It also provides mDNS fallback for environments where broadcast doesn't work
-// (e.g., Docker bridge networks, Kubernetes pods).
+// LANDiscovery handles local area network peer discovery via UDP broadcast
+// and, where IPv6 is available, the ff02::1 all-nodes multicast group on each
+// multicast-capable interface. It also provides mDNS fallback for environments
+// where neither works (e.g., Docker bridge networks, Kubernetes pods).
 type LANDiscovery struct {
 	enabled       bool
 	publicKey     [32]byte
@@ -36,6 +38,15 @@ type LANDiscovery struct {
 	mdns           *MDNSDiscovery
 	mdnsEnabled    bool
 	broadcastFails int // Count of consecutive broadcast failures
+
+	// IPv6 multicast sockets, one per joined interface
+	multicast []*lanMulticastConn
+	// Last OnPeer event per public key, for deduplication
+	seenPeers map[[32]byte]time.Time
+
+	// Interface listing and multicast join, replaceable in tests
+	interfaces      func() ([]net.Interface, error)
+	listenMulticast func(ifi *net.Interface, group *net.UDPAddr) (net.PacketConn, error)
 }
 
 // NewLANDiscovery creates a new LAN discovery instance.
@@ -72,10 +83,11 @@ func (ld *LANDiscovery) Start() error {
 		return nil
 	}
 
-	// Create UDP connection for broadcasting
-	conn, err := net.ListenPacket("udp", fmt.Sprintf(":%d", ld.discoveryPort))
+	// Create UDP connection for broadcasting. It is IPv4-only so the IPv6
+	// multicast sockets can share the discovery port.
+	conn, err := net.ListenPacket("udp4", fmt.Sprintf(":%d", ld.discoveryPort))
 	if err != nil {
-		logrus.WithError(err).Error("Failed to create LAN discovery socket")
+		pkgLog.WithError(err).Error("Failed to create LAN discovery socket")
 		return fmt.Errorf("failed to create LAN discovery socket: %w", err)
 	}
 
@@ -85,6 +97,8 @@ func (ld *LANDiscovery) Start() error {
 	// Recreate stopChan so it's fresh for this start session
 	ld.stopChan = make(chan struct{})
 
+	ld.joinMulticastGroupsLocked()
+
 	// Start broadcast goroutine
 	ld.wg.Add(1)
 	go ld.broadcastLoop()
@@ -93,7 +107,7 @@ func (ld *LANDiscovery) Start() error {
 	ld.wg.Add(1)
 	go ld.receiveLoop()
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"port": ld.discoveryPort,
 	}).Info("LAN discovery started")
 
@@ -113,12 +127,20 @@ func (ld *LANDiscovery) Stop() {
 	ld.stopMDNSLocked()
 	ld.closeStopChannelLocked()
 	ld.closeConnLocked()
+	ld.leaveMulticastGroupsLocked()
 	ld.mu.Unlock()
 
 	// Wait for goroutines to finish
 	ld.wg.Wait()
 
-	logrus.Info("LAN discovery stopped")
+	pkgLog.Info("LAN discovery stopped")
+}
+
+// Close stops LAN discovery and leaves every multicast group it joined.
+// It implements io.Closer and always returns nil.
+func (ld *LANDiscovery) Close() error {
+	ld.Stop()
+	return nil
 }
 
 // stopMDNSLocked stops the fallback mDNS discovery while ld.mu is held.
@@ -178,8 +200,9 @@ func (ld *LANDiscovery) broadcastLoop() {
 	}
 }
 
-// broadcast sends a LAN discovery packet to the broadcast address.
-// If broadcast fails repeatedly, it enables mDNS as a fallback.
+// broadcast sends a LAN discovery packet to the IPv4 broadcast addresses and
+// the IPv6 multicast groups. If every send fails repeatedly, it enables mDNS
+// as a fallback.
 func (ld *LANDiscovery) broadcast() {
 	ld.mu.RLock()
 	conn := ld.conn
@@ -197,6 +220,9 @@ func (ld *LANDiscovery) broadcast() {
 	if ld.sendPrivateBroadcasts(conn, packet, discoveryPort) {
 		anySuccess = true
 	}
+	if ld.sendMulticast(packet) {
+		anySuccess = true
+	}
 	ld.recordBroadcastResult(anySuccess)
 }
 
@@ -212,11 +238,11 @@ func (ld *LANDiscovery) buildBroadcastPacket(publicKey [32]byte, port uint16) []
 func (ld *LANDiscovery) sendPrimaryBroadcast(conn net.PacketConn, packet []byte, discoveryPort, port uint16) bool {
 	broadcastAddr := transport.NewUDPAddr(net.IPv4bcast, int(discoveryPort))
 	if _, err := conn.WriteTo(packet, broadcastAddr); err != nil {
-		logrus.WithError(err).Debug("Failed to send IPv4 LAN discovery broadcast")
+		pkgLog.WithError(err).Debug("Failed to send IPv4 LAN discovery broadcast")
 		return false
 	}
 
-	logrus.WithFields(logrus.Fields{"addr": broadcastAddr.String(), "port": port}).Debug("Sent LAN discovery broadcast")
+	pkgLog.WithFields(logrus.Fields{"addr": broadcastAddr.String(), "port": port}).Debug("Sent LAN discovery broadcast")
 	return true
 }
 
@@ -227,7 +253,7 @@ func (ld *LANDiscovery) sendPrivateBroadcasts(conn net.PacketConn, packet []byte
 	for _, bcAddr := range privateBroadcasts {
 		addr := transport.NewUDPAddr(net.ParseIP(bcAddr), int(discoveryPort))
 		if _, err := conn.WriteTo(packet, addr); err != nil {
-			logrus.WithFields(logrus.Fields{"addr": bcAddr, "port": discoveryPort, "error": err.Error()}).Debug("Failed to send LAN discovery broadcast to private network")
+			pkgLog.WithFields(logrus.Fields{"addr": bcAddr, "port": discoveryPort, "error": err.Error()}).Debug("Failed to send LAN discovery broadcast to private network")
 			continue
 		}
 		sent = true
@@ -289,7 +315,7 @@ func (ld *LANDiscovery) enableMDNSFallback() {
 
 	// Start mDNS
 	if err := mdns.Start(); err != nil {
-		logrus.WithError(err).Warn("Failed to start mDNS fallback discovery")
+		pkgLog.WithError(err).Warn("Failed to start mDNS fallback discovery")
 		return
 	}
 
@@ -297,7 +323,7 @@ func (ld *LANDiscovery) enableMDNSFallback() {
 	ld.mdnsEnabled = true
 	ld.mu.Unlock()
 
-	logrus.Info("Enabled mDNS fallback for local discovery (broadcast not working)")
+	pkgLog.Info("Enabled mDNS fallback for local discovery (broadcast not working)")
 }
 
 // EnableMDNS manually enables mDNS discovery alongside broadcast.
@@ -331,7 +357,7 @@ func (ld *LANDiscovery) EnableMDNS() error {
 	ld.mdnsEnabled = true
 	ld.mu.Unlock()
 
-	logrus.Info("mDNS discovery enabled")
+	pkgLog.Info("mDNS discovery enabled")
 	return nil
 }
 
@@ -409,7 +435,7 @@ func (ld *LANDiscovery) receiveAndHandlePacket(conn net.PacketConn, buffer []byt
 func (ld *LANDiscovery) handlePacket(data []byte, addr net.Addr) {
 	// LAN discovery packet format: [public key (32 bytes)][port (2 bytes)]
 	if len(data) < 34 {
-		logrus.Debug("Received invalid LAN discovery packet (too short)")
+		pkgLog.Debug("Received invalid LAN discovery packet (too short)")
 		return
 	}
 
@@ -434,9 +460,11 @@ func (ld *LANDiscovery) handlePacket(data []byte, addr net.Addr) {
 		host = addr.String()
 	}
 
+	// Link-local IPv6 senders carry the interface as a zone
+	host, zone, _ := strings.Cut(host, "%")
 	ip := net.ParseIP(host)
 	if ip == nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"address": addr.String(),
 		}).Debug("Failed to parse IP from LAN discovery address")
 		return
@@ -444,8 +472,17 @@ func (ld *LANDiscovery) handlePacket(data []byte, addr net.Addr) {
 
 	// Create peer address with the port from the packet
 	peerAddr := transport.NewUDPAddr(ip, int(port))
+	if zone != "" {
+		peerAddr = &net.UDPAddr{IP: ip, Port: int(port), Zone: zone}
+	}
+
+	// The same announcement may arrive by broadcast and on several
+	// multicast interfaces
+	if !ld.markPeerSeen(publicKey) {
+		return
+	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"peer_addr":  peerAddr.String(),
 		"public_key": fmt.Sprintf("%x", publicKey[:8]),
 	}).Info("Discovered LAN peer")
@@ -0,0 +1,15 @@
+package dht
+
+import (
+	"github.com/opd-ai/toxcore/logging"
+	"github.com/sirupsen/logrus"
+)
+
+// pkgLog is the logger for this package, tagged with component "dht".
+var pkgLog = logging.NewComponent("dht")
+
+// SetLogLevel sets the log level for this package independently of the
+// global logrus level.
+func SetLogLevel(level logrus.Level) {
+	pkgLog.SetLevel(level)
+}
@@ -3,6 +3,8 @@ package dht
 import (
 	"context"
 	"crypto/rand"
+	"errors"
+	"fmt"
 	"net"
 	"sync"
 	"time"
@@ -22,6 +24,40 @@ type MaintenanceConfig struct {
 	NodeTimeout time.Duration
 	// How long before a bad node is removed
 	PruneTimeout time.Duration
+	// How many FIND_NODE queries the bootstrapper's lookup keeps in flight
+	// (zero means the Kademlia default, Alpha)
+	Alpha int
+}
+
+// Minimum maintenance intervals accepted by Maintainer.Reconfigure. They keep
+// a misconfigured node from flooding the network, or itself, with maintenance
+// traffic.
+const (
+	MinPingInterval   = 5 * time.Second
+	MinLookupInterval = 30 * time.Second
+)
+
+// ErrInvalidMaintenanceConfig is returned by Maintainer.Reconfigure when an
+// interval or timeout is out of range.
+var ErrInvalidMaintenanceConfig = errors.New("invalid maintenance config")
+
+// Validate checks that the intervals are not below MinPingInterval and
+// MinLookupInterval, that both timeouts are positive and that Alpha is not
+// negative.
+func (c MaintenanceConfig) Validate() error {
+	switch {
+	case c.PingInterval < MinPingInterval:
+		return fmt.Errorf("%w: ping interval %v below minimum %v", ErrInvalidMaintenanceConfig, c.PingInterval, MinPingInterval)
+	case c.LookupInterval < MinLookupInterval:
+		return fmt.Errorf("%w: lookup interval %v below minimum %v", ErrInvalidMaintenanceConfig, c.LookupInterval, MinLookupInterval)
+	case c.NodeTimeout <= 0:
+		return fmt.Errorf("%w: node timeout must be positive", ErrInvalidMaintenanceConfig)
+	case c.PruneTimeout <= 0:
+		return fmt.Errorf("%w: prune timeout must be positive", ErrInvalidMaintenanceConfig)
+	case c.Alpha < 0:
+		return fmt.Errorf("%w: alpha must not be negative", ErrInvalidMaintenanceConfig)
+	}
+	return nil
 }
 
 // DefaultMaintenanceConfig returns sensible defaults for DHT maintenance.
@@ -31,6 +67,7 @@ func DefaultMaintenanceConfig() *MaintenanceConfig {
 		LookupInterval: 5 * time.Minute,
 		NodeTimeout:    10 * time.Minute,
 		PruneTimeout:   1 * time.Hour,
+		Alpha:          Alpha,
 	}
 }
 
@@ -51,6 +88,13 @@ type Maintainer struct {
 	isRunning    bool
 	lastActivity time.Time
 	timeProvider TimeProvider
+	metrics      maintainerMetrics
+
+	// reconfigMu serializes Reconfigure calls. routineCancel stops the
+	// current generation of maintenance routines without stopping the
+	// Maintainer, so Reconfigure can restart them.
+	reconfigMu    sync.Mutex
+	routineCancel context.CancelFunc
 }
 
 // NewMaintainer creates a new DHT maintenance manager.
@@ -65,6 +109,9 @@ func NewMaintainer(routingTable *RoutingTable, bootstrapper *BootstrapManager,
 	}
 
 	ctx, cancel := context.WithCancel(context.Background())
+	if bootstrapper != nil {
+		bootstrapper.SetLookupAlpha(config.Alpha)
+	}
 
 	return &Maintainer{
 		routingTable: routingTable,
@@ -107,16 +154,81 @@ func (m *Maintainer) Start() error {
 	}
 
 	m.isRunning = true
+	m.startRoutines()
+
+	return nil
+}
+
+// startRoutines launches the maintenance routines with the current config.
+// The caller must hold m.mu.
+func (m *Maintainer) startRoutines() {
+	ctx, cancel := context.WithCancel(m.ctx)
+	m.routineCancel = cancel
+	cfg := *m.config
+
 	m.wg.Add(3)
+	go m.pingRoutine(ctx, cfg.PingInterval)
+	go m.lookupRoutine(ctx, cfg.LookupInterval)
+	go m.pruneRoutine(ctx, cfg.PingInterval) // Reuse ping interval
+}
+
+// Reconfigure atomically replaces the maintenance configuration. If the
+// Maintainer is running, its routines are stopped, any in-flight ping, lookup
+// or prune pass is allowed to finish, and the routines are restarted with the
+// new intervals. Returns an error wrapping ErrInvalidMaintenanceConfig if cfg
+// fails Validate; the current configuration is then left unchanged.
+//
+//export ToxDHTMaintainerReconfigure
+func (m *Maintainer) Reconfigure(cfg MaintenanceConfig) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+
+	m.reconfigMu.Lock()
+	defer m.reconfigMu.Unlock()
+
+	m.mu.Lock()
+	m.config = &cfg
+	running := m.isRunning
+	bootstrapper := m.bootstrapper
+	cancel := m.routineCancel
+	m.mu.Unlock()
 
-	// Start maintenance routines
-	go m.pingRoutine()
-	go m.lookupRoutine()
-	go m.pruneRoutine()
+	pkgLog.WithFields(logrus.Fields{
+		"function":        "Reconfigure",
+		"ping_interval":   cfg.PingInterval,
+		"lookup_interval": cfg.LookupInterval,
+		"running":         running,
+	}).Info("DHT maintenance reconfigured")
 
+	if bootstrapper != nil {
+		bootstrapper.SetLookupAlpha(cfg.Alpha)
+	}
+	if !running {
+		return nil
+	}
+
+	// Wait for the old routines, including any pass in progress, to exit
+	cancel()
+	m.wg.Wait()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.isRunning && m.ctx.Err() == nil {
+		m.startRoutines()
+	}
 	return nil
 }
 
+// GetCurrentConfig returns a copy of the active maintenance configuration.
+//
+//export ToxDHTMaintainerGetCurrentConfig
+func (m *Maintainer) GetCurrentConfig() MaintenanceConfig {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return *m.config
+}
+
 // Stop halts all maintenance tasks.
 //
 //export ToxDHTMaintainerStop
@@ -135,51 +247,53 @@ func (m *Maintainer) Stop() {
 }
 
 // pingRoutine periodically pings nodes to check if they're alive.
-func (m *Maintainer) pingRoutine() {
+func (m *Maintainer) pingRoutine(ctx context.Context, interval time.Duration) {
 	defer m.wg.Done()
-	ticker := time.NewTicker(m.config.PingInterval)
+	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
 	for {
 		select {
-		case <-m.ctx.Done():
+		case <-ctx.Done():
 			return
 		case <-ticker.C:
 			m.pingAllNodes()
+			m.recordCycle("ping")
+			m.logRoutingMetrics()
 		}
 	}
 }
 
 // lookupRoutine periodically looks up random nodes to keep the routing table fresh.
-func (m *Maintainer) lookupRoutine() {
+func (m *Maintainer) lookupRoutine(ctx context.Context, interval time.Duration) {
 	defer m.wg.Done()
-
-	ticker := time.NewTicker(m.config.LookupInterval)
+	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
 	for {
 		select {
-		case <-m.ctx.Done():
+		case <-ctx.Done():
 			return
 		case <-ticker.C:
 			m.lookupRandomNodes()
+			m.recordCycle("lookup")
 		}
 	}
 }
 
 // pruneRoutine removes dead nodes from the routing table.
-func (m *Maintainer) pruneRoutine() {
+func (m *Maintainer) pruneRoutine(ctx context.Context, interval time.Duration) {
 	defer m.wg.Done()
-
-	ticker := time.NewTicker(m.config.PingInterval) // Reuse ping interval
+	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
 	for {
 		select {
-		case <-m.ctx.Done():
+		case <-ctx.Done():
 			return
 		case <-ticker.C:
 			m.pruneDeadNodes()
+			m.recordCycle("prune")
 		}
 	}
 }
@@ -201,6 +315,7 @@ func (m *Maintainer) collectInactiveNodes() []*Node {
 	defer m.routingTable.mu.RUnlock()
 
 	nodesToPing := make([]*Node, 0)
+	nodeTimeout := m.GetCurrentConfig().NodeTimeout
 
 	for i := 0; i < 256; i++ {
 		bucket := m.routingTable.kBuckets[i]
@@ -208,7 +323,7 @@ func (m *Maintainer) collectInactiveNodes() []*Node {
 
 		for _, node := range nodes {
 			// Skip nodes that were seen recently
-			if node.IsActive(m.config.NodeTimeout / 2) {
+			if node.IsActive(nodeTimeout / 2) {
 				continue
 			}
 			nodesToPing = append(nodesToPing, node)
@@ -229,8 +344,9 @@ func (m *Maintainer) sendPingToNodes(nodes []*Node) {
 		}
 
 		// Send ping
+		node.RecordPingSent()
 		if err := m.transport.Send(packet, node.Address); err != nil {
-			logrus.WithError(err).Debug("dht: best-effort ping send failed")
+			pkgLog.WithError(err).Debug("dht: best-effort ping send failed")
 		}
 	}
 }
@@ -256,7 +372,7 @@ func (m *Maintainer) pingBootstrapNodes() {
 		}
 
 		if err := m.transport.Send(packet, addr); err != nil {
-			logrus.WithError(err).Debug("dht: best-effort bootstrap ping send failed")
+			pkgLog.WithError(err).Debug("dht: best-effort bootstrap ping send failed")
 		}
 	}
 }
@@ -267,6 +383,29 @@ func (m *Maintainer) resolveBootstrapAddress(bn *BootstrapNode) net.Addr {
 	return bn.Address
 }
 
+// logRoutingMetrics logs a routing table health summary after a ping cycle
+// and reports the table size to the routing table's MetricsRecorder.
+func (m *Maintainer) logRoutingMetrics() {
+	metrics := m.routingTable.Metrics()
+	m.routingTable.observe(RoutingTableSizeMetric, float64(metrics.TotalNodes))
+
+	nonEmpty := 0
+	for _, ratio := range metrics.BucketFillRatios {
+		if ratio > 0 {
+			nonEmpty++
+		}
+	}
+	pkgLog.WithFields(logrus.Fields{
+		"function":          "logRoutingMetrics",
+		"total_nodes":       metrics.TotalNodes,
+		"good_nodes":        metrics.GoodNodes,
+		"bad_nodes":         metrics.BadNodes,
+		"unknown_nodes":     metrics.UnknownNodes,
+		"non_empty_buckets": nonEmpty,
+		"average_rtt":       metrics.AverageRTT,
+	}).Info("DHT routing table metrics")
+}
+
 // createPingPacket creates a ping packet with the sender's public key.
 func createPingPacket(publicKey [32]byte) []byte {
 	// Simple ping packet: just our public key
@@ -288,7 +427,7 @@ func (m *Maintainer) lookupRandomNodes() {
 		// For other iterations, lookup random IDs
 		var randomKey [32]byte
 		if _, err := rand.Read(randomKey[:]); err != nil {
-			logrus.WithError(err).Debug("dht: skipping random node lookup: crypto/rand failed")
+			pkgLog.WithError(err).Debug("dht: skipping random node lookup: crypto/rand failed")
 			continue
 		}
 
@@ -329,7 +468,7 @@ func (m *Maintainer) lookupClosestNodes(targetKey [32]byte) {
 
 		// Send to each close node
 		if err := m.transport.Send(packet, node.Address); err != nil {
-			logrus.WithError(err).Debug("dht: best-effort get-nodes send failed")
+			pkgLog.WithError(err).Debug("dht: best-effort get-nodes send failed")
 		}
 	}
 }
@@ -337,26 +476,27 @@ func (m *Maintainer) lookupClosestNodes(targetKey [32]byte) {
 // pruneDeadNodes removes unresponsive nodes from the routing table.
 func (m *Maintainer) pruneDeadNodes() {
 	now := m.getTimeProvider().Now()
+	cfg := m.GetCurrentConfig()
 
 	for i := 0; i < 256; i++ {
 		bucket := m.routingTable.kBuckets[i]
-		m.pruneNodesInBucket(bucket, now)
+		m.pruneNodesInBucket(bucket, now, cfg)
 	}
 }
 
 // pruneNodesInBucket checks and prunes dead nodes in a single k-bucket.
-func (m *Maintainer) pruneNodesInBucket(bucket *KBucket, now time.Time) {
+func (m *Maintainer) pruneNodesInBucket(bucket *KBucket, now time.Time, cfg MaintenanceConfig) {
 	for _, node := range bucket.GetNodes() {
-		m.updateNodeStatus(node, now)
-		if node.GetStatus() == StatusBad && now.Sub(node.GetLastSeen()) > m.config.PruneTimeout {
+		updateNodeStatus(node, now, cfg.NodeTimeout)
+		if node.GetStatus() == StatusBad && now.Sub(node.GetLastSeen()) > cfg.PruneTimeout {
 			bucket.RemoveNode(node.ID.PublicKey)
 		}
 	}
 }
 
 // updateNodeStatus marks a good node as bad if it has been silent too long.
-func (m *Maintainer) updateNodeStatus(node *Node, now time.Time) {
-	if node.GetStatus() == StatusGood && now.Sub(node.GetLastSeen()) > m.config.NodeTimeout {
+func updateNodeStatus(node *Node, now time.Time, nodeTimeout time.Duration) {
+	if node.GetStatus() == StatusGood && now.Sub(node.GetLastSeen()) > nodeTimeout {
 		node.SetStatus(StatusBad)
 	}
 }
@@ -0,0 +1,176 @@
+package dht
+
+import (
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/opd-ai/toxcore/transport"
+)
+
+// newReconfigureTestMaintainer builds a maintainer with one bootstrap node to
+// ping and intervals short enough for tests.
+func newReconfigureTestMaintainer(t *testing.T) (*Maintainer, *MockTransport) {
+	t.Helper()
+	selfID := createTestToxID(1)
+	selfNode := NewNode(selfID, newMockAddr("local:1234"))
+	mt := newMockTransport(selfNode.Address)
+	routingTable := NewRoutingTable(selfID, 8)
+	bootstrapper, err := NewBootstrapManager(selfID, mt, routingTable)
+	if err != nil {
+		t.Fatalf("Failed to create bootstrap manager: %v", err)
+	}
+	bootstrapAddr, err := net.ResolveUDPAddr("udp", "127.0.0.1:33445")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := bootstrapper.AddNode(bootstrapAddr, "0202020202020202020202020202020202020202020202020202020202020202"); err != nil {
+		t.Fatalf("Failed to add bootstrap node: %v", err)
+	}
+
+	config := &MaintenanceConfig{
+		PingInterval:   10 * time.Millisecond,
+		LookupInterval: time.Hour,
+		NodeTimeout:    time.Minute,
+		PruneTimeout:   time.Hour,
+	}
+	return NewMaintainer(routingTable, bootstrapper, mt, selfNode, config), mt
+}
+
+func TestMaintenanceConfigValidate(t *testing.T) {
+	valid := *DefaultMaintenanceConfig()
+	if err := valid.Validate(); err != nil {
+		t.Errorf("default config should be valid, got %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		mutate func(c *MaintenanceConfig)
+	}{
+		{"ping below floor", func(c *MaintenanceConfig) { c.PingInterval = MinPingInterval - time.Millisecond }},
+		{"lookup below floor", func(c *MaintenanceConfig) { c.LookupInterval = MinLookupInterval - time.Millisecond }},
+		{"zero node timeout", func(c *MaintenanceConfig) { c.NodeTimeout = 0 }},
+		{"zero prune timeout", func(c *MaintenanceConfig) { c.PruneTimeout = 0 }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := valid
+			tt.mutate(&cfg)
+			if err := cfg.Validate(); !errors.Is(err, ErrInvalidMaintenanceConfig) {
+				t.Errorf("Validate() = %v, want ErrInvalidMaintenanceConfig", err)
+			}
+		})
+	}
+}
+
+func TestMaintainerReconfigure(t *testing.T) {
+	maintainer, _ := newReconfigureTestMaintainer(t)
+	original := maintainer.GetCurrentConfig()
+
+	invalid := original
+	invalid.PingInterval = time.Second
+	if err := maintainer.Reconfigure(invalid); !errors.Is(err, ErrInvalidMaintenanceConfig) {
+		t.Fatalf("Reconfigure with 1s ping = %v, want ErrInvalidMaintenanceConfig", err)
+	}
+	if maintainer.GetCurrentConfig() != original {
+		t.Error("rejected config should not replace the current one")
+	}
+
+	// Not running: the config is replaced and nothing starts
+	want := MaintenanceConfig{
+		PingInterval:   MinPingInterval,
+		LookupInterval: MinLookupInterval,
+		NodeTimeout:    time.Minute,
+		PruneTimeout:   time.Hour,
+	}
+	if err := maintainer.Reconfigure(want); err != nil {
+		t.Fatalf("Reconfigure failed: %v", err)
+	}
+	if got := maintainer.GetCurrentConfig(); got != want {
+		t.Errorf("GetCurrentConfig() = %+v, want %+v", got, want)
+	}
+	if maintainer.isRunning {
+		t.Error("Reconfigure should not start a stopped maintainer")
+	}
+}
+
+func TestMaintainerReconfigureWaitsForInFlightPing(t *testing.T) {
+	maintainer, mt := newReconfigureTestMaintainer(t)
+
+	var once sync.Once
+	pingStarted := make(chan struct{})
+	releasePing := make(chan struct{})
+	var mu sync.Mutex
+	var pingDone bool
+	mt.sendFunc = func(packet *transport.Packet, addr net.Addr) error {
+		if packet.PacketType != transport.PacketPingRequest {
+			return nil
+		}
+		first := false
+		once.Do(func() { first = true })
+		if first {
+			close(pingStarted)
+			<-releasePing
+			mu.Lock()
+			pingDone = true
+			mu.Unlock()
+		}
+		return nil
+	}
+
+	if err := maintainer.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer maintainer.Stop()
+
+	select {
+	case <-pingStarted:
+	case <-time.After(2 * time.Second):
+		t.Fatal("ping was never sent")
+	}
+
+	reconfigured := make(chan error, 1)
+	go func() {
+		reconfigured <- maintainer.Reconfigure(MaintenanceConfig{
+			PingInterval:   MinPingInterval,
+			LookupInterval: MinLookupInterval,
+			NodeTimeout:    time.Minute,
+			PruneTimeout:   time.Hour,
+		})
+	}()
+
+	select {
+	case err := <-reconfigured:
+		t.Fatalf("Reconfigure returned before the in-flight ping finished: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(releasePing)
+	select {
+	case err := <-reconfigured:
+		if err != nil {
+			t.Fatalf("Reconfigure failed: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Reconfigure did not return after the ping completed")
+	}
+
+	mu.Lock()
+	done := pingDone
+	mu.Unlock()
+	if !done {
+		t.Error("in-flight ping should complete before the routines are replaced")
+	}
+	if !maintainer.isRunning || maintainer.GetCurrentConfig().PingInterval != MinPingInterval {
+		t.Error("maintainer should keep running with the new config")
+	}
+
+	// With a 5s interval no further pings arrive during the test
+	mt.ResetSentPackets()
+	time.Sleep(50 * time.Millisecond)
+	if packets, _ := mt.GetSentPackets(); len(packets) != 0 {
+		t.Errorf("expected no pings with the new interval, got %d packets", len(packets))
+	}
+}
@@ -92,7 +92,7 @@ func (md *MDNSDiscovery) joinMulticastGroups() error {
 	// Join IPv4 multicast group
 	conn4, err := md.joinMulticastGroup("udp4", mdnsIPv4Addr)
 	if err != nil {
-		logrus.WithError(err).Warn("Failed to join IPv4 mDNS multicast group")
+		pkgLog.WithError(err).Warn("Failed to join IPv4 mDNS multicast group")
 	} else {
 		md.conn4 = conn4
 	}
@@ -100,7 +100,7 @@ func (md *MDNSDiscovery) joinMulticastGroups() error {
 	// Join IPv6 multicast group
 	conn6, err := md.joinMulticastGroup("udp6", mdnsIPv6Addr)
 	if err != nil {
-		logrus.WithError(err).Warn("Failed to join IPv6 mDNS multicast group")
+		pkgLog.WithError(err).Warn("Failed to join IPv6 mDNS multicast group")
 	} else {
 		md.conn6 = conn6
 	}
@@ -158,7 +158,7 @@ func (md *MDNSDiscovery) Start() error {
 	md.startReceiverGoroutines()
 	md.startBackgroundLoops()
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"port":    md.port,
 		"hasIPv4": md.conn4 != nil,
 		"hasIPv6": md.conn6 != nil,
@@ -215,7 +215,7 @@ func (md *MDNSDiscovery) joinInterfaces(ifaces []net.Interface, group string, jo
 
 // logJoinGroupFailure records an interface-specific multicast join failure.
 func (md *MDNSDiscovery) logJoinGroupFailure(name, group, network string, err error) {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"interface": name,
 		"group":     group,
 		"error":     err.Error(),
@@ -263,7 +263,7 @@ func (md *MDNSDiscovery) Stop() {
 	// Wait for goroutines to finish
 	md.wg.Wait()
 
-	logrus.Info("mDNS discovery stopped")
+	pkgLog.Info("mDNS discovery stopped")
 }
 
 // OnPeer registers a callback for when a peer is discovered via mDNS.
@@ -339,7 +339,7 @@ func (md *MDNSDiscovery) cleanupStalePeers() {
 		return
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"removed":   removed,
 		"component": "MDNSDiscovery",
 	}).Debug("Cleaned up stale mDNS peers")
@@ -358,14 +358,14 @@ func (md *MDNSDiscovery) sendQuery() {
 	// Send via IPv4
 	if conn4 != nil {
 		if _, err := conn4.WriteTo(query, mdnsUDPv4Addr); err != nil {
-			logrus.WithError(err).Debug("Failed to send mDNS IPv4 query")
+			pkgLog.WithError(err).Debug("Failed to send mDNS IPv4 query")
 		}
 	}
 
 	// Send via IPv6
 	if conn6 != nil {
 		if _, err := conn6.WriteTo(query, mdnsUDPv6Addr); err != nil {
-			logrus.WithError(err).Debug("Failed to send mDNS IPv6 query")
+			pkgLog.WithError(err).Debug("Failed to send mDNS IPv6 query")
 		}
 	}
 }
@@ -385,14 +385,14 @@ func (md *MDNSDiscovery) sendAnnouncement() {
 	// Send via IPv4
 	if conn4 != nil {
 		if _, err := conn4.WriteTo(response, mdnsUDPv4Addr); err != nil {
-			logrus.WithError(err).Debug("Failed to send mDNS IPv4 announcement")
+			pkgLog.WithError(err).Debug("Failed to send mDNS IPv4 announcement")
 		}
 	}
 
 	// Send via IPv6
 	if conn6 != nil {
 		if _, err := conn6.WriteTo(response, mdnsUDPv6Addr); err != nil {
-			logrus.WithError(err).Debug("Failed to send mDNS IPv6 announcement")
+			pkgLog.WithError(err).Debug("Failed to send mDNS IPv6 announcement")
 		}
 	}
 }
@@ -559,7 +559,7 @@ func (md *MDNSDiscovery) notifyPeer(publicKey [32]byte, port uint16, addr net.Ad
 
 	// Only log if this is a new peer or hasn't been seen recently
 	if !exists || now.Sub(lastSeen) > time.Minute {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"peer_addr":  peerAddr.String(),
 			"public_key": keyHex[:16],
 			"via":        label,
@@ -0,0 +1,293 @@
+package dht
+
+import (
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/opd-ai/toxcore/crypto"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Prometheus metric names exported by the DHT package.
+const (
+	metricBucketFillRatio    = "toxcore_dht_bucket_fill_ratio"
+	metricGoodNodes          = "toxcore_dht_good_nodes_total"
+	metricBadNodes           = "toxcore_dht_bad_nodes_total"
+	metricLookups            = "toxcore_dht_lookups_total"
+	metricLookupDuration     = "toxcore_dht_lookup_duration_seconds"
+	metricMaintenanceCycles  = "toxcore_dht_maintenance_cycles_total"
+	maintenanceTaskLabelName = "task"
+)
+
+// Histogram names under which a RoutingTable reports to its MetricsRecorder.
+const (
+	// RoutingTableRTTMetric receives each answered ping's round-trip time
+	// in seconds.
+	RoutingTableRTTMetric = "toxcore_dht_ping_rtt_seconds"
+	// RoutingTableSizeMetric receives the number of nodes in the table
+	// after each Maintainer ping cycle.
+	RoutingTableSizeMetric = "toxcore_dht_routing_table_size"
+)
+
+// MetricsRecorder receives routing table observations for a metrics backend
+// other than the Prometheus collector. It is the interface NonceStore
+// reports to, so one implementation can serve both.
+type MetricsRecorder = crypto.MetricsRecorder
+
+// RoutingTableMetrics is a point-in-time summary of routing table health.
+type RoutingTableMetrics struct {
+	// BucketFillRatios holds the fraction of each k-bucket's capacity in use,
+	// indexed by bucket.
+	BucketFillRatios []float64
+	// AverageRTT is the mean of the per-node average ping round-trip times,
+	// over the nodes that have answered a ping.
+	AverageRTT   time.Duration
+	TotalNodes   int
+	GoodNodes    int
+	BadNodes     int
+	UnknownNodes int
+}
+
+// NodeMetrics holds the latency data of one routing table node.
+type NodeMetrics struct {
+	PublicKey    [32]byte
+	Address      net.Addr
+	Status       NodeStatus
+	LastSeen     time.Time
+	AverageRTT   time.Duration
+	RTTSamples   uint32
+	PingCount    uint32
+	SuccessCount uint32
+	FailureCount uint32
+}
+
+// Metrics returns bucket fill levels, node health counts and the average
+// ping round-trip time of the routing table.
+func (rt *RoutingTable) Metrics() RoutingTableMetrics {
+	metrics := RoutingTableMetrics{BucketFillRatios: make([]float64, len(rt.kBuckets))}
+	var rttSum time.Duration
+	rttNodes := 0
+	for i, bucket := range rt.kBuckets {
+		nodes, capacity := bucket.snapshot()
+		if capacity > 0 {
+			metrics.BucketFillRatios[i] = float64(len(nodes)) / float64(capacity)
+		}
+		for _, node := range nodes {
+			metrics.TotalNodes++
+			switch node.GetStatus() {
+			case StatusGood:
+				metrics.GoodNodes++
+			case StatusBad:
+				metrics.BadNodes++
+			default:
+				metrics.UnknownNodes++
+			}
+			if rtt := node.GetAverageRTT(); rtt > 0 {
+				rttSum += rtt
+				rttNodes++
+			}
+		}
+	}
+	if rttNodes > 0 {
+		metrics.AverageRTT = rttSum / time.Duration(rttNodes)
+	}
+	return metrics
+}
+
+// MetricsSnapshot returns the ping statistics of every node in the routing
+// table.
+func (rt *RoutingTable) MetricsSnapshot() []NodeMetrics {
+	nodes := rt.GetAllNodes()
+	snapshot := make([]NodeMetrics, 0, len(nodes))
+	for _, node := range nodes {
+		node.mu.RLock()
+		snapshot = append(snapshot, NodeMetrics{
+			PublicKey:    node.PublicKey,
+			Address:      node.Address,
+			Status:       node.Status,
+			LastSeen:     node.LastSeen,
+			AverageRTT:   node.PingStats.AverageRTT,
+			RTTSamples:   node.PingStats.RTTSamples,
+			PingCount:    node.PingStats.PingCount,
+			SuccessCount: node.PingStats.SuccessCount,
+			FailureCount: node.PingStats.FailureCount,
+		})
+		node.mu.RUnlock()
+	}
+	return snapshot
+}
+
+// SetMetricsRecorder makes the routing table report ping round-trip times,
+// and the Maintainer report the table size, to recorder. Pass nil to stop.
+func (rt *RoutingTable) SetMetricsRecorder(recorder MetricsRecorder) {
+	rt.recorderMu.Lock()
+	defer rt.recorderMu.Unlock()
+	rt.recorder = recorder
+}
+
+// observe reports value to the metrics recorder, if one is set.
+func (rt *RoutingTable) observe(name string, value float64) {
+	rt.recorderMu.RLock()
+	recorder := rt.recorder
+	rt.recorderMu.RUnlock()
+	if recorder != nil {
+		recorder.ObserveHistogram(name, value)
+	}
+}
+
+// recordRTT records one answered ping's round-trip time.
+func (rt *RoutingTable) recordRTT(rtt time.Duration) {
+	rt.getMetrics().pingRTT.Observe(rtt.Seconds())
+	rt.observe(RoutingTableRTTMetric, rtt.Seconds())
+}
+
+// routingMetrics holds the accumulating lookup and ping metrics of a
+// RoutingTable. Gauge-style values (bucket fill, node health) are computed
+// on scrape.
+type routingMetrics struct {
+	lookups        prometheus.Counter
+	lookupDuration prometheus.Histogram
+	pingRTT        prometheus.Histogram
+}
+
+// newRoutingMetrics creates the lookup counter and the lookup duration and
+// ping RTT histograms.
+func newRoutingMetrics() *routingMetrics {
+	return &routingMetrics{
+		lookups: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: metricLookups,
+			Help: "Total number of DHT node lookups performed.",
+		}),
+		lookupDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    metricLookupDuration,
+			Help:    "Duration of DHT node lookups in seconds.",
+			Buckets: prometheus.ExponentialBuckets(0.01, 2, 12),
+		}),
+		pingRTT: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    RoutingTableRTTMetric,
+			Help:    "Round-trip time of answered DHT pings in seconds.",
+			Buckets: prometheus.ExponentialBuckets(0.005, 2, 12),
+		}),
+	}
+}
+
+// routingTableCollector implements prometheus.Collector for a RoutingTable.
+type routingTableCollector struct {
+	rt *RoutingTable
+
+	bucketFillDesc *prometheus.Desc
+	goodNodesDesc  *prometheus.Desc
+	badNodesDesc   *prometheus.Desc
+}
+
+// getMetrics returns the routing table's lookup metrics, creating them on first use.
+func (rt *RoutingTable) getMetrics() *routingMetrics {
+	rt.metricsOnce.Do(func() {
+		rt.metrics = newRoutingMetrics()
+	})
+	return rt.metrics
+}
+
+// RecordLookup records the completion of a node lookup that took duration d.
+// IterativeLookup calls this automatically; custom lookup implementations
+// may call it to contribute to the exported lookup metrics.
+func (rt *RoutingTable) RecordLookup(d time.Duration) {
+	m := rt.getMetrics()
+	m.lookups.Inc()
+	m.lookupDuration.Observe(d.Seconds())
+}
+
+// PrometheusCollector returns a prometheus.Collector exposing routing table
+// health: per-bucket fill ratio, good and bad node counts, lookup count and
+// duration, and ping round-trip times. Register it with a prometheus.Registerer, or use
+// Maintainer.RegisterMetrics to register it together with maintenance metrics.
+func (rt *RoutingTable) PrometheusCollector() prometheus.Collector {
+	return &routingTableCollector{
+		rt: rt,
+		bucketFillDesc: prometheus.NewDesc(metricBucketFillRatio,
+			"Fraction of each k-bucket's capacity currently in use.",
+			[]string{"bucket"}, nil),
+		goodNodesDesc: prometheus.NewDesc(metricGoodNodes,
+			"Number of routing table nodes currently marked good.", nil, nil),
+		badNodesDesc: prometheus.NewDesc(metricBadNodes,
+			"Number of routing table nodes currently marked bad.", nil, nil),
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (c *routingTableCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.bucketFillDesc
+	ch <- c.goodNodesDesc
+	ch <- c.badNodesDesc
+	m := c.rt.getMetrics()
+	m.lookups.Describe(ch)
+	m.lookupDuration.Describe(ch)
+	m.pingRTT.Describe(ch)
+}
+
+// Collect implements prometheus.Collector.
+func (c *routingTableCollector) Collect(ch chan<- prometheus.Metric) {
+	metrics := c.rt.Metrics()
+	for i, ratio := range metrics.BucketFillRatios {
+		ch <- prometheus.MustNewConstMetric(c.bucketFillDesc, prometheus.GaugeValue, ratio, strconv.Itoa(i))
+	}
+	ch <- prometheus.MustNewConstMetric(c.goodNodesDesc, prometheus.GaugeValue, float64(metrics.GoodNodes))
+	ch <- prometheus.MustNewConstMetric(c.badNodesDesc, prometheus.GaugeValue, float64(metrics.BadNodes))
+
+	m := c.rt.getMetrics()
+	m.lookups.Collect(ch)
+	m.lookupDuration.Collect(ch)
+	m.pingRTT.Collect(ch)
+}
+
+// snapshot returns a copy of the bucket's nodes and its current capacity.
+func (kb *KBucket) snapshot() ([]*Node, int) {
+	kb.mu.RLock()
+	defer kb.mu.RUnlock()
+	nodes := make([]*Node, len(kb.nodes))
+	copy(nodes, kb.nodes)
+	return nodes, kb.maxSize
+}
+
+// maintainerMetrics holds the maintenance cycle counter of a Maintainer.
+type maintainerMetrics struct {
+	once   sync.Once
+	cycles *prometheus.CounterVec
+}
+
+// getCycles returns the maintenance cycle counter, creating it on first use.
+func (mm *maintainerMetrics) getCycles() *prometheus.CounterVec {
+	mm.once.Do(func() {
+		mm.cycles = prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: metricMaintenanceCycles,
+			Help: "Total number of DHT maintenance cycles run, by task.",
+		}, []string{maintenanceTaskLabelName})
+	})
+	return mm.cycles
+}
+
+// recordCycle increments the cycle counter for a maintenance task.
+func (m *Maintainer) recordCycle(task string) {
+	m.metrics.getCycles().WithLabelValues(task).Inc()
+}
+
+// RegisterMetrics registers the routing table collector and the
+// toxcore_dht_maintenance_cycles_total counter with reg.
+//
+// Example:
+//
+//	reg := prometheus.NewRegistry()
+//	if err := maintainer.RegisterMetrics(reg); err != nil {
+//	    log.Fatal(err)
+//	}
+//	http.Handle("/metrics", promhttp.HandlerFor(reg, promhttp.HandlerOpts{}))
+func (m *Maintainer) RegisterMetrics(reg prometheus.Registerer) error {
+	if m.routingTable != nil {
+		if err := reg.Register(m.routingTable.PrometheusCollector()); err != nil {
+			return err
+		}
+	}
+	return reg.Register(m.metrics.getCycles())
+}
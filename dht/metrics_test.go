@@ -0,0 +1,220 @@
+package dht
+
+import (
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/opd-ai/toxcore/crypto"
+	"github.com/opd-ai/toxcore/transport"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// TestRoutingTableCollector verifies node health gauges and lookup metrics
+func TestRoutingTableCollector(t *testing.T) {
+	rt := NewRoutingTable(crypto.ToxID{PublicKey: [32]byte{0xff}}, 8)
+	addr := &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 33445}
+
+	good := NewNode(crypto.ToxID{PublicKey: [32]byte{1}}, addr)
+	good.SetStatus(StatusGood)
+	bad := NewNode(crypto.ToxID{PublicKey: [32]byte{2}}, addr)
+	bad.SetStatus(StatusBad)
+	rt.AddNode(good)
+	rt.AddNode(bad)
+
+	rt.RecordLookup(150 * time.Millisecond)
+	rt.RecordLookup(50 * time.Millisecond)
+
+	collector := rt.PrometheusCollector()
+	expected := `
+# HELP toxcore_dht_good_nodes_total Number of routing table nodes currently marked good.
+# TYPE toxcore_dht_good_nodes_total gauge
+toxcore_dht_good_nodes_total 1
+# HELP toxcore_dht_bad_nodes_total Number of routing table nodes currently marked bad.
+# TYPE toxcore_dht_bad_nodes_total gauge
+toxcore_dht_bad_nodes_total 1
+# HELP toxcore_dht_lookups_total Total number of DHT node lookups performed.
+# TYPE toxcore_dht_lookups_total counter
+toxcore_dht_lookups_total 2
+`
+	if err := testutil.CollectAndCompare(collector, strings.NewReader(expected),
+		metricGoodNodes, metricBadNodes, metricLookups); err != nil {
+		t.Fatalf("unexpected metrics: %v", err)
+	}
+
+	// 256 bucket gauges + good + bad + lookups + lookup and RTT histograms
+	if n := testutil.CollectAndCount(collector); n != 256+5 {
+		t.Errorf("expected %d metrics, got %d", 256+5, n)
+	}
+}
+
+// TestMaintainerRegisterMetrics verifies registration and the cycle counter
+func TestMaintainerRegisterMetrics(t *testing.T) {
+	rt := NewRoutingTable(crypto.ToxID{PublicKey: [32]byte{0xff}}, 8)
+	m := NewMaintainer(rt, nil, nil, nil, nil)
+
+	reg := prometheus.NewRegistry()
+	if err := m.RegisterMetrics(reg); err != nil {
+		t.Fatalf("RegisterMetrics failed: %v", err)
+	}
+
+	m.recordCycle("ping")
+	m.recordCycle("ping")
+	m.recordCycle("prune")
+
+	if got := testutil.ToFloat64(m.metrics.getCycles().WithLabelValues("ping")); got != 2 {
+		t.Errorf("expected 2 ping cycles, got %v", got)
+	}
+
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("Gather failed: %v", err)
+	}
+	names := make(map[string]bool)
+	for _, f := range families {
+		names[f.GetName()] = true
+	}
+	for _, name := range []string{metricBucketFillRatio, metricGoodNodes, metricBadNodes,
+		metricLookups, metricLookupDuration, metricMaintenanceCycles} {
+		if !names[name] {
+			t.Errorf("metric %s not registered", name)
+		}
+	}
+
+	if err := m.RegisterMetrics(reg); err == nil {
+		t.Error("expected duplicate registration to fail")
+	}
+}
+
+// recordingMetrics is a MetricsRecorder that keeps every observation.
+type recordingMetrics struct {
+	mu     sync.Mutex
+	values map[string][]float64
+}
+
+func (r *recordingMetrics) ObserveHistogram(name string, value float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.values == nil {
+		r.values = make(map[string][]float64)
+	}
+	r.values[name] = append(r.values[name], value)
+}
+
+func (r *recordingMetrics) get(name string) []float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.values[name]
+}
+
+// TestRoutingTableMetrics verifies fill ratios, health counts and average RTT
+func TestRoutingTableMetrics(t *testing.T) {
+	rt := NewRoutingTable(crypto.ToxID{PublicKey: [32]byte{0xff}}, 8)
+	addr := &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 33445}
+
+	good := NewNode(crypto.ToxID{PublicKey: [32]byte{1}}, addr)
+	good.SetStatus(StatusGood)
+	good.PingStats.AverageRTT = 40 * time.Millisecond
+	bad := NewNode(crypto.ToxID{PublicKey: [32]byte{2}}, addr)
+	bad.SetStatus(StatusBad)
+	bad.PingStats.AverageRTT = 80 * time.Millisecond
+	unknown := NewNode(crypto.ToxID{PublicKey: [32]byte{0xfe}}, addr)
+	for _, node := range []*Node{good, bad, unknown} {
+		rt.AddNode(node)
+	}
+
+	metrics := rt.Metrics()
+	if metrics.TotalNodes != 3 || metrics.GoodNodes != 1 || metrics.BadNodes != 1 || metrics.UnknownNodes != 1 {
+		t.Errorf("unexpected node counts: %+v", metrics)
+	}
+	if metrics.AverageRTT != 60*time.Millisecond {
+		t.Errorf("expected average RTT 60ms over nodes with samples, got %v", metrics.AverageRTT)
+	}
+	if len(metrics.BucketFillRatios) != 256 {
+		t.Fatalf("expected 256 bucket ratios, got %d", len(metrics.BucketFillRatios))
+	}
+	if ratio := metrics.BucketFillRatios[computeBucketIndex(rt.selfID, good)]; ratio != 2.0/8 {
+		t.Errorf("expected the bucket holding nodes 1 and 2 to be 2/8 full, got %v", ratio)
+	}
+	if ratio := metrics.BucketFillRatios[computeBucketIndex(rt.selfID, unknown)]; ratio != 1.0/8 {
+		t.Errorf("expected node fe's bucket to be 1/8 full, got %v", ratio)
+	}
+}
+
+// TestNodeRollingRTT verifies the RTT average moves 1/8 towards each sample
+func TestNodeRollingRTT(t *testing.T) {
+	tp := &mockTimeProvider{current: time.Unix(1000, 0)}
+	node := NewNodeWithTimeProvider(crypto.ToxID{PublicKey: [32]byte{1}}, nil, tp)
+
+	if _, ok := node.recordPingResponse(true, tp); ok {
+		t.Error("expected no RTT sample without an outstanding ping")
+	}
+	tp.Advance(time.Second)
+
+	node.RecordPingSentWithTimeProvider(tp)
+	tp.Advance(100 * time.Millisecond)
+	if rtt, ok := node.recordPingResponse(true, tp); !ok || rtt != 100*time.Millisecond {
+		t.Fatalf("expected a 100ms sample, got %v (%v)", rtt, ok)
+	}
+	if _, ok := node.recordPingResponse(true, tp); ok {
+		t.Error("expected a duplicate response not to be sampled again")
+	}
+	tp.Advance(time.Second)
+
+	node.RecordPingSentWithTimeProvider(tp)
+	tp.Advance(20 * time.Millisecond)
+	node.RecordPingResponseWithTimeProvider(true, tp)
+	if avg := node.GetAverageRTT(); avg != 90*time.Millisecond {
+		t.Errorf("expected rolling average 90ms, got %v", avg)
+	}
+	if node.PingStats.RTTSamples != 2 {
+		t.Errorf("expected 2 RTT samples, got %d", node.PingStats.RTTSamples)
+	}
+}
+
+// TestPingResponseRecordsRTT verifies ping responses feed node and table metrics
+func TestPingResponseRecordsRTT(t *testing.T) {
+	selfID := crypto.ToxID{PublicKey: [32]byte{0xff}}
+	rt := NewRoutingTable(selfID, 8)
+	recorder := &recordingMetrics{}
+	rt.SetMetricsRecorder(recorder)
+	bm, err := NewBootstrapManager(selfID, newMockTransport(newMockAddr("127.0.0.1:33445")), rt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	addr := &net.UDPAddr{IP: net.ParseIP("192.0.2.1"), Port: 33445}
+	node := NewNode(crypto.ToxID{PublicKey: [32]byte{1}}, addr)
+	rt.AddNode(node)
+	node.RecordPingSent()
+	time.Sleep(2 * time.Millisecond)
+
+	response := &transport.Packet{PacketType: transport.PacketPingResponse, Data: make([]byte, 32)}
+	copy(response.Data, node.PublicKey[:])
+	if err := bm.handlePingResponsePacket(response, addr); err != nil {
+		t.Fatal(err)
+	}
+
+	snapshot := rt.MetricsSnapshot()
+	if len(snapshot) != 1 || snapshot[0].RTTSamples != 1 || snapshot[0].AverageRTT < 2*time.Millisecond {
+		t.Fatalf("expected one node with an RTT sample of at least 2ms, got %+v", snapshot)
+	}
+	if snapshot[0].Status != StatusGood || snapshot[0].Address.String() != addr.String() {
+		t.Errorf("expected the node to be good at %s, got %+v", addr, snapshot[0])
+	}
+	if got := recorder.get(RoutingTableRTTMetric); len(got) != 1 || got[0] < 0.002 {
+		t.Errorf("expected one RTT observation of at least 2ms, got %v", got)
+	}
+	if n := testutil.CollectAndCount(rt.PrometheusCollector(), RoutingTableRTTMetric); n != 1 {
+		t.Errorf("expected the RTT histogram to be exported, got %d metrics", n)
+	}
+
+	m := NewMaintainer(rt, bm, nil, nil, nil)
+	m.logRoutingMetrics()
+	if got := recorder.get(RoutingTableSizeMetric); len(got) != 1 || got[0] != 1 {
+		t.Errorf("expected the table size to be reported after a ping cycle, got %v", got)
+	}
+}
@@ -0,0 +1,111 @@
+package dht
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/opd-ai/toxcore/transport"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// migrationNonceSize is the size of the nonce appended to the ping that
+	// probes a migrated node's new address.
+	migrationNonceSize = 16
+
+	// migrationProbeTimeout bounds how long a probe waits for its echo.
+	migrationProbeTimeout = 10 * time.Second
+
+	// maxMigrationProbes caps the probes in flight, so a flood of
+	// notifications cannot grow memory or turn us into a ping reflector.
+	maxMigrationProbes = 64
+)
+
+// migrationProbe is a ping sent to the new address of a node that announced
+// a migration. The routing table is only updated once the nonce is echoed
+// from that address, so a notification with a forged source address has no
+// effect.
+type migrationProbe struct {
+	publicKey [32]byte
+	nonce     [migrationNonceSize]byte
+	expires   time.Time
+}
+
+// migrationProbes holds the probes in flight, keyed by new address.
+type migrationProbes struct {
+	mu     sync.Mutex
+	probes map[string]migrationProbe
+}
+
+// getMigration returns the migration probe state, initializing it on first use.
+func (bm *BootstrapManager) getMigration() *migrationProbes {
+	bm.migrationOnce.Do(func() {
+		bm.migration = &migrationProbes{probes: make(map[string]migrationProbe)}
+	})
+	return bm.migration
+}
+
+// probeMigratedNode pings addr with a fresh nonce on behalf of the known node
+// publicKey. The address is adopted by confirmMigration when the echo
+// arrives.
+func (bm *BootstrapManager) probeMigratedNode(publicKey [32]byte, addr net.Addr) error {
+	probe := migrationProbe{
+		publicKey: publicKey,
+		expires:   bm.getTimeProvider().Now().Add(migrationProbeTimeout),
+	}
+	if _, err := rand.Read(probe.nonce[:]); err != nil {
+		return fmt.Errorf("failed to generate migration nonce: %w", err)
+	}
+
+	m := bm.getMigration()
+	now := bm.getTimeProvider().Now()
+	m.mu.Lock()
+	for key, pending := range m.probes {
+		if now.After(pending.expires) {
+			delete(m.probes, key)
+		}
+	}
+	if _, exists := m.probes[addr.String()]; !exists && len(m.probes) >= maxMigrationProbes {
+		m.mu.Unlock()
+		return fmt.Errorf("too many migration probes in flight, ignoring notification from %s", addr)
+	}
+	m.probes[addr.String()] = probe
+	m.mu.Unlock()
+
+	data := append(createPingPacket(bm.selfID.PublicKey), probe.nonce[:]...)
+	return bm.transport.Send(&transport.Packet{PacketType: transport.PacketPingRequest, Data: data}, addr)
+}
+
+// confirmMigration checks a ping response against the probe sent to addr and,
+// if it echoes the probe's nonce in time, moves the node to addr. It reports
+// whether the response answered a probe.
+func (bm *BootstrapManager) confirmMigration(data []byte, addr net.Addr) bool {
+	if addr == nil || len(data) != 32+migrationNonceSize {
+		return false
+	}
+
+	m := bm.getMigration()
+	m.mu.Lock()
+	probe, exists := m.probes[addr.String()]
+	if !exists || subtle.ConstantTimeCompare(data[32:], probe.nonce[:]) != 1 {
+		m.mu.Unlock()
+		return false
+	}
+	delete(m.probes, addr.String())
+	m.mu.Unlock()
+
+	if bm.getTimeProvider().Now().After(probe.expires) {
+		return true
+	}
+	if bm.routingTable.UpdateNodeAddress(probe.publicKey, addr) {
+		pkgLog.WithFields(logrus.Fields{
+			"function": "confirmMigration",
+			"address":  addr.String(),
+		}).Debug("Updated address of migrated node")
+	}
+	return true
+}
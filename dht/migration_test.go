@@ -0,0 +1,72 @@
+package dht
+
+import (
+	"net"
+	"testing"
+
+	"github.com/opd-ai/toxcore/crypto"
+	"github.com/opd-ai/toxcore/transport"
+)
+
+func TestMigrationNotifyRequiresRoundTrip(t *testing.T) {
+	selfID := crypto.ToxID{PublicKey: [32]byte{0xff}}
+	rt := NewRoutingTable(selfID, 8)
+	mock := newMockTransport(newMockAddr("127.0.0.1:33445"))
+	bm, err := NewBootstrapManager(selfID, mock, rt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	oldAddr := &net.UDPAddr{IP: net.ParseIP("192.0.2.1"), Port: 33445}
+	newAddr := &net.UDPAddr{IP: net.ParseIP("192.0.2.2"), Port: 33445}
+	known := crypto.ToxID{PublicKey: [32]byte{1}}
+	rt.AddNode(NewNode(known, oldAddr))
+	addressOf := func() string { return rt.getNode(known.PublicKey).Address.String() }
+
+	notify := &transport.Packet{
+		PacketType: transport.PacketMigrationNotify,
+		Data:       (&transport.MigrationNotification{PublicKey: known.PublicKey}).Serialize(),
+	}
+	if err := bm.handleMigrationNotifyPacket(notify, newAddr); err != nil {
+		t.Fatal(err)
+	}
+	if addressOf() != oldAddr.String() {
+		t.Fatal("Expected the notification alone not to move the node")
+	}
+
+	packets, addrs := mock.GetSentPackets()
+	if len(packets) != 1 || packets[0].PacketType != transport.PacketPingRequest ||
+		addrs[0].String() != newAddr.String() || len(packets[0].Data) != 32+migrationNonceSize {
+		t.Fatalf("Expected one nonce ping to the new address, got %v to %v", packets, addrs)
+	}
+	echo := packets[0].Data
+
+	forged := append([]byte(nil), echo...)
+	forged[len(forged)-1] ^= 0xff
+	if err := bm.handlePingResponsePacket(&transport.Packet{PacketType: transport.PacketPingResponse, Data: forged}, newAddr); err != nil {
+		t.Fatal(err)
+	}
+	if err := bm.handlePingResponsePacket(&transport.Packet{PacketType: transport.PacketPingResponse, Data: echo}, oldAddr); err != nil {
+		t.Fatal(err)
+	}
+	if addressOf() != oldAddr.String() {
+		t.Fatal("Expected a wrong nonce or address not to move the node")
+	}
+
+	if err := bm.handlePingResponsePacket(&transport.Packet{PacketType: transport.PacketPingResponse, Data: echo}, newAddr); err != nil {
+		t.Fatal(err)
+	}
+	if addressOf() != newAddr.String() {
+		t.Errorf("Expected node at %s after the round trip, got %s", newAddr, addressOf())
+	}
+
+	// Unknown keys are not probed
+	mock.ResetSentPackets()
+	notify.Data = (&transport.MigrationNotification{PublicKey: [32]byte{2}}).Serialize()
+	if err := bm.handleMigrationNotifyPacket(notify, newAddr); err != nil {
+		t.Fatal(err)
+	}
+	if packets, _ := mock.GetSentPackets(); len(packets) != 0 {
+		t.Errorf("Expected no probe for an unknown key, got %v", packets)
+	}
+}
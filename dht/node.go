@@ -82,14 +82,23 @@ type PingStats struct {
 	PingCount        uint32
 	SuccessCount     uint32
 	FailureCount     uint32
+
+	// AverageRTT is a rolling average of the round-trip times of answered
+	// pings, weighted 1/8 towards each new sample; RTTSamples counts them.
+	AverageRTT time.Duration
+	RTTSamples uint32
 }
 
 // Node represents a peer in the Tox DHT network.
 //
 //export ToxDHTNode
 type Node struct {
-	ID        crypto.ToxID
-	Address   net.Addr
+	ID      crypto.ToxID
+	Address net.Addr // Address in use; the preferred family for dual-stack nodes
+	// AddrV4 and AddrV6 hold the per-family addresses of dual-stack nodes
+	// (see NewDualStackNode). Both are nil for single-address nodes.
+	AddrV4    net.Addr
+	AddrV6    net.Addr
 	LastSeen  time.Time
 	Status    NodeStatus
 	PublicKey [32]byte
@@ -209,16 +218,36 @@ func (n *Node) RecordPingResponse(success bool) {
 
 // RecordPingResponseWithTimeProvider marks a ping response with a custom time provider.
 func (n *Node) RecordPingResponseWithTimeProvider(success bool, tp TimeProvider) {
+	n.recordPingResponse(success, tp)
+}
+
+// recordPingResponse implements RecordPingResponseWithTimeProvider. For a
+// response to an outstanding ping it also updates the rolling RTT average
+// and returns the measured round-trip time.
+func (n *Node) recordPingResponse(success bool, tp TimeProvider) (time.Duration, bool) {
 	if tp == nil {
 		tp = getDefaultTimeProvider()
 	}
 	n.mu.Lock()
 	defer n.mu.Unlock()
 
+	var rtt time.Duration
+	measured := false
 	if success {
-		n.PingStats.LastPingReceived = tp.Now()
-		n.PingStats.SuccessCount++
-		n.LastSeen = tp.Now()
+		now := tp.Now()
+		stats := &n.PingStats
+		if !stats.LastPingSent.IsZero() && stats.LastPingSent.After(stats.LastPingReceived) {
+			rtt, measured = now.Sub(stats.LastPingSent), true
+			if stats.RTTSamples == 0 {
+				stats.AverageRTT = rtt
+			} else {
+				stats.AverageRTT += (rtt - stats.AverageRTT) / 8
+			}
+			stats.RTTSamples++
+		}
+		stats.LastPingReceived = now
+		stats.SuccessCount++
+		n.LastSeen = now
 		n.Status = StatusGood
 	} else {
 		n.PingStats.FailureCount++
@@ -227,6 +256,15 @@ func (n *Node) RecordPingResponseWithTimeProvider(success bool, tp TimeProvider)
 			n.Status = StatusBad
 		}
 	}
+	return rtt, measured
+}
+
+// GetAverageRTT returns the node's rolling average ping round-trip time, or
+// zero if no ping has been answered yet.
+func (n *Node) GetAverageRTT() time.Duration {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	return n.PingStats.AverageRTT
 }
 
 // GetStatus returns the current status of the node under lock.
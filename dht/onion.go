@@ -0,0 +1,653 @@
+package dht
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/opd-ai/toxcore/crypto"
+	"github.com/opd-ai/toxcore/transport"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultOnionHops is the number of relays in an onion circuit.
+	DefaultOnionHops = 3
+
+	// DefaultOnionCircuitLifetime is how long a circuit is reused before it is rebuilt.
+	DefaultOnionCircuitLifetime = 10 * time.Minute
+
+	// onionReverseRouteTTL bounds how long a relay remembers where to send replies.
+	onionReverseRouteTTL = 30 * time.Second
+
+	// maxOnionRoutes bounds the outstanding requests a relay or client tracks.
+	maxOnionRoutes = 4096
+
+	// onionLayerOverhead is the ephemeral public key and nonce prepended to each layer.
+	onionLayerOverhead = 32 + crypto.NonceSize
+
+	// onionLayerHeaderSize is type(1) || circuitID(8) || replyID(8) || addrLen(1).
+	onionLayerHeaderSize = 1 + 8 + 8 + 1
+)
+
+// RelaySelectionStrategy determines how onion relays are chosen from the routing table.
+type RelaySelectionStrategy uint8
+
+const (
+	// RelaySelectRandom picks relays uniformly at random from good nodes.
+	RelaySelectRandom RelaySelectionStrategy = iota
+	// RelaySelectBucketDiverse picks at most one relay per k-bucket so that
+	// hops are spread across the key space.
+	RelaySelectBucketDiverse
+)
+
+var (
+	// ErrInsufficientRelays indicates the routing table has too few nodes to build a circuit.
+	ErrInsufficientRelays = errors.New("insufficient nodes for onion circuit")
+
+	// ErrOnionRelayDisabled indicates an onion packet arrived but no relay key is configured.
+	ErrOnionRelayDisabled = errors.New("onion relaying not enabled")
+
+	// ErrOnionForwardRefused indicates an onion layer carried a packet type
+	// relays do not forward.
+	ErrOnionForwardRefused = errors.New("onion relay refuses to forward packet")
+)
+
+// OnionConfig configures onion routing of DHT lookups.
+type OnionConfig struct {
+	// Number of relays between the client and the queried node
+	NumHops int
+	// How relays are chosen from the routing table
+	RelaySelectionStrategy RelaySelectionStrategy
+	// How long a circuit is reused before a fresh one is built
+	CircuitLifetime time.Duration
+}
+
+// DefaultOnionConfig returns a 3-hop, randomly selected circuit configuration.
+func DefaultOnionConfig() *OnionConfig {
+	return &OnionConfig{
+		NumHops:                DefaultOnionHops,
+		RelaySelectionStrategy: RelaySelectRandom,
+		CircuitLifetime:        DefaultOnionCircuitLifetime,
+	}
+}
+
+// OnionCircuit is an ordered list of relays used to forward DHT requests.
+// Requests sent through a circuit carry the circuit's ephemeral public key
+// instead of the client's long-term key.
+type OnionCircuit struct {
+	Hops      []*Node
+	CreatedAt time.Time
+	ephemeral *crypto.KeyPair
+}
+
+// EphemeralPublicKey returns the public key that identifies this circuit to queried nodes.
+func (c *OnionCircuit) EphemeralPublicKey() [32]byte {
+	return c.ephemeral.Public
+}
+
+// contains reports whether publicKey is one of the circuit's relays.
+func (c *OnionCircuit) contains(publicKey [32]byte) bool {
+	for _, hop := range c.Hops {
+		if hop.PublicKey == publicKey {
+			return true
+		}
+	}
+	return false
+}
+
+// onionState holds a routing table's onion configuration, cached circuit and
+// the requests awaiting a reply through it.
+type onionState struct {
+	mu       sync.Mutex
+	config   *OnionConfig
+	circuit  *OnionCircuit
+	requests map[uint64]time.Time // request ID -> expiry
+}
+
+// SetOnionConfig enables onion routing of lookups with the given configuration.
+// A nil config disables onion routing. The current circuit is discarded.
+func (rt *RoutingTable) SetOnionConfig(config *OnionConfig) {
+	rt.onion.mu.Lock()
+	defer rt.onion.mu.Unlock()
+
+	rt.onion.circuit = nil
+	if config == nil {
+		rt.onion.config = nil
+		return
+	}
+	cfg := *config
+	if cfg.NumHops < 1 {
+		cfg.NumHops = DefaultOnionHops
+	}
+	if cfg.CircuitLifetime <= 0 {
+		cfg.CircuitLifetime = DefaultOnionCircuitLifetime
+	}
+	rt.onion.config = &cfg
+}
+
+// OnionEnabled reports whether lookups are routed through an onion circuit.
+func (rt *RoutingTable) OnionEnabled() bool {
+	rt.onion.mu.Lock()
+	defer rt.onion.mu.Unlock()
+	return rt.onion.config != nil
+}
+
+// OnionCircuit returns the circuit to use for a request to destination.
+// The circuit is built lazily on first use and reused until it reaches
+// CircuitLifetime or until destination is one of its relays.
+func (rt *RoutingTable) OnionCircuit(destination [32]byte) (*OnionCircuit, error) {
+	rt.onion.mu.Lock()
+	defer rt.onion.mu.Unlock()
+
+	cfg := rt.onion.config
+	if cfg == nil {
+		return nil, errors.New("onion routing not enabled")
+	}
+
+	now := getDefaultTimeProvider().Now()
+	if c := rt.onion.circuit; c != nil && now.Sub(c.CreatedAt) < cfg.CircuitLifetime && !c.contains(destination) {
+		return c, nil
+	}
+
+	hops, err := rt.selectRelays(cfg, destination)
+	if err != nil {
+		return nil, err
+	}
+	kp, err := crypto.GenerateKeyPair()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate circuit key: %w", err)
+	}
+
+	rt.onion.circuit = &OnionCircuit{Hops: hops, CreatedAt: now, ephemeral: kp}
+	pkgLog.WithFields(logrus.Fields{
+		"function": "OnionCircuit",
+		"hops":     len(hops),
+	}).Debug("Built new onion circuit")
+	return rt.onion.circuit, nil
+}
+
+// wrapOnionRequest wraps inner for destAddr in circuit under a fresh request
+// ID, which is remembered so the reply is accepted when it returns.
+func (rt *RoutingTable) wrapOnionRequest(circuit *OnionCircuit, destAddr net.Addr, inner *transport.Packet) (*transport.Packet, net.Addr, error) {
+	requestID, err := newOnionID()
+	if err != nil {
+		return nil, nil, err
+	}
+	wrapped, firstHop, err := WrapOnionPacket(circuit, requestID, destAddr, inner)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	now := getDefaultTimeProvider().Now()
+	rt.onion.mu.Lock()
+	defer rt.onion.mu.Unlock()
+	if rt.onion.requests == nil {
+		rt.onion.requests = make(map[uint64]time.Time)
+	}
+	for id, expires := range rt.onion.requests {
+		if now.After(expires) {
+			delete(rt.onion.requests, id)
+		}
+	}
+	if len(rt.onion.requests) >= maxOnionRoutes {
+		return nil, nil, errors.New("too many outstanding onion requests")
+	}
+	rt.onion.requests[requestID] = now.Add(onionReverseRouteTTL)
+	return wrapped, firstHop, nil
+}
+
+// takeOnionRequest removes requestID from the outstanding requests and
+// reports whether it was live.
+func (rt *RoutingTable) takeOnionRequest(requestID uint64, now time.Time) bool {
+	rt.onion.mu.Lock()
+	defer rt.onion.mu.Unlock()
+	expires, ok := rt.onion.requests[requestID]
+	delete(rt.onion.requests, requestID)
+	return ok && !now.After(expires)
+}
+
+// selectRelays picks cfg.NumHops distinct good nodes, never including exclude.
+func (rt *RoutingTable) selectRelays(cfg *OnionConfig, exclude [32]byte) ([]*Node, error) {
+	var candidates []*Node
+	usedBuckets := make(map[int]bool)
+
+	for _, node := range shuffleNodes(rt.GetAllNodes()) {
+		if node.PublicKey == exclude || node.GetStatus() == StatusBad || node.Address == nil {
+			continue
+		}
+		if cfg.RelaySelectionStrategy == RelaySelectBucketDiverse {
+			bucket := computeBucketIndex(rt.selfID, node)
+			if usedBuckets[bucket] {
+				continue
+			}
+			usedBuckets[bucket] = true
+		}
+		candidates = append(candidates, node)
+		if len(candidates) == cfg.NumHops {
+			return candidates, nil
+		}
+	}
+	return nil, ErrInsufficientRelays
+}
+
+// shuffleNodes returns nodes in a cryptographically random order.
+func shuffleNodes(nodes []*Node) []*Node {
+	for i := len(nodes) - 1; i > 0; i-- {
+		j, err := rand.Int(rand.Reader, big.NewInt(int64(i+1)))
+		if err != nil {
+			continue
+		}
+		k := int(j.Int64())
+		nodes[i], nodes[k] = nodes[k], nodes[i]
+	}
+	return nodes
+}
+
+// WrapOnionPacket wraps inner, destined for destAddr, in one encryption layer
+// per circuit hop. It returns the outer packet and the first relay's address.
+// Replies come back to the client tagged with requestID.
+//
+// Each layer is ephemeralPK(32) || nonce(24) || Encrypt(type(1) || circuitID(8) ||
+// replyID(8) || addrLen(1) || addr || payload), where addr is the next hop (or
+// destAddr for the last relay). circuitID is a fresh random value per hop and
+// request, under which the relay remembers the return path; replyID is the
+// previous hop's circuitID, or requestID for the first relay.
+func WrapOnionPacket(circuit *OnionCircuit, requestID uint64, destAddr net.Addr, inner *transport.Packet) (*transport.Packet, net.Addr, error) {
+	if circuit == nil || len(circuit.Hops) == 0 {
+		return nil, nil, errors.New("empty onion circuit")
+	}
+
+	ids := make([]uint64, len(circuit.Hops))
+	for i := range ids {
+		id, err := newOnionID()
+		if err != nil {
+			return nil, nil, err
+		}
+		ids[i] = id
+	}
+
+	next := destAddr
+	packetType := inner.PacketType
+	payload := inner.Data
+	for i := len(circuit.Hops) - 1; i >= 0; i-- {
+		hop := circuit.Hops[i]
+		replyID := requestID
+		if i > 0 {
+			replyID = ids[i-1]
+		}
+		layer, err := encryptOnionLayer(hop.PublicKey, ids[i], replyID, next, packetType, payload)
+		if err != nil {
+			return nil, nil, err
+		}
+		next = hop.Address
+		packetType = transport.PacketOnionSend
+		payload = layer
+	}
+
+	return &transport.Packet{PacketType: transport.PacketOnionSend, Data: payload}, next, nil
+}
+
+// newOnionID returns a random circuit or request identifier.
+func newOnionID() (uint64, error) {
+	var b [8]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return 0, fmt.Errorf("failed to generate onion ID: %w", err)
+	}
+	return binary.BigEndian.Uint64(b[:]), nil
+}
+
+// encryptOnionLayer seals one layer for a relay using a fresh ephemeral key.
+func encryptOnionLayer(relayPK [32]byte, circuitID, replyID uint64, next net.Addr, packetType transport.PacketType, payload []byte) ([]byte, error) {
+	addr := next.String()
+	if len(addr) > 255 {
+		return nil, fmt.Errorf("onion next-hop address too long: %d bytes", len(addr))
+	}
+
+	plain := make([]byte, 0, onionLayerHeaderSize+len(addr)+len(payload))
+	plain = append(plain, byte(packetType))
+	plain = binary.BigEndian.AppendUint64(plain, circuitID)
+	plain = binary.BigEndian.AppendUint64(plain, replyID)
+	plain = append(plain, byte(len(addr)))
+	plain = append(plain, addr...)
+	plain = append(plain, payload...)
+
+	kp, err := crypto.GenerateKeyPair()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate layer key: %w", err)
+	}
+	nonce, err := crypto.GenerateNonce()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate layer nonce: %w", err)
+	}
+	sealed, err := crypto.Encrypt(plain, nonce, relayPK, kp.Private)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt onion layer: %w", err)
+	}
+
+	layer := make([]byte, 0, onionLayerOverhead+len(sealed))
+	layer = append(layer, kp.Public[:]...)
+	layer = append(layer, nonce[:]...)
+	layer = append(layer, sealed...)
+	return layer, nil
+}
+
+// OnionLayer is one peeled onion layer.
+type OnionLayer struct {
+	// Identifies this request's return path at the relay
+	CircuitID uint64
+	// Identifier to tag replies with when returning them to the previous hop
+	ReplyID uint64
+	// Address the inner packet is forwarded to
+	Next string
+	// The packet to forward
+	Packet *transport.Packet
+}
+
+// UnwrapOnionLayer removes one layer addressed to the relay owning secretKey.
+func UnwrapOnionLayer(data []byte, secretKey [32]byte) (*OnionLayer, error) {
+	if len(data) <= onionLayerOverhead {
+		return nil, errors.New("onion layer too short")
+	}
+
+	var senderPK [32]byte
+	var nonce crypto.Nonce
+	copy(senderPK[:], data[:32])
+	copy(nonce[:], data[32:onionLayerOverhead])
+
+	plain, err := crypto.Decrypt(data[onionLayerOverhead:], nonce, senderPK, secretKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt onion layer: %w", err)
+	}
+	if len(plain) < onionLayerHeaderSize || len(plain) < onionLayerHeaderSize+int(plain[onionLayerHeaderSize-1]) {
+		return nil, errors.New("malformed onion layer")
+	}
+
+	addrEnd := onionLayerHeaderSize + int(plain[onionLayerHeaderSize-1])
+	return &OnionLayer{
+		CircuitID: binary.BigEndian.Uint64(plain[1:9]),
+		ReplyID:   binary.BigEndian.Uint64(plain[9:17]),
+		Next:      string(plain[onionLayerHeaderSize:addrEnd]),
+		Packet: &transport.Packet{
+			PacketType: transport.PacketType(plain[0]),
+			Data:       plain[addrEnd:],
+		},
+	}, nil
+}
+
+// onionExitReplies maps the lookup requests an exit relay forwards to the
+// reply type it accepts back for them. No other packet types leave a circuit.
+var onionExitReplies = map[transport.PacketType]transport.PacketType{
+	transport.PacketGetNodes:   transport.PacketSendNodes,
+	transport.PacketGroupQuery: transport.PacketGroupQueryResponse,
+}
+
+// onionRelay holds the relay key and reverse routes of a BootstrapManager.
+type onionRelay struct {
+	mu       sync.Mutex
+	keyPair  *crypto.KeyPair
+	routes   map[uint64]onionReverseRoute // circuit ID -> return path
+	exits    map[onionExitKey][]uint64    // outstanding exit requests, oldest first
+	callback OnionReplyCallback
+}
+
+// onionReverseRoute remembers where replies for one relayed request must be
+// returned.
+type onionReverseRoute struct {
+	previous net.Addr
+	next     string // The only address replies are accepted from
+	replyID  uint64
+	expires  time.Time
+}
+
+// onionExitKey identifies the replies an exit relay is waiting for.
+type onionExitKey struct {
+	addr      string
+	replyType transport.PacketType
+}
+
+// OnionReplyCallback receives the payload of a reply that completed its return
+// path through an onion circuit. For lookups the payload is a SEND_NODES body
+// and senderPK the replying node; for group queries it is a group query
+// response body and senderPK is zero.
+type OnionReplyCallback func(senderPK [32]byte, packetType transport.PacketType, data []byte)
+
+// getOnionRelay returns the relay state, initializing it on first use.
+func (bm *BootstrapManager) getOnionRelay() *onionRelay {
+	bm.onionOnce.Do(func() {
+		bm.onionRelay = &onionRelay{
+			routes: make(map[uint64]onionReverseRoute),
+			exits:  make(map[onionExitKey][]uint64),
+		}
+	})
+	return bm.onionRelay
+}
+
+// EnableOnionRelay lets this node peel and forward onion layers addressed to
+// keyPair. A nil keyPair disables relaying.
+func (bm *BootstrapManager) EnableOnionRelay(keyPair *crypto.KeyPair) {
+	relay := bm.getOnionRelay()
+	relay.mu.Lock()
+	defer relay.mu.Unlock()
+	relay.keyPair = keyPair
+}
+
+// OnOnionReply sets the callback invoked when a reply arrives at the end of
+// its return path, i.e. at the client that built the circuit.
+func (bm *BootstrapManager) OnOnionReply(callback OnionReplyCallback) {
+	relay := bm.getOnionRelay()
+	relay.mu.Lock()
+	defer relay.mu.Unlock()
+	relay.callback = callback
+}
+
+// handleOnionSendPacket peels one onion layer and forwards the inner packet.
+// Only further onion layers and lookup requests are forwarded, so the relay
+// cannot be used to reflect arbitrary packets.
+func (bm *BootstrapManager) handleOnionSendPacket(packet *transport.Packet, senderAddr net.Addr) error {
+	relay := bm.getOnionRelay()
+	relay.mu.Lock()
+	kp := relay.keyPair
+	relay.mu.Unlock()
+	if kp == nil {
+		return ErrOnionRelayDisabled
+	}
+
+	layer, err := UnwrapOnionLayer(packet.Data, kp.Private)
+	if err != nil {
+		return err
+	}
+	inner := layer.Packet
+	replyType, isExit := onionExitReplies[inner.PacketType]
+	if !isExit && inner.PacketType != transport.PacketOnionSend {
+		return fmt.Errorf("%w: packet type %d", ErrOnionForwardRefused, inner.PacketType)
+	}
+	nextAddr, err := net.ResolveUDPAddr("udp", layer.Next)
+	if err != nil {
+		return fmt.Errorf("invalid onion next hop %q: %w", layer.Next, err)
+	}
+
+	route := onionReverseRoute{previous: senderAddr, next: nextAddr.String(), replyID: layer.ReplyID}
+	var exit *onionExitKey
+	if isExit {
+		exit = &onionExitKey{addr: route.next, replyType: replyType}
+	}
+	if err := relay.addReverseRoute(layer.CircuitID, route, exit, bm.getTimeProvider().Now()); err != nil {
+		return err
+	}
+	return bm.transport.Send(inner, nextAddr)
+}
+
+// addReverseRoute records the return path for circuitID, and for an exit
+// request the reply it waits for, after dropping expired routes.
+func (r *onionRelay) addReverseRoute(circuitID uint64, route onionReverseRoute, exit *onionExitKey, now time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.expireLocked(now)
+
+	if _, exists := r.routes[circuitID]; exists {
+		return errors.New("duplicate onion circuit ID")
+	}
+	if len(r.routes) >= maxOnionRoutes {
+		return errors.New("too many outstanding onion requests")
+	}
+	route.expires = now.Add(onionReverseRouteTTL)
+	r.routes[circuitID] = route
+	if exit != nil {
+		r.exits[*exit] = append(r.exits[*exit], circuitID)
+	}
+	return nil
+}
+
+// expireLocked drops expired routes and exit requests. The caller must hold r.mu.
+func (r *onionRelay) expireLocked(now time.Time) {
+	for id, route := range r.routes {
+		if now.After(route.expires) {
+			delete(r.routes, id)
+		}
+	}
+	for key, ids := range r.exits {
+		live := ids[:0]
+		for _, id := range ids {
+			if _, ok := r.routes[id]; ok {
+				live = append(live, id)
+			}
+		}
+		if len(live) == 0 {
+			delete(r.exits, key)
+		} else {
+			r.exits[key] = live
+		}
+	}
+}
+
+// takeRoute removes and returns the route for circuitID if it is live and
+// from is the hop the request was forwarded to.
+func (r *onionRelay) takeRoute(circuitID uint64, from string, now time.Time) (onionReverseRoute, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	route, ok := r.routes[circuitID]
+	if !ok || route.next != from {
+		return onionReverseRoute{}, false
+	}
+	delete(r.routes, circuitID)
+	return route, !now.After(route.expires)
+}
+
+// takeExitRoute removes and returns the route of the oldest live request
+// this node forwarded as an exit that key answers.
+func (r *onionRelay) takeExitRoute(key onionExitKey, now time.Time) (onionReverseRoute, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ids := r.exits[key]
+	for len(ids) > 0 {
+		id := ids[0]
+		ids = ids[1:]
+		route, ok := r.routes[id]
+		if !ok {
+			continue
+		}
+		delete(r.routes, id)
+		if now.After(route.expires) {
+			continue
+		}
+		r.setExitsLocked(key, ids)
+		return route, true
+	}
+	r.setExitsLocked(key, nil)
+	return onionReverseRoute{}, false
+}
+
+// setExitsLocked stores the outstanding exit requests for key. The caller
+// must hold r.mu.
+func (r *onionRelay) setExitsLocked(key onionExitKey, ids []uint64) {
+	if len(ids) == 0 {
+		delete(r.exits, key)
+		return
+	}
+	r.exits[key] = ids
+}
+
+// sendOnionReply sends a reply tagged with replyID to the previous hop.
+func (bm *BootstrapManager) sendOnionReply(route onionReverseRoute, body []byte) error {
+	data := make([]byte, 0, 8+len(body))
+	data = binary.BigEndian.AppendUint64(data, route.replyID)
+	data = append(data, body...)
+	return bm.transport.Send(&transport.Packet{PacketType: transport.PacketOnionReply, Data: data}, route.previous)
+}
+
+// forwardOnionReply returns a lookup reply down the circuit of the request
+// this node forwarded to senderAddr as an exit relay. It returns false if no
+// such request is outstanding, in which case the reply is processed normally.
+func (bm *BootstrapManager) forwardOnionReply(packet *transport.Packet, senderAddr net.Addr) (bool, error) {
+	if senderAddr == nil {
+		return false, nil
+	}
+	key := onionExitKey{addr: senderAddr.String(), replyType: packet.PacketType}
+	route, ok := bm.getOnionRelay().takeExitRoute(key, bm.getTimeProvider().Now())
+	if !ok {
+		return false, nil
+	}
+
+	// Tag the original reply type so the client can interpret it
+	body := append([]byte{byte(packet.PacketType)}, packet.Data...)
+	return true, bm.sendOnionReply(route, body)
+}
+
+// handleOnionReplyPacket forwards a reply towards the client, or delivers it
+// if this node built the circuit. Replies that match neither a relayed
+// request nor one of our own are dropped.
+func (bm *BootstrapManager) handleOnionReplyPacket(packet *transport.Packet, senderAddr net.Addr) error {
+	if len(packet.Data) < 8+1 || senderAddr == nil {
+		return errors.New("onion reply too short")
+	}
+	id := binary.BigEndian.Uint64(packet.Data[:8])
+	now := bm.getTimeProvider().Now()
+
+	relay := bm.getOnionRelay()
+	if route, ok := relay.takeRoute(id, senderAddr.String(), now); ok {
+		return bm.sendOnionReply(route, packet.Data[8:])
+	}
+	if bm.routingTable == nil || !bm.routingTable.takeOnionRequest(id, now) {
+		return errors.New("unsolicited onion reply")
+	}
+
+	packetType := transport.PacketType(packet.Data[8])
+	body := packet.Data[9:]
+	var senderPK [32]byte
+
+	// The replying node is reachable only through the circuit, so only the
+	// nodes it returned are added to the routing table.
+	switch packetType {
+	case transport.PacketSendNodes:
+		if len(body) < 32 {
+			return errors.New("onion reply too short")
+		}
+		copy(senderPK[:], body[:32])
+		if len(body) > 32 && body[32] > 0 {
+			inner := &transport.Packet{PacketType: packetType, Data: body}
+			if _, err := bm.processReceivedNodesWithVersionDetection(inner, int(body[32]), senderAddr); err != nil {
+				return err
+			}
+		}
+	case transport.PacketGroupQueryResponse:
+		if err := bm.deliverGroupQueryResponse(body); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unexpected onion reply type %d", packetType)
+	}
+
+	relay.mu.Lock()
+	callback := relay.callback
+	relay.mu.Unlock()
+	if callback != nil {
+		callback(senderPK, packetType, body)
+	}
+	return nil
+}
@@ -0,0 +1,401 @@
+package dht
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/opd-ai/toxcore/crypto"
+	"github.com/opd-ai/toxcore/transport"
+)
+
+// newOnionTestTable creates a routing table populated with n relay nodes and returns their key pairs.
+func newOnionTestTable(t *testing.T, n int) (*RoutingTable, map[[32]byte]*crypto.KeyPair) {
+	t.Helper()
+	self, err := crypto.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rt := NewRoutingTable(*crypto.NewToxID(self.Public, [4]byte{}), 8)
+	keys := make(map[[32]byte]*crypto.KeyPair)
+	for i := 0; i < n; i++ {
+		kp, err := crypto.GenerateKeyPair()
+		if err != nil {
+			t.Fatal(err)
+		}
+		addr := &net.UDPAddr{IP: net.IPv4(10, 0, 1, byte(i+1)), Port: 33445}
+		rt.AddNode(NewNode(*crypto.NewToxID(kp.Public, [4]byte{}), addr))
+		keys[kp.Public] = kp
+	}
+	return rt, keys
+}
+
+// TestOnionWrapUnwrapRoundTrip verifies each relay can peel exactly its own layer
+func TestOnionWrapUnwrapRoundTrip(t *testing.T) {
+	rt, keys := newOnionTestTable(t, 5)
+	rt.SetOnionConfig(DefaultOnionConfig())
+
+	var dest [32]byte
+	circuit, err := rt.OnionCircuit(dest)
+	if err != nil {
+		t.Fatalf("OnionCircuit failed: %v", err)
+	}
+	if len(circuit.Hops) != DefaultOnionHops {
+		t.Fatalf("expected %d hops, got %d", DefaultOnionHops, len(circuit.Hops))
+	}
+
+	destAddr := &net.UDPAddr{IP: net.IPv4(192, 0, 2, 1), Port: 33445}
+	inner := &transport.Packet{PacketType: transport.PacketGetNodes, Data: []byte("find-node-body")}
+	packet, addr, err := WrapOnionPacket(circuit, 7, destAddr, inner)
+	if err != nil {
+		t.Fatalf("WrapOnionPacket failed: %v", err)
+	}
+	if addr.String() != circuit.Hops[0].Address.String() {
+		t.Fatalf("first hop = %s, want %s", addr, circuit.Hops[0].Address)
+	}
+
+	replyID := uint64(7)
+	for i, hop := range circuit.Hops {
+		if packet.PacketType != transport.PacketOnionSend {
+			t.Fatalf("hop %d received packet type %d", i, packet.PacketType)
+		}
+		layer, err := UnwrapOnionLayer(packet.Data, keys[hop.PublicKey].Private)
+		if err != nil {
+			t.Fatalf("hop %d failed to unwrap: %v", i, err)
+		}
+		want := destAddr.String()
+		if i < len(circuit.Hops)-1 {
+			want = circuit.Hops[i+1].Address.String()
+		}
+		if layer.Next != want {
+			t.Fatalf("hop %d next = %s, want %s", i, layer.Next, want)
+		}
+		// Each hop returns replies under the previous hop's circuit ID
+		if layer.ReplyID != replyID {
+			t.Fatalf("hop %d reply ID = %d, want %d", i, layer.ReplyID, replyID)
+		}
+		replyID = layer.CircuitID
+		packet = layer.Packet
+	}
+
+	if packet.PacketType != transport.PacketGetNodes || !bytes.Equal(packet.Data, inner.Data) {
+		t.Errorf("destination received %d %q", packet.PacketType, packet.Data)
+	}
+}
+
+// TestOnionLayerRejectsWrongKey verifies a relay cannot peel another relay's layer
+func TestOnionLayerRejectsWrongKey(t *testing.T) {
+	rt, _ := newOnionTestTable(t, 3)
+	rt.SetOnionConfig(DefaultOnionConfig())
+	circuit, err := rt.OnionCircuit([32]byte{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	packet, _, err := WrapOnionPacket(circuit, 1, circuit.Hops[0].Address, &transport.Packet{PacketType: transport.PacketGetNodes})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	other, _ := crypto.GenerateKeyPair()
+	if _, err := UnwrapOnionLayer(packet.Data, other.Private); err == nil {
+		t.Error("expected decryption failure with unrelated key")
+	}
+}
+
+// TestOnionCircuitReuse verifies circuits are reused and rebuilt when the destination is a relay
+func TestOnionCircuitReuse(t *testing.T) {
+	rt, _ := newOnionTestTable(t, 6)
+	rt.SetOnionConfig(DefaultOnionConfig())
+
+	first, err := rt.OnionCircuit([32]byte{1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := rt.OnionCircuit([32]byte{2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first != second {
+		t.Error("circuit should be reused across lookups")
+	}
+
+	hopKey := first.Hops[1].PublicKey
+	third, err := rt.OnionCircuit(hopKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if third == first || third.contains(hopKey) {
+		t.Error("circuit containing the destination should be rebuilt without it")
+	}
+}
+
+// TestOnionCircuitInsufficientRelays verifies circuits are not built from too few nodes
+func TestOnionCircuitInsufficientRelays(t *testing.T) {
+	rt, _ := newOnionTestTable(t, 2)
+	rt.SetOnionConfig(DefaultOnionConfig())
+	if _, err := rt.OnionCircuit([32]byte{}); !errors.Is(err, ErrInsufficientRelays) {
+		t.Errorf("expected ErrInsufficientRelays, got %v", err)
+	}
+
+	rt.SetOnionConfig(nil)
+	if rt.OnionEnabled() {
+		t.Error("nil config should disable onion routing")
+	}
+}
+
+// newOnionRelayTest creates a bootstrap manager relaying for relayKP.
+func newOnionRelayTest(t *testing.T, relayKP *crypto.KeyPair) (*BootstrapManager, *MockTransport) {
+	t.Helper()
+	mt := newMockTransport(newMockAddr("127.0.0.1:33445"))
+	selfID := *crypto.NewToxID(relayKP.Public, [4]byte{})
+	bm, err := NewBootstrapManagerForTesting(selfID, mt, NewRoutingTable(selfID, 8), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return bm, mt
+}
+
+// lastSent returns the last packet mt sent and its destination.
+func lastSent(t *testing.T, mt *MockTransport) (*transport.Packet, string) {
+	t.Helper()
+	if len(mt.sentPackets) == 0 {
+		t.Fatal("nothing sent")
+	}
+	return mt.sentPackets[len(mt.sentPackets)-1], mt.sentAddresses[len(mt.sentAddresses)-1].String()
+}
+
+// TestOnionRelayForwardsRequestAndReply verifies an exit relay forwards lookups
+// and returns only the reply that answers them
+func TestOnionRelayForwardsRequestAndReply(t *testing.T) {
+	relayKP, _ := crypto.GenerateKeyPair()
+	bm, mt := newOnionRelayTest(t, relayKP)
+
+	previous := &net.UDPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 33445}
+	dest := &net.UDPAddr{IP: net.IPv4(10, 0, 0, 2), Port: 33445}
+	circuit := &OnionCircuit{Hops: []*Node{{PublicKey: relayKP.Public, Address: previous}}}
+	inner := &transport.Packet{PacketType: transport.PacketGetNodes, Data: make([]byte, 64)}
+	wrapped, _, err := WrapOnionPacket(circuit, 99, dest, inner)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := bm.HandlePacket(wrapped, previous); !errors.Is(err, ErrOnionRelayDisabled) {
+		t.Fatalf("expected ErrOnionRelayDisabled, got %v", err)
+	}
+	bm.EnableOnionRelay(relayKP)
+	if err := bm.HandlePacket(wrapped, previous); err != nil {
+		t.Fatalf("HandlePacket failed: %v", err)
+	}
+	if sent, addr := lastSent(t, mt); addr != dest.String() || sent.PacketType != transport.PacketGetNodes {
+		t.Fatalf("relay sent type %d to %s, want the peeled request to %s", sent.PacketType, addr, dest)
+	}
+
+	// A SEND_NODES from a node we relayed nothing to is processed normally
+	reply := make([]byte, 33)
+	copy(reply, bytes.Repeat([]byte{0x42}, 32))
+	other := &net.UDPAddr{IP: net.IPv4(10, 0, 0, 3), Port: 33445}
+	sentBefore := len(mt.sentPackets)
+	if err := bm.HandlePacket(&transport.Packet{PacketType: transport.PacketSendNodes, Data: reply}, other); err != nil {
+		t.Fatalf("reply handling failed: %v", err)
+	}
+	if len(mt.sentPackets) != sentBefore {
+		t.Fatal("unrelated SEND_NODES must not be relayed")
+	}
+
+	// The destination answers the relay; the reply goes back to the previous hop
+	if err := bm.HandlePacket(&transport.Packet{PacketType: transport.PacketSendNodes, Data: reply}, dest); err != nil {
+		t.Fatalf("reply handling failed: %v", err)
+	}
+	last, addr := lastSent(t, mt)
+	if last.PacketType != transport.PacketOnionReply || addr != previous.String() {
+		t.Fatalf("sent type %d to %s, want onion reply to %s", last.PacketType, addr, previous)
+	}
+	if id := binary.BigEndian.Uint64(last.Data[:8]); id != 99 {
+		t.Errorf("reply ID = %d, want 99", id)
+	}
+	if last.Data[8] != byte(transport.PacketSendNodes) {
+		t.Error("exit reply should be tagged with the original packet type")
+	}
+
+	// The route is consumed by the reply
+	sentBefore = len(mt.sentPackets)
+	if err := bm.HandlePacket(&transport.Packet{PacketType: transport.PacketSendNodes, Data: reply}, dest); err != nil {
+		t.Fatalf("reply handling failed: %v", err)
+	}
+	if len(mt.sentPackets) != sentBefore {
+		t.Error("a second reply must not be relayed")
+	}
+}
+
+// TestOnionRelayRefusesOtherPackets verifies relays only forward lookups and onion layers
+func TestOnionRelayRefusesOtherPackets(t *testing.T) {
+	relayKP, _ := crypto.GenerateKeyPair()
+	bm, mt := newOnionRelayTest(t, relayKP)
+	bm.EnableOnionRelay(relayKP)
+
+	previous := &net.UDPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 33445}
+	dest := &net.UDPAddr{IP: net.IPv4(10, 0, 0, 2), Port: 33445}
+	circuit := &OnionCircuit{Hops: []*Node{{PublicKey: relayKP.Public, Address: previous}}}
+
+	tests := []struct {
+		packetType transport.PacketType
+		allowed    bool
+	}{
+		{transport.PacketGetNodes, true},
+		{transport.PacketGroupQuery, true},
+		{transport.PacketSendNodes, false},
+		{transport.PacketFriendRequest, false},
+		{transport.PacketPingRequest, false},
+	}
+	for _, tt := range tests {
+		wrapped, _, err := WrapOnionPacket(circuit, 1, dest, &transport.Packet{PacketType: tt.packetType, Data: []byte{1}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		sentBefore := len(mt.sentPackets)
+		err = bm.HandlePacket(wrapped, previous)
+		if tt.allowed && (err != nil || len(mt.sentPackets) != sentBefore+1) {
+			t.Errorf("type %d: expected forwarding, got %v", tt.packetType, err)
+		}
+		if !tt.allowed && (!errors.Is(err, ErrOnionForwardRefused) || len(mt.sentPackets) != sentBefore) {
+			t.Errorf("type %d: expected ErrOnionForwardRefused, got %v", tt.packetType, err)
+		}
+	}
+}
+
+// TestOnionRelayRoutesByCircuitID verifies concurrent circuits through the same
+// next hop return replies along their own paths
+func TestOnionRelayRoutesByCircuitID(t *testing.T) {
+	relayKP, _ := crypto.GenerateKeyPair()
+	nextKP, _ := crypto.GenerateKeyPair()
+	bm, mt := newOnionRelayTest(t, relayKP)
+	bm.EnableOnionRelay(relayKP)
+
+	next := &net.UDPAddr{IP: net.IPv4(10, 0, 0, 5), Port: 33445}
+	dest := &net.UDPAddr{IP: net.IPv4(10, 0, 0, 2), Port: 33445}
+	circuit := &OnionCircuit{Hops: []*Node{
+		{PublicKey: relayKP.Public, Address: &net.UDPAddr{IP: net.IPv4(10, 0, 0, 4), Port: 33445}},
+		{PublicKey: nextKP.Public, Address: next},
+	}}
+
+	clients := []*net.UDPAddr{
+		{IP: net.IPv4(10, 0, 1, 1), Port: 33445},
+		{IP: net.IPv4(10, 0, 1, 2), Port: 33445},
+	}
+	circuitIDs := make([]uint64, len(clients))
+	for i, client := range clients {
+		wrapped, _, err := WrapOnionPacket(circuit, uint64(100+i), dest, &transport.Packet{PacketType: transport.PacketGetNodes, Data: make([]byte, 64)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		layer, err := UnwrapOnionLayer(wrapped.Data, relayKP.Private)
+		if err != nil {
+			t.Fatal(err)
+		}
+		circuitIDs[i] = layer.CircuitID
+		if err := bm.HandlePacket(wrapped, client); err != nil {
+			t.Fatalf("HandlePacket failed: %v", err)
+		}
+	}
+
+	reply := func(id uint64, from net.Addr) error {
+		data := binary.BigEndian.AppendUint64(nil, id)
+		data = append(data, byte(transport.PacketSendNodes))
+		return bm.HandlePacket(&transport.Packet{PacketType: transport.PacketOnionReply, Data: data}, from)
+	}
+
+	// Replies claiming a circuit from the wrong hop are dropped
+	if err := reply(circuitIDs[1], dest); err == nil {
+		t.Error("reply from a hop the request was not sent to should be rejected")
+	}
+
+	// The second circuit's reply goes to the second client only
+	if err := reply(circuitIDs[1], next); err != nil {
+		t.Fatalf("reply failed: %v", err)
+	}
+	last, addr := lastSent(t, mt)
+	if addr != clients[1].String() || binary.BigEndian.Uint64(last.Data[:8]) != 101 {
+		t.Errorf("reply sent to %s with ID %d, want %s with ID 101", addr, binary.BigEndian.Uint64(last.Data[:8]), clients[1])
+	}
+	if err := reply(circuitIDs[1], next); err == nil {
+		t.Error("route should be consumed by the first reply")
+	}
+
+	if err := reply(circuitIDs[0], next); err != nil {
+		t.Fatalf("reply failed: %v", err)
+	}
+	if _, addr := lastSent(t, mt); addr != clients[0].String() {
+		t.Errorf("reply sent to %s, want %s", addr, clients[0])
+	}
+}
+
+// TestOnionReplyDeliveredToClient verifies replies to our own requests reach the callback
+func TestOnionReplyDeliveredToClient(t *testing.T) {
+	rt, _ := newOnionTestTable(t, 3)
+	rt.SetOnionConfig(DefaultOnionConfig())
+	mt := newMockTransport(newMockAddr("127.0.0.1:33445"))
+	bm, err := NewBootstrapManagerForTesting(rt.selfID, mt, rt, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var gotSender [32]byte
+	var gotType transport.PacketType
+	bm.OnOnionReply(func(senderPK [32]byte, packetType transport.PacketType, data []byte) {
+		gotSender, gotType = senderPK, packetType
+	})
+
+	dest := &net.UDPAddr{IP: net.IPv4(192, 0, 2, 1), Port: 33445}
+	circuit, err := rt.OnionCircuit([32]byte{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := rt.wrapOnionRequest(circuit, dest, &transport.Packet{PacketType: transport.PacketGetNodes}); err != nil {
+		t.Fatal(err)
+	}
+
+	var requestID uint64
+	for id := range rt.onion.requests {
+		requestID = id
+	}
+	body := append([]byte{byte(transport.PacketSendNodes)}, bytes.Repeat([]byte{0x07}, 32)...)
+	body = append(body, 0)
+	hop := &net.UDPAddr{IP: net.IPv4(10, 0, 0, 9), Port: 33445}
+
+	if err := bm.HandlePacket(&transport.Packet{PacketType: transport.PacketOnionReply, Data: append(binary.BigEndian.AppendUint64(nil, requestID+1), body...)}, hop); err == nil {
+		t.Error("reply to an unknown request should be rejected")
+	}
+	data := append(binary.BigEndian.AppendUint64(nil, requestID), body...)
+	if err := bm.HandlePacket(&transport.Packet{PacketType: transport.PacketOnionReply, Data: data}, hop); err != nil {
+		t.Fatalf("HandlePacket failed: %v", err)
+	}
+	if gotType != transport.PacketSendNodes || gotSender != [32]byte(bytes.Repeat([]byte{0x07}, 32)) {
+		t.Errorf("callback received type %d sender %s", gotType, fmt.Sprintf("%x", gotSender[:4]))
+	}
+	if err := bm.HandlePacket(&transport.Packet{PacketType: transport.PacketOnionReply, Data: data}, hop); err == nil {
+		t.Error("a request should accept only one reply")
+	}
+}
+
+// TestGroupQueryOnionWrapped verifies group queries go through a circuit when onion routing is enabled
+func TestGroupQueryOnionWrapped(t *testing.T) {
+	rt, _ := newOnionTestTable(t, 4)
+	rt.SetOnionConfig(DefaultOnionConfig())
+	for _, node := range rt.GetAllNodes() {
+		node.Update(StatusGood)
+	}
+	mt := newMockTransport(newMockAddr("127.0.0.1:33445"))
+
+	rt.sendQueryToNodes(rt.buildQueryPacket(42), rt.selectNodesToQuery(), mt)
+	if len(mt.sentPackets) == 0 {
+		t.Fatal("no queries sent")
+	}
+	for _, packet := range mt.sentPackets {
+		if packet.PacketType != transport.PacketOnionSend {
+			t.Errorf("query sent as type %d, want onion", packet.PacketType)
+		}
+	}
+}
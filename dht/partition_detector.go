@@ -142,7 +142,7 @@ func (pd *PartitionDetector) Start() error {
 
 	go pd.monitorRoutine()
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":       "Start",
 		"check_interval": pd.config.CheckInterval,
 		"threshold":      pd.config.PartitionThreshold,
@@ -162,7 +162,7 @@ func (pd *PartitionDetector) Stop() {
 	pd.cancel()
 	pd.mu.Unlock()
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "Stop",
 	}).Info("Partition detector stopped")
 }
@@ -309,7 +309,7 @@ func (pd *PartitionDetector) transitionState(oldState, newState PartitionState)
 	callback := pd.onPartition
 	pd.mu.Unlock()
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":  "transitionState",
 		"old_state": oldState.String(),
 		"new_state": newState.String(),
@@ -332,7 +332,7 @@ func (pd *PartitionDetector) attemptRecovery() {
 	recoveryNum := pd.recoveryCount
 	pd.mu.Unlock()
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":       "attemptRecovery",
 		"recovery_count": recoveryNum,
 	}).Info("Attempting partition recovery")
@@ -390,7 +390,7 @@ func (pd *PartitionDetector) handleRecoverySuccess(method string) {
 	callback := pd.onPartition
 	pd.mu.Unlock()
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "handleRecoverySuccess",
 		"method":   method,
 	}).Info("Partition recovery successful")
@@ -406,7 +406,7 @@ func (pd *PartitionDetector) handleRecoveryFailure() {
 	pd.state = StatePartitioned // Back to partitioned state to retry later
 	pd.mu.Unlock()
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "handleRecoveryFailure",
 	}).Warn("Partition recovery failed, will retry")
 }
@@ -420,7 +420,7 @@ func (pd *PartitionDetector) ForceRecoveryAttempt() {
 	pd.mu.Unlock()
 
 	if oldState != StatePartitioned && oldState != StateRecovering {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":  "ForceRecoveryAttempt",
 			"old_state": oldState.String(),
 		}).Info("Forced partition recovery triggered")
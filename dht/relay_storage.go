@@ -8,7 +8,6 @@ import (
 	"time"
 
 	"github.com/opd-ai/toxcore/transport"
-	"github.com/sirupsen/logrus"
 )
 
 // RelayAnnouncement represents a relay server announcement stored in the DHT.
@@ -330,7 +329,7 @@ func (rt *RoutingTable) sendRelayQueriesToNodes(tr transport.Transport, nodes []
 	for _, node := range nodes {
 		if node.GetStatus() == StatusGood && node.Address != nil {
 			if err := tr.Send(packet, node.Address); err != nil {
-				logrus.WithError(err).Debug("dht: best-effort relay query send failed")
+				pkgLog.WithError(err).Debug("dht: best-effort relay query send failed")
 			}
 		}
 	}
@@ -3,6 +3,7 @@ package dht
 import (
 	"container/heap"
 	"container/list"
+	"net"
 	"sync"
 	"time"
 
@@ -226,6 +227,49 @@ func (kb *KBucket) addNodeLocked(node *Node) bool {
 	return false
 }
 
+// replaceNode updates node if it is already in the bucket, or puts it in
+// place of a bad node, without ever growing the bucket.
+func (kb *KBucket) replaceNode(node *Node) bool {
+	kb.mu.Lock()
+	defer kb.mu.Unlock()
+
+	for i, existingNode := range kb.nodes {
+		if existingNode.ID.PublicKey == node.ID.PublicKey {
+			kb.nodes = append(kb.nodes[:i], kb.nodes[i+1:]...)
+			kb.nodes = append(kb.nodes, node)
+			return true
+		}
+	}
+	for i, existingNode := range kb.nodes {
+		if existingNode.GetStatus() == StatusBad {
+			kb.nodes[i] = node
+			return true
+		}
+	}
+	return false
+}
+
+// updateNode replaces the node with the same public key, if there is one.
+func (kb *KBucket) updateNode(node *Node) bool {
+	kb.mu.Lock()
+	defer kb.mu.Unlock()
+
+	for i, existingNode := range kb.nodes {
+		if existingNode.ID.PublicKey == node.ID.PublicKey {
+			kb.nodes[i] = node
+			return true
+		}
+	}
+	return false
+}
+
+// Len returns the number of nodes in the k-bucket.
+func (kb *KBucket) Len() int {
+	kb.mu.RLock()
+	defer kb.mu.RUnlock()
+	return len(kb.nodes)
+}
+
 // GetNodes returns a copy of all nodes in the k-bucket.
 func (kb *KBucket) GetNodes() []*Node {
 	kb.mu.RLock()
@@ -260,6 +304,10 @@ type RoutingTable struct {
 	maxNodes int
 	mu       sync.RWMutex
 
+	// nodeLimit caps the total number of nodes across all buckets; 0 means
+	// the table is bounded only by bucket capacity.
+	nodeLimit int
+
 	// Group storage for DHT-based group discovery
 	groupStorage *GroupStorage
 
@@ -268,6 +316,20 @@ type RoutingTable struct {
 
 	// Lookup cache for reducing repeated FindClosestNodes queries
 	lookupCache *LookupCache
+
+	// Prometheus lookup metrics, created on first use
+	metricsOnce sync.Once
+	metrics     *routingMetrics
+
+	// Optional push-style metrics backend (see SetMetricsRecorder)
+	recorderMu sync.RWMutex
+	recorder   MetricsRecorder
+
+	// Optional onion routing of lookups
+	onion onionState
+
+	// preference selects the address family used for dual-stack nodes
+	preference NetworkPreference
 }
 
 // NewRoutingTable creates a new DHT routing table.
@@ -311,6 +373,11 @@ func (rt *RoutingTable) addNodeWithFn(node *Node, addFn func(bucketIndex int) bo
 	added := func() bool {
 		rt.mu.Lock()
 		defer rt.mu.Unlock()
+		rt.prepareDualStack(node, bucketIndex)
+		if rt.nodeLimit > 0 && rt.sizeLocked() >= rt.nodeLimit {
+			// At the limit, only refresh known nodes or replace bad ones.
+			return rt.kBuckets[bucketIndex].replaceNode(node)
+		}
 		return addFn(bucketIndex)
 	}()
 
@@ -323,6 +390,9 @@ func (rt *RoutingTable) addNodeWithFn(node *Node, addFn func(bucketIndex int) bo
 
 // AddNode adds a node to the appropriate k-bucket in the routing table.
 // If successful, this invalidates the lookup cache since the routing table changed.
+// Dual-stack nodes (see NewDualStackNode) have their Address set to the family
+// selected by the table's NetworkPreference; a node re-added with a single
+// address keeps the other family's address from the existing entry.
 //
 //export ToxDHTRoutingTableAddNode
 func (rt *RoutingTable) AddNode(node *Node) bool {
@@ -536,6 +606,70 @@ func (rt *RoutingTable) GetAllNodes() []*Node {
 	return allNodes
 }
 
+// Size returns the total number of nodes in the routing table.
+//
+//export ToxDHTRoutingTableSize
+func (rt *RoutingTable) Size() int {
+	rt.mu.RLock()
+	defer rt.mu.RUnlock()
+	return rt.sizeLocked()
+}
+
+// sizeLocked counts nodes across all buckets; rt.mu must be held.
+func (rt *RoutingTable) sizeLocked() int {
+	total := 0
+	for _, bucket := range rt.kBuckets {
+		total += bucket.Len()
+	}
+	return total
+}
+
+// SetNodeLimit caps the total number of nodes the routing table holds.
+// Once the limit is reached, new nodes are only accepted in place of bad
+// ones. A limit of 0 or less removes the cap.
+//
+//export ToxDHTRoutingTableSetNodeLimit
+func (rt *RoutingTable) SetNodeLimit(limit int) {
+	if limit < 0 {
+		limit = 0
+	}
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	rt.nodeLimit = limit
+}
+
+// UpdateNodeAddress moves the known node with publicKey to addr and marks it
+// good, as when a peer announces that its network address has changed.
+// Unknown keys are ignored, so the routing table never grows through this
+// path. It reports whether a node was updated.
+//
+//export ToxDHTRoutingTableUpdateNodeAddress
+func (rt *RoutingTable) UpdateNodeAddress(publicKey [32]byte, addr net.Addr) bool {
+	if publicKey == rt.selfID.PublicKey {
+		return false
+	}
+
+	var nospam [4]byte
+	node := NewNode(*crypto.NewToxID(publicKey, nospam), addr)
+	node.Update(StatusGood)
+
+	rt.mu.Lock()
+	updated := rt.kBuckets[computeBucketIndex(rt.selfID, node)].updateNode(node)
+	rt.mu.Unlock()
+
+	if updated && rt.lookupCache != nil {
+		rt.lookupCache.Clear()
+	}
+	return updated
+}
+
+// NodeLimit returns the node limit set by SetNodeLimit, or 0 if there is none.
+func (rt *RoutingTable) NodeLimit() int {
+	rt.mu.RLock()
+	defer rt.mu.RUnlock()
+	return rt.nodeLimit
+}
+
 // computeBucketIndex calculates the bucket index for a node relative to a self ID.
 // It creates a temporary node from selfID for distance calculation.
 func computeBucketIndex(selfID crypto.ToxID, node *Node) int {
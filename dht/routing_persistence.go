@@ -0,0 +1,269 @@
+package dht
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/opd-ai/toxcore/crypto"
+	"github.com/opd-ai/toxcore/transport"
+	"github.com/sirupsen/logrus"
+)
+
+// routingTableFormatVersion is the version of the saved routing table format.
+const routingTableFormatVersion = 1
+
+// RestoredNodeTTL bounds how old a saved node may be when the routing table
+// is loaded. Age counts from the node's saved last-seen time, not from the
+// load, so a long-forgotten save does not send pings to peers that have
+// likely left the network.
+const RestoredNodeTTL = 24 * time.Hour
+
+// ErrUnsupportedRoutingTableVersion is returned by Load for a saved routing
+// table written in an unknown format.
+var ErrUnsupportedRoutingTableVersion = errors.New("unsupported routing table format version")
+
+// routingTableFile is the on-disk format of a saved routing table.
+type routingTableFile struct {
+	Version int                `json:"version"`
+	SavedAt time.Time          `json:"saved_at"`
+	Nodes   []routingTableNode `json:"nodes"`
+}
+
+// routingTableNode is the JSON representation of one routing table entry.
+type routingTableNode struct {
+	PublicKey string     `json:"public_key"`
+	Network   string     `json:"network"`
+	Address   string     `json:"address"`
+	LastSeen  time.Time  `json:"last_seen"`
+	Status    NodeStatus `json:"status"`
+}
+
+// Save writes every node in the routing table, with its public key, address,
+// last-seen time and status, to w as JSON.
+func (rt *RoutingTable) Save(w io.Writer) error {
+	nodes := rt.GetAllNodes()
+	file := routingTableFile{
+		Version: routingTableFormatVersion,
+		SavedAt: getDefaultTimeProvider().Now().UTC(),
+		Nodes:   make([]routingTableNode, 0, len(nodes)),
+	}
+	for _, node := range nodes {
+		node.mu.RLock()
+		addr, lastSeen, status := node.Address, node.LastSeen, node.Status
+		node.mu.RUnlock()
+		if addr == nil {
+			continue
+		}
+		file.Nodes = append(file.Nodes, routingTableNode{
+			PublicKey: hex.EncodeToString(node.PublicKey[:]),
+			Network:   addr.Network(),
+			Address:   addr.String(),
+			LastSeen:  lastSeen.UTC(),
+			Status:    status,
+		})
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(file); err != nil {
+		return fmt.Errorf("failed to serialize routing table: %w", err)
+	}
+	return nil
+}
+
+// Load adds the nodes saved by Save to the routing table. Restored nodes keep
+// their saved last-seen time but start in StatusUnknown, so they are pinged
+// before they are trusted. Nodes last seen more than RestoredNodeTTL ago, and
+// nodes with addresses that no longer resolve, are skipped.
+func (rt *RoutingTable) Load(r io.Reader) error {
+	_, err := rt.load(r)
+	return err
+}
+
+// load implements Load and returns the restored nodes.
+func (rt *RoutingTable) load(r io.Reader) ([]*Node, error) {
+	var file routingTableFile
+	if err := json.NewDecoder(r).Decode(&file); err != nil {
+		return nil, fmt.Errorf("failed to parse routing table: %w", err)
+	}
+	if file.Version != routingTableFormatVersion {
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedRoutingTableVersion, file.Version)
+	}
+
+	now := getDefaultTimeProvider().Now()
+	var restored []*Node
+	skipped := 0
+	for _, saved := range file.Nodes {
+		node, err := restoreNode(saved, now)
+		if err != nil {
+			skipped++
+			pkgLog.WithFields(logrus.Fields{
+				"function": "Load",
+				"address":  saved.Address,
+				"error":    err.Error(),
+			}).Debug("Skipping saved routing table node")
+			continue
+		}
+		if rt.hasNode(node.PublicKey) {
+			continue // Live state wins over the save
+		}
+		if rt.AddNode(node) {
+			restored = append(restored, node)
+		}
+	}
+
+	pkgLog.WithFields(logrus.Fields{
+		"function": "Load",
+		"saved_at": file.SavedAt,
+		"restored": len(restored),
+		"skipped":  skipped,
+	}).Info("Routing table restored")
+	return restored, nil
+}
+
+// restoreNode rebuilds a node from its saved form.
+func restoreNode(saved routingTableNode, now time.Time) (*Node, error) {
+	decoded, err := hex.DecodeString(saved.PublicKey)
+	if err != nil || len(decoded) != 32 {
+		return nil, errors.New("invalid public key")
+	}
+	if now.Sub(saved.LastSeen) > RestoredNodeTTL {
+		return nil, fmt.Errorf("last seen %s, older than %s", saved.LastSeen, RestoredNodeTTL)
+	}
+	addr, err := resolveSavedAddr(saved.Network, saved.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	var publicKey [32]byte
+	var nospam [4]byte // Zeros for DHT nodes
+	copy(publicKey[:], decoded)
+	node := NewNode(*crypto.NewToxID(publicKey, nospam), addr)
+	node.LastSeen = saved.LastSeen
+	node.Status = StatusUnknown
+	return node, nil
+}
+
+// hasNode reports whether a node with publicKey is in the routing table.
+func (rt *RoutingTable) hasNode(publicKey [32]byte) bool {
+	return rt.getNode(publicKey) != nil
+}
+
+// getNode returns the routing table node with publicKey, or nil.
+func (rt *RoutingTable) getNode(publicKey [32]byte) *Node {
+	index := computeBucketIndex(rt.selfID, &Node{PublicKey: publicKey})
+	for _, node := range rt.kBuckets[index].GetNodes() {
+		if node.PublicKey == publicKey {
+			return node
+		}
+	}
+	return nil
+}
+
+// resolveSavedAddr turns a saved network and address back into a net.Addr.
+func resolveSavedAddr(network, address string) (net.Addr, error) {
+	switch {
+	case strings.HasPrefix(network, "udp"):
+		return net.ResolveUDPAddr(network, address)
+	case strings.HasPrefix(network, "tcp"):
+		return net.ResolveTCPAddr(network, address)
+	default:
+		return nil, fmt.Errorf("unsupported network %q", network)
+	}
+}
+
+// SaveFile saves the routing table to path, atomically via a temporary file
+// and rename.
+func (rt *RoutingTable) SaveFile(path string) error {
+	tmpFile := path + ".tmp"
+	f, err := os.OpenFile(tmpFile, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to create temporary routing table: %w", err)
+	}
+	if err := rt.Save(f); err != nil {
+		f.Close()
+		os.Remove(tmpFile)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmpFile)
+		return fmt.Errorf("failed to write temporary routing table: %w", err)
+	}
+	if err := os.Rename(tmpFile, path); err != nil {
+		return fmt.Errorf("failed to rename routing table: %w", err)
+	}
+	return nil
+}
+
+// LoadFile loads a routing table saved with SaveFile. A missing file is not
+// an error and restores no nodes.
+func (rt *RoutingTable) LoadFile(path string) error {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read routing table: %w", err)
+	}
+	defer f.Close()
+	return rt.Load(f)
+}
+
+// NewBootstrapManagerWithSavedRoutingTable is NewBootstrapManagerWithKeyPair
+// for a node restarting with a routing table saved by RoutingTable.Save: the
+// table is restored from saved, if non-nil, before the manager is returned.
+// The restored nodes are pinged when Bootstrap is next called, alongside the
+// usual bootstrap nodes, so the table refills without waiting for them.
+func NewBootstrapManagerWithSavedRoutingTable(selfID crypto.ToxID, keyPair *crypto.KeyPair, transportArg transport.Transport, routingTable *RoutingTable, saved io.Reader) (*BootstrapManager, error) {
+	bm, err := NewBootstrapManagerWithKeyPair(selfID, keyPair, transportArg, routingTable)
+	if err != nil {
+		return nil, err
+	}
+	if saved == nil {
+		return bm, nil
+	}
+	restored, err := routingTable.load(saved)
+	if err != nil {
+		return nil, err
+	}
+	bm.mu.Lock()
+	bm.restoredNodes = restored
+	bm.mu.Unlock()
+	return bm, nil
+}
+
+// pingRestoredNodes pings the nodes restored from a saved routing table once,
+// so those that respond are marked good. Nodes that do not respond stay in
+// StatusUnknown until maintenance ages them out.
+func (bm *BootstrapManager) pingRestoredNodes() {
+	bm.mu.Lock()
+	nodes := bm.restoredNodes
+	bm.restoredNodes = nil
+	bm.mu.Unlock()
+	if len(nodes) == 0 || bm.transport == nil {
+		return
+	}
+
+	packet := &transport.Packet{
+		PacketType: transport.PacketPingRequest,
+		Data:       createPingPacket(bm.selfID.PublicKey),
+	}
+	for _, node := range nodes {
+		node.RecordPingSent()
+		if err := bm.transport.Send(packet, node.Address); err != nil {
+			pkgLog.WithError(err).Debug("dht: best-effort restored node ping failed")
+		}
+	}
+
+	pkgLog.WithFields(logrus.Fields{
+		"function": "pingRestoredNodes",
+		"nodes":    len(nodes),
+	}).Info("Pinged nodes restored from saved routing table")
+}
@@ -0,0 +1,215 @@
+package dht
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opd-ai/toxcore/crypto"
+	"github.com/opd-ai/toxcore/transport"
+)
+
+// newPersistenceTestNode creates a node with a public key starting with b.
+func newPersistenceTestNode(t *testing.T, b byte, addr net.Addr, lastSeen time.Time, status NodeStatus) *Node {
+	t.Helper()
+	node := NewNode(crypto.ToxID{PublicKey: [32]byte{b, 0x42}}, addr)
+	node.LastSeen = lastSeen
+	node.Status = status
+	return node
+}
+
+func TestRoutingTableSaveLoadRoundTrip(t *testing.T) {
+	selfID := crypto.ToxID{PublicKey: [32]byte{0xaa}}
+	original := NewRoutingTable(selfID, 8)
+	seen := time.Now().Add(-time.Hour).Truncate(time.Second)
+
+	udpAddr, _ := net.ResolveUDPAddr("udp", "192.0.2.1:33445")
+	tcpAddr, _ := net.ResolveTCPAddr("tcp", "[2001:db8::1]:33446")
+	nodes := []*Node{
+		newPersistenceTestNode(t, 0x01, udpAddr, seen, StatusGood),
+		newPersistenceTestNode(t, 0x02, tcpAddr, seen.Add(time.Minute), StatusBad),
+		newPersistenceTestNode(t, 0x80, udpAddr, seen.Add(2*time.Minute), StatusUnknown),
+	}
+	for _, node := range nodes {
+		if !original.AddNode(node) {
+			t.Fatalf("Failed to add node %x", node.PublicKey[0])
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := original.Save(&buf); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	for _, want := range []string{`"network": "udp"`, `"address": "[2001:db8::1]:33446"`, `"status": 2`} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("Expected saved table to contain %s:\n%s", want, buf.String())
+		}
+	}
+
+	restored := NewRoutingTable(selfID, 8)
+	if err := restored.Load(&buf); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if restored.Size() != len(nodes) {
+		t.Fatalf("Expected %d restored nodes, got %d", len(nodes), restored.Size())
+	}
+
+	byKey := make(map[[32]byte]*Node)
+	for _, node := range restored.GetAllNodes() {
+		byKey[node.PublicKey] = node
+	}
+	for _, want := range nodes {
+		got, ok := byKey[want.PublicKey]
+		if !ok {
+			t.Fatalf("Node %x was not restored", want.PublicKey[0])
+		}
+		if got.ID.PublicKey != want.PublicKey {
+			t.Errorf("Node %x restored with ID %x", want.PublicKey[0], got.ID.PublicKey[:2])
+		}
+		if got.Address.Network() != want.Address.Network() || got.Address.String() != want.Address.String() {
+			t.Errorf("Node %x restored at %s/%s, want %s/%s", want.PublicKey[0],
+				got.Address.Network(), got.Address, want.Address.Network(), want.Address)
+		}
+		if !got.GetLastSeen().Equal(want.GetLastSeen()) {
+			t.Errorf("Node %x restored with last seen %v, want %v", want.PublicKey[0], got.GetLastSeen(), want.GetLastSeen())
+		}
+		if got.GetStatus() != StatusUnknown {
+			t.Errorf("Node %x restored with status %d, want StatusUnknown", want.PublicKey[0], got.GetStatus())
+		}
+	}
+
+	closest := restored.FindClosestNodes(crypto.ToxID{PublicKey: [32]byte{0x01}}, 1)
+	if len(closest) != 1 || closest[0].PublicKey[0] != 0x01 {
+		t.Error("Expected restored nodes to be placed in their buckets")
+	}
+}
+
+func TestRoutingTableLoadSkipsStaleAndInvalidNodes(t *testing.T) {
+	selfID := crypto.ToxID{PublicKey: [32]byte{0xaa}}
+	original := NewRoutingTable(selfID, 8)
+	udpAddr, _ := net.ResolveUDPAddr("udp", "192.0.2.1:33445")
+
+	fresh := newPersistenceTestNode(t, 0x01, udpAddr, time.Now().Add(-time.Hour), StatusGood)
+	stale := newPersistenceTestNode(t, 0x02, udpAddr, time.Now().Add(-RestoredNodeTTL-time.Hour), StatusGood)
+	unsupported := newPersistenceTestNode(t, 0x03, newMockAddr("node.onion:33445"), time.Now(), StatusGood)
+	for _, node := range []*Node{fresh, stale, unsupported} {
+		original.AddNode(node)
+	}
+
+	var buf bytes.Buffer
+	if err := original.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	restored := NewRoutingTable(selfID, 8)
+	if err := restored.Load(&buf); err != nil {
+		t.Fatal(err)
+	}
+	all := restored.GetAllNodes()
+	if len(all) != 1 || all[0].PublicKey != fresh.PublicKey {
+		t.Errorf("Expected only the fresh node to be restored, got %d nodes", len(all))
+	}
+
+	// Nodes already in the table keep their live state
+	live := newPersistenceTestNode(t, 0x01, udpAddr, time.Now(), StatusGood)
+	current := NewRoutingTable(selfID, 8)
+	current.AddNode(live)
+	buf.Reset()
+	if err := original.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if err := current.Load(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if all := current.GetAllNodes(); len(all) != 1 || all[0] != live || all[0].GetStatus() != StatusGood {
+		t.Error("Expected Load not to replace a node already in the table")
+	}
+}
+
+func TestRoutingTableLoadErrors(t *testing.T) {
+	rt := NewRoutingTable(crypto.ToxID{PublicKey: [32]byte{0xaa}}, 8)
+	if err := rt.Load(strings.NewReader("not json")); err == nil {
+		t.Error("Expected malformed input to fail")
+	}
+	if err := rt.Load(strings.NewReader(`{"version": 99, "nodes": []}`)); !errors.Is(err, ErrUnsupportedRoutingTableVersion) {
+		t.Errorf("Expected ErrUnsupportedRoutingTableVersion, got %v", err)
+	}
+}
+
+func TestRoutingTableSaveLoadFile(t *testing.T) {
+	selfID := crypto.ToxID{PublicKey: [32]byte{0xaa}}
+	path := filepath.Join(t.TempDir(), "routing.json")
+
+	rt := NewRoutingTable(selfID, 8)
+	if err := rt.LoadFile(path); err != nil || rt.Size() != 0 {
+		t.Fatalf("Expected a missing file to restore nothing, got %d nodes, err %v", rt.Size(), err)
+	}
+
+	udpAddr, _ := net.ResolveUDPAddr("udp", "192.0.2.1:33445")
+	rt.AddNode(newPersistenceTestNode(t, 0x01, udpAddr, time.Now(), StatusGood))
+	if err := rt.SaveFile(path); err != nil {
+		t.Fatalf("SaveFile failed: %v", err)
+	}
+
+	restored := NewRoutingTable(selfID, 8)
+	if err := restored.LoadFile(path); err != nil {
+		t.Fatalf("LoadFile failed: %v", err)
+	}
+	if restored.Size() != 1 {
+		t.Errorf("Expected 1 restored node, got %d", restored.Size())
+	}
+}
+
+func TestBootstrapManagerPingsRestoredNodes(t *testing.T) {
+	selfID := crypto.ToxID{PublicKey: [32]byte{0xaa}}
+	udpAddr, _ := net.ResolveUDPAddr("udp", "192.0.2.1:33445")
+	saved := NewRoutingTable(selfID, 8)
+	saved.AddNode(newPersistenceTestNode(t, 0x01, udpAddr, time.Now(), StatusGood))
+	var buf bytes.Buffer
+	if err := saved.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	mt := newMockTransport(newMockAddr("127.0.0.1:33445"))
+	rt := NewRoutingTable(selfID, 8)
+	bm, err := NewBootstrapManagerWithSavedRoutingTable(selfID, nil, mt, rt, &buf)
+	if err != nil {
+		t.Fatalf("NewBootstrapManagerWithSavedRoutingTable failed: %v", err)
+	}
+	if rt.Size() != 1 {
+		t.Fatalf("Expected the saved node to be restored, got %d nodes", rt.Size())
+	}
+
+	// Bootstrap fails without bootstrap nodes, but still pings restored nodes
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_ = bm.Bootstrap(ctx)
+	packets, addrs := mt.GetSentPackets()
+	if len(packets) != 1 || packets[0].PacketType != transport.PacketPingRequest || addrs[0].String() != udpAddr.String() {
+		t.Fatalf("Expected one ping to the restored node, got %d packets", len(packets))
+	}
+
+	// A ping response marks the node good
+	response := &transport.Packet{PacketType: transport.PacketPingResponse, Data: make([]byte, 32)}
+	copy(response.Data, rt.GetAllNodes()[0].PublicKey[:])
+	if err := bm.handlePingResponsePacket(response, udpAddr); err != nil {
+		t.Fatal(err)
+	}
+	if status := rt.GetAllNodes()[0].GetStatus(); status != StatusGood {
+		t.Errorf("Expected the restored node to be good after a ping response, got %d", status)
+	}
+
+	// Restored nodes are pinged only once
+	_ = bm.Bootstrap(ctx)
+	if packets, _ := mt.GetSentPackets(); len(packets) != 1 {
+		t.Errorf("Expected no further pings, got %d packets", len(packets))
+	}
+
+	if _, err := NewBootstrapManagerWithSavedRoutingTable(selfID, nil, mt, NewRoutingTable(selfID, 8), strings.NewReader("{")); err == nil {
+		t.Error("Expected a corrupt saved table to fail construction")
+	}
+}
@@ -28,6 +28,10 @@
 //	    fmt.Printf("Message from %d: %s\n", friendID, message)
 //	})
 //
+// New validates its options with Options.Validate before starting any
+// transport. A failure wraps a sentinel such as ErrInvalidProxyPort or
+// ErrSavedataMismatch, so callers can test it with errors.Is.
+//
 //	// Connect to the Tox network
 //	err = tox.Bootstrap("node.tox.biribiri.org", 33445,
 //	    "F404ABAA1C99A9D37D61AB54898F56793E1DEF8BD46B1038B9D822E8460FAB67")
@@ -41,6 +45,18 @@
 //	    time.Sleep(tox.IterationInterval())
 //	}
 //
+// Services that must shut down on a signal can run the loop with
+// IterateWithContext instead, which returns ctx.Err() within one iteration
+// interval of cancellation (or ErrToxKilled after Kill):
+//
+//	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM)
+//	defer stop()
+//	_ = tox.IterateWithContext(ctx)
+//	tox.Kill()
+//
+// Kill cancels the instance's lifecycle context ([Tox.Context]), which stops
+// background message sends, and stops the async manager.
+//
 // # Core Types
 //
 // The package defines several core types:
@@ -56,6 +72,10 @@
 //	// Add friend by Tox address (includes nospam)
 //	friendID, err := tox.AddFriend(toxAddress, "Hello!")
 //
+//	// Parse an address from text (hex or tox: URI) or a scanned QR code
+//	address, err := toxcore.ParseToxAddressString("tox:" + hexAddress)
+//	address, err = toxcore.ParseToxQRCode(pngData)
+//
 //	// Add friend by public key (requires prior mutual agreement)
 //	friendID, err := tox.AddFriendByPublicKey(publicKey)
 //
@@ -141,6 +161,38 @@
 //	options.ProxyHost = "127.0.0.1"
 //	options.ProxyPort = 9050
 //
+// # Logging
+//
+// The dht, transport, async, messaging and av packages each log through
+// their own component logger, tagged with a "component" field. Components
+// follow the global logrus level unless given their own:
+//
+//	options.LogConfig = map[string]string{"dht": "debug"}
+//	tox, err := toxcore.New(options)
+//	tox.SetComponentLogLevel("transport", logrus.WarnLevel)
+//
+// # Health Checks
+//
+// Server deployments can expose liveness and readiness probes over HTTP.
+// GET /healthz reports the connection status, online friends and uptime as
+// JSON; GET /readyz returns 503 until the DHT has at least one good node:
+//
+//	if err := tox.StartHealthServer("127.0.0.1:8080"); err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer tox.StopHealthServer()
+//
+// # Resource Limits
+//
+// Options.ResourceLimits caps friends, conferences, pending friend requests,
+// DHT nodes and queued async messages. Operations that would exceed a limit
+// fail with an error wrapping ErrResourceLimit; ResourceUsage reports
+// current usage against each limit:
+//
+//	options.ResourceLimits.MaxFriends = 500
+//	usage := tox.ResourceUsage()
+//	fmt.Printf("%d/%d friends\n", usage.Friends.Current, usage.Friends.Limit)
+//
 // # Persistence
 //
 // Save and restore Tox state:
@@ -155,6 +207,13 @@
 //	options.SavedataType = toxcore.SavedataTypeToxSave
 //	tox, err := toxcore.New(options)
 //
+// Setting Options.SavedataPassphrase encrypts GetSavedata output with
+// AES-256-GCM under an Argon2id-derived key. The header records the Argon2id
+// parameters and salt, so New and NewFromSavedata recognise encrypted data
+// (IsSavedataEncrypted) and decrypt it with the same passphrase, returning
+// ErrWrongPassphrase when authentication fails. The passphrase slice is wiped
+// once the key is derived, and Kill wipes the key.
+//
 // # Deterministic Testing
 //
 // For reproducible testing, time-dependent components support injectable time providers:
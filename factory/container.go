@@ -0,0 +1,167 @@
+package factory
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Well-known service names used by the toxcore factories.
+const (
+	// ServicePacketDelivery resolves to an interfaces.IPacketDelivery.
+	ServicePacketDelivery = "packet_delivery"
+
+	// ServiceNetworkTransport resolves to an interfaces.INetworkTransport.
+	// It is optional; when absent only simulation delivery can be built.
+	ServiceNetworkTransport = "network_transport"
+)
+
+// ErrServiceNotRegistered indicates that Resolve was called for a name with
+// no registered provider.
+var ErrServiceNotRegistered = errors.New("service not registered")
+
+// Provider builds a service, resolving its own dependencies from c.
+type Provider func(c *Container) (interface{}, error)
+
+// serviceEntry holds a provider and its lazily created singleton instance.
+type serviceEntry struct {
+	mu       sync.Mutex
+	provider Provider
+	instance interface{}
+	resolved bool
+}
+
+// containerRegistry is the state shared by a Container and the scoped views
+// handed to providers.
+type containerRegistry struct {
+	mu       sync.RWMutex
+	services map[string]*serviceEntry
+}
+
+// Container is a minimal dependency injection registry. Services are
+// registered by name with a Provider and created on first Resolve; the
+// result of a successful build is cached, so each name yields a singleton.
+// A failed build is not cached and is retried on the next Resolve.
+//
+// Providers receive a Container that remembers the chain of services being
+// built. Resolving a name already in that chain panics with the full cycle,
+// e.g. "a -> b -> a", because a cycle is a wiring bug rather than a runtime
+// condition. Detection covers cycles within a single resolution; two
+// goroutines resolving opposite ends of a cycle at the same time will block.
+//
+// Container is safe for concurrent use.
+type Container struct {
+	registry *containerRegistry
+	path     []string
+}
+
+// NewContainer creates an empty container.
+func NewContainer() *Container {
+	return &Container{
+		registry: &containerRegistry{services: make(map[string]*serviceEntry)},
+	}
+}
+
+// Register associates provider with name, replacing any earlier
+// registration and discarding its cached instance.
+func (c *Container) Register(name string, provider Provider) {
+	c.registry.mu.Lock()
+	c.registry.services[name] = &serviceEntry{provider: provider}
+	c.registry.mu.Unlock()
+
+	logrus.WithFields(logrus.Fields{
+		"function": "Container.Register",
+		"service":  name,
+	}).Debug("Registered service provider")
+}
+
+// Has reports whether a provider is registered under name.
+func (c *Container) Has(name string) bool {
+	c.registry.mu.RLock()
+	defer c.registry.mu.RUnlock()
+	_, ok := c.registry.services[name]
+	return ok
+}
+
+// Names returns the registered service names in sorted order.
+func (c *Container) Names() []string {
+	c.registry.mu.RLock()
+	names := make([]string, 0, len(c.registry.services))
+	for name := range c.registry.services {
+		names = append(names, name)
+	}
+	c.registry.mu.RUnlock()
+	sort.Strings(names)
+	return names
+}
+
+// Resolve returns the service registered under name, building it on first
+// use. It panics if name is already being built further up the current
+// resolution chain.
+func (c *Container) Resolve(name string) (interface{}, error) {
+	for _, pending := range c.path {
+		if pending == name {
+			cycle := append(append([]string(nil), c.path...), name)
+			panic(fmt.Sprintf("factory: circular dependency: %s", strings.Join(cycle, " -> ")))
+		}
+	}
+
+	c.registry.mu.RLock()
+	entry, ok := c.registry.services[name]
+	c.registry.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrServiceNotRegistered, name)
+	}
+
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+	if entry.resolved {
+		return entry.instance, nil
+	}
+
+	scoped := &Container{
+		registry: c.registry,
+		path:     append(append([]string(nil), c.path...), name),
+	}
+	instance, err := entry.provider(scoped)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"function": "Container.Resolve",
+			"service":  name,
+			"error":    err.Error(),
+		}).Warn("Service provider failed")
+		return nil, fmt.Errorf("resolve %q: %w", name, err)
+	}
+
+	entry.instance = instance
+	entry.resolved = true
+
+	logrus.WithFields(logrus.Fields{
+		"function": "Container.Resolve",
+		"service":  name,
+		"type":     fmt.Sprintf("%T", instance),
+	}).Debug("Resolved service")
+
+	return instance, nil
+}
+
+// MustResolveAs resolves name and asserts the result to T, panicking if the
+// service is missing, fails to build, or has a different type. It is meant
+// for application wiring where a missing dependency is unrecoverable. Go
+// methods cannot take type parameters, so the container is an argument.
+func MustResolveAs[T any](c *Container, name string) T {
+	instance, err := c.Resolve(name)
+	if err != nil {
+		panic(fmt.Sprintf("factory: %v", err))
+	}
+	typed, ok := instance.(T)
+	if !ok {
+		panic(fmt.Sprintf("factory: service %q is %T, not %v", name, instance, reflect.TypeOf((*T)(nil)).Elem()))
+	}
+	return typed
+}
@@ -0,0 +1,121 @@
+package factory
+
+import (
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/opd-ai/toxcore/interfaces"
+)
+
+func TestContainerResolveSingleton(t *testing.T) {
+	c := NewContainer()
+	var builds int32
+	c.Register("counter", func(c *Container) (interface{}, error) {
+		atomic.AddInt32(&builds, 1)
+		return new(int), nil
+	})
+
+	first, err := c.Resolve("counter")
+	if err != nil {
+		t.Fatalf("Resolve failed: %v", err)
+	}
+	second, err := c.Resolve("counter")
+	if err != nil {
+		t.Fatalf("Resolve failed: %v", err)
+	}
+	if first != second {
+		t.Error("Expected the same instance on repeated Resolve")
+	}
+	if builds != 1 {
+		t.Errorf("Expected provider to run once, ran %d times", builds)
+	}
+}
+
+func TestContainerResolveErrors(t *testing.T) {
+	c := NewContainer()
+	if _, err := c.Resolve("missing"); !errors.Is(err, ErrServiceNotRegistered) {
+		t.Errorf("Expected ErrServiceNotRegistered, got %v", err)
+	}
+
+	errBoom := errors.New("boom")
+	attempts := 0
+	c.Register("flaky", func(c *Container) (interface{}, error) {
+		attempts++
+		if attempts == 1 {
+			return nil, errBoom
+		}
+		return "ok", nil
+	})
+	if _, err := c.Resolve("flaky"); !errors.Is(err, errBoom) {
+		t.Errorf("Expected provider error, got %v", err)
+	}
+	if v, err := c.Resolve("flaky"); err != nil || v != "ok" {
+		t.Errorf("Expected retry to succeed, got %v, %v", v, err)
+	}
+}
+
+func TestContainerCircularDependencyPanics(t *testing.T) {
+	c := NewContainer()
+	c.Register("a", func(c *Container) (interface{}, error) { return c.Resolve("b") })
+	c.Register("b", func(c *Container) (interface{}, error) { return c.Resolve("c") })
+	c.Register("c", func(c *Container) (interface{}, error) { return c.Resolve("a") })
+
+	defer func() {
+		r := recover()
+		msg, ok := r.(string)
+		if !ok || !strings.Contains(msg, "a -> b -> c -> a") {
+			t.Errorf("Expected cycle panic, got %v", r)
+		}
+		// The container must stay usable after the panic unwinds
+		c.Register("a", func(c *Container) (interface{}, error) { return 1, nil })
+		if _, err := c.Resolve("a"); err != nil {
+			t.Errorf("Resolve after cycle failed: %v", err)
+		}
+	}()
+	c.Resolve("a")
+}
+
+func TestMustResolveAs(t *testing.T) {
+	c := NewContainer()
+	c.Register("name", func(c *Container) (interface{}, error) { return "tox", nil })
+
+	if got := MustResolveAs[string](c, "name"); got != "tox" {
+		t.Errorf("MustResolveAs = %q, want %q", got, "tox")
+	}
+
+	defer func() {
+		if r := recover(); r == nil || !strings.Contains(r.(string), "not int") {
+			t.Errorf("Expected type mismatch panic, got %v", r)
+		}
+	}()
+	MustResolveAs[int](c, "name")
+}
+
+func TestPacketDeliveryFactoryRegister(t *testing.T) {
+	c := NewContainer()
+	f := NewPacketDeliveryFactory()
+	f.SwitchToSimulation()
+	f.Register(c)
+
+	delivery := MustResolveAs[interfaces.IPacketDelivery](c, ServicePacketDelivery)
+	if !delivery.IsSimulation() {
+		t.Error("Expected simulation delivery without a registered transport")
+	}
+
+	c = NewContainer()
+	f.SwitchToReal()
+	f.Register(c)
+	if _, err := c.Resolve(ServicePacketDelivery); err == nil {
+		t.Error("Expected error for real delivery without transport")
+	}
+
+	c.Register(ServiceNetworkTransport, func(c *Container) (interface{}, error) {
+		return newMockTransport(), nil
+	})
+	delivery = MustResolveAs[interfaces.IPacketDelivery](c, ServicePacketDelivery)
+	if delivery.IsSimulation() {
+		t.Error("Expected real delivery with a registered transport")
+	}
+}
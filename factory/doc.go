@@ -71,4 +71,19 @@
 //	factory := NewPacketDeliveryFactory()
 //	factory.SwitchToSimulation()  // Switch to simulation mode
 //	factory.SwitchToReal()        // Switch back to real mode
+//
+// # Dependency Injection
+//
+// Container is a small named-service registry for wiring components that
+// depend on IPacketDelivery, INetworkTransport and similar interfaces.
+// Providers resolve their own dependencies and results are cached as
+// singletons; a circular dependency panics with the cycle path:
+//
+//	c := NewContainer()
+//	c.Register(ServiceNetworkTransport, func(c *Container) (interface{}, error) {
+//	    return transport, nil
+//	})
+//	NewPacketDeliveryFactory().Register(c) // registers "packet_delivery"
+//
+//	delivery := MustResolveAs[interfaces.IPacketDelivery](c, ServicePacketDelivery)
 package factory
@@ -419,3 +419,24 @@ func (f *PacketDeliveryFactory) UpdateConfig(config *interfaces.PacketDeliveryCo
 
 	return nil
 }
+
+// Register installs the factory in c under ServicePacketDelivery. The
+// provider resolves ServiceNetworkTransport when it is registered and
+// otherwise passes a nil transport, which is valid in simulation mode.
+func (f *PacketDeliveryFactory) Register(c *Container) {
+	c.Register(ServicePacketDelivery, func(c *Container) (interface{}, error) {
+		var transport interfaces.INetworkTransport
+		if c.Has(ServiceNetworkTransport) {
+			resolved, err := c.Resolve(ServiceNetworkTransport)
+			if err != nil {
+				return nil, err
+			}
+			t, ok := resolved.(interfaces.INetworkTransport)
+			if !ok {
+				return nil, fmt.Errorf("%s is %T, not interfaces.INetworkTransport", ServiceNetworkTransport, resolved)
+			}
+			transport = t
+		}
+		return f.CreatePacketDelivery(transport)
+	})
+}
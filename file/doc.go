@@ -67,7 +67,7 @@
 //	// Handle incoming file requests
 //	manager.OnFileRequest(func(friendID, fileID uint32, fileName string, fileSize uint64) {
 //	    fmt.Printf("File request: %s (%d bytes)\n", fileName, fileSize)
-//	    manager.AcceptFile(friendID, fileID, savePath)
+//	    transfer, err := manager.AcceptFile(friendID, fileID, senderAddr)
 //	})
 //
 //	// Send a file
@@ -147,14 +147,75 @@
 //	fmt.Printf("Transferred: %d/%d bytes\n", stats.Transferred, stats.FileSize)
 //	fmt.Printf("Speed: %d bytes/sec\n", stats.Speed)
 //
+// # Flow Control
+//
+// Transfers started with SendFile use a sliding send window: at most
+// DefaultWindowSize (16) chunks may be unacknowledged, configurable with
+// SetWindowSize. FillWindow sends until the window is full and SendChunk
+// returns ErrWindowFull while it is. Each PacketFileDataAck carries the
+// sequence number of the last chunk received in order; it releases the
+// acknowledged chunks and the Manager sends the next ones. RetransmitExpired,
+// called from Tox.Iterate, resends chunks not acknowledged within
+// DefaultAckTimeout (see SetAckTimeout) and fails the transfer with
+// ErrAckTimeout after MaxRetries attempts. For outgoing transfers GetSpeed
+// reports acknowledged throughput.
+//
+//	manager.SetWindowSize(32)
+//	transfer, err := manager.SendFile(friendID, fileID, path, size, addr)
+//	err = transfer.Start()
+//	sent, err := manager.FillWindow(friendID, fileID, addr)
+//
+// // # Resuming Interrupted Transfers
+//
+// AcceptFile starts an incoming transfer and keeps a resume manifest next to
+// the destination file (the file name plus ResumeManifestSuffix). The
+// manifest records the file ID, name, size, the sender's public key (see
+// SetFriendKeyLookup) and a bitmap of received chunks, and is rewritten
+// atomically after every WriteChunk. It is kept when a transfer fails and
+// removed when it completes or is cancelled.
+//
+// When the sender repeats the file request after a disconnect, AcceptFile
+// finds the matching manifest, reopens the partial file and sends a
+// FileControlResume packet with the first missing offset. The sender's
+// Manager applies it with Transfer.ResumeFrom and requests the chunk at
+// that offset, so the chunks already delivered are skipped:
+//
+//	manager.SetFriendKeyLookup(func(friendID uint32) ([32]byte, error) {
+//	    return tox.GetFriendPublicKey(friendID)
+//	})
+//	transfer, err := manager.AcceptFile(friendID, fileID, senderAddr)
+//	fmt.Printf("Continuing at byte %d\n", transfer.GetTransferred())
+//
+// # Integrity Verification
+//
+// SendFile hashes the source file with SHA-256 and appends the digest to the
+// PacketFileRequest; Transfer.ReadChunk computes it lazily for transfers
+// created without SendFile. Receivers pass it to Transfer.SetExpectedHash and
+// accumulate a digest as chunks are written. When the last chunk arrives the
+// digests are compared in constant time; a mismatch deletes the partial file
+// and fails the transfer with ErrIntegrityCheckFailed. Requests without a
+// hash, as sent by older peers, are not verified.
+//
+// # Progress Webhooks
+//
+// Headless deployments can receive progress without polling. SetProgressWebhook
+// makes the Manager POST a signed JSON WebhookPayload each time a transfer
+// crosses another interval, plus a final "completed" or "cancelled" event:
+//
+//	err := manager.SetProgressWebhook("https://bot.example/tox", secret, 10)
+//
+// Receivers verify the X-Tox-Signature header by comparing it with
+// SignWebhookPayload(secret, body). Delivery failures are logged and never
+// affect the transfer.
+//
 // # Packet Types
 //
 // File transfer uses dedicated packet types registered in transport layer:
 //
 //   - PacketFileRequest: Initiates file transfer negotiation
-//   - PacketFileControl: Pause, resume, cancel commands
+//   - PacketFileControl: Pause, resume, cancel and resume-from-offset commands
 //   - PacketFileData: File chunk payload
-//   - PacketFileDataAck: Chunk acknowledgment for flow control
+//   - PacketFileDataAck: Chunk acknowledgment carrying the chunk sequence number
 //
 // # Thread Safety
 //
@@ -171,7 +232,7 @@
 //   - ✅ Transport integration via Manager.NewManager(transport)
 //   - ✅ AddressResolver for friend ID resolution
 //   - ✅ Integrated into main Tox struct via toxcore_file.go
-//   - ⚠️ No persistence/serialization for active transfers
+//   - ✅ Resume manifests for interrupted incoming transfers
 //
 // # Example: Complete File Transfer
 //
@@ -185,7 +246,7 @@
 //	// Receiver side
 //	manager := file.NewManager(transport)
 //	manager.OnFileRequest(func(friendID, fileID uint32, name string, size uint64) {
-//	    manager.AcceptFile(friendID, fileID, "/downloads/"+name)
+//	    manager.AcceptFile(friendID, fileID, senderAddr)
 //	})
 //
 // # Error Handling
@@ -193,8 +254,9 @@
 // The package provides sentinel errors for common failure modes:
 //
 //	var (
-//	    ErrDirectoryTraversal  // Path contains directory traversal attempt
-//	    ErrChunkTooLarge       // Chunk exceeds MaxChunkSize
+//	    ErrDirectoryTraversal   // Path contains directory traversal attempt
+//	    ErrChunkTooLarge        // Chunk exceeds MaxChunkSize
+//	    ErrIntegrityCheckFailed // Completed file does not match its hash
 //	)
 //
 // All errors are wrapped with context for debugging.
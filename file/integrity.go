@@ -0,0 +1,177 @@
+package file
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/sirupsen/logrus"
+)
+
+// FileHashSize is the size of the SHA-256 file hash carried by file requests.
+const FileHashSize = sha256.Size
+
+// ErrIntegrityCheckFailed indicates a completed file does not match the hash
+// announced in its file request.
+var ErrIntegrityCheckFailed = errors.New("file integrity check failed")
+
+// serializeFileRequestWithHash creates a file request packet payload carrying
+// the SHA-256 hash of the complete file. A nil hash produces the legacy
+// format understood by every peer.
+// Format: [file_id (4 bytes)][file_size (8 bytes)][name_len (2 bytes)][file_name][hash (32 bytes, optional)]
+func serializeFileRequestWithHash(fileID uint32, fileName string, fileSize uint64, hash []byte) []byte {
+	nameBytes := []byte(fileName)
+	data := make([]byte, 4+8+2+len(nameBytes)+len(hash))
+
+	binary.BigEndian.PutUint32(data[0:4], fileID)
+	binary.BigEndian.PutUint64(data[4:12], fileSize)
+	binary.BigEndian.PutUint16(data[12:14], uint16(len(nameBytes)))
+	copy(data[14:], nameBytes)
+	copy(data[14+len(nameBytes):], hash)
+
+	return data
+}
+
+// fileRequestHash returns the optional file hash following the name in a
+// file request payload, or nil for a legacy request.
+func fileRequestHash(data []byte) ([]byte, error) {
+	if len(data) < 14 {
+		return nil, errors.New("file request packet too short")
+	}
+	end := 14 + int(binary.BigEndian.Uint16(data[12:14]))
+	switch len(data) - end {
+	case 0:
+		return nil, nil
+	case FileHashSize:
+		hash := make([]byte, FileHashSize)
+		copy(hash, data[end:])
+		return hash, nil
+	default:
+		return nil, errors.New("file request hash has invalid length")
+	}
+}
+
+// SetExpectedHash sets the SHA-256 hash an incoming transfer must match when
+// it completes. A nil hash disables verification. It must be called before
+// the transfer is started.
+//
+//export ToxFileTransferSetExpectedHash
+func (t *Transfer) SetExpectedHash(hash []byte) error {
+	if hash != nil && len(hash) != FileHashSize {
+		return fmt.Errorf("expected hash must be %d bytes, got %d", FileHashSize, len(hash))
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.Direction != TransferDirectionIncoming {
+		return errors.New("expected hash only applies to incoming transfers")
+	}
+	if t.State != TransferStatePending {
+		return errors.New("expected hash must be set before the transfer starts")
+	}
+	t.expectedHash = hash
+	t.hasher = nil
+	if hash != nil {
+		t.hasher = sha256.New()
+	}
+	return nil
+}
+
+// ExpectedHash returns the hash an incoming transfer is verified against,
+// or nil when it is not verified.
+func (t *Transfer) ExpectedHash() []byte {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.expectedHash
+}
+
+// SourceHash returns the SHA-256 hash of an outgoing transfer's source file.
+// The file is hashed on first use and the result cached.
+//
+//export ToxFileTransferSourceHash
+func (t *Transfer) SourceHash() ([]byte, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.sourceHashLocked()
+}
+
+// sourceHashLocked computes the source hash if needed. It reads the file
+// through its own handle so the transfer position is unaffected. Caller must
+// hold t.mu.
+func (t *Transfer) sourceHashLocked() ([]byte, error) {
+	if t.Direction != TransferDirectionOutgoing {
+		return nil, errors.New("source hash only applies to outgoing transfers")
+	}
+	if t.sourceHash != nil {
+		return t.sourceHash, nil
+	}
+
+	f, err := os.Open(t.FileName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file for hashing: %w", err)
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return nil, fmt.Errorf("failed to hash file: %w", err)
+	}
+	t.sourceHash = h.Sum(nil)
+	return t.sourceHash, nil
+}
+
+// seedHasherLocked feeds the first n bytes already on disk into the hasher,
+// so a resumed transfer is verified over the whole file. Caller must hold
+// t.mu.
+func (t *Transfer) seedHasherLocked(n uint64) error {
+	if t.hasher == nil || n == 0 {
+		return nil
+	}
+	f, err := os.Open(t.FileName)
+	if err != nil {
+		return fmt.Errorf("failed to open partial file for hashing: %w", err)
+	}
+	defer f.Close()
+	if _, err := io.CopyN(t.hasher, f, int64(n)); err != nil {
+		return fmt.Errorf("failed to hash partial file: %w", err)
+	}
+	return nil
+}
+
+// verifyIntegrityLocked compares the accumulated digest with the expected
+// hash. On a mismatch the partial file and its resume manifest are deleted.
+// Caller must hold t.mu.
+func (t *Transfer) verifyIntegrityLocked() error {
+	if t.hasher == nil {
+		return nil
+	}
+	if subtle.ConstantTimeCompare(t.hasher.Sum(nil), t.expectedHash) == 1 {
+		return nil
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"function":  "verifyIntegrity",
+		"friend_id": t.FriendID,
+		"file_id":   t.FileID,
+		"file_name": t.FileName,
+	}).Error("Received file does not match its announced hash")
+
+	if t.FileHandle != nil {
+		t.FileHandle.Close()
+		t.FileHandle = nil
+	}
+	if err := os.Remove(t.FileName); err != nil && !os.IsNotExist(err) {
+		logrus.WithFields(logrus.Fields{
+			"function":  "verifyIntegrity",
+			"friend_id": t.FriendID,
+			"file_id":   t.FileID,
+			"error":     err.Error(),
+		}).Warn("Failed to delete corrupted file")
+	}
+	t.removeManifestLocked()
+	return ErrIntegrityCheckFailed
+}
@@ -0,0 +1,106 @@
+package file
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/opd-ai/toxcore/transport"
+)
+
+// startHashedTransfer hands a file request carrying hash to a receiver and
+// starts the resulting incoming transfer.
+func startHashedTransfer(t *testing.T, size uint64, hash []byte) *Transfer {
+	t.Helper()
+	trans := newMockTransport()
+	receiver := NewManager(trans)
+	addr := &mockAddr{network: "udp", address: testPeerAddr}
+	trans.simulateReceive(transport.PacketFileRequest, serializeFileRequestWithHash(5, "data.bin", size, hash), addr)
+
+	transfer, err := receiver.GetTransfer(5, 5)
+	if err != nil {
+		t.Fatalf("file request not handled: %v", err)
+	}
+	if !bytes.Equal(transfer.ExpectedHash(), hash) {
+		t.Fatalf("expected hash %x, got %x", hash, transfer.ExpectedHash())
+	}
+	if err := transfer.Start(); err != nil {
+		t.Fatal(err)
+	}
+	return transfer
+}
+
+func TestIntegrityVerifiedTransfer(t *testing.T) {
+	t.Chdir(t.TempDir())
+	data := bytes.Repeat([]byte("integrity"), 300)
+	sum := sha256.Sum256(data)
+	transfer := startHashedTransfer(t, uint64(len(data)), sum[:])
+
+	for offset := 0; offset < len(data); offset += ChunkSize {
+		end := min(offset+ChunkSize, len(data))
+		if err := transfer.WriteChunk(data[offset:end]); err != nil {
+			t.Fatalf("WriteChunk failed: %v", err)
+		}
+	}
+	if transfer.GetState() != TransferStateCompleted {
+		t.Fatalf("expected completed transfer, got %v", transfer.GetState())
+	}
+	if got, err := os.ReadFile("data.bin"); err != nil || !bytes.Equal(got, data) {
+		t.Errorf("expected the verified file on disk, got err %v", err)
+	}
+}
+
+func TestIntegrityMismatchDeletesFile(t *testing.T) {
+	t.Chdir(t.TempDir())
+	data := []byte("tampered in transit")
+	sum := sha256.Sum256([]byte("original contents!!"))
+	transfer := startHashedTransfer(t, uint64(len(data)), sum[:])
+
+	var completeErr error
+	transfer.OnComplete(func(err error) { completeErr = err })
+	if err := transfer.WriteChunk(data); !errors.Is(err, ErrIntegrityCheckFailed) {
+		t.Fatalf("expected ErrIntegrityCheckFailed, got %v", err)
+	}
+	if transfer.GetState() != TransferStateError || !errors.Is(completeErr, ErrIntegrityCheckFailed) {
+		t.Errorf("expected error state and callback, got %v %v", transfer.GetState(), completeErr)
+	}
+	if _, err := os.Stat("data.bin"); !os.IsNotExist(err) {
+		t.Errorf("expected the corrupted file to be deleted, got %v", err)
+	}
+}
+
+func TestIntegrityLegacyRequest(t *testing.T) {
+	t.Chdir(t.TempDir())
+	transfer := startHashedTransfer(t, 4, nil)
+	if err := transfer.WriteChunk([]byte("data")); err != nil {
+		t.Fatalf("WriteChunk failed: %v", err)
+	}
+	if transfer.GetState() != TransferStateCompleted {
+		t.Errorf("expected an unverified transfer to complete, got %v", transfer.GetState())
+	}
+}
+
+func TestSendFileCarriesSourceHash(t *testing.T) {
+	data := []byte("hash me on the way out")
+	source := filepath.Join(t.TempDir(), "out.bin")
+	if err := os.WriteFile(source, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	trans := newMockTransport()
+	sender := NewManager(trans)
+	if _, err := sender.SendFile(1, 1, source, uint64(len(data)), &mockAddr{network: "udp", address: testPeerAddr}); err != nil {
+		t.Fatalf("SendFile failed: %v", err)
+	}
+
+	hash, err := fileRequestHash(trans.getLastPacket().packet.Data)
+	sum := sha256.Sum256(data)
+	if err != nil || !bytes.Equal(hash, sum[:]) {
+		t.Errorf("expected request hash %x, got %x (%v)", sum, hash, err)
+	}
+	if _, err := fileRequestHash(append(serializeFileRequest(1, "a", 1), 1, 2, 3)); err == nil {
+		t.Error("expected an error for a truncated hash")
+	}
+}
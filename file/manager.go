@@ -8,6 +8,7 @@ import (
 	"net"
 	"path/filepath"
 	"sync"
+	"time"
 
 	"github.com/opd-ai/toxcore/transport"
 	"github.com/sirupsen/logrus"
@@ -53,6 +54,9 @@ type Manager struct {
 	transfers           map[transferKey]*Transfer
 	addressResolver     AddressResolver
 	friendAddressLookup FriendAddressLookup
+	friendKeyLookup     FriendKeyLookup
+	windowSize          int
+	ackTimeout          time.Duration
 	mu                  sync.RWMutex
 
 	// Callbacks for notifying upper layers about file events
@@ -60,6 +64,9 @@ type Manager struct {
 	fileRecvCallback         FileRecvCallback
 	fileRecvChunkCallback    FileRecvChunkCallback
 	fileChunkRequestCallback FileChunkRequestCallback
+
+	webhookMu sync.Mutex
+	webhook   *progressWebhook
 }
 
 // transferKey uniquely identifies a file transfer.
@@ -78,6 +85,8 @@ func NewManager(t transport.Transport) *Manager {
 		transport:       t,
 		transfers:       make(map[transferKey]*Transfer),
 		addressResolver: nil, // Must be set via SetAddressResolver for proper friend ID resolution
+		windowSize:      DefaultWindowSize,
+		ackTimeout:      DefaultAckTimeout,
 	}
 
 	// Register packet handlers for file transfer
@@ -217,6 +226,19 @@ func (m *Manager) SendFile(friendID, fileID uint32, fileName string, fileSize ui
 		return nil, ErrFileNameTooLong
 	}
 
+	transfer := NewTransfer(friendID, fileID, fileName, fileSize, TransferDirectionOutgoing)
+	// Hash outside the lock; an unreadable file just falls back to an
+	// unverified request and fails later when the transfer starts.
+	fileHash, err := transfer.SourceHash()
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"function":  "SendFile",
+			"file_name": fileName,
+			"error":     err.Error(),
+		}).Warn("Sending file request without integrity hash")
+		fileHash = nil
+	}
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -224,15 +246,14 @@ func (m *Manager) SendFile(friendID, fileID uint32, fileName string, fileSize ui
 	if _, exists := m.transfers[key]; exists {
 		return nil, fmt.Errorf("transfer already exists for friend %d file %d", friendID, fileID)
 	}
-
-	transfer := NewTransfer(friendID, fileID, fileName, fileSize, TransferDirectionOutgoing)
+	transfer.window = newSendWindow(m.windowSize, m.ackTimeout, addr)
 	m.transfers[key] = transfer
 
 	// Send file request packet
 	if m.transport != nil {
 		packet := &transport.Packet{
 			PacketType: transport.PacketFileRequest,
-			Data:       serializeFileRequest(fileID, fileName, fileSize),
+			Data:       serializeFileRequestWithHash(fileID, fileName, fileSize, fileHash),
 		}
 		if err := m.transport.Send(packet, addr); err != nil {
 			delete(m.transfers, key)
@@ -282,6 +303,7 @@ func (m *Manager) CancelTransfersForFriend(friendID uint32) int {
 	}
 	for _, key := range keysToDelete {
 		delete(m.transfers, key)
+		m.forgetWebhookTransfer(key)
 	}
 	if cancelledCount > 0 {
 		logrus.WithFields(logrus.Fields{"function": "CancelTransfersForFriend", "friend_id": friendID, "cancelled_count": cancelledCount}).Info("Cancelled file transfers for deleted friend")
@@ -299,19 +321,31 @@ func (m *Manager) cancelTransferForFriend(friendID uint32, key transferKey, tran
 			"error":     err.Error(),
 		}).Warn("Failed to cancel transfer (may already be completed)")
 	}
+	m.notifyWebhookProgress(key, transfer)
 }
 
 // SendChunk sends the next chunk of data for an outgoing transfer.
+// Transfers started by SendFile return ErrWindowFull while their send
+// window has no room for another unacknowledged chunk.
 func (m *Manager) SendChunk(friendID, fileID uint32, addr net.Addr) error {
 	transfer, err := m.GetTransfer(friendID, fileID)
 	if err != nil {
 		return err
 	}
+	transfer.mu.Lock()
+	window := transfer.window
+	transfer.mu.Unlock()
+	if window != nil && window.full() {
+		return ErrWindowFull
+	}
 
 	// Snapshot position before reading so the receiver can write to the correct offset.
+	key := transferKey{friendID: friendID, fileID: fileID}
 	position := transfer.GetTransferred()
 	chunk, err := transfer.ReadChunk(ChunkSize)
 	if err != nil {
+		// Reading past the end completes the transfer
+		m.notifyWebhookProgress(key, transfer)
 		return fmt.Errorf("failed to read chunk: %w", err)
 	}
 
@@ -326,7 +360,11 @@ func (m *Manager) SendChunk(friendID, fileID uint32, addr net.Addr) error {
 			return fmt.Errorf("failed to send file data: %w", err)
 		}
 	}
+	if window != nil {
+		window.push(position, chunk, transfer.timeProvider.Now())
+	}
 
+	m.notifyWebhookProgress(key, transfer)
 	return nil
 }
 
@@ -340,8 +378,17 @@ func (m *Manager) handleFileRequest(packet *transport.Packet, addr net.Addr) err
 		return err
 	}
 
+	fileHash, err := fileRequestHash(packet.Data)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{"function": "handleFileRequest", "error": err.Error()}).Error("Failed to parse file request hash")
+		return err
+	}
+
 	friendID := m.resolveFriendIDFromAddr(addr, fileID, "handleFileRequest")
 	transfer := NewTransfer(friendID, fileID, fileName, fileSize, TransferDirectionIncoming)
+	if err := transfer.SetExpectedHash(fileHash); err != nil {
+		return err
+	}
 	m.storeIncomingTransfer(friendID, fileID, transfer)
 	logrus.WithFields(logrus.Fields{"function": "handleFileRequest", "friend_id": friendID, "file_id": fileID, "file_name": fileName, "file_size": fileSize}).Info("Incoming file transfer created")
 	m.notifyIncomingFileRequest(friendID, fileID, fileSize, fileName)
@@ -356,8 +403,13 @@ func (m *Manager) storeIncomingTransfer(friendID, fileID uint32, transfer *Trans
 	m.transfers[key] = transfer
 	m.mu.Unlock()
 	if oldTransfer != nil {
+		// A repeated request is how a sender retries after a disconnect, so
+		// keep the old transfer's resume manifest for AcceptFile to pick up.
+		oldTransfer.detachManifest()
 		m.logReplacedTransferCancel(friendID, fileID, oldTransfer)
+		m.notifyWebhookProgress(key, oldTransfer)
 	}
+	m.forgetWebhookTransfer(key)
 }
 
 // logReplacedTransferCancel logs best-effort cleanup when a request supersedes an existing transfer.
@@ -384,7 +436,8 @@ func (m *Manager) handleFileControl(packet *transport.Packet, addr net.Addr) err
 		"from":     addr.String(),
 	}).Debug("Handling file control packet")
 
-	// Control packet format: [file_id (4 bytes)][control_type (1 byte)]
+	// Control packet format: [file_id (4 bytes)][control_type (1 byte)],
+	// followed by [offset (8 bytes)] for FileControlResume.
 	if len(packet.Data) < 5 {
 		logrus.Error("File control packet too short")
 		return errors.New("file control packet too short")
@@ -413,7 +466,11 @@ func (m *Manager) handleFileControl(packet *transport.Packet, addr net.Addr) err
 	case 2: // Resume
 		return transfer.Resume()
 	case 3: // Cancel
-		return transfer.Cancel()
+		err := transfer.Cancel()
+		m.notifyWebhookProgress(transferKey{friendID: friendID, fileID: fileID}, transfer)
+		return err
+	case FileControlResume:
+		return m.resumeOutgoingTransfer(friendID, fileID, transfer, packet.Data)
 	default:
 		return fmt.Errorf("unknown control type: %d", controlType)
 	}
@@ -441,6 +498,11 @@ func (m *Manager) handleFileData(packet *transport.Packet, addr net.Addr) error
 	}
 
 	if err := m.validateChunkPosition(transfer, fileID, position); err != nil {
+		if expected := transfer.GetTransferred(); position < expected {
+			// A retransmitted chunk whose acknowledgment was lost; repeat the
+			// acknowledgment so the sender's window can advance.
+			_ = m.sendDataAck(addr, fileID, expected) //nolint:errcheck // best-effort re-acknowledgment
+		}
 		return err
 	}
 
@@ -449,6 +511,7 @@ func (m *Manager) handleFileData(packet *transport.Packet, addr net.Addr) error
 	}
 
 	m.invokeRecvChunkCallback(friendID, fileID, position, chunk)
+	m.notifyWebhookProgress(transferKey{friendID: friendID, fileID: fileID}, transfer)
 	return m.sendDataAck(addr, fileID, transfer.GetTransferred())
 }
 
@@ -517,7 +580,7 @@ func (m *Manager) sendDataAck(addr net.Addr, fileID uint32, transferred uint64)
 	}
 	ackPacket := &transport.Packet{
 		PacketType: transport.PacketFileDataAck,
-		Data:       serializeFileDataAck(fileID, transferred),
+		Data:       serializeFileDataAck(fileID, transferred, chunkSequence(transferred-1)),
 	}
 	if err := m.transport.Send(ackPacket, addr); err != nil {
 		return fmt.Errorf("failed to send acknowledgment: %w", err)
@@ -534,7 +597,8 @@ func (m *Manager) handleFileDataAck(packet *transport.Packet, addr net.Addr) err
 		"from":     addr.String(),
 	}).Debug("Handling file data acknowledgment")
 
-	// Ack packet format: [file_id (4 bytes)][bytes_received (8 bytes)]
+	// Ack packet format: [file_id (4 bytes)][bytes_received (8 bytes)][sequence (4 bytes)].
+	// Older peers omit the sequence number of the last chunk received.
 	if len(packet.Data) < 12 {
 		logrus.Error("File data ack packet too short")
 		return errors.New("file data ack packet too short")
@@ -542,6 +606,10 @@ func (m *Manager) handleFileDataAck(packet *transport.Packet, addr net.Addr) err
 
 	fileID := binary.BigEndian.Uint32(packet.Data[0:4])
 	bytesReceived := binary.BigEndian.Uint64(packet.Data[4:12])
+	sequence := chunkSequence(bytesReceived - 1)
+	if len(packet.Data) >= 16 {
+		sequence = binary.BigEndian.Uint32(packet.Data[12:16])
+	}
 
 	// Resolve friend ID from address using the configured resolver
 	friendID := m.resolveFriendIDFromAddr(addr, fileID, "handleFileDataAck")
@@ -560,6 +628,7 @@ func (m *Manager) handleFileDataAck(packet *transport.Packet, addr net.Addr) err
 
 	// Update flow control state
 	transfer.SetAcknowledgedBytes(bytesReceived)
+	m.advanceWindow(friendID, fileID, transfer, sequence)
 
 	logrus.WithFields(logrus.Fields{
 		"function":       "handleFileDataAck",
@@ -586,17 +655,9 @@ func (m *Manager) handleFileDataAck(packet *transport.Packet, addr net.Addr) err
 }
 
 // serializeFileRequest creates a file request packet payload.
+// Format: [file_id (4 bytes)][file_size (8 bytes)][name_len (2 bytes)][file_name]
 func serializeFileRequest(fileID uint32, fileName string, fileSize uint64) []byte {
-	// Format: [file_id (4 bytes)][file_size (8 bytes)][name_len (2 bytes)][file_name]
-	nameBytes := []byte(fileName)
-	data := make([]byte, 4+8+2+len(nameBytes))
-
-	binary.BigEndian.PutUint32(data[0:4], fileID)
-	binary.BigEndian.PutUint64(data[4:12], fileSize)
-	binary.BigEndian.PutUint16(data[12:14], uint16(len(nameBytes)))
-	copy(data[14:], nameBytes)
-
-	return data
+	return serializeFileRequestWithHash(fileID, fileName, fileSize, nil)
 }
 
 // deserializeFileRequest parses a file request packet payload.
@@ -664,10 +725,11 @@ func deserializeFileData(data []byte) (uint32, uint64, []byte, error) {
 }
 
 // serializeFileDataAck creates a file data acknowledgment packet payload.
-func serializeFileDataAck(fileID uint32, bytesReceived uint64) []byte {
-	// Format: [file_id (4 bytes)][bytes_received (8 bytes)]
-	data := make([]byte, 12)
+func serializeFileDataAck(fileID uint32, bytesReceived uint64, sequence uint32) []byte {
+	// Format: [file_id (4 bytes)][bytes_received (8 bytes)][sequence (4 bytes)]
+	data := make([]byte, 16)
 	binary.BigEndian.PutUint32(data[0:4], fileID)
 	binary.BigEndian.PutUint64(data[4:12], bytesReceived)
+	binary.BigEndian.PutUint32(data[12:16], sequence)
 	return data
 }
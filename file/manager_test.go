@@ -222,12 +222,12 @@ func TestSendChunk(t *testing.T) {
 }
 
 func TestHandleFileData(t *testing.T) {
+	t.Chdir(t.TempDir())
 	trans := newMockTransport()
 	manager := NewManager(trans)
 	addr := &mockAddr{network: "udp", address: testPeerAddr}
 
-	tmpDir := t.TempDir()
-	receiveFile := filepath.Join(tmpDir, "received.txt")
+	receiveFile := "received.txt"
 
 	// Create incoming transfer
 	requestData := serializeFileRequest(4, receiveFile, testFileSize1KB)
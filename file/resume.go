@@ -0,0 +1,410 @@
+package file
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+
+	"github.com/opd-ai/toxcore/transport"
+	"github.com/sirupsen/logrus"
+)
+
+// ResumeManifestSuffix is appended to an incoming transfer's destination path
+// to name the manifest that records which chunks have been received.
+const ResumeManifestSuffix = ".resume"
+
+// FileControlResume is the file control type asking the sender to continue
+// a transfer from a byte offset, after the receiver found a partial file.
+// Packet format: [file_id (4 bytes)][control_type (1 byte)][offset (8 bytes)].
+const FileControlResume byte = 4
+
+// ErrInvalidResumeOffset indicates a resume offset beyond the end of the file.
+var ErrInvalidResumeOffset = errors.New("invalid resume offset")
+
+// FriendKeyLookup resolves a friend's public key by friend ID. Resume
+// manifests record the key so a partial file is only resumed from the peer
+// that started it.
+type FriendKeyLookup func(friendID uint32) ([32]byte, error)
+
+// resumeManifest is the on-disk record of a partially received file. Bit i
+// of Bitmap is set once the ChunkSize bytes at offset i*ChunkSize (or the
+// final short chunk) have been written.
+type resumeManifest struct {
+	FileID        uint32 `json:"file_id"`
+	FileName      string `json:"file_name"`
+	FileSize      uint64 `json:"file_size"`
+	Bitmap        []byte `json:"bitmap"`
+	PeerPublicKey string `json:"peer_public_key"`
+}
+
+// newResumeManifest creates an empty manifest for the transfer.
+func newResumeManifest(fileID uint32, fileName string, fileSize uint64, peerKey [32]byte) *resumeManifest {
+	chunks := (fileSize + ChunkSize - 1) / ChunkSize
+	return &resumeManifest{
+		FileID:        fileID,
+		FileName:      fileName,
+		FileSize:      fileSize,
+		Bitmap:        make([]byte, (chunks+7)/8),
+		PeerPublicKey: hex.EncodeToString(peerKey[:]),
+	}
+}
+
+// matches reports whether the manifest describes the same file from the
+// same peer as the pending transfer.
+func (m *resumeManifest) matches(other *resumeManifest) bool {
+	return m.FileID == other.FileID && m.FileName == other.FileName &&
+		m.FileSize == other.FileSize && m.PeerPublicKey == other.PeerPublicKey &&
+		len(m.Bitmap) == len(other.Bitmap)
+}
+
+// markReceived sets the bits of every chunk fully covered by the first
+// transferred bytes.
+func (m *resumeManifest) markReceived(transferred uint64) {
+	complete := transferred / ChunkSize
+	if transferred == m.FileSize && transferred%ChunkSize != 0 {
+		complete++
+	}
+	for i := uint64(0); i < complete; i++ {
+		m.Bitmap[i/8] |= 1 << (i % 8)
+	}
+}
+
+// firstMissingOffset returns the offset of the first chunk not yet received.
+func (m *resumeManifest) firstMissingOffset() uint64 {
+	chunks := (m.FileSize + ChunkSize - 1) / ChunkSize
+	for i := uint64(0); i < chunks; i++ {
+		if m.Bitmap[i/8]&(1<<(i%8)) == 0 {
+			return i * ChunkSize
+		}
+	}
+	return m.FileSize
+}
+
+// resumeManifestPath returns the validated manifest path for a destination.
+func resumeManifestPath(fileName string) (string, error) {
+	return ValidatePath(fileName + ResumeManifestSuffix)
+}
+
+// loadResumeManifest reads a manifest. A missing file returns nil, nil.
+func loadResumeManifest(path string) (*resumeManifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read resume manifest: %w", err)
+	}
+	var m resumeManifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse resume manifest: %w", err)
+	}
+	return &m, nil
+}
+
+// saveResumeManifest writes the manifest through a temporary file and a
+// rename, so readers never see a partially written bitmap.
+func saveResumeManifest(path string, m *resumeManifest) error {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return fmt.Errorf("failed to serialize resume manifest: %w", err)
+	}
+	tmpFile := path + ".tmp"
+	if err := os.WriteFile(tmpFile, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write temporary resume manifest: %w", err)
+	}
+	if err := os.Rename(tmpFile, path); err != nil {
+		return fmt.Errorf("failed to rename resume manifest: %w", err)
+	}
+	return nil
+}
+
+// StartResumable starts an incoming transfer with a resume manifest next to
+// the destination file. If a manifest for the same file ID, name, size and
+// peer key already exists, the partial file is kept and writing continues at
+// the first missing chunk; otherwise the file is created afresh. It returns
+// the offset the sender should continue from.
+func (t *Transfer) StartResumable(peerKey [32]byte) (uint64, error) {
+	t.logStarting()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.Direction != TransferDirectionIncoming {
+		return 0, errors.New("only incoming transfers can be resumed")
+	}
+	if err := t.validateTransferState(); err != nil {
+		return 0, err
+	}
+	if err := t.validateAndSanitizePath(); err != nil {
+		return 0, err
+	}
+	path, err := resumeManifestPath(t.FileName)
+	if err != nil {
+		t.Error = err
+		t.State = TransferStateError
+		return 0, err
+	}
+
+	manifest := newResumeManifest(t.FileID, t.FileName, t.FileSize, peerKey)
+	offset, err := t.openResumedFile(path, manifest)
+	if err != nil {
+		return 0, err
+	}
+	if offset == 0 {
+		if err := t.openTransferFile(); err != nil {
+			return 0, err
+		}
+	}
+	err = t.seedHasherLocked(offset)
+	if err == nil {
+		err = saveResumeManifest(path, manifest)
+	}
+	if err != nil {
+		t.FileHandle.Close()
+		t.FileHandle = nil
+		t.Error = err
+		t.State = TransferStateError
+		return 0, err
+	}
+
+	t.manifestPath = path
+	t.manifest = manifest
+	t.Transferred = offset
+	t.finalizeTransferStart()
+	if err := t.checkTransferCompletion(); err != nil {
+		return 0, err
+	}
+	return offset, nil
+}
+
+// openResumedFile opens the partial destination file when an existing
+// manifest matches, copying its bitmap into manifest. It returns 0 without
+// opening anything when there is nothing to resume.
+func (t *Transfer) openResumedFile(path string, manifest *resumeManifest) (uint64, error) {
+	existing, err := loadResumeManifest(path)
+	if err != nil || existing == nil || !existing.matches(manifest) {
+		if err != nil {
+			logrus.WithFields(logrus.Fields{
+				"function":  "StartResumable",
+				"file_id":   t.FileID,
+				"file_name": t.FileName,
+				"error":     err.Error(),
+			}).Warn("Ignoring unreadable resume manifest")
+		}
+		return 0, nil
+	}
+	copy(manifest.Bitmap, existing.Bitmap)
+	offset := manifest.firstMissingOffset()
+	if offset == 0 {
+		return 0, nil
+	}
+
+	if fi, lerr := os.Lstat(t.FileName); lerr == nil && fi.Mode()&os.ModeSymlink != 0 {
+		return 0, fmt.Errorf("refusing to resume file %q: path is an existing symlink", t.FileName)
+	}
+	handle, err := os.OpenFile(t.FileName, os.O_WRONLY, 0o600)
+	if err != nil {
+		// The partial file is gone; start over.
+		clear(manifest.Bitmap)
+		return 0, nil
+	}
+	if _, err := handle.Seek(int64(offset), io.SeekStart); err != nil {
+		handle.Close()
+		t.Error = err
+		t.State = TransferStateError
+		return 0, err
+	}
+	t.FileHandle = handle
+
+	logrus.WithFields(logrus.Fields{
+		"function":  "StartResumable",
+		"friend_id": t.FriendID,
+		"file_id":   t.FileID,
+		"file_name": t.FileName,
+		"offset":    offset,
+	}).Info("Resuming partial file transfer")
+	return offset, nil
+}
+
+// recordManifestLocked marks the bytes transferred so far in the resume
+// manifest and saves it. Caller must hold t.mu.
+func (t *Transfer) recordManifestLocked() {
+	if t.manifest == nil {
+		return
+	}
+	t.manifest.markReceived(t.Transferred)
+	if err := saveResumeManifest(t.manifestPath, t.manifest); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"function":  "WriteChunk",
+			"friend_id": t.FriendID,
+			"file_id":   t.FileID,
+			"error":     err.Error(),
+		}).Warn("Failed to update resume manifest")
+	}
+}
+
+// removeManifestLocked deletes the resume manifest once the transfer can no
+// longer be resumed. Caller must hold t.mu.
+func (t *Transfer) removeManifestLocked() {
+	if t.manifest == nil {
+		return
+	}
+	if err := os.Remove(t.manifestPath); err != nil && !os.IsNotExist(err) {
+		logrus.WithFields(logrus.Fields{
+			"function":  "removeManifest",
+			"friend_id": t.FriendID,
+			"file_id":   t.FileID,
+			"error":     err.Error(),
+		}).Warn("Failed to remove resume manifest")
+	}
+	t.manifest = nil
+}
+
+// detachManifest stops the transfer from maintaining its resume manifest
+// without deleting it, so a replacement transfer can resume from it.
+func (t *Transfer) detachManifest() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.manifest = nil
+}
+
+// ResumeFrom continues an outgoing transfer at offset, skipping the bytes
+// the receiver already holds. It reopens the source file if a failure
+// closed it and moves the transfer back to running.
+//
+//export ToxFileTransferResumeFrom
+func (t *Transfer) ResumeFrom(offset uint64) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.Direction != TransferDirectionOutgoing {
+		return errors.New("cannot resume an incoming transfer from an offset")
+	}
+	if t.State == TransferStateCompleted || t.State == TransferStateCancelled {
+		return ErrTransferAlreadyFinished
+	}
+	if offset > t.FileSize {
+		return fmt.Errorf("%w: %d exceeds file size %d", ErrInvalidResumeOffset, offset, t.FileSize)
+	}
+
+	if t.FileHandle == nil {
+		if err := t.openTransferFile(); err != nil {
+			return err
+		}
+	}
+	if _, err := t.FileHandle.Seek(int64(offset), io.SeekStart); err != nil {
+		return fmt.Errorf("resume seek failed: %w", err)
+	}
+
+	t.Transferred = offset
+	t.acknowledged = offset
+	if t.window != nil {
+		t.window.reset()
+	}
+	t.Error = nil
+	if t.State != TransferStateRunning {
+		t.finalizeTransferStart()
+	}
+	t.lastChunkTime = t.timeProvider.Now()
+
+	logrus.WithFields(logrus.Fields{
+		"function":  "ResumeFrom",
+		"friend_id": t.FriendID,
+		"file_id":   t.FileID,
+		"offset":    offset,
+	}).Info("Outgoing transfer resumed at offset")
+	return nil
+}
+
+// SetFriendKeyLookup sets the lookup function used to resolve a friend's
+// public key for resume manifests. Without it, manifests record a zero key.
+func (m *Manager) SetFriendKeyLookup(lookup FriendKeyLookup) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.friendKeyLookup = lookup
+	logrus.WithFields(logrus.Fields{
+		"function":   "SetFriendKeyLookup",
+		"lookup_set": lookup != nil,
+	}).Info("Friend key lookup configured")
+}
+
+// AcceptFile starts a pending incoming transfer with a resume manifest. If
+// a manifest left by an interrupted transfer of the same file from the same
+// peer exists, the partial file is kept and a FileControlResume packet
+// carrying the first missing offset is sent to addr, so the sender skips
+// the chunks already received.
+func (m *Manager) AcceptFile(friendID, fileID uint32, addr net.Addr) (*Transfer, error) {
+	transfer, err := m.GetTransfer(friendID, fileID)
+	if err != nil {
+		return nil, err
+	}
+	if transfer.Direction != TransferDirectionIncoming {
+		return nil, errors.New("cannot accept an outgoing transfer")
+	}
+
+	var peerKey [32]byte
+	m.mu.RLock()
+	lookup := m.friendKeyLookup
+	m.mu.RUnlock()
+	if lookup != nil {
+		if peerKey, err = lookup(friendID); err != nil {
+			return nil, fmt.Errorf("failed to resolve friend key: %w", err)
+		}
+	}
+
+	offset, err := transfer.StartResumable(peerKey)
+	if err != nil {
+		return nil, err
+	}
+	if offset > 0 {
+		if err := m.sendResumeControl(addr, fileID, offset); err != nil {
+			return transfer, err
+		}
+	}
+	return transfer, nil
+}
+
+// sendResumeControl sends a FileControlResume packet for fileID.
+func (m *Manager) sendResumeControl(addr net.Addr, fileID uint32, offset uint64) error {
+	if m.transport == nil {
+		return nil
+	}
+	data := make([]byte, 13)
+	binary.BigEndian.PutUint32(data[0:4], fileID)
+	data[4] = FileControlResume
+	binary.BigEndian.PutUint64(data[5:13], offset)
+	packet := &transport.Packet{
+		PacketType: transport.PacketFileControl,
+		Data:       data,
+	}
+	if err := m.transport.Send(packet, addr); err != nil {
+		return fmt.Errorf("failed to send resume control: %w", err)
+	}
+	return nil
+}
+
+// resumeOutgoingTransfer applies a FileControlResume packet to an outgoing
+// transfer and asks the upper layer for the chunk at the resume offset.
+func (m *Manager) resumeOutgoingTransfer(friendID, fileID uint32, transfer *Transfer, data []byte) error {
+	if len(data) < 13 {
+		return errors.New("file resume control packet too short")
+	}
+	offset := binary.BigEndian.Uint64(data[5:13])
+	if err := transfer.ResumeFrom(offset); err != nil {
+		return err
+	}
+
+	if offset < transfer.FileSize {
+		m.callbackMu.RLock()
+		callback := m.fileChunkRequestCallback
+		m.callbackMu.RUnlock()
+		if callback != nil {
+			callback(friendID, fileID, offset, ChunkSize)
+		}
+	}
+	return nil
+}
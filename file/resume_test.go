@@ -0,0 +1,169 @@
+package file
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/opd-ai/toxcore/transport"
+)
+
+// deliverChunks sends n chunks from sender to receiver.
+func deliverChunks(t *testing.T, sender *Manager, senderTrans, receiverTrans *mockTransport, n int) {
+	t.Helper()
+	senderAddr := &mockAddr{network: "udp", address: testPeerAddr}
+	receiverAddr := &mockAddr{network: "udp", address: testPeerAddr2}
+	for i := 0; i < n; i++ {
+		senderTrans.clearPackets()
+		if err := sender.SendChunk(5, 5, receiverAddr); err != nil {
+			t.Fatalf("SendChunk %d failed: %v", i, err)
+		}
+		data := senderTrans.getLastPacket()
+		receiverTrans.simulateReceive(data.packet.PacketType, data.packet.Data, senderAddr)
+	}
+}
+
+func TestAcceptFileResumesAfterDisconnect(t *testing.T) {
+	senderTrans := newMockTransport()
+	receiverTrans := newMockTransport()
+	sender := NewManager(senderTrans)
+	receiver := NewManager(receiverTrans)
+	peerKey := [32]byte{1, 2, 3}
+	receiver.SetFriendKeyLookup(func(uint32) ([32]byte, error) { return peerKey, nil })
+
+	senderAddr := &mockAddr{network: "udp", address: testPeerAddr}
+	receiverAddr := &mockAddr{network: "udp", address: testPeerAddr2}
+
+	sourceData := bytes.Repeat([]byte("0123456789abcdef"), 5*ChunkSize/16+10)
+	sourceFile := filepath.Join(t.TempDir(), "source.bin")
+	if err := os.WriteFile(sourceFile, sourceData, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(t.TempDir())
+
+	outgoing, err := sender.SendFile(5, 5, sourceFile, uint64(len(sourceData)), receiverAddr)
+	if err != nil {
+		t.Fatalf("SendFile failed: %v", err)
+	}
+	request := senderTrans.getLastPacket().packet
+	receiverTrans.simulateReceive(request.PacketType, request.Data, senderAddr)
+	if err := outgoing.Start(); err != nil {
+		t.Fatal(err)
+	}
+	incoming, err := receiver.AcceptFile(5, 5, senderAddr)
+	if err != nil {
+		t.Fatalf("AcceptFile failed: %v", err)
+	}
+	manifestPath := "source.bin" + ResumeManifestSuffix
+	if _, err := os.Stat(manifestPath); err != nil {
+		t.Fatalf("expected a resume manifest: %v", err)
+	}
+
+	// Three chunks arrive, then the connection drops.
+	deliverChunks(t, sender, senderTrans, receiverTrans, 3)
+	tp := newMockTimeProvider()
+	incoming.SetTimeProvider(tp)
+	tp.advance(2 * DefaultStallTimeout)
+	if err := incoming.CheckTimeout(); !errors.Is(err, ErrTransferStalled) {
+		t.Fatalf("expected a stalled transfer, got %v", err)
+	}
+	if _, err := os.Stat(manifestPath); err != nil {
+		t.Fatalf("expected the manifest to survive the failure: %v", err)
+	}
+
+	// The sender retries the request and the receiver accepts it again.
+	receiverTrans.clearPackets()
+	receiverTrans.simulateReceive(request.PacketType, request.Data, senderAddr)
+	resumed, err := receiver.AcceptFile(5, 5, senderAddr)
+	if err != nil {
+		t.Fatalf("AcceptFile after disconnect failed: %v", err)
+	}
+	if got := resumed.GetTransferred(); got != 3*ChunkSize {
+		t.Errorf("expected receiver to resume at %d, got %d", 3*ChunkSize, got)
+	}
+
+	control := receiverTrans.getLastPacket()
+	if control == nil || control.packet.PacketType != transport.PacketFileControl {
+		t.Fatal("expected a file control packet")
+	}
+	if control.packet.Data[4] != FileControlResume {
+		t.Fatalf("expected FileControlResume, got %d", control.packet.Data[4])
+	}
+	if offset := binary.BigEndian.Uint64(control.packet.Data[5:13]); offset != 3*ChunkSize {
+		t.Errorf("expected resume offset %d, got %d", 3*ChunkSize, offset)
+	}
+
+	var requested uint64
+	sender.SetFileChunkRequestCallback(func(_, _ uint32, position uint64, _ int) { requested = position })
+	senderTrans.simulateReceive(control.packet.PacketType, control.packet.Data, receiverAddr)
+	if requested != 3*ChunkSize || outgoing.GetTransferred() != 3*ChunkSize {
+		t.Errorf("expected sender to skip to %d, requested %d transferred %d", 3*ChunkSize, requested, outgoing.GetTransferred())
+	}
+
+	deliverChunks(t, sender, senderTrans, receiverTrans, 3)
+	if resumed.GetState() != TransferStateCompleted {
+		t.Fatalf("expected completed transfer, got %v", resumed.GetState())
+	}
+	received, err := os.ReadFile("source.bin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(received, sourceData) {
+		t.Error("resumed file does not match the source")
+	}
+	if _, err := os.Stat(manifestPath); !os.IsNotExist(err) {
+		t.Errorf("expected manifest removed after completion, got %v", err)
+	}
+}
+
+func TestAcceptFileIgnoresForeignManifest(t *testing.T) {
+	t.Chdir(t.TempDir())
+	m := newResumeManifest(5, "data.bin", 3*ChunkSize, [32]byte{9})
+	m.markReceived(2 * ChunkSize)
+	if err := os.WriteFile("data.bin", make([]byte, 2*ChunkSize), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := saveResumeManifest("data.bin"+ResumeManifestSuffix, m); err != nil {
+		t.Fatal(err)
+	}
+
+	trans := newMockTransport()
+	receiver := NewManager(trans)
+	receiver.SetFriendKeyLookup(func(uint32) ([32]byte, error) { return [32]byte{1}, nil })
+	addr := &mockAddr{network: "udp", address: testPeerAddr}
+	trans.simulateReceive(transport.PacketFileRequest, serializeFileRequest(5, "data.bin", 3*ChunkSize), addr)
+
+	transfer, err := receiver.AcceptFile(5, 5, addr)
+	if err != nil {
+		t.Fatalf("AcceptFile failed: %v", err)
+	}
+	if transfer.GetTransferred() != 0 || len(trans.packets) != 0 {
+		t.Errorf("expected a fresh start for a manifest from another peer")
+	}
+	if fi, err := os.Stat("data.bin"); err != nil || fi.Size() != 0 {
+		t.Errorf("expected the partial file to be truncated, got %v %v", fi, err)
+	}
+}
+
+func TestResumeManifestOffsets(t *testing.T) {
+	m := newResumeManifest(1, "f", 2*ChunkSize+10, [32]byte{})
+	if got := m.firstMissingOffset(); got != 0 {
+		t.Errorf("expected offset 0, got %d", got)
+	}
+	m.markReceived(ChunkSize + 100)
+	if got := m.firstMissingOffset(); got != ChunkSize {
+		t.Errorf("a partial chunk must be re-sent: expected %d, got %d", ChunkSize, got)
+	}
+	m.markReceived(m.FileSize)
+	if got := m.firstMissingOffset(); got != m.FileSize {
+		t.Errorf("expected offset %d for a complete file, got %d", m.FileSize, got)
+	}
+
+	outgoing := NewTransfer(1, 1, "f", 10, TransferDirectionOutgoing)
+	if err := outgoing.ResumeFrom(11); !errors.Is(err, ErrInvalidResumeOffset) {
+		t.Errorf("expected ErrInvalidResumeOffset, got %v", err)
+	}
+}
@@ -15,6 +15,7 @@ package file
 import (
 	"errors"
 	"fmt"
+	"hash"
 	"io"
 	"os"
 	"path/filepath"
@@ -143,6 +144,13 @@ type Transfer struct {
 	timeProvider  TimeProvider
 	acknowledged  uint64 // bytes acknowledged by peer (for flow control)
 	ackCallback   func(uint64)
+	manifestPath  string          // resume manifest, set by StartResumable
+	manifest      *resumeManifest // nil when the transfer is not resumable
+	expectedHash  []byte          // SHA-256 announced by the sender, nil if unverified
+	hasher        hash.Hash       // digest of received bytes, nil if unverified
+	sourceHash    []byte          // cached SHA-256 of an outgoing source file
+	window        *sendWindow     // unacknowledged chunks, set by Manager.SendFile
+	lastAckTime   time.Time       // when the peer last acknowledged new bytes
 }
 
 // NewTransfer creates a new file transfer.
@@ -476,6 +484,7 @@ func (t *Transfer) Cancel() error {
 	}
 
 	t.State = TransferStateCancelled
+	t.removeManifestLocked()
 
 	if t.completeCallback != nil {
 		t.completeCallback(errors.New("transfer cancelled"))
@@ -517,11 +526,13 @@ func (t *Transfer) WriteChunk(data []byte) error {
 	if err := t.writeDataToFile(data); err != nil {
 		return err
 	}
+	if t.hasher != nil {
+		t.hasher.Write(data)
+	}
 
 	t.updateWriteProgress(data)
-	t.checkTransferCompletion()
-
-	return nil
+	t.recordManifestLocked()
+	return t.checkTransferCompletion()
 }
 
 // validateWriteRequest checks if the transfer is in a valid state for writing.
@@ -562,9 +573,15 @@ func (t *Transfer) updateWriteProgress(data []byte) {
 }
 
 // recordTransferredBytes updates progress counters and notifies listeners.
+// Outgoing transfers measure speed from acknowledgments instead, see
+// SetAcknowledgedBytes.
 func (t *Transfer) recordTransferredBytes(bytesTransferred uint64) {
 	t.Transferred += bytesTransferred
-	t.updateTransferSpeed(bytesTransferred)
+	if t.Direction == TransferDirectionIncoming {
+		t.updateTransferSpeed(bytesTransferred)
+	} else {
+		t.lastChunkTime = t.timeProvider.Now()
+	}
 	transferred := t.Transferred
 	cb := t.progressCallback
 	// Release the lock before invoking the callback so that a callback calling
@@ -577,10 +594,15 @@ func (t *Transfer) recordTransferredBytes(bytesTransferred uint64) {
 }
 
 // checkTransferCompletion checks if the transfer is complete and triggers completion if needed.
-func (t *Transfer) checkTransferCompletion() {
-	if t.State == TransferStateRunning && t.Transferred >= t.FileSize {
-		t.completeLocked(nil)
+// Incoming transfers with an expected hash are verified first; a mismatch
+// fails the transfer with ErrIntegrityCheckFailed.
+func (t *Transfer) checkTransferCompletion() error {
+	if t.State != TransferStateRunning || t.Transferred < t.FileSize {
+		return nil
 	}
+	err := t.verifyIntegrityLocked()
+	t.completeLocked(err)
+	return err
 }
 
 // ReadChunk reads the next chunk from an outgoing file transfer.
@@ -603,6 +625,10 @@ func (t *Transfer) ReadChunk(size uint16) ([]byte, error) {
 	if err := t.validateReadRequest(); err != nil {
 		return nil, err
 	}
+	if _, err := t.sourceHashLocked(); err != nil {
+		t.failTransferLocked(err)
+		return nil, err
+	}
 
 	chunk, n, err := t.readFileChunk(size)
 	if err != nil {
@@ -657,7 +683,8 @@ func (t *Transfer) failTransferLocked(err error) {
 
 // handleEOF processes end-of-file conditions and determines if transfer is complete.
 func (t *Transfer) handleEOF(chunk []byte, n int) ([]byte, error) {
-	if t.Transferred+uint64(n) >= t.FileSize {
+	// Windowed transfers complete once the last chunk is acknowledged.
+	if t.window == nil && t.Transferred+uint64(n) >= t.FileSize {
 		t.completeLocked(nil)
 	}
 
@@ -725,6 +752,7 @@ func (t *Transfer) completeLocked(err error) {
 		t.Error = err
 	} else {
 		t.State = TransferStateCompleted
+		t.removeManifestLocked()
 	}
 
 	cb := t.completeCallback
@@ -743,19 +771,21 @@ func (t *Transfer) updateTransferSpeed(chunkSize uint64) {
 	duration := t.timeProvider.Since(t.lastChunkTime).Seconds()
 
 	if duration > 0 {
-		instantSpeed := float64(chunkSize) / duration
-
-		// Exponential moving average with alpha = 0.3
-		if t.transferSpeed == 0 {
-			t.transferSpeed = instantSpeed
-		} else {
-			t.transferSpeed = 0.7*t.transferSpeed + 0.3*instantSpeed
-		}
+		t.transferSpeed = smoothSpeed(t.transferSpeed, float64(chunkSize)/duration)
 	}
 
 	t.lastChunkTime = now
 }
 
+// smoothSpeed folds an instantaneous speed sample into the running estimate.
+func smoothSpeed(current, instantSpeed float64) float64 {
+	// Exponential moving average with alpha = 0.3
+	if current == 0 {
+		return instantSpeed
+	}
+	return 0.7*current + 0.3*instantSpeed
+}
+
 // OnProgress sets a callback function to be called when progress updates.
 // This method is safe for concurrent use.
 //
@@ -941,6 +971,7 @@ func (t *Transfer) SetAcknowledgedBytes(bytes uint64) {
 		return
 	}
 
+	t.recordAckSpeedLocked(bytes - t.acknowledged)
 	t.acknowledged = bytes
 
 	logrus.WithFields(logrus.Fields{
@@ -951,6 +982,10 @@ func (t *Transfer) SetAcknowledgedBytes(bytes uint64) {
 		"transferred":  t.Transferred,
 	}).Debug("Updated acknowledged bytes")
 
+	if t.window != nil && t.State == TransferStateRunning && bytes >= t.FileSize {
+		t.completeLocked(nil)
+	}
+
 	cb := t.ackCallback
 	// Unlock before invoking callback to prevent re-entrant getter calls from
 	// deadlocking on the same mutex (M-FILE-3).
@@ -961,6 +996,26 @@ func (t *Transfer) SetAcknowledgedBytes(bytes uint64) {
 	t.mu.Lock()
 }
 
+// recordAckSpeedLocked updates the throughput estimate of an outgoing
+// transfer from newly acknowledged bytes and the time since the previous
+// acknowledgment. Caller must hold t.mu.
+func (t *Transfer) recordAckSpeedLocked(newlyAcked uint64) {
+	if newlyAcked == 0 {
+		return
+	}
+	since := t.lastAckTime
+	if since.IsZero() {
+		since = t.StartTime
+	}
+	t.lastAckTime = t.timeProvider.Now()
+	if since.IsZero() {
+		return
+	}
+	if duration := t.lastAckTime.Sub(since).Seconds(); duration > 0 {
+		t.transferSpeed = smoothSpeed(t.transferSpeed, float64(newlyAcked)/duration)
+	}
+}
+
 // GetAcknowledgedBytes returns the number of bytes acknowledged by the peer.
 //
 //export ToxFileTransferGetAcknowledgedBytes
@@ -0,0 +1,254 @@
+package file
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Webhook event names sent in the "event" field of a WebhookPayload.
+const (
+	WebhookEventProgress  = "progress"
+	WebhookEventCompleted = "completed"
+	WebhookEventCancelled = "cancelled"
+)
+
+// WebhookSignatureHeader carries the hex HMAC-SHA256 of the request body,
+// keyed with the webhook secret and prefixed with "sha256=".
+const WebhookSignatureHeader = "X-Tox-Signature"
+
+const (
+	// webhookTimeout bounds each webhook POST.
+	webhookTimeout = 10 * time.Second
+	// webhookQueueSize is the number of events buffered for delivery.
+	// Progress events are dropped when the queue is full.
+	webhookQueueSize = 64
+)
+
+// ErrInvalidWebhook is returned by SetProgressWebhook for an unusable URL
+// or interval.
+var ErrInvalidWebhook = errors.New("invalid progress webhook")
+
+// WebhookPayload is the JSON body POSTed to the progress webhook.
+type WebhookPayload struct {
+	Event            string  `json:"event"`
+	FriendID         uint32  `json:"friendID"`
+	FileID           uint32  `json:"fileID"`
+	Filename         string  `json:"filename"`
+	Transferred      uint64  `json:"transferred"`
+	Total            uint64  `json:"total"`
+	SpeedBytesPerSec float64 `json:"speedBytesPerSec"`
+}
+
+// progressWebhook delivers transfer events to one webhook URL from a
+// single worker goroutine, so events for a transfer arrive in order.
+type progressWebhook struct {
+	url      string
+	secret   []byte
+	interval int
+	client   *http.Client
+	queue    chan WebhookPayload
+
+	// steps holds the last reported progress step per transfer; a negative
+	// step means the final event was sent. Guarded by Manager.webhookMu.
+	steps map[transferKey]int
+}
+
+// SetProgressWebhook makes the manager POST transfer progress to webhookURL every
+// intervalPercent percent (1-100), with a final event when a transfer
+// completes or is cancelled. Each body is a WebhookPayload signed with
+// HMAC-SHA256 using secret in the X-Tox-Signature header. Cancellations are
+// reported when they go through the Manager: peer cancel packets,
+// replaced incoming requests and CancelTransfersForFriend.
+//
+// Webhooks are delivered in the background; failures are logged and never
+// affect the transfer. Pass an empty webhookURL to disable the webhook.
+func (m *Manager) SetProgressWebhook(webhookURL, secret string, intervalPercent int) error {
+	var hook *progressWebhook
+	if webhookURL != "" {
+		if err := validateWebhook(webhookURL, intervalPercent); err != nil {
+			logrus.WithFields(logrus.Fields{
+				"function": "SetProgressWebhook",
+				"error":    err.Error(),
+			}).Error("Rejected progress webhook configuration")
+			return err
+		}
+		hook = &progressWebhook{
+			url:      webhookURL,
+			secret:   []byte(secret),
+			interval: intervalPercent,
+			client:   &http.Client{Timeout: webhookTimeout},
+			queue:    make(chan WebhookPayload, webhookQueueSize),
+			steps:    make(map[transferKey]int),
+		}
+		go hook.run()
+	}
+
+	m.webhookMu.Lock()
+	old := m.webhook
+	m.webhook = hook
+	m.webhookMu.Unlock()
+	if old != nil {
+		close(old.queue)
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"function":         "SetProgressWebhook",
+		"enabled":          hook != nil,
+		"interval_percent": intervalPercent,
+	}).Info("Progress webhook configured")
+	return nil
+}
+
+// validateWebhook checks the webhook URL and interval.
+func validateWebhook(rawURL string, intervalPercent int) error {
+	if intervalPercent < 1 || intervalPercent > 100 {
+		return fmt.Errorf("%w: interval %d%% not in 1-100", ErrInvalidWebhook, intervalPercent)
+	}
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidWebhook, err)
+	}
+	if (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("%w: URL must be absolute http or https", ErrInvalidWebhook)
+	}
+	return nil
+}
+
+// notifyWebhookProgress queues an event for transfer if it crossed another
+// progress interval, completed or was cancelled since the last event.
+func (m *Manager) notifyWebhookProgress(key transferKey, transfer *Transfer) {
+	m.webhookMu.Lock()
+	defer m.webhookMu.Unlock()
+	hook := m.webhook
+	if hook == nil {
+		return
+	}
+	last := hook.steps[key]
+	if last < 0 {
+		return
+	}
+
+	var event string
+	switch transfer.GetState() {
+	case TransferStateCompleted:
+		event = WebhookEventCompleted
+	case TransferStateCancelled:
+		event = WebhookEventCancelled
+	default:
+		step := int(transfer.GetProgress()) / hook.interval
+		if step <= last {
+			return
+		}
+		hook.steps[key] = step
+		hook.enqueue(hook.payload(WebhookEventProgress, transfer), false)
+		return
+	}
+	hook.steps[key] = -1
+	hook.enqueue(hook.payload(event, transfer), true)
+}
+
+// forgetWebhookTransfer drops the progress state of a removed transfer.
+func (m *Manager) forgetWebhookTransfer(key transferKey) {
+	m.webhookMu.Lock()
+	defer m.webhookMu.Unlock()
+	if m.webhook != nil {
+		delete(m.webhook.steps, key)
+	}
+}
+
+// payload builds the webhook body for transfer.
+func (h *progressWebhook) payload(event string, transfer *Transfer) WebhookPayload {
+	return WebhookPayload{
+		Event:            event,
+		FriendID:         transfer.FriendID,
+		FileID:           transfer.FileID,
+		Filename:         filepath.Base(transfer.FileName),
+		Transferred:      transfer.GetTransferred(),
+		Total:            transfer.FileSize,
+		SpeedBytesPerSec: transfer.GetSpeed(),
+	}
+}
+
+// enqueue hands payload to the worker without blocking the transfer. When
+// the queue is full, progress events are dropped and final events are
+// posted from their own goroutine so they are not lost. The caller must
+// hold Manager.webhookMu, which keeps the queue open.
+func (h *progressWebhook) enqueue(payload WebhookPayload, final bool) {
+	select {
+	case h.queue <- payload:
+		return
+	default:
+	}
+	if final {
+		go h.deliver(payload)
+		return
+	}
+	logrus.WithFields(logrus.Fields{
+		"function":  "notifyWebhookProgress",
+		"friend_id": payload.FriendID,
+		"file_id":   payload.FileID,
+	}).Warn("Progress webhook queue full, dropping progress event")
+}
+
+// run delivers queued events until the queue is closed.
+func (h *progressWebhook) run() {
+	for payload := range h.queue {
+		h.deliver(payload)
+	}
+}
+
+// deliver posts payload, logging failures.
+func (h *progressWebhook) deliver(payload WebhookPayload) {
+	if err := h.post(payload); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"function":  "progressWebhook.deliver",
+			"event":     payload.Event,
+			"friend_id": payload.FriendID,
+			"file_id":   payload.FileID,
+			"error":     err.Error(),
+		}).Error("Failed to deliver progress webhook")
+	}
+}
+
+// post sends one signed webhook request.
+func (h *progressWebhook) post(payload WebhookPayload) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode payload: %w", err)
+	}
+	req, err := http.NewRequest(http.MethodPost, h.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookSignatureHeader, SignWebhookPayload(h.secret, body))
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// SignWebhookPayload returns the X-Tox-Signature header value for body,
+// so receivers can verify webhooks with hmac.Equal.
+func SignWebhookPayload(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
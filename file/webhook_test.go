@@ -0,0 +1,175 @@
+package file
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/opd-ai/toxcore/transport"
+)
+
+// webhookRecorder collects webhook deliveries and checks their signatures.
+type webhookRecorder struct {
+	t        *testing.T
+	secret   []byte
+	mu       sync.Mutex
+	payloads []WebhookPayload
+}
+
+func (r *webhookRecorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		r.t.Errorf("failed to read webhook body: %v", err)
+		return
+	}
+	if got, want := req.Header.Get(WebhookSignatureHeader), SignWebhookPayload(r.secret, body); got != want {
+		r.t.Errorf("signature %q, want %q", got, want)
+	}
+	var payload WebhookPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		r.t.Errorf("invalid webhook JSON: %v", err)
+	}
+	r.mu.Lock()
+	r.payloads = append(r.payloads, payload)
+	r.mu.Unlock()
+}
+
+// wait returns the received payloads once n have arrived.
+func (r *webhookRecorder) wait(n int) []WebhookPayload {
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		r.mu.Lock()
+		if len(r.payloads) >= n {
+			payloads := append([]WebhookPayload(nil), r.payloads...)
+			r.mu.Unlock()
+			return payloads
+		}
+		r.mu.Unlock()
+		time.Sleep(5 * time.Millisecond)
+	}
+	r.t.Fatalf("timed out waiting for %d webhooks", n)
+	return nil
+}
+
+func TestProgressWebhookIncomingTransfer(t *testing.T) {
+	t.Chdir(t.TempDir()) // Incoming files are written to the working directory
+	recorder := &webhookRecorder{t: t, secret: []byte("secret")}
+	server := httptest.NewServer(recorder)
+	defer server.Close()
+
+	trans := newMockTransport()
+	manager := NewManager(trans)
+	if err := manager.SetProgressWebhook(server.URL, "secret", 50); err != nil {
+		t.Fatalf("SetProgressWebhook failed: %v", err)
+	}
+	addr := &mockAddr{network: "udp", address: testPeerAddr}
+
+	trans.simulateReceive(transport.PacketFileRequest, serializeFileRequest(6, "webhook.bin", testFileSize1KB), addr)
+	transfer, err := manager.GetTransfer(6, 6)
+	if err != nil {
+		t.Fatalf("GetTransfer failed: %v", err)
+	}
+	if err := transfer.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+
+	chunk := make([]byte, testFileSize1KB/4)
+	for i := 0; i < 4; i++ {
+		trans.simulateReceive(transport.PacketFileData, serializeFileData(6, uint64(i*len(chunk)), chunk), addr)
+	}
+
+	// 25% and 75% stay within an interval; 100% is reported as completion
+	payloads := recorder.wait(2)
+	if len(payloads) != 2 {
+		t.Fatalf("expected 2 webhooks, got %+v", payloads)
+	}
+	first := payloads[0]
+	if first.Event != WebhookEventProgress || first.Transferred != 512 || first.Total != testFileSize1KB ||
+		first.FriendID != 6 || first.FileID != 6 || first.Filename != "webhook.bin" {
+		t.Errorf("unexpected progress webhook: %+v", first)
+	}
+	if payloads[1].Event != WebhookEventCompleted || payloads[1].Transferred != testFileSize1KB {
+		t.Errorf("unexpected completion webhook: %+v", payloads[1])
+	}
+}
+
+func TestProgressWebhookCancelled(t *testing.T) {
+	t.Chdir(t.TempDir())
+	recorder := &webhookRecorder{t: t, secret: []byte("key")}
+	server := httptest.NewServer(recorder)
+	defer server.Close()
+
+	trans := newMockTransport()
+	manager := NewManager(trans)
+	if err := manager.SetProgressWebhook(server.URL, "key", 10); err != nil {
+		t.Fatalf("SetProgressWebhook failed: %v", err)
+	}
+	addr := &mockAddr{network: "udp", address: testPeerAddr}
+	trans.simulateReceive(transport.PacketFileRequest, serializeFileRequest(7, "c.bin", testFileSize1KB), addr)
+
+	if n := manager.CancelTransfersForFriend(7); n != 1 {
+		t.Fatalf("expected 1 cancelled transfer, got %d", n)
+	}
+	payloads := recorder.wait(1)
+	if payloads[0].Event != WebhookEventCancelled || payloads[0].FileID != 7 {
+		t.Errorf("unexpected cancellation webhook: %+v", payloads[0])
+	}
+}
+
+func TestProgressWebhookFailureDoesNotAbortTransfer(t *testing.T) {
+	t.Chdir(t.TempDir())
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	trans := newMockTransport()
+	manager := NewManager(trans)
+	if err := manager.SetProgressWebhook(server.URL, "", 1); err != nil {
+		t.Fatalf("SetProgressWebhook failed: %v", err)
+	}
+	addr := &mockAddr{network: "udp", address: testPeerAddr}
+	trans.simulateReceive(transport.PacketFileRequest, serializeFileRequest(8, "f.bin", 16), addr)
+	transfer, _ := manager.GetTransfer(8, 8)
+	if err := transfer.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	if err := trans.handler[transport.PacketFileData](&transport.Packet{
+		PacketType: transport.PacketFileData,
+		Data:       serializeFileData(8, 0, make([]byte, 16)),
+	}, addr); err != nil {
+		t.Fatalf("file data failed: %v", err)
+	}
+	if transfer.GetState() != TransferStateCompleted {
+		t.Errorf("expected completed transfer, got state %v", transfer.GetState())
+	}
+	if err := manager.SetProgressWebhook("", "", 0); err != nil {
+		t.Errorf("disabling webhook failed: %v", err)
+	}
+}
+
+func TestSetProgressWebhookValidation(t *testing.T) {
+	manager := NewManager(nil)
+	tests := []struct {
+		name     string
+		url      string
+		interval int
+	}{
+		{"zero interval", "http://localhost/hook", 0},
+		{"interval over 100", "http://localhost/hook", 101},
+		{"relative URL", "/hook", 10},
+		{"unsupported scheme", "ftp://localhost/hook", 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := manager.SetProgressWebhook(tt.url, "s", tt.interval); !errors.Is(err, ErrInvalidWebhook) {
+				t.Errorf("expected ErrInvalidWebhook, got %v", err)
+			}
+		})
+	}
+}
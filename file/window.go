@@ -0,0 +1,268 @@
+package file
+
+import (
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/opd-ai/toxcore/transport"
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultWindowSize is the default number of unacknowledged chunks an
+// outgoing transfer may have in flight.
+const DefaultWindowSize = 16
+
+// DefaultAckTimeout is the default time to wait for a chunk acknowledgment
+// before the chunk is retransmitted.
+const DefaultAckTimeout = 10 * time.Second
+
+// MaxRetries is the number of times an unacknowledged chunk is retransmitted
+// before the transfer fails with ErrAckTimeout.
+const MaxRetries = 3
+
+// ErrWindowFull indicates the send window has no room for another chunk
+// until an acknowledgment arrives.
+var ErrWindowFull = errors.New("send window full")
+
+// ErrAckTimeout indicates a chunk was not acknowledged after MaxRetries
+// retransmissions.
+var ErrAckTimeout = errors.New("chunk acknowledgment timed out")
+
+// chunkSequence returns the sequence number of the chunk starting at, or
+// containing, position. Manager sends ChunkSize chunks, so both peers derive
+// the same number from the file position.
+func chunkSequence(position uint64) uint32 {
+	return uint32(position / ChunkSize)
+}
+
+// inFlightChunk is a sent chunk awaiting acknowledgment. The payload is kept
+// so it can be retransmitted without rereading the file.
+type inFlightChunk struct {
+	sequence uint32
+	position uint64
+	data     []byte
+	sentAt   time.Time
+	retries  int
+}
+
+// sendWindow tracks the unacknowledged chunks of an outgoing transfer.
+type sendWindow struct {
+	mu         sync.Mutex
+	size       int
+	ackTimeout time.Duration
+	addr       net.Addr
+	inFlight   []*inFlightChunk // ordered by sequence
+}
+
+// newSendWindow creates an empty window sending to addr.
+func newSendWindow(size int, ackTimeout time.Duration, addr net.Addr) *sendWindow {
+	return &sendWindow{size: size, ackTimeout: ackTimeout, addr: addr}
+}
+
+// full reports whether another chunk may be sent.
+func (w *sendWindow) full() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return len(w.inFlight) >= w.size
+}
+
+// push records a chunk that has just been sent.
+func (w *sendWindow) push(position uint64, data []byte, now time.Time) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.inFlight = append(w.inFlight, &inFlightChunk{
+		sequence: chunkSequence(position),
+		position: position,
+		data:     data,
+		sentAt:   now,
+	})
+}
+
+// acknowledge advances the window past every chunk up to and including
+// sequence that lies within the first acked bytes, and returns the number
+// of chunks released.
+func (w *sendWindow) acknowledge(sequence uint32, acked uint64) int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	n := 0
+	for n < len(w.inFlight) {
+		c := w.inFlight[n]
+		if c.sequence > sequence || c.position+uint64(len(c.data)) > acked {
+			break
+		}
+		n++
+	}
+	w.inFlight = w.inFlight[n:]
+	return n
+}
+
+// expired returns the chunks whose acknowledgment is overdue, marking them
+// as resent at now. It returns ErrAckTimeout once a chunk has used up its
+// MaxRetries retransmissions.
+func (w *sendWindow) expired(now time.Time) ([]*inFlightChunk, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	var resend []*inFlightChunk
+	for _, c := range w.inFlight {
+		if now.Sub(c.sentAt) < w.ackTimeout {
+			continue
+		}
+		if c.retries >= MaxRetries {
+			return nil, ErrAckTimeout
+		}
+		c.retries++
+		c.sentAt = now
+		resend = append(resend, c)
+	}
+	return resend, nil
+}
+
+// reset drops every in-flight chunk, e.g. when the transfer restarts at a
+// new offset.
+func (w *sendWindow) reset() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.inFlight = nil
+}
+
+// len returns the number of chunks in flight.
+func (w *sendWindow) len() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return len(w.inFlight)
+}
+
+// GetInFlightChunks returns the number of sent chunks awaiting
+// acknowledgment. Transfers without a send window always return 0.
+//
+//export ToxFileTransferGetInFlightChunks
+func (t *Transfer) GetInFlightChunks() int {
+	t.mu.Lock()
+	w := t.window
+	t.mu.Unlock()
+	if w == nil {
+		return 0
+	}
+	return w.len()
+}
+
+// SetWindowSize sets the number of unacknowledged chunks transfers started
+// by SendFile may have in flight. It applies to transfers created afterwards.
+func (m *Manager) SetWindowSize(size int) error {
+	if size < 1 {
+		return errors.New("window size must be at least 1")
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.windowSize = size
+	return nil
+}
+
+// SetAckTimeout sets how long transfers started by SendFile wait for a chunk
+// acknowledgment before retransmitting it.
+func (m *Manager) SetAckTimeout(timeout time.Duration) error {
+	if timeout <= 0 {
+		return errors.New("ack timeout must be positive")
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.ackTimeout = timeout
+	return nil
+}
+
+// FillWindow sends chunks of an outgoing transfer until its send window is
+// full or the file has been read, and returns the number of chunks sent.
+// Acknowledgments call it automatically; callers use it to start sending.
+func (m *Manager) FillWindow(friendID, fileID uint32, addr net.Addr) (int, error) {
+	sent := 0
+	for {
+		err := m.SendChunk(friendID, fileID, addr)
+		if errors.Is(err, ErrWindowFull) || errors.Is(err, io.EOF) {
+			return sent, nil
+		}
+		if err != nil {
+			return sent, err
+		}
+		sent++
+	}
+}
+
+// advanceWindow releases the chunks covered by an acknowledgment and sends
+// the chunks that now fit in the window.
+func (m *Manager) advanceWindow(friendID, fileID uint32, transfer *Transfer, sequence uint32) {
+	transfer.mu.Lock()
+	w := transfer.window
+	acked := transfer.acknowledged
+	transfer.mu.Unlock()
+	if w == nil || w.acknowledge(sequence, acked) == 0 || transfer.GetState() != TransferStateRunning {
+		return
+	}
+	if _, err := m.FillWindow(friendID, fileID, w.addr); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"function":  "advanceWindow",
+			"friend_id": friendID,
+			"file_id":   fileID,
+			"error":     err.Error(),
+		}).Warn("Failed to send chunks after acknowledgment")
+	}
+}
+
+// RetransmitExpired resends every in-flight chunk whose acknowledgment is
+// older than the ack timeout and fails transfers whose chunks have already
+// been retransmitted MaxRetries times. It returns the number of chunks
+// resent and is meant to be called periodically, e.g. from Tox.Iterate.
+func (m *Manager) RetransmitExpired() int {
+	m.mu.RLock()
+	transfers := make(map[transferKey]*Transfer, len(m.transfers))
+	for key, transfer := range m.transfers {
+		transfers[key] = transfer
+	}
+	m.mu.RUnlock()
+
+	resent := 0
+	for key, transfer := range transfers {
+		transfer.mu.Lock()
+		w := transfer.window
+		now := transfer.timeProvider.Now()
+		transfer.mu.Unlock()
+		if w == nil || transfer.GetState() != TransferStateRunning {
+			continue
+		}
+
+		chunks, err := w.expired(now)
+		if err != nil {
+			logrus.WithFields(logrus.Fields{
+				"function":  "RetransmitExpired",
+				"friend_id": key.friendID,
+				"file_id":   key.fileID,
+				"retries":   MaxRetries,
+			}).Warn("Chunk not acknowledged after maximum retries; failing transfer")
+			transfer.complete(err)
+			m.notifyWebhookProgress(key, transfer)
+			continue
+		}
+		for _, c := range chunks {
+			if m.transport == nil {
+				break
+			}
+			packet := &transport.Packet{
+				PacketType: transport.PacketFileData,
+				Data:       serializeFileData(key.fileID, c.position, c.data),
+			}
+			if err := m.transport.Send(packet, w.addr); err != nil {
+				logrus.WithFields(logrus.Fields{
+					"function": "RetransmitExpired",
+					"file_id":  key.fileID,
+					"sequence": c.sequence,
+					"error":    err.Error(),
+				}).Warn("Failed to retransmit chunk")
+				continue
+			}
+			resent++
+		}
+	}
+	return resent
+}
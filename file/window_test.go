@@ -0,0 +1,162 @@
+package file
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/opd-ai/toxcore/transport"
+)
+
+// newWindowedSender starts an outgoing transfer of size bytes from a manager
+// with the given window size.
+func newWindowedSender(t *testing.T, windowSize int, size int) (*Manager, *mockTransport, *Transfer, []byte) {
+	t.Helper()
+	data := make([]byte, size)
+	for i := range data {
+		data[i] = byte(i % 251)
+	}
+	source := filepath.Join(t.TempDir(), "window.bin")
+	if err := os.WriteFile(source, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	trans := newMockTransport()
+	sender := NewManager(trans)
+	if err := sender.SetWindowSize(windowSize); err != nil {
+		t.Fatal(err)
+	}
+	receiverAddr := &mockAddr{network: "udp", address: testPeerAddr2}
+	transfer, err := sender.SendFile(5, 5, source, uint64(size), receiverAddr)
+	if err != nil {
+		t.Fatalf("SendFile failed: %v", err)
+	}
+	if err := transfer.Start(); err != nil {
+		t.Fatal(err)
+	}
+	trans.clearPackets()
+	return sender, trans, transfer, data
+}
+
+func TestSendWindowLimitsInFlightChunks(t *testing.T) {
+	const window = 4
+	sender, senderTrans, outgoing, data := newWindowedSender(t, window, 10*ChunkSize+100)
+	senderAddr := &mockAddr{network: "udp", address: testPeerAddr}
+	receiverAddr := &mockAddr{network: "udp", address: testPeerAddr2}
+
+	t.Chdir(t.TempDir())
+	receiverTrans := newMockTransport()
+	receiver := NewManager(receiverTrans)
+	receiverTrans.simulateReceive(transport.PacketFileRequest, serializeFileRequest(5, "window.bin", uint64(len(data))), senderAddr)
+	incoming, err := receiver.GetTransfer(5, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := incoming.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	sent, err := sender.FillWindow(5, 5, receiverAddr)
+	if err != nil || sent != window {
+		t.Fatalf("expected %d chunks sent into the window, got %d (%v)", window, sent, err)
+	}
+	if err := sender.SendChunk(5, 5, receiverAddr); !errors.Is(err, ErrWindowFull) {
+		t.Fatalf("expected ErrWindowFull, got %v", err)
+	}
+
+	// Deliver chunks to the receiver but hold its acks back, releasing
+	// them one at a time.
+	var pendingAcks []*transport.Packet
+	for len(senderTrans.packets) > 0 || len(pendingAcks) > 0 {
+		for _, p := range senderTrans.packets {
+			receiverTrans.simulateReceive(p.packet.PacketType, p.packet.Data, senderAddr)
+		}
+		senderTrans.clearPackets()
+		for _, p := range receiverTrans.packets {
+			pendingAcks = append(pendingAcks, p.packet)
+		}
+		receiverTrans.clearPackets()
+
+		if inFlight := outgoing.GetInFlightChunks(); inFlight > window {
+			t.Fatalf("window exceeded: %d chunks in flight", inFlight)
+		}
+		if len(pendingAcks) == 0 {
+			break
+		}
+		ack := pendingAcks[0]
+		pendingAcks = pendingAcks[1:]
+		senderTrans.simulateReceive(ack.PacketType, ack.Data, receiverAddr)
+		if n := len(senderTrans.packets); n > 1 {
+			t.Fatalf("one ack released %d chunks", n)
+		}
+	}
+
+	if incoming.GetState() != TransferStateCompleted || outgoing.GetState() != TransferStateCompleted {
+		t.Fatalf("expected both sides completed, got %v / %v", incoming.GetState(), outgoing.GetState())
+	}
+	if received, _ := os.ReadFile("window.bin"); !bytes.Equal(received, data) {
+		t.Error("received file does not match the source")
+	}
+}
+
+func TestSendWindowRetransmitsUntilMaxRetries(t *testing.T) {
+	sender, senderTrans, outgoing, _ := newWindowedSender(t, 2, 5*ChunkSize)
+	tp := newMockTimeProvider()
+	outgoing.SetTimeProvider(tp)
+	receiverAddr := &mockAddr{network: "udp", address: testPeerAddr2}
+
+	if sent, err := sender.FillWindow(5, 5, receiverAddr); err != nil || sent != 2 {
+		t.Fatalf("expected 2 chunks sent, got %d (%v)", sent, err)
+	}
+	senderTrans.clearPackets()
+	if n := sender.RetransmitExpired(); n != 0 {
+		t.Errorf("expected no retransmission before the timeout, got %d", n)
+	}
+
+	for i := 0; i < MaxRetries; i++ {
+		tp.advance(DefaultAckTimeout)
+		if n := sender.RetransmitExpired(); n != 2 {
+			t.Fatalf("retry %d: expected 2 chunks resent, got %d", i+1, n)
+		}
+	}
+	if pos := binaryPosition(senderTrans.packets[0].packet.Data); pos != 0 {
+		t.Errorf("expected the oldest chunk resent first, got position %d", pos)
+	}
+
+	var completeErr error
+	outgoing.OnComplete(func(err error) { completeErr = err })
+	tp.advance(DefaultAckTimeout)
+	sender.RetransmitExpired()
+	if outgoing.GetState() != TransferStateError || !errors.Is(completeErr, ErrAckTimeout) {
+		t.Errorf("expected failure with ErrAckTimeout, got %v %v", outgoing.GetState(), completeErr)
+	}
+}
+
+func TestSendWindowSpeedFromAcks(t *testing.T) {
+	tp := newMockTimeProvider()
+	outgoing := NewTransfer(1, 1, "f", 4*ChunkSize, TransferDirectionOutgoing)
+	outgoing.SetTimeProvider(tp)
+	outgoing.mu.Lock()
+	outgoing.State = TransferStateRunning
+	outgoing.StartTime = tp.Now()
+	outgoing.Transferred = 4 * ChunkSize
+	outgoing.mu.Unlock()
+
+	if speed := outgoing.GetSpeed(); speed != 0 {
+		t.Errorf("expected no speed before acknowledgments, got %v", speed)
+	}
+	tp.advance(2 * time.Second)
+	outgoing.SetAcknowledgedBytes(2 * ChunkSize)
+	if speed := outgoing.GetSpeed(); speed != ChunkSize {
+		t.Errorf("expected %d B/s from acknowledgments, got %v", ChunkSize, speed)
+	}
+}
+
+// binaryPosition returns the position field of a file data payload.
+func binaryPosition(data []byte) uint64 {
+	_, position, _, _ := deserializeFileData(data)
+	return position
+}
@@ -0,0 +1,182 @@
+package friend
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Block list errors.
+var (
+	// ErrSenderBlocked is returned by AddRequest for a sender on the block list.
+	ErrSenderBlocked = errors.New("friend request sender is blocked")
+
+	// ErrAlreadyBlocked is returned by Block for a key that is already blocked.
+	ErrAlreadyBlocked = errors.New("public key is already blocked")
+
+	// ErrNotBlocked is returned by Unblock for a key that is not blocked.
+	ErrNotBlocked = errors.New("public key is not blocked")
+)
+
+// BlockList is a thread-safe set of blocked public keys. It persists as a
+// text file with one hex-encoded public key per line.
+//
+//export ToxFriendBlockList
+type BlockList struct {
+	mu   sync.RWMutex
+	keys map[[32]byte]struct{}
+}
+
+// NewBlockList creates an empty block list.
+//
+//export ToxFriendBlockListNew
+func NewBlockList() *BlockList {
+	return &BlockList{keys: make(map[[32]byte]struct{})}
+}
+
+// Block adds publicKey to the block list.
+//
+//export ToxFriendBlockListBlock
+func (b *BlockList) Block(publicKey [32]byte) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.keys[publicKey]; ok {
+		return ErrAlreadyBlocked
+	}
+	b.keys[publicKey] = struct{}{}
+	return nil
+}
+
+// Unblock removes publicKey from the block list.
+//
+//export ToxFriendBlockListUnblock
+func (b *BlockList) Unblock(publicKey [32]byte) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.keys[publicKey]; !ok {
+		return ErrNotBlocked
+	}
+	delete(b.keys, publicKey)
+	return nil
+}
+
+// IsBlocked reports whether publicKey is on the block list.
+//
+//export ToxFriendBlockListIsBlocked
+func (b *BlockList) IsBlocked(publicKey [32]byte) bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	_, ok := b.keys[publicKey]
+	return ok
+}
+
+// All returns the blocked public keys in ascending byte order.
+//
+//export ToxFriendBlockListAll
+func (b *BlockList) All() [][32]byte {
+	b.mu.RLock()
+	keys := make([][32]byte, 0, len(b.keys))
+	for pk := range b.keys {
+		keys = append(keys, pk)
+	}
+	b.mu.RUnlock()
+
+	sort.Slice(keys, func(i, j int) bool { return bytes.Compare(keys[i][:], keys[j][:]) < 0 })
+	return keys
+}
+
+// Save writes the block list to path, one hex public key per line. The list
+// is written to a temporary file in the same directory and renamed over
+// path, so a crash during Save leaves either the old or the new file.
+//
+//export ToxFriendBlockListSave
+func (b *BlockList) Save(path string) error {
+	keys := b.All()
+	var buf bytes.Buffer
+	for _, pk := range keys {
+		buf.WriteString(hex.EncodeToString(pk[:]))
+		buf.WriteByte('\n')
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary block list file: %w", err)
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath) // No-op once renamed
+
+	if _, err := tmp.Write(buf.Bytes()); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write block list: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to sync block list: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close block list: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("failed to replace block list: %w", err)
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"function":     "BlockList.Save",
+		"path":         path,
+		"blocked_keys": len(keys),
+	}).Debug("Block list saved")
+
+	return nil
+}
+
+// Load replaces the block list with the keys read from path. Blank lines
+// are ignored; any other line must be a 64-character hex public key. On
+// error the block list is left unchanged.
+//
+//export ToxFriendBlockListLoad
+func (b *BlockList) Load(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read block list: %w", err)
+	}
+
+	keys := make(map[[32]byte]struct{})
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		decoded, err := hex.DecodeString(line)
+		if err != nil || len(decoded) != 32 {
+			return fmt.Errorf("block list line %d: expected a 64-character hex public key", lineNum)
+		}
+		var pk [32]byte
+		copy(pk[:], decoded)
+		keys[pk] = struct{}{}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read block list: %w", err)
+	}
+
+	b.mu.Lock()
+	b.keys = keys
+	b.mu.Unlock()
+
+	logrus.WithFields(logrus.Fields{
+		"function":     "BlockList.Load",
+		"path":         path,
+		"blocked_keys": len(keys),
+	}).Debug("Block list loaded")
+
+	return nil
+}
@@ -0,0 +1,129 @@
+package friend
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBlockListBlockUnblock(t *testing.T) {
+	bl := NewBlockList()
+	pk := [32]byte{1}
+
+	if bl.IsBlocked(pk) {
+		t.Fatal("New block list should be empty")
+	}
+	if err := bl.Block(pk); err != nil {
+		t.Fatalf("Block failed: %v", err)
+	}
+	if err := bl.Block(pk); !errors.Is(err, ErrAlreadyBlocked) {
+		t.Errorf("Expected ErrAlreadyBlocked, got %v", err)
+	}
+	if !bl.IsBlocked(pk) {
+		t.Error("Key should be blocked")
+	}
+	if err := bl.Unblock(pk); err != nil {
+		t.Fatalf("Unblock failed: %v", err)
+	}
+	if err := bl.Unblock(pk); !errors.Is(err, ErrNotBlocked) {
+		t.Errorf("Expected ErrNotBlocked, got %v", err)
+	}
+	if bl.IsBlocked(pk) {
+		t.Error("Key should be unblocked")
+	}
+}
+
+func TestBlockListSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "blocked.txt")
+	bl := NewBlockList()
+	for _, pk := range [][32]byte{{3}, {1}, {2}} {
+		if err := bl.Block(pk); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := bl.Save(path); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	// Saving again replaces the file and leaves no temporary files behind
+	if err := bl.Unblock([32]byte{3}); err != nil {
+		t.Fatal(err)
+	}
+	if err := bl.Save(path); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("Expected only the block list file, found %d entries", len(entries))
+	}
+
+	loaded := NewBlockList()
+	if err := loaded.Block([32]byte{9}); err != nil {
+		t.Fatal(err)
+	}
+	if err := loaded.Load(path); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	all := loaded.All()
+	if len(all) != 2 || all[0] != ([32]byte{1}) || all[1] != ([32]byte{2}) {
+		t.Errorf("Unexpected keys after load: %x", all)
+	}
+}
+
+func TestBlockListLoadErrors(t *testing.T) {
+	dir := t.TempDir()
+	bl := NewBlockList()
+	if err := bl.Load(filepath.Join(dir, "missing")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Expected os.ErrNotExist, got %v", err)
+	}
+
+	path := filepath.Join(dir, "bad.txt")
+	if err := os.WriteFile(path, []byte("abcd\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := bl.Block([32]byte{1}); err != nil {
+		t.Fatal(err)
+	}
+	if err := bl.Load(path); err == nil {
+		t.Error("Expected an error for a malformed line")
+	}
+	if !bl.IsBlocked([32]byte{1}) {
+		t.Error("A failed Load must leave the list unchanged")
+	}
+}
+
+func TestRequestManagerDropsBlockedSenders(t *testing.T) {
+	rm := NewRequestManager()
+	bl := NewBlockList()
+	rm.SetBlockList(bl)
+	calls := 0
+	rm.SetHandler(func(*Request) bool {
+		calls++
+		return false
+	})
+
+	sender := [32]byte{5}
+	if err := bl.Block(sender); err != nil {
+		t.Fatal(err)
+	}
+	if err := rm.AddRequest(&Request{SenderPublicKey: sender, Message: "hi"}); !errors.Is(err, ErrSenderBlocked) {
+		t.Fatalf("Expected ErrSenderBlocked, got %v", err)
+	}
+	if calls != 0 || rm.PendingCount() != 0 {
+		t.Error("Blocked requests must not be stored or reach the handler")
+	}
+
+	if err := bl.Unblock(sender); err != nil {
+		t.Fatal(err)
+	}
+	if err := rm.AddRequest(&Request{SenderPublicKey: sender, Message: "hi"}); err != nil {
+		t.Fatalf("AddRequest after unblock: %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("Expected the handler to run once, got %d", calls)
+	}
+}
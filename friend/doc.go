@@ -68,6 +68,62 @@
 //	manager.AcceptRequest(publicKey)
 //	manager.RejectRequest(publicKey)
 //
+// SetRateLimit bounds how many requests each sender public key may add per
+// minute, with a cap of GlobalRateLimitFactor times that across all senders
+// (see SetGlobalRateLimit). Requests over the limit are dropped before the
+// handler runs, AddRequest returns ErrRateLimitExceeded, and
+// GetDroppedRequests counts them. The limit is off by default:
+//
+//	manager.SetRateLimit(5)
+//	if err := manager.AddRequest(receivedRequest); errors.Is(err, friend.ErrRateLimitExceeded) {
+//	    // Flood from this sender; the request was discarded
+//	}
+//
+// # Block List
+//
+// BlockList holds public keys whose friend requests are dropped. Attached to
+// a RequestManager with SetBlockList, it makes AddRequest return
+// ErrSenderBlocked without calling the handler. Save writes it atomically as
+// one hex public key per line and Load reads it back:
+//
+//	blocked := friend.NewBlockList()
+//	manager.SetBlockList(blocked)
+//	blocked.Block(publicKey)
+//	err := blocked.Save("blocked.txt")
+//
+// Tox.BlockFriend and Tox.UnblockFriend manage the block list of a Tox
+// instance, which Tox.BlockList returns for persistence.
+//
+// # vCard Export
+//
+// FriendInfo.ToVCard encodes a friend as a vCard 4.0 (RFC 6350) contact with
+// FN, NOTE, X-TOX-ADDRESS and an optional PHOTO, and FromVCard reverses it.
+// ParseVCards reads collections such as the output of Tox.ExportFriendList:
+//
+//	card, err := f.ToVCard()
+//	imported, err := friend.FromVCard(card)
+//
+// # Bulk Import
+//
+// ImportEntry, DecodeImportList and EncodeImportList define the JSON contact
+// list used by Tox.ImportFriends and Tox.ExportFriends:
+//
+//	[{"public_key": "<hex public key or Tox ID>", "message": "optional greeting"}]
+//
+// # Friend List Backup
+//
+// FriendList is a versioned JSON backup that also keeps each friend's name,
+// status message and time added (FriendInfo.AddedAt). Tox.ExportFriendBackup
+// produces one and Tox.ImportFriendBackup restores it, skipping friends that
+// already exist:
+//
+//	fl, err := tox.ExportFriendBackup()
+//	data, err := fl.Marshal()
+//	// ... later, on another instance
+//	var restored friend.FriendList
+//	err = restored.Unmarshal(data)
+//	imported, skipped, err := tox.ImportFriendBackup(&restored)
+//
 // # Deterministic Testing
 //
 // For reproducible test scenarios, use the TimeProvider variants:
@@ -0,0 +1,112 @@
+package friend
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// FriendListVersion is the schema version written by FriendList.Marshal.
+// Unmarshal rejects documents with a newer version.
+const FriendListVersion = 1
+
+// FriendList is a versioned JSON backup of a friend list:
+//
+//	{"version": 1, "friends": [{"public_key": "<64 hex chars>", "name": "...",
+//	  "status_message": "...", "added_at": "2024-01-02T15:04:05Z"}]}
+//
+// Only PublicKey, Name, StatusMessage and AddedAt of each friend are kept.
+//
+//export ToxFriendList
+type FriendList struct {
+	Friends []*FriendInfo
+}
+
+// friendListSerialized is the JSON document of a FriendList.
+type friendListSerialized struct {
+	Version int                         `json:"version"`
+	Friends []friendListEntrySerialized `json:"friends"`
+}
+
+// friendListEntrySerialized is one friend in a FriendList document.
+type friendListEntrySerialized struct {
+	PublicKey     string    `json:"public_key"`
+	Name          string    `json:"name"`
+	StatusMessage string    `json:"status_message"`
+	AddedAt       time.Time `json:"added_at"`
+}
+
+// Marshal serializes the friend list to JSON.
+//
+//export ToxFriendListMarshal
+func (fl *FriendList) Marshal() ([]byte, error) {
+	doc := friendListSerialized{
+		Version: FriendListVersion,
+		Friends: make([]friendListEntrySerialized, 0, len(fl.Friends)),
+	}
+	for _, f := range fl.Friends {
+		f.mu.RLock()
+		doc.Friends = append(doc.Friends, friendListEntrySerialized{
+			PublicKey:     hex.EncodeToString(f.PublicKey[:]),
+			Name:          f.Name,
+			StatusMessage: f.StatusMessage,
+			AddedAt:       f.AddedAt,
+		})
+		f.mu.RUnlock()
+	}
+
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal FriendList: %w", err)
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"function":      "FriendList.Marshal",
+		"friends_count": len(doc.Friends),
+		"data_size":     len(data),
+	}).Debug("FriendList marshaled successfully")
+
+	return data, nil
+}
+
+// Unmarshal replaces the friend list with the friends in a JSON document
+// written by Marshal. A document without a version is read as version 1.
+//
+//export ToxFriendListUnmarshal
+func (fl *FriendList) Unmarshal(data []byte) error {
+	var doc friendListSerialized
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("failed to unmarshal FriendList: %w", err)
+	}
+	if doc.Version > FriendListVersion {
+		return fmt.Errorf("unsupported FriendList version %d, newest supported is %d", doc.Version, FriendListVersion)
+	}
+
+	friends := make([]*FriendInfo, 0, len(doc.Friends))
+	for i, entry := range doc.Friends {
+		decoded, err := hex.DecodeString(entry.PublicKey)
+		if err != nil || len(decoded) != 32 {
+			return fmt.Errorf("friend %d: public_key must be 64 hex characters", i)
+		}
+		f := &FriendInfo{
+			Name:          entry.Name,
+			StatusMessage: entry.StatusMessage,
+			AddedAt:       entry.AddedAt,
+			timeProvider:  defaultTimeProvider,
+		}
+		copy(f.PublicKey[:], decoded)
+		friends = append(friends, f)
+	}
+	fl.Friends = friends
+
+	logrus.WithFields(logrus.Fields{
+		"function":      "FriendList.Unmarshal",
+		"version":       doc.Version,
+		"friends_count": len(friends),
+	}).Debug("FriendList unmarshaled successfully")
+
+	return nil
+}
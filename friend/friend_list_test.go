@@ -0,0 +1,71 @@
+package friend
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFriendListRoundTrip(t *testing.T) {
+	added := time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)
+	original := &FriendList{Friends: []*FriendInfo{
+		{PublicKey: [32]byte{1}, Name: "alice", StatusMessage: "hi", AddedAt: added},
+		{PublicKey: [32]byte{2}, Name: "bob"},
+	}}
+
+	data, err := original.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+
+	var doc map[string]interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+	if doc["version"] != float64(FriendListVersion) {
+		t.Errorf("version = %v, want %d", doc["version"], FriendListVersion)
+	}
+	if !strings.Contains(string(data), `"public_key":"01`) {
+		t.Errorf("Expected a hex-encoded public key in %s", data)
+	}
+
+	var decoded FriendList
+	if err := decoded.Unmarshal(data); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if len(decoded.Friends) != len(original.Friends) {
+		t.Fatalf("Expected %d friends, got %d", len(original.Friends), len(decoded.Friends))
+	}
+	for i, want := range original.Friends {
+		got := decoded.Friends[i]
+		if got.PublicKey != want.PublicKey || got.Name != want.Name ||
+			got.StatusMessage != want.StatusMessage || !got.AddedAt.Equal(want.AddedAt) {
+			t.Errorf("friend %d: got %+v, want %+v", i, got, want)
+		}
+	}
+}
+
+func TestFriendListUnmarshalErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"malformed", "not json"},
+		{"newer version", `{"version": 2, "friends": []}`},
+		{"bad key", `{"version": 1, "friends": [{"public_key": "abcd"}]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var fl FriendList
+			if err := fl.Unmarshal([]byte(tt.data)); err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+
+	var fl FriendList
+	if err := fl.Unmarshal([]byte(`{"friends": []}`)); err != nil {
+		t.Errorf("Missing version should read as version 1: %v", err)
+	}
+}
@@ -844,6 +844,15 @@ func TestRequestManager_AddRequest(t *testing.T) {
 	if len(pending) != 2 {
 		t.Errorf("Expected 2 pending requests, got %d", len(pending))
 	}
+	if rm.PendingCount() != 2 {
+		t.Errorf("Expected PendingCount 2, got %d", rm.PendingCount())
+	}
+
+	// Accepted requests no longer count as pending
+	rm.AcceptRequest(pk1)
+	if rm.PendingCount() != 1 {
+		t.Errorf("Expected PendingCount 1 after accept, got %d", rm.PendingCount())
+	}
 }
 
 // TestRequestManager_AddRequest_DuplicateUpdate tests updating existing request.
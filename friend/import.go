@@ -0,0 +1,79 @@
+package friend
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/opd-ai/toxcore/crypto"
+)
+
+// ImportEntry is one element of the JSON contact list accepted by
+// Tox.ImportFriends and written by Tox.ExportFriends:
+//
+//	[{"public_key": "<64 or 76 hex chars>", "message": "optional greeting"}]
+//
+// PublicKey may be a bare public key or a full Tox ID, the latter optionally
+// in the tox: URI form. A friend request with
+// Message is only sent for full Tox IDs; bare keys are added silently.
+type ImportEntry struct {
+	PublicKey string `json:"public_key"`
+	Message   string `json:"message,omitempty"`
+}
+
+// IsToxID reports whether the entry carries a full Tox ID rather than a
+// bare public key.
+func (e ImportEntry) IsToxID() bool {
+	value := strings.TrimSpace(e.PublicKey)
+	return len(value) == crypto.ToxIDHexLength || crypto.HasToxURIScheme(value)
+}
+
+// Key decodes the entry's public key, verifying the checksum of a full
+// Tox ID.
+func (e ImportEntry) Key() ([32]byte, error) {
+	var publicKey [32]byte
+	value := strings.TrimSpace(e.PublicKey)
+
+	switch {
+	case e.IsToxID():
+		id, err := crypto.ParseToxAddress(value)
+		if err != nil {
+			return publicKey, fmt.Errorf("invalid Tox ID: %w", err)
+		}
+		return id.PublicKey, nil
+	case len(value) == hex.EncodedLen(len(publicKey)):
+		decoded, err := hex.DecodeString(value)
+		if err != nil {
+			return publicKey, fmt.Errorf("invalid public key: %w", err)
+		}
+		copy(publicKey[:], decoded)
+		return publicKey, nil
+	default:
+		return publicKey, fmt.Errorf("public_key must be 64 or %d hex characters, got %d",
+			crypto.ToxIDHexLength, len(value))
+	}
+}
+
+// DecodeImportList reads a JSON array of ImportEntry values from r.
+func DecodeImportList(r io.Reader) ([]ImportEntry, error) {
+	var entries []ImportEntry
+	if err := json.NewDecoder(r).Decode(&entries); err != nil {
+		return nil, fmt.Errorf("failed to decode friend list: %w", err)
+	}
+	return entries, nil
+}
+
+// EncodeImportList writes entries to w as an indented JSON array.
+func EncodeImportList(w io.Writer, entries []ImportEntry) error {
+	if entries == nil {
+		entries = []ImportEntry{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(entries); err != nil {
+		return fmt.Errorf("failed to encode friend list: %w", err)
+	}
+	return nil
+}
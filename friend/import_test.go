@@ -0,0 +1,73 @@
+package friend
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/opd-ai/toxcore/crypto"
+)
+
+func TestImportEntryKey(t *testing.T) {
+	var publicKey [32]byte
+	publicKey[0] = 0x42
+	id := crypto.NewToxID(publicKey, [4]byte{9, 9, 9, 9})
+
+	tests := []struct {
+		name    string
+		entry   ImportEntry
+		isToxID bool
+		wantErr bool
+	}{
+		{"public key", ImportEntry{PublicKey: hex.EncodeToString(publicKey[:])}, false, false},
+		{"tox id uppercase", ImportEntry{PublicKey: strings.ToUpper(id.String())}, true, false},
+		{"tox URI", ImportEntry{PublicKey: "tox:" + id.String()}, true, false},
+		{"tox URI bad length", ImportEntry{PublicKey: "tox:" + hex.EncodeToString(publicKey[:])}, true, true},
+		{"bad checksum", ImportEntry{PublicKey: id.String()[:72] + "0000"}, true, true},
+		{"bad length", ImportEntry{PublicKey: "abcd"}, false, true},
+		{"bad hex", ImportEntry{PublicKey: strings.Repeat("zz", 32)}, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.entry.IsToxID(); got != tt.isToxID {
+				t.Errorf("IsToxID() = %v, want %v", got, tt.isToxID)
+			}
+			key, err := tt.entry.Key()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Key() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && key != publicKey {
+				t.Errorf("Key() = %x, want %x", key, publicKey)
+			}
+		})
+	}
+}
+
+func TestImportListRoundTrip(t *testing.T) {
+	entries := []ImportEntry{
+		{PublicKey: strings.Repeat("ab", 32)},
+		{PublicKey: strings.Repeat("cd", 32), Message: "hello"},
+	}
+
+	var buf bytes.Buffer
+	if err := EncodeImportList(&buf, entries); err != nil {
+		t.Fatalf("EncodeImportList failed: %v", err)
+	}
+	decoded, err := DecodeImportList(&buf)
+	if err != nil {
+		t.Fatalf("DecodeImportList failed: %v", err)
+	}
+	if len(decoded) != 2 || decoded[0] != entries[0] || decoded[1] != entries[1] {
+		t.Errorf("round trip mismatch: %+v", decoded)
+	}
+
+	if _, err := DecodeImportList(strings.NewReader(`{"public_key": "x"}`)); err == nil {
+		t.Error("expected error for non-array JSON")
+	}
+
+	buf.Reset()
+	if err := EncodeImportList(&buf, nil); err != nil || strings.TrimSpace(buf.String()) != "[]" {
+		t.Errorf("expected empty array, got %q (%v)", buf.String(), err)
+	}
+}
@@ -72,8 +72,11 @@ type FriendInfo struct {
 	Status           FriendStatus
 	ConnectionStatus ConnectionStatus
 	LastSeen         time.Time
+	AddedAt          time.Time // When the friend was added to the friend list
 	UserData         interface{}
-	timeProvider     TimeProvider
+	// Avatar holds raw profile picture data, exported as the vCard PHOTO.
+	Avatar       []byte
+	timeProvider TimeProvider
 }
 
 // New creates a new FriendInfo with the given public key.
@@ -99,6 +102,7 @@ func NewWithTimeProvider(publicKey [32]byte, tp TimeProvider) *FriendInfo {
 		Status:           FriendStatusNone,
 		ConnectionStatus: ConnectionNone,
 		LastSeen:         tp.Now(),
+		AddedAt:          tp.Now(),
 		timeProvider:     tp,
 	}
 
@@ -272,17 +272,49 @@ type RequestManager struct {
 	mu              sync.RWMutex
 	pendingRequests []*Request
 	handler         RequestHandler
+	limiter         requestRateLimiter
+	blockList       *BlockList
+	timeProvider    TimeProvider
 }
 
 // NewRequestManager creates a new friend request manager.
 //
 //export ToxFriendRequestManagerNew
 func NewRequestManager() *RequestManager {
+	return NewRequestManagerWithTimeProvider(defaultTimeProvider)
+}
+
+// NewRequestManagerWithTimeProvider creates a new friend request manager
+// whose rate limit uses a custom time provider.
+func NewRequestManagerWithTimeProvider(tp TimeProvider) *RequestManager {
+	if tp == nil {
+		tp = defaultTimeProvider
+	}
 	return &RequestManager{
 		pendingRequests: make([]*Request, 0),
+		timeProvider:    tp,
 	}
 }
 
+// SetBlockList sets the block list consulted by AddRequest. Pass nil to
+// accept requests from every sender.
+//
+//export ToxFriendRequestManagerSetBlockList
+func (m *RequestManager) SetBlockList(blockList *BlockList) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.blockList = blockList
+}
+
+// now returns the current time from the manager's time provider. The
+// caller must hold m.mu.
+func (m *RequestManager) now() time.Time {
+	if m.timeProvider == nil {
+		return defaultTimeProvider.Now()
+	}
+	return m.timeProvider.Now()
+}
+
 // SetHandler sets the handler for incoming friend requests.
 //
 //export ToxFriendRequestManagerSetHandler
@@ -295,14 +327,36 @@ func (m *RequestManager) SetHandler(handler RequestHandler) {
 // AddRequest adds a new incoming friend request.
 // The handler callback (if set) is invoked outside the lock to prevent deadlocks
 // when the handler calls back into the RequestManager (e.g., AcceptRequest).
+// Requests from senders on the block list set with SetBlockList are dropped
+// with ErrSenderBlocked. When a rate limit is set with SetRateLimit and the
+// request exceeds it, the request is dropped and ErrRateLimitExceeded is
+// returned.
 //
 //export ToxFriendRequestManagerAddRequest
-func (m *RequestManager) AddRequest(request *Request) {
+func (m *RequestManager) AddRequest(request *Request) error {
 	var handler RequestHandler
 
 	// Critical section: update state and capture handler
 	m.mu.Lock()
 
+	if m.blockList != nil && m.blockList.IsBlocked(request.SenderPublicKey) {
+		m.mu.Unlock()
+		logrus.WithFields(logrus.Fields{
+			"function":          "RequestManager.AddRequest",
+			"sender_public_key": fmt.Sprintf("%x", request.SenderPublicKey[:8]),
+		}).Debug("Friend request dropped: sender is blocked")
+		return ErrSenderBlocked
+	}
+
+	if !m.limiter.allowLocked(request.SenderPublicKey, m.now()) {
+		m.mu.Unlock()
+		logrus.WithFields(logrus.Fields{
+			"function":          "RequestManager.AddRequest",
+			"sender_public_key": fmt.Sprintf("%x", request.SenderPublicKey[:8]),
+		}).Debug("Friend request dropped by rate limit")
+		return ErrRateLimitExceeded
+	}
+
 	// Check if this is a duplicate
 	for _, existing := range m.pendingRequests {
 		if existing.SenderPublicKey == request.SenderPublicKey {
@@ -311,7 +365,7 @@ func (m *RequestManager) AddRequest(request *Request) {
 			existing.Timestamp = request.Timestamp
 			existing.Handled = false
 			m.mu.Unlock()
-			return
+			return nil
 		}
 	}
 
@@ -334,6 +388,7 @@ func (m *RequestManager) AddRequest(request *Request) {
 		}
 		m.mu.Unlock()
 	}
+	return nil
 }
 
 // GetPendingRequests returns copies of all pending friend requests so that
@@ -355,6 +410,23 @@ func (m *RequestManager) GetPendingRequests() []*Request {
 	return pending
 }
 
+// PendingCount returns the number of friend requests that have not been
+// handled yet.
+//
+//export ToxFriendRequestManagerPendingCount
+func (m *RequestManager) PendingCount() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	count := 0
+	for _, req := range m.pendingRequests {
+		if !req.Handled {
+			count++
+		}
+	}
+	return count
+}
+
 // AcceptRequest accepts a friend request.
 //
 //export ToxFriendRequestManagerAcceptRequest
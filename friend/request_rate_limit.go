@@ -0,0 +1,145 @@
+package friend
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrRateLimitExceeded is returned by AddRequest when a request is dropped
+// because its sender, or all senders together, exceeded the rate limit.
+var ErrRateLimitExceeded = errors.New("friend request rate limit exceeded")
+
+const (
+	// rateLimitWindow is the period the per-minute limits refill over.
+	rateLimitWindow = time.Minute
+
+	// GlobalRateLimitFactor is the multiple of the per-sender limit that
+	// SetRateLimit applies across all senders unless SetGlobalRateLimit
+	// overrides it.
+	GlobalRateLimitFactor = 10
+)
+
+// tokenBucket allows bursts of up to its capacity and refills continuously,
+// so it never admits more than capacity requests in any window.
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// refill adds the tokens earned since the last refill, up to capacity.
+func (b *tokenBucket) refill(now time.Time, capacity int) {
+	elapsed := now.Sub(b.last)
+	if elapsed > 0 {
+		b.tokens += float64(capacity) * elapsed.Seconds() / rateLimitWindow.Seconds()
+		b.last = now
+	}
+	if b.tokens > float64(capacity) {
+		b.tokens = float64(capacity)
+	}
+}
+
+// requestRateLimiter holds per-sender and global token buckets. It is
+// guarded by the RequestManager mutex.
+type requestRateLimiter struct {
+	perSender int // Requests per minute per sender; 0 disables limiting
+	global    int // Requests per minute across all senders; 0 disables the cap
+	senders   map[[32]byte]*tokenBucket
+	all       tokenBucket
+	lastPrune time.Time
+	dropped   uint64
+}
+
+// newBucket returns a full bucket for capacity.
+func newBucket(now time.Time, capacity int) *tokenBucket {
+	return &tokenBucket{tokens: float64(capacity), last: now}
+}
+
+// allowLocked reports whether a request from sender may be accepted at now,
+// taking a token from the sender's bucket and the global bucket if so.
+func (l *requestRateLimiter) allowLocked(sender [32]byte, now time.Time) bool {
+	if l.perSender <= 0 {
+		return true
+	}
+	l.pruneLocked(now)
+
+	bucket, ok := l.senders[sender]
+	if !ok {
+		bucket = newBucket(now, l.perSender)
+		l.senders[sender] = bucket
+	}
+	bucket.refill(now, l.perSender)
+	if l.global > 0 {
+		l.all.refill(now, l.global)
+	}
+
+	if bucket.tokens < 1 || (l.global > 0 && l.all.tokens < 1) {
+		l.dropped++
+		return false
+	}
+	bucket.tokens--
+	if l.global > 0 {
+		l.all.tokens--
+	}
+	return true
+}
+
+// pruneLocked forgets senders idle for a whole window, whose buckets have
+// refilled completely, so a flood of distinct keys cannot grow the map
+// without bound.
+func (l *requestRateLimiter) pruneLocked(now time.Time) {
+	if now.Sub(l.lastPrune) < rateLimitWindow {
+		return
+	}
+	for sender, bucket := range l.senders {
+		if now.Sub(bucket.last) >= rateLimitWindow {
+			delete(l.senders, sender)
+		}
+	}
+	l.lastPrune = now
+}
+
+// SetRateLimit limits AddRequest to maxPerMinute requests per sender public
+// key in any 60-second window, and to GlobalRateLimitFactor times that across
+// all senders unless SetGlobalRateLimit sets another cap. Requests over the
+// limit are dropped without invoking the handler. A maxPerMinute of 0, the
+// default, disables rate limiting.
+//
+//export ToxFriendRequestManagerSetRateLimit
+func (m *RequestManager) SetRateLimit(maxPerMinute int) {
+	if maxPerMinute < 0 {
+		maxPerMinute = 0
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	m.limiter.perSender = maxPerMinute
+	m.limiter.global = maxPerMinute * GlobalRateLimitFactor
+	m.limiter.senders = make(map[[32]byte]*tokenBucket)
+	m.limiter.all = *newBucket(now, m.limiter.global)
+	m.limiter.lastPrune = now
+}
+
+// SetGlobalRateLimit caps AddRequest at maxPerMinute requests across all
+// senders while a per-sender limit is set with SetRateLimit. A maxPerMinute
+// of 0 removes the global cap.
+//
+//export ToxFriendRequestManagerSetGlobalRateLimit
+func (m *RequestManager) SetGlobalRateLimit(maxPerMinute int) {
+	if maxPerMinute < 0 {
+		maxPerMinute = 0
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.limiter.global = maxPerMinute
+	m.limiter.all = *newBucket(m.now(), maxPerMinute)
+}
+
+// GetDroppedRequests returns the number of requests dropped by the rate limit.
+//
+//export ToxFriendRequestManagerGetDroppedRequests
+func (m *RequestManager) GetDroppedRequests() uint64 {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.limiter.dropped
+}
@@ -0,0 +1,106 @@
+package friend
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// newRateLimitTestManager returns a request manager on a mock clock with
+// the given per-sender limit.
+func newRateLimitTestManager(maxPerMinute int) (*RequestManager, *mockTimeProvider) {
+	clock := &mockTimeProvider{fixedTime: time.Unix(1700000000, 0)}
+	rm := NewRequestManagerWithTimeProvider(clock)
+	rm.SetRateLimit(maxPerMinute)
+	return rm, clock
+}
+
+func TestRateLimitDisabledByDefault(t *testing.T) {
+	rm := NewRequestManager()
+	for i := 0; i < 100; i++ {
+		if err := rm.AddRequest(&Request{SenderPublicKey: [32]byte{1}, Message: "hi"}); err != nil {
+			t.Fatalf("AddRequest %d: %v", i, err)
+		}
+	}
+	if rm.GetDroppedRequests() != 0 {
+		t.Errorf("Expected no dropped requests, got %d", rm.GetDroppedRequests())
+	}
+}
+
+func TestRateLimitPerSender(t *testing.T) {
+	rm, clock := newRateLimitTestManager(3)
+	calls := 0
+	rm.SetHandler(func(*Request) bool {
+		calls++
+		return false
+	})
+
+	sender := [32]byte{1}
+	for i := 0; i < 3; i++ {
+		if err := rm.AddRequest(&Request{SenderPublicKey: sender, Message: "hi"}); err != nil {
+			t.Fatalf("AddRequest %d: %v", i, err)
+		}
+	}
+	if err := rm.AddRequest(&Request{SenderPublicKey: sender, Message: "hi"}); !errors.Is(err, ErrRateLimitExceeded) {
+		t.Fatalf("Expected ErrRateLimitExceeded, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("Expected the handler to run once, got %d", calls)
+	}
+
+	// Other senders have their own buckets
+	if err := rm.AddRequest(&Request{SenderPublicKey: [32]byte{2}, Message: "hi"}); err != nil {
+		t.Fatalf("Other sender: %v", err)
+	}
+
+	// One token is earned every 20 seconds at 3 per minute
+	clock.fixedTime = clock.fixedTime.Add(20 * time.Second)
+	if err := rm.AddRequest(&Request{SenderPublicKey: sender, Message: "hi"}); err != nil {
+		t.Fatalf("After refill: %v", err)
+	}
+	if err := rm.AddRequest(&Request{SenderPublicKey: sender, Message: "hi"}); !errors.Is(err, ErrRateLimitExceeded) {
+		t.Fatalf("Expected ErrRateLimitExceeded, got %v", err)
+	}
+	if rm.GetDroppedRequests() != 2 {
+		t.Errorf("Expected 2 dropped requests, got %d", rm.GetDroppedRequests())
+	}
+}
+
+func TestRateLimitGlobal(t *testing.T) {
+	rm, clock := newRateLimitTestManager(2)
+	rm.SetGlobalRateLimit(3)
+
+	for i := byte(0); i < 3; i++ {
+		if err := rm.AddRequest(&Request{SenderPublicKey: [32]byte{i}, Message: "hi"}); err != nil {
+			t.Fatalf("AddRequest %d: %v", i, err)
+		}
+	}
+	if err := rm.AddRequest(&Request{SenderPublicKey: [32]byte{9}, Message: "hi"}); !errors.Is(err, ErrRateLimitExceeded) {
+		t.Fatalf("Expected the global cap to apply, got %v", err)
+	}
+	if rm.PendingCount() != 3 {
+		t.Errorf("Dropped requests must not be stored, got %d pending", rm.PendingCount())
+	}
+
+	clock.fixedTime = clock.fixedTime.Add(time.Minute)
+	if err := rm.AddRequest(&Request{SenderPublicKey: [32]byte{9}, Message: "hi"}); err != nil {
+		t.Fatalf("After refill: %v", err)
+	}
+}
+
+func TestRateLimitPrunesIdleSenders(t *testing.T) {
+	rm, clock := newRateLimitTestManager(1)
+	for i := byte(0); i < 5; i++ {
+		if err := rm.AddRequest(&Request{SenderPublicKey: [32]byte{i}, Message: "hi"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	clock.fixedTime = clock.fixedTime.Add(time.Minute)
+	if err := rm.AddRequest(&Request{SenderPublicKey: [32]byte{9}, Message: "hi"}); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(rm.limiter.senders); n != 1 {
+		t.Errorf("Expected idle senders to be pruned, %d tracked", n)
+	}
+}
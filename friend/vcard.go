@@ -0,0 +1,297 @@
+package friend
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/opd-ai/toxcore/crypto"
+	"github.com/sirupsen/logrus"
+)
+
+// vCard property names used for friend export.
+const (
+	vCardToxAddress = "X-TOX-ADDRESS"
+	// vCardMaxLineOctets is the RFC 6350 §3.2 line length before folding.
+	vCardMaxLineOctets = 75
+)
+
+var (
+	// ErrInvalidVCard indicates the data is not a well-formed vCard.
+	ErrInvalidVCard = errors.New("invalid vCard")
+
+	// ErrMissingToxAddress indicates a vCard has no usable X-TOX-ADDRESS property.
+	ErrMissingToxAddress = errors.New("vCard has no X-TOX-ADDRESS")
+)
+
+// ToVCard encodes the friend as a vCard 4.0 (RFC 6350) document.
+//
+// The document carries FN (name), NOTE (status message), X-TOX-ADDRESS and,
+// when Avatar is set, PHOTO as a data URI. Friends are tracked by public key
+// only, so X-TOX-ADDRESS holds the 64-character hex public key rather than a
+// full Tox ID with nospam.
+//
+//export ToxFriendInfoToVCard
+func (f *FriendInfo) ToVCard() ([]byte, error) {
+	f.mu.RLock()
+	name := f.Name
+	note := f.StatusMessage
+	publicKey := f.PublicKey
+	avatar := append([]byte(nil), f.Avatar...)
+	f.mu.RUnlock()
+
+	if name == "" {
+		// FN is mandatory in vCard 4.0; fall back to a short key prefix
+		name = hex.EncodeToString(publicKey[:8])
+	}
+
+	var buf bytes.Buffer
+	writeVCardLine(&buf, "BEGIN:VCARD")
+	writeVCardLine(&buf, "VERSION:4.0")
+	writeVCardLine(&buf, "FN:"+escapeVCardText(name))
+	if note != "" {
+		writeVCardLine(&buf, "NOTE:"+escapeVCardText(note))
+	}
+	writeVCardLine(&buf, vCardToxAddress+":"+hex.EncodeToString(publicKey[:]))
+	if len(avatar) > 0 {
+		mediaType := http.DetectContentType(avatar)
+		writeVCardLine(&buf, "PHOTO:data:"+mediaType+";base64,"+base64.StdEncoding.EncodeToString(avatar))
+	}
+	writeVCardLine(&buf, "END:VCARD")
+
+	logrus.WithFields(logrus.Fields{
+		"function":   "FriendInfo.ToVCard",
+		"public_key": fmt.Sprintf("%x", publicKey[:8]),
+		"size":       buf.Len(),
+	}).Debug("Friend exported as vCard")
+
+	return buf.Bytes(), nil
+}
+
+// FromVCard parses a single vCard and constructs a FriendInfo from its
+// X-TOX-ADDRESS, FN, NOTE and PHOTO properties. X-TOX-ADDRESS may be either
+// a 64-character public key or a 76-character Tox ID, whose checksum is
+// verified.
+//
+//export ToxFriendInfoFromVCard
+func FromVCard(data []byte) (*FriendInfo, error) {
+	cards, err := ParseVCards(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(cards) != 1 {
+		return nil, fmt.Errorf("%w: expected 1 vCard, found %d", ErrInvalidVCard, len(cards))
+	}
+	return cards[0], nil
+}
+
+// ParseVCards parses a vCard collection, such as the output of
+// Tox.ExportFriendList, into one FriendInfo per card.
+func ParseVCards(data []byte) ([]*FriendInfo, error) {
+	lines, err := unfoldVCardLines(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		cards   []*FriendInfo
+		current map[string]string
+	)
+	for _, line := range lines {
+		if line == "" {
+			continue
+		}
+		name, value, ok := splitVCardProperty(line)
+		if !ok {
+			return nil, fmt.Errorf("%w: malformed line %q", ErrInvalidVCard, line)
+		}
+
+		switch {
+		case name == "BEGIN" && strings.EqualFold(value, "VCARD"):
+			if current != nil {
+				return nil, fmt.Errorf("%w: nested BEGIN:VCARD", ErrInvalidVCard)
+			}
+			current = make(map[string]string)
+		case name == "END" && strings.EqualFold(value, "VCARD"):
+			if current == nil {
+				return nil, fmt.Errorf("%w: END:VCARD without BEGIN", ErrInvalidVCard)
+			}
+			f, err := friendFromVCardProperties(current)
+			if err != nil {
+				return nil, err
+			}
+			cards = append(cards, f)
+			current = nil
+		case current == nil:
+			return nil, fmt.Errorf("%w: property %s outside vCard", ErrInvalidVCard, name)
+		default:
+			if _, seen := current[name]; !seen {
+				current[name] = value
+			}
+		}
+	}
+
+	if current != nil {
+		return nil, fmt.Errorf("%w: missing END:VCARD", ErrInvalidVCard)
+	}
+	if len(cards) == 0 {
+		return nil, fmt.Errorf("%w: no vCard found", ErrInvalidVCard)
+	}
+	return cards, nil
+}
+
+// friendFromVCardProperties builds a FriendInfo from the properties of one card.
+func friendFromVCardProperties(props map[string]string) (*FriendInfo, error) {
+	publicKey, err := parseVCardToxAddress(props[vCardToxAddress])
+	if err != nil {
+		return nil, err
+	}
+
+	f := New(publicKey)
+	if fn, ok := props["FN"]; ok {
+		if err := f.SetName(unescapeVCardText(fn)); err != nil {
+			return nil, err
+		}
+	}
+	if note, ok := props["NOTE"]; ok {
+		if err := f.SetStatusMessage(unescapeVCardText(note)); err != nil {
+			return nil, err
+		}
+	}
+	if photo, ok := props["PHOTO"]; ok {
+		avatar, err := decodeVCardPhoto(photo)
+		if err != nil {
+			return nil, err
+		}
+		f.Avatar = avatar
+	}
+	return f, nil
+}
+
+// parseVCardToxAddress decodes a public key in hex or a Tox ID in hex or
+// tox: URI form.
+func parseVCardToxAddress(value string) ([32]byte, error) {
+	var publicKey [32]byte
+	value = strings.TrimSpace(value)
+
+	switch {
+	case len(value) == 0:
+		return publicKey, ErrMissingToxAddress
+	case len(value) == crypto.ToxIDHexLength || crypto.HasToxURIScheme(value):
+		id, err := crypto.ParseToxAddress(value)
+		if err != nil {
+			return publicKey, fmt.Errorf("%w: %v", ErrMissingToxAddress, err)
+		}
+		return id.PublicKey, nil
+	case len(value) == hex.EncodedLen(len(publicKey)):
+		decoded, err := hex.DecodeString(value)
+		if err != nil {
+			return publicKey, fmt.Errorf("%w: %v", ErrMissingToxAddress, err)
+		}
+		copy(publicKey[:], decoded)
+		return publicKey, nil
+	default:
+		return publicKey, fmt.Errorf("%w: unexpected length %d", ErrMissingToxAddress, len(value))
+	}
+}
+
+// decodeVCardPhoto decodes a base64 data URI PHOTO value.
+func decodeVCardPhoto(value string) ([]byte, error) {
+	const prefix = "data:"
+	comma := strings.IndexByte(value, ',')
+	if !strings.HasPrefix(strings.ToLower(value), prefix) || comma < 0 ||
+		!strings.HasSuffix(strings.ToLower(value[:comma]), ";base64") {
+		return nil, fmt.Errorf("%w: PHOTO must be a base64 data URI", ErrInvalidVCard)
+	}
+	avatar, err := base64.StdEncoding.DecodeString(value[comma+1:])
+	if err != nil {
+		return nil, fmt.Errorf("%w: PHOTO: %v", ErrInvalidVCard, err)
+	}
+	return avatar, nil
+}
+
+// splitVCardProperty returns the upper-cased property name (without
+// parameters or group) and the raw value of a content line.
+func splitVCardProperty(line string) (string, string, bool) {
+	colon := strings.IndexByte(line, ':')
+	if colon <= 0 {
+		return "", "", false
+	}
+	name := line[:colon]
+	if semi := strings.IndexByte(name, ';'); semi >= 0 {
+		name = name[:semi]
+	}
+	if dot := strings.LastIndexByte(name, '.'); dot >= 0 {
+		name = name[dot+1:]
+	}
+	return strings.ToUpper(name), line[colon+1:], true
+}
+
+// unfoldVCardLines splits data into logical content lines, joining folded
+// continuation lines (RFC 6350 §3.2).
+func unfoldVCardLines(data []byte) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 4096), len(data)+1)
+	for scanner.Scan() {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		if len(line) > 0 && (line[0] == ' ' || line[0] == '\t') && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidVCard, err)
+	}
+	return lines, nil
+}
+
+// writeVCardLine writes a content line terminated by CRLF, folding it at
+// vCardMaxLineOctets without splitting UTF-8 sequences.
+func writeVCardLine(buf *bytes.Buffer, line string) {
+	limit := vCardMaxLineOctets
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		buf.WriteString(line[:cut])
+		buf.WriteString("\r\n ")
+		line = line[cut:]
+		// Continuation lines start with a space that counts toward the limit
+		limit = vCardMaxLineOctets - 1
+	}
+	buf.WriteString(line)
+	buf.WriteString("\r\n")
+}
+
+// escapeVCardText escapes a TEXT value per RFC 6350 §3.4.
+func escapeVCardText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ",", `\,`, ";", `\;`, "\r\n", `\n`, "\n", `\n`).Replace(s)
+}
+
+// unescapeVCardText reverses escapeVCardText.
+func unescapeVCardText(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n', 'N':
+			b.WriteByte('\n')
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,104 @@
+package friend
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/opd-ai/toxcore/crypto"
+)
+
+func TestVCardRoundTrip(t *testing.T) {
+	var publicKey [32]byte
+	for i := range publicKey {
+		publicKey[i] = byte(i + 1)
+	}
+
+	f := New(publicKey)
+	if err := f.SetName("Alice; Smith, Jr."); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.SetStatusMessage(strings.Repeat("long status line\n", 10)); err != nil {
+		t.Fatal(err)
+	}
+	f.Avatar = []byte("\x89PNG\r\n\x1a\nfake image data")
+
+	card, err := f.ToVCard()
+	if err != nil {
+		t.Fatalf("ToVCard failed: %v", err)
+	}
+
+	for _, line := range strings.Split(string(card), "\r\n") {
+		if len(line) > vCardMaxLineOctets {
+			t.Errorf("line exceeds %d octets: %q", vCardMaxLineOctets, line)
+		}
+	}
+	for _, want := range []string{"BEGIN:VCARD\r\n", "VERSION:4.0\r\n", "FN:Alice\\; Smith\\, Jr.\r\n", "PHOTO:data:image/png;base64,"} {
+		if !bytes.Contains(card, []byte(want)) {
+			t.Errorf("vCard missing %q:\n%s", want, card)
+		}
+	}
+
+	parsed, err := FromVCard(card)
+	if err != nil {
+		t.Fatalf("FromVCard failed: %v", err)
+	}
+	if parsed.PublicKey != publicKey {
+		t.Error("public key mismatch")
+	}
+	if parsed.GetName() != f.GetName() {
+		t.Errorf("name = %q, want %q", parsed.GetName(), f.GetName())
+	}
+	if parsed.GetStatusMessage() != f.GetStatusMessage() {
+		t.Errorf("status message = %q, want %q", parsed.GetStatusMessage(), f.GetStatusMessage())
+	}
+	if !bytes.Equal(parsed.Avatar, f.Avatar) {
+		t.Error("avatar mismatch")
+	}
+}
+
+func TestFromVCardToxID(t *testing.T) {
+	var publicKey [32]byte
+	publicKey[0] = 0xAB
+	id := crypto.NewToxID(publicKey, [4]byte{1, 2, 3, 4})
+
+	card := "BEGIN:VCARD\r\nVERSION:4.0\r\nFN:Bob\r\nX-TOX-ADDRESS:" + strings.ToUpper(id.String()) + "\r\nEND:VCARD\r\n"
+	f, err := FromVCard([]byte(card))
+	if err != nil {
+		t.Fatalf("FromVCard failed: %v", err)
+	}
+	if f.PublicKey != publicKey || f.GetName() != "Bob" {
+		t.Errorf("unexpected friend: %x %q", f.PublicKey[:4], f.GetName())
+	}
+
+	uri := strings.Replace(card, "X-TOX-ADDRESS:", "X-TOX-ADDRESS:tox:", 1)
+	if f, err := FromVCard([]byte(uri)); err != nil || f.PublicKey != publicKey {
+		t.Errorf("FromVCard with tox: URI failed: %v", err)
+	}
+
+	bad := strings.Replace(card, strings.ToUpper(id.String()[72:]), "0000", 1)
+	if _, err := FromVCard([]byte(bad)); !errors.Is(err, ErrMissingToxAddress) {
+		t.Errorf("expected ErrMissingToxAddress for bad checksum, got %v", err)
+	}
+}
+
+func TestParseVCardsErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want error
+	}{
+		{"empty", "", ErrInvalidVCard},
+		{"unterminated", "BEGIN:VCARD\r\nFN:x\r\n", ErrInvalidVCard},
+		{"no address", "BEGIN:VCARD\r\nFN:x\r\nEND:VCARD\r\n", ErrMissingToxAddress},
+		{"outside card", "FN:x\r\n", ErrInvalidVCard},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseVCards([]byte(tt.data)); !errors.Is(err, tt.want) {
+				t.Errorf("ParseVCards() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,368 @@
+package group
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"time"
+
+	"github.com/opd-ai/toxcore/transport"
+	"github.com/sirupsen/logrus"
+)
+
+// JoinRejectCode is the reason code carried in a peer_join_rejected message.
+type JoinRejectCode uint8
+
+const (
+	// JoinRejectBanned rejects a peer whose public key is on the ban list.
+	JoinRejectBanned JoinRejectCode = 1
+)
+
+// joinRejectedType is the BroadcastMessage type sent to a peer whose
+// announcement was rejected.
+const joinRejectedType = "peer_join_rejected"
+
+var (
+	// ErrPermissionDenied is returned when the local peer's role does not
+	// allow a ban list change. It is ErrInsufficientPrivileges, so either
+	// can be matched with errors.Is.
+	ErrPermissionDenied = ErrInsufficientPrivileges
+	// ErrBanned is returned for a public key on the group's ban list.
+	ErrBanned = errors.New("banned from group")
+	// ErrNotBanned is returned by UnbanPeer for a public key without a ban.
+	ErrNotBanned = errors.New("peer is not banned")
+)
+
+// BannedPeer is a single ban list entry keyed by the peer's public key.
+// A zero ExpiresAt marks a permanent ban. BannedBy is the peer ID of the
+// moderator who issued the ban.
+type BannedPeer struct {
+	PublicKey [32]byte
+	BannedAt  time.Time
+	BannedBy  uint32
+	Reason    string
+	ExpiresAt time.Time
+}
+
+// IsPermanent reports whether the ban has no expiry.
+func (b *BannedPeer) IsPermanent() bool {
+	return b.ExpiresAt.IsZero()
+}
+
+// bannedPeerJSON is the on-disk representation of a BannedPeer.
+// Public keys are hex encoded to keep the file human-readable.
+type bannedPeerJSON struct {
+	PublicKey string    `json:"public_key"`
+	BannedAt  time.Time `json:"banned_at,omitempty"`
+	BannedBy  uint32    `json:"banned_by,omitempty"`
+	Reason    string    `json:"reason,omitempty"`
+	ExpiresAt time.Time `json:"expires_at,omitempty"`
+}
+
+// MarshalJSON encodes the entry with a hex public key.
+func (b BannedPeer) MarshalJSON() ([]byte, error) {
+	return json.Marshal(bannedPeerJSON{
+		PublicKey: hex.EncodeToString(b.PublicKey[:]),
+		BannedAt:  b.BannedAt,
+		BannedBy:  b.BannedBy,
+		Reason:    b.Reason,
+		ExpiresAt: b.ExpiresAt,
+	})
+}
+
+// UnmarshalJSON decodes an entry written by MarshalJSON.
+func (b *BannedPeer) UnmarshalJSON(data []byte) error {
+	var raw bannedPeerJSON
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	key, err := hex.DecodeString(raw.PublicKey)
+	if err != nil || len(key) != 32 {
+		return fmt.Errorf("invalid public key in ban list: %q", raw.PublicKey)
+	}
+	*b = BannedPeer{BannedAt: raw.BannedAt, BannedBy: raw.BannedBy, Reason: raw.Reason, ExpiresAt: raw.ExpiresAt}
+	copy(b.PublicKey[:], key)
+	return nil
+}
+
+// banListFile is the on-disk ban list format.
+type banListFile struct {
+	GroupID uint32       `json:"group_id"`
+	Bans    []BannedPeer `json:"bans"`
+}
+
+// PeerJoinRejectedData tells an announcing peer why it was not admitted.
+type PeerJoinRejectedData struct {
+	PeerID uint32         `json:"peer_id"`
+	Code   JoinRejectCode `json:"code"`
+}
+
+// ToMap converts PeerJoinRejectedData to map representation.
+func (d PeerJoinRejectedData) ToMap() map[string]interface{} {
+	return map[string]interface{}{
+		"peer_id": d.PeerID,
+		"code":    d.Code,
+	}
+}
+
+// BanPublicKey bans a public key from the group.
+// A duration of zero creates a permanent ban; otherwise the ban expires after duration.
+// Requires Moderator role or higher.
+func (g *Chat) BanPublicKey(publicKey [32]byte, duration time.Duration) error {
+	if duration < 0 {
+		return errors.New("ban duration cannot be negative")
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if _, err := g.requireSelfRoleLocked(RoleModerator, "ban"); err != nil {
+		return err
+	}
+
+	now := g.getTimeProvider().Now()
+	entry := &BannedPeer{PublicKey: publicKey, BannedAt: now, BannedBy: g.SelfPeerID}
+	if duration > 0 {
+		entry.ExpiresAt = now.Add(duration)
+	}
+	g.setBanLocked(entry)
+	return nil
+}
+
+// BanPeer permanently bans a connected peer's public key and kicks it from
+// the group. The peer cannot rejoin until UnbanPeer is called. Requires
+// Moderator role or higher and a role above the target's; otherwise the
+// error wraps ErrPermissionDenied.
+func (g *Chat) BanPeer(peerID uint32, reason string) error {
+	g.mu.Lock()
+	target, self, err := g.validatePeerPermission(peerID, RoleModerator, "ban")
+	if err != nil {
+		g.mu.Unlock()
+		return err
+	}
+	if target.PublicKey == ([32]byte{}) {
+		g.mu.Unlock()
+		return fmt.Errorf("peer %d has no known public key", peerID)
+	}
+	g.setBanLocked(&BannedPeer{
+		PublicKey: target.PublicKey,
+		BannedAt:  g.getTimeProvider().Now(),
+		BannedBy:  self.ID,
+		Reason:    reason,
+	})
+	g.mu.Unlock()
+
+	if err := g.KickPeer(peerID); err != nil {
+		return fmt.Errorf("banned peer %d but failed to disconnect it: %w", peerID, err)
+	}
+	return nil
+}
+
+// UnbanPeer removes a public key from the ban list. It returns ErrNotBanned
+// if the key has no active ban. Requires Moderator role or higher.
+func (g *Chat) UnbanPeer(publicKey [32]byte) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if _, err := g.requireSelfRoleLocked(RoleModerator, "unban"); err != nil {
+		return err
+	}
+	if banned, _ := g.checkBanLocked(publicKey); !banned {
+		return ErrNotBanned
+	}
+	delete(g.bans, publicKey)
+	return nil
+}
+
+// setBanLocked stores a ban entry. Caller must hold g.mu.
+func (g *Chat) setBanLocked(entry *BannedPeer) {
+	if g.bans == nil {
+		g.bans = make(map[[32]byte]*BannedPeer)
+	}
+	g.bans[entry.PublicKey] = entry
+}
+
+// CheckBan reports whether a public key is currently banned and when the ban
+// expires. Permanent bans return a zero expiry. Expired bans are pruned.
+func (g *Chat) CheckBan(peerPubKey [32]byte) (banned bool, expiresAt time.Time) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.checkBanLocked(peerPubKey)
+}
+
+// checkBanLocked is CheckBan for callers already holding g.mu.
+func (g *Chat) checkBanLocked(peerPubKey [32]byte) (bool, time.Time) {
+	entry, exists := g.bans[peerPubKey]
+	if !exists {
+		return false, time.Time{}
+	}
+	if !entry.IsPermanent() && !g.getTimeProvider().Now().Before(entry.ExpiresAt) {
+		delete(g.bans, peerPubKey)
+		return false, time.Time{}
+	}
+	return true, entry.ExpiresAt
+}
+
+// GetBanList returns a copy of all active bans.
+func (g *Chat) GetBanList() []BannedPeer {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.pruneExpiredBansLocked()
+	bans := make([]BannedPeer, 0, len(g.bans))
+	for _, entry := range g.bans {
+		bans = append(bans, *entry)
+	}
+	return bans
+}
+
+// pruneExpiredBansLocked drops temporary bans whose expiry has passed.
+func (g *Chat) pruneExpiredBansLocked() {
+	now := g.getTimeProvider().Now()
+	for key, entry := range g.bans {
+		if !entry.IsPermanent() && !now.Before(entry.ExpiresAt) {
+			delete(g.bans, key)
+		}
+	}
+}
+
+// SaveBanList writes the active ban list to path as JSON.
+// The file is written atomically via a temporary file and rename.
+func (g *Chat) SaveBanList(path string) error {
+	g.mu.Lock()
+	g.pruneExpiredBansLocked()
+	file := banListFile{GroupID: g.ID, Bans: make([]BannedPeer, 0, len(g.bans))}
+	for _, entry := range g.bans {
+		file.Bans = append(file.Bans, *entry)
+	}
+	g.mu.Unlock()
+
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to serialize ban list: %w", err)
+	}
+
+	tmpFile := path + ".tmp"
+	if err := os.WriteFile(tmpFile, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write temporary ban list: %w", err)
+	}
+	if err := os.Rename(tmpFile, path); err != nil {
+		return fmt.Errorf("failed to rename ban list: %w", err)
+	}
+	return nil
+}
+
+// LoadBanList reads a ban list previously written by SaveBanList and merges
+// its active entries into the group. A missing file is not an error.
+func (g *Chat) LoadBanList(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read ban list: %w", err)
+	}
+
+	var file banListFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("failed to parse ban list: %w", err)
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if file.GroupID != 0 && file.GroupID != g.ID {
+		return fmt.Errorf("ban list belongs to group %d, not %d", file.GroupID, g.ID)
+	}
+	for i := range file.Bans {
+		g.setBanLocked(&file.Bans[i])
+	}
+	g.pruneExpiredBansLocked()
+	return nil
+}
+
+// SetBanListPath enables ban list persistence at path. Any existing file is
+// loaded immediately, and the list is saved again when the group is left.
+func (g *Chat) SetBanListPath(path string) error {
+	if err := g.LoadBanList(path); err != nil {
+		return err
+	}
+	g.mu.Lock()
+	g.banListPath = path
+	g.mu.Unlock()
+	return nil
+}
+
+// persistBanList saves the ban list if a path was configured.
+// Failures are logged because callers are on cleanup paths.
+func (g *Chat) persistBanList() {
+	g.mu.RLock()
+	path := g.banListPath
+	g.mu.RUnlock()
+	if path == "" {
+		return
+	}
+	if err := g.SaveBanList(path); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"function": "persistBanList",
+			"group_id": g.ID,
+			"path":     path,
+			"error":    err.Error(),
+		}).Warn("Failed to persist group ban list")
+	}
+}
+
+// sendJoinRejection tells a banned peer that its announcement was rejected.
+// The peer is not a member, so the message is sent straight to addr. It is
+// unsequenced: taking a broadcast sequence number would leave a gap in the
+// members' reorder buffers.
+func (g *Chat) sendJoinRejection(peerID uint32, addr net.Addr) {
+	g.mu.RLock()
+	tr := g.transport
+	msg := &BroadcastMessage{
+		Type:      joinRejectedType,
+		ChatID:    g.ID,
+		SenderID:  g.SelfPeerID,
+		Timestamp: g.getTimeProvider().Now(),
+		Data:      PeerJoinRejectedData{PeerID: peerID, Code: JoinRejectBanned}.ToMap(),
+	}
+	g.mu.RUnlock()
+	if tr == nil || addr == nil {
+		return
+	}
+
+	data, err := marshalBroadcastMessage(msg)
+	if err != nil {
+		return
+	}
+	if err := tr.Send(&transport.Packet{PacketType: transport.PacketGroupBroadcast, Data: data}, addr); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"function": "sendJoinRejection",
+			"group_id": g.ID,
+			"peer_id":  peerID,
+			"error":    err.Error(),
+		}).Debug("Failed to send join rejection to banned peer")
+	}
+}
+
+// JoinRejection returns an error wrapping ErrBanned if msg, as returned by
+// HandleBroadcastPacket, rejects the local peer's announcement because it
+// is banned, and nil otherwise.
+func (g *Chat) JoinRejection(msg *BroadcastMessage) error {
+	if msg == nil || msg.Type != joinRejectedType {
+		return nil
+	}
+	peerID, _ := msg.Data["peer_id"].(float64)
+	code, _ := msg.Data["code"].(float64)
+
+	g.mu.RLock()
+	selfID := g.SelfPeerID
+	g.mu.RUnlock()
+	if uint32(peerID) != selfID || JoinRejectCode(code) != JoinRejectBanned {
+		return nil
+	}
+	return fmt.Errorf("%w: group %d rejected peer %d", ErrBanned, msg.ChatID, selfID)
+}
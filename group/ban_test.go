@@ -0,0 +1,242 @@
+package group
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/opd-ai/toxcore/transport"
+)
+
+func newBanTestChat(role Role) (*Chat, *fakeTimeProvider) {
+	tp := newFakeTimeProvider()
+	chat := &Chat{
+		ID:           42,
+		SelfPeerID:   1,
+		Peers:        map[uint32]*Peer{1: {ID: 1, Role: role}},
+		timeProvider: tp,
+	}
+	return chat, tp
+}
+
+// TestBanPublicKeyPermanentAndTemporary verifies both ban kinds and expiry
+func TestBanPublicKeyPermanentAndTemporary(t *testing.T) {
+	chat, tp := newBanTestChat(RoleFounder)
+	permanent := [32]byte{1}
+	temporary := [32]byte{2}
+
+	if err := chat.BanPublicKey(permanent, 0); err != nil {
+		t.Fatalf("permanent ban failed: %v", err)
+	}
+	if err := chat.BanPublicKey(temporary, time.Hour); err != nil {
+		t.Fatalf("temporary ban failed: %v", err)
+	}
+
+	banned, expires := chat.CheckBan(permanent)
+	if !banned || !expires.IsZero() {
+		t.Errorf("expected permanent ban with zero expiry, got banned=%v expires=%v", banned, expires)
+	}
+	banned, expires = chat.CheckBan(temporary)
+	if !banned || expires.IsZero() {
+		t.Errorf("expected temporary ban with expiry, got banned=%v expires=%v", banned, expires)
+	}
+
+	tp.Advance(2 * time.Hour)
+	if banned, _ := chat.CheckBan(temporary); banned {
+		t.Error("temporary ban should have expired")
+	}
+	if banned, _ := chat.CheckBan(permanent); !banned {
+		t.Error("permanent ban should not expire")
+	}
+}
+
+// TestBanPublicKeyRequiresModerator verifies role enforcement
+func TestBanPublicKeyRequiresModerator(t *testing.T) {
+	chat, _ := newBanTestChat(RoleUser)
+	if err := chat.BanPublicKey([32]byte{1}, 0); err == nil {
+		t.Error("expected regular user to be denied")
+	}
+}
+
+// TestSaveLoadBanList verifies bans survive a save/load round trip
+func TestSaveLoadBanList(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bans.json")
+
+	chat, _ := newBanTestChat(RoleFounder)
+	_ = chat.BanPublicKey([32]byte{1}, 0)
+	_ = chat.BanPublicKey([32]byte{2}, time.Hour)
+	if err := chat.SaveBanList(path); err != nil {
+		t.Fatalf("SaveBanList failed: %v", err)
+	}
+
+	restarted, _ := newBanTestChat(RoleFounder)
+	if err := restarted.LoadBanList(path); err != nil {
+		t.Fatalf("LoadBanList failed: %v", err)
+	}
+	if len(restarted.GetBanList()) != 2 {
+		t.Fatalf("expected 2 bans after reload, got %d", len(restarted.GetBanList()))
+	}
+	if _, expires := restarted.CheckBan([32]byte{1}); !expires.IsZero() {
+		t.Error("permanent ban lost its permanence after reload")
+	}
+
+	other := &Chat{ID: 7, Peers: map[uint32]*Peer{}}
+	if err := other.LoadBanList(path); err == nil {
+		t.Error("expected error loading ban list from another group")
+	}
+}
+
+// TestLoadBanListMissingFile verifies that a missing file is not an error
+func TestLoadBanListMissingFile(t *testing.T) {
+	chat, _ := newBanTestChat(RoleFounder)
+	if err := chat.LoadBanList(filepath.Join(t.TempDir(), "missing.json")); err != nil {
+		t.Errorf("expected nil for missing file, got %v", err)
+	}
+}
+
+// TestLeavePersistsBanList verifies Leave saves the ban list when a path is set
+func TestLeavePersistsBanList(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bans.json")
+	chat, _ := newBanTestChat(RoleModerator)
+	if err := chat.SetBanListPath(path); err != nil {
+		t.Fatalf("SetBanListPath failed: %v", err)
+	}
+	_ = chat.BanPublicKey([32]byte{9}, 0)
+
+	if err := chat.Leave("bye"); err != nil {
+		t.Fatalf("Leave failed: %v", err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("ban list not written on Leave: %v", err)
+	}
+}
+
+// TestHandlePeerAnnounceRejectsBanned verifies banned peers cannot rejoin
+func TestHandlePeerAnnounceRejectsBanned(t *testing.T) {
+	chat, _ := newBanTestChat(RoleFounder)
+	bannedKey := [32]byte{5}
+	_ = chat.BanPublicKey(bannedKey, 0)
+
+	if chat.HandlePeerAnnounce(PeerAnnounceData{PeerID: 50, PublicKey: bannedKey, Connection: 2}, nil) {
+		t.Error("banned peer should not be admitted")
+	}
+	if _, exists := chat.Peers[50]; exists {
+		t.Error("banned peer was added to the peer list")
+	}
+	if !chat.HandlePeerAnnounce(PeerAnnounceData{PeerID: 51, PublicKey: [32]byte{6}, Connection: 2}, nil) {
+		t.Error("unbanned peer should be admitted")
+	}
+}
+
+// TestBanPeerKicksAndRejectsRejoin verifies BanPeer disconnects the peer and
+// that its next announcement is answered with JoinRejectBanned
+func TestBanPeerKicksAndRejectsRejoin(t *testing.T) {
+	trans := &mockTransport{}
+	chat, _ := newBanTestChat(RoleModerator)
+	chat.transport = trans
+	peerKey := [32]byte{7}
+	peerAddr := &mockAddr{address: "10.0.0.7:33445"}
+	chat.Peers[7] = &Peer{ID: 7, Role: RoleUser, PublicKey: peerKey, Connection: 2, Address: peerAddr}
+
+	if err := chat.BanPeer(7, "spam"); err != nil {
+		t.Fatalf("BanPeer failed: %v", err)
+	}
+	if _, exists := chat.Peers[7]; exists {
+		t.Error("banned peer is still in the peer list")
+	}
+	bans := chat.GetBanList()
+	if len(bans) != 1 || bans[0].PublicKey != peerKey || bans[0].BannedBy != 1 || bans[0].Reason != "spam" || bans[0].BannedAt.IsZero() {
+		t.Errorf("unexpected ban list %+v", bans)
+	}
+
+	sequence := chat.sendSequence.Load()
+	if chat.HandlePeerAnnounce(PeerAnnounceData{PeerID: 7, PublicKey: peerKey, Connection: 2}, peerAddr) {
+		t.Fatal("banned peer should not be admitted")
+	}
+	if chat.sendSequence.Load() != sequence {
+		t.Error("the rejection must not consume a broadcast sequence number")
+	}
+	calls := trans.getSendCalls()
+	last := calls[len(calls)-1]
+	if last.addr.String() != peerAddr.String() || last.packet.PacketType != transport.PacketGroupBroadcast {
+		t.Fatalf("expected a rejection sent to %s, got %v to %s", peerAddr, last.packet.PacketType, last.addr)
+	}
+
+	banned := &Chat{ID: chat.ID, SelfPeerID: 7}
+	msgs, err := banned.HandleBroadcastPacket(last.packet.Data)
+	if err != nil || len(msgs) != 1 || msgs[0].SequenceNumber != 0 {
+		t.Fatalf("HandleBroadcastPacket returned %d messages, err %v", len(msgs), err)
+	}
+	if err := banned.JoinRejection(msgs[0]); !errors.Is(err, ErrBanned) {
+		t.Errorf("expected ErrBanned, got %v", err)
+	}
+	if err := (&Chat{ID: chat.ID, SelfPeerID: 8}).JoinRejection(msgs[0]); err != nil {
+		t.Errorf("expected no rejection for another peer, got %v", err)
+	}
+}
+
+// TestBanPeerRequiresModerator verifies role checks inside BanPeer and UnbanPeer
+func TestBanPeerRequiresModerator(t *testing.T) {
+	chat, _ := newBanTestChat(RoleTrustedUser)
+	chat.Peers[7] = &Peer{ID: 7, Role: RoleUser, PublicKey: [32]byte{7}}
+
+	if err := chat.BanPeer(7, "spam"); !errors.Is(err, ErrPermissionDenied) {
+		t.Errorf("expected ErrPermissionDenied, got %v", err)
+	}
+	if err := chat.UnbanPeer([32]byte{7}); !errors.Is(err, ErrPermissionDenied) {
+		t.Errorf("expected ErrPermissionDenied from UnbanPeer, got %v", err)
+	}
+
+	mod, _ := newBanTestChat(RoleModerator)
+	mod.Peers[2] = &Peer{ID: 2, Role: RoleFounder, PublicKey: [32]byte{2}}
+	if err := mod.BanPeer(2, ""); !errors.Is(err, ErrPermissionDenied) {
+		t.Errorf("expected a moderator to be denied banning the founder, got %v", err)
+	}
+}
+
+// TestUnbanPeer verifies an unbanned key can rejoin
+func TestUnbanPeer(t *testing.T) {
+	chat, _ := newBanTestChat(RoleFounder)
+	key := [32]byte{9}
+	_ = chat.BanPublicKey(key, 0)
+
+	if err := chat.UnbanPeer(key); err != nil {
+		t.Fatalf("UnbanPeer failed: %v", err)
+	}
+	if err := chat.UnbanPeer(key); !errors.Is(err, ErrNotBanned) {
+		t.Errorf("expected ErrNotBanned, got %v", err)
+	}
+	if !chat.HandlePeerAnnounce(PeerAnnounceData{PeerID: 9, PublicKey: key, Connection: 2}, nil) {
+		t.Error("unbanned peer should be admitted")
+	}
+}
+
+// TestBannedPeerJSON verifies ban entries round-trip through encoding/json
+func TestBannedPeerJSON(t *testing.T) {
+	entry := BannedPeer{
+		PublicKey: [32]byte{0xab, 0xcd},
+		BannedAt:  time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		BannedBy:  3,
+		Reason:    "flooding",
+	}
+	data, err := json.Marshal([]BannedPeer{entry})
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	if !strings.Contains(string(data), `"public_key":"abcd00`) {
+		t.Errorf("expected a hex public key, got %s", data)
+	}
+
+	var decoded []BannedPeer
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if len(decoded) != 1 || decoded[0].PublicKey != entry.PublicKey || !decoded[0].BannedAt.Equal(entry.BannedAt) ||
+		decoded[0].BannedBy != 3 || decoded[0].Reason != "flooding" || !decoded[0].IsPermanent() {
+		t.Errorf("round trip mismatch: %+v", decoded)
+	}
+}
@@ -0,0 +1,566 @@
+package group
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/opd-ai/toxcore/crypto"
+	"github.com/opd-ai/toxcore/transport"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/curve25519"
+)
+
+// channelMessageType is the BroadcastMessage type of private channel messages.
+const channelMessageType = "channel_message"
+
+var (
+	// ErrChannelNotFound is returned for an unknown private channel ID.
+	ErrChannelNotFound = errors.New("private channel not found")
+	// ErrNotChannelMember is returned when the local peer is not in a
+	// private channel's access list.
+	ErrNotChannelMember = errors.New("not a member of this private channel")
+	// ErrChannelEncryptionUnavailable is returned when the group was created
+	// without a key pair, so channel keys cannot be encrypted.
+	ErrChannelEncryptionUnavailable = errors.New("private channels require a group key pair")
+)
+
+// privateChannel is the local state of a private channel. Every member holds
+// the same session key; the owner distributes a new one when a member leaves.
+type privateChannel struct {
+	id      uint32
+	ownerID uint32
+	keyID   uint32
+	key     [32]byte
+	members map[uint32]bool
+}
+
+// memberList returns the channel members sorted by peer ID.
+func (ch *privateChannel) memberList() []uint32 {
+	members := make([]uint32, 0, len(ch.members))
+	for id := range ch.members {
+		members = append(members, id)
+	}
+	sort.Slice(members, func(i, j int) bool { return members[i] < members[j] })
+	return members
+}
+
+// ChannelKeyDistribution carries a private channel session key encrypted for
+// one member. It is sent directly to that member in a PacketGroupChannelKey
+// packet. The key is sealed with XChaCha20-Poly1305 under a key derived from
+// static and ephemeral Curve25519 DH, as for sender key distributions.
+type ChannelKeyDistribution struct {
+	ChatID             uint32   `json:"chat_id"`
+	ChannelID          uint32   `json:"channel_id"`
+	KeyID              uint32   `json:"key_id"`
+	OwnerID            uint32   `json:"owner_id"`
+	Members            []uint32 `json:"members"`
+	SenderPublicKey    [32]byte `json:"sender_public_key"`
+	EphemeralPublicKey [32]byte `json:"ephemeral_public_key"`
+	Nonce              [24]byte `json:"nonce"`
+	EncryptedKey       []byte   `json:"encrypted_key"`
+}
+
+// additionalData binds the encrypted key to the channel, key generation and
+// access list.
+func (d *ChannelKeyDistribution) additionalData() []byte {
+	ad := make([]byte, 16, 16+4*len(d.Members))
+	binary.BigEndian.PutUint32(ad[0:4], d.ChatID)
+	binary.BigEndian.PutUint32(ad[4:8], d.ChannelID)
+	binary.BigEndian.PutUint32(ad[8:12], d.KeyID)
+	binary.BigEndian.PutUint32(ad[12:16], d.OwnerID)
+	for _, id := range d.Members {
+		ad = binary.BigEndian.AppendUint32(ad, id)
+	}
+	return ad
+}
+
+// ChannelMessageData represents the data payload for a private channel
+// message broadcast. Only channel members can decrypt Ciphertext.
+type ChannelMessageData struct {
+	ChannelID  uint32 `json:"channel_id"`
+	KeyID      uint32 `json:"key_id"`
+	SenderID   uint32 `json:"sender_id"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// ToMap converts ChannelMessageData to map representation.
+func (d ChannelMessageData) ToMap() map[string]interface{} {
+	return map[string]interface{}{
+		"channel_id": d.ChannelID,
+		"key_id":     d.KeyID,
+		"sender_id":  d.SenderID,
+		"nonce":      d.Nonce,
+		"ciphertext": d.Ciphertext,
+	}
+}
+
+// ChannelMessage is a decrypted private channel message.
+type ChannelMessage struct {
+	ChannelID uint32
+	SenderID  uint32
+	Message   string
+}
+
+// channelMessageAD binds a channel message to its group, channel, key
+// generation and sender.
+func channelMessageAD(chatID uint32, d *ChannelMessageData) []byte {
+	ad := make([]byte, 16)
+	binary.BigEndian.PutUint32(ad[0:4], chatID)
+	binary.BigEndian.PutUint32(ad[4:8], d.ChannelID)
+	binary.BigEndian.PutUint32(ad[8:12], d.KeyID)
+	binary.BigEndian.PutUint32(ad[12:16], d.SenderID)
+	return ad
+}
+
+// CreatePrivateChannel creates a private channel for self and memberIDs.
+// A fresh session key is generated and sent to each member encrypted to
+// their public key in a PacketGroupChannelKey packet. Messages sent with
+// SendChannelMessage can only be decrypted by channel members. Members must
+// be known peers with a public key; the group must have been created with
+// CreateWithKeyPair.
+func (g *Chat) CreatePrivateChannel(memberIDs []uint32) (uint32, error) {
+	g.mu.Lock()
+	if g.keyPair == nil {
+		g.mu.Unlock()
+		return 0, ErrChannelEncryptionUnavailable
+	}
+	if _, err := g.requireSelfRoleLocked(RoleUser, "create private channel"); err != nil {
+		g.mu.Unlock()
+		return 0, err
+	}
+
+	members := map[uint32]bool{g.SelfPeerID: true}
+	for _, id := range memberIDs {
+		peer, exists := g.Peers[id]
+		if !exists {
+			g.mu.Unlock()
+			return 0, fmt.Errorf("peer %d not found", id)
+		}
+		if peer.PublicKey == ([32]byte{}) {
+			g.mu.Unlock()
+			return 0, fmt.Errorf("peer %d has no public key", id)
+		}
+		members[id] = true
+	}
+
+	channelID, err := g.newChannelIDLocked()
+	if err != nil {
+		g.mu.Unlock()
+		return 0, err
+	}
+	ch := &privateChannel{id: channelID, ownerID: g.SelfPeerID, members: members}
+	if _, err := rand.Read(ch.key[:]); err != nil {
+		g.mu.Unlock()
+		return 0, fmt.Errorf("failed to generate channel key: %w", err)
+	}
+	if g.channels == nil {
+		g.channels = make(map[uint32]*privateChannel)
+	}
+	g.channels[channelID] = ch
+	jobs, err := g.channelKeyJobsLocked(ch)
+	g.mu.Unlock()
+	if err != nil {
+		return 0, err
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"function":   "CreatePrivateChannel",
+		"group_id":   g.ID,
+		"channel_id": channelID,
+		"members":    len(members),
+	}).Info("Created private channel")
+
+	if err := g.sendChannelKeys(jobs); err != nil {
+		return channelID, fmt.Errorf("failed to distribute channel key: %w", err)
+	}
+	return channelID, nil
+}
+
+// newChannelIDLocked returns an unused non-zero channel ID. The caller must
+// hold g.mu.
+func (g *Chat) newChannelIDLocked() (uint32, error) {
+	for {
+		id, err := generateRandomID()
+		if err != nil {
+			return 0, fmt.Errorf("failed to generate channel ID: %w", err)
+		}
+		if _, exists := g.channels[id]; id != 0 && !exists {
+			return id, nil
+		}
+	}
+}
+
+// GetChannelMembers returns the peer IDs in a private channel's access
+// list, sorted by ID.
+func (g *Chat) GetChannelMembers(channelID uint32) ([]uint32, error) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	ch, exists := g.channels[channelID]
+	if !exists {
+		return nil, ErrChannelNotFound
+	}
+	return ch.memberList(), nil
+}
+
+// RemoveChannelMember removes peerID from a private channel and refreshes
+// the channel key, so the removed peer cannot read later messages. Only the
+// channel owner can remove other members; any member can remove itself,
+// which drops the channel locally.
+func (g *Chat) RemoveChannelMember(channelID, peerID uint32) error {
+	g.mu.Lock()
+	ch, exists := g.channels[channelID]
+	if !exists {
+		g.mu.Unlock()
+		return ErrChannelNotFound
+	}
+	if peerID == g.SelfPeerID {
+		delete(g.channels, channelID)
+		g.mu.Unlock()
+		return nil
+	}
+	if ch.ownerID != g.SelfPeerID {
+		g.mu.Unlock()
+		return fmt.Errorf("%w: only the channel owner can remove members", ErrInsufficientPrivileges)
+	}
+	if !ch.members[peerID] {
+		g.mu.Unlock()
+		return fmt.Errorf("peer %d is not in channel %d", peerID, channelID)
+	}
+	delete(ch.members, peerID)
+	jobs, err := g.rekeyChannelLocked(ch)
+	g.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	return g.sendChannelKeys(jobs)
+}
+
+// removePeerFromChannels drops a peer that left the group from every
+// channel. The channel owner, or the lowest remaining member ID if the owner
+// left, refreshes the key of each affected channel.
+func (g *Chat) removePeerFromChannels(peerID uint32) {
+	g.mu.Lock()
+	var jobs []peerJob
+	for _, ch := range g.channels {
+		if !ch.members[peerID] {
+			continue
+		}
+		delete(ch.members, peerID)
+		if len(ch.members) == 0 {
+			delete(g.channels, ch.id)
+			continue
+		}
+		if ch.ownerID == peerID {
+			ch.ownerID = ch.memberList()[0]
+		}
+		if ch.ownerID != g.SelfPeerID {
+			continue
+		}
+		channelJobs, err := g.rekeyChannelLocked(ch)
+		if err != nil {
+			logrus.WithFields(logrus.Fields{
+				"function":   "removePeerFromChannels",
+				"channel_id": ch.id,
+				"error":      err.Error(),
+			}).Error("Failed to refresh private channel key")
+			continue
+		}
+		jobs = append(jobs, channelJobs...)
+	}
+	g.mu.Unlock()
+
+	if err := g.sendChannelKeys(jobs); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"function": "removePeerFromChannels",
+			"peer_id":  peerID,
+			"error":    err.Error(),
+		}).Warn("Failed to distribute refreshed channel keys")
+	}
+}
+
+// rekeyChannelLocked replaces the channel key and returns the packets that
+// distribute it. The caller must hold g.mu.
+func (g *Chat) rekeyChannelLocked(ch *privateChannel) ([]peerJob, error) {
+	if _, err := rand.Read(ch.key[:]); err != nil {
+		return nil, fmt.Errorf("failed to generate channel key: %w", err)
+	}
+	ch.keyID++
+
+	logrus.WithFields(logrus.Fields{
+		"function":   "rekeyChannel",
+		"channel_id": ch.id,
+		"key_id":     ch.keyID,
+		"members":    len(ch.members),
+	}).Info("Refreshed private channel key")
+	return g.channelKeyJobsLocked(ch)
+}
+
+// channelKeyJobsLocked encrypts the channel key for every member except
+// self. The caller must hold g.mu.
+func (g *Chat) channelKeyJobsLocked(ch *privateChannel) ([]peerJob, error) {
+	members := ch.memberList()
+	var jobs []peerJob
+	for _, id := range members {
+		if id == g.SelfPeerID {
+			continue
+		}
+		peer, exists := g.Peers[id]
+		if !exists {
+			continue
+		}
+		dist := &ChannelKeyDistribution{
+			ChatID:          g.ID,
+			ChannelID:       ch.id,
+			KeyID:           ch.keyID,
+			OwnerID:         ch.ownerID,
+			Members:         members,
+			SenderPublicKey: g.keyPair.Public,
+		}
+		if err := sealChannelKey(dist, g.keyPair.Private, peer.PublicKey, ch.key); err != nil {
+			return nil, err
+		}
+		data, err := json.Marshal(dist)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize channel key: %w", err)
+		}
+		jobs = append(jobs, peerJob{
+			peerID: id,
+			packet: &transport.Packet{PacketType: transport.PacketGroupChannelKey, Data: data},
+		})
+	}
+	return jobs, nil
+}
+
+// sendChannelKeys sends key distributions to their members. Failures are
+// logged; an error is returned only if every send failed.
+func (g *Chat) sendChannelKeys(jobs []peerJob) error {
+	var errs []error
+	for _, job := range jobs {
+		if err := g.broadcastPeerUpdate(job.peerID, job.packet); err != nil {
+			logrus.WithFields(logrus.Fields{
+				"function": "sendChannelKeys",
+				"peer_id":  job.peerID,
+				"error":    err.Error(),
+			}).Warn("Failed to send channel key to member")
+			errs = append(errs, err)
+		}
+	}
+	if len(jobs) > 0 && len(errs) == len(jobs) {
+		return errors.Join(errs...)
+	}
+	return nil
+}
+
+// sealChannelKey encrypts key for the peer with public key peerPublic and
+// fills in the distribution's ephemeral key, nonce and ciphertext.
+func sealChannelKey(dist *ChannelKeyDistribution, selfPrivate, peerPublic, key [32]byte) error {
+	ephemeralPrivate, ephemeralPublic, err := generateEphemeralKeyPair()
+	if err != nil {
+		return err
+	}
+	staticDH, err := curve25519.X25519(selfPrivate[:], peerPublic[:])
+	if err != nil {
+		crypto.ZeroBytes(ephemeralPrivate[:])
+		return fmt.Errorf("failed to compute static DH: %w", err)
+	}
+	ephemeralDH, err := curve25519.X25519(ephemeralPrivate[:], peerPublic[:])
+	crypto.ZeroBytes(ephemeralPrivate[:])
+	if err != nil {
+		return fmt.Errorf("failed to compute ephemeral DH: %w", err)
+	}
+	encKey := combineAndDeriveKey(staticDH, ephemeralDH)
+
+	aead, err := chacha20poly1305.NewX(encKey[:])
+	if err != nil {
+		return fmt.Errorf("failed to create cipher: %w", err)
+	}
+	if _, err := rand.Read(dist.Nonce[:]); err != nil {
+		return fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	dist.EphemeralPublicKey = ephemeralPublic
+	dist.EncryptedKey = aead.Seal(nil, dist.Nonce[:], key[:], dist.additionalData())
+	return nil
+}
+
+// openChannelKey decrypts the channel key in dist with our private key.
+func openChannelKey(dist *ChannelKeyDistribution, selfPrivate [32]byte) ([32]byte, error) {
+	var key [32]byte
+	staticDH, err := curve25519.X25519(selfPrivate[:], dist.SenderPublicKey[:])
+	if err != nil {
+		return key, fmt.Errorf("failed to compute static DH: %w", err)
+	}
+	ephemeralDH, err := curve25519.X25519(selfPrivate[:], dist.EphemeralPublicKey[:])
+	if err != nil {
+		return key, fmt.Errorf("failed to compute ephemeral DH: %w", err)
+	}
+	decKey := combineAndDeriveKey(staticDH, ephemeralDH)
+
+	aead, err := chacha20poly1305.NewX(decKey[:])
+	if err != nil {
+		return key, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	plaintext, err := aead.Open(nil, dist.Nonce[:], dist.EncryptedKey, dist.additionalData())
+	if err != nil {
+		return key, fmt.Errorf("failed to decrypt channel key: %w", err)
+	}
+	if len(plaintext) != len(key) {
+		return key, errors.New("invalid channel key length")
+	}
+	copy(key[:], plaintext)
+	crypto.ZeroBytes(plaintext)
+	return key, nil
+}
+
+// HandleChannelKeyPacket applies a received PacketGroupChannelKey payload,
+// joining the channel or replacing its key. Distributions must come from a
+// group member listed in the channel, and a known channel only accepts a
+// newer key generation.
+func (g *Chat) HandleChannelKeyPacket(data []byte) error {
+	var dist ChannelKeyDistribution
+	if err := json.Unmarshal(data, &dist); err != nil {
+		return fmt.Errorf("failed to decode channel key: %w", err)
+	}
+	if dist.ChatID != g.ID {
+		return fmt.Errorf("channel key for group %d received by group %d", dist.ChatID, g.ID)
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.keyPair == nil {
+		return ErrChannelEncryptionUnavailable
+	}
+
+	members := make(map[uint32]bool, len(dist.Members))
+	for _, id := range dist.Members {
+		members[id] = true
+	}
+	senderID, known := g.peerIDByPublicKeyLocked(dist.SenderPublicKey)
+	if !known || !members[senderID] {
+		return fmt.Errorf("channel key from peer outside channel %d", dist.ChannelID)
+	}
+	if !members[g.SelfPeerID] {
+		return ErrNotChannelMember
+	}
+	if ch, exists := g.channels[dist.ChannelID]; exists && dist.KeyID <= ch.keyID {
+		return fmt.Errorf("stale channel key %d for channel %d (have %d)", dist.KeyID, dist.ChannelID, ch.keyID)
+	}
+
+	key, err := openChannelKey(&dist, g.keyPair.Private)
+	if err != nil {
+		return err
+	}
+	if g.channels == nil {
+		g.channels = make(map[uint32]*privateChannel)
+	}
+	g.channels[dist.ChannelID] = &privateChannel{
+		id:      dist.ChannelID,
+		ownerID: dist.OwnerID,
+		keyID:   dist.KeyID,
+		key:     key,
+		members: members,
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"function":   "HandleChannelKeyPacket",
+		"channel_id": dist.ChannelID,
+		"key_id":     dist.KeyID,
+		"sender_id":  senderID,
+	}).Debug("Installed private channel key")
+	return nil
+}
+
+// peerIDByPublicKeyLocked finds the peer with publicKey. The caller must
+// hold g.mu.
+func (g *Chat) peerIDByPublicKeyLocked(publicKey [32]byte) (uint32, bool) {
+	for id, peer := range g.Peers {
+		if peer.PublicKey == publicKey {
+			return id, true
+		}
+	}
+	return 0, false
+}
+
+// SendChannelMessage encrypts message with the channel session key and
+// broadcasts it to the group. Peers outside the channel receive the
+// broadcast but cannot decrypt it.
+func (g *Chat) SendChannelMessage(channelID uint32, message string) error {
+	g.mu.RLock()
+	if err := g.validateSendMessage(message); err != nil {
+		g.mu.RUnlock()
+		return err
+	}
+	ch, exists := g.channels[channelID]
+	if !exists {
+		g.mu.RUnlock()
+		return ErrChannelNotFound
+	}
+	data := ChannelMessageData{ChannelID: channelID, KeyID: ch.keyID, SenderID: g.SelfPeerID}
+	key := ch.key
+	g.mu.RUnlock()
+
+	aead, err := chacha20poly1305.NewX(key[:])
+	if err != nil {
+		return fmt.Errorf("failed to create cipher: %w", err)
+	}
+	data.Nonce = make([]byte, aead.NonceSize())
+	if _, err := rand.Read(data.Nonce); err != nil {
+		return fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	data.Ciphertext = aead.Seal(nil, data.Nonce, []byte(message), channelMessageAD(g.ID, &data))
+
+	if err := g.broadcastGroupUpdateTyped(channelMessageType, data); err != nil {
+		return fmt.Errorf("failed to broadcast channel message: %w", err)
+	}
+	return nil
+}
+
+// DecryptChannelMessage decrypts a received private channel broadcast, as
+// returned by HandleBroadcastPacket. It returns ErrNotChannelMember if the
+// local peer does not hold the channel key.
+func (g *Chat) DecryptChannelMessage(msg *BroadcastMessage) (*ChannelMessage, error) {
+	if msg.Type != channelMessageType {
+		return nil, fmt.Errorf("unexpected channel message type %q", msg.Type)
+	}
+	raw, err := json.Marshal(msg.Data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode channel message: %w", err)
+	}
+	var data ChannelMessageData
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, fmt.Errorf("failed to decode channel message: %w", err)
+	}
+
+	g.mu.RLock()
+	ch, exists := g.channels[data.ChannelID]
+	var key [32]byte
+	var keyID uint32
+	if exists {
+		key, keyID = ch.key, ch.keyID
+	}
+	g.mu.RUnlock()
+	if !exists {
+		return nil, ErrNotChannelMember
+	}
+	if data.KeyID != keyID {
+		return nil, fmt.Errorf("channel key mismatch: expected %d, got %d", keyID, data.KeyID)
+	}
+
+	aead, err := chacha20poly1305.NewX(key[:])
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	if len(data.Nonce) != aead.NonceSize() {
+		return nil, errors.New("invalid channel message nonce")
+	}
+	plaintext, err := aead.Open(nil, data.Nonce, data.Ciphertext, channelMessageAD(g.ID, &data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt channel message: %w", err)
+	}
+	return &ChannelMessage{ChannelID: data.ChannelID, SenderID: data.SenderID, Message: string(plaintext)}, nil
+}
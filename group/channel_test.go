@@ -0,0 +1,190 @@
+package group
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/opd-ai/toxcore/crypto"
+	"github.com/opd-ai/toxcore/transport"
+)
+
+// newChannelTestGroup builds one Chat per peer ID, each knowing every peer's
+// public key and address.
+func newChannelTestGroup(t *testing.T, peerIDs ...uint32) (map[uint32]*Chat, map[uint32]*mockTransport) {
+	t.Helper()
+	keys := make(map[uint32]*crypto.KeyPair)
+	for _, id := range peerIDs {
+		kp, err := crypto.GenerateKeyPair()
+		if err != nil {
+			t.Fatalf("GenerateKeyPair failed: %v", err)
+		}
+		keys[id] = kp
+	}
+
+	chats := make(map[uint32]*Chat)
+	transports := make(map[uint32]*mockTransport)
+	for _, self := range peerIDs {
+		peers := make(map[uint32]*Peer)
+		for _, id := range peerIDs {
+			peers[id] = &Peer{
+				ID:         id,
+				Role:       RoleUser,
+				Connection: 2,
+				PublicKey:  keys[id].Public,
+				Address:    &mockAddr{address: fmt.Sprintf("10.0.0.%d:33445", id)},
+			}
+		}
+		transports[self] = &mockTransport{}
+		chats[self] = &Chat{
+			ID:         9,
+			SelfPeerID: self,
+			Peers:      peers,
+			transport:  transports[self],
+			keyPair:    keys[self],
+		}
+	}
+	return chats, transports
+}
+
+// sentPackets returns the packets of the given type sent to peerID.
+func sentPackets(trans *mockTransport, packetType transport.PacketType, peerID uint32) [][]byte {
+	addr := fmt.Sprintf("10.0.0.%d:33445", peerID)
+	var packets [][]byte
+	for _, call := range trans.getSendCalls() {
+		if call.packet.PacketType == packetType && call.addr.String() == addr {
+			packets = append(packets, call.packet.Data)
+		}
+	}
+	return packets
+}
+
+// receiveChannelMessage passes the last broadcast sent to receiver through
+// HandleBroadcastPacket and DecryptChannelMessage.
+func receiveChannelMessage(t *testing.T, trans *mockTransport, receiver *Chat) (*ChannelMessage, error) {
+	t.Helper()
+	packets := sentPackets(trans, transport.PacketGroupBroadcast, receiver.SelfPeerID)
+	if len(packets) == 0 {
+		t.Fatalf("no broadcast sent to peer %d", receiver.SelfPeerID)
+	}
+	msgs, err := receiver.HandleBroadcastPacket(packets[len(packets)-1])
+	if err != nil || len(msgs) != 1 {
+		t.Fatalf("HandleBroadcastPacket returned %d messages, err %v", len(msgs), err)
+	}
+	return receiver.DecryptChannelMessage(msgs[0])
+}
+
+// TestPrivateChannelMessaging verifies only channel members can decrypt
+func TestPrivateChannelMessaging(t *testing.T) {
+	chats, transports := newChannelTestGroup(t, 1, 2, 3)
+
+	channelID, err := chats[1].CreatePrivateChannel([]uint32{2})
+	if err != nil {
+		t.Fatalf("CreatePrivateChannel failed: %v", err)
+	}
+	keyPackets := sentPackets(transports[1], transport.PacketGroupChannelKey, 2)
+	if len(keyPackets) != 1 {
+		t.Fatalf("expected 1 channel key for peer 2, got %d", len(keyPackets))
+	}
+	if n := len(sentPackets(transports[1], transport.PacketGroupChannelKey, 3)); n != 0 {
+		t.Fatalf("non-member received %d channel keys", n)
+	}
+	if err := chats[2].HandleChannelKeyPacket(keyPackets[0]); err != nil {
+		t.Fatalf("HandleChannelKeyPacket failed: %v", err)
+	}
+	if err := chats[3].HandleChannelKeyPacket(keyPackets[0]); err == nil {
+		t.Error("non-member should not be able to install the channel key")
+	}
+
+	if err := chats[1].SendChannelMessage(channelID, "admins only"); err != nil {
+		t.Fatalf("SendChannelMessage failed: %v", err)
+	}
+	msg, err := receiveChannelMessage(t, transports[1], chats[2])
+	if err != nil {
+		t.Fatalf("member failed to decrypt: %v", err)
+	}
+	if msg.Message != "admins only" || msg.ChannelID != channelID || msg.SenderID != 1 {
+		t.Errorf("unexpected channel message %+v", msg)
+	}
+	if _, err := receiveChannelMessage(t, transports[1], chats[3]); !errors.Is(err, ErrNotChannelMember) {
+		t.Errorf("expected ErrNotChannelMember for non-member, got %v", err)
+	}
+}
+
+// TestPrivateChannelRekeyOnLeave verifies removed members lose access
+func TestPrivateChannelRekeyOnLeave(t *testing.T) {
+	chats, transports := newChannelTestGroup(t, 1, 2, 3)
+
+	channelID, err := chats[1].CreatePrivateChannel([]uint32{2, 3})
+	if err != nil {
+		t.Fatalf("CreatePrivateChannel failed: %v", err)
+	}
+	for _, id := range []uint32{2, 3} {
+		if err := chats[id].HandleChannelKeyPacket(sentPackets(transports[1], transport.PacketGroupChannelKey, id)[0]); err != nil {
+			t.Fatalf("peer %d failed to install key: %v", id, err)
+		}
+	}
+
+	if err := chats[2].RemoveChannelMember(channelID, 3); !errors.Is(err, ErrInsufficientPrivileges) {
+		t.Errorf("expected non-owner removal to fail, got %v", err)
+	}
+	if err := chats[1].RemoveChannelMember(channelID, 3); err != nil {
+		t.Fatalf("RemoveChannelMember failed: %v", err)
+	}
+	refreshed := sentPackets(transports[1], transport.PacketGroupChannelKey, 2)
+	if len(refreshed) != 2 {
+		t.Fatalf("expected refreshed key for peer 2, got %d key packets", len(refreshed))
+	}
+	if n := len(sentPackets(transports[1], transport.PacketGroupChannelKey, 3)); n != 1 {
+		t.Errorf("removed member received %d key packets, want 1", n)
+	}
+	if err := chats[2].HandleChannelKeyPacket(refreshed[1]); err != nil {
+		t.Fatalf("HandleChannelKeyPacket for refreshed key failed: %v", err)
+	}
+	if err := chats[2].HandleChannelKeyPacket(refreshed[0]); err == nil {
+		t.Error("stale channel key should be rejected")
+	}
+	members, _ := chats[2].GetChannelMembers(channelID)
+	if len(members) != 2 {
+		t.Errorf("expected 2 members after removal, got %v", members)
+	}
+
+	if err := chats[1].SendChannelMessage(channelID, "after rekey"); err != nil {
+		t.Fatalf("SendChannelMessage failed: %v", err)
+	}
+	if msg, err := receiveChannelMessage(t, transports[1], chats[2]); err != nil || msg.Message != "after rekey" {
+		t.Errorf("remaining member failed to decrypt: %v", err)
+	}
+	if _, err := receiveChannelMessage(t, transports[1], chats[3]); err == nil {
+		t.Error("removed member decrypted a message sent after rekey")
+	}
+}
+
+// TestPrivateChannelRequiresKeyPair verifies unencrypted groups are refused
+func TestPrivateChannelRequiresKeyPair(t *testing.T) {
+	chat := &Chat{ID: 9, SelfPeerID: 1, Peers: map[uint32]*Peer{1: {ID: 1}}}
+	if _, err := chat.CreatePrivateChannel(nil); !errors.Is(err, ErrChannelEncryptionUnavailable) {
+		t.Errorf("expected ErrChannelEncryptionUnavailable, got %v", err)
+	}
+}
+
+// TestPrivateChannelRekeyOnKick verifies kicking a member refreshes the key
+func TestPrivateChannelRekeyOnKick(t *testing.T) {
+	chats, transports := newChannelTestGroup(t, 1, 2, 3)
+	chats[1].Peers[1].Role = RoleModerator
+
+	channelID, err := chats[1].CreatePrivateChannel([]uint32{2, 3})
+	if err != nil {
+		t.Fatalf("CreatePrivateChannel failed: %v", err)
+	}
+	if err := chats[1].KickPeer(3); err != nil {
+		t.Fatalf("KickPeer failed: %v", err)
+	}
+	members, _ := chats[1].GetChannelMembers(channelID)
+	if len(members) != 2 || members[0] != 1 || members[1] != 2 {
+		t.Errorf("unexpected members after kick: %v", members)
+	}
+	if n := len(sentPackets(transports[1], transport.PacketGroupChannelKey, 2)); n != 2 {
+		t.Errorf("expected refreshed key for peer 2 after kick, got %d key packets", n)
+	}
+}
@@ -52,6 +52,7 @@ import (
 	"fmt"
 	"net"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/opd-ai/toxcore/crypto"
@@ -81,6 +82,11 @@ type BroadcastConfig struct {
 	Logger     logrus.FieldLogger
 	OnSuccess  func(peerID uint32)
 	OnFailure  func(peerID uint32, err error)
+
+	// packetType is the transport packet type used for the broadcast.
+	packetType transport.PacketType
+	// onSent receives the broadcast message once the broadcast succeeded.
+	onSent func(msg *BroadcastMessage)
 }
 
 // defaultBroadcastConfig returns the default broadcast configuration.
@@ -89,6 +95,7 @@ func defaultBroadcastConfig() *BroadcastConfig {
 		Timeout:    30 * time.Second,
 		MaxWorkers: 10,
 		Logger:     logrus.StandardLogger(),
+		packetType: transport.PacketGroupBroadcast,
 	}
 }
 
@@ -170,6 +177,10 @@ var (
 // but the local peer is not present in the peer list (e.g., after Leave()).
 var ErrNotMember = errors.New("not a member of this group")
 
+// ErrInsufficientPrivileges is returned when the local peer's role does not
+// permit an operation, including attempts to grant a role at or above its own.
+var ErrInsufficientPrivileges = errors.New("insufficient privileges")
+
 // SetDefaultTimeProvider sets the package-level time provider for testing.
 // This affects standalone functions like registerGroup that don't have access
 // to a Chat instance's time provider.
@@ -236,19 +247,77 @@ const (
 )
 
 // Role represents a peer's role in the group.
+//
+// Role values are carried on the wire, so they do not follow the hierarchy:
+// RoleTrustedUser was added after RoleFounder. Compare roles with the Can*
+// methods or by rank, never numerically.
 type Role uint8
 
 const (
 	// RoleUser is a regular group member.
-	RoleUser Role = iota
-	// RoleModerator can kick and ban users.
-	RoleModerator
+	RoleUser Role = 0
+	// RoleModerator can kick and ban users and promote users to RoleTrustedUser.
+	RoleModerator Role = 1
 	// RoleAdmin has full control over the group.
-	RoleAdmin
+	RoleAdmin Role = 2
 	// RoleFounder created the group and cannot be demoted.
-	RoleFounder
+	RoleFounder Role = 3
+	// RoleTrustedUser can pin messages and add reactions but cannot kick or
+	// ban. It ranks between RoleUser and RoleModerator.
+	RoleTrustedUser Role = 4
 )
 
+// rank returns the position of the role in the hierarchy, from 0 for
+// RoleUser to 4 for RoleFounder, or -1 for an unknown role.
+func (r Role) rank() int {
+	switch r {
+	case RoleUser:
+		return 0
+	case RoleTrustedUser:
+		return 1
+	case RoleModerator:
+		return 2
+	case RoleAdmin:
+		return 3
+	case RoleFounder:
+		return 4
+	default:
+		return -1
+	}
+}
+
+// atLeast reports whether the role ranks at or above other.
+func (r Role) atLeast(other Role) bool { return r.rank() >= other.rank() }
+
+// outranks reports whether the role ranks strictly above other.
+func (r Role) outranks(other Role) bool { return r.rank() > other.rank() }
+
+// CanPinMessages reports whether the role may pin messages.
+func (r Role) CanPinMessages() bool {
+	switch r {
+	case RoleTrustedUser, RoleModerator, RoleAdmin, RoleFounder:
+		return true
+	default:
+		return false
+	}
+}
+
+// CanReact reports whether the role may add emoji reactions.
+func (r Role) CanReact() bool { return r.CanPinMessages() }
+
+// CanKick reports whether the role may kick peers.
+func (r Role) CanKick() bool {
+	switch r {
+	case RoleModerator, RoleAdmin, RoleFounder:
+		return true
+	default:
+		return false
+	}
+}
+
+// CanBan reports whether the role may ban peers.
+func (r Role) CanBan() bool { return r.CanKick() }
+
 // MessageCallback is called when a message is received in a group.
 type MessageCallback func(groupID, peerID uint32, message string)
 
@@ -272,6 +341,9 @@ type GroupInfo struct {
 	Name    string
 	Type    ChatType
 	Privacy Privacy
+	// Description is the group description. Announcements carry only the
+	// first dht.MaxAnnouncementDescriptionLen bytes.
+	Description string
 }
 
 // groupRegistry stores group information for DHT lookups.
@@ -295,12 +367,13 @@ func registerGroup(chatID uint32, info *GroupInfo, dhtRouting *dht.RoutingTable,
 	if dhtRouting != nil && transport != nil {
 		tp := getDefaultTimeProvider()
 		announcement := &dht.GroupAnnouncement{
-			GroupID:   chatID,
-			Name:      info.Name,
-			Type:      uint8(info.Type),
-			Privacy:   uint8(info.Privacy),
-			Timestamp: tp.Now(),
-			TTL:       24 * time.Hour,
+			GroupID:     chatID,
+			Name:        info.Name,
+			Type:        uint8(info.Type),
+			Privacy:     uint8(info.Privacy),
+			Timestamp:   tp.Now(),
+			TTL:         24 * time.Hour,
+			Description: dht.TruncateDescription(info.Description),
 		}
 
 		if err := dhtRouting.AnnounceGroup(announcement, transport); err != nil {
@@ -340,9 +413,10 @@ func queryDHTForGroup(chatID uint32, dhtRouting *dht.RoutingTable, transport tra
 		groupRegistry.RUnlock()
 		// Return a copy to prevent external modification
 		return &GroupInfo{
-			Name:    info.Name,
-			Type:    info.Type,
-			Privacy: info.Privacy,
+			Name:        info.Name,
+			Type:        info.Type,
+			Privacy:     info.Privacy,
+			Description: info.Description,
 		}, nil
 	}
 	groupRegistry.RUnlock()
@@ -415,9 +489,10 @@ func convertAnnouncementToGroupInfo(announcement *dht.GroupAnnouncement) *GroupI
 		return nil
 	}
 	return &GroupInfo{
-		Name:    announcement.Name,
-		Type:    ChatType(announcement.Type),
-		Privacy: Privacy(announcement.Privacy),
+		Name:        announcement.Name,
+		Type:        ChatType(announcement.Type),
+		Privacy:     Privacy(announcement.Privacy),
+		Description: announcement.Description,
 	}
 }
 
@@ -542,6 +617,24 @@ type Chat struct {
 	peerCallback           PeerCallback
 	peerDiscoveredCallback PeerDiscoveredCallback
 
+	// Outgoing broadcast sequence counter and receive-side reorder buffer
+	sendSequence  atomic.Uint64
+	reorderBuffer *ReorderBuffer
+
+	// Banned public keys and optional persistence path
+	bans        map[[32]byte]*BannedPeer
+	banListPath string
+
+	// Group topic and description, see SetTopic and SetDescription
+	topic       string
+	description string
+
+	// Private channels this peer belongs to, keyed by channel ID
+	channels map[uint32]*privateChannel
+
+	// Recent messages for GetMessageHistory, created on first use
+	history *messageHistory
+
 	mu sync.RWMutex
 }
 
@@ -556,6 +649,9 @@ type Peer struct {
 	PublicKey  [32]byte
 	Address    net.Addr // Cached network address for direct communication
 	LastActive time.Time
+	// Capabilities holds the feature flags from the peer's announcement
+	// (see transport.CapabilityBit)
+	Capabilities uint64
 }
 
 // SetTimeProvider sets the time provider for deterministic testing.
@@ -810,6 +906,7 @@ func createJoinedChat(chatID uint32, groupInfo *GroupInfo, transport transport.T
 		Name:               groupInfo.Name,
 		Type:               groupInfo.Type,
 		Privacy:            groupInfo.Privacy,
+		description:        groupInfo.Description,
 		PeerCount:          1,
 		SelfPeerID:         selfPeerID,
 		Peers:              make(map[uint32]*Peer),
@@ -1049,11 +1146,12 @@ func (g *Chat) sendEncryptedGroupMessage(message string) error {
 
 // sendPlaintextGroupMessage broadcasts a plaintext message to the group.
 func (g *Chat) sendPlaintextGroupMessage(message string) error {
-	err := g.broadcastGroupUpdateTyped("group_message", GroupMessageData{
+	data := GroupMessageData{
 		SenderID:  g.SelfPeerID,
 		Message:   message,
 		Timestamp: g.getTimeProvider().Now().Unix(),
-	})
+	}
+	err := g.broadcastGroupUpdateWithOptions(groupMessageType, data.ToMap(), withOnSent(g.recordHistory))
 	if err != nil {
 		return fmt.Errorf("failed to broadcast message to group: %w", err)
 	}
@@ -1132,6 +1230,9 @@ func (g *Chat) Leave(message string) error {
 		}).Warn("Failed to broadcast leave message")
 	}
 
+	// Persist bans so they survive a restart of this peer
+	g.persistBanList()
+
 	g.mu.Lock()
 	defer g.mu.Unlock()
 
@@ -1146,6 +1247,10 @@ func (g *Chat) Leave(message string) error {
 	// Mark self as no longer in the group
 	g.SelfPeerID = 0
 
+	// Drop private channel keys and the ordering state of every sender
+	g.channels = nil
+	g.reorderBuffer = nil
+
 	// Clear message callback to prevent further message processing
 	g.messageCallback = nil
 
@@ -1228,8 +1333,8 @@ func (g *Chat) validatePeerPermission(targetPeerID uint32, requiredRole Role, ac
 		return nil, nil, err
 	}
 
-	if selfPeer.Role <= targetPeer.Role {
-		return nil, nil, fmt.Errorf("cannot %s peer with equal or higher role", action)
+	if !selfPeer.Role.outranks(targetPeer.Role) {
+		return nil, nil, fmt.Errorf("%w: cannot %s peer with equal or higher role", ErrInsufficientPrivileges, action)
 	}
 
 	return targetPeer, selfPeer, nil
@@ -1241,8 +1346,8 @@ func (g *Chat) requireSelfRoleLocked(requiredRole Role, action string) (*Peer, e
 	if selfPeer == nil {
 		return nil, ErrNotMember
 	}
-	if selfPeer.Role < requiredRole {
-		return nil, fmt.Errorf("insufficient privileges to %s", action)
+	if !selfPeer.Role.atLeast(requiredRole) {
+		return nil, fmt.Errorf("%w to %s", ErrInsufficientPrivileges, action)
 	}
 	return selfPeer, nil
 }
@@ -1277,26 +1382,40 @@ func (g *Chat) KickPeer(peerID uint32) error {
 	g.mu.Lock()
 	delete(g.Peers, peerID)
 	g.mu.Unlock()
+	g.getReorderBuffer().RemoveSender(peerID)
+
+	// Refresh the keys of private channels the peer belonged to.
+	g.removePeerFromChannels(peerID)
 
 	return nil
 }
 
 // SetPeerRole changes a peer's role in the group.
 //
+// The role hierarchy is enforced: Moderators and above may change roles, but a
+// peer can only modify peers strictly below itself and can only grant roles
+// strictly below its own. A Moderator can therefore promote RoleUser to
+// RoleTrustedUser but not to RoleModerator. Violations return an error
+// wrapping ErrInsufficientPrivileges.
+//
 //export ToxGroupSetPeerRole
 func (g *Chat) SetPeerRole(peerID uint32, role Role) error {
 	// Apply mutation under write lock, capture broadcast data, then release
 	// before broadcasting so collectOnlinePeerJobs can acquire RLock.
 	g.mu.Lock()
-	targetPeer, selfPeer, err := g.validatePeerPermission(peerID, RoleAdmin, "change role of")
+	targetPeer, selfPeer, err := g.validatePeerPermission(peerID, RoleModerator, "change role of")
 	if err != nil {
 		g.mu.Unlock()
 		return err
 	}
 
-	if role >= selfPeer.Role {
+	if role.rank() < 0 {
+		g.mu.Unlock()
+		return fmt.Errorf("unknown role %d", role)
+	}
+	if !selfPeer.Role.outranks(role) {
 		g.mu.Unlock()
-		return errors.New("cannot assign role equal or higher than your own")
+		return fmt.Errorf("%w: cannot assign role equal or higher than your own", ErrInsufficientPrivileges)
 	}
 
 	// Cannot change the founder's role
@@ -1578,21 +1697,23 @@ func (d PeerNameChangeData) ToMap() map[string]interface{} {
 // PeerAnnounceData represents a peer announcement for auto-discovery.
 // Sent when a peer joins a group or when requested by other peers.
 type PeerAnnounceData struct {
-	PeerID     uint32   `json:"peer_id"`
-	Name       string   `json:"name"`
-	PublicKey  [32]byte `json:"public_key"`
-	Connection uint8    `json:"connection"`
-	Role       Role     `json:"role"`
+	PeerID       uint32   `json:"peer_id"`
+	Name         string   `json:"name"`
+	PublicKey    [32]byte `json:"public_key"`
+	Connection   uint8    `json:"connection"`
+	Role         Role     `json:"role"`
+	Capabilities uint64   `json:"capabilities"`
 }
 
 // ToMap converts PeerAnnounceData to map representation.
 func (d PeerAnnounceData) ToMap() map[string]interface{} {
 	return map[string]interface{}{
-		"peer_id":    d.PeerID,
-		"name":       d.Name,
-		"public_key": d.PublicKey[:],
-		"connection": d.Connection,
-		"role":       d.Role,
+		"peer_id":      d.PeerID,
+		"name":         d.Name,
+		"public_key":   d.PublicKey[:],
+		"connection":   d.Connection,
+		"role":         d.Role,
+		"capabilities": d.Capabilities,
 	}
 }
 
@@ -1632,12 +1753,19 @@ func (d PeerListResponseData) ToMap() map[string]interface{} {
 // Uses JSON encoding for compatibility and debuggability. While gob encoding was considered
 // for performance, benchmarking showed JSON is actually 3x faster and 30% smaller for the
 // map[string]interface{} data structure used here due to gob's type information overhead.
+//
+// SequenceNumber increases monotonically per sender so receivers can restore
+// send order with a ReorderBuffer. PossiblyReordered is set locally by the
+// receiver and is never transmitted.
 type BroadcastMessage struct {
-	Type      string                 `json:"type"`
-	ChatID    uint32                 `json:"chat_id"`
-	SenderID  uint32                 `json:"sender_id"`
-	Timestamp time.Time              `json:"timestamp"`
-	Data      map[string]interface{} `json:"data"`
+	Type           string                 `json:"type"`
+	ChatID         uint32                 `json:"chat_id"`
+	SenderID       uint32                 `json:"sender_id"`
+	SequenceNumber uint64                 `json:"sequence_number,omitempty"`
+	Timestamp      time.Time              `json:"timestamp"`
+	Data           map[string]interface{} `json:"data"`
+
+	PossiblyReordered bool `json:"-"`
 }
 
 // broadcastGroupUpdate sends a group state update to all connected peers
@@ -1654,7 +1782,8 @@ func (g *Chat) broadcastGroupUpdateWithOptions(updateType string, data map[strin
 		opt(cfg)
 	}
 
-	msgBytes, err := g.createBroadcastMessage(updateType, data)
+	msg := g.newBroadcastMessage(updateType, data)
+	msgBytes, err := marshalBroadcastMessage(msg)
 	if err != nil {
 		return err
 	}
@@ -1665,7 +1794,13 @@ func (g *Chat) broadcastGroupUpdateWithOptions(updateType string, data map[strin
 	successfulBroadcasts, broadcastErrors := g.sendToConnectedPeersWithConfig(ctx, msgBytes, cfg)
 	g.logBroadcastResultsWithLogger(cfg.Logger, updateType, successfulBroadcasts, broadcastErrors, len(msgBytes))
 
-	return g.validateBroadcastResults(successfulBroadcasts, broadcastErrors)
+	if err := g.validateBroadcastResults(successfulBroadcasts, broadcastErrors); err != nil {
+		return err
+	}
+	if cfg.onSent != nil {
+		cfg.onSent(msg)
+	}
+	return nil
 }
 
 // broadcastGroupUpdateTyped sends a group state update using type-safe broadcast data.
@@ -1683,14 +1818,24 @@ func (g *Chat) BroadcastGroupUpdateTypedWithOptions(updateType string, data Broa
 // createBroadcastMessage creates and serializes a broadcast message for the group update.
 // Uses JSON encoding which benchmarks show is more efficient than gob for map[string]interface{}.
 func (g *Chat) createBroadcastMessage(updateType string, data map[string]interface{}) ([]byte, error) {
-	msg := BroadcastMessage{
-		Type:      updateType,
-		ChatID:    g.ID,
-		SenderID:  g.SelfPeerID,
-		Timestamp: g.getTimeProvider().Now(),
-		Data:      data,
+	return marshalBroadcastMessage(g.newBroadcastMessage(updateType, data))
+}
+
+// newBroadcastMessage builds the broadcast message for a group update,
+// assigning it the next outgoing sequence number.
+func (g *Chat) newBroadcastMessage(updateType string, data map[string]interface{}) *BroadcastMessage {
+	return &BroadcastMessage{
+		Type:           updateType,
+		ChatID:         g.ID,
+		SenderID:       g.SelfPeerID,
+		SequenceNumber: g.sendSequence.Add(1),
+		Timestamp:      g.getTimeProvider().Now(),
+		Data:           data,
 	}
+}
 
+// marshalBroadcastMessage serializes a broadcast message for transmission.
+func marshalBroadcastMessage(msg *BroadcastMessage) ([]byte, error) {
 	msgBytes, err := json.Marshal(msg)
 	if err != nil {
 		return nil, fmt.Errorf("failed to serialize broadcast message: %w", err)
@@ -1700,7 +1845,7 @@ func (g *Chat) createBroadcastMessage(updateType string, data map[string]interfa
 }
 
 // collectOnlinePeerJobs creates jobs for all online peers.
-func (g *Chat) collectOnlinePeerJobs(msgBytes []byte) []peerJob {
+func (g *Chat) collectOnlinePeerJobs(msgBytes []byte, packetType transport.PacketType) []peerJob {
 	g.mu.RLock()
 	defer g.mu.RUnlock()
 
@@ -1716,7 +1861,7 @@ func (g *Chat) collectOnlinePeerJobs(msgBytes []byte) []peerJob {
 		jobs = append(jobs, peerJob{
 			peerID: peerID,
 			packet: &transport.Packet{
-				PacketType: transport.PacketGroupBroadcast,
+				PacketType: packetType,
 				Data:       msgBytes,
 			},
 		})
@@ -1788,7 +1933,7 @@ func (g *Chat) sendToConnectedPeers(ctx context.Context, msgBytes []byte) (int,
 
 // sendToConnectedPeersWithConfig sends the broadcast message with configurable options.
 func (g *Chat) sendToConnectedPeersWithConfig(ctx context.Context, msgBytes []byte, cfg *BroadcastConfig) (int, []error) {
-	jobs := g.collectOnlinePeerJobs(msgBytes)
+	jobs := g.collectOnlinePeerJobs(msgBytes, cfg.packetType)
 	if len(jobs) == 0 {
 		return 0, nil
 	}
@@ -1958,11 +2103,12 @@ func (g *Chat) AnnounceSelf() error {
 	}
 
 	announceData := PeerAnnounceData{
-		PeerID:     self.ID,
-		Name:       self.Name,
-		PublicKey:  self.PublicKey,
-		Connection: self.Connection,
-		Role:       self.Role,
+		PeerID:       self.ID,
+		Name:         self.Name,
+		PublicKey:    self.PublicKey,
+		Connection:   self.Connection,
+		Role:         self.Role,
+		Capabilities: transport.LocalCapabilities(),
 	}
 	g.mu.RUnlock()
 
@@ -1987,14 +2133,43 @@ func (g *Chat) RequestPeerList() error {
 
 // HandlePeerAnnounce processes a peer announcement message and adds the peer if new.
 // This is called internally when receiving peer_announce broadcast messages.
+// A newly joined peer that advertises transport.CapabilityGroupHistory is
+// sent the group's message history; a banned peer is sent a
+// peer_join_rejected message with JoinRejectBanned.
 // Returns true if a new peer was discovered, false if the peer was already known.
 func (g *Chat) HandlePeerAnnounce(data PeerAnnounceData, sourceAddr net.Addr) bool {
+	added, err := g.admitAnnouncedPeer(data, sourceAddr)
+	if errors.Is(err, ErrBanned) {
+		g.sendJoinRejection(data.PeerID, sourceAddr)
+	}
+	if !added {
+		return false
+	}
+	if data.Capabilities&transport.CapabilityGroupHistory.Mask() != 0 {
+		g.sendHistorySync(data.PeerID)
+	}
+	return true
+}
+
+// admitAnnouncedPeer adds or refreshes an announced peer and reports whether
+// it was new. Banned peers are refused with ErrBanned.
+func (g *Chat) admitAnnouncedPeer(data PeerAnnounceData, sourceAddr net.Addr) (bool, error) {
 	g.mu.Lock()
 	defer g.mu.Unlock()
 
 	// Don't process announcements from self
 	if data.PeerID == g.SelfPeerID {
-		return false
+		return false, nil
+	}
+
+	// Reject banned peers before (re)admitting them
+	if banned, _ := g.checkBanLocked(data.PublicKey); banned {
+		logrus.WithFields(logrus.Fields{
+			"function": "HandlePeerAnnounce",
+			"group_id": g.ID,
+			"peer_id":  data.PeerID,
+		}).Debug("Ignoring announcement from banned peer")
+		return false, ErrBanned
 	}
 
 	existingPeer, exists := g.Peers[data.PeerID]
@@ -2003,19 +2178,21 @@ func (g *Chat) HandlePeerAnnounce(data PeerAnnounceData, sourceAddr net.Addr) bo
 		existingPeer.Address = sourceAddr
 		existingPeer.LastActive = g.getTimeProvider().Now()
 		existingPeer.Connection = data.Connection
-		return false
+		existingPeer.Capabilities = data.Capabilities
+		return false, nil
 	}
 
 	// Add new peer — default to RoleUser regardless of the announced role;
 	// elevated roles are only granted via signed role-change messages.
 	newPeer := &Peer{
-		ID:         data.PeerID,
-		Name:       data.Name,
-		PublicKey:  data.PublicKey,
-		Role:       RoleUser,
-		Connection: data.Connection,
-		Address:    sourceAddr,
-		LastActive: g.getTimeProvider().Now(),
+		ID:           data.PeerID,
+		Name:         data.Name,
+		PublicKey:    data.PublicKey,
+		Role:         RoleUser,
+		Connection:   data.Connection,
+		Address:      sourceAddr,
+		LastActive:   g.getTimeProvider().Now(),
+		Capabilities: data.Capabilities,
 	}
 	g.Peers[data.PeerID] = newPeer
 
@@ -2029,7 +2206,7 @@ func (g *Chat) HandlePeerAnnounce(data PeerAnnounceData, sourceAddr net.Addr) bo
 		safeInvokeCallback(func() { callback(groupID, peerID, &peerCopy) })
 	}
 
-	return true
+	return true, nil
 }
 
 // HandlePeerListRequest processes a peer list request and sends back known peers.
@@ -2053,22 +2230,24 @@ func (g *Chat) HandlePeerListRequest(data PeerListRequestData) error {
 			continue
 		}
 		peers = append(peers, PeerAnnounceData{
-			PeerID:     peer.ID,
-			Name:       peer.Name,
-			PublicKey:  peer.PublicKey,
-			Connection: peer.Connection,
-			Role:       peer.Role,
+			PeerID:       peer.ID,
+			Name:         peer.Name,
+			PublicKey:    peer.PublicKey,
+			Connection:   peer.Connection,
+			Role:         peer.Role,
+			Capabilities: peer.Capabilities,
 		})
 	}
 
 	// Also include self in the response
 	if self, ok := g.Peers[g.SelfPeerID]; ok {
 		peers = append(peers, PeerAnnounceData{
-			PeerID:     self.ID,
-			Name:       self.Name,
-			PublicKey:  self.PublicKey,
-			Connection: self.Connection,
-			Role:       self.Role,
+			PeerID:       self.ID,
+			Name:         self.Name,
+			PublicKey:    self.PublicKey,
+			Connection:   self.Connection,
+			Role:         self.Role,
+			Capabilities: transport.LocalCapabilities(),
 		})
 	}
 
@@ -2089,6 +2268,7 @@ func (g *Chat) HandlePeerListRequest(data PeerListRequestData) error {
 
 // HandlePeerListResponse processes a peer list response and adds discovered peers.
 // This is called internally when receiving peer_list_response broadcast messages.
+// Peers learned this way are existing members, so no history is sent to them.
 func (g *Chat) HandlePeerListResponse(data PeerListResponseData, sourceAddr net.Addr) {
 	for _, peerData := range data.Peers {
 		// Use source address for the responder, nil for others
@@ -2096,6 +2276,6 @@ func (g *Chat) HandlePeerListResponse(data PeerListResponseData, sourceAddr net.
 		if peerData.PeerID == data.ResponderID {
 			addr = sourceAddr
 		}
-		g.HandlePeerAnnounce(peerData, addr)
+		g.admitAnnouncedPeer(peerData, addr)
 	}
 }
@@ -152,14 +152,18 @@
 // Groups support hierarchical role-based permissions:
 //
 //	const (
-//	    RoleFounder    // Full control, can delete group
-//	    RoleModerator  // Can kick/ban users, moderate messages
-//	    RoleUser       // Can send messages, participate in chat
-//	    RoleObserver   // Can only read messages
+//	    RoleUser        // Can send messages, participate in chat
+//	    RoleTrustedUser // Can also pin messages and add reactions
+//	    RoleModerator   // Can kick/ban users, promote users to trusted
+//	    RoleAdmin       // Can change group name and privacy
+//	    RoleFounder     // Full control, cannot be demoted
 //	)
 //
-//	// Set peer role (requires Founder or Moderator privileges)
-//	err := group.SetPeerRole(peerID, group.RoleModerator)
+//	// Set peer role (requires Moderator privileges or higher)
+//	err := group.SetPeerRole(peerID, group.RoleTrustedUser)
+//
+// A peer can only grant roles strictly below its own; violations return an
+// error wrapping ErrInsufficientPrivileges.
 //
 //	// Get peer information
 //	role := group.GetPeerRole(peerID)
@@ -210,6 +214,67 @@
 //	// Kick a peer (requires Moderator privileges)
 //	err := group.KickPeer(peerID)
 //
+// # Ban Lists
+//
+// Bans are keyed by public key and may be permanent (zero duration) or
+// temporary. BanPeer bans a member with a reason and kicks it in one step;
+// only Moderators and Founders may ban or unban, and others get
+// ErrPermissionDenied. Banned peers that announce themselves are refused and
+// sent a peer_join_rejected message with JoinRejectBanned, which
+// JoinRejection turns into ErrBanned on their side. Entries marshal with
+// encoding/json; SetBanListPath loads an existing list and saves it again on
+// Leave, so bans survive a restart:
+//
+//	err := group.SetBanListPath("group-bans.json")
+//	err = group.BanPeer(peerID, "spam")                    // permanent, kicks
+//	err = group.BanPublicKey(peer.PublicKey, 24*time.Hour) // temporary
+//	banned, expiresAt := group.CheckBan(peer.PublicKey)
+//	err = group.UnbanPeer(peer.PublicKey)
+//
+// # Message History
+//
+// Each group keeps its most recent plaintext messages (1000 by default, see
+// SetHistorySize) so clients can page through what they missed. Every
+// message gets a locally assigned SequenceNumber that serves as the cursor
+// and stays valid as older messages are evicted:
+//
+//	msgs, next, err := group.GetMessageHistory(0, 50)
+//	msgs, next, err = group.GetMessageHistory(next, 50) // following page
+//
+// When a new peer announces itself with transport.CapabilityGroupHistory in
+// its capability flags, members send it their history in
+// PacketGroupHistorySync packets, which the newcomer applies with
+// HandleHistorySyncPacket. Messages it already holds are skipped.
+//
+// # Topic and Description
+//
+// Moderators and above can set a topic (up to 512 bytes) and a description
+// (up to 4096 bytes). Changes are broadcast in PacketGroupMetadata packets,
+// which receivers apply with HandleMetadataPacket. Group announcements
+// carry the first 128 bytes of the description so discovery listings can
+// show it before joining:
+//
+//	err := group.SetTopic("Release planning")
+//	err = group.SetDescription("Weekly sync for the release team")
+//	err = other.HandleMetadataPacket(packet.Data)
+//	topic := other.GetTopic()
+//
+// # Private Channels
+//
+// In groups created with CreateWithKeyPair, members can open private
+// channels for a subset of peers. The creator generates a session key and
+// sends it to each member encrypted to their public key in a
+// PacketGroupChannelKey packet; receivers install it with
+// HandleChannelKeyPacket. Channel messages are broadcast to the whole group
+// but only members can decrypt them:
+//
+//	channelID, err := group.CreatePrivateChannel([]uint32{adminA, adminB})
+//	err = group.SendChannelMessage(channelID, "admins only")
+//	msg, err := other.DecryptChannelMessage(broadcast)
+//
+// When a member is removed with RemoveChannelMember or kicked from the
+// group, the channel owner distributes a new key to the remaining members.
+//
 // # Deterministic Testing
 //
 // For reproducible test scenarios, use the TimeProvider interface:
@@ -234,6 +299,21 @@
 //   - Partial delivery failures are logged but don't block other peers
 //   - Results are aggregated and logged via structured logging
 //
+// # Ordered Delivery
+//
+// Because peers are served concurrently, receivers may see a sender's
+// broadcasts out of order. Each BroadcastMessage carries a per-sender
+// SequenceNumber, and HandleBroadcastPacket passes received packets through a
+// ReorderBuffer that holds early arrivals until the gap is filled:
+//
+//	chat.SetReorderConfig(64, 2*time.Second)
+//	msgs, err := chat.HandleBroadcastPacket(packet.Data)
+//	// periodically:
+//	msgs = append(msgs, chat.FlushReorderBuffer()...)
+//
+// Messages that cannot be ordered within the timeout, or that overflow the
+// buffer, are delivered with PossiblyReordered set.
+//
 // # Integration
 //
 // The group package integrates with core toxcore-go infrastructure:
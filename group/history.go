@@ -0,0 +1,369 @@
+package group
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/opd-ai/toxcore/transport"
+	"github.com/sirupsen/logrus"
+)
+
+func init() {
+	transport.RegisterCapability(transport.CapabilityGroupHistory, "group-history")
+}
+
+// DefaultHistorySize is the number of recent messages a group keeps for
+// GetMessageHistory unless SetHistorySize selects another size.
+const DefaultHistorySize = 1000
+
+// groupMessageType is the BroadcastMessage type of plaintext group messages.
+const groupMessageType = "group_message"
+
+const (
+	// historySyncHeaderSize is the chat ID, sender peer ID and entry count
+	// at the start of a PacketGroupHistorySync payload.
+	historySyncHeaderSize = 10
+	// historySyncEntryHeaderSize is the peer ID, sender sequence number,
+	// timestamp and text length preceding each entry's text.
+	historySyncEntryHeaderSize = 22
+	// historySyncMaxPayload bounds one PacketGroupHistorySync payload. It
+	// fits a single maximum-length message and stays below the UDP
+	// transport's 2048-byte read buffer.
+	historySyncMaxPayload = historySyncHeaderSize + historySyncEntryHeaderSize + 1372
+)
+
+var (
+	// ErrInvalidHistoryLimit is returned by GetMessageHistory when limit is
+	// not positive.
+	ErrInvalidHistoryLimit = errors.New("history limit must be positive")
+	// ErrMalformedHistorySync is returned when a PacketGroupHistorySync
+	// payload cannot be decoded.
+	ErrMalformedHistorySync = errors.New("malformed history sync packet")
+)
+
+// GroupMessage is a message kept in a group's history.
+type GroupMessage struct {
+	// SequenceNumber is assigned locally in arrival order and is the cursor
+	// for GetMessageHistory. Numbers are never reused, so a cursor stays
+	// valid while older messages are evicted.
+	SequenceNumber uint64
+	PeerID         uint32
+	Timestamp      time.Time
+	Text           string
+
+	// senderSequence is the sender's BroadcastMessage sequence number, used
+	// to recognize a message received both live and in a history sync.
+	senderSequence uint64
+}
+
+// historyKey identifies a message independently of where it was received.
+type historyKey struct {
+	peerID         uint32
+	senderSequence uint64
+	timestamp      int64
+}
+
+func (m *GroupMessage) key() historyKey {
+	return historyKey{peerID: m.PeerID, senderSequence: m.senderSequence, timestamp: m.Timestamp.UnixNano()}
+}
+
+// messageHistory is a circular buffer of the most recent group messages.
+// Sequence numbers are contiguous from the oldest held message to the
+// newest, so a cursor maps to a buffer position directly.
+type messageHistory struct {
+	mu      sync.RWMutex
+	entries []GroupMessage
+	start   int // Index of the oldest message
+	count   int
+	lastSeq uint64
+	keys    map[historyKey]struct{}
+}
+
+// newMessageHistory creates a history holding up to size messages. Values
+// <= 0 select DefaultHistorySize.
+func newMessageHistory(size int) *messageHistory {
+	if size <= 0 {
+		size = DefaultHistorySize
+	}
+	return &messageHistory{
+		entries: make([]GroupMessage, size),
+		keys:    make(map[historyKey]struct{}),
+	}
+}
+
+// add appends msg, evicting the oldest message when full, and reports
+// whether it was added. A message already held is ignored.
+func (h *messageHistory) add(msg GroupMessage) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	key := msg.key()
+	if _, dup := h.keys[key]; dup {
+		return false
+	}
+	if h.count == len(h.entries) {
+		delete(h.keys, h.entries[h.start].key())
+		h.start = (h.start + 1) % len(h.entries)
+		h.count--
+	}
+
+	h.lastSeq++
+	msg.SequenceNumber = h.lastSeq
+	h.entries[(h.start+h.count)%len(h.entries)] = msg
+	h.count++
+	h.keys[key] = struct{}{}
+	return true
+}
+
+// page returns up to limit messages with a sequence number above cursor,
+// oldest first, and the sequence number of the last one returned.
+func (h *messageHistory) page(cursor uint64, limit int) ([]GroupMessage, uint64) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	if h.count == 0 || cursor >= h.lastSeq {
+		return nil, cursor
+	}
+	skip := 0
+	if oldest := h.entries[h.start].SequenceNumber; cursor >= oldest {
+		skip = int(cursor - oldest + 1)
+	}
+
+	msgs := make([]GroupMessage, min(limit, h.count-skip))
+	for i := range msgs {
+		msgs[i] = h.entries[(h.start+skip+i)%len(h.entries)]
+	}
+	return msgs, msgs[len(msgs)-1].SequenceNumber
+}
+
+// all returns every held message, oldest first.
+func (h *messageHistory) all() []GroupMessage {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	msgs := make([]GroupMessage, h.count)
+	for i := range msgs {
+		msgs[i] = h.entries[(h.start+i)%len(h.entries)]
+	}
+	return msgs
+}
+
+// resize changes the capacity, keeping the newest messages and the sequence
+// numbering.
+func (h *messageHistory) resize(size int) {
+	if size <= 0 {
+		size = DefaultHistorySize
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	keep := min(size, h.count)
+	entries := make([]GroupMessage, size)
+	for i := 0; i < keep; i++ {
+		entries[i] = h.entries[(h.start+h.count-keep+i)%len(h.entries)]
+	}
+	for i := 0; i < h.count-keep; i++ {
+		delete(h.keys, h.entries[(h.start+i)%len(h.entries)].key())
+	}
+	h.entries, h.start, h.count = entries, 0, keep
+}
+
+// withOnSent passes the broadcast message to onSent once the broadcast
+// succeeded.
+func withOnSent(onSent func(msg *BroadcastMessage)) BroadcastOption {
+	return func(cfg *BroadcastConfig) {
+		cfg.onSent = onSent
+	}
+}
+
+// SetHistorySize sets how many recent messages the group keeps for
+// GetMessageHistory. Values <= 0 select DefaultHistorySize. Shrinking the
+// history drops the oldest messages; cursors remain valid.
+func (g *Chat) SetHistorySize(size int) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.history == nil {
+		g.history = newMessageHistory(size)
+		return
+	}
+	g.history.resize(size)
+}
+
+// getHistory returns the message history, creating it with defaults on first use.
+func (g *Chat) getHistory() *messageHistory {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.history == nil {
+		g.history = newMessageHistory(0)
+	}
+	return g.history
+}
+
+// GetMessageHistory returns up to limit messages received after cursor,
+// oldest first, and the cursor to pass to the next call. A cursor of zero
+// starts at the oldest message still held; a page shorter than limit means
+// the caller has caught up, and nextCursor then accepts later messages.
+//
+// Only plaintext group messages are kept. Groups using sender-key
+// encryption keep no history, since the join sync would carry it in the
+// clear.
+func (g *Chat) GetMessageHistory(cursor uint64, limit int) ([]GroupMessage, uint64, error) {
+	if limit <= 0 {
+		return nil, cursor, fmt.Errorf("%w: %d", ErrInvalidHistoryLimit, limit)
+	}
+	msgs, nextCursor := g.getHistory().page(cursor, limit)
+	return msgs, nextCursor, nil
+}
+
+// recordHistory adds msg to the history if it is a plaintext group message.
+func (g *Chat) recordHistory(msg *BroadcastMessage) {
+	if msg == nil || msg.Type != groupMessageType {
+		return
+	}
+	text, ok := msg.Data["message"].(string)
+	if !ok {
+		return
+	}
+	g.getHistory().add(GroupMessage{
+		PeerID:         msg.SenderID,
+		Timestamp:      msg.Timestamp,
+		Text:           text,
+		senderSequence: msg.SequenceNumber,
+	})
+}
+
+// sendHistorySync sends the current history to a newly joined peer in one
+// or more PacketGroupHistorySync packets. Failures are logged, since the
+// peer still receives new messages live.
+func (g *Chat) sendHistorySync(peerID uint32) {
+	g.mu.RLock()
+	history, tr, chatID, selfID := g.history, g.transport, g.ID, g.SelfPeerID
+	g.mu.RUnlock()
+	if history == nil || tr == nil {
+		return
+	}
+	msgs := history.all()
+	if len(msgs) == 0 {
+		return
+	}
+
+	for _, chunk := range encodeHistorySync(chatID, selfID, msgs) {
+		packet := &transport.Packet{PacketType: transport.PacketGroupHistorySync, Data: chunk}
+		if err := g.broadcastPeerUpdate(peerID, packet); err != nil {
+			logrus.WithFields(logrus.Fields{
+				"function": "sendHistorySync",
+				"group_id": chatID,
+				"peer_id":  peerID,
+				"error":    err.Error(),
+			}).Debug("Failed to send message history to new peer")
+			return
+		}
+	}
+	logrus.WithFields(logrus.Fields{
+		"function": "sendHistorySync",
+		"group_id": chatID,
+		"peer_id":  peerID,
+		"messages": len(msgs),
+	}).Debug("Sent message history to new peer")
+}
+
+// HandleHistorySyncPacket adds the messages from a received
+// PacketGroupHistorySync payload to the history. Messages already held,
+// for example because they also arrived live, are skipped. The sender must
+// be a known member of the group.
+func (g *Chat) HandleHistorySyncPacket(data []byte) error {
+	chatID, senderID, msgs, err := decodeHistorySync(data)
+	if err != nil {
+		return err
+	}
+	if chatID != g.ID {
+		return fmt.Errorf("history sync for group %d received by group %d", chatID, g.ID)
+	}
+
+	g.mu.RLock()
+	_, known := g.Peers[senderID]
+	g.mu.RUnlock()
+	if !known {
+		return fmt.Errorf("history sync from unknown peer %d", senderID)
+	}
+
+	history := g.getHistory()
+	for _, msg := range msgs {
+		history.add(msg)
+	}
+	return nil
+}
+
+// encodeHistorySync packs msgs into PacketGroupHistorySync payloads of at
+// most historySyncMaxPayload bytes:
+//
+//	chat ID (4) | sender ID (4) | count (2) | entries
+//	entry: peer ID (4) | sender sequence (8) | unix nanos (8) | text length (2) | text
+func encodeHistorySync(chatID, senderID uint32, msgs []GroupMessage) [][]byte {
+	var chunks [][]byte
+	var chunk []byte
+	count := 0
+	flush := func() {
+		if count > 0 {
+			binary.BigEndian.PutUint16(chunk[8:10], uint16(count))
+			chunks = append(chunks, chunk)
+		}
+		chunk, count = nil, 0
+	}
+
+	for _, msg := range msgs {
+		size := historySyncEntryHeaderSize + len(msg.Text)
+		if chunk != nil && len(chunk)+size > historySyncMaxPayload {
+			flush()
+		}
+		if chunk == nil {
+			chunk = make([]byte, historySyncHeaderSize, historySyncMaxPayload)
+			binary.BigEndian.PutUint32(chunk[0:4], chatID)
+			binary.BigEndian.PutUint32(chunk[4:8], senderID)
+		}
+		chunk = binary.BigEndian.AppendUint32(chunk, msg.PeerID)
+		chunk = binary.BigEndian.AppendUint64(chunk, msg.senderSequence)
+		chunk = binary.BigEndian.AppendUint64(chunk, uint64(msg.Timestamp.UnixNano()))
+		chunk = binary.BigEndian.AppendUint16(chunk, uint16(len(msg.Text)))
+		chunk = append(chunk, msg.Text...)
+		count++
+	}
+	flush()
+	return chunks
+}
+
+// decodeHistorySync parses a payload produced by encodeHistorySync.
+func decodeHistorySync(data []byte) (chatID, senderID uint32, msgs []GroupMessage, err error) {
+	if len(data) < historySyncHeaderSize {
+		return 0, 0, nil, fmt.Errorf("%w: %d bytes", ErrMalformedHistorySync, len(data))
+	}
+	chatID = binary.BigEndian.Uint32(data[0:4])
+	senderID = binary.BigEndian.Uint32(data[4:8])
+	count := int(binary.BigEndian.Uint16(data[8:10]))
+
+	rest := data[historySyncHeaderSize:]
+	msgs = make([]GroupMessage, 0, count)
+	for i := 0; i < count; i++ {
+		if len(rest) < historySyncEntryHeaderSize {
+			return 0, 0, nil, fmt.Errorf("%w: truncated entry %d", ErrMalformedHistorySync, i)
+		}
+		textLen := int(binary.BigEndian.Uint16(rest[20:22]))
+		if textLen > 1372 || len(rest) < historySyncEntryHeaderSize+textLen {
+			return 0, 0, nil, fmt.Errorf("%w: bad text length in entry %d", ErrMalformedHistorySync, i)
+		}
+		msgs = append(msgs, GroupMessage{
+			PeerID:         binary.BigEndian.Uint32(rest[0:4]),
+			senderSequence: binary.BigEndian.Uint64(rest[4:12]),
+			Timestamp:      time.Unix(0, int64(binary.BigEndian.Uint64(rest[12:20]))),
+			Text:           string(rest[historySyncEntryHeaderSize : historySyncEntryHeaderSize+textLen]),
+		})
+		rest = rest[historySyncEntryHeaderSize+textLen:]
+	}
+	if len(rest) != 0 {
+		return 0, 0, nil, fmt.Errorf("%w: %d trailing bytes", ErrMalformedHistorySync, len(rest))
+	}
+	return chatID, senderID, msgs, nil
+}
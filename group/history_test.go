@@ -0,0 +1,218 @@
+package group
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/opd-ai/toxcore/transport"
+)
+
+func newHistoryTestChat(trans transport.Transport) *Chat {
+	return &Chat{
+		ID:         88,
+		SelfPeerID: 1,
+		Peers: map[uint32]*Peer{
+			1: {ID: 1, Role: RoleFounder},
+			2: {ID: 2, Role: RoleUser, Connection: 2, Address: &mockAddr{address: "10.0.0.2:33445"}},
+		},
+		transport: trans,
+	}
+}
+
+// TestGetMessageHistoryPagination verifies cursor paging over sent messages
+func TestGetMessageHistoryPagination(t *testing.T) {
+	chat := newHistoryTestChat(&mockTransport{})
+	for i := 1; i <= 5; i++ {
+		if err := chat.SendMessage(fmt.Sprintf("message %d", i)); err != nil {
+			t.Fatalf("SendMessage failed: %v", err)
+		}
+	}
+
+	var got []string
+	cursor := uint64(0)
+	for {
+		page, next, err := chat.GetMessageHistory(cursor, 2)
+		if err != nil {
+			t.Fatalf("GetMessageHistory failed: %v", err)
+		}
+		for _, msg := range page {
+			if msg.PeerID != 1 || msg.Timestamp.IsZero() {
+				t.Errorf("unexpected message %+v", msg)
+			}
+			got = append(got, msg.Text)
+		}
+		if len(page) < 2 {
+			if next != cursor+uint64(len(page)) {
+				t.Errorf("expected final cursor %d, got %d", cursor+uint64(len(page)), next)
+			}
+			break
+		}
+		cursor = next
+	}
+	if strings.Join(got, ",") != "message 1,message 2,message 3,message 4,message 5" {
+		t.Errorf("unexpected history %v", got)
+	}
+
+	if _, _, err := chat.GetMessageHistory(0, 0); !errors.Is(err, ErrInvalidHistoryLimit) {
+		t.Errorf("expected ErrInvalidHistoryLimit, got %v", err)
+	}
+}
+
+// TestGetMessageHistoryCursorSurvivesEviction verifies that a cursor keeps
+// its position while older messages are evicted
+func TestGetMessageHistoryCursorSurvivesEviction(t *testing.T) {
+	chat := newHistoryTestChat(&mockTransport{})
+	chat.SetHistorySize(3)
+	for i := 1; i <= 3; i++ {
+		chat.SendMessage(fmt.Sprintf("m%d", i))
+	}
+	page, cursor, _ := chat.GetMessageHistory(0, 2)
+	if len(page) != 2 || page[1].Text != "m2" {
+		t.Fatalf("unexpected first page %+v", page)
+	}
+
+	chat.SendMessage("m4")
+	chat.SendMessage("m5")
+	page, _, _ = chat.GetMessageHistory(cursor, 10)
+	if len(page) != 3 || page[0].Text != "m3" || page[2].Text != "m5" {
+		t.Errorf("expected m3..m5 after the cursor, got %+v", page)
+	}
+
+	page, _, _ = chat.GetMessageHistory(0, 10)
+	if len(page) != 3 || page[0].Text != "m3" {
+		t.Errorf("expected the oldest held message to be m3, got %+v", page)
+	}
+}
+
+// TestHistorySyncOnMidConversationJoin verifies that a peer joining after
+// messages were exchanged receives the backlog and no duplicates
+func TestHistorySyncOnMidConversationJoin(t *testing.T) {
+	trans := &mockTransport{}
+	founder := newHistoryTestChat(trans)
+
+	// Peer 2 talks before the newcomer joins
+	member := &Chat{ID: founder.ID, SelfPeerID: 2, Peers: map[uint32]*Peer{1: {ID: 1}, 2: {ID: 2}}}
+	reply, err := member.createBroadcastMessage(groupMessageType, GroupMessageData{SenderID: 2, Message: "hi founder"}.ToMap())
+	if err != nil {
+		t.Fatal(err)
+	}
+	founder.SendMessage("welcome")
+	if _, err := founder.HandleBroadcastPacket(reply); err != nil {
+		t.Fatalf("HandleBroadcastPacket failed: %v", err)
+	}
+	founder.SendMessage(strings.Repeat("x", 1372)) // Forces a second sync chunk
+
+	// A newcomer that does not advertise history support gets no sync
+	if !founder.HandlePeerAnnounce(PeerAnnounceData{PeerID: 5, Connection: 2}, &mockAddr{address: "10.0.0.5:33445"}) {
+		t.Fatal("expected peer 5 to be new")
+	}
+	if n := len(sentPackets(trans, transport.PacketGroupHistorySync, 5)); n != 0 {
+		t.Errorf("expected no history sync to a peer without the capability, got %d", n)
+	}
+
+	joinerAddr := &mockAddr{address: "10.0.0.3:33445"}
+	announce := PeerAnnounceData{PeerID: 3, Connection: 2, Capabilities: transport.CapabilityGroupHistory.Mask()}
+	if !founder.HandlePeerAnnounce(announce, joinerAddr) {
+		t.Fatal("expected peer 3 to be new")
+	}
+	syncs := sentPackets(trans, transport.PacketGroupHistorySync, 3)
+	if len(syncs) != 2 {
+		t.Fatalf("expected 2 history sync packets to the new peer, got %d", len(syncs))
+	}
+
+	joiner := &Chat{ID: founder.ID, SelfPeerID: 3, Peers: map[uint32]*Peer{1: {ID: 1}, 3: {ID: 3}}}
+	for _, data := range syncs {
+		if err := joiner.HandleHistorySyncPacket(data); err != nil {
+			t.Fatalf("HandleHistorySyncPacket failed: %v", err)
+		}
+	}
+	// A second copy, as sent by another member, adds nothing
+	if err := joiner.HandleHistorySyncPacket(syncs[0]); err != nil {
+		t.Fatal(err)
+	}
+
+	founderHistory, _, _ := founder.GetMessageHistory(0, 10)
+	joinerHistory, _, _ := joiner.GetMessageHistory(0, 10)
+	if len(joinerHistory) != 3 {
+		t.Fatalf("expected the joiner to hold 3 messages, got %d", len(joinerHistory))
+	}
+	for i, msg := range joinerHistory {
+		want := founderHistory[i]
+		if msg.PeerID != want.PeerID || msg.Text != want.Text || !msg.Timestamp.Equal(want.Timestamp) {
+			t.Errorf("message %d: expected %d %q, got %d %q", i, want.PeerID, want.Text, msg.PeerID, msg.Text)
+		}
+	}
+	if joinerHistory[1].PeerID != 2 || joinerHistory[1].Text != "hi founder" {
+		t.Errorf("expected the member's message in the backlog, got %+v", joinerHistory[1])
+	}
+
+	// Peers learned from a peer list are existing members and get no sync
+	founder.HandlePeerListResponse(PeerListResponseData{ResponderID: 4, Peers: []PeerAnnounceData{{PeerID: 4, Connection: 2, Capabilities: transport.CapabilityGroupHistory.Mask()}}}, &mockAddr{address: "10.0.0.4:33445"})
+	if n := len(sentPackets(trans, transport.PacketGroupHistorySync, 4)); n != 0 {
+		t.Errorf("expected no history sync to an existing member, got %d", n)
+	}
+}
+
+// TestHandleHistorySyncPacketRejectsInvalid verifies sender and format checks
+func TestHandleHistorySyncPacketRejectsInvalid(t *testing.T) {
+	chat := newHistoryTestChat(nil)
+	msgs := []GroupMessage{{PeerID: 2, Text: "hello"}}
+
+	if err := chat.HandleHistorySyncPacket(encodeHistorySync(chat.ID, 9, msgs)[0]); err == nil {
+		t.Error("expected a sync from an unknown peer to be rejected")
+	}
+	if err := chat.HandleHistorySyncPacket(encodeHistorySync(chat.ID+1, 2, msgs)[0]); err == nil {
+		t.Error("expected a sync for another group to be rejected")
+	}
+	data := encodeHistorySync(chat.ID, 2, msgs)[0]
+	if err := chat.HandleHistorySyncPacket(data[:len(data)-1]); !errors.Is(err, ErrMalformedHistorySync) {
+		t.Errorf("expected ErrMalformedHistorySync, got %v", err)
+	}
+	if page, _, _ := chat.GetMessageHistory(0, 10); len(page) != 0 {
+		t.Errorf("expected no messages from rejected syncs, got %d", len(page))
+	}
+}
+
+// TestGetMessageHistoryConcurrent exercises paging while messages arrive
+func TestGetMessageHistoryConcurrent(t *testing.T) {
+	chat := newHistoryTestChat(&mockTransport{})
+	chat.SetHistorySize(50)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 200; i++ {
+			chat.SendMessage(fmt.Sprintf("m%d", i))
+		}
+	}()
+	for r := 0; r < 4; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			cursor := uint64(0)
+			for i := 0; i < 200; i++ {
+				page, next, err := chat.GetMessageHistory(cursor, 7)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				for j := 1; j < len(page); j++ {
+					if page[j].SequenceNumber != page[j-1].SequenceNumber+1 {
+						t.Errorf("non-contiguous page %d -> %d", page[j-1].SequenceNumber, page[j].SequenceNumber)
+						return
+					}
+				}
+				if len(page) > 0 && page[0].SequenceNumber <= cursor {
+					t.Errorf("page starts at %d, not after cursor %d", page[0].SequenceNumber, cursor)
+					return
+				}
+				cursor = next
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,165 @@
+package group
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/opd-ai/toxcore/transport"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// MaxTopicLength is the maximum group topic length in bytes.
+	MaxTopicLength = 512
+	// MaxDescriptionLength is the maximum group description length in bytes.
+	MaxDescriptionLength = 4096
+)
+
+// metadataUpdateType is the BroadcastMessage type of topic and description
+// changes sent in PacketGroupMetadata packets.
+const metadataUpdateType = "group_metadata_change"
+
+var (
+	// ErrTopicTooLong is returned when a topic exceeds MaxTopicLength.
+	ErrTopicTooLong = errors.New("group topic too long")
+	// ErrDescriptionTooLong is returned when a description exceeds
+	// MaxDescriptionLength.
+	ErrDescriptionTooLong = errors.New("group description too long")
+)
+
+// GroupMetadataChangeData represents the data payload for a topic or
+// description change broadcast. Both fields carry the current values so
+// receivers can apply the update as-is.
+type GroupMetadataChangeData struct {
+	Topic       string `json:"topic"`
+	Description string `json:"description"`
+}
+
+// ToMap converts GroupMetadataChangeData to map representation.
+func (d GroupMetadataChangeData) ToMap() map[string]interface{} {
+	return map[string]interface{}{
+		"topic":       d.Topic,
+		"description": d.Description,
+	}
+}
+
+// withPacketType sends the broadcast with packetType instead of
+// PacketGroupBroadcast.
+func withPacketType(packetType transport.PacketType) BroadcastOption {
+	return func(cfg *BroadcastConfig) {
+		cfg.packetType = packetType
+	}
+}
+
+// SetTopic changes the group's topic and broadcasts it to connected peers.
+// The topic may be at most MaxTopicLength bytes. Requires Moderator role or
+// higher.
+func (g *Chat) SetTopic(topic string) error {
+	if len(topic) > MaxTopicLength {
+		return fmt.Errorf("%w: %d bytes (max %d)", ErrTopicTooLong, len(topic), MaxTopicLength)
+	}
+
+	g.mu.Lock()
+	if _, err := g.requireSelfRoleLocked(RoleModerator, "change group topic"); err != nil {
+		g.mu.Unlock()
+		return err
+	}
+	g.topic = topic
+	data := GroupMetadataChangeData{Topic: g.topic, Description: g.description}
+	g.mu.Unlock()
+
+	if err := g.broadcastMetadata(data); err != nil {
+		return fmt.Errorf("failed to broadcast topic change: %w", err)
+	}
+	return nil
+}
+
+// SetDescription changes the group's description, broadcasts it to
+// connected peers and re-announces the group so discovery listings show the
+// first dht.MaxAnnouncementDescriptionLen bytes. The description may be at
+// most MaxDescriptionLength bytes. Requires Moderator role or higher.
+func (g *Chat) SetDescription(desc string) error {
+	if len(desc) > MaxDescriptionLength {
+		return fmt.Errorf("%w: %d bytes (max %d)", ErrDescriptionTooLong, len(desc), MaxDescriptionLength)
+	}
+
+	g.mu.Lock()
+	if _, err := g.requireSelfRoleLocked(RoleModerator, "change group description"); err != nil {
+		g.mu.Unlock()
+		return err
+	}
+	g.description = desc
+	data := GroupMetadataChangeData{Topic: g.topic, Description: g.description}
+	info := &GroupInfo{Name: g.Name, Type: g.Type, Privacy: g.Privacy, Description: desc}
+	dhtRouting, tr := g.dht, g.transport
+	g.mu.Unlock()
+
+	registerGroup(g.ID, info, dhtRouting, tr)
+
+	if err := g.broadcastMetadata(data); err != nil {
+		return fmt.Errorf("failed to broadcast description change: %w", err)
+	}
+	return nil
+}
+
+// GetTopic returns the group's topic.
+func (g *Chat) GetTopic() string {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.topic
+}
+
+// GetDescription returns the group's description.
+func (g *Chat) GetDescription() string {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.description
+}
+
+// broadcastMetadata sends the current topic and description to connected
+// peers as a PacketGroupMetadata packet.
+func (g *Chat) broadcastMetadata(data GroupMetadataChangeData) error {
+	return g.broadcastGroupUpdateWithOptions(metadataUpdateType, data.ToMap(), withPacketType(transport.PacketGroupMetadata))
+}
+
+// HandleMetadataPacket applies a received PacketGroupMetadata payload. The
+// update is rejected with ErrInsufficientPrivileges unless the sender is a
+// known member with Moderator role or higher.
+func (g *Chat) HandleMetadataPacket(data []byte) error {
+	var msg BroadcastMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return fmt.Errorf("failed to decode metadata message: %w", err)
+	}
+	if msg.ChatID != g.ID {
+		return fmt.Errorf("metadata for group %d received by group %d", msg.ChatID, g.ID)
+	}
+	if msg.Type != metadataUpdateType {
+		return fmt.Errorf("unexpected metadata message type %q", msg.Type)
+	}
+
+	topic, _ := msg.Data["topic"].(string)
+	desc, _ := msg.Data["description"].(string)
+	if len(topic) > MaxTopicLength {
+		return fmt.Errorf("%w: %d bytes (max %d)", ErrTopicTooLong, len(topic), MaxTopicLength)
+	}
+	if len(desc) > MaxDescriptionLength {
+		return fmt.Errorf("%w: %d bytes (max %d)", ErrDescriptionTooLong, len(desc), MaxDescriptionLength)
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	sender, exists := g.Peers[msg.SenderID]
+	if !exists || !sender.Role.atLeast(RoleModerator) {
+		logrus.WithFields(logrus.Fields{
+			"function":  "HandleMetadataPacket",
+			"group_id":  g.ID,
+			"sender_id": msg.SenderID,
+		}).Warn("Rejected group metadata change from unprivileged peer")
+		return fmt.Errorf("%w: peer %d cannot change group metadata", ErrInsufficientPrivileges, msg.SenderID)
+	}
+
+	g.topic = topic
+	g.description = desc
+	return nil
+}
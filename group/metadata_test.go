@@ -0,0 +1,126 @@
+package group
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/opd-ai/toxcore/transport"
+)
+
+func newMetadataTestChat(role Role, trans transport.Transport) *Chat {
+	return &Chat{
+		ID:         77,
+		SelfPeerID: 1,
+		Peers: map[uint32]*Peer{
+			1: {ID: 1, Role: role},
+			2: {ID: 2, Role: RoleUser, Connection: 2, Address: &mockAddr{address: "192.168.1.2:33445"}},
+		},
+		transport: trans,
+	}
+}
+
+// TestSetTopicAndDescriptionBroadcast verifies local updates and the
+// PacketGroupMetadata broadcast
+func TestSetTopicAndDescriptionBroadcast(t *testing.T) {
+	mockTrans := &mockTransport{}
+	chat := newMetadataTestChat(RoleModerator, mockTrans)
+	defer unregisterGroup(chat.ID)
+
+	if err := chat.SetTopic("release planning"); err != nil {
+		t.Fatalf("SetTopic failed: %v", err)
+	}
+	if err := chat.SetDescription("Weekly sync for the release team"); err != nil {
+		t.Fatalf("SetDescription failed: %v", err)
+	}
+	if chat.GetTopic() != "release planning" {
+		t.Errorf("unexpected topic %q", chat.GetTopic())
+	}
+	if chat.GetDescription() != "Weekly sync for the release team" {
+		t.Errorf("unexpected description %q", chat.GetDescription())
+	}
+
+	calls := mockTrans.getSendCalls()
+	if len(calls) != 2 {
+		t.Fatalf("expected 2 metadata broadcasts, got %d", len(calls))
+	}
+	for _, call := range calls {
+		if call.packet.PacketType != transport.PacketGroupMetadata {
+			t.Errorf("expected PacketGroupMetadata, got %v", call.packet.PacketType)
+		}
+	}
+
+	receiver := &Chat{
+		ID:    chat.ID,
+		Peers: map[uint32]*Peer{1: {ID: 1, Role: RoleModerator}},
+	}
+	if err := receiver.HandleMetadataPacket(calls[1].packet.Data); err != nil {
+		t.Fatalf("HandleMetadataPacket failed: %v", err)
+	}
+	if receiver.GetTopic() != "release planning" || receiver.GetDescription() != "Weekly sync for the release team" {
+		t.Errorf("receiver got topic %q description %q", receiver.GetTopic(), receiver.GetDescription())
+	}
+}
+
+// TestSetTopicRequiresModerator verifies role enforcement on both ends
+func TestSetTopicRequiresModerator(t *testing.T) {
+	chat := newMetadataTestChat(RoleTrustedUser, &mockTransport{})
+	if err := chat.SetTopic("topic"); !errors.Is(err, ErrInsufficientPrivileges) {
+		t.Errorf("expected ErrInsufficientPrivileges for topic, got %v", err)
+	}
+	if err := chat.SetDescription("desc"); !errors.Is(err, ErrInsufficientPrivileges) {
+		t.Errorf("expected ErrInsufficientPrivileges for description, got %v", err)
+	}
+
+	// A message from peer 1 must be rejected by a receiver that knows peer 1
+	// as a regular user.
+	sender := newMetadataTestChat(RoleFounder, &mockTransport{})
+	msg, err := sender.createBroadcastMessage(metadataUpdateType, GroupMetadataChangeData{Topic: "spoofed"}.ToMap())
+	if err != nil {
+		t.Fatalf("createBroadcastMessage failed: %v", err)
+	}
+	receiver := &Chat{ID: sender.ID, Peers: map[uint32]*Peer{1: {ID: 1, Role: RoleUser}}}
+	if err := receiver.HandleMetadataPacket(msg); !errors.Is(err, ErrInsufficientPrivileges) {
+		t.Errorf("expected ErrInsufficientPrivileges on receive, got %v", err)
+	}
+	if receiver.GetTopic() != "" {
+		t.Errorf("rejected update changed topic to %q", receiver.GetTopic())
+	}
+}
+
+// TestSetTopicAndDescriptionLimits verifies the byte length limits
+func TestSetTopicAndDescriptionLimits(t *testing.T) {
+	chat := newMetadataTestChat(RoleFounder, &mockTransport{})
+	defer unregisterGroup(chat.ID)
+
+	if err := chat.SetTopic(strings.Repeat("t", MaxTopicLength)); err != nil {
+		t.Errorf("topic at limit rejected: %v", err)
+	}
+	if err := chat.SetTopic(strings.Repeat("t", MaxTopicLength+1)); !errors.Is(err, ErrTopicTooLong) {
+		t.Errorf("expected ErrTopicTooLong, got %v", err)
+	}
+	if err := chat.SetDescription(strings.Repeat("d", MaxDescriptionLength)); err != nil {
+		t.Errorf("description at limit rejected: %v", err)
+	}
+	if err := chat.SetDescription(strings.Repeat("d", MaxDescriptionLength+1)); !errors.Is(err, ErrDescriptionTooLong) {
+		t.Errorf("expected ErrDescriptionTooLong, got %v", err)
+	}
+}
+
+// TestSetDescriptionUpdatesRegistry verifies discovery sees the description
+func TestSetDescriptionUpdatesRegistry(t *testing.T) {
+	chat := newMetadataTestChat(RoleFounder, &mockTransport{})
+	chat.Name = "Release"
+	defer unregisterGroup(chat.ID)
+
+	if err := chat.SetDescription("Weekly sync"); err != nil {
+		t.Fatalf("SetDescription failed: %v", err)
+	}
+	info, err := queryDHTForGroup(chat.ID, nil, nil, 0)
+	if err != nil {
+		t.Fatalf("queryDHTForGroup failed: %v", err)
+	}
+	if info.Name != "Release" || info.Description != "Weekly sync" {
+		t.Errorf("unexpected group info %+v", info)
+	}
+}
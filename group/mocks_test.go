@@ -164,3 +164,29 @@ func (m *mockFriendResolver) resolve(friendID uint32) (net.Addr, error) {
 	}
 	return addr, nil
 }
+
+// fakeTimeProvider is a manually advanced TimeProvider for deterministic tests
+type fakeTimeProvider struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func newFakeTimeProvider() *fakeTimeProvider {
+	return &fakeTimeProvider{now: time.Unix(1700000000, 0)}
+}
+
+func (f *fakeTimeProvider) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+func (f *fakeTimeProvider) Since(t time.Time) time.Duration {
+	return f.Now().Sub(t)
+}
+
+func (f *fakeTimeProvider) Advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now = f.now.Add(d)
+}
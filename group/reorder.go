@@ -0,0 +1,281 @@
+package group
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultReorderBufferSize is the default number of out-of-order messages
+	// buffered per sender before the oldest gap is skipped.
+	DefaultReorderBufferSize = 64
+	// DefaultReorderTimeout is the default time a buffered message waits for
+	// missing predecessors before it is delivered as possibly reordered.
+	DefaultReorderTimeout = 2 * time.Second
+)
+
+// bufferedMessage is a broadcast message waiting for its predecessors.
+type bufferedMessage struct {
+	msg     *BroadcastMessage
+	arrived time.Time
+}
+
+// senderStream tracks the delivery position for a single sender.
+type senderStream struct {
+	next    uint64
+	pending map[uint64]*bufferedMessage
+}
+
+// ReorderBuffer restores per-sender ordering of group broadcasts.
+//
+// The worker-pool broadcast delivers packets to peers concurrently, so a
+// receiver may observe a sender's messages out of order. Each outgoing
+// BroadcastMessage carries a monotonically increasing SequenceNumber and the
+// ReorderBuffer holds early arrivals until the gap before them is filled,
+// following the same bounded-buffer approach as the RTP jitter buffer.
+//
+// Memory use is capped at BufferSize pending messages per sender. When the cap
+// is reached, or a pending message has waited longer than Timeout, the buffer
+// skips the gap and releases the message with PossiblyReordered set.
+type ReorderBuffer struct {
+	mu           sync.Mutex
+	bufferSize   int
+	timeout      time.Duration
+	streams      map[uint32]*senderStream
+	timeProvider TimeProvider
+}
+
+// NewReorderBuffer creates a reorder buffer.
+//
+// Parameters:
+//   - bufferSize: Maximum pending messages per sender (<= 0 uses DefaultReorderBufferSize)
+//   - timeout: Maximum wait for missing messages (<= 0 uses DefaultReorderTimeout)
+//   - tp: Time provider (nil uses the package default)
+func NewReorderBuffer(bufferSize int, timeout time.Duration, tp TimeProvider) *ReorderBuffer {
+	if bufferSize <= 0 {
+		bufferSize = DefaultReorderBufferSize
+	}
+	if timeout <= 0 {
+		timeout = DefaultReorderTimeout
+	}
+	if tp == nil {
+		tp = getDefaultTimeProvider()
+	}
+	return &ReorderBuffer{
+		bufferSize:   bufferSize,
+		timeout:      timeout,
+		streams:      make(map[uint32]*senderStream),
+		timeProvider: tp,
+	}
+}
+
+// Push adds a received message and returns the messages that are now ready
+// for delivery, in sender order.
+//
+// Messages without a sequence number (from senders predating sequencing) are
+// delivered immediately. Messages older than the current delivery position
+// are delivered immediately with PossiblyReordered set.
+func (rb *ReorderBuffer) Push(msg *BroadcastMessage) []*BroadcastMessage {
+	if msg == nil {
+		return nil
+	}
+
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+
+	if msg.SequenceNumber == 0 {
+		return []*BroadcastMessage{msg}
+	}
+
+	stream, exists := rb.streams[msg.SenderID]
+	if !exists {
+		stream = &senderStream{
+			next:    msg.SequenceNumber,
+			pending: make(map[uint64]*bufferedMessage),
+		}
+		rb.streams[msg.SenderID] = stream
+	}
+
+	switch {
+	case msg.SequenceNumber < stream.next:
+		msg.PossiblyReordered = true
+		return []*BroadcastMessage{msg}
+	case msg.SequenceNumber == stream.next:
+		stream.next++
+		return append([]*BroadcastMessage{msg}, stream.drain()...)
+	}
+
+	if _, dup := stream.pending[msg.SequenceNumber]; dup {
+		return nil
+	}
+	stream.pending[msg.SequenceNumber] = &bufferedMessage{msg: msg, arrived: rb.timeProvider.Now()}
+
+	if len(stream.pending) > rb.bufferSize {
+		logrus.WithFields(logrus.Fields{
+			"function":  "ReorderBuffer.Push",
+			"sender_id": msg.SenderID,
+			"pending":   len(stream.pending),
+			"expected":  stream.next,
+		}).Debug("Reorder buffer full, skipping sequence gap")
+		return stream.skipGap()
+	}
+	return nil
+}
+
+// Expire releases messages that have waited longer than the reorder timeout.
+// Callers should invoke it periodically, e.g. from their iteration loop.
+func (rb *ReorderBuffer) Expire() []*BroadcastMessage {
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+
+	now := rb.timeProvider.Now()
+	var released []*BroadcastMessage
+	for _, stream := range rb.streams {
+		for stream.hasExpired(now, rb.timeout) {
+			released = append(released, stream.skipGap()...)
+		}
+	}
+	return released
+}
+
+// Len returns the total number of messages waiting in the buffer.
+func (rb *ReorderBuffer) Len() int {
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+
+	total := 0
+	for _, stream := range rb.streams {
+		total += len(stream.pending)
+	}
+	return total
+}
+
+// RemoveSender discards ordering state for a sender that left the group.
+func (rb *ReorderBuffer) RemoveSender(senderID uint32) {
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+	delete(rb.streams, senderID)
+}
+
+// drain releases consecutive pending messages starting at s.next.
+func (s *senderStream) drain() []*BroadcastMessage {
+	var released []*BroadcastMessage
+	for {
+		entry, ok := s.pending[s.next]
+		if !ok {
+			return released
+		}
+		delete(s.pending, s.next)
+		released = append(released, entry.msg)
+		s.next++
+	}
+}
+
+// skipGap advances past the missing sequence range to the lowest pending
+// message, flags it as possibly reordered, and drains what follows it.
+func (s *senderStream) skipGap() []*BroadcastMessage {
+	lowest, found := s.lowestPending()
+	if !found {
+		return nil
+	}
+	s.next = lowest
+	released := s.drain()
+	if len(released) > 0 {
+		released[0].PossiblyReordered = true
+	}
+	return released
+}
+
+// lowestPending returns the smallest buffered sequence number.
+func (s *senderStream) lowestPending() (uint64, bool) {
+	var lowest uint64
+	found := false
+	for seq := range s.pending {
+		if !found || seq < lowest {
+			lowest = seq
+			found = true
+		}
+	}
+	return lowest, found
+}
+
+// hasExpired reports whether any pending message has exceeded timeout.
+func (s *senderStream) hasExpired(now time.Time, timeout time.Duration) bool {
+	for _, entry := range s.pending {
+		if now.Sub(entry.arrived) >= timeout {
+			return true
+		}
+	}
+	return false
+}
+
+// SetReorderConfig configures the receive-side reorder buffer.
+// Values <= 0 select DefaultReorderBufferSize and DefaultReorderTimeout.
+// Any messages currently buffered are discarded.
+func (g *Chat) SetReorderConfig(bufferSize int, timeout time.Duration) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.reorderBuffer = NewReorderBuffer(bufferSize, timeout, g.getTimeProvider())
+}
+
+// getReorderBuffer returns the reorder buffer, creating it with defaults on first use.
+func (g *Chat) getReorderBuffer() *ReorderBuffer {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.reorderBuffer == nil {
+		g.reorderBuffer = NewReorderBuffer(0, 0, g.getTimeProvider())
+	}
+	return g.reorderBuffer
+}
+
+// HandleBroadcastPacket decodes a received PacketGroupBroadcast payload and
+// passes it through the reorder buffer. It returns the messages that are
+// ready for in-order delivery; the slice is empty while a gap is pending.
+// Delivered group messages are added to the message history.
+func (g *Chat) HandleBroadcastPacket(data []byte) ([]*BroadcastMessage, error) {
+	var msg BroadcastMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return nil, fmt.Errorf("failed to decode broadcast message: %w", err)
+	}
+	if msg.ChatID != g.ID {
+		return nil, fmt.Errorf("broadcast for group %d received by group %d", msg.ChatID, g.ID)
+	}
+	rb := g.getReorderBuffer()
+	msgs := g.recordDelivered(rb.Push(&msg))
+	forgetDepartedSenders(rb, msgs)
+	return msgs, nil
+}
+
+// forgetDepartedSenders drops the ordering state of peers whose leave or
+// kick is among msgs, so streams of departed senders do not accumulate.
+func forgetDepartedSenders(rb *ReorderBuffer, msgs []*BroadcastMessage) {
+	for _, msg := range msgs {
+		switch msg.Type {
+		case "peer_leave":
+			rb.RemoveSender(msg.SenderID)
+		case "peer_kick":
+			if kicked, ok := msg.Data["kicked_peer_id"].(float64); ok {
+				rb.RemoveSender(uint32(kicked))
+			}
+		}
+	}
+}
+
+// FlushReorderBuffer returns buffered messages whose reorder timeout has
+// elapsed. They are flagged with PossiblyReordered.
+func (g *Chat) FlushReorderBuffer() []*BroadcastMessage {
+	return g.recordDelivered(g.getReorderBuffer().Expire())
+}
+
+// recordDelivered adds the group messages among msgs to the message history
+// and returns msgs.
+func (g *Chat) recordDelivered(msgs []*BroadcastMessage) []*BroadcastMessage {
+	for _, msg := range msgs {
+		g.recordHistory(msg)
+	}
+	return msgs
+}
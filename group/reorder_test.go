@@ -0,0 +1,240 @@
+package group
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func seqMsg(sender uint32, seq uint64) *BroadcastMessage {
+	return &BroadcastMessage{Type: "group_message", ChatID: 1, SenderID: sender, SequenceNumber: seq}
+}
+
+func sequences(msgs []*BroadcastMessage) []uint64 {
+	out := make([]uint64, len(msgs))
+	for i, m := range msgs {
+		out[i] = m.SequenceNumber
+	}
+	return out
+}
+
+func equalSeqs(a, b []uint64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// TestReorderBufferRestoresOrder verifies that out-of-order messages are held until gaps fill
+func TestReorderBufferRestoresOrder(t *testing.T) {
+	rb := NewReorderBuffer(0, 0, newFakeTimeProvider())
+
+	if got := sequences(rb.Push(seqMsg(7, 1))); !equalSeqs(got, []uint64{1}) {
+		t.Fatalf("first message: got %v", got)
+	}
+	if got := rb.Push(seqMsg(7, 3)); len(got) != 0 {
+		t.Fatalf("expected seq 3 to be buffered, got %v", sequences(got))
+	}
+	if got := rb.Push(seqMsg(7, 4)); len(got) != 0 {
+		t.Fatalf("expected seq 4 to be buffered, got %v", sequences(got))
+	}
+	if rb.Len() != 2 {
+		t.Fatalf("expected 2 pending, got %d", rb.Len())
+	}
+
+	got := rb.Push(seqMsg(7, 2))
+	if !equalSeqs(sequences(got), []uint64{2, 3, 4}) {
+		t.Fatalf("expected [2 3 4], got %v", sequences(got))
+	}
+	for _, m := range got {
+		if m.PossiblyReordered {
+			t.Errorf("seq %d unexpectedly flagged as reordered", m.SequenceNumber)
+		}
+	}
+	if rb.Len() != 0 {
+		t.Errorf("expected empty buffer, got %d", rb.Len())
+	}
+}
+
+// TestReorderBufferIndependentSenders verifies per-sender sequencing
+func TestReorderBufferIndependentSenders(t *testing.T) {
+	rb := NewReorderBuffer(0, 0, newFakeTimeProvider())
+	rb.Push(seqMsg(1, 10))
+	if got := rb.Push(seqMsg(2, 5)); !equalSeqs(sequences(got), []uint64{5}) {
+		t.Fatalf("sender 2 should not wait on sender 1, got %v", sequences(got))
+	}
+}
+
+// TestReorderBufferTimeout verifies delivery with the reordered flag after the timeout
+func TestReorderBufferTimeout(t *testing.T) {
+	tp := newFakeTimeProvider()
+	rb := NewReorderBuffer(0, 500*time.Millisecond, tp)
+	rb.Push(seqMsg(1, 1))
+	rb.Push(seqMsg(1, 3))
+	rb.Push(seqMsg(1, 4))
+
+	if got := rb.Expire(); len(got) != 0 {
+		t.Fatalf("expected nothing before timeout, got %v", sequences(got))
+	}
+
+	tp.Advance(600 * time.Millisecond)
+	got := rb.Expire()
+	if !equalSeqs(sequences(got), []uint64{3, 4}) {
+		t.Fatalf("expected [3 4], got %v", sequences(got))
+	}
+	if !got[0].PossiblyReordered {
+		t.Error("expected first message after gap to be flagged")
+	}
+
+	late := rb.Push(seqMsg(1, 2))
+	if len(late) != 1 || !late[0].PossiblyReordered {
+		t.Fatal("expected late message to be delivered flagged")
+	}
+}
+
+// TestReorderBufferCapacity verifies that the buffer size caps pending messages
+func TestReorderBufferCapacity(t *testing.T) {
+	rb := NewReorderBuffer(2, time.Hour, newFakeTimeProvider())
+	rb.Push(seqMsg(1, 1))
+	rb.Push(seqMsg(1, 3))
+	rb.Push(seqMsg(1, 4))
+
+	got := rb.Push(seqMsg(1, 6))
+	if !equalSeqs(sequences(got), []uint64{3, 4}) {
+		t.Fatalf("expected overflow to release [3 4], got %v", sequences(got))
+	}
+	if rb.Len() != 1 {
+		t.Errorf("expected 1 pending after overflow, got %d", rb.Len())
+	}
+}
+
+// TestReorderBufferUnsequenced verifies legacy messages bypass buffering
+func TestReorderBufferUnsequenced(t *testing.T) {
+	rb := NewReorderBuffer(0, 0, nil)
+	if got := rb.Push(seqMsg(1, 0)); len(got) != 1 {
+		t.Fatalf("expected immediate delivery, got %d messages", len(got))
+	}
+}
+
+// TestBroadcastSequenceNumbers verifies outgoing messages carry increasing sequence numbers
+func TestBroadcastSequenceNumbers(t *testing.T) {
+	chat := &Chat{ID: 1, SelfPeerID: 9, Peers: map[uint32]*Peer{}}
+
+	var prev uint64
+	for i := 0; i < 3; i++ {
+		data, err := chat.createBroadcastMessage("test", nil)
+		if err != nil {
+			t.Fatalf("createBroadcastMessage failed: %v", err)
+		}
+		var msg BroadcastMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			t.Fatalf("unmarshal failed: %v", err)
+		}
+		if msg.SequenceNumber <= prev {
+			t.Fatalf("sequence not increasing: %d after %d", msg.SequenceNumber, prev)
+		}
+		prev = msg.SequenceNumber
+	}
+}
+
+// TestHandleBroadcastPacket verifies decoding and reordering of received packets
+func TestHandleBroadcastPacket(t *testing.T) {
+	chat := &Chat{ID: 1, Peers: map[uint32]*Peer{}, timeProvider: newFakeTimeProvider()}
+
+	encode := func(seq uint64) []byte {
+		data, err := json.Marshal(seqMsg(4, seq))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return data
+	}
+
+	if _, err := chat.HandleBroadcastPacket(encode(1)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got, _ := chat.HandleBroadcastPacket(encode(3))
+	if len(got) != 0 {
+		t.Fatalf("expected seq 3 to be buffered")
+	}
+	got, _ = chat.HandleBroadcastPacket(encode(2))
+	if !equalSeqs(sequences(got), []uint64{2, 3}) {
+		t.Fatalf("expected [2 3], got %v", sequences(got))
+	}
+
+	wrong, _ := json.Marshal(&BroadcastMessage{ChatID: 2, SenderID: 4, SequenceNumber: 4})
+	if _, err := chat.HandleBroadcastPacket(wrong); err == nil {
+		t.Error("expected error for mismatched chat ID")
+	}
+	if _, err := chat.HandleBroadcastPacket([]byte("{")); err == nil {
+		t.Error("expected error for malformed packet")
+	}
+}
+
+// TestReorderBufferForgetsDepartedSenders verifies leave, kick and ban drop the sender's stream
+func TestReorderBufferForgetsDepartedSenders(t *testing.T) {
+	chat, _ := newBanTestChat(RoleModerator)
+	chat.transport = &mockTransport{}
+	streams := func() map[uint32]*senderStream {
+		rb := chat.getReorderBuffer()
+		rb.mu.Lock()
+		defer rb.mu.Unlock()
+		return rb.streams
+	}
+	receive := func(msg *BroadcastMessage) []*BroadcastMessage {
+		msg.ChatID = chat.ID
+		data, err := json.Marshal(msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := chat.HandleBroadcastPacket(data)
+		if err != nil {
+			t.Fatalf("HandleBroadcastPacket failed: %v", err)
+		}
+		return got
+	}
+	for _, sender := range []uint32{7, 8, 9, 10} {
+		addr := &mockAddr{address: fmt.Sprintf("10.0.0.%d:33445", sender)}
+		chat.Peers[sender] = &Peer{ID: sender, Role: RoleUser, PublicKey: [32]byte{byte(sender)}, Connection: 2, Address: addr}
+		receive(seqMsg(sender, 1))
+	}
+
+	// A received leave releases the pending messages, then drops the stream
+	receive(seqMsg(7, 3))
+	leave := &BroadcastMessage{Type: "peer_leave", SenderID: 7, SequenceNumber: 2}
+	if got := receive(leave); !equalSeqs(sequences(got), []uint64{2, 3}) {
+		t.Fatalf("expected [2 3], got %v", sequences(got))
+	}
+	if _, ok := streams()[7]; ok {
+		t.Error("stream of a peer that left was kept")
+	}
+
+	// A received kick drops the kicked peer's stream
+	kick := &BroadcastMessage{Type: "peer_kick", SenderID: 8, SequenceNumber: 2, Data: PeerKickData{KickedPeerID: 9}.ToMap()}
+	receive(kick)
+	if _, ok := streams()[9]; ok {
+		t.Error("stream of a kicked peer was kept")
+	}
+	if _, ok := streams()[8]; !ok {
+		t.Error("stream of the kicking peer was dropped")
+	}
+
+	// Kicking or banning locally drops the target's stream
+	if err := chat.BanPeer(10, "spam"); err != nil {
+		t.Fatalf("BanPeer failed: %v", err)
+	}
+	if _, ok := streams()[10]; ok {
+		t.Error("stream of a banned peer was kept")
+	}
+	if err := chat.KickPeer(8); err != nil {
+		t.Fatalf("KickPeer failed: %v", err)
+	}
+	if len(streams()) != 0 {
+		t.Errorf("expected no streams left, got %d", len(streams()))
+	}
+}
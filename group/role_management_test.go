@@ -2,6 +2,7 @@ package group
 
 import (
 	"encoding/json"
+	"errors"
 	"strings"
 	"testing"
 
@@ -79,7 +80,7 @@ func TestSetPeerRole_BroadcastIncludesCorrectOldRole(t *testing.T) {
 		t.Errorf("Expected peer_id 2, got %v", data["peer_id"])
 	}
 
-	// Critical check: old_role should be RoleUser (0), not RoleModerator (1)
+	// Critical check: old_role should be RoleUser, not RoleModerator
 	oldRole := Role(data["old_role"].(float64))
 	if oldRole != RoleUser {
 		t.Errorf("Expected old_role to be RoleUser (%d), got %d", RoleUser, oldRole)
@@ -370,3 +371,77 @@ func TestSetPeerRole_FounderChangingAdminRole(t *testing.T) {
 		t.Errorf("Expected new_role to be Moderator, got %v", newRole)
 	}
 }
+
+// TestSetPeerRole_ModeratorPromotesTrustedUser verifies moderators can grant RoleTrustedUser
+func TestSetPeerRole_ModeratorPromotesTrustedUser(t *testing.T) {
+	chat := &Chat{
+		ID:         1,
+		SelfPeerID: 1,
+		transport:  &mockTransport{},
+		Peers: map[uint32]*Peer{
+			1: {ID: 1, Role: RoleModerator},
+			2: {ID: 2, Role: RoleUser},
+		},
+	}
+
+	if err := chat.SetPeerRole(2, RoleTrustedUser); err != nil {
+		t.Fatalf("Moderator should be able to promote user to trusted user: %v", err)
+	}
+	if chat.Peers[2].Role != RoleTrustedUser {
+		t.Errorf("Expected RoleTrustedUser, got %v", chat.Peers[2].Role)
+	}
+
+	err := chat.SetPeerRole(2, RoleModerator)
+	if !errors.Is(err, ErrInsufficientPrivileges) {
+		t.Errorf("Expected ErrInsufficientPrivileges granting equal role, got %v", err)
+	}
+}
+
+// TestSetPeerRole_TrustedUserCannotGrant verifies trusted users cannot change roles
+func TestSetPeerRole_TrustedUserCannotGrant(t *testing.T) {
+	chat := &Chat{
+		ID:         1,
+		SelfPeerID: 1,
+		Peers: map[uint32]*Peer{
+			1: {ID: 1, Role: RoleTrustedUser},
+			2: {ID: 2, Role: RoleUser},
+		},
+	}
+
+	err := chat.SetPeerRole(2, RoleUser)
+	if !errors.Is(err, ErrInsufficientPrivileges) {
+		t.Errorf("Expected ErrInsufficientPrivileges, got %v", err)
+	}
+}
+
+// TestRolePermissions verifies the capability helpers follow the hierarchy
+func TestRolePermissions(t *testing.T) {
+	if RoleUser.CanPinMessages() || RoleUser.CanReact() {
+		t.Error("RoleUser should not pin or react")
+	}
+	if !RoleTrustedUser.CanPinMessages() || !RoleTrustedUser.CanReact() {
+		t.Error("RoleTrustedUser should pin and react")
+	}
+	if RoleTrustedUser.CanKick() || RoleTrustedUser.CanBan() {
+		t.Error("RoleTrustedUser should not kick or ban")
+	}
+	if !RoleModerator.CanKick() || !RoleModerator.CanBan() {
+		t.Error("RoleModerator should kick and ban")
+	}
+	if !RoleTrustedUser.outranks(RoleUser) || !RoleModerator.outranks(RoleTrustedUser) {
+		t.Error("RoleTrustedUser must rank between RoleUser and RoleModerator")
+	}
+	if Role(9).atLeast(RoleUser) || Role(9).CanPinMessages() {
+		t.Error("unknown roles should carry no permissions")
+	}
+}
+
+// TestRoleWireValues verifies the roles that predate RoleTrustedUser keep
+// their values, so peers running older versions read them unchanged.
+func TestRoleWireValues(t *testing.T) {
+	for role, want := range map[Role]uint8{RoleUser: 0, RoleModerator: 1, RoleAdmin: 2, RoleFounder: 3, RoleTrustedUser: 4} {
+		if uint8(role) != want {
+			t.Errorf("role rank %d has value %d, want %d", role.rank(), role, want)
+		}
+	}
+}
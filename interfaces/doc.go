@@ -87,6 +87,21 @@
 //   - Integration testing with actual networks
 //   - Performance benchmarking
 //
+// # Packet Hooks
+//
+// Implementations that also satisfy [PacketHookable] let security middleware
+// such as rate limiters, filters or loggers inspect every packet. The
+// pre-delivery hook runs before a packet reaches the transport; the
+// post-receive hook runs from ProcessReceivedPacket on the receive path. A
+// hook may return a modified packet, [ErrDropPacket] to discard the packet
+// silently, or any other error to reject it:
+//
+//	limiter := interfaces.NewRateLimitingHook(50, 100)
+//	if hookable, ok := delivery.(interfaces.PacketHookable); ok {
+//	    hookable.SetPostReceiveHook(limiter.Hook)
+//	    hookable.SetPreDeliveryHook(interfaces.LoggingHook{Direction: "outbound"}.Hook)
+//	}
+//
 // # Thread Safety
 //
 // All implementations of these interfaces must be safe for concurrent use.
@@ -11,6 +11,36 @@ var ErrInvalidTimeout = errors.New("network timeout must be positive")
 // ErrInvalidRetryAttempts is returned when RetryAttempts is negative.
 var ErrInvalidRetryAttempts = errors.New("retry attempts cannot be negative")
 
+// ErrCircuitOpen is returned by DeliverPacket without attempting delivery
+// while the circuit breaker for the destination friend is open.
+var ErrCircuitOpen = errors.New("circuit breaker open")
+
+// CircuitState is the state of a per-friend delivery circuit breaker.
+type CircuitState int
+
+const (
+	// CircuitClosed delivers packets normally.
+	CircuitClosed CircuitState = iota
+	// CircuitOpen fails deliveries immediately with ErrCircuitOpen.
+	CircuitOpen
+	// CircuitHalfOpen allows a single probe delivery to test the friend.
+	CircuitHalfOpen
+)
+
+// String returns the state name.
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
 // PacketDeliveryStats provides type-safe statistics for packet delivery operations.
 // This replaces the untyped map[string]interface{} return from GetStats().
 type PacketDeliveryStats struct {
@@ -35,6 +65,11 @@ type PacketDeliveryStats struct {
 	// AverageLatencyMs is the average delivery latency in milliseconds.
 	// Zero if no packets have been delivered.
 	AverageLatencyMs float64
+
+	// CircuitStates holds the circuit breaker state of each friend whose
+	// breaker has left the closed state. Friends not listed are closed.
+	// Nil for implementations without circuit breakers.
+	CircuitStates map[uint32]CircuitState
 }
 
 // IPacketDelivery defines the interface for packet delivery operations.
@@ -0,0 +1,140 @@
+package interfaces
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ErrDropPacket is returned by a PacketHook to discard a packet silently.
+// DeliverPacket reports success for a dropped packet; any other hook error
+// aborts delivery and is returned to the caller.
+var ErrDropPacket = errors.New("packet dropped by hook")
+
+// PacketHook inspects a packet exchanged with a friend and returns the
+// packet to continue with, which may be modified or replaced. Hooks run on
+// the delivery path and must be safe for concurrent use.
+type PacketHook func(friendID uint32, packet []byte) ([]byte, error)
+
+// PacketHookable is implemented by IPacketDelivery implementations that let
+// security middleware intercept packets. Both RealPacketDelivery and
+// SimulatedPacketDelivery implement it:
+//
+//	if hookable, ok := delivery.(interfaces.PacketHookable); ok {
+//	    hookable.SetPreDeliveryHook(limiter.Hook)
+//	}
+type PacketHookable interface {
+	// SetPreDeliveryHook sets the hook run by DeliverPacket (and therefore
+	// BroadcastPacket, once per friend) before the packet is handed to the
+	// network transport. Pass nil to remove it.
+	SetPreDeliveryHook(hook PacketHook)
+
+	// SetPostReceiveHook sets the hook run by ProcessReceivedPacket. Pass
+	// nil to remove it.
+	SetPostReceiveHook(hook PacketHook)
+
+	// ProcessReceivedPacket runs the post-receive hook on a packet received
+	// from a friend and returns the packet to process. The receive path
+	// must discard the packet without reporting an error when the result
+	// is ErrDropPacket, and discard it as invalid for any other error.
+	ProcessReceivedPacket(friendID uint32, packet []byte) ([]byte, error)
+}
+
+// RateLimitingHook is a sample PacketHook that limits the packet rate per
+// friend with a token bucket, dropping packets over the limit:
+//
+//	limiter := interfaces.NewRateLimitingHook(50, 100)
+//	hookable.SetPostReceiveHook(limiter.Hook)
+type RateLimitingHook struct {
+	mu      sync.Mutex
+	rate    float64 // tokens added per second
+	burst   float64
+	buckets map[uint32]*tokenBucket
+	now     func() time.Time
+}
+
+// tokenBucket holds the tokens left for one friend.
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimitingHook creates a rate limiter allowing packetsPerSecond
+// packets per friend on average, with bursts of up to burst packets.
+// Non-positive values allow one packet per second without bursts.
+func NewRateLimitingHook(packetsPerSecond float64, burst int) *RateLimitingHook {
+	if packetsPerSecond <= 0 {
+		packetsPerSecond = 1
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	return &RateLimitingHook{
+		rate:    packetsPerSecond,
+		burst:   float64(burst),
+		buckets: make(map[uint32]*tokenBucket),
+		now:     time.Now,
+	}
+}
+
+// Hook implements PacketHook, returning ErrDropPacket when friendID exceeded
+// its rate.
+func (h *RateLimitingHook) Hook(friendID uint32, packet []byte) ([]byte, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := h.now()
+	bucket, ok := h.buckets[friendID]
+	if !ok {
+		bucket = &tokenBucket{tokens: h.burst, last: now}
+		h.buckets[friendID] = bucket
+	}
+	bucket.tokens = min(h.burst, bucket.tokens+now.Sub(bucket.last).Seconds()*h.rate)
+	bucket.last = now
+
+	if bucket.tokens < 1 {
+		return nil, ErrDropPacket
+	}
+	bucket.tokens--
+	return packet, nil
+}
+
+// Forget discards the rate limiting state of a removed friend.
+func (h *RateLimitingHook) Forget(friendID uint32) {
+	h.mu.Lock()
+	delete(h.buckets, friendID)
+	h.mu.Unlock()
+}
+
+// LoggingHook is a sample PacketHook that logs each packet and passes it
+// through unchanged:
+//
+//	hookable.SetPreDeliveryHook(interfaces.LoggingHook{Direction: "outbound"}.Hook)
+type LoggingHook struct {
+	// Direction is included in each log entry, e.g. "outbound".
+	Direction string
+	// Level is the logrus level of the entries. The zero value
+	// (logrus.PanicLevel) logs at logrus.DebugLevel instead.
+	Level logrus.Level
+}
+
+// Hook implements PacketHook.
+func (h LoggingHook) Hook(friendID uint32, packet []byte) ([]byte, error) {
+	fields := logrus.Fields{
+		"function":    "LoggingHook",
+		"direction":   h.Direction,
+		"friend_id":   friendID,
+		"packet_size": len(packet),
+	}
+	if len(packet) > 0 {
+		fields["packet_type"] = packet[0]
+	}
+	level := h.Level
+	if level == logrus.PanicLevel {
+		level = logrus.DebugLevel
+	}
+	logrus.WithFields(fields).Log(level, "Packet intercepted")
+	return packet, nil
+}
@@ -0,0 +1,70 @@
+package interfaces
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// TestRateLimitingHook tests token bucket limiting per friend.
+func TestRateLimitingHook(t *testing.T) {
+	now := time.Unix(1000, 0)
+	hook := NewRateLimitingHook(2, 3)
+	hook.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		if _, err := hook.Hook(1, []byte{1}); err != nil {
+			t.Fatalf("packet %d within burst dropped: %v", i, err)
+		}
+	}
+	if _, err := hook.Hook(1, []byte{1}); !errors.Is(err, ErrDropPacket) {
+		t.Errorf("packet over burst: got %v, want ErrDropPacket", err)
+	}
+
+	// Other friends have their own bucket
+	if _, err := hook.Hook(2, []byte{1}); err != nil {
+		t.Errorf("friend 2 should not be limited: %v", err)
+	}
+
+	// Half a second refills one token at 2 packets per second
+	now = now.Add(500 * time.Millisecond)
+	if _, err := hook.Hook(1, []byte{1}); err != nil {
+		t.Errorf("packet after refill dropped: %v", err)
+	}
+	if _, err := hook.Hook(1, []byte{1}); !errors.Is(err, ErrDropPacket) {
+		t.Errorf("second packet after refill: got %v, want ErrDropPacket", err)
+	}
+
+	hook.Forget(1)
+	if _, err := hook.Hook(1, []byte{1}); err != nil {
+		t.Errorf("forgotten friend should start with a full bucket: %v", err)
+	}
+}
+
+// TestRateLimitingHookDefaults tests that invalid parameters are clamped.
+func TestRateLimitingHookDefaults(t *testing.T) {
+	hook := NewRateLimitingHook(0, 0)
+	if hook.rate != 1 || hook.burst != 1 {
+		t.Errorf("got rate %v burst %v, want 1 and 1", hook.rate, hook.burst)
+	}
+}
+
+// TestLoggingHook tests that LoggingHook passes packets through unchanged.
+func TestLoggingHook(t *testing.T) {
+	packet := []byte{7, 1, 2}
+	for _, level := range []logrus.Level{logrus.PanicLevel, logrus.InfoLevel} {
+		got, err := LoggingHook{Direction: "inbound", Level: level}.Hook(1, packet)
+		if err != nil {
+			t.Fatalf("level %v: unexpected error: %v", level, err)
+		}
+		if !bytes.Equal(got, packet) {
+			t.Errorf("level %v: packet modified to %v", level, got)
+		}
+	}
+	if _, err := (LoggingHook{}).Hook(1, nil); err != nil {
+		t.Errorf("empty packet: unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,21 @@
+package limits
+
+// Compile-time guards for limits mandated by the Tox protocol. Each
+// expression indexes a one-element array with the difference between the
+// constant and its required value, so any drift is a constant index out of
+// range and the package fails to compile. Ordering guards use the difference
+// as an array length, which must not be negative.
+
+// Exact values fixed by the protocol specification.
+var (
+	_ = [1]struct{}{}[MaxPlaintextMessage-1372]
+	_ = [1]struct{}{}[EncryptionOverhead-16]
+	_ = [1]struct{}{}[MaxEncryptedMessage-(MaxPlaintextMessage+EncryptionOverhead)]
+)
+
+// Ordering between layers: storage must hold an encrypted message and the
+// processing buffer must hold a storage message.
+var (
+	_ [MaxStorageMessage - MaxEncryptedMessage]struct{}
+	_ [MaxProcessingBuffer - MaxStorageMessage]struct{}
+)
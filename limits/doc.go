@@ -101,6 +101,14 @@
 //	    }
 //	}
 //
+// # Protocol Guards
+//
+// assertions.go pins the protocol-mandated constants (MaxPlaintextMessage,
+// EncryptionOverhead, MaxEncryptedMessage) with compile-time assertions, so
+// changing any of them to an incompatible value breaks the build rather than
+// interoperability. runtime_test.go repeats the size relationships as tests
+// with descriptive failures.
+//
 // # Performance
 //
 // All validation functions are designed for zero-allocation operation and minimal
@@ -0,0 +1,21 @@
+package limits
+
+import "testing"
+
+// poly1305TagSize is the authentication tag appended by NaCl box and
+// secretbox; it is spelled out here rather than taken from EncryptionOverhead
+// so the test fails if both constants drift together.
+const poly1305TagSize = 16
+
+// TestRuntimeLimitRelations re-checks at test time the relationships that
+// assertions.go enforces at compile time, reporting the offending values.
+func TestRuntimeLimitRelations(t *testing.T) {
+	if MaxEncryptedMessage != MaxPlaintextMessage+poly1305TagSize {
+		t.Errorf("MaxEncryptedMessage = %d, want MaxPlaintextMessage (%d) + %d (Poly1305 tag)",
+			MaxEncryptedMessage, MaxPlaintextMessage, poly1305TagSize)
+	}
+	if MaxStorageMessage < MaxEncryptedMessage {
+		t.Errorf("MaxStorageMessage (%d) must be >= MaxEncryptedMessage (%d)",
+			MaxStorageMessage, MaxEncryptedMessage)
+	}
+}
@@ -0,0 +1,116 @@
+package logging
+
+import (
+	"io"
+	"sync/atomic"
+
+	"github.com/sirupsen/logrus"
+)
+
+// inheritLevel marks a component that follows the standard logger's level.
+const inheritLevel = -1
+
+// Component is a named logger with its own level. It embeds the
+// component-tagged entry, so WithFields, WithError, Debug and friends can be
+// called on it directly.
+type Component struct {
+	*logrus.Entry
+
+	name   string
+	logger *logrus.Logger
+	level  atomic.Int64 // logrus.Level, or inheritLevel
+}
+
+// NewComponent creates a logger for the named component that follows the
+// standard logger's level until SetLevel is called.
+func NewComponent(name string) *Component {
+	c := &Component{name: name}
+	c.level.Store(inheritLevel)
+	c.logger = &logrus.Logger{
+		Out:       standardOutput{},
+		Formatter: &componentFormatter{component: c},
+		Hooks:     make(logrus.LevelHooks),
+		Level:     logrus.TraceLevel,
+		ExitFunc:  func(code int) { logrus.StandardLogger().Exit(code) },
+	}
+	c.Entry = logrus.NewEntry(c.logger).WithField("component", name)
+	return c
+}
+
+// Name returns the component name used in the "component" field.
+func (c *Component) Name() string {
+	return c.name
+}
+
+// SetLevel gives the component its own level, independent of the standard
+// logger.
+func (c *Component) SetLevel(level logrus.Level) {
+	c.level.Store(int64(level))
+	c.logger.SetLevel(level)
+}
+
+// ResetLevel makes the component follow the standard logger's level again.
+func (c *Component) ResetLevel() {
+	c.level.Store(inheritLevel)
+	c.logger.SetLevel(logrus.TraceLevel)
+}
+
+// GetLevel returns the component's effective level.
+func (c *Component) GetLevel() logrus.Level {
+	if level := c.level.Load(); level != inheritLevel {
+		return logrus.Level(level)
+	}
+	return logrus.GetLevel()
+}
+
+// IsLevelEnabled reports whether entries at level would be logged.
+func (c *Component) IsLevelEnabled(level logrus.Level) bool {
+	return c.GetLevel() >= level
+}
+
+// componentFormatter hands component entries to the standard logger. The
+// component logger's own gate is left wide open while inheriting, so the
+// effective level is checked here and logrus.SetLevel takes effect without
+// the component having to be told.
+//
+// Entries the standard logger would also log are re-logged through it, which
+// applies its formatter, hooks and output under its own lock. Entries only
+// the component enables (an explicit level more verbose than the standard
+// one) are formatted here with the standard formatter and written to the
+// standard output.
+type componentFormatter struct {
+	component *Component
+}
+
+// Format implements logrus.Formatter. It returns no bytes for entries that
+// were dropped or forwarded.
+func (f *componentFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	if !f.component.IsLevelEnabled(entry.Level) {
+		return nil, nil
+	}
+
+	std := logrus.StandardLogger()
+	if std.IsLevelEnabled(entry.Level) {
+		logrus.NewEntry(std).WithFields(entry.Data).WithTime(entry.Time).Log(entry.Level, entry.Message)
+		return nil, nil
+	}
+
+	for _, hook := range std.Hooks[entry.Level] {
+		if err := hook.Fire(entry); err != nil {
+			return nil, err
+		}
+	}
+	return std.Formatter.Format(entry)
+}
+
+// standardOutput writes to the standard logger's current output.
+type standardOutput struct{}
+
+// Write implements io.Writer. Dropped and forwarded entries arrive empty
+// and are not written.
+func (standardOutput) Write(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	return io.Writer(logrus.StandardLogger().Out).Write(p)
+}
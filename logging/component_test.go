@@ -0,0 +1,112 @@
+package logging
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+// captureStandardLogger redirects the standard logger for the duration of a test.
+func captureStandardLogger(t *testing.T, level logrus.Level) *bytes.Buffer {
+	t.Helper()
+	std := logrus.StandardLogger()
+	out, formatter, prevLevel := std.Out, std.Formatter, std.GetLevel()
+	var buf bytes.Buffer
+	logrus.SetOutput(&buf)
+	logrus.SetFormatter(&logrus.TextFormatter{DisableTimestamp: true, DisableColors: true})
+	logrus.SetLevel(level)
+	t.Cleanup(func() {
+		logrus.SetOutput(out)
+		logrus.SetFormatter(formatter)
+		logrus.SetLevel(prevLevel)
+	})
+	return &buf
+}
+
+func TestComponentInheritsStandardLevel(t *testing.T) {
+	buf := captureStandardLogger(t, logrus.InfoLevel)
+	c := NewComponent("dht")
+
+	c.WithField("function", "test").Debug("hidden")
+	c.Info("shown")
+	if strings.Contains(buf.String(), "hidden") {
+		t.Errorf("debug entry logged at info level:\n%s", buf)
+	}
+	if !strings.Contains(buf.String(), "component=dht") || !strings.Contains(buf.String(), "shown") {
+		t.Errorf("expected component-tagged info entry, got:\n%s", buf)
+	}
+
+	buf.Reset()
+	logrus.SetLevel(logrus.DebugLevel)
+	c.Debug("now visible")
+	if !strings.Contains(buf.String(), "now visible") {
+		t.Errorf("component did not follow logrus.SetLevel, got:\n%s", buf)
+	}
+}
+
+func TestComponentOwnLevel(t *testing.T) {
+	buf := captureStandardLogger(t, logrus.WarnLevel)
+	verbose := NewComponent("dht")
+	quiet := NewComponent("transport")
+
+	verbose.SetLevel(logrus.DebugLevel)
+	if verbose.GetLevel() != logrus.DebugLevel || quiet.GetLevel() != logrus.WarnLevel {
+		t.Fatalf("levels = %v, %v", verbose.GetLevel(), quiet.GetLevel())
+	}
+
+	verbose.Debug("dht debug")
+	quiet.Info("transport info")
+	out := buf.String()
+	if !strings.Contains(out, "dht debug") {
+		t.Errorf("component debug entry missing:\n%s", out)
+	}
+	if strings.Contains(out, "transport info") {
+		t.Errorf("other component ignored the standard level:\n%s", out)
+	}
+
+	verbose.SetLevel(logrus.ErrorLevel)
+	buf.Reset()
+	verbose.Warn("suppressed")
+	if buf.Len() != 0 {
+		t.Errorf("entry below component level logged:\n%s", buf)
+	}
+
+	verbose.ResetLevel()
+	if verbose.GetLevel() != logrus.WarnLevel {
+		t.Errorf("ResetLevel did not restore inheritance, level = %v", verbose.GetLevel())
+	}
+	if !verbose.IsLevelEnabled(logrus.WarnLevel) || verbose.IsLevelEnabled(logrus.InfoLevel) {
+		t.Error("IsLevelEnabled does not match the inherited level")
+	}
+}
+
+type recordingHook struct{ messages []string }
+
+func (h *recordingHook) Levels() []logrus.Level { return logrus.AllLevels }
+
+func (h *recordingHook) Fire(entry *logrus.Entry) error {
+	h.messages = append(h.messages, entry.Message)
+	return nil
+}
+
+func TestComponentFiresStandardHooks(t *testing.T) {
+	captureStandardLogger(t, logrus.InfoLevel)
+	hook := &recordingHook{}
+	std := logrus.StandardLogger()
+	previous := std.ReplaceHooks(make(logrus.LevelHooks))
+	t.Cleanup(func() { std.ReplaceHooks(previous) })
+	std.AddHook(hook)
+
+	c := NewComponent("async")
+	c.Info("forwarded")
+	c.SetLevel(logrus.DebugLevel)
+	c.Debug("direct")
+	c.SetLevel(logrus.WarnLevel)
+	c.Info("dropped")
+
+	if strings.Join(hook.messages, ",") != "forwarded,direct" {
+		t.Errorf("hook saw %v", hook.messages)
+	}
+}
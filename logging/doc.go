@@ -0,0 +1,26 @@
+// Package logging provides per-component loggers for the toxcore packages.
+//
+// Every instrumented package (dht, transport, async, messaging, av and the
+// av sub-packages) owns a Component created with NewComponent. A Component
+// embeds a *logrus.Entry tagged with a "component" field, so package code
+// logs exactly as it would with the global logrus functions:
+//
+//	var pkgLog = logging.NewComponent("dht")
+//
+//	pkgLog.WithFields(logrus.Fields{"function": "Bootstrap"}).Debug("Starting")
+//
+// # Levels
+//
+// A component initially follows the level of the standard logrus logger, so
+// logrus.SetLevel keeps working as before. Calling SetLevel on the component
+// gives it its own level, allowing debug output from one package without
+// flooding the others; ResetLevel returns it to following the standard
+// logger. Packages expose this as SetLogLevel, and Tox.SetComponentLogLevel
+// and Options.LogConfig select a package by component name.
+//
+// # Output
+//
+// Component output always goes through the standard logger's current
+// output, formatter and hooks, so logrus.SetOutput and logrus.SetFormatter
+// apply to every component.
+package logging
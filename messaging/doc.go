@@ -59,6 +59,47 @@
 //	    time.Sleep(100 * time.Millisecond)
 //	}
 //
+// # Threaded Replies
+//
+// [MessageManager.SendReply] sends a message that references an earlier one
+// through [Message.ReplyToID]. The target must be known to the manager, sent
+// by it (in memory or in the configured [MessageStore]) or received from the
+// same friend, or [ErrUnknownReplyTarget] is returned:
+//
+//	reply, err := mm.SendReply(friendID, parent.ID, "Agreed")
+//
+// Threads are flat: a reply to a reply refers to the original message. The
+// reference travels as a 4-byte field in the friend message packet, with 0
+// marking a top-level message, followed for replies by a fixed
+// [ReplySummarySize]-byte [ReplySummary] naming the original's author and
+// quoting its first 62 bytes, so the recipient can render the quote without
+// a lookup. Summaries are not persisted.
+//
+// # Reactions
+//
+// Emoji reactions from friends are recorded with [MessageManager.AddReaction]
+// and listed with [MessageManager.GetMessageReactions] for messages sent to
+// the friend, or [MessageManager.GetReceivedMessageReactions] for messages
+// received from them. A reaction is at most [MaxReactionEmojiBytes] of UTF-8;
+// reactions to messages not exchanged with the reacting friend are dropped.
+//
+// # Editing Messages
+//
+// [Message.SetBody] replaces the text of a message in the Delivered or Read
+// state and sends a [MessageEdit] through the transport, which must also
+// implement [EditTransport]. Other states return [ErrMessageNotDelivered].
+// The new body is padded and encrypted like a new message and signed with
+// the key from [KeyProvider.GetSelfPrivateKey]; each edit increments
+// [Message.EditCount]:
+//
+//	err := msg.SetBody("Corrected text")
+//
+// The receiver records incoming messages with [MessageManager.ReceiveMessage]
+// and passes edits to [MessageManager.HandleMessageEdit], which verifies the
+// signature, rejects edits that do not advance EditCount and fires the
+// [MessageManager.OnMessageEdit] callback. The signing key is sealed inside
+// the encrypted body, so only an edit the friend encrypted is accepted.
+//
 // # Message States
 //
 // Messages progress through a state machine:
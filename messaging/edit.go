@@ -0,0 +1,294 @@
+package messaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/opd-ai/toxcore/crypto"
+	"github.com/sirupsen/logrus"
+)
+
+// ErrMessageNotDelivered indicates an edit was attempted on a message that
+// has not been delivered to the friend.
+var ErrMessageNotDelivered = errors.New("message not delivered")
+
+// ErrEditNotSupported indicates the configured transport cannot send edits.
+var ErrEditNotSupported = errors.New("transport does not support message edits")
+
+// ErrInvalidEdit indicates a received edit is malformed, carries a bad
+// signature or is older than the version already held.
+var ErrInvalidEdit = errors.New("invalid message edit")
+
+// messageEditHeaderSize is the size of the encoded edit preceding the body:
+// [MESSAGE_ID(4)][EDIT_COUNT(4)][SIGNING_KEY(32)][SIGNATURE(64)].
+const messageEditHeaderSize = 8 + 32 + crypto.SignatureSize
+
+// editBindingSize is the size of the header sealed together with the edited
+// text: [MESSAGE_ID(4)][EDIT_COUNT(4)][SIGNING_KEY(32)]. Only the friend can
+// produce a body that decrypts under their long-term key or ratchet session,
+// so the sealed copy ties the signing key to the friend's identity.
+const editBindingSize = 8 + 32
+
+// EditTransport is implemented by message transports that can deliver
+// message edits. The Tox instance implements it alongside MessageTransport.
+type EditTransport interface {
+	// SendEditPacket sends an edit of a previously sent message to a friend.
+	SendEditPacket(friendID uint32, edit *MessageEdit) error
+}
+
+// MessageEdit is a signed replacement body for a previously sent message.
+//
+// Body is encrypted and padded exactly like a new message, with the message
+// ID, edit count and SigningKey sealed ahead of the text. The signature is
+// made with the sender's private key over the message ID, edit count and
+// body; SigningKey is the matching Ed25519 public key.
+type MessageEdit struct {
+	MessageID  uint32
+	EditCount  uint32
+	Body       string
+	SigningKey [32]byte
+	Signature  crypto.Signature
+}
+
+// receivedKey identifies a message received from a friend.
+type receivedKey struct {
+	friendID  uint32
+	messageID uint32
+}
+
+// binding returns the header sealed with the edited text.
+func (e *MessageEdit) binding() []byte {
+	data := make([]byte, editBindingSize)
+	binary.BigEndian.PutUint32(data[:4], e.MessageID)
+	binary.BigEndian.PutUint32(data[4:8], e.EditCount)
+	copy(data[8:], e.SigningKey[:])
+	return data
+}
+
+// signedData returns the bytes covered by the edit signature.
+func (e *MessageEdit) signedData() []byte {
+	data := make([]byte, 8+len(e.Body))
+	binary.BigEndian.PutUint32(data[:4], e.MessageID)
+	binary.BigEndian.PutUint32(data[4:8], e.EditCount)
+	copy(data[8:], e.Body)
+	return data
+}
+
+// MarshalBinary encodes the edit as
+// [MESSAGE_ID(4)][EDIT_COUNT(4)][SIGNING_KEY(32)][SIGNATURE(64)][BODY...].
+func (e *MessageEdit) MarshalBinary() ([]byte, error) {
+	data := make([]byte, messageEditHeaderSize+len(e.Body))
+	binary.BigEndian.PutUint32(data[:4], e.MessageID)
+	binary.BigEndian.PutUint32(data[4:8], e.EditCount)
+	copy(data[8:40], e.SigningKey[:])
+	copy(data[40:messageEditHeaderSize], e.Signature[:])
+	copy(data[messageEditHeaderSize:], e.Body)
+	return data, nil
+}
+
+// UnmarshalBinary decodes an edit encoded by MarshalBinary.
+func (e *MessageEdit) UnmarshalBinary(data []byte) error {
+	if len(data) <= messageEditHeaderSize {
+		return fmt.Errorf("%w: packet too small", ErrInvalidEdit)
+	}
+	e.MessageID = binary.BigEndian.Uint32(data[:4])
+	e.EditCount = binary.BigEndian.Uint32(data[4:8])
+	copy(e.SigningKey[:], data[8:40])
+	copy(e.Signature[:], data[40:messageEditHeaderSize])
+	e.Body = string(data[messageEditHeaderSize:])
+	return nil
+}
+
+// SetBody replaces the text of a delivered or read message and sends a
+// signed edit to the friend. The new text is encrypted like a new message
+// and EditCount is incremented. Messages in any other state return
+// ErrMessageNotDelivered.
+//
+//export ToxMessageSetBody
+func (m *Message) SetBody(newText string) error {
+	if err := validateMessageText(newText); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	state := m.State
+	mm := m.manager
+	friendID := m.FriendID
+	messageID := m.ID
+	editCount := m.EditCount + 1
+	m.mu.Unlock()
+
+	if state != MessageStateDelivered && state != MessageStateRead {
+		return ErrMessageNotDelivered
+	}
+	if mm == nil {
+		return ErrMessageNotFound
+	}
+
+	edit, err := mm.newMessageEdit(friendID, messageID, editCount, newText)
+	if err != nil {
+		return err
+	}
+
+	mm.mu.Lock()
+	editTransport, ok := mm.transport.(EditTransport)
+	mm.mu.Unlock()
+	if !ok {
+		return ErrEditNotSupported
+	}
+	if err := editTransport.SendEditPacket(friendID, edit); err != nil {
+		return fmt.Errorf("failed to send edit: %w", err)
+	}
+
+	m.mu.Lock()
+	m.Text = edit.Body
+	m.encrypted = true
+	m.EditCount = editCount
+	m.mu.Unlock()
+
+	pkgLog.WithFields(logrus.Fields{
+		"function":   "SetBody",
+		"friend_id":  friendID,
+		"message_id": messageID,
+		"edit_count": editCount,
+	}).Debug("Sent message edit")
+	return nil
+}
+
+// newMessageEdit encrypts text for friendID and signs the resulting edit.
+func (mm *MessageManager) newMessageEdit(friendID, messageID, editCount uint32, text string) (*MessageEdit, error) {
+	mm.mu.Lock()
+	sess := mm.ratchetSessions[friendID]
+	kp := mm.keyProvider
+	mm.mu.Unlock()
+
+	// The key provider is needed for the signature even on the ratchet path.
+	if kp == nil {
+		return nil, fmt.Errorf("%w: %w", ErrOutboundPlaintextBlocked, ErrNoEncryption)
+	}
+
+	privateKey := kp.GetSelfPrivateKey()
+	edit := &MessageEdit{
+		MessageID:  messageID,
+		EditCount:  editCount,
+		SigningKey: crypto.GetSignaturePublicKey(privateKey),
+	}
+
+	sealed := string(edit.binding()) + text
+	var err error
+	if sess != nil {
+		edit.Body, err = sealWithRatchet(sess, sealed)
+	} else {
+		edit.Body, err = sealWithNaCl(friendID, sealed, kp)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	edit.Signature, err = crypto.Sign(edit.signedData(), privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign edit: %w", err)
+	}
+	return edit, nil
+}
+
+// ReceiveMessage records a message received from a friend under the ID the
+// friend assigned to it, so that later edits can be applied to it.
+func (mm *MessageManager) ReceiveMessage(friendID, messageID uint32, text string, messageType MessageType) *Message {
+	message := newMessageWithTime(friendID, text, messageType, mm.timeProvider.Now())
+	return mm.storeReceived(message, messageID)
+}
+
+// ReceiveReply records a reply received from a friend, like ReceiveMessage,
+// together with the reference to the message it answers.
+func (mm *MessageManager) ReceiveReply(friendID, messageID, replyToID uint32, summary *ReplySummary, text string) *Message {
+	message := newMessageWithTime(friendID, text, MessageTypeNormal, mm.timeProvider.Now())
+	message.ReplyToID = &replyToID
+	message.ReplySummary = summary
+	return mm.storeReceived(message, messageID)
+}
+
+// storeReceived marks message as delivered under the friend's ID for it and
+// adds it to the received messages.
+func (mm *MessageManager) storeReceived(message *Message, messageID uint32) *Message {
+	message.ID = messageID
+	message.State = MessageStateDelivered
+
+	mm.mu.Lock()
+	mm.received[receivedKey{friendID: message.FriendID, messageID: messageID}] = message
+	mm.mu.Unlock()
+	return message
+}
+
+// OnMessageEdit sets the callback invoked after an edit from a friend has
+// been applied to a received message.
+func (mm *MessageManager) OnMessageEdit(callback func(msg *Message)) {
+	mm.mu.Lock()
+	defer mm.mu.Unlock()
+	mm.editCallback = callback
+}
+
+// HandleMessageEdit verifies and applies an edit received from friendID to
+// the message recorded with ReceiveMessage. The body must decrypt with the
+// friend's key or ratchet session and name the same message, edit count and
+// signing key as the signed header, so an edit signed by anyone other than
+// the friend is rejected. Edits that do not advance EditCount are rejected.
+func (mm *MessageManager) HandleMessageEdit(friendID uint32, edit *MessageEdit) error {
+	valid, err := crypto.Verify(edit.signedData(), edit.Signature, edit.SigningKey)
+	if err != nil || !valid {
+		return fmt.Errorf("%w: bad signature", ErrInvalidEdit)
+	}
+
+	key := receivedKey{friendID: friendID, messageID: edit.MessageID}
+	mm.mu.Lock()
+	_, exists := mm.received[key]
+	mm.mu.Unlock()
+	if !exists {
+		return ErrMessageNotFound
+	}
+
+	sealed, err := mm.DecryptMessage(friendID, edit.Body)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidEdit, err)
+	}
+	if len(sealed) < editBindingSize || !bytes.Equal([]byte(sealed[:editBindingSize]), edit.binding()) {
+		return fmt.Errorf("%w: not sealed by friend %d", ErrInvalidEdit, friendID)
+	}
+	text := sealed[editBindingSize:]
+	message, callback, err := mm.applyMessageEdit(key, edit, text)
+	if err != nil {
+		return err
+	}
+
+	pkgLog.WithFields(logrus.Fields{
+		"function":   "HandleMessageEdit",
+		"friend_id":  friendID,
+		"message_id": edit.MessageID,
+		"edit_count": edit.EditCount,
+	}).Debug("Applied message edit")
+
+	if callback != nil {
+		callback(message)
+	}
+	return nil
+}
+
+// applyMessageEdit checks the edit count, stores the decrypted text and
+// returns the edited message with the edit callback.
+func (mm *MessageManager) applyMessageEdit(key receivedKey, edit *MessageEdit, text string) (*Message, func(*Message), error) {
+	mm.mu.Lock()
+	defer mm.mu.Unlock()
+
+	message := mm.received[key]
+
+	message.mu.Lock()
+	defer message.mu.Unlock()
+	if edit.EditCount <= message.EditCount {
+		return nil, nil, fmt.Errorf("%w: stale edit %d", ErrInvalidEdit, edit.EditCount)
+	}
+	message.Text = text
+	message.EditCount = edit.EditCount
+	return message, mm.editCallback, nil
+}
@@ -0,0 +1,209 @@
+package messaging
+
+import (
+	"encoding/base64"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/opd-ai/toxcore/crypto"
+)
+
+// mockEditTransport records sent messages and edits.
+type mockEditTransport struct {
+	mockTransport
+	edits []*MessageEdit
+	mu    sync.Mutex
+}
+
+func (m *mockEditTransport) SendEditPacket(friendID uint32, edit *MessageEdit) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.edits = append(m.edits, edit)
+	return nil
+}
+
+func (m *mockEditTransport) getEdits() []*MessageEdit {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]*MessageEdit(nil), m.edits...)
+}
+
+// newEditPair returns a sender manager talking to friend 1 and a receiver
+// manager that knows the sender as friend 7.
+func newEditPair(t *testing.T) (*MessageManager, *MessageManager, *mockEditTransport) {
+	t.Helper()
+	senderKeys, err := crypto.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	receiverKeys, err := crypto.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	trans := &mockEditTransport{}
+	sender := NewMessageManager()
+	sender.SetTransport(trans)
+	sender.SetKeyProvider(&mockKeyProvider{
+		friendPublicKeys: map[uint32][32]byte{1: receiverKeys.Public},
+		selfPrivateKey:   senderKeys.Private,
+		selfPublicKey:    senderKeys.Public,
+	})
+	receiver := NewMessageManager()
+	receiver.SetKeyProvider(&mockKeyProvider{
+		friendPublicKeys: map[uint32][32]byte{7: senderKeys.Public},
+		selfPrivateKey:   receiverKeys.Private,
+		selfPublicKey:    receiverKeys.Public,
+	})
+	t.Cleanup(sender.Close)
+	t.Cleanup(receiver.Close)
+	return sender, receiver, trans
+}
+
+// sendDelivered sends text and waits until it has been marked delivered.
+func sendDelivered(t *testing.T, mm *MessageManager, text string) *Message {
+	t.Helper()
+	msg, err := mm.SendMessage(1, text, MessageTypeNormal)
+	if err != nil {
+		t.Fatalf("SendMessage failed: %v", err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for msg.GetState() != MessageStateSent && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	mm.MarkMessageDelivered(msg.ID)
+	return msg
+}
+
+func TestSetBodyEditsReceivedMessage(t *testing.T) {
+	sender, receiver, trans := newEditPair(t)
+	msg := sendDelivered(t, sender, "helo")
+	received := receiver.ReceiveMessage(7, msg.ID, "helo", MessageTypeNormal)
+
+	var edited []*Message
+	receiver.OnMessageEdit(func(m *Message) { edited = append(edited, m) })
+
+	if err := msg.SetBody("hello"); err != nil {
+		t.Fatalf("SetBody failed: %v", err)
+	}
+	if msg.EditCount != 1 {
+		t.Errorf("expected EditCount 1, got %d", msg.EditCount)
+	}
+	edits := trans.getEdits()
+	if len(edits) != 1 {
+		t.Fatalf("expected 1 edit sent, got %d", len(edits))
+	}
+
+	// The edit survives the wire encoding.
+	data, _ := edits[0].MarshalBinary()
+	var wire MessageEdit
+	if err := wire.UnmarshalBinary(data); err != nil {
+		t.Fatalf("UnmarshalBinary failed: %v", err)
+	}
+	if err := receiver.HandleMessageEdit(7, &wire); err != nil {
+		t.Fatalf("HandleMessageEdit failed: %v", err)
+	}
+	if received.GetText() != "hello" || received.EditCount != 1 {
+		t.Errorf("expected edited text and count 1, got %q %d", received.GetText(), received.EditCount)
+	}
+	if len(edited) != 1 || edited[0] != received {
+		t.Errorf("expected OnMessageEdit with the received message, got %v", edited)
+	}
+
+	// Replaying the same edit is rejected.
+	if err := receiver.HandleMessageEdit(7, &wire); !errors.Is(err, ErrInvalidEdit) {
+		t.Errorf("expected ErrInvalidEdit for a replayed edit, got %v", err)
+	}
+}
+
+func TestSetBodyPadsBody(t *testing.T) {
+	sender, receiver, trans := newEditPair(t)
+	msg := sendDelivered(t, sender, "short")
+	msg.SetState(MessageStateRead)
+	if err := msg.SetBody("x"); err != nil {
+		t.Fatalf("SetBody on a read message failed: %v", err)
+	}
+	if err := msg.SetBody(string(make([]byte, 300))); err != nil {
+		t.Fatalf("SetBody failed: %v", err)
+	}
+
+	for i, want := range []int{256, 1024} {
+		raw, err := base64.StdEncoding.DecodeString(trans.getEdits()[i].Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// nonce || padded payload || Poly1305 tag
+		if got := len(raw) - crypto.NonceSize - 16; got != want {
+			t.Errorf("edit %d: expected a %d-byte padded payload, got %d", i, want, got)
+		}
+	}
+
+	receiver.ReceiveMessage(7, msg.ID, "short", MessageTypeNormal)
+	if err := receiver.HandleMessageEdit(7, trans.getEdits()[1]); err != nil {
+		t.Fatalf("HandleMessageEdit failed: %v", err)
+	}
+}
+
+func TestSetBodyRequiresDelivery(t *testing.T) {
+	sender, _, trans := newEditPair(t)
+	msg := sendDelivered(t, sender, "hello")
+	msg.SetState(MessageStateFailed)
+
+	if err := msg.SetBody("edited"); !errors.Is(err, ErrMessageNotDelivered) {
+		t.Errorf("expected ErrMessageNotDelivered, got %v", err)
+	}
+	if len(trans.getEdits()) != 0 || msg.EditCount != 0 {
+		t.Error("expected no edit for a failed message")
+	}
+}
+
+func TestHandleMessageEditRejectsForgery(t *testing.T) {
+	sender, receiver, trans := newEditPair(t)
+	msg := sendDelivered(t, sender, "hello")
+	receiver.ReceiveMessage(7, msg.ID, "hello", MessageTypeNormal)
+	if err := msg.SetBody("first"); err != nil {
+		t.Fatal(err)
+	}
+	edit := *trans.getEdits()[0]
+
+	tampered := edit
+	tampered.EditCount = 5
+	if err := receiver.HandleMessageEdit(7, &tampered); !errors.Is(err, ErrInvalidEdit) {
+		t.Errorf("expected ErrInvalidEdit for a tampered edit, got %v", err)
+	}
+
+	unknown := edit
+	unknown.MessageID = msg.ID + 1
+	forgedKeys, _ := crypto.GenerateKeyPair()
+	unknown.SigningKey = crypto.GetSignaturePublicKey(forgedKeys.Private)
+	unknown.Signature, _ = crypto.Sign(unknown.signedData(), forgedKeys.Private)
+	if err := receiver.HandleMessageEdit(7, &unknown); !errors.Is(err, ErrMessageNotFound) {
+		t.Errorf("expected ErrMessageNotFound, got %v", err)
+	}
+
+	// A correctly signed edit under another key is refused even as the first
+	// edit, because the friend did not seal that key into the body.
+	forged := edit
+	forged.SigningKey = crypto.GetSignaturePublicKey(forgedKeys.Private)
+	forged.Signature, _ = crypto.Sign(forged.signedData(), forgedKeys.Private)
+	if err := receiver.HandleMessageEdit(7, &forged); !errors.Is(err, ErrInvalidEdit) {
+		t.Errorf("expected ErrInvalidEdit for a different signing key, got %v", err)
+	}
+
+	// A body sealed for another message cannot be moved onto this one.
+	other := sendDelivered(t, sender, "other")
+	receiver.ReceiveMessage(7, other.ID, "other", MessageTypeNormal)
+	moved := edit
+	moved.MessageID = other.ID
+	moved.Signature, _ = crypto.Sign(moved.signedData(), forgedKeys.Private)
+	moved.SigningKey = crypto.GetSignaturePublicKey(forgedKeys.Private)
+	if err := receiver.HandleMessageEdit(7, &moved); !errors.Is(err, ErrInvalidEdit) {
+		t.Errorf("expected ErrInvalidEdit for a body sealed for another message, got %v", err)
+	}
+
+	if err := receiver.HandleMessageEdit(7, &edit); err != nil {
+		t.Fatalf("HandleMessageEdit failed: %v", err)
+	}
+}
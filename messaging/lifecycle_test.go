@@ -1,6 +1,7 @@
 package messaging
 
 import (
+	"context"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -82,6 +83,20 @@ func TestMessageManager_Close(t *testing.T) {
 	})
 }
 
+func TestNewMessageManagerWithContext(t *testing.T) {
+	parent, cancel := context.WithCancel(context.Background())
+	mm := NewMessageManagerWithContext(parent)
+	defer mm.Close()
+
+	if mm.isContextCancelled() {
+		t.Fatal("manager context should not start cancelled")
+	}
+	cancel()
+	if !mm.isContextCancelled() {
+		t.Error("cancelling the parent should cancel the manager context")
+	}
+}
+
 func TestMessageManager_GracefulShutdown(t *testing.T) {
 	t.Run("Messages marked pending on shutdown", func(t *testing.T) {
 		mm := NewMessageManager()
@@ -39,6 +39,10 @@ var ErrStoreNotConfigured = errors.New("message store not configured")
 // ErrLoadFailed indicates message loading from the store failed.
 var ErrLoadFailed = errors.New("failed to load messages from store")
 
+// ErrReplyTargetNotFound indicates a reply references a message that is
+// neither tracked in memory nor present in the persisted store.
+var ErrReplyTargetNotFound = errors.New("reply target message not found")
+
 // MessageType represents the type of message.
 type MessageType uint8
 
@@ -180,12 +184,30 @@ type Message struct {
 	Retries     uint8
 	LastAttempt time.Time
 
+	// ReplyToID references the message this one replies to, or is nil for a
+	// top-level message. On the wire a nil reference is encoded as 0.
+	ReplyToID *uint32
+
+	// ReplySummary quotes the message ReplyToID refers to. It travels with
+	// the reply but is not persisted.
+	ReplySummary *ReplySummary
+
+	// EditCount is the number of times the message body has been edited.
+	EditCount uint32
+
 	// encrypted tracks whether Text already holds ciphertext.
 	// Guards against double-encryption on retry: encryptMessage is a no-op when true.
 	encrypted bool
 
 	deliveryCallback DeliveryCallback
 
+	// preview is the start of the plaintext, kept for reply summaries after
+	// Text has been replaced by ciphertext.
+	preview string
+
+	// manager is the MessageManager that sent the message, used by SetBody.
+	manager *MessageManager
+
 	mu sync.Mutex
 }
 
@@ -243,6 +265,19 @@ type MessageManager struct {
 	// disappearing maps friend ID → per-conversation disappearing-message manager.
 	disappearing map[uint32]*DisappearingMessageManager
 
+	// reactions maps message ID → reactions received for that message.
+	reactions map[uint32][]Reaction
+
+	// receivedReactions holds reactions to messages received from friends.
+	receivedReactions map[receivedKey][]Reaction
+
+	// received holds messages received from friends, keyed by the sender's
+	// friend ID and message ID, so that their edits can be applied.
+	received map[receivedKey]*Message
+
+	// editCallback is invoked after a friend's edit has been applied.
+	editCallback func(msg *Message)
+
 	// Exponential backoff configuration
 	initialDelay  time.Duration
 	maxDelay      time.Duration
@@ -269,7 +304,7 @@ func NewMessage(friendID uint32, text string, messageType MessageType) *Message
 // newMessageWithTime creates a new message with an explicit timestamp.
 // This is used internally to support deterministic time for testing.
 func newMessageWithTime(friendID uint32, text string, messageType MessageType, timestamp time.Time) *Message {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":     "NewMessage",
 		"friend_id":    friendID,
 		"message_type": messageType,
@@ -284,9 +319,10 @@ func newMessageWithTime(friendID uint32, text string, messageType MessageType, t
 		State:       MessageStatePending,
 		Retries:     0,
 		LastAttempt: time.Time{}, // Zero time
+		preview:     truncateReplyText(text),
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":     "NewMessage",
 		"friend_id":    friendID,
 		"message_type": messageType,
@@ -350,6 +386,17 @@ func (m *Message) GetFriendID() uint32 {
 	return m.FriendID
 }
 
+// GetReplyToID returns the ID of the message this one replies to, or 0 for
+// a top-level message. This method is safe for concurrent use.
+func (m *Message) GetReplyToID() uint32 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.ReplyToID == nil {
+		return 0
+	}
+	return *m.ReplyToID
+}
+
 // GetText returns the message text (which may be base64-encoded ciphertext after
 // encryption). This method is safe for concurrent use.
 func (m *Message) GetText() string {
@@ -370,6 +417,8 @@ type messageJSON struct {
 	State       MessageState `json:"state"`
 	Retries     uint8        `json:"retries"`
 	LastAttempt time.Time    `json:"last_attempt"`
+	ReplyToID   *uint32      `json:"reply_to_id,omitempty"`
+	EditCount   uint32       `json:"edit_count,omitempty"`
 }
 
 // MarshalJSON implements json.Marshaler for Message.
@@ -387,6 +436,8 @@ func (m *Message) MarshalJSON() ([]byte, error) {
 		State:       m.State,
 		Retries:     m.Retries,
 		LastAttempt: m.LastAttempt,
+		ReplyToID:   m.ReplyToID,
+		EditCount:   m.EditCount,
 	})
 }
 
@@ -409,6 +460,8 @@ func (m *Message) UnmarshalJSON(data []byte) error {
 	m.State = jm.State
 	m.Retries = jm.Retries
 	m.LastAttempt = jm.LastAttempt
+	m.ReplyToID = jm.ReplyToID
+	m.EditCount = jm.EditCount
 
 	return nil
 }
@@ -416,22 +469,32 @@ func (m *Message) UnmarshalJSON(data []byte) error {
 // NewMessageManager creates a new message manager.
 // Call Close() to gracefully shut down the manager and wait for pending goroutines.
 func NewMessageManager() *MessageManager {
-	ctx, cancel := context.WithCancel(context.Background())
+	return NewMessageManagerWithContext(context.Background())
+}
+
+// NewMessageManagerWithContext creates a message manager whose background
+// sends stop when parent is cancelled, as well as on Close. Close must still
+// be called to wait for pending goroutines.
+func NewMessageManagerWithContext(parent context.Context) *MessageManager {
+	ctx, cancel := context.WithCancel(parent)
 	return &MessageManager{
-		messages:        make(map[uint32]*Message),
-		pendingQueue:    make([]*Message, 0),
-		ratchetSessions: make(map[uint32]*ratchet.Session),
-		disappearing:    make(map[uint32]*DisappearingMessageManager),
-		maxRetries:      3,
-		retryInterval:   5 * time.Second,
-		initialDelay:    5 * time.Second,
-		maxDelay:        5 * time.Minute,
-		backoffFactor:   2.0,
-		retryEnabled:    true,
-		nextID:          1,
-		timeProvider:    DefaultTimeProvider{},
-		ctx:             ctx,
-		cancel:          cancel,
+		messages:          make(map[uint32]*Message),
+		pendingQueue:      make([]*Message, 0),
+		ratchetSessions:   make(map[uint32]*ratchet.Session),
+		disappearing:      make(map[uint32]*DisappearingMessageManager),
+		reactions:         make(map[uint32][]Reaction),
+		receivedReactions: make(map[receivedKey][]Reaction),
+		received:          make(map[receivedKey]*Message),
+		maxRetries:        3,
+		retryInterval:     5 * time.Second,
+		initialDelay:      5 * time.Second,
+		maxDelay:          5 * time.Minute,
+		backoffFactor:     2.0,
+		retryEnabled:      true,
+		nextID:            1,
+		timeProvider:      DefaultTimeProvider{},
+		ctx:               ctx,
+		cancel:            cancel,
 	}
 }
 
@@ -660,7 +723,7 @@ func (mm *MessageManager) HandleDeliveryReceipt(friendID, messageID uint32, rece
 	mm.mu.Unlock()
 
 	if !exists {
-		logrus.WithFields(logrus.Fields{"function": "HandleDeliveryReceipt", "friend_id": friendID, "message_id": messageID}).Debug("Received receipt for unknown message ID")
+		pkgLog.WithFields(logrus.Fields{"function": "HandleDeliveryReceipt", "friend_id": friendID, "message_id": messageID}).Debug("Received receipt for unknown message ID")
 		return
 	}
 	if message.GetFriendID() != friendID {
@@ -676,7 +739,7 @@ func (mm *MessageManager) HandleDeliveryReceipt(friendID, messageID uint32, rece
 	if callback != nil {
 		callback(friendID, messageID, newState)
 	}
-	logrus.WithFields(logrus.Fields{"function": "HandleDeliveryReceipt", "friend_id": friendID, "message_id": messageID, "state": newState}).Debug("Delivery receipt processed")
+	pkgLog.WithFields(logrus.Fields{"function": "HandleDeliveryReceipt", "friend_id": friendID, "message_id": messageID, "state": newState}).Debug("Delivery receipt processed")
 }
 
 // resolveReceiptState maps receipt bytes to message states and logs unknown values.
@@ -687,14 +750,14 @@ func resolveReceiptState(receiptType byte, messageID uint32) (MessageState, bool
 	case 0x01:
 		return MessageStateRead, true
 	default:
-		logrus.WithFields(logrus.Fields{"function": "HandleDeliveryReceipt", "receipt_type": receiptType, "message_id": messageID}).Warn("Unknown receipt type")
+		pkgLog.WithFields(logrus.Fields{"function": "HandleDeliveryReceipt", "receipt_type": receiptType, "message_id": messageID}).Warn("Unknown receipt type")
 		return 0, false
 	}
 }
 
 // logUnexpectedReceiptFriend logs receipts arriving from a different friend than expected.
 func logUnexpectedReceiptFriend(message *Message, friendID, messageID uint32) {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":           "HandleDeliveryReceipt",
 		"expected_friend_id": message.GetFriendID(),
 		"actual_friend_id":   friendID,
@@ -763,7 +826,7 @@ func (mm *MessageManager) SaveMessages() error {
 		return fmt.Errorf("failed to save messages: %w", err)
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":      "SaveMessages",
 		"message_count": len(messages),
 	}).Debug("Messages saved successfully")
@@ -800,7 +863,7 @@ func (mm *MessageManager) LoadMessages() error {
 
 	mm.restoreMessagesFromSnapshot(snapshot)
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":      "LoadMessages",
 		"message_count": len(snapshot.Messages),
 		"next_id":       mm.nextID,
@@ -845,6 +908,7 @@ func (mm *MessageManager) restoreMessagesFromSnapshot(snapshot *managerSnapshot)
 			// Skip corrupted/null entries that can appear in persisted JSON (M-MSG-3).
 			continue
 		}
+		msg.manager = mm
 		mm.messages[msg.ID] = msg
 
 		if mm.shouldRestoreToPending(msg) {
@@ -874,19 +938,94 @@ func (mm *MessageManager) shouldRestoreToPending(msg *Message) bool {
 //
 //export ToxSendMessage
 func (mm *MessageManager) SendMessage(friendID uint32, text string, messageType MessageType) (*Message, error) {
-	if len(text) == 0 {
-		return nil, ErrMessageEmpty
+	if err := validateMessageText(text); err != nil {
+		return nil, err
 	}
-	if len(text) > limits.MaxPlaintextMessage {
-		return nil, ErrMessageTooLong
+
+	mm.mu.Lock()
+	defer mm.mu.Unlock()
+
+	return mm.queueMessage(friendID, text, messageType, nil, nil), nil
+}
+
+// SendReply sends a message to a friend as a reply to an earlier message.
+//
+// replyToID must identify a message known to this manager: one it sent, in
+// memory or in the configured MessageStore, or one received from friendID;
+// otherwise ErrReplyTargetNotFound is returned and nothing is sent. Threads
+// are one level deep, so a reply to a reply refers to the original message.
+// The reply carries a ReplySummary of the message it refers to.
+//
+//export ToxSendReply
+func (mm *MessageManager) SendReply(friendID, replyToID uint32, content string) (*Message, error) {
+	if err := validateMessageText(content); err != nil {
+		return nil, err
+	}
+	if replyToID == 0 {
+		return nil, ErrReplyTargetNotFound
+	}
+
+	rootID, summary, err := mm.resolveReplyTarget(friendID, replyToID)
+	if err != nil {
+		if errors.Is(err, ErrReplyTargetNotFound) {
+			return nil, fmt.Errorf("%w: %d", ErrReplyTargetNotFound, replyToID)
+		}
+		return nil, err
 	}
 
 	mm.mu.Lock()
 	defer mm.mu.Unlock()
 
+	return mm.queueMessage(friendID, content, MessageTypeNormal, &rootID, summary), nil
+}
+
+// validateMessageText checks the length limits shared by all outgoing messages.
+func validateMessageText(text string) error {
+	if len(text) == 0 {
+		return ErrMessageEmpty
+	}
+	if len(text) > limits.MaxPlaintextMessage {
+		return ErrMessageTooLong
+	}
+	return nil
+}
+
+// findStoredMessage returns the message with the given ID from the persisted
+// snapshot in store, or nil if there is none. A nil store holds no messages.
+func findStoredMessage(store MessageStore, messageID uint32) (*Message, error) {
+	if store == nil {
+		return nil, nil
+	}
+
+	data, err := store.Load()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrLoadFailed, err)
+	}
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	var snapshot managerSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("%w: failed to deserialize: %w", ErrLoadFailed, err)
+	}
+	for _, msg := range snapshot.Messages {
+		if msg != nil && msg.ID == messageID {
+			return msg, nil
+		}
+	}
+	return nil, nil
+}
+
+// queueMessage creates a message, stores it and starts the first send attempt.
+// The caller must hold mm.mu.
+func (mm *MessageManager) queueMessage(friendID uint32, text string, messageType MessageType, replyToID *uint32, summary *ReplySummary) *Message {
 	// Create a new message using injected time provider
 	message := newMessageWithTime(friendID, text, messageType, mm.timeProvider.Now())
+	message.ReplyToID = replyToID
+	message.ReplySummary = summary
 	message.ID = mm.nextID
+	message.manager = mm
 	mm.nextID++
 
 	// Store the message
@@ -902,7 +1041,7 @@ func (mm *MessageManager) SendMessage(friendID uint32, text string, messageType
 		mm.attemptMessageSend(message)
 	}()
 
-	return message, nil
+	return message
 }
 
 // ProcessPendingMessages attempts to send messages in the pending queue.
@@ -1096,28 +1235,38 @@ func (mm *MessageManager) encryptMessage(message *Message) error {
 // encryptWithRatchet encrypts plainText using the Double Ratchet session and
 // stores the result (header || ciphertext, base64-encoded) in message.Text.
 func (mm *MessageManager) encryptWithRatchet(message *Message, sess *ratchet.Session, plainText string) error {
+	text, err := sealWithRatchet(sess, plainText)
+	if err != nil {
+		return err
+	}
+	message.mu.Lock()
+	message.Text = text
+	message.encrypted = true
+	message.mu.Unlock()
+	return nil
+}
+
+// sealWithRatchet pads and encrypts plainText with the Double Ratchet session
+// and returns header || ciphertext, base64-encoded.
+func sealWithRatchet(sess *ratchet.Session, plainText string) (string, error) {
 	paddedData, err := encodeMessagePayload([]byte(plainText))
 	if err != nil {
-		return fmt.Errorf("ratchet encrypt: %w", err)
+		return "", fmt.Errorf("ratchet encrypt: %w", err)
 	}
 	h, ct, err := sess.RatchetEncrypt(paddedData, nil)
 	if err != nil {
-		return fmt.Errorf("ratchet encrypt: %w", err)
+		return "", fmt.Errorf("ratchet encrypt: %w", err)
 	}
 	// Wire format: header bytes || ciphertext, then base64.
 	wire := append(h.Encode(), ct...) //nolint:gocritic // intentional new slice: avoids aliasing between header and ciphertext backing arrays in downstream base64 encoding
-	message.mu.Lock()
-	message.Text = base64.StdEncoding.EncodeToString(wire)
-	message.encrypted = true
-	message.mu.Unlock()
-	return nil
+	return base64.StdEncoding.EncodeToString(wire), nil
 }
 
 // encryptWithNaCl encrypts plainText using the static NaCl-box path.
 // kp is the KeyProvider snapshot taken under mm.mu before this call.
 func (mm *MessageManager) encryptWithNaCl(message *Message, plainText string, kp KeyProvider) error {
 	if kp == nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"friend_id":    message.FriendID,
 			"message_type": message.Type,
 			"security":     "plaintext_blocked",
@@ -1125,31 +1274,41 @@ func (mm *MessageManager) encryptWithNaCl(message *Message, plainText string, kp
 		return fmt.Errorf("%w: %w", ErrOutboundPlaintextBlocked, ErrNoEncryption)
 	}
 
-	recipientPK, err := kp.GetFriendPublicKey(message.FriendID)
+	text, err := sealWithNaCl(message.FriendID, plainText, kp)
 	if err != nil {
 		return err
 	}
+
+	message.mu.Lock()
+	message.Text = text
+	message.encrypted = true
+	message.mu.Unlock()
+	return nil
+}
+
+// sealWithNaCl pads and encrypts plainText for friendID with NaCl box and
+// returns nonce || ciphertext, base64-encoded.
+func sealWithNaCl(friendID uint32, plainText string, kp KeyProvider) (string, error) {
+	recipientPK, err := kp.GetFriendPublicKey(friendID)
+	if err != nil {
+		return "", err
+	}
 	senderSK := kp.GetSelfPrivateKey()
 
 	nonce, err := crypto.GenerateNonce()
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	paddedData, err := encodeMessagePayload([]byte(plainText))
 	if err != nil {
-		return fmt.Errorf("nacl encrypt: %w", err)
+		return "", fmt.Errorf("nacl encrypt: %w", err)
 	}
 	encryptedData, err := crypto.Encrypt(paddedData, nonce, recipientPK, senderSK)
 	if err != nil {
-		return err
+		return "", err
 	}
-
-	message.mu.Lock()
-	message.Text = base64.StdEncoding.EncodeToString(append(nonce[:], encryptedData...))
-	message.encrypted = true
-	message.mu.Unlock()
-	return nil
+	return base64.StdEncoding.EncodeToString(append(nonce[:], encryptedData...)), nil
 }
 
 // updateMessageSendingState updates the message state before sending.
@@ -1176,7 +1335,7 @@ func (mm *MessageManager) updateMessageSendingState(message *Message) bool {
 // handleEncryptionError handles encryption failures for a message.
 func (mm *MessageManager) handleEncryptionError(message *Message, err error) bool {
 	if errors.Is(err, ErrOutboundPlaintextBlocked) || errors.Is(err, ErrNoEncryption) {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":  "attemptMessageSend",
 			"friend_id": message.FriendID,
 			"error":     err.Error(),
@@ -1186,7 +1345,7 @@ func (mm *MessageManager) handleEncryptionError(message *Message, err error) boo
 		return false
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":  "attemptMessageSend",
 		"friend_id": message.FriendID,
 		"error":     err.Error(),
@@ -146,7 +146,7 @@ func NewPriorityQueue(config *PriorityQueueConfig) *PriorityQueue {
 	pq.cond = sync.NewCond(&pq.mu)
 	heap.Init(&pq.heap)
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":         "NewPriorityQueue",
 		"max_size":         config.MaxSize,
 		"default_priority": PriorityNames[config.DefaultPriority],
@@ -184,7 +184,7 @@ func (pq *PriorityQueue) dropEnqueueWhenFullLocked(msg *Message, priority Messag
 		return false
 	}
 	pq.stats.Dropped.Add(1)
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":   "Enqueue",
 		"queue_size": pq.heap.Len(),
 		"max_size":   pq.config.MaxSize,
@@ -0,0 +1,104 @@
+package messaging
+
+import (
+	"errors"
+	"unicode/utf8"
+
+	"github.com/sirupsen/logrus"
+)
+
+// MaxReactionEmojiBytes is the maximum UTF-8 encoded length of a reaction.
+const MaxReactionEmojiBytes = 8
+
+// ErrInvalidReaction indicates a reaction emoji is empty, longer than
+// MaxReactionEmojiBytes or not valid UTF-8.
+var ErrInvalidReaction = errors.New("invalid reaction emoji")
+
+// Reaction is an emoji reaction a friend attached to a message.
+type Reaction struct {
+	SenderID uint32
+	Emoji    string
+}
+
+// ValidateReactionEmoji checks that emoji can be carried in a reaction packet.
+func ValidateReactionEmoji(emoji string) error {
+	if len(emoji) == 0 || len(emoji) > MaxReactionEmojiBytes || !utf8.ValidString(emoji) {
+		return ErrInvalidReaction
+	}
+	return nil
+}
+
+// AddReaction records a reaction received from friend senderID on a message
+// exchanged with that friend: one this manager sent to the friend, or one
+// received from the friend under the ID the friend assigned to it. As for
+// replies, sent messages are looked up first. Reactions to other messages,
+// invalid emoji and repeats of a reaction already recorded are discarded. It
+// reports whether the reaction was recorded.
+func (mm *MessageManager) AddReaction(senderID, messageID uint32, emoji string) bool {
+	if ValidateReactionEmoji(emoji) != nil {
+		return false
+	}
+
+	mm.mu.Lock()
+	defer mm.mu.Unlock()
+
+	reaction := Reaction{SenderID: senderID, Emoji: emoji}
+	if sent, exists := mm.messages[messageID]; exists && sent.GetFriendID() == senderID {
+		reactions, added := appendReaction(mm.reactions[messageID], reaction)
+		mm.reactions[messageID] = reactions
+		return added
+	}
+	key := receivedKey{friendID: senderID, messageID: messageID}
+	if _, exists := mm.received[key]; exists {
+		reactions, added := appendReaction(mm.receivedReactions[key], reaction)
+		mm.receivedReactions[key] = reactions
+		return added
+	}
+
+	pkgLog.WithFields(logrus.Fields{
+		"function":   "AddReaction",
+		"sender_id":  senderID,
+		"message_id": messageID,
+	}).Debug("Discarding reaction to unknown message")
+	return false
+}
+
+// appendReaction adds reaction to reactions unless it is already present.
+func appendReaction(reactions []Reaction, reaction Reaction) ([]Reaction, bool) {
+	for _, r := range reactions {
+		if r == reaction {
+			return reactions, false
+		}
+	}
+	return append(reactions, reaction), true
+}
+
+// GetMessageReactions returns the reactions recorded for a message this
+// manager sent, in the order they were received.
+//
+//export ToxGetMessageReactions
+func (mm *MessageManager) GetMessageReactions(messageID uint32) []Reaction {
+	mm.mu.Lock()
+	defer mm.mu.Unlock()
+	return copyReactions(mm.reactions[messageID])
+}
+
+// GetReceivedMessageReactions returns the reactions recorded for a message
+// received from friendID, identified by the friend's message ID.
+//
+//export ToxGetReceivedMessageReactions
+func (mm *MessageManager) GetReceivedMessageReactions(friendID, messageID uint32) []Reaction {
+	mm.mu.Lock()
+	defer mm.mu.Unlock()
+	return copyReactions(mm.receivedReactions[receivedKey{friendID: friendID, messageID: messageID}])
+}
+
+// copyReactions returns a copy of reactions, or nil if there are none.
+func copyReactions(reactions []Reaction) []Reaction {
+	if len(reactions) == 0 {
+		return nil
+	}
+	result := make([]Reaction, len(reactions))
+	copy(result, reactions)
+	return result
+}
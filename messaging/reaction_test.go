@@ -0,0 +1,103 @@
+package messaging
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestValidateReactionEmoji(t *testing.T) {
+	tests := []struct {
+		name  string
+		emoji string
+		valid bool
+	}{
+		{"single emoji", "👍", true},
+		{"eight bytes", "👍👍", true},
+		{"empty", "", false},
+		{"too long", "👍👍x", false},
+		{"invalid utf8", "\xff", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateReactionEmoji(tt.emoji)
+			if tt.valid && err != nil {
+				t.Errorf("ValidateReactionEmoji(%q) = %v, want nil", tt.emoji, err)
+			}
+			if !tt.valid && !errors.Is(err, ErrInvalidReaction) {
+				t.Errorf("ValidateReactionEmoji(%q) = %v, want ErrInvalidReaction", tt.emoji, err)
+			}
+		})
+	}
+}
+
+func TestAddReaction(t *testing.T) {
+	mm := NewMessageManager()
+	defer mm.Close()
+
+	msg, err := mm.SendMessage(1, "hello", MessageTypeNormal)
+	if err != nil {
+		t.Fatalf("SendMessage failed: %v", err)
+	}
+
+	if !mm.AddReaction(1, msg.ID, "👍") {
+		t.Error("reaction to known message should be recorded")
+	}
+	if !mm.AddReaction(1, msg.ID, "🎉") {
+		t.Error("another emoji from the same sender should be recorded")
+	}
+	if mm.AddReaction(2, msg.ID, "👍") {
+		t.Error("reaction from a friend the message was not sent to should be discarded")
+	}
+	if mm.AddReaction(1, msg.ID, "👍") {
+		t.Error("repeated reaction should be discarded")
+	}
+	if mm.AddReaction(1, msg.ID+100, "👍") {
+		t.Error("reaction to unknown message should be discarded")
+	}
+	if mm.AddReaction(1, msg.ID, "") {
+		t.Error("invalid reaction should be discarded")
+	}
+
+	want := []Reaction{{SenderID: 1, Emoji: "👍"}, {SenderID: 1, Emoji: "🎉"}}
+	got := mm.GetMessageReactions(msg.ID)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("GetMessageReactions() = %v, want %v", got, want)
+	}
+
+	// The returned slice is a copy
+	got[0].Emoji = "x"
+	if mm.GetMessageReactions(msg.ID)[0].Emoji != "👍" {
+		t.Error("GetMessageReactions should return a copy")
+	}
+	if mm.GetMessageReactions(msg.ID+100) != nil {
+		t.Error("unknown message should have no reactions")
+	}
+}
+
+func TestAddReactionToReceivedMessage(t *testing.T) {
+	mm := NewMessageManager()
+	defer mm.Close()
+
+	sent, err := mm.SendMessage(1, "ours", MessageTypeNormal)
+	if err != nil {
+		t.Fatalf("SendMessage failed: %v", err)
+	}
+	// Friend 2 uses the same ID for a message of theirs.
+	mm.ReceiveMessage(2, sent.ID, "theirs", MessageTypeNormal)
+
+	if !mm.AddReaction(2, sent.ID, "👍") {
+		t.Error("reaction to a message received from the friend should be recorded")
+	}
+	if mm.AddReaction(3, sent.ID, "👍") {
+		t.Error("reaction from a friend who exchanged no such message should be discarded")
+	}
+
+	want := []Reaction{{SenderID: 2, Emoji: "👍"}}
+	if got := mm.GetReceivedMessageReactions(2, sent.ID); !reflect.DeepEqual(got, want) {
+		t.Errorf("GetReceivedMessageReactions() = %v, want %v", got, want)
+	}
+	if got := mm.GetMessageReactions(sent.ID); got != nil {
+		t.Errorf("reaction to the received message was recorded on the sent one: %v", got)
+	}
+}
@@ -0,0 +1,131 @@
+package messaging
+
+import (
+	"errors"
+	"unicode/utf8"
+)
+
+// ReplySummarySize is the fixed encoded size of a ReplySummary.
+const ReplySummarySize = 64
+
+// replySummaryTextSize is the room left for quoted text after the author
+// and length bytes.
+const replySummaryTextSize = ReplySummarySize - 2
+
+// ErrUnknownReplyTarget is an alias of ErrReplyTargetNotFound.
+var ErrUnknownReplyTarget = ErrReplyTargetNotFound
+
+// ErrInvalidReplySummary indicates an encoded reply summary is malformed.
+var ErrInvalidReplySummary = errors.New("invalid reply summary")
+
+// ReplyAuthor identifies who wrote the message a reply refers to, from the
+// point of view of the reply's sender.
+type ReplyAuthor uint8
+
+const (
+	// ReplyAuthorSender means the sender of the reply wrote the original.
+	ReplyAuthorSender ReplyAuthor = iota
+	// ReplyAuthorRecipient means the recipient of the reply wrote the original.
+	ReplyAuthorRecipient
+)
+
+// ReplySummary describes the message a reply refers to, so the recipient can
+// render the quote without looking the message up. Text holds at most the
+// first 62 bytes of the original, cut at a UTF-8 boundary.
+type ReplySummary struct {
+	Author ReplyAuthor
+	Text   string
+}
+
+// newReplySummary builds a summary, truncating text to fit.
+func newReplySummary(author ReplyAuthor, text string) *ReplySummary {
+	return &ReplySummary{Author: author, Text: truncateReplyText(text)}
+}
+
+// truncateReplyText cuts text to replySummaryTextSize bytes without
+// splitting a UTF-8 sequence.
+func truncateReplyText(text string) string {
+	if len(text) <= replySummaryTextSize {
+		return text
+	}
+	cut := replySummaryTextSize
+	for cut > 0 && !utf8.RuneStart(text[cut]) {
+		cut--
+	}
+	return text[:cut]
+}
+
+// MarshalBinary encodes the summary as [AUTHOR(1)][TEXT_LEN(1)][TEXT(62)],
+// zero-padding the text field.
+func (s *ReplySummary) MarshalBinary() ([]byte, error) {
+	text := truncateReplyText(s.Text)
+	data := make([]byte, ReplySummarySize)
+	data[0] = byte(s.Author)
+	data[1] = byte(len(text))
+	copy(data[2:], text)
+	return data, nil
+}
+
+// UnmarshalBinary decodes a summary encoded by MarshalBinary.
+func (s *ReplySummary) UnmarshalBinary(data []byte) error {
+	if len(data) != ReplySummarySize {
+		return ErrInvalidReplySummary
+	}
+	author := ReplyAuthor(data[0])
+	textLen := int(data[1])
+	if author > ReplyAuthorRecipient || textLen > replySummaryTextSize || !utf8.Valid(data[2:2+textLen]) {
+		return ErrInvalidReplySummary
+	}
+	s.Author = author
+	s.Text = string(data[2 : 2+textLen])
+	return nil
+}
+
+// resolveReplyTarget finds the message replyToID refers to and returns the
+// ID the reply should carry together with its summary. Replies are flat: a
+// reply to a reply is attached to the original message instead.
+//
+// Messages this manager sent are looked up first, in memory and then in the
+// store, followed by messages received from friendID.
+func (mm *MessageManager) resolveReplyTarget(friendID, replyToID uint32) (uint32, *ReplySummary, error) {
+	mm.mu.Lock()
+	target, sent := mm.messages[replyToID]
+	received, fromFriend := mm.received[receivedKey{friendID: friendID, messageID: replyToID}]
+	store := mm.store
+	mm.mu.Unlock()
+
+	author := ReplyAuthorSender
+	switch {
+	case sent:
+	case fromFriend:
+		target = received
+		author = ReplyAuthorRecipient
+	default:
+		stored, err := findStoredMessage(store, replyToID)
+		if err != nil {
+			return 0, nil, err
+		}
+		if stored == nil {
+			return 0, nil, ErrReplyTargetNotFound
+		}
+		target = stored
+	}
+
+	target.mu.Lock()
+	defer target.mu.Unlock()
+
+	if target.ReplyToID == nil {
+		return replyToID, newReplySummary(author, target.preview), nil
+	}
+
+	// Attach to the thread root, reusing the target's summary of it.
+	summary := &ReplySummary{}
+	if target.ReplySummary != nil {
+		*summary = *target.ReplySummary
+	}
+	if author == ReplyAuthorRecipient {
+		// The friend's summary is written from the friend's point of view.
+		summary.Author = ReplyAuthorRecipient - summary.Author
+	}
+	return *target.ReplyToID, summary, nil
+}
@@ -0,0 +1,187 @@
+package messaging
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestSendReply(t *testing.T) {
+	mm := NewMessageManager()
+	defer mm.Close()
+
+	parent, err := mm.SendMessage(1, "original", MessageTypeNormal)
+	if err != nil {
+		t.Fatalf("SendMessage failed: %v", err)
+	}
+
+	reply, err := mm.SendReply(1, parent.ID, "reply")
+	if err != nil {
+		t.Fatalf("SendReply failed: %v", err)
+	}
+	if reply.GetReplyToID() != parent.ID {
+		t.Errorf("GetReplyToID() = %d, want %d", reply.GetReplyToID(), parent.ID)
+	}
+	if parent.GetReplyToID() != 0 {
+		t.Errorf("top-level message GetReplyToID() = %d, want 0", parent.GetReplyToID())
+	}
+}
+
+func TestSendReplyUnknownTarget(t *testing.T) {
+	mm := NewMessageManager()
+	defer mm.Close()
+
+	for _, id := range []uint32{0, 42} {
+		if _, err := mm.SendReply(1, id, "reply"); !errors.Is(err, ErrReplyTargetNotFound) {
+			t.Errorf("SendReply(replyToID=%d) error = %v, want ErrReplyTargetNotFound", id, err)
+		}
+	}
+	if len(mm.GetMessagesByFriend(1)) != 0 {
+		t.Error("rejected reply should not be queued")
+	}
+
+	if _, err := mm.SendReply(1, 1, ""); !errors.Is(err, ErrMessageEmpty) {
+		t.Errorf("empty reply error = %v, want ErrMessageEmpty", err)
+	}
+}
+
+func TestSendReplyPersistedTarget(t *testing.T) {
+	data, err := json.Marshal(managerSnapshot{
+		Messages: []*Message{{ID: 7, FriendID: 1, Text: "stored", State: MessageStateDelivered}},
+		NextID:   8,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := &mockMessageStore{data: data}
+
+	mm := NewMessageManager()
+	defer mm.Close()
+	mm.SetStore(store)
+
+	reply, err := mm.SendReply(1, 7, "reply to stored")
+	if err != nil {
+		t.Fatalf("SendReply failed: %v", err)
+	}
+	if reply.GetReplyToID() != 7 {
+		t.Errorf("GetReplyToID() = %d, want 7", reply.GetReplyToID())
+	}
+
+	store.loadErr = errors.New("disk error")
+	if _, err := mm.SendReply(1, 99, "reply"); !errors.Is(err, ErrLoadFailed) {
+		t.Errorf("store failure error = %v, want ErrLoadFailed", err)
+	}
+}
+
+func TestReplyToIDRoundTrip(t *testing.T) {
+	replyTo := uint32(5)
+	data, err := json.Marshal(&Message{ID: 6, FriendID: 1, Text: "reply", ReplyToID: &replyTo})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded Message
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.GetReplyToID() != replyTo {
+		t.Errorf("GetReplyToID() = %d, want %d", decoded.GetReplyToID(), replyTo)
+	}
+
+	data, err = json.Marshal(&Message{ID: 7, FriendID: 1, Text: "top-level"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := fields["reply_to_id"]; ok {
+		t.Error("top-level message should omit reply_to_id")
+	}
+}
+
+func TestSendReplySummary(t *testing.T) {
+	mm := NewMessageManager()
+	defer mm.Close()
+
+	parent, err := mm.SendMessage(1, "original message", MessageTypeNormal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reply, err := mm.SendReply(1, parent.ID, "reply")
+	if err != nil {
+		t.Fatalf("SendReply failed: %v", err)
+	}
+	if s := reply.ReplySummary; s == nil || s.Author != ReplyAuthorSender || s.Text != "original message" {
+		t.Errorf("reply summary = %+v, want own \"original message\"", reply.ReplySummary)
+	}
+
+	received := mm.ReceiveMessage(1, 500, "friend's message", MessageTypeNormal)
+	reply, err = mm.SendReply(1, received.ID, "reply to friend")
+	if err != nil {
+		t.Fatalf("SendReply to a received message failed: %v", err)
+	}
+	if s := reply.ReplySummary; s == nil || s.Author != ReplyAuthorRecipient || s.Text != "friend's message" {
+		t.Errorf("reply summary = %+v, want recipient's \"friend's message\"", reply.ReplySummary)
+	}
+	if _, err := mm.SendReply(2, received.ID, "wrong friend"); !errors.Is(err, ErrUnknownReplyTarget) {
+		t.Errorf("reply to another friend's message error = %v, want ErrUnknownReplyTarget", err)
+	}
+}
+
+func TestSendReplyFlattensThreads(t *testing.T) {
+	mm := NewMessageManager()
+	defer mm.Close()
+
+	root, _ := mm.SendMessage(1, "root", MessageTypeNormal)
+	first, err := mm.SendReply(1, root.ID, "first")
+	if err != nil {
+		t.Fatal(err)
+	}
+	nested, err := mm.SendReply(1, first.ID, "nested")
+	if err != nil {
+		t.Fatalf("SendReply to a reply failed: %v", err)
+	}
+	if nested.GetReplyToID() != root.ID || nested.ReplySummary.Text != "root" {
+		t.Errorf("nested reply refers to %d %+v, want the root %d", nested.GetReplyToID(), nested.ReplySummary, root.ID)
+	}
+
+	// A friend's reply to one of our messages has its summary author flipped.
+	friendReply := mm.ReceiveReply(1, 900, root.ID, &ReplySummary{Author: ReplyAuthorRecipient, Text: "root"}, "friend reply")
+	nested, err = mm.SendReply(1, friendReply.ID, "answer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nested.GetReplyToID() != root.ID || nested.ReplySummary.Author != ReplyAuthorSender {
+		t.Errorf("reply to friend's reply = %d %+v, want own root %d", nested.GetReplyToID(), nested.ReplySummary, root.ID)
+	}
+}
+
+func TestReplySummaryBinary(t *testing.T) {
+	long := strings.Repeat("é", 40) // 80 bytes of two-byte runes
+	data, err := (&ReplySummary{Author: ReplyAuthorRecipient, Text: long}).MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != ReplySummarySize {
+		t.Fatalf("encoded summary is %d bytes, want %d", len(data), ReplySummarySize)
+	}
+
+	var decoded ReplySummary
+	if err := decoded.UnmarshalBinary(data); err != nil {
+		t.Fatalf("UnmarshalBinary failed: %v", err)
+	}
+	if decoded.Author != ReplyAuthorRecipient || decoded.Text != strings.Repeat("é", 31) {
+		t.Errorf("decoded summary = %+v, want 31 runes from the recipient", decoded)
+	}
+
+	data[1] = 63
+	if err := decoded.UnmarshalBinary(data); !errors.Is(err, ErrInvalidReplySummary) {
+		t.Errorf("oversized text length error = %v, want ErrInvalidReplySummary", err)
+	}
+	if err := decoded.UnmarshalBinary(data[:10]); !errors.Is(err, ErrInvalidReplySummary) {
+		t.Errorf("short summary error = %v, want ErrInvalidReplySummary", err)
+	}
+}
@@ -132,7 +132,9 @@
 //
 // Key Verification: After successful handshake, verify the peer's identity using
 // GetRemoteStaticKey(). Compare against known trusted keys or implement a trust-on-
-// first-use (TOFU) model.
+// first-use (TOFU) model. KeyPinStore captures the TOFU model: LocalKeyPinStore
+// pins each address to the first key seen there, optionally persisted as JSON,
+// and CheckPin reports ErrKeyPinMismatch when the key later changes.
 //
 // Secure Memory: Private key material is automatically wiped from memory using
 // crypto.ZeroBytes() after key derivation to minimize exposure window.
@@ -164,6 +166,10 @@
 //   - ErrHandshakeNotComplete: Operation requires completed handshake
 //   - ErrInvalidMessage: Received message is invalid for current state
 //   - ErrHandshakeComplete: Handshake already finished, cannot process more messages
+//   - ErrHandshakeFailed: IK responder could not authenticate the initiator, usually
+//     because the initiator holds a stale static key; NoiseTransport retries once
+//     with an XX handshake
+//   - ErrKeyPinMismatch: Peer's static key differs from the key pinned for its address
 //
 // # Integration with Transport Layer
 //
@@ -21,6 +21,10 @@ var (
 	ErrInvalidMessage = errors.New("invalid message for current handshake state")
 	// ErrHandshakeComplete indicates handshake is already complete
 	ErrHandshakeComplete = errors.New("handshake already complete")
+	// ErrHandshakeFailed indicates the IK responder could not authenticate
+	// the initiator's message, typically because the initiator used a stale
+	// static key for us. The initiator should fall back to an XX handshake.
+	ErrHandshakeFailed = errors.New("handshake failed: peer static key mismatch")
 )
 
 // copyRemoteStaticKey extracts and copies the remote peer's static key from a handshake state.
@@ -130,6 +134,7 @@ type IKHandshake struct {
 	nonce       [32]byte // Unique handshake nonce for replay protection
 	timestamp   int64    // Unix timestamp for handshake freshness validation
 	localPubKey []byte   // Store our static public key for identity verification
+	peerPayload []byte   // Payload of the initiator's message (responder only)
 }
 
 // NewIKHandshake creates a new IK pattern handshake.
@@ -292,11 +297,14 @@ func (ik *IKHandshake) processResponderMessage(payload, receivedMessage []byte)
 		return nil, false, fmt.Errorf("responder requires received message")
 	}
 
-	// Read initiator's message
-	_, _, _, err := ik.state.ReadMessage(nil, receivedMessage)
+	// Read initiator's message. Failure here means the initiator encrypted
+	// to a static key other than ours, so report it as ErrHandshakeFailed to
+	// let the transport reject the attempt and trigger an XX fallback.
+	peerPayload, _, _, err := ik.state.ReadMessage(nil, receivedMessage)
 	if err != nil {
-		return nil, false, fmt.Errorf("responder read failed: %w", err)
+		return nil, false, fmt.Errorf("%w: responder read failed: %w", ErrHandshakeFailed, err)
 	}
+	ik.peerPayload = copyHandshakeBytes(peerPayload)
 
 	// Write response message (<- e, ee, se)
 	message, writeSendCipher, writeRecvCipher, err := ik.state.WriteMessage(nil, payload)
@@ -378,6 +386,15 @@ func (ik *IKHandshake) GetRemoteStaticKey() ([]byte, error) {
 	return copyRemoteStaticKey(ik.complete, ik.state)
 }
 
+// GetPeerPayload returns the payload the initiator sent in its handshake
+// message. It is only set on the responder, once WriteMessage has read that
+// message; the payload is encrypted and authenticated by the handshake.
+func (ik *IKHandshake) GetPeerPayload() []byte {
+	ik.mu.RLock()
+	defer ik.mu.RUnlock()
+	return copyHandshakeBytes(ik.peerPayload)
+}
+
 // GetChannelBinding returns the Noise handshake hash (transcript binding) for
 // use as input key material in session ticket PSK derivation.
 // Returns nil if the handshake is not yet complete.
@@ -593,6 +610,18 @@ func (xx *XXHandshake) GetRemoteStaticKey() ([]byte, error) {
 	return copyRemoteStaticKey(xx.complete, xx.state)
 }
 
+// GetChannelBinding returns the Noise handshake hash of a completed XX
+// handshake, or nil if the handshake is not yet complete.
+func (xx *XXHandshake) GetChannelBinding() []byte {
+	xx.mu.RLock()
+	defer xx.mu.RUnlock()
+
+	if !xx.complete || xx.state == nil {
+		return nil
+	}
+	return copyHandshakeBytes(xx.state.ChannelBinding())
+}
+
 // GetLocalStaticKey returns our static public key for XX pattern.
 func (xx *XXHandshake) GetLocalStaticKey() []byte {
 	xx.mu.RLock()
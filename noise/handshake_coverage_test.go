@@ -7,6 +7,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/opd-ai/toxcore/crypto"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -377,6 +378,31 @@ func TestGetRemoteStaticKey(t *testing.T) {
 	assert.Nil(t, remoteKey)
 }
 
+// TestGetPeerPayload tests that the responder exposes the initiator's payload
+func TestGetPeerPayload(t *testing.T) {
+	initiatorKeys, err := crypto.GenerateKeyPair()
+	require.NoError(t, err)
+	responderKeys, err := crypto.GenerateKeyPair()
+	require.NoError(t, err)
+
+	initiator, err := NewIKHandshake(initiatorKeys.Private[:], responderKeys.Public[:], Initiator)
+	require.NoError(t, err)
+	responder, err := NewIKHandshake(responderKeys.Private[:], nil, Responder)
+	require.NoError(t, err)
+	assert.Nil(t, responder.GetPeerPayload(), "Should be empty before the initiator's message is read")
+
+	message, _, err := initiator.WriteMessage([]byte("flags"), nil)
+	require.NoError(t, err)
+	response, _, err := responder.WriteMessage([]byte("reply"), message)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("flags"), responder.GetPeerPayload())
+
+	payload, complete, err := initiator.ReadMessage(response)
+	require.NoError(t, err)
+	assert.True(t, complete)
+	assert.Equal(t, []byte("reply"), payload)
+}
+
 // TestGetLocalStaticKey tests getting the local static key
 func TestGetLocalStaticKey(t *testing.T) {
 	privateKey := make([]byte, 32)
@@ -980,3 +1006,37 @@ func TestXXGetRemoteStaticKeyValidation(t *testing.T) {
 	_, err = initiator.GetRemoteStaticKey()
 	assert.Equal(t, ErrHandshakeNotComplete, err)
 }
+
+// TestXXHandshakeChannelBinding verifies both XX peers derive the same
+// channel binding once the handshake completes.
+func TestXXHandshakeChannelBinding(t *testing.T) {
+	initPriv := make([]byte, 32)
+	rand.Read(initPriv)
+	respPriv := make([]byte, 32)
+	rand.Read(respPriv)
+
+	initiator, err := NewXXHandshake(initPriv, Initiator)
+	require.NoError(t, err)
+	responder, err := NewXXHandshake(respPriv, Responder)
+	require.NoError(t, err)
+	assert.Nil(t, initiator.GetChannelBinding(), "binding should be nil before completion")
+
+	msg1, _, err := initiator.WriteMessage(nil, nil)
+	require.NoError(t, err)
+	_, _, err = responder.ReadMessage(msg1)
+	require.NoError(t, err)
+	msg2, _, err := responder.WriteMessage(nil, nil)
+	require.NoError(t, err)
+	_, _, err = initiator.ReadMessage(msg2)
+	require.NoError(t, err)
+	msg3, complete, err := initiator.WriteMessage(nil, nil)
+	require.NoError(t, err)
+	require.True(t, complete)
+	_, complete, err = responder.ReadMessage(msg3)
+	require.NoError(t, err)
+	require.True(t, complete)
+
+	binding := initiator.GetChannelBinding()
+	require.NotEmpty(t, binding)
+	assert.Equal(t, binding, responder.GetChannelBinding())
+}
@@ -46,6 +46,7 @@ func TestIKHandshakeKeyMismatch(t *testing.T) {
 	// Real responder should reject because keys don't match.
 	_, _, err = responder.WriteMessage(nil, msg1)
 	assert.Error(t, err, "responder should reject message encrypted for wrong peer key")
+	assert.ErrorIs(t, err, ErrHandshakeFailed, "stale key should be reported as ErrHandshakeFailed")
 }
 
 // TestIKHandshakeBitFlipInMessage verifies that any single-byte corruption in
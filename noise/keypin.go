@@ -0,0 +1,153 @@
+package noise
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+)
+
+// ErrKeyPinMismatch indicates a peer presented a static key that differs
+// from the key pinned for its address.
+var ErrKeyPinMismatch = errors.New("remote static key does not match pinned key")
+
+// KeyPinStore remembers the remote static key seen for each address after
+// the first successful handshake (trust on first use), so later handshakes
+// from that address can be rejected if the key changes.
+type KeyPinStore interface {
+	// Pin records pk as the key for addr, replacing any previous pin.
+	Pin(addr net.Addr, pk [32]byte) error
+	// CheckPin returns ErrKeyPinMismatch if addr is pinned to a key other
+	// than pk. Unpinned addresses pass.
+	CheckPin(addr net.Addr, pk [32]byte) error
+	// Unpin forgets the key pinned for addr, e.g. after a legitimate key
+	// rotation.
+	Unpin(addr net.Addr)
+}
+
+// LocalKeyPinStore is a KeyPinStore kept in memory and, when created with
+// a path, persisted to a JSON file after each change.
+type LocalKeyPinStore struct {
+	mu   sync.RWMutex
+	path string
+	pins map[string][32]byte
+}
+
+var _ KeyPinStore = (*LocalKeyPinStore)(nil)
+
+// keyPinFile is the JSON layout of a LocalKeyPinStore file, mapping
+// addresses to hex-encoded keys.
+type keyPinFile struct {
+	Pins map[string]string `json:"pins"`
+}
+
+// NewLocalKeyPinStore creates a pin store persisted at path, loading any
+// pins already saved there. A missing file starts an empty store. An empty
+// path keeps pins in memory only.
+func NewLocalKeyPinStore(path string) (*LocalKeyPinStore, error) {
+	store := &LocalKeyPinStore{
+		path: path,
+		pins: make(map[string][32]byte),
+	}
+	if path == "" {
+		return store, nil
+	}
+	if err := store.load(); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// load reads the pins saved at s.path.
+func (s *LocalKeyPinStore) load() error {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read key pin file: %w", err)
+	}
+
+	var file keyPinFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("failed to parse key pin file: %w", err)
+	}
+	for addr, encoded := range file.Pins {
+		raw, err := hex.DecodeString(encoded)
+		if err != nil || len(raw) != 32 {
+			return fmt.Errorf("invalid pinned key for %s", addr)
+		}
+		var pk [32]byte
+		copy(pk[:], raw)
+		s.pins[addr] = pk
+	}
+	return nil
+}
+
+// Pin implements KeyPinStore.
+func (s *LocalKeyPinStore) Pin(addr net.Addr, pk [32]byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if existing, ok := s.pins[addr.String()]; ok && existing == pk {
+		return nil
+	}
+	s.pins[addr.String()] = pk
+	return s.saveLocked()
+}
+
+// CheckPin implements KeyPinStore.
+func (s *LocalKeyPinStore) CheckPin(addr net.Addr, pk [32]byte) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if pinned, ok := s.pins[addr.String()]; ok && pinned != pk {
+		return fmt.Errorf("%w: %s", ErrKeyPinMismatch, addr)
+	}
+	return nil
+}
+
+// Unpin implements KeyPinStore. If the file cannot be rewritten the pin is
+// still removed from memory, and the file is corrected by the next Pin.
+func (s *LocalKeyPinStore) Unpin(addr net.Addr) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.pins[addr.String()]; !ok {
+		return
+	}
+	delete(s.pins, addr.String())
+	_ = s.saveLocked() //nolint:errcheck // Unpin has no error return; see doc
+}
+
+// Count returns the number of pinned addresses.
+func (s *LocalKeyPinStore) Count() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.pins)
+}
+
+// saveLocked writes all pins to s.path atomically via a temporary file and
+// rename. The caller must hold s.mu.
+func (s *LocalKeyPinStore) saveLocked() error {
+	if s.path == "" {
+		return nil
+	}
+	file := keyPinFile{Pins: make(map[string]string, len(s.pins))}
+	for addr, pk := range s.pins {
+		file.Pins[addr] = hex.EncodeToString(pk[:])
+	}
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to serialize key pins: %w", err)
+	}
+
+	tmpFile := s.path + ".tmp"
+	if err := os.WriteFile(tmpFile, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write temporary key pin file: %w", err)
+	}
+	if err := os.Rename(tmpFile, s.path); err != nil {
+		return fmt.Errorf("failed to rename key pin file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package noise
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalKeyPinStore(t *testing.T) {
+	store, err := NewLocalKeyPinStore("")
+	require.NoError(t, err)
+
+	addr := &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 33445}
+	key1 := [32]byte{1}
+	key2 := [32]byte{2}
+
+	assert.NoError(t, store.CheckPin(addr, key1), "unpinned address should pass")
+	require.NoError(t, store.Pin(addr, key1))
+	assert.NoError(t, store.CheckPin(addr, key1))
+	assert.ErrorIs(t, store.CheckPin(addr, key2), ErrKeyPinMismatch)
+
+	store.Unpin(addr)
+	assert.NoError(t, store.CheckPin(addr, key2))
+	assert.Equal(t, 0, store.Count())
+}
+
+func TestLocalKeyPinStorePersistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pins.json")
+	addr := &net.UDPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 33445}
+	other := &net.UDPAddr{IP: net.IPv4(10, 0, 0, 2), Port: 33445}
+	key := [32]byte{0xAB, 0xCD}
+
+	store, err := NewLocalKeyPinStore(path)
+	require.NoError(t, err)
+	require.NoError(t, store.Pin(addr, key))
+	require.NoError(t, store.Pin(other, key))
+	store.Unpin(other)
+
+	reloaded, err := NewLocalKeyPinStore(path)
+	require.NoError(t, err)
+	assert.Equal(t, 1, reloaded.Count())
+	assert.NoError(t, reloaded.CheckPin(addr, key))
+	assert.ErrorIs(t, reloaded.CheckPin(addr, [32]byte{}), ErrKeyPinMismatch)
+	assert.NoError(t, reloaded.CheckPin(other, [32]byte{}))
+}
+
+func TestLocalKeyPinStoreInvalidFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pins.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"pins":{"a":"zz"}}`), 0o600))
+	_, err := NewLocalKeyPinStore(path)
+	assert.Error(t, err)
+}
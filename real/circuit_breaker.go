@@ -0,0 +1,181 @@
+package real
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/opd-ai/toxcore/interfaces"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultConsecutiveFailures is the number of consecutive failed
+	// deliveries to a friend that opens its circuit breaker.
+	DefaultConsecutiveFailures = 5
+	// DefaultOpenDuration is how long an open circuit breaker fails
+	// deliveries before allowing a probe.
+	DefaultOpenDuration = 30 * time.Second
+)
+
+// Clock provides the current time for deterministic testing.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+}
+
+// DefaultClock implements Clock using time.Now.
+type DefaultClock struct{}
+
+// Now returns the current time using time.Now.
+func (DefaultClock) Now() time.Time {
+	return time.Now()
+}
+
+// CircuitBreakerConfig configures the per-friend circuit breakers of
+// RealPacketDelivery.
+type CircuitBreakerConfig struct {
+	// ConsecutiveFailures is the number of consecutive failed deliveries,
+	// each after all retry attempts, that opens the breaker.
+	ConsecutiveFailures int
+	// OpenDuration is how long the breaker stays open before a probe.
+	OpenDuration time.Duration
+}
+
+// DefaultCircuitBreakerConfig returns the default circuit breaker settings.
+func DefaultCircuitBreakerConfig() CircuitBreakerConfig {
+	return CircuitBreakerConfig{
+		ConsecutiveFailures: DefaultConsecutiveFailures,
+		OpenDuration:        DefaultOpenDuration,
+	}
+}
+
+// Validate checks that the configuration values are positive.
+func (c CircuitBreakerConfig) Validate() error {
+	if c.ConsecutiveFailures < 1 {
+		return fmt.Errorf("consecutive failures must be at least 1, got %d", c.ConsecutiveFailures)
+	}
+	if c.OpenDuration <= 0 {
+		return fmt.Errorf("open duration must be positive, got %v", c.OpenDuration)
+	}
+	return nil
+}
+
+// circuitBreaker tracks delivery failures to one friend. It is guarded by
+// the owning RealPacketDelivery's mutex.
+type circuitBreaker struct {
+	state    interfaces.CircuitState
+	failures int
+	openedAt time.Time
+	probing  bool // A half-open probe is in flight
+}
+
+// admit decides whether a delivery may proceed. It returns probe true when
+// the delivery is the single half-open probe, and ErrCircuitOpen when the
+// delivery must fail fast.
+func (b *circuitBreaker) admit(now time.Time, cfg CircuitBreakerConfig) (probe bool, err error) {
+	if b.state == interfaces.CircuitOpen && now.Sub(b.openedAt) >= cfg.OpenDuration {
+		b.state = interfaces.CircuitHalfOpen
+	}
+	switch b.state {
+	case interfaces.CircuitOpen:
+		return false, interfaces.ErrCircuitOpen
+	case interfaces.CircuitHalfOpen:
+		if b.probing {
+			return false, interfaces.ErrCircuitOpen
+		}
+		b.probing = true
+		return true, nil
+	default:
+		return false, nil
+	}
+}
+
+// record updates the breaker with the outcome of an admitted delivery and
+// returns the state before and after.
+func (b *circuitBreaker) record(ok bool, now time.Time, cfg CircuitBreakerConfig) (from, to interfaces.CircuitState) {
+	from = b.state
+	b.probing = false
+	switch {
+	case ok:
+		b.state = interfaces.CircuitClosed
+		b.failures = 0
+	case b.state == interfaces.CircuitHalfOpen:
+		b.state = interfaces.CircuitOpen
+		b.openedAt = now
+	default:
+		b.failures++
+		if b.failures >= cfg.ConsecutiveFailures {
+			b.state = interfaces.CircuitOpen
+			b.openedAt = now
+		}
+	}
+	return from, b.state
+}
+
+// SetCircuitBreakerConfig changes the failure threshold and open duration
+// of the per-friend circuit breakers. Existing breaker states are kept.
+func (r *RealPacketDelivery) SetCircuitBreakerConfig(cfg CircuitBreakerConfig) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.breakerConfig = cfg
+	return nil
+}
+
+// SetClock sets a custom Clock implementation (primarily for testing).
+func (r *RealPacketDelivery) SetClock(c Clock) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.clock = c
+}
+
+// admitDelivery consults the friend's circuit breaker before sending.
+func (r *RealPacketDelivery) admitDelivery(friendID uint32) (probe bool, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	b, exists := r.breakers[friendID]
+	if !exists {
+		return false, nil
+	}
+	probe, err = b.admit(r.clock.Now(), r.breakerConfig)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"function":  "RealPacketDelivery.DeliverPacket",
+			"friend_id": friendID,
+		}).Debug("Circuit breaker open, failing delivery fast")
+		return false, fmt.Errorf("friend %d unreachable: %w", friendID, err)
+	}
+	return probe, nil
+}
+
+// recordDelivery feeds a delivery outcome into the friend's circuit breaker.
+// Breakers are created on the first failure and dropped after a success.
+func (r *RealPacketDelivery) recordDelivery(friendID uint32, ok bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	b, exists := r.breakers[friendID]
+	if !exists {
+		if ok {
+			return
+		}
+		b = &circuitBreaker{}
+		r.breakers[friendID] = b
+	}
+
+	from, to := b.record(ok, r.clock.Now(), r.breakerConfig)
+	if ok {
+		delete(r.breakers, friendID)
+	}
+	if from != to {
+		logrus.WithFields(logrus.Fields{
+			"function":  "RealPacketDelivery.DeliverPacket",
+			"friend_id": friendID,
+			"from":      from.String(),
+			"to":        to.String(),
+		}).Info("Circuit breaker state changed")
+	}
+}
@@ -0,0 +1,186 @@
+package real
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/opd-ai/toxcore/interfaces"
+)
+
+// mockClock is a manually advanced Clock.
+type mockClock struct {
+	now time.Time
+}
+
+func (c *mockClock) Now() time.Time { return c.now }
+
+// newBreakerTestDelivery returns a delivery with friend 1 registered, no
+// retry sleeps and a mock clock. The breaker opens after 3 failures for 10s.
+func newBreakerTestDelivery(t *testing.T) (*RealPacketDelivery, *mockTransport, *mockClock) {
+	t.Helper()
+	transport := newMockTransport()
+	transport.friends[1] = &mockAddr{network: "udp", address: "127.0.0.1:33445"}
+	pd := NewRealPacketDelivery(transport, defaultConfig())
+	pd.SetSleeper(&mockSleeper{})
+	clock := &mockClock{now: time.Unix(1700000000, 0)}
+	pd.SetClock(clock)
+	if err := pd.SetCircuitBreakerConfig(CircuitBreakerConfig{ConsecutiveFailures: 3, OpenDuration: 10 * time.Second}); err != nil {
+		t.Fatal(err)
+	}
+	return pd, transport, clock
+}
+
+func circuitState(pd *RealPacketDelivery, friendID uint32) interfaces.CircuitState {
+	return pd.GetTypedStats().CircuitStates[friendID]
+}
+
+func TestCircuitBreakerConfigValidate(t *testing.T) {
+	if err := DefaultCircuitBreakerConfig().Validate(); err != nil {
+		t.Errorf("default config should be valid: %v", err)
+	}
+	pd := NewRealPacketDelivery(newMockTransport(), defaultConfig())
+	if err := pd.SetCircuitBreakerConfig(CircuitBreakerConfig{ConsecutiveFailures: 0, OpenDuration: time.Second}); err == nil {
+		t.Error("expected error for zero failure threshold")
+	}
+	if err := pd.SetCircuitBreakerConfig(CircuitBreakerConfig{ConsecutiveFailures: 1}); err == nil {
+		t.Error("expected error for zero open duration")
+	}
+}
+
+func TestCircuitBreaker_OpensAfterConsecutiveFailures(t *testing.T) {
+	pd, transport, _ := newBreakerTestDelivery(t)
+	transport.setSendToFriendErr(errors.New("network error"))
+
+	for i := 0; i < 3; i++ {
+		if state := circuitState(pd, 1); state != interfaces.CircuitClosed {
+			t.Fatalf("breaker %v after %d failures, want closed", state, i)
+		}
+		if err := pd.DeliverPacket(1, []byte("x")); err == nil || errors.Is(err, interfaces.ErrCircuitOpen) {
+			t.Fatalf("delivery %d: expected a network error, got %v", i, err)
+		}
+	}
+	if state := circuitState(pd, 1); state != interfaces.CircuitOpen {
+		t.Fatalf("expected breaker open, got %v", state)
+	}
+
+	// Open breakers fail fast without touching the network
+	sent := atomic.LoadInt32(&transport.sendCount)
+	if err := pd.DeliverPacket(1, []byte("x")); !errors.Is(err, interfaces.ErrCircuitOpen) {
+		t.Errorf("expected ErrCircuitOpen, got %v", err)
+	}
+	if atomic.LoadInt32(&transport.sendCount) != sent {
+		t.Error("open breaker must not send")
+	}
+}
+
+func TestCircuitBreaker_SuccessResetsFailureCount(t *testing.T) {
+	pd, transport, _ := newBreakerTestDelivery(t)
+	for i := 0; i < 5; i++ {
+		transport.setSendToFriendErr(errors.New("network error"))
+		pd.DeliverPacket(1, []byte("x"))
+		pd.DeliverPacket(1, []byte("x"))
+		transport.setSendToFriendErr(nil)
+		if err := pd.DeliverPacket(1, []byte("x")); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if state := circuitState(pd, 1); state != interfaces.CircuitClosed {
+		t.Errorf("non-consecutive failures must not open the breaker, got %v", state)
+	}
+}
+
+func TestCircuitBreaker_HalfOpenProbe(t *testing.T) {
+	pd, transport, clock := newBreakerTestDelivery(t)
+	transport.setSendToFriendErr(errors.New("network error"))
+	for i := 0; i < 3; i++ {
+		pd.DeliverPacket(1, []byte("x"))
+	}
+
+	// Still open just before OpenDuration elapses
+	clock.now = clock.now.Add(10*time.Second - time.Millisecond)
+	if err := pd.DeliverPacket(1, []byte("x")); !errors.Is(err, interfaces.ErrCircuitOpen) {
+		t.Fatalf("expected ErrCircuitOpen, got %v", err)
+	}
+
+	// A failed probe makes one attempt and restarts the open timer
+	clock.now = clock.now.Add(time.Millisecond)
+	if state := circuitState(pd, 1); state != interfaces.CircuitHalfOpen {
+		t.Fatalf("expected half-open after OpenDuration, got %v", state)
+	}
+	sent := atomic.LoadInt32(&transport.sendCount)
+	if err := pd.DeliverPacket(1, []byte("x")); err == nil || errors.Is(err, interfaces.ErrCircuitOpen) {
+		t.Fatalf("expected probe to fail with a network error, got %v", err)
+	}
+	if n := atomic.LoadInt32(&transport.sendCount) - sent; n != 1 {
+		t.Errorf("probe made %d attempts, want 1", n)
+	}
+	if state := circuitState(pd, 1); state != interfaces.CircuitOpen {
+		t.Fatalf("expected breaker reopened after failed probe, got %v", state)
+	}
+	clock.now = clock.now.Add(5 * time.Second)
+	if err := pd.DeliverPacket(1, []byte("x")); !errors.Is(err, interfaces.ErrCircuitOpen) {
+		t.Fatalf("expected open timer restarted by failed probe, got %v", err)
+	}
+
+	// A successful probe closes the breaker
+	clock.now = clock.now.Add(5 * time.Second)
+	transport.setSendToFriendErr(nil)
+	if err := pd.DeliverPacket(1, []byte("x")); err != nil {
+		t.Fatalf("expected probe to succeed, got %v", err)
+	}
+	if state := circuitState(pd, 1); state != interfaces.CircuitClosed {
+		t.Errorf("expected breaker closed after successful probe, got %v", state)
+	}
+	if len(pd.GetTypedStats().CircuitStates) != 0 {
+		t.Error("closed breakers are not listed in stats")
+	}
+}
+
+func TestCircuitBreaker_SingleProbeInFlight(t *testing.T) {
+	pd, _, clock := newBreakerTestDelivery(t)
+	pd.breakers[1] = &circuitBreaker{state: interfaces.CircuitOpen, openedAt: clock.now}
+	clock.now = clock.now.Add(10 * time.Second)
+
+	probe, err := pd.admitDelivery(1)
+	if err != nil || !probe {
+		t.Fatalf("expected first delivery admitted as probe, got %v, %v", probe, err)
+	}
+	if _, err := pd.admitDelivery(1); !errors.Is(err, interfaces.ErrCircuitOpen) {
+		t.Errorf("expected concurrent delivery to fail fast during probe, got %v", err)
+	}
+	pd.recordDelivery(1, true)
+	if probe, err := pd.admitDelivery(1); err != nil || probe {
+		t.Errorf("expected normal delivery after probe success, got %v, %v", probe, err)
+	}
+}
+
+func TestCircuitBreaker_PerFriend(t *testing.T) {
+	pd, transport, _ := newBreakerTestDelivery(t)
+	transport.friends[2] = &mockAddr{network: "udp", address: "127.0.0.2:33445"}
+	pd.breakers[1] = &circuitBreaker{state: interfaces.CircuitOpen, openedAt: pd.clock.Now()}
+
+	if err := pd.DeliverPacket(2, []byte("x")); err != nil {
+		t.Errorf("friend 2 must be unaffected by friend 1's breaker: %v", err)
+	}
+	if err := pd.RemoveFriend(1); err != nil {
+		t.Fatal(err)
+	}
+	if _, exists := pd.GetTypedStats().CircuitStates[1]; exists {
+		t.Error("RemoveFriend should drop the friend's breaker")
+	}
+}
+
+func TestCircuitStateString(t *testing.T) {
+	for state, want := range map[interfaces.CircuitState]string{
+		interfaces.CircuitClosed:   "closed",
+		interfaces.CircuitOpen:     "open",
+		interfaces.CircuitHalfOpen: "half-open",
+		interfaces.CircuitState(9): "unknown",
+	} {
+		if got := state.String(); got != want {
+			t.Errorf("%d.String() = %q, want %q", int(state), got, want)
+		}
+	}
+}
@@ -73,6 +73,21 @@
 // (500ms * attempt number) to avoid overwhelming the network during
 // transient failures.
 //
+// # Circuit Breaker
+//
+// A per-friend circuit breaker stops retry storms against unreachable
+// friends. After ConsecutiveFailures failed deliveries in a row (default 5)
+// the breaker opens and DeliverPacket returns interfaces.ErrCircuitOpen
+// immediately for OpenDuration (default 30s). The next delivery after that
+// is a single-attempt probe: success closes the breaker, failure reopens it
+// for another OpenDuration. GetTypedStats reports open and half-open
+// breakers in CircuitStates:
+//
+//	delivery.SetCircuitBreakerConfig(real.CircuitBreakerConfig{
+//	    ConsecutiveFailures: 5,
+//	    OpenDuration:        30 * time.Second,
+//	})
+//
 // # Thread Safety
 //
 // All methods on RealPacketDelivery are safe for concurrent use.
@@ -94,6 +109,8 @@
 //
 //	delivery.SetSleeper(&mockSleeper{})
 //
+// SetClock likewise injects the time source used by the circuit breakers.
+//
 // # Comparison with Simulation
 //
 // The real package provides actual network delivery, while simulation
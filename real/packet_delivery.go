@@ -1,6 +1,7 @@
 package real
 
 import (
+	"errors"
 	"fmt"
 	"net"
 	"sync"
@@ -24,15 +25,27 @@ func (DefaultSleeper) Sleep(d time.Duration) {
 	time.Sleep(d)
 }
 
-// RealPacketDelivery implements actual network-based packet delivery
+// RealPacketDelivery implements actual network-based packet delivery.
+//
+// Each friend has a circuit breaker: after ConsecutiveFailures failed
+// deliveries in a row it opens and DeliverPacket returns
+// interfaces.ErrCircuitOpen without touching the network for OpenDuration.
+// A single probe attempt then decides whether it closes or reopens.
 type RealPacketDelivery struct {
-	transport   interfaces.INetworkTransport
-	friendAddrs map[uint32]net.Addr
-	config      *interfaces.PacketDeliveryConfig
-	mu          sync.RWMutex
-	sleeper     Sleeper
+	transport     interfaces.INetworkTransport
+	friendAddrs   map[uint32]net.Addr
+	config        *interfaces.PacketDeliveryConfig
+	mu            sync.RWMutex
+	sleeper       Sleeper
+	clock         Clock
+	preHook       interfaces.PacketHook
+	postHook      interfaces.PacketHook
+	breakers      map[uint32]*circuitBreaker
+	breakerConfig CircuitBreakerConfig
 }
 
+var _ interfaces.PacketHookable = (*RealPacketDelivery)(nil)
+
 // NewRealPacketDelivery creates a new real packet delivery implementation
 func NewRealPacketDelivery(transport interfaces.INetworkTransport, config *interfaces.PacketDeliveryConfig) *RealPacketDelivery {
 	if config == nil {
@@ -46,10 +59,13 @@ func NewRealPacketDelivery(transport interfaces.INetworkTransport, config *inter
 	}).Info("Creating real packet delivery implementation")
 
 	return &RealPacketDelivery{
-		transport:   transport,
-		friendAddrs: make(map[uint32]net.Addr),
-		config:      config,
-		sleeper:     DefaultSleeper{},
+		transport:     transport,
+		friendAddrs:   make(map[uint32]net.Addr),
+		config:        config,
+		sleeper:       DefaultSleeper{},
+		clock:         DefaultClock{},
+		breakers:      make(map[uint32]*circuitBreaker),
+		breakerConfig: DefaultCircuitBreakerConfig(),
 	}
 }
 
@@ -78,13 +94,85 @@ func (r *RealPacketDelivery) DeliverPacket(friendID uint32, packet []byte) error
 		return fmt.Errorf("no network transport configured")
 	}
 
+	packet, err := r.runPreDeliveryHook(friendID, packet)
+	if errors.Is(err, interfaces.ErrDropPacket) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
 	addr, err := r.resolveFriendAddress(friendID, transport)
 	if err != nil {
 		return err
 	}
 	_ = addr
 
-	return r.attemptDeliveryWithRetries(friendID, packet, transport)
+	probe, err := r.admitDelivery(friendID)
+	if err != nil {
+		return err
+	}
+
+	err = r.attemptDeliveryWithRetries(friendID, packet, transport, probe)
+	r.recordDelivery(friendID, err == nil)
+	return err
+}
+
+// SetPreDeliveryHook implements interfaces.PacketHookable.
+func (r *RealPacketDelivery) SetPreDeliveryHook(hook interfaces.PacketHook) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.preHook = hook
+}
+
+// SetPostReceiveHook implements interfaces.PacketHookable.
+func (r *RealPacketDelivery) SetPostReceiveHook(hook interfaces.PacketHook) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.postHook = hook
+}
+
+// ProcessReceivedPacket implements interfaces.PacketHookable.
+func (r *RealPacketDelivery) ProcessReceivedPacket(friendID uint32, packet []byte) ([]byte, error) {
+	r.mu.RLock()
+	hook := r.postHook
+	r.mu.RUnlock()
+
+	return applyHook(hook, "RealPacketDelivery.ProcessReceivedPacket", friendID, packet)
+}
+
+// runPreDeliveryHook applies the pre-delivery hook, if any.
+func (r *RealPacketDelivery) runPreDeliveryHook(friendID uint32, packet []byte) ([]byte, error) {
+	r.mu.RLock()
+	hook := r.preHook
+	r.mu.RUnlock()
+
+	return applyHook(hook, "RealPacketDelivery.DeliverPacket", friendID, packet)
+}
+
+// applyHook runs hook on packet, logging drops and wrapping other errors.
+// A nil hook passes the packet through.
+func applyHook(hook interfaces.PacketHook, function string, friendID uint32, packet []byte) ([]byte, error) {
+	if hook == nil {
+		return packet, nil
+	}
+	result, err := hook(friendID, packet)
+	if errors.Is(err, interfaces.ErrDropPacket) {
+		logrus.WithFields(logrus.Fields{
+			"function":  function,
+			"friend_id": friendID,
+		}).Debug("Packet dropped by hook")
+		return nil, err
+	}
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"function":  function,
+			"friend_id": friendID,
+			"error":     err.Error(),
+		}).Warn("Packet hook rejected packet")
+		return nil, fmt.Errorf("packet hook rejected packet for friend %d: %w", friendID, err)
+	}
+	return result, nil
 }
 
 // resolveFriendAddress retrieves or caches the address for a friend.
@@ -121,10 +209,11 @@ func (r *RealPacketDelivery) fetchAndCacheAddress(friendID uint32, transport int
 
 // attemptDeliveryWithRetries tries to deliver a packet with exponential backoff.
 // RetryAttempts is treated as the total number of attempts; it is clamped to at
-// least 1 so that a zero value does not silently skip delivery (L-11).
-func (r *RealPacketDelivery) attemptDeliveryWithRetries(friendID uint32, packet []byte, transport interfaces.INetworkTransport) error {
+// least 1 so that a zero value does not silently skip delivery (L-11). A
+// half-open circuit breaker probe makes a single attempt.
+func (r *RealPacketDelivery) attemptDeliveryWithRetries(friendID uint32, packet []byte, transport interfaces.INetworkTransport, probe bool) error {
 	attempts := r.config.RetryAttempts
-	if attempts < 1 {
+	if attempts < 1 || probe {
 		attempts = 1
 	}
 	var lastErr error
@@ -135,7 +224,7 @@ func (r *RealPacketDelivery) attemptDeliveryWithRetries(friendID uint32, packet
 		} else {
 			lastErr = err
 			logDeliveryRetry(friendID, attempt+1, err)
-			r.waitBeforeRetry(attempt)
+			r.waitBeforeRetry(attempt, attempts)
 		}
 	}
 
@@ -163,8 +252,8 @@ func logDeliveryRetry(friendID uint32, attempt int, err error) {
 }
 
 // waitBeforeRetry implements exponential backoff between retries.
-func (r *RealPacketDelivery) waitBeforeRetry(attempt int) {
-	if attempt < r.config.RetryAttempts-1 {
+func (r *RealPacketDelivery) waitBeforeRetry(attempt, attempts int) {
+	if attempt < attempts-1 {
 		r.sleeper.Sleep(time.Duration(500*(attempt+1)) * time.Millisecond)
 	}
 }
@@ -382,6 +471,7 @@ func (r *RealPacketDelivery) RemoveFriend(friendID uint32) error {
 	defer r.mu.Unlock()
 
 	delete(r.friendAddrs, friendID)
+	delete(r.breakers, friendID)
 
 	logrus.WithFields(logrus.Fields{
 		"function":          "RealPacketDelivery.RemoveFriend",
@@ -426,15 +516,29 @@ func (r *RealPacketDelivery) GetStats() map[string]interface{} {
 // GetTypedStats returns type-safe statistics about packet delivery.
 //
 // This method provides structured access to delivery statistics without
-// the type assertion requirements of GetStats().
+// the type assertion requirements of GetStats(). CircuitStates lists the
+// friends whose circuit breaker is open or half-open.
 //
 // Thread-safe: uses read lock for concurrent access.
 func (r *RealPacketDelivery) GetTypedStats() interfaces.PacketDeliveryStats {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
+	states := make(map[uint32]interfaces.CircuitState)
+	now := r.clock.Now()
+	for friendID, b := range r.breakers {
+		state := b.state
+		if state == interfaces.CircuitOpen && now.Sub(b.openedAt) >= r.breakerConfig.OpenDuration {
+			state = interfaces.CircuitHalfOpen // Next delivery is the probe
+		}
+		if state != interfaces.CircuitClosed {
+			states[friendID] = state
+		}
+	}
+
 	return interfaces.PacketDeliveryStats{
-		IsSimulation: false,
-		FriendCount:  len(r.friendAddrs),
+		IsSimulation:  false,
+		FriendCount:   len(r.friendAddrs),
+		CircuitStates: states,
 	}
 }
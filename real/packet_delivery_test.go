@@ -672,3 +672,55 @@ func TestSetSleeper(t *testing.T) {
 		t.Error("expected custom sleeper to be set")
 	}
 }
+
+func TestPacketHooks(t *testing.T) {
+	transport := newMockTransport()
+	pd := NewRealPacketDelivery(transport, defaultConfig())
+	transport.friends[1] = &mockAddr{network: "udp", address: "127.0.0.1:33445"}
+
+	var seen []byte
+	pd.SetPreDeliveryHook(func(friendID uint32, packet []byte) ([]byte, error) {
+		seen = packet
+		return append([]byte{0xFF}, packet...), nil
+	})
+	if err := pd.DeliverPacket(1, []byte("data")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(seen) != "data" || atomic.LoadInt32(&transport.sendCount) != 1 {
+		t.Errorf("hook saw %q, send count %d", seen, transport.sendCount)
+	}
+
+	pd.SetPreDeliveryHook(func(uint32, []byte) ([]byte, error) {
+		return nil, interfaces.ErrDropPacket
+	})
+	if err := pd.DeliverPacket(1, []byte("data")); err != nil {
+		t.Errorf("dropped packet should not report an error: %v", err)
+	}
+	if got := atomic.LoadInt32(&transport.sendCount); got != 1 {
+		t.Errorf("dropped packet was sent, send count %d", got)
+	}
+
+	errBlocked := errors.New("blocked")
+	pd.SetPreDeliveryHook(func(uint32, []byte) ([]byte, error) {
+		return nil, errBlocked
+	})
+	if err := pd.DeliverPacket(1, []byte("data")); !errors.Is(err, errBlocked) {
+		t.Errorf("expected hook error, got %v", err)
+	}
+
+	pd.SetPreDeliveryHook(nil)
+	if err := pd.DeliverPacket(1, []byte("data")); err != nil {
+		t.Errorf("unexpected error after removing hook: %v", err)
+	}
+
+	got, err := pd.ProcessReceivedPacket(1, []byte("in"))
+	if err != nil || string(got) != "in" {
+		t.Errorf("without hook got %q, %v", got, err)
+	}
+	pd.SetPostReceiveHook(func(uint32, []byte) ([]byte, error) {
+		return nil, interfaces.ErrDropPacket
+	})
+	if _, err := pd.ProcessReceivedPacket(1, []byte("in")); !errors.Is(err, interfaces.ErrDropPacket) {
+		t.Errorf("expected ErrDropPacket, got %v", err)
+	}
+}
@@ -56,10 +56,31 @@
 //   - Timestamp: Unix nanoseconds when delivery occurred
 //   - Success: Whether the delivery succeeded
 //   - Error: Any error that occurred during delivery
+//   - Latency: Simulated latency when network conditions are enabled
+//   - Sequence: Send order when network conditions are enabled
 //
 // Use GetDeliveryLog to retrieve the log, and ClearDeliveryLog to reset
 // between test cases.
 //
+// # Network Conditions
+//
+// NewSimulatedPacketDeliveryWithConditions applies a simulated network to
+// DeliverPacket: Gaussian latency (LatencyMean, LatencyStdDev), random loss
+// reported as ErrPacketLost, and reordering, where a packet is held back in
+// a small queue and logged after the next one. Latency is applied through
+// the real.Sleeper set with SetSleeper, and a seeded rand.Source makes runs
+// reproducible:
+//
+//	sim, err := simulation.NewSimulatedPacketDeliveryWithConditions(config,
+//	    simulation.NetworkConditions{
+//	        LatencyMean:           50 * time.Millisecond,
+//	        LatencyStdDev:         10 * time.Millisecond,
+//	        PacketLossProbability: 0.05,
+//	        ReorderProbability:    0.02,
+//	    }, rand.NewSource(1))
+//
+// GetTypedStats reports the mean latency of successful deliveries.
+//
 // # Thread Safety
 //
 // All methods on SimulatedPacketDelivery are safe for concurrent use from
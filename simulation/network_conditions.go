@@ -0,0 +1,158 @@
+package simulation
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/opd-ai/toxcore/interfaces"
+	"github.com/opd-ai/toxcore/real"
+	"github.com/sirupsen/logrus"
+)
+
+// ErrPacketLost is returned by DeliverPacket when the simulated network
+// drops the packet.
+var ErrPacketLost = errors.New("packet lost by simulated network")
+
+// maxHeldPackets bounds the holding queue used to reorder packets. When it
+// is full, packets selected for reordering are delivered in order.
+const maxHeldPackets = 4
+
+// NetworkConditions describes the simulated network applied by
+// DeliverPacket. The zero value delivers every packet instantly and in
+// order.
+type NetworkConditions struct {
+	// LatencyMean is the mean one-way delay.
+	LatencyMean time.Duration
+	// LatencyStdDev is the standard deviation of the Gaussian delay.
+	// Sampled delays below zero are clamped to zero.
+	LatencyStdDev time.Duration
+	// PacketLossProbability is the chance in [0, 1] that a packet is lost.
+	PacketLossProbability float64
+	// ReorderProbability is the chance in [0, 1] that a packet is held back
+	// and delivered after the next packet.
+	ReorderProbability float64
+}
+
+// Validate checks that durations are non-negative and probabilities lie in
+// [0, 1].
+func (c NetworkConditions) Validate() error {
+	if c.LatencyMean < 0 || c.LatencyStdDev < 0 {
+		return fmt.Errorf("latency mean and standard deviation cannot be negative: %v, %v", c.LatencyMean, c.LatencyStdDev)
+	}
+	if !validProbability(c.PacketLossProbability) {
+		return fmt.Errorf("packet loss probability must be in [0, 1]: %f", c.PacketLossProbability)
+	}
+	if !validProbability(c.ReorderProbability) {
+		return fmt.Errorf("reorder probability must be in [0, 1]: %f", c.ReorderProbability)
+	}
+	return nil
+}
+
+// validProbability reports whether p is in [0, 1].
+func validProbability(p float64) bool {
+	return !math.IsNaN(p) && p >= 0 && p <= 1
+}
+
+// NewSimulatedPacketDeliveryWithConditions creates a simulation that applies
+// network conditions to DeliverPacket: each packet is delayed by a latency
+// drawn from a Gaussian distribution, may be lost (DeliverPacket returns
+// ErrPacketLost), and may be held back and delivered after the next packet.
+// Delays are applied through the simulation's Sleeper, which SetSleeper can
+// replace. All randomness is drawn from source, so a fixed seed reproduces
+// the same run; a nil source is seeded from the current time. Broadcasts are
+// not subject to the conditions.
+func NewSimulatedPacketDeliveryWithConditions(config *interfaces.PacketDeliveryConfig, conditions NetworkConditions, source rand.Source) (*SimulatedPacketDelivery, error) {
+	if err := conditions.Validate(); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"function": "NewSimulatedPacketDeliveryWithConditions",
+			"error":    err.Error(),
+		}).Error("Invalid network conditions")
+		return nil, err
+	}
+	if source == nil {
+		source = rand.NewSource(time.Now().UnixNano())
+	}
+
+	sim := NewSimulatedPacketDelivery(config)
+	sim.conditions = &conditions
+	sim.rng = rand.New(source)
+
+	logrus.WithFields(logrus.Fields{
+		"function":       "NewSimulatedPacketDeliveryWithConditions",
+		"latency_mean":   conditions.LatencyMean.String(),
+		"latency_stddev": conditions.LatencyStdDev.String(),
+		"loss":           conditions.PacketLossProbability,
+		"reorder":        conditions.ReorderProbability,
+	}).Info("Simulated network conditions enabled")
+	return sim, nil
+}
+
+// SetSleeper sets the Sleeper used to apply simulated latency (primarily
+// for testing, to avoid real delays).
+func (s *SimulatedPacketDelivery) SetSleeper(sleeper real.Sleeper) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sleeper = sleeper
+}
+
+// deliverWithConditionsLocked applies the network conditions to a packet
+// for a known friend. The caller must hold s.mu; it is released while the
+// latency is slept.
+func (s *SimulatedPacketDelivery) deliverWithConditionsLocked(friendID uint32, packet []byte) error {
+	c := s.conditions
+	delay := time.Duration(s.rng.NormFloat64()*float64(c.LatencyStdDev)) + c.LatencyMean
+	if delay < 0 {
+		delay = 0
+	}
+	lost := s.rng.Float64() < c.PacketLossProbability
+	hold := !lost && s.rng.Float64() < c.ReorderProbability
+	s.nextSequence++
+	record := DeliveryRecord{
+		FriendID:   friendID,
+		PacketSize: len(packet),
+		Sequence:   s.nextSequence,
+		Latency:    delay,
+	}
+
+	sleeper := s.sleeper
+	s.mu.Unlock()
+	sleeper.Sleep(delay)
+	s.mu.Lock()
+
+	record.Timestamp = time.Now().UnixNano()
+	if lost {
+		record.Error = ErrPacketLost
+		s.deliveryLog = append(s.deliveryLog, record)
+		logrus.WithFields(logrus.Fields{
+			"function":  "SimulatedPacketDelivery.DeliverPacket",
+			"friend_id": friendID,
+			"sequence":  record.Sequence,
+		}).Debug("Simulated packet loss")
+		return fmt.Errorf("friend %d: %w", friendID, ErrPacketLost)
+	}
+
+	record.Success = true
+	if hold && len(s.held) < maxHeldPackets {
+		s.held = append(s.held, record)
+		return nil
+	}
+
+	s.deliveryLog = append(s.deliveryLog, record)
+	s.releaseHeldLocked(record)
+	return nil
+}
+
+// releaseHeldLocked delivers the held packets right after next, so each
+// arrives after a packet sent later than it. Their latency grows by the
+// time spent waiting for next. The caller must hold s.mu.
+func (s *SimulatedPacketDelivery) releaseHeldLocked(next DeliveryRecord) {
+	for _, record := range s.held {
+		record.Latency += next.Latency
+		record.Timestamp = next.Timestamp
+		s.deliveryLog = append(s.deliveryLog, record)
+	}
+	s.held = s.held[:0]
+}
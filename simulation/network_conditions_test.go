@@ -0,0 +1,229 @@
+package simulation
+
+import (
+	"errors"
+	"math/rand"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordingSleeper records requested delays instead of sleeping.
+type recordingSleeper struct {
+	mu     sync.Mutex
+	delays []time.Duration
+}
+
+func (r *recordingSleeper) Sleep(d time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.delays = append(r.delays, d)
+}
+
+func newConditionedSim(t *testing.T, conditions NetworkConditions, seed int64) (*SimulatedPacketDelivery, *recordingSleeper) {
+	t.Helper()
+	sim, err := NewSimulatedPacketDeliveryWithConditions(newTestConfig(), conditions, rand.NewSource(seed))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sleeper := &recordingSleeper{}
+	sim.SetSleeper(sleeper)
+	sim.AddFriend(1, nil)
+	return sim, sleeper
+}
+
+func TestNetworkConditionsValidate(t *testing.T) {
+	tests := []struct {
+		name       string
+		conditions NetworkConditions
+	}{
+		{"negative mean", NetworkConditions{LatencyMean: -time.Millisecond}},
+		{"negative stddev", NetworkConditions{LatencyStdDev: -time.Millisecond}},
+		{"loss above one", NetworkConditions{PacketLossProbability: 1.5}},
+		{"negative reorder", NetworkConditions{ReorderProbability: -0.1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewSimulatedPacketDeliveryWithConditions(newTestConfig(), tt.conditions, nil); err == nil {
+				t.Error("expected validation error")
+			}
+		})
+	}
+
+	if _, err := NewSimulatedPacketDeliveryWithConditions(newTestConfig(), NetworkConditions{}, nil); err != nil {
+		t.Errorf("zero conditions should be valid: %v", err)
+	}
+}
+
+func TestNetworkConditionsLatency(t *testing.T) {
+	sim, sleeper := newConditionedSim(t, NetworkConditions{
+		LatencyMean:   50 * time.Millisecond,
+		LatencyStdDev: 10 * time.Millisecond,
+	}, 1)
+
+	const packets = 2000
+	for i := 0; i < packets; i++ {
+		if err := sim.DeliverPacket(1, []byte("data")); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if len(sleeper.delays) != packets {
+		t.Fatalf("expected %d sleeps, got %d", packets, len(sleeper.delays))
+	}
+	var total time.Duration
+	for i, record := range sim.GetDeliveryLog() {
+		if record.Latency != sleeper.delays[i] {
+			t.Errorf("record %d latency %v does not match sleep %v", i, record.Latency, sleeper.delays[i])
+		}
+		total += record.Latency
+	}
+	mean := total / packets
+	if mean < 49*time.Millisecond || mean > 51*time.Millisecond {
+		t.Errorf("expected mean latency near 50ms, got %v", mean)
+	}
+
+	stats := sim.GetTypedStats()
+	if stats.AverageLatencyMs < 49 || stats.AverageLatencyMs > 51 {
+		t.Errorf("expected AverageLatencyMs near 50, got %f", stats.AverageLatencyMs)
+	}
+}
+
+func TestNetworkConditionsNegativeLatencyClamped(t *testing.T) {
+	sim, sleeper := newConditionedSim(t, NetworkConditions{LatencyStdDev: 10 * time.Millisecond}, 1)
+	for i := 0; i < 100; i++ {
+		_ = sim.DeliverPacket(1, []byte("data"))
+	}
+	for _, d := range sleeper.delays {
+		if d < 0 {
+			t.Fatalf("negative delay %v", d)
+		}
+	}
+}
+
+func TestNetworkConditionsLoss(t *testing.T) {
+	sim, _ := newConditionedSim(t, NetworkConditions{PacketLossProbability: 0.25}, 1)
+
+	const packets = 4000
+	var lost int
+	for i := 0; i < packets; i++ {
+		err := sim.DeliverPacket(1, []byte("data"))
+		if errors.Is(err, ErrPacketLost) {
+			lost++
+		} else if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if rate := float64(lost) / packets; rate < 0.22 || rate > 0.28 {
+		t.Errorf("expected loss rate near 0.25, got %f", rate)
+	}
+
+	stats := sim.GetTypedStats()
+	if stats.PacketsFailed != int64(lost) {
+		t.Errorf("expected %d failed deliveries, got %d", lost, stats.PacketsFailed)
+	}
+	for _, record := range sim.GetDeliveryLog() {
+		if !record.Success && !errors.Is(record.Error, ErrPacketLost) {
+			t.Errorf("failed record has unexpected error %v", record.Error)
+		}
+	}
+}
+
+func TestNetworkConditionsReorder(t *testing.T) {
+	sim, _ := newConditionedSim(t, NetworkConditions{
+		LatencyMean:        10 * time.Millisecond,
+		ReorderProbability: 0.3,
+	}, 1)
+
+	const packets = 200
+	for i := 0; i < packets; i++ {
+		if err := sim.DeliverPacket(1, []byte("data")); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	log := sim.GetDeliveryLog()
+	seen := make(map[uint64]bool)
+	var reordered int
+	for i, record := range log {
+		if seen[record.Sequence] {
+			t.Fatalf("sequence %d delivered twice", record.Sequence)
+		}
+		seen[record.Sequence] = true
+		if i > 0 && record.Sequence < log[i-1].Sequence {
+			reordered++
+			if record.Latency <= 10*time.Millisecond {
+				t.Errorf("reordered packet %d should include time held, got %v", record.Sequence, record.Latency)
+			}
+		}
+	}
+	if reordered == 0 {
+		t.Error("expected some packets to be reordered")
+	}
+
+	// Packets still held are delivered by the next one and none are lost
+	sim.conditions.ReorderProbability = 0
+	if err := sim.DeliverPacket(1, []byte("data")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := len(sim.GetDeliveryLog()); got != packets+1 {
+		t.Errorf("expected %d records after flush, got %d", packets+1, got)
+	}
+}
+
+func TestNetworkConditionsDeterministic(t *testing.T) {
+	conditions := NetworkConditions{
+		LatencyMean:           20 * time.Millisecond,
+		LatencyStdDev:         5 * time.Millisecond,
+		PacketLossProbability: 0.1,
+		ReorderProbability:    0.1,
+	}
+	run := func() []DeliveryRecord {
+		sim, _ := newConditionedSim(t, conditions, 42)
+		for i := 0; i < 100; i++ {
+			_ = sim.DeliverPacket(1, []byte("data"))
+		}
+		return sim.GetDeliveryLog()
+	}
+
+	first, second := run(), run()
+	if len(first) != len(second) {
+		t.Fatalf("runs differ in length: %d vs %d", len(first), len(second))
+	}
+	for i := range first {
+		if first[i].Sequence != second[i].Sequence || first[i].Latency != second[i].Latency || first[i].Success != second[i].Success {
+			t.Fatalf("runs differ at record %d", i)
+		}
+	}
+}
+
+func TestNetworkConditionsClearDeliveryLog(t *testing.T) {
+	sim, _ := newConditionedSim(t, NetworkConditions{ReorderProbability: 1}, 1)
+
+	if err := sim.DeliverPacket(1, []byte("data")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(sim.GetDeliveryLog()) != 0 {
+		t.Fatal("held packet should not be logged yet")
+	}
+
+	sim.ClearDeliveryLog()
+	sim.conditions.ReorderProbability = 0
+	if err := sim.DeliverPacket(1, []byte("data")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	log := sim.GetDeliveryLog()
+	if len(log) != 1 || log[0].Sequence != 2 {
+		t.Errorf("cleared held packet should not be released, got %+v", log)
+	}
+}
+
+func TestNetworkConditionsUnknownFriend(t *testing.T) {
+	sim, sleeper := newConditionedSim(t, NetworkConditions{LatencyMean: time.Millisecond}, 1)
+	if err := sim.DeliverPacket(99, []byte("data")); err == nil || errors.Is(err, ErrPacketLost) {
+		t.Errorf("expected friend not found error, got %v", err)
+	}
+	if len(sleeper.delays) != 0 {
+		t.Error("unknown friends should fail without simulated latency")
+	}
+}
@@ -1,12 +1,15 @@
 package simulation
 
 import (
+	"errors"
 	"fmt"
+	"math/rand"
 	"net"
 	"sync"
 	"time"
 
 	"github.com/opd-ai/toxcore/interfaces"
+	"github.com/opd-ai/toxcore/real"
 	"github.com/sirupsen/logrus"
 )
 
@@ -16,8 +19,19 @@ type SimulatedPacketDelivery struct {
 	friendMap   map[uint32]bool
 	config      *interfaces.PacketDeliveryConfig
 	mu          sync.RWMutex
+	preHook     interfaces.PacketHook
+	postHook    interfaces.PacketHook
+
+	// Network condition simulation; conditions is nil for instant delivery
+	conditions   *NetworkConditions
+	rng          *rand.Rand
+	sleeper      real.Sleeper
+	held         []DeliveryRecord // Packets held back for reordering
+	nextSequence uint64
 }
 
+var _ interfaces.PacketHookable = (*SimulatedPacketDelivery)(nil)
+
 // DeliveryRecord represents a packet delivery event for testing verification.
 // Each record captures metadata about a delivery attempt, enabling test code
 // to verify correct delivery behavior including timing, success/failure, and
@@ -33,6 +47,13 @@ type DeliveryRecord struct {
 	Success bool
 	// Error holds any error that occurred during delivery (nil on success).
 	Error error
+	// Latency is the simulated delivery latency, including time held back
+	// for reordering. Zero without network conditions.
+	Latency time.Duration
+	// Sequence numbers packets in send order, starting at 1, when network
+	// conditions are simulated; reordered packets appear in the log out of
+	// sequence. Zero without network conditions.
+	Sequence uint64
 }
 
 // NewSimulatedPacketDelivery creates a new simulation implementation for testing
@@ -51,6 +72,7 @@ func NewSimulatedPacketDelivery(config *interfaces.PacketDeliveryConfig) *Simula
 		deliveryLog: make([]DeliveryRecord, 0),
 		friendMap:   make(map[uint32]bool),
 		config:      config,
+		sleeper:     real.DefaultSleeper{},
 	}
 }
 
@@ -63,9 +85,26 @@ func (s *SimulatedPacketDelivery) DeliverPacket(friendID uint32, packet []byte)
 		"packet_size": len(packet),
 	}).Info("Simulating packet delivery")
 
+	hooked, err := s.runHook(s.getPreDeliveryHook(), friendID, packet)
+	if errors.Is(err, interfaces.ErrDropPacket) {
+		return nil
+	}
+
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	if err != nil {
+		s.deliveryLog = append(s.deliveryLog, DeliveryRecord{
+			FriendID:   friendID,
+			PacketSize: len(packet),
+			Timestamp:  time.Now().UnixNano(),
+			Success:    false,
+			Error:      err,
+		})
+		return err
+	}
+	packet = hooked
+
 	// Check if friend exists
 	if !s.friendMap[friendID] {
 		err := fmt.Errorf("friend %d not found in simulation", friendID)
@@ -86,6 +125,10 @@ func (s *SimulatedPacketDelivery) DeliverPacket(friendID uint32, packet []byte)
 		return err
 	}
 
+	if s.conditions != nil {
+		return s.deliverWithConditionsLocked(friendID, packet)
+	}
+
 	// Simulate successful delivery
 	s.deliveryLog = append(s.deliveryLog, DeliveryRecord{
 		FriendID:   friendID,
@@ -130,6 +173,7 @@ func (s *SimulatedPacketDelivery) BroadcastPacket(packet []byte, excludeFriends
 
 	var successCount int
 	var excludedCount int
+	var failed []uint32
 
 	// Simulate delivery to each friend
 	for friendID := range s.friendMap {
@@ -138,14 +182,24 @@ func (s *SimulatedPacketDelivery) BroadcastPacket(packet []byte, excludeFriends
 			continue
 		}
 
+		// Hooks run under the lock here, so they must not call back into s
+		friendPacket, err := s.runHook(s.preHook, friendID, packet)
+		if errors.Is(err, interfaces.ErrDropPacket) {
+			continue
+		}
+
 		// Simulate delivery
 		s.deliveryLog = append(s.deliveryLog, DeliveryRecord{
 			FriendID:   friendID,
-			PacketSize: len(packet),
+			PacketSize: len(friendPacket),
 			Timestamp:  time.Now().UnixNano(),
-			Success:    true,
-			Error:      nil,
+			Success:    err == nil,
+			Error:      err,
 		})
+		if err != nil {
+			failed = append(failed, friendID)
+			continue
+		}
 		successCount++
 	}
 
@@ -157,9 +211,58 @@ func (s *SimulatedPacketDelivery) BroadcastPacket(packet []byte, excludeFriends
 		"total_deliveries": len(s.deliveryLog),
 	}).Info("Broadcast packet simulation completed")
 
+	if len(failed) > 0 {
+		return fmt.Errorf("broadcast failed for %d friends: %v", len(failed), failed)
+	}
 	return nil
 }
 
+// SetPreDeliveryHook implements interfaces.PacketHookable. The hook also
+// runs for each friend of a broadcast, while the simulation is locked.
+func (s *SimulatedPacketDelivery) SetPreDeliveryHook(hook interfaces.PacketHook) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.preHook = hook
+}
+
+// SetPostReceiveHook implements interfaces.PacketHookable.
+func (s *SimulatedPacketDelivery) SetPostReceiveHook(hook interfaces.PacketHook) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.postHook = hook
+}
+
+// ProcessReceivedPacket implements interfaces.PacketHookable.
+func (s *SimulatedPacketDelivery) ProcessReceivedPacket(friendID uint32, packet []byte) ([]byte, error) {
+	s.mu.RLock()
+	hook := s.postHook
+	s.mu.RUnlock()
+	return s.runHook(hook, friendID, packet)
+}
+
+// getPreDeliveryHook returns the pre-delivery hook under the read lock.
+func (s *SimulatedPacketDelivery) getPreDeliveryHook() interfaces.PacketHook {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.preHook
+}
+
+// runHook applies hook to packet, wrapping errors other than
+// ErrDropPacket. A nil hook passes the packet through.
+func (s *SimulatedPacketDelivery) runHook(hook interfaces.PacketHook, friendID uint32, packet []byte) ([]byte, error) {
+	if hook == nil {
+		return packet, nil
+	}
+	result, err := hook(friendID, packet)
+	if errors.Is(err, interfaces.ErrDropPacket) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("packet hook rejected packet for friend %d: %w", friendID, err)
+	}
+	return result, nil
+}
+
 // SetNetworkTransport implements IPacketDelivery.SetNetworkTransport (no-op for simulation)
 func (s *SimulatedPacketDelivery) SetNetworkTransport(transport interfaces.INetworkTransport) error {
 	logrus.Warn("SIMULATION FUNCTION - NOT A REAL OPERATION")
@@ -251,7 +354,8 @@ func (s *SimulatedPacketDelivery) GetDeliveryLog() []DeliveryRecord {
 	return log
 }
 
-// ClearDeliveryLog removes all entries from the delivery log.
+// ClearDeliveryLog removes all entries from the delivery log and discards
+// packets held back for reordering.
 // Call this between test cases to reset the simulation state.
 // Safe for concurrent use.
 func (s *SimulatedPacketDelivery) ClearDeliveryLog() {
@@ -264,6 +368,7 @@ func (s *SimulatedPacketDelivery) ClearDeliveryLog() {
 	defer s.mu.Unlock()
 
 	s.deliveryLog = make([]DeliveryRecord, 0)
+	s.held = nil
 
 	logrus.WithFields(logrus.Fields{
 		"function": "SimulatedPacketDelivery.ClearDeliveryLog",
@@ -319,8 +424,8 @@ func (s *SimulatedPacketDelivery) GetStats() map[string]interface{} {
 // This method provides structured access to delivery statistics without
 // the type assertion requirements of GetStats().
 //
-// Note: AverageLatencyMs is always 0 for simulation as there is no actual
-// network latency in simulated delivery.
+// AverageLatencyMs is the mean Latency of successful deliveries, which is
+// 0 unless network conditions are simulated.
 //
 // Safe for concurrent use.
 func (s *SimulatedPacketDelivery) GetTypedStats() interfaces.PacketDeliveryStats {
@@ -330,15 +435,22 @@ func (s *SimulatedPacketDelivery) GetTypedStats() interfaces.PacketDeliveryStats
 	var successCount int64
 	var failedCount int64
 	var bytesSent int64
+	var totalLatency time.Duration
 	for _, record := range s.deliveryLog {
 		bytesSent += int64(record.PacketSize)
 		if record.Success {
 			successCount++
+			totalLatency += record.Latency
 		} else {
 			failedCount++
 		}
 	}
 
+	var averageLatencyMs float64
+	if successCount > 0 {
+		averageLatencyMs = float64(totalLatency) / float64(successCount) / float64(time.Millisecond)
+	}
+
 	return interfaces.PacketDeliveryStats{
 		IsSimulation:     true,
 		FriendCount:      len(s.friendMap),
@@ -346,6 +458,6 @@ func (s *SimulatedPacketDelivery) GetTypedStats() interfaces.PacketDeliveryStats
 		PacketsDelivered: successCount,
 		PacketsFailed:    failedCount,
 		BytesSent:        bytesSent,
-		AverageLatencyMs: 0, // Simulation has no network latency
+		AverageLatencyMs: averageLatencyMs,
 	}
 }
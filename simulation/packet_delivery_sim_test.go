@@ -1,6 +1,7 @@
 package simulation
 
 import (
+	"fmt"
 	"sync"
 	"testing"
 	"time"
@@ -607,3 +608,53 @@ func BenchmarkConcurrentDelivery(b *testing.B) {
 		}
 	})
 }
+
+func TestPacketHooks(t *testing.T) {
+	sim := NewSimulatedPacketDelivery(newTestConfig())
+	sim.AddFriend(1, nil)
+	sim.AddFriend(2, nil)
+
+	sim.SetPreDeliveryHook(func(friendID uint32, packet []byte) ([]byte, error) {
+		if friendID == 2 {
+			return nil, interfaces.ErrDropPacket
+		}
+		return append(packet, 0), nil
+	})
+
+	if err := sim.DeliverPacket(1, []byte("abc")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := sim.DeliverPacket(2, []byte("abc")); err != nil {
+		t.Errorf("dropped packet should not report an error: %v", err)
+	}
+	log := sim.GetDeliveryLog()
+	if len(log) != 1 || log[0].FriendID != 1 || log[0].PacketSize != 4 {
+		t.Fatalf("unexpected delivery log: %+v", log)
+	}
+
+	sim.ClearDeliveryLog()
+	if err := sim.BroadcastPacket([]byte("abc"), nil); err != nil {
+		t.Fatalf("unexpected broadcast error: %v", err)
+	}
+	if log := sim.GetDeliveryLog(); len(log) != 1 || log[0].FriendID != 1 {
+		t.Errorf("broadcast should skip dropped friend: %+v", log)
+	}
+
+	sim.SetPreDeliveryHook(func(uint32, []byte) ([]byte, error) {
+		return nil, fmt.Errorf("blocked")
+	})
+	if err := sim.DeliverPacket(1, []byte("abc")); err == nil {
+		t.Error("expected hook error")
+	}
+	if err := sim.BroadcastPacket([]byte("abc"), nil); err == nil {
+		t.Error("expected broadcast error")
+	}
+
+	sim.SetPostReceiveHook(func(_ uint32, packet []byte) ([]byte, error) {
+		return packet[1:], nil
+	})
+	got, err := sim.ProcessReceivedPacket(1, []byte("xyz"))
+	if err != nil || string(got) != "yz" {
+		t.Errorf("got %q, %v", got, err)
+	}
+}
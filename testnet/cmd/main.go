@@ -31,6 +31,7 @@ type CLIConfig struct {
 	retryBackoff         time.Duration
 	logLevel             string
 	logFile              string
+	junitReport          string
 	verbose              bool
 	enableHealthChecks   bool
 	collectMetrics       bool
@@ -42,7 +43,7 @@ type CLIConfig struct {
 // Network flags: -port, -address
 // Timeout flags: -overall-timeout, -bootstrap-timeout, -connection-timeout, -friend-request-timeout, -message-timeout
 // Retry flags: -retry-attempts, -retry-backoff
-// Logging flags: -log-level, -log-file, -verbose
+// Logging flags: -log-level, -log-file, -verbose, -junit-report
 // Feature flags: -health-checks, -metrics
 // Help flag: -help
 func parseCLIFlags() *CLIConfig {
@@ -67,6 +68,7 @@ func parseCLIFlags() *CLIConfig {
 	flag.StringVar(&config.logLevel, "log-level", "INFO", "Log level (DEBUG, INFO, WARN, ERROR)")
 	flag.StringVar(&config.logFile, "log-file", "", "Log file path (default: stdout)")
 	flag.BoolVar(&config.verbose, "verbose", true, "Enable verbose output")
+	flag.StringVar(&config.junitReport, "junit-report", "", "Write a JUnit XML report to this path")
 
 	// Feature flags
 	flag.BoolVar(&config.enableHealthChecks, "health-checks", true, "Enable health checks")
@@ -106,6 +108,9 @@ func printUsage() {
 	fmt.Println()
 	fmt.Printf("  # Run with log file and reduced verbosity\n")
 	fmt.Printf("  %s -log-file test.log -verbose=false\n", os.Args[0])
+	fmt.Println()
+	fmt.Printf("  # Write a JUnit XML report for CI\n")
+	fmt.Printf("  %s -junit-report report.xml\n", os.Args[0])
 }
 
 // validLogLevels contains the allowed log level values.
@@ -202,6 +207,7 @@ func createTestConfig(cliConfig *CLIConfig) *internal.TestConfig {
 		RetryBackoff:         cliConfig.retryBackoff,
 		LogLevel:             cliConfig.logLevel,
 		LogFile:              cliConfig.logFile,
+		JUnitReportPath:      cliConfig.junitReport,
 		VerboseOutput:        cliConfig.verbose,
 		EnableHealthChecks:   cliConfig.enableHealthChecks,
 		CollectMetrics:       cliConfig.collectMetrics,
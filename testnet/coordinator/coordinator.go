@@ -0,0 +1,251 @@
+package coordinator
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/opd-ai/toxcore/testnet/internal"
+	"github.com/sirupsen/logrus"
+)
+
+// Default timeouts for talking to workers.
+const (
+	// DefaultDialTimeout bounds connecting to a worker.
+	DefaultDialTimeout = 10 * time.Second
+	// DefaultResultGrace is added to a test's overall timeout when waiting
+	// for a worker's result, to cover setup and reporting.
+	DefaultResultGrace = 30 * time.Second
+)
+
+// ErrNoWorkers is returned by DispatchTest when no worker is registered.
+var ErrNoWorkers = errors.New("no workers registered")
+
+// TestResults is the unified report of a test dispatched to every worker.
+type TestResults struct {
+	TotalWorkers  int
+	PassedWorkers int
+	FailedWorkers int
+
+	// Test counts summed over all workers
+	TotalTests   int
+	PassedTests  int
+	FailedTests  int
+	SkippedTests int
+
+	// Wall-clock time from dispatch until the last worker reported
+	ExecutionTime time.Duration
+	FinalStatus   internal.TestStatus
+
+	// Per-worker results in registration order
+	Workers []WorkerResult
+}
+
+// Passed reports whether every worker passed.
+func (r *TestResults) Passed() bool {
+	return r.FinalStatus == internal.TestStatusPassed
+}
+
+// Summary returns a human-readable report of the results, one line per
+// worker after an overall line.
+func (r *TestResults) Summary() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s: %d/%d workers passed, %d tests, %d passed, %d failed, %d skipped (execution time: %v)\n",
+		r.FinalStatus, r.PassedWorkers, r.TotalWorkers, r.TotalTests, r.PassedTests, r.FailedTests, r.SkippedTests, r.ExecutionTime)
+	for _, w := range r.Workers {
+		fmt.Fprintf(&b, "  %s: %s (%v)", w.WorkerID, w.Status, w.ExecutionTime)
+		if w.Error != "" {
+			fmt.Fprintf(&b, ": %s", w.Error)
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// Coordinator dispatches test configurations to remote workers and
+// aggregates their results.
+//
+// Workers are contacted over TCP with a line-delimited JSON protocol: the
+// coordinator sends one dispatch message carrying a WorkerConfig and the
+// worker answers on the same connection with one result message.
+type Coordinator struct {
+	mu      sync.Mutex
+	workers []net.Addr
+	logger  *logrus.Entry
+
+	dialTimeout time.Duration
+	resultGrace time.Duration
+}
+
+// NewCoordinator creates a coordinator with no registered workers.
+func NewCoordinator() *Coordinator {
+	return &Coordinator{
+		logger:      logrus.WithField("component", "coordinator"),
+		dialTimeout: DefaultDialTimeout,
+		resultGrace: DefaultResultGrace,
+	}
+}
+
+// SetTimeouts overrides the dial timeout and the grace period added to a
+// test's overall timeout while waiting for results. Non-positive values
+// keep the current setting.
+func (c *Coordinator) SetTimeouts(dial, resultGrace time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if dial > 0 {
+		c.dialTimeout = dial
+	}
+	if resultGrace > 0 {
+		c.resultGrace = resultGrace
+	}
+}
+
+// RegisterWorker adds the worker listening at addr. Registering the same
+// address twice has no effect.
+func (c *Coordinator) RegisterWorker(addr net.Addr) error {
+	if addr == nil {
+		return fmt.Errorf("worker address cannot be nil")
+	}
+	if addr.Network() != "tcp" {
+		return fmt.Errorf("worker address must be TCP, got %s", addr.Network())
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, existing := range c.workers {
+		if existing.String() == addr.String() {
+			return nil
+		}
+	}
+	c.workers = append(c.workers, addr)
+
+	c.logger.WithFields(logrus.Fields{
+		"worker":        addr.String(),
+		"total_workers": len(c.workers),
+	}).Info("Worker registered")
+	return nil
+}
+
+// Workers returns the registered worker addresses in registration order.
+func (c *Coordinator) Workers() []net.Addr {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	workers := make([]net.Addr, len(c.workers))
+	copy(workers, c.workers)
+	return workers
+}
+
+// DispatchTest sends cfg to every registered worker, runs the tests
+// concurrently, and waits for all results. Each worker receives its own
+// WorkerID and WorkerIndex. A worker that cannot be reached or does not
+// report in time is counted as failed; the returned error is non-nil only
+// if the test could not be dispatched at all.
+func (c *Coordinator) DispatchTest(cfg WorkerConfig) (*TestResults, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid worker configuration: %w", err)
+	}
+
+	c.mu.Lock()
+	workers := make([]net.Addr, len(c.workers))
+	copy(workers, c.workers)
+	dialTimeout, resultGrace := c.dialTimeout, c.resultGrace
+	c.mu.Unlock()
+
+	if len(workers) == 0 {
+		return nil, ErrNoWorkers
+	}
+
+	c.logger.WithFields(logrus.Fields{
+		"workers":         len(workers),
+		"overall_timeout": cfg.OverallTimeout,
+	}).Info("Dispatching test to workers")
+
+	start := time.Now()
+	results := make([]WorkerResult, len(workers))
+	var wg sync.WaitGroup
+	for i, addr := range workers {
+		workerCfg := cfg
+		workerCfg.WorkerID = addr.String()
+		workerCfg.WorkerIndex = i
+
+		wg.Add(1)
+		go func(i int, addr net.Addr) {
+			defer wg.Done()
+			result, err := dispatchToWorker(addr, workerCfg, dialTimeout, cfg.OverallTimeout+resultGrace)
+			if err != nil {
+				c.logger.WithFields(logrus.Fields{
+					"worker": addr.String(),
+					"error":  err.Error(),
+				}).Warn("Worker dispatch failed")
+				result = newWorkerResult(addr.String(), nil, err)
+			}
+			results[i] = result
+		}(i, addr)
+	}
+	wg.Wait()
+
+	report := aggregateResults(results, time.Since(start))
+	c.logger.WithFields(logrus.Fields{
+		"status":         report.FinalStatus.String(),
+		"passed_workers": report.PassedWorkers,
+		"failed_workers": report.FailedWorkers,
+		"execution_time": report.ExecutionTime,
+	}).Info("Distributed test completed")
+	return report, nil
+}
+
+// dispatchToWorker sends cfg to the worker at addr and waits for its result.
+func dispatchToWorker(addr net.Addr, cfg WorkerConfig, dialTimeout, resultTimeout time.Duration) (WorkerResult, error) {
+	conn, err := net.DialTimeout(addr.Network(), addr.String(), dialTimeout)
+	if err != nil {
+		return WorkerResult{}, fmt.Errorf("failed to connect to worker: %w", err)
+	}
+	defer conn.Close()
+
+	if err := conn.SetDeadline(time.Now().Add(resultTimeout)); err != nil {
+		return WorkerResult{}, fmt.Errorf("failed to set deadline: %w", err)
+	}
+	if err := writeMessage(conn, &message{Type: MessageTypeDispatch, Config: &cfg}); err != nil {
+		return WorkerResult{}, err
+	}
+
+	msg, err := readMessage(bufio.NewReader(conn), MessageTypeResult)
+	if err != nil {
+		return WorkerResult{}, err
+	}
+	if msg.Result == nil {
+		return WorkerResult{}, errors.New("result message carries no result")
+	}
+	result := *msg.Result
+	result.WorkerID = cfg.WorkerID
+	return result, nil
+}
+
+// aggregateResults combines per-worker results into a unified report. The
+// run passes only if every worker passed.
+func aggregateResults(results []WorkerResult, elapsed time.Duration) *TestResults {
+	report := &TestResults{
+		TotalWorkers:  len(results),
+		ExecutionTime: elapsed,
+		FinalStatus:   internal.TestStatusPassed,
+		Workers:       results,
+	}
+	for i := range results {
+		r := &results[i]
+		report.TotalTests += r.TotalTests
+		report.PassedTests += r.PassedTests
+		report.FailedTests += r.FailedTests
+		report.SkippedTests += r.SkippedTests
+		if r.Passed() {
+			report.PassedWorkers++
+		} else {
+			report.FailedWorkers++
+			report.FinalStatus = internal.TestStatusFailed
+		}
+	}
+	return report
+}
@@ -0,0 +1,161 @@
+package coordinator
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// startWorker serves dispatches with runner on a loopback port.
+func startWorker(t *testing.T, runner TestRunner) net.Addr {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	worker := NewWorker(runner)
+	go worker.Serve(listener)
+	t.Cleanup(func() { worker.Close() })
+	return listener.Addr()
+}
+
+// testWorkerConfig returns a valid configuration with short timeouts.
+func testWorkerConfig() WorkerConfig {
+	cfg := DefaultWorkerConfig()
+	cfg.OverallTimeout = 5 * time.Second
+	return cfg
+}
+
+func TestDispatchTestAggregatesResults(t *testing.T) {
+	var mu sync.Mutex
+	seen := make(map[int]WorkerConfig)
+	runner := func(pass bool) TestRunner {
+		return func(ctx context.Context, cfg WorkerConfig) WorkerResult {
+			mu.Lock()
+			seen[cfg.WorkerIndex] = cfg
+			mu.Unlock()
+			if !pass {
+				return WorkerResult{Status: "FAILED", TotalTests: 1, FailedTests: 1, Error: "message not delivered"}
+			}
+			return WorkerResult{Status: "PASSED", TotalTests: 1, PassedTests: 1, ExecutionTime: time.Second}
+		}
+	}
+
+	c := NewCoordinator()
+	passing := startWorker(t, runner(true))
+	failing := startWorker(t, runner(false))
+	for _, addr := range []net.Addr{passing, failing, passing} {
+		if err := c.RegisterWorker(addr); err != nil {
+			t.Fatalf("RegisterWorker failed: %v", err)
+		}
+	}
+	if len(c.Workers()) != 2 {
+		t.Fatalf("Expected duplicate registration to be ignored, got %d workers", len(c.Workers()))
+	}
+
+	cfg := testWorkerConfig()
+	cfg.RetryAttempts = 7
+	results, err := c.DispatchTest(cfg)
+	if err != nil {
+		t.Fatalf("DispatchTest failed: %v", err)
+	}
+
+	if results.Passed() || results.PassedWorkers != 1 || results.FailedWorkers != 1 {
+		t.Errorf("Expected one passing and one failing worker, got %+v", results)
+	}
+	if results.TotalTests != 2 || results.PassedTests != 1 || results.FailedTests != 1 {
+		t.Errorf("Expected test counts 2/1/1, got %d/%d/%d", results.TotalTests, results.PassedTests, results.FailedTests)
+	}
+	if results.Workers[0].WorkerID != passing.String() || results.Workers[1].WorkerID != failing.String() {
+		t.Errorf("Expected results in registration order, got %s, %s", results.Workers[0].WorkerID, results.Workers[1].WorkerID)
+	}
+	if seen[1].WorkerID != failing.String() || seen[1].RetryAttempts != 7 {
+		t.Errorf("Worker did not receive the dispatched configuration: %+v", seen[1])
+	}
+	if summary := results.Summary(); !strings.Contains(summary, "message not delivered") || !strings.HasPrefix(summary, "FAILED: 1/2 workers passed") {
+		t.Errorf("Unexpected summary:\n%s", summary)
+	}
+}
+
+func TestDispatchTestUnreachableWorker(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	addr := listener.Addr()
+	listener.Close()
+
+	c := NewCoordinator()
+	c.SetTimeouts(time.Second, time.Second)
+	if err := c.RegisterWorker(addr); err != nil {
+		t.Fatalf("RegisterWorker failed: %v", err)
+	}
+	results, err := c.DispatchTest(testWorkerConfig())
+	if err != nil {
+		t.Fatalf("DispatchTest failed: %v", err)
+	}
+	if results.Passed() || results.FailedWorkers != 1 || results.Workers[0].Error == "" {
+		t.Errorf("Unreachable worker should be reported as failed, got %+v", results.Workers[0])
+	}
+}
+
+func TestDispatchTestValidation(t *testing.T) {
+	c := NewCoordinator()
+	if _, err := c.DispatchTest(testWorkerConfig()); !errors.Is(err, ErrNoWorkers) {
+		t.Errorf("Expected ErrNoWorkers, got %v", err)
+	}
+
+	cfg := testWorkerConfig()
+	cfg.BootstrapPort = 0
+	if _, err := c.DispatchTest(cfg); err == nil {
+		t.Error("Expected invalid configuration to be rejected")
+	}
+
+	if err := c.RegisterWorker(nil); err == nil {
+		t.Error("Expected nil address to be rejected")
+	}
+	if err := c.RegisterWorker(&net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 7700}); err == nil {
+		t.Error("Expected non-TCP address to be rejected")
+	}
+}
+
+func TestWorkerRejectsInvalidDispatch(t *testing.T) {
+	ran := false
+	addr := startWorker(t, func(ctx context.Context, cfg WorkerConfig) WorkerResult {
+		ran = true
+		return WorkerResult{Status: "PASSED"}
+	})
+
+	conn, err := net.Dial("tcp", addr.String())
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer conn.Close()
+
+	cfg := testWorkerConfig()
+	cfg.WorkerID = "w1"
+	cfg.OverallTimeout = 0
+	if err := writeMessage(conn, &message{Type: MessageTypeDispatch, Config: &cfg}); err != nil {
+		t.Fatalf("writeMessage failed: %v", err)
+	}
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	msg, err := readMessage(bufio.NewReader(conn), MessageTypeResult)
+	if err != nil {
+		t.Fatalf("readMessage failed: %v", err)
+	}
+	if ran || msg.Result.Passed() || msg.Result.WorkerID != "w1" || !strings.Contains(msg.Result.Error, "overall timeout") {
+		t.Errorf("Invalid configuration should be reported without running, got %+v (ran=%v)", msg.Result, ran)
+	}
+}
+
+func TestReadMessageRejectsWrongVersion(t *testing.T) {
+	r := bufio.NewReader(strings.NewReader(`{"version":99,"type":"result"}` + "\n"))
+	if _, err := readMessage(r, MessageTypeResult); err == nil || !strings.Contains(err.Error(), "protocol version") {
+		t.Errorf("Expected protocol version error, got %v", err)
+	}
+}
@@ -0,0 +1,50 @@
+// Package coordinator distributes the Tox network integration test suite
+// across machines for load testing at scale.
+//
+// The single-process testnet/cmd runs every test client in one process. A
+// Coordinator instead sends the test configuration to any number of remote
+// workers, each running the testnet/worker binary, and aggregates their
+// results into one TestResults report.
+//
+// # Protocol
+//
+// Coordinator and worker speak line-delimited JSON over TCP. For each test
+// the coordinator opens a connection to every worker and sends a single
+// dispatch message carrying a WorkerConfig; the worker runs the test and
+// answers on the same connection with a single result message carrying a
+// WorkerResult. Every message includes the protocol version.
+//
+// Workers are stateless. All configuration comes from the dispatch, and a
+// worker keeps nothing between connections, so workers can be restarted or
+// replaced between dispatches.
+//
+// # Usage
+//
+// Start a worker on each machine:
+//
+//	go run ./testnet/worker -listen :7700
+//
+// Register the workers and dispatch a test:
+//
+//	c := coordinator.NewCoordinator()
+//	for _, host := range []string{"10.0.0.11:7700", "10.0.0.12:7700"} {
+//	    addr, err := net.ResolveTCPAddr("tcp", host)
+//	    if err != nil {
+//	        log.Fatal(err)
+//	    }
+//	    if err := c.RegisterWorker(addr); err != nil {
+//	        log.Fatal(err)
+//	    }
+//	}
+//
+//	results, err := c.DispatchTest(coordinator.DefaultWorkerConfig())
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	fmt.Print(results.Summary())
+//
+// Each worker runs its own local test network, so BootstrapAddress and
+// BootstrapPort refer to the worker's machine. Workers sharing a host need
+// different ports; WorkerIndex in the dispatched configuration identifies
+// each worker for such adjustments in a custom TestRunner.
+package coordinator
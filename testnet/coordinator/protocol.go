@@ -0,0 +1,234 @@
+package coordinator
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/opd-ai/toxcore/testnet/internal"
+)
+
+// ProtocolVersion is the version of the coordinator-worker wire protocol.
+// A worker rejects dispatches carrying a different version.
+const ProtocolVersion = 1
+
+// maxMessageSize bounds a single protocol message to keep a misbehaving
+// peer from exhausting memory.
+const maxMessageSize = 1 << 20
+
+// Message types exchanged between coordinator and worker.
+const (
+	// MessageTypeDispatch carries a WorkerConfig from coordinator to worker.
+	MessageTypeDispatch = "dispatch"
+	// MessageTypeResult carries a WorkerResult from worker to coordinator.
+	MessageTypeResult = "result"
+)
+
+// WorkerConfig is the complete test configuration sent to a worker. Workers
+// keep no state of their own, so everything a test run needs is here.
+type WorkerConfig struct {
+	// Set by the coordinator: WorkerID is the address the worker was
+	// registered with and WorkerIndex its position in registration order.
+	WorkerID    string `json:"worker_id"`
+	WorkerIndex int    `json:"worker_index"`
+
+	// Network configuration for the worker's local test network
+	BootstrapAddress string `json:"bootstrap_address"`
+	BootstrapPort    uint16 `json:"bootstrap_port"`
+
+	// Timeout configuration
+	OverallTimeout       time.Duration `json:"overall_timeout"`
+	BootstrapTimeout     time.Duration `json:"bootstrap_timeout"`
+	ConnectionTimeout    time.Duration `json:"connection_timeout"`
+	FriendRequestTimeout time.Duration `json:"friend_request_timeout"`
+	MessageTimeout       time.Duration `json:"message_timeout"`
+
+	// Retry configuration
+	RetryAttempts int           `json:"retry_attempts"`
+	RetryBackoff  time.Duration `json:"retry_backoff"`
+
+	// Logging and feature configuration
+	LogLevel           string `json:"log_level"`
+	VerboseOutput      bool   `json:"verbose_output"`
+	EnableHealthChecks bool   `json:"enable_health_checks"`
+	CollectMetrics     bool   `json:"collect_metrics"`
+}
+
+// DefaultWorkerConfig returns a WorkerConfig with the same defaults as the
+// single-process test suite.
+func DefaultWorkerConfig() WorkerConfig {
+	d := internal.DefaultTestConfig()
+	return WorkerConfig{
+		BootstrapAddress:     d.BootstrapAddress,
+		BootstrapPort:        d.BootstrapPort,
+		OverallTimeout:       d.OverallTimeout,
+		BootstrapTimeout:     d.BootstrapTimeout,
+		ConnectionTimeout:    d.ConnectionTimeout,
+		FriendRequestTimeout: d.FriendRequestTimeout,
+		MessageTimeout:       d.MessageTimeout,
+		RetryAttempts:        d.RetryAttempts,
+		RetryBackoff:         d.RetryBackoff,
+		LogLevel:             d.LogLevel,
+		VerboseOutput:        d.VerboseOutput,
+		EnableHealthChecks:   d.EnableHealthChecks,
+		CollectMetrics:       d.CollectMetrics,
+	}
+}
+
+// Validate checks that the configuration can be run by a worker.
+func (c WorkerConfig) Validate() error {
+	if c.BootstrapPort == 0 {
+		return fmt.Errorf("bootstrap port cannot be zero")
+	}
+	if c.BootstrapAddress == "" {
+		return fmt.Errorf("bootstrap address cannot be empty")
+	}
+	if c.OverallTimeout <= 0 {
+		return fmt.Errorf("overall timeout must be positive")
+	}
+	if c.ConnectionTimeout <= 0 {
+		return fmt.Errorf("connection timeout must be positive")
+	}
+	if c.RetryAttempts < 0 {
+		return fmt.Errorf("retry attempts cannot be negative")
+	}
+	if c.RetryBackoff <= 0 {
+		return fmt.Errorf("retry backoff must be positive")
+	}
+	return nil
+}
+
+// toTestConfig converts the configuration for the local test orchestrator.
+// Worker logs go to the worker's own output, never to a file.
+func (c WorkerConfig) toTestConfig() *internal.TestConfig {
+	return &internal.TestConfig{
+		BootstrapPort:        c.BootstrapPort,
+		BootstrapAddress:     c.BootstrapAddress,
+		OverallTimeout:       c.OverallTimeout,
+		BootstrapTimeout:     c.BootstrapTimeout,
+		ConnectionTimeout:    c.ConnectionTimeout,
+		FriendRequestTimeout: c.FriendRequestTimeout,
+		MessageTimeout:       c.MessageTimeout,
+		RetryAttempts:        c.RetryAttempts,
+		RetryBackoff:         c.RetryBackoff,
+		LogLevel:             c.LogLevel,
+		VerboseOutput:        c.VerboseOutput,
+		EnableHealthChecks:   c.EnableHealthChecks,
+		CollectMetrics:       c.CollectMetrics,
+	}
+}
+
+// StepResult is the outcome of one test step on a worker.
+type StepResult struct {
+	Name          string        `json:"name"`
+	Status        string        `json:"status"`
+	ExecutionTime time.Duration `json:"execution_time"`
+	Error         string        `json:"error,omitempty"`
+}
+
+// WorkerResult is the outcome of a test run reported by one worker.
+type WorkerResult struct {
+	WorkerID      string        `json:"worker_id"`
+	Status        string        `json:"status"`
+	TotalTests    int           `json:"total_tests"`
+	PassedTests   int           `json:"passed_tests"`
+	FailedTests   int           `json:"failed_tests"`
+	SkippedTests  int           `json:"skipped_tests"`
+	ExecutionTime time.Duration `json:"execution_time"`
+	Steps         []StepResult  `json:"steps,omitempty"`
+	Error         string        `json:"error,omitempty"`
+}
+
+// Passed reports whether the worker's run passed.
+func (r *WorkerResult) Passed() bool {
+	return r.Status == internal.TestStatusPassed.String()
+}
+
+// newWorkerResult converts orchestrator results into a WorkerResult.
+func newWorkerResult(workerID string, results *internal.TestResults, err error) WorkerResult {
+	result := WorkerResult{WorkerID: workerID, Status: internal.TestStatusFailed.String()}
+	if results != nil {
+		result.Status = results.FinalStatus.String()
+		result.TotalTests = results.TotalTests
+		result.PassedTests = results.PassedTests
+		result.FailedTests = results.FailedTests
+		result.SkippedTests = results.SkippedTests
+		result.ExecutionTime = results.ExecutionTime
+		result.Error = results.ErrorDetails
+		for _, step := range results.TestSteps {
+			result.Steps = append(result.Steps, StepResult{
+				Name:          step.StepName,
+				Status:        step.Status.String(),
+				ExecutionTime: step.ExecutionTime,
+				Error:         step.ErrorMessage,
+			})
+		}
+	}
+	if err != nil && result.Error == "" {
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// message is the envelope for every protocol message. Messages are sent as
+// one JSON object per line.
+type message struct {
+	Version int           `json:"version"`
+	Type    string        `json:"type"`
+	Config  *WorkerConfig `json:"config,omitempty"`
+	Result  *WorkerResult `json:"result,omitempty"`
+}
+
+// writeMessage sends msg as a single JSON line.
+func writeMessage(w io.Writer, msg *message) error {
+	msg.Version = ProtocolVersion
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s message: %w", msg.Type, err)
+	}
+	if _, err := w.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to send %s message: %w", msg.Type, err)
+	}
+	return nil
+}
+
+// readMessage reads one JSON line and checks it has the expected type and
+// protocol version.
+func readMessage(r *bufio.Reader, wantType string) (*message, error) {
+	line, err := readLine(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var msg message
+	if err := json.Unmarshal(line, &msg); err != nil {
+		return nil, fmt.Errorf("failed to decode message: %w", err)
+	}
+	if msg.Version != ProtocolVersion {
+		return nil, fmt.Errorf("unsupported protocol version %d (want %d)", msg.Version, ProtocolVersion)
+	}
+	if msg.Type != wantType {
+		return nil, fmt.Errorf("unexpected message type %q (want %q)", msg.Type, wantType)
+	}
+	return &msg, nil
+}
+
+// readLine reads up to a newline, failing once maxMessageSize is exceeded.
+func readLine(r *bufio.Reader) ([]byte, error) {
+	var line []byte
+	for {
+		chunk, isPrefix, err := r.ReadLine()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read message: %w", err)
+		}
+		line = append(line, chunk...)
+		if len(line) > maxMessageSize {
+			return nil, fmt.Errorf("message exceeds %d bytes", maxMessageSize)
+		}
+		if !isPrefix {
+			return line, nil
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package coordinator
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/opd-ai/toxcore/testnet/internal"
+	"github.com/sirupsen/logrus"
+)
+
+// TestRunner runs one dispatched test and returns its result. Run is the
+// runner used by workers by default.
+type TestRunner func(ctx context.Context, cfg WorkerConfig) WorkerResult
+
+// Run executes the integration test suite locally with cfg, exactly as the
+// single-process testnet/cmd does.
+func Run(ctx context.Context, cfg WorkerConfig) WorkerResult {
+	orchestrator, err := internal.NewTestOrchestrator(cfg.toTestConfig())
+	if err != nil {
+		return newWorkerResult(cfg.WorkerID, nil, fmt.Errorf("orchestrator creation failed: %w", err))
+	}
+	defer func() {
+		if cleanupErr := orchestrator.Cleanup(); cleanupErr != nil {
+			logrus.WithError(cleanupErr).Warn("Orchestrator cleanup warning")
+		}
+	}()
+
+	if err := orchestrator.ValidateConfiguration(); err != nil {
+		return newWorkerResult(cfg.WorkerID, nil, fmt.Errorf("configuration validation failed: %w", err))
+	}
+	results, err := orchestrator.RunTests(ctx)
+	return newWorkerResult(cfg.WorkerID, results, err)
+}
+
+// Worker accepts test dispatches from a Coordinator and reports the results.
+//
+// A worker is stateless: each connection carries one dispatch with the full
+// test configuration, and the result is written back on the same connection
+// before it is closed. Several dispatches may run concurrently.
+type Worker struct {
+	runner TestRunner
+	logger *logrus.Entry
+
+	mu       sync.Mutex
+	listener net.Listener
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+}
+
+// NewWorker creates a worker that runs dispatched tests with runner.
+// A nil runner selects Run.
+func NewWorker(runner TestRunner) *Worker {
+	if runner == nil {
+		runner = Run
+	}
+	return &Worker{
+		runner: runner,
+		logger: logrus.WithField("component", "worker"),
+	}
+}
+
+// ListenAndServe listens on the TCP address addr and serves dispatches
+// until Close is called.
+func (w *Worker) ListenAndServe(addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+	return w.Serve(listener)
+}
+
+// Serve accepts dispatches on listener until Close is called. It always
+// returns a non-nil error; after Close the error is net.ErrClosed.
+func (w *Worker) Serve(listener net.Listener) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	w.mu.Lock()
+	if w.listener != nil {
+		w.mu.Unlock()
+		cancel()
+		return errors.New("worker is already serving")
+	}
+	w.listener = listener
+	w.cancel = cancel
+	w.mu.Unlock()
+
+	w.logger.WithField("address", listener.Addr().String()).Info("Worker accepting dispatches")
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return net.ErrClosed
+			}
+			return fmt.Errorf("accept failed: %w", err)
+		}
+
+		w.wg.Add(1)
+		go w.handleConn(ctx, conn)
+	}
+}
+
+// Addr returns the address the worker is listening on, or nil if it is not
+// serving.
+func (w *Worker) Addr() net.Addr {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.listener == nil {
+		return nil
+	}
+	return w.listener.Addr()
+}
+
+// Close stops accepting dispatches, cancels running tests, and waits for
+// their connections to finish.
+func (w *Worker) Close() error {
+	w.mu.Lock()
+	listener, cancel := w.listener, w.cancel
+	w.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+	}
+	var err error
+	if listener != nil {
+		err = listener.Close()
+	}
+	w.wg.Wait()
+	return err
+}
+
+// handleConn reads one dispatch, runs it, and writes the result back.
+func (w *Worker) handleConn(ctx context.Context, conn net.Conn) {
+	defer w.wg.Done()
+	defer conn.Close()
+
+	logger := w.logger.WithField("coordinator", conn.RemoteAddr().String())
+
+	// The dispatch must arrive promptly; the run itself is bounded by the
+	// configuration's overall timeout.
+	_ = conn.SetReadDeadline(time.Now().Add(dispatchReadTimeout))
+	msg, err := readMessage(bufio.NewReader(conn), MessageTypeDispatch)
+	if err == nil && msg.Config == nil {
+		err = errors.New("dispatch carries no configuration")
+	}
+	if err != nil {
+		logger.WithError(err).Warn("Invalid dispatch")
+		return
+	}
+	_ = conn.SetReadDeadline(time.Time{})
+	cfg := *msg.Config
+
+	var result WorkerResult
+	if err := cfg.Validate(); err != nil {
+		result = newWorkerResult(cfg.WorkerID, nil, fmt.Errorf("invalid configuration: %w", err))
+	} else {
+		logger.WithFields(logrus.Fields{
+			"worker_id":       cfg.WorkerID,
+			"overall_timeout": cfg.OverallTimeout,
+		}).Info("Running dispatched test")
+
+		runCtx, cancel := context.WithTimeout(ctx, cfg.OverallTimeout)
+		result = w.runner(runCtx, cfg)
+		cancel()
+	}
+	result.WorkerID = cfg.WorkerID
+
+	if err := writeMessage(conn, &message{Type: MessageTypeResult, Result: &result}); err != nil {
+		logger.WithError(err).Warn("Failed to report result")
+		return
+	}
+	logger.WithFields(logrus.Fields{
+		"worker_id": cfg.WorkerID,
+		"status":    result.Status,
+	}).Info("Reported test result")
+}
+
+// dispatchReadTimeout bounds how long a worker waits for the dispatch after
+// a coordinator connects.
+const dispatchReadTimeout = 30 * time.Second
@@ -25,6 +25,7 @@ type TestClient struct {
 	friendRequestCh chan FriendRequest
 	messageCh       chan Message
 	connectionCh    chan ConnectionEvent
+	friendStatusCh  chan FriendConnectionEvent
 }
 
 // FriendConnection represents a friend relationship state.
@@ -78,6 +79,14 @@ type ConnectionEvent struct {
 	Timestamp time.Time
 }
 
+// FriendConnectionEvent represents a friend's connection status change
+// reported by OnFriendConnectionStatus.
+type FriendConnectionEvent struct {
+	FriendID  uint32
+	Status    toxcore.ConnectionStatus
+	Timestamp time.Time
+}
+
 // ClientMetrics tracks client performance and activity during tests.
 // It provides counters for validating expected message/request flows:
 //   - StartTime: When the client was initialized
@@ -192,6 +201,7 @@ func NewTestClient(config *ClientConfig) (*TestClient, error) {
 		friendRequestCh: make(chan FriendRequest, 10),
 		messageCh:       make(chan Message, 100),
 		connectionCh:    make(chan ConnectionEvent, 10),
+		friendStatusCh:  make(chan FriendConnectionEvent, 10),
 	}
 	client.metrics.StartTime = client.getTimeProvider().Now()
 
@@ -205,6 +215,7 @@ func NewTestClient(config *ClientConfig) (*TestClient, error) {
 func (tc *TestClient) setupCallbacks() {
 	tc.setupFriendRequestCallback()
 	tc.setupFriendMessageCallback()
+	tc.setupFriendConnectionStatusCallback()
 }
 
 // setupFriendRequestCallback registers the friend request handler.
@@ -252,6 +263,36 @@ func (tc *TestClient) setupFriendMessageCallback() {
 	})
 }
 
+// setupFriendConnectionStatusCallback registers the friend connection status handler.
+func (tc *TestClient) setupFriendConnectionStatusCallback() {
+	tc.tox.OnFriendConnectionStatus(func(friendID uint32, status toxcore.ConnectionStatus) {
+		tc.logger.WithFields(logrus.Fields{
+			"friend_id":         friendID,
+			"connection_status": status,
+		}).Info("Friend connection status changed")
+
+		tc.mu.Lock()
+		if friend, exists := tc.friends[friendID]; exists {
+			friend.Status = FriendStatusOffline
+			if status != toxcore.ConnectionNone {
+				friend.Status = FriendStatusOnline
+			}
+			friend.LastSeen = tc.getTimeProvider().Now()
+		}
+		tc.mu.Unlock()
+
+		select {
+		case tc.friendStatusCh <- FriendConnectionEvent{
+			FriendID:  friendID,
+			Status:    status,
+			Timestamp: tc.getTimeProvider().Now(),
+		}:
+		default:
+			tc.logger.Warn("Friend status channel full, dropping event")
+		}
+	})
+}
+
 // updateMetricsForMessage updates metrics counters when receiving a friend message.
 func (tc *TestClient) updateMetricsForMessage(friendID uint32) {
 	tc.metrics.mu.Lock()
@@ -457,6 +498,25 @@ func (tc *TestClient) WaitForConnection(timeout time.Duration) error {
 	}
 }
 
+// WaitForFriendConnectionStatus waits for OnFriendConnectionStatus to report
+// the friend as online (any status other than ConnectionNone) or offline.
+// Events for other friends or states are discarded.
+func (tc *TestClient) WaitForFriendConnectionStatus(friendID uint32, online bool, timeout time.Duration) (*FriendConnectionEvent, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("client %s: timeout waiting for friend %d (online=%t) after %v", tc.name, friendID, online, timeout)
+		case event := <-tc.friendStatusCh:
+			if event.FriendID == friendID && (event.Status != toxcore.ConnectionNone) == online {
+				return &event, nil
+			}
+		}
+	}
+}
+
 // WaitForFriendRequest waits for a friend request to arrive.
 func (tc *TestClient) WaitForFriendRequest(timeout time.Duration) (*FriendRequest, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), timeout)
@@ -483,6 +543,24 @@ func (tc *TestClient) WaitForMessage(timeout time.Duration) (*Message, error) {
 	}
 }
 
+// SetFriendConnectionStatus updates a friend's connection status, firing
+// OnFriendConnectionStatus when it changes.
+func (tc *TestClient) SetFriendConnectionStatus(friendID uint32, status toxcore.ConnectionStatus) error {
+	if err := tc.tox.SetFriendConnectionStatus(friendID, status); err != nil {
+		return fmt.Errorf("client %s: %w", tc.name, err)
+	}
+	return nil
+}
+
+// InstallPartitioner routes the client's packet delivery through the
+// partitioner so its packets are dropped while the network is partitioned.
+func (tc *TestClient) InstallPartitioner(np *NetworkPartitioner) error {
+	if err := tc.tox.SetPacketDelivery(np.Wrap(tc.tox.GetPacketDelivery())); err != nil {
+		return fmt.Errorf("client %s: failed to install partitioner: %w", tc.name, err)
+	}
+	return nil
+}
+
 // GetName returns the client name.
 func (tc *TestClient) GetName() string {
 	return tc.name
@@ -38,6 +38,12 @@
 //
 //	results, err := orchestrator.ExecuteTest(ctx)
 //
+// Each protocol step (bootstrap, clientSetup, friendRequest,
+// messageDelivery and, when enabled, partitionRecovery) is recorded as a
+// separate TestStepResult. WriteJUnitReport serializes them as a surefire
+// JUnit XML testsuite for CI systems; set TestConfig.JUnitReportPath to
+// write the report automatically when RunTests finishes.
+//
 // # Bootstrap Server
 //
 // BootstrapServer creates a local DHT bootstrap node that test clients connect to
@@ -108,6 +114,20 @@
 //	    log.Fatal(err)
 //	}
 //
+// # Network Partitions
+//
+// Partition testing is opt-in. RunWithPartition adds a step after the message
+// exchange that partitions the network n times:
+//
+//	config := internal.DefaultProtocolConfig().RunWithPartition(2, 5*time.Second)
+//
+// PartitionNetwork can also be called directly once the clients are friends.
+// A NetworkPartitioner wrapping both clients' packet delivery drops every
+// packet for the given duration while each client queues a message for the
+// other. After healing, the suite waits for OnFriendConnectionStatus to
+// report the reconnection on both sides and verifies the queued messages
+// arrive. Partition start and end are logged with timestamps.
+//
 // # Configuration
 //
 // Each component has a default configuration that can be customized:
@@ -0,0 +1,130 @@
+package internal
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// junitTimestampFormat is the ISO 8601 form surefire uses for the suite
+// timestamp.
+const junitTimestampFormat = "2006-01-02T15:04:05"
+
+// junitTestSuite is the surefire <testsuite> element.
+type junitTestSuite struct {
+	XMLName   xml.Name        `xml:"testsuite"`
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	Time      string          `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+// junitTestCase is a surefire <testcase> element.
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *struct{}     `xml:"skipped,omitempty"`
+}
+
+// junitFailure is a surefire <failure> element.
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Body    string `xml:",chardata"`
+}
+
+// WriteJUnitReport writes the test results to w as JUnit XML in the
+// surefire format: one testsuite with a testcase per protocol step, timed
+// with the orchestrator's TimeProvider. Failed and timed-out steps carry a
+// failure element with the step's error message. If the run failed before
+// any step was recorded, a single failed testcase reports the error.
+func (to *TestOrchestrator) WriteJUnitReport(w io.Writer) error {
+	suite := to.buildJUnitSuite()
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return fmt.Errorf("failed to write JUnit report: %w", err)
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(suite); err != nil {
+		return fmt.Errorf("failed to write JUnit report: %w", err)
+	}
+	if _, err := io.WriteString(w, "\n"); err != nil {
+		return fmt.Errorf("failed to write JUnit report: %w", err)
+	}
+	return nil
+}
+
+// writeJUnitReportFile writes the JUnit report to path, replacing any
+// existing file.
+func (to *TestOrchestrator) writeJUnitReportFile(path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create JUnit report: %w", err)
+	}
+	if err := to.WriteJUnitReport(file); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to close JUnit report: %w", err)
+	}
+
+	to.logger.WithField("path", path).Info("📝 JUnit report written")
+	return nil
+}
+
+// buildJUnitSuite converts the test results to the surefire suite element.
+func (to *TestOrchestrator) buildJUnitSuite() junitTestSuite {
+	suite := junitTestSuite{
+		Name:      "toxcore.testnet",
+		Time:      junitSeconds(to.results.ExecutionTime),
+		Timestamp: to.startTime.Format(junitTimestampFormat),
+	}
+
+	steps := to.results.TestSteps
+	if len(steps) == 0 && to.results.ErrorDetails != "" {
+		steps = []TestStepResult{{
+			StepName:      "protocolTest",
+			Status:        TestStatusFailed,
+			ExecutionTime: to.results.ExecutionTime,
+			ErrorMessage:  to.results.ErrorDetails,
+		}}
+	}
+
+	for _, step := range steps {
+		testCase := junitTestCase{
+			Name:      step.StepName,
+			ClassName: "testnet.protocol",
+			Time:      junitSeconds(step.ExecutionTime),
+		}
+		switch step.Status {
+		case TestStatusFailed, TestStatusTimeout:
+			testCase.Failure = &junitFailure{
+				Message: step.ErrorMessage,
+				Type:    step.Status.String(),
+				Body:    step.ErrorMessage,
+			}
+			suite.Failures++
+		case TestStatusSkipped:
+			testCase.Skipped = &struct{}{}
+			suite.Skipped++
+		}
+		suite.TestCases = append(suite.TestCases, testCase)
+	}
+	suite.Tests = len(suite.TestCases)
+	return suite
+}
+
+// junitSeconds formats a duration as the decimal seconds JUnit expects.
+func junitSeconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
@@ -0,0 +1,143 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// newJUnitTestOrchestrator returns an orchestrator with a mock clock and
+// logging discarded.
+func newJUnitTestOrchestrator(t *testing.T) (*TestOrchestrator, *MockTimeProvider) {
+	t.Helper()
+	logrus.SetOutput(io.Discard)
+	t.Cleanup(func() { logrus.SetOutput(os.Stderr) })
+
+	orchestrator, err := NewTestOrchestrator(nil)
+	if err != nil {
+		t.Fatalf("NewTestOrchestrator failed: %v", err)
+	}
+	t.Cleanup(func() { orchestrator.Cleanup() })
+
+	mockTime := NewMockTimeProvider(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+	orchestrator.SetTimeProvider(mockTime)
+	orchestrator.startTime = mockTime.Now()
+	return orchestrator, mockTime
+}
+
+// TestWriteJUnitReport tests the surefire structure of the report.
+func TestWriteJUnitReport(t *testing.T) {
+	orchestrator, mockTime := newJUnitTestOrchestrator(t)
+
+	orchestrator.executeWithStepTracking("bootstrap", func() error {
+		mockTime.Advance(1500 * time.Millisecond)
+		return nil
+	})
+	orchestrator.executeWithStepTracking("friendRequest", func() error {
+		mockTime.Advance(250 * time.Millisecond)
+		return errors.New("timeout waiting for friend request")
+	})
+	orchestrator.results.ExecutionTime = 1750 * time.Millisecond
+
+	var buf bytes.Buffer
+	if err := orchestrator.WriteJUnitReport(&buf); err != nil {
+		t.Fatalf("WriteJUnitReport failed: %v", err)
+	}
+	if !strings.HasPrefix(buf.String(), xml.Header) {
+		t.Error("report should start with the XML header")
+	}
+
+	var suite junitTestSuite
+	if err := xml.Unmarshal(buf.Bytes(), &suite); err != nil {
+		t.Fatalf("report is not valid XML: %v", err)
+	}
+	if suite.Tests != 2 || suite.Failures != 1 || suite.Errors != 0 {
+		t.Errorf("unexpected counts: tests=%d failures=%d errors=%d", suite.Tests, suite.Failures, suite.Errors)
+	}
+	if suite.Time != "1.750" || suite.Timestamp != "2025-01-01T12:00:00" {
+		t.Errorf("unexpected suite timing: time=%s timestamp=%s", suite.Time, suite.Timestamp)
+	}
+	if len(suite.TestCases) != 2 {
+		t.Fatalf("expected 2 testcases, got %d", len(suite.TestCases))
+	}
+
+	passed, failed := suite.TestCases[0], suite.TestCases[1]
+	if passed.Name != "bootstrap" || passed.Time != "1.500" || passed.Failure != nil {
+		t.Errorf("unexpected passing testcase: %+v", passed)
+	}
+	if failed.Name != "friendRequest" || failed.Time != "0.250" || failed.Failure == nil {
+		t.Fatalf("unexpected failing testcase: %+v", failed)
+	}
+	if failed.Failure.Message != "timeout waiting for friend request" {
+		t.Errorf("unexpected failure message %q", failed.Failure.Message)
+	}
+}
+
+// TestWriteJUnitReportWithoutSteps tests that a run failing before any step
+// still reports a failed testcase.
+func TestWriteJUnitReportWithoutSteps(t *testing.T) {
+	orchestrator, _ := newJUnitTestOrchestrator(t)
+	orchestrator.results.ErrorDetails = "context canceled"
+
+	var buf bytes.Buffer
+	if err := orchestrator.WriteJUnitReport(&buf); err != nil {
+		t.Fatalf("WriteJUnitReport failed: %v", err)
+	}
+	var suite junitTestSuite
+	if err := xml.Unmarshal(buf.Bytes(), &suite); err != nil {
+		t.Fatalf("report is not valid XML: %v", err)
+	}
+	if suite.Tests != 1 || suite.Failures != 1 || suite.TestCases[0].Failure.Message != "context canceled" {
+		t.Errorf("unexpected report: %+v", suite)
+	}
+}
+
+// TestWriteJUnitReportFile tests writing the report to JUnitReportPath.
+func TestWriteJUnitReportFile(t *testing.T) {
+	orchestrator, _ := newJUnitTestOrchestrator(t)
+	orchestrator.executeWithStepTracking("bootstrap", func() error { return nil })
+
+	path := filepath.Join(t.TempDir(), "report.xml")
+	if err := orchestrator.writeJUnitReportFile(path); err != nil {
+		t.Fatalf("writeJUnitReportFile failed: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read report: %v", err)
+	}
+	if !strings.Contains(string(data), `<testcase name="bootstrap" classname="testnet.protocol"`) {
+		t.Errorf("report missing testcase:\n%s", data)
+	}
+
+	if err := orchestrator.writeJUnitReportFile(filepath.Join(t.TempDir(), "missing", "report.xml")); err == nil {
+		t.Error("expected error for an unwritable path")
+	}
+}
+
+// TestProtocolSuiteStepRunner tests that ExecuteTest reports steps through
+// the configured runner.
+func TestProtocolSuiteStepRunner(t *testing.T) {
+	suite := NewProtocolTestSuite(nil)
+	var steps []string
+	stepErr := errors.New("stop")
+	suite.SetStepRunner(func(stepName string, operation func() error) error {
+		steps = append(steps, stepName)
+		return stepErr
+	})
+
+	if err := suite.ExecuteTest(context.Background()); !errors.Is(err, stepErr) {
+		t.Errorf("expected step error, got %v", err)
+	}
+	if len(steps) != 1 || steps[0] != "bootstrap" {
+		t.Errorf("expected only the bootstrap step, got %v", steps)
+	}
+}
@@ -46,6 +46,9 @@ type TestConfig struct {
 	// Test configuration
 	EnableHealthChecks bool
 	CollectMetrics     bool
+
+	// JUnitReportPath, if set, is where RunTests writes a JUnit XML report.
+	JUnitReportPath string
 }
 
 // TestResults holds the outcomes of test execution.
@@ -215,6 +218,15 @@ func (to *TestOrchestrator) RunTests(ctx context.Context) (*TestResults, error)
 	// Generate final report
 	to.generateFinalReport()
 
+	if to.config.JUnitReportPath != "" {
+		if reportErr := to.writeJUnitReportFile(to.config.JUnitReportPath); reportErr != nil {
+			to.logger.WithError(reportErr).Error("❌ Failed to write JUnit report")
+			if err == nil {
+				err = reportErr
+			}
+		}
+	}
+
 	return to.results, err
 }
 
@@ -238,10 +250,9 @@ func (to *TestOrchestrator) executeTestWorkflow(ctx context.Context) error {
 		}
 	}()
 
-	// Execute the test with step tracking
-	return to.executeWithStepTracking("Complete Protocol Test", func() error {
-		return protocolSuite.ExecuteTest(ctx)
-	})
+	// Execute the test, tracking each protocol step separately
+	protocolSuite.SetStepRunner(to.executeWithStepTracking)
+	return protocolSuite.ExecuteTest(ctx)
 }
 
 // executeWithStepTracking executes a test step with result tracking.
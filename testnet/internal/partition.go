@@ -0,0 +1,142 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/opd-ai/toxcore/interfaces"
+	"github.com/sirupsen/logrus"
+)
+
+// ErrNetworkPartitioned is returned for packets dropped by a NetworkPartitioner.
+var ErrNetworkPartitioned = errors.New("network partitioned")
+
+// NetworkPartitioner simulates a network partition between test clients.
+// It wraps each client's packet delivery so that, while partitioned, every
+// packet addressed to a friend is dropped. Partition and Heal log the
+// transitions with timestamps from the configured TimeProvider.
+type NetworkPartitioner struct {
+	mu             sync.RWMutex
+	partitioned    bool
+	partitionStart time.Time
+	droppedPackets int64
+	logger         *logrus.Entry
+	timeProvider   TimeProvider
+}
+
+// NewNetworkPartitioner creates a partitioner that starts healed.
+func NewNetworkPartitioner(logger *logrus.Entry) *NetworkPartitioner {
+	if logger == nil {
+		logger = logrus.WithField("component", "partition")
+	}
+	return &NetworkPartitioner{
+		logger:       logger,
+		timeProvider: NewDefaultTimeProvider(),
+	}
+}
+
+// Partition starts dropping packets. It returns an error if the network is
+// already partitioned.
+func (np *NetworkPartitioner) Partition() error {
+	np.mu.Lock()
+	defer np.mu.Unlock()
+
+	if np.partitioned {
+		return fmt.Errorf("network is already partitioned")
+	}
+	np.partitioned = true
+	np.partitionStart = np.getTimeProvider().Now()
+
+	np.logger.WithField("partition_start", np.partitionStart.Format(time.RFC3339Nano)).Info("🔌 Network partition started")
+	return nil
+}
+
+// Heal stops dropping packets and returns how long the partition lasted.
+// It returns an error if the network is not partitioned.
+func (np *NetworkPartitioner) Heal() (time.Duration, error) {
+	np.mu.Lock()
+	defer np.mu.Unlock()
+
+	if !np.partitioned {
+		return 0, fmt.Errorf("network is not partitioned")
+	}
+	np.partitioned = false
+	end := np.getTimeProvider().Now()
+	elapsed := end.Sub(np.partitionStart)
+
+	np.logger.WithFields(logrus.Fields{
+		"partition_end":   end.Format(time.RFC3339Nano),
+		"duration":        elapsed,
+		"dropped_packets": np.droppedPackets,
+	}).Info("🔗 Network partition healed")
+	return elapsed, nil
+}
+
+// IsPartitioned reports whether packets are currently being dropped.
+func (np *NetworkPartitioner) IsPartitioned() bool {
+	np.mu.RLock()
+	defer np.mu.RUnlock()
+	return np.partitioned
+}
+
+// DroppedPackets returns the number of packets dropped across all partitions.
+func (np *NetworkPartitioner) DroppedPackets() int64 {
+	np.mu.RLock()
+	defer np.mu.RUnlock()
+	return np.droppedPackets
+}
+
+// Wrap returns a packet delivery that forwards to inner unless the network
+// is partitioned.
+func (np *NetworkPartitioner) Wrap(inner interfaces.IPacketDelivery) interfaces.IPacketDelivery {
+	return &partitionedDelivery{IPacketDelivery: inner, partitioner: np}
+}
+
+// SetTimeProvider sets a custom TimeProvider for deterministic testing.
+// If nil is passed, the default time provider (system clock) will be used.
+func (np *NetworkPartitioner) SetTimeProvider(tp TimeProvider) {
+	np.mu.Lock()
+	defer np.mu.Unlock()
+	np.timeProvider = tp
+}
+
+// getTimeProvider returns the configured TimeProvider or the default.
+func (np *NetworkPartitioner) getTimeProvider() TimeProvider {
+	return getTimeProvider(np.timeProvider)
+}
+
+// drop counts a packet dropped by the partition and reports whether it
+// should be dropped.
+func (np *NetworkPartitioner) drop() bool {
+	np.mu.Lock()
+	defer np.mu.Unlock()
+	if !np.partitioned {
+		return false
+	}
+	np.droppedPackets++
+	return true
+}
+
+// partitionedDelivery is the packet delivery middleware installed by Wrap.
+type partitionedDelivery struct {
+	interfaces.IPacketDelivery
+	partitioner *NetworkPartitioner
+}
+
+// DeliverPacket drops the packet while partitioned.
+func (pd *partitionedDelivery) DeliverPacket(friendID uint32, packet []byte) error {
+	if pd.partitioner.drop() {
+		return fmt.Errorf("friend %d: %w", friendID, ErrNetworkPartitioned)
+	}
+	return pd.IPacketDelivery.DeliverPacket(friendID, packet)
+}
+
+// BroadcastPacket drops the broadcast while partitioned.
+func (pd *partitionedDelivery) BroadcastPacket(packet []byte, excludeFriends []uint32) error {
+	if pd.partitioner.drop() {
+		return fmt.Errorf("broadcast: %w", ErrNetworkPartitioned)
+	}
+	return pd.IPacketDelivery.BroadcastPacket(packet, excludeFriends)
+}
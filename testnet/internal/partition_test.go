@@ -0,0 +1,120 @@
+package internal
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/opd-ai/toxcore/interfaces"
+	"github.com/opd-ai/toxcore/simulation"
+	"github.com/sirupsen/logrus"
+)
+
+// newPartitionTestDelivery returns a simulated delivery with friend 1 registered.
+func newPartitionTestDelivery(t *testing.T) *simulation.SimulatedPacketDelivery {
+	t.Helper()
+	sim := simulation.NewSimulatedPacketDelivery(&interfaces.PacketDeliveryConfig{
+		UseSimulation:   true,
+		EnableBroadcast: true,
+	})
+	if err := sim.AddFriend(1, nil); err != nil {
+		t.Fatalf("AddFriend failed: %v", err)
+	}
+	return sim
+}
+
+// TestNetworkPartitionerDropsWhilePartitioned tests that packets are dropped
+// only between Partition and Heal.
+func TestNetworkPartitionerDropsWhilePartitioned(t *testing.T) {
+	sim := newPartitionTestDelivery(t)
+	np := NewNetworkPartitioner(logrus.WithField("test", "partition"))
+	delivery := np.Wrap(sim)
+
+	if err := delivery.DeliverPacket(1, []byte("before")); err != nil {
+		t.Fatalf("delivery before partition failed: %v", err)
+	}
+
+	if err := np.Partition(); err != nil {
+		t.Fatalf("Partition failed: %v", err)
+	}
+	if !np.IsPartitioned() {
+		t.Error("IsPartitioned should be true after Partition")
+	}
+	if err := delivery.DeliverPacket(1, []byte("during")); !errors.Is(err, ErrNetworkPartitioned) {
+		t.Errorf("expected ErrNetworkPartitioned, got %v", err)
+	}
+	if err := delivery.BroadcastPacket([]byte("during"), nil); !errors.Is(err, ErrNetworkPartitioned) {
+		t.Errorf("expected ErrNetworkPartitioned for broadcast, got %v", err)
+	}
+
+	if _, err := np.Heal(); err != nil {
+		t.Fatalf("Heal failed: %v", err)
+	}
+	if err := delivery.DeliverPacket(1, []byte("after")); err != nil {
+		t.Fatalf("delivery after healing failed: %v", err)
+	}
+
+	if got := len(sim.GetDeliveryLog()); got != 2 {
+		t.Errorf("expected 2 packets to reach the inner delivery, got %d", got)
+	}
+	if np.DroppedPackets() != 2 {
+		t.Errorf("expected 2 dropped packets, got %d", np.DroppedPackets())
+	}
+	if !delivery.IsSimulation() {
+		t.Error("wrapped delivery should forward IsSimulation")
+	}
+}
+
+// TestNetworkPartitionerTransitions tests partition state errors and the
+// reported partition duration.
+func TestNetworkPartitionerTransitions(t *testing.T) {
+	np := NewNetworkPartitioner(nil)
+	mockTime := NewMockTimeProvider(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	np.SetTimeProvider(mockTime)
+
+	if _, err := np.Heal(); err == nil {
+		t.Error("Heal should fail when not partitioned")
+	}
+	if err := np.Partition(); err != nil {
+		t.Fatalf("Partition failed: %v", err)
+	}
+	if err := np.Partition(); err == nil {
+		t.Error("Partition should fail when already partitioned")
+	}
+
+	mockTime.Advance(3 * time.Second)
+	elapsed, err := np.Heal()
+	if err != nil {
+		t.Fatalf("Heal failed: %v", err)
+	}
+	if elapsed != 3*time.Second {
+		t.Errorf("expected 3s partition, got %v", elapsed)
+	}
+}
+
+// TestRunWithPartition tests that partitioning is opt-in.
+func TestRunWithPartition(t *testing.T) {
+	config := DefaultProtocolConfig()
+	if config.PartitionCount != 0 {
+		t.Errorf("default config should not partition, got %d partitions", config.PartitionCount)
+	}
+
+	if got := config.RunWithPartition(2, 5*time.Second); got != config {
+		t.Error("RunWithPartition should return the same config")
+	}
+	if config.PartitionCount != 2 || config.PartitionDuration != 5*time.Second {
+		t.Errorf("unexpected partition settings: %d, %v", config.PartitionCount, config.PartitionDuration)
+	}
+}
+
+// TestPartitionNetworkValidation tests PartitionNetwork argument and state checks.
+func TestPartitionNetworkValidation(t *testing.T) {
+	suite := NewProtocolTestSuite(nil)
+
+	if err := suite.PartitionNetwork(0); err == nil {
+		t.Error("PartitionNetwork should reject a zero duration")
+	}
+	if err := suite.PartitionNetwork(time.Second); err == nil {
+		t.Error("PartitionNetwork should fail before clients are set up")
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/opd-ai/toxcore"
 	"github.com/sirupsen/logrus"
 )
 
@@ -15,8 +16,17 @@ type ProtocolTestSuite struct {
 	clientB *TestClient
 	logger  *logrus.Entry
 	config  *ProtocolConfig
+
+	partitioner *NetworkPartitioner
+	partitions  int // Partitions run so far, used to label queued messages
+
+	stepRunner StepRunner
 }
 
+// StepRunner runs a named protocol test step. ExecuteTest passes each step
+// through it so callers can record per-step results and timing.
+type StepRunner func(stepName string, operation func() error) error
+
 // ProtocolConfig holds configuration for protocol testing.
 type ProtocolConfig struct {
 	BootstrapTimeout     time.Duration
@@ -26,6 +36,8 @@ type ProtocolConfig struct {
 	RetryAttempts        int
 	RetryBackoff         time.Duration
 	AcceptanceDelay      time.Duration // Delay after friend request acceptance for processing
+	PartitionCount       int           // Partitions to run after message exchange; 0 disables
+	PartitionDuration    time.Duration // How long each partition lasts
 	Logger               *logrus.Entry
 }
 
@@ -43,6 +55,16 @@ func DefaultProtocolConfig() *ProtocolConfig {
 	}
 }
 
+// RunWithPartition enables n network partitions of the given duration after
+// the message exchange step and returns the config for chaining:
+//
+//	config := internal.DefaultProtocolConfig().RunWithPartition(2, 5*time.Second)
+func (c *ProtocolConfig) RunWithPartition(n int, duration time.Duration) *ProtocolConfig {
+	c.PartitionCount = n
+	c.PartitionDuration = duration
+	return c
+}
+
 // NewProtocolTestSuite creates a new protocol test suite.
 func NewProtocolTestSuite(config *ProtocolConfig) *ProtocolTestSuite {
 	if config == nil {
@@ -61,29 +83,50 @@ func (pts *ProtocolTestSuite) ExecuteTest(ctx context.Context) error {
 	pts.logger.Info("=" + fmt.Sprintf("%50s", "="))
 
 	// Step 1: Network Initialization
-	if err := pts.initializeNetwork(ctx); err != nil {
+	if err := pts.runStep("bootstrap", func() error { return pts.initializeNetwork(ctx) }); err != nil {
 		return fmt.Errorf("network initialization failed: %w", err)
 	}
 
 	// Step 2: Client Setup
-	if err := pts.setupClients(ctx); err != nil {
+	if err := pts.runStep("clientSetup", func() error { return pts.setupClients(ctx) }); err != nil {
 		return fmt.Errorf("client setup failed: %w", err)
 	}
 
 	// Step 3: Friend Connection
-	if err := pts.establishFriendConnection(ctx); err != nil {
+	if err := pts.runStep("friendRequest", func() error { return pts.establishFriendConnection(ctx) }); err != nil {
 		return fmt.Errorf("friend connection failed: %w", err)
 	}
 
 	// Step 4: Message Exchange
-	if err := pts.testMessageExchange(ctx); err != nil {
+	if err := pts.runStep("messageDelivery", func() error { return pts.testMessageExchange(ctx) }); err != nil {
 		return fmt.Errorf("message exchange failed: %w", err)
 	}
 
+	// Step 5: Partition Recovery (opt-in)
+	if pts.config.PartitionCount > 0 {
+		if err := pts.runStep("partitionRecovery", func() error { return pts.testPartitionRecovery(ctx) }); err != nil {
+			return fmt.Errorf("partition recovery failed: %w", err)
+		}
+	}
+
 	pts.logger.Info("🎉 All tests completed successfully!")
 	return nil
 }
 
+// SetStepRunner sets the runner each ExecuteTest step is passed through.
+// If nil is passed, steps run directly.
+func (pts *ProtocolTestSuite) SetStepRunner(runner StepRunner) {
+	pts.stepRunner = runner
+}
+
+// runStep runs a named step through the configured StepRunner.
+func (pts *ProtocolTestSuite) runStep(stepName string, operation func() error) error {
+	if pts.stepRunner == nil {
+		return operation()
+	}
+	return pts.stepRunner(stepName, operation)
+}
+
 // initializeNetwork sets up and validates the bootstrap server.
 func (pts *ProtocolTestSuite) initializeNetwork(ctx context.Context) error {
 	pts.logger.Info("📡 Step 1: Network Initialization")
@@ -157,7 +200,17 @@ func (pts *ProtocolTestSuite) createClients() error {
 	}
 	pts.clientB = clientB
 
-	return nil
+	return pts.installPartitioner()
+}
+
+// installPartitioner routes both clients' packet delivery through a shared
+// NetworkPartitioner, which passes packets through until PartitionNetwork.
+func (pts *ProtocolTestSuite) installPartitioner() error {
+	pts.partitioner = NewNetworkPartitioner(pts.logger)
+	if err := pts.clientA.InstallPartitioner(pts.partitioner); err != nil {
+		return err
+	}
+	return pts.clientB.InstallPartitioner(pts.partitioner)
 }
 
 // startClients starts both test clients.
@@ -389,6 +442,119 @@ func (pts *ProtocolTestSuite) testAliceSendsToBob(friendIDA uint32) error {
 	)
 }
 
+// testPartitionRecovery runs the configured number of network partitions.
+func (pts *ProtocolTestSuite) testPartitionRecovery(ctx context.Context) error {
+	pts.logger.Info("🔌 Step 5: Partition Recovery")
+
+	for i := 0; i < pts.config.PartitionCount; i++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		pts.logger.WithFields(logrus.Fields{
+			"partition": i + 1,
+			"total":     pts.config.PartitionCount,
+			"duration":  pts.config.PartitionDuration,
+		}).Info("Running network partition")
+
+		if err := pts.PartitionNetwork(pts.config.PartitionDuration); err != nil {
+			return fmt.Errorf("partition %d: %w", i+1, err)
+		}
+	}
+
+	pts.logger.Info("✅ Partition recovery completed successfully")
+	return nil
+}
+
+// queuedMessage is a message held back by the suite while the network is
+// partitioned.
+type queuedMessage struct {
+	sender, receiver         *TestClient
+	friendID                 uint32
+	content                  string
+	senderName, receiverName string
+}
+
+// PartitionNetwork drops all packets between Alice and Bob for duration and
+// then heals the network. While partitioned, each client queues a message
+// for the other. After healing it waits for both clients to see the other
+// reconnect via OnFriendConnectionStatus and verifies the queued messages
+// are delivered. toxcore has no liveness probe that would notice the
+// partition, so the suite reports the lost and restored links itself.
+// Clients must be friends before calling it.
+func (pts *ProtocolTestSuite) PartitionNetwork(duration time.Duration) error {
+	if duration <= 0 {
+		return fmt.Errorf("partition duration must be positive: %v", duration)
+	}
+	if pts.clientA == nil || pts.clientB == nil || pts.partitioner == nil {
+		return fmt.Errorf("clients must be set up before partitioning the network")
+	}
+	if len(pts.clientA.GetFriends()) == 0 || len(pts.clientB.GetFriends()) == 0 {
+		return fmt.Errorf("clients must be friends before partitioning the network")
+	}
+	friendIDA, friendIDB, err := pts.getFriendIDsForMessaging()
+	if err != nil {
+		return fmt.Errorf("failed to get friend IDs: %w", err)
+	}
+
+	if err := pts.partitioner.Partition(); err != nil {
+		return err
+	}
+	if err := pts.setFriendLinks(friendIDA, friendIDB, toxcore.ConnectionNone); err != nil {
+		return err
+	}
+	pts.partitions++
+	queued := []queuedMessage{
+		{pts.clientA, pts.clientB, friendIDA, fmt.Sprintf("Alice's message queued during partition %d.", pts.partitions), "Alice", "Bob"},
+		{pts.clientB, pts.clientA, friendIDB, fmt.Sprintf("Bob's message queued during partition %d.", pts.partitions), "Bob", "Alice"},
+	}
+	pts.logger.WithField("queued_messages", len(queued)).Info("📥 Messages queued while partitioned")
+
+	time.Sleep(duration)
+
+	if _, err := pts.partitioner.Heal(); err != nil {
+		return err
+	}
+	if err := pts.setFriendLinks(friendIDA, friendIDB, toxcore.ConnectionUDP); err != nil {
+		return err
+	}
+	if err := pts.waitForReconnection(friendIDA, friendIDB); err != nil {
+		return err
+	}
+
+	for _, m := range queued {
+		if err := pts.sendAndVerifyMessage(m.sender, m.receiver, m.friendID, m.content, m.senderName, m.receiverName); err != nil {
+			return fmt.Errorf("queued message not delivered after healing: %w", err)
+		}
+	}
+	return nil
+}
+
+// setFriendLinks sets the connection status each client holds for the other.
+func (pts *ProtocolTestSuite) setFriendLinks(friendIDA, friendIDB uint32, status toxcore.ConnectionStatus) error {
+	if err := pts.clientA.SetFriendConnectionStatus(friendIDA, status); err != nil {
+		return err
+	}
+	return pts.clientB.SetFriendConnectionStatus(friendIDB, status)
+}
+
+// waitForReconnection waits for both clients to report the other online.
+func (pts *ProtocolTestSuite) waitForReconnection(friendIDA, friendIDB uint32) error {
+	eventA, err := pts.clientA.WaitForFriendConnectionStatus(friendIDA, true, pts.config.ConnectionTimeout)
+	if err != nil {
+		return fmt.Errorf("Alice did not detect reconnection: %w", err)
+	}
+	eventB, err := pts.clientB.WaitForFriendConnectionStatus(friendIDB, true, pts.config.ConnectionTimeout)
+	if err != nil {
+		return fmt.Errorf("Bob did not detect reconnection: %w", err)
+	}
+
+	pts.logger.WithFields(logrus.Fields{
+		"alice_reconnected": eventA.Timestamp.Format(time.RFC3339Nano),
+		"bob_reconnected":   eventB.Timestamp.Format(time.RFC3339Nano),
+	}).Info("✅ Both clients detected reconnection")
+	return nil
+}
+
 // logFinalMetrics outputs final test metrics and status.
 func (pts *ProtocolTestSuite) logFinalMetrics() {
 	pts.logger.Info("📊 Final Test Metrics:")
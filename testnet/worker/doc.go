@@ -0,0 +1,28 @@
+// Package main provides the worker executable for distributed runs of the
+// Tox network integration test suite.
+//
+// # Overview
+//
+// A worker listens for TCP connections from a testnet/coordinator
+// Coordinator. Each connection carries one test configuration; the worker
+// runs the integration test suite locally with it, exactly as testnet/cmd
+// would, and writes the result back on the same connection. Workers keep no
+// state between dispatches: every setting comes from the coordinator.
+//
+// # Usage
+//
+// Run a worker on the default port:
+//
+//	go run ./testnet/worker
+//
+// Run a worker on a specific address with debug logging:
+//
+//	go run ./testnet/worker -listen 0.0.0.0:7800 -log-level DEBUG
+//
+// # Configuration Options
+//
+//   - -listen: TCP address to accept dispatches on (default: :7700)
+//   - -log-level: Log level: DEBUG, INFO, WARN, or ERROR (default: INFO)
+//
+// Interrupting the worker cancels running tests and stops it.
+package main
@@ -0,0 +1,68 @@
+// Package main provides the testnet worker, which runs integration tests
+// dispatched by a testnet/coordinator Coordinator and reports the results.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/opd-ai/toxcore/testnet/coordinator"
+	"github.com/sirupsen/logrus"
+)
+
+// parseFlags parses command-line flags and returns the listen address and
+// log level.
+func parseFlags() (listen, logLevel string) {
+	flag.StringVar(&listen, "listen", ":7700", "TCP address to accept dispatches on")
+	flag.StringVar(&logLevel, "log-level", "INFO", "Log level (DEBUG, INFO, WARN, ERROR)")
+	flag.Parse()
+	return listen, logLevel
+}
+
+// main is the entry point for the worker.
+func main() {
+	os.Exit(run())
+}
+
+// run starts the worker and serves dispatches until interrupted, returning
+// the exit code.
+func run() int {
+	listen, logLevel := parseFlags()
+
+	level, err := logrus.ParseLevel(strings.ToLower(logLevel))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid log level %q: %v\n", logLevel, err)
+		return 1
+	}
+	logrus.SetLevel(level)
+
+	worker := coordinator.NewWorker(nil)
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt)
+	go func() {
+		sig := <-sigChan
+		logrus.WithFields(logrus.Fields{
+			"signal":  sig.String(),
+			"context": "signal_handling",
+		}).Info("Received interrupt signal, shutting down worker")
+		if err := worker.Close(); err != nil {
+			logrus.WithError(err).Warn("Worker shutdown warning")
+		}
+	}()
+
+	if err := worker.ListenAndServe(listen); err != nil && !errors.Is(err, net.ErrClosed) {
+		logrus.WithFields(logrus.Fields{
+			"error":   err.Error(),
+			"listen":  listen,
+			"context": "worker_serve",
+		}).Error("Worker stopped")
+		return 1
+	}
+	return 0
+}
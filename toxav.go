@@ -127,6 +127,10 @@ func (t *toxAVTransportAdapter) convertPacketType(packetType byte) (transport.Pa
 		return transport.PacketAVVideoFrame, nil
 	case 0x35:
 		return transport.PacketAVBitrateControl, nil
+	case 0x36:
+		return transport.PacketAVCallTransfer, nil
+	case 0x37:
+		return transport.PacketAVCallTransferInvite, nil
 	default:
 		logrus.WithFields(logrus.Fields{
 			"function":    "Send",
@@ -272,6 +276,10 @@ func (t *toxAVTransportAdapter) convertToTransportPacketType(packetType byte) (t
 		transportPacketType = transport.PacketAVVideoFrame
 	case 0x35:
 		transportPacketType = transport.PacketAVBitrateControl
+	case 0x36:
+		transportPacketType = transport.PacketAVCallTransfer
+	case 0x37:
+		transportPacketType = transport.PacketAVCallTransferInvite
 	default:
 		logrus.WithFields(logrus.Fields{
 			"function":    "RegisterHandler",
@@ -383,6 +391,15 @@ type ToxAV struct {
 	videoBitRateCb func(friendNumber, bitRate uint32)
 	audioReceiveCb func(friendNumber uint32, pcm []int16, sampleCount int, channels uint8, samplingRate uint32)
 	videoReceiveCb func(friendNumber uint32, width, height uint16, y, u, v []byte, yStride, uStride, vStride int)
+
+	// Recording and screen share sessions keyed by friend number
+	recorders        map[uint32]*callRecorder
+	screenShares     map[uint32]*screenShare
+	recordingStatsCb func(friendNumber uint32, bytesWritten uint64)
+
+	// newScreenSource overrides the screen capture source; nil uses
+	// video.NewScreenCapture
+	newScreenSource func(width, height uint16) screenSource
 }
 
 // NewToxAV creates a new ToxAV instance from an existing Tox instance.
@@ -419,6 +436,9 @@ func NewToxAV(tox *Tox) (*ToxAV, error) {
 		impl: manager,
 	}
 
+	// End recordings and screen shares with their calls
+	manager.SetCallStateCallback(toxav.callStateHandler(nil))
+
 	// Register for friend deletion notifications to clean up active calls
 	tox.OnFriendDeleted(func(friendID uint32) {
 		toxav.impl.EndCallIfActive(friendID)
@@ -601,6 +621,8 @@ func (av *ToxAV) Kill() {
 	av.mu.Lock()
 	defer av.mu.Unlock()
 
+	av.closeSessionsLocked()
+
 	if av.impl != nil {
 		logrus.WithFields(logrus.Fields{
 			"function": "Kill",
@@ -1369,7 +1391,7 @@ func (av *ToxAV) CallbackCallState(callback func(friendNumber uint32, state avpk
 
 	// Wire the callback to the underlying av.Manager
 	if av.impl != nil {
-		av.impl.SetCallStateCallback(callback)
+		av.impl.SetCallStateCallback(av.callStateHandler(callback))
 		logrus.WithFields(logrus.Fields{
 			"function": "CallbackCallState",
 		}).Debug("Call state callback wired to av.Manager")
@@ -1454,7 +1476,7 @@ func (av *ToxAV) CallbackAudioReceiveFrame(callback func(friendNumber uint32, pc
 
 	// Wire the callback to the underlying av.Manager
 	if av.impl != nil {
-		av.impl.SetAudioReceiveCallback(callback)
+		av.wireReceiveCallbacksLocked()
 		logrus.WithFields(logrus.Fields{
 			"function": "CallbackAudioReceiveFrame",
 		}).Debug("Audio callback wired to av.Manager")
@@ -1483,7 +1505,7 @@ func (av *ToxAV) CallbackVideoReceiveFrame(callback func(friendNumber uint32, wi
 
 	// Wire the callback to the underlying av.Manager
 	if av.impl != nil {
-		av.impl.SetVideoReceiveCallback(callback)
+		av.wireReceiveCallbacksLocked()
 		logrus.WithFields(logrus.Fields{
 			"function": "CallbackVideoReceiveFrame",
 		}).Debug("Video callback wired to av.Manager")
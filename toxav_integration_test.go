@@ -424,6 +424,18 @@ func TestToxAVTransportAdapter_AudioVideoFramePackets(t *testing.T) {
 			expectedTransport: transport.PacketAVBitrateControl,
 			description:       "Bitrate control packet",
 		},
+		{
+			name:              "CallTransfer",
+			packetType:        0x36,
+			expectedTransport: transport.PacketAVCallTransfer,
+			description:       "Call transfer notice",
+		},
+		{
+			name:              "CallTransferInvite",
+			packetType:        0x37,
+			expectedTransport: transport.PacketAVCallTransferInvite,
+			description:       "Call transfer invite",
+		},
 	}
 
 	for _, tt := range tests {
@@ -503,7 +515,7 @@ func TestToxAVTransportAdapter_UnknownPacketType(t *testing.T) {
 	testData := []byte{0x01, 0x02, 0x03}
 
 	// Test unknown packet types
-	unknownTypes := []byte{0x00, 0x29, 0x36, 0xFF}
+	unknownTypes := []byte{0x00, 0x29, 0x38, 0xFF}
 	for _, pt := range unknownTypes {
 		err := adapter.Send(pt, testData, testAddr)
 		assert.Error(t, err, "Unknown packet type 0x%02x should return error", pt)
@@ -0,0 +1,516 @@
+package toxcore
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	avpkg "github.com/opd-ai/toxcore/av"
+	"github.com/opd-ai/toxcore/av/video"
+	"github.com/sirupsen/logrus"
+)
+
+// RecordingFormat selects the container written by StartRecording.
+type RecordingFormat uint8
+
+const (
+	// RecordingFormatWAV records received audio as 16-bit PCM WAV.
+	// Video frames are ignored.
+	RecordingFormatWAV RecordingFormat = iota
+	// RecordingFormatRaw records received audio and video as a stream of
+	// length-prefixed frames. Each record is kind(1) | length(4, big-endian)
+	// followed by the frame header and payload.
+	RecordingFormatRaw
+)
+
+// Raw recording record kinds.
+const (
+	rawRecordAudio byte = 0x01
+	rawRecordVideo byte = 0x02
+)
+
+// wavHeaderSize is the size of the canonical RIFF/WAVE header.
+const wavHeaderSize = 44
+
+var (
+	// ErrRecordingActive is returned when a recording is already running for a friend.
+	ErrRecordingActive = errors.New("recording already active for this friend")
+	// ErrNotRecording is returned when stopping a recording that was never started.
+	ErrNotRecording = errors.New("no active recording for this friend")
+	// ErrInvalidRecordingFormat is returned for unknown RecordingFormat values.
+	ErrInvalidRecordingFormat = errors.New("invalid recording format")
+	// ErrScreenShareActive is returned when screen sharing is already running for a friend.
+	ErrScreenShareActive = errors.New("screen share already active for this friend")
+	// ErrNotScreenSharing is returned when stopping a screen share that was never started.
+	ErrNotScreenSharing = errors.New("no active screen share for this friend")
+	// ErrVideoNotEnabled is returned when screen sharing is requested on an audio-only call.
+	ErrVideoNotEnabled = errors.New("call has no video stream")
+)
+
+// callRecorder writes the received media of one call to a file.
+type callRecorder struct {
+	mu           sync.Mutex
+	file         *os.File
+	w            *bufio.Writer
+	format       RecordingFormat
+	bytesWritten uint64
+	dataBytes    uint32
+	channels     uint8
+	samplingRate uint32
+	headerDone   bool
+}
+
+// newCallRecorder creates the output file for a recording.
+func newCallRecorder(path string, format RecordingFormat) (*callRecorder, error) {
+	if format != RecordingFormatWAV && format != RecordingFormatRaw {
+		return nil, ErrInvalidRecordingFormat
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("open recording file: %w", err)
+	}
+	return &callRecorder{file: file, w: bufio.NewWriter(file), format: format}, nil
+}
+
+// writeAudio appends a PCM frame and returns the total bytes written.
+func (r *callRecorder) writeAudio(pcm []int16, sampleCount int, channels uint8, samplingRate uint32) (uint64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	samples := sampleCount * int(channels)
+	if samples > len(pcm) {
+		samples = len(pcm)
+	}
+	payload := make([]byte, samples*2)
+	for i := 0; i < samples; i++ {
+		binary.LittleEndian.PutUint16(payload[i*2:], uint16(pcm[i]))
+	}
+
+	switch r.format {
+	case RecordingFormatWAV:
+		if !r.headerDone {
+			r.channels = channels
+			r.samplingRate = samplingRate
+			if err := r.write(wavHeader(channels, samplingRate, 0)); err != nil {
+				return r.bytesWritten, err
+			}
+			r.headerDone = true
+		}
+		if channels != r.channels || samplingRate != r.samplingRate {
+			// WAV cannot change format mid-stream; drop mismatched frames.
+			return r.bytesWritten, nil
+		}
+		r.dataBytes += uint32(len(payload))
+		return r.bytesWritten, r.write(payload)
+	default:
+		header := make([]byte, 5)
+		header[0] = channels
+		binary.BigEndian.PutUint32(header[1:], samplingRate)
+		return r.bytesWritten, r.writeRecord(rawRecordAudio, header, payload)
+	}
+}
+
+// writeVideo appends a YUV420 frame in raw format and returns the total
+// bytes written. WAV recordings ignore video.
+func (r *callRecorder) writeVideo(width, height uint16, y, u, v []byte) (uint64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.format != RecordingFormatRaw {
+		return r.bytesWritten, nil
+	}
+	header := make([]byte, 4)
+	binary.BigEndian.PutUint16(header[0:], width)
+	binary.BigEndian.PutUint16(header[2:], height)
+	return r.bytesWritten, r.writeRecord(rawRecordVideo, header, y, u, v)
+}
+
+// writeRecord writes one length-prefixed raw record. Caller must hold r.mu.
+func (r *callRecorder) writeRecord(kind byte, parts ...[]byte) error {
+	length := 0
+	for _, p := range parts {
+		length += len(p)
+	}
+	prefix := make([]byte, 5)
+	prefix[0] = kind
+	binary.BigEndian.PutUint32(prefix[1:], uint32(length))
+	if err := r.write(prefix); err != nil {
+		return err
+	}
+	for _, p := range parts {
+		if err := r.write(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// write appends data to the file. Caller must hold r.mu.
+func (r *callRecorder) write(data []byte) error {
+	n, err := r.w.Write(data)
+	r.bytesWritten += uint64(n)
+	return err
+}
+
+// close flushes buffered data, finalizes the WAV header and closes the file.
+func (r *callRecorder) close() (uint64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	err := r.w.Flush()
+	if err == nil && r.format == RecordingFormatWAV && r.headerDone {
+		_, err = r.file.WriteAt(wavHeader(r.channels, r.samplingRate, r.dataBytes), 0)
+	}
+	if closeErr := r.file.Close(); err == nil {
+		err = closeErr
+	}
+	return r.bytesWritten, err
+}
+
+// wavHeader builds a PCM WAV header for dataBytes of 16-bit samples.
+func wavHeader(channels uint8, samplingRate, dataBytes uint32) []byte {
+	h := make([]byte, wavHeaderSize)
+	blockAlign := uint16(channels) * 2
+	copy(h[0:], "RIFF")
+	binary.LittleEndian.PutUint32(h[4:], 36+dataBytes)
+	copy(h[8:], "WAVEfmt ")
+	binary.LittleEndian.PutUint32(h[16:], 16)
+	binary.LittleEndian.PutUint16(h[20:], 1) // PCM
+	binary.LittleEndian.PutUint16(h[22:], uint16(channels))
+	binary.LittleEndian.PutUint32(h[24:], samplingRate)
+	binary.LittleEndian.PutUint32(h[28:], samplingRate*uint32(blockAlign))
+	binary.LittleEndian.PutUint16(h[32:], blockAlign)
+	binary.LittleEndian.PutUint16(h[34:], 16)
+	copy(h[36:], "data")
+	binary.LittleEndian.PutUint32(h[40:], dataBytes)
+	return h
+}
+
+// StartRecording begins writing the media received from friendNumber to path.
+// The friend must be in an active call. Progress is reported through the
+// callback registered with CallbackRecordingStats.
+func (av *ToxAV) StartRecording(friendNumber uint32, path string, format RecordingFormat) error {
+	av.mu.Lock()
+	defer av.mu.Unlock()
+
+	if err := av.requireCallLocked(friendNumber); err != nil {
+		return err
+	}
+	if _, exists := av.recorders[friendNumber]; exists {
+		return ErrRecordingActive
+	}
+	recorder, err := newCallRecorder(path, format)
+	if err != nil {
+		return err
+	}
+	if av.recorders == nil {
+		av.recorders = make(map[uint32]*callRecorder)
+	}
+	av.recorders[friendNumber] = recorder
+	av.wireReceiveCallbacksLocked()
+
+	logrus.WithFields(logrus.Fields{
+		"function":      "StartRecording",
+		"friend_number": friendNumber,
+		"path":          path,
+		"format":        format,
+	}).Info("Call recording started")
+	return nil
+}
+
+// StopRecording finishes the recording for friendNumber and closes its file.
+func (av *ToxAV) StopRecording(friendNumber uint32) error {
+	av.mu.Lock()
+	recorder, exists := av.recorders[friendNumber]
+	if !exists {
+		av.mu.Unlock()
+		return ErrNotRecording
+	}
+	delete(av.recorders, friendNumber)
+	av.wireReceiveCallbacksLocked()
+	cb := av.recordingStatsCb
+	av.mu.Unlock()
+
+	written, err := recorder.close()
+	if cb != nil {
+		cb(friendNumber, written)
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"function":      "StopRecording",
+		"friend_number": friendNumber,
+		"bytes_written": written,
+	}).Info("Call recording stopped")
+	if err != nil {
+		return fmt.Errorf("finalize recording: %w", err)
+	}
+	return nil
+}
+
+// CallbackRecordingStats sets the callback that reports the total number of
+// bytes written to a recording after each frame and when it stops.
+func (av *ToxAV) CallbackRecordingStats(callback func(friendNumber uint32, bytesWritten uint64)) {
+	av.mu.Lock()
+	defer av.mu.Unlock()
+	av.recordingStatsCb = callback
+}
+
+// screenSource supplies the frames of a screen share. It is satisfied by
+// *video.ScreenCapture and replaced in tests.
+type screenSource interface {
+	Start() error
+	Stop() error
+	FrameChan() <-chan *video.VideoFrame
+}
+
+// screenShare is a running screen share. done is closed once the goroutine
+// forwarding the source's frames into the call has returned.
+type screenShare struct {
+	source screenSource
+	done   chan struct{}
+}
+
+// openScreenSource creates the capture source for a screen share at the
+// call's video resolution. Caller must hold av.mu.
+func (av *ToxAV) openScreenSource(width, height uint16) screenSource {
+	if av.newScreenSource != nil {
+		return av.newScreenSource(width, height)
+	}
+	return video.NewScreenCapture(width, height)
+}
+
+// StartScreenShare captures the display and sends it as the video stream of
+// the call with friendNumber until StopScreenShare is called or the call
+// ends. The call must be active with video enabled. Frames are scaled to the
+// call's video resolution. It returns video.ErrNotSupported when the
+// platform cannot capture the screen.
+func (av *ToxAV) StartScreenShare(friendNumber uint32) error {
+	av.mu.Lock()
+	defer av.mu.Unlock()
+
+	if err := av.requireCallLocked(friendNumber); err != nil {
+		return err
+	}
+	call := av.impl.GetCall(friendNumber)
+	if !call.IsVideoEnabled() {
+		return ErrVideoNotEnabled
+	}
+	if _, exists := av.screenShares[friendNumber]; exists {
+		return ErrScreenShareActive
+	}
+
+	var width, height uint16
+	if processor := call.GetVideoProcessor(); processor != nil {
+		width, height = processor.GetFrameSize()
+	}
+	source := av.openScreenSource(width, height)
+	if err := source.Start(); err != nil {
+		return fmt.Errorf("start screen capture: %w", err)
+	}
+	share := &screenShare{source: source, done: make(chan struct{})}
+	if av.screenShares == nil {
+		av.screenShares = make(map[uint32]*screenShare)
+	}
+	av.screenShares[friendNumber] = share
+	go av.streamScreen(friendNumber, source.FrameChan(), share.done)
+
+	logrus.WithFields(logrus.Fields{
+		"function":      "StartScreenShare",
+		"friend_number": friendNumber,
+		"width":         width,
+		"height":        height,
+	}).Info("Screen share started")
+	return nil
+}
+
+// streamScreen sends captured frames to friendNumber until the source closes
+// frames, then closes done.
+func (av *ToxAV) streamScreen(friendNumber uint32, frames <-chan *video.VideoFrame, done chan<- struct{}) {
+	defer close(done)
+
+	for frame := range frames {
+		if err := av.VideoSendFrame(friendNumber, frame.Width, frame.Height, frame.Y, frame.U, frame.V); err != nil {
+			logrus.WithFields(logrus.Fields{
+				"function":      "streamScreen",
+				"friend_number": friendNumber,
+				"error":         err.Error(),
+			}).Debug("Failed to send screen frame")
+		}
+	}
+}
+
+// StopScreenShare ends screen sharing with friendNumber. It returns once the
+// capture has stopped and no further frames will be sent.
+func (av *ToxAV) StopScreenShare(friendNumber uint32) error {
+	av.mu.Lock()
+	share, exists := av.screenShares[friendNumber]
+	if !exists {
+		av.mu.Unlock()
+		return ErrNotScreenSharing
+	}
+	delete(av.screenShares, friendNumber)
+	av.mu.Unlock()
+
+	err := share.source.Stop()
+	<-share.done
+
+	logrus.WithFields(logrus.Fields{
+		"function":      "StopScreenShare",
+		"friend_number": friendNumber,
+	}).Info("Screen share stopped")
+	if err != nil {
+		return fmt.Errorf("stop screen capture: %w", err)
+	}
+	return nil
+}
+
+// IsScreenSharing reports whether screen sharing is active with friendNumber.
+func (av *ToxAV) IsScreenSharing(friendNumber uint32) bool {
+	av.mu.RLock()
+	defer av.mu.RUnlock()
+	_, exists := av.screenShares[friendNumber]
+	return exists
+}
+
+// requireCallLocked checks that the instance is alive and friendNumber is in
+// a call. Caller must hold av.mu.
+func (av *ToxAV) requireCallLocked(friendNumber uint32) error {
+	if av.impl == nil {
+		return errors.New("ToxAV instance has been destroyed")
+	}
+	if av.tox != nil && !av.tox.friends.Exists(friendNumber) {
+		return ErrFriendNotFound
+	}
+	if av.impl.GetCall(friendNumber) == nil {
+		return ErrNoActiveCall
+	}
+	return nil
+}
+
+// wireReceiveCallbacksLocked installs the receive callbacks on the manager.
+// While any recording is active, frames are routed through the recorders
+// before reaching the application callbacks. Caller must hold av.mu.
+func (av *ToxAV) wireReceiveCallbacksLocked() {
+	if av.impl == nil {
+		return
+	}
+	if len(av.recorders) == 0 {
+		av.impl.SetAudioReceiveCallback(av.audioReceiveCb)
+		av.impl.SetVideoReceiveCallback(av.videoReceiveCb)
+		return
+	}
+	av.impl.SetAudioReceiveCallback(av.dispatchAudioReceive)
+	av.impl.SetVideoReceiveCallback(av.dispatchVideoReceive)
+}
+
+// dispatchAudioReceive records an incoming audio frame and forwards it.
+func (av *ToxAV) dispatchAudioReceive(friendNumber uint32, pcm []int16, sampleCount int, channels uint8, samplingRate uint32) {
+	av.mu.RLock()
+	recorder := av.recorders[friendNumber]
+	appCb := av.audioReceiveCb
+	statsCb := av.recordingStatsCb
+	av.mu.RUnlock()
+
+	if recorder != nil {
+		written, err := recorder.writeAudio(pcm, sampleCount, channels, samplingRate)
+		av.reportRecording(friendNumber, written, err, statsCb)
+	}
+	if appCb != nil {
+		appCb(friendNumber, pcm, sampleCount, channels, samplingRate)
+	}
+}
+
+// dispatchVideoReceive records an incoming video frame and forwards it.
+func (av *ToxAV) dispatchVideoReceive(friendNumber uint32, width, height uint16, y, u, v []byte, yStride, uStride, vStride int) {
+	av.mu.RLock()
+	recorder := av.recorders[friendNumber]
+	appCb := av.videoReceiveCb
+	statsCb := av.recordingStatsCb
+	av.mu.RUnlock()
+
+	if recorder != nil && recorder.format == RecordingFormatRaw {
+		written, err := recorder.writeVideo(width, height, y, u, v)
+		av.reportRecording(friendNumber, written, err, statsCb)
+	}
+	if appCb != nil {
+		appCb(friendNumber, width, height, y, u, v, yStride, uStride, vStride)
+	}
+}
+
+// reportRecording logs write failures and reports progress to the stats callback.
+func (av *ToxAV) reportRecording(friendNumber uint32, written uint64, err error, cb func(uint32, uint64)) {
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"function":      "reportRecording",
+			"friend_number": friendNumber,
+			"error":         err.Error(),
+		}).Warn("Failed to write recording frame")
+		return
+	}
+	if cb != nil {
+		cb(friendNumber, written)
+	}
+}
+
+// callStateHandler returns the call state callback installed on the
+// manager. When a call finishes or fails it ends the call's recording and
+// screen share, then forwards the state to callback.
+func (av *ToxAV) callStateHandler(callback func(friendNumber uint32, state avpkg.CallState)) func(uint32, avpkg.CallState) {
+	return func(friendNumber uint32, state avpkg.CallState) {
+		if state == avpkg.CallStateFinished || state == avpkg.CallStateError {
+			// The manager invokes this with its lock held, and ToxAV calls
+			// into the manager with av.mu held, so clean up asynchronously.
+			go av.endCallSessions(friendNumber)
+		}
+		if callback != nil {
+			callback(friendNumber, state)
+		}
+	}
+}
+
+// endCallSessions stops the recording and screen share of an ended call.
+func (av *ToxAV) endCallSessions(friendNumber uint32) {
+	if err := av.StopRecording(friendNumber); err != nil && !errors.Is(err, ErrNotRecording) {
+		logrus.WithFields(logrus.Fields{
+			"function":      "endCallSessions",
+			"friend_number": friendNumber,
+			"error":         err.Error(),
+		}).Warn("Failed to finalize recording")
+	}
+	if err := av.StopScreenShare(friendNumber); err != nil && !errors.Is(err, ErrNotScreenSharing) {
+		logrus.WithFields(logrus.Fields{
+			"function":      "endCallSessions",
+			"friend_number": friendNumber,
+			"error":         err.Error(),
+		}).Warn("Failed to stop screen share")
+	}
+}
+
+// closeSessionsLocked closes all open recordings and stops all screen
+// captures. The frame forwarding goroutines exit once their source closes
+// its frame channel. Caller must hold av.mu.
+func (av *ToxAV) closeSessionsLocked() {
+	for friendNumber, recorder := range av.recorders {
+		if _, err := recorder.close(); err != nil {
+			logrus.WithFields(logrus.Fields{
+				"function":      "closeSessionsLocked",
+				"friend_number": friendNumber,
+				"error":         err.Error(),
+			}).Warn("Failed to finalize recording")
+		}
+	}
+	for friendNumber, share := range av.screenShares {
+		if err := share.source.Stop(); err != nil {
+			logrus.WithFields(logrus.Fields{
+				"function":      "closeSessionsLocked",
+				"friend_number": friendNumber,
+				"error":         err.Error(),
+			}).Warn("Failed to stop screen capture")
+		}
+	}
+	av.recorders = nil
+	av.screenShares = nil
+}
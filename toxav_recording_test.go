@@ -0,0 +1,166 @@
+package toxcore
+
+import (
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	avpkg "github.com/opd-ai/toxcore/av"
+	"github.com/opd-ai/toxcore/av/video"
+	"github.com/opd-ai/toxcore/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCallRecorderWAV(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "call.wav")
+	r, err := newCallRecorder(path, RecordingFormatWAV)
+	require.NoError(t, err)
+
+	pcm := make([]int16, 960)
+	for i := range pcm {
+		pcm[i] = int16(i)
+	}
+	_, err = r.writeAudio(pcm, 480, 2, 48000)
+	require.NoError(t, err)
+	_, err = r.writeVideo(2, 2, []byte{1, 2, 3, 4}, []byte{5}, []byte{6})
+	require.NoError(t, err)
+	written, err := r.close()
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(len(data)), written)
+	assert.Len(t, data, wavHeaderSize+960*2, "video is not written to WAV")
+	assert.Equal(t, "RIFF", string(data[0:4]))
+	assert.Equal(t, uint16(2), binary.LittleEndian.Uint16(data[22:]))
+	assert.Equal(t, uint32(48000), binary.LittleEndian.Uint32(data[24:]))
+	assert.Equal(t, uint32(960*2), binary.LittleEndian.Uint32(data[40:]))
+}
+
+func TestCallRecorderRaw(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "call.raw")
+	r, err := newCallRecorder(path, RecordingFormatRaw)
+	require.NoError(t, err)
+
+	_, err = r.writeAudio([]int16{1, 2}, 2, 1, 8000)
+	require.NoError(t, err)
+	_, err = r.writeVideo(2, 2, []byte{1, 2, 3, 4}, []byte{5}, []byte{6})
+	require.NoError(t, err)
+	_, err = r.close()
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, rawRecordAudio, data[0])
+	audioLen := binary.BigEndian.Uint32(data[1:])
+	assert.Equal(t, uint32(5+4), audioLen)
+	next := 5 + int(audioLen)
+	require.Equal(t, rawRecordVideo, data[next])
+	assert.Equal(t, uint32(4+6), binary.BigEndian.Uint32(data[next+1:]))
+}
+
+func TestStartRecordingRequiresCall(t *testing.T) {
+	tox, err := New(NewOptionsForTesting())
+	require.NoError(t, err)
+	defer tox.Kill()
+
+	toxav, err := NewToxAV(tox)
+	require.NoError(t, err)
+	defer toxav.Kill()
+
+	path := filepath.Join(t.TempDir(), "call.wav")
+	assert.ErrorIs(t, toxav.StartRecording(42, path, RecordingFormatWAV), ErrFriendNotFound)
+	assert.ErrorIs(t, toxav.StopRecording(42), ErrNotRecording)
+	assert.ErrorIs(t, toxav.StartScreenShare(42), ErrFriendNotFound)
+	assert.ErrorIs(t, toxav.StopScreenShare(42), ErrNotScreenSharing)
+	assert.False(t, toxav.IsScreenSharing(42))
+
+	_, err = newCallRecorder(path, RecordingFormat(9))
+	assert.ErrorIs(t, err, ErrInvalidRecordingFormat)
+}
+
+// fakeScreenSource is a screen share source fed by the test.
+type fakeScreenSource struct {
+	frames  chan *video.VideoFrame
+	stopped chan struct{}
+}
+
+func newFakeScreenSource() *fakeScreenSource {
+	return &fakeScreenSource{frames: make(chan *video.VideoFrame), stopped: make(chan struct{})}
+}
+
+func (s *fakeScreenSource) Start() error                        { return nil }
+func (s *fakeScreenSource) FrameChan() <-chan *video.VideoFrame { return s.frames }
+
+func (s *fakeScreenSource) Stop() error {
+	close(s.frames)
+	close(s.stopped)
+	return nil
+}
+
+// discardAVTransport accepts and drops all AV signaling.
+type discardAVTransport struct{}
+
+func (discardAVTransport) Send(packetType byte, data, addr []byte) error { return nil }
+func (discardAVTransport) RegisterHandler(packetType byte, handler func(data, addr []byte) error) {
+}
+
+func TestScreenShareEndsWithCall(t *testing.T) {
+	tox, err := New(NewOptionsForTesting())
+	require.NoError(t, err)
+	defer tox.Kill()
+
+	friendKeys, err := crypto.GenerateKeyPair()
+	require.NoError(t, err)
+	friendNumber, err := tox.AddFriendByPublicKey(friendKeys.Public)
+	require.NoError(t, err)
+	tox.friends.Get(friendNumber).ConnectionStatus = ConnectionUDP
+
+	manager, err := avpkg.NewManager(discardAVTransport{}, func(uint32) ([]byte, error) {
+		return []byte{127, 0, 0, 1, 0, 9}, nil
+	})
+	require.NoError(t, err)
+	require.NoError(t, manager.Start())
+	toxav := &ToxAV{tox: tox, impl: manager}
+	defer toxav.Kill()
+	toxav.CallbackCallState(nil)
+
+	source := newFakeScreenSource()
+	var gotWidth, gotHeight uint16
+	toxav.newScreenSource = func(width, height uint16) screenSource {
+		gotWidth, gotHeight = width, height
+		return source
+	}
+
+	require.NoError(t, toxav.Call(friendNumber, 48000, 500000))
+	path := filepath.Join(t.TempDir(), "call.raw")
+	require.NoError(t, toxav.StartRecording(friendNumber, path, RecordingFormatRaw))
+	require.NoError(t, toxav.StartScreenShare(friendNumber))
+	assert.ErrorIs(t, toxav.StartScreenShare(friendNumber), ErrScreenShareActive)
+	assert.True(t, toxav.IsScreenSharing(friendNumber))
+	assert.NotZero(t, gotWidth, "source is created at the call's resolution")
+	assert.NotZero(t, gotHeight)
+
+	// Captured frames are consumed and sent into the call
+	frame := &video.VideoFrame{Width: 2, Height: 2, Y: make([]byte, 4), U: make([]byte, 1), V: make([]byte, 1)}
+	select {
+	case source.frames <- frame:
+	case <-time.After(time.Second):
+		t.Fatal("screen frames are not consumed")
+	}
+
+	require.NoError(t, toxav.CallControl(friendNumber, avpkg.CallControlCancel))
+	select {
+	case <-source.stopped:
+	case <-time.After(time.Second):
+		t.Fatal("screen capture not stopped when the call ended")
+	}
+	assert.Eventually(t, func() bool { return !toxav.IsScreenSharing(friendNumber) }, time.Second, 10*time.Millisecond)
+	assert.Eventually(t, func() bool {
+		return errors.Is(toxav.StopRecording(friendNumber), ErrNotRecording)
+	}, time.Second, 10*time.Millisecond, "recording ends with the call")
+}
@@ -127,6 +127,13 @@ type Options struct {
 	ThreadsEnabled   bool
 	BootstrapTimeout time.Duration
 
+	// SavedataPassphrase, when set, encrypts GetSavedata output with
+	// AES-256-GCM under an Argon2id-derived key, and New uses it to decrypt
+	// encrypted SavedataData. The slice is wiped once the key is derived.
+	// Loading encrypted data with the wrong passphrase fails with
+	// ErrWrongPassphrase.
+	SavedataPassphrase []byte `json:"-"`
+
 	// Relay configuration for symmetric NAT fallback
 	RelayServers []RelayServerConfig // List of TCP relay servers
 	RelayEnabled bool                // Enable relay fallback for failed connections
@@ -161,6 +168,18 @@ type Options struct {
 	// the NmcdResolver.  Ignored when NameResolverEnabled is false.
 	// Defaults to "<default data dir>/names.db" when empty.
 	NameResolverDBPath string
+
+	// LogConfig sets per-component log levels when the instance is created,
+	// mapping a component name ("dht", "transport", "async", "messaging",
+	// "av") to a logrus level name such as "debug" or "warn". Components not
+	// listed keep following the global logrus level. See
+	// Tox.SetComponentLogLevel.
+	LogConfig map[string]string
+
+	// ResourceLimits bounds friends, conferences, pending friend requests,
+	// DHT nodes and queued async messages. Unset fields use the defaults
+	// from DefaultResourceLimits.
+	ResourceLimits ResourceLimits
 }
 
 // DeliveryRetryConfig configures automatic retry behavior for message delivery.
@@ -303,6 +322,7 @@ func NewOptions() *Options {
 		MinBootstrapNodes:   4,                // Default: require 4 nodes for production use
 		AsyncStorageEnabled: true,             // Default: participate as storage node for async messaging
 		DeliveryRetryConfig: DefaultDeliveryRetryConfig(),
+		ResourceLimits:      DefaultResourceLimits(),
 	}
 
 	logrus.WithFields(logrus.Fields{
@@ -384,6 +404,10 @@ type Tox struct {
 	nospam        [4]byte // Nospam value for ToxID generation
 	selfMutex     sync.RWMutex
 
+	// Savedata encryption key derived from Options.SavedataPassphrase; nil
+	// when savedata is stored in plaintext. Guarded by selfMutex.
+	savedataSealer *savedataSealer
+
 	// Friend-related fields - uses sharded storage for reduced mutex contention at scale
 	friends              *friend.FriendStore[Friend]
 	friendsAddMu         sync.Mutex // Serialises generateFriendID + Set to prevent TOCTOU (F-TOXCORE-H4)
@@ -392,12 +416,14 @@ type Tox struct {
 	pendingFriendReqs    []*pendingFriendRequest
 	pendingFriendReqsMux sync.Mutex
 	requestManager       *friend.RequestManager // Centralized friend request management
+	blockList            *friend.BlockList      // Senders whose friend requests are dropped
 
 	// File transfers
 	fileTransfers map[uint64]*file.Transfer // Key: (friendID << 32) | fileID
 	transfersMu   sync.RWMutex
 	fileIDCounter uint32        // atomic monotonic counter for unique file transfer IDs
 	fileManager   *file.Manager // Centralized file transfer management with transport integration
+	fileManagerMu sync.RWMutex  // Protects fileManager pointer access
 
 	// Conferences (simple group chats)
 	conferences      map[uint32]*group.Chat
@@ -420,6 +446,8 @@ type Tox struct {
 	friendRequestCallback          FriendRequestCallback
 	friendMessageCallback          FriendMessageCallback
 	simpleFriendMessageCallback    SimpleFriendMessageCallback
+	friendMessageReplyCallback     FriendMessageReplyCallback
+	friendMessageWithReplyCallback FriendMessageWithReplyCallback
 	friendStatusCallback           FriendStatusCallback
 	connectionStatusCallback       ConnectionStatusCallback
 	friendConnectionStatusCallback FriendConnectionStatusCallback
@@ -434,6 +462,7 @@ type Tox struct {
 	friendTypingCallback        func(friendID uint32, isTyping bool)
 	friendDeletedCallback       func(friendID uint32)   // Called when a friend is deleted
 	friendKeyChangeCallback     FriendKeyChangeCallback // TOFU key-change alarm
+	messageReactionCallback     MessageReactionCallback
 
 	// Callback mutex for thread safety
 	callbackMu sync.RWMutex
@@ -442,6 +471,16 @@ type Tox struct {
 	ctx    context.Context
 	cancel context.CancelFunc
 
+	// limits holds the effective resource limits (see Options.ResourceLimits).
+	limits ResourceLimits
+
+	// startTime records when the instance was created, for health reporting.
+	startTime time.Time
+
+	// Optional HTTP health check server (see StartHealthServer)
+	health   *healthServer
+	healthMu sync.Mutex
+
 	// Monotonic counter incremented on every Iterate() call.
 	// Used to rate-limit periodic maintenance operations.
 	iterationCount uint64
@@ -477,7 +516,17 @@ func (t *Tox) GetSavedata() []byte {
 		logrus.WithError(err).Error("Failed to serialize Tox state")
 		return nil
 	}
-	return data
+	if t.savedataSealer == nil {
+		return data
+	}
+
+	defer crypto.ZeroBytes(data)
+	sealed, err := t.savedataSealer.seal(data)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to encrypt Tox state")
+		return nil
+	}
+	return sealed
 }
 
 // createKeyPair creates a cryptographic key pair based on the provided options.
@@ -518,6 +567,9 @@ func getDefaultDataDir() string {
 func initializeToxInstance(options *Options, keyPair *crypto.KeyPair, udpTransport, tcpTransport transport.Transport, nospam [4]byte, toxID *crypto.ToxID) (*Tox, error) {
 	ctx, cancel := context.WithCancel(context.Background())
 	rdht := dht.NewRoutingTable(*toxID, 8)
+	if options.IPv6Enabled && udpTransport != nil && dht.SupportsIPv6(udpTransport.LocalAddr()) {
+		rdht.SetNetworkPreference(dht.NetworkPreference{PreferIPv6: true})
+	}
 
 	bootstrapManager, err := createBootstrapManager(options, toxID, keyPair, udpTransport, rdht)
 	if err != nil {
@@ -536,6 +588,7 @@ func initializeToxInstance(options *Options, keyPair *crypto.KeyPair, udpTranspo
 	}
 
 	packetDelivery := setupPacketDelivery(udpTransport)
+	bootstrapManager.SetDNSBootstrapEnabled(!packetDelivery.IsSimulation())
 
 	tox := createToxInstance(options, keyPair, rdht, udpTransport, tcpTransport, bootstrapManager, packetDelivery, nospam, asyncManager, ctx, cancel)
 
@@ -648,10 +701,16 @@ func createToxInstance(options *Options, keyPair *crypto.KeyPair, rdht *dht.Rout
 		asyncManager:     asyncManager,
 		ctx:              ctx,
 		cancel:           cancel,
+		limits:           options.ResourceLimits.withDefaults(),
+		startTime:        time.Now(),
 		timeProvider:     RealTimeProvider{},
 		nameResolver:     initializeNameResolver(options),
 	}
 
+	if rdht != nil {
+		rdht.SetNodeLimit(tox.limits.MaxDHTNodes)
+	}
+
 	initializeMessagingManagers(tox)
 	initializeFileManager(tox, udpTransport)
 	initializeLANDiscovery(tox, options)
@@ -705,7 +764,7 @@ func initializeNameResolver(options *Options) nameresolver.Resolver {
 
 // initializeMessagingManagers configures the message and friend request managers.
 func initializeMessagingManagers(tox *Tox) {
-	tox.messageManager = messaging.NewMessageManager()
+	tox.messageManager = messaging.NewMessageManagerWithContext(tox.ctx)
 	tox.messageManager.SetTransport(tox)
 	tox.messageManager.SetKeyProvider(tox)
 
@@ -722,6 +781,8 @@ func initializeMessagingManagers(tox *Tox) {
 	}
 
 	tox.requestManager = friend.NewRequestManager()
+	tox.blockList = friend.NewBlockList()
+	tox.requestManager.SetBlockList(tox.blockList)
 }
 
 // initializeFileManager sets up the file transfer manager with transport integration.
@@ -769,10 +830,37 @@ func initializeFileManager(tox *Tox, udpTransport transport.Transport) {
 
 // New creates a new Tox instance with the specified options.
 // If options is nil, default options are used.
+// Options are checked with Options.Validate first, so misconfiguration is
+// reported as a wrapped validation sentinel such as ErrInvalidProxyPort.
 // Returns the Tox instance or an error if initialization fails.
 func New(options *Options) (*Tox, error) {
+	if err := options.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid options: %w", err)
+	}
 	logNewInstanceStarting()
 	options = validateAndInitializeOptions(options)
+	options, sealer, err := prepareSavedataEncryption(options)
+	if err != nil {
+		return nil, err
+	}
+	return newWithSavedataSealer(options, sealer)
+}
+
+// newWithSavedataSealer creates a Tox instance from validated options whose
+// savedata has already been decrypted, keeping sealer for later saves.
+func newWithSavedataSealer(options *Options, sealer *savedataSealer) (*Tox, error) {
+	tox, err := newInstance(options, sealer)
+	if err != nil && sealer != nil {
+		sealer.wipe()
+	}
+	return tox, err
+}
+
+// newInstance builds the Tox instance and loads its saved state.
+func newInstance(options *Options, sealer *savedataSealer) (*Tox, error) {
+	if err := applyLogConfig(options.LogConfig); err != nil {
+		return nil, err
+	}
 	logOptionsConfiguration(options)
 	checkForRiskyConfigurations(options)
 
@@ -790,6 +878,7 @@ func New(options *Options) (*Tox, error) {
 	if err != nil {
 		return nil, err
 	}
+	tox.savedataSealer = sealer
 
 	if err := tox.loadSavedState(options); err != nil {
 		logrus.WithFields(logrus.Fields{"function": "New", "error": err.Error()}).Error("Failed to load saved state, cleaning up")
@@ -1042,8 +1131,24 @@ func NewFromSavedata(options *Options, savedata []byte) (*Tox, error) {
 		"savedata_length": len(savedata),
 	}).Info("Creating Tox instance from savedata")
 
+	if err := options.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid options: %w", err)
+	}
+
+	var passphrase []byte
+	if options != nil {
+		passphrase = options.SavedataPassphrase
+	}
+	sealer, savedata, err := openSavedata(passphrase, savedata)
+	if err != nil {
+		return nil, err
+	}
+
 	savedState, err := parseSavedState(savedata)
 	if err != nil {
+		if sealer != nil {
+			sealer.wipe()
+		}
 		return nil, err
 	}
 
@@ -1052,7 +1157,8 @@ func NewFromSavedata(options *Options, savedata []byte) (*Tox, error) {
 	logrus.WithFields(logrus.Fields{
 		"function": "NewFromSavedata",
 	}).Debug("Creating Tox instance with restored key")
-	tox, err := New(options)
+	logNewInstanceStarting()
+	tox, err := newWithSavedataSealer(options, sealer)
 	if err != nil {
 		logrus.WithFields(logrus.Fields{
 			"function": "NewFromSavedata",
@@ -1109,14 +1215,14 @@ func (t *Tox) processIncomingPacket(packet []byte, senderAddr net.Addr) error {
 	}
 
 	packetType := packet[0]
-	return t.routePacketByType(packetType, packet)
+	return t.routePacketByType(packetType, packet, senderAddr)
 }
 
 // routePacketByType routes the packet to the appropriate handler based on type.
-func (t *Tox) routePacketByType(packetType byte, packet []byte) error {
+func (t *Tox) routePacketByType(packetType byte, packet []byte, senderAddr net.Addr) error {
 	switch packetType {
 	case 0x01:
-		return t.processFriendMessagePacket(packet)
+		return t.processFriendMessagePacket(packet, senderAddr)
 	case 0x02:
 		return t.processFriendNameUpdatePacket(packet)
 	case 0x03:
@@ -1205,6 +1311,7 @@ type Friend struct {
 	Name             string
 	StatusMessage    string
 	LastSeen         time.Time
+	AddedAt          time.Time // When the friend was added to the friend list
 	UserData         interface{}
 	IsTyping         bool
 	// DisappearingMessages holds the disappearing-message configuration for
@@ -0,0 +1,86 @@
+// Package toxcore implements the core functionality of the Tox protocol.
+// This file contains the canonical parsers for Tox addresses given as text
+// or as a QR code image.
+package toxcore
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	_ "image/jpeg" // register JPEG decoding for ParseToxQRCode
+	_ "image/png"  // register PNG decoding for ParseToxQRCode
+
+	"github.com/makiuchi-d/gozxing"
+	"github.com/makiuchi-d/gozxing/qrcode"
+	"github.com/opd-ai/toxcore/crypto"
+)
+
+// ToxURIScheme is the URI scheme prefix used for Tox addresses in links and
+// QR codes, as in "tox:<76 hex characters>".
+const ToxURIScheme = crypto.ToxURIScheme
+
+// ErrInvalidToxAddress indicates a string or QR code does not hold a valid
+// Tox address.
+var ErrInvalidToxAddress = crypto.ErrInvalidToxAddress
+
+// ParseToxAddressString parses a Tox address given either as 76 hex
+// characters or in the tox: URI form, and returns the raw 38-byte address:
+// public key, nospam, and checksum. Surrounding whitespace, the case of the
+// hex digits and the scheme, and a "//" after the scheme are accepted. The
+// checksum is verified.
+//
+//export ToxParseAddressString
+func ParseToxAddressString(s string) ([crypto.ToxIDSize]byte, error) {
+	var address [crypto.ToxIDSize]byte
+
+	id, err := crypto.ParseToxAddress(s)
+	if err != nil {
+		return address, err
+	}
+	copy(address[:crypto.KeySize], id.PublicKey[:])
+	copy(address[crypto.KeySize:crypto.KeySize+crypto.ToxIDNospamSize], id.Nospam[:])
+	copy(address[crypto.KeySize+crypto.ToxIDNospamSize:], id.Checksum[:])
+	return address, nil
+}
+
+// ParseToxQRCode decodes a PNG or JPEG image containing a QR code of a Tox
+// address and returns the raw 38-byte address. The QR payload must use the
+// tox: URI form.
+//
+//export ToxParseQRCode
+func ParseToxQRCode(imageData []byte) ([crypto.ToxIDSize]byte, error) {
+	var address [crypto.ToxIDSize]byte
+
+	img, _, err := image.Decode(bytes.NewReader(imageData))
+	if err != nil {
+		return address, fmt.Errorf("failed to decode image: %w", err)
+	}
+	bitmap, err := gozxing.NewBinaryBitmapFromImage(img)
+	if err != nil {
+		return address, fmt.Errorf("failed to prepare image for QR decoding: %w", err)
+	}
+	result, err := decodeQRCode(bitmap)
+	if err != nil {
+		return address, fmt.Errorf("no QR code found: %w", err)
+	}
+
+	payload := result.GetText()
+	if !crypto.HasToxURIScheme(payload) {
+		return address, fmt.Errorf("%w: QR code does not contain a %s URI", ErrInvalidToxAddress, ToxURIScheme)
+	}
+	return ParseToxAddressString(payload)
+}
+
+// decodeQRCode reads a QR code from bitmap. Images that contain only the
+// code, as generated tox: QR codes do, are decoded with the PURE_BARCODE
+// hint, which avoids the finder-pattern misdetections that make the general
+// detector fail on a small fraction of valid codes. Other images fall back
+// to the general detector.
+func decodeQRCode(bitmap *gozxing.BinaryBitmap) (*gozxing.Result, error) {
+	reader := qrcode.NewQRCodeReader()
+	pure := map[gozxing.DecodeHintType]interface{}{gozxing.DecodeHintType_PURE_BARCODE: true}
+	if result, err := reader.Decode(bitmap, pure); err == nil {
+		return result, nil
+	}
+	return reader.Decode(bitmap, nil)
+}
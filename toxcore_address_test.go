@@ -0,0 +1,156 @@
+package toxcore
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/makiuchi-d/gozxing"
+	"github.com/makiuchi-d/gozxing/qrcode"
+	"github.com/opd-ai/toxcore/crypto"
+)
+
+// testToxAddress returns a Tox ID with a valid checksum for parsing tests.
+func testToxAddress(t *testing.T) *crypto.ToxID {
+	t.Helper()
+	keyPair, err := crypto.GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("Failed to generate key pair: %v", err)
+	}
+	return crypto.NewToxID(keyPair.Public, [4]byte{1, 2, 3, 4})
+}
+
+// encodeQRCode renders text as a QR code image.
+func encodeQRCode(t *testing.T, text string) image.Image {
+	t.Helper()
+	matrix, err := qrcode.NewQRCodeWriter().Encode(text, gozxing.BarcodeFormat_QR_CODE, 256, 256, nil)
+	if err != nil {
+		t.Fatalf("Failed to encode QR code: %v", err)
+	}
+	return matrix
+}
+
+func TestParseToxAddressString(t *testing.T) {
+	id := testToxAddress(t)
+	hexAddress := id.String()
+
+	inputs := []string{
+		hexAddress,
+		strings.ToUpper(hexAddress),
+		"tox:" + hexAddress,
+		"TOX:" + hexAddress,
+		"tox://" + hexAddress,
+		"  tox:" + hexAddress + "\n",
+	}
+	for _, input := range inputs {
+		address, err := ParseToxAddressString(input)
+		if err != nil {
+			t.Errorf("ParseToxAddressString(%q) failed: %v", input, err)
+			continue
+		}
+		if !bytes.Equal(address[:crypto.KeySize], id.PublicKey[:]) {
+			t.Errorf("ParseToxAddressString(%q) returned the wrong public key", input)
+		}
+		if !bytes.Equal(address[crypto.ToxIDSize-2:], id.Checksum[:]) {
+			t.Errorf("ParseToxAddressString(%q) returned the wrong checksum", input)
+		}
+	}
+
+	corrupted := hexAddress[:74] + "00"
+	if corrupted == hexAddress {
+		corrupted = hexAddress[:74] + "01"
+	}
+	invalid := []string{"", "tox:", hexAddress[:70], "tox:" + hexAddress + "00", "zz" + hexAddress[2:], corrupted, "http:" + hexAddress}
+	for _, input := range invalid {
+		if _, err := ParseToxAddressString(input); !errors.Is(err, ErrInvalidToxAddress) {
+			t.Errorf("ParseToxAddressString(%q) error = %v, want ErrInvalidToxAddress", input, err)
+		}
+	}
+}
+
+func TestParseToxQRCode(t *testing.T) {
+	id := testToxAddress(t)
+	img := encodeQRCode(t, "tox:"+strings.ToUpper(id.String()))
+
+	var pngData, jpegData bytes.Buffer
+	if err := png.Encode(&pngData, img); err != nil {
+		t.Fatalf("Failed to encode PNG: %v", err)
+	}
+	if err := jpeg.Encode(&jpegData, img, &jpeg.Options{Quality: 90}); err != nil {
+		t.Fatalf("Failed to encode JPEG: %v", err)
+	}
+
+	for name, data := range map[string][]byte{"png": pngData.Bytes(), "jpeg": jpegData.Bytes()} {
+		address, err := ParseToxQRCode(data)
+		if err != nil {
+			t.Errorf("ParseToxQRCode(%s) failed: %v", name, err)
+			continue
+		}
+		if !bytes.Equal(address[:crypto.KeySize], id.PublicKey[:]) {
+			t.Errorf("ParseToxQRCode(%s) returned the wrong public key", name)
+		}
+	}
+}
+
+// TestParseToxQRCodeManyKeys round-trips QR codes of many random addresses,
+// since decoding failures depend on the payload.
+func TestParseToxQRCodeManyKeys(t *testing.T) {
+	for i := 0; i < 200; i++ {
+		id := testToxAddress(t)
+		var data bytes.Buffer
+		if err := png.Encode(&data, encodeQRCode(t, "tox:"+strings.ToUpper(id.String()))); err != nil {
+			t.Fatalf("Failed to encode PNG: %v", err)
+		}
+		address, err := ParseToxQRCode(data.Bytes())
+		if err != nil {
+			t.Fatalf("ParseToxQRCode(%s) failed: %v", id.String(), err)
+		}
+		if !bytes.Equal(address[:crypto.KeySize], id.PublicKey[:]) {
+			t.Fatalf("ParseToxQRCode(%s) returned the wrong public key", id.String())
+		}
+	}
+}
+
+func TestParseToxQRCodeRejectsInvalidPayloads(t *testing.T) {
+	id := testToxAddress(t)
+
+	var raw bytes.Buffer
+	if err := png.Encode(&raw, encodeQRCode(t, id.String())); err != nil {
+		t.Fatalf("Failed to encode PNG: %v", err)
+	}
+	if _, err := ParseToxQRCode(raw.Bytes()); !errors.Is(err, ErrInvalidToxAddress) {
+		t.Errorf("QR code without tox: prefix error = %v, want ErrInvalidToxAddress", err)
+	}
+
+	var blank bytes.Buffer
+	if err := png.Encode(&blank, image.NewGray(image.Rect(0, 0, 64, 64))); err != nil {
+		t.Fatalf("Failed to encode PNG: %v", err)
+	}
+	if _, err := ParseToxQRCode(blank.Bytes()); err == nil {
+		t.Error("Image without a QR code should fail to parse")
+	}
+	if _, err := ParseToxQRCode([]byte("not an image")); err == nil {
+		t.Error("Non-image data should fail to parse")
+	}
+}
+
+func TestAddFriendAcceptsToxURI(t *testing.T) {
+	tox, err := New(NewOptionsForTesting())
+	if err != nil {
+		t.Fatalf("Failed to create Tox instance: %v", err)
+	}
+	defer tox.Kill()
+
+	id := testToxAddress(t)
+	friendID, err := tox.AddFriend("tox:"+id.String(), "Hello")
+	if err != nil {
+		t.Fatalf("AddFriend with tox: URI failed: %v", err)
+	}
+	if got, err := tox.GetFriendPublicKey(friendID); err != nil || got != id.PublicKey {
+		t.Errorf("Friend public key = %x, %v; want %x", got, err, id.PublicKey)
+	}
+}
@@ -32,6 +32,16 @@ type FriendStatusChangeCallback func(friendPK [32]byte, online bool)
 // FriendMessageCallback is called when a message is received from a friend.
 type FriendMessageCallback func(friendID uint32, message string, messageType MessageType)
 
+// FriendMessageReplyCallback is called when a message is received from a
+// friend. replyToID is the ID of the message being replied to, or 0 for a
+// top-level message.
+type FriendMessageReplyCallback func(friendID uint32, message string, messageType MessageType, replyToID uint32)
+
+// FriendMessageWithReplyCallback is called when a message is received from
+// a friend. For replies, msg.ReplyToID and msg.ReplySummary describe the
+// message being replied to; both are nil for a top-level message.
+type FriendMessageWithReplyCallback func(friendID uint32, msg *messaging.Message)
+
 // OnFriendRequest sets the callback for friend requests.
 //
 //export ToxOnFriendRequest
@@ -61,6 +71,28 @@ func (t *Tox) OnFriendMessageDetailed(callback FriendMessageCallback) {
 	t.friendMessageCallback = callback
 }
 
+// OnFriendMessageReply sets the callback for friend messages with message type
+// and reply reference. Callbacks registered with OnFriendMessage and
+// OnFriendMessageDetailed keep receiving the same messages without the
+// reply reference.
+//
+//export ToxOnFriendMessageReply
+func (t *Tox) OnFriendMessageReply(callback FriendMessageReplyCallback) {
+	t.callbackMu.Lock()
+	defer t.callbackMu.Unlock()
+	t.friendMessageReplyCallback = callback
+}
+
+// OnFriendMessageWithReply sets the callback for friend messages delivered
+// as a messaging.Message carrying the reply reference and its summary.
+//
+//export ToxOnFriendMessageWithReply
+func (t *Tox) OnFriendMessageWithReply(callback FriendMessageWithReplyCallback) {
+	t.callbackMu.Lock()
+	defer t.callbackMu.Unlock()
+	t.friendMessageWithReplyCallback = callback
+}
+
 // OnFriendStatus sets the callback for friend status changes.
 //
 //export ToxOnFriendStatus
@@ -241,6 +273,22 @@ const (
 	DeliveryStateFailed
 )
 
+// MessageReactionCallback is called when a friend reacts to a message.
+// messageID is the ID of the reacted-to message as returned by
+// FriendSendMessage.
+type MessageReactionCallback func(friendID, messageID uint32, emoji string)
+
+// OnMessageReaction sets the callback for emoji reactions received from
+// friends. Reactions to message IDs that are not known locally are discarded
+// without invoking the callback.
+//
+//export ToxOnMessageReaction
+func (t *Tox) OnMessageReaction(callback MessageReactionCallback) {
+	t.callbackMu.Lock()
+	defer t.callbackMu.Unlock()
+	t.messageReactionCallback = callback
+}
+
 // OnMessageDelivery sets the callback for message delivery state changes.
 // This callback is fired whenever a message's delivery status changes,
 // such as when the message is sent, delivered, read, or fails.
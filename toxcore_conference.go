@@ -15,6 +15,13 @@ import (
 //
 //export ToxConferenceNew
 func (t *Tox) ConferenceNew() (uint32, error) {
+	t.conferencesMu.RLock()
+	err := t.checkGroupLimitLocked()
+	t.conferencesMu.RUnlock()
+	if err != nil {
+		return 0, err
+	}
+
 	// Create the group outside the lock to avoid holding the lock during DHT I/O
 	chat, err := group.CreateWithKeyPair("Conference", group.ChatTypeText, group.PrivacyPublic, t.udpTransport, t.dht, t.keyPair)
 	if err != nil {
@@ -25,6 +32,11 @@ func (t *Tox) ConferenceNew() (uint32, error) {
 	t.conferencesMu.Lock()
 	defer t.conferencesMu.Unlock()
 
+	// Re-check: another conference may have been created meanwhile
+	if err := t.checkGroupLimitLocked(); err != nil {
+		return 0, err
+	}
+
 	// Generate unique conference ID
 	conferenceID := t.nextConferenceID
 	t.nextConferenceID++
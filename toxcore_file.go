@@ -311,5 +311,13 @@ func (t *Tox) buildFileChunkPacket(fileID uint32, position uint64, data []byte)
 
 // FileManager returns the file transfer manager.
 func (t *Tox) FileManager() *file.Manager {
+	return t.loadFileManager()
+}
+
+// loadFileManager returns the file manager under a read lock. Returns nil
+// if Kill() has already cleared it.
+func (t *Tox) loadFileManager() *file.Manager {
+	t.fileManagerMu.RLock()
+	defer t.fileManagerMu.RUnlock()
 	return t.fileManager
 }
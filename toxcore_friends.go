@@ -4,10 +4,14 @@
 package toxcore
 
 import (
+	"bytes"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"io"
 	"net"
 	"reflect"
+	"sort"
 	"time"
 
 	"github.com/opd-ai/toxcore/crypto"
@@ -17,13 +21,14 @@ import (
 )
 
 // AddFriend adds a new friend by their Tox ID and sends a friend request.
-// The address must be a valid Tox ID (76 hex characters).
+// The address must be a valid Tox ID, as 76 hex characters or a tox: URI
+// (see ParseToxAddressString).
 // Returns the new friend's ID on success.
 //
 //export ToxAddFriend
 func (t *Tox) AddFriend(address, message string) (uint32, error) {
 	// Parse the Tox ID
-	toxID, err := crypto.ToxIDFromString(address)
+	toxID, err := crypto.ParseToxAddress(address)
 	if err != nil {
 		return 0, err
 	}
@@ -37,6 +42,10 @@ func (t *Tox) AddFriend(address, message string) (uint32, error) {
 		t.friendsAddMu.Unlock()
 		return friendID, errors.New("already a friend")
 	}
+	if err := t.checkFriendLimit(); err != nil {
+		t.friendsAddMu.Unlock()
+		return 0, err
+	}
 
 	friendID, err = t.generateFriendID()
 	if err != nil {
@@ -48,6 +57,7 @@ func (t *Tox) AddFriend(address, message string) (uint32, error) {
 		Status:           FriendStatusNone,
 		ConnectionStatus: ConnectionNone,
 		LastSeen:         t.now(),
+		AddedAt:          t.now(),
 	}
 
 	// Add to friends list
@@ -85,6 +95,10 @@ func (t *Tox) AddFriendByPublicKey(publicKey [32]byte) (uint32, error) {
 		t.friendsAddMu.Unlock()
 		return friendID, errors.New("already a friend")
 	}
+	if err := t.checkFriendLimit(); err != nil {
+		t.friendsAddMu.Unlock()
+		return 0, err
+	}
 
 	var genErr error
 	friendID, genErr = t.generateFriendID()
@@ -97,6 +111,7 @@ func (t *Tox) AddFriendByPublicKey(publicKey [32]byte) (uint32, error) {
 		Status:           FriendStatusNone,
 		ConnectionStatus: ConnectionNone,
 		LastSeen:         t.now(),
+		AddedAt:          t.now(),
 	}
 
 	// Add to friends list
@@ -112,6 +127,56 @@ func (t *Tox) AddFriendByPublicKey(publicKey [32]byte) (uint32, error) {
 	return friendID, nil
 }
 
+// BlockFriend adds publicKey to the block list, so friend requests from it
+// are dropped without invoking the OnFriendRequest callback. A pending
+// request from the key is discarded. Blocking does not remove an existing
+// friend; use DeleteFriend for that.
+//
+//export ToxBlockFriend
+func (t *Tox) BlockFriend(publicKey [32]byte) error {
+	if t.blockList == nil {
+		return errors.New("block list not initialized")
+	}
+	if err := t.blockList.Block(publicKey); err != nil {
+		return err
+	}
+	if t.requestManager != nil {
+		t.requestManager.RejectRequest(publicKey)
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"function":   "BlockFriend",
+		"public_key": fmt.Sprintf("%x", publicKey[:8]),
+	}).Info("Public key blocked")
+	return nil
+}
+
+// UnblockFriend removes publicKey from the block list.
+//
+//export ToxUnblockFriend
+func (t *Tox) UnblockFriend(publicKey [32]byte) error {
+	if t.blockList == nil {
+		return errors.New("block list not initialized")
+	}
+	if err := t.blockList.Unblock(publicKey); err != nil {
+		return err
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"function":   "UnblockFriend",
+		"public_key": fmt.Sprintf("%x", publicKey[:8]),
+	}).Info("Public key unblocked")
+	return nil
+}
+
+// BlockList returns the block list used by BlockFriend and UnblockFriend.
+// Use its Save and Load methods to persist it across restarts.
+//
+//export ToxBlockList
+func (t *Tox) BlockList() *friend.BlockList {
+	return t.blockList
+}
+
 // getFriendIDByPublicKey finds a friend ID by public key.
 func (t *Tox) getFriendIDByPublicKey(publicKey [32]byte) (uint32, bool) {
 	id, f := t.friends.FindByPublicKey(publicKey, func(f *Friend) [32]byte {
@@ -259,6 +324,7 @@ func (t *Tox) GetFriends() map[uint32]*Friend {
 			Name:                 f.Name,
 			StatusMessage:        f.StatusMessage,
 			LastSeen:             f.LastSeen,
+			AddedAt:              f.AddedAt,
 			UserData:             cloneFriendUserData(f.UserData),
 			IsTyping:             f.IsTyping,
 			DisappearingMessages: f.DisappearingMessages,
@@ -401,12 +467,195 @@ func (t *Tox) GetFriendsCount() int {
 	return count
 }
 
+// sortedFriendIDs returns the keys of friends in ascending order.
+func sortedFriendIDs(friends map[uint32]*Friend) []uint32 {
+	ids := make([]uint32, 0, len(friends))
+	for id := range friends {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// ExportFriendList returns the friend list as a vCard 4.0 collection, one
+// card per friend in ascending friend ID order. Each card ends with CRLF, so
+// the cards are CRLF-separated. See friend.FriendInfo.ToVCard for the fields.
+//
+//export ToxExportFriendList
+func (t *Tox) ExportFriendList() ([]byte, error) {
+	friends := t.GetFriends()
+	ids := sortedFriendIDs(friends)
+
+	var out bytes.Buffer
+	for _, id := range ids {
+		f := friends[id]
+		info := &friend.FriendInfo{
+			PublicKey:     f.PublicKey,
+			Name:          f.Name,
+			StatusMessage: f.StatusMessage,
+		}
+		card, err := info.ToVCard()
+		if err != nil {
+			return nil, fmt.Errorf("export friend %d: %w", id, err)
+		}
+		out.Write(card)
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"function":      "ExportFriendList",
+		"friends_count": len(ids),
+		"size":          out.Len(),
+	}).Debug("Friend list exported as vCard collection")
+
+	return out.Bytes(), nil
+}
+
+// ImportFriends adds every friend listed in a JSON array read from r (see
+// friend.ImportEntry for the format). Entries whose key is a full Tox ID and
+// that carry a message are added with AddFriend, which sends a friend
+// request; all others are added with AddFriendByPublicKey.
+//
+// Keys that are already friends are counted as skipped. A bad entry does not
+// stop the import: every failure is collected and returned together in err
+// once all entries have been processed. err is also set, with all counts
+// zero, when r does not contain a valid JSON array.
+//
+//export ToxImportFriends
+func (t *Tox) ImportFriends(r io.Reader) (added, skipped, failed int, err error) {
+	entries, err := friend.DecodeImportList(r)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	var failures []error
+	for i, entry := range entries {
+		publicKey, keyErr := entry.Key()
+		if keyErr != nil {
+			failed++
+			failures = append(failures, fmt.Errorf("entry %d: %w", i, keyErr))
+			continue
+		}
+		if _, exists := t.getFriendIDByPublicKey(publicKey); exists {
+			skipped++
+			continue
+		}
+
+		var addErr error
+		if entry.IsToxID() && entry.Message != "" {
+			_, addErr = t.AddFriend(entry.PublicKey, entry.Message)
+		} else {
+			_, addErr = t.AddFriendByPublicKey(publicKey)
+		}
+		if addErr != nil {
+			failed++
+			failures = append(failures, fmt.Errorf("entry %d: %w", i, addErr))
+			continue
+		}
+		added++
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"function": "ImportFriends",
+		"entries":  len(entries),
+		"added":    added,
+		"skipped":  skipped,
+		"failed":   failed,
+	}).Info("Friend import completed")
+
+	return added, skipped, failed, errors.Join(failures...)
+}
+
+// ExportFriends writes all current friends to w in the JSON format read by
+// ImportFriends, ordered by friend ID, so the output can be used as a
+// contact list backup.
+//
+//export ToxExportFriends
+func (t *Tox) ExportFriends(w io.Writer) error {
+	friends := t.GetFriends()
+	ids := sortedFriendIDs(friends)
+
+	entries := make([]friend.ImportEntry, 0, len(ids))
+	for _, id := range ids {
+		pk := friends[id].PublicKey
+		entries = append(entries, friend.ImportEntry{PublicKey: hex.EncodeToString(pk[:])})
+	}
+	return friend.EncodeImportList(w, entries)
+}
+
+// ExportFriendBackup returns the friend list as a friend.FriendList, in
+// ascending friend ID order, for backup or migration to another client. Use
+// FriendList.Marshal to obtain the versioned JSON document.
+//
+//export ToxExportFriendBackup
+func (t *Tox) ExportFriendBackup() (*friend.FriendList, error) {
+	friends := t.GetFriends()
+	ids := sortedFriendIDs(friends)
+
+	fl := &friend.FriendList{Friends: make([]*friend.FriendInfo, 0, len(ids))}
+	for _, id := range ids {
+		f := friends[id]
+		fl.Friends = append(fl.Friends, &friend.FriendInfo{
+			PublicKey:     f.PublicKey,
+			Name:          f.Name,
+			StatusMessage: f.StatusMessage,
+			AddedAt:       f.AddedAt,
+		})
+	}
+	return fl, nil
+}
+
+// ImportFriendBackup adds every friend in fl with AddFriendByPublicKey and
+// restores its last known name, status message and time added, which the
+// friend's own updates replace once it comes online. Public keys that are
+// already friends, or that appear twice in fl, are counted as skipped.
+// Import stops at the first friend that cannot be added and returns the
+// counts so far with the error.
+//
+//export ToxImportFriendBackup
+func (t *Tox) ImportFriendBackup(fl *friend.FriendList) (imported, skipped int, err error) {
+	if fl == nil {
+		return 0, 0, errors.New("friend list cannot be nil")
+	}
+
+	for i, info := range fl.Friends {
+		if info == nil {
+			continue
+		}
+		if _, exists := t.getFriendIDByPublicKey(info.PublicKey); exists {
+			skipped++
+			continue
+		}
+		friendID, addErr := t.AddFriendByPublicKey(info.PublicKey)
+		if addErr != nil {
+			return imported, skipped, fmt.Errorf("friend %d: %w", i, addErr)
+		}
+		t.friends.Update(friendID, func(f *Friend) {
+			f.Name = info.Name
+			f.StatusMessage = info.StatusMessage
+			if !info.AddedAt.IsZero() {
+				f.AddedAt = info.AddedAt
+			}
+		})
+		imported++
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"function": "ImportFriendBackup",
+		"friends":  len(fl.Friends),
+		"imported": imported,
+		"skipped":  skipped,
+	}).Info("Friend backup imported")
+
+	return imported, skipped, nil
+}
+
 // cleanupFriendFileTransfers cancels any pending file transfers for a friend.
 func (t *Tox) cleanupFriendFileTransfers(friendID uint32) {
-	if t.fileManager == nil {
+	fm := t.loadFileManager()
+	if fm == nil {
 		return
 	}
-	cancelled := t.fileManager.CancelTransfersForFriend(friendID)
+	cancelled := fm.CancelTransfersForFriend(friendID)
 	if cancelled > 0 {
 		logrus.WithFields(logrus.Fields{
 			"function":            "cleanupFriendFileTransfers",
@@ -640,12 +889,27 @@ func (t *Tox) receiveFriendRequest(senderPublicKey [32]byte, message string) {
 
 	// Route through RequestManager if available for centralized request handling
 	if t.requestManager != nil {
+		if t.requestManager.PendingCount() >= t.limits.MaxPendingRequests {
+			logrus.WithFields(logrus.Fields{
+				"function":  "receiveFriendRequest",
+				"sender_pk": fmt.Sprintf("%x", senderPublicKey[:8]),
+				"limit":     t.limits.MaxPendingRequests,
+			}).Warn("Dropping friend request: pending request limit reached")
+			return
+		}
 		// Create a friend.Request to track in RequestManager
 		req := &friend.Request{
 			SenderPublicKey: senderPublicKey,
 			Message:         message,
 		}
-		t.requestManager.AddRequest(req)
+		if err := t.requestManager.AddRequest(req); err != nil {
+			logrus.WithFields(logrus.Fields{
+				"function":  "receiveFriendRequest",
+				"sender_pk": fmt.Sprintf("%x", senderPublicKey[:8]),
+				"error":     err.Error(),
+			}).Warn("Dropping friend request")
+			return
+		}
 	}
 
 	// Trigger the friend request callback if set
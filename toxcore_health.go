@@ -0,0 +1,231 @@
+// toxcore_health.go contains the optional HTTP health check server used as a
+// liveness and readiness probe for long-running deployments.
+
+package toxcore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/opd-ai/toxcore/dht"
+	"github.com/sirupsen/logrus"
+)
+
+// healthShutdownTimeout bounds how long in-flight health requests may take to
+// complete when the health server is stopped.
+const healthShutdownTimeout = 5 * time.Second
+
+var (
+	// ErrHealthServerRunning is returned by StartHealthServer when a health
+	// server is already running.
+	ErrHealthServerRunning = errors.New("health server already running")
+	// ErrHealthServerNotRunning is returned by StopHealthServer when no health
+	// server is running.
+	ErrHealthServerNotRunning = errors.New("health server not running")
+)
+
+// HealthStatus is the JSON body served by the /healthz endpoint.
+type HealthStatus struct {
+	Status        string `json:"status"`
+	Connection    string `json:"connection"`
+	FriendsOnline int    `json:"friends_online"`
+	UptimeSeconds int64  `json:"uptime_seconds"`
+}
+
+// healthServer is a running health check HTTP server.
+type healthServer struct {
+	server   *http.Server
+	listener net.Listener
+	cancel   context.CancelFunc
+	done     chan struct{}
+}
+
+// StartHealthServer starts an HTTP server on addr serving:
+//
+//   - GET /healthz: always 200 with a HealthStatus JSON body
+//   - GET /readyz: 200 once the DHT has at least one good node, 503 before
+//
+// The server runs in its own goroutines and never blocks Iterate. It is
+// shut down gracefully by StopHealthServer or Kill.
+//
+//export ToxStartHealthServer
+func (t *Tox) StartHealthServer(addr string) error {
+	t.healthMu.Lock()
+	defer t.healthMu.Unlock()
+
+	if t.health != nil {
+		return ErrHealthServerRunning
+	}
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", t.handleHealthz)
+	mux.HandleFunc("/readyz", t.handleReadyz)
+
+	ctx, cancel := context.WithCancel(t.ctx)
+	hs := &healthServer{
+		server: &http.Server{
+			Handler:           mux,
+			ReadHeaderTimeout: 5 * time.Second,
+			BaseContext:       func(net.Listener) context.Context { return ctx },
+		},
+		listener: listener,
+		cancel:   cancel,
+		done:     make(chan struct{}),
+	}
+	t.health = hs
+
+	go hs.serve()
+	go hs.shutdownOnCancel(ctx)
+
+	logrus.WithFields(logrus.Fields{
+		"function": "StartHealthServer",
+		"address":  listener.Addr().String(),
+	}).Info("Health server started")
+
+	return nil
+}
+
+// StopHealthServer shuts down the health server started by StartHealthServer,
+// waiting for in-flight requests to complete.
+//
+//export ToxStopHealthServer
+func (t *Tox) StopHealthServer() error {
+	t.healthMu.Lock()
+	hs := t.health
+	t.health = nil
+	t.healthMu.Unlock()
+
+	if hs == nil {
+		return ErrHealthServerNotRunning
+	}
+
+	hs.cancel()
+	<-hs.done
+
+	logrus.WithFields(logrus.Fields{
+		"function": "StopHealthServer",
+		"address":  hs.listener.Addr().String(),
+	}).Info("Health server stopped")
+
+	return nil
+}
+
+// stopHealthServer stops the health server if one is running.
+func (t *Tox) stopHealthServer() {
+	if err := t.StopHealthServer(); err != nil && !errors.Is(err, ErrHealthServerNotRunning) {
+		logrus.WithError(err).Warn("Failed to stop health server")
+	}
+}
+
+// serve runs the HTTP server until it is shut down.
+func (hs *healthServer) serve() {
+	if err := hs.server.Serve(hs.listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		logrus.WithFields(logrus.Fields{
+			"function": "healthServer.serve",
+			"error":    err.Error(),
+		}).Warn("Health server stopped unexpectedly")
+	}
+}
+
+// shutdownOnCancel gracefully shuts the server down once ctx is cancelled,
+// either by StopHealthServer or by the Tox instance being killed.
+func (hs *healthServer) shutdownOnCancel(ctx context.Context) {
+	defer close(hs.done)
+	<-ctx.Done()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), healthShutdownTimeout)
+	defer cancel()
+	if err := hs.server.Shutdown(shutdownCtx); err != nil {
+		logrus.WithError(err).Warn("Health server shutdown did not complete cleanly")
+		hs.server.Close()
+	}
+}
+
+// handleHealthz serves the liveness probe.
+func (t *Tox) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(t.healthStatus()); err != nil {
+		logrus.WithError(err).Debug("Failed to write health response")
+	}
+}
+
+// handleReadyz serves the readiness probe.
+func (t *Tox) handleReadyz(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if t.goodDHTNodeCount() == 0 {
+		http.Error(w, "DHT not bootstrapped", http.StatusServiceUnavailable)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintln(w, "ready")
+}
+
+// healthStatus gathers the current HealthStatus.
+func (t *Tox) healthStatus() HealthStatus {
+	online := 0
+	t.friends.Range(func(_ uint32, f *Friend) bool {
+		if f.ConnectionStatus != ConnectionNone {
+			online++
+		}
+		return true
+	})
+
+	return HealthStatus{
+		Status:        "ok",
+		Connection:    connectionStatusName(t.SelfGetConnectionStatus()),
+		FriendsOnline: online,
+		UptimeSeconds: int64(time.Since(t.startTime).Seconds()),
+	}
+}
+
+// goodDHTNodeCount returns the number of DHT nodes currently marked good.
+func (t *Tox) goodDHTNodeCount() int {
+	t.dhtMutex.RLock()
+	rt := t.dht
+	t.dhtMutex.RUnlock()
+
+	if rt == nil {
+		return 0
+	}
+	good := 0
+	for _, node := range rt.GetAllNodes() {
+		if node.GetStatus() == dht.StatusGood {
+			good++
+		}
+	}
+	return good
+}
+
+// connectionStatusName returns the lowercase name used for status in
+// health responses.
+func connectionStatusName(status ConnectionStatus) string {
+	switch status {
+	case ConnectionTCP:
+		return "tcp"
+	case ConnectionUDP:
+		return "udp"
+	default:
+		return "none"
+	}
+}
@@ -1488,7 +1488,7 @@ func TestProxyOptionsValidation(t *testing.T) {
 				Host: "",
 				Port: 9050,
 			},
-			shouldWork: true, // Empty host will cause proxy creation to fail gracefully
+			shouldWork: false, // Rejected by Options.Validate
 		},
 		{
 			name: "Zero port",
@@ -1497,7 +1497,7 @@ func TestProxyOptionsValidation(t *testing.T) {
 				Host: testLocalhost,
 				Port: 0,
 			},
-			shouldWork: true, // Zero port will cause proxy creation to fail gracefully
+			shouldWork: false, // Rejected by Options.Validate
 		},
 	}
 
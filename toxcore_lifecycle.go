@@ -16,6 +16,10 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+// ErrToxKilled is returned by IterateWithContext when the Tox instance is
+// killed while the loop is running.
+var ErrToxKilled = errors.New("tox instance killed")
+
 // Iterate performs a single iteration of the Tox event loop.
 //
 //export ToxIterate
@@ -35,10 +39,47 @@ func (t *Tox) Iterate() {
 	// Retry pending friend requests (production retry queue)
 	t.retryPendingFriendRequests()
 
+	// Retransmit file chunks whose acknowledgment timed out
+	if fm := t.loadFileManager(); fm != nil {
+		fm.RetransmitExpired()
+	}
+
 	// Increment iteration count after processing
 	atomic.AddUint64(&t.iterationCount, 1)
 }
 
+// IterateWithContext runs the Tox event loop, calling Iterate every
+// IterationInterval until ctx is cancelled or the instance is killed. It
+// checks for cancellation after each iteration, so it returns within one
+// iteration interval, with ctx.Err() on cancellation or ErrToxKilled after
+// Kill:
+//
+//	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM)
+//	defer stop()
+//	if err := tox.IterateWithContext(ctx); errors.Is(err, context.Canceled) {
+//	    tox.Kill()
+//	}
+func (t *Tox) IterateWithContext(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(t.IterationInterval())
+	defer ticker.Stop()
+
+	for {
+		t.Iterate()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-t.ctx.Done():
+			return ErrToxKilled
+		case <-ticker.C:
+		}
+	}
+}
+
 // IterationInterval returns the recommended interval between Iterate() calls.
 //
 //export ToxIterationInterval
@@ -83,6 +124,7 @@ func (t *Tox) Kill() {
 	t.stopBackgroundServices()
 	t.cleanupManagers()
 	t.clearCallbacks()
+	t.wipeSavedataKey()
 }
 
 // Context returns the lifecycle context of this Tox instance.
@@ -108,8 +150,10 @@ func (t *Tox) closeTransports() {
 	}
 }
 
-// stopBackgroundServices stops async manager and LAN discovery services.
+// stopBackgroundServices stops the health server, async manager and LAN
+// discovery services.
 func (t *Tox) stopBackgroundServices() {
+	t.stopHealthServer()
 	t.stopAsyncManager()
 	t.stopLANDiscovery()
 	t.closeNATTraversal()
@@ -168,16 +212,20 @@ func (t *Tox) snapshotBootstrapManager() *dht.BootstrapManager {
 func (t *Tox) cleanupManagers() {
 	t.cancelActiveFileTransfers()
 
+	// Close outside the lock: Close waits for in-flight sends, which may
+	// call back into the Tox instance.
 	t.messageManagerMu.Lock()
-	if t.messageManager != nil {
-		t.messageManager = nil
-	}
+	mm := t.messageManager
+	t.messageManager = nil
 	t.messageManagerMu.Unlock()
-
-	if t.fileManager != nil {
-		t.fileManager = nil
+	if mm != nil {
+		mm.Close()
 	}
 
+	t.fileManagerMu.Lock()
+	t.fileManager = nil
+	t.fileManagerMu.Unlock()
+
 	if t.requestManager != nil {
 		t.requestManager = nil
 	}
@@ -229,6 +277,8 @@ func (t *Tox) clearCallbacks() {
 	t.friendRequestCallback = nil
 	t.friendMessageCallback = nil
 	t.simpleFriendMessageCallback = nil
+	t.friendMessageReplyCallback = nil
+	t.friendMessageWithReplyCallback = nil
 	t.friendStatusCallback = nil
 	t.connectionStatusCallback = nil
 	t.friendConnectionStatusCallback = nil
@@ -240,6 +290,7 @@ func (t *Tox) clearCallbacks() {
 	t.friendStatusMessageCallback = nil
 	t.friendTypingCallback = nil
 	t.friendDeletedCallback = nil
+	t.messageReactionCallback = nil
 }
 
 // doDHTMaintenance performs periodic DHT maintenance tasks.
@@ -414,6 +465,11 @@ func (t *Tox) Load(data []byte) error {
 		return err
 	}
 
+	data, err := t.decryptLoadData(data)
+	if err != nil {
+		return err
+	}
+
 	saveData, err := t.unmarshalSaveData(data)
 	if err != nil {
 		return err
@@ -0,0 +1,152 @@
+// toxcore_limits.go contains resource limits that bound the memory a Tox
+// instance can be made to use, and the usage statistics that report on them.
+
+package toxcore
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Default resource limits. They are generous enough for normal use while
+// keeping a misbehaving peer from growing state without bound.
+const (
+	DefaultMaxFriends         = 10000
+	DefaultMaxGroups          = 1000
+	DefaultMaxPendingRequests = 1000
+	DefaultMaxDHTNodes        = 2048
+	DefaultMaxAsyncMessages   = 10000
+)
+
+var (
+	// ErrResourceLimit is returned when an operation would exceed one of the
+	// configured ResourceLimits. Errors for specific limits wrap it.
+	ErrResourceLimit = errors.New("resource limit reached")
+	// ErrFriendLimitReached is returned by AddFriend and AddFriendByPublicKey
+	// when the friend list already holds ResourceLimits.MaxFriends entries.
+	ErrFriendLimitReached = fmt.Errorf("%w: friend limit reached", ErrResourceLimit)
+)
+
+// ResourceLimits bounds the state a Tox instance keeps. Fields that are zero
+// or negative use the corresponding Default value.
+type ResourceLimits struct {
+	// MaxFriends caps the friend list.
+	MaxFriends int
+	// MaxGroups caps the number of conferences.
+	MaxGroups int
+	// MaxPendingRequests caps unhandled incoming friend requests; requests
+	// beyond it are dropped.
+	MaxPendingRequests int
+	// MaxDHTNodes caps the DHT routing table.
+	MaxDHTNodes int
+	// MaxAsyncMessages caps offline messages queued for sending.
+	MaxAsyncMessages int
+}
+
+// DefaultResourceLimits returns the default resource limits.
+func DefaultResourceLimits() ResourceLimits {
+	return ResourceLimits{
+		MaxFriends:         DefaultMaxFriends,
+		MaxGroups:          DefaultMaxGroups,
+		MaxPendingRequests: DefaultMaxPendingRequests,
+		MaxDHTNodes:        DefaultMaxDHTNodes,
+		MaxAsyncMessages:   DefaultMaxAsyncMessages,
+	}
+}
+
+// withDefaults returns l with unset fields replaced by their defaults.
+func (l ResourceLimits) withDefaults() ResourceLimits {
+	defaults := DefaultResourceLimits()
+	if l.MaxFriends <= 0 {
+		l.MaxFriends = defaults.MaxFriends
+	}
+	if l.MaxGroups <= 0 {
+		l.MaxGroups = defaults.MaxGroups
+	}
+	if l.MaxPendingRequests <= 0 {
+		l.MaxPendingRequests = defaults.MaxPendingRequests
+	}
+	if l.MaxDHTNodes <= 0 {
+		l.MaxDHTNodes = defaults.MaxDHTNodes
+	}
+	if l.MaxAsyncMessages <= 0 {
+		l.MaxAsyncMessages = defaults.MaxAsyncMessages
+	}
+	return l
+}
+
+// ResourceCount is the current usage of a resource and its limit.
+type ResourceCount struct {
+	Current int
+	Limit   int
+}
+
+// ResourceStats reports usage against each of the ResourceLimits.
+type ResourceStats struct {
+	Friends         ResourceCount
+	Groups          ResourceCount
+	PendingRequests ResourceCount
+	DHTNodes        ResourceCount
+	AsyncMessages   ResourceCount
+}
+
+// ResourceUsage returns current resource usage and the configured limits,
+// for monitoring how close the instance is to them.
+//
+//export ToxResourceUsage
+func (t *Tox) ResourceUsage() ResourceStats {
+	stats := ResourceStats{
+		Friends:         ResourceCount{Current: t.friends.Count(), Limit: t.limits.MaxFriends},
+		Groups:          ResourceCount{Limit: t.limits.MaxGroups},
+		PendingRequests: ResourceCount{Limit: t.limits.MaxPendingRequests},
+		DHTNodes:        ResourceCount{Limit: t.limits.MaxDHTNodes},
+		AsyncMessages:   ResourceCount{Limit: t.limits.MaxAsyncMessages},
+	}
+
+	t.conferencesMu.RLock()
+	stats.Groups.Current = len(t.conferences)
+	t.conferencesMu.RUnlock()
+
+	if t.requestManager != nil {
+		stats.PendingRequests.Current = t.requestManager.PendingCount()
+	}
+
+	t.dhtMutex.RLock()
+	if t.dht != nil {
+		stats.DHTNodes.Current = t.dht.Size()
+	}
+	t.dhtMutex.RUnlock()
+
+	if t.asyncManager != nil {
+		stats.AsyncMessages.Current = t.asyncManager.QueueDepth()
+	}
+
+	return stats
+}
+
+// checkFriendLimit returns ErrFriendLimitReached if no more friends can be
+// added. Callers hold friendsAddMu.
+func (t *Tox) checkFriendLimit() error {
+	if t.friends.Count() >= t.limits.MaxFriends {
+		return fmt.Errorf("%w (%d)", ErrFriendLimitReached, t.limits.MaxFriends)
+	}
+	return nil
+}
+
+// checkGroupLimitLocked returns an ErrResourceLimit error if no more
+// conferences can be created. Callers hold conferencesMu.
+func (t *Tox) checkGroupLimitLocked() error {
+	if len(t.conferences) >= t.limits.MaxGroups {
+		return fmt.Errorf("%w: conference limit %d", ErrResourceLimit, t.limits.MaxGroups)
+	}
+	return nil
+}
+
+// checkAsyncMessageLimit returns an ErrResourceLimit error if the async
+// outbound queue is full.
+func (t *Tox) checkAsyncMessageLimit() error {
+	if t.asyncManager.QueueDepth() >= t.limits.MaxAsyncMessages {
+		return fmt.Errorf("%w: async message limit %d", ErrResourceLimit, t.limits.MaxAsyncMessages)
+	}
+	return nil
+}
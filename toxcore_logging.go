@@ -0,0 +1,88 @@
+package toxcore
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/opd-ai/toxcore/async"
+	"github.com/opd-ai/toxcore/av"
+	"github.com/opd-ai/toxcore/dht"
+	"github.com/opd-ai/toxcore/messaging"
+	"github.com/opd-ai/toxcore/transport"
+	"github.com/sirupsen/logrus"
+)
+
+// ErrUnknownLogComponent indicates a component name with no per-component logger.
+var ErrUnknownLogComponent = errors.New("unknown log component")
+
+// componentLogLevelSetters maps component names to their package's SetLogLevel.
+var componentLogLevelSetters = map[string]func(logrus.Level){
+	"async":     async.SetLogLevel,
+	"av":        av.SetLogLevel,
+	"dht":       dht.SetLogLevel,
+	"messaging": messaging.SetLogLevel,
+	"transport": transport.SetLogLevel,
+}
+
+// LogComponents returns the component names accepted by SetComponentLogLevel
+// and Options.LogConfig, in sorted order.
+func LogComponents() []string {
+	names := make([]string, 0, len(componentLogLevelSetters))
+	for name := range componentLogLevelSetters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SetComponentLogLevel sets the log level of one component ("dht",
+// "transport", "async", "messaging" or "av") independently of the global
+// logrus level. Component loggers are per package, so the level applies to
+// every Tox instance in the process.
+//
+//export ToxSetComponentLogLevel
+func (t *Tox) SetComponentLogLevel(component string, level logrus.Level) error {
+	return setComponentLogLevel(component, level)
+}
+
+// setComponentLogLevel delegates to the named component's package.
+func setComponentLogLevel(component string, level logrus.Level) error {
+	setter, ok := componentLogLevelSetters[strings.ToLower(component)]
+	if !ok {
+		return fmt.Errorf("%w: %q (known: %s)", ErrUnknownLogComponent, component, strings.Join(LogComponents(), ", "))
+	}
+	setter(level)
+
+	logrus.WithFields(logrus.Fields{
+		"function":  "SetComponentLogLevel",
+		"component": component,
+		"level":     level.String(),
+	}).Debug("Component log level updated")
+
+	return nil
+}
+
+// applyLogConfig applies Options.LogConfig, mapping component names to
+// level names such as "debug" or "warn". All entries are validated before
+// any level changes.
+func applyLogConfig(config map[string]string) error {
+	levels := make(map[string]logrus.Level, len(config))
+	for component, name := range config {
+		if _, ok := componentLogLevelSetters[strings.ToLower(component)]; !ok {
+			return fmt.Errorf("invalid LogConfig: %w: %q", ErrUnknownLogComponent, component)
+		}
+		level, err := logrus.ParseLevel(name)
+		if err != nil {
+			return fmt.Errorf("invalid LogConfig level for %q: %w", component, err)
+		}
+		levels[component] = level
+	}
+	for component, level := range levels {
+		if err := setComponentLogLevel(component, level); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -139,6 +139,9 @@ func (t *Tox) sendAsyncMessage(publicKey [32]byte, message string, msgType Messa
 	if t.asyncManager == nil {
 		return fmt.Errorf("friend is not connected and async messaging is unavailable")
 	}
+	if err := t.checkAsyncMessageLimit(); err != nil {
+		return err
+	}
 
 	// Convert toxcore.MessageType to async.MessageType
 	asyncMsgType := async.MessageType(msgType)
@@ -174,11 +177,14 @@ func (t *Tox) doMessageProcessing() {
 }
 
 // dispatchFriendMessage dispatches an incoming friend message to the appropriate callback(s).
-// This method ensures both simple and detailed callbacks are called if they are registered.
-func (t *Tox) dispatchFriendMessage(friendID uint32, message string, messageType MessageType) {
+// This method ensures the simple, detailed and reply callbacks are all called if they are
+// registered; the older signatures are adapted by dropping the reply reference.
+func (t *Tox) dispatchFriendMessage(friendID, messageID uint32, message string, messageType MessageType, replyToID uint32, summary *messaging.ReplySummary) {
 	t.callbackMu.RLock()
 	simpleCb := t.simpleFriendMessageCallback
 	detailedCb := t.friendMessageCallback
+	replyCb := t.friendMessageReplyCallback
+	withReplyCb := t.friendMessageWithReplyCallback
 	t.callbackMu.RUnlock()
 
 	if simpleCb != nil {
@@ -187,14 +193,34 @@ func (t *Tox) dispatchFriendMessage(friendID uint32, message string, messageType
 	if detailedCb != nil {
 		detailedCb(friendID, message, messageType)
 	}
+	if replyCb != nil {
+		replyCb(friendID, message, messageType, replyToID)
+	}
+	if withReplyCb != nil {
+		msg := messaging.NewMessage(friendID, message, messaging.MessageType(messageType))
+		msg.ID = messageID
+		msg.State = messaging.MessageStateDelivered
+		if replyToID != 0 {
+			msg.ReplyToID = &replyToID
+			msg.ReplySummary = summary
+		}
+		withReplyCb(friendID, msg)
+	}
 }
 
-// receiveFriendMessage processes incoming messages from friends.
-// This method is automatically called by the network layer when message packets are received
-// and is integrated with the transport system for real-time message handling.
+// receiveFriendMessage processes incoming top-level messages from friends.
 //
 //export ToxReceiveFriendMessage
 func (t *Tox) receiveFriendMessage(friendID uint32, message string, messageType MessageType) {
+	t.receiveFriendMessageReply(friendID, 0, message, messageType, 0, nil)
+}
+
+// receiveFriendMessageReply processes incoming messages from friends.
+// This method is automatically called by the network layer when message packets are received
+// and is integrated with the transport system for real-time message handling.
+// Messages carrying the sender's message ID are recorded with the message
+// manager so that later edits, reactions and replies can refer to them.
+func (t *Tox) receiveFriendMessageReply(friendID, messageID uint32, message string, messageType MessageType, replyToID uint32, summary *messaging.ReplySummary) {
 	// Basic packet validation using shared validation logic
 	if !t.isValidMessage(message) {
 		return // Ignore invalid messages (empty or oversized)
@@ -205,8 +231,28 @@ func (t *Tox) receiveFriendMessage(friendID uint32, message string, messageType
 		return // Ignore messages from unknown friends
 	}
 
+	if messageID != 0 {
+		t.recordReceivedMessage(friendID, messageID, message, messageType, replyToID, summary)
+	}
+
 	// Dispatch to registered callbacks
-	t.dispatchFriendMessage(friendID, message, messageType)
+	t.dispatchFriendMessage(friendID, messageID, message, messageType, replyToID, summary)
+}
+
+// recordReceivedMessage stores a received message with the message manager
+// under the ID the friend assigned to it.
+func (t *Tox) recordReceivedMessage(friendID, messageID uint32, message string, messageType MessageType, replyToID uint32, summary *messaging.ReplySummary) {
+	t.messageManagerMu.RLock()
+	mm := t.messageManager
+	t.messageManagerMu.RUnlock()
+	if mm == nil {
+		return
+	}
+	if replyToID != 0 {
+		mm.ReceiveReply(friendID, messageID, replyToID, summary, message)
+		return
+	}
+	mm.ReceiveMessage(friendID, messageID, message, messaging.MessageType(messageType))
 }
 
 // receiveFriendStatusMessageUpdate processes incoming friend status message update packets
@@ -227,19 +273,53 @@ func (t *Tox) handleFriendMessagePacket(packet *transport.Packet, senderAddr net
 }
 
 // processFriendMessagePacket handles incoming friend message packets.
-func (t *Tox) processFriendMessagePacket(packet []byte) error {
-	if len(packet) < 6 {
+// Packet format: [TYPE(1)][FRIEND_ID(4)][MESSAGE_TYPE(1)][MESSAGE_ID(4)][REPLY_TO_ID(4)][REPLY_SUMMARY(64)?][MESSAGE...]
+//
+// Packets from the network are attributed to the friend at senderAddr, so
+// that edits and reactions checked against the same address find the
+// message; FRIEND_ID is only used for packets delivered locally without an
+// address. Packets from addresses that resolve to no friend are dropped.
+func (t *Tox) processFriendMessagePacket(packet []byte, senderAddr net.Addr) error {
+	if len(packet) < friendMessageHeaderSize {
 		return errors.New("friend message packet too small")
 	}
 
 	friendID := binary.BigEndian.Uint32(packet[1:5])
+	if senderAddr != nil {
+		sourceID, err := t.resolveFriendIDFromAddress(senderAddr)
+		if err != nil {
+			return nil
+		}
+		friendID = sourceID
+	}
 	messageType := MessageType(packet[5])
-	message := string(packet[6:])
+	messageID := binary.BigEndian.Uint32(packet[6:10])
+	replyToID := binary.BigEndian.Uint32(packet[10:14])
+	body := packet[friendMessageHeaderSize:]
+
+	var summary *messaging.ReplySummary
+	if replyToID != 0 {
+		if len(body) < messaging.ReplySummarySize {
+			return errors.New("friend reply packet missing reply summary")
+		}
+		summary = &messaging.ReplySummary{}
+		if err := summary.UnmarshalBinary(body[:messaging.ReplySummarySize]); err != nil {
+			return err
+		}
+		body = body[messaging.ReplySummarySize:]
+	}
 
-	t.receiveFriendMessage(friendID, message, messageType)
+	t.receiveFriendMessageReply(friendID, messageID, string(body), messageType, replyToID, summary)
 	return nil
 }
 
+// friendMessageHeaderSize is the size of the friend message packet header
+// preceding the message text. The message ID is the one the sender assigned,
+// which edits and reactions refer back to. A reply-to ID of 0 marks a
+// top-level message; replies carry a messaging.ReplySummarySize summary
+// before the text.
+const friendMessageHeaderSize = 14
+
 // SendMessagePacket sends a message packet to a friend using the transport layer.
 // This is a low-level method used by the message manager for actual packet delivery.
 func (t *Tox) SendMessagePacket(friendID uint32, message *messaging.Message) error {
@@ -252,13 +332,27 @@ func (t *Tox) SendMessagePacket(friendID uint32, message *messaging.Message) err
 	}
 	f := &snapshot
 
-	// Build packet: [TYPE(1)][FRIEND_ID(4)][MESSAGE_TYPE(1)][MESSAGE...]
+	// Build packet: [TYPE(1)][FRIEND_ID(4)][MESSAGE_TYPE(1)][MESSAGE_ID(4)][REPLY_TO_ID(4)][REPLY_SUMMARY(64)?][MESSAGE...]
 	msgText := message.GetText()
-	packet := make([]byte, 6+len(msgText))
+	replyToID := message.GetReplyToID()
+	packet := make([]byte, friendMessageHeaderSize, friendMessageHeaderSize+messaging.ReplySummarySize+len(msgText))
 	packet[0] = 0x01 // Friend message packet type
 	binary.BigEndian.PutUint32(packet[1:5], friendID)
 	packet[5] = byte(message.Type)
-	copy(packet[6:], msgText)
+	binary.BigEndian.PutUint32(packet[6:10], message.GetID())
+	binary.BigEndian.PutUint32(packet[10:14], replyToID)
+	if replyToID != 0 {
+		summary := message.ReplySummary
+		if summary == nil {
+			summary = &messaging.ReplySummary{}
+		}
+		encoded, err := summary.MarshalBinary()
+		if err != nil {
+			return err
+		}
+		packet = append(packet, encoded...)
+	}
+	packet = append(packet, msgText...)
 
 	// Get friend's network address from DHT
 	friendAddr, err := t.resolveFriendAddress(f)
@@ -326,3 +420,216 @@ func (t *Tox) sendRealTimeMessageWithID(friendID uint32, message string, msgType
 	}
 	return msg.ID, nil
 }
+
+// FriendSendReply sends a message to a friend as a reply to an earlier message
+// and returns the new message ID. replyToID must be an ID returned by
+// FriendSendMessage or FriendSendReply; a reply to a reply is attached to the
+// original message. Replies are only delivered in real time; an error is
+// returned if the friend is offline.
+//
+//export ToxFriendSendReply
+func (t *Tox) FriendSendReply(friendID, replyToID uint32, message string) (uint32, error) {
+	if err := t.validateMessageInput(message); err != nil {
+		return 0, err
+	}
+
+	friend, err := t.validateAndRetrieveFriend(friendID)
+	if err != nil {
+		return 0, err
+	}
+	if friend.ConnectionStatus == ConnectionNone {
+		return 0, errors.New("friend is not connected: replies require real-time delivery")
+	}
+
+	t.messageManagerMu.RLock()
+	mm := t.messageManager
+	t.messageManagerMu.RUnlock()
+	if mm == nil {
+		return 0, fmt.Errorf("message manager not initialised")
+	}
+	msg, err := mm.SendReply(friendID, replyToID, message)
+	if err != nil {
+		return 0, err
+	}
+	return msg.ID, nil
+}
+
+// messageReactionHeaderSize is the size of the reaction packet header
+// preceding the emoji: [SENDER_PK(32)][MESSAGE_ID(4)].
+const messageReactionHeaderSize = 36
+
+// ReactToMessage sends an emoji reaction to a message exchanged with a
+// friend: one received from them, identified by the message ID passed to the
+// OnFriendMessageWithReply callback, or one sent to them. The emoji is limited to messaging.MaxReactionEmojiBytes bytes of UTF-8 and
+// the friend must be online.
+//
+//export ToxReactToMessage
+func (t *Tox) ReactToMessage(friendID, messageID uint32, emoji string) error {
+	if err := messaging.ValidateReactionEmoji(emoji); err != nil {
+		return err
+	}
+
+	friend, err := t.validateFriendOnline(friendID, "friend is not online")
+	if err != nil {
+		return err
+	}
+
+	// Build packet: [SENDER_PK(32)][MESSAGE_ID(4)][EMOJI...]
+	packet := make([]byte, messageReactionHeaderSize+len(emoji))
+	copy(packet[:32], t.keyPair.Public[:])
+	binary.BigEndian.PutUint32(packet[32:36], messageID)
+	copy(packet[messageReactionHeaderSize:], emoji)
+
+	friendAddr, err := t.resolveFriendAddress(friend)
+	if err != nil {
+		return fmt.Errorf("failed to resolve friend address: %w", err)
+	}
+	if t.udpTransport == nil {
+		return errors.New("transport not available")
+	}
+
+	transportPacket := &transport.Packet{
+		PacketType: transport.PacketMessageReaction,
+		Data:       packet,
+	}
+	if err := t.udpTransport.Send(transportPacket, friendAddr); err != nil {
+		return fmt.Errorf("failed to send reaction: %w", err)
+	}
+	return nil
+}
+
+// GetMessageReactions returns the reactions friends have sent for a message
+// sent to them.
+//
+//export ToxGetMessageReactions
+func (t *Tox) GetMessageReactions(messageID uint32) []messaging.Reaction {
+	t.messageManagerMu.RLock()
+	mm := t.messageManager
+	t.messageManagerMu.RUnlock()
+	if mm == nil {
+		return nil
+	}
+	return mm.GetMessageReactions(messageID)
+}
+
+// GetReceivedMessageReactions returns the reactions a friend has sent for one
+// of their own messages, identified by the message ID passed to the
+// OnFriendMessageWithReply callback.
+//
+//export ToxGetReceivedMessageReactions
+func (t *Tox) GetReceivedMessageReactions(friendID, messageID uint32) []messaging.Reaction {
+	t.messageManagerMu.RLock()
+	mm := t.messageManager
+	t.messageManagerMu.RUnlock()
+	if mm == nil {
+		return nil
+	}
+	return mm.GetReceivedMessageReactions(friendID, messageID)
+}
+
+// handleMessageReactionPacket processes incoming reaction packets from the transport layer.
+// Reactions from unknown senders, from an address that does not belong to the
+// claimed sender, or to unknown messages are dropped silently.
+func (t *Tox) handleMessageReactionPacket(packet *transport.Packet, addr net.Addr) error {
+	data := packet.Data
+	if len(data) <= messageReactionHeaderSize {
+		return errors.New("message reaction packet too small")
+	}
+
+	var senderPublicKey [32]byte
+	copy(senderPublicKey[:], data[:32])
+	messageID := binary.BigEndian.Uint32(data[32:36])
+	emoji := string(data[messageReactionHeaderSize:])
+
+	friendID, found := t.authenticatedFriendID(senderPublicKey, addr)
+	if !found {
+		return nil
+	}
+	t.receiveMessageReaction(friendID, messageID, emoji)
+	return nil
+}
+
+// receiveMessageReaction records a reaction and invokes the reaction callback
+// if the reacted-to message is known to the message manager.
+func (t *Tox) receiveMessageReaction(friendID, messageID uint32, emoji string) {
+	t.messageManagerMu.RLock()
+	mm := t.messageManager
+	t.messageManagerMu.RUnlock()
+	if mm == nil || !mm.AddReaction(friendID, messageID, emoji) {
+		return
+	}
+
+	t.callbackMu.RLock()
+	callback := t.messageReactionCallback
+	t.callbackMu.RUnlock()
+	if callback != nil {
+		callback(friendID, messageID, emoji)
+	}
+}
+
+// SendEditPacket sends a signed message edit to a friend. It implements
+// messaging.EditTransport. Packet format: [SENDER_PK(32)][EDIT...], where the
+// edit is encoded by messaging.MessageEdit.MarshalBinary.
+func (t *Tox) SendEditPacket(friendID uint32, edit *messaging.MessageEdit) error {
+	friend, err := t.validateFriendOnline(friendID, "friend is not online")
+	if err != nil {
+		return err
+	}
+
+	encoded, err := edit.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	packet := make([]byte, 32+len(encoded))
+	copy(packet[:32], t.keyPair.Public[:])
+	copy(packet[32:], encoded)
+
+	friendAddr, err := t.resolveFriendAddress(friend)
+	if err != nil {
+		return fmt.Errorf("failed to resolve friend address: %w", err)
+	}
+	if t.udpTransport == nil {
+		return errors.New("transport not available")
+	}
+
+	transportPacket := &transport.Packet{
+		PacketType: transport.PacketMessageEdit,
+		Data:       packet,
+	}
+	if err := t.udpTransport.Send(transportPacket, friendAddr); err != nil {
+		return fmt.Errorf("failed to send edit: %w", err)
+	}
+	return nil
+}
+
+// handleMessageEditPacket processes incoming message edit packets from the
+// transport layer and hands them to the message manager, which checks that
+// the friend sealed and signed the edit and fires its OnMessageEdit callback.
+// Edits from unknown senders, or whose claimed key does not match the friend
+// at the source address, are dropped silently.
+func (t *Tox) handleMessageEditPacket(packet *transport.Packet, addr net.Addr) error {
+	data := packet.Data
+	if len(data) < 32 {
+		return errors.New("message edit packet too small")
+	}
+
+	var senderPublicKey [32]byte
+	copy(senderPublicKey[:], data[:32])
+	var edit messaging.MessageEdit
+	if err := edit.UnmarshalBinary(data[32:]); err != nil {
+		return err
+	}
+
+	friendID, found := t.authenticatedFriendID(senderPublicKey, addr)
+	if !found {
+		return nil
+	}
+
+	t.messageManagerMu.RLock()
+	mm := t.messageManager
+	t.messageManagerMu.RUnlock()
+	if mm == nil {
+		return nil
+	}
+	return mm.HandleMessageEdit(friendID, &edit)
+}
@@ -254,7 +254,14 @@ func (t *Tox) registerUDPHandlers() {
 	t.udpTransport.RegisterHandler(transport.PacketPingResponse, t.handlePingResponse)
 	t.udpTransport.RegisterHandler(transport.PacketGetNodes, t.handleGetNodes)
 	t.udpTransport.RegisterHandler(transport.PacketSendNodes, t.handleSendNodes)
+	t.udpTransport.RegisterHandler(transport.PacketMigrationNotify, t.handleMigrationNotify)
 	// Register more handlers here
+
+	// Migration notifications sent on network changes identify us by our
+	// DHT key so peers can update their routing table entry.
+	if udp := extractUDPTransport(t.udpTransport); udp != nil {
+		udp.SetMigrationIdentity(t.keyPair.Public)
+	}
 }
 
 // registerTCPHandlers registers packet handlers for TCP transport.
@@ -302,6 +309,15 @@ func (t *Tox) handleSendNodes(packet *transport.Packet, addr net.Addr) error {
 	return bm.HandlePacket(packet, addr)
 }
 
+// handleMigrationNotify processes peer address migration packets.
+func (t *Tox) handleMigrationNotify(packet *transport.Packet, addr net.Addr) error {
+	bm := t.snapshotBootstrapManager()
+	if bm == nil {
+		return nil
+	}
+	return bm.HandlePacket(packet, addr)
+}
+
 // validateBootstrapPublicKey validates the public key format and hex encoding.
 func validateBootstrapPublicKey(publicKeyHex, address string, port uint16) error {
 	if len(publicKeyHex) != 64 {
@@ -385,6 +401,18 @@ func (t *Tox) Bootstrap(address string, port uint16, publicKeyHex string) error
 	return t.executeBootstrapProcess(address, port)
 }
 
+// AddBootstrapDomain registers a domain whose DNS TXT records list bootstrap
+// nodes as "host:port:pubkey". The domain is resolved by the next bootstrap
+// and again after failed attempts; see dht.BootstrapManager.AddBootstrapDomain.
+// Domains are not resolved while packet delivery runs in simulation mode.
+func (t *Tox) AddBootstrapDomain(domain string) error {
+	bm := t.snapshotBootstrapManager()
+	if bm == nil {
+		return errors.New("bootstrap manager not initialized")
+	}
+	return bm.AddBootstrapDomain(domain)
+}
+
 // addBootstrapNode adds a bootstrap node to the manager.
 func (t *Tox) addBootstrapNode(addr net.Addr, publicKeyHex string) error {
 	logrus.WithFields(logrus.Fields{
@@ -669,6 +697,8 @@ func registerPacketHandlers(udpTransport transport.Transport, tox *Tox) {
 	if udpTransport != nil {
 		udpTransport.RegisterHandler(transport.PacketFriendMessage, tox.handleFriendMessagePacket)
 		udpTransport.RegisterHandler(transport.PacketFriendRequest, tox.handleFriendRequestPacket)
+		udpTransport.RegisterHandler(transport.PacketMessageReaction, tox.handleMessageReactionPacket)
+		udpTransport.RegisterHandler(transport.PacketMessageEdit, tox.handleMessageEditPacket)
 	}
 }
 
@@ -721,6 +751,27 @@ func (t *Tox) resolveFriendIDFromAddress(addr net.Addr) (uint32, error) {
 	return 0, fmt.Errorf("no friend found for address: %s", addr.String())
 }
 
+// authenticatedFriendID returns the friend a packet claiming to come from
+// publicKey was sent by. The claim is only accepted when the source address
+// resolves to the same friend, so a packet naming another friend's key from
+// a different address is dropped.
+func (t *Tox) authenticatedFriendID(publicKey [32]byte, addr net.Addr) (uint32, bool) {
+	friendID, found := t.getFriendIDByPublicKey(publicKey)
+	if !found || addr == nil {
+		return 0, false
+	}
+	sourceID, err := t.resolveFriendIDFromAddress(addr)
+	if err != nil || sourceID != friendID {
+		logrus.WithFields(logrus.Fields{
+			"function":  "authenticatedFriendID",
+			"friend_id": friendID,
+			"address":   addr.String(),
+		}).Debug("Dropping packet whose sender key does not match its source address")
+		return 0, false
+	}
+	return friendID, true
+}
+
 // snapshotDHT returns the current DHT routing table under lock.
 func (t *Tox) snapshotDHT() *dht.RoutingTable {
 	t.dhtMutex.RLock()
@@ -1000,8 +1051,14 @@ func (t *Tox) loadDelivery() interfaces.IPacketDelivery {
 }
 
 // storeDelivery replaces the packet delivery implementation under a write lock.
+// DNS bootstrap follows the delivery mode, as simulated networks cannot reach
+// the resolved nodes.
 func (t *Tox) storeDelivery(d interfaces.IPacketDelivery) {
 	t.deliveryMu.Lock()
-	defer t.deliveryMu.Unlock()
 	t.packetDelivery = d
+	t.deliveryMu.Unlock()
+
+	if bm := t.snapshotBootstrapManager(); bm != nil {
+		bm.SetDNSBootstrapEnabled(!d.IsSimulation())
+	}
 }
@@ -19,3 +19,32 @@ func TestWrapWithProxyIfConfiguredFailsWhenSocks5UDPProxyIsRequired(t *testing.T
 		t.Fatal("expected no transport on fatal SOCKS5 UDP proxy setup failure")
 	}
 }
+
+func TestDNSBootstrapFollowsPacketDeliveryMode(t *testing.T) {
+	tox, err := New(NewOptionsForTesting())
+	if err != nil {
+		t.Fatalf("Failed to create Tox instance: %v", err)
+	}
+	defer tox.Kill()
+
+	if err := tox.AddBootstrapDomain("nodes.example.org"); err != nil {
+		t.Fatalf("AddBootstrapDomain failed: %v", err)
+	}
+	bm := tox.snapshotBootstrapManager()
+	if bm.IsDNSBootstrapEnabled() == tox.IsPacketDeliverySimulation() {
+		t.Fatal("DNS bootstrap must be disabled exactly in simulation mode")
+	}
+
+	if err := tox.SetPacketDeliveryMode(true); err != nil {
+		t.Fatalf("SetPacketDeliveryMode failed: %v", err)
+	}
+	if bm.IsDNSBootstrapEnabled() {
+		t.Error("DNS bootstrap must be disabled in simulation mode")
+	}
+	if err := tox.SetPacketDeliveryMode(false); err != nil {
+		t.Fatalf("SetPacketDeliveryMode failed: %v", err)
+	}
+	if !bm.IsDNSBootstrapEnabled() {
+		t.Error("DNS bootstrap must be re-enabled with real packet delivery")
+	}
+}
@@ -0,0 +1,110 @@
+package toxcore
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Savedata passphrase length bounds enforced by Options.Validate.
+const (
+	// MinSavedataPassphraseLength is the shortest accepted SavedataPassphrase.
+	MinSavedataPassphraseLength = 8
+	// MaxSavedataPassphraseLength is the longest accepted SavedataPassphrase.
+	MaxSavedataPassphraseLength = 1024
+)
+
+// Option validation errors returned by Options.Validate.
+var (
+	// ErrInvalidProxyType is returned for a proxy type other than the
+	// ProxyType constants.
+	ErrInvalidProxyType = errors.New("invalid proxy type")
+	// ErrMissingProxyHost is returned when a proxy is enabled without a host.
+	ErrMissingProxyHost = errors.New("proxy host is required when a proxy type is set")
+	// ErrInvalidProxyPort is returned when a proxy is enabled with port 0.
+	ErrInvalidProxyPort = errors.New("proxy port must be between 1 and 65535")
+	// ErrInvalidSavedataType is returned for a savedata type other than the
+	// SaveDataType constants.
+	ErrInvalidSavedataType = errors.New("invalid savedata type")
+	// ErrSavedataMismatch is returned when SavedataData is missing or does
+	// not match SavedataType or SavedataLength.
+	ErrSavedataMismatch = errors.New("savedata does not match savedata type or length")
+	// ErrInvalidSavedataPassphrase is returned when SavedataPassphrase is
+	// set but shorter than MinSavedataPassphraseLength or longer than
+	// MaxSavedataPassphraseLength.
+	ErrInvalidSavedataPassphrase = errors.New("invalid savedata passphrase length")
+	// ErrInvalidBootstrapTimeout is returned for a negative BootstrapTimeout.
+	ErrInvalidBootstrapTimeout = errors.New("bootstrap timeout must not be negative")
+	// ErrInvalidMinBootstrapNodes is returned for a negative MinBootstrapNodes.
+	ErrInvalidMinBootstrapNodes = errors.New("minimum bootstrap nodes must not be negative")
+)
+
+// Validate checks the options for conflicting or out-of-range settings, so
+// misconfiguration is reported before New starts any transport. Each failure
+// wraps one of the Err* sentinels above for use with errors.Is. The zero
+// value and the result of NewOptions are valid.
+//
+// TCPPort needs no check: 0 disables the TCP transport and every other
+// uint16 is a valid port.
+func (o *Options) Validate() error {
+	if o == nil {
+		return nil
+	}
+	if err := o.validateProxy(); err != nil {
+		return err
+	}
+	if err := o.validateSavedata(); err != nil {
+		return err
+	}
+	if o.BootstrapTimeout < 0 {
+		return fmt.Errorf("%w: %v", ErrInvalidBootstrapTimeout, o.BootstrapTimeout)
+	}
+	if o.MinBootstrapNodes < 0 {
+		return fmt.Errorf("%w: %d", ErrInvalidMinBootstrapNodes, o.MinBootstrapNodes)
+	}
+	return nil
+}
+
+// validateProxy checks that an enabled proxy has a known type, a host and a port.
+func (o *Options) validateProxy() error {
+	if o.Proxy == nil || o.Proxy.Type == ProxyTypeNone {
+		return nil
+	}
+	if o.Proxy.Type != ProxyTypeHTTP && o.Proxy.Type != ProxyTypeSOCKS5 {
+		return fmt.Errorf("%w: %d", ErrInvalidProxyType, o.Proxy.Type)
+	}
+	if o.Proxy.Host == "" {
+		return ErrMissingProxyHost
+	}
+	if o.Proxy.Port == 0 {
+		return fmt.Errorf("%w: got 0", ErrInvalidProxyPort)
+	}
+	return nil
+}
+
+// validateSavedata checks SavedataData against SavedataType and
+// SavedataLength, and the SavedataPassphrase length.
+func (o *Options) validateSavedata() error {
+	if n := len(o.SavedataPassphrase); n > 0 && (n < MinSavedataPassphraseLength || n > MaxSavedataPassphraseLength) {
+		return fmt.Errorf("%w: %d bytes, want %d to %d", ErrInvalidSavedataPassphrase, n, MinSavedataPassphraseLength, MaxSavedataPassphraseLength)
+	}
+
+	switch o.SavedataType {
+	case SaveDataTypeNone:
+		return nil
+	case SaveDataTypeToxSave:
+		if len(o.SavedataData) == 0 {
+			return fmt.Errorf("%w: savedata type is ToxSave but no data provided", ErrSavedataMismatch)
+		}
+	case SaveDataTypeSecretKey:
+		if len(o.SavedataData) != 32 {
+			return fmt.Errorf("%w: savedata type SecretKey requires exactly 32 bytes, got %d", ErrSavedataMismatch, len(o.SavedataData))
+		}
+	default:
+		return fmt.Errorf("%w: %d", ErrInvalidSavedataType, o.SavedataType)
+	}
+
+	if o.SavedataLength > 0 && int(o.SavedataLength) != len(o.SavedataData) {
+		return fmt.Errorf("%w: SavedataLength is %d but SavedataData has %d bytes", ErrSavedataMismatch, o.SavedataLength, len(o.SavedataData))
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package toxcore
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestOptionsValidate(t *testing.T) {
+	validKey := make([]byte, 32)
+	tests := []struct {
+		name   string
+		modify func(o *Options)
+		want   error
+	}{
+		{"defaults", func(o *Options) {}, nil},
+		{"proxy type none ignores host", func(o *Options) { o.Proxy = &ProxyOptions{Type: ProxyTypeNone} }, nil},
+		{"valid proxy", func(o *Options) { o.Proxy = &ProxyOptions{Type: ProxyTypeSOCKS5, Host: "127.0.0.1", Port: 9050} }, nil},
+		{"unknown proxy type", func(o *Options) { o.Proxy = &ProxyOptions{Type: ProxyType(9), Host: "127.0.0.1", Port: 9050} }, ErrInvalidProxyType},
+		{"proxy without host", func(o *Options) { o.Proxy = &ProxyOptions{Type: ProxyTypeHTTP, Port: 8080} }, ErrMissingProxyHost},
+		{"proxy without port", func(o *Options) { o.Proxy = &ProxyOptions{Type: ProxyTypeHTTP, Host: "127.0.0.1"} }, ErrInvalidProxyPort},
+		{"unknown savedata type", func(o *Options) { o.SavedataType = SaveDataType(255) }, ErrInvalidSavedataType},
+		{"toxsave without data", func(o *Options) { o.SavedataType = SaveDataTypeToxSave }, ErrSavedataMismatch},
+		{"secret key of wrong size", func(o *Options) {
+			o.SavedataType = SaveDataTypeSecretKey
+			o.SavedataData = validKey[:16]
+		}, ErrSavedataMismatch},
+		{"savedata length mismatch", func(o *Options) {
+			o.SavedataType = SaveDataTypeSecretKey
+			o.SavedataData = validKey
+			o.SavedataLength = 31
+		}, ErrSavedataMismatch},
+		{"secret key", func(o *Options) {
+			o.SavedataType = SaveDataTypeSecretKey
+			o.SavedataData = validKey
+			o.SavedataLength = 32
+		}, nil},
+		{"short passphrase", func(o *Options) { o.SavedataPassphrase = []byte("short") }, ErrInvalidSavedataPassphrase},
+		{"long passphrase", func(o *Options) {
+			o.SavedataPassphrase = bytes.Repeat([]byte{'a'}, MaxSavedataPassphraseLength+1)
+		}, ErrInvalidSavedataPassphrase},
+		{"negative bootstrap timeout", func(o *Options) { o.BootstrapTimeout = -time.Second }, ErrInvalidBootstrapTimeout},
+		{"negative minimum bootstrap nodes", func(o *Options) { o.MinBootstrapNodes = -1 }, ErrInvalidMinBootstrapNodes},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := NewOptions()
+			tt.modify(options)
+			err := options.Validate()
+			if tt.want == nil {
+				if err != nil {
+					t.Errorf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Validate() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestOptionsValidateZeroValues(t *testing.T) {
+	var nilOptions *Options
+	if err := nilOptions.Validate(); err != nil {
+		t.Errorf("nil options: %v", err)
+	}
+	if err := (&Options{}).Validate(); err != nil {
+		t.Errorf("zero options: %v", err)
+	}
+	if err := NewOptionsForTesting().Validate(); err != nil {
+		t.Errorf("testing options: %v", err)
+	}
+}
+
+func TestNewRejectsInvalidOptions(t *testing.T) {
+	options := NewOptionsForTesting()
+	options.Proxy = &ProxyOptions{Type: ProxyTypeSOCKS5, Host: "127.0.0.1"}
+	if _, err := New(options); !errors.Is(err, ErrInvalidProxyPort) {
+		t.Errorf("New() = %v, want ErrInvalidProxyPort", err)
+	}
+
+	options = NewOptionsForTesting()
+	options.SavedataPassphrase = []byte("short")
+	if _, err := NewFromSavedata(options, []byte("{}")); !errors.Is(err, ErrInvalidSavedataPassphrase) {
+		t.Errorf("NewFromSavedata() = %v, want ErrInvalidSavedataPassphrase", err)
+	}
+	if !bytes.Equal(options.SavedataPassphrase, []byte("short")) {
+		t.Error("Options must be validated before the passphrase is consumed")
+	}
+}
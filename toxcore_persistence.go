@@ -385,6 +385,7 @@ func cloneFriendEntry(friend *Friend) *Friend {
 		Name:                 friend.Name,
 		StatusMessage:        friend.StatusMessage,
 		LastSeen:             friend.LastSeen,
+		AddedAt:              friend.AddedAt,
 		IsTyping:             friend.IsTyping,
 		DisappearingMessages: friend.DisappearingMessages,
 	}
@@ -0,0 +1,271 @@
+package toxcore
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/opd-ai/toxcore/crypto"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/crypto/argon2"
+)
+
+// Encrypted savedata format constants
+const (
+	// SavedataEncryptedMagic identifies passphrase-encrypted savedata
+	SavedataEncryptedMagic uint32 = 0x544F5845 // "TOXE"
+	// SavedataEncryptionVersion is the current encrypted savedata format version
+	SavedataEncryptionVersion uint8 = 1
+
+	// savedataSaltSize is the size of the Argon2id salt stored in the header.
+	savedataSaltSize = crypto.SaltSize
+	// savedataNonceSize is the size of the AES-GCM nonce stored in the header.
+	savedataNonceSize = 12
+	// savedataHeaderSize is the size of the header preceding the ciphertext:
+	// [4B magic][1B version][4B time][4B memory KiB][1B threads][32B salt][12B nonce].
+	// The whole header is authenticated as additional data.
+	savedataHeaderSize = 4 + 1 + 4 + 4 + 1 + savedataSaltSize + savedataNonceSize
+
+	// Upper bounds on Argon2id parameters read from a header, so crafted
+	// savedata cannot make New compute or allocate without limit.
+	maxSavedataArgon2idTime   = 64
+	maxSavedataArgon2idMemory = 1024 * 1024 // 1 GiB
+)
+
+var (
+	// ErrWrongPassphrase is returned when encrypted savedata fails
+	// authentication, meaning the passphrase is wrong or the data was modified.
+	ErrWrongPassphrase = errors.New("wrong savedata passphrase or corrupted savedata")
+	// ErrSavedataEncrypted is returned when encrypted savedata is loaded
+	// without a passphrase.
+	ErrSavedataEncrypted = errors.New("savedata is encrypted but no passphrase was provided")
+)
+
+// IsSavedataEncrypted reports whether data starts with the encrypted
+// savedata magic, as produced by GetSavedata when Options.SavedataPassphrase
+// is set.
+func IsSavedataEncrypted(data []byte) bool {
+	if len(data) < 4 {
+		return false
+	}
+	return binary.BigEndian.Uint32(data[:4]) == SavedataEncryptedMagic
+}
+
+// savedataHeader holds the Argon2id parameters and salt of encrypted savedata.
+type savedataHeader struct {
+	time    uint32
+	memory  uint32 // KiB
+	threads uint8
+	salt    [savedataSaltSize]byte
+}
+
+// marshal encodes the header followed by nonce.
+func (h savedataHeader) marshal(nonce []byte) []byte {
+	buf := make([]byte, 0, savedataHeaderSize)
+	buf = binary.BigEndian.AppendUint32(buf, SavedataEncryptedMagic)
+	buf = append(buf, SavedataEncryptionVersion)
+	buf = binary.BigEndian.AppendUint32(buf, h.time)
+	buf = binary.BigEndian.AppendUint32(buf, h.memory)
+	buf = append(buf, h.threads)
+	buf = append(buf, h.salt[:]...)
+	return append(buf, nonce...)
+}
+
+// parseSavedataHeader decodes and validates the header of encrypted savedata.
+func parseSavedataHeader(data []byte) (savedataHeader, error) {
+	var h savedataHeader
+	if !IsSavedataEncrypted(data) {
+		return h, errors.New("not encrypted savedata")
+	}
+	if len(data) < savedataHeaderSize {
+		return h, fmt.Errorf("encrypted savedata too short: %d bytes", len(data))
+	}
+	if version := data[4]; version != SavedataEncryptionVersion {
+		return h, fmt.Errorf("unsupported encrypted savedata version: %d", version)
+	}
+	h.time = binary.BigEndian.Uint32(data[5:9])
+	h.memory = binary.BigEndian.Uint32(data[9:13])
+	h.threads = data[13]
+	if h.time == 0 || h.time > maxSavedataArgon2idTime ||
+		h.memory < 8*uint32(h.threads) || h.memory > maxSavedataArgon2idMemory || h.threads == 0 {
+		return h, fmt.Errorf("invalid Argon2id parameters: time=%d memory=%d threads=%d", h.time, h.memory, h.threads)
+	}
+	copy(h.salt[:], data[14:14+savedataSaltSize])
+	return h, nil
+}
+
+// savedataSealer encrypts and decrypts savedata with a key derived from the
+// passphrase. It keeps the derived key rather than the passphrase, so every
+// savedata it writes reuses the header the key was derived under. It is
+// guarded by the Tox selfMutex.
+type savedataSealer struct {
+	header savedataHeader
+	key    [32]byte
+	wiped  bool
+}
+
+// newSavedataSealer derives a key from passphrase under header and wipes the
+// passphrase.
+func newSavedataSealer(passphrase []byte, header savedataHeader) (*savedataSealer, error) {
+	derived := argon2.IDKey(passphrase, header.salt[:], header.time, header.memory, header.threads, crypto.Argon2KeyLen)
+	defer crypto.ZeroBytes(derived)
+	if err := crypto.SecureWipe(passphrase); err != nil {
+		return nil, fmt.Errorf("failed to wipe savedata passphrase: %w", err)
+	}
+
+	s := &savedataSealer{header: header}
+	copy(s.key[:], derived)
+	return s, nil
+}
+
+// aead returns the AES-256-GCM cipher for the sealer's key.
+func (s *savedataSealer) aead() (cipher.AEAD, error) {
+	if s.wiped {
+		return nil, errors.New("savedata key has been wiped")
+	}
+	block, err := aes.NewCipher(s.key[:])
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal encrypts plaintext under a fresh nonce.
+func (s *savedataSealer) seal(plaintext []byte) ([]byte, error) {
+	gcm, err := s.aead()
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, savedataNonceSize)
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	header := s.header.marshal(nonce)
+	return gcm.Seal(header, nonce, plaintext, header), nil
+}
+
+// open decrypts encrypted savedata. Data sealed under a different salt or
+// passphrase fails authentication with ErrWrongPassphrase.
+func (s *savedataSealer) open(data []byte) ([]byte, error) {
+	if _, err := parseSavedataHeader(data); err != nil {
+		return nil, err
+	}
+	gcm, err := s.aead()
+	if err != nil {
+		return nil, err
+	}
+	header := data[:savedataHeaderSize]
+	nonce := header[savedataHeaderSize-savedataNonceSize:]
+	plaintext, err := gcm.Open(nil, nonce, data[savedataHeaderSize:], header)
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+	return plaintext, nil
+}
+
+// wipe erases the derived key. Later seal and open calls fail.
+func (s *savedataSealer) wipe() {
+	crypto.ZeroBytes(s.key[:])
+	s.wiped = true
+}
+
+// openSavedata prepares savedata encryption for a new instance. Without a
+// passphrase it returns data unchanged, or ErrSavedataEncrypted if data is
+// encrypted. With one it derives the key, reusing the header of encrypted
+// data or a fresh salt and the crypto.DefaultArgon2id* parameters otherwise,
+// and returns the decrypted data. The passphrase is wiped in both cases.
+func openSavedata(passphrase, data []byte) (*savedataSealer, []byte, error) {
+	encrypted := IsSavedataEncrypted(data)
+	if len(passphrase) == 0 {
+		if encrypted {
+			return nil, nil, ErrSavedataEncrypted
+		}
+		return nil, data, nil
+	}
+
+	header := savedataHeader{
+		time:    crypto.DefaultArgon2idTime,
+		memory:  crypto.DefaultArgon2idMemory,
+		threads: crypto.DefaultArgon2idThreads,
+	}
+	if encrypted {
+		var err error
+		if header, err = parseSavedataHeader(data); err != nil {
+			crypto.ZeroBytes(passphrase)
+			return nil, nil, err
+		}
+	} else if _, err := io.ReadFull(rand.Reader, header.salt[:]); err != nil {
+		crypto.ZeroBytes(passphrase)
+		return nil, nil, fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	sealer, err := newSavedataSealer(passphrase, header)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !encrypted {
+		return sealer, data, nil
+	}
+
+	plaintext, err := sealer.open(data)
+	if err != nil {
+		sealer.wipe()
+		return nil, nil, err
+	}
+	logrus.WithFields(logrus.Fields{
+		"function":        "openSavedata",
+		"savedata_length": len(plaintext),
+	}).Debug("Decrypted savedata")
+	return sealer, plaintext, nil
+}
+
+// prepareSavedataEncryption derives the savedata key from
+// options.SavedataPassphrase and decrypts encrypted ToxSave data. When the
+// data is decrypted, the returned options are a copy holding the plaintext so
+// the caller's options are left untouched apart from the wiped passphrase.
+func prepareSavedataEncryption(options *Options) (*Options, *savedataSealer, error) {
+	var data []byte
+	if options.SavedataType == SaveDataTypeToxSave {
+		data = options.SavedataData
+	}
+
+	sealer, plaintext, err := openSavedata(options.SavedataPassphrase, data)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{"function": "New", "error": err.Error()}).Error("Failed to prepare savedata encryption")
+		return nil, nil, err
+	}
+	if IsSavedataEncrypted(data) {
+		decrypted := *options
+		decrypted.SavedataData = plaintext
+		decrypted.SavedataLength = uint32(len(plaintext))
+		options = &decrypted
+	}
+	return options, sealer, nil
+}
+
+// decryptLoadData decrypts encrypted data passed to Load with the key the
+// instance was created with. Plaintext data is returned unchanged.
+func (t *Tox) decryptLoadData(data []byte) ([]byte, error) {
+	if !IsSavedataEncrypted(data) {
+		return data, nil
+	}
+	t.selfMutex.RLock()
+	defer t.selfMutex.RUnlock()
+	if t.savedataSealer == nil {
+		return nil, ErrSavedataEncrypted
+	}
+	return t.savedataSealer.open(data)
+}
+
+// wipeSavedataKey erases the savedata key when the instance is killed.
+func (t *Tox) wipeSavedataKey() {
+	t.selfMutex.Lock()
+	defer t.selfMutex.Unlock()
+	if t.savedataSealer != nil {
+		t.savedataSealer.wipe()
+	}
+}
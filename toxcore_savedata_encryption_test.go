@@ -0,0 +1,179 @@
+package toxcore
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+// newSavedataSource creates an instance with a friend and a name set, and
+// returns it with its savedata.
+func newSavedataSource(t *testing.T, passphrase []byte) (*Tox, []byte) {
+	t.Helper()
+	options := NewOptionsForTesting()
+	options.SavedataPassphrase = passphrase
+	tox, err := New(options)
+	if err != nil {
+		t.Fatalf("Failed to create Tox instance: %v", err)
+	}
+	t.Cleanup(tox.Kill)
+
+	if _, err := tox.AddFriendByPublicKey(testSequentialPublicKey); err != nil {
+		t.Fatalf("Failed to add friend: %v", err)
+	}
+	if err := tox.SelfSetName("Encrypted User"); err != nil {
+		t.Fatalf("Failed to set name: %v", err)
+	}
+
+	savedata := tox.GetSavedata()
+	if len(savedata) == 0 {
+		t.Fatal("GetSavedata returned empty data")
+	}
+	return tox, savedata
+}
+
+// checkRestored verifies that restored matches the state set by newSavedataSource.
+func checkRestored(t *testing.T, original, restored *Tox) {
+	t.Helper()
+	if original.SelfGetPublicKey() != restored.SelfGetPublicKey() {
+		t.Error("Public key was not restored")
+	}
+	if _, err := restored.GetFriendByPublicKey(testSequentialPublicKey); err != nil {
+		t.Errorf("Friend was not restored: %v", err)
+	}
+	if name := restored.SelfGetName(); name != "Encrypted User" {
+		t.Errorf("Self name not restored: got %q", name)
+	}
+}
+
+func TestSavedataEncryptionRoundTrip(t *testing.T) {
+	tests := []struct {
+		name       string
+		passphrase string
+	}{
+		{"plaintext", ""},
+		{"passphrase", "correct horse battery staple"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tox1, savedata := newSavedataSource(t, []byte(tt.passphrase))
+			encrypted := tt.passphrase != ""
+			if IsSavedataEncrypted(savedata) != encrypted {
+				t.Fatalf("IsSavedataEncrypted = %v, want %v", !encrypted, encrypted)
+			}
+			if encrypted && bytes.Contains(savedata, tox1.keyPair.Private[:]) {
+				t.Fatal("Encrypted savedata contains the secret key")
+			}
+
+			// Through Options.SavedataData
+			options := NewOptionsForTesting()
+			options.SavedataType = SaveDataTypeToxSave
+			options.SavedataData = savedata
+			options.SavedataLength = uint32(len(savedata))
+			options.SavedataPassphrase = []byte(tt.passphrase)
+			tox2, err := New(options)
+			if err != nil {
+				t.Fatalf("New failed: %v", err)
+			}
+			defer tox2.Kill()
+			checkRestored(t, tox1, tox2)
+			if encrypted && !bytes.Equal(options.SavedataData, savedata) {
+				t.Error("New modified the caller's SavedataData")
+			}
+
+			// Through NewFromSavedata; saves stay encrypted under the same passphrase
+			options = NewOptionsForTesting()
+			options.SavedataPassphrase = []byte(tt.passphrase)
+			tox3, err := NewFromSavedata(options, tox2.GetSavedata())
+			if err != nil {
+				t.Fatalf("NewFromSavedata failed: %v", err)
+			}
+			defer tox3.Kill()
+			checkRestored(t, tox1, tox3)
+
+			resaved := tox3.GetSavedata()
+			if IsSavedataEncrypted(resaved) != encrypted {
+				t.Error("Resaved data changed encryption")
+			}
+			if err := tox3.Load(resaved); err != nil {
+				t.Errorf("Load of own savedata failed: %v", err)
+			}
+		})
+	}
+}
+
+func TestSavedataEncryptionWrongPassphrase(t *testing.T) {
+	_, savedata := newSavedataSource(t, []byte("right passphrase"))
+
+	options := NewOptionsForTesting()
+	options.SavedataType = SaveDataTypeToxSave
+	options.SavedataData = savedata
+	options.SavedataPassphrase = []byte("wrong passphrase")
+	if _, err := New(options); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("New with wrong passphrase: got %v, want ErrWrongPassphrase", err)
+	}
+
+	options = NewOptionsForTesting()
+	options.SavedataPassphrase = []byte("wrong passphrase")
+	if _, err := NewFromSavedata(options, savedata); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("NewFromSavedata with wrong passphrase: got %v, want ErrWrongPassphrase", err)
+	}
+
+	if _, err := NewFromSavedata(NewOptionsForTesting(), savedata); !errors.Is(err, ErrSavedataEncrypted) {
+		t.Errorf("NewFromSavedata without passphrase: got %v, want ErrSavedataEncrypted", err)
+	}
+
+	// Tampering with the ciphertext fails the same way
+	tampered := append([]byte(nil), savedata...)
+	tampered[len(tampered)-1] ^= 0xFF
+	options = NewOptionsForTesting()
+	options.SavedataPassphrase = []byte("right passphrase")
+	if _, err := NewFromSavedata(options, tampered); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("NewFromSavedata with tampered data: got %v, want ErrWrongPassphrase", err)
+	}
+}
+
+func TestSavedataEncryptionWipesPassphrase(t *testing.T) {
+	passphrase := []byte("secret passphrase")
+	tox, _ := newSavedataSource(t, passphrase)
+	if !bytes.Equal(passphrase, make([]byte, len(passphrase))) {
+		t.Error("Passphrase was not wiped after key derivation")
+	}
+
+	tox.Kill()
+	if data := tox.GetSavedata(); data != nil {
+		t.Error("GetSavedata must not return data once the key is wiped")
+	}
+}
+
+func TestParseSavedataHeaderRejectsInvalidParameters(t *testing.T) {
+	valid := savedataHeader{time: 1, memory: 64 * 1024, threads: 4}.marshal(make([]byte, savedataNonceSize))
+	if _, err := parseSavedataHeader(valid); err != nil {
+		t.Fatalf("Valid header rejected: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		header savedataHeader
+	}{
+		{"zero time", savedataHeader{time: 0, memory: 64 * 1024, threads: 4}},
+		{"excessive memory", savedataHeader{time: 1, memory: maxSavedataArgon2idMemory + 1, threads: 4}},
+		{"zero threads", savedataHeader{time: 1, memory: 64 * 1024, threads: 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := parseSavedataHeader(tt.header.marshal(make([]byte, savedataNonceSize))); err == nil {
+				t.Error("Expected invalid parameters to be rejected")
+			}
+		})
+	}
+
+	badVersion := append([]byte(nil), valid...)
+	badVersion[4] = SavedataEncryptionVersion + 1
+	if _, err := parseSavedataHeader(badVersion); err == nil {
+		t.Error("Expected unsupported version to be rejected")
+	}
+	if _, err := parseSavedataHeader(valid[:savedataHeaderSize-1]); err == nil {
+		t.Error("Expected truncated header to be rejected")
+	}
+}
@@ -1,17 +1,27 @@
 package toxcore
 
 import (
+	"bytes"
+	"context"
 	"crypto/rand"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/opd-ai/toxcore/async"
 	"github.com/opd-ai/toxcore/crypto"
+	"github.com/opd-ai/toxcore/dht"
 	"github.com/opd-ai/toxcore/file"
 	"github.com/opd-ai/toxcore/friend"
 	"github.com/opd-ai/toxcore/messaging"
@@ -231,6 +241,220 @@ func TestAddFriendByPublicKey(t *testing.T) {
 	t.Log("AddFriendByPublicKey test passed")
 }
 
+func TestExportFriendListVCard(t *testing.T) {
+	tox, err := New(NewOptionsForTesting())
+	if err != nil {
+		t.Fatalf("Failed to create Tox instance: %v", err)
+	}
+	defer tox.Kill()
+
+	keys := [][32]byte{{1}, {2}, {3}}
+	for i, pk := range keys {
+		friendID, err := tox.AddFriendByPublicKey(pk)
+		if err != nil {
+			t.Fatalf("Failed to add friend: %v", err)
+		}
+		tox.friends.Update(friendID, func(f *Friend) {
+			f.Name = "friend-" + string(rune('a'+i))
+		})
+	}
+
+	data, err := tox.ExportFriendList()
+	if err != nil {
+		t.Fatalf("ExportFriendList failed: %v", err)
+	}
+
+	cards, err := friend.ParseVCards(data)
+	if err != nil {
+		t.Fatalf("ParseVCards failed: %v", err)
+	}
+	if len(cards) != len(keys) {
+		t.Fatalf("Expected %d vCards, got %d", len(keys), len(cards))
+	}
+	for i, card := range cards {
+		if card.PublicKey != keys[i] {
+			t.Errorf("card %d: public key out of order", i)
+		}
+		if want := "friend-" + string(rune('a'+i)); card.GetName() != want {
+			t.Errorf("card %d: name = %q, want %q", i, card.GetName(), want)
+		}
+	}
+}
+
+func TestImportExportFriends(t *testing.T) {
+	tox, err := New(NewOptionsForTesting())
+	if err != nil {
+		t.Fatalf("Failed to create Tox instance: %v", err)
+	}
+	defer tox.Kill()
+
+	existing := [32]byte{7}
+	if _, err := tox.AddFriendByPublicKey(existing); err != nil {
+		t.Fatalf("AddFriendByPublicKey failed: %v", err)
+	}
+
+	newKey := [32]byte{8}
+	requested := crypto.NewToxID([32]byte{9}, [4]byte{1, 2, 3, 4})
+	input := fmt.Sprintf(`[
+		{"public_key": "%x"},
+		{"public_key": "%x"},
+		{"public_key": "%s", "message": "hi from the bridge"},
+		{"public_key": "not-hex"}
+	]`, existing[:], newKey[:], requested.String())
+
+	added, skipped, failed, err := tox.ImportFriends(strings.NewReader(input))
+	if added != 2 || skipped != 1 || failed != 1 {
+		t.Errorf("ImportFriends = (%d, %d, %d), want (2, 1, 1)", added, skipped, failed)
+	}
+	if err == nil || !strings.Contains(err.Error(), "entry 3") {
+		t.Errorf("Expected aggregated error for entry 3, got %v", err)
+	}
+	if tox.GetFriendsCount() != 3 {
+		t.Errorf("Expected 3 friends, got %d", tox.GetFriendsCount())
+	}
+
+	var backup bytes.Buffer
+	if err := tox.ExportFriends(&backup); err != nil {
+		t.Fatalf("ExportFriends failed: %v", err)
+	}
+
+	restored, err := New(NewOptionsForTesting())
+	if err != nil {
+		t.Fatalf("Failed to create Tox instance: %v", err)
+	}
+	defer restored.Kill()
+
+	added, skipped, failed, err = restored.ImportFriends(&backup)
+	if err != nil || added != 3 || skipped != 0 || failed != 0 {
+		t.Errorf("Round-trip import = (%d, %d, %d, %v), want (3, 0, 0, nil)", added, skipped, failed, err)
+	}
+	for _, pk := range [][32]byte{existing, newKey, requested.PublicKey} {
+		if _, exists := restored.getFriendIDByPublicKey(pk); !exists {
+			t.Errorf("Friend %x missing after round trip", pk[:4])
+		}
+	}
+
+	if _, _, _, err := tox.ImportFriends(strings.NewReader("not json")); err == nil {
+		t.Error("Expected error for malformed JSON")
+	}
+}
+
+func TestFriendBackupRoundTrip(t *testing.T) {
+	tox, err := New(NewOptionsForTesting())
+	if err != nil {
+		t.Fatalf("Failed to create Tox instance: %v", err)
+	}
+	defer tox.Kill()
+
+	for i, pk := range [][32]byte{{1}, {2}, {3}} {
+		friendID, err := tox.AddFriendByPublicKey(pk)
+		if err != nil {
+			t.Fatalf("Failed to add friend: %v", err)
+		}
+		tox.friends.Update(friendID, func(f *Friend) {
+			f.Name = "friend-" + string(rune('a'+i))
+			f.StatusMessage = "status " + string(rune('a'+i))
+			f.AddedAt = time.Date(2024, 1, i+1, 12, 0, 0, 0, time.UTC)
+		})
+	}
+
+	exported, err := tox.ExportFriendBackup()
+	if err != nil {
+		t.Fatalf("ExportFriendBackup failed: %v", err)
+	}
+	data, err := exported.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+
+	restored, err := New(NewOptionsForTesting())
+	if err != nil {
+		t.Fatalf("Failed to create Tox instance: %v", err)
+	}
+	defer restored.Kill()
+	if _, err := restored.AddFriendByPublicKey([32]byte{2}); err != nil {
+		t.Fatalf("Failed to add friend: %v", err)
+	}
+
+	var fl friend.FriendList
+	if err := fl.Unmarshal(data); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	imported, skipped, err := restored.ImportFriendBackup(&fl)
+	if err != nil || imported != 2 || skipped != 1 {
+		t.Fatalf("ImportFriendBackup = (%d, %d, %v), want (2, 1, nil)", imported, skipped, err)
+	}
+
+	// Replace the pre-existing friend's details so the lists can be compared
+	id, _ := restored.getFriendIDByPublicKey([32]byte{2})
+	restored.friends.Update(id, func(f *Friend) {
+		f.Name, f.StatusMessage, f.AddedAt = exported.Friends[1].Name, exported.Friends[1].StatusMessage, exported.Friends[1].AddedAt
+	})
+
+	roundTrip, err := restored.ExportFriendBackup()
+	if err != nil {
+		t.Fatalf("ExportFriendBackup failed: %v", err)
+	}
+	got := make(map[[32]byte]*friend.FriendInfo)
+	for _, f := range roundTrip.Friends {
+		got[f.PublicKey] = f
+	}
+	if len(got) != len(exported.Friends) {
+		t.Fatalf("Expected %d friends, got %d", len(exported.Friends), len(got))
+	}
+	for _, want := range exported.Friends {
+		f := got[want.PublicKey]
+		if f == nil || f.Name != want.Name || f.StatusMessage != want.StatusMessage || !f.AddedAt.Equal(want.AddedAt) {
+			t.Errorf("Friend %x differs after round trip: %+v", want.PublicKey[:4], f)
+		}
+	}
+
+	if _, _, err := restored.ImportFriendBackup(nil); err == nil {
+		t.Error("Expected error for a nil friend list")
+	}
+}
+
+func TestBlockFriendDropsFriendRequests(t *testing.T) {
+	tox, err := New(NewOptionsForTesting())
+	if err != nil {
+		t.Fatalf("Failed to create Tox instance: %v", err)
+	}
+	defer tox.Kill()
+
+	var requests []string
+	tox.OnFriendRequest(func(publicKey [32]byte, message string) {
+		requests = append(requests, message)
+	})
+
+	blocked := [32]byte{11}
+	tox.receiveFriendRequest(blocked, "pending")
+	if err := tox.BlockFriend(blocked); err != nil {
+		t.Fatalf("BlockFriend failed: %v", err)
+	}
+	if err := tox.BlockFriend(blocked); !errors.Is(err, friend.ErrAlreadyBlocked) {
+		t.Errorf("Expected ErrAlreadyBlocked, got %v", err)
+	}
+	if tox.RequestManager().PendingCount() != 0 {
+		t.Error("Blocking should discard the pending request")
+	}
+
+	tox.receiveFriendRequest(blocked, "spam")
+	if len(requests) != 1 {
+		t.Errorf("Blocked sender reached the callback: %v", requests)
+	}
+
+	if err := tox.UnblockFriend(blocked); err != nil {
+		t.Fatalf("UnblockFriend failed: %v", err)
+	}
+	if err := tox.UnblockFriend(blocked); !errors.Is(err, friend.ErrNotBlocked) {
+		t.Errorf("Expected ErrNotBlocked, got %v", err)
+	}
+	tox.receiveFriendRequest(blocked, "sorry")
+	if len(requests) != 2 {
+		t.Errorf("Unblocked sender should reach the callback: %v", requests)
+	}
+}
+
 // TestDocumentedAPICompatibility tests the exact API usage shown in README.md
 func TestDocumentedAPICompatibility(t *testing.T) {
 	options := NewOptionsForTesting()
@@ -319,6 +543,259 @@ func TestMessageFromUnknownFriend(t *testing.T) {
 	t.Log("Unknown friend message filtering test passed")
 }
 
+// TestMessageReactionPacket tests that reaction packets reach the callback and
+// the message manager, and that reactions to unknown messages are dropped.
+func TestMessageReactionPacket(t *testing.T) {
+	options := NewOptionsForTesting()
+	tox, err := New(options)
+	if err != nil {
+		t.Fatalf("Failed to create Tox instance: %v", err)
+	}
+	defer tox.Kill()
+
+	friendPK := [32]byte{4, 5, 6}
+	tox.friends.Set(1, &Friend{
+		PublicKey:        friendPK,
+		Status:           FriendStatusOnline,
+		ConnectionStatus: ConnectionUDP,
+		LastSeen:         time.Now(),
+	})
+	friendAddr := &net.UDPAddr{IP: net.IPv4(192, 0, 2, 7), Port: 33445}
+	tox.dht.AddNode(dht.NewNode(*crypto.NewToxID(friendPK, [4]byte{}), friendAddr))
+
+	msg, err := tox.messageManager.SendMessage(1, "react to me", messaging.MessageTypeNormal)
+	if err != nil {
+		t.Fatalf("SendMessage failed: %v", err)
+	}
+
+	var calls int
+	var gotMessageID uint32
+	var gotEmoji string
+	tox.OnMessageReaction(func(friendID, messageID uint32, emoji string) {
+		calls++
+		gotMessageID = messageID
+		gotEmoji = emoji
+	})
+
+	buildPacket := func(sender [32]byte, messageID uint32, emoji string) *transport.Packet {
+		data := append(sender[:], 0, 0, 0, 0)
+		binary.BigEndian.PutUint32(data[32:36], messageID)
+		return &transport.Packet{PacketType: transport.PacketMessageReaction, Data: append(data, emoji...)}
+	}
+
+	// The sender is taken from the source address, not the claimed key.
+	otherAddr := &net.UDPAddr{IP: net.IPv4(192, 0, 2, 8), Port: 33445}
+	if err := tox.handleMessageReactionPacket(buildPacket(friendPK, msg.ID, "👍"), otherAddr); err != nil {
+		t.Errorf("reaction from another address should be dropped silently, got %v", err)
+	}
+	if calls != 0 {
+		t.Fatalf("reaction claiming the friend's key from another address fired the callback")
+	}
+
+	if err := tox.handleMessageReactionPacket(buildPacket(friendPK, msg.ID, "🎉"), friendAddr); err != nil {
+		t.Fatalf("handleMessageReactionPacket failed: %v", err)
+	}
+	if calls != 1 || gotMessageID != msg.ID || gotEmoji != "🎉" {
+		t.Errorf("callback got %d calls (%d, %q), want 1 call (%d, \"🎉\")", calls, gotMessageID, gotEmoji, msg.ID)
+	}
+	if reactions := tox.GetMessageReactions(msg.ID); len(reactions) != 1 || reactions[0].SenderID != 1 {
+		t.Errorf("GetMessageReactions() = %v, want one reaction from friend 1", reactions)
+	}
+
+	// Unknown message and unknown sender are discarded silently
+	if err := tox.handleMessageReactionPacket(buildPacket(friendPK, msg.ID+100, "🎉"), friendAddr); err != nil {
+		t.Errorf("unknown message should be dropped silently, got %v", err)
+	}
+	if err := tox.handleMessageReactionPacket(buildPacket([32]byte{9}, msg.ID, "👍"), friendAddr); err != nil {
+		t.Errorf("unknown sender should be dropped silently, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("callback fired %d times, want 1", calls)
+	}
+
+	// Reactions to the friend's own messages use the friend's message ID.
+	tox.messageManager.ReceiveMessage(1, 900, "theirs", messaging.MessageTypeNormal)
+	if err := tox.handleMessageReactionPacket(buildPacket(friendPK, 900, "👍"), friendAddr); err != nil {
+		t.Fatalf("handleMessageReactionPacket failed: %v", err)
+	}
+	if reactions := tox.GetReceivedMessageReactions(1, 900); len(reactions) != 1 || reactions[0].Emoji != "👍" {
+		t.Errorf("GetReceivedMessageReactions() = %v, want one 👍", reactions)
+	}
+
+	if err := tox.ReactToMessage(1, msg.ID, "too long emoji"); !errors.Is(err, messaging.ErrInvalidReaction) {
+		t.Errorf("ReactToMessage with oversized emoji error = %v, want ErrInvalidReaction", err)
+	}
+}
+
+// TestFriendMessageReplyCallback tests that the reply-to ID is parsed from
+// friend message packets and that callbacks with the older signatures still fire.
+func TestFriendMessageReplyCallback(t *testing.T) {
+	options := NewOptionsForTesting()
+	tox, err := New(options)
+	if err != nil {
+		t.Fatalf("Failed to create Tox instance: %v", err)
+	}
+	defer tox.Kill()
+
+	tox.friends.Set(1, &Friend{
+		PublicKey:        [32]byte{1, 2, 3},
+		Status:           FriendStatusOnline,
+		ConnectionStatus: ConnectionUDP,
+		LastSeen:         time.Now(),
+	})
+
+	var simpleCalls, detailedCalls int
+	var gotReplyToID uint32
+	var gotMessage string
+	tox.OnFriendMessage(func(friendID uint32, message string) { simpleCalls++ })
+	tox.OnFriendMessageDetailed(func(friendID uint32, message string, messageType MessageType) { detailedCalls++ })
+	tox.OnFriendMessageReply(func(friendID uint32, message string, messageType MessageType, replyToID uint32) {
+		gotMessage = message
+		gotReplyToID = replyToID
+	})
+
+	var gotMsg *messaging.Message
+	tox.OnFriendMessageWithReply(func(friendID uint32, msg *messaging.Message) { gotMsg = msg })
+
+	// [TYPE(1)][FRIEND_ID(4)][MESSAGE_TYPE(1)][MESSAGE_ID(4)][REPLY_TO_ID(4)][REPLY_SUMMARY(64)][MESSAGE...]
+	packet := []byte{0x01, 0, 0, 0, 1, byte(MessageTypeNormal), 0, 0, 0, 7, 0, 0, 0, 42}
+	summary, _ := (&messaging.ReplySummary{Author: messaging.ReplyAuthorRecipient, Text: "original"}).MarshalBinary()
+	packet = append(packet, summary...)
+	packet = append(packet, "threaded"...)
+	if err := tox.processFriendMessagePacket(packet, nil); err != nil {
+		t.Fatalf("processFriendMessagePacket failed: %v", err)
+	}
+
+	if gotMessage != "threaded" || gotReplyToID != 42 {
+		t.Errorf("reply callback got (%q, %d), want (\"threaded\", 42)", gotMessage, gotReplyToID)
+	}
+	if simpleCalls != 1 || detailedCalls != 1 {
+		t.Errorf("legacy callbacks fired %d/%d times, want 1/1", simpleCalls, detailedCalls)
+	}
+	if gotMsg == nil || gotMsg.GetID() != 7 || gotMsg.GetText() != "threaded" || gotMsg.GetReplyToID() != 42 ||
+		gotMsg.ReplySummary == nil || gotMsg.ReplySummary.Text != "original" ||
+		gotMsg.ReplySummary.Author != messaging.ReplyAuthorRecipient {
+		t.Errorf("OnFriendMessageWithReply got %+v", gotMsg)
+	}
+
+	// The reply is recorded under the sender's message ID, so a reply to it
+	// is attached to the thread root the sender quoted.
+	nested, err := tox.messageManager.SendReply(1, 7, "answer")
+	if err != nil {
+		t.Fatalf("reply to received message failed: %v", err)
+	}
+	if nested.GetReplyToID() != 42 || nested.ReplySummary.Text != "original" {
+		t.Errorf("reply to received reply = %d %+v, want root 42", nested.GetReplyToID(), nested.ReplySummary)
+	}
+
+	tox.receiveFriendMessage(1, "top-level", MessageTypeNormal)
+	if gotReplyToID != 0 {
+		t.Errorf("top-level message replyToID = %d, want 0", gotReplyToID)
+	}
+	if gotMsg.ReplyToID != nil || gotMsg.ReplySummary != nil {
+		t.Errorf("top-level message carried a reply reference: %+v", gotMsg)
+	}
+
+	if err := tox.processFriendMessagePacket(packet[:friendMessageHeaderSize-1], nil); err == nil {
+		t.Error("expected error for truncated packet")
+	}
+	if err := tox.processFriendMessagePacket(packet[:friendMessageHeaderSize+10], nil); err == nil {
+		t.Error("expected error for a reply without a summary")
+	}
+}
+
+// editCapture is a message transport that records the edits it is asked to send.
+type editCapture struct {
+	mu    sync.Mutex
+	edits []*messaging.MessageEdit
+}
+
+func (c *editCapture) SendMessagePacket(uint32, *messaging.Message) error { return nil }
+
+func (c *editCapture) SendEditPacket(_ uint32, edit *messaging.MessageEdit) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.edits = append(c.edits, edit)
+	return nil
+}
+
+// TestMessageEditPacket tests that an edit is applied to a message received
+// from the same friend, and only when it arrives from that friend's address.
+func TestMessageEditPacket(t *testing.T) {
+	receiver, err := New(NewOptionsForTesting())
+	if err != nil {
+		t.Fatalf("Failed to create Tox instance: %v", err)
+	}
+	defer receiver.Kill()
+	sender, err := New(NewOptionsForTesting())
+	if err != nil {
+		t.Fatalf("Failed to create Tox instance: %v", err)
+	}
+	defer sender.Kill()
+
+	senderPK := sender.SelfGetPublicKey()
+	if _, err := receiver.AddFriendByPublicKey(senderPK); err != nil {
+		t.Fatal(err)
+	}
+	peerID, err := sender.AddFriendByPublicKey(receiver.SelfGetPublicKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+	friendAddr := &net.UDPAddr{IP: net.IPv4(192, 0, 2, 7), Port: 33445}
+	otherAddr := &net.UDPAddr{IP: net.IPv4(192, 0, 2, 8), Port: 33445}
+	receiver.dht.AddNode(dht.NewNode(*crypto.NewToxID(senderPK, [4]byte{}), friendAddr))
+
+	capture := &editCapture{}
+	mm := messaging.NewMessageManager()
+	defer mm.Close()
+	mm.SetTransport(capture)
+	mm.SetKeyProvider(sender)
+	msg, err := mm.SendMessage(peerID, "helo", messaging.MessageTypeNormal)
+	if err != nil {
+		t.Fatalf("SendMessage failed: %v", err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for msg.GetState() != messaging.MessageStateSent && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	mm.MarkMessageDelivered(msg.ID)
+	if err := msg.SetBody("hello"); err != nil {
+		t.Fatalf("SetBody failed: %v", err)
+	}
+
+	// The original message arrives from the friend's address; its FRIEND_ID
+	// is the sender's ID for us and is not used to attribute it.
+	packet := make([]byte, friendMessageHeaderSize)
+	packet[0] = 0x01
+	binary.BigEndian.PutUint32(packet[1:5], peerID+100)
+	packet[5] = byte(MessageTypeNormal)
+	binary.BigEndian.PutUint32(packet[6:10], msg.ID)
+	if err := receiver.processFriendMessagePacket(append(packet, "helo"...), friendAddr); err != nil {
+		t.Fatalf("processFriendMessagePacket failed: %v", err)
+	}
+
+	var edited []string
+	receiver.messageManager.OnMessageEdit(func(m *messaging.Message) { edited = append(edited, m.GetText()) })
+	encoded, err := capture.edits[0].MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	editPacket := &transport.Packet{PacketType: transport.PacketMessageEdit, Data: append(senderPK[:], encoded...)}
+
+	if err := receiver.handleMessageEditPacket(editPacket, otherAddr); err != nil {
+		t.Errorf("edit from another address should be dropped silently, got %v", err)
+	}
+	if len(edited) != 0 {
+		t.Fatalf("edit claiming the friend's key from another address was applied: %v", edited)
+	}
+	if err := receiver.handleMessageEditPacket(editPacket, friendAddr); err != nil {
+		t.Fatalf("handleMessageEditPacket failed: %v", err)
+	}
+	if len(edited) != 1 || edited[0] != "hello" {
+		t.Errorf("OnMessageEdit got %v, want [hello]", edited)
+	}
+}
+
 // --- Tests from edge_case_fixes_test.go ---
 
 // TestFriendIDStartsAtOne verifies that friend IDs start from 1, not 0
@@ -3515,6 +3992,78 @@ func TestKillIdempotent(t *testing.T) {
 	}
 }
 
+// TestIterateWithContextCancellation verifies that IterateWithContext
+// returns ctx.Err() within one iteration interval of cancellation.
+func TestIterateWithContextCancellation(t *testing.T) {
+	tox, err := New(NewOptionsForTesting())
+	if err != nil {
+		t.Fatalf("Failed to create Tox instance: %v", err)
+	}
+	defer tox.Kill()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- tox.IterateWithContext(ctx) }()
+
+	// Let a few iterations run before cancelling
+	start := atomic.LoadUint64(&tox.iterationCount)
+	deadline := time.Now().Add(5 * time.Second)
+	for atomic.LoadUint64(&tox.iterationCount) < start+2 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	cancelled := time.Now()
+	cancel()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected context.Canceled, got %v", err)
+		}
+		// Allow for an iteration in progress at cancellation time
+		if elapsed := time.Since(cancelled); elapsed > 2*tox.IterationInterval() {
+			t.Errorf("IterateWithContext returned %v after cancellation, interval is %v", elapsed, tox.IterationInterval())
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("IterateWithContext did not return after cancellation")
+	}
+
+	// An already cancelled context returns without iterating
+	count := atomic.LoadUint64(&tox.iterationCount)
+	if err := tox.IterateWithContext(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if atomic.LoadUint64(&tox.iterationCount) != count {
+		t.Error("IterateWithContext should not iterate with a cancelled context")
+	}
+}
+
+// TestIterateWithContextKill verifies that Kill stops IterateWithContext
+// and cancels the lifecycle context shared with the message manager.
+func TestIterateWithContextKill(t *testing.T) {
+	tox, err := New(NewOptionsForTesting())
+	if err != nil {
+		t.Fatalf("Failed to create Tox instance: %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- tox.IterateWithContext(context.Background()) }()
+	time.Sleep(2 * tox.IterationInterval())
+
+	tox.Kill()
+	select {
+	case err := <-done:
+		if !errors.Is(err, ErrToxKilled) {
+			t.Errorf("Expected ErrToxKilled, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("IterateWithContext did not return after Kill")
+	}
+
+	if tox.Context().Err() == nil {
+		t.Error("Kill should cancel the lifecycle context")
+	}
+}
+
 // --- Tests from message_processing_race_test.go ---
 
 // TestMessageProcessing_NilCheck verifies that doMessageProcessing properly
@@ -5217,3 +5766,178 @@ func TestFileSendWireFormatRoundTrip(t *testing.T) {
 		t.Errorf("fileName: got %q, want %q", gotFileName, testFileName)
 	}
 }
+
+func TestSetComponentLogLevel(t *testing.T) {
+	t.Cleanup(func() {
+		for _, component := range LogComponents() {
+			_ = setComponentLogLevel(component, logrus.GetLevel())
+		}
+	})
+
+	options := NewOptionsForTesting()
+	options.LogConfig = map[string]string{"dht": "debug", "transport": "warn"}
+	tox, err := New(options)
+	if err != nil {
+		t.Fatalf("New with LogConfig failed: %v", err)
+	}
+	defer tox.Kill()
+
+	if err := tox.SetComponentLogLevel("async", logrus.ErrorLevel); err != nil {
+		t.Errorf("SetComponentLogLevel(async) failed: %v", err)
+	}
+	if err := tox.SetComponentLogLevel("nonexistent", logrus.DebugLevel); !errors.Is(err, ErrUnknownLogComponent) {
+		t.Errorf("expected ErrUnknownLogComponent, got %v", err)
+	}
+
+	for _, config := range []map[string]string{
+		{"nonexistent": "debug"},
+		{"dht": "loud"},
+	} {
+		options := NewOptionsForTesting()
+		options.LogConfig = config
+		if bad, err := New(options); err == nil {
+			bad.Kill()
+			t.Errorf("New accepted invalid LogConfig %v", config)
+		}
+	}
+}
+
+func TestHealthServer(t *testing.T) {
+	tox, err := New(NewOptionsForTesting())
+	if err != nil {
+		t.Fatalf("Failed to create Tox instance: %v", err)
+	}
+	defer tox.Kill()
+
+	if err := tox.StartHealthServer("127.0.0.1:0"); err != nil {
+		t.Fatalf("StartHealthServer failed: %v", err)
+	}
+	if err := tox.StartHealthServer("127.0.0.1:0"); !errors.Is(err, ErrHealthServerRunning) {
+		t.Errorf("second StartHealthServer error = %v, want ErrHealthServerRunning", err)
+	}
+
+	friendID, err := tox.AddFriendByPublicKey([32]byte{7})
+	if err != nil {
+		t.Fatalf("AddFriendByPublicKey failed: %v", err)
+	}
+	if err := tox.SetFriendConnectionStatus(friendID, ConnectionUDP); err != nil {
+		t.Fatalf("SetFriendConnectionStatus failed: %v", err)
+	}
+
+	base := "http://" + tox.health.listener.Addr().String()
+
+	resp, err := http.Get(base + "/healthz")
+	if err != nil {
+		t.Fatalf("GET /healthz failed: %v", err)
+	}
+	var status HealthStatus
+	err = json.NewDecoder(resp.Body).Decode(&status)
+	resp.Body.Close()
+	if err != nil {
+		t.Fatalf("Failed to decode /healthz body: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK || status.Status != "ok" {
+		t.Errorf("/healthz = %d %+v, want 200 with status ok", resp.StatusCode, status)
+	}
+	if status.Connection != "none" || status.FriendsOnline != 1 {
+		t.Errorf("/healthz = %+v, want connection none and 1 friend online", status)
+	}
+
+	readyStatus := func() int {
+		resp, err := http.Get(base + "/readyz")
+		if err != nil {
+			t.Fatalf("GET /readyz failed: %v", err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+	if code := readyStatus(); code != http.StatusServiceUnavailable {
+		t.Errorf("/readyz before bootstrap = %d, want 503", code)
+	}
+
+	node := dht.NewNode(*crypto.NewToxID([32]byte{8}, [4]byte{}), &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 33445})
+	node.Update(dht.StatusGood)
+	tox.dht.AddNode(node)
+	if code := readyStatus(); code != http.StatusOK {
+		t.Errorf("/readyz with a good DHT node = %d, want 200", code)
+	}
+
+	if err := tox.StopHealthServer(); err != nil {
+		t.Fatalf("StopHealthServer failed: %v", err)
+	}
+	if err := tox.StopHealthServer(); !errors.Is(err, ErrHealthServerNotRunning) {
+		t.Errorf("second StopHealthServer error = %v, want ErrHealthServerNotRunning", err)
+	}
+	if _, err := http.Get(base + "/healthz"); err == nil {
+		t.Error("health server still accepting requests after StopHealthServer")
+	}
+}
+
+func TestHealthServerStoppedByKill(t *testing.T) {
+	tox, err := New(NewOptionsForTesting())
+	if err != nil {
+		t.Fatalf("Failed to create Tox instance: %v", err)
+	}
+	if err := tox.StartHealthServer("127.0.0.1:0"); err != nil {
+		t.Fatalf("StartHealthServer failed: %v", err)
+	}
+	addr := tox.health.listener.Addr().String()
+
+	tox.Kill()
+
+	if _, err := http.Get("http://" + addr + "/healthz"); err == nil {
+		t.Error("health server still accepting requests after Kill")
+	}
+}
+
+func TestResourceLimits(t *testing.T) {
+	options := NewOptionsForTesting()
+	options.ResourceLimits = ResourceLimits{MaxFriends: 2, MaxGroups: 1, MaxPendingRequests: 1}
+	tox, err := New(options)
+	if err != nil {
+		t.Fatalf("Failed to create Tox instance: %v", err)
+	}
+	defer tox.Kill()
+
+	for i := byte(1); i <= 2; i++ {
+		if _, err := tox.AddFriendByPublicKey([32]byte{i}); err != nil {
+			t.Fatalf("AddFriendByPublicKey %d failed: %v", i, err)
+		}
+	}
+	_, err = tox.AddFriendByPublicKey([32]byte{3})
+	if !errors.Is(err, ErrFriendLimitReached) || !errors.Is(err, ErrResourceLimit) {
+		t.Errorf("AddFriendByPublicKey over limit error = %v, want ErrFriendLimitReached", err)
+	}
+
+	if _, err := tox.ConferenceNew(); err != nil {
+		t.Fatalf("ConferenceNew failed: %v", err)
+	}
+	if _, err := tox.ConferenceNew(); !errors.Is(err, ErrResourceLimit) {
+		t.Errorf("ConferenceNew over limit error = %v, want ErrResourceLimit", err)
+	}
+
+	var requests int
+	tox.OnFriendRequest(func(publicKey [32]byte, message string) { requests++ })
+	tox.receiveFriendRequest([32]byte{10}, "hello")
+	tox.receiveFriendRequest([32]byte{11}, "hello")
+	if requests != 1 {
+		t.Errorf("friend request callback fired %d times, want 1", requests)
+	}
+
+	usage := tox.ResourceUsage()
+	if usage.Friends != (ResourceCount{Current: 2, Limit: 2}) {
+		t.Errorf("Friends usage = %+v, want 2/2", usage.Friends)
+	}
+	if usage.Groups != (ResourceCount{Current: 1, Limit: 1}) {
+		t.Errorf("Groups usage = %+v, want 1/1", usage.Groups)
+	}
+	if usage.PendingRequests != (ResourceCount{Current: 1, Limit: 1}) {
+		t.Errorf("PendingRequests usage = %+v, want 1/1", usage.PendingRequests)
+	}
+	if usage.DHTNodes.Limit != DefaultMaxDHTNodes || tox.dht.NodeLimit() != DefaultMaxDHTNodes {
+		t.Errorf("DHT node limit = %d (table %d), want default %d", usage.DHTNodes.Limit, tox.dht.NodeLimit(), DefaultMaxDHTNodes)
+	}
+	if usage.AsyncMessages.Limit != DefaultMaxAsyncMessages {
+		t.Errorf("AsyncMessages limit = %d, want default %d", usage.AsyncMessages.Limit, DefaultMaxAsyncMessages)
+	}
+}
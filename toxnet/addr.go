@@ -1,7 +1,6 @@
 package toxnet
 
 import (
-	"encoding/hex"
 	"strings"
 
 	"github.com/opd-ai/toxcore/crypto"
@@ -14,16 +13,14 @@ type ToxAddr struct {
 }
 
 // NewToxAddr creates a new ToxAddr from a Tox ID string.
-// The Tox ID should be a 76-character hexadecimal string.
+// The Tox ID should be a 76-character hexadecimal string, optionally in the
+// tox: URI form.
 func NewToxAddr(toxIDString string) (*ToxAddr, error) {
-	// Remove any whitespace and convert to uppercase for consistency
-	toxIDString = strings.ToUpper(strings.TrimSpace(toxIDString))
-
-	toxID, err := crypto.ToxIDFromString(toxIDString)
+	toxID, err := crypto.ParseToxAddress(toxIDString)
 	if err != nil {
 		return nil, &ToxNetError{
 			Op:   "parse",
-			Addr: toxIDString,
+			Addr: strings.TrimSpace(toxIDString),
 			Err:  ErrInvalidToxID,
 		}
 	}
@@ -99,21 +96,6 @@ func ResolveToxAddr(address string) (*ToxAddr, error) {
 
 // IsToxAddr checks if an address string represents a valid Tox address.
 func IsToxAddr(address string) bool {
-	// Clean the address
-	address = strings.ToUpper(strings.TrimSpace(address))
-
-	// Check length (76 hex characters)
-	if len(address) != 76 {
-		return false
-	}
-
-	// Check if it's valid hex
-	_, err := hex.DecodeString(address)
-	if err != nil {
-		return false
-	}
-
-	// Try to parse as ToxID to validate checksum
-	_, err = crypto.ToxIDFromString(address)
+	_, err := crypto.ParseToxAddress(address)
 	return err == nil
 }
@@ -38,6 +38,19 @@ func TestParseToxAddr(t *testing.T) {
 		}
 	})
 
+	t.Run("tox URI", func(t *testing.T) {
+		addr, err := ParseToxAddr("tox:" + validToxIDString)
+		if err != nil {
+			t.Fatalf("ParseToxAddr() error = %v", err)
+		}
+		if addr.String() != validToxIDString {
+			t.Errorf("ParseToxAddr() returned wrong address")
+		}
+		if !IsToxAddr("TOX://" + validToxIDString) {
+			t.Error("IsToxAddr() rejected tox: URI")
+		}
+	})
+
 	t.Run("lowercase address", func(t *testing.T) {
 		// Test case insensitivity
 		lowerAddr := "76518406f6a9f2217e8dc487cc783c25cc16a15eb36ff32e335364ec37166a8712a20c01"
@@ -112,34 +112,14 @@ func (r *callbackRouter) routeMessageToConnection(friendID uint32, message strin
 
 // updateConnectionStatus updates connection state and signals status changes.
 func updateConnectionStatus(conn *ToxConn, status toxcore.FriendStatus) {
-	conn.mu.Lock()
-	wasConnected := conn.connected
-	conn.connected = (status == toxcore.FriendStatusOnline)
-	conn.mu.Unlock()
-
-	if !wasConnected && conn.connected {
-		select {
-		case conn.connStateCh <- true:
-		default:
-		}
-	}
+	conn.setConnected(status == toxcore.FriendStatusOnline)
 }
 
 // updateConnectionStatusByConnStatus updates connection state from a
 // FriendConnectionStatus callback.  Any non-None status means transport-level
 // connectivity is available, which is what DialContext/Write actually need.
 func updateConnectionStatusByConnStatus(conn *ToxConn, status toxcore.ConnectionStatus) {
-	conn.mu.Lock()
-	wasConnected := conn.connected
-	conn.connected = (status != toxcore.ConnectionNone)
-	conn.mu.Unlock()
-
-	if !wasConnected && conn.connected {
-		select {
-		case conn.connStateCh <- true:
-		default:
-		}
-	}
+	conn.setConnected(status != toxcore.ConnectionNone)
 }
 
 // routeStatusToConnection delivers status updates to the appropriate ToxConn.
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"net"
 	"sync"
 	"time"
@@ -24,6 +25,12 @@ type ToxConn struct {
 	closed    bool
 	mu        sync.RWMutex
 
+	// Lifecycle state reported by State, guarded by mu. stateNotify is
+	// closed and replaced on every transition to wake WaitForState.
+	state         ToxConnState
+	stateNotify   chan struct{}
+	stateCallback StateChangeCallback
+
 	// Read buffer for incoming messages
 	readBuffer *bytes.Buffer
 	readMu     sync.Mutex
@@ -37,10 +44,13 @@ type ToxConn struct {
 	// Write chunking for large messages
 	writeMu sync.Mutex
 
-	// Deadline management
-	readDeadline  time.Time
-	writeDeadline time.Time
-	deadlineMu    sync.RWMutex
+	// Deadline management. deadlineNotify is closed and replaced (under
+	// deadlineMu) whenever a deadline changes, so blocked reads and writes
+	// re-arm their timers.
+	readDeadline   time.Time
+	writeDeadline  time.Time
+	deadlineNotify chan struct{}
+	deadlineMu     sync.RWMutex
 
 	// Context for cancellation
 	ctx    context.Context
@@ -54,6 +64,17 @@ type ToxConn struct {
 
 	// timeProvider provides time for deadline checks (injectable for testing)
 	timeProvider TimeProvider
+
+	// sender replaces FriendSendMessage for loopback and pipe connections
+	// that deliver chunks locally instead of through the Tox instance.
+	sender func(chunk []byte) error
+
+	// onClose runs once after Close for locally attached connections.
+	onClose func()
+
+	// peerClosed is set (under readMu) when a locally attached peer closes,
+	// so reads return io.EOF once the buffer drains.
+	peerClosed bool
 }
 
 // newToxConn creates a new ToxConn instance
@@ -71,9 +92,12 @@ func newToxConn(tox *toxcore.Tox, friendID uint32, localAddr, remoteAddr *ToxAdd
 		cancel:       cancel,
 		connStateCh:  make(chan bool, 1),
 		timeProvider: defaultTimeProvider,
+		state:        StateConnecting,
+		stateNotify:  make(chan struct{}),
 	}
 
 	conn.readNotify = make(chan struct{})
+	conn.deadlineNotify = make(chan struct{})
 
 	// Register with the callback router for this Tox instance
 	conn.router = getOrCreateRouter(tox)
@@ -84,6 +108,9 @@ func newToxConn(tox *toxcore.Tox, friendID uint32, localAddr, remoteAddr *ToxAdd
 	for _, friend := range friends {
 		if friend.PublicKey == remoteAddr.PublicKey() {
 			conn.connected = (friend.ConnectionStatus != toxcore.ConnectionNone)
+			if conn.connected {
+				conn.state = StateConnected
+			}
 			break
 		}
 	}
@@ -129,6 +156,31 @@ func (c *ToxConn) setupReadTimeout() (<-chan time.Time, func()) {
 	return nil, func() {} // No-op cleanup for nil timeout
 }
 
+// deadlineChanged returns a channel that is closed the next time a deadline
+// is set.
+func (c *ToxConn) deadlineChanged() <-chan struct{} {
+	c.deadlineMu.RLock()
+	defer c.deadlineMu.RUnlock()
+	return c.deadlineNotify
+}
+
+// setDeadlines updates the selected deadlines and wakes blocked operations.
+func (c *ToxConn) setDeadlines(t time.Time, read, write bool) {
+	c.deadlineMu.Lock()
+	defer c.deadlineMu.Unlock()
+	if read {
+		c.readDeadline = t
+	}
+	if write {
+		c.writeDeadline = t
+	}
+	if c.deadlineNotify == nil {
+		c.deadlineNotify = make(chan struct{})
+	}
+	close(c.deadlineNotify)
+	c.deadlineNotify = make(chan struct{})
+}
+
 func drainTimerChannel(c <-chan time.Time) {
 	select {
 	case <-c:
@@ -159,28 +211,28 @@ func (c *ToxConn) checkReadEarlyExit(timeout <-chan time.Time) error {
 }
 
 // waitForDataSignal waits for data availability signal with timeout handling.
-// Must be called with c.readMu held; it releases and re-acquires readMu around the select.
-func (c *ToxConn) waitForDataSignal(timeout <-chan time.Time) error {
+// It also returns when the read deadline changes so the caller can re-arm
+// its timer. Must be called with c.readMu held; it releases and re-acquires
+// readMu around the select.
+func (c *ToxConn) waitForDataSignal(timeout <-chan time.Time, deadlineChanged <-chan struct{}) error {
 	if err := c.checkReadEarlyExit(timeout); err != nil {
 		return err
 	}
 	ch := c.readNotify
 	c.readMu.Unlock()
-	var err error
-	if timeout != nil {
-		err = awaitSignalWithTimeout(ch, c.ctx, timeout)
-	} else {
-		err = awaitSignalNoTimeout(ch, c.ctx)
-	}
+	err := awaitSignal(ch, c.ctx, timeout, deadlineChanged)
 	c.readMu.Lock()
 	return err
 }
 
-// awaitSignalWithTimeout waits for a read signal, closure, or timeout.
-func awaitSignalWithTimeout(ch <-chan struct{}, ctx context.Context, timeout <-chan time.Time) error {
+// awaitSignal waits for a read signal, a deadline change, closure, or
+// timeout. A nil timeout channel never fires.
+func awaitSignal(ch <-chan struct{}, ctx context.Context, timeout <-chan time.Time, deadlineChanged <-chan struct{}) error {
 	select {
 	case <-ch:
 		return nil
+	case <-deadlineChanged:
+		return nil
 	case <-ctx.Done():
 		return ErrConnectionClosed
 	case <-timeout:
@@ -188,16 +240,6 @@ func awaitSignalWithTimeout(ch <-chan struct{}, ctx context.Context, timeout <-c
 	}
 }
 
-// awaitSignalNoTimeout waits for a read signal or connection closure.
-func awaitSignalNoTimeout(ch <-chan struct{}, ctx context.Context) error {
-	select {
-	case <-ch:
-		return nil
-	case <-ctx.Done():
-		return ErrConnectionClosed
-	}
-}
-
 // markClosed records a closed state exactly once for close paths.
 func markClosed(lock interface {
 	Lock()
@@ -213,14 +255,24 @@ func markClosed(lock interface {
 	return true
 }
 
-// waitForReadData waits for data to be available in the read buffer with timeout handling.
-func (c *ToxConn) waitForReadData(timeout <-chan time.Time) error {
+// waitForReadData waits for data to be available in the read buffer with
+// timeout handling. The timer is re-armed whenever the read deadline changes,
+// so SetReadDeadline affects a Read that is already blocked.
+// Must be called with c.readMu held.
+func (c *ToxConn) waitForReadData() error {
 	for c.readBuffer.Len() == 0 {
 		if err := c.checkConnectionClosed(); err != nil {
 			return err
 		}
+		if c.peerClosed {
+			return io.EOF
+		}
 
-		if err := c.waitForDataSignal(timeout); err != nil {
+		changed := c.deadlineChanged()
+		timeout, cleanup := c.setupReadTimeout()
+		err := c.waitForDataSignal(timeout, changed)
+		cleanup()
+		if err != nil {
 			return err
 		}
 	}
@@ -238,13 +290,10 @@ func (c *ToxConn) Read(b []byte) (int, error) {
 		return 0, err
 	}
 
-	timeout, cleanup := c.setupReadTimeout()
-	defer cleanup()
-
 	c.readMu.Lock()
 	defer c.readMu.Unlock()
 
-	if err := c.waitForReadData(timeout); err != nil {
+	if err := c.waitForReadData(); err != nil {
 		return 0, err
 	}
 
@@ -329,6 +378,9 @@ func (c *ToxConn) checkWriteDeadline() error {
 // an underlying error, both the written count and a wrapped ErrPartialWrite are returned.
 // sendChunk sends a single chunk of data and returns an error on failure.
 func (c *ToxConn) sendChunk(chunk []byte) error {
+	if c.sender != nil {
+		return c.sender(chunk)
+	}
 	_, err := c.tox.FriendSendMessage(c.friendID, string(chunk), toxcore.MessageTypeNormal)
 	return err
 }
@@ -378,11 +430,9 @@ func (c *ToxConn) writeChunkedData(b []byte) (int, error) {
 	return totalWritten, nil
 }
 
-// waitForConnection waits for the friend to come online
+// waitForConnection waits for the friend to come online, re-arming the
+// write deadline timer whenever the deadline changes.
 func (c *ToxConn) waitForConnection() error {
-	timeout, cleanup := c.setupConnectionTimeout()
-	defer cleanup()
-
 	for {
 		connected, err := c.checkConnectionStatus()
 		if err != nil {
@@ -392,7 +442,11 @@ func (c *ToxConn) waitForConnection() error {
 			return nil
 		}
 
-		if err := c.waitForConnectionEvent(timeout); err != nil {
+		changed := c.deadlineChanged()
+		timeout, cleanup := c.setupConnectionTimeout()
+		err = c.waitForConnectionEvent(timeout, changed)
+		cleanup()
+		if err != nil {
 			return err
 		}
 	}
@@ -437,12 +491,15 @@ func (c *ToxConn) checkConnectionStatus() (bool, error) {
 	return connected, nil
 }
 
-// waitForConnectionEvent waits for connection state changes or timeout.
-func (c *ToxConn) waitForConnectionEvent(timeout <-chan time.Time) error {
+// waitForConnectionEvent waits for connection state changes, a deadline
+// change, or timeout.
+func (c *ToxConn) waitForConnectionEvent(timeout <-chan time.Time, deadlineChanged <-chan struct{}) error {
 	select {
 	case <-c.connStateCh:
 		// Connection state changed, will check again in main loop
 		return nil
+	case <-deadlineChanged:
+		return nil
 	case <-timeout:
 		return &ToxNetError{Op: "write", Err: ErrTimeout}
 	case <-c.ctx.Done():
@@ -453,9 +510,17 @@ func (c *ToxConn) waitForConnectionEvent(timeout <-chan time.Time) error {
 // Close implements net.Conn.Close().
 // It closes the connection and cleans up resources.
 func (c *ToxConn) Close() error {
-	if !markClosed(&c.mu, &c.closed) {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
 		return nil
 	}
+	c.closed = true
+	from, callback, changed := c.transitionLocked(StateClosing)
+	c.mu.Unlock()
+	if changed && callback != nil {
+		callback(c, from, StateClosing)
+	}
 
 	// Unregister from callback router
 	if c.router != nil {
@@ -468,6 +533,10 @@ func (c *ToxConn) Close() error {
 	c.broadcastRead()
 	c.readMu.Unlock()
 
+	if c.onClose != nil {
+		c.onClose()
+	}
+
 	return nil
 }
 
@@ -484,30 +553,25 @@ func (c *ToxConn) RemoteAddr() net.Addr {
 }
 
 // SetDeadline implements net.Conn.SetDeadline().
-// It sets both read and write deadlines.
+// It sets both read and write deadlines atomically. Like all deadline
+// setters it also applies to operations that are already blocked; a zero
+// value means no deadline.
 func (c *ToxConn) SetDeadline(t time.Time) error {
-	c.deadlineMu.Lock()
-	c.readDeadline = t
-	c.writeDeadline = t
-	c.deadlineMu.Unlock()
+	c.setDeadlines(t, true, true)
 	return nil
 }
 
 // SetReadDeadline implements net.Conn.SetReadDeadline().
 // It sets the deadline for read operations.
 func (c *ToxConn) SetReadDeadline(t time.Time) error {
-	c.deadlineMu.Lock()
-	c.readDeadline = t
-	c.deadlineMu.Unlock()
+	c.setDeadlines(t, true, false)
 	return nil
 }
 
 // SetWriteDeadline implements net.Conn.SetWriteDeadline().
 // It sets the deadline for write operations.
 func (c *ToxConn) SetWriteDeadline(t time.Time) error {
-	c.deadlineMu.Lock()
-	c.writeDeadline = t
-	c.deadlineMu.Unlock()
+	c.setDeadlines(t, false, true)
 	return nil
 }
 
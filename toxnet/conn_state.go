@@ -0,0 +1,131 @@
+package toxnet
+
+import (
+	"context"
+	"fmt"
+)
+
+// ToxConnState describes the lifecycle of a ToxConn.
+type ToxConnState int
+
+const (
+	// StateConnecting means the friend has not been online since the
+	// connection was created.
+	StateConnecting ToxConnState = iota
+	// StateConnected means the friend is online and writes are sent
+	// immediately.
+	StateConnected
+	// StateDisconnected means the friend went offline after being
+	// connected. Buffered data can still be read and writes wait for the
+	// friend to return.
+	StateDisconnected
+	// StateClosing means Close was called. It is the final state.
+	StateClosing
+)
+
+// String returns the state name for logging.
+func (s ToxConnState) String() string {
+	switch s {
+	case StateConnecting:
+		return "connecting"
+	case StateConnected:
+		return "connected"
+	case StateDisconnected:
+		return "disconnected"
+	case StateClosing:
+		return "closing"
+	default:
+		return fmt.Sprintf("ToxConnState(%d)", int(s))
+	}
+}
+
+// StateChangeCallback is called after a ToxConn changes state. It runs on
+// the goroutine that caused the change (the Tox callback goroutine or the
+// caller of Close) and should not block.
+type StateChangeCallback func(conn *ToxConn, from, to ToxConnState)
+
+// State returns the current connection state.
+func (c *ToxConn) State() ToxConnState {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.state
+}
+
+// OnStateChange registers a callback invoked on every state change,
+// replacing any previous callback. Pass nil to remove it.
+func (c *ToxConn) OnStateChange(callback StateChangeCallback) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stateCallback = callback
+}
+
+// WaitForState blocks until the connection reaches target or ctx is done,
+// returning ctx.Err() in the latter case. Waiting for any state other than
+// StateClosing returns ErrConnectionClosed once the connection is closed.
+func (c *ToxConn) WaitForState(ctx context.Context, target ToxConnState) error {
+	for {
+		c.mu.RLock()
+		state := c.state
+		notify := c.stateNotify
+		c.mu.RUnlock()
+
+		if state == target {
+			return nil
+		}
+		if state == StateClosing {
+			return ErrConnectionClosed
+		}
+
+		select {
+		case <-notify:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// setConnected records the friend's connectivity, moving between the
+// connecting, connected and disconnected states, and wakes writers waiting
+// for the friend to come online.
+func (c *ToxConn) setConnected(connected bool) {
+	c.mu.Lock()
+	wasConnected := c.connected
+	c.connected = connected
+
+	next := c.state
+	switch {
+	case c.state == StateClosing:
+	case connected:
+		next = StateConnected
+	case c.state == StateConnected:
+		next = StateDisconnected
+	}
+	from, callback, changed := c.transitionLocked(next)
+	c.mu.Unlock()
+
+	if !wasConnected && connected {
+		select {
+		case c.connStateCh <- true:
+		default:
+		}
+	}
+	if changed && callback != nil {
+		callback(c, from, next)
+	}
+}
+
+// transitionLocked moves the connection to next and wakes WaitForState
+// callers. It returns the previous state and the callback to run once
+// c.mu is released. The caller must hold c.mu.
+func (c *ToxConn) transitionLocked(next ToxConnState) (ToxConnState, StateChangeCallback, bool) {
+	from := c.state
+	if from == next {
+		return from, nil, false
+	}
+	c.state = next
+	if c.stateNotify != nil {
+		close(c.stateNotify)
+	}
+	c.stateNotify = make(chan struct{})
+	return from, c.stateCallback, true
+}
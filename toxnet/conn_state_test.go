@@ -0,0 +1,113 @@
+package toxnet
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/opd-ai/toxcore"
+)
+
+// newConnectingConn returns a local connection whose friend has not come
+// online yet.
+func newConnectingConn() *ToxConn {
+	conn := newLocalConn(context.Background(), 0, &ToxAddr{}, &ToxAddr{})
+	conn.connected = false
+	conn.state = StateConnecting
+	return conn
+}
+
+func TestToxConnStateTransitions(t *testing.T) {
+	conn := newConnectingConn()
+
+	type change struct{ from, to ToxConnState }
+	var mu sync.Mutex
+	var changes []change
+	conn.OnStateChange(func(c *ToxConn, from, to ToxConnState) {
+		if c != conn {
+			t.Error("callback received the wrong connection")
+		}
+		mu.Lock()
+		changes = append(changes, change{from, to})
+		mu.Unlock()
+	})
+
+	if conn.State() != StateConnecting {
+		t.Fatalf("initial state %v, want connecting", conn.State())
+	}
+	// Going offline before ever connecting stays in connecting
+	updateConnectionStatusByConnStatus(conn, toxcore.ConnectionNone)
+	updateConnectionStatusByConnStatus(conn, toxcore.ConnectionUDP)
+	updateConnectionStatusByConnStatus(conn, toxcore.ConnectionTCP)
+	updateConnectionStatus(conn, toxcore.FriendStatusNone)
+	updateConnectionStatus(conn, toxcore.FriendStatusOnline)
+	if err := conn.Close(); err != nil {
+		t.Fatal(err)
+	}
+	updateConnectionStatusByConnStatus(conn, toxcore.ConnectionUDP)
+
+	want := []change{
+		{StateConnecting, StateConnected},
+		{StateConnected, StateDisconnected},
+		{StateDisconnected, StateConnected},
+		{StateConnected, StateClosing},
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(changes) != len(want) {
+		t.Fatalf("state changes %v, want %v", changes, want)
+	}
+	for i := range want {
+		if changes[i] != want[i] {
+			t.Errorf("change %d: %v, want %v", i, changes[i], want[i])
+		}
+	}
+	if conn.State() != StateClosing {
+		t.Errorf("state after close %v, want closing", conn.State())
+	}
+}
+
+func TestToxConnWaitForState(t *testing.T) {
+	conn := newConnectingConn()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- conn.WaitForState(context.Background(), StateConnected)
+	}()
+	time.Sleep(10 * time.Millisecond)
+	updateConnectionStatusByConnStatus(conn, toxcore.ConnectionUDP)
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("WaitForState failed: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("WaitForState did not return after connecting")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := conn.WaitForState(ctx, StateDisconnected); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected context deadline, got %v", err)
+	}
+
+	go func() {
+		done <- conn.WaitForState(context.Background(), StateDisconnected)
+	}()
+	time.Sleep(10 * time.Millisecond)
+	conn.Close()
+	if err := <-done; !errors.Is(err, ErrConnectionClosed) {
+		t.Errorf("expected ErrConnectionClosed, got %v", err)
+	}
+	if err := conn.WaitForState(context.Background(), StateClosing); err != nil {
+		t.Errorf("waiting for closing on a closed connection: %v", err)
+	}
+}
+
+func TestToxConnStateString(t *testing.T) {
+	if StateDisconnected.String() != "disconnected" || ToxConnState(9).String() != "ToxConnState(9)" {
+		t.Errorf("unexpected names %q %q", StateDisconnected, ToxConnState(9))
+	}
+}
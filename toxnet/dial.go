@@ -2,12 +2,37 @@ package toxnet
 
 import (
 	"context"
+	"fmt"
 	"net"
 	"time"
 
 	"github.com/opd-ai/toxcore"
+	"github.com/sirupsen/logrus"
 )
 
+// DefaultDialMessage is the friend request message sent when dialing a Tox
+// address that is not yet a friend.
+const DefaultDialMessage = "Connection request from Tox networking layer"
+
+// DialOptions configures DialWithOptions.
+type DialOptions struct {
+	// MessageText is sent with the friend request when the target is not
+	// already a friend. Empty means DefaultDialMessage.
+	MessageText string
+
+	// HandshakeTimeout bounds the wait for the friend to come online, in
+	// addition to the context. Zero means the context alone decides.
+	HandshakeTimeout time.Duration
+}
+
+// message returns the friend request text to send.
+func (o DialOptions) message() string {
+	if o.MessageText == "" {
+		return DefaultDialMessage
+	}
+	return o.MessageText
+}
+
 // Dial connects to a Tox address and returns a net.Conn.
 // The toxID should be a 76-character hexadecimal Tox ID string.
 func Dial(toxID string, tox *toxcore.Tox) (net.Conn, error) {
@@ -54,12 +79,12 @@ func findExistingFriend(tox *toxcore.Tox, remoteAddr *ToxAddr) (uint32, bool) {
 // addFriendWithContext adds a friend if the context has not already been cancelled.
 // Once AddFriend starts it cannot be interrupted, so this helper only avoids
 // starting the operation after cancellation and otherwise preserves AddFriend behavior.
-func addFriendWithContext(ctx context.Context, tox *toxcore.Tox, toxID string) (uint32, error) {
+func addFriendWithContext(ctx context.Context, tox *toxcore.Tox, toxID, message string) (uint32, error) {
 	if err := ctx.Err(); err != nil {
 		return 0, NewToxNetError("dial", toxID, err)
 	}
 
-	friendID, err := tox.AddFriend(toxID, "Connection request from Tox networking layer")
+	friendID, err := tox.AddFriend(toxID, message)
 	if err != nil {
 		return 0, NewToxNetError("dial", toxID, err)
 	}
@@ -68,29 +93,55 @@ func addFriendWithContext(ctx context.Context, tox *toxcore.Tox, toxID string) (
 
 // DialContext connects to a Tox address with a context and returns a net.Conn.
 func DialContext(ctx context.Context, toxID string, tox *toxcore.Tox) (net.Conn, error) {
-	if err := checkContextDone(ctx, toxID); err != nil {
+	conn, err := DialWithOptions(ctx, toxID, tox, DialOptions{})
+	if err != nil {
 		return nil, err
 	}
+	return conn, nil
+}
 
-	remoteAddr, err := NewToxAddr(toxID)
+// DialWithOptions connects to a Tox address and returns a ToxConn ready for
+// I/O. It parses the address, sends a friend request with opts.MessageText
+// unless the target is already a friend, and waits until the friend's
+// connection status reports them online. The wait ends early when ctx is
+// done or opts.HandshakeTimeout elapses; a friend added by this call is then
+// deleted again so an abandoned dial leaves no pending friend behind.
+func DialWithOptions(ctx context.Context, toxAddress string, tox *toxcore.Tox, opts DialOptions) (*ToxConn, error) {
+	if err := checkContextDone(ctx, toxAddress); err != nil {
+		return nil, err
+	}
+	if opts.HandshakeTimeout < 0 {
+		return nil, NewToxNetError("dial", toxAddress, fmt.Errorf("handshake timeout cannot be negative: %v", opts.HandshakeTimeout))
+	}
+
+	remoteAddr, err := NewToxAddr(toxAddress)
 	if err != nil {
 		return nil, err
 	}
 
 	localAddr := createLocalAddr(tox)
 
-	friendID, err := getOrAddFriend(ctx, tox, toxID, remoteAddr)
+	friendID, added, err := getOrAddFriend(ctx, tox, toxAddress, remoteAddr, opts.message())
 	if err != nil {
 		return nil, err
 	}
 
 	conn := newToxConn(tox, friendID, localAddr, remoteAddr)
 
+	if opts.HandshakeTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.HandshakeTimeout)
+		defer cancel()
+	}
+
 	if err := waitForConnection(ctx, conn); err != nil {
 		conn.Close()
+		if added {
+			removeDialedFriend(tox, friendID)
+		}
 		return nil, &ToxNetError{
 			Op:   "dial",
-			Addr: toxID,
+			Addr: toxAddress,
 			Err:  err,
 		}
 	}
@@ -98,6 +149,17 @@ func DialContext(ctx context.Context, toxID string, tox *toxcore.Tox) (net.Conn,
 	return conn, nil
 }
 
+// removeDialedFriend deletes a friend added by an abandoned dial.
+func removeDialedFriend(tox *toxcore.Tox, friendID uint32) {
+	if err := tox.DeleteFriend(friendID); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"function":  "DialWithOptions",
+			"friend_id": friendID,
+			"error":     err.Error(),
+		}).Warn("Failed to remove friend added by abandoned dial")
+	}
+}
+
 // createLocalAddr creates a local ToxAddr from the tox instance.
 func createLocalAddr(tox *toxcore.Tox) *ToxAddr {
 	localPublicKey := tox.SelfGetPublicKey()
@@ -105,18 +167,23 @@ func createLocalAddr(tox *toxcore.Tox) *ToxAddr {
 	return NewToxAddrFromPublicKey(localPublicKey, localNospam)
 }
 
-// getOrAddFriend retrieves an existing friend or adds a new one.
-func getOrAddFriend(ctx context.Context, tox *toxcore.Tox, toxID string, remoteAddr *ToxAddr) (uint32, error) {
+// getOrAddFriend retrieves an existing friend or adds a new one, reporting
+// whether the friend was added.
+func getOrAddFriend(ctx context.Context, tox *toxcore.Tox, toxID string, remoteAddr *ToxAddr, message string) (uint32, bool, error) {
 	friendID, found := findExistingFriend(tox, remoteAddr)
 	if found {
-		return friendID, nil
+		return friendID, false, nil
 	}
 
 	if err := checkContextDone(ctx, toxID); err != nil {
-		return 0, err
+		return 0, false, err
 	}
 
-	return addFriendWithContext(ctx, tox, toxID)
+	friendID, err := addFriendWithContext(ctx, tox, toxID, message)
+	if err != nil {
+		return 0, false, err
+	}
+	return friendID, true, nil
 }
 
 // calculatePollInterval determines the optimal polling interval based on context deadline.
@@ -140,12 +207,14 @@ func calculatePollInterval(ctx context.Context) time.Duration {
 	return defaultInterval
 }
 
-// pollForConnection polls the connection status until connected or context cancellation.
-// It uses the provided ticker to check connection status at regular intervals.
+// pollForConnection waits until the connection is up or the context is
+// cancelled. The callback router signals conn.connStateCh when
+// OnFriendConnectionStatus reports the friend online; the ticker re-checks
+// the status at regular intervals in case a signal was consumed elsewhere.
 func pollForConnection(ctx context.Context, conn *ToxConn, ticker *time.Ticker) error {
 	defer ticker.Stop()
 	for {
-		if err := waitForConnectionPoll(ctx, ticker); err != nil {
+		if err := waitForConnectionPoll(ctx, conn.connStateCh, ticker); err != nil {
 			return err
 		}
 		if conn.IsConnected() {
@@ -154,11 +223,14 @@ func pollForConnection(ctx context.Context, conn *ToxConn, ticker *time.Ticker)
 	}
 }
 
-// waitForConnectionPoll blocks until the next poll tick or context cancellation.
-func waitForConnectionPoll(ctx context.Context, ticker *time.Ticker) error {
+// waitForConnectionPoll blocks until a connection state signal, the next
+// poll tick, or context cancellation.
+func waitForConnectionPoll(ctx context.Context, connStateCh <-chan bool, ticker *time.Ticker) error {
 	select {
 	case <-ctx.Done():
 		return ctx.Err()
+	case <-connStateCh:
+		return nil
 	case <-ticker.C:
 		return nil
 	}
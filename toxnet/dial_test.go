@@ -2,7 +2,9 @@ package toxnet
 
 import (
 	"context"
+	"errors"
 	"net"
+	"strings"
 	"testing"
 	"time"
 
@@ -563,3 +565,130 @@ func TestWithManualAcceptForcesManualMode(t *testing.T) {
 		t.Fatal("WithManualAccept should set auto-accept=false")
 	}
 }
+
+// dialTestToxID returns a valid Tox ID for a peer that never comes online.
+func dialTestToxID() string {
+	publicKey := [32]byte{
+		0x76, 0x51, 0x84, 0x06, 0xF6, 0xA9, 0xF2, 0x21,
+		0x7E, 0x8D, 0xC4, 0x87, 0xCC, 0x78, 0x3C, 0x25,
+		0xCC, 0x16, 0xA1, 0x5E, 0xB3, 0x6F, 0xF3, 0x2E,
+		0x33, 0x53, 0x64, 0xEC, 0x37, 0x16, 0x6A, 0x87,
+	}
+	return crypto.NewToxID(publicKey, [4]byte{0x12, 0xA2, 0x0C, 0x01}).String()
+}
+
+func TestDialWithOptionsConnects(t *testing.T) {
+	tox, err := toxcore.New(toxcore.NewOptionsForTesting())
+	if err != nil {
+		t.Fatalf("Failed to create Tox instance: %v", err)
+	}
+	defer tox.Kill()
+
+	// Report the friend online through the connection status callback path
+	// once Dial has added it and registered its connection.
+	go func() {
+		for i := 0; i < 200; i++ {
+			for id := range tox.GetFriends() {
+				if conn := getOrCreateRouter(tox).getConnection(id); conn != nil {
+					updateConnectionStatusByConnStatus(conn, toxcore.ConnectionUDP)
+					return
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}()
+
+	start := time.Now()
+	conn, err := DialWithOptions(context.Background(), dialTestToxID(), tox, DialOptions{
+		MessageText:      "hello from toxnet",
+		HandshakeTimeout: 5 * time.Second,
+	})
+	if err != nil {
+		t.Fatalf("DialWithOptions() error = %v", err)
+	}
+	defer conn.Close()
+
+	if !conn.IsConnected() {
+		t.Error("Expected the returned connection to be online")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Dial took %v; the status callback should unblock it promptly", elapsed)
+	}
+	if len(tox.GetFriends()) != 1 {
+		t.Errorf("Expected the dialed friend to remain, got %d friends", len(tox.GetFriends()))
+	}
+}
+
+func TestDialWithOptionsCancellationRemovesFriend(t *testing.T) {
+	tox, err := toxcore.New(toxcore.NewOptionsForTesting())
+	if err != nil {
+		t.Fatalf("Failed to create Tox instance: %v", err)
+	}
+	defer tox.Kill()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = DialWithOptions(ctx, dialTestToxID(), tox, DialOptions{})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected context.DeadlineExceeded, got %v", err)
+	}
+	if n := len(tox.GetFriends()); n != 0 {
+		t.Errorf("Expected the friend added by the dial to be removed, got %d friends", n)
+	}
+
+	// HandshakeTimeout ends the wait without a context deadline
+	start := time.Now()
+	_, err = DialWithOptions(context.Background(), dialTestToxID(), tox, DialOptions{HandshakeTimeout: 30 * time.Millisecond})
+	if !errors.Is(err, context.DeadlineExceeded) || time.Since(start) > time.Second {
+		t.Fatalf("Expected the handshake timeout to expire, got %v after %v", err, time.Since(start))
+	}
+	if n := len(tox.GetFriends()); n != 0 {
+		t.Errorf("Expected no friends after handshake timeout, got %d", n)
+	}
+
+	// An existing friend is kept when the dial is abandoned
+	if _, err := tox.AddFriend(dialTestToxID(), "existing"); err != nil {
+		t.Fatalf("AddFriend failed: %v", err)
+	}
+	_, err = DialWithOptions(context.Background(), dialTestToxID(), tox, DialOptions{HandshakeTimeout: 10 * time.Millisecond})
+	if err == nil {
+		t.Fatal("Expected dial to time out")
+	}
+	if n := len(tox.GetFriends()); n != 1 {
+		t.Errorf("Expected the existing friend to remain, got %d friends", n)
+	}
+}
+
+func TestDialWithOptionsInvalidAddress(t *testing.T) {
+	tox, err := toxcore.New(toxcore.NewOptionsForTesting())
+	if err != nil {
+		t.Fatalf("Failed to create Tox instance: %v", err)
+	}
+	defer tox.Kill()
+
+	valid := dialTestToxID()
+	badChecksum := valid[:len(valid)-1] + "0"
+	if badChecksum == valid {
+		badChecksum = valid[:len(valid)-1] + "1"
+	}
+	tests := map[string]string{
+		"empty":        "",
+		"not hex":      strings.Repeat("z", len(valid)),
+		"too short":    valid[:len(valid)-2],
+		"bad checksum": badChecksum,
+	}
+	for name, addr := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := DialWithOptions(context.Background(), addr, tox, DialOptions{}); err == nil {
+				t.Error("Expected error for invalid address")
+			}
+		})
+	}
+
+	if _, err := DialWithOptions(context.Background(), valid, tox, DialOptions{HandshakeTimeout: -time.Second}); err == nil {
+		t.Error("Expected error for negative handshake timeout")
+	}
+	if n := len(tox.GetFriends()); n != 0 {
+		t.Errorf("Expected no friends after failed dials, got %d", n)
+	}
+}
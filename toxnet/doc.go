@@ -36,6 +36,18 @@
 //	// Use conn like any other net.Conn
 //	io.Copy(os.Stdout, conn)
 //
+// # Connection State
+//
+// A [ToxConn] moves through [StateConnecting], [StateConnected],
+// [StateDisconnected] (the friend went offline) and finally [StateClosing].
+// Servers multiplexing many connections can tell a departed friend from a
+// finished stream without waiting for Read:
+//
+//	toxConn.OnStateChange(func(c *toxnet.ToxConn, from, to toxnet.ToxConnState) {
+//	    log.Printf("friend %d: %v -> %v", c.FriendID(), from, to)
+//	})
+//	err := toxConn.WaitForState(ctx, toxnet.StateConnected)
+//
 // # Packet-based API (net.PacketConn)
 //
 // For datagram-style communication, use the packet-based API:
@@ -80,6 +92,20 @@
 //	    go handleConnection(conn)
 //	}
 //
+// # In-Memory Connections
+//
+// [Pipe] returns two connected [ToxConn] endpoints that exchange data through
+// simulated packet delivery, and [NewLoopbackConn] returns a connection for an
+// existing friend that echoes writes back to its own reader. Both let code
+// that expects a net.Conn or io.ReadWriteCloser be tested without a network:
+//
+//	client, server, err := toxnet.Pipe()
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	go io.Copy(server, server) // echo
+//	fmt.Fprintln(client, "ping")
+//
 // # Error Handling
 //
 // All errors are wrapped with [ToxNetError] providing context about the operation
@@ -84,11 +84,14 @@ func (e *ToxNetError) Timeout() bool {
 	return errors.Is(e.Err, ErrTimeout)
 }
 
-// Temporary reports whether the error is temporary.
-// Timeout errors are considered temporary (the caller may retry).
-// All other ToxNetErrors are treated as permanent.
+// Temporary reports whether the error is temporary. It always returns
+// false: an expired deadline keeps failing until the deadline is changed,
+// so retrying the same operation would not succeed.
+//
+// Deprecated: Temporary errors are not well-defined (see net.Error). Check
+// Timeout or use errors.Is instead.
 func (e *ToxNetError) Temporary() bool {
-	return e.Timeout()
+	return false
 }
 
 // NewToxNetError creates a new ToxNetError with the specified operation,
@@ -0,0 +1,226 @@
+package toxnet
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/opd-ai/toxcore"
+)
+
+func TestPipeRoundTrip(t *testing.T) {
+	a, b, err := Pipe()
+	if err != nil {
+		t.Fatalf("Pipe failed: %v", err)
+	}
+	defer a.Close()
+	defer b.Close()
+
+	if !a.IsConnected() || !b.IsConnected() {
+		t.Fatal("Expected both pipe ends to be connected")
+	}
+	if a.LocalAddr().String() != b.RemoteAddr().String() {
+		t.Error("Pipe addresses are not mirrored")
+	}
+
+	if _, err := a.Write([]byte("hello\nworld\n")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	a.Close()
+
+	scanner := bufio.NewScanner(b)
+	var lines []string
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("Scanner failed: %v", err)
+	}
+	if len(lines) != 2 || lines[0] != "hello" || lines[1] != "world" {
+		t.Errorf("Unexpected lines: %q", lines)
+	}
+}
+
+func TestPipeGzipStream(t *testing.T) {
+	a, b, err := Pipe()
+	if err != nil {
+		t.Fatalf("Pipe failed: %v", err)
+	}
+	defer b.Close()
+
+	// Larger than a single chunk to exercise write chunking
+	payload := bytes.Repeat([]byte("tox pipe payload "), 500)
+
+	go func() {
+		zw := gzip.NewWriter(a)
+		zw.Write(payload)
+		zw.Close()
+		a.Close()
+	}()
+
+	zr, err := gzip.NewReader(b)
+	if err != nil {
+		t.Fatalf("gzip.NewReader failed: %v", err)
+	}
+	got, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatalf("ReadAll failed: %v", err)
+	}
+	if !bytes.Equal(got, payload) {
+		t.Errorf("Payload mismatch: got %d bytes, want %d", len(got), len(payload))
+	}
+}
+
+func TestPipeWriteAfterPeerClose(t *testing.T) {
+	a, b, err := Pipe()
+	if err != nil {
+		t.Fatalf("Pipe failed: %v", err)
+	}
+	defer a.Close()
+	b.Close()
+
+	if _, err := a.Write([]byte("data")); !errors.Is(err, ErrConnectionClosed) {
+		t.Errorf("Expected ErrConnectionClosed, got %v", err)
+	}
+}
+
+func TestPipeReadDeadline(t *testing.T) {
+	a, b, err := Pipe()
+	if err != nil {
+		t.Fatalf("Pipe failed: %v", err)
+	}
+	defer a.Close()
+	defer b.Close()
+
+	b.SetReadDeadline(time.Now().Add(20 * time.Millisecond))
+	_, err = b.Read(make([]byte, 8))
+	var netErr *ToxNetError
+	if !errors.As(err, &netErr) || !netErr.Timeout() {
+		t.Errorf("Expected timeout error, got %v", err)
+	}
+}
+
+func TestPipeReadDeadlineAccuracy(t *testing.T) {
+	a, b, err := Pipe()
+	if err != nil {
+		t.Fatalf("Pipe failed: %v", err)
+	}
+	defer a.Close()
+	defer b.Close()
+
+	const deadline = 50 * time.Millisecond
+	start := time.Now()
+	b.SetReadDeadline(start.Add(deadline))
+	_, err = b.Read(make([]byte, 8))
+	elapsed := time.Since(start)
+
+	var netErr net.Error
+	if !errors.As(err, &netErr) || !netErr.Timeout() || netErr.Temporary() {
+		t.Fatalf("Expected net.Error with Timeout() true and Temporary() false, got %v", err)
+	}
+	if elapsed < deadline-10*time.Millisecond || elapsed > deadline+10*time.Millisecond {
+		t.Errorf("Read returned after %v, want %v ±10ms", elapsed, deadline)
+	}
+
+	// Clearing the deadline lets the next read succeed
+	b.SetReadDeadline(time.Time{})
+	if _, err := a.Write([]byte("ok")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	buf := make([]byte, 8)
+	n, err := b.Read(buf)
+	if err != nil || string(buf[:n]) != "ok" {
+		t.Errorf("Expected read after reset to return \"ok\", got %q, %v", buf[:n], err)
+	}
+}
+
+func TestPipeDeadlineChangedDuringRead(t *testing.T) {
+	a, b, err := Pipe()
+	if err != nil {
+		t.Fatalf("Pipe failed: %v", err)
+	}
+	defer a.Close()
+	defer b.Close()
+
+	// A Read blocked with no deadline is released by SetDeadline
+	errCh := make(chan error, 1)
+	go func() {
+		_, err := b.Read(make([]byte, 8))
+		errCh <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+	start := time.Now()
+	b.SetDeadline(start.Add(30 * time.Millisecond))
+
+	select {
+	case err := <-errCh:
+		var netErr net.Error
+		if !errors.As(err, &netErr) || !netErr.Timeout() {
+			t.Fatalf("Expected timeout error, got %v", err)
+		}
+		if elapsed := time.Since(start); elapsed > 40*time.Millisecond {
+			t.Errorf("Read returned %v after the deadline was set, want about 30ms", elapsed)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Read did not observe the new deadline")
+	}
+
+	// Extending the deadline of a blocked Read keeps it waiting for data
+	b.SetReadDeadline(time.Now().Add(20 * time.Millisecond))
+	go func() {
+		_, err := b.Read(make([]byte, 8))
+		errCh <- err
+	}()
+	time.Sleep(5 * time.Millisecond)
+	b.SetReadDeadline(time.Now().Add(time.Second))
+	time.Sleep(30 * time.Millisecond)
+	if _, err := a.Write([]byte("late")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if err := <-errCh; err != nil {
+		t.Errorf("Expected extended read to succeed, got %v", err)
+	}
+}
+
+func TestNewLoopbackConn(t *testing.T) {
+	tox, err := toxcore.New(toxcore.NewOptionsForTesting())
+	if err != nil {
+		t.Fatalf("Failed to create Tox instance: %v", err)
+	}
+	defer tox.Kill()
+
+	if _, err := NewLoopbackConn(tox, 42); !errors.Is(err, ErrFriendNotFound) {
+		t.Errorf("Expected ErrFriendNotFound, got %v", err)
+	}
+	if _, err := NewLoopbackConn(nil, 0); err == nil {
+		t.Error("Expected error for nil Tox instance")
+	}
+
+	friendID, err := tox.AddFriendByPublicKey([32]byte{1, 2, 3})
+	if err != nil {
+		t.Fatalf("AddFriendByPublicKey failed: %v", err)
+	}
+
+	conn, err := NewLoopbackConn(tox, friendID)
+	if err != nil {
+		t.Fatalf("NewLoopbackConn failed: %v", err)
+	}
+	defer conn.Close()
+
+	var rwc io.ReadWriteCloser = conn
+	if _, err := rwc.Write([]byte("echo")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	buf := make([]byte, 4)
+	if _, err := io.ReadFull(rwc, buf); err != nil {
+		t.Fatalf("ReadFull failed: %v", err)
+	}
+	if string(buf) != "echo" {
+		t.Errorf("Expected %q, got %q", "echo", buf)
+	}
+}
@@ -51,7 +51,7 @@ type MultiNetworkParser struct {
 func NewMultiNetworkParser() *MultiNetworkParser {
 	parser := &MultiNetworkParser{
 		parsers: make(map[string]NetworkParser),
-		logger:  logrus.WithField("component", "MultiNetworkParser"),
+		logger:  pkgLog.WithField("component", "MultiNetworkParser"),
 	}
 
 	// Register default parsers
@@ -195,7 +195,7 @@ type IPAddressParser struct {
 // NewIPAddressParser creates a new IP address parser
 func NewIPAddressParser() *IPAddressParser {
 	return &IPAddressParser{
-		logger: logrus.WithField("component", "IPAddressParser"),
+		logger: pkgLog.WithField("component", "IPAddressParser"),
 	}
 }
 
@@ -398,7 +398,7 @@ type TorAddressParser struct {
 // NewTorAddressParser creates a new Tor address parser
 func NewTorAddressParser() *TorAddressParser {
 	return &TorAddressParser{
-		logger: logrus.WithField("component", "TorAddressParser"),
+		logger: pkgLog.WithField("component", "TorAddressParser"),
 	}
 }
 
@@ -449,7 +449,7 @@ type I2PAddressParser struct {
 // NewI2PAddressParser creates a new I2P address parser
 func NewI2PAddressParser() *I2PAddressParser {
 	return &I2PAddressParser{
-		logger: logrus.WithField("component", "I2PAddressParser"),
+		logger: pkgLog.WithField("component", "I2PAddressParser"),
 	}
 }
 
@@ -481,7 +481,7 @@ type NymAddressParser struct {
 // NewNymAddressParser creates a new Nym address parser
 func NewNymAddressParser() *NymAddressParser {
 	return &NymAddressParser{
-		logger: logrus.WithField("component", "NymAddressParser"),
+		logger: pkgLog.WithField("component", "NymAddressParser"),
 	}
 }
 
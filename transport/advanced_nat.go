@@ -12,8 +12,6 @@ import (
 	"strings"
 	"sync"
 	"time"
-
-	"github.com/sirupsen/logrus"
 )
 
 // ConnectionMethod represents different connection establishment methods
@@ -286,7 +284,7 @@ func (ant *AdvancedNATTraversal) attemptSTUNConnection(ctx context.Context, remo
 
 	// Use the discovered public address for connection establishment
 	// This would involve coordinating with remote peer through signaling
-	logrus.WithField("public_addr", publicAddr.String()).Debug("STUN discovered public address for connection setup")
+	pkgLog.WithField("public_addr", publicAddr.String()).Debug("STUN discovered public address for connection setup")
 
 	return nil // Assume success for now
 }
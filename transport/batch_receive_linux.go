@@ -179,13 +179,13 @@ func (br *BatchReceiver) configureSocketBuffer(fd, bufferSize int) {
 	}
 
 	if err := unix.SetsockoptInt(fd, unix.SOL_SOCKET, unix.SO_RCVBUF, bufferSize); err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":    "NewBatchReceiver",
 			"buffer_size": bufferSize,
 			"error":       err.Error(),
 		}).Debug("Failed to set SO_RCVBUF")
 	} else {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":    "NewBatchReceiver",
 			"buffer_size": bufferSize,
 		}).Debug("Set SO_RCVBUF for improved throughput")
@@ -227,7 +227,7 @@ func (br *BatchReceiver) RecvBatch(timeout time.Duration) ([]ReceivedPacket, err
 	}
 
 	if err := br.setReadTimeout(timeout); err != nil {
-		logrus.WithError(err).Debug("Failed to set read deadline")
+		pkgLog.WithError(err).Debug("Failed to set read deadline")
 	}
 
 	n, addr, err := br.conn.ReadFrom(br.buffer)
@@ -338,7 +338,7 @@ func (br *BatchReceiver) growBuffer() {
 		return
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":     "BatchReceiver.maybeAdjustBuffers",
 		"old_size":     br.currentSize,
 		"new_size":     newSize,
@@ -366,7 +366,7 @@ func (br *BatchReceiver) shrinkBuffer(avgSize uint64) {
 		return
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":     "BatchReceiver.maybeAdjustBuffers",
 		"old_size":     br.currentSize,
 		"new_size":     newSize,
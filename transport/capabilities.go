@@ -0,0 +1,131 @@
+package transport
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/bits"
+	"sync"
+)
+
+// CapabilityBit identifies an optional protocol feature by its bit position
+// in the 64-bit capability flags exchanged during the versioned handshake.
+// The flags travel in the Noise-IK handshake payloads, so they are encrypted
+// and authenticated; a legacy handshake negotiates no capabilities.
+// Unlike a protocol version bump, a new feature only needs to claim a bit.
+//
+// Bit positions are part of the wire protocol: once assigned they must never
+// be reused for a different feature.
+type CapabilityBit uint8
+
+// MaxCapabilityBits is the number of feature bits in the capability flags.
+const MaxCapabilityBits = 64
+
+// Well-known capability bits. A feature advertises its bit by calling
+// RegisterCapability from an init function in the package implementing it.
+const (
+	// CapabilityQUIC indicates that the peer accepts connections over
+	// QUICTransport.
+	CapabilityQUIC CapabilityBit = 0
+	// CapabilityGroupHistory indicates that the peer accepts group history
+	// in PacketGroupHistorySync packets. Group members advertise it in
+	// their announcements.
+	CapabilityGroupHistory CapabilityBit = 1
+	// CapabilityCompression indicates support for PacketCompressed envelopes
+	// (see CompressedTransport).
+	CapabilityCompression CapabilityBit = 2
+	// CapabilityREDAudio indicates support for RFC 2198 redundant audio.
+	CapabilityREDAudio CapabilityBit = 3
+)
+
+// ErrNoNegotiatedCapabilities is returned when no versioned handshake has
+// completed with a peer.
+var ErrNoNegotiatedCapabilities = errors.New("no capabilities negotiated with peer")
+
+// capabilityFlagsSize is the wire size of the capability flags.
+const capabilityFlagsSize = 8
+
+var (
+	capabilityMu    sync.RWMutex
+	capabilityNames = make(map[CapabilityBit]string)
+)
+
+// RegisterCapability claims bit for the named feature and adds it to the
+// flags advertised by LocalCapabilities. It is meant to be called from a
+// package init function and panics if bit is out of range or already
+// claimed, since either is a programming error.
+func RegisterCapability(bit CapabilityBit, name string) {
+	if bit >= MaxCapabilityBits {
+		panic(fmt.Sprintf("transport: capability bit %d out of range for %q", bit, name))
+	}
+
+	capabilityMu.Lock()
+	defer capabilityMu.Unlock()
+	if existing, ok := capabilityNames[bit]; ok {
+		panic(fmt.Sprintf("transport: capability bit %d already registered as %q", bit, existing))
+	}
+	capabilityNames[bit] = name
+}
+
+// LocalCapabilities returns the capability flags with one bit set for each
+// registered feature.
+func LocalCapabilities() uint64 {
+	capabilityMu.RLock()
+	defer capabilityMu.RUnlock()
+
+	var flags uint64
+	for bit := range capabilityNames {
+		flags |= bit.Mask()
+	}
+	return flags
+}
+
+// Mask returns the capability flags with only this bit set.
+func (b CapabilityBit) Mask() uint64 {
+	if b >= MaxCapabilityBits {
+		return 0
+	}
+	return 1 << b
+}
+
+// String returns the registered feature name, or the bit number if the bit
+// is not registered.
+func (b CapabilityBit) String() string {
+	capabilityMu.RLock()
+	name, ok := capabilityNames[b]
+	capabilityMu.RUnlock()
+	if ok {
+		return name
+	}
+	return fmt.Sprintf("CapabilityBit(%d)", uint8(b))
+}
+
+// CapabilityNames returns the names of the features set in flags, ordered by
+// bit position.
+func CapabilityNames(flags uint64) []string {
+	names := make([]string, 0, bits.OnesCount64(flags))
+	for flags != 0 {
+		bit := CapabilityBit(bits.TrailingZeros64(flags))
+		names = append(names, bit.String())
+		flags &^= bit.Mask()
+	}
+	return names
+}
+
+// encodeCapabilityPayload returns the Noise handshake payload carrying flags:
+// [ExtensionVendorMagic(1)][flags(8, big-endian)].
+func encodeCapabilityPayload(flags uint64) []byte {
+	data := make([]byte, 0, 1+capabilityFlagsSize)
+	data = append(data, ExtensionVendorMagic)
+	return binary.BigEndian.AppendUint64(data, flags)
+}
+
+// decodeCapabilityPayload reads the flags from a Noise handshake payload.
+// Peers that predate capability flags send an empty payload and report zero
+// flags.
+func decodeCapabilityPayload(payload []byte) uint64 {
+	if len(payload) < 1+capabilityFlagsSize || payload[0] != ExtensionVendorMagic {
+		return 0
+	}
+	return binary.BigEndian.Uint64(payload[1 : 1+capabilityFlagsSize])
+}
@@ -0,0 +1,240 @@
+package transport
+
+import (
+	"bytes"
+	"errors"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/opd-ai/toxcore/crypto"
+)
+
+func TestRegisteredCapabilities(t *testing.T) {
+	if LocalCapabilities()&CapabilityCompression.Mask() == 0 {
+		t.Error("compression capability should be registered by init")
+	}
+	if LocalCapabilities()&CapabilityQUIC.Mask() == 0 {
+		t.Error("QUIC capability should be registered by init")
+	}
+	if got := CapabilityCompression.String(); got != "compression" {
+		t.Errorf("CapabilityCompression.String() = %q, want %q", got, "compression")
+	}
+	if got := CapabilityBit(63).String(); got != "CapabilityBit(63)" {
+		t.Errorf("unregistered bit String() = %q", got)
+	}
+
+	names := CapabilityNames(CapabilityCompression.Mask() | CapabilityBit(63).Mask())
+	if want := []string{"compression", "CapabilityBit(63)"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("CapabilityNames() = %v, want %v", names, want)
+	}
+}
+
+func TestRegisterCapabilityPanics(t *testing.T) {
+	tests := []struct {
+		name string
+		bit  CapabilityBit
+	}{
+		{"duplicate", CapabilityCompression},
+		{"out of range", MaxCapabilityBits},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("RegisterCapability did not panic")
+				}
+			}()
+			RegisterCapability(tt.bit, "test")
+		})
+	}
+}
+
+func TestCapabilityPayload(t *testing.T) {
+	const flags = uint64(1<<63 | 1<<2)
+	if got := decodeCapabilityPayload(encodeCapabilityPayload(flags)); got != flags {
+		t.Errorf("payload round trip: got %x, want %x", got, flags)
+	}
+	// Peers that predate capability flags send no payload
+	if got := decodeCapabilityPayload(nil); got != 0 {
+		t.Errorf("empty payload: got %x, want 0", got)
+	}
+	if got := decodeCapabilityPayload([]byte{0x00, 0, 0, 0, 0, 0, 0, 0, 1}); got != 0 {
+		t.Errorf("unknown vendor: got %x, want 0", got)
+	}
+}
+
+// newCapabilityManagers returns Noise-IK handshake managers for an
+// initiator and a responder, and the responder's public key.
+func newCapabilityManagers(t *testing.T, initiatorFlags, responderFlags uint64) (*VersionedHandshakeManager, *VersionedHandshakeManager, [32]byte) {
+	t.Helper()
+	initiatorKeys, err := crypto.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	responderKeys, err := crypto.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	versions := []ProtocolVersion{ProtocolLegacy, ProtocolNoiseIK}
+	initiator := NewVersionedHandshakeManager(initiatorKeys.Private, versions, ProtocolNoiseIK)
+	initiator.SetLocalCapabilities(initiatorFlags)
+	responder := NewVersionedHandshakeManager(responderKeys.Private, versions, ProtocolNoiseIK)
+	responder.SetLocalCapabilities(responderFlags)
+	return initiator, responder, responderKeys.Public
+}
+
+func TestHandshakeCapabilitiesNotInPlaintext(t *testing.T) {
+	flags := CapabilityCompression.Mask() | CapabilityBit(63).Mask()
+	initiator, _, responderKey := newCapabilityManagers(t, flags, 0)
+
+	message, _, err := initiator.prepareNoiseHandshake(responderKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := SerializeVersionedHandshakeRequest(initiator.createHandshakeRequest(message, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(data, encodeCapabilityPayload(flags)) {
+		t.Error("capability flags should not appear in the clear")
+	}
+}
+
+func TestNegotiatedCapabilitiesResponder(t *testing.T) {
+	initiator, responder, responderKey := newCapabilityManagers(t,
+		CapabilityCompression.Mask()|CapabilityQUIC.Mask(),
+		CapabilityCompression.Mask()|CapabilityREDAudio.Mask())
+	peerAddr, _ := net.ResolveUDPAddr("udp", "127.0.0.1:8081")
+
+	if _, err := responder.GetNegotiatedCapabilities(peerAddr); !errors.Is(err, ErrNoNegotiatedCapabilities) {
+		t.Errorf("expected ErrNoNegotiatedCapabilities before handshake, got %v", err)
+	}
+	if responder.HasCapability(peerAddr, CapabilityCompression) {
+		t.Error("HasCapability should be false before handshake")
+	}
+
+	message, noiseHandshake, err := initiator.prepareNoiseHandshake(responderKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	response, err := responder.HandleHandshakeRequest(initiator.createHandshakeRequest(message, nil), NewMockTransport("127.0.0.1:8080"), peerAddr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	advertised, err := readResponseCapabilities(noiseHandshake, response)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if advertised != responder.GetLocalCapabilities() {
+		t.Errorf("response advertised %x, want %x", advertised, responder.GetLocalCapabilities())
+	}
+
+	flags, err := responder.GetNegotiatedCapabilities(peerAddr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if flags != CapabilityCompression.Mask() {
+		t.Errorf("negotiated %x, want %x", flags, CapabilityCompression.Mask())
+	}
+	if !responder.HasCapability(peerAddr, CapabilityCompression) {
+		t.Error("compression should be negotiated")
+	}
+	if responder.HasCapability(peerAddr, CapabilityREDAudio) || responder.HasCapability(peerAddr, CapabilityQUIC) {
+		t.Error("capabilities supported by only one side should not be negotiated")
+	}
+
+	responder.ForgetCapabilities(peerAddr)
+	if responder.HasCapability(peerAddr, CapabilityCompression) {
+		t.Error("capabilities should be forgotten")
+	}
+}
+
+func TestNegotiatedCapabilitiesLegacy(t *testing.T) {
+	manager := NewVersionedHandshakeManager([32]byte{1}, []ProtocolVersion{ProtocolLegacy}, ProtocolLegacy)
+	manager.SetLocalCapabilities(CapabilityCompression.Mask())
+	peerAddr, _ := net.ResolveUDPAddr("udp", "127.0.0.1:8081")
+
+	// A legacy handshake has no authenticated payload to carry flags
+	_, err := manager.HandleHandshakeRequest(&VersionedHandshakeRequest{
+		ProtocolVersion:   ProtocolLegacy,
+		SupportedVersions: []ProtocolVersion{ProtocolLegacy},
+		LegacyData:        encodeCapabilityPayload(CapabilityCompression.Mask()),
+	}, NewMockTransport("127.0.0.1:8080"), peerAddr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if flags, err := manager.GetNegotiatedCapabilities(peerAddr); err != nil || flags != 0 {
+		t.Errorf("negotiated %x (%v), want 0", flags, err)
+	}
+}
+
+// answerHandshake runs InitiateHandshake against responder, letting
+// tamper modify the serialized response, and returns its error.
+func answerHandshake(t *testing.T, initiator, responder *VersionedHandshakeManager, responderKey [32]byte, peerAddr net.Addr, tamper func([]byte)) error {
+	t.Helper()
+	initiator.handshakeTimeout = time.Second
+	mockTransport := NewMockTransport("127.0.0.1:8080")
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := initiator.InitiateHandshake(responderKey, mockTransport, peerAddr)
+		done <- err
+	}()
+
+	// Wait for the request, then answer it
+	deadline := time.Now().Add(time.Second)
+	for len(mockTransport.GetPackets()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	packets := mockTransport.GetPackets()
+	if len(packets) != 1 {
+		t.Fatalf("expected handshake request, got %d packets", len(packets))
+	}
+	request, err := ParseVersionedHandshakeRequest(packets[0].packet.Data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	response, err := responder.HandleHandshakeRequest(request, NewMockTransport("127.0.0.1:8081"), mockTransport.LocalAddr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := SerializeVersionedHandshakeResponse(response)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tamper(data)
+	if err := mockTransport.SimulateReceive(&Packet{PacketType: PacketNoiseHandshake, Data: data}, peerAddr); err != nil {
+		t.Fatal(err)
+	}
+	return <-done
+}
+
+func TestNegotiatedCapabilitiesInitiator(t *testing.T) {
+	initiator, responder, responderKey := newCapabilityManagers(t,
+		CapabilityREDAudio.Mask()|CapabilityGroupHistory.Mask(),
+		CapabilityREDAudio.Mask()|CapabilityCompression.Mask())
+	peerAddr, _ := net.ResolveUDPAddr("udp", "127.0.0.1:8081")
+
+	if err := answerHandshake(t, initiator, responder, responderKey, peerAddr, func([]byte) {}); err != nil {
+		t.Fatal(err)
+	}
+	if flags, _ := initiator.GetNegotiatedCapabilities(peerAddr); flags != CapabilityREDAudio.Mask() {
+		t.Errorf("negotiated %x, want %x", flags, CapabilityREDAudio.Mask())
+	}
+}
+
+func TestNegotiatedCapabilitiesTamperedResponse(t *testing.T) {
+	initiator, responder, responderKey := newCapabilityManagers(t, CapabilityREDAudio.Mask(), CapabilityREDAudio.Mask())
+	peerAddr, _ := net.ResolveUDPAddr("udp", "127.0.0.1:8081")
+
+	// Flip a bit in the encrypted payload at the end of the Noise message
+	err := answerHandshake(t, initiator, responder, responderKey, peerAddr, func(data []byte) { data[len(data)-1] ^= 0x01 })
+	if err == nil {
+		t.Fatal("expected a tampered handshake response to be rejected")
+	}
+	if _, err := initiator.GetNegotiatedCapabilities(peerAddr); !errors.Is(err, ErrNoNegotiatedCapabilities) {
+		t.Errorf("expected no capabilities after a failed handshake, got %v", err)
+	}
+}
@@ -0,0 +1,267 @@
+package transport
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+
+	"github.com/klauspost/compress/snappy"
+	"github.com/klauspost/compress/zstd"
+	"github.com/sirupsen/logrus"
+)
+
+func init() {
+	RegisterCapability(CapabilityCompression, "compression")
+}
+
+// CompressionAlgo identifies the algorithm used in a compressed envelope.
+type CompressionAlgo uint8
+
+const (
+	// CompressionZstd compresses with Zstandard: better ratios, more CPU.
+	CompressionZstd CompressionAlgo = 1
+	// CompressionSnappy compresses with Snappy: lower ratios, very fast.
+	CompressionSnappy CompressionAlgo = 2
+)
+
+// String returns the algorithm name.
+func (a CompressionAlgo) String() string {
+	switch a {
+	case CompressionZstd:
+		return "zstd"
+	case CompressionSnappy:
+		return "snappy"
+	default:
+		return fmt.Sprintf("CompressionAlgo(%d)", uint8(a))
+	}
+}
+
+const (
+	// DefaultCompressionThreshold is the payload size in bytes above which
+	// packets are compressed when no threshold is given.
+	DefaultCompressionThreshold = 1024
+
+	// compressionHeaderSize is algorithm(1) + original size(4) + packet type(1).
+	compressionHeaderSize = 6
+
+	// maxDecompressedSize bounds the original size a peer may claim, so a
+	// small envelope cannot expand into an arbitrarily large allocation.
+	maxDecompressedSize = 1024 * 1024 // limits.MaxProcessingBuffer
+)
+
+var (
+	// ErrUnsupportedCompression is returned for an unknown CompressionAlgo.
+	ErrUnsupportedCompression = errors.New("unsupported compression algorithm")
+	// ErrInvalidCompressedPacket is returned for a malformed compressed
+	// envelope.
+	ErrInvalidCompressedPacket = errors.New("invalid compressed packet")
+)
+
+// compressedTransport wraps a Transport, compressing large packets on Send
+// and transparently decompressing PacketCompressed envelopes on receipt.
+type compressedTransport struct {
+	Transport
+	threshold int
+	algorithm CompressionAlgo
+
+	encoder *zstd.Encoder
+	decoder *zstd.Decoder
+
+	mu       sync.RWMutex
+	handlers map[PacketType]PacketHandler
+}
+
+// CompressedTransport wraps underlying so that packets whose payload is
+// larger than threshold bytes are sent as a PacketCompressed envelope:
+//
+//	[algorithm(1)][original_size(4, big-endian)][packet_type(1)][compressed_data]
+//
+// Smaller packets, and packets that do not shrink, pass through unmodified.
+// Received envelopes are decompressed (with either algorithm, regardless of
+// the one configured for sending) and dispatched to the handler registered
+// for the original packet type. Both peers must use the wrapper; a
+// threshold of 0 or less uses DefaultCompressionThreshold.
+//
+// Compression is applied to the packet payload as given. Encrypted payloads
+// are incompressible, so wrap the transport below any encrypting layer for
+// it to be effective. An unsupported algorithm leaves underlying unwrapped.
+func CompressedTransport(underlying Transport, threshold int, algorithm CompressionAlgo) Transport {
+	ct, err := newCompressedTransport(underlying, threshold, algorithm)
+	if err != nil {
+		pkgLog.WithFields(logrus.Fields{
+			"function":  "CompressedTransport",
+			"algorithm": algorithm.String(),
+			"error":     err.Error(),
+		}).Warn("Compression disabled")
+		return underlying
+	}
+	return ct
+}
+
+// newCompressedTransport creates the wrapper and registers its envelope
+// handler on underlying.
+func newCompressedTransport(underlying Transport, threshold int, algorithm CompressionAlgo) (*compressedTransport, error) {
+	if algorithm != CompressionZstd && algorithm != CompressionSnappy {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedCompression, algorithm)
+	}
+	if threshold <= 0 {
+		threshold = DefaultCompressionThreshold
+	}
+
+	ct := &compressedTransport{
+		Transport: underlying,
+		threshold: threshold,
+		algorithm: algorithm,
+		handlers:  make(map[PacketType]PacketHandler),
+	}
+
+	var err error
+	if algorithm == CompressionZstd {
+		ct.encoder, err = zstd.NewWriter(nil,
+			zstd.WithEncoderLevel(zstd.SpeedDefault),
+			zstd.WithWindowSize(maxDecompressedSize))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create zstd encoder: %w", err)
+		}
+	}
+	ct.decoder, err = zstd.NewReader(nil, zstd.WithDecoderMaxMemory(maxDecompressedSize))
+	if err != nil {
+		if ct.encoder != nil {
+			ct.encoder.Close()
+		}
+		return nil, fmt.Errorf("failed to create zstd decoder: %w", err)
+	}
+
+	underlying.RegisterHandler(PacketCompressed, ct.handleCompressed)
+	return ct, nil
+}
+
+// Send compresses packets above the threshold before forwarding them.
+func (ct *compressedTransport) Send(packet *Packet, addr net.Addr) error {
+	if packet == nil || len(packet.Data) <= ct.threshold || packet.PacketType == PacketCompressed {
+		return ct.Transport.Send(packet, addr)
+	}
+
+	envelope, ok := ct.compress(packet)
+	if !ok {
+		return ct.Transport.Send(packet, addr)
+	}
+	return ct.Transport.Send(envelope, addr)
+}
+
+// compress builds a PacketCompressed envelope for packet. It reports false
+// if compression would not make the packet smaller.
+func (ct *compressedTransport) compress(packet *Packet) (*Packet, bool) {
+	if len(packet.Data) > maxDecompressedSize {
+		return nil, false
+	}
+
+	header := make([]byte, compressionHeaderSize, compressionHeaderSize+len(packet.Data))
+	header[0] = byte(ct.algorithm)
+	binary.BigEndian.PutUint32(header[1:5], uint32(len(packet.Data)))
+	header[5] = byte(packet.PacketType)
+
+	var data []byte
+	switch ct.algorithm {
+	case CompressionZstd:
+		data = ct.encoder.EncodeAll(packet.Data, header)
+	case CompressionSnappy:
+		data = append(header, snappy.Encode(nil, packet.Data)...)
+	}
+
+	if len(data) >= len(packet.Data) {
+		return nil, false
+	}
+	return &Packet{PacketType: PacketCompressed, Data: data}, true
+}
+
+// RegisterHandler records handler for envelopes carrying packetType and
+// registers it on the underlying transport for uncompressed packets.
+func (ct *compressedTransport) RegisterHandler(packetType PacketType, handler PacketHandler) {
+	if packetType == PacketCompressed {
+		return
+	}
+	ct.mu.Lock()
+	ct.handlers[packetType] = handler
+	ct.mu.Unlock()
+	ct.Transport.RegisterHandler(packetType, handler)
+}
+
+// handleCompressed decompresses an envelope and dispatches the original
+// packet.
+func (ct *compressedTransport) handleCompressed(packet *Packet, addr net.Addr) error {
+	inner, err := ct.decompress(packet.Data)
+	if err != nil {
+		pkgLog.WithFields(logrus.Fields{
+			"function":    "handleCompressed",
+			"source_addr": addr.String(),
+			"error":       err.Error(),
+		}).Debug("Dropping invalid compressed packet")
+		return err
+	}
+
+	ct.mu.RLock()
+	handler, ok := ct.handlers[inner.PacketType]
+	ct.mu.RUnlock()
+	if !ok {
+		return nil
+	}
+	return handler(inner, addr)
+}
+
+// decompress parses an envelope and returns the original packet.
+func (ct *compressedTransport) decompress(data []byte) (*Packet, error) {
+	if len(data) < compressionHeaderSize {
+		return nil, fmt.Errorf("%w: %d bytes", ErrInvalidCompressedPacket, len(data))
+	}
+	algorithm := CompressionAlgo(data[0])
+	originalSize := binary.BigEndian.Uint32(data[1:5])
+	packetType := PacketType(data[5])
+	payload := data[compressionHeaderSize:]
+
+	if originalSize > maxDecompressedSize {
+		return nil, fmt.Errorf("%w: original size %d exceeds %d", ErrInvalidCompressedPacket, originalSize, maxDecompressedSize)
+	}
+	if packetType == PacketCompressed {
+		return nil, fmt.Errorf("%w: nested envelope", ErrInvalidCompressedPacket)
+	}
+
+	var (
+		decoded []byte
+		err     error
+	)
+	switch algorithm {
+	case CompressionZstd:
+		decoded, err = ct.decoder.DecodeAll(payload, make([]byte, 0, originalSize))
+	case CompressionSnappy:
+		var n int
+		n, err = snappy.DecodedLen(payload)
+		if err == nil && n != int(originalSize) {
+			err = fmt.Errorf("decoded length %d does not match %d", n, originalSize)
+		}
+		if err == nil {
+			decoded, err = snappy.Decode(make([]byte, originalSize), payload)
+		}
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedCompression, algorithm)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrInvalidCompressedPacket, algorithm, err)
+	}
+	if len(decoded) != int(originalSize) {
+		return nil, fmt.Errorf("%w: size %d does not match %d", ErrInvalidCompressedPacket, len(decoded), originalSize)
+	}
+
+	return &Packet{PacketType: packetType, Data: decoded}, nil
+}
+
+// Close releases the codecs and closes the underlying transport.
+func (ct *compressedTransport) Close() error {
+	if ct.encoder != nil {
+		ct.encoder.Close()
+	}
+	ct.decoder.Close()
+	return ct.Transport.Close()
+}
@@ -0,0 +1,250 @@
+package transport
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// loopbackTransport delivers every sent packet straight to its own handlers
+// and records what went out on the wire.
+type loopbackTransport struct {
+	mu       sync.Mutex
+	handlers map[PacketType]PacketHandler
+	sent     []*Packet
+}
+
+func newLoopbackTransport() *loopbackTransport {
+	return &loopbackTransport{handlers: make(map[PacketType]PacketHandler)}
+}
+
+func (l *loopbackTransport) Send(packet *Packet, addr net.Addr) error {
+	l.mu.Lock()
+	l.sent = append(l.sent, packet)
+	handler := l.handlers[packet.PacketType]
+	l.mu.Unlock()
+	if handler != nil {
+		return handler(packet, addr)
+	}
+	return nil
+}
+
+func (l *loopbackTransport) RegisterHandler(packetType PacketType, handler PacketHandler) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.handlers[packetType] = handler
+}
+
+func (l *loopbackTransport) lastSent() *Packet {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.sent[len(l.sent)-1]
+}
+
+func (l *loopbackTransport) Close() error               { return nil }
+func (l *loopbackTransport) LocalAddr() net.Addr        { return &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)} }
+func (l *loopbackTransport) IsConnectionOriented() bool { return false }
+
+// groupHistoryPayload returns a JSON payload resembling a group history
+// snapshot.
+func groupHistoryPayload(size int) []byte {
+	type entry struct {
+		Peer      string    `json:"peer"`
+		Message   string    `json:"message"`
+		Timestamp time.Time `json:"timestamp"`
+	}
+	var buf bytes.Buffer
+	base := time.Unix(1700000000, 0).UTC()
+	for i := 0; buf.Len() < size; i++ {
+		data, _ := json.Marshal(entry{
+			Peer:      fmt.Sprintf("peer-%d", i%7),
+			Message:   fmt.Sprintf("message number %d in the conversation", i),
+			Timestamp: base.Add(time.Duration(i) * time.Second),
+		})
+		buf.Write(data)
+	}
+	return buf.Bytes()[:size]
+}
+
+func randomPayload(size int) []byte {
+	data := make([]byte, size)
+	_, _ = rand.Read(data)
+	return data
+}
+
+func TestCompressedTransportRoundTrip(t *testing.T) {
+	for _, algo := range []CompressionAlgo{CompressionZstd, CompressionSnappy} {
+		t.Run(algo.String(), func(t *testing.T) {
+			lo := newLoopbackTransport()
+			ct := CompressedTransport(lo, 256, algo)
+
+			var got *Packet
+			ct.RegisterHandler(PacketFileData, func(p *Packet, _ net.Addr) error {
+				got = p
+				return nil
+			})
+
+			data := groupHistoryPayload(16 * 1024)
+			require.NoError(t, ct.Send(&Packet{PacketType: PacketFileData, Data: data}, lo.LocalAddr()))
+
+			wire := lo.lastSent()
+			assert.Equal(t, PacketCompressed, wire.PacketType)
+			assert.Equal(t, byte(algo), wire.Data[0])
+			assert.Less(t, len(wire.Data), len(data))
+
+			require.NotNil(t, got)
+			assert.Equal(t, PacketFileData, got.PacketType)
+			assert.Equal(t, data, got.Data)
+		})
+	}
+}
+
+func TestCompressedTransportPassThrough(t *testing.T) {
+	lo := newLoopbackTransport()
+	ct := CompressedTransport(lo, 256, CompressionZstd)
+
+	var received [][]byte
+	ct.RegisterHandler(PacketFileData, func(p *Packet, _ net.Addr) error {
+		received = append(received, p.Data)
+		return nil
+	})
+
+	// Below the threshold
+	small := groupHistoryPayload(200)
+	require.NoError(t, ct.Send(&Packet{PacketType: PacketFileData, Data: small}, lo.LocalAddr()))
+	assert.Equal(t, PacketFileData, lo.lastSent().PacketType)
+
+	// Incompressible
+	random := randomPayload(4096)
+	require.NoError(t, ct.Send(&Packet{PacketType: PacketFileData, Data: random}, lo.LocalAddr()))
+	assert.Equal(t, PacketFileData, lo.lastSent().PacketType)
+
+	assert.Equal(t, [][]byte{small, random}, received)
+}
+
+func TestCompressedTransportReceivesEitherAlgorithm(t *testing.T) {
+	lo := newLoopbackTransport()
+	receiver := CompressedTransport(lo, 0, CompressionZstd)
+
+	var got []byte
+	receiver.RegisterHandler(PacketFileData, func(p *Packet, _ net.Addr) error {
+		got = p.Data
+		return nil
+	})
+
+	sender, err := newCompressedTransport(newLoopbackTransport(), 0, CompressionSnappy)
+	require.NoError(t, err)
+	data := groupHistoryPayload(8192)
+	envelope, ok := sender.compress(&Packet{PacketType: PacketFileData, Data: data})
+	require.True(t, ok)
+
+	require.NoError(t, lo.Send(envelope, lo.LocalAddr()))
+	assert.Equal(t, data, got)
+}
+
+func TestCompressedTransportRejectsInvalidEnvelopes(t *testing.T) {
+	ct, err := newCompressedTransport(newLoopbackTransport(), 0, CompressionZstd)
+	require.NoError(t, err)
+
+	snappyEnvelope, _ := (&compressedTransport{algorithm: CompressionSnappy, threshold: 1}).compress(
+		&Packet{PacketType: PacketFileData, Data: groupHistoryPayload(4096)})
+	require.NotNil(t, snappyEnvelope)
+	wrongSize := append([]byte(nil), snappyEnvelope.Data...)
+	wrongSize[4]++
+
+	tests := map[string][]byte{
+		"short":          {byte(CompressionZstd), 0, 0},
+		"unknown algo":   {9, 0, 0, 0, 4, byte(PacketFileData), 1, 2, 3, 4},
+		"too large":      {byte(CompressionZstd), 0xFF, 0xFF, 0xFF, 0xFF, byte(PacketFileData)},
+		"nested":         {byte(CompressionZstd), 0, 0, 0, 1, byte(PacketCompressed), 0},
+		"corrupt":        {byte(CompressionZstd), 0, 0, 0, 8, byte(PacketFileData), 1, 2, 3, 4},
+		"size mismatch":  wrongSize,
+		"snappy garbage": {byte(CompressionSnappy), 0, 0, 0, 8, byte(PacketFileData), 0xFF, 0xFF},
+	}
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := ct.decompress(data)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestCompressedTransportUnsupportedAlgorithm(t *testing.T) {
+	lo := newLoopbackTransport()
+	assert.Same(t, Transport(lo), CompressedTransport(lo, 0, CompressionAlgo(42)))
+
+	_, err := newCompressedTransport(lo, 0, CompressionAlgo(42))
+	assert.ErrorIs(t, err, ErrUnsupportedCompression)
+}
+
+// BenchmarkCompressedTransport reports the compression ratio (wire bytes
+// over original bytes) and time per packet for typical large payloads.
+func BenchmarkCompressedTransport(b *testing.B) {
+	payloads := []struct {
+		name string
+		data []byte
+	}{
+		{"FileChunkText", bytes.Repeat([]byte("The quick brown fox jumps over the lazy dog. "), 31)[:1371]},
+		{"FileChunkRandom", randomPayload(1371)},
+		{"GroupHistory16K", groupHistoryPayload(16 * 1024)},
+		{"GroupHistory64K", groupHistoryPayload(64 * 1024)},
+	}
+
+	for _, algo := range []CompressionAlgo{CompressionZstd, CompressionSnappy} {
+		for _, p := range payloads {
+			b.Run(algo.String()+"/"+p.name, func(b *testing.B) {
+				lo := newLoopbackTransport()
+				ct, err := newCompressedTransport(lo, 256, algo)
+				if err != nil {
+					b.Fatal(err)
+				}
+				packet := &Packet{PacketType: PacketFileData, Data: p.data}
+
+				b.SetBytes(int64(len(p.data)))
+				b.ReportAllocs()
+				wire := 0
+				for i := 0; i < b.N; i++ {
+					if envelope, ok := ct.compress(packet); ok {
+						wire = len(envelope.Data)
+					} else {
+						wire = len(p.data)
+					}
+				}
+				b.ReportMetric(float64(wire)/float64(len(p.data)), "ratio")
+			})
+		}
+	}
+}
+
+// BenchmarkDecompress measures receive-side CPU cost per envelope.
+func BenchmarkDecompress(b *testing.B) {
+	data := groupHistoryPayload(16 * 1024)
+	for _, algo := range []CompressionAlgo{CompressionZstd, CompressionSnappy} {
+		b.Run(algo.String(), func(b *testing.B) {
+			ct, err := newCompressedTransport(newLoopbackTransport(), 256, algo)
+			if err != nil {
+				b.Fatal(err)
+			}
+			envelope, ok := ct.compress(&Packet{PacketType: PacketFileData, Data: data})
+			if !ok {
+				b.Fatal("payload did not compress")
+			}
+
+			b.SetBytes(int64(len(data)))
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := ct.decompress(envelope.Data); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -149,7 +149,7 @@ func (ct *CoverTrafficManager) runPeer(addr net.Addr, stop <-chan struct{}) {
 	for {
 		delay, err := ct.randomDelay()
 		if err != nil {
-			logrus.WithError(err).Warn("CoverTrafficManager: failed to generate random delay; using MinInterval")
+			pkgLog.WithError(err).Warn("CoverTrafficManager: failed to generate random delay; using MinInterval")
 			delay = ct.config.minInterval()
 		}
 
@@ -167,7 +167,7 @@ func (ct *CoverTrafficManager) runPeer(addr net.Addr, stop <-chan struct{}) {
 		}
 
 		if err := ct.sendDummy(addr); err != nil {
-			logrus.WithFields(logrus.Fields{
+			pkgLog.WithFields(logrus.Fields{
 				"peer":  addr.String(),
 				"error": err,
 			}).Debug("CoverTrafficManager: dummy send failed (session may not be ready)")
@@ -32,6 +32,20 @@
 //	transport, err := NewTCPTransport(":33445")
 //	// Connection-oriented, reliable delivery, NAT traversal support
 //
+// WebSocket Transport:
+//
+//	tlsConfig, err := LoadWebSocketTLSConfig("cert.pem", "key.pem")
+//	transport, err := NewWebSocketTransport(":443", tlsConfig)
+//	// Binary WebSocket frames over ws:// or wss://, for networks that only
+//	// allow HTTP(S); connection-oriented like TCP
+//
+// QUIC Transport:
+//
+//	transport, err := NewQUICTransportWithOptions(":33445", tlsConfig,
+//	    QUICOptions{Delivery: QUICDeliveryDatagrams})
+//	// TLS 1.3 over UDP with 0-RTT resumption and connection migration;
+//	// QUICOptions.SkipNoise lets mutual TLS replace the Noise-IK layer
+//
 // Noise Transport (encrypted wrapper):
 //
 //	noiseTransport := NewNoiseTransport(underlying, keypair, nil)
@@ -43,6 +57,11 @@
 //	proxyTransport, err := NewProxyTransport(underlying, config)
 //	// Routes traffic through Tor, I2P, or other proxy services
 //
+// Compressed Transport (compression wrapper):
+//
+//	compressed := CompressedTransport(underlying, 1024, CompressionZstd)
+//	// Compresses payloads over 1 KiB (zstd or snappy); both peers must wrap
+//
 // # Noise Protocol Integration
 //
 // The Noise-IK pattern provides mutual authentication, forward secrecy, and
@@ -52,6 +71,17 @@
 //   - Handshake negotiation with replay protection via nonce tracking
 //   - Timestamp freshness validation (HandshakeMaxAge = 5 minutes)
 //   - Session lifecycle with idle timeout cleanup (SessionIdleTimeout = 5 minutes)
+//   - Per-handshake deadlines (HandshakeTimeout = 30 seconds, configurable with
+//     SetHandshakeTimeout); stuck handshakes are reaped and return ErrHandshakeTimeout
+//   - Remote static key pinning per address (SetKeyPinStore); a handshake
+//     presenting a different key than the first one seen is dropped with
+//     noise.ErrKeyPinMismatch
+//   - A single XX fallback handshake (PacketNoiseFallback) when the peer
+//     rejects an IK initiation; the rejection must echo the initiation's
+//     ephemeral key, and the fallback is dropped with
+//     ErrNoiseFallbackKeyMismatch unless the peer proves the expected key
+//   - Optional in-band rekeying (SetInBandRekeyThreshold): both peers hash
+//     each direction's key forward every N messages without a round trip
 //   - Transparent encryption/decryption of all packet types except handshakes
 //
 // # Multi-Network Support
@@ -75,12 +105,54 @@
 //   - UDP hole punching with persistent keepalive
 //   - Advanced NAT detection with relay fallback for symmetric NAT
 //
+// # QoS Marking
+//
+// UDPTransport can set the DSCP field on outgoing packets so routers that
+// honour DiffServ prioritize real-time traffic. SetDSCPClass applies one
+// class to the whole socket; EnableDSCPMarking marks per packet using
+// DSCPClassForPacket (EF for audio/video, CS6 for DHT control, CS0 otherwise).
+// Marking is a no-op on platforms without IP_TOS support.
+//
+// # Connection Migration
+//
+// SetMigrationEnabled makes UDPTransport follow the host across network
+// changes. OS change notifications (netlink, routing sockets or the IP
+// Helper API) trigger a re-bind of the socket on the same port, a refreshed
+// UPnP mapping when SetMigrationUPnP is set, and a PacketMigrationNotify to
+// recently active peers so their DHT routing tables pick up the new address
+// once a ping round trip with it succeeds. Migrate performs the same steps
+// on demand.
+//
+// # OpenTelemetry
+//
+// InstrumentedTransport wraps any Transport so each Send produces a
+// "transport.Send" span and feeds the toxcore_transport_send_duration_ms
+// histogram and toxcore_transport_errors_total counter. Only the OTel API is
+// imported; build with -tags otel_off to drop the dependency entirely, in
+// which case the wrapper returns the underlying transport.
+//
 // # Version Negotiation
 //
 // Protocol version negotiation ensures backward compatibility as the Tox
 // protocol evolves. The VersionedHandshakeManager coordinates version
 // discovery and capability exchange during connection establishment.
 //
+// Alongside the version, each Noise-IK handshake carries 64-bit capability
+// flags in its encrypted, authenticated payloads, so optional features do
+// not need a version bump. A feature claims a bit from
+// an init function, and callers check the flags negotiated with a peer (the
+// bitwise AND of both sides) before using the extension:
+//
+//	func init() {
+//	    transport.RegisterCapability(transport.CapabilityCompression, "compression")
+//	}
+//
+//	if handshakeManager.HasCapability(peerAddr, transport.CapabilityCompression) {
+//	    // peer understands PacketCompressed
+//	}
+//
+// Peers that predate capability flags, and legacy handshakes, negotiate zero.
+//
 // # Packet Types
 //
 // All Tox packet types are defined in packet.go with type-safe constants:
@@ -120,5 +192,6 @@
 //	    ErrNoiseSessionNotFound // No active session with peer
 //	    ErrHandshakeReplay     // Replay attack detected
 //	    ErrHandshakeTooOld     // Handshake timestamp expired
+//	    ErrHandshakeTimeout    // Handshake did not complete in time
 //	)
 package transport
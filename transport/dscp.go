@@ -0,0 +1,131 @@
+package transport
+
+import (
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+)
+
+// DSCPClass is a Differentiated Services Code Point (RFC 2474) used to ask
+// network equipment to prioritize packets.
+type DSCPClass uint8
+
+const (
+	// DSCPClassCS0 is the default best-effort class.
+	DSCPClassCS0 DSCPClass = 0
+	// DSCPClassEF is Expedited Forwarding (RFC 3246), used for real-time audio and video.
+	DSCPClassEF DSCPClass = 46
+	// DSCPClassCS6 is Class Selector 6, used for network control traffic such
+	// as DHT pings and handshakes.
+	DSCPClassCS6 DSCPClass = 48
+)
+
+// String returns the conventional name of the class.
+func (c DSCPClass) String() string {
+	switch c {
+	case DSCPClassCS0:
+		return "CS0"
+	case DSCPClassEF:
+		return "EF"
+	case DSCPClassCS6:
+		return "CS6"
+	default:
+		return fmt.Sprintf("DSCP(%d)", uint8(c))
+	}
+}
+
+// TOS returns the IP TOS / IPv6 traffic class byte for the class.
+// The DSCP occupies the upper six bits; the ECN bits are left clear.
+func (c DSCPClass) TOS() int {
+	return int(c) << 2
+}
+
+// DSCPClassForPacket returns the class used for packetType when per-packet
+// DSCP marking is enabled.
+func DSCPClassForPacket(packetType PacketType) DSCPClass {
+	switch packetType {
+	case PacketAVAudioFrame, PacketAVVideoFrame:
+		return DSCPClassEF
+	case PacketPingRequest, PacketPingResponse, PacketGetNodes, PacketSendNodes,
+		PacketNoiseHandshake, PacketNoiseFallback, PacketVersionNegotiation, PacketDHTRequest:
+		return DSCPClassCS6
+	default:
+		return DSCPClassCS0
+	}
+}
+
+// SetDSCPClass marks every packet sent by the transport with class and
+// disables per-packet marking. On platforms without IP_TOS support this
+// is a no-op.
+func (t *UDPTransport) SetDSCPClass(class DSCPClass) error {
+	if class > 63 {
+		return fmt.Errorf("invalid DSCP class: %d", class)
+	}
+
+	t.dscpMu.Lock()
+	defer t.dscpMu.Unlock()
+	if err := setSocketDSCP(t.packetConn(), class); err != nil {
+		return fmt.Errorf("failed to set DSCP class %s: %w", class, err)
+	}
+	t.dscpClass = class
+	t.dscpPerPacket = false
+
+	pkgLog.WithFields(logrus.Fields{
+		"function":   "SetDSCPClass",
+		"class":      class.String(),
+		"local_addr": t.listenAddr.String(),
+	}).Debug("Set socket DSCP class")
+	return nil
+}
+
+// EnableDSCPMarking turns per-packet DSCP marking on or off. When enabled,
+// each packet is sent with DSCPClassForPacket: EF for audio and video frames,
+// CS6 for DHT and handshake traffic, and best-effort otherwise.
+//
+// The class is a socket option, so marked sends are serialized and the
+// option is only changed when consecutive packets differ in class.
+func (t *UDPTransport) EnableDSCPMarking(enabled bool) error {
+	t.dscpMu.Lock()
+	defer t.dscpMu.Unlock()
+	if !enabled && t.dscpPerPacket && t.dscpClass != DSCPClassCS0 {
+		if err := setSocketDSCP(t.packetConn(), DSCPClassCS0); err != nil {
+			return fmt.Errorf("failed to reset DSCP class: %w", err)
+		}
+		t.dscpClass = DSCPClassCS0
+	}
+	t.dscpPerPacket = enabled
+	return nil
+}
+
+// DSCPClass returns the class currently set on the socket.
+func (t *UDPTransport) DSCPClass() DSCPClass {
+	t.dscpMu.Lock()
+	defer t.dscpMu.Unlock()
+	return t.dscpClass
+}
+
+// writeMarked writes data with the DSCP class for packetType when
+// per-packet marking is enabled. It reports whether it handled the write.
+func (t *UDPTransport) writeMarked(packetType PacketType, write func() (int, error)) (bool, int, error) {
+	t.dscpMu.Lock()
+	defer t.dscpMu.Unlock()
+	if !t.dscpPerPacket {
+		return false, 0, nil
+	}
+
+	class := DSCPClassForPacket(packetType)
+	if class != t.dscpClass {
+		if err := setSocketDSCP(t.packetConn(), class); err != nil {
+			// Marking is advisory; send unmarked rather than drop the packet
+			pkgLog.WithFields(logrus.Fields{
+				"function": "writeMarked",
+				"class":    class.String(),
+				"error":    err.Error(),
+			}).Debug("Failed to change DSCP class")
+		} else {
+			t.dscpClass = class
+		}
+	}
+	n, err := write()
+	return true, n, err
+}
@@ -0,0 +1,10 @@
+//go:build !linux && !freebsd && !darwin && !netbsd && !openbsd
+
+package transport
+
+import "net"
+
+// setSocketDSCP is a no-op on platforms without IP_TOS support.
+func setSocketDSCP(conn net.PacketConn, class DSCPClass) error {
+	return nil
+}
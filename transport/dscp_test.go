@@ -0,0 +1,102 @@
+//go:build linux
+
+package transport
+
+import (
+	"net"
+	"syscall"
+	"testing"
+)
+
+func TestDSCPClassValues(t *testing.T) {
+	tests := []struct {
+		class DSCPClass
+		name  string
+		tos   int
+	}{
+		{DSCPClassCS0, "CS0", 0x00},
+		{DSCPClassEF, "EF", 0xb8},
+		{DSCPClassCS6, "CS6", 0xc0},
+	}
+	for _, tt := range tests {
+		if tt.class.String() != tt.name || tt.class.TOS() != tt.tos {
+			t.Errorf("%v: got %s/0x%x, want %s/0x%x", uint8(tt.class), tt.class, tt.class.TOS(), tt.name, tt.tos)
+		}
+	}
+}
+
+func TestDSCPClassForPacket(t *testing.T) {
+	cases := map[PacketType]DSCPClass{
+		PacketAVAudioFrame:   DSCPClassEF,
+		PacketAVVideoFrame:   DSCPClassEF,
+		PacketPingRequest:    DSCPClassCS6,
+		PacketGetNodes:       DSCPClassCS6,
+		PacketNoiseHandshake: DSCPClassCS6,
+		PacketFriendMessage:  DSCPClassCS0,
+	}
+	for pt, want := range cases {
+		if got := DSCPClassForPacket(pt); got != want {
+			t.Errorf("DSCPClassForPacket(%d) = %s, want %s", pt, got, want)
+		}
+	}
+}
+
+// readSocketTOS returns the IP_TOS option of a UDP transport's socket.
+func readSocketTOS(t *testing.T, udp *UDPTransport) int {
+	t.Helper()
+	raw, err := udp.conn.(syscall.Conn).SyscallConn()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tos int
+	var sockErr error
+	if err := raw.Control(func(fd uintptr) {
+		tos, sockErr = syscall.GetsockoptInt(int(fd), syscall.IPPROTO_IP, syscall.IP_TOS)
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if sockErr != nil {
+		t.Fatal(sockErr)
+	}
+	return tos
+}
+
+func TestUDPTransportDSCPMarking(t *testing.T) {
+	tr, err := NewUDPTransport("127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("NewUDPTransport failed: %v", err)
+	}
+	defer tr.Close()
+	udp := tr.(*UDPTransport)
+
+	if err := udp.SetDSCPClass(DSCPClassEF); err != nil {
+		t.Fatalf("SetDSCPClass failed: %v", err)
+	}
+	if got := readSocketTOS(t, udp); got != DSCPClassEF.TOS() {
+		t.Errorf("socket TOS = 0x%x, want 0x%x", got, DSCPClassEF.TOS())
+	}
+	if err := udp.SetDSCPClass(64); err == nil {
+		t.Error("expected error for out-of-range class")
+	}
+
+	if err := udp.EnableDSCPMarking(true); err != nil {
+		t.Fatal(err)
+	}
+	dest := udp.LocalAddr().(*net.UDPAddr)
+	if err := udp.Send(&Packet{PacketType: PacketPingRequest, Data: []byte{1}}, dest); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	if got := udp.DSCPClass(); got != DSCPClassCS6 {
+		t.Errorf("class after ping = %s, want CS6", got)
+	}
+	if got := readSocketTOS(t, udp); got != DSCPClassCS6.TOS() {
+		t.Errorf("socket TOS = 0x%x, want 0x%x", got, DSCPClassCS6.TOS())
+	}
+
+	if err := udp.EnableDSCPMarking(false); err != nil {
+		t.Fatal(err)
+	}
+	if got := readSocketTOS(t, udp); got != 0 {
+		t.Errorf("socket TOS after disabling = 0x%x, want 0", got)
+	}
+}
@@ -0,0 +1,38 @@
+//go:build linux || freebsd || darwin || netbsd || openbsd
+
+package transport
+
+import (
+	"net"
+	"syscall"
+)
+
+// setSocketDSCP sets the IP_TOS (or IPV6_TCLASS) socket option on conn.
+// Connections that don't expose a file descriptor are left unchanged.
+func setSocketDSCP(conn net.PacketConn, class DSCPClass) error {
+	sc, ok := conn.(syscall.Conn)
+	if !ok {
+		return nil
+	}
+	raw, err := sc.SyscallConn()
+	if err != nil {
+		return err
+	}
+
+	ipv6 := false
+	if udpAddr, ok := conn.LocalAddr().(*net.UDPAddr); ok && udpAddr.IP != nil && udpAddr.IP.To4() == nil {
+		ipv6 = true
+	}
+
+	var sockErr error
+	if err := raw.Control(func(fd uintptr) {
+		if ipv6 {
+			sockErr = syscall.SetsockoptInt(int(fd), syscall.IPPROTO_IPV6, syscall.IPV6_TCLASS, class.TOS())
+			return
+		}
+		sockErr = syscall.SetsockoptInt(int(fd), syscall.IPPROTO_IP, syscall.IP_TOS, class.TOS())
+	}); err != nil {
+		return err
+	}
+	return sockErr
+}
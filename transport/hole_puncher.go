@@ -11,8 +11,6 @@ import (
 	"net"
 	"sync"
 	"time"
-
-	"github.com/sirupsen/logrus"
 )
 
 // HolePuncher manages UDP hole punching operations
@@ -193,7 +191,7 @@ func (hp *HolePuncher) waitForResponse(ctx context.Context, expectedAddr net.Add
 	buffer := make([]byte, 1024)
 	if err := hp.setReadTimeout(); err != nil {
 		// Log error but continue with default timeout behavior
-		logrus.WithError(err).Warn("Failed to set read timeout for hole punching")
+		pkgLog.WithError(err).Warn("Failed to set read timeout for hole punching")
 	}
 
 	for {
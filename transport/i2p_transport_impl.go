@@ -72,7 +72,7 @@ func NewI2PTransportWithSAMAddr(samAddr string) *I2PTransport {
 		samAddr = onramp.SAM_ADDR
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "NewI2PTransportWithSAMAddr",
 		"sam_addr": samAddr,
 	}).Info("Creating I2P transport (onramp)")
@@ -90,7 +90,7 @@ func (t *I2PTransport) ensureGarlic() error {
 
 	garlic, err := onramp.NewGarlic("toxcore-i2p", t.samAddr, onramp.OPT_SMALL)
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "I2PTransport.ensureGarlic",
 			"sam_addr": t.samAddr,
 			"error":    err.Error(),
@@ -109,7 +109,7 @@ func (t *I2PTransport) Listen(address string) (net.Listener, error) {
 	t.mu.Lock()
 	defer t.mu.Unlock()
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "I2PTransport.Listen",
 		"address":  address,
 	}).Debug("I2P listen requested")
@@ -124,7 +124,7 @@ func (t *I2PTransport) Listen(address string) (net.Listener, error) {
 
 	listener, err := t.garlic.Listen()
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "I2PTransport.Listen",
 			"address":  address,
 			"error":    err.Error(),
@@ -132,7 +132,7 @@ func (t *I2PTransport) Listen(address string) (net.Listener, error) {
 		return nil, fmt.Errorf("I2P listener creation failed: %w", err)
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":   "I2PTransport.Listen",
 		"address":    address,
 		"local_addr": listener.Addr().String(),
@@ -147,7 +147,7 @@ func (t *I2PTransport) Dial(address string) (net.Conn, error) {
 	t.mu.Lock()
 	defer t.mu.Unlock()
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "I2PTransport.Dial",
 		"address":  address,
 		"sam_addr": t.samAddr,
@@ -163,7 +163,7 @@ func (t *I2PTransport) Dial(address string) (net.Conn, error) {
 
 	conn, err := t.garlic.Dial("tcp", address)
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "I2PTransport.Dial",
 			"address":  address,
 			"error":    err.Error(),
@@ -171,7 +171,7 @@ func (t *I2PTransport) Dial(address string) (net.Conn, error) {
 		return nil, fmt.Errorf("I2P dial failed: %w", err)
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":    "I2PTransport.Dial",
 		"address":     address,
 		"local_addr":  conn.LocalAddr().String(),
@@ -187,7 +187,7 @@ func (t *I2PTransport) DialPacket(address string) (net.PacketConn, error) {
 	t.mu.Lock()
 	defer t.mu.Unlock()
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "I2PTransport.DialPacket",
 		"address":  address,
 		"sam_addr": t.samAddr,
@@ -203,7 +203,7 @@ func (t *I2PTransport) DialPacket(address string) (net.PacketConn, error) {
 
 	conn, err := t.garlic.ListenPacket()
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "I2PTransport.DialPacket",
 			"address":  address,
 			"error":    err.Error(),
@@ -211,7 +211,7 @@ func (t *I2PTransport) DialPacket(address string) (net.PacketConn, error) {
 		return nil, fmt.Errorf("I2P datagram creation failed: %w", err)
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":   "I2PTransport.DialPacket",
 		"address":    address,
 		"local_addr": conn.LocalAddr().String(),
@@ -230,7 +230,7 @@ func (t *I2PTransport) Close() error {
 	t.mu.Lock()
 	defer t.mu.Unlock()
 
-	logrus.WithField("function", "I2PTransport.Close").Debug("Closing I2P transport")
+	pkgLog.WithField("function", "I2PTransport.Close").Debug("Closing I2P transport")
 
 	if t.garlic != nil {
 		err := t.garlic.Close()
@@ -16,14 +16,14 @@ type IPTransport struct {
 
 // NewIPTransport creates a new IP transport instance.
 func NewIPTransport() *IPTransport {
-	logrus.WithField("function", "NewIPTransport").Info("Creating IP transport")
+	pkgLog.WithField("function", "NewIPTransport").Info("Creating IP transport")
 	return &IPTransport{}
 }
 
 // Listen creates a listener on the given IP address.
 // The address should be in the format "host:port" or ":port".
 func (t *IPTransport) Listen(address string) (net.Listener, error) {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "IPTransport.Listen",
 		"address":  address,
 	}).Debug("Creating TCP listener")
@@ -31,7 +31,7 @@ func (t *IPTransport) Listen(address string) (net.Listener, error) {
 	// Default to TCP for stream-oriented listening
 	listener, err := net.Listen("tcp", address)
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "IPTransport.Listen",
 			"address":  address,
 			"error":    err.Error(),
@@ -39,7 +39,7 @@ func (t *IPTransport) Listen(address string) (net.Listener, error) {
 		return nil, fmt.Errorf("IP transport listen failed: %w", err)
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":   "IPTransport.Listen",
 		"address":    address,
 		"local_addr": listener.Addr().String(),
@@ -50,14 +50,14 @@ func (t *IPTransport) Listen(address string) (net.Listener, error) {
 
 // Dial establishes a TCP connection to the given IP address.
 func (t *IPTransport) Dial(address string) (net.Conn, error) {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "IPTransport.Dial",
 		"address":  address,
 	}).Debug("Dialing TCP connection")
 
 	conn, err := net.Dial("tcp", address)
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "IPTransport.Dial",
 			"address":  address,
 			"error":    err.Error(),
@@ -65,7 +65,7 @@ func (t *IPTransport) Dial(address string) (net.Conn, error) {
 		return nil, fmt.Errorf("IP transport dial failed: %w", err)
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":    "IPTransport.Dial",
 		"address":     address,
 		"local_addr":  conn.LocalAddr().String(),
@@ -77,14 +77,14 @@ func (t *IPTransport) Dial(address string) (net.Conn, error) {
 
 // DialPacket creates a UDP packet connection to the given IP address.
 func (t *IPTransport) DialPacket(address string) (net.PacketConn, error) {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "IPTransport.DialPacket",
 		"address":  address,
 	}).Debug("Creating UDP packet connection")
 
 	conn, err := net.ListenPacket("udp", address)
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "IPTransport.DialPacket",
 			"address":  address,
 			"error":    err.Error(),
@@ -92,7 +92,7 @@ func (t *IPTransport) DialPacket(address string) (net.PacketConn, error) {
 		return nil, fmt.Errorf("IP transport dial packet failed: %w", err)
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":   "IPTransport.DialPacket",
 		"address":    address,
 		"local_addr": conn.LocalAddr().String(),
@@ -108,6 +108,6 @@ func (t *IPTransport) SupportedNetworks() []string {
 
 // Close closes the IP transport. Currently a no-op as IP transport is stateless.
 func (t *IPTransport) Close() error {
-	logrus.WithField("function", "IPTransport.Close").Debug("Closing IP transport")
+	pkgLog.WithField("function", "IPTransport.Close").Debug("Closing IP transport")
 	return nil
 }
@@ -53,7 +53,7 @@ func NewLokinetTransport() *LokinetTransport {
 		proxyAddr = "127.0.0.1:9050" // Default Lokinet SOCKS5 port
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":   "NewLokinetTransport",
 		"proxy_addr": proxyAddr,
 	}).Info("Creating Lokinet transport")
@@ -61,7 +61,7 @@ func NewLokinetTransport() *LokinetTransport {
 	// Create SOCKS5 dialer for the Lokinet proxy
 	dialer, err := proxy.SOCKS5("tcp", proxyAddr, nil, proxy.Direct)
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":   "NewLokinetTransport",
 			"proxy_addr": proxyAddr,
 			"error":      err.Error(),
@@ -79,7 +79,7 @@ func NewLokinetTransport() *LokinetTransport {
 // supported via SOCKS5 alone. Applications should configure SNApps
 // via Lokinet configuration and use the regular IP transport to bind locally.
 func (t *LokinetTransport) Listen(address string) (net.Listener, error) {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "LokinetTransport.Listen",
 		"address":  address,
 	}).Debug("Lokinet listen requested")
@@ -99,7 +99,7 @@ func (t *LokinetTransport) Dial(address string) (net.Conn, error) {
 	proxyAddr := t.proxyAddr
 	t.mu.RUnlock()
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":   "LokinetTransport.Dial",
 		"address":    address,
 		"proxy_addr": proxyAddr,
@@ -110,7 +110,7 @@ func (t *LokinetTransport) Dial(address string) (net.Conn, error) {
 		var err error
 		dialer, err = proxy.SOCKS5("tcp", proxyAddr, nil, proxy.Direct)
 		if err != nil {
-			logrus.WithFields(logrus.Fields{
+			pkgLog.WithFields(logrus.Fields{
 				"function":   "LokinetTransport.Dial",
 				"proxy_addr": proxyAddr,
 				"error":      err.Error(),
@@ -126,7 +126,7 @@ func (t *LokinetTransport) Dial(address string) (net.Conn, error) {
 	// Dial through SOCKS5 proxy
 	conn, err := dialer.Dial("tcp", address)
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "LokinetTransport.Dial",
 			"address":  address,
 			"error":    err.Error(),
@@ -134,7 +134,7 @@ func (t *LokinetTransport) Dial(address string) (net.Conn, error) {
 		return nil, fmt.Errorf("Lokinet dial failed: %w", err)
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":    "LokinetTransport.Dial",
 		"address":     address,
 		"local_addr":  conn.LocalAddr().String(),
@@ -147,7 +147,7 @@ func (t *LokinetTransport) Dial(address string) (net.Conn, error) {
 // DialPacket creates a packet connection through Lokinet.
 // Currently returns an error as Lokinet transport primarily uses TCP.
 func (t *LokinetTransport) DialPacket(address string) (net.PacketConn, error) {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "LokinetTransport.DialPacket",
 		"address":  address,
 	}).Debug("Lokinet packet dial requested")
@@ -162,6 +162,6 @@ func (t *LokinetTransport) SupportedNetworks() []string {
 
 // Close closes the Lokinet transport.
 func (t *LokinetTransport) Close() error {
-	logrus.WithField("function", "LokinetTransport.Close").Debug("Closing Lokinet transport")
+	pkgLog.WithField("function", "LokinetTransport.Close").Debug("Closing Lokinet transport")
 	return nil
 }
@@ -0,0 +1,34 @@
+package transport
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-i2p/onramp"
+)
+
+// TestMain keeps the keys generated by the Tor and I2P transports out of the
+// source tree. onramp resolves its keystore directories against the working
+// directory at package init, so they are redirected to a temporary directory
+// and the empty directories created at init are removed.
+func TestMain(m *testing.M) {
+	os.Exit(runWithTempKeystores(m))
+}
+
+func runWithTempKeystores(m *testing.M) int {
+	dir, err := os.MkdirTemp("", "toxcore-transport-keys")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to create keystore directory: %v\n", err)
+		return 1
+	}
+	defer os.RemoveAll(dir)
+
+	for _, path := range []*string{&onramp.I2P_KEYSTORE_PATH, &onramp.ONION_KEYSTORE_PATH, &onramp.TLS_KEYSTORE_PATH} {
+		// Remove only if empty, so keys a developer placed there survive.
+		os.Remove(*path)
+		*path = filepath.Join(dir, filepath.Base(*path))
+	}
+	return m.Run()
+}
@@ -0,0 +1,557 @@
+// Package transport provides network transport implementations for Tox.
+//
+// This file implements connection migration for UDPTransport: when the host
+// changes networks (WiFi to LTE and back), the transport re-binds its socket,
+// refreshes the UPnP port mapping and tells recently active peers its new
+// address so they can update their routing tables without a re-bootstrap.
+package transport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// migrationPeerTTL is how recently a peer must have exchanged packets
+	// with the transport to be sent a migration notification.
+	migrationPeerTTL = 2 * time.Minute
+
+	// maxMigrationPeers bounds the number of peers tracked for notification.
+	maxMigrationPeers = 1024
+
+	// migrationSettleDelay coalesces the burst of events a single network
+	// change produces (link down, address removed, address added, ...).
+	migrationSettleDelay = 500 * time.Millisecond
+
+	// migrationPollInterval is how often interface addresses are compared on
+	// platforms without change notifications.
+	migrationPollInterval = 5 * time.Second
+
+	// migrationUPnPTimeout bounds gateway discovery and mapping on migration.
+	migrationUPnPTimeout = 10 * time.Second
+
+	// migrationNotificationSize is the vendor header plus the public key.
+	migrationNotificationSize = 2 + 32
+)
+
+var (
+	// ErrMigrationClosed is returned when migrating a closed transport.
+	ErrMigrationClosed = errors.New("cannot migrate closed transport")
+	// ErrInvalidMigrationNotification is returned for malformed
+	// PacketMigrationNotify payloads.
+	ErrInvalidMigrationNotification = errors.New("invalid migration notification")
+)
+
+// Hooks replaced in tests.
+var (
+	newNetworkMonitorFunc = newNetworkMonitor
+	interfaceAddrsFunc    = net.InterfaceAddrs
+)
+
+// networkMonitor delivers a signal whenever the OS reports a change to
+// network interfaces or addresses. Events may be spurious; the transport
+// compares interface addresses before migrating.
+type networkMonitor interface {
+	Events() <-chan struct{}
+	Close() error
+}
+
+// MigrationNotification is the payload of a PacketMigrationNotify packet.
+// The new address is the source address the packet arrives from.
+type MigrationNotification struct {
+	// PublicKey identifies the peer whose address changed.
+	PublicKey [32]byte
+}
+
+// Serialize encodes the notification in the extension payload format.
+func (n *MigrationNotification) Serialize() []byte {
+	data := make([]byte, migrationNotificationSize)
+	data[0] = ExtensionVendorMagic
+	data[1] = ExtensionProtocolVersion
+	copy(data[2:], n.PublicKey[:])
+	return data
+}
+
+// ParseMigrationNotification decodes a PacketMigrationNotify payload.
+func ParseMigrationNotification(data []byte) (*MigrationNotification, error) {
+	if len(data) < migrationNotificationSize {
+		return nil, fmt.Errorf("%w: %d bytes", ErrInvalidMigrationNotification, len(data))
+	}
+	if data[0] != ExtensionVendorMagic {
+		return nil, fmt.Errorf("%w: unknown vendor 0x%02x", ErrInvalidMigrationNotification, data[0])
+	}
+	n := &MigrationNotification{}
+	copy(n.PublicKey[:], data[2:migrationNotificationSize])
+	return n, nil
+}
+
+// migrationPeer is a remote address the transport recently talked to.
+type migrationPeer struct {
+	addr     net.Addr
+	lastSeen time.Time
+}
+
+// migrationState holds the migration configuration of a UDPTransport.
+type migrationState struct {
+	mu          sync.Mutex
+	enabled     bool
+	cancel      context.CancelFunc
+	wg          sync.WaitGroup
+	identity    [32]byte
+	hasIdentity bool
+	upnp        *UPnPClient
+	handler     func(localAddr net.Addr)
+	peers       map[string]migrationPeer
+	addrs       string // interface address fingerprint at the last check
+
+	// tracking gates peer tracking on the packet path
+	tracking atomic.Bool
+}
+
+// SetMigrationEnabled turns connection migration on or off. When enabled,
+// the transport listens for OS network-change notifications (netlink on
+// Linux, routing sockets on macOS and FreeBSD, the IP Helper API on
+// Windows, and address polling elsewhere). When the set of interface
+// addresses changes it:
+//
+//  1. re-binds its socket, keeping the same port when possible;
+//  2. refreshes the UPnP port mapping, if SetMigrationUPnP was called;
+//  3. sends a PacketMigrationNotify packet to every peer it exchanged
+//     packets with in the last two minutes, if SetMigrationIdentity was
+//     called, so they can update their routing table entry.
+//
+// Returns an error if the transport is closed.
+func (t *UDPTransport) SetMigrationEnabled(enabled bool) error {
+	m := &t.migration
+	m.mu.Lock()
+
+	if enabled == m.enabled {
+		m.mu.Unlock()
+		return nil
+	}
+
+	if !enabled {
+		m.mu.Unlock()
+		t.stopMigration()
+		return nil
+	}
+
+	if t.ctx.Err() != nil {
+		m.mu.Unlock()
+		return ErrMigrationClosed
+	}
+
+	monitor, err := newNetworkMonitorFunc()
+	if err != nil {
+		pkgLog.WithFields(logrus.Fields{
+			"function": "SetMigrationEnabled",
+			"error":    err.Error(),
+		}).Warn("Network change notifications unavailable, polling interface addresses")
+		monitor = newPollingMonitor(migrationPollInterval)
+	}
+
+	ctx, cancel := context.WithCancel(t.ctx)
+	m.enabled = true
+	m.cancel = cancel
+	m.addrs = interfaceFingerprint()
+	if m.peers == nil {
+		m.peers = make(map[string]migrationPeer)
+	}
+	m.tracking.Store(true)
+	m.wg.Add(1)
+	m.mu.Unlock()
+
+	go t.runMigrationMonitor(ctx, monitor)
+
+	pkgLog.WithFields(logrus.Fields{
+		"function":   "SetMigrationEnabled",
+		"local_addr": t.LocalAddr().String(),
+	}).Info("Connection migration enabled")
+
+	return nil
+}
+
+// MigrationEnabled reports whether connection migration is enabled.
+func (t *UDPTransport) MigrationEnabled() bool {
+	t.migration.mu.Lock()
+	defer t.migration.mu.Unlock()
+	return t.migration.enabled
+}
+
+// SetMigrationIdentity sets the public key sent in migration notifications.
+// Peers are only notified after an identity has been set.
+func (t *UDPTransport) SetMigrationIdentity(publicKey [32]byte) {
+	t.migration.mu.Lock()
+	defer t.migration.mu.Unlock()
+	t.migration.identity = publicKey
+	t.migration.hasIdentity = true
+}
+
+// SetMigrationUPnP sets the UPnP client used to re-create the port mapping
+// after a migration. Pass nil to skip UPnP.
+func (t *UDPTransport) SetMigrationUPnP(client *UPnPClient) {
+	t.migration.mu.Lock()
+	defer t.migration.mu.Unlock()
+	t.migration.upnp = client
+}
+
+// OnMigration sets a callback invoked with the new local address after each
+// migration. Pass nil to remove it.
+func (t *UDPTransport) OnMigration(handler func(localAddr net.Addr)) {
+	t.migration.mu.Lock()
+	defer t.migration.mu.Unlock()
+	t.migration.handler = handler
+}
+
+// Migrate performs a migration immediately, for applications that learn of
+// network changes from the platform themselves (e.g. mobile connectivity
+// callbacks). It blocks while the UPnP mapping is refreshed.
+func (t *UDPTransport) Migrate() error {
+	if err := t.rebind(); err != nil {
+		return err
+	}
+
+	localAddr := t.LocalAddr()
+	t.refreshUPnPMapping(localAddr)
+	notified := t.notifyMigrationPeers()
+
+	t.migration.mu.Lock()
+	handler := t.migration.handler
+	t.migration.mu.Unlock()
+	if handler != nil {
+		handler(localAddr)
+	}
+
+	pkgLog.WithFields(logrus.Fields{
+		"function":       "Migrate",
+		"local_addr":     localAddr.String(),
+		"peers_notified": notified,
+	}).Info("UDP transport migrated to new network")
+
+	return nil
+}
+
+// stopMigration stops the network monitor and waits for it to exit.
+func (t *UDPTransport) stopMigration() {
+	m := &t.migration
+	m.mu.Lock()
+	cancel := m.cancel
+	m.enabled = false
+	m.cancel = nil
+	m.tracking.Store(false)
+	m.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+	}
+	m.wg.Wait()
+}
+
+// runMigrationMonitor migrates the transport whenever monitor reports a
+// change that alters the interface addresses.
+func (t *UDPTransport) runMigrationMonitor(ctx context.Context, monitor networkMonitor) {
+	defer t.migration.wg.Done()
+	defer monitor.Close()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-monitor.Events():
+		}
+
+		if !waitForSettle(ctx, monitor.Events()) {
+			return
+		}
+		if !t.interfacesChanged() {
+			continue
+		}
+		if err := t.Migrate(); err != nil {
+			pkgLog.WithFields(logrus.Fields{
+				"function": "runMigrationMonitor",
+				"error":    err.Error(),
+			}).Warn("Connection migration failed")
+		}
+	}
+}
+
+// waitForSettle returns once no event has arrived for migrationSettleDelay,
+// or false if ctx is cancelled first.
+func waitForSettle(ctx context.Context, events <-chan struct{}) bool {
+	for {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-events:
+		case <-time.After(migrationSettleDelay):
+			return true
+		}
+	}
+}
+
+// interfacesChanged reports whether the interface addresses differ from the
+// last check, and records the current set.
+func (t *UDPTransport) interfacesChanged() bool {
+	current := interfaceFingerprint()
+	t.migration.mu.Lock()
+	defer t.migration.mu.Unlock()
+	if current == t.migration.addrs {
+		return false
+	}
+	t.migration.addrs = current
+	return true
+}
+
+// interfaceFingerprint returns the sorted non-loopback interface addresses.
+func interfaceFingerprint() string {
+	addrs, err := interfaceAddrsFunc()
+	if err != nil {
+		return ""
+	}
+	var list []string
+	for _, addr := range addrs {
+		if ipNet, ok := addr.(*net.IPNet); ok && ipNet.IP.IsLoopback() {
+			continue
+		}
+		list = append(list, addr.String())
+	}
+	sort.Strings(list)
+	return strings.Join(list, ",")
+}
+
+// rebind replaces the socket with a fresh one bound to the same address.
+// If that address is no longer available it falls back to the same port on
+// all interfaces, then to any port.
+func (t *UDPTransport) rebind() error {
+	t.dscpMu.Lock()
+	defer t.dscpMu.Unlock()
+	t.connMu.Lock()
+	defer t.connMu.Unlock()
+
+	if t.ctx.Err() != nil {
+		return ErrMigrationClosed
+	}
+
+	oldAddr := t.conn.LocalAddr().String()
+	host, port, err := net.SplitHostPort(oldAddr)
+	if err != nil {
+		return fmt.Errorf("failed to parse local address %s: %w", oldAddr, err)
+	}
+	candidates := []string{oldAddr, net.JoinHostPort("", port), net.JoinHostPort(host, "0"), ":0"}
+
+	// The old socket holds the port, so it must go first
+	if err := t.conn.Close(); err != nil && !errors.Is(err, net.ErrClosed) {
+		pkgLog.WithError(err).Debug("Error closing UDP socket for rebind")
+	}
+
+	var conn net.PacketConn
+	for _, candidate := range candidates {
+		conn, err = net.ListenPacket("udp", candidate)
+		if err == nil {
+			break
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to rebind UDP socket: %w", err)
+	}
+	t.conn = conn
+
+	if t.dscpClass != DSCPClassCS0 {
+		if err := setSocketDSCP(conn, t.dscpClass); err != nil {
+			pkgLog.WithError(err).Debug("Failed to restore DSCP class after rebind")
+		}
+	}
+
+	pkgLog.WithFields(logrus.Fields{
+		"function": "rebind",
+		"old_addr": oldAddr,
+		"new_addr": conn.LocalAddr().String(),
+	}).Debug("Rebound UDP socket")
+
+	return nil
+}
+
+// refreshUPnPMapping re-creates the UPnP port mapping for localAddr on the
+// gateway of the new network.
+func (t *UDPTransport) refreshUPnPMapping(localAddr net.Addr) {
+	t.migration.mu.Lock()
+	client := t.migration.upnp
+	t.migration.mu.Unlock()
+	if client == nil {
+		return
+	}
+
+	udpAddr, ok := localAddr.(*net.UDPAddr)
+	if !ok {
+		return
+	}
+	internalIP := primaryIPv4()
+	if internalIP == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(t.ctx, migrationUPnPTimeout)
+	defer cancel()
+
+	client.Reset()
+	err := client.DiscoverGateway(ctx)
+	if err == nil {
+		err = client.AddPortMapping(ctx, UPnPMapping{
+			ExternalPort: udpAddr.Port,
+			InternalPort: udpAddr.Port,
+			InternalIP:   internalIP.String(),
+			Protocol:     "UDP",
+			Description:  "Tox " + strconv.Itoa(udpAddr.Port),
+			Duration:     time.Hour,
+		})
+	}
+	if err != nil {
+		pkgLog.WithFields(logrus.Fields{
+			"function": "refreshUPnPMapping",
+			"port":     udpAddr.Port,
+			"error":    err.Error(),
+		}).Warn("Failed to refresh UPnP mapping after migration")
+	}
+}
+
+// primaryIPv4 returns the first non-loopback IPv4 interface address.
+func primaryIPv4() net.IP {
+	addrs, err := interfaceAddrsFunc()
+	if err != nil {
+		return nil
+	}
+	for _, addr := range addrs {
+		ipNet, ok := addr.(*net.IPNet)
+		if !ok || ipNet.IP.IsLoopback() {
+			continue
+		}
+		if ip4 := ipNet.IP.To4(); ip4 != nil {
+			return ip4
+		}
+	}
+	return nil
+}
+
+// trackMigrationPeer records addr as recently active while migration is
+// enabled.
+func (t *UDPTransport) trackMigrationPeer(addr net.Addr) {
+	m := &t.migration
+	if !m.tracking.Load() || addr == nil {
+		return
+	}
+
+	key := addr.String()
+	now := time.Now()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, known := m.peers[key]; !known && len(m.peers) >= maxMigrationPeers {
+		m.pruneMigrationPeersLocked(now)
+		if len(m.peers) >= maxMigrationPeers {
+			return
+		}
+	}
+	m.peers[key] = migrationPeer{addr: addr, lastSeen: now}
+}
+
+// pruneMigrationPeersLocked drops peers not seen within migrationPeerTTL.
+func (m *migrationState) pruneMigrationPeersLocked(now time.Time) {
+	for key, peer := range m.peers {
+		if now.Sub(peer.lastSeen) > migrationPeerTTL {
+			delete(m.peers, key)
+		}
+	}
+}
+
+// notifyMigrationPeers sends a PacketMigrationNotify packet to each recently
+// active peer and returns how many were sent.
+func (t *UDPTransport) notifyMigrationPeers() int {
+	m := &t.migration
+	m.mu.Lock()
+	if !m.hasIdentity {
+		m.mu.Unlock()
+		pkgLog.WithField("function", "notifyMigrationPeers").Debug("No migration identity set, not notifying peers")
+		return 0
+	}
+	notification := &MigrationNotification{PublicKey: m.identity}
+	m.pruneMigrationPeersLocked(time.Now())
+	peers := make([]net.Addr, 0, len(m.peers))
+	for _, peer := range m.peers {
+		peers = append(peers, peer.addr)
+	}
+	m.mu.Unlock()
+
+	packet := &Packet{
+		PacketType: PacketMigrationNotify,
+		Data:       notification.Serialize(),
+	}
+	sent := 0
+	for _, addr := range peers {
+		if err := t.Send(packet, addr); err != nil {
+			pkgLog.WithFields(logrus.Fields{
+				"function":  "notifyMigrationPeers",
+				"peer_addr": addr.String(),
+				"error":     err.Error(),
+			}).Debug("Failed to send migration notification")
+			continue
+		}
+		sent++
+	}
+	return sent
+}
+
+// pollingMonitor signals on a fixed interval; the transport then compares
+// interface addresses. It is used where no change notification API exists.
+type pollingMonitor struct {
+	events chan struct{}
+	stop   chan struct{}
+	once   sync.Once
+}
+
+// newPollingMonitor creates a monitor that signals every interval.
+func newPollingMonitor(interval time.Duration) *pollingMonitor {
+	pm := &pollingMonitor{
+		events: make(chan struct{}, 1),
+		stop:   make(chan struct{}),
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-pm.stop:
+				return
+			case <-ticker.C:
+				signalNetworkChange(pm.events)
+			}
+		}
+	}()
+	return pm
+}
+
+// Events implements networkMonitor.
+func (pm *pollingMonitor) Events() <-chan struct{} { return pm.events }
+
+// Close implements networkMonitor.
+func (pm *pollingMonitor) Close() error {
+	pm.once.Do(func() { close(pm.stop) })
+	return nil
+}
+
+// signalNetworkChange delivers an event without blocking; a pending event
+// already covers this one.
+func signalNetworkChange(events chan struct{}) {
+	select {
+	case events <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,234 @@
+package transport
+
+import (
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeNetworkMonitor lets tests trigger network change events.
+type fakeNetworkMonitor struct {
+	events chan struct{}
+	closed chan struct{}
+	once   sync.Once
+}
+
+func (f *fakeNetworkMonitor) Events() <-chan struct{} { return f.events }
+
+func (f *fakeNetworkMonitor) Close() error {
+	f.once.Do(func() { close(f.closed) })
+	return nil
+}
+
+// fakeInterfaces is a replaceable interface address list.
+type fakeInterfaces struct {
+	mu    sync.Mutex
+	addrs []net.Addr
+}
+
+func (f *fakeInterfaces) set(cidrs ...string) {
+	var addrs []net.Addr
+	for _, cidr := range cidrs {
+		ip, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		ipNet.IP = ip
+		addrs = append(addrs, ipNet)
+	}
+	f.mu.Lock()
+	f.addrs = addrs
+	f.mu.Unlock()
+}
+
+func (f *fakeInterfaces) get() ([]net.Addr, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.addrs, nil
+}
+
+// installMigrationFakes replaces the network monitor and interface hooks for
+// the duration of the test.
+func installMigrationFakes(t *testing.T) (*fakeNetworkMonitor, *fakeInterfaces) {
+	t.Helper()
+	monitor := &fakeNetworkMonitor{events: make(chan struct{}, 1), closed: make(chan struct{})}
+	ifaces := &fakeInterfaces{}
+	ifaces.set("192.168.1.10/24")
+
+	origMonitor, origAddrs := newNetworkMonitorFunc, interfaceAddrsFunc
+	newNetworkMonitorFunc = func() (networkMonitor, error) { return monitor, nil }
+	interfaceAddrsFunc = ifaces.get
+	t.Cleanup(func() {
+		newNetworkMonitorFunc, interfaceAddrsFunc = origMonitor, origAddrs
+	})
+	return monitor, ifaces
+}
+
+func newTestUDPTransport(t *testing.T) *UDPTransport {
+	t.Helper()
+	tr, err := NewUDPTransport("127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { tr.Close() })
+	return tr.(*UDPTransport)
+}
+
+func TestMigrationNotificationRoundTrip(t *testing.T) {
+	var pk [32]byte
+	for i := range pk {
+		pk[i] = byte(i)
+	}
+
+	data := (&MigrationNotification{PublicKey: pk}).Serialize()
+	assert.Len(t, data, migrationNotificationSize)
+
+	parsed, err := ParseMigrationNotification(data)
+	require.NoError(t, err)
+	assert.Equal(t, pk, parsed.PublicKey)
+
+	_, err = ParseMigrationNotification(data[:10])
+	assert.ErrorIs(t, err, ErrInvalidMigrationNotification)
+
+	data[0] = 0x00
+	_, err = ParseMigrationNotification(data)
+	assert.ErrorIs(t, err, ErrInvalidMigrationNotification)
+}
+
+func TestUDPTransportMigrationOnNetworkChange(t *testing.T) {
+	monitor, ifaces := installMigrationFakes(t)
+
+	local := newTestUDPTransport(t)
+	peer := newTestUDPTransport(t)
+
+	received := make(chan net.Addr, 1)
+	var receivedKey [32]byte
+	peer.RegisterHandler(PacketMigrationNotify, func(packet *Packet, addr net.Addr) error {
+		n, err := ParseMigrationNotification(packet.Data)
+		if err == nil {
+			receivedKey = n.PublicKey
+			received <- addr
+		}
+		return err
+	})
+
+	var identity [32]byte
+	identity[0] = 0x42
+	local.SetMigrationIdentity(identity)
+
+	migrated := make(chan net.Addr, 1)
+	local.OnMigration(func(addr net.Addr) { migrated <- addr })
+
+	require.NoError(t, local.SetMigrationEnabled(true))
+	assert.True(t, local.MigrationEnabled())
+
+	oldPort := local.LocalAddr().(*net.UDPAddr).Port
+
+	// Talking to the peer makes it a notification target
+	require.NoError(t, local.Send(&Packet{PacketType: PacketPingRequest, Data: []byte{1}}, peer.LocalAddr()))
+
+	// An event without an address change does not migrate
+	monitor.events <- struct{}{}
+	select {
+	case <-migrated:
+		t.Fatal("migrated without an interface change")
+	case <-time.After(2 * migrationSettleDelay):
+	}
+
+	ifaces.set("10.0.0.5/8")
+	monitor.events <- struct{}{}
+
+	select {
+	case addr := <-migrated:
+		assert.Equal(t, oldPort, addr.(*net.UDPAddr).Port, "port should be preserved")
+	case <-time.After(5 * time.Second):
+		t.Fatal("transport did not migrate")
+	}
+
+	select {
+	case from := <-received:
+		assert.Equal(t, identity, receivedKey)
+		assert.Equal(t, oldPort, from.(*net.UDPAddr).Port)
+	case <-time.After(5 * time.Second):
+		t.Fatal("peer did not receive migration notification")
+	}
+
+	// The new socket is used for both sending and receiving
+	echoed := make(chan struct{}, 1)
+	local.RegisterHandler(PacketPingResponse, func(*Packet, net.Addr) error {
+		echoed <- struct{}{}
+		return nil
+	})
+	require.NoError(t, peer.Send(&Packet{PacketType: PacketPingResponse, Data: []byte{1}}, local.LocalAddr()))
+	select {
+	case <-echoed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("migrated transport did not receive packet")
+	}
+}
+
+func TestUDPTransportMigrationWithoutIdentity(t *testing.T) {
+	installMigrationFakes(t)
+
+	local := newTestUDPTransport(t)
+	peer := newTestUDPTransport(t)
+
+	received := make(chan struct{}, 1)
+	peer.RegisterHandler(PacketMigrationNotify, func(*Packet, net.Addr) error {
+		received <- struct{}{}
+		return nil
+	})
+
+	require.NoError(t, local.SetMigrationEnabled(true))
+	require.NoError(t, local.Send(&Packet{PacketType: PacketPingRequest, Data: []byte{1}}, peer.LocalAddr()))
+	require.NoError(t, local.Migrate())
+
+	select {
+	case <-received:
+		t.Fatal("notification sent without an identity")
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+func TestUDPTransportMigrationDisable(t *testing.T) {
+	monitor, _ := installMigrationFakes(t)
+	local := newTestUDPTransport(t)
+
+	require.NoError(t, local.SetMigrationEnabled(true))
+	require.NoError(t, local.SetMigrationEnabled(false))
+	assert.False(t, local.MigrationEnabled())
+
+	select {
+	case <-monitor.closed:
+	case <-time.After(time.Second):
+		t.Fatal("monitor not closed when migration disabled")
+	}
+}
+
+func TestUDPTransportMigrationClosed(t *testing.T) {
+	monitor, _ := installMigrationFakes(t)
+	local := newTestUDPTransport(t)
+
+	require.NoError(t, local.SetMigrationEnabled(true))
+	require.NoError(t, local.Close())
+
+	select {
+	case <-monitor.closed:
+	case <-time.After(time.Second):
+		t.Fatal("monitor not closed with transport")
+	}
+
+	assert.ErrorIs(t, local.SetMigrationEnabled(true), ErrMigrationClosed)
+	assert.ErrorIs(t, local.Migrate(), ErrMigrationClosed)
+}
+
+func TestNewNetworkMonitor(t *testing.T) {
+	monitor, err := newNetworkMonitor()
+	if err != nil {
+		t.Skipf("network change notifications unavailable: %v", err)
+	}
+	assert.NotNil(t, monitor.Events())
+	assert.NoError(t, monitor.Close())
+}
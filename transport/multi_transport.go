@@ -22,7 +22,7 @@ type MultiTransport struct {
 // NewMultiTransport creates a new multi-transport instance with default transports.
 // It initializes support for IP networks and privacy networks (Tor, I2P, Nym, Lokinet).
 func NewMultiTransport() *MultiTransport {
-	logrus.WithField("function", "NewMultiTransport").Info("Creating multi-transport")
+	pkgLog.WithField("function", "NewMultiTransport").Info("Creating multi-transport")
 
 	mt := &MultiTransport{
 		transports: make(map[string]NetworkTransport),
@@ -35,7 +35,7 @@ func NewMultiTransport() *MultiTransport {
 	mt.RegisterTransport("nym", NewNymTransport())
 	mt.RegisterTransport("loki", NewLokinetTransport())
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":           "NewMultiTransport",
 		"registered_count":   len(mt.transports),
 		"supported_networks": mt.GetSupportedNetworks(),
@@ -50,7 +50,7 @@ func (mt *MultiTransport) RegisterTransport(networkType string, transport Networ
 	mt.mu.Lock()
 	defer mt.mu.Unlock()
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":     "MultiTransport.RegisterTransport",
 		"network_type": networkType,
 		"transport":    fmt.Sprintf("%T", transport),
@@ -66,7 +66,7 @@ func (mt *MultiTransport) selectTransport(address string) (NetworkTransport, err
 	mt.mu.RLock()
 	defer mt.mu.RUnlock()
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "MultiTransport.selectTransport",
 		"address":  address,
 	}).Debug("Selecting transport for address")
@@ -75,7 +75,7 @@ func (mt *MultiTransport) selectTransport(address string) (NetworkTransport, err
 
 	transport, exists := mt.transports[networkType]
 	if !exists {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":     "MultiTransport.selectTransport",
 			"address":      address,
 			"network_type": networkType,
@@ -83,7 +83,7 @@ func (mt *MultiTransport) selectTransport(address string) (NetworkTransport, err
 		return nil, fmt.Errorf("no transport registered for network type: %s", networkType)
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":     "MultiTransport.selectTransport",
 		"address":      address,
 		"network_type": networkType,
@@ -101,14 +101,14 @@ func (mt *MultiTransport) selectTransport(address string) (NetworkTransport, err
 // - .nym addresses use NymTransport
 // - .loki addresses use LokinetTransport
 func (mt *MultiTransport) Listen(address string) (net.Listener, error) {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "MultiTransport.Listen",
 		"address":  address,
 	}).Info("Creating listener via multi-transport")
 
 	transport, err := mt.selectTransport(address)
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "MultiTransport.Listen",
 			"address":  address,
 			"error":    err.Error(),
@@ -118,7 +118,7 @@ func (mt *MultiTransport) Listen(address string) (net.Listener, error) {
 
 	listener, err := transport.Listen(address)
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":  "MultiTransport.Listen",
 			"address":   address,
 			"transport": fmt.Sprintf("%T", transport),
@@ -127,7 +127,7 @@ func (mt *MultiTransport) Listen(address string) (net.Listener, error) {
 		return nil, fmt.Errorf("listen failed: %w", err)
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":   "MultiTransport.Listen",
 		"address":    address,
 		"transport":  fmt.Sprintf("%T", transport),
@@ -139,14 +139,14 @@ func (mt *MultiTransport) Listen(address string) (net.Listener, error) {
 
 // Dial establishes a connection to the given address using the appropriate transport.
 func (mt *MultiTransport) Dial(address string) (net.Conn, error) {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "MultiTransport.Dial",
 		"address":  address,
 	}).Info("Dialing connection via multi-transport")
 
 	transport, err := mt.selectTransport(address)
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "MultiTransport.Dial",
 			"address":  address,
 			"error":    err.Error(),
@@ -156,7 +156,7 @@ func (mt *MultiTransport) Dial(address string) (net.Conn, error) {
 
 	conn, err := transport.Dial(address)
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":  "MultiTransport.Dial",
 			"address":   address,
 			"transport": fmt.Sprintf("%T", transport),
@@ -165,7 +165,7 @@ func (mt *MultiTransport) Dial(address string) (net.Conn, error) {
 		return nil, fmt.Errorf("dial failed: %w", err)
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":    "MultiTransport.Dial",
 		"address":     address,
 		"transport":   fmt.Sprintf("%T", transport),
@@ -191,7 +191,7 @@ func (mt *MultiTransport) selectPacketTransport(address string) (NetworkTranspor
 	mt.mu.RLock()
 	defer mt.mu.RUnlock()
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "MultiTransport.selectPacketTransport",
 		"address":  address,
 	}).Debug("Selecting packet transport for address")
@@ -199,7 +199,7 @@ func (mt *MultiTransport) selectPacketTransport(address string) (NetworkTranspor
 	// .i2p addresses always use I2P native datagrams.
 	if strings.Contains(address, ".i2p") {
 		if t, ok := mt.transports["i2p"]; ok {
-			logrus.WithFields(logrus.Fields{
+			pkgLog.WithFields(logrus.Fields{
 				"function":  "MultiTransport.selectPacketTransport",
 				"address":   address,
 				"transport": "i2p",
@@ -222,7 +222,7 @@ func (mt *MultiTransport) selectI2PForTorFallback(address string) (NetworkTransp
 	i2pTransport, hasI2P := mt.transports["i2p"]
 	_, hasTor := mt.transports["tor"]
 	if hasTor && hasI2P {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":  "MultiTransport.selectPacketTransport",
 			"address":   address,
 			"transport": "i2p",
@@ -238,7 +238,7 @@ func (mt *MultiTransport) selectTransportByAddress(address string) (NetworkTrans
 
 	t, exists := mt.transports[networkType]
 	if !exists {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":     "MultiTransport.selectPacketTransport",
 			"address":      address,
 			"network_type": networkType,
@@ -246,7 +246,7 @@ func (mt *MultiTransport) selectTransportByAddress(address string) (NetworkTrans
 		return nil, fmt.Errorf("no transport registered for network type: %s", networkType)
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":     "MultiTransport.selectPacketTransport",
 		"address":      address,
 		"network_type": networkType,
@@ -259,14 +259,14 @@ func (mt *MultiTransport) selectTransportByAddress(address string) (NetworkTrans
 // DialPacket creates a packet connection to the given address using the appropriate transport.
 // When Tor and I2P are both registered, I2P is preferred since Tor is TCP-only.
 func (mt *MultiTransport) DialPacket(address string) (net.PacketConn, error) {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "MultiTransport.DialPacket",
 		"address":  address,
 	}).Info("Creating packet connection via multi-transport")
 
 	transport, err := mt.selectPacketTransport(address)
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "MultiTransport.DialPacket",
 			"address":  address,
 			"error":    err.Error(),
@@ -276,7 +276,7 @@ func (mt *MultiTransport) DialPacket(address string) (net.PacketConn, error) {
 
 	conn, err := transport.DialPacket(address)
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":  "MultiTransport.DialPacket",
 			"address":   address,
 			"transport": fmt.Sprintf("%T", transport),
@@ -285,7 +285,7 @@ func (mt *MultiTransport) DialPacket(address string) (net.PacketConn, error) {
 		return nil, fmt.Errorf("dial packet failed: %w", err)
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":   "MultiTransport.DialPacket",
 		"address":    address,
 		"transport":  fmt.Sprintf("%T", transport),
@@ -305,7 +305,7 @@ func (mt *MultiTransport) GetSupportedNetworks() []string {
 		networks = append(networks, transport.SupportedNetworks()...)
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "MultiTransport.GetSupportedNetworks",
 		"networks": networks,
 		"count":    len(networks),
@@ -322,7 +322,7 @@ func (mt *MultiTransport) GetTransport(networkType string) (NetworkTransport, bo
 
 	transport, exists := mt.transports[networkType]
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":     "MultiTransport.GetTransport",
 		"network_type": networkType,
 		"exists":       exists,
@@ -336,7 +336,7 @@ func (mt *MultiTransport) Close() error {
 	mt.mu.Lock()
 	defer mt.mu.Unlock()
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":        "MultiTransport.Close",
 		"transport_count": len(mt.transports),
 	}).Info("Closing multi-transport")
@@ -344,7 +344,7 @@ func (mt *MultiTransport) Close() error {
 	var errs []error
 	for networkType, transport := range mt.transports {
 		if err := transport.Close(); err != nil {
-			logrus.WithFields(logrus.Fields{
+			pkgLog.WithFields(logrus.Fields{
 				"function":     "MultiTransport.Close",
 				"network_type": networkType,
 				"error":        err.Error(),
@@ -361,6 +361,6 @@ func (mt *MultiTransport) Close() error {
 		return errs[0]
 	}
 
-	logrus.WithField("function", "MultiTransport.Close").Info("Multi-transport closed successfully")
+	pkgLog.WithField("function", "MultiTransport.Close").Info("Multi-transport closed successfully")
 	return nil
 }
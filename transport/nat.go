@@ -11,8 +11,6 @@ import (
 	"net"
 	"sync"
 	"time"
-
-	"github.com/sirupsen/logrus"
 )
 
 // natFallbackAddr is the RFC 5737 TEST-NET-3 address used as a fallback when public address
@@ -226,7 +224,7 @@ func (nt *NATTraversal) runPeriodicDetection(ctx context.Context) {
 // logPeriodicDetection runs one detection pass and logs refresh failures.
 func (nt *NATTraversal) logPeriodicDetection() {
 	if _, err := nt.DetectNATType(); err != nil {
-		logrus.WithError(err).Warn("Periodic NAT type detection failed")
+		pkgLog.WithError(err).Warn("Periodic NAT type detection failed")
 	}
 }
 
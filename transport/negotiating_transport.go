@@ -257,7 +257,7 @@ func (nt *NegotiatingTransport) Send(packet *Packet, addr net.Addr) error {
 		return nt.handleNegotiationFailure(packet, addr, err)
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"peer":               addr.String(),
 		"negotiated_version": negotiatedVersion.String(),
 		"security_level":     getSecurityLevel(negotiatedVersion),
@@ -292,7 +292,7 @@ func (nt *NegotiatingTransport) handleNegotiationFailure(packet *Packet, addr ne
 			"peer does not support Noise-IK and legacy fallback is disabled",
 			err,
 		)
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"peer":           addr.String(),
 			"reason":         "negotiation_failed",
 			"error":          err.Error(),
@@ -309,7 +309,7 @@ func (nt *NegotiatingTransport) handleNegotiationFailure(packet *Packet, addr ne
 		"peer does not support Noise-IK, falling back to legacy encryption",
 		err,
 	)
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"peer":           addr.String(),
 		"reason":         "negotiation_failed",
 		"error":          err.Error(),
@@ -395,7 +395,7 @@ func (nt *NegotiatingTransport) getPeerVersion(addr net.Addr) ProtocolVersion {
 		delete(nt.peerVersions, addr.String())
 		nt.versionsMu.Unlock()
 
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"peer":    addr.String(),
 			"version": entry.version.String(),
 		}).Debug("Peer version entry expired - will re-negotiate")
@@ -424,7 +424,7 @@ func (nt *NegotiatingTransport) setPeerVersion(addr net.Addr, version ProtocolVe
 	defer nt.versionsMu.Unlock()
 	nt.peerVersions[addr.String()] = entry
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"peer":    addr.String(),
 		"version": version.String(),
 		"ttl":     ttl,
@@ -480,7 +480,7 @@ func (nt *NegotiatingTransport) handleVersionNegotiation(packet *Packet, senderA
 	negotiatedCaps := NegotiateCapabilities(nt.capabilities.AdvertisedCapabilities, peerCaps)
 	nt.setPeerCapabilities(senderAddr, negotiatedCaps)
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"peer":            senderAddr.String(),
 		"peer_caps":       fmt.Sprintf("0x%02x", peerCaps),
 		"our_caps":        fmt.Sprintf("0x%02x", nt.capabilities.AdvertisedCapabilities),
@@ -497,7 +497,7 @@ func (nt *NegotiatingTransport) handleVersionNegotiation(packet *Packet, senderA
 			fmt.Sprintf("peer does not support required capabilities (missing 0x%02x); downgrade refused", missing),
 			fmt.Errorf("peer capabilities 0x%02x < required 0x%02x", peerCaps, nt.capabilities.AdvertisedCapabilities),
 		)
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"peer":         senderAddr.String(),
 			"peer_caps":    fmt.Sprintf("0x%02x", peerCaps),
 			"required":     fmt.Sprintf("0x%02x", nt.capabilities.AdvertisedCapabilities),
@@ -545,9 +545,9 @@ func (nt *NegotiatingTransport) parseAndValidatePacket(packet *Packet, senderAdd
 				if secErr.Err != nil {
 					fields["underlying_err"] = secErr.Err.Error()
 				}
-				logrus.WithFields(fields).Error("Rejected version negotiation packet - fatal security error")
+				pkgLog.WithFields(fields).Error("Rejected version negotiation packet - fatal security error")
 			} else {
-				logrus.WithFields(logrus.Fields{
+				pkgLog.WithFields(logrus.Fields{
 					"peer":           senderAddr.String(),
 					"security_event": secErr.Event,
 					"category":       secErr.Category.String(),
@@ -558,7 +558,7 @@ func (nt *NegotiatingTransport) parseAndValidatePacket(packet *Packet, senderAdd
 		}
 
 		// Regular parsing error
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"peer":   senderAddr.String(),
 			"reason": err.Error(),
 		}).Warn("Rejected version negotiation packet - parsing error")
@@ -570,7 +570,7 @@ func (nt *NegotiatingTransport) parseAndValidatePacket(packet *Packet, senderAdd
 // logSignedPacketInfo logs information about signed packets for security auditing.
 func logSignedPacketInfo(senderAddr net.Addr, senderPubKey *[32]byte, vnPacket *VersionNegotiationPacket) {
 	if senderPubKey != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"peer":         senderAddr.String(),
 			"signed":       true,
 			"sender_key":   fmt.Sprintf("%x", senderPubKey[:8]),
@@ -0,0 +1,38 @@
+//go:build darwin || freebsd
+// +build darwin freebsd
+
+package transport
+
+import (
+	"fmt"
+
+	"golang.org/x/sys/unix"
+)
+
+// newNetworkMonitor listens on a routing socket for interface and address
+// changes. This is the kernel interface SystemConfiguration itself builds on,
+// and needs no CGO.
+func newNetworkMonitor() (networkMonitor, error) {
+	fd, err := unix.Socket(unix.AF_ROUTE, unix.SOCK_RAW, unix.AF_UNSPEC)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open routing socket: %w", err)
+	}
+	if err := setMonitorReadTimeout(fd); err != nil {
+		unix.Close(fd)
+		return nil, fmt.Errorf("failed to set routing socket read timeout: %w", err)
+	}
+	return newSocketMonitor(fd, isRouteChange), nil
+}
+
+// isRouteChange reports whether msg is an interface or address change. The
+// message type is the fourth byte of every routing message header.
+func isRouteChange(msg []byte) bool {
+	if len(msg) < 4 {
+		return false
+	}
+	switch msg[3] {
+	case unix.RTM_NEWADDR, unix.RTM_DELADDR, unix.RTM_IFINFO:
+		return true
+	}
+	return false
+}
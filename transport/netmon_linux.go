@@ -0,0 +1,52 @@
+//go:build linux
+// +build linux
+
+package transport
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"golang.org/x/sys/unix"
+)
+
+// newNetworkMonitor subscribes to rtnetlink link and address notifications.
+func newNetworkMonitor() (networkMonitor, error) {
+	fd, err := unix.Socket(unix.AF_NETLINK, unix.SOCK_RAW|unix.SOCK_CLOEXEC, unix.NETLINK_ROUTE)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open netlink socket: %w", err)
+	}
+
+	addr := &unix.SockaddrNetlink{
+		Family: unix.AF_NETLINK,
+		Groups: unix.RTMGRP_LINK | unix.RTMGRP_IPV4_IFADDR | unix.RTMGRP_IPV6_IFADDR,
+	}
+	if err := unix.Bind(fd, addr); err != nil {
+		unix.Close(fd)
+		return nil, fmt.Errorf("failed to bind netlink socket: %w", err)
+	}
+	if err := setMonitorReadTimeout(fd); err != nil {
+		unix.Close(fd)
+		return nil, fmt.Errorf("failed to set netlink read timeout: %w", err)
+	}
+
+	return newSocketMonitor(fd, isNetlinkChange), nil
+}
+
+// isNetlinkChange reports whether msg holds a link or address change.
+func isNetlinkChange(msg []byte) bool {
+	for len(msg) >= unix.NLMSG_HDRLEN {
+		length := int(binary.NativeEndian.Uint32(msg[0:4]))
+		msgType := binary.NativeEndian.Uint16(msg[4:6])
+		switch msgType {
+		case unix.RTM_NEWADDR, unix.RTM_DELADDR, unix.RTM_NEWLINK, unix.RTM_DELLINK:
+			return true
+		}
+		aligned := (length + unix.NLMSG_ALIGNTO - 1) &^ (unix.NLMSG_ALIGNTO - 1)
+		if length < unix.NLMSG_HDRLEN || aligned > len(msg) {
+			return false
+		}
+		msg = msg[aligned:]
+	}
+	return false
+}
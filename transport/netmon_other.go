@@ -0,0 +1,10 @@
+//go:build !linux && !darwin && !freebsd && !windows
+// +build !linux,!darwin,!freebsd,!windows
+
+package transport
+
+// newNetworkMonitor falls back to polling interface addresses on platforms
+// without a supported change notification API.
+func newNetworkMonitor() (networkMonitor, error) {
+	return newPollingMonitor(migrationPollInterval), nil
+}
@@ -0,0 +1,78 @@
+//go:build linux || darwin || freebsd
+// +build linux darwin freebsd
+
+package transport
+
+import (
+	"errors"
+	"sync"
+
+	"golang.org/x/sys/unix"
+)
+
+// socketMonitor reads change messages from a kernel notification socket
+// (netlink or a routing socket) and signals those isChange accepts.
+type socketMonitor struct {
+	fd       int
+	isChange func(msg []byte) bool
+	events   chan struct{}
+	stop     chan struct{}
+	once     sync.Once
+}
+
+// newSocketMonitor starts reading fd. The socket must have a receive
+// timeout so the reader notices Close.
+func newSocketMonitor(fd int, isChange func(msg []byte) bool) *socketMonitor {
+	sm := &socketMonitor{
+		fd:       fd,
+		isChange: isChange,
+		events:   make(chan struct{}, 1),
+		stop:     make(chan struct{}),
+	}
+	go sm.run()
+	return sm
+}
+
+// run reads messages until Close, then closes the socket.
+func (sm *socketMonitor) run() {
+	defer unix.Close(sm.fd)
+
+	buf := make([]byte, 8192)
+	for {
+		select {
+		case <-sm.stop:
+			return
+		default:
+		}
+
+		n, err := unix.Read(sm.fd, buf)
+		if err != nil {
+			if errors.Is(err, unix.EAGAIN) || errors.Is(err, unix.EINTR) {
+				continue
+			}
+			pkgLog.WithError(err).Debug("Network change socket read failed")
+			// Report a change so the transport re-checks its addresses
+			signalNetworkChange(sm.events)
+			return
+		}
+		if n > 0 && sm.isChange(buf[:n]) {
+			signalNetworkChange(sm.events)
+		}
+	}
+}
+
+// Events implements networkMonitor.
+func (sm *socketMonitor) Events() <-chan struct{} { return sm.events }
+
+// Close implements networkMonitor.
+func (sm *socketMonitor) Close() error {
+	sm.once.Do(func() { close(sm.stop) })
+	return nil
+}
+
+// setMonitorReadTimeout makes reads on fd return periodically so the monitor
+// can be stopped.
+func setMonitorReadTimeout(fd int) error {
+	tv := unix.Timeval{Sec: 1}
+	return unix.SetsockoptTimeval(fd, unix.SOL_SOCKET, unix.SO_RCVTIMEO, &tv)
+}
@@ -0,0 +1,73 @@
+//go:build windows
+// +build windows
+
+package transport
+
+import (
+	"fmt"
+	"sync"
+
+	"golang.org/x/sys/windows"
+)
+
+// Windows limits the number of callbacks a process can create, so a single
+// callback fans out to every active monitor.
+var (
+	addrChangeOnce     sync.Once
+	addrChangeCallback uintptr
+	windowsMonitorsMu  sync.Mutex
+	windowsMonitors    = make(map[*windowsMonitor]struct{})
+)
+
+// windowsMonitor receives IP Helper unicast address change notifications.
+type windowsMonitor struct {
+	handle windows.Handle
+	events chan struct{}
+	once   sync.Once
+}
+
+// newNetworkMonitor registers for unicast address changes with the IP
+// Helper API.
+func newNetworkMonitor() (networkMonitor, error) {
+	addrChangeOnce.Do(func() {
+		addrChangeCallback = windows.NewCallback(onAddrChange)
+	})
+
+	wm := &windowsMonitor{events: make(chan struct{}, 1)}
+	windowsMonitorsMu.Lock()
+	windowsMonitors[wm] = struct{}{}
+	windowsMonitorsMu.Unlock()
+
+	if err := windows.NotifyUnicastIpAddressChange(windows.AF_UNSPEC, addrChangeCallback, nil, false, &wm.handle); err != nil {
+		windowsMonitorsMu.Lock()
+		delete(windowsMonitors, wm)
+		windowsMonitorsMu.Unlock()
+		return nil, fmt.Errorf("failed to register for address changes: %w", err)
+	}
+	return wm, nil
+}
+
+// onAddrChange is the IP Helper notification callback.
+func onAddrChange(callerContext, row, notificationType uintptr) uintptr {
+	windowsMonitorsMu.Lock()
+	defer windowsMonitorsMu.Unlock()
+	for wm := range windowsMonitors {
+		signalNetworkChange(wm.events)
+	}
+	return 0
+}
+
+// Events implements networkMonitor.
+func (wm *windowsMonitor) Events() <-chan struct{} { return wm.events }
+
+// Close implements networkMonitor.
+func (wm *windowsMonitor) Close() error {
+	var err error
+	wm.once.Do(func() {
+		err = windows.CancelMibChangeNotify2(wm.handle)
+		windowsMonitorsMu.Lock()
+		delete(windowsMonitors, wm)
+		windowsMonitorsMu.Unlock()
+	})
+	return err
+}
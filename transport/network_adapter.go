@@ -17,7 +17,7 @@ type NetworkTransportAdapter struct {
 
 // NewNetworkTransportAdapter creates a new adapter for UDPTransport
 func NewNetworkTransportAdapter(udpTransport *UDPTransport) *NetworkTransportAdapter {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":   "NewNetworkTransportAdapter",
 		"local_addr": udpTransport.LocalAddr().String(),
 	}).Info("Creating network transport adapter")
@@ -30,7 +30,7 @@ func NewNetworkTransportAdapter(udpTransport *UDPTransport) *NetworkTransportAda
 
 // Send implements INetworkTransport.Send
 func (n *NetworkTransportAdapter) Send(packet []byte, addr net.Addr) error {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":    "NetworkTransportAdapter.Send",
 		"destination": addr.String(),
 		"packet_size": len(packet),
@@ -47,7 +47,7 @@ func (n *NetworkTransportAdapter) Send(packet []byte, addr net.Addr) error {
 
 // SendToFriend implements INetworkTransport.SendToFriend
 func (n *NetworkTransportAdapter) SendToFriend(friendID uint32, packet []byte) error {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":    "NetworkTransportAdapter.SendToFriend",
 		"friend_id":   friendID,
 		"packet_size": len(packet),
@@ -59,7 +59,7 @@ func (n *NetworkTransportAdapter) SendToFriend(friendID uint32, packet []byte) e
 
 	if !exists {
 		err := fmt.Errorf("friend %d address not found", friendID)
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":  "NetworkTransportAdapter.SendToFriend",
 			"friend_id": friendID,
 			"error":     err.Error(),
@@ -72,7 +72,7 @@ func (n *NetworkTransportAdapter) SendToFriend(friendID uint32, packet []byte) e
 
 // GetFriendAddress implements INetworkTransport.GetFriendAddress
 func (n *NetworkTransportAdapter) GetFriendAddress(friendID uint32) (net.Addr, error) {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":  "NetworkTransportAdapter.GetFriendAddress",
 		"friend_id": friendID,
 	}).Debug("Getting friend address from network transport adapter")
@@ -83,7 +83,7 @@ func (n *NetworkTransportAdapter) GetFriendAddress(friendID uint32) (net.Addr, e
 	addr, exists := n.friendAddrs[friendID]
 	if !exists {
 		err := fmt.Errorf("friend %d address not found", friendID)
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":  "NetworkTransportAdapter.GetFriendAddress",
 			"friend_id": friendID,
 			"error":     err.Error(),
@@ -91,7 +91,7 @@ func (n *NetworkTransportAdapter) GetFriendAddress(friendID uint32) (net.Addr, e
 		return nil, err
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":  "NetworkTransportAdapter.GetFriendAddress",
 		"friend_id": friendID,
 		"address":   addr.String(),
@@ -102,7 +102,7 @@ func (n *NetworkTransportAdapter) GetFriendAddress(friendID uint32) (net.Addr, e
 
 // RegisterFriend implements INetworkTransport.RegisterFriend
 func (n *NetworkTransportAdapter) RegisterFriend(friendID uint32, addr net.Addr) error {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":  "NetworkTransportAdapter.RegisterFriend",
 		"friend_id": friendID,
 		"address":   addr.String(),
@@ -113,7 +113,7 @@ func (n *NetworkTransportAdapter) RegisterFriend(friendID uint32, addr net.Addr)
 
 	n.friendAddrs[friendID] = addr
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":      "NetworkTransportAdapter.RegisterFriend",
 		"friend_id":     friendID,
 		"total_friends": len(n.friendAddrs),
@@ -124,7 +124,7 @@ func (n *NetworkTransportAdapter) RegisterFriend(friendID uint32, addr net.Addr)
 
 // Close implements INetworkTransport.Close
 func (n *NetworkTransportAdapter) Close() error {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "NetworkTransportAdapter.Close",
 	}).Info("Closing network transport adapter")
 
@@ -132,7 +132,7 @@ func (n *NetworkTransportAdapter) Close() error {
 		return n.udpTransport.Close()
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "NetworkTransportAdapter.Close",
 	}).Info("Network transport adapter closed successfully")
 
@@ -149,7 +149,7 @@ func (n *NetworkTransportAdapter) IsConnected() bool {
 	localAddr := n.udpTransport.LocalAddr()
 	connected := localAddr != nil
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":  "NetworkTransportAdapter.IsConnected",
 		"connected": connected,
 		"local_addr": func() string {
@@ -180,7 +180,7 @@ func (n *NetworkTransportAdapter) GetFriendCount() int {
 
 // RemoveFriend removes a friend's address registration
 func (n *NetworkTransportAdapter) RemoveFriend(friendID uint32) error {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":  "NetworkTransportAdapter.RemoveFriend",
 		"friend_id": friendID,
 	}).Info("Removing friend from network transport adapter")
@@ -190,7 +190,7 @@ func (n *NetworkTransportAdapter) RemoveFriend(friendID uint32) error {
 
 	delete(n.friendAddrs, friendID)
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":          "NetworkTransportAdapter.RemoveFriend",
 		"friend_id":         friendID,
 		"remaining_friends": len(n.friendAddrs),
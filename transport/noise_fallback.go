@@ -0,0 +1,285 @@
+package transport
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/flynn/noise"
+	toxnoise "github.com/opd-ai/toxcore/noise"
+	"github.com/sirupsen/logrus"
+)
+
+// PacketNoiseFallback payload kinds, carried in the first byte.
+const (
+	// noiseFallbackReject tells the initiator that its IK message could not
+	// be authenticated. It echoes the ephemeral key of the rejected message.
+	noiseFallbackReject byte = 0x01
+	// noiseFallbackMessage carries one XX handshake message.
+	noiseFallbackMessage byte = 0x02
+)
+
+// noiseHandshake is the handshake state held by a NoiseSession. Sessions
+// start with an IK handshake and switch to an XX handshake on fallback;
+// both produce cipher states the same way.
+type noiseHandshake interface {
+	WriteMessage(payload, receivedMessage []byte) ([]byte, bool, error)
+	ReadMessage(message []byte) ([]byte, bool, error)
+	GetCipherStates() (*noise.CipherState, *noise.CipherState, error)
+	GetRemoteStaticKey() ([]byte, error)
+	GetChannelBinding() []byte
+}
+
+var (
+	_ noiseHandshake = (*toxnoise.IKHandshake)(nil)
+	_ noiseHandshake = (*toxnoise.XXHandshake)(nil)
+)
+
+// sendFallbackPacket sends a PacketNoiseFallback of the given kind.
+func (nt *NoiseTransport) sendFallbackPacket(kind byte, message []byte, addr net.Addr) error {
+	data := make([]byte, 1+len(message))
+	data[0] = kind
+	copy(data[1:], message)
+	return nt.underlying.Send(&Packet{PacketType: PacketNoiseFallback, Data: data}, addr)
+}
+
+// sendHandshakeRejection tells addr that its IK initiation failed, so it
+// can retry with XX. The initiation's ephemeral key is echoed so the
+// initiator can tie the rejection to its attempt. The responder keeps no
+// state for the rejected attempt.
+func (nt *NoiseTransport) sendHandshakeRejection(peerEphemeral []byte, addr net.Addr) {
+	if err := nt.sendFallbackPacket(noiseFallbackReject, peerEphemeral, addr); err != nil {
+		pkgLog.WithFields(logrus.Fields{
+			"function": "sendHandshakeRejection",
+			"peer":     addr.String(),
+			"error":    err.Error(),
+		}).Warn("Failed to send Noise handshake rejection")
+	}
+}
+
+// handleFallbackPacket processes incoming PacketNoiseFallback packets.
+func (nt *NoiseTransport) handleFallbackPacket(packet *Packet, addr net.Addr) error {
+	if len(packet.Data) < 1 {
+		return errors.New("fallback packet too short")
+	}
+	switch packet.Data[0] {
+	case noiseFallbackReject:
+		return nt.handleHandshakeRejection(packet.Data[1:], addr)
+	case noiseFallbackMessage:
+		return nt.handleFallbackMessage(packet.Data[1:], addr)
+	default:
+		return fmt.Errorf("unknown fallback packet kind %d", packet.Data[0])
+	}
+}
+
+// handleHandshakeRejection replaces our pending IK initiation with addr by
+// an XX handshake and sends its first message. The rejection must echo the
+// ephemeral key of that initiation and arrive within the handshake timeout;
+// anything else is ignored without touching the session. Only one fallback
+// is attempted per session; it must finish within the original handshake
+// timeout.
+func (nt *NoiseTransport) handleHandshakeRejection(echo []byte, addr net.Addr) error {
+	nt.sessionsMu.RLock()
+	session, exists := nt.sessions[addr.String()]
+	nt.sessionsMu.RUnlock()
+	if !exists {
+		return ErrNoiseSessionNotFound
+	}
+
+	session.mu.Lock()
+	if session.role != toxnoise.Initiator || session.complete || session.fallback ||
+		time.Since(session.createdAt) > nt.getHandshakeTimeout() {
+		session.mu.Unlock()
+		return fmt.Errorf("unexpected handshake rejection from %s", addr)
+	}
+	if len(session.ikEphemeral) == 0 || subtle.ConstantTimeCompare(echo, session.ikEphemeral) != 1 {
+		session.mu.Unlock()
+		return fmt.Errorf("handshake rejection from %s does not match our initiation", addr)
+	}
+	session.fallback = true
+	createdAt := session.createdAt
+	session.mu.Unlock()
+
+	handshake, err := toxnoise.NewXXHandshake(nt.staticPriv, toxnoise.Initiator)
+	if err != nil {
+		nt.deleteSession(addr)
+		return fmt.Errorf("failed to create fallback handshake: %w", err)
+	}
+
+	var message []byte
+	err = nt.runHandshakeStep(createdAt, func() error {
+		var stepErr error
+		message, _, stepErr = handshake.WriteMessage(nil, nil)
+		return stepErr
+	})
+	if err != nil {
+		nt.deleteSession(addr)
+		if errors.Is(err, ErrHandshakeTimeout) {
+			return err
+		}
+		return fmt.Errorf("failed to generate fallback handshake message: %w", err)
+	}
+
+	session.mu.Lock()
+	session.handshake = handshake
+	session.mu.Unlock()
+
+	pkgLog.WithFields(logrus.Fields{
+		"function": "handleHandshakeRejection",
+		"peer":     addr.String(),
+	}).Info("Noise-IK handshake rejected by peer, retrying with XX")
+
+	return nt.sendFallbackPacket(noiseFallbackMessage, message, addr)
+}
+
+// handleFallbackMessage advances the XX fallback handshake with addr. The
+// first message from an unknown address starts a responder session. Each
+// step reads the peer's message and, unless that completed the handshake,
+// answers with the next one.
+func (nt *NoiseTransport) handleFallbackMessage(message []byte, addr net.Addr) error {
+	nt.sessionsMu.RLock()
+	session, exists := nt.sessions[addr.String()]
+	nt.sessionsMu.RUnlock()
+
+	if !exists {
+		var err error
+		if session, err = nt.createFallbackResponderSession(message, addr); err != nil {
+			return err
+		}
+	}
+
+	session.mu.RLock()
+	fallback, complete := session.fallback, session.complete
+	handshake, createdAt := session.handshake, session.createdAt
+	session.mu.RUnlock()
+	if !fallback || complete {
+		return fmt.Errorf("unexpected fallback handshake message from %s", addr)
+	}
+
+	var (
+		response []byte
+		done     bool
+	)
+	err := nt.runHandshakeStep(createdAt, func() error {
+		var stepErr error
+		if _, done, stepErr = handshake.ReadMessage(message); stepErr != nil || done {
+			return stepErr
+		}
+		response, done, stepErr = handshake.WriteMessage(nil, nil)
+		return stepErr
+	})
+	if err != nil {
+		nt.deleteSession(addr)
+		if errors.Is(err, ErrHandshakeTimeout) {
+			return err
+		}
+		return fmt.Errorf("fallback handshake failed: %w", err)
+	}
+
+	if done {
+		if err := nt.checkKeyPin(session, addr); err != nil {
+			nt.deleteSession(addr)
+			return err
+		}
+		// Check before our final message goes out, so the responder
+		// never completes a session we are going to drop.
+		if err := nt.checkFallbackKey(session, addr); err != nil {
+			nt.deleteSession(addr)
+			return err
+		}
+	}
+
+	// As with IK, send our handshake message before any encrypted packet so
+	// the peer can complete its session first.
+	if response != nil {
+		if err := nt.sendFallbackPacket(noiseFallbackMessage, response, addr); err != nil {
+			nt.deleteSession(addr)
+			return err
+		}
+	}
+
+	if done {
+		return nt.completeFallback(session, addr)
+	}
+	return nil
+}
+
+// createFallbackResponderSession starts an XX responder session for an
+// initiator whose IK attempt we rejected. The first XX message is the
+// initiator's ephemeral key, which serves as the replay token.
+func (nt *NoiseTransport) createFallbackResponderSession(message []byte, addr net.Addr) (*NoiseSession, error) {
+	if len(message) < noiseEphemeralKeySize {
+		return nil, fmt.Errorf("fallback handshake message too short: %d bytes", len(message))
+	}
+	var peerEphemeral [noiseEphemeralKeySize]byte
+	copy(peerEphemeral[:], message[:noiseEphemeralKeySize])
+	now := time.Now()
+	if err := nt.validateHandshakeNonce(peerEphemeral, now.Unix()); err != nil {
+		return nil, fmt.Errorf("handshake validation failed: %w", err)
+	}
+
+	handshake, err := toxnoise.NewXXHandshake(nt.staticPriv, toxnoise.Responder)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create fallback responder handshake: %w", err)
+	}
+
+	nt.sessionsMu.Lock()
+	defer nt.sessionsMu.Unlock()
+	if session, exists := nt.sessions[addr.String()]; exists {
+		return session, nil
+	}
+	if len(nt.sessions) >= MaxNoiseSessions {
+		return nil, fmt.Errorf("noise session limit reached (%d): rejecting fallback handshake from %s", MaxNoiseSessions, addr)
+	}
+	session := &NoiseSession{
+		handshake:  handshake,
+		peerAddr:   addr,
+		role:       toxnoise.Responder,
+		fallback:   true,
+		createdAt:  now,
+		lastActive: now,
+	}
+	nt.sessions[addr.String()] = session
+	return session, nil
+}
+
+// checkFallbackKey rejects an XX fallback in which the peer proved a
+// different static key than the one our IK initiation was made with. A
+// rejection can be forged by anyone who sees the initiation, so accepting a
+// new key here would let them downgrade the handshake and impersonate the
+// peer. Responder sessions have no expected key and always pass.
+func (nt *NoiseTransport) checkFallbackKey(session *NoiseSession, addr net.Addr) error {
+	session.mu.RLock()
+	role, expected := session.role, session.expectedPeerKey
+	session.mu.RUnlock()
+	if role != toxnoise.Initiator {
+		return nil
+	}
+
+	pk, err := remoteStaticKey(session)
+	if err != nil {
+		return fmt.Errorf("failed to get remote static key: %w", err)
+	}
+	if subtle.ConstantTimeCompare(pk[:], expected) != 1 {
+		pkgLog.WithFields(logrus.Fields{
+			"function":   "checkFallbackKey",
+			"peer":       addr.String(),
+			"public_key": pk[:8],
+		}).Warn("Peer proved a different static key in XX fallback, dropping handshake")
+		return fmt.Errorf("%w: peer %s", ErrNoiseFallbackKeyMismatch, addr)
+	}
+	return nil
+}
+
+// completeFallback wires the XX cipher states into the session exactly as
+// for IK.
+func (nt *NoiseTransport) completeFallback(session *NoiseSession, addr net.Addr) error {
+	if err := nt.completeCipherSetup(session, addr); err != nil {
+		nt.deleteSession(addr)
+		return err
+	}
+	nt.pinPeerKey(session, addr)
+	return nil
+}
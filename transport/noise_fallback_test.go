@@ -0,0 +1,286 @@
+package transport
+
+import (
+	"bytes"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/opd-ai/toxcore/crypto"
+	toxnoise "github.com/opd-ai/toxcore/noise"
+)
+
+// noisePair is two NoiseTransports over mock transports whose sent packets
+// are delivered to each other by relay.
+type noisePair struct {
+	a, b           *NoiseTransport
+	mockA, mockB   *MockTransport
+	addrA, addrB   net.Addr
+	keysA, keysB   *crypto.KeyPair
+	deliveredFromA int
+	deliveredFromB int
+}
+
+func newNoisePair(t *testing.T) *noisePair {
+	t.Helper()
+	p := &noisePair{
+		mockA: NewMockTransport("127.0.0.1:7001"),
+		mockB: NewMockTransport("127.0.0.1:7002"),
+	}
+	p.addrA, p.addrB = p.mockA.LocalAddr(), p.mockB.LocalAddr()
+
+	var err error
+	if p.keysA, err = crypto.GenerateKeyPair(); err != nil {
+		t.Fatal(err)
+	}
+	if p.keysB, err = crypto.GenerateKeyPair(); err != nil {
+		t.Fatal(err)
+	}
+	if p.a, err = NewNoiseTransport(p.mockA, p.keysA.Private[:]); err != nil {
+		t.Fatal(err)
+	}
+	if p.b, err = NewNoiseTransport(p.mockB, p.keysB.Private[:]); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+// relay delivers pending packets in both directions until neither side
+// sends anything new, returning the errors reported by the handlers.
+func (p *noisePair) relay() []error {
+	var errs []error
+	for {
+		progressed := false
+		packetsA := p.mockA.GetPackets()
+		for ; p.deliveredFromA < len(packetsA); p.deliveredFromA++ {
+			if err := p.mockB.SimulateReceive(packetsA[p.deliveredFromA].packet, p.addrA); err != nil {
+				errs = append(errs, err)
+			}
+			progressed = true
+		}
+		packetsB := p.mockB.GetPackets()
+		for ; p.deliveredFromB < len(packetsB); p.deliveredFromB++ {
+			if err := p.mockA.SimulateReceive(packetsB[p.deliveredFromB].packet, p.addrB); err != nil {
+				errs = append(errs, err)
+			}
+			progressed = true
+		}
+		if !progressed {
+			return errs
+		}
+	}
+}
+
+func (p *noisePair) session(nt *NoiseTransport, addr net.Addr) *NoiseSession {
+	nt.sessionsMu.RLock()
+	defer nt.sessionsMu.RUnlock()
+	return nt.sessions[addr.String()]
+}
+
+// staleKey returns a valid public key that is not b's.
+func staleKey(t *testing.T) []byte {
+	t.Helper()
+	old, err := crypto.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return old.Public[:]
+}
+
+// ikEphemeral returns the ephemeral key of the IK initiation a sent to b.
+func (p *noisePair) ikEphemeral(t *testing.T) []byte {
+	t.Helper()
+	for _, sent := range p.mockA.GetPackets() {
+		if sent.packet.PacketType == PacketNoiseHandshake {
+			return sent.packet.Data[:noiseEphemeralKeySize]
+		}
+	}
+	t.Fatal("No IK initiation sent")
+	return nil
+}
+
+func TestNoiseXXFallbackRejectsRotatedKey(t *testing.T) {
+	p := newNoisePair(t)
+	stale := staleKey(t)
+	if err := p.a.AddPeer(p.addrB, stale); err != nil {
+		t.Fatal(err)
+	}
+
+	packet := &Packet{PacketType: PacketFriendMessage, Data: []byte("after rotation")}
+	if err := p.a.Send(packet, p.addrB); !errors.Is(err, ErrNoiseSessionIncomplete) {
+		t.Fatalf("Expected ErrNoiseSessionIncomplete, got %v", err)
+	}
+	errs := p.relay()
+
+	var mismatch bool
+	for _, err := range errs {
+		mismatch = mismatch || errors.Is(err, ErrNoiseFallbackKeyMismatch)
+	}
+	if !mismatch {
+		t.Errorf("Expected ErrNoiseFallbackKeyMismatch from fallback, got %v", errs)
+	}
+	if p.session(p.a, p.addrB) != nil {
+		t.Error("Expected initiator session to be dropped after key mismatch")
+	}
+	if sessionB := p.session(p.b, p.addrA); sessionB != nil && sessionB.IsComplete() {
+		t.Error("Expected responder session not to complete")
+	}
+
+	p.a.peerKeysMu.RLock()
+	cached := p.a.peerKeys[p.addrB.String()]
+	p.a.peerKeysMu.RUnlock()
+	if !bytes.Equal(cached, stale) {
+		t.Error("Expected peer key to stay unchanged after key mismatch")
+	}
+}
+
+func TestNoiseXXFallbackWithExpectedKey(t *testing.T) {
+	p := newNoisePair(t)
+	if err := p.a.AddPeer(p.addrB, p.keysB.Public[:]); err != nil {
+		t.Fatal(err)
+	}
+
+	received := make(chan []byte, 1)
+	p.b.RegisterHandler(PacketFriendMessage, func(packet *Packet, addr net.Addr) error {
+		received <- packet.Data
+		return nil
+	})
+
+	if err := p.a.initiateHandshake(p.addrB); err != nil {
+		t.Fatal(err)
+	}
+	// Drop the IK initiation and reject it as an on-path attacker would;
+	// the fallback still reaches b, which proves the expected key.
+	if err := p.a.handleHandshakeRejection(p.ikEphemeral(t), p.addrB); err != nil {
+		t.Fatal(err)
+	}
+	p.deliveredFromA = 1
+	p.relay()
+
+	sessionA, sessionB := p.session(p.a, p.addrB), p.session(p.b, p.addrA)
+	if sessionA == nil || !sessionA.IsComplete() {
+		t.Fatal("Expected initiator session to complete via XX fallback")
+	}
+	if sessionB == nil || !sessionB.IsComplete() {
+		t.Fatal("Expected responder session to complete via XX fallback")
+	}
+	if _, ok := sessionA.handshake.(*toxnoise.XXHandshake); !ok {
+		t.Errorf("Expected XX handshake on initiator session, got %T", sessionA.handshake)
+	}
+
+	packet := &Packet{PacketType: PacketFriendMessage, Data: []byte("after fallback")}
+	if err := p.a.Send(packet, p.addrB); err != nil {
+		t.Fatalf("Send after fallback failed: %v", err)
+	}
+	p.relay()
+	select {
+	case data := <-received:
+		if string(data) != "after fallback" {
+			t.Errorf("Unexpected payload %q", data)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for encrypted packet over fallback session")
+	}
+}
+
+func TestNoiseXXFallbackRespectsKeyPin(t *testing.T) {
+	p := newNoisePair(t)
+	stale := staleKey(t)
+	if err := p.a.AddPeer(p.addrB, stale); err != nil {
+		t.Fatal(err)
+	}
+	var pinned [32]byte
+	copy(pinned[:], stale)
+	if err := p.a.getKeyPinStore().Pin(p.addrB, pinned); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := p.a.initiateHandshake(p.addrB); err != nil {
+		t.Fatal(err)
+	}
+	errs := p.relay()
+
+	var mismatch bool
+	for _, err := range errs {
+		mismatch = mismatch || errors.Is(err, toxnoise.ErrKeyPinMismatch)
+	}
+	if !mismatch {
+		t.Errorf("Expected ErrKeyPinMismatch from fallback, got %v", errs)
+	}
+	if p.session(p.a, p.addrB) != nil {
+		t.Error("Expected initiator session to be dropped after pin mismatch")
+	}
+	p.a.peerKeysMu.RLock()
+	cached := p.a.peerKeys[p.addrB.String()]
+	p.a.peerKeysMu.RUnlock()
+	if !bytes.Equal(cached, stale) {
+		t.Error("Expected peer key to stay unchanged after pin mismatch")
+	}
+}
+
+func TestNoiseXXFallbackSingleAttempt(t *testing.T) {
+	p := newNoisePair(t)
+	if err := p.a.AddPeer(p.addrB, staleKey(t)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := p.a.handleHandshakeRejection(nil, p.addrB); !errors.Is(err, ErrNoiseSessionNotFound) {
+		t.Errorf("Expected ErrNoiseSessionNotFound without a session, got %v", err)
+	}
+
+	if err := p.a.initiateHandshake(p.addrB); err != nil {
+		t.Fatal(err)
+	}
+	echo := p.ikEphemeral(t)
+	if err := p.a.handleHandshakeRejection(nil, p.addrB); err == nil {
+		t.Error("Expected a rejection without the echoed ephemeral to be refused")
+	}
+	if err := p.a.handleHandshakeRejection(staleKey(t), p.addrB); err == nil {
+		t.Error("Expected a rejection with the wrong ephemeral to be refused")
+	}
+	if p.session(p.a, p.addrB).fallback {
+		t.Fatal("Expected a refused rejection to leave the IK session untouched")
+	}
+	if err := p.a.handleHandshakeRejection(echo, p.addrB); err != nil {
+		t.Fatalf("First rejection failed: %v", err)
+	}
+	session := p.session(p.a, p.addrB)
+	session.mu.RLock()
+	handshake := session.handshake
+	session.mu.RUnlock()
+
+	if err := p.a.handleHandshakeRejection(echo, p.addrB); err == nil {
+		t.Error("Expected a second rejection to be refused")
+	}
+	session.mu.RLock()
+	defer session.mu.RUnlock()
+	if session.handshake != handshake {
+		t.Error("Expected the fallback handshake not to be restarted")
+	}
+}
+
+func TestNoiseResponderRejectsStaleKey(t *testing.T) {
+	p := newNoisePair(t)
+	initiator, err := toxnoise.NewIKHandshake(p.keysA.Private[:], staleKey(t), toxnoise.Initiator)
+	if err != nil {
+		t.Fatal(err)
+	}
+	message, _, err := initiator.WriteMessage(nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = p.b.handleHandshakePacket(&Packet{PacketType: PacketNoiseHandshake, Data: message}, p.addrA)
+	if !errors.Is(err, toxnoise.ErrHandshakeFailed) {
+		t.Fatalf("Expected ErrHandshakeFailed, got %v", err)
+	}
+	packets := p.mockB.GetPackets()
+	if len(packets) != 1 || packets[0].packet.PacketType != PacketNoiseFallback ||
+		!bytes.Equal(packets[0].packet.Data, append([]byte{noiseFallbackReject}, message[:noiseEphemeralKeySize]...)) {
+		t.Fatalf("Expected a single rejection packet, got %v", packets)
+	}
+	if p.session(p.b, p.addrA) != nil {
+		t.Error("Expected responder to keep no state for a rejected handshake")
+	}
+}
@@ -0,0 +1,95 @@
+package transport
+
+import (
+	"errors"
+	"fmt"
+	"net"
+
+	toxnoise "github.com/opd-ai/toxcore/noise"
+	"github.com/sirupsen/logrus"
+)
+
+// SetKeyPinStore replaces the store used to pin each peer's static key to
+// its address. After a handshake completes the remote key is pinned, and
+// a later handshake from the same address presenting a different key is
+// dropped with toxnoise.ErrKeyPinMismatch. The default is an in-memory
+// toxnoise.LocalKeyPinStore; pass one created with a file path to keep pins
+// across restarts, or nil to disable pinning.
+func (nt *NoiseTransport) SetKeyPinStore(store toxnoise.KeyPinStore) {
+	nt.keyPinsMu.Lock()
+	nt.keyPins = store
+	nt.keyPinsMu.Unlock()
+}
+
+// getKeyPinStore returns the configured pin store, or nil.
+func (nt *NoiseTransport) getKeyPinStore() toxnoise.KeyPinStore {
+	nt.keyPinsMu.RLock()
+	defer nt.keyPinsMu.RUnlock()
+	return nt.keyPins
+}
+
+// remoteStaticKey returns the peer's static key from a completed handshake.
+func remoteStaticKey(session *NoiseSession) ([32]byte, error) {
+	session.mu.RLock()
+	handshake := session.handshake
+	session.mu.RUnlock()
+
+	var pk [32]byte
+	key, err := handshake.GetRemoteStaticKey()
+	if err != nil {
+		return pk, err
+	}
+	if len(key) != len(pk) {
+		return pk, fmt.Errorf("remote static key must be 32 bytes, got %d", len(key))
+	}
+	copy(pk[:], key)
+	return pk, nil
+}
+
+// checkKeyPin rejects a completed handshake whose remote static key does not
+// match the key pinned for addr. The caller must drop the session on error.
+func (nt *NoiseTransport) checkKeyPin(session *NoiseSession, addr net.Addr) error {
+	store := nt.getKeyPinStore()
+	if store == nil {
+		return nil
+	}
+	pk, err := remoteStaticKey(session)
+	if err != nil {
+		return fmt.Errorf("failed to get remote static key: %w", err)
+	}
+	if err := store.CheckPin(addr, pk); err != nil {
+		fields := logrus.Fields{
+			"function":   "checkKeyPin",
+			"peer":       addr.String(),
+			"public_key": pk[:8],
+			"error":      err.Error(),
+		}
+		if errors.Is(err, toxnoise.ErrKeyPinMismatch) {
+			pkgLog.WithFields(fields).Warn("Peer presented a different static key than pinned, dropping handshake")
+		} else {
+			pkgLog.WithFields(fields).Warn("Key pin check failed, dropping handshake")
+		}
+		return err
+	}
+	return nil
+}
+
+// pinPeerKey pins the remote static key of a completed session to addr.
+// A failure to persist the pin is logged; the session stays usable.
+func (nt *NoiseTransport) pinPeerKey(session *NoiseSession, addr net.Addr) {
+	store := nt.getKeyPinStore()
+	if store == nil {
+		return
+	}
+	pk, err := remoteStaticKey(session)
+	if err == nil {
+		err = store.Pin(addr, pk)
+	}
+	if err != nil {
+		pkgLog.WithFields(logrus.Fields{
+			"function": "pinPeerKey",
+			"peer":     addr.String(),
+			"error":    err.Error(),
+		}).Warn("Failed to pin peer static key")
+	}
+}
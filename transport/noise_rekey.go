@@ -0,0 +1,77 @@
+package transport
+
+import (
+	"crypto/sha256"
+
+	"github.com/flynn/noise"
+	"github.com/opd-ai/toxcore/crypto"
+	"github.com/sirupsen/logrus"
+)
+
+// noiseRekeyLabel domain-separates in-band rekey derivation from every other
+// hash computed over session key material.
+const noiseRekeyLabel = "toxcore-noise-rekey-v1"
+
+// noiseCipherSuite is the suite negotiated by both the IK and XX handshakes.
+var noiseCipherSuite = noise.NewCipherSuite(noise.DH25519, noise.CipherChaChaPoly, noise.HashSHA256)
+
+// sessionCipher wraps the Noise CipherState of one direction of a session so
+// the key can be replaced in-band without a new handshake.
+type sessionCipher struct {
+	*noise.CipherState
+}
+
+// newSessionCipher wraps cs, returning nil for a nil cs.
+func newSessionCipher(cs *noise.CipherState) *sessionCipher {
+	if cs == nil {
+		return nil
+	}
+	return &sessionCipher{CipherState: cs}
+}
+
+// RekeyAt replaces the key once counter messages have been processed with
+// it. The new key is SHA-256(noiseRekeyLabel || key) and the nonce restarts
+// at zero. Both peers call RekeyAt with the same counter after every message,
+// so they switch keys at the same point without exchanging anything. A zero
+// counter never rekeys. It reports whether the key was replaced.
+func (sc *sessionCipher) RekeyAt(counter uint64) bool {
+	if counter == 0 || sc.Nonce() < counter {
+		return false
+	}
+
+	key := sc.UnsafeKey()
+	h := sha256.New()
+	h.Write([]byte(noiseRekeyLabel))
+	h.Write(key[:])
+	var next [32]byte
+	copy(next[:], h.Sum(nil))
+	crypto.ZeroBytes(key[:])
+
+	sc.CipherState = noise.UnsafeNewCipherState(noiseCipherSuite, next, 0)
+	crypto.ZeroBytes(next[:])
+	return true
+}
+
+// SetInBandRekeyThreshold makes sessions replace each direction's key after
+// every threshold messages, so a key recovered from memory cannot decrypt
+// traffic sent before its last rekey. Unlike the handshake rekey enforced by
+// NoiseSession.SetRekeyThreshold, this needs no round trip: both peers derive
+// the next key locally at the same message count, so they must be
+// configured with the same threshold. It applies to sessions completed after
+// the call. A threshold of 0, the default, disables in-band rekeying.
+func (nt *NoiseTransport) SetInBandRekeyThreshold(threshold uint64) {
+	nt.inBandRekeyThreshold.Store(threshold)
+}
+
+// rekeyInBand rekeys cipher if it has reached the session's in-band
+// threshold. Caller must hold ns.mu.
+func (ns *NoiseSession) rekeyInBand(cipher *sessionCipher, direction string) {
+	if !cipher.RekeyAt(ns.inBandRekeyAt) {
+		return
+	}
+	pkgLog.WithFields(logrus.Fields{
+		"function":  "rekeyInBand",
+		"peer":      ns.peerAddr,
+		"direction": direction,
+	}).Debug("Rekeyed Noise session cipher in-band")
+}
@@ -0,0 +1,160 @@
+package transport
+
+import (
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/flynn/noise"
+)
+
+func TestSessionCipherRekeyAt(t *testing.T) {
+	key := [32]byte{1, 2, 3}
+	sender := newSessionCipher(noise.UnsafeNewCipherState(noiseCipherSuite, key, 0))
+	receiver := newSessionCipher(noise.UnsafeNewCipherState(noiseCipherSuite, key, 0))
+	stale := newSessionCipher(noise.UnsafeNewCipherState(noiseCipherSuite, key, 0))
+
+	for i := 0; i < 7; i++ {
+		ciphertext, err := sender.Encrypt(nil, nil, []byte("message"))
+		if err != nil {
+			t.Fatalf("Encrypt %d failed: %v", i, err)
+		}
+		if _, err := receiver.Decrypt(nil, nil, ciphertext); err != nil {
+			t.Fatalf("Decrypt %d failed: %v", i, err)
+		}
+		_, staleErr := stale.Decrypt(nil, nil, ciphertext)
+		if i < 3 && staleErr != nil {
+			t.Fatalf("Decrypt %d without rekey failed before the threshold: %v", i, staleErr)
+		}
+		if i >= 3 && staleErr == nil {
+			t.Fatalf("Expected message %d to be unreadable with the old key", i)
+		}
+
+		sentRekey, recvRekey := sender.RekeyAt(3), receiver.RekeyAt(3)
+		if sentRekey != recvRekey || sentRekey != (i == 2 || i == 5) {
+			t.Fatalf("Message %d: unexpected rekey (send %v, recv %v)", i, sentRekey, recvRekey)
+		}
+	}
+
+	if sender.UnsafeKey() == key {
+		t.Error("Expected the key to change after rekeying")
+	}
+	if sender.Nonce() != 1 {
+		t.Errorf("Expected nonce to restart after the last rekey, got %d", sender.Nonce())
+	}
+	if sender.RekeyAt(0) {
+		t.Error("Expected a zero counter to disable rekeying")
+	}
+}
+
+// newEstablishedNoisePair returns a pair with an established session from a
+// to b, both configured with the given in-band rekey threshold.
+func newEstablishedNoisePair(t *testing.T, threshold uint64) *noisePair {
+	t.Helper()
+	p := newNoisePair(t)
+	p.a.SetInBandRekeyThreshold(threshold)
+	p.b.SetInBandRekeyThreshold(threshold)
+	if err := p.a.AddPeer(p.addrB, p.keysB.Public[:]); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.a.initiateHandshake(p.addrB); err != nil {
+		t.Fatal(err)
+	}
+	if errs := p.relay(); len(errs) != 0 {
+		t.Fatalf("Handshake failed: %v", errs)
+	}
+	return p
+}
+
+func collectFriendMessages(nt *NoiseTransport) chan string {
+	received := make(chan string, 64)
+	nt.RegisterHandler(PacketFriendMessage, func(packet *Packet, addr net.Addr) error {
+		received <- string(packet.Data)
+		return nil
+	})
+	return received
+}
+
+func expectFriendMessages(t *testing.T, received chan string, want map[string]bool) {
+	t.Helper()
+	for len(want) > 0 {
+		select {
+		case data := <-received:
+			if !want[data] {
+				t.Fatalf("Unexpected message %q", data)
+			}
+			delete(want, data)
+		case <-time.After(2 * time.Second):
+			t.Fatalf("Timed out waiting for %d messages", len(want))
+		}
+	}
+}
+
+func TestNoiseInBandRekeyKeepsSessionUsable(t *testing.T) {
+	const threshold = 4
+	p := newEstablishedNoisePair(t, threshold)
+	receivedA, receivedB := collectFriendMessages(p.a), collectFriendMessages(p.b)
+
+	sessionA := p.session(p.a, p.addrB)
+	sessionA.mu.RLock()
+	initialKey := sessionA.sendCipher.UnsafeKey()
+	sessionA.mu.RUnlock()
+
+	// Interleave both directions across several rekey boundaries
+	wantA, wantB := make(map[string]bool), make(map[string]bool)
+	for i := 0; i < 5*threshold+1; i++ {
+		toB := fmt.Sprintf("a->b %d", i)
+		toA := fmt.Sprintf("b->a %d", i)
+		wantB[toB], wantA[toA] = true, true
+		if err := p.a.Send(&Packet{PacketType: PacketFriendMessage, Data: []byte(toB)}, p.addrB); err != nil {
+			t.Fatalf("Send %q failed: %v", toB, err)
+		}
+		if errs := p.relay(); len(errs) != 0 {
+			t.Fatalf("Delivering %q failed: %v", toB, errs)
+		}
+		if err := p.b.Send(&Packet{PacketType: PacketFriendMessage, Data: []byte(toA)}, p.addrA); err != nil {
+			t.Fatalf("Send %q failed: %v", toA, err)
+		}
+		if errs := p.relay(); len(errs) != 0 {
+			t.Fatalf("Delivering %q failed: %v", toA, errs)
+		}
+	}
+	expectFriendMessages(t, receivedB, wantB)
+	expectFriendMessages(t, receivedA, wantA)
+
+	sessionA.mu.RLock()
+	defer sessionA.mu.RUnlock()
+	if sessionA.sendCipher.UnsafeKey() == initialKey {
+		t.Error("Expected the send key to have been rekeyed in-band")
+	}
+	if sessionA.sendCipher.Nonce() >= threshold {
+		t.Errorf("Expected nonce below %d after rekeying, got %d", threshold, sessionA.sendCipher.Nonce())
+	}
+	if sessionA.sendMessageCount <= threshold {
+		t.Errorf("Expected message count to continue across rekeys, got %d", sessionA.sendMessageCount)
+	}
+}
+
+func TestNoiseInBandRekeyThresholdMismatch(t *testing.T) {
+	p := newEstablishedNoisePair(t, 2)
+	sessionB := p.session(p.b, p.addrA)
+	sessionB.mu.Lock()
+	sessionB.inBandRekeyAt = 0
+	sessionB.mu.Unlock()
+	received := collectFriendMessages(p.b)
+
+	var errs []error
+	for i := 0; i < 4; i++ {
+		packet := &Packet{PacketType: PacketFriendMessage, Data: []byte(fmt.Sprintf("message %d", i))}
+		if err := p.a.Send(packet, p.addrB); err != nil {
+			t.Fatalf("Send %d failed: %v", i, err)
+		}
+		errs = append(errs, p.relay()...)
+	}
+	// The initiator's version commitment and "message 0" use the first key
+	expectFriendMessages(t, received, map[string]bool{"message 0": true})
+	if len(errs) != 3 {
+		t.Errorf("Expected every message after the sender rekeyed to fail, got %v", errs)
+	}
+}
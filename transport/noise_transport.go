@@ -1,6 +1,7 @@
 package transport
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net"
@@ -9,7 +10,6 @@ import (
 	"sync/atomic"
 	"time"
 
-	"github.com/flynn/noise"
 	"github.com/opd-ai/toxcore/crypto"
 	toxnoise "github.com/opd-ai/toxcore/noise"
 	"github.com/sirupsen/logrus"
@@ -37,6 +37,11 @@ var (
 	// ErrNoiseSessionIncomplete indicates a handshake is in progress but not yet
 	// complete. The caller should retry after the handshake completes.
 	ErrNoiseSessionIncomplete = errors.New("noise session incomplete: handshake in progress")
+	// ErrNoiseFallbackKeyMismatch indicates that the peer proved a different
+	// static key in an XX fallback than the one our IK initiation was made
+	// with. The session is dropped and the cached key is kept; a peer that
+	// rotated its key must be registered again with AddPeer.
+	ErrNoiseFallbackKeyMismatch = errors.New("noise fallback: peer static key does not match expected key")
 )
 
 const (
@@ -88,16 +93,21 @@ const (
 
 // NoiseSession tracks the handshake and cipher state for a peer connection.
 type NoiseSession struct {
-	mu         sync.RWMutex // Protects all fields for concurrent access
-	handshake  *toxnoise.IKHandshake
-	sendCipher *noise.CipherState
-	recvCipher *noise.CipherState
+	mu         sync.RWMutex   // Protects all fields for concurrent access
+	handshake  noiseHandshake // IK handshake, or XX after a fallback
+	sendCipher *sessionCipher
+	recvCipher *sessionCipher
 	peerAddr   net.Addr
 	role       toxnoise.HandshakeRole
 	complete   bool
+	fallback   bool      // True once the session switched to an XX fallback handshake
 	createdAt  time.Time // Time when session was created
 	lastActive time.Time // Time of last activity (send/receive)
 
+	// IK initiation state, used to validate a fallback (initiator only)
+	expectedPeerKey []byte // Static key the IK initiation was made with
+	ikEphemeral     []byte // Our IK ephemeral key, echoed by a genuine rejection
+
 	// Version commitment state
 	commitmentExchange  *VersionCommitmentExchange
 	versionCommitted    bool            // True after version commitment exchange completes
@@ -111,6 +121,11 @@ type NoiseSession struct {
 	recvMessageCount uint64 // Number of messages decrypted with current receive cipher
 	rekeyThreshold   uint64 // Configurable threshold (0 = DefaultRekeyThreshold)
 
+	// inBandRekeyAt is the number of messages after which each cipher's key
+	// is replaced in-band (0 = disabled). The message counters above keep
+	// counting across in-band rekeys.
+	inBandRekeyAt uint64
+
 	// Time-based rekey configuration.  A zero value uses the package-level default.
 	rekeyAfterDuration time.Duration // Maximum session age before forced rekey
 	rekeyIdleTimeout   time.Duration // Maximum idle period before forced rekey
@@ -142,13 +157,37 @@ type NoiseTransport struct {
 	// Protocol version for commitment exchange
 	// Protected with atomic operations to avoid data races during concurrent access
 	protocolVersion atomic.Uint32
+
+	// handshakeTimeout bounds how long a handshake may stay incomplete
+	// (nanoseconds; defaults to HandshakeTimeout).
+	handshakeTimeout atomic.Int64
+
+	// inBandRekeyThreshold is copied into each session as it completes
+	// (0 disables in-band rekeying).
+	inBandRekeyThreshold atomic.Uint64
+
+	// keyPins rejects handshakes whose remote static key differs from the
+	// key first seen at the same address (nil disables pinning).
+	keyPins   toxnoise.KeyPinStore
+	keyPinsMu sync.RWMutex
+
+	// passthrough is set when the underlying transport authenticates peers
+	// itself (see peerAuthenticator); packets then bypass Noise entirely.
+	passthrough bool
+}
+
+// peerAuthenticator is implemented by transports whose own security layer
+// mutually authenticates peers and encrypts traffic, such as QUICTransport
+// with QUICOptions.SkipNoise.
+type peerAuthenticator interface {
+	AuthenticatesPeers() bool
 }
 
 // NewNoiseTransport creates a transport wrapper that adds Noise-IK encryption.
 // staticPrivKey is our long-term Curve25519 private key (32 bytes).
 // underlying is the base transport (UDP/TCP) to wrap.
 func NewNoiseTransport(underlying Transport, staticPrivKey []byte) (*NoiseTransport, error) {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":        "NewNoiseTransport",
 		"static_key_len":  len(staticPrivKey),
 		"underlying_type": fmt.Sprintf("%T", underlying),
@@ -167,7 +206,7 @@ func NewNoiseTransport(underlying Transport, staticPrivKey []byte) (*NoiseTransp
 	startNoiseTransportCleanup(nt)
 	registerNoiseHandlers(underlying, nt, keypair)
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":            "NewNoiseTransport",
 		"public_key":          keypair.Public[:8],
 		"handlers_registered": 2,
@@ -179,7 +218,7 @@ func NewNoiseTransport(underlying Transport, staticPrivKey []byte) (*NoiseTransp
 // validateNoiseTransportInputs validates the inputs for NewNoiseTransport.
 func validateNoiseTransportInputs(underlying Transport, staticPrivKey []byte) error {
 	if len(staticPrivKey) != 32 {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":       "NewNoiseTransport",
 			"static_key_len": len(staticPrivKey),
 			"expected_len":   32,
@@ -187,7 +226,7 @@ func validateNoiseTransportInputs(underlying Transport, staticPrivKey []byte) er
 		return fmt.Errorf("static private key must be 32 bytes, got %d", len(staticPrivKey))
 	}
 	if underlying == nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "NewNoiseTransport",
 		}).Error("Underlying transport is nil")
 		return errors.New("underlying transport cannot be nil")
@@ -201,7 +240,7 @@ func generateKeypair(staticPrivKey []byte) (*crypto.KeyPair, error) {
 	copy(staticPrivArray[:], staticPrivKey)
 	keypair, err := crypto.FromSecretKey(staticPrivArray)
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "NewNoiseTransport",
 			"error":    err.Error(),
 		}).Error("Failed to generate keypair from private key")
@@ -223,14 +262,24 @@ func createNoiseTransportInstance(underlying Transport, staticPrivKey []byte, ke
 		stopCleanup:        make(chan struct{}),
 		stopSessionCleanup: make(chan struct{}),
 	}
+	if pa, ok := underlying.(peerAuthenticator); ok && pa.AuthenticatesPeers() {
+		nt.passthrough = true
+		pkgLog.WithFields(logrus.Fields{
+			"function":        "NewNoiseTransport",
+			"underlying_type": fmt.Sprintf("%T", underlying),
+		}).Info("Underlying transport authenticates peers, Noise-IK bypassed")
+	}
+	// An in-memory store cannot fail to open
+	nt.keyPins, _ = toxnoise.NewLocalKeyPinStore("")
 
 	copy(nt.staticPriv, staticPrivKey)
 	copy(nt.staticPub, keypair.Public[:])
 
 	// Initialize protocol version to Noise-IK
 	nt.protocolVersion.Store(uint32(ProtocolNoiseIK))
+	nt.handshakeTimeout.Store(int64(HandshakeTimeout))
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":      "NewNoiseTransport",
 		"public_key":    keypair.Public[:8],
 		"session_count": 0,
@@ -258,6 +307,7 @@ func startNoiseTransportCleanup(nt *NoiseTransport) {
 func registerNoiseHandlers(underlying Transport, nt *NoiseTransport, keypair *crypto.KeyPair) {
 	underlying.RegisterHandler(PacketNoiseHandshake, nt.handleHandshakePacket)
 	underlying.RegisterHandler(PacketNoiseMessage, nt.handleEncryptedPacket)
+	underlying.RegisterHandler(PacketNoiseFallback, nt.handleFallbackPacket)
 	// Note: PacketVersionCommitment is registered with nt.handlers, not underlying,
 	// because it arrives encrypted as part of PacketNoiseMessage and is dispatched
 	// after decryption in handleEncryptedPacket.
@@ -382,8 +432,9 @@ func (nt *NoiseTransport) AddPeer(addr net.Addr, publicKey []byte) error {
 // handshake is initiated and [ErrNoiseSessionIncomplete] is returned.  Callers
 // must retry the send (with appropriate backoff) until the session completes.
 // Sending without a known peer key returns [ErrNoiseHandshakeFailed].
+// Over a transport that authenticates peers itself, packets are sent as is.
 func (nt *NoiseTransport) Send(packet *Packet, addr net.Addr) error {
-	if packet.PacketType == PacketNoiseHandshake {
+	if packet.PacketType == PacketNoiseHandshake || nt.passthrough {
 		// Handshake packets are never encrypted
 		return nt.underlying.Send(packet, addr)
 	}
@@ -396,7 +447,7 @@ func (nt *NoiseTransport) Send(packet *Packet, addr net.Addr) error {
 	if exists && !session.IsComplete() {
 		// Session exists but handshake is still in progress — do NOT
 		// re-initiate (that would overwrite in-flight state). Caller must retry.
-		logrus.WithField("peer", addr.String()).Debug("Noise handshake in progress")
+		pkgLog.WithField("peer", addr.String()).Debug("Noise handshake in progress")
 		return ErrNoiseSessionIncomplete
 	}
 
@@ -408,14 +459,14 @@ func (nt *NoiseTransport) Send(packet *Packet, addr net.Addr) error {
 			// backoff, use a different transport, or notify the user that secure
 			// communication is not possible. Silent downgrade to cleartext is a
 			// critical security vulnerability (CVE-class: protocol downgrade).
-			logrus.WithFields(logrus.Fields{
+			pkgLog.WithFields(logrus.Fields{
 				"peer":  addr.String(),
 				"error": err,
 			}).Warn("Noise handshake failed - refusing to send unencrypted")
 			return fmt.Errorf("%w: %v", ErrNoiseHandshakeFailed, err)
 		}
 		// Handshake triggered but not yet complete; caller must retry.
-		logrus.WithField("peer", addr.String()).Debug("Noise handshake initiated")
+		pkgLog.WithField("peer", addr.String()).Debug("Noise handshake initiated")
 		return ErrNoiseSessionIncomplete
 	}
 
@@ -457,7 +508,7 @@ func (nt *NoiseTransport) Close() error {
 	// Persist the nonce store so nonces survive across restarts.
 	if nt.nonceStore != nil {
 		if err := nt.nonceStore.Close(); err != nil {
-			logrus.WithError(err).Warn("NoiseTransport: failed to save persistent nonce store on close")
+			pkgLog.WithError(err).Warn("NoiseTransport: failed to save persistent nonce store on close")
 		}
 	}
 
@@ -482,7 +533,7 @@ func (nt *NoiseTransport) SetNonceDataDir(dataDir string) error {
 	nt.noncesMu.Lock()
 	if nt.nonceStore != nil {
 		if closeErr := nt.nonceStore.Close(); closeErr != nil {
-			logrus.WithError(closeErr).Warn("NoiseTransport: failed to close previous nonce store")
+			pkgLog.WithError(closeErr).Warn("NoiseTransport: failed to close previous nonce store")
 		}
 	}
 	nt.nonceStore = ns
@@ -492,16 +543,61 @@ func (nt *NoiseTransport) SetNonceDataDir(dataDir string) error {
 	return nil
 }
 
+// SetHandshakeTimeout sets the maximum time a handshake may remain incomplete,
+// measured from when its session was created. A handshake step that would
+// finish after the deadline is abandoned: the partial state is discarded, the
+// session slot is freed and ErrHandshakeTimeout is returned. The session
+// cleanup goroutine also reaps incomplete handshakes older than d.
+// A non-positive d restores the default of HandshakeTimeout.
+func (nt *NoiseTransport) SetHandshakeTimeout(d time.Duration) {
+	if d <= 0 {
+		d = HandshakeTimeout
+	}
+	nt.handshakeTimeout.Store(int64(d))
+}
+
+// getHandshakeTimeout returns the configured handshake timeout.
+func (nt *NoiseTransport) getHandshakeTimeout() time.Duration {
+	return time.Duration(nt.handshakeTimeout.Load())
+}
+
+// runHandshakeStep runs a single handshake WriteMessage/ReadMessage step
+// under a context that expires handshakeTimeout after started. If the
+// deadline passes first, the step's result is ignored and ErrHandshakeTimeout
+// is returned; the caller is responsible for discarding the session.
+func (nt *NoiseTransport) runHandshakeStep(started time.Time, step func() error) error {
+	ctx, cancel := context.WithDeadline(context.Background(), started.Add(nt.getHandshakeTimeout()))
+	defer cancel()
+
+	if ctx.Err() != nil {
+		return fmt.Errorf("%w: exceeded %v", ErrHandshakeTimeout, nt.getHandshakeTimeout())
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- step() }()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("%w: exceeded %v", ErrHandshakeTimeout, nt.getHandshakeTimeout())
+	}
+}
+
 // LocalAddr returns the local address from the underlying transport.
 func (nt *NoiseTransport) LocalAddr() net.Addr {
 	return nt.underlying.LocalAddr()
 }
 
-// RegisterHandler registers a handler for decrypted packets.
+// RegisterHandler registers a handler for decrypted packets. In passthrough
+// mode the handler is registered directly on the underlying transport.
 func (nt *NoiseTransport) RegisterHandler(packetType PacketType, handler PacketHandler) {
 	nt.handlersMu.Lock()
 	nt.handlers[packetType] = handler
 	nt.handlersMu.Unlock()
+	if nt.passthrough && packetType != PacketVersionCommitment {
+		nt.underlying.RegisterHandler(packetType, handler)
+	}
 }
 
 // initiateHandshake starts a Noise-IK handshake with a known peer.
@@ -523,21 +619,31 @@ func (nt *NoiseTransport) initiateHandshake(addr net.Addr) error {
 	}
 
 	// Generate initial message
-	message, _, err := handshake.WriteMessage(nil, nil)
+	now := time.Now()
+	var message []byte
+	err = nt.runHandshakeStep(now, func() error {
+		var stepErr error
+		message, _, stepErr = handshake.WriteMessage(nil, nil)
+		return stepErr
+	})
+	if errors.Is(err, ErrHandshakeTimeout) {
+		return err
+	}
 	if err != nil {
 		return fmt.Errorf("failed to generate handshake message: %w", err)
 	}
 
 	// Store session
-	now := time.Now()
 	nt.sessionsMu.Lock()
 	nt.sessions[addrKey] = &NoiseSession{
-		handshake:  handshake,
-		peerAddr:   addr,
-		role:       toxnoise.Initiator,
-		complete:   false,
-		createdAt:  now,
-		lastActive: now,
+		handshake:       handshake,
+		peerAddr:        addr,
+		role:            toxnoise.Initiator,
+		complete:        false,
+		createdAt:       now,
+		lastActive:      now,
+		expectedPeerKey: append([]byte(nil), peerPubKey...),
+		ikEphemeral:     append([]byte(nil), message[:noiseEphemeralKeySize]...),
 	}
 	nt.sessionsMu.Unlock()
 
@@ -559,12 +665,22 @@ func (nt *NoiseTransport) handleHandshakePacket(packet *Packet, addr net.Addr) e
 
 	session.mu.RLock()
 	isComplete := session.complete
+	isFallback := session.fallback
 	role := session.role
+	createdAt := session.createdAt
 	session.mu.RUnlock()
 
 	if isComplete {
 		return fmt.Errorf("handshake already complete for peer %s", addr)
 	}
+	if isFallback {
+		return fmt.Errorf("fallback handshake in progress for peer %s", addr)
+	}
+
+	if time.Since(createdAt) > nt.getHandshakeTimeout() {
+		nt.deleteSession(addr)
+		return fmt.Errorf("%w: peer %s", ErrHandshakeTimeout, addr)
+	}
 
 	if role == toxnoise.Responder {
 		return nt.processResponderHandshake(session, packet, addr)
@@ -619,7 +735,12 @@ const noiseEphemeralKeySize = 32
 // processResponderHandshake handles handshake processing for responder role.
 func (nt *NoiseTransport) processResponderHandshake(session *NoiseSession, packet *Packet, addr net.Addr) error {
 	session.mu.Lock()
-	handshake := session.handshake
+	handshake, ok := session.handshake.(*toxnoise.IKHandshake)
+	if !ok {
+		session.mu.Unlock()
+		nt.deleteSession(addr)
+		return fmt.Errorf("unexpected handshake type %T for IK responder", session.handshake)
+	}
 
 	// Replay protection: the Noise IK initiator message begins with the
 	// initiator's ephemeral public key (noiseEphemeralKeySize bytes). Use that
@@ -640,12 +761,33 @@ func (nt *NoiseTransport) processResponderHandshake(session *NoiseSession, packe
 		return fmt.Errorf("handshake validation failed: %w", err)
 	}
 
-	response, complete, err := handshake.WriteMessage(nil, packet.Data)
+	var (
+		response []byte
+		complete bool
+	)
+	err := nt.runHandshakeStep(session.createdAt, func() error {
+		var stepErr error
+		response, complete, stepErr = handshake.WriteMessage(nil, packet.Data)
+		return stepErr
+	})
 	session.mu.Unlock()
+	if errors.Is(err, ErrHandshakeTimeout) {
+		nt.deleteSession(addr)
+		return err
+	}
 	if err != nil {
 		nt.deleteSession(addr)
+		if errors.Is(err, toxnoise.ErrHandshakeFailed) {
+			nt.sendHandshakeRejection(peerEphemeral[:], addr)
+		}
 		return fmt.Errorf("failed to generate handshake response: %w", err)
 	}
+	if complete {
+		if err := nt.checkKeyPin(session, addr); err != nil {
+			nt.deleteSession(addr)
+			return err
+		}
+	}
 
 	// Send the handshake response first so the initiator can complete its own
 	// session before receiving any subsequent encrypted packets (e.g. the
@@ -664,6 +806,7 @@ func (nt *NoiseTransport) processResponderHandshake(session *NoiseSession, packe
 			nt.deleteSession(addr)
 			return err
 		}
+		nt.pinPeerKey(session, addr)
 	}
 
 	return nil
@@ -680,15 +823,32 @@ func (nt *NoiseTransport) deleteSession(addr net.Addr) {
 func (nt *NoiseTransport) processInitiatorHandshake(session *NoiseSession, packet *Packet, addr net.Addr) error {
 	session.mu.Lock()
 	handshake := session.handshake
+	createdAt := session.createdAt
 	session.mu.Unlock()
 
-	_, complete, err := handshake.ReadMessage(packet.Data)
+	var complete bool
+	err := nt.runHandshakeStep(createdAt, func() error {
+		var stepErr error
+		_, complete, stepErr = handshake.ReadMessage(packet.Data)
+		return stepErr
+	})
+	if errors.Is(err, ErrHandshakeTimeout) {
+		nt.deleteSession(addr)
+		return err
+	}
 	if err != nil {
 		return fmt.Errorf("failed to read handshake response: %w", err)
 	}
 
 	if complete {
-		return nt.completeCipherSetup(session, addr)
+		if err := nt.checkKeyPin(session, addr); err != nil {
+			nt.deleteSession(addr)
+			return err
+		}
+		if err := nt.completeCipherSetup(session, addr); err != nil {
+			return err
+		}
+		nt.pinPeerKey(session, addr)
 	}
 
 	return nil
@@ -710,8 +870,9 @@ func (nt *NoiseTransport) completeCipherSetup(session *NoiseSession, addr net.Ad
 		return fmt.Errorf("failed to get cipher states: %w", err)
 	}
 
-	session.sendCipher = sendCipher
-	session.recvCipher = recvCipher
+	session.sendCipher = newSessionCipher(sendCipher)
+	session.recvCipher = newSessionCipher(recvCipher)
+	session.inBandRekeyAt = nt.inBandRekeyThreshold.Load()
 	session.complete = true
 
 	// Get handshake transcript channel binding for version commitment binding.
@@ -722,7 +883,7 @@ func (nt *NoiseTransport) completeCipherSetup(session *NoiseSession, addr net.Ad
 	exchange, err := NewVersionCommitmentExchange(ProtocolVersion(nt.protocolVersion.Load()), handshakeHash)
 	if err != nil {
 		session.mu.Unlock()
-		logrus.WithError(err).Warn("Failed to create version commitment exchange")
+		pkgLog.WithError(err).Warn("Failed to create version commitment exchange")
 		return nil // Don't fail handshake, commitment is defense-in-depth
 	}
 	session.commitmentExchange = exchange
@@ -736,7 +897,7 @@ func (nt *NoiseTransport) completeCipherSetup(session *NoiseSession, addr net.Ad
 	// nonce before the initiator's session is ready to decrypt it.
 	if role == toxnoise.Initiator {
 		if sendErr := nt.sendVersionCommitmentOnce(session, addr); sendErr != nil {
-			logrus.WithError(sendErr).Warn("Failed to send version commitment (initiator)")
+			pkgLog.WithError(sendErr).Warn("Failed to send version commitment (initiator)")
 		}
 	}
 
@@ -858,7 +1019,7 @@ func (nt *NoiseTransport) ensureCommitmentExchange(session *NoiseSession) error
 // verifyPeerCommitment validates the peer's version commitment.
 func (nt *NoiseTransport) verifyPeerCommitment(session *NoiseSession, commitmentData []byte, addr net.Addr) error {
 	if err := session.commitmentExchange.ProcessPeerCommitment(commitmentData); err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"peer":  addr.String(),
 			"error": err.Error(),
 		}).Warn("Version commitment verification failed - potential downgrade attack")
@@ -872,7 +1033,7 @@ func (nt *NoiseTransport) finalizeVersionCommitment(session *NoiseSession, addr
 	session.versionCommitted = true
 	session.agreedVersion = ProtocolVersion(nt.protocolVersion.Load())
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"peer":    addr.String(),
 		"version": session.agreedVersion.String(),
 	}).Info("Version commitment exchange complete")
@@ -903,7 +1064,7 @@ func (nt *NoiseTransport) handleEncryptedPacket(packet *Packet, addr net.Addr) e
 	// permanently desync nonce counters and silently drop all subsequent messages.
 	if session.role == toxnoise.Responder {
 		if sendErr := nt.sendVersionCommitmentOnce(session, addr); sendErr != nil {
-			logrus.WithError(sendErr).Warn("Failed to send version commitment (responder, lazy)")
+			pkgLog.WithError(sendErr).Warn("Failed to send version commitment (responder, lazy)")
 		}
 	}
 
@@ -964,6 +1125,7 @@ func (nt *NoiseTransport) encryptPacket(packet *Packet, session *NoiseSession) (
 
 	session.lastActive = time.Now()
 	session.sendMessageCount++
+	session.rekeyInBand(session.sendCipher, "send")
 	session.mu.Unlock()
 
 	return &Packet{
@@ -1021,7 +1183,7 @@ func (nt *NoiseTransport) storeHandshakeNonce(nonce [32]byte, now int64) error {
 		return ErrHandshakeReplay
 	}
 	if len(nt.usedNonces) >= MaxNonceMapSize {
-		logrus.Warn("NoiseTransport: nonce map at capacity — dropping handshake to prevent memory exhaustion; consider calling SetNonceDataDir")
+		pkgLog.Warn("NoiseTransport: nonce map at capacity — dropping handshake to prevent memory exhaustion; consider calling SetNonceDataDir")
 		return ErrHandshakeReplay
 	}
 	nt.usedNonces[nonce] = now
@@ -1074,7 +1236,7 @@ func (nt *NoiseTransport) performSessionCleanup() {
 	}
 
 	if removed > 0 {
-		logrus.WithField("removed_count", removed).Debug("Session cleanup completed")
+		pkgLog.WithField("removed_count", removed).Debug("Session cleanup completed")
 	}
 }
 
@@ -1089,12 +1251,14 @@ func (nt *NoiseTransport) shouldRemoveSession(session *NoiseSession, now time.Ti
 	return nt.isSessionIdle(session, now)
 }
 
-// isHandshakeTimedOut checks if an incomplete handshake has exceeded the timeout.
+// isHandshakeTimedOut checks if an incomplete handshake has exceeded the
+// configured handshake timeout.
 func (nt *NoiseTransport) isHandshakeTimedOut(session *NoiseSession, now time.Time) bool {
-	if now.Sub(session.createdAt) > HandshakeTimeout {
-		logrus.WithFields(logrus.Fields{
+	timeout := nt.getHandshakeTimeout()
+	if now.Sub(session.createdAt) > timeout {
+		pkgLog.WithFields(logrus.Fields{
 			"age":     now.Sub(session.createdAt),
-			"timeout": HandshakeTimeout,
+			"timeout": timeout,
 		}).Info("Removing incomplete handshake session (timeout)")
 		return true
 	}
@@ -1104,7 +1268,7 @@ func (nt *NoiseTransport) isHandshakeTimedOut(session *NoiseSession, now time.Ti
 // isSessionIdle checks if a complete session has been idle too long.
 func (nt *NoiseTransport) isSessionIdle(session *NoiseSession, now time.Time) bool {
 	if now.Sub(session.lastActive) > SessionIdleTimeout {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"idle":    now.Sub(session.lastActive),
 			"timeout": SessionIdleTimeout,
 		}).Info("Removing idle session (timeout)")
@@ -1147,7 +1311,7 @@ func (ns *NoiseSession) checkRekeyThreshold(msgCount uint64, direction string) (
 		return 0, ErrRekeyRequired
 	}
 	if msgCount == RekeyWarningThreshold {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":      "NoiseSession." + direction,
 			"peer_addr":     ns.peerAddr.String(),
 			"message_count": msgCount,
@@ -1193,11 +1357,11 @@ func (ns *NoiseSession) checkRekeyConditions(msgCount uint64, direction string,
 // Caller must NOT hold ns.mu. The function handles locking internally.
 func (ns *NoiseSession) doCipherOp(
 	data []byte,
-	cipher **noise.CipherState,
+	cipher **sessionCipher,
 	msgCount *uint64,
 	direction string,
 	cipherNilErr string,
-	op func(cs *noise.CipherState, data []byte) ([]byte, error),
+	op func(cs *sessionCipher, data []byte) ([]byte, error),
 ) ([]byte, error) {
 	ns.mu.Lock()
 	defer ns.mu.Unlock()
@@ -1221,6 +1385,7 @@ func (ns *NoiseSession) doCipherOp(
 
 	ns.lastActive = time.Now()
 	(*msgCount)++
+	ns.rekeyInBand(*cipher, direction)
 	return result, nil
 }
 
@@ -1234,7 +1399,7 @@ func (ns *NoiseSession) Encrypt(plaintext []byte) ([]byte, error) {
 		&ns.sendMessageCount,
 		"Encrypt",
 		"send cipher not initialized",
-		func(cs *noise.CipherState, data []byte) ([]byte, error) {
+		func(cs *sessionCipher, data []byte) ([]byte, error) {
 			return cs.Encrypt(nil, nil, data)
 		},
 	)
@@ -1250,7 +1415,7 @@ func (ns *NoiseSession) Decrypt(ciphertext []byte) ([]byte, error) {
 		&ns.recvMessageCount,
 		"Decrypt",
 		"receive cipher not initialized",
-		func(cs *noise.CipherState, data []byte) ([]byte, error) {
+		func(cs *sessionCipher, data []byte) ([]byte, error) {
 			return cs.Decrypt(nil, nil, data)
 		},
 	)
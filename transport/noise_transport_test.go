@@ -8,7 +8,6 @@ import (
 	"testing"
 	"time"
 
-	"github.com/flynn/noise"
 	"github.com/opd-ai/toxcore/crypto"
 	toxnoise "github.com/opd-ai/toxcore/noise"
 )
@@ -902,7 +901,7 @@ func TestNoiseSessionDoCipherOpPreservesIdleTimeoutChecks(t *testing.T) {
 		lastActive:       time.Now().Add(-DefaultRekeyIdleTimeout - time.Second),
 		rekeyIdleTimeout: DefaultRekeyIdleTimeout,
 	}
-	cipher := &noise.CipherState{}
+	cipher := &sessionCipher{}
 	msgCount := uint64(0)
 
 	_, err := session.doCipherOp(
@@ -911,7 +910,7 @@ func TestNoiseSessionDoCipherOpPreservesIdleTimeoutChecks(t *testing.T) {
 		&msgCount,
 		"Decrypt",
 		"receive cipher not initialized",
-		func(cs *noise.CipherState, data []byte) ([]byte, error) {
+		func(cs *sessionCipher, data []byte) ([]byte, error) {
 			t.Fatal("cipher op should not run when idle rekey is required")
 			return nil, nil
 		},
@@ -931,7 +930,7 @@ func TestNoiseSessionDoCipherOpUpdatesLastActiveAfterSuccess(t *testing.T) {
 		createdAt:  time.Now(),
 		lastActive: oldLastActive,
 	}
-	cipher := &noise.CipherState{}
+	cipher := &sessionCipher{}
 	msgCount := uint64(0)
 
 	result, err := session.doCipherOp(
@@ -940,7 +939,7 @@ func TestNoiseSessionDoCipherOpUpdatesLastActiveAfterSuccess(t *testing.T) {
 		&msgCount,
 		"Encrypt",
 		"send cipher not initialized",
-		func(cs *noise.CipherState, data []byte) ([]byte, error) {
+		func(cs *sessionCipher, data []byte) ([]byte, error) {
 			return append([]byte(nil), data...), nil
 		},
 	)
@@ -957,3 +956,55 @@ func TestNoiseSessionDoCipherOpUpdatesLastActiveAfterSuccess(t *testing.T) {
 		t.Fatal("expected lastActive to update after successful cipher operation")
 	}
 }
+
+// TestNoiseKeyPinning verifies that a handshake from a pinned address with a
+// different static key is rejected.
+func TestNoiseKeyPinning(t *testing.T) {
+	responderKeys, err := crypto.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	mockTransport := NewMockTransport("127.0.0.1:8081")
+	nt, err := NewNoiseTransport(mockTransport, responderKeys.Private[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer nt.Close()
+	addr, _ := net.ResolveUDPAddr("udp", "127.0.0.1:8080")
+
+	handshakeFrom := func(keys *crypto.KeyPair) error {
+		initiator, err := toxnoise.NewIKHandshake(keys.Private[:], responderKeys.Public[:], toxnoise.Initiator)
+		if err != nil {
+			t.Fatal(err)
+		}
+		message, _, err := initiator.WriteMessage(nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = nt.handleHandshakePacket(&Packet{PacketType: PacketNoiseHandshake, Data: message}, addr)
+		nt.deleteSession(addr) // let the next handshake start fresh
+		return err
+	}
+
+	firstKeys, _ := crypto.GenerateKeyPair()
+	otherKeys, _ := crypto.GenerateKeyPair()
+	if err := handshakeFrom(firstKeys); err != nil {
+		t.Fatalf("first handshake failed: %v", err)
+	}
+	if err := handshakeFrom(firstKeys); err != nil {
+		t.Fatalf("handshake with pinned key failed: %v", err)
+	}
+
+	mockTransport.ClearPackets()
+	if err := handshakeFrom(otherKeys); !errors.Is(err, toxnoise.ErrKeyPinMismatch) {
+		t.Fatalf("expected ErrKeyPinMismatch, got %v", err)
+	}
+	if len(mockTransport.GetPackets()) != 0 {
+		t.Error("responder should not answer a handshake with a mismatched key")
+	}
+
+	nt.SetKeyPinStore(nil)
+	if err := handshakeFrom(otherKeys); err != nil {
+		t.Errorf("handshake with pinning disabled failed: %v", err)
+	}
+}
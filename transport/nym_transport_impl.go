@@ -63,7 +63,7 @@ func NewNymTransport() *NymTransport {
 		proxyAddr = "127.0.0.1:1080" // Default Nym SOCKS5 proxy port
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":   "NewNymTransport",
 		"proxy_addr": proxyAddr,
 	}).Info("Creating Nym transport (SOCKS5)")
@@ -71,7 +71,7 @@ func NewNymTransport() *NymTransport {
 	// Create SOCKS5 dialer for the Nym proxy
 	dialer, err := proxy.SOCKS5("tcp", proxyAddr, nil, proxy.Direct)
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":   "NewNymTransport",
 			"proxy_addr": proxyAddr,
 			"error":      err.Error(),
@@ -88,7 +88,7 @@ func NewNymTransport() *NymTransport {
 // Nym does not provide a listener interface via its SOCKS5 proxy; this operation
 // is unsupported. Configure a dedicated Nym service via the Nym service provider framework.
 func (t *NymTransport) Listen(address string) (net.Listener, error) {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "NymTransport.Listen",
 		"address":  address,
 	}).Debug("Nym listen requested")
@@ -109,7 +109,7 @@ func (t *NymTransport) Dial(address string) (net.Conn, error) {
 	proxyAddr := t.proxyAddr
 	t.mu.RUnlock()
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":   "NymTransport.Dial",
 		"address":    address,
 		"proxy_addr": proxyAddr,
@@ -149,7 +149,7 @@ func (t *NymTransport) ensureDialerInitialized(dialer proxy.Dialer, proxyAddr st
 
 	newDialer, err := proxy.SOCKS5("tcp", proxyAddr, nil, proxy.Direct)
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":   "NymTransport.Dial",
 			"proxy_addr": proxyAddr,
 			"error":      err.Error(),
@@ -168,7 +168,7 @@ func (t *NymTransport) ensureDialerInitialized(dialer proxy.Dialer, proxyAddr st
 func dialThroughNymProxy(dialer proxy.Dialer, address, proxyAddr string) (net.Conn, error) {
 	conn, err := dialer.Dial("tcp", address)
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":   "NymTransport.Dial",
 			"address":    address,
 			"proxy_addr": proxyAddr,
@@ -181,7 +181,7 @@ func dialThroughNymProxy(dialer proxy.Dialer, address, proxyAddr string) (net.Co
 
 // logNymConnectionEstablished logs successful Nym connection.
 func logNymConnectionEstablished(address string, conn net.Conn) {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":    "NymTransport.Dial",
 		"address":     address,
 		"local_addr":  conn.LocalAddr().String(),
@@ -198,7 +198,7 @@ func (t *NymTransport) DialPacket(address string) (net.PacketConn, error) {
 	proxyAddr := t.proxyAddr
 	t.mu.RUnlock()
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "NymTransport.DialPacket",
 		"address":  address,
 	}).Debug("Nym packet dial requested")
@@ -221,7 +221,7 @@ func (t *NymTransport) DialPacket(address string) (net.PacketConn, error) {
 
 	conn, err := dialer.Dial("tcp", address)
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":   "NymTransport.DialPacket",
 			"address":    address,
 			"proxy_addr": proxyAddr,
@@ -230,7 +230,7 @@ func (t *NymTransport) DialPacket(address string) (net.PacketConn, error) {
 		return nil, fmt.Errorf("Nym packet dial failed (is Nym client running on %s?): %w", proxyAddr, err)
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":   "NymTransport.DialPacket",
 		"address":    address,
 		"local_addr": conn.LocalAddr().String(),
@@ -246,6 +246,6 @@ func (t *NymTransport) SupportedNetworks() []string {
 
 // Close closes the Nym transport.
 func (t *NymTransport) Close() error {
-	logrus.WithField("function", "NymTransport.Close").Debug("Closing Nym transport")
+	pkgLog.WithField("function", "NymTransport.Close").Debug("Closing Nym transport")
 	return nil
 }
@@ -0,0 +1,118 @@
+//go:build !otel_off
+
+package transport
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+	"go.opentelemetry.io/otel/trace"
+	tracenoop "go.opentelemetry.io/otel/trace/noop"
+)
+
+// otelInstrumentationName identifies this package as the OpenTelemetry
+// instrumentation scope.
+const otelInstrumentationName = "github.com/opd-ai/toxcore/transport"
+
+// instrumentedTransport wraps a Transport with OpenTelemetry tracing and
+// metrics on Send. All other methods delegate unchanged.
+type instrumentedTransport struct {
+	Transport
+	tracer       trace.Tracer
+	sendDuration metric.Float64Histogram
+	sendErrors   metric.Int64Counter
+}
+
+// InstrumentedTransport wraps underlying so that every Send runs inside an
+// OpenTelemetry span named "transport.Send" carrying the packet_type,
+// addr_network and packet_size_bytes attributes, and records the
+// toxcore_transport_send_duration_ms histogram and the
+// toxcore_transport_errors_total counter.
+//
+// Only the OpenTelemetry API is imported; applications choose and configure
+// the SDK. A nil tp or mp disables the corresponding signal. Building with
+// the otel_off tag replaces this wrapper with one that returns underlying
+// unchanged.
+func InstrumentedTransport(underlying Transport, tp trace.TracerProvider, mp metric.MeterProvider) Transport {
+	if tp == nil {
+		tp = tracenoop.NewTracerProvider()
+	}
+	if mp == nil {
+		mp = noop.NewMeterProvider()
+	}
+
+	meter := mp.Meter(otelInstrumentationName)
+	sendDuration, err := meter.Float64Histogram("toxcore_transport_send_duration_ms",
+		metric.WithDescription("Duration of transport Send calls"),
+		metric.WithUnit("ms"))
+	if err != nil {
+		pkgLog.WithFields(logrus.Fields{
+			"function": "InstrumentedTransport",
+			"error":    err.Error(),
+		}).Warn("Failed to create send duration histogram")
+		sendDuration, _ = noop.NewMeterProvider().Meter(otelInstrumentationName).Float64Histogram("toxcore_transport_send_duration_ms")
+	}
+	sendErrors, err := meter.Int64Counter("toxcore_transport_errors_total",
+		metric.WithDescription("Number of failed transport Send calls"))
+	if err != nil {
+		pkgLog.WithFields(logrus.Fields{
+			"function": "InstrumentedTransport",
+			"error":    err.Error(),
+		}).Warn("Failed to create send error counter")
+		sendErrors, _ = noop.NewMeterProvider().Meter(otelInstrumentationName).Int64Counter("toxcore_transport_errors_total")
+	}
+
+	return &instrumentedTransport{
+		Transport:    underlying,
+		tracer:       tp.Tracer(otelInstrumentationName),
+		sendDuration: sendDuration,
+		sendErrors:   sendErrors,
+	}
+}
+
+// Send forwards to the underlying transport inside an OpenTelemetry span.
+func (it *instrumentedTransport) Send(packet *Packet, addr net.Addr) error {
+	attrs := sendAttributes(packet, addr)
+	ctx, span := it.tracer.Start(context.Background(), "transport.Send",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...))
+	defer span.End()
+
+	start := time.Now()
+	err := it.Transport.Send(packet, addr)
+	elapsed := float64(time.Since(start)) / float64(time.Millisecond)
+
+	metricAttrs := metric.WithAttributes(attrs[:2]...)
+	it.sendDuration.Record(ctx, elapsed, metricAttrs)
+	if err != nil {
+		it.sendErrors.Add(ctx, 1, metricAttrs)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return err
+}
+
+// sendAttributes returns packet_type and addr_network (used on both spans
+// and metrics) followed by packet_size_bytes (spans only).
+func sendAttributes(packet *Packet, addr net.Addr) []attribute.KeyValue {
+	var packetType, size int
+	if packet != nil {
+		packetType = int(packet.PacketType)
+		size = 1 + len(packet.Data)
+	}
+	network := ""
+	if addr != nil {
+		network = addr.Network()
+	}
+	return []attribute.KeyValue{
+		attribute.Int("packet_type", packetType),
+		attribute.String("addr_network", network),
+		attribute.Int("packet_size_bytes", size),
+	}
+}
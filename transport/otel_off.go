@@ -0,0 +1,11 @@
+//go:build otel_off
+
+package transport
+
+// InstrumentedTransport returns underlying unchanged. This variant is built
+// with the otel_off tag so that the package does not depend on
+// OpenTelemetry; the provider parameters accept any value, so callers that
+// pass real providers still compile.
+func InstrumentedTransport(underlying Transport, _, _ any) Transport {
+	return underlying
+}
@@ -0,0 +1,83 @@
+//go:build !otel_off
+
+package transport
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// failingSendTransport fails every Send with err.
+type failingSendTransport struct {
+	mockTransport
+	err error
+}
+
+func (f *failingSendTransport) Send(packet *Packet, addr net.Addr) error {
+	return f.err
+}
+
+func TestInstrumentedTransportSpansAndMetrics(t *testing.T) {
+	spans := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans))
+	reader := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+	sendErr := errors.New("send failed")
+	healthy := InstrumentedTransport(newMockTransport(), tp, mp)
+	failing := InstrumentedTransport(&failingSendTransport{mockTransport: *newMockTransport(), err: sendErr}, tp, mp)
+
+	addr := &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 33445}
+	packet := &Packet{PacketType: PacketFriendMessage, Data: make([]byte, 10)}
+
+	require.NoError(t, healthy.Send(packet, addr))
+	assert.ErrorIs(t, failing.Send(packet, addr), sendErr)
+
+	ended := spans.Ended()
+	require.Len(t, ended, 2)
+	assert.Equal(t, "transport.Send", ended[0].Name())
+	assert.Contains(t, ended[0].Attributes(), attribute.Int("packet_type", int(PacketFriendMessage)))
+	assert.Contains(t, ended[0].Attributes(), attribute.String("addr_network", "udp"))
+	assert.Contains(t, ended[0].Attributes(), attribute.Int("packet_size_bytes", 11))
+	assert.Equal(t, codes.Error, ended[1].Status().Code)
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+	require.Len(t, rm.ScopeMetrics, 1)
+
+	found := map[string]metricdata.Metrics{}
+	for _, m := range rm.ScopeMetrics[0].Metrics {
+		found[m.Name] = m
+	}
+
+	duration, ok := found["toxcore_transport_send_duration_ms"].Data.(metricdata.Histogram[float64])
+	require.True(t, ok, "send duration histogram missing")
+	require.Len(t, duration.DataPoints, 1)
+	assert.Equal(t, uint64(2), duration.DataPoints[0].Count)
+
+	errorsTotal, ok := found["toxcore_transport_errors_total"].Data.(metricdata.Sum[int64])
+	require.True(t, ok, "error counter missing")
+	require.Len(t, errorsTotal.DataPoints, 1)
+	assert.Equal(t, int64(1), errorsTotal.DataPoints[0].Value)
+}
+
+func TestInstrumentedTransportNilProviders(t *testing.T) {
+	underlying := newMockTransport()
+	wrapped := InstrumentedTransport(underlying, nil, nil)
+
+	assert.NoError(t, wrapped.Send(&Packet{PacketType: PacketPingRequest, Data: []byte{1}}, nil))
+	assert.Equal(t, underlying.LocalAddr(), wrapped.LocalAddr())
+	assert.False(t, wrapped.IsConnectionOriented())
+	assert.NoError(t, wrapped.Close())
+}
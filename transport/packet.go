@@ -123,6 +123,91 @@ const (
 	// Legacy c-toxcore clients will ignore these packet types.
 	// See packet_extensions.go for the extension registry and compatibility notes.
 
+	// PacketAVRTCP carries RTCP sender and receiver reports for an RTP
+	// session when they are not multiplexed with the audio RTP packets.
+	// Extension type: opd-ai v0.1
+	PacketAVRTCP PacketType = 233
+
+	// PacketMessageEdit replaces the body of a previously sent friend
+	// message. The payload carries the message ID, edit count, re-encrypted
+	// body and the sender's Ed25519 signature over them.
+	// Extension type: opd-ai v0.1
+	PacketMessageEdit PacketType = 234
+
+	// PacketGroupHistorySync sends a group's recent message history to a
+	// newly joined member, in compact binary chunks.
+	// Extension type: opd-ai v0.1
+	PacketGroupHistorySync PacketType = 235
+
+	// PacketNoiseFallback carries the XX fallback handshake used when a peer
+	// cannot authenticate our Noise-IK initiation. The fallback only
+	// completes if the peer proves the static key the initiation expected.
+	// The first payload byte distinguishes the IK rejection from an XX
+	// handshake message.
+	// Extension type: opd-ai v0.1
+	PacketNoiseFallback PacketType = 236
+
+	// PacketAsyncDeliveryReceipt tells the sender of an async message that
+	// the recipient retrieved and decrypted it. The payload is the
+	// recipient's public key, the message's recipient proof HMAC and an
+	// Ed25519 signature; it carries no message content.
+	// Extension type: opd-ai v0.1
+	PacketAsyncDeliveryReceipt PacketType = 237
+
+	// PacketAVCallTransferInvite offers a transferred audio/video call to a
+	// new party, with the RTP stream state of the original call.
+	// Extension type: opd-ai v0.1
+	PacketAVCallTransferInvite PacketType = 238
+
+	// PacketAVCallTransfer ends a call because it is being transferred to
+	// another party.
+	// Extension type: opd-ai v0.1
+	PacketAVCallTransfer PacketType = 239
+
+	// PacketGroupChannelKey delivers a group private channel session key,
+	// encrypted to one channel member's public key.
+	// Extension type: opd-ai v0.1
+	PacketGroupChannelKey PacketType = 240
+
+	// PacketGroupMetadata broadcasts a change to a group's topic or
+	// description to the connected members.
+	// Extension type: opd-ai v0.1
+	PacketGroupMetadata PacketType = 241
+
+	// PacketGroupSearchQuery asks a DHT node for the group announcements it
+	// stores whose name contains a search string.
+	// Extension type: opd-ai v0.1
+	PacketGroupSearchQuery PacketType = 242
+
+	// PacketGroupSearchResponse returns the announcements matching a
+	// PacketGroupSearchQuery.
+	// Extension type: opd-ai v0.1
+	PacketGroupSearchResponse PacketType = 243
+
+	// PacketMessageReaction attaches an emoji reaction to a previously
+	// received friend message. The payload names the message ID and carries
+	// up to 8 bytes of UTF-8.
+	// Extension type: opd-ai v0.1
+	PacketMessageReaction PacketType = 244
+
+	// PacketAsyncPreKeyRequest asks a friend to send a fresh pre-key bundle
+	// when our stock of their one-time pre-keys runs low. The friend replies
+	// with a PacketAsyncPreKeyExchange.
+	// Extension type: opd-ai v0.1
+	PacketAsyncPreKeyRequest PacketType = 245
+
+	// PacketCompressed carries a packet whose payload was compressed by
+	// CompressedTransport. The envelope names the algorithm, original size
+	// and original packet type.
+	// Extension type: opd-ai v0.1
+	PacketCompressed PacketType = 246
+
+	// PacketMigrationNotify tells peers that the sender's address changed
+	// after a network change, so they can update its routing table entry
+	// without a full re-bootstrap. See UDPTransport.SetMigrationEnabled.
+	// Extension type: opd-ai v0.1
+	PacketMigrationNotify PacketType = 247
+
 	// PacketCoverTraffic carries an encrypted dummy payload used for cover
 	// traffic.  Recipients MUST silently discard its contents.  The payload
 	// is indistinguishable from a real message at the cipher layer; only the
@@ -170,14 +255,14 @@ type Packet struct {
 
 // Serialize converts a packet to a byte slice for transmission.
 func (p *Packet) Serialize() ([]byte, error) {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":    "Serialize",
 		"packet_type": p.PacketType,
 		"data_size":   len(p.Data),
 	}).Debug("Serializing packet for transmission")
 
 	if p.Data == nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "Serialize",
 			"error":    "packet data is nil",
 		}).Error("Packet serialization failed")
@@ -189,7 +274,7 @@ func (p *Packet) Serialize() ([]byte, error) {
 	result[0] = byte(p.PacketType)
 	copy(result[1:], p.Data)
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":        "Serialize",
 		"packet_type":     p.PacketType,
 		"serialized_size": len(result),
@@ -202,13 +287,13 @@ func (p *Packet) Serialize() ([]byte, error) {
 //
 //export ToxParsePacket
 func ParsePacket(data []byte) (*Packet, error) {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":  "ParsePacket",
 		"data_size": len(data),
 	}).Debug("Parsing packet from byte slice")
 
 	if len(data) < 1 {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":  "ParsePacket",
 			"data_size": len(data),
 			"error":     "packet too short",
@@ -224,7 +309,7 @@ func ParsePacket(data []byte) (*Packet, error) {
 
 	copy(packet.Data, data[1:])
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":    "ParsePacket",
 		"packet_type": packetType,
 		"data_size":   len(packet.Data),
@@ -242,7 +327,7 @@ type NodePacket struct {
 
 // Serialize converts a NodePacket to a byte slice.
 func (np *NodePacket) Serialize() ([]byte, error) {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":     "Serialize",
 		"public_key":   string(np.PublicKey[:8]),
 		"payload_size": len(np.Payload),
@@ -255,7 +340,7 @@ func (np *NodePacket) Serialize() ([]byte, error) {
 	copy(result[32:56], np.Nonce[:])
 	copy(result[56:], np.Payload)
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":        "Serialize",
 		"serialized_size": len(result),
 	}).Debug("Node packet serialized successfully")
@@ -265,13 +350,13 @@ func (np *NodePacket) Serialize() ([]byte, error) {
 
 // ParseNodePacket converts a byte slice to a NodePacket structure.
 func ParseNodePacket(data []byte) (*NodePacket, error) {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":  "ParseNodePacket",
 		"data_size": len(data),
 	}).Debug("Parsing node packet from byte slice")
 
 	if len(data) < 56 { // 32 (pubkey) + 24 (nonce)
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":     "ParseNodePacket",
 			"data_size":    len(data),
 			"min_required": 56,
@@ -288,7 +373,7 @@ func ParseNodePacket(data []byte) (*NodePacket, error) {
 	copy(packet.Nonce[:], data[32:56])
 	copy(packet.Payload, data[56:])
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":     "ParseNodePacket",
 		"public_key":   string(packet.PublicKey[:8]),
 		"payload_size": len(packet.Payload),
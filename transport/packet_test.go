@@ -2,6 +2,10 @@ package transport
 
 import (
 	"bytes"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -339,3 +343,280 @@ func TestNodePacketSerializeRoundTrip(t *testing.T) {
 		t.Errorf("Payload mismatch: got %v, want %v", parsed.Payload, original.Payload)
 	}
 }
+
+// updateGolden regenerates testdata/*.golden instead of comparing against them:
+//
+//	go test ./transport -run TestPacketSerializationStability -update-golden
+var updateGolden = flag.Bool("update-golden", false, "regenerate transport/testdata golden files")
+
+// goldenCase encodes a fixed input and decodes wire bytes back into a
+// re-encoded form, so a round trip can be compared with the golden bytes.
+type goldenCase struct {
+	name   string
+	encode func() ([]byte, error)
+	decode func(data []byte) ([]byte, error)
+}
+
+// goldenPacketTypes lists every known packet type and its golden file stem.
+var goldenPacketTypes = []struct {
+	name       string
+	packetType PacketType
+}{
+	{"ping_request", PacketPingRequest},
+	{"ping_response", PacketPingResponse},
+	{"get_nodes", PacketGetNodes},
+	{"send_nodes", PacketSendNodes},
+	{"friend_request", PacketFriendRequest},
+	{"lan_discovery", PacketLANDiscovery},
+	{"friend_message", PacketFriendMessage},
+	{"friend_message_ack", PacketFriendMessageAck},
+	{"friend_name_update", PacketFriendNameUpdate},
+	{"friend_status_message_update", PacketFriendStatusMessageUpdate},
+	{"onion_send", PacketOnionSend},
+	{"onion_receive", PacketOnionReceive},
+	{"onion_reply", PacketOnionReply},
+	{"onion_announce_request", PacketOnionAnnounceRequest},
+	{"onion_announce_response", PacketOnionAnnounceResponse},
+	{"onion_data_request", PacketOnionDataRequest},
+	{"onion_data_response", PacketOnionDataResponse},
+	{"file_request", PacketFileRequest},
+	{"file_control", PacketFileControl},
+	{"file_data", PacketFileData},
+	{"file_data_ack", PacketFileDataAck},
+	{"group_invite", PacketGroupInvite},
+	{"group_invite_response", PacketGroupInviteResponse},
+	{"group_broadcast", PacketGroupBroadcast},
+	{"group_announce", PacketGroupAnnounce},
+	{"group_query", PacketGroupQuery},
+	{"group_query_response", PacketGroupQueryResponse},
+	{"onet", PacketOnet},
+	{"dht_request", PacketDHTRequest},
+	{"async_store", PacketAsyncStore},
+	{"async_store_response", PacketAsyncStoreResponse},
+	{"async_retrieve", PacketAsyncRetrieve},
+	{"async_retrieve_response", PacketAsyncRetrieveResponse},
+	{"async_prekey_exchange", PacketAsyncPreKeyExchange},
+	{"av_call_request", PacketAVCallRequest},
+	{"av_call_response", PacketAVCallResponse},
+	{"av_call_control", PacketAVCallControl},
+	{"av_audio_frame", PacketAVAudioFrame},
+	{"av_video_frame", PacketAVVideoFrame},
+	{"av_bitrate_control", PacketAVBitrateControl},
+	{"cover_traffic", PacketCoverTraffic},
+	{"version_negotiation", PacketVersionNegotiation},
+	{"noise_handshake", PacketNoiseHandshake},
+	{"noise_message", PacketNoiseMessage},
+	{"version_commitment", PacketVersionCommitment},
+	{"relay_announce", PacketRelayAnnounce},
+	{"relay_query", PacketRelayQuery},
+	{"relay_query_response", PacketRelayQueryResponse},
+}
+
+// goldenBytes returns n deterministic bytes derived from seed.
+func goldenBytes(seed byte, n int) []byte {
+	out := make([]byte, n)
+	for i := range out {
+		out[i] = seed + byte(i*7)
+	}
+	return out
+}
+
+// goldenCases builds the fixed inputs for every golden file.
+func goldenCases() []goldenCase {
+	var cases []goldenCase
+
+	reencodePacket := func(data []byte) ([]byte, error) {
+		p, err := ParsePacket(data)
+		if err != nil {
+			return nil, err
+		}
+		return p.Serialize()
+	}
+	for _, pt := range goldenPacketTypes {
+		pt := pt
+		cases = append(cases, goldenCase{
+			name: "packet_" + pt.name,
+			encode: func() ([]byte, error) {
+				return (&Packet{PacketType: pt.packetType, Data: goldenBytes(byte(pt.packetType), 16)}).Serialize()
+			},
+			decode: reencodePacket,
+		})
+	}
+
+	var publicKey [32]byte
+	copy(publicKey[:], goldenBytes(0x10, 32))
+	var nonce [24]byte
+	copy(nonce[:], goldenBytes(0x20, 24))
+
+	cases = append(cases,
+		goldenCase{
+			name: "node_packet",
+			encode: func() ([]byte, error) {
+				return (&NodePacket{PublicKey: publicKey, Nonce: nonce, Payload: goldenBytes(0x30, 20)}).Serialize()
+			},
+			decode: func(data []byte) ([]byte, error) {
+				p, err := ParseNodePacket(data)
+				if err != nil {
+					return nil, err
+				}
+				return p.Serialize()
+			},
+		},
+		goldenCase{
+			name: "version_negotiation_body",
+			encode: func() ([]byte, error) {
+				return SerializeVersionNegotiation(&VersionNegotiationPacket{
+					SupportedVersions: []ProtocolVersion{ProtocolLegacy, ProtocolNoiseIK},
+					PreferredVersion:  ProtocolNoiseIK,
+				})
+			},
+			decode: func(data []byte) ([]byte, error) {
+				p, err := ParseVersionNegotiation(data)
+				if err != nil {
+					return nil, err
+				}
+				return SerializeVersionNegotiation(p)
+			},
+		},
+		goldenCase{
+			name: "version_commitment_body",
+			encode: func() ([]byte, error) {
+				c := &VersionCommitment{Version: ProtocolNoiseIK, Timestamp: 1700000000}
+				copy(c.HMAC[:], goldenBytes(0x40, 32))
+				return SerializeVersionCommitment(c)
+			},
+			decode: func(data []byte) ([]byte, error) {
+				c, err := ParseVersionCommitment(data)
+				if err != nil {
+					return nil, err
+				}
+				return SerializeVersionCommitment(c)
+			},
+		},
+		goldenCase{
+			name: "versioned_handshake_request",
+			encode: func() ([]byte, error) {
+				return SerializeVersionedHandshakeRequest(&VersionedHandshakeRequest{
+					ProtocolVersion:   ProtocolNoiseIK,
+					SupportedVersions: []ProtocolVersion{ProtocolLegacy, ProtocolNoiseIK},
+					NoiseMessage:      goldenBytes(0x50, 48),
+					LegacyData:        goldenBytes(0x60, 8),
+				})
+			},
+			decode: func(data []byte) ([]byte, error) {
+				r, err := ParseVersionedHandshakeRequest(data)
+				if err != nil {
+					return nil, err
+				}
+				return SerializeVersionedHandshakeRequest(r)
+			},
+		},
+		goldenCase{
+			name: "versioned_handshake_response",
+			encode: func() ([]byte, error) {
+				return SerializeVersionedHandshakeResponse(&VersionedHandshakeResponse{
+					AgreedVersion: ProtocolNoiseIK,
+					NoiseMessage:  goldenBytes(0x70, 48),
+					LegacyData:    goldenBytes(0x80, 8),
+				})
+			},
+			decode: func(data []byte) ([]byte, error) {
+				r, err := ParseVersionedHandshakeResponse(data)
+				if err != nil {
+					return nil, err
+				}
+				return SerializeVersionedHandshakeResponse(r)
+			},
+		},
+		goldenCase{
+			name: "node_entry_legacy_ipv4",
+			encode: func() ([]byte, error) {
+				return NewLegacyIPParser().SerializeNodeEntry(&NodeEntry{
+					PublicKey: publicKey,
+					Address:   &NetworkAddress{Type: AddressTypeIPv4, Data: []byte{192, 0, 2, 1}, Port: 33445, Network: "udp"},
+				})
+			},
+			decode: func(data []byte) ([]byte, error) {
+				entry, _, err := NewLegacyIPParser().ParseNodeEntry(data, 0)
+				if err != nil {
+					return nil, err
+				}
+				return NewLegacyIPParser().SerializeNodeEntry(entry)
+			},
+		},
+		goldenCase{
+			name: "node_entry_extended_ipv6",
+			encode: func() ([]byte, error) {
+				return NewExtendedParser().SerializeNodeEntry(&NodeEntry{
+					PublicKey: publicKey,
+					Address:   &NetworkAddress{Type: AddressTypeIPv6, Data: append([]byte{0x20, 0x01, 0x0d, 0xb8}, make([]byte, 12)...), Port: 33445, Network: "udp"},
+				})
+			},
+			decode: func(data []byte) ([]byte, error) {
+				entry, _, err := NewExtendedParser().ParseNodeEntry(data, 0)
+				if err != nil {
+					return nil, err
+				}
+				return NewExtendedParser().SerializeNodeEntry(entry)
+			},
+		},
+		goldenCase{
+			name: "extension_header",
+			encode: func() ([]byte, error) {
+				return SerializeExtensionHeader(), nil
+			},
+			decode: func(data []byte) ([]byte, error) {
+				if ParseExtensionHeader(data) == nil {
+					return nil, errors.New("invalid extension header")
+				}
+				return SerializeExtensionHeader(), nil
+			},
+		},
+	)
+
+	return cases
+}
+
+// TestPacketSerializationStability pins the wire encoding of every packet
+// type to the golden files in testdata. A failure here means the encoding
+// changed: that breaks interoperability with deployed nodes and must come
+// with a protocol version bump (ProtocolVersion or ExtensionProtocolVersion)
+// before the golden files are regenerated with -update-golden.
+func TestPacketSerializationStability(t *testing.T) {
+	for _, tc := range goldenCases() {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join("testdata", tc.name+".golden")
+
+			encoded, err := tc.encode()
+			if err != nil {
+				t.Fatalf("encode failed: %v", err)
+			}
+
+			if *updateGolden {
+				if err := os.MkdirAll("testdata", 0o755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, encoded, 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			golden, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("read golden file (run with -update-golden to create): %v", err)
+			}
+			if !bytes.Equal(encoded, golden) {
+				t.Fatalf("encoding of %s changed; bump the protocol version and run with -update-golden\n got: %x\nwant: %x",
+					tc.name, encoded, golden)
+			}
+
+			roundTrip, err := tc.decode(golden)
+			if err != nil {
+				t.Fatalf("decode golden file failed: %v", err)
+			}
+			if !bytes.Equal(roundTrip, golden) {
+				t.Errorf("round trip of %s differs\n got: %x\nwant: %x", tc.name, roundTrip, golden)
+			}
+		})
+	}
+}
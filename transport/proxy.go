@@ -73,7 +73,7 @@ func NewProxyTransport(underlying Transport, config *ProxyConfig) (*ProxyTranspo
 
 // logProxyCreation emits the initial proxy setup details for diagnostics.
 func logProxyCreation(config *ProxyConfig, proxyAddr string) {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":          "NewProxyTransport",
 		"proxy_type":        config.Type,
 		"proxy_addr":        proxyAddr,
@@ -104,7 +104,7 @@ func (t *ProxyTransport) enableSOCKS5UDPProxy(password string) error {
 	}
 	association, err := NewSOCKS5UDPAssociation(t.proxyAddr, t.username, password)
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":   "NewProxyTransport",
 			"proxy_addr": t.proxyAddr,
 			"error":      err.Error(),
@@ -113,7 +113,7 @@ func (t *ProxyTransport) enableSOCKS5UDPProxy(password string) error {
 	}
 	t.password = password
 	t.udpAssociation = association
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":   "NewProxyTransport",
 		"proxy_addr": t.proxyAddr,
 		"relay_addr": association.RelayAddr().String(),
@@ -123,7 +123,7 @@ func (t *ProxyTransport) enableSOCKS5UDPProxy(password string) error {
 
 // logProxyReady reports the final proxy transport state after setup.
 func logProxyReady(t *ProxyTransport, proxyType string) {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":         "NewProxyTransport",
 		"proxy_type":       proxyType,
 		"proxy_addr":       t.proxyAddr,
@@ -155,7 +155,7 @@ func createSocks5Dialer(config *ProxyConfig, proxyAddr string) (proxy.Dialer, *u
 
 	dialer, err := proxy.SOCKS5("tcp", proxyAddr, auth, proxy.Direct)
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":   "NewProxyTransport",
 			"proxy_type": config.Type,
 			"proxy_addr": proxyAddr,
@@ -186,7 +186,7 @@ func createHTTPDialer(config *ProxyConfig, proxyAddr string) (proxy.Dialer, *url
 
 	dialer := &httpProxyDialer{proxyURL: httpProxyURL}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":   "NewProxyTransport",
 		"proxy_type": config.Type,
 		"proxy_addr": proxyAddr,
@@ -200,7 +200,7 @@ func createHTTPDialer(config *ProxyConfig, proxyAddr string) (proxy.Dialer, *url
 // the proxy using UDP ASSOCIATE. Otherwise, delegates to the underlying transport.
 // For TCP-based transports, establishes connections through the configured proxy.
 func (t *ProxyTransport) Send(packet *Packet, addr net.Addr) error {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":    "ProxyTransport.Send",
 		"packet_type": packet.PacketType,
 		"dest_addr":   addr.String(),
@@ -261,7 +261,7 @@ func (t *ProxyTransport) getUDPAssociation() *SOCKS5UDPAssociation {
 func serializeProxyPacket(packet *Packet, functionName string) ([]byte, error) {
 	data, err := packet.Serialize()
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":    functionName,
 			"packet_type": packet.PacketType,
 			"error":       err.Error(),
@@ -293,7 +293,7 @@ func (t *ProxyTransport) reestablishUDPAssociation(packetType PacketType) (*SOCK
 	defer t.mu.Unlock()
 	newAssociation, err := NewSOCKS5UDPAssociation(t.proxyAddr, t.username, t.password)
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":    "sendViaUDPProxy",
 			"packet_type": packetType,
 			"error":       err.Error(),
@@ -306,7 +306,7 @@ func (t *ProxyTransport) reestablishUDPAssociation(packetType PacketType) (*SOCK
 
 // logUDPSend records successful SOCKS5 UDP relay writes.
 func logUDPSend(packet *Packet, addr net.Addr, bytesSent int) {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":    "sendViaUDPProxy",
 		"packet_type": packet.PacketType,
 		"dest_addr":   addr.String(),
@@ -318,7 +318,7 @@ func logUDPSend(packet *Packet, addr net.Addr, bytesSent int) {
 func (t *ProxyTransport) sendViaTCPProxy(packet *Packet, addr net.Addr) error {
 	conn, err := t.getOrCreateProxyConnection(addr)
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":    "sendViaTCPProxy",
 			"packet_type": packet.PacketType,
 			"dest_addr":   addr.String(),
@@ -329,7 +329,7 @@ func (t *ProxyTransport) sendViaTCPProxy(packet *Packet, addr net.Addr) error {
 
 	data, err := packet.Serialize()
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":    "sendViaTCPProxy",
 			"packet_type": packet.PacketType,
 			"error":       err.Error(),
@@ -345,7 +345,7 @@ func (t *ProxyTransport) sendViaTCPProxy(packet *Packet, addr net.Addr) error {
 	n, err := conn.Write(data)
 	if err != nil {
 		t.cleanupConnection(addr)
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":    "sendViaTCPProxy",
 			"packet_type": packet.PacketType,
 			"dest_addr":   addr.String(),
@@ -354,7 +354,7 @@ func (t *ProxyTransport) sendViaTCPProxy(packet *Packet, addr net.Addr) error {
 		return fmt.Errorf("proxy write failed: %w", err)
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":    "sendViaTCPProxy",
 		"packet_type": packet.PacketType,
 		"dest_addr":   addr.String(),
@@ -388,7 +388,7 @@ func (t *ProxyTransport) getProxyConnection(addrKey string) net.Conn {
 
 // dialProxyConnection opens a new TCP connection through the configured proxy.
 func (t *ProxyTransport) dialProxyConnection(addrKey string) (net.Conn, error) {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":   "getOrCreateProxyConnection",
 		"dest_addr":  addrKey,
 		"proxy_type": t.proxyType,
@@ -396,7 +396,7 @@ func (t *ProxyTransport) dialProxyConnection(addrKey string) (net.Conn, error) {
 	}).Info("Establishing new connection via proxy")
 	newConn, err := t.proxyDialer.Dial("tcp", addrKey)
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":   "getOrCreateProxyConnection",
 			"dest_addr":  addrKey,
 			"proxy_type": t.proxyType,
@@ -416,7 +416,7 @@ func (t *ProxyTransport) storeProxyConnection(addrKey string, newConn net.Conn)
 		return existing
 	}
 	t.connections[addrKey] = newConn
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":    "getOrCreateProxyConnection",
 		"dest_addr":   addrKey,
 		"proxy_type":  t.proxyType,
@@ -444,7 +444,7 @@ func (t *ProxyTransport) cleanupConnection(addr net.Addr) {
 
 // Close closes the proxy transport and underlying transport.
 func (t *ProxyTransport) Close() error {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":   "ProxyTransport.Close",
 		"proxy_type": t.proxyType,
 		"proxy_addr": t.proxyAddr,
@@ -454,7 +454,7 @@ func (t *ProxyTransport) Close() error {
 	t.mu.Lock()
 	if t.udpAssociation != nil {
 		if err := t.udpAssociation.Close(); err != nil {
-			logrus.WithFields(logrus.Fields{
+			pkgLog.WithFields(logrus.Fields{
 				"function": "ProxyTransport.Close",
 				"error":    err.Error(),
 			}).Warn("Error closing UDP association")
@@ -479,7 +479,7 @@ func (t *ProxyTransport) LocalAddr() net.Addr {
 
 // RegisterHandler registers a packet handler with the underlying transport.
 func (t *ProxyTransport) RegisterHandler(packetType PacketType, handler PacketHandler) {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":    "ProxyTransport.RegisterHandler",
 		"packet_type": packetType,
 		"proxy_type":  t.proxyType,
@@ -502,7 +502,7 @@ func (t *ProxyTransport) DialProxy(address string) (net.Conn, error) {
 	proxyAddr := t.proxyAddr
 	t.mu.RUnlock()
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":   "ProxyTransport.DialProxy",
 		"address":    address,
 		"proxy_type": proxyType,
@@ -511,7 +511,7 @@ func (t *ProxyTransport) DialProxy(address string) (net.Conn, error) {
 
 	conn, err := dialer.Dial("tcp", address)
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":   "ProxyTransport.DialProxy",
 			"address":    address,
 			"proxy_type": proxyType,
@@ -520,7 +520,7 @@ func (t *ProxyTransport) DialProxy(address string) (net.Conn, error) {
 		return nil, fmt.Errorf("proxy dial failed: %w", err)
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":    "ProxyTransport.DialProxy",
 		"address":     address,
 		"proxy_type":  proxyType,
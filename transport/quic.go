@@ -0,0 +1,535 @@
+package transport
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/quic-go/quic-go"
+	"github.com/sirupsen/logrus"
+)
+
+func init() {
+	RegisterCapability(CapabilityQUIC, "quic")
+}
+
+// QUICALPN is the TLS application protocol negotiated by QUICTransport.
+const QUICALPN = "toxcore"
+
+// quicMaxPacketSize bounds a packet read from a QUIC stream, matching the
+// TCP transport's maximum packet size.
+const quicMaxPacketSize = 1024 * 1024
+
+// quicDialTimeout bounds connection establishment when dialing.
+const quicDialTimeout = 10 * time.Second
+
+// quicIdleTimeout closes connections without traffic, matching the TCP
+// transport's read deadline.
+const quicIdleTimeout = tcpReadDeadline
+
+// Application error codes sent when closing QUIC connections.
+const (
+	quicCodeNoError   quic.ApplicationErrorCode = 0
+	quicCodeDuplicate quic.ApplicationErrorCode = 1
+)
+
+// ErrQUICMutualTLSRequired is returned when QUICOptions.SkipNoise is set but
+// the TLS configuration does not authenticate both peers.
+var ErrQUICMutualTLSRequired = errors.New("skipping Noise requires mutual TLS authentication")
+
+// QUICDeliveryMode selects how QUICTransport maps packets onto QUIC.
+type QUICDeliveryMode uint8
+
+const (
+	// QUICDeliveryStreams sends each packet on its own unidirectional stream:
+	// reliable, retransmitted on loss, and without head-of-line blocking
+	// between packets.
+	QUICDeliveryStreams QUICDeliveryMode = iota
+	// QUICDeliveryDatagrams sends each packet as an unreliable QUIC datagram
+	// (RFC 9221), like UDP but congestion-controlled and encrypted. Packets
+	// too large for a datagram fall back to a stream.
+	QUICDeliveryDatagrams
+)
+
+// String returns the mode name.
+func (m QUICDeliveryMode) String() string {
+	switch m {
+	case QUICDeliveryStreams:
+		return "streams"
+	case QUICDeliveryDatagrams:
+		return "datagrams"
+	default:
+		return fmt.Sprintf("QUICDeliveryMode(%d)", uint8(m))
+	}
+}
+
+// QUICOptions configures a QUICTransport created with
+// NewQUICTransportWithOptions. The zero value matches NewQUICTransport.
+type QUICOptions struct {
+	// Delivery selects streams or datagrams for outgoing packets. Incoming
+	// packets are accepted in either form.
+	Delivery QUICDeliveryMode
+
+	// SkipNoise declares that QUIC's TLS 1.3 handshake already authenticates
+	// both peers, so a NoiseTransport wrapping this transport passes packets
+	// straight through instead of running Noise-IK on top. It requires a TLS
+	// configuration with ClientAuth set to tls.RequireAndVerifyClientCert,
+	// and disables 0-RTT, whose early data could be replayed.
+	SkipNoise bool
+
+	// KeepAlivePeriod, if non-zero, sends keep-alives so idle connections
+	// and their NAT bindings stay open.
+	KeepAlivePeriod time.Duration
+}
+
+// QUICTransport carries Tox packets over QUIC (RFC 9000). It satisfies the
+// Transport interface.
+//
+// One UDP socket serves both inbound and outbound connections. A connection
+// is dialed on the first Send to an address, using 0-RTT when a session
+// ticket from an earlier connection to the peer is cached, and replies to an
+// inbound connection reuse it. Each packet travels in the same serialized
+// form the TCP transport uses, the 1-byte packet type followed by the
+// payload, on a stream or in a datagram as QUICOptions.Delivery selects.
+//
+// QUIC identifies connections by connection ID rather than by address, so a
+// connection survives a peer's address change. Packets keep being delivered
+// to handlers with the address the connection was first seen on, so sessions
+// keyed by address in the layers above, such as NoiseTransport, stay valid.
+// Migrate moves the connections this transport dialed onto a new socket
+// after a local network change.
+type QUICTransport struct {
+	transport  *quic.Transport
+	listener   *quic.EarlyListener
+	listenAddr net.Addr
+	serverTLS  *tls.Config
+	clientTLS  *tls.Config
+	config     *quic.Config
+	options    QUICOptions
+	handlers   map[PacketType]PacketHandler
+	conns      map[string]*quicConn
+	migrated   []*quic.Transport
+	mu         sync.RWMutex
+	ctx        context.Context
+	cancel     context.CancelFunc
+	closeOnce  sync.Once
+}
+
+// quicConn is a QUIC connection registered under a stable address.
+type quicConn struct {
+	conn   *quic.Conn
+	addr   net.Addr
+	dialed bool
+}
+
+// NewQUICTransport listens for QUIC connections on listenAddr. tlsConfig must
+// carry a certificate, which is presented when accepting connections, and is
+// also used to verify peers when dialing (RootCAs, ServerName,
+// InsecureSkipVerify). It uses stream delivery and keeps Noise enabled.
+func NewQUICTransport(listenAddr string, tlsConfig *tls.Config) (*QUICTransport, error) {
+	return NewQUICTransportWithOptions(listenAddr, tlsConfig, QUICOptions{})
+}
+
+// NewQUICTransportWithOptions is NewQUICTransport with explicit options.
+func NewQUICTransportWithOptions(listenAddr string, tlsConfig *tls.Config, opts QUICOptions) (*QUICTransport, error) {
+	pkgLog.WithFields(logrus.Fields{
+		"function":    "NewQUICTransport",
+		"listen_addr": listenAddr,
+		"delivery":    opts.Delivery.String(),
+		"skip_noise":  opts.SkipNoise,
+	}).Info("Creating new QUIC transport")
+
+	conn, err := net.ListenPacket("udp", listenAddr)
+	if err != nil {
+		pkgLog.WithFields(logrus.Fields{
+			"function":    "NewQUICTransport",
+			"listen_addr": listenAddr,
+			"error":       err.Error(),
+		}).Error("Failed to create QUIC listener")
+		return nil, err
+	}
+
+	t, err := newQUICTransport(conn, tlsConfig, opts)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return t, nil
+}
+
+// newQUICTransport runs a QUICTransport over conn, which it takes ownership
+// of once it succeeds.
+func newQUICTransport(conn net.PacketConn, tlsConfig *tls.Config, opts QUICOptions) (*QUICTransport, error) {
+	if tlsConfig == nil || (len(tlsConfig.Certificates) == 0 && tlsConfig.GetCertificate == nil) {
+		return nil, errors.New("QUIC transport requires a TLS certificate")
+	}
+	if opts.Delivery > QUICDeliveryDatagrams {
+		return nil, fmt.Errorf("invalid QUIC delivery mode %d", opts.Delivery)
+	}
+	if opts.SkipNoise && tlsConfig.ClientAuth != tls.RequireAndVerifyClientCert {
+		return nil, ErrQUICMutualTLSRequired
+	}
+
+	serverTLS := tlsConfig.Clone()
+	if len(serverTLS.NextProtos) == 0 {
+		serverTLS.NextProtos = []string{QUICALPN}
+	}
+	serverTLS.MinVersion = tls.VersionTLS13
+	clientTLS := serverTLS.Clone()
+	if !opts.SkipNoise && clientTLS.ClientSessionCache == nil {
+		clientTLS.ClientSessionCache = tls.NewLRUClientSessionCache(0)
+	}
+
+	config := &quic.Config{
+		MaxIdleTimeout:  quicIdleTimeout,
+		KeepAlivePeriod: opts.KeepAlivePeriod,
+		EnableDatagrams: true,
+		Allow0RTT:       !opts.SkipNoise,
+	}
+
+	qt := &quic.Transport{Conn: conn}
+	listener, err := qt.ListenEarly(serverTLS, config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start QUIC listener: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t := &QUICTransport{
+		transport:  qt,
+		listener:   listener,
+		listenAddr: conn.LocalAddr(),
+		serverTLS:  serverTLS,
+		clientTLS:  clientTLS,
+		config:     config,
+		options:    opts,
+		handlers:   make(map[PacketType]PacketHandler),
+		conns:      make(map[string]*quicConn),
+		ctx:        ctx,
+		cancel:     cancel,
+	}
+	go t.acceptLoop()
+
+	pkgLog.WithFields(logrus.Fields{
+		"function":   "NewQUICTransport",
+		"local_addr": t.listenAddr.String(),
+	}).Info("QUIC transport created successfully")
+
+	return t, nil
+}
+
+// RegisterHandler registers a handler for a specific packet type.
+func (t *QUICTransport) RegisterHandler(packetType PacketType, handler PacketHandler) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.handlers[packetType] = handler
+
+	pkgLog.WithFields(logrus.Fields{
+		"function":      "RegisterHandler",
+		"packet_type":   packetType,
+		"handler_count": len(t.handlers),
+		"local_addr":    t.listenAddr.String(),
+	}).Debug("QUIC packet handler registered")
+}
+
+// Send sends a packet to addr, dialing a QUIC connection if none is open.
+func (t *QUICTransport) Send(packet *Packet, addr net.Addr) error {
+	data, err := packet.Serialize()
+	if err != nil {
+		return err
+	}
+
+	qc, err := t.getOrDial(addr)
+	if err != nil {
+		return err
+	}
+
+	if t.options.Delivery == QUICDeliveryDatagrams {
+		err = qc.conn.SendDatagram(data)
+		var tooLarge *quic.DatagramTooLargeError
+		if !errors.As(err, &tooLarge) {
+			return t.checkSendError(qc, packet, err)
+		}
+	}
+	return t.checkSendError(qc, packet, t.sendOnStream(qc, data))
+}
+
+// sendOnStream writes data on a new unidirectional stream.
+func (t *QUICTransport) sendOnStream(qc *quicConn, data []byte) error {
+	stream, err := qc.conn.OpenUniStream()
+	if err != nil {
+		return err
+	}
+	if _, err := stream.Write(data); err != nil {
+		stream.CancelWrite(0)
+		return err
+	}
+	return stream.Close()
+}
+
+// checkSendError drops a connection that failed to send and logs err.
+func (t *QUICTransport) checkSendError(qc *quicConn, packet *Packet, err error) error {
+	if err == nil {
+		return nil
+	}
+	if qc.conn.Context().Err() != nil {
+		t.removeConnection(qc)
+	}
+	pkgLog.WithFields(logrus.Fields{
+		"function":    "Send",
+		"packet_type": packet.PacketType,
+		"dest_addr":   qc.addr.String(),
+		"error":       err.Error(),
+	}).Error("Failed to send packet over QUIC")
+	return err
+}
+
+// Dial opens a QUIC connection to addr unless one is already open.
+func (t *QUICTransport) Dial(addr net.Addr) error {
+	_, err := t.getOrDial(addr)
+	return err
+}
+
+// getOrDial returns the open connection to addr or dials a new one.
+func (t *QUICTransport) getOrDial(addr net.Addr) (*quicConn, error) {
+	if err := checkContextCancellation(t.ctx); err != nil {
+		return nil, errors.New("QUIC transport closed")
+	}
+
+	addrKey := addr.String()
+	t.mu.RLock()
+	qc, exists := t.conns[addrKey]
+	t.mu.RUnlock()
+	if exists {
+		return qc, nil
+	}
+
+	udpAddr, err := net.ResolveUDPAddr("udp", addrKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid QUIC address %s: %w", addrKey, err)
+	}
+	ctx, cancel := context.WithTimeout(t.ctx, quicDialTimeout)
+	defer cancel()
+	conn, err := t.transport.DialEarly(ctx, udpAddr, t.clientTLS, t.config)
+	if err != nil {
+		pkgLog.WithFields(logrus.Fields{
+			"function":  "Dial",
+			"dest_addr": addrKey,
+			"error":     err.Error(),
+		}).Error("Failed to establish QUIC connection")
+		return nil, fmt.Errorf("QUIC dial %s: %w", addrKey, err)
+	}
+
+	qc = &quicConn{conn: conn, addr: addr, dialed: true}
+	t.mu.Lock()
+	// Re-check: another goroutine may have connected first while we were dialing.
+	if existing, raced := t.conns[addrKey]; raced {
+		t.mu.Unlock()
+		conn.CloseWithError(quicCodeDuplicate, "duplicate connection")
+		return existing, nil
+	}
+	t.conns[addrKey] = qc
+	t.mu.Unlock()
+
+	pkgLog.WithFields(logrus.Fields{
+		"function":  "Dial",
+		"dest_addr": addrKey,
+		"used_0rtt": conn.ConnectionState().Used0RTT,
+	}).Info("QUIC connection established")
+
+	t.serveConnection(qc)
+	return qc, nil
+}
+
+// acceptLoop registers inbound connections until the listener closes.
+func (t *QUICTransport) acceptLoop() {
+	for {
+		conn, err := t.listener.Accept(t.ctx)
+		if err != nil {
+			return
+		}
+		qc := &quicConn{conn: conn, addr: conn.RemoteAddr()}
+
+		t.mu.Lock()
+		if _, exists := t.conns[qc.addr.String()]; exists {
+			// Both peers dialed at once; keep the outbound connection
+			t.mu.Unlock()
+			conn.CloseWithError(quicCodeDuplicate, "duplicate connection")
+			continue
+		}
+		t.conns[qc.addr.String()] = qc
+		t.mu.Unlock()
+
+		t.serveConnection(qc)
+	}
+}
+
+// serveConnection starts the stream and datagram readers for qc, and removes
+// qc once the connection ends.
+func (t *QUICTransport) serveConnection(qc *quicConn) {
+	go t.streamLoop(qc)
+	go t.datagramLoop(qc)
+	go func() {
+		<-qc.conn.Context().Done()
+		t.removeConnection(qc)
+	}()
+}
+
+// streamLoop reads one packet from each unidirectional stream the peer opens.
+func (t *QUICTransport) streamLoop(qc *quicConn) {
+	for {
+		stream, err := qc.conn.AcceptUniStream(t.ctx)
+		if err != nil {
+			return
+		}
+		go func() {
+			data, err := io.ReadAll(io.LimitReader(stream, quicMaxPacketSize+1))
+			if err != nil || len(data) > quicMaxPacketSize {
+				stream.CancelRead(0)
+				return
+			}
+			t.processPacket(data, qc.addr)
+		}()
+	}
+}
+
+// datagramLoop reads packets sent as QUIC datagrams.
+func (t *QUICTransport) datagramLoop(qc *quicConn) {
+	for {
+		data, err := qc.conn.ReceiveDatagram(t.ctx)
+		if err != nil {
+			return
+		}
+		t.processPacket(data, qc.addr)
+	}
+}
+
+// processPacket parses packet data and dispatches it to the appropriate handler.
+func (t *QUICTransport) processPacket(data []byte, addr net.Addr) {
+	packet, err := ParsePacket(data)
+	if err != nil {
+		return
+	}
+
+	handler, exists, _ := lookupPacketHandler(&t.mu, t.handlers, packet.PacketType)
+	if exists {
+		dispatchPacketHandler(handler, packet, addr)
+	}
+}
+
+// removeConnection forgets qc, if it is still registered, and closes it.
+func (t *QUICTransport) removeConnection(qc *quicConn) {
+	t.mu.Lock()
+	if t.conns[qc.addr.String()] == qc {
+		delete(t.conns, qc.addr.String())
+	}
+	t.mu.Unlock()
+	qc.conn.CloseWithError(quicCodeNoError, "")
+}
+
+// Migrate moves every connection this transport dialed onto conn, for
+// example after the local address changed. Each connection validates the new
+// path before switching to it, and peers keep their sessions since the
+// connection IDs are unchanged. The listener stays on the original socket;
+// inbound connections cannot be migrated from this side, as QUIC leaves
+// migration to the client. The transport takes ownership of conn.
+func (t *QUICTransport) Migrate(ctx context.Context, conn net.PacketConn) error {
+	if err := checkContextCancellation(t.ctx); err != nil {
+		conn.Close()
+		return errors.New("QUIC transport closed")
+	}
+	qt := &quic.Transport{Conn: conn}
+
+	t.mu.Lock()
+	t.migrated = append(t.migrated, qt)
+	dialed := make([]*quicConn, 0, len(t.conns))
+	for _, qc := range t.conns {
+		if qc.dialed {
+			dialed = append(dialed, qc)
+		}
+	}
+	t.mu.Unlock()
+
+	var errs []error
+	for _, qc := range dialed {
+		if err := migrateConnection(ctx, qc.conn, qt); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", qc.addr, err))
+		}
+	}
+
+	pkgLog.WithFields(logrus.Fields{
+		"function":    "Migrate",
+		"new_addr":    conn.LocalAddr().String(),
+		"connections": len(dialed),
+		"failed":      len(errs),
+	}).Info("QUIC connections migrated")
+
+	return errors.Join(errs...)
+}
+
+// migrateConnection probes a path for conn over qt and switches to it.
+func migrateConnection(ctx context.Context, conn *quic.Conn, qt *quic.Transport) error {
+	path, err := conn.AddPath(qt)
+	if err != nil {
+		return err
+	}
+	if err := path.Probe(ctx); err != nil {
+		path.Close()
+		return err
+	}
+	return path.Switch()
+}
+
+// AuthenticatesPeers reports whether QUICOptions.SkipNoise was set, in which
+// case a NoiseTransport wrapping this transport does not add Noise-IK.
+func (t *QUICTransport) AuthenticatesPeers() bool {
+	return t.options.SkipNoise
+}
+
+// SupportedNetworks returns the address networks QUIC can dial, so that
+// NoiseTransport accepts UDP peer addresses despite the transport being
+// connection-oriented.
+func (t *QUICTransport) SupportedNetworks() []string {
+	return []string{"udp", "udp4", "udp6"}
+}
+
+// Close shuts down the transport and all its connections. It is safe to call
+// Close multiple times.
+func (t *QUICTransport) Close() error {
+	var err error
+	t.closeOnce.Do(func() {
+		t.cancel()
+
+		t.mu.Lock()
+		for _, qc := range t.conns {
+			qc.conn.CloseWithError(quicCodeNoError, "transport closed")
+		}
+		migrated := t.migrated
+		t.mu.Unlock()
+
+		t.listener.Close()
+		for _, qt := range migrated {
+			qt.Close()
+			qt.Conn.Close()
+		}
+		err = t.transport.Close()
+		if closeErr := t.transport.Conn.Close(); err == nil {
+			err = closeErr
+		}
+	})
+	return err
+}
+
+// LocalAddr returns the local address the transport is listening on.
+func (t *QUICTransport) LocalAddr() net.Addr {
+	return t.listenAddr
+}
+
+// IsConnectionOriented returns true: packets travel over QUIC connections.
+func (t *QUICTransport) IsConnectionOriented() bool {
+	return true
+}
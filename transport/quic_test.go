@@ -0,0 +1,346 @@
+package transport
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"math/rand"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/opd-ai/toxcore/crypto"
+)
+
+// newQUICTestTLSConfig returns a TLS configuration whose self-signed
+// certificate is trusted both as a server and as a client certificate.
+func newQUICTestTLSConfig(t testing.TB) *tls.Config {
+	t.Helper()
+	certFile, keyFile, roots := writeTestCertificate(t, t.TempDir())
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		RootCAs:      roots,
+		ClientCAs:    roots,
+	}
+}
+
+// newQUICPair creates a server and a client QUIC transport on loopback.
+func newQUICPair(t *testing.T, tlsConfig *tls.Config, opts QUICOptions) (server, client *QUICTransport) {
+	t.Helper()
+	var err error
+	if server, err = NewQUICTransportWithOptions("127.0.0.1:0", tlsConfig, opts); err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	t.Cleanup(func() { server.Close() })
+	if client, err = NewQUICTransportWithOptions("127.0.0.1:0", tlsConfig, opts); err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return server, client
+}
+
+func TestQUICTransportRoundTrip(t *testing.T) {
+	tlsConfig := newQUICTestTLSConfig(t)
+	for _, delivery := range []QUICDeliveryMode{QUICDeliveryStreams, QUICDeliveryDatagrams} {
+		t.Run(delivery.String(), func(t *testing.T) {
+			server, client := newQUICPair(t, tlsConfig, QUICOptions{Delivery: delivery})
+			if !server.IsConnectionOriented() {
+				t.Error("Expected QUIC transport to be connection-oriented")
+			}
+			atServer := receivePackets(server, PacketFriendMessage)
+			atClient := receivePackets(client, PacketFriendMessage)
+
+			if err := client.Send(&Packet{PacketType: PacketFriendMessage, Data: []byte("ping")}, server.LocalAddr()); err != nil {
+				t.Fatalf("Send failed: %v", err)
+			}
+			got := expectPacket(t, atServer, "ping")
+
+			// The reply reuses the inbound connection
+			if err := server.Send(&Packet{PacketType: PacketFriendMessage, Data: []byte("pong")}, got.addr); err != nil {
+				t.Fatalf("Reply failed: %v", err)
+			}
+			expectPacket(t, atClient, "pong")
+
+			// Packets too large for a datagram still arrive
+			large := bytes.Repeat([]byte("x"), 8000)
+			if err := client.Send(&Packet{PacketType: PacketFriendMessage, Data: large}, server.LocalAddr()); err != nil {
+				t.Fatalf("Large send failed: %v", err)
+			}
+			expectPacket(t, atServer, string(large))
+		})
+	}
+}
+
+func TestQUICTransportZeroRTT(t *testing.T) {
+	server, client := newQUICPair(t, newQUICTestTLSConfig(t), QUICOptions{})
+	atServer := receivePackets(server, PacketFriendMessage)
+
+	send := func(msg string) *quicConn {
+		t.Helper()
+		if err := client.Send(&Packet{PacketType: PacketFriendMessage, Data: []byte(msg)}, server.LocalAddr()); err != nil {
+			t.Fatalf("Send failed: %v", err)
+		}
+		expectPacket(t, atServer, msg)
+		client.mu.RLock()
+		defer client.mu.RUnlock()
+		return client.conns[server.LocalAddr().String()]
+	}
+
+	first := send("first")
+	if first.conn.ConnectionState().Used0RTT {
+		t.Error("Expected the first connection not to use 0-RTT")
+	}
+	// Give the server's session ticket time to arrive before reconnecting
+	time.Sleep(100 * time.Millisecond)
+	client.removeConnection(first)
+
+	second := send("second")
+	<-second.conn.HandshakeComplete()
+	if !second.conn.ConnectionState().Used0RTT {
+		t.Error("Expected the resumed connection to use 0-RTT")
+	}
+}
+
+func TestQUICTransportOptionsValidation(t *testing.T) {
+	tlsConfig := newQUICTestTLSConfig(t)
+	if _, err := NewQUICTransport("127.0.0.1:0", nil); err == nil {
+		t.Error("Expected a missing TLS configuration to fail")
+	}
+	if _, err := NewQUICTransportWithOptions("127.0.0.1:0", tlsConfig, QUICOptions{Delivery: 7}); err == nil {
+		t.Error("Expected an invalid delivery mode to fail")
+	}
+	if _, err := NewQUICTransportWithOptions("127.0.0.1:0", tlsConfig, QUICOptions{SkipNoise: true}); !errors.Is(err, ErrQUICMutualTLSRequired) {
+		t.Errorf("Expected ErrQUICMutualTLSRequired, got %v", err)
+	}
+}
+
+// newNoiseOverQUIC wraps a QUIC transport pair in NoiseTransports.
+func newNoiseOverQUIC(t *testing.T, opts QUICOptions, tlsConfig *tls.Config) (server, client *NoiseTransport, serverAddr net.Addr, serverPub []byte) {
+	t.Helper()
+	quicServer, quicClient := newQUICPair(t, tlsConfig, opts)
+	serverKeys, err := crypto.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientKeys, err := crypto.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if server, err = NewNoiseTransport(quicServer, serverKeys.Private[:]); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { server.Close() })
+	if client, err = NewNoiseTransport(quicClient, clientKeys.Private[:]); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return server, client, quicServer.LocalAddr(), serverKeys.Public[:]
+}
+
+func TestQUICTransportWithNoise(t *testing.T) {
+	server, client, serverAddr, serverPub := newNoiseOverQUIC(t, QUICOptions{}, newQUICTestTLSConfig(t))
+	if err := client.AddPeer(serverAddr, serverPub); err != nil {
+		t.Fatalf("AddPeer with a UDP address failed: %v", err)
+	}
+	atServer := receivePackets(server, PacketFriendMessage)
+
+	packet := &Packet{PacketType: PacketFriendMessage, Data: []byte("over noise")}
+	if err := client.Send(packet, serverAddr); !errors.Is(err, ErrNoiseSessionIncomplete) {
+		t.Fatalf("Expected the first send to start a handshake, got %v", err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for client.Send(packet, serverAddr) != nil {
+		if time.Now().After(deadline) {
+			t.Fatal("Timed out waiting for the Noise handshake over QUIC")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	expectPacket(t, atServer, "over noise")
+}
+
+func TestQUICTransportSkipNoise(t *testing.T) {
+	tlsConfig := newQUICTestTLSConfig(t)
+	tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	server, client, serverAddr, _ := newNoiseOverQUIC(t, QUICOptions{SkipNoise: true}, tlsConfig)
+	atServer := receivePackets(server, PacketFriendMessage)
+	atClient := receivePackets(client, PacketFriendMessage)
+
+	// No peer key or handshake is needed; TLS authenticated both sides
+	if err := client.Send(&Packet{PacketType: PacketFriendMessage, Data: []byte("direct")}, serverAddr); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	got := expectPacket(t, atServer, "direct")
+	if err := server.Send(&Packet{PacketType: PacketFriendMessage, Data: []byte("reply")}, got.addr); err != nil {
+		t.Fatalf("Reply failed: %v", err)
+	}
+	expectPacket(t, atClient, "reply")
+
+	// A client without a certificate is rejected by the server
+	anonymous := tlsConfig.Clone()
+	anonymous.Certificates = nil
+	anonymous.GetCertificate = func(*tls.ClientHelloInfo) (*tls.Certificate, error) { return &tlsConfig.Certificates[0], nil }
+	intruder, err := NewQUICTransport("127.0.0.1:0", anonymous)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer intruder.Close()
+	if err := intruder.Send(&Packet{PacketType: PacketFriendMessage, Data: []byte("spoof")}, serverAddr); err == nil {
+		select {
+		case <-atServer:
+			t.Error("Expected a client without a certificate to be rejected")
+		case <-time.After(200 * time.Millisecond):
+		}
+	}
+}
+
+func TestQUICTransportMigration(t *testing.T) {
+	server, client := newQUICPair(t, newQUICTestTLSConfig(t), QUICOptions{})
+	atServer := receivePackets(server, PacketFriendMessage)
+	atClient := receivePackets(client, PacketFriendMessage)
+
+	if err := client.Send(&Packet{PacketType: PacketFriendMessage, Data: []byte("before")}, server.LocalAddr()); err != nil {
+		t.Fatal(err)
+	}
+	before := expectPacket(t, atServer, "before")
+
+	newConn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Migrate(ctx, newConn); err != nil {
+		t.Fatalf("Migrate failed: %v", err)
+	}
+
+	if err := client.Send(&Packet{PacketType: PacketFriendMessage, Data: []byte("after")}, server.LocalAddr()); err != nil {
+		t.Fatal(err)
+	}
+	after := expectPacket(t, atServer, "after")
+	if after.addr.String() != before.addr.String() {
+		t.Errorf("Expected packets to keep arriving from %s, got %s", before.addr, after.addr)
+	}
+
+	server.mu.RLock()
+	qc := server.conns[before.addr.String()]
+	server.mu.RUnlock()
+	if qc == nil || qc.conn.RemoteAddr().String() != newConn.LocalAddr().String() {
+		t.Errorf("Expected the server connection to follow the client to %s", newConn.LocalAddr())
+	}
+	if err := server.Send(&Packet{PacketType: PacketFriendMessage, Data: []byte("reply")}, before.addr); err != nil {
+		t.Fatal(err)
+	}
+	expectPacket(t, atClient, "reply")
+}
+
+// lossyPacketConn drops a fraction of outgoing datagrams.
+type lossyPacketConn struct {
+	net.PacketConn
+	mu   sync.Mutex
+	rng  *rand.Rand
+	loss float64
+}
+
+func newLossyPacketConn(conn net.PacketConn, loss float64) *lossyPacketConn {
+	return &lossyPacketConn{PacketConn: conn, rng: rand.New(rand.NewSource(1)), loss: loss}
+}
+
+func (c *lossyPacketConn) WriteTo(p []byte, addr net.Addr) (int, error) {
+	c.mu.Lock()
+	drop := c.rng.Float64() < c.loss
+	c.mu.Unlock()
+	if drop {
+		return len(p), nil
+	}
+	return c.PacketConn.WriteTo(p, addr)
+}
+
+const benchmarkPacketLoss = 0.02
+
+// benchmarkRoundTrip measures ping/pong latency between client and server,
+// resending a ping whose pong does not arrive within retry. Late pongs for
+// earlier pings are ignored.
+func benchmarkRoundTrip(b *testing.B, client, server Transport, retry time.Duration) {
+	server.RegisterHandler(PacketPingRequest, func(packet *Packet, addr net.Addr) error {
+		return server.Send(&Packet{PacketType: PacketPingResponse, Data: packet.Data}, addr)
+	})
+	pongs := make(chan uint64, 64)
+	client.RegisterHandler(PacketPingResponse, func(packet *Packet, _ net.Addr) error {
+		pongs <- binary.BigEndian.Uint64(packet.Data)
+		return nil
+	})
+
+	ping := &Packet{PacketType: PacketPingRequest, Data: make([]byte, 64)}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		seq := uint64(i)
+		binary.BigEndian.PutUint64(ping.Data, seq)
+	resend:
+		if err := client.Send(ping, server.LocalAddr()); err != nil {
+			b.Fatal(err)
+		}
+		timeout := time.After(retry)
+		for {
+			select {
+			case got := <-pongs:
+				if got != seq {
+					continue
+				}
+			case <-timeout:
+				goto resend
+			}
+			break
+		}
+	}
+}
+
+// BenchmarkRoundTripUnderLoss compares round trips over UDP, which needs
+// application-level resends, with QUIC, which retransmits lost frames.
+func BenchmarkRoundTripUnderLoss(b *testing.B) {
+	b.Run("UDP", func(b *testing.B) {
+		var transports [2]Transport
+		for i := range transports {
+			tr, err := NewUDPTransport("127.0.0.1:0")
+			if err != nil {
+				b.Fatal(err)
+			}
+			defer tr.Close()
+			udp := tr.(*UDPTransport)
+			udp.connMu.Lock()
+			udp.conn = newLossyPacketConn(udp.conn, benchmarkPacketLoss)
+			udp.connMu.Unlock()
+			transports[i] = tr
+		}
+		benchmarkRoundTrip(b, transports[0], transports[1], 200*time.Millisecond)
+	})
+
+	for _, delivery := range []QUICDeliveryMode{QUICDeliveryStreams, QUICDeliveryDatagrams} {
+		b.Run("QUIC-"+delivery.String(), func(b *testing.B) {
+			tlsConfig := newQUICTestTLSConfig(b)
+			var transports [2]Transport
+			for i := range transports {
+				conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+				if err != nil {
+					b.Fatal(err)
+				}
+				tr, err := newQUICTransport(newLossyPacketConn(conn, benchmarkPacketLoss), tlsConfig, QUICOptions{Delivery: delivery})
+				if err != nil {
+					b.Fatal(err)
+				}
+				defer tr.Close()
+				transports[i] = tr
+			}
+			if err := transports[0].(*QUICTransport).Dial(transports[1].LocalAddr()); err != nil {
+				b.Fatal(err)
+			}
+			benchmarkRoundTrip(b, transports[0], transports[1], 200*time.Millisecond)
+		})
+	}
+}
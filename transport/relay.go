@@ -102,7 +102,7 @@ func (rc *RelayClient) AddRelayServer(server RelayServerInfo) {
 	rc.mu.Lock()
 	defer rc.mu.Unlock()
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "AddRelayServer",
 		"address":  server.Address,
 		"port":     server.Port,
@@ -122,7 +122,7 @@ func (rc *RelayClient) RemoveRelayServer(address string) {
 	for i, server := range rc.servers {
 		if server.Address == address {
 			rc.servers = append(rc.servers[:i], rc.servers[i+1:]...)
-			logrus.WithFields(logrus.Fields{
+			pkgLog.WithFields(logrus.Fields{
 				"function": "RemoveRelayServer",
 				"address":  address,
 			}).Info("Removed relay server")
@@ -143,7 +143,7 @@ func (rc *RelayClient) Connect(ctx context.Context) error {
 	rc.state = RelayStateConnecting
 	rc.mu.Unlock()
 
-	logrus.WithField("function", "Connect").Info("Attempting relay connection")
+	pkgLog.WithField("function", "Connect").Info("Attempting relay connection")
 
 	servers := rc.getServersByPriority()
 	if len(servers) == 0 {
@@ -155,7 +155,7 @@ func (rc *RelayClient) Connect(ctx context.Context) error {
 	for _, server := range servers {
 		if err := rc.connectToServer(ctx, server); err != nil {
 			lastErr = err
-			logrus.WithFields(logrus.Fields{
+			pkgLog.WithFields(logrus.Fields{
 				"function": "Connect",
 				"address":  server.Address,
 				"error":    err.Error(),
@@ -172,7 +172,7 @@ func (rc *RelayClient) Connect(ctx context.Context) error {
 		rc.startKeepalive()
 		go rc.readLoop()
 
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "Connect",
 			"address":  server.Address,
 		}).Info("Connected to relay server")
@@ -279,7 +279,7 @@ func (rc *RelayClient) RelayTo(packet *Packet, targetPublicKey [32]byte) error {
 		return fmt.Errorf("failed to send relayed packet: %w", err)
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":    "RelayTo",
 		"packet_type": packet.PacketType,
 		"data_size":   len(packetData),
@@ -336,7 +336,7 @@ func (rc *RelayClient) getActiveConnection() net.Conn {
 // handleReadError processes errors from the read operation.
 func (rc *RelayClient) handleReadError(err error) {
 	if !errors.Is(err, io.EOF) {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "readLoop",
 			"error":    err.Error(),
 		}).Warn("Relay read error")
@@ -447,7 +447,7 @@ func (rc *RelayClient) dispatchPacket(packet *Packet, sourceKey []byte) {
 		default:
 		}
 		if err := handler(packet, relayedAddr); err != nil {
-			logrus.WithFields(logrus.Fields{
+			pkgLog.WithFields(logrus.Fields{
 				"function": "handleDataPacket",
 				"error":    err.Error(),
 			}).Warn("Data handler error")
@@ -475,7 +475,7 @@ func (rc *RelayClient) handleDisconnect() {
 	}
 	rc.mu.Unlock()
 
-	logrus.WithField("function", "handleDisconnect").Info("Relay disconnected")
+	pkgLog.WithField("function", "handleDisconnect").Info("Relay disconnected")
 }
 
 // startKeepalive starts the keepalive ping/pong mechanism.
@@ -525,7 +525,7 @@ func (rc *RelayClient) runKeepaliveLoop() {
 
 func (rc *RelayClient) sendKeepalivePing() {
 	if err := rc.sendPing(); err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "startKeepalive",
 			"error":    err.Error(),
 		}).Warn("Failed to send keepalive ping")
@@ -633,7 +633,7 @@ func (rc *RelayClient) Close() error {
 	rc.state = RelayStateDisconnected
 	rc.activeServer = nil
 
-	logrus.WithField("function", "Close").Info("Relay client closed")
+	pkgLog.WithField("function", "Close").Info("Relay client closed")
 
 	return nil
 }
@@ -142,7 +142,7 @@ func NewRelayMux(conn net.Conn, localKey [32]byte, config *MuxConfig) (*RelayMux
 		cancel:       cancel,
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":    "NewRelayMux",
 		"local_addr":  conn.LocalAddr().String(),
 		"max_streams": config.MaxStreams,
@@ -202,7 +202,7 @@ func (m *RelayMux) OpenStream(ctx context.Context, peerKey [32]byte) (*MuxStream
 
 	m.stats.StreamsOpened.Add(1)
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":  "OpenStream",
 		"stream_id": streamID,
 		"peer_key":  fmt.Sprintf("%x", peerKey[:8]),
@@ -318,7 +318,7 @@ func (m *RelayMux) readNextFrame(header []byte) ([]byte, bool) {
 	}
 
 	if length > uint32(m.config.MaxFrameSize) {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "readLoop",
 			"length":   length,
 			"max":      m.config.MaxFrameSize,
@@ -348,7 +348,7 @@ func (m *RelayMux) readFrameHeader(header []byte) (uint32, error) {
 	_, err := io.ReadFull(m.conn, header)
 	if err != nil {
 		if !errors.Is(err, io.EOF) && !m.closed.Load() {
-			logrus.WithFields(logrus.Fields{
+			pkgLog.WithFields(logrus.Fields{
 				"function": "readLoop",
 				"error":    err.Error(),
 			}).Warn("Mux read error")
@@ -369,7 +369,7 @@ func (m *RelayMux) readFrameData(length uint32) ([]byte, error) {
 	data := make([]byte, length)
 	if _, err := io.ReadFull(m.conn, data); err != nil {
 		if !errors.Is(err, io.EOF) && !m.closed.Load() {
-			logrus.WithFields(logrus.Fields{
+			pkgLog.WithFields(logrus.Fields{
 				"function": "readLoop",
 				"error":    err.Error(),
 			}).Warn("Mux frame read error")
@@ -402,7 +402,7 @@ func (m *RelayMux) processFrame(data []byte) {
 	case MuxPacketStreamReset:
 		m.handleStreamReset(data[1:])
 	default:
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":    "processFrame",
 			"packet_type": packetType,
 		}).Debug("Unknown mux packet type")
@@ -431,7 +431,7 @@ func (m *RelayMux) handleStreamOpen(data []byte) {
 		m.mu.Unlock()
 		// Reject the duplicate inbound open so both peers continue using the existing stream ID.
 		m.sendStreamReset(streamID)
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":    "handleStreamOpen",
 			"stream_id":   streamID,
 			"peer_key":    fmt.Sprintf("%x", peerKey[:8]),
@@ -456,7 +456,7 @@ func (m *RelayMux) handleStreamOpen(data []byte) {
 	m.sendStreamOpenAck(streamID)
 	m.stats.StreamsOpened.Add(1)
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":  "handleStreamOpen",
 		"stream_id": streamID,
 		"peer_key":  fmt.Sprintf("%x", peerKey[:8]),
@@ -490,7 +490,7 @@ func (m *RelayMux) handleStreamOpenAck(data []byte) {
 
 	if ok {
 		stream.lastActive.Store(time.Now().UnixNano())
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":  "handleStreamOpenAck",
 			"stream_id": streamID,
 		}).Debug("Stream open acknowledged")
@@ -519,7 +519,7 @@ func (m *RelayMux) handleStreamData(data []byte) {
 	case stream.readBuf <- payload:
 	default:
 		// Buffer full, drop packet
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":  "handleStreamData",
 			"stream_id": streamID,
 		}).Debug("Stream buffer full, dropping data")
@@ -565,7 +565,7 @@ func (m *RelayMux) handleStreamCloseAck(data []byte) {
 	}
 	m.mu.Unlock()
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":  "handleStreamCloseAck",
 		"stream_id": streamID,
 	}).Debug("Stream close acknowledged")
@@ -589,7 +589,7 @@ func (m *RelayMux) handleStreamReset(data []byte) {
 
 	m.stats.Errors.Add(1)
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":  "handleStreamReset",
 		"stream_id": streamID,
 	}).Debug("Stream reset received")
@@ -674,7 +674,7 @@ func (m *RelayMux) cleanupIdleStreams() {
 	m.mu.Unlock()
 
 	if len(toRemove) > 0 {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "cleanupIdleStreams",
 			"count":    len(toRemove),
 		}).Debug("Cleaned up idle streams")
@@ -757,7 +757,7 @@ func (m *RelayMux) Close() error {
 
 	m.closeAllStreams()
 
-	logrus.WithField("function", "Close").Info("Relay multiplexer closed")
+	pkgLog.WithField("function", "Close").Info("Relay multiplexer closed")
 
 	return m.conn.Close()
 }
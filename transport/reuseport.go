@@ -113,7 +113,7 @@ func normalizeReusePortConfig(config *ReusePortConfig) *ReusePortConfig {
 
 // logReusePortCreation logs the start of transport creation.
 func logReusePortCreation(listenAddr string, numSockets int) {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":    "NewReusePortTransport",
 		"listen_addr": listenAddr,
 		"num_sockets": numSockets,
@@ -148,7 +148,7 @@ func (t *ReusePortTransport) setupSockets(listenAddr string, numSockets int) err
 
 // fallbackToSingleSocket creates a single standard UDP socket as fallback.
 func (t *ReusePortTransport) fallbackToSingleSocket(listenAddr string) error {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "NewReusePortTransport",
 	}).Warn("SO_REUSEPORT not available, falling back to single socket")
 
@@ -170,7 +170,7 @@ func (t *ReusePortTransport) startPacketProcessors() {
 
 // logReusePortSuccess logs successful transport creation.
 func logReusePortSuccess(transport *ReusePortTransport) {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":    "NewReusePortTransport",
 		"listen_addr": transport.listenAddr.String(),
 		"num_sockets": len(transport.sockets),
@@ -183,7 +183,7 @@ func (t *ReusePortTransport) RegisterHandler(packetType PacketType, handler Pack
 	defer t.mu.Unlock()
 	t.handlers[packetType] = handler
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":    "ReusePortTransport.RegisterHandler",
 		"packet_type": packetType,
 	}).Debug("Handler registered")
@@ -205,7 +205,7 @@ func (t *ReusePortTransport) Send(packet *Packet, addr net.Addr) error {
 	_, err = t.sockets[0].WriteTo(data, addr)
 	if err != nil {
 		atomic.AddUint64(&t.sendErrors, 1)
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":    "ReusePortTransport.Send",
 			"target_addr": addr.String(),
 			"error":       err.Error(),
@@ -225,7 +225,7 @@ func (t *ReusePortTransport) processPackets(socketID int, conn net.PacketConn) {
 	const maxPacketSize = 2048
 	buffer := make([]byte, maxPacketSize)
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":  "ReusePortTransport.processPackets",
 		"socket_id": socketID,
 	}).Debug("Starting packet processing loop")
@@ -245,7 +245,7 @@ func (t *ReusePortTransport) processIncomingPacket(socketID int, conn net.Packet
 	// Set read deadline for non-blocking reads with timeout
 	if err := conn.SetReadDeadline(time.Now().Add(100 * time.Millisecond)); err != nil {
 		// Log but continue
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":  "ReusePortTransport.processIncomingPacket",
 			"socket_id": socketID,
 			"error":     err.Error(),
@@ -270,7 +270,7 @@ func (t *ReusePortTransport) processIncomingPacket(socketID int, conn net.Packet
 
 	packet, err := ParsePacket(buffer[:n])
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":  "ReusePortTransport.processIncomingPacket",
 			"socket_id": socketID,
 			"error":     err.Error(),
@@ -298,7 +298,7 @@ func (t *ReusePortTransport) dispatchPacket(packet *Packet, addr net.Addr) {
 		// Legacy unbounded goroutine spawning
 		go func(p *Packet, a net.Addr, h PacketHandler) {
 			if err := h(p, a); err != nil {
-				logrus.WithFields(logrus.Fields{
+				pkgLog.WithFields(logrus.Fields{
 					"function":    "ReusePortTransport.dispatchPacket",
 					"packet_type": p.PacketType,
 					"error":       err.Error(),
@@ -328,7 +328,7 @@ func (t *ReusePortTransport) Close() error {
 		}
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":    "ReusePortTransport.Close",
 		"num_sockets": len(t.sockets),
 	}).Info("Transport closed")
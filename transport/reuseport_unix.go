@@ -61,7 +61,7 @@ func updateAddrIfEphemeral(addr *net.UDPAddr, sharedAddr net.Addr) *net.UDPAddr
 
 // logReusePortSocketsCreated logs successful creation of SO_REUSEPORT sockets.
 func logReusePortSocketsCreated(sharedAddr net.Addr, numSockets int) {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":    "createReusePortSockets",
 		"listen_addr": sharedAddr.String(),
 		"num_sockets": numSockets,
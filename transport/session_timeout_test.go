@@ -280,6 +280,103 @@ func TestSessionTimestampUpdate(t *testing.T) {
 }
 
 // mockTransportHelper is a minimal mock for testing
+func TestSetHandshakeTimeoutReapsStuckHandshakes(t *testing.T) {
+	mockTransport := &mockTransportHelper{}
+	privKey := make([]byte, 32)
+	for i := range privKey {
+		privKey[i] = byte(i)
+	}
+
+	nt, err := NewNoiseTransport(mockTransport, privKey)
+	require.NoError(t, err)
+	defer nt.Close()
+
+	assert.Equal(t, HandshakeTimeout, nt.getHandshakeTimeout())
+
+	nt.SetHandshakeTimeout(time.Second)
+	assert.Equal(t, time.Second, nt.getHandshakeTimeout())
+
+	// Three seconds old: within the default timeout but past the configured one
+	testAddr := &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 8080}
+	oldTime := time.Now().Add(-3 * time.Second)
+	nt.sessionsMu.Lock()
+	nt.sessions[testAddr.String()] = &NoiseSession{
+		peerAddr:   testAddr,
+		complete:   false,
+		createdAt:  oldTime,
+		lastActive: oldTime,
+	}
+	nt.sessionsMu.Unlock()
+
+	nt.performSessionCleanup()
+
+	nt.sessionsMu.RLock()
+	_, exists := nt.sessions[testAddr.String()]
+	nt.sessionsMu.RUnlock()
+	assert.False(t, exists, "Handshake older than the configured timeout should be reaped")
+
+	nt.SetHandshakeTimeout(0)
+	assert.Equal(t, HandshakeTimeout, nt.getHandshakeTimeout(), "Non-positive timeout should restore the default")
+}
+
+func TestHandshakeTimeoutFreesSessionSlot(t *testing.T) {
+	mockTransport := &mockTransportHelper{}
+	privKey := make([]byte, 32)
+	for i := range privKey {
+		privKey[i] = byte(i)
+	}
+
+	nt, err := NewNoiseTransport(mockTransport, privKey)
+	require.NoError(t, err)
+	defer nt.Close()
+	nt.SetHandshakeTimeout(time.Second)
+
+	// An initiator session whose peer never answered in time
+	peerPriv := make([]byte, 32)
+	peerPriv[0] = 1
+	peerKeys, err := generateKeypair(peerPriv)
+	require.NoError(t, err)
+	testAddr := &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 8080}
+	require.NoError(t, nt.AddPeer(testAddr, peerKeys.Public[:]))
+
+	require.NoError(t, nt.initiateHandshake(testAddr))
+	nt.sessionsMu.Lock()
+	nt.sessions[testAddr.String()].createdAt = time.Now().Add(-2 * time.Second)
+	nt.sessionsMu.Unlock()
+
+	err = nt.handleHandshakePacket(&Packet{PacketType: PacketNoiseHandshake, Data: make([]byte, 48)}, testAddr)
+	assert.ErrorIs(t, err, ErrHandshakeTimeout)
+
+	nt.sessionsMu.RLock()
+	_, exists := nt.sessions[testAddr.String()]
+	nt.sessionsMu.RUnlock()
+	assert.False(t, exists, "Timed-out handshake should release its session slot")
+}
+
+func TestRunHandshakeStepDeadline(t *testing.T) {
+	mockTransport := &mockTransportHelper{}
+	privKey := make([]byte, 32)
+	for i := range privKey {
+		privKey[i] = byte(i)
+	}
+
+	nt, err := NewNoiseTransport(mockTransport, privKey)
+	require.NoError(t, err)
+	defer nt.Close()
+	nt.SetHandshakeTimeout(20 * time.Millisecond)
+
+	release := make(chan struct{})
+	defer close(release)
+	err = nt.runHandshakeStep(time.Now(), func() error {
+		<-release
+		return nil
+	})
+	assert.ErrorIs(t, err, ErrHandshakeTimeout)
+
+	err = nt.runHandshakeStep(time.Now(), func() error { return nil })
+	assert.NoError(t, err)
+}
+
 type mockTransportHelper struct {
 	handlers map[PacketType]PacketHandler
 }
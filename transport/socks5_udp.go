@@ -66,7 +66,7 @@ type socks5Auth struct {
 // It performs the initial negotiation including authentication and the
 // UDP ASSOCIATE command, then returns an association ready for use.
 func NewSOCKS5UDPAssociation(proxyAddr, username, password string) (*SOCKS5UDPAssociation, error) {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":   "NewSOCKS5UDPAssociation",
 		"proxy_addr": proxyAddr,
 	}).Info("Creating new SOCKS5 UDP association")
@@ -81,7 +81,7 @@ func NewSOCKS5UDPAssociation(proxyAddr, username, password string) (*SOCKS5UDPAs
 		return nil, err
 	}
 	association.startKeepAlive()
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":   "NewSOCKS5UDPAssociation",
 		"proxy_addr": proxyAddr,
 		"relay_addr": association.relayAddr.String(),
@@ -93,7 +93,7 @@ func NewSOCKS5UDPAssociation(proxyAddr, username, password string) (*SOCKS5UDPAs
 func dialSOCKS5Proxy(proxyAddr string) (net.Conn, error) {
 	tcpConn, err := net.DialTimeout("tcp", proxyAddr, 10*time.Second)
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":   "NewSOCKS5UDPAssociation",
 			"proxy_addr": proxyAddr,
 			"error":      err.Error(),
@@ -137,7 +137,7 @@ func (a *SOCKS5UDPAssociation) initializeUDPAssociation() error {
 
 // performHandshake performs the SOCKS5 method negotiation per RFC 1928.
 func (a *SOCKS5UDPAssociation) performHandshake() error {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "performHandshake",
 	}).Debug("Starting SOCKS5 method negotiation")
 
@@ -182,7 +182,7 @@ func (a *SOCKS5UDPAssociation) performHandshake() error {
 	}
 
 	selectedMethod := response[1]
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":        "performHandshake",
 		"selected_method": selectedMethod,
 	}).Debug("Server selected authentication method")
@@ -218,7 +218,7 @@ func (a *SOCKS5UDPAssociation) performUsernamePasswordAuth() error {
 		return errors.New("server requires authentication but no credentials provided")
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "performUsernamePasswordAuth",
 	}).Debug("Performing username/password authentication")
 
@@ -261,7 +261,7 @@ func (a *SOCKS5UDPAssociation) performUsernamePasswordAuth() error {
 		return errors.New("authentication failed")
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "performUsernamePasswordAuth",
 	}).Debug("Authentication successful")
 
@@ -270,7 +270,7 @@ func (a *SOCKS5UDPAssociation) performUsernamePasswordAuth() error {
 
 // sendUDPAssociateCommand sends the UDP ASSOCIATE command and parses the response.
 func (a *SOCKS5UDPAssociation) sendUDPAssociateCommand() error {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "sendUDPAssociateCommand",
 	}).Debug("Sending UDP ASSOCIATE command")
 
@@ -322,7 +322,7 @@ func (a *SOCKS5UDPAssociation) sendUDPAssociateCommand() error {
 
 	a.relayAddr = relayAddr
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":   "sendUDPAssociateCommand",
 		"relay_addr": relayAddr.String(),
 	}).Info("UDP ASSOCIATE successful, relay address received")
@@ -396,7 +396,7 @@ func (a *SOCKS5UDPAssociation) createUDPSocket() error {
 
 	a.udpConn = udpConn
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":   "createUDPSocket",
 		"local_addr": udpConn.LocalAddr().String(),
 	}).Debug("Local UDP socket created for relay communication")
@@ -433,7 +433,7 @@ func (a *SOCKS5UDPAssociation) SendUDP(data []byte, destAddr net.Addr) error {
 
 	n, err := udpConn.WriteTo(packet, relayAddr)
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":   "SendUDP",
 			"dest_addr":  destAddr.String(),
 			"relay_addr": relayAddr.String(),
@@ -446,7 +446,7 @@ func (a *SOCKS5UDPAssociation) SendUDP(data []byte, destAddr net.Addr) error {
 	a.lastActivity = time.Now()
 	a.mu.Unlock()
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":   "SendUDP",
 		"dest_addr":  destAddr.String(),
 		"bytes_sent": n,
@@ -558,7 +558,7 @@ func (a *SOCKS5UDPAssociation) ReceiveUDP(buffer []byte) (int, net.Addr, error)
 	a.lastActivity = time.Now()
 	a.mu.Unlock()
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":     "ReceiveUDP",
 		"source_addr":  sourceAddr.String(),
 		"payload_size": payloadLen,
@@ -666,7 +666,7 @@ func (a *SOCKS5UDPAssociation) startKeepAlive() {
 		_, err := tcpConn.Read(oneByte)
 		if err != nil && !isTimeoutError(err) {
 			// Connection is dead, close the association
-			logrus.WithFields(logrus.Fields{
+			pkgLog.WithFields(logrus.Fields{
 				"function": "startKeepAlive",
 				"error":    err.Error(),
 			}).Warn("SOCKS5 TCP control connection lost")
@@ -716,7 +716,7 @@ func (a *SOCKS5UDPAssociation) Close() error {
 	}
 	a.closed = true
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "SOCKS5UDPAssociation.Close",
 	}).Info("Closing SOCKS5 UDP association")
 
@@ -37,14 +37,14 @@ type TCPTransport struct {
 //
 //export ToxNewTCPTransport
 func NewTCPTransport(listenAddr string) (Transport, error) {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":    "NewTCPTransport",
 		"listen_addr": listenAddr,
 	}).Info("Creating new TCP transport")
 
 	listener, err := net.Listen("tcp", listenAddr)
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":    "NewTCPTransport",
 			"listen_addr": listenAddr,
 			"error":       err.Error(),
@@ -64,7 +64,7 @@ func NewTCPTransport(listenAddr string) (Transport, error) {
 		connSem:    make(chan struct{}, tcpMaxConnections),
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":     "NewTCPTransport",
 		"listen_addr":  listenAddr,
 		"actual_addr":  listener.Addr().String(),
@@ -74,7 +74,7 @@ func NewTCPTransport(listenAddr string) (Transport, error) {
 	// Start accepting connections
 	go transport.acceptConnections()
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":   "NewTCPTransport",
 		"local_addr": listener.Addr().String(),
 	}).Info("TCP transport initialization completed")
@@ -95,7 +95,7 @@ func NewTCPTransportFromListener(listener net.Listener) (Transport, error) {
 		return nil, fmt.Errorf("listener must not be nil")
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":   "NewTCPTransportFromListener",
 		"local_addr": listener.Addr().String(),
 	}).Info("Creating TCP transport from existing listener")
@@ -114,7 +114,7 @@ func NewTCPTransportFromListener(listener net.Listener) (Transport, error) {
 
 	go t.acceptConnections()
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":   "NewTCPTransportFromListener",
 		"local_addr": listener.Addr().String(),
 	}).Info("TCP transport from listener initialized")
@@ -124,7 +124,7 @@ func NewTCPTransportFromListener(listener net.Listener) (Transport, error) {
 
 // RegisterHandler registers a handler for a specific packet type.
 func (t *TCPTransport) RegisterHandler(packetType PacketType, handler PacketHandler) {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":    "RegisterHandler",
 		"packet_type": packetType,
 		"local_addr":  t.listenAddr.String(),
@@ -135,7 +135,7 @@ func (t *TCPTransport) RegisterHandler(packetType PacketType, handler PacketHand
 
 	t.handlers[packetType] = handler
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":      "RegisterHandler",
 		"packet_type":   packetType,
 		"handler_count": len(t.handlers),
@@ -166,7 +166,7 @@ func (t *TCPTransport) Send(packet *Packet, addr net.Addr) error {
 
 // logSendAttempt logs the initial send attempt.
 func logSendAttempt(packet *Packet, addr, listenAddr net.Addr) {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":    "Send",
 		"packet_type": packet.PacketType,
 		"dest_addr":   addr.String(),
@@ -176,7 +176,7 @@ func logSendAttempt(packet *Packet, addr, listenAddr net.Addr) {
 
 // logSendConnectionError logs connection creation failures.
 func logSendConnectionError(packet *Packet, addr net.Addr, err error) {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":    "Send",
 		"packet_type": packet.PacketType,
 		"dest_addr":   addr.String(),
@@ -186,7 +186,7 @@ func logSendConnectionError(packet *Packet, addr net.Addr, err error) {
 
 // logSendSerializationError logs packet serialization failures.
 func logSendSerializationError(packet *Packet, addr net.Addr, err error) {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":    "Send",
 		"packet_type": packet.PacketType,
 		"dest_addr":   addr.String(),
@@ -205,16 +205,16 @@ func logSendResult(packet *Packet, addr net.Addr, data []byte, err error) {
 
 	if err != nil {
 		fields["error"] = err.Error()
-		logrus.WithFields(fields).Error("Failed to write packet to TCP connection")
+		pkgLog.WithFields(fields).Error("Failed to write packet to TCP connection")
 	} else {
-		logrus.WithFields(fields).Debug("TCP packet sent successfully")
+		pkgLog.WithFields(fields).Debug("TCP packet sent successfully")
 	}
 }
 
 // getOrCreateConnection retrieves an existing connection or creates a new one for the given address.
 func (t *TCPTransport) getOrCreateConnection(addr net.Addr) (net.Conn, error) {
 	addrKey := addr.String()
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":  "getOrCreateConnection",
 		"dest_addr": addrKey,
 	}).Debug("Getting or creating TCP connection")
@@ -234,7 +234,7 @@ func (t *TCPTransport) tryGetExistingConnection(addrKey string) net.Conn {
 	t.mu.RUnlock()
 
 	if exists {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":     "getOrCreateConnection",
 			"dest_addr":    addrKey,
 			"client_count": clientCount,
@@ -252,7 +252,7 @@ func (t *TCPTransport) createNewConnection(addrKey string) (net.Conn, error) {
 	clientCount := len(t.clients)
 	t.mu.RUnlock()
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":     "getOrCreateConnection",
 		"dest_addr":    addrKey,
 		"client_count": clientCount,
@@ -260,7 +260,7 @@ func (t *TCPTransport) createNewConnection(addrKey string) (net.Conn, error) {
 
 	newConn, err := net.Dial("tcp", addrKey)
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":  "getOrCreateConnection",
 			"dest_addr": addrKey,
 			"error":     err.Error(),
@@ -279,7 +279,7 @@ func (t *TCPTransport) createNewConnection(addrKey string) (net.Conn, error) {
 	newClientCount := len(t.clients)
 	t.mu.Unlock()
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":         "getOrCreateConnection",
 		"dest_addr":        addrKey,
 		"new_client_count": newClientCount,
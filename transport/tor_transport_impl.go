@@ -60,7 +60,7 @@ func NewTorTransport() *TorTransport {
 		controlAddr = "127.0.0.1:9051" // Default Tor control port
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":     "NewTorTransport",
 		"control_addr": controlAddr,
 	}).Info("Creating Tor transport (onramp)")
@@ -78,7 +78,7 @@ func (t *TorTransport) ensureOnion() error {
 
 	onion, err := onramp.NewOnion("toxcore-tor")
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":     "TorTransport.ensureOnion",
 			"control_addr": t.controlAddr,
 			"error":        err.Error(),
@@ -94,7 +94,7 @@ func (t *TorTransport) ensureOnion() error {
 // The onramp library panics when the Tor daemon is not running; this helper
 // converts such panics into actionable errors instead of crashing the caller.
 func recoverTorPanic(operation string, r interface{}) error {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":  "TorTransport." + operation,
 		"operation": operation,
 		"panic":     r,
@@ -110,7 +110,7 @@ func (t *TorTransport) Listen(address string) (listener net.Listener, err error)
 	t.mu.Lock()
 	defer t.mu.Unlock()
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "TorTransport.Listen",
 		"address":  address,
 	}).Debug("Tor listen requested")
@@ -132,7 +132,7 @@ func (t *TorTransport) Listen(address string) (listener net.Listener, err error)
 
 	listener, err = t.onion.Listen()
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "TorTransport.Listen",
 			"address":  address,
 			"error":    err.Error(),
@@ -140,7 +140,7 @@ func (t *TorTransport) Listen(address string) (listener net.Listener, err error)
 		return nil, fmt.Errorf("Tor listener creation failed: %w", err)
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":   "TorTransport.Listen",
 		"address":    address,
 		"local_addr": listener.Addr().String(),
@@ -157,7 +157,7 @@ func (t *TorTransport) Dial(address string) (conn net.Conn, err error) {
 	t.mu.Lock()
 	defer t.mu.Unlock()
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":     "TorTransport.Dial",
 		"address":      address,
 		"control_addr": t.controlAddr,
@@ -176,7 +176,7 @@ func (t *TorTransport) Dial(address string) (conn net.Conn, err error) {
 
 	conn, err = t.onion.Dial("tcp", address)
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "TorTransport.Dial",
 			"address":  address,
 			"error":    err.Error(),
@@ -184,7 +184,7 @@ func (t *TorTransport) Dial(address string) (conn net.Conn, err error) {
 		return nil, fmt.Errorf("Tor dial failed: %w", err)
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":    "TorTransport.Dial",
 		"address":     address,
 		"local_addr":  conn.LocalAddr().String(),
@@ -197,7 +197,7 @@ func (t *TorTransport) Dial(address string) (conn net.Conn, err error) {
 // DialPacket creates a packet connection through Tor.
 // Currently returns an error as Tor transport primarily uses TCP.
 func (t *TorTransport) DialPacket(address string) (net.PacketConn, error) {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "TorTransport.DialPacket",
 		"address":  address,
 	}).Debug("Tor packet dial requested")
@@ -216,7 +216,7 @@ func (t *TorTransport) Close() error {
 	t.mu.Lock()
 	defer t.mu.Unlock()
 
-	logrus.WithField("function", "TorTransport.Close").Debug("Closing Tor transport")
+	pkgLog.WithField("function", "TorTransport.Close").Debug("Closing Tor transport")
 
 	if t.onion != nil {
 		err := t.onion.Close()
@@ -14,13 +14,22 @@ import (
 // UDPTransport implements UDP-based communication for the Tox protocol.
 // It satisfies the Transport interface.
 type UDPTransport struct {
-	conn       net.PacketConn // Already using interface type
+	conn       net.PacketConn // Already using interface type; replaced on migration
+	connMu     sync.RWMutex   // Guards conn against replacement by rebind
 	listenAddr net.Addr       // Changed from *net.UDPAddr to net.Addr
 	handlers   map[PacketType]PacketHandler
 	mu         sync.RWMutex
 	closeOnce  sync.Once
 	ctx        context.Context
 	cancel     context.CancelFunc
+
+	// DSCP marking; dscpMu serializes option changes with marked writes
+	dscpMu        sync.Mutex
+	dscpClass     DSCPClass
+	dscpPerPacket bool
+
+	// Connection migration across network changes (see migration.go)
+	migration migrationState
 }
 
 // PacketHandler is a function that processes incoming packets.
@@ -51,7 +60,7 @@ func dispatchPacketHandler(handler PacketHandler, packet *Packet, addr net.Addr)
 //
 //export ToxNewUDPTransport
 func NewUDPTransport(listenAddr string) (Transport, error) {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":    "NewUDPTransport",
 		"listen_addr": listenAddr,
 	}).Info("Creating new UDP transport")
@@ -59,7 +68,7 @@ func NewUDPTransport(listenAddr string) (Transport, error) {
 	// Use net.ListenPacket instead of net.ListenUDP for more abstraction
 	conn, err := net.ListenPacket("udp", listenAddr)
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":    "NewUDPTransport",
 			"listen_addr": listenAddr,
 			"error":       err.Error(),
@@ -77,7 +86,7 @@ func NewUDPTransport(listenAddr string) (Transport, error) {
 		cancel:     cancel,
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":      "NewUDPTransport",
 		"listen_addr":   listenAddr,
 		"actual_addr":   conn.LocalAddr().String(),
@@ -87,7 +96,7 @@ func NewUDPTransport(listenAddr string) (Transport, error) {
 	// Start packet processing loop
 	go transport.processPackets()
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":   "NewUDPTransport",
 		"local_addr": conn.LocalAddr().String(),
 	}).Info("UDP transport initialization completed")
@@ -97,7 +106,7 @@ func NewUDPTransport(listenAddr string) (Transport, error) {
 
 // RegisterHandler registers a handler for a specific packet type.
 func (t *UDPTransport) RegisterHandler(packetType PacketType, handler PacketHandler) {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":    "RegisterHandler",
 		"packet_type": packetType,
 		"local_addr":  t.listenAddr.String(),
@@ -108,7 +117,7 @@ func (t *UDPTransport) RegisterHandler(packetType PacketType, handler PacketHand
 
 	t.handlers[packetType] = handler
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":      "RegisterHandler",
 		"packet_type":   packetType,
 		"handler_count": len(t.handlers),
@@ -120,7 +129,7 @@ func (t *UDPTransport) RegisterHandler(packetType PacketType, handler PacketHand
 //
 //export ToxUDPSend
 func (t *UDPTransport) Send(packet *Packet, addr net.Addr) error {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":    "Send",
 		"packet_type": packet.PacketType,
 		"dest_addr":   addr.String(),
@@ -129,7 +138,7 @@ func (t *UDPTransport) Send(packet *Packet, addr net.Addr) error {
 
 	data, err := packet.Serialize()
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":    "Send",
 			"packet_type": packet.PacketType,
 			"dest_addr":   addr.String(),
@@ -138,9 +147,15 @@ func (t *UDPTransport) Send(packet *Packet, addr net.Addr) error {
 		return err
 	}
 
-	n, err := t.conn.WriteTo(data, addr)
+	t.trackMigrationPeer(addr)
+
+	write := func() (int, error) { return t.packetConn().WriteTo(data, addr) }
+	handled, n, err := t.writeMarked(packet.PacketType, write)
+	if !handled {
+		n, err = write()
+	}
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":    "Send",
 			"packet_type": packet.PacketType,
 			"dest_addr":   addr.String(),
@@ -150,7 +165,7 @@ func (t *UDPTransport) Send(packet *Packet, addr net.Addr) error {
 		return err
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":    "Send",
 		"packet_type": packet.PacketType,
 		"dest_addr":   addr.String(),
@@ -166,24 +181,25 @@ func (t *UDPTransport) Send(packet *Packet, addr net.Addr) error {
 //
 //export ToxUDPClose
 func (t *UDPTransport) Close() error {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":   "Close",
 		"local_addr": t.listenAddr.String(),
 	}).Info("Closing UDP transport")
 
 	t.cancel()
+	t.stopMigration()
 
 	var closeErr error
 	t.closeOnce.Do(func() {
-		closeErr = t.conn.Close()
+		closeErr = t.packetConn().Close()
 		if closeErr != nil {
-			logrus.WithFields(logrus.Fields{
+			pkgLog.WithFields(logrus.Fields{
 				"function":   "Close",
 				"local_addr": t.listenAddr.String(),
 				"error":      closeErr.Error(),
 			}).Error("Error closing UDP connection")
 		} else {
-			logrus.WithFields(logrus.Fields{
+			pkgLog.WithFields(logrus.Fields{
 				"function":   "Close",
 				"local_addr": t.listenAddr.String(),
 			}).Info("UDP transport closed successfully")
@@ -200,7 +216,7 @@ func (t *UDPTransport) IsConnectionOriented() bool {
 
 // processPackets handles incoming packets.
 func (t *UDPTransport) processPackets() {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":   "processPackets",
 		"local_addr": t.listenAddr.String(),
 	}).Info("Starting UDP packet processing loop")
@@ -210,7 +226,7 @@ func (t *UDPTransport) processPackets() {
 	for {
 		select {
 		case <-t.ctx.Done():
-			logrus.WithFields(logrus.Fields{
+			pkgLog.WithFields(logrus.Fields{
 				"function":   "processPackets",
 				"local_addr": t.listenAddr.String(),
 			}).Info("UDP packet processing loop terminated")
@@ -223,14 +239,14 @@ func (t *UDPTransport) processPackets() {
 
 // processIncomingPacket reads and processes a single incoming packet.
 func (t *UDPTransport) processIncomingPacket(buffer []byte) {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":    "processIncomingPacket",
 		"buffer_size": len(buffer),
 	}).Debug("Processing incoming UDP packet")
 
 	data, addr, err := t.readPacketData(buffer)
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "processIncomingPacket",
 			"error":    err.Error(),
 		}).Debug("Failed to read packet data")
@@ -239,7 +255,7 @@ func (t *UDPTransport) processIncomingPacket(buffer []byte) {
 
 	packet, err := t.parsePacketData(data)
 	if err != nil {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":    "processIncomingPacket",
 			"source_addr": addr.String(),
 			"data_size":   len(data),
@@ -248,7 +264,7 @@ func (t *UDPTransport) processIncomingPacket(buffer []byte) {
 		return // Error already handled in parsePacketData
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":    "processIncomingPacket",
 		"source_addr": addr.String(),
 		"packet_type": packet.PacketType,
@@ -260,13 +276,22 @@ func (t *UDPTransport) processIncomingPacket(buffer []byte) {
 
 // readPacketData reads data from the connection with timeout handling.
 func (t *UDPTransport) readPacketData(buffer []byte) ([]byte, net.Addr, error) {
+	conn := t.packetConn()
+
 	// Set read deadline for non-blocking reads with timeout
-	if err := t.conn.SetReadDeadline(time.Now().Add(100 * time.Millisecond)); err != nil {
-		logrus.WithError(err).Warn("Failed to set read deadline on UDP connection")
+	if err := conn.SetReadDeadline(time.Now().Add(100 * time.Millisecond)); err != nil {
+		if errors.Is(err, net.ErrClosed) && t.ctx.Err() == nil {
+			// Replaced by rebind; the next read uses the new socket
+			return nil, nil, err
+		}
+		pkgLog.WithError(err).Warn("Failed to set read deadline on UDP connection")
 	}
 
-	n, addr, err := t.conn.ReadFrom(buffer)
+	n, addr, err := conn.ReadFrom(buffer)
 	if err != nil {
+		if errors.Is(err, net.ErrClosed) && t.ctx.Err() == nil {
+			return nil, nil, err
+		}
 		return nil, nil, t.handleReadError(err)
 	}
 
@@ -278,7 +303,7 @@ func (t *UDPTransport) handleReadError(err error) error {
 	var netErr net.Error
 	if errors.As(err, &netErr) && netErr.Timeout() {
 		// This is just a timeout, continue
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "handleReadError",
 			"error":    "timeout",
 		}).Debug("UDP read timeout (normal operation)")
@@ -287,14 +312,14 @@ func (t *UDPTransport) handleReadError(err error) error {
 	// Check for message too long error by inspecting error string
 	if strings.Contains(err.Error(), "message too long") {
 		// Packet larger than buffer, log and discard
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function": "handleReadError",
 			"error":    "message too long",
 		}).Warn("UDP packet too large for buffer, discarding")
 		return err
 	}
 	// Log other errors here
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "handleReadError",
 		"error":    err.Error(),
 	}).Error("UDP read error")
@@ -303,7 +328,7 @@ func (t *UDPTransport) handleReadError(err error) error {
 
 // parsePacketData parses raw packet data into a structured packet.
 func (t *UDPTransport) parsePacketData(data []byte) (*Packet, error) {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":  "parsePacketData",
 		"data_size": len(data),
 	}).Debug("Parsing packet data")
@@ -311,7 +336,7 @@ func (t *UDPTransport) parsePacketData(data []byte) (*Packet, error) {
 	packet, err := ParsePacket(data)
 	if err != nil {
 		// Log error but continue processing other packets
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":  "parsePacketData",
 			"data_size": len(data),
 			"error":     err.Error(),
@@ -319,7 +344,7 @@ func (t *UDPTransport) parsePacketData(data []byte) (*Packet, error) {
 		return nil, err
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":    "parsePacketData",
 		"packet_type": packet.PacketType,
 		"data_size":   len(data),
@@ -330,7 +355,7 @@ func (t *UDPTransport) parsePacketData(data []byte) (*Packet, error) {
 
 // dispatchPacketToHandler finds and executes the appropriate packet handler.
 func (t *UDPTransport) dispatchPacketToHandler(packet *Packet, addr net.Addr) {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":    "dispatchPacketToHandler",
 		"packet_type": packet.PacketType,
 		"source_addr": addr.String(),
@@ -338,15 +363,16 @@ func (t *UDPTransport) dispatchPacketToHandler(packet *Packet, addr net.Addr) {
 
 	handler, exists, handlerCount := lookupPacketHandler(&t.mu, t.handlers, packet.PacketType)
 	if exists {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":      "dispatchPacketToHandler",
 			"packet_type":   packet.PacketType,
 			"source_addr":   addr.String(),
 			"handler_count": handlerCount,
 		}).Debug("Handler found, processing packet in goroutine")
+		t.trackMigrationPeer(addr)
 		dispatchPacketHandler(handler, packet, addr)
 	} else {
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":      "dispatchPacketToHandler",
 			"packet_type":   packet.PacketType,
 			"source_addr":   addr.String(),
@@ -359,11 +385,18 @@ func (t *UDPTransport) dispatchPacketToHandler(packet *Packet, addr net.Addr) {
 //
 //export ToxUDPLocalAddr
 func (t *UDPTransport) LocalAddr() net.Addr {
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":   "LocalAddr",
 		"local_addr": t.listenAddr.String(),
 	}).Debug("Returning local address")
-	return t.conn.LocalAddr()
+	return t.packetConn().LocalAddr()
+}
+
+// packetConn returns the socket currently in use.
+func (t *UDPTransport) packetConn() net.PacketConn {
+	t.connMu.RLock()
+	defer t.connMu.RUnlock()
+	return t.conn
 }
 
 // GetUDPTransport returns the UDP transport itself.
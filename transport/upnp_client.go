@@ -477,6 +477,15 @@ func (uc *UPnPClient) SetTimeout(timeout time.Duration) {
 	uc.timeout = timeout
 }
 
+// Reset forgets the discovered gateway so the next DiscoverGateway call
+// searches again, e.g. after the host has moved to a different network.
+func (uc *UPnPClient) Reset() {
+	uc.gatewayURL = ""
+	uc.controlURL = ""
+	uc.serviceType = ""
+	uc.discoveryDone = false
+}
+
 // IsAvailable checks if UPnP is available on the network
 func (uc *UPnPClient) IsAvailable(ctx context.Context) bool {
 	err := uc.DiscoverGateway(ctx)
@@ -70,6 +70,9 @@ type VersionedHandshakeResponse struct {
 
 // SerializeVersionedHandshakeRequest converts a versioned handshake request to bytes.
 // Wire format: [version(1)][num_supported(1)][supported_versions][noise_len(2)][noise_data][legacy_data]
+//
+// Capability flags are not part of this format; they travel encrypted in the
+// Noise handshake payload (see VersionedHandshakeManager).
 func SerializeVersionedHandshakeRequest(req *VersionedHandshakeRequest) ([]byte, error) {
 	if req == nil {
 		return nil, errors.New("handshake request cannot be nil")
@@ -272,11 +275,7 @@ func ParseVersionedHandshakeResponse(data []byte) (*VersionedHandshakeResponse,
 	offset += noiseLen
 
 	// Read legacy data
-	var legacyData []byte
-	if offset < len(data) {
-		legacyData = make([]byte, len(data)-offset)
-		copy(legacyData, data[offset:])
-	}
+	legacyData := readLegacyData(data, offset)
 
 	return &VersionedHandshakeResponse{
 		AgreedVersion: agreedVersion,
@@ -300,6 +299,10 @@ type VersionedHandshakeManager struct {
 	handshakeTimeout  time.Duration
 	pendingMu         sync.Mutex
 	pending           map[string]*pendingHandshake
+
+	capabilitiesMu    sync.RWMutex
+	localCapabilities uint64            // Flags advertised in our handshakes
+	negotiated        map[string]uint64 // Local AND remote flags per peer address
 }
 
 // NewVersionedHandshakeManager creates a new versioned handshake manager.
@@ -311,29 +314,33 @@ func NewVersionedHandshakeManager(staticPrivKey [32]byte, supportedVersions []Pr
 		preferredVersion:  preferredVersion,
 		handshakeTimeout:  10 * time.Second,
 		pending:           make(map[string]*pendingHandshake),
+		localCapabilities: LocalCapabilities(),
+		negotiated:        make(map[string]uint64),
 	}
 }
 
 // InitiateHandshake starts a versioned handshake as the initiator.
 // peerPubKey is the peer's long-term public key (required for Noise-IK).
-// prepareNoiseHandshake creates a Noise-IK handshake message for the initiator.
-// It returns the serialized message or nil if Noise-IK is not supported.
-func (vhm *VersionedHandshakeManager) prepareNoiseHandshake(peerPubKey [32]byte) ([]byte, error) {
+// prepareNoiseHandshake creates a Noise-IK handshake message for the initiator,
+// carrying our capability flags as its payload. It returns the serialized
+// message and the handshake needed to read the response, or nils if Noise-IK
+// is not supported.
+func (vhm *VersionedHandshakeManager) prepareNoiseHandshake(peerPubKey [32]byte) ([]byte, *noise.IKHandshake, error) {
 	if !vhm.isVersionSupported(ProtocolNoiseIK) {
-		return nil, nil
+		return nil, nil, nil
 	}
 
 	noiseHandshake, err := noise.NewIKHandshake(vhm.staticPrivKey[:], peerPubKey[:], noise.Initiator)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create noise handshake: %w", err)
+		return nil, nil, fmt.Errorf("failed to create noise handshake: %w", err)
 	}
 
-	message, _, err := noiseHandshake.WriteMessage(nil, nil)
+	message, _, err := noiseHandshake.WriteMessage(encodeCapabilityPayload(vhm.GetLocalCapabilities()), nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create noise handshake message: %w", err)
+		return nil, nil, fmt.Errorf("failed to create noise handshake message: %w", err)
 	}
 
-	return message, nil
+	return message, noiseHandshake, nil
 }
 
 // prepareLegacyHandshake creates legacy handshake data.
@@ -395,7 +402,7 @@ func (vhm *VersionedHandshakeManager) awaitHandshakeResponse(pending *pendingHan
 // legacy compatibility data, and sends it to the peer via the transport.
 // The function blocks until a response is received or the handshake times out.
 func (vhm *VersionedHandshakeManager) InitiateHandshake(peerPubKey [32]byte, transport Transport, peerAddr net.Addr) (*VersionedHandshakeResponse, error) {
-	noiseMessage, err := vhm.prepareNoiseHandshake(peerPubKey)
+	noiseMessage, noiseHandshake, err := vhm.prepareNoiseHandshake(peerPubKey)
 	if err != nil {
 		return nil, err
 	}
@@ -423,7 +430,30 @@ func (vhm *VersionedHandshakeManager) InitiateHandshake(peerPubKey [32]byte, tra
 		return nil, fmt.Errorf("failed to send handshake request: %w", err)
 	}
 
-	return vhm.awaitHandshakeResponse(pending)
+	response, err := vhm.awaitHandshakeResponse(pending)
+	if err != nil {
+		return nil, err
+	}
+	capabilities, err := readResponseCapabilities(noiseHandshake, response)
+	if err != nil {
+		return nil, err
+	}
+	vhm.recordCapabilities(peerAddr, capabilities)
+	return response, nil
+}
+
+// readResponseCapabilities completes our Noise-IK handshake with the
+// response and returns the capability flags from its authenticated payload.
+// A legacy handshake has no authenticated payload and yields no flags.
+func readResponseCapabilities(noiseHandshake *noise.IKHandshake, response *VersionedHandshakeResponse) (uint64, error) {
+	if response.AgreedVersion != ProtocolNoiseIK || noiseHandshake == nil {
+		return 0, nil
+	}
+	payload, _, err := noiseHandshake.ReadMessage(response.NoiseMessage)
+	if err != nil {
+		return 0, fmt.Errorf("failed to process noise handshake response: %w", err)
+	}
+	return decodeCapabilityPayload(payload), nil
 }
 
 // handleHandshakeResponse processes incoming handshake response packets
@@ -461,7 +491,7 @@ func (vhm *VersionedHandshakeManager) handleHandshakeResponse(packet *Packet, ad
 func (vhm *VersionedHandshakeManager) HandleHandshakeRequest(request *VersionedHandshakeRequest, transport Transport, peerAddr net.Addr) (*VersionedHandshakeResponse, error) {
 	agreedVersion := vhm.selectBestVersion(request.SupportedVersions)
 
-	responseNoiseMessage, responseLegacyData, err := vhm.processHandshakeByVersion(agreedVersion, request)
+	responseNoiseMessage, responseLegacyData, capabilities, err := vhm.processHandshakeByVersion(agreedVersion, request)
 	if err != nil {
 		return nil, err
 	}
@@ -476,38 +506,42 @@ func (vhm *VersionedHandshakeManager) HandleHandshakeRequest(request *VersionedH
 		return nil, err
 	}
 
+	vhm.recordCapabilities(peerAddr, capabilities)
 	return response, nil
 }
 
 // processHandshakeByVersion processes handshake based on the agreed version.
-func (vhm *VersionedHandshakeManager) processHandshakeByVersion(version ProtocolVersion, request *VersionedHandshakeRequest) ([]byte, []byte, error) {
+// It also returns the initiator's capability flags, which only a Noise-IK
+// handshake can carry authenticated.
+func (vhm *VersionedHandshakeManager) processHandshakeByVersion(version ProtocolVersion, request *VersionedHandshakeRequest) ([]byte, []byte, uint64, error) {
 	switch version {
 	case ProtocolNoiseIK:
 		return vhm.processNoiseHandshake(request)
 	case ProtocolLegacy:
-		return nil, []byte{}, nil
+		return nil, []byte{}, 0, nil
 	default:
-		return nil, nil, ErrVersionMismatch
+		return nil, nil, 0, ErrVersionMismatch
 	}
 }
 
-// processNoiseHandshake handles Noise-IK protocol handshake.
-func (vhm *VersionedHandshakeManager) processNoiseHandshake(request *VersionedHandshakeRequest) ([]byte, []byte, error) {
+// processNoiseHandshake handles Noise-IK protocol handshake. Capability
+// flags are exchanged as the payloads of the two handshake messages.
+func (vhm *VersionedHandshakeManager) processNoiseHandshake(request *VersionedHandshakeRequest) ([]byte, []byte, uint64, error) {
 	if len(request.NoiseMessage) == 0 {
-		return nil, nil, errors.New("noise message required for Noise-IK handshake")
+		return nil, nil, 0, errors.New("noise message required for Noise-IK handshake")
 	}
 
 	noiseHandshake, err := noise.NewIKHandshake(vhm.staticPrivKey[:], nil, noise.Responder)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to create noise responder handshake: %w", err)
+		return nil, nil, 0, fmt.Errorf("failed to create noise responder handshake: %w", err)
 	}
 
-	message, _, err := noiseHandshake.WriteMessage(nil, request.NoiseMessage)
+	message, _, err := noiseHandshake.WriteMessage(encodeCapabilityPayload(vhm.GetLocalCapabilities()), request.NoiseMessage)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to process noise handshake message: %w", err)
+		return nil, nil, 0, fmt.Errorf("failed to process noise handshake message: %w", err)
 	}
 
-	return message, nil, nil
+	return message, nil, decodeCapabilityPayload(noiseHandshake.GetPeerPayload()), nil
 }
 
 // sendHandshakeResponse serializes and sends the handshake response.
@@ -546,3 +580,54 @@ func (vhm *VersionedHandshakeManager) GetSupportedVersions() []ProtocolVersion {
 	copy(versions, vhm.supportedVersions)
 	return versions
 }
+
+// SetLocalCapabilities overrides the capability flags advertised in future
+// handshakes. By default the manager advertises LocalCapabilities().
+func (vhm *VersionedHandshakeManager) SetLocalCapabilities(flags uint64) {
+	vhm.capabilitiesMu.Lock()
+	defer vhm.capabilitiesMu.Unlock()
+	vhm.localCapabilities = flags
+}
+
+// GetLocalCapabilities returns the capability flags advertised in handshakes.
+func (vhm *VersionedHandshakeManager) GetLocalCapabilities() uint64 {
+	vhm.capabilitiesMu.RLock()
+	defer vhm.capabilitiesMu.RUnlock()
+	return vhm.localCapabilities
+}
+
+// recordCapabilities stores the flags both we and the peer at addr support.
+func (vhm *VersionedHandshakeManager) recordCapabilities(addr net.Addr, remote uint64) {
+	vhm.capabilitiesMu.Lock()
+	defer vhm.capabilitiesMu.Unlock()
+	vhm.negotiated[addr.String()] = vhm.localCapabilities & remote
+}
+
+// GetNegotiatedCapabilities returns the bitwise AND of our capability flags
+// and those of the peer at addr, as agreed in the last completed handshake.
+// Peers that predate capability flags negotiate zero.
+func (vhm *VersionedHandshakeManager) GetNegotiatedCapabilities(addr net.Addr) (uint64, error) {
+	vhm.capabilitiesMu.RLock()
+	defer vhm.capabilitiesMu.RUnlock()
+
+	flags, ok := vhm.negotiated[addr.String()]
+	if !ok {
+		return 0, fmt.Errorf("%w: %s", ErrNoNegotiatedCapabilities, addr)
+	}
+	return flags, nil
+}
+
+// HasCapability reports whether feature was negotiated with the peer at addr.
+// Feature code should check it before using a protocol extension.
+func (vhm *VersionedHandshakeManager) HasCapability(addr net.Addr, feature CapabilityBit) bool {
+	flags, err := vhm.GetNegotiatedCapabilities(addr)
+	return err == nil && flags&feature.Mask() != 0
+}
+
+// ForgetCapabilities discards the capabilities negotiated with the peer at
+// addr, for example when the peer disconnects.
+func (vhm *VersionedHandshakeManager) ForgetCapabilities(addr net.Addr) {
+	vhm.capabilitiesMu.Lock()
+	defer vhm.capabilitiesMu.Unlock()
+	delete(vhm.negotiated, addr.String())
+}
@@ -0,0 +1,358 @@
+package transport
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/sirupsen/logrus"
+)
+
+// WebSocketPath is the HTTP path on which WebSocketTransport accepts
+// upgrades and to which it dials.
+const WebSocketPath = "/tox"
+
+// wsMaxMessageSize bounds an incoming WebSocket message, matching the TCP
+// transport's maximum packet size.
+const wsMaxMessageSize = 1024 * 1024
+
+// wsHandshakeTimeout bounds the HTTP upgrade when dialing.
+const wsHandshakeTimeout = 10 * time.Second
+
+// wsWriteTimeout bounds a single frame write.
+const wsWriteTimeout = 5 * time.Second
+
+// WebSocketTransport carries Tox packets over WebSocket connections, for
+// networks that block UDP and raw TCP but allow HTTP(S), typically on port
+// 443. It satisfies the Transport interface.
+//
+// Each packet is sent as one binary WebSocket message holding the same
+// serialized form the TCP transport uses: the 1-byte packet type followed by
+// the payload. The WebSocket framing replaces TCP's length prefix. As with
+// TCP, a connection is dialed on the first Send to an address, replies to an
+// inbound connection reuse it, and NewNoiseTransport can wrap the transport
+// for end-to-end encryption.
+//
+// With a TLS configuration the transport serves and dials wss://, otherwise
+// ws://.
+type WebSocketTransport struct {
+	listener   net.Listener
+	listenAddr net.Addr
+	server     *http.Server
+	upgrader   websocket.Upgrader
+	dialer     *websocket.Dialer
+	scheme     string
+	handlers   map[PacketType]PacketHandler
+	clients    map[string]*wsConn
+	mu         sync.RWMutex
+	ctx        context.Context
+	cancel     context.CancelFunc
+	closeOnce  sync.Once
+	connSem    chan struct{} // bounded semaphore limiting concurrent inbound connections
+}
+
+// wsConn serializes writes to a WebSocket connection, which supports only
+// one concurrent writer.
+type wsConn struct {
+	conn    *websocket.Conn
+	writeMu sync.Mutex
+}
+
+// writePacket sends data as a single binary message.
+func (c *wsConn) writePacket(data []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if err := c.conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout)); err != nil {
+		return err
+	}
+	return c.conn.WriteMessage(websocket.BinaryMessage, data)
+}
+
+// NewWebSocketTransport listens on listenAddr and serves WebSocket upgrades
+// at WebSocketPath. With a non-nil tlsConfig, which must carry a server
+// certificate, the listener serves TLS and outgoing connections use wss://
+// verified against the same configuration (RootCAs, ServerName,
+// InsecureSkipVerify). Use LoadWebSocketTLSConfig to build one from PEM
+// files.
+func NewWebSocketTransport(listenAddr string, tlsConfig *tls.Config) (*WebSocketTransport, error) {
+	pkgLog.WithFields(logrus.Fields{
+		"function":    "NewWebSocketTransport",
+		"listen_addr": listenAddr,
+		"tls":         tlsConfig != nil,
+	}).Info("Creating new WebSocket transport")
+
+	listener, err := net.Listen("tcp", listenAddr)
+	if err != nil {
+		pkgLog.WithFields(logrus.Fields{
+			"function":    "NewWebSocketTransport",
+			"listen_addr": listenAddr,
+			"error":       err.Error(),
+		}).Error("Failed to create WebSocket listener")
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t := &WebSocketTransport{
+		listenAddr: listener.Addr(),
+		scheme:     "ws",
+		handlers:   make(map[PacketType]PacketHandler),
+		clients:    make(map[string]*wsConn),
+		ctx:        ctx,
+		cancel:     cancel,
+		connSem:    make(chan struct{}, tcpMaxConnections),
+		upgrader: websocket.Upgrader{
+			// Peers authenticate through the Noise handshake, not through
+			// browser credentials, so cross-origin upgrades are allowed.
+			CheckOrigin: func(*http.Request) bool { return true },
+		},
+		dialer: &websocket.Dialer{
+			Proxy:            http.ProxyFromEnvironment,
+			HandshakeTimeout: wsHandshakeTimeout,
+		},
+	}
+	if tlsConfig != nil {
+		listener = tls.NewListener(listener, tlsConfig.Clone())
+		t.dialer.TLSClientConfig = tlsConfig.Clone()
+		t.scheme = "wss"
+	}
+	t.listener = listener
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(WebSocketPath, t.handleUpgrade)
+	t.server = &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: wsHandshakeTimeout,
+	}
+	go func() {
+		if err := t.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			pkgLog.WithFields(logrus.Fields{
+				"function": "NewWebSocketTransport",
+				"error":    err.Error(),
+			}).Error("WebSocket server stopped")
+		}
+	}()
+
+	pkgLog.WithFields(logrus.Fields{
+		"function":   "NewWebSocketTransport",
+		"local_addr": t.listenAddr.String(),
+		"scheme":     t.scheme,
+	}).Info("WebSocket transport created successfully")
+
+	return t, nil
+}
+
+// LoadWebSocketTLSConfig builds a TLS configuration for NewWebSocketTransport
+// from a PEM certificate chain and private key.
+func LoadWebSocketTLSConfig(certFile, keyFile string) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load WebSocket TLS certificate: %w", err)
+	}
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}, nil
+}
+
+// RegisterHandler registers a handler for a specific packet type.
+func (t *WebSocketTransport) RegisterHandler(packetType PacketType, handler PacketHandler) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.handlers[packetType] = handler
+
+	pkgLog.WithFields(logrus.Fields{
+		"function":      "RegisterHandler",
+		"packet_type":   packetType,
+		"handler_count": len(t.handlers),
+		"local_addr":    t.listenAddr.String(),
+	}).Debug("WebSocket packet handler registered")
+}
+
+// Send sends a packet to addr, dialing a WebSocket connection if none is
+// open.
+func (t *WebSocketTransport) Send(packet *Packet, addr net.Addr) error {
+	data, err := packet.Serialize()
+	if err != nil {
+		return err
+	}
+
+	conn, err := t.getOrDial(addr)
+	if err != nil {
+		return err
+	}
+	if err := conn.writePacket(data); err != nil {
+		t.removeConnection(addr, conn)
+		pkgLog.WithFields(logrus.Fields{
+			"function":    "Send",
+			"packet_type": packet.PacketType,
+			"dest_addr":   addr.String(),
+			"error":       err.Error(),
+		}).Error("Failed to write packet to WebSocket connection")
+		return err
+	}
+	return nil
+}
+
+// Dial opens a WebSocket connection to addr, performing the HTTP upgrade
+// handshake, unless one is already open.
+func (t *WebSocketTransport) Dial(addr net.Addr) error {
+	_, err := t.getOrDial(addr)
+	return err
+}
+
+// getOrDial returns the open connection to addr or dials a new one.
+func (t *WebSocketTransport) getOrDial(addr net.Addr) (*wsConn, error) {
+	if err := checkContextCancellation(t.ctx); err != nil {
+		return nil, errors.New("websocket transport closed")
+	}
+
+	addrKey := addr.String()
+	t.mu.RLock()
+	conn, exists := t.clients[addrKey]
+	t.mu.RUnlock()
+	if exists {
+		return conn, nil
+	}
+
+	url := fmt.Sprintf("%s://%s%s", t.scheme, addrKey, WebSocketPath)
+	wsc, resp, err := t.dialer.DialContext(t.ctx, url, nil)
+	if resp != nil && resp.Body != nil {
+		resp.Body.Close()
+	}
+	if err != nil {
+		pkgLog.WithFields(logrus.Fields{
+			"function":  "Dial",
+			"dest_addr": addrKey,
+			"error":     err.Error(),
+		}).Error("Failed to establish WebSocket connection")
+		return nil, fmt.Errorf("websocket dial %s: %w", url, err)
+	}
+	newConn := &wsConn{conn: wsc}
+
+	t.mu.Lock()
+	// Re-check: another goroutine may have connected first while we were dialing.
+	if existing, raced := t.clients[addrKey]; raced {
+		t.mu.Unlock()
+		wsc.Close()
+		return existing, nil
+	}
+	t.clients[addrKey] = newConn
+	t.mu.Unlock()
+
+	pkgLog.WithFields(logrus.Fields{
+		"function":  "Dial",
+		"dest_addr": addrKey,
+	}).Info("WebSocket connection established")
+
+	go t.readLoop(newConn, addr)
+	return newConn, nil
+}
+
+// handleUpgrade accepts an inbound WebSocket connection.
+func (t *WebSocketTransport) handleUpgrade(w http.ResponseWriter, r *http.Request) {
+	select {
+	case t.connSem <- struct{}{}:
+	default:
+		http.Error(w, "too many connections", http.StatusServiceUnavailable)
+		return
+	}
+	defer func() { <-t.connSem }()
+
+	wsc, err := t.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// Upgrade has already replied with an HTTP error
+		return
+	}
+	conn := &wsConn{conn: wsc}
+	addr := wsc.RemoteAddr()
+
+	t.mu.Lock()
+	t.clients[addr.String()] = conn
+	t.mu.Unlock()
+
+	t.readLoop(conn, addr)
+}
+
+// readLoop dispatches packets from conn until it fails, is idle for longer
+// than the TCP read deadline or the transport closes.
+func (t *WebSocketTransport) readLoop(conn *wsConn, addr net.Addr) {
+	defer t.removeConnection(addr, conn)
+
+	conn.conn.SetReadLimit(wsMaxMessageSize)
+	for {
+		if err := checkContextCancellation(t.ctx); err != nil {
+			return
+		}
+		if err := conn.conn.SetReadDeadline(time.Now().Add(tcpReadDeadline)); err != nil {
+			return
+		}
+		messageType, data, err := conn.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		if messageType != websocket.BinaryMessage {
+			continue
+		}
+		t.processPacket(data, addr)
+	}
+}
+
+// removeConnection forgets conn for addr, if it is still the registered
+// connection, and closes it.
+func (t *WebSocketTransport) removeConnection(addr net.Addr, conn *wsConn) {
+	t.mu.Lock()
+	if t.clients[addr.String()] == conn {
+		delete(t.clients, addr.String())
+	}
+	t.mu.Unlock()
+	conn.conn.Close()
+}
+
+// processPacket parses packet data and dispatches it to the appropriate handler.
+func (t *WebSocketTransport) processPacket(data []byte, addr net.Addr) {
+	packet, err := ParsePacket(data)
+	if err != nil {
+		return
+	}
+
+	handler, exists, _ := lookupPacketHandler(&t.mu, t.handlers, packet.PacketType)
+	if exists {
+		dispatchPacketHandler(handler, packet, addr)
+	}
+}
+
+// Close shuts down the transport. It is safe to call Close multiple times.
+func (t *WebSocketTransport) Close() error {
+	var err error
+	t.closeOnce.Do(func() {
+		t.cancel()
+
+		t.mu.Lock()
+		for _, conn := range t.clients {
+			conn.conn.Close()
+		}
+		t.mu.Unlock()
+
+		// Close stops the listener; hijacked WebSocket connections are
+		// closed above.
+		err = t.server.Close()
+	})
+	return err
+}
+
+// LocalAddr returns the local address the transport is listening on.
+func (t *WebSocketTransport) LocalAddr() net.Addr {
+	return t.listenAddr
+}
+
+// IsConnectionOriented returns true: packets travel over persistent
+// WebSocket connections.
+func (t *WebSocketTransport) IsConnectionOriented() bool {
+	return true
+}
@@ -0,0 +1,241 @@
+package transport
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/opd-ai/toxcore/crypto"
+)
+
+// writeTestCertificate writes a self-signed PEM certificate for 127.0.0.1
+// and its key to dir, returning their paths and a pool trusting it.
+func writeTestCertificate(t testing.TB, dir string) (certFile, keyFile string, roots *x509.CertPool) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "toxcore test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certFile, keyFile = filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	roots = x509.NewCertPool()
+	roots.AddCert(cert)
+	return certFile, keyFile, roots
+}
+
+// newWebSocketPair creates a server and a client transport on loopback.
+func newWebSocketPair(t *testing.T, tlsConfig *tls.Config) (server, client *WebSocketTransport) {
+	t.Helper()
+	var err error
+	if server, err = NewWebSocketTransport("127.0.0.1:0", tlsConfig); err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	t.Cleanup(func() { server.Close() })
+	if client, err = NewWebSocketTransport("127.0.0.1:0", tlsConfig); err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return server, client
+}
+
+type receivedPacket struct {
+	packet *Packet
+	addr   net.Addr
+}
+
+func receivePackets(tr Transport, packetType PacketType) chan receivedPacket {
+	received := make(chan receivedPacket, 8)
+	tr.RegisterHandler(packetType, func(packet *Packet, addr net.Addr) error {
+		received <- receivedPacket{packet, addr}
+		return nil
+	})
+	return received
+}
+
+func expectPacket(t *testing.T, received chan receivedPacket, want string) receivedPacket {
+	t.Helper()
+	select {
+	case got := <-received:
+		if string(got.packet.Data) != want {
+			t.Fatalf("Expected %q, got %q", want, got.packet.Data)
+		}
+		return got
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Timed out waiting for %q", want)
+	}
+	return receivedPacket{}
+}
+
+func testWebSocketRoundTrip(t *testing.T, tlsConfig *tls.Config) {
+	server, client := newWebSocketPair(t, tlsConfig)
+	atServer := receivePackets(server, PacketFriendMessage)
+	atClient := receivePackets(client, PacketFriendMessage)
+
+	if err := client.Dial(server.LocalAddr()); err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	if err := client.Send(&Packet{PacketType: PacketFriendMessage, Data: []byte("ping")}, server.LocalAddr()); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	got := expectPacket(t, atServer, "ping")
+
+	// The reply reuses the inbound connection
+	if err := server.Send(&Packet{PacketType: PacketFriendMessage, Data: []byte("pong")}, got.addr); err != nil {
+		t.Fatalf("Reply failed: %v", err)
+	}
+	expectPacket(t, atClient, "pong")
+}
+
+func TestWebSocketTransportRoundTrip(t *testing.T) {
+	testWebSocketRoundTrip(t, nil)
+}
+
+func TestWebSocketTransportTLS(t *testing.T) {
+	certFile, keyFile, roots := writeTestCertificate(t, t.TempDir())
+	tlsConfig, err := LoadWebSocketTLSConfig(certFile, keyFile)
+	if err != nil {
+		t.Fatalf("LoadWebSocketTLSConfig failed: %v", err)
+	}
+	tlsConfig.RootCAs = roots
+	testWebSocketRoundTrip(t, tlsConfig)
+
+	// A client that does not trust the certificate cannot connect
+	server, err := NewWebSocketTransport("127.0.0.1:0", tlsConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+	untrusted := tlsConfig.Clone()
+	untrusted.RootCAs = x509.NewCertPool()
+	client, err := NewWebSocketTransport("127.0.0.1:0", untrusted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	if err := client.Dial(server.LocalAddr()); err == nil {
+		t.Error("Expected dial with an untrusted certificate to fail")
+	}
+
+	if _, err := LoadWebSocketTLSConfig(filepath.Join(t.TempDir(), "missing.pem"), keyFile); err == nil {
+		t.Error("Expected missing certificate file to fail")
+	}
+}
+
+func TestWebSocketTransportFraming(t *testing.T) {
+	server, err := NewWebSocketTransport("127.0.0.1:0", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+	if !server.IsConnectionOriented() {
+		t.Error("Expected WebSocket transport to be connection-oriented")
+	}
+	received := receivePackets(server, PacketPingRequest)
+
+	conn, resp, err := websocket.DefaultDialer.Dial("ws://"+server.LocalAddr().String()+WebSocketPath, nil)
+	if err != nil {
+		t.Fatalf("Raw dial failed: %v", err)
+	}
+	resp.Body.Close()
+	defer conn.Close()
+
+	// Text frames are ignored; binary frames carry [type][payload]
+	if err := conn.WriteMessage(websocket.TextMessage, []byte{byte(PacketPingRequest), 'x'}); err != nil {
+		t.Fatal(err)
+	}
+	if err := conn.WriteMessage(websocket.BinaryMessage, []byte{byte(PacketPingRequest), 'o', 'k'}); err != nil {
+		t.Fatal(err)
+	}
+	expectPacket(t, received, "ok")
+
+	if err := server.Send(&Packet{PacketType: PacketPingResponse, Data: []byte("pong")}, conn.LocalAddr()); err != nil {
+		t.Fatalf("Send to raw client failed: %v", err)
+	}
+	messageType, data, err := conn.ReadMessage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if messageType != websocket.BinaryMessage || PacketType(data[0]) != PacketPingResponse || string(data[1:]) != "pong" {
+		t.Errorf("Unexpected frame type %d data %v", messageType, data)
+	}
+}
+
+func TestWebSocketTransportWithNoise(t *testing.T) {
+	server, client := newWebSocketPair(t, nil)
+
+	serverKeys, err := crypto.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientKeys, err := crypto.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	noiseServer, err := NewNoiseTransport(server, serverKeys.Private[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer noiseServer.Close()
+	noiseClient, err := NewNoiseTransport(client, clientKeys.Private[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer noiseClient.Close()
+
+	if err := noiseClient.AddPeer(server.LocalAddr(), serverKeys.Public[:]); err != nil {
+		t.Fatal(err)
+	}
+	atServer := receivePackets(noiseServer, PacketFriendMessage)
+
+	packet := &Packet{PacketType: PacketFriendMessage, Data: []byte("over noise")}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if err := noiseClient.Send(packet, server.LocalAddr()); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Timed out waiting for the Noise handshake over WebSocket")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	expectPacket(t, atServer, "over noise")
+}
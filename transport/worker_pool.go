@@ -91,7 +91,7 @@ func NewWorkerPool(config *WorkerPoolConfig) *WorkerPool {
 		go wp.worker(i)
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function":    "NewWorkerPool",
 		"num_workers": config.NumWorkers,
 		"queue_size":  config.QueueSize,
@@ -104,7 +104,7 @@ func NewWorkerPool(config *WorkerPoolConfig) *WorkerPool {
 // worker is the main loop for a worker goroutine.
 func (wp *WorkerPool) worker(id int) {
 	defer wp.wg.Done()
-	logrus.WithField("worker_id", id).Debug("Worker started")
+	pkgLog.WithField("worker_id", id).Debug("Worker started")
 
 	for {
 		select {
@@ -114,7 +114,7 @@ func (wp *WorkerPool) worker(id int) {
 			}
 			wp.processWork(work)
 		case <-wp.stopChan:
-			logrus.WithField("worker_id", id).Debug("Worker stopping (stop signal)")
+			pkgLog.WithField("worker_id", id).Debug("Worker stopping (stop signal)")
 			return
 		}
 	}
@@ -125,7 +125,7 @@ func (wp *WorkerPool) shouldStopWorker(id int, ok bool) bool {
 	if ok {
 		return false
 	}
-	logrus.WithField("worker_id", id).Debug("Worker stopping (channel closed)")
+	pkgLog.WithField("worker_id", id).Debug("Worker stopping (channel closed)")
 	return true
 }
 
@@ -133,7 +133,7 @@ func (wp *WorkerPool) shouldStopWorker(id int, ok bool) bool {
 func (wp *WorkerPool) processWork(work *packetWork) {
 	defer func() {
 		if r := recover(); r != nil {
-			logrus.WithFields(logrus.Fields{
+			pkgLog.WithFields(logrus.Fields{
 				"function":    "WorkerPool.processWork",
 				"panic":       r,
 				"packet_type": work.packet.PacketType,
@@ -168,7 +168,7 @@ func (wp *WorkerPool) trySubmitNonBlocking(work *packetWork) bool {
 		return false
 	default:
 		atomic.AddUint64(&wp.dropped, 1)
-		logrus.WithFields(logrus.Fields{
+		pkgLog.WithFields(logrus.Fields{
 			"function":    "WorkerPool.Submit",
 			"packet_type": work.packet.PacketType,
 			"queue_len":   len(wp.workChan),
@@ -215,7 +215,7 @@ func (wp *WorkerPool) Stop() {
 		return // Already stopped
 	}
 
-	logrus.WithField("function", "WorkerPool.Stop").Info("Stopping worker pool")
+	pkgLog.WithField("function", "WorkerPool.Stop").Info("Stopping worker pool")
 
 	close(wp.stopChan)
 	wp.wg.Wait()
@@ -227,7 +227,7 @@ func (wp *WorkerPool) Stop() {
 		drained++
 	}
 
-	logrus.WithFields(logrus.Fields{
+	pkgLog.WithFields(logrus.Fields{
 		"function": "WorkerPool.Stop",
 		"drained":  drained,
 	}).Info("Worker pool stopped")